@@ -8,6 +8,9 @@
 -- Enable pgvector extension
 CREATE EXTENSION IF NOT EXISTS vector;
 
+-- Enable pg_trgm (trigram index over file contents for cindex grep)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 -- Core Tables
 
 CREATE TABLE code_chunks (
@@ -93,11 +96,318 @@ ALTER TABLE code_files ADD COLUMN IF NOT EXISTS workspace_id TEXT;
 ALTER TABLE code_files ADD COLUMN IF NOT EXISTS package_name TEXT;
 ALTER TABLE code_files ADD COLUMN IF NOT EXISTS service_id TEXT;
 
+-- Parse diagnostics from the last build (NULL when tree-sitter parsed the file cleanly)
+-- parse_error_column counts UTF-16 code units, parse_error_byte_column UTF-8 bytes
+-- parse_partial: tree-sitter kept the declarations that parsed instead of falling back to regex
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS parse_error TEXT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS parse_error_line INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS parse_error_column INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS parse_error_byte_column INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS parse_partial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Original encoding of files transcoded to UTF-8 for indexing (NULL for UTF-8)
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS encoding TEXT;
+
+-- Generated files indexed with GENERATED_FILES=tag (hidden from search_codebase unless include_generated)
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS generated BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Integrity: chunks written for the file and their checksum (MD5 of the sorted per-chunk MD5s)
+-- quarantined_at: stored chunks failed verification; dropped until the file is rebuilt
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS chunk_count INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS chunk_checksum TEXT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS quarantined_at TIMESTAMP;
+
+-- License: SPDX identifier or expression; source spdx/header (declared in the file) or directory (inherited)
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS license TEXT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS license_source TEXT;
+CREATE INDEX IF NOT EXISTS idx_files_license ON code_files(license);
+
+-- Go build constraint: file name suffix and //go:build line as one expression (NULL: every platform)
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS build_constraint TEXT;
+
+-- Size and modification time (epoch milliseconds) when indexed; incremental runs skip reading
+-- files whose size and mtime are unchanged
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS file_size BIGINT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS file_mtime_ms BIGINT;
+
+-- Line kinds (code, comment only, blank; NULL before they were recorded) and whether the file holds tests,
+-- by name or directory; cindex stats --by aggregates them with the symbols of each file
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS code_lines INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS comment_lines INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS blank_lines INT;
+ALTER TABLE code_files ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Secret scanner findings (SCAN_SECRETS=true): redacted preview and fingerprint only, never the secret
+-- Restricted: not joined into search or exposed by MCP tools; read with `cindex secrets`
+CREATE TABLE IF NOT EXISTS secret_findings (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    rule TEXT NOT NULL,
+    line_number INT NOT NULL,
+    column_number INT NOT NULL,  -- UTF-16 code units
+    byte_column INT NOT NULL,    -- UTF-8 bytes
+    redacted TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,   -- Truncated SHA-256 of the value (same secret, same fingerprint)
+    detected_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_secret_findings_file ON secret_findings(file_path);
+CREATE INDEX IF NOT EXISTS idx_secret_findings_repo ON secret_findings(repo_id);
+REVOKE ALL ON secret_findings FROM PUBLIC;
+
+-- Linter findings imported with `cindex lint <report>` (staticcheck, golangci-lint)
+-- Each import replaces the previous findings of its tool; re-indexing a file clears its findings
+CREATE TABLE IF NOT EXISTS lint_findings (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    line_number INT NOT NULL,
+    column_number INT NOT NULL,
+    end_line INT,
+    tool TEXT NOT NULL,          -- 'staticcheck' or 'golangci-lint'
+    linter TEXT NOT NULL,        -- Reporting linter (staticcheck, errcheck, govet, ...)
+    rule TEXT NOT NULL,          -- Check code (SA4006) or linter name
+    severity TEXT NOT NULL,
+    message TEXT NOT NULL,
+    imported_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_lint_findings_file ON lint_findings(file_path, line_number);
+CREATE INDEX IF NOT EXISTS idx_lint_findings_repo ON lint_findings(repo_id);
+
+-- Type-checked Go facts from `cindex index --typed` (go/packages)
+-- Each typed run replaces its index's rows; re-indexing a file clears the rows on it
+CREATE TABLE IF NOT EXISTS go_implementations (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,     -- File declaring the type
+    line_number INT NOT NULL,
+    type_name TEXT NOT NULL,
+    type_package TEXT NOT NULL,
+    interface_name TEXT NOT NULL,
+    interface_package TEXT NOT NULL, -- Import path ('' for error)
+    pointer_receiver BOOLEAN NOT NULL DEFAULT false -- Only *T satisfies the interface
+);
+CREATE INDEX IF NOT EXISTS idx_go_implementations_type ON go_implementations(file_path, type_name);
+CREATE INDEX IF NOT EXISTS idx_go_implementations_repo ON go_implementations(repo_id);
+
+CREATE TABLE IF NOT EXISTS go_references (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    line_number INT NOT NULL,
+    column_number INT NOT NULL,
+    target_name TEXT NOT NULL,   -- Package-level name, or Receiver.Method
+    target_package TEXT NOT NULL,
+    target_file TEXT NOT NULL,
+    target_line INT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_go_references_target ON go_references(target_name);
+CREATE INDEX IF NOT EXISTS idx_go_references_file ON go_references(file_path);
+CREATE INDEX IF NOT EXISTS idx_go_references_target_file ON go_references(target_file);
+CREATE INDEX IF NOT EXISTS idx_go_references_repo ON go_references(repo_id);
+ALTER TABLE go_references ADD COLUMN IF NOT EXISTS access TEXT; -- 'read', 'write', or 'call'
+
+-- Go call graph: calls found in function bodies at index time (no type checking)
+-- Each indexed Go file replaces its rows
+CREATE TABLE IF NOT EXISTS go_calls (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    caller_name TEXT NOT NULL,   -- Function, or Receiver.Method
+    caller_line INT NOT NULL,
+    callee_name TEXT NOT NULL,   -- Function, Receiver.Method (method), or method name (dynamic)
+    callee_package TEXT,         -- Import path (import)
+    callee_qualifier TEXT,       -- Expression the method is called on (dynamic)
+    call_kind TEXT NOT NULL,     -- 'function', 'method', 'import', or 'dynamic'
+    line_number INT NOT NULL,
+    column_number INT NOT NULL   -- UTF-8 bytes
+);
+CREATE INDEX IF NOT EXISTS idx_go_calls_callee ON go_calls(callee_name);
+CREATE INDEX IF NOT EXISTS idx_go_calls_caller ON go_calls(caller_name);
+CREATE INDEX IF NOT EXISTS idx_go_calls_file ON go_calls(file_path);
+CREATE INDEX IF NOT EXISTS idx_go_calls_repo ON go_calls(repo_id);
+
+-- Go constants with their values where the file determines them (cindex enums)
+-- Each indexed Go file replaces its rows
+CREATE TABLE IF NOT EXISTS go_constants (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    constant_name TEXT NOT NULL,
+    type_name TEXT,              -- Declared, inherited within the group, or converted to
+    value TEXT,                  -- Go literal (2, 3600, "v1"); NULL if it depends on other files
+    expression TEXT NOT NULL,    -- As written, or repeated from the spec above
+    iota INT NOT NULL,           -- Index of the spec in its declaration
+    group_line INT NOT NULL,     -- Line of the const keyword
+    line_number INT NOT NULL,
+    doc TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_go_constants_type ON go_constants(type_name);
+CREATE INDEX IF NOT EXISTS idx_go_constants_name ON go_constants(constant_name);
+CREATE INDEX IF NOT EXISTS idx_go_constants_file ON go_constants(file_path);
+CREATE INDEX IF NOT EXISTS idx_go_constants_repo ON go_constants(repo_id);
+
+-- Type parameters of generic Go functions and types (generic: and constraint: filters, cindex generics)
+-- Each indexed Go file replaces its rows
+CREATE TABLE IF NOT EXISTS go_type_parameters (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    symbol_name TEXT NOT NULL,   -- Generic function or type
+    symbol_kind TEXT NOT NULL,   -- 'function' or 'type'
+    parameter_name TEXT NOT NULL,
+    constraint_text TEXT NOT NULL, -- As written: any, comparable, ~int | ~string, constraints.Ordered
+    position INT NOT NULL,       -- 0-based index in the type parameter list
+    line_number INT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_go_type_parameters_symbol ON go_type_parameters(file_path, symbol_name);
+CREATE INDEX IF NOT EXISTS idx_go_type_parameters_name ON go_type_parameters(symbol_name);
+CREATE INDEX IF NOT EXISTS idx_go_type_parameters_repo ON go_type_parameters(repo_id);
+
+-- Generic names followed by explicit type arguments (Map[int, string], Cache[K, V]{}); resolved to generics when
+-- queried, since an index expression reads the same. Each indexed Go file replaces its rows
+CREATE TABLE IF NOT EXISTS go_instantiations (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    target_name TEXT NOT NULL,
+    target_package TEXT,         -- Import path when qualified by an import
+    type_arguments TEXT[] NOT NULL,
+    context_name TEXT,           -- Function or Receiver.Method containing the instantiation
+    line_number INT NOT NULL,
+    column_number INT NOT NULL   -- 1-based, UTF-8 bytes
+);
+CREATE INDEX IF NOT EXISTS idx_go_instantiations_target ON go_instantiations(target_name);
+CREATE INDEX IF NOT EXISTS idx_go_instantiations_file ON go_instantiations(file_path);
+CREATE INDEX IF NOT EXISTS idx_go_instantiations_repo ON go_instantiations(repo_id);
+
+-- Types embedded in Go structs and interfaces (cindex hierarchy); resolved to declarations when queried
+-- Each indexed Go file replaces its rows
+CREATE TABLE IF NOT EXISTS go_embeddings (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    type_name TEXT NOT NULL,     -- Struct or interface doing the embedding
+    type_kind TEXT NOT NULL,     -- 'struct' or 'interface'
+    embedded_name TEXT NOT NULL, -- Without type arguments: Base of Base[T]
+    embedded_package TEXT,       -- Import path when qualified by an import
+    pointer BOOLEAN NOT NULL,    -- Embedded as *T
+    line_number INT NOT NULL,
+    column_number INT NOT NULL   -- 1-based, UTF-8 bytes
+);
+CREATE INDEX IF NOT EXISTS idx_go_embeddings_type ON go_embeddings(type_name);
+CREATE INDEX IF NOT EXISTS idx_go_embeddings_embedded ON go_embeddings(embedded_name);
+CREATE INDEX IF NOT EXISTS idx_go_embeddings_file ON go_embeddings(file_path);
+CREATE INDEX IF NOT EXISTS idx_go_embeddings_repo ON go_embeddings(repo_id);
+
+-- Annotations read from comments: TODOs, deprecations, Go directives and build tags (cindex todos, deprecated)
+-- Each indexed file replaces its rows
+CREATE TABLE IF NOT EXISTS code_annotations (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    kind TEXT NOT NULL,          -- todo, deprecated, directive, build, plugin
+    tag TEXT NOT NULL,           -- Marker as written: TODO, FIXME, Deprecated, @deprecated, go:generate, ...
+    assignee TEXT,               -- TODO(assignee)
+    text TEXT NOT NULL,
+    line_number INT NOT NULL,
+    column_number INT NOT NULL,  -- UTF-16 code units
+    byte_column INT NOT NULL,    -- UTF-8 bytes
+    symbol_name TEXT,            -- Symbol documented or enclosing; NULL for the file
+    symbol_type TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_code_annotations_kind ON code_annotations(kind);
+CREATE INDEX IF NOT EXISTS idx_code_annotations_assignee ON code_annotations(LOWER(assignee));
+CREATE INDEX IF NOT EXISTS idx_code_annotations_file ON code_annotations(file_path);
+CREATE INDEX IF NOT EXISTS idx_code_annotations_repo ON code_annotations(repo_id);
+
+-- String literals and non-doc comments, one per line (cindex search --scope strings|comments)
+CREATE TABLE IF NOT EXISTS code_literals (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    kind TEXT NOT NULL,          -- string, comment
+    text TEXT NOT NULL,          -- Strings as written, comments without their markers
+    line_number INT NOT NULL,
+    column_number INT NOT NULL,  -- UTF-16 code units, of the opening quote or comment marker
+    byte_column INT NOT NULL     -- UTF-8 bytes
+);
+CREATE INDEX IF NOT EXISTS idx_code_literals_trgm ON code_literals USING GIN (text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_code_literals_file ON code_literals(file_path);
+CREATE INDEX IF NOT EXISTS idx_code_literals_repo ON code_literals(repo_id);
+
+-- Metrics reported by analyzer plugins, per file or symbol (cindex plugins --metric)
+CREATE TABLE IF NOT EXISTS code_plugin_metrics (
+    id BIGSERIAL PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    analyzer TEXT NOT NULL,      -- Analyzer that reported it
+    name TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    symbol_name TEXT,            -- Symbol measured; NULL for the file
+    line_number INT
+);
+CREATE INDEX IF NOT EXISTS idx_code_plugin_metrics_name ON code_plugin_metrics(name);
+CREATE INDEX IF NOT EXISTS idx_code_plugin_metrics_file ON code_plugin_metrics(file_path);
+CREATE INDEX IF NOT EXISTS idx_code_plugin_metrics_repo ON code_plugin_metrics(repo_id);
+
+-- File contents for regex search (cindex grep)
+-- The trigram index lets PostgreSQL read only files holding every trigram a pattern requires
+CREATE TABLE IF NOT EXISTS code_contents (
+    file_path TEXT PRIMARY KEY,
+    repo_id TEXT,
+    repo_path TEXT NOT NULL,
+    content TEXT NOT NULL        -- As indexed: UTF-8, NFC, LF line endings
+);
+CREATE INDEX IF NOT EXISTS idx_code_contents_trgm ON code_contents USING GIN (content gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_code_contents_repo ON code_contents(repo_id);
+
 ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS repo_id TEXT;
 ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS workspace_id TEXT;
 ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS package_name TEXT;
 ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS service_id TEXT;
 
+-- Function metrics: last line, cyclomatic and cognitive complexity (set at index time)
+-- coverage: percent of statements covered, from `cindex coverage <profile>` (NULL until imported; reset on re-index)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS end_line INT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS complexity INT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS cognitive_complexity INT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS coverage REAL;
+
+-- Clone fingerprints: MinHash signature of the normalized function body and its token count (cindex dupes)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS clone_signature INT[];
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS clone_tokens INT;
+
+-- Last change to a symbol's lines, from git blame (cindex index --history; NULL until recorded, reset on re-index)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS last_commit TEXT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS last_author TEXT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS last_modified TIMESTAMPTZ;
+
+-- Doc comments (markers stripped) and their full-text vector with the words of the symbol name (cindex doc --search)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS doc_comment TEXT;
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS doc_tsv TSVECTOR;
+CREATE INDEX IF NOT EXISTS idx_symbols_doc_tsv ON code_symbols USING GIN (doc_tsv);
+
+-- Words of the symbol name, lowercase, and their stems (word: search, see @indexing/tokenizer)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS name_tokens TEXT[];
+CREATE INDEX IF NOT EXISTS idx_symbols_name_tokens ON code_symbols USING GIN (name_tokens);
+
+-- Structured symbol IDs: package, then the descriptors of the symbol (id: search, see @indexing/symbol-ids)
+ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS symbol_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_symbols_symbol_id ON code_symbols(symbol_id);
+
 -- Hybrid Search Support (vector + full-text search)
 -- tsvector columns for PostgreSQL full-text search, combined with vector similarity
 ALTER TABLE code_chunks ADD COLUMN IF NOT EXISTS content_tsv tsvector;
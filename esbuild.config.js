@@ -9,11 +9,12 @@ import * as esbuild from 'esbuild';
  * Base configuration shared between build and dev modes
  */
 const baseConfig = {
-  entryPoints: ['src/index.ts'],
+  // Parse workers run in their own threads, so their entry is bundled next to the server
+  entryPoints: { index: 'src/index.ts', 'parse-worker': 'src/indexing/parse-worker.ts' },
   bundle: true,
   platform: 'node',
   target: 'node22',
-  outfile: 'dist/index.js',
+  outdir: 'dist',
   sourcemap: true,
   format: 'esm',
 
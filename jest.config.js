@@ -16,10 +16,13 @@ export default {
   coverageReporters: ['text', 'lcov', 'html'],
   moduleNameMapper: {
     '^@/(.*)$': '<rootDir>/src/$1',
+    '^@cli/(.*)$': '<rootDir>/src/cli/$1',
     '^@config/(.*)$': '<rootDir>/src/config/$1',
     '^@database/(.*)$': '<rootDir>/src/database/$1',
     '^@indexing/(.*)$': '<rootDir>/src/indexing/$1',
     '^@retrieval/(.*)$': '<rootDir>/src/retrieval/$1',
+    '^@http/(.*)$': '<rootDir>/src/http/$1',
+    '^@lsp/(.*)$': '<rootDir>/src/lsp/$1',
     '^@mcp/(.*)$': '<rootDir>/src/mcp/$1',
     '^@utils/(.*)$': '<rootDir>/src/utils/$1',
     '^@types/(.*)$': '<rootDir>/src/types/$1',
@@ -0,0 +1,59 @@
+/**
+ * User-defined command aliases
+ *
+ * Aliases are declared under `aliases:` in a .cindex.yaml in the working
+ * directory or any ancestor (nearer files override farther ones), so teams
+ * can commit shared shortcuts alongside the code:
+ *
+ *   aliases:
+ *     types: search kind:type name:$1
+ *     todo: search name:$1 path:src/ --porcelain
+ *
+ * `$1`..`$9` insert positional arguments and `$@` inserts all of them;
+ * arguments not consumed by a placeholder are appended.
+ */
+import { findProjectConfigs, readMapping } from '@cli/project-config';
+
+/**
+ * Load aliases from .cindex.yaml files in a directory and its ancestors
+ *
+ * @param cwd - Directory to start from (default: process.cwd())
+ * @returns Alias name to expansion template
+ */
+export const loadAliases = (cwd: string = process.cwd()): Record<string, string> => {
+  const layers = findProjectConfigs(cwd).map((config) => readMapping(config, 'aliases'));
+
+  // Farthest first so nearer configs win
+  return Object.assign({}, ...layers.reverse()) as Record<string, string>;
+};
+
+/**
+ * Expand an alias template with the user's arguments
+ *
+ * @param template - Alias expansion (e.g. 'search kind:type name:$1')
+ * @param args - Arguments given after the alias name
+ * @returns Expanded argv (command name first)
+ */
+export const expandAlias = (template: string, args: string[]): string[] => {
+  const used = new Set<number>();
+  let usedAll = false;
+
+  const expanded = template
+    .split(/\s+/)
+    .filter(Boolean)
+    .flatMap((token) => {
+      if (token === '$@') {
+        usedAll = true;
+        return args;
+      }
+      const replaced = token.replace(/\$(\d)/g, (_, digit: string) => {
+        const index = Number(digit) - 1;
+        used.add(index);
+        return args[index] ?? '';
+      });
+      return replaced.length > 0 ? [replaced] : [];
+    });
+
+  const rest = usedAll ? [] : args.filter((_, index) => !used.has(index));
+  return [...expanded, ...rest];
+};
@@ -0,0 +1,193 @@
+/**
+ * CLI commands: todos, deprecated, annotations
+ * List annotations read from comments at index time (see @indexing/annotations)
+ *
+ *   cindex todos                          TODO, FIXME, HACK, XXX, and BUG comments
+ *   cindex todos --assignee ada           TODO(ada): ... (case-insensitive)
+ *   cindex todos --unassigned --tag FIXME
+ *   cindex deprecated                     symbols marked Deprecated: or @deprecated
+ *   cindex annotations --kind directive   //go:generate, //go:embed, ... (and build tags with --kind build)
+ *   cindex annotations --kind plugin      reported by analyzer plugins (see @indexing/plugins)
+ *
+ * Each annotation names the symbol it documents or sits in, so a deprecation
+ * can be followed with `cindex refs`. Columns are UTF-8 bytes by default;
+ * pass --position-encoding utf-16 for LSP-style code-unit columns.
+ */
+import { parseArgs } from 'node:util';
+
+import { getPositionEncoding, isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listAnnotations, type AnnotationFilter } from '@database/queries';
+import { selectColumn } from '@utils/positions';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { ANNOTATION_KINDS, type AnnotationKind, type AnnotationRecord } from '@/types/database';
+
+/** Work markers read as todos */
+const TODO_TAGS = ['TODO', 'FIXME', 'HACK', 'XXX', 'BUG'];
+
+/**
+ * Marker of an annotation as written, with its assignee
+ */
+const markerOf = (annotation: AnnotationRecord): string =>
+  annotation.assignee ? `${annotation.tag}(${annotation.assignee})` : annotation.tag;
+
+/**
+ * List annotations and print them
+ *
+ * @param filter - Annotations to list
+ * @param empty - Message when there are none
+ */
+const printAnnotations = async (filter: AnnotationFilter, empty: string): Promise<ExitCode> => {
+  const { db } = await openSession();
+  try {
+    const annotations = await readIndex(filter.repoId, () => listAnnotations(db.getPool(), filter));
+    const encoding = getPositionEncoding();
+    const columnOf = (annotation: AnnotationRecord): number =>
+      selectColumn({ column: annotation.column_number, byte_column: annotation.byte_column }, encoding);
+
+    // Porcelain: annotation<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>kind<TAB>tag<TAB>assignee<TAB>symbol<TAB>text
+    if (isPorcelain()) {
+      for (const annotation of annotations) {
+        const { repo_id, file_path, line_number, kind, tag, assignee, symbol_name, text } = annotation;
+        printRecord('annotation', [
+          repo_id,
+          file_path,
+          line_number,
+          columnOf(annotation),
+          kind,
+          tag,
+          assignee,
+          symbol_name,
+          text,
+        ]);
+      }
+      return annotations.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    }
+    if (annotations.length === 0) {
+      print(empty);
+      return ExitCode.NoResults;
+    }
+
+    const theme = getTheme();
+    for (const annotation of annotations) {
+      const position = theme.line(`${String(annotation.line_number)}:${String(columnOf(annotation))}`);
+      const symbol = annotation.symbol_name
+        ? theme.dim(`(${annotation.symbol_type ?? 'symbol'} ${annotation.symbol_name})`)
+        : theme.dim('(file)');
+      const text = annotation.text ? `  ${annotation.text}` : '';
+      print(`${theme.path(annotation.file_path)}:${position}  ${theme.kind(markerOf(annotation))}${text}  ${symbol}`);
+    }
+    const files = new Set(annotations.map((annotation) => annotation.file_path)).size;
+    print();
+    print(`${String(annotations.length)} annotations in ${String(files)} files`);
+    return ExitCode.Success;
+  } finally {
+    await db.close();
+  }
+};
+
+/**
+ * Todos command - list TODO-style comments, optionally by assignee
+ */
+export const todosCommand: CliCommand = {
+  name: 'todos',
+  description: 'List TODO, FIXME, HACK, XXX, and BUG comments, by assignee',
+  usage: 'cindex todos [--assignee <name> | --unassigned] [--tag <marker>] [--repo-id <name>]',
+  options: [
+    { name: 'assignee', description: 'Only TODO(<name>) comments (case-insensitive)', takesValue: true },
+    { name: 'unassigned', description: 'Only comments without an assignee' },
+    { name: 'tag', description: 'Only this marker (repeatable)', takesValue: true, complete: TODO_TAGS },
+    REPO_ID_OPTION,
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        assignee: { type: 'string' },
+        unassigned: { type: 'boolean', default: false },
+        tag: { type: 'string', multiple: true, default: [] },
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    if (values.assignee !== undefined && values.unassigned) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Pass either --assignee or --unassigned' });
+    }
+    const unknown = values.tag.find((tag) => !TODO_TAGS.includes(tag.toUpperCase()));
+    if (unknown !== undefined) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --tag value '${unknown}' (expected ${TODO_TAGS.join(', ')})`,
+      });
+    }
+
+    const assignee = values.unassigned ? null : values.assignee?.replace(/^@/, '');
+    return printAnnotations(
+      { kinds: ['todo'], tags: values.tag, assignee, repoId: resolveRepoId(values['repo-id']) },
+      assignee ? `No TODOs assigned to ${assignee}` : 'No TODOs in the index'
+    );
+  },
+};
+
+/**
+ * Deprecated command - list symbols and files marked deprecated
+ */
+export const deprecatedCommand: CliCommand = {
+  name: 'deprecated',
+  description: 'List symbols marked Deprecated: or @deprecated',
+  usage: 'cindex deprecated [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    return printAnnotations(
+      { kinds: ['deprecated'], repoId: resolveRepoId(values['repo-id']) },
+      'No deprecated symbols in the index'
+    );
+  },
+};
+
+/**
+ * Annotations command - list annotations of any kind
+ */
+export const annotationsCommand: CliCommand = {
+  name: 'annotations',
+  description: 'List annotations from comments: todos, deprecations, Go directives, build tags, and plugins',
+  usage: 'cindex annotations [--kind <kind>] [--tag <marker>] [--repo-id <name>]',
+  options: [
+    {
+      name: 'kind',
+      description: 'Only this kind (repeatable; default: all)',
+      takesValue: true,
+      complete: [...ANNOTATION_KINDS],
+    },
+    { name: 'tag', description: 'Only this marker, e.g. go:generate (repeatable)', takesValue: true },
+    REPO_ID_OPTION,
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        kind: { type: 'string', multiple: true, default: [] },
+        tag: { type: 'string', multiple: true, default: [] },
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const unknown = values.kind.find((kind) => !(ANNOTATION_KINDS as readonly string[]).includes(kind));
+    if (unknown !== undefined) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --kind value '${unknown}' (expected ${ANNOTATION_KINDS.join(', ')})`,
+      });
+    }
+
+    const kinds = values.kind.length > 0 ? (values.kind as AnnotationKind[]) : [...ANNOTATION_KINDS];
+    return printAnnotations(
+      { kinds, tags: values.tag, repoId: resolveRepoId(values['repo-id']) },
+      'No annotations in the index'
+    );
+  },
+};
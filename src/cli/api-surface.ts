@@ -0,0 +1,238 @@
+/**
+ * Exported API surface: normalized listing and breaking-change diff (cindex api)
+ *
+ * Every exported symbol becomes one entry: its module, kind, name, and a
+ * signature normalized so that formatting, doc comments, and initializer
+ * values do not count as changes. A module is the symbol's directory for
+ * languages whose packages are directories (Go, Java, Kotlin, C#) and its
+ * file otherwise. The listing is sorted, so snapshots of the same tree are
+ * byte-identical and diff cleanly in review.
+ */
+import * as path from 'node:path';
+
+import { compareStrings } from '@utils/ordering';
+import { type ExportedSymbolRecord } from '@/types/database';
+import { Language } from '@/types/indexing';
+
+/** Snapshot format version (bumped if entry fields change meaning) */
+export const API_SNAPSHOT_VERSION = 1;
+
+/** Package pattern matching the whole repository */
+export const ALL_PACKAGES = './...';
+
+/**
+ * One exported symbol in the API surface
+ */
+export interface ApiEntry {
+  module: string;
+  kind: string;
+  name: string;
+  signature: string;
+}
+
+/**
+ * Saved API surface (`cindex api --json`), the baseline for --diff
+ */
+export interface ApiSnapshot {
+  version: number;
+  repo_id: string | null;
+  /** Package pattern the snapshot was taken with */
+  pattern: string;
+  entries: ApiEntry[];
+}
+
+/**
+ * Difference between a baseline and the current API surface
+ * - removed, changed: breaking
+ * - added: compatible
+ */
+export interface ApiChange {
+  status: 'removed' | 'changed' | 'added';
+  /** Entry as it is now (as it was, for removed entries) */
+  entry: ApiEntry;
+  /** Baseline signature of a changed entry */
+  previous?: string;
+}
+
+/** Languages whose packages are directories, not files */
+const DIRECTORY_PACKAGES: ReadonlySet<string> = new Set([Language.Go, Language.Java, Language.Kotlin, Language.CSharp]);
+
+/**
+ * Check whether a file path matches a package pattern
+ *
+ * Patterns follow Go's convention, relative to the repository root:
+ * `./...` matches everything, `./pkg/...` a directory and its subdirectories,
+ * and `./pkg` (or `pkg/file.ts`) one directory's files or one file.
+ *
+ * @param filePath - Stored file path (forward slashes)
+ * @param pattern - Package pattern
+ */
+export const matchesPackagePattern = (filePath: string, pattern: string): boolean => {
+  const normalized = pattern.replace(/\\/g, '/').replace(/^(?:\.\/)+/, '').replace(/\/+$/, '');
+  if (normalized === '...') return true;
+  if (normalized.endsWith('/...')) return filePath.startsWith(normalized.slice(0, -'...'.length));
+
+  const dir = path.posix.dirname(filePath);
+  return filePath === normalized || dir === (normalized === '' ? '.' : normalized);
+};
+
+/**
+ * Normalize a stored symbol definition into a comparable signature
+ *
+ * Whitespace is collapsed; `export` keywords, doc comments appended by the
+ * symbol extractor, and variable initializers are dropped.
+ *
+ * @param symbol - Exported symbol
+ * @returns Single-line signature (always containing the symbol's name)
+ */
+export const normalizeSignature = (
+  symbol: Pick<ExportedSymbolRecord, 'symbol_name' | 'symbol_type' | 'definition'>
+): string => {
+  const kind = symbol.symbol_type;
+  let text = symbol.definition ?? '';
+  if (kind === 'function' || kind === 'method' || kind === 'class') {
+    // Function and class definitions end with " - <docstring>"
+    text = text.replace(/ - [\s\S]*$/, '');
+  }
+  text = text
+    .replace(/\s+/g, ' ')
+    .trim()
+    .replace(/^export\s+(?:default\s+)?/, '')
+    .replace(/\s*;$/, '');
+  if (kind === 'variable' || kind === 'constant') {
+    text = text.replace(/\s*=(?!>)[\s\S]*$/, '');
+  }
+  return text.includes(symbol.symbol_name) ? text : `${kind} ${symbol.symbol_name}`;
+};
+
+/**
+ * Paths of an index relative to its tree: revision and remote source indexes store them under <index>/
+ *
+ * @param symbols - Exported symbols of one index
+ * @param repoId - The index
+ * @returns The symbols, without the prefix if every path has it
+ */
+export const relativeToIndex = (symbols: ExportedSymbolRecord[], repoId: string): ExportedSymbolRecord[] => {
+  const prefix = `${repoId}/`;
+  if (symbols.length === 0 || !symbols.every((symbol) => symbol.file_path.startsWith(prefix))) return symbols;
+  return symbols.map((symbol) => ({ ...symbol, file_path: symbol.file_path.slice(prefix.length) }));
+};
+
+/**
+ * Module an exported symbol belongs to
+ */
+const moduleOf = (symbol: Pick<ExportedSymbolRecord, 'file_path' | 'language'>): string => {
+  if (!DIRECTORY_PACKAGES.has(symbol.language)) return symbol.file_path;
+  const dir = path.posix.dirname(symbol.file_path);
+  return dir === '.' ? '' : dir;
+};
+
+/**
+ * Identity of an entry across snapshots
+ */
+const entryKey = (entry: ApiEntry): string => `${entry.module}\0${entry.kind}\0${entry.name}`;
+
+/**
+ * Compare entries by module, kind, name, then signature
+ */
+const compareEntries = (a: ApiEntry, b: ApiEntry): number => {
+  return (
+    compareStrings(a.module, b.module) ||
+    compareStrings(a.kind, b.kind) ||
+    compareStrings(a.name, b.name) ||
+    compareStrings(a.signature, b.signature)
+  );
+};
+
+/**
+ * Build the sorted, de-duplicated API surface of a set of exported symbols
+ *
+ * @param symbols - Exported symbols
+ * @returns One entry per distinct module, kind, name, and signature
+ */
+export const buildApiSurface = (symbols: ExportedSymbolRecord[]): ApiEntry[] => {
+  const entries = new Map<string, ApiEntry>();
+  for (const symbol of symbols) {
+    const entry: ApiEntry = {
+      module: moduleOf(symbol),
+      kind: symbol.symbol_type,
+      name: symbol.symbol_name,
+      signature: normalizeSignature(symbol),
+    };
+    entries.set(`${entryKey(entry)}\0${entry.signature}`, entry);
+  }
+  return [...entries.values()].sort(compareEntries);
+};
+
+/**
+ * One line of the text listing (`pkg <module>, <signature>`, like Go's api/*.txt)
+ */
+export const formatApiEntry = (entry: ApiEntry): string => `pkg ${entry.module || '.'}, ${entry.signature}`;
+
+/**
+ * Diff a baseline API surface against the current one
+ *
+ * Entries are matched by module, kind, and name. When exactly one baseline
+ * and one current signature share a name, a difference is reported as a
+ * change; overloads that disappear or appear are removed or added.
+ *
+ * @param previous - Baseline entries
+ * @param current - Current entries
+ * @returns Changes ordered like the listing
+ */
+export const diffApiSurface = (previous: ApiEntry[], current: ApiEntry[]): ApiChange[] => {
+  const group = (entries: ApiEntry[]): Map<string, ApiEntry[]> => {
+    const groups = new Map<string, ApiEntry[]>();
+    for (const entry of entries) {
+      groups.set(entryKey(entry), [...(groups.get(entryKey(entry)) ?? []), entry]);
+    }
+    return groups;
+  };
+  const before = group(previous);
+  const after = group(current);
+
+  const changes: ApiChange[] = [];
+  for (const key of new Set([...before.keys(), ...after.keys()])) {
+    const old = before.get(key) ?? [];
+    const now = after.get(key) ?? [];
+    const removed = old.filter((entry) => !now.some((candidate) => candidate.signature === entry.signature));
+    const added = now.filter((entry) => !old.some((candidate) => candidate.signature === entry.signature));
+
+    if (removed.length === 1 && added.length === 1 && old.length === 1 && now.length === 1) {
+      changes.push({ status: 'changed', entry: added[0], previous: removed[0].signature });
+      continue;
+    }
+    changes.push(...removed.map((entry) => ({ status: 'removed' as const, entry })));
+    changes.push(...added.map((entry) => ({ status: 'added' as const, entry })));
+  }
+
+  return changes.sort((a, b) => compareEntries(a.entry, b.entry) || compareStrings(a.status, b.status));
+};
+
+/**
+ * Check whether a change breaks existing callers
+ */
+export const isBreakingChange = (change: ApiChange): boolean => change.status !== 'added';
+
+/**
+ * Parse a saved snapshot
+ *
+ * @param text - Content of a file written by `cindex api --json`
+ * @returns Snapshot
+ * @throws {Error} If the content is not a snapshot of a supported version
+ */
+export const parseApiSnapshot = (text: string): ApiSnapshot => {
+  const value = JSON.parse(text) as Partial<ApiSnapshot> | null;
+  if (typeof value !== 'object' || value === null || !Array.isArray(value.entries)) {
+    throw new Error('not an API snapshot (expected the output of cindex api --json)');
+  }
+  if (value.version !== API_SNAPSHOT_VERSION) {
+    throw new Error(`unsupported snapshot version ${String(value.version)} (expected ${String(API_SNAPSHOT_VERSION)})`);
+  }
+  return {
+    version: value.version,
+    repo_id: value.repo_id ?? null,
+    pattern: value.pattern ?? ALL_PACKAGES,
+    entries: value.entries,
+  };
+};
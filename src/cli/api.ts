@@ -0,0 +1,195 @@
+/**
+ * CLI commands: api, api-diff
+ * List the exported API surface, or diff it against a saved snapshot or another index
+ *
+ *   cindex api ./...                      normalized listing (pkg <module>, <signature>)
+ *   cindex api ./... --json > api.json    snapshot to commit or attach to a release
+ *   cindex api ./... --diff api.json      breaking changes since the snapshot
+ *   cindex api-diff api@v1.4.0 api        breaking changes between two indexes
+ *
+ * Removed and changed entries are breaking and exit with 5, so a release
+ * pipeline can gate on the diff. The surface comes from the index, so run
+ * `cindex index` on the release candidate first. api-diff compares indexes
+ * already in the database, such as a release indexed with --rev or from a
+ * URL and the working copy, with paths relative to each index's tree.
+ */
+import * as fs from 'node:fs';
+import { parseArgs } from 'node:util';
+
+import {
+  ALL_PACKAGES,
+  API_SNAPSHOT_VERSION,
+  buildApiSurface,
+  diffApiSurface,
+  formatApiEntry,
+  isBreakingChange,
+  matchesPackagePattern,
+  parseApiSnapshot,
+  relativeToIndex,
+  type ApiChange,
+  type ApiSnapshot,
+} from '@cli/api-surface';
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listExportedSymbols, listIndexedRepositories } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Marker per change status in the text diff */
+const CHANGE_MARKERS: Record<ApiChange['status'], string> = { removed: '-', changed: '~', added: '+' };
+
+/**
+ * Print changes between a baseline and the current surface
+ *
+ * @param source - Snapshot file or index the baseline came from
+ * @returns ExitCode.PolicyViolation if any change is breaking
+ */
+const printDiff = (baseline: ApiSnapshot, current: ApiSnapshot, source: string): ExitCode => {
+  const changes = diffApiSurface(baseline.entries, current.entries);
+  const breaking = changes.filter(isBreakingChange);
+
+  // Porcelain: api_change<TAB>status<TAB>module<TAB>kind<TAB>name<TAB>signature<TAB>previous_signature
+  if (isPorcelain()) {
+    for (const { status, entry, previous } of changes) {
+      printRecord('api_change', [status, entry.module, entry.kind, entry.name, entry.signature, previous]);
+    }
+    return breaking.length > 0 ? ExitCode.PolicyViolation : ExitCode.Success;
+  }
+
+  const theme = getTheme();
+  if (baseline.pattern !== current.pattern) {
+    print(theme.dim(`Note: ${source} was taken with ${baseline.pattern}; comparing against ${current.pattern}`));
+  }
+  for (const change of changes) {
+    const line = `${CHANGE_MARKERS[change.status]} ${formatApiEntry(change.entry)}`;
+    print(change.previous ? `${line}  ${theme.dim(`(was: ${change.previous})`)}` : line);
+  }
+
+  const count = (status: ApiChange['status']): string =>
+    String(changes.filter((change) => change.status === status).length);
+  if (changes.length > 0) print();
+  print(
+    `${String(breaking.length)} breaking changes (${count('removed')} removed, ${count('changed')} changed), ` +
+      `${count('added')} added since ${source}`
+  );
+  return breaking.length > 0 ? ExitCode.PolicyViolation : ExitCode.Success;
+};
+
+/**
+ * API command - exported symbol listing and breaking-change diff
+ */
+export const apiCommand: CliCommand = {
+  name: 'api',
+  description: 'List exported symbols and signatures, or diff them against a snapshot',
+  usage: 'cindex api [<pattern>] [--json] [--diff <snapshot.json>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'json', description: 'Write a snapshot for a later --diff' },
+    { name: 'diff', description: 'Report changes since a snapshot (exit 5 if any break callers)', takesValue: true },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' }, json: { type: 'boolean' }, diff: { type: 'string' } },
+    });
+    const repoId = resolveRepoId(values['repo-id']);
+    const pattern = positionals[0] ?? ALL_PACKAGES;
+
+    let baseline: ApiSnapshot | undefined;
+    if (values.diff) {
+      try {
+        baseline = parseApiSnapshot(fs.readFileSync(values.diff, 'utf-8'));
+      } catch (error) {
+        const message = error instanceof Error ? error.message : String(error);
+        return reportError(ExitCode.Failure, {
+          code: 'INVALID_SNAPSHOT',
+          message: `Cannot read ${values.diff}: ${message}`,
+          file: values.diff,
+          hint: 'Create a snapshot with: cindex api --json > api.json',
+        });
+      }
+    }
+
+    const { db } = await openSession();
+    try {
+      const symbols = await readIndex(repoId, () => listExportedSymbols(db.getPool(), repoId));
+      const current: ApiSnapshot = {
+        version: API_SNAPSHOT_VERSION,
+        repo_id: repoId ?? null,
+        pattern,
+        entries: buildApiSurface(symbols.filter((symbol) => matchesPackagePattern(symbol.file_path, pattern))),
+      };
+
+      if (baseline) return printDiff(baseline, current, values.diff ?? '');
+
+      if (values.json) {
+        process.stdout.write(JSON.stringify(current, null, 2) + '\n');
+      } else if (isPorcelain()) {
+        // Porcelain: api<TAB>module<TAB>kind<TAB>name<TAB>signature
+        for (const entry of current.entries) {
+          printRecord('api', [entry.module, entry.kind, entry.name, entry.signature]);
+        }
+      } else {
+        for (const entry of current.entries) {
+          print(formatApiEntry(entry));
+        }
+      }
+      return current.entries.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
+
+/**
+ * API diff command - breaking changes between the API surfaces of two indexes
+ */
+export const apiDiffCommand: CliCommand = {
+  name: 'api-diff',
+  description: 'Report breaking API changes between two indexes, e.g. a release and its successor',
+  usage: 'cindex api-diff <old-index> <new-index> [<pattern>]',
+  positional: 'repo',
+  run: async (args) => {
+    const { positionals } = parseArgs({ args, allowPositionals: true, options: {} });
+    const [oldId, newId, pattern = ALL_PACKAGES] = positionals;
+    if (!oldId || !newId || positionals.length > 3) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Expected two indexes and an optional package pattern',
+        hint: 'e.g. cindex api-diff api@v1.4.0 api@v1.5.0 ./pkg/...',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const indexed = new Set((await listIndexedRepositories(pool)).map((repo) => repo.repo_id));
+      const missing = [oldId, newId].filter((id) => !indexed.has(id));
+      if (missing.length > 0) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index: ${missing.join(', ')}`,
+          hint: "Run 'cindex list' to see available indexes; index a release with cindex index . --rev <tag>",
+        });
+      }
+
+      const [baseline, current] = await Promise.all(
+        [oldId, newId].map(async (id): Promise<ApiSnapshot> => {
+          const symbols = relativeToIndex(await readIndex(id, () => listExportedSymbols(pool, id)), id);
+          return {
+            version: API_SNAPSHOT_VERSION,
+            repo_id: id,
+            pattern,
+            entries: buildApiSurface(symbols.filter((symbol) => matchesPackagePattern(symbol.file_path, pattern))),
+          };
+        })
+      );
+      return printDiff(baseline, current, oldId);
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,195 @@
+/**
+ * CLI command: audit
+ * Flag security-sensitive patterns in the index with the audit rules (see @indexing/audit-rules)
+ *
+ *   cindex audit                        built-in rules and those of .cindex.yaml
+ *   cindex audit --severity high        only high and critical findings
+ *   cindex audit --rules audit.yaml     rules of a file too
+ *
+ * Built-in rules flag SQL built by string concatenation, passwords compared
+ * as plain text, and session IDs or tokens made from weak randomness or the
+ * clock. Rules read the index as stored, so re-index before auditing
+ * changed code.
+ */
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import {
+  getPositionEncoding,
+  isNdjson,
+  isPorcelain,
+  print,
+  printJsonRecord,
+  printRecord,
+  reportError,
+  toErrorReport,
+} from '@cli/output';
+import { findProjectConfigs, parseProjectConfig } from '@cli/project-config';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoCalls, listSourceFiles, listSymbolSpans } from '@database/queries';
+import {
+  AUDIT_SEVERITIES,
+  isAuditSeverity,
+  parseAuditConfig,
+  resolveAuditRules,
+  runAudit,
+  severityRank,
+  type AuditConfig,
+  type AuditFinding,
+} from '@indexing/audit-rules';
+import { selectColumn } from '@utils/positions';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Read the rule configs: a --rules file, then the audit mappings of .cindex.yaml files
+ *
+ * @param rulesFile - Value of --rules, if given
+ * @returns Configs, nearest first
+ * @throws {Error} If a file cannot be read or a rule is invalid (the message names the file)
+ */
+const loadAuditConfigs = (rulesFile: string | undefined): AuditConfig[] => {
+  const sources: { file: string; doc: unknown }[] = findProjectConfigs().map((config) => ({
+    file: config.file,
+    doc: config.doc.audit,
+  }));
+  if (rulesFile) sources.unshift({ file: path.resolve(rulesFile), doc: parseProjectConfig(rulesFile) });
+
+  return sources.map(({ file, doc }) => {
+    try {
+      return parseAuditConfig(doc);
+    } catch (error) {
+      throw new Error(`${file}: ${error instanceof Error ? error.message : String(error)}`);
+    }
+  });
+};
+
+/**
+ * Print findings
+ *
+ * Porcelain: audit<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>rule<TAB>severity<TAB>symbol<TAB>message
+ */
+const printFindings = (findings: AuditFinding[]): void => {
+  const encoding = getPositionEncoding();
+  const columnOf = (finding: AuditFinding): number =>
+    selectColumn({ column: finding.column_number, byte_column: finding.byte_column }, encoding);
+
+  if (isNdjson()) {
+    for (const finding of findings) {
+      printJsonRecord('audit', {
+        repo_id: finding.repo_id,
+        path: finding.file_path,
+        line: finding.line_number,
+        column: columnOf(finding),
+        rule: finding.rule,
+        severity: finding.severity,
+        symbol: finding.symbol_name,
+        message: finding.message,
+        text: finding.text,
+      });
+    }
+    return;
+  }
+  if (isPorcelain()) {
+    for (const finding of findings) {
+      const { repo_id, file_path, line_number, rule, severity, symbol_name, message } = finding;
+      printRecord('audit', [repo_id, file_path, line_number, columnOf(finding), rule, severity, symbol_name, message]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const finding of findings) {
+    const position = theme.line(`${String(finding.line_number)}:${String(columnOf(finding))}`);
+    const label = theme.dim(`(${finding.rule}${finding.symbol_name ? `, in ${finding.symbol_name}` : ''})`);
+    const severity = finding.severity.toUpperCase();
+    print(`${theme.path(finding.file_path)}:${position}  ${severity}  ${finding.message}  ${label}`);
+    print(`    ${theme.dim(finding.text)}`);
+  }
+  print();
+  const counts = [...AUDIT_SEVERITIES].reverse().flatMap((severity) => {
+    const count = findings.filter((finding) => finding.severity === severity).length;
+    return count > 0 ? [`${String(count)} ${severity}`] : [];
+  });
+  print(`${String(findings.length)} findings (${counts.join(', ')})`);
+};
+
+/**
+ * Audit command - match the audit rules against the index
+ */
+export const auditCommand: CliCommand = {
+  name: 'audit',
+  description: 'Flag security-sensitive patterns such as SQL concatenation and plaintext passwords',
+  usage: 'cindex audit [--severity <level>] [--rules <file>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'severity',
+      description: `Lowest severity reported: ${AUDIT_SEVERITIES.join(', ')} (default low)`,
+      takesValue: true,
+      complete: [...AUDIT_SEVERITIES],
+    },
+    {
+      name: 'rules',
+      description: 'YAML file of more rules, in the form of the audit mapping of .cindex.yaml',
+      takesValue: true,
+      complete: 'path',
+    },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: { 'repo-id': { type: 'string' }, severity: { type: 'string' }, rules: { type: 'string' } },
+    });
+
+    const minimum = values.severity ?? 'low';
+    if (!isAuditSeverity(minimum)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --severity value: ${minimum}`,
+        hint: `Expected one of ${AUDIT_SEVERITIES.join(', ')}`,
+      });
+    }
+
+    let configs: AuditConfig[];
+    try {
+      configs = loadAuditConfigs(values.rules);
+    } catch (error) {
+      // Read and YAML errors keep their file and position; rule errors name their file in the message
+      const report = toErrorReport(error);
+      return reportError(ExitCode.Failure, {
+        ...report,
+        code: report.code === 'INTERNAL_ERROR' ? 'INVALID_RULES' : report.code,
+        hint: 'See Audit Rules in the README for the rule fields',
+      });
+    }
+    const rules = resolveAuditRules(configs).filter((rule) => severityRank(rule.severity) >= severityRank(minimum));
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const source = await readIndex(repoId, async () => ({
+        files: await listSourceFiles(pool, repoId),
+        calls: await listGoCalls(pool, repoId),
+        spans: await listSymbolSpans(pool, repoId),
+      }));
+      const findings = runAudit(source, rules);
+
+      if (findings.length === 0) {
+        if (!isPorcelain()) {
+          print(`No findings for ${String(rules.length)} rules in ${String(source.files.length)} files`);
+        }
+        return ExitCode.Success;
+      }
+      printFindings(findings);
+
+      // Findings fail the check, like secrets do
+      return ExitCode.PartialFailure;
+    } finally {
+      await db.close();
+    }
+  },
+};
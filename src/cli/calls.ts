@@ -0,0 +1,178 @@
+/**
+ * CLI commands: callers, callees
+ * Walk the Go call graph recorded at index time (see @indexing/go-calls)
+ *
+ *   cindex callers AuthService.CreateSession   calls of one method
+ *   cindex callers auth.Login                  calls of a function, qualified by its package
+ *   cindex callees AuthService.Login           calls made by a method
+ *
+ * Calls are resolved from syntax alone: through the receiver, to a
+ * same-package function, or through an import. A method called on a value of
+ * unknown type is listed as a possible caller of every method of that name;
+ * --exact leaves those out. For type-checked uses, see cindex refs.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoCallees, listGoCallers } from '@database/queries';
+import { defaultImportName } from '@indexing/go-calls';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoCallRecord } from '@/types/database';
+
+/**
+ * Callee as written at the call: time.Now, s.dbClient.Exec, AuthService.queryUser
+ */
+export const calleeLabel = (call: GoCallRecord): string => {
+  if (call.call_kind === 'import' && call.callee_package) {
+    return `${defaultImportName(call.callee_package)}.${call.callee_name}`;
+  }
+  if (call.call_kind === 'dynamic' && call.callee_qualifier) return `${call.callee_qualifier}.${call.callee_name}`;
+  return call.callee_name;
+};
+
+/**
+ * Print calls
+ *
+ * Porcelain:
+ *   call<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>caller<TAB>callee<TAB>kind<TAB>package<TAB>qualifier
+ *
+ * @param calls - Calls to print
+ * @param show - Side of each call to name after its location
+ */
+const printCalls = (calls: GoCallRecord[], show: 'caller' | 'callee'): void => {
+  if (isPorcelain()) {
+    for (const call of calls) {
+      printRecord('call', [
+        call.repo_id,
+        call.file_path,
+        call.line_number,
+        call.column_number,
+        call.caller_name,
+        call.callee_name,
+        call.call_kind,
+        call.callee_package,
+        call.callee_qualifier,
+      ]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  // Callees of a name several declarations share (Handle on many receivers) say whose they are
+  const severalCallers = new Set(calls.map((call) => call.caller_name)).size > 1;
+  for (const call of calls) {
+    const location = `${call.file_path}:${String(call.line_number)}:${String(call.column_number)}`;
+    let line = `${theme.path(location)}  `;
+    if (show === 'caller') {
+      line += theme.kind(call.caller_name);
+      if (call.call_kind === 'dynamic') line += `  ${theme.dim(`(possible: ${calleeLabel(call)})`)}`;
+    } else {
+      line += theme.kind(calleeLabel(call));
+      if (severalCallers) line += `  ${theme.dim(`(in ${call.caller_name})`)}`;
+    }
+    print(line);
+  }
+};
+
+/**
+ * Callers command - calls of a Go function or method
+ */
+export const callersCommand: CliCommand = {
+  name: 'callers',
+  description: 'List calls of a Go function or method',
+  usage: 'cindex callers <name> [--exact] [--repo-id <name>]',
+  options: [REPO_ID_OPTION, { name: 'exact', description: 'Leave out method calls on values of unknown type' }],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        exact: { type: 'boolean', default: false },
+      },
+    });
+
+    const [target] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing function name',
+        hint: 'Usage: cindex callers <name>, e.g. cindex callers AuthService.CreateSession',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const calls = await readIndex(repoId, () => listGoCallers(db.getPool(), target, { repoId, exact: values.exact }));
+      if (calls.length === 0) {
+        if (!isPorcelain()) print(`No calls of ${target}`);
+        return ExitCode.NoResults;
+      }
+
+      printCalls(calls, 'caller');
+      if (!isPorcelain()) {
+        const possible = calls.filter((call) => call.call_kind === 'dynamic').length;
+        const callers = new Set(calls.map((call) => `${call.file_path}:${call.caller_name}`)).size;
+        const of = possible > 0 ? ` (${String(possible)} possible)` : '';
+        print();
+        print(`${String(calls.length)} calls${of} from ${String(callers)} functions`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
+
+/**
+ * Callees command - calls made by a Go function or method
+ */
+export const calleesCommand: CliCommand = {
+  name: 'callees',
+  description: 'List calls made by a Go function or method',
+  usage: 'cindex callees <name> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const [caller] = positionals;
+    if (!caller) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing function name',
+        hint: 'Usage: cindex callees <name>, e.g. cindex callees AuthService.Login',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const calls = await readIndex(repoId, () => listGoCallees(db.getPool(), caller, repoId));
+      if (calls.length === 0) {
+        if (!isPorcelain()) print(`No calls made by ${caller}`);
+        return ExitCode.NoResults;
+      }
+
+      printCalls(calls, 'callee');
+      if (!isPorcelain()) {
+        const callees = new Set(calls.map(calleeLabel)).size;
+        print();
+        print(`${String(calls.length)} calls to ${String(callees)} functions`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,295 @@
+/**
+ * CLI command: completion
+ * Generate bash/zsh/fish completion scripts from registered command metadata
+ *
+ * Dynamic values (indexed repository IDs, languages) are resolved at
+ * completion time by calling back into `cindex completion --list <kind>`.
+ */
+import { parseArgs } from 'node:util';
+
+import { print, reportError } from '@cli/output';
+import { openSession } from '@cli/session';
+import { listIndexedRepositories } from '@database/queries';
+import { initLogger } from '@utils/logger';
+import { ExitCode, type CliCommand, type CliCompletion, type CliOption } from '@/types/cli';
+import { Language } from '@/types/indexing';
+
+/** Supported shells */
+const SHELLS = ['bash', 'zsh', 'fish'] as const;
+
+type Shell = (typeof SHELLS)[number];
+
+/** Dynamic completion lists served by --list */
+type ListKind = 'repos' | 'languages' | 'commands';
+
+/**
+ * Shell command that prints a dynamic completion list
+ */
+const listCommand = (kind: ListKind): string => `cindex completion --list ${kind} 2>/dev/null`;
+
+/**
+ * Supported language names (excluding Unknown)
+ */
+const languageNames = (): string[] => Object.values(Language).filter((language) => language !== Language.Unknown);
+
+/**
+ * Quote a string for single-quoted shell contexts
+ */
+const singleQuote = (value: string): string => `'${value.replace(/'/g, `'\\''`)}'`;
+
+/**
+ * Options of a command: its own, then the global ones it does not redefine
+ */
+const commandOptions = (command: CliCommand, globals: CliOption[]): CliOption[] => {
+  const own = command.options ?? [];
+  return [...own, ...globals.filter((option) => !own.some((candidate) => candidate.name === option.name))];
+};
+
+/**
+ * Generate bash completion script
+ */
+const bashScript = (commands: CliCommand[], globals: CliOption[]): string => {
+  const names = [...commands.map((command) => command.name), 'help'].join(' ');
+
+  const valueCases: string[] = [];
+  const commandCases: string[] = [];
+
+  const valueWords = (complete: CliCompletion | undefined): string => {
+    if (complete === 'repo') return `compgen -W "$(${listCommand('repos')})" -- "$cur"`;
+    if (complete === 'language') return `compgen -W "$(${listCommand('languages')})" -- "$cur"`;
+    if (complete === 'dir') return 'compgen -d -- "$cur"';
+    if (complete === 'path' || complete === undefined) return 'compgen -f -- "$cur"';
+    return `compgen -W "${complete.join(' ')}" -- "$cur"`;
+  };
+
+  // Value cases are shared by all commands, so a command's own --format does not override the global one
+  const seen = new Set<string>(globals.map((option) => option.name));
+  for (const command of commands) {
+    for (const option of command.options ?? []) {
+      if (!option.takesValue || seen.has(option.name)) continue;
+      seen.add(option.name);
+      valueCases.push(`    --${option.name}) COMPREPLY=( $(${valueWords(option.complete)}) ); return ;;`);
+    }
+
+    const flags = commandOptions(command, globals).map((option) => `--${option.name}`).join(' ');
+    const positional = command.positional
+      ? `\n      [[ "$cur" != -* ]] && COMPREPLY+=( $(${valueWords(command.positional)}) )`
+      : '';
+    const flagWords = `COMPREPLY=( $(compgen -W "${flags}" -- "$cur") )`;
+    commandCases.push(`    ${command.name})\n      ${flagWords}${positional}\n      ;;`);
+  }
+
+  return `# cindex bash completion
+# Install: cindex completion bash > /etc/bash_completion.d/cindex
+#      or: echo 'source <(cindex completion bash)' >> ~/.bashrc
+_cindex() {
+  local cur prev cmd
+  cur="\${COMP_WORDS[COMP_CWORD]}"
+  prev="\${COMP_WORDS[COMP_CWORD-1]}"
+  cmd="\${COMP_WORDS[1]}"
+
+  if [[ $COMP_CWORD -eq 1 ]]; then
+    COMPREPLY=( $(compgen -W "${names}" -- "$cur") )
+    return
+  fi
+
+  case "$prev" in
+${valueCases.join('\n')}
+  esac
+
+  case "$cmd" in
+${commandCases.join('\n')}
+  esac
+}
+complete -F _cindex cindex
+`;
+};
+
+/**
+ * Generate zsh completion script
+ */
+const zshScript = (commands: CliCommand[], globals: CliOption[]): string => {
+  const zshEscape = (value: string): string => value.replace(/[[\]:']/g, '\\$&');
+
+  const valueAction = (complete: CliCompletion | undefined): string => {
+    if (complete === 'repo') return '_cindex_repos';
+    if (complete === 'language') return '_cindex_languages';
+    if (complete === 'dir') return '_files -/';
+    if (complete === 'path' || complete === undefined) return '_files';
+    return `(${complete.join(' ')})`;
+  };
+
+  const describe = [...commands, { name: 'help', description: 'Show help' }]
+    .map((command) => `    ${singleQuote(`${command.name}:${zshEscape(command.description)}`)}`)
+    .join('\n');
+
+  const commandCases = commands.map((command) => {
+    const specs = commandOptions(command, globals).map((option) => {
+      const value = option.takesValue ? `:${option.name}:${valueAction(option.complete)}` : '';
+      return `        ${singleQuote(`--${option.name}[${zshEscape(option.description)}]${value}`)}`;
+    });
+    if (command.positional) {
+      specs.push(`        ${singleQuote(`*:argument:${valueAction(command.positional)}`)}`);
+    }
+    return `    ${command.name})\n      _arguments \\\n${specs.join(' \\\n')}\n      ;;`;
+  });
+
+  return `#compdef cindex
+# cindex zsh completion
+# Install: cindex completion zsh > "\${fpath[1]}/_cindex"
+#      or: echo 'source <(cindex completion zsh)' >> ~/.zshrc
+_cindex_repos() {
+  local -a repos
+  repos=(\${(f)"$(${listCommand('repos')})"})
+  _describe 'repository' repos
+}
+
+_cindex_languages() {
+  local -a languages
+  languages=(\${(f)"$(${listCommand('languages')})"})
+  _describe 'language' languages
+}
+
+_cindex() {
+  local -a commands
+  commands=(
+${describe}
+  )
+
+  if (( CURRENT == 2 )); then
+    _describe 'command' commands
+    return
+  fi
+
+  case "$words[2]" in
+${commandCases.join('\n')}
+  esac
+}
+
+compdef _cindex cindex
+`;
+};
+
+/**
+ * Generate fish completion script
+ */
+const fishScript = (commands: CliCommand[], globals: CliOption[]): string => {
+  const valueArgs = (complete: CliCompletion | undefined): string => {
+    if (complete === 'repo') return ` -x -a '(${listCommand('repos')})'`;
+    if (complete === 'language') return ` -x -a '(${listCommand('languages')})'`;
+    if (complete === 'dir') return ` -x -a '(__fish_complete_directories)'`;
+    if (complete === 'path' || complete === undefined) return ' -r -F';
+    return ` -x -a ${singleQuote(complete.join(' '))}`;
+  };
+
+  const lines = [
+    '# cindex fish completion',
+    '# Install: cindex completion fish > ~/.config/fish/completions/cindex.fish',
+    'complete -c cindex -f',
+  ];
+
+  for (const command of [...commands, { name: 'help', description: 'Show help' } as CliCommand]) {
+    lines.push(`complete -c cindex -n __fish_use_subcommand -a ${command.name} -d ${singleQuote(command.description)}`);
+  }
+
+  for (const command of commands) {
+    const condition = `-n '__fish_seen_subcommand_from ${command.name}'`;
+    for (const option of commandOptions(command, globals)) {
+      const value = option.takesValue ? valueArgs(option.complete) : '';
+      lines.push(`complete -c cindex ${condition} -l ${option.name} -d ${singleQuote(option.description)}${value}`);
+    }
+    if (command.positional) {
+      lines.push(`complete -c cindex ${condition}${valueArgs(command.positional)}`);
+    }
+  }
+
+  return lines.join('\n') + '\n';
+};
+
+/**
+ * Print a dynamic completion list (one value per line)
+ *
+ * Failures (e.g. database unavailable) print nothing so completion degrades silently.
+ */
+const printList = async (kind: string, commands: CliCommand[]): Promise<ExitCode> => {
+  if (kind === 'languages') {
+    languageNames().forEach((language) => {
+      print(language);
+    });
+    return ExitCode.Success;
+  }
+
+  if (kind === 'commands') {
+    commands.forEach((command) => {
+      print(command.name);
+    });
+    return ExitCode.Success;
+  }
+
+  if (kind === 'repos') {
+    initLogger('ERROR');
+    try {
+      const { db } = await openSession();
+      try {
+        const repos = await listIndexedRepositories(db.getPool());
+        repos.forEach((repo) => {
+          print(repo.repo_id);
+        });
+      } finally {
+        await db.close();
+      }
+    } catch {
+      // Completion must never print errors into the user's shell
+    }
+    return ExitCode.Success;
+  }
+
+  return ExitCode.Usage;
+};
+
+/**
+ * Create the completion command
+ *
+ * Takes the command registry lazily to avoid an import cycle with the dispatcher.
+ *
+ * @param getCommands - Returns all registered commands
+ * @param globals - Global options accepted by every command
+ * @returns Completion command
+ */
+export const createCompletionCommand = (getCommands: () => CliCommand[], globals: CliOption[]): CliCommand => ({
+  name: 'completion',
+  description: 'Print shell completion script (bash, zsh, fish)',
+  usage: `cindex completion <${SHELLS.join('|')}>`,
+  positional: [...SHELLS],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { list: { type: 'string' } },
+    });
+
+    if (values.list) {
+      return printList(values.list, getCommands());
+    }
+
+    const shell = positionals[0] as Shell | undefined;
+    if (!shell || !SHELLS.includes(shell)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Unknown or missing shell '${shell ?? ''}'`,
+        hint: `Usage: cindex completion <${SHELLS.join('|')}>`,
+      });
+    }
+
+    const commands = getCommands();
+    const script =
+      shell === 'bash'
+        ? bashScript(commands, globals)
+        : shell === 'zsh'
+          ? zshScript(commands, globals)
+          : fishScript(commands, globals);
+
+    process.stdout.write(script);
+    return ExitCode.Success;
+  },
+});
@@ -0,0 +1,192 @@
+/**
+ * CLI command: config
+ * Export and import team settings as YAML, and inspect built-in exclusions
+ *
+ *   cindex config export [--output team.yaml]   settings in effect + aliases
+ *   cindex config import team.yaml              merge into ./.cindex.yaml
+ *   cindex config defaults                      default exclusions and overrides
+ *
+ * The database password, the embedding API key, and settings only taken
+ * from the environment (see @cli/project-config) are never exported or imported.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import * as yaml from 'js-yaml';
+
+import { loadAliases } from '@cli/aliases';
+import { isPorcelain, print, printRecord, reportError, toErrorReport } from '@cli/output';
+import {
+  filterSettings,
+  isSharedSetting,
+  parseProjectConfig,
+  readMapping,
+  readProjectConfig,
+  validateSettings,
+} from '@cli/project-config';
+import { getTheme } from '@cli/theme';
+import { isEnvSet, loadConfig } from '@config/env';
+import {
+  DEFAULT_BINARY_EXTENSIONS,
+  DEFAULT_EXCLUDED_DIRECTORIES,
+  DEFAULT_GENERATED_FILE_NAMES,
+  resolveExcludedDirectories,
+} from '@indexing/default-exclusions';
+import { ConfigurationError } from '@utils/errors';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { ENV_PREFIX, ENV_VARS } from '@/types/config';
+
+/** Project config written by import and init */
+export const PROJECT_CONFIG_FILE = '.cindex.yaml';
+
+/**
+ * Settings explicitly set in the environment or project config that a shared file may hold
+ */
+const currentSettings = (): Record<string, string> => {
+  const settings: Record<string, string> = {};
+  for (const key of Object.values(ENV_VARS)) {
+    if (!isSharedSetting(key) || !isEnvSet(key)) continue;
+    settings[key] = process.env[ENV_PREFIX + key] ?? process.env[key] ?? '';
+  }
+  return settings;
+};
+
+/**
+ * Write a YAML document, merging top-level mappings into an existing file
+ *
+ * @param file - Target .cindex.yaml path
+ * @param updates - Top-level keys whose mappings are merged (new values win)
+ */
+export const mergeProjectConfig = (file: string, updates: Record<string, Record<string, unknown>>): void => {
+  const existing = readProjectConfig(file)?.doc ?? {};
+  const merged: Record<string, unknown> = { ...existing };
+
+  for (const [key, mapping] of Object.entries(updates)) {
+    if (Object.keys(mapping).length === 0) continue;
+    const current = existing[key];
+    merged[key] =
+      typeof current === 'object' && current !== null && !Array.isArray(current) ? { ...current, ...mapping } : mapping;
+  }
+
+  fs.writeFileSync(file, yaml.dump(merged, { lineWidth: 120 }));
+};
+
+/**
+ * config export - print (or write) settings in effect and aliases
+ */
+const exportConfig = (output: string | undefined): ExitCode => {
+  const document = { settings: currentSettings(), aliases: loadAliases() };
+  const text = yaml.dump(document, { lineWidth: 120 });
+
+  if (output) {
+    fs.writeFileSync(output, text);
+    print(`Exported ${String(Object.keys(document.settings).length)} settings to ${output}`);
+  } else {
+    process.stdout.write(text);
+  }
+  return ExitCode.Success;
+};
+
+/**
+ * config import - validate a settings file and merge it into ./.cindex.yaml
+ */
+const importConfig = (input: string): ExitCode => {
+  const file = path.resolve(input);
+  try {
+    parseProjectConfig(file);
+  } catch (error) {
+    const { message, ...report } = toErrorReport(error);
+    return reportError(ExitCode.Failure, { file, ...report, message: `Cannot read ${input}: ${message}` });
+  }
+
+  const source = readProjectConfig(file);
+  if (!source) {
+    return reportError(ExitCode.Failure, { code: 'CONFIG_ERROR', message: `${input} is not a YAML mapping`, file });
+  }
+
+  const settings = filterSettings(readMapping(source, 'settings'), source.file);
+  const aliases = readMapping(source, 'aliases');
+
+  try {
+    validateSettings(settings);
+  } catch (error) {
+    if (error instanceof ConfigurationError) {
+      const { message, ...report } = toErrorReport(error);
+      return reportError(ExitCode.Failure, { ...report, message: `Invalid settings in ${input}: ${message}`, file });
+    }
+    throw error;
+  }
+
+  const target = path.resolve(PROJECT_CONFIG_FILE);
+  mergeProjectConfig(target, { settings, aliases });
+
+  const counts = `${String(Object.keys(settings).length)} settings, ${String(Object.keys(aliases).length)} aliases`;
+  print(`Imported ${counts} into ${target}`);
+  return ExitCode.Success;
+};
+
+/**
+ * config defaults - print the built-in exclusions and the directory set in effect
+ *
+ * Porcelain: default<TAB>kind<TAB>value<TAB>status, kind is directory, binary, or
+ * generated; status is excluded, or indexed for defaults removed by EXCLUDE_DIRECTORIES
+ * (directories added by it are listed with status excluded)
+ */
+const printDefaults = (): ExitCode => {
+  const excluded = resolveExcludedDirectories(loadConfig().indexing.exclude_directories);
+  const directories = [...new Set([...DEFAULT_EXCLUDED_DIRECTORIES, ...excluded])];
+  const status = (name: string): string => (excluded.has(name) ? 'excluded' : 'indexed');
+
+  if (isPorcelain()) {
+    for (const name of directories) printRecord('default', ['directory', name, status(name)]);
+    for (const ext of DEFAULT_BINARY_EXTENSIONS) printRecord('default', ['binary', ext, 'excluded']);
+    for (const name of DEFAULT_GENERATED_FILE_NAMES) printRecord('default', ['generated', name, 'excluded']);
+    return ExitCode.Success;
+  }
+
+  const theme = getTheme();
+  const label = (name: string): string => {
+    if (!excluded.has(name)) return theme.dim(`${name} (indexed)`);
+    return DEFAULT_EXCLUDED_DIRECTORIES.has(name) ? name : `${name} (added)`;
+  };
+  print('Excluded directories:');
+  print(`  ${directories.map(label).join('  ')}`);
+  print(theme.dim(`  Change with ${ENV_VARS.EXCLUDE_DIRECTORIES}: a name adds a directory, !name indexes a default`));
+  print();
+  print('Binary extensions:');
+  print(`  ${[...DEFAULT_BINARY_EXTENSIONS].join(' ')}`);
+  print();
+  print('Generated file names:');
+  print(`  ${DEFAULT_GENERATED_FILE_NAMES.join(' ')}`);
+  print(theme.dim(`  Files detected as generated by content follow ${ENV_VARS.GENERATED_FILES} (exclude or tag)`));
+  return ExitCode.Success;
+};
+
+/**
+ * Config command - export/import shared settings, inspect default exclusions
+ */
+export const configCommand: CliCommand = {
+  name: 'config',
+  description: 'Export or import team settings (.cindex.yaml), or list default exclusions',
+  usage: 'cindex config export [--output <file>] | cindex config import <file> | cindex config defaults',
+  options: [{ name: 'output', description: 'Write export to a file instead of stdout', takesValue: true }],
+  positional: ['export', 'import', 'defaults'],
+  run: (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { output: { type: 'string', short: 'o' } },
+    });
+    const [action, file] = positionals;
+
+    if (action === 'export') return Promise.resolve(exportConfig(values.output));
+    if (action === 'import' && file) return Promise.resolve(importConfig(file));
+    if (action === 'defaults') return Promise.resolve(printDefaults());
+
+    const message = action ? `Unknown or incomplete action '${action}'` : 'Missing action';
+    return Promise.resolve(
+      reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message, hint: `Usage: ${configCommand.usage}` })
+    );
+  },
+};
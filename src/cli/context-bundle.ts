@@ -0,0 +1,190 @@
+/**
+ * Context bundles: a symbol and what it takes to understand it, within a token budget (cindex context)
+ *
+ * A bundle holds the symbol's definition and doc comment, then the functions
+ * it calls, the functions that call it, and the types its definition names,
+ * in that order. Each part is rendered as a Markdown section and counted at
+ * the chunker's estimate (4 characters a token); a part that does not fit
+ * the remaining budget is left out and listed, and smaller parts after it
+ * still go in. The definition always goes in, cut to the budget if it alone
+ * exceeds it.
+ */
+import * as path from 'node:path';
+
+import { type SymbolPreview } from '@cli/show';
+import { cleanDocComment } from '@indexing/doc-comments';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
+
+/** Characters counted as one token, as the chunker estimates */
+const CHARS_PER_TOKEN = 4;
+
+/**
+ * Part of a bundle a symbol is in
+ */
+export type ContextSection = 'definition' | 'callee' | 'caller' | 'type';
+
+/** Sections in bundle order, with their headings */
+export const CONTEXT_SECTIONS: [ContextSection, string][] = [
+  ['definition', 'Definition'],
+  ['callee', 'Calls'],
+  ['caller', 'Called by'],
+  ['type', 'Types'],
+];
+
+/**
+ * Symbol in a bundle
+ */
+export interface ContextItem extends SymbolPreview {
+  section: ContextSection;
+  /** Estimated tokens of the item's Markdown */
+  tokens: number;
+  /** Source lines left out to fit the budget (definition only) */
+  truncated?: number;
+}
+
+/**
+ * Declaration related to the bundled symbol, with the part it belongs in
+ */
+export interface RelatedSymbol {
+  section: Exclude<ContextSection, 'definition'>;
+  preview: SymbolPreview;
+}
+
+/**
+ * Token-budgeted bundle
+ */
+export interface ContextBundle {
+  symbol: string;
+  budget: number;
+  /** Estimated tokens of the bundle's Markdown */
+  tokens: number;
+  items: ContextItem[];
+  /** Items left out to fit the budget, source omitted */
+  omitted: Omit<ContextItem, 'source' | 'doc'>[];
+}
+
+/**
+ * Estimate the tokens of text
+ */
+export const estimateTokens = (text: string): number => Math.ceil(text.length / CHARS_PER_TOKEN);
+
+/**
+ * Names a definition may refer to types by, in order of appearance
+ *
+ * Comments and string literals are skipped; whether a name is a type is
+ * left to the index.
+ *
+ * @param source - Definition source
+ * @param exclude - Names to leave out (the symbol's own)
+ * @returns Distinct identifiers
+ */
+export const referencedNames = (source: string, exclude: string[] = []): string[] => {
+  const code = source.replace(/\/\/.*$|#.*$|\/\*[\s\S]*?\*\/|"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'|`[^`]*`/gm, ' ');
+  const names = new Set<string>();
+  for (const [name] of code.matchAll(new RegExp(IDENTIFIER_PATTERN, 'gu'))) {
+    if (!exclude.includes(name)) names.add(name);
+  }
+  return [...names];
+};
+
+/**
+ * Pick the declaration of a name nearest to a file: in it, then in its directory, then the first
+ *
+ * @param previews - Declarations of one name
+ * @param filePath - File the name is used in
+ * @returns Nearest declaration, or null if there is none
+ */
+export const nearestPreview = (previews: SymbolPreview[], filePath: string): SymbolPreview | null => {
+  const directory = path.posix.dirname(filePath);
+  return (
+    previews.find((preview) => preview.file_path === filePath) ??
+    previews.find((preview) => path.posix.dirname(preview.file_path) === directory) ??
+    previews[0] ??
+    null
+  );
+};
+
+/**
+ * Render one item as Markdown: its location, doc comment, and fenced source
+ */
+export const renderItem = (item: Omit<ContextItem, 'tokens'>): string => {
+  const range = `${item.file_path}:${String(item.start_line)}-${String(item.end_line)}`;
+  const lines = [`### ${item.kind} ${item.name}`, '', `\`${range}\``, ''];
+  const doc = item.doc ? cleanDocComment(item.doc) : '';
+  if (doc) lines.push(doc, '');
+  lines.push(`\`\`\`${item.language}`, item.source, '```');
+  if (item.truncated) lines.push('', `(${String(item.truncated)} more lines left out for the budget)`);
+  return lines.join('\n');
+};
+
+/**
+ * Cut a definition's source by lines until it fits a budget
+ */
+const fitDefinition = (preview: SymbolPreview, budget: number): Omit<ContextItem, 'tokens'> => {
+  const lines = preview.source.split('\n');
+  let item: Omit<ContextItem, 'tokens'> = { ...preview, section: 'definition' };
+  for (let keep = lines.length - 1; keep > 0 && estimateTokens(renderItem(item)) > budget; keep--) {
+    const source = lines.slice(0, keep).join('\n');
+    item = { ...preview, section: 'definition', source, truncated: lines.length - keep };
+  }
+  return item;
+};
+
+/**
+ * Assemble a bundle within a budget
+ *
+ * @param symbol - Symbol as asked for
+ * @param definition - The symbol's declaration
+ * @param related - Callees, callers, and types, each in the order to include them
+ * @param budget - Token budget
+ * @returns Bundle with the items that fit, in section order
+ */
+export const packBundle = (
+  symbol: string,
+  definition: SymbolPreview,
+  related: RelatedSymbol[],
+  budget: number
+): ContextBundle => {
+  const fitted = fitDefinition(definition, budget);
+  const items: ContextItem[] = [{ ...fitted, tokens: estimateTokens(renderItem(fitted)) }];
+  const omitted: ContextBundle['omitted'] = [];
+  let used = items[0].tokens;
+
+  const seen = new Set([`${definition.file_path}:${String(definition.start_line)}`]);
+  const order = CONTEXT_SECTIONS.map(([section]) => section);
+  const ordered = [...related].sort((a, b) => order.indexOf(a.section) - order.indexOf(b.section));
+  for (const { section, preview } of ordered) {
+    const key = `${preview.file_path}:${String(preview.start_line)}`;
+    if (seen.has(key)) continue;
+    seen.add(key);
+    const item: ContextItem = { ...preview, section, tokens: estimateTokens(renderItem({ ...preview, section })) };
+    if (used + item.tokens <= budget) {
+      items.push(item);
+      used += item.tokens;
+    } else {
+      const { source: _source, doc: _doc, ...left } = item;
+      omitted.push(left);
+    }
+  }
+  return { symbol, budget, tokens: used, items, omitted };
+};
+
+/**
+ * Render a bundle as Markdown, one section per part
+ */
+export const renderBundle = (bundle: ContextBundle): string => {
+  const parts = [`# ${bundle.symbol}`];
+  for (const [section, heading] of CONTEXT_SECTIONS) {
+    const items = bundle.items.filter((item) => item.section === section);
+    if (items.length === 0) continue;
+    parts.push(`## ${heading}`, ...items.map(renderItem));
+  }
+  if (bundle.omitted.length > 0) {
+    const lines = bundle.omitted.map((item) => {
+      const location = `${item.file_path}:${String(item.start_line)}`;
+      return `- ${item.kind} ${item.name} (\`${location}\`, ${String(item.tokens)} tokens)`;
+    });
+    parts.push('## Left out for the budget', lines.join('\n'));
+  }
+  return parts.join('\n\n') + '\n';
+};
@@ -0,0 +1,183 @@
+/**
+ * CLI command: context
+ * Assemble a symbol's definition, doc comment, callees, callers, and the types it names into a
+ * token-budgeted bundle for a prompt (see @cli/context-bundle)
+ *
+ *   cindex context AuthService.Login                 Markdown, 8000 tokens
+ *   cindex context AuthService.Login --budget 4000 --json
+ *
+ * The symbol is resolved as cindex show resolves it, and the first match is
+ * bundled. Callees and callers come from the Go call graph, calls on values
+ * of unknown type left out; other languages bundle the definition and its
+ * types. Calls into packages outside the index (the standard library) have
+ * no source to include. Like show, the source is the one stored at index time.
+ */
+import { parseArgs } from 'node:util';
+
+import { type Pool } from 'pg';
+
+import { calleeLabel } from '@cli/calls';
+import {
+  nearestPreview,
+  packBundle,
+  referencedNames,
+  renderBundle,
+  type ContextBundle,
+  type RelatedSymbol,
+} from '@cli/context-bundle';
+import { isPorcelain, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { resolvePreviews, splitQualifiedName, type SymbolPreview } from '@cli/show';
+import { findTypeSymbolNames, listGoCallees, listGoCallers } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Default token budget */
+const DEFAULT_BUDGET = 8000;
+
+/** Most callees, callers, or types looked up for one bundle */
+const RELATED_LIMIT = 25;
+
+/**
+ * Find a symbol, the symbols it relates to, and pack them into a bundle
+ *
+ * @param db - Database connection pool
+ * @param input - Symbol as asked for
+ * @param budget - Token budget
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Bundle, or null if the symbol is not indexed
+ */
+const buildBundle = async (db: Pool, input: string, budget: number, repoId?: string): Promise<ContextBundle | null> => {
+  const segments = splitQualifiedName(input);
+  const [definition] = await resolvePreviews(db, segments, repoId);
+  if (!definition) return null;
+  const target = segments.join('.');
+  const index = definition.repo_id ?? repoId;
+  const related: RelatedSymbol[] = [];
+  const declarationOf = async (name: string, filePath: string): Promise<SymbolPreview | null> =>
+    nearestPreview(await resolvePreviews(db, splitQualifiedName(name), index), filePath);
+
+  if (definition.language === 'go') {
+    // Calls made inside this declaration, not others of the same name
+    const callees = (await listGoCallees(db, target, index)).filter(
+      (call) =>
+        call.call_kind !== 'dynamic' &&
+        call.file_path === definition.file_path &&
+        call.caller_line >= definition.start_line &&
+        call.caller_line <= definition.end_line
+    );
+    for (const label of [...new Set(callees.map(calleeLabel))].slice(0, RELATED_LIMIT)) {
+      const preview = await declarationOf(label, definition.file_path);
+      if (preview) related.push({ section: 'callee', preview });
+    }
+
+    const callers = await listGoCallers(db, target, { repoId: index, exact: true });
+    const seen = new Set<string>();
+    for (const call of callers) {
+      const key = `${call.file_path}:${String(call.caller_line)}`;
+      if (seen.has(key) || seen.size >= RELATED_LIMIT) continue;
+      seen.add(key);
+      const previews = await resolvePreviews(db, splitQualifiedName(call.caller_name), index);
+      const preview = previews.find((p) => p.file_path === call.file_path && p.start_line === call.caller_line);
+      const found = preview ?? nearestPreview(previews, call.file_path);
+      if (found) related.push({ section: 'caller', preview: found });
+    }
+  }
+
+  const names = referencedNames(definition.source, [definition.name]);
+  for (const name of (await findTypeSymbolNames(db, names, index)).slice(0, RELATED_LIMIT)) {
+    const previews = await resolvePreviews(db, [name], index);
+    const types = previews.filter((preview) => ['class', 'interface', 'type'].includes(preview.kind));
+    const preview = nearestPreview(types, definition.file_path);
+    if (preview) related.push({ section: 'type', preview });
+  }
+
+  return packBundle(input, definition, related, budget);
+};
+
+/**
+ * Print the items of a bundle
+ *
+ * Porcelain:
+ *   context<TAB>symbol<TAB>budget<TAB>tokens
+ *   context_item<TAB>section<TAB>repo_id<TAB>kind<TAB>name<TAB>file<TAB>start_line<TAB>end_line<TAB>tokens
+ *   context_omitted<TAB>section<TAB>repo_id<TAB>kind<TAB>name<TAB>file<TAB>start_line<TAB>end_line<TAB>tokens
+ */
+const printBundleRecords = (bundle: ContextBundle): void => {
+  printRecord('context', [bundle.symbol, bundle.budget, bundle.tokens]);
+  const records = [
+    ...bundle.items.map((item) => ['context_item', item] as const),
+    ...bundle.omitted.map((item) => ['context_omitted', item] as const),
+  ];
+  for (const [type, item] of records) {
+    const { section, repo_id, kind, name, file_path, start_line, end_line, tokens } = item;
+    printRecord(type, [section, repo_id, kind, name, file_path, start_line, end_line, tokens]);
+  }
+};
+
+/**
+ * Context command - a token-budgeted bundle of a symbol and its neighbours
+ */
+export const contextCommand: CliCommand = {
+  name: 'context',
+  description: "Bundle a symbol's definition, callees, callers, and types for a prompt",
+  usage: 'cindex context <[qualifier.]symbol> [--budget <tokens>] [--json] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'budget', description: `Tokens the bundle may take (default ${String(DEFAULT_BUDGET)})`, takesValue: true },
+    { name: 'json', description: 'Write the bundle as JSON instead of Markdown' },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        budget: { type: 'string' },
+        json: { type: 'boolean', default: false },
+      },
+    });
+
+    const [input] = positionals;
+    if (!input || positionals.length > 1 || splitQualifiedName(input).length === 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: positionals.length > 1 ? 'Expected a single symbol name' : 'Missing symbol name',
+        hint: `Usage: ${contextCommand.usage}`,
+      });
+    }
+    const budget = values.budget !== undefined ? Number(values.budget) : DEFAULT_BUDGET;
+    if (!Number.isInteger(budget) || budget < 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --budget value: ${values.budget ?? ''}`,
+        hint: 'Expected a positive number of tokens',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const bundle = await readIndex(repoId, () => buildBundle(db.getPool(), input, budget, repoId));
+      if (!bundle) {
+        return reportError(ExitCode.NoResults, {
+          code: 'SYMBOL_NOT_FOUND',
+          message: `No symbol '${input}' in the index`,
+          hint: 'Try `cindex search` to find the right name',
+        });
+      }
+
+      if (values.json) {
+        process.stdout.write(JSON.stringify(bundle, null, 2) + '\n');
+      } else if (isPorcelain()) {
+        printBundleRecords(bundle);
+      } else {
+        process.stdout.write(renderBundle(bundle));
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,138 @@
+/**
+ * CLI command: coverage
+ * Import a Go coverage profile and record per-function coverage on indexed symbols
+ *
+ *   go test -coverprofile=cover.out ./...
+ *   cindex coverage cover.out
+ *   cindex search coverage:<50 complexity:>10
+ *
+ * Each import replaces the previous one: Go functions in files the profile
+ * does not mention are cleared. Re-indexing a file clears its functions too,
+ * so import the profile again after indexing.
+ */
+import * as fs from 'node:fs';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoFunctionSpans } from '@database/queries';
+import { createDatabaseWriter } from '@database/writer';
+import { computeFunctionCoverage, parseCoverProfile, type CoverProfile } from '@indexing/go-coverage';
+import { acquireIndexLock, beginGeneration, publishGeneration } from '@indexing/index-lock';
+import { matchStoredPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Unmatched profile files listed before the rest are summarized */
+const UNMATCHED_SHOWN = 5;
+
+/**
+ * Coverage command - attach coverage percentages to Go functions
+ */
+export const coverageCommand: CliCommand = {
+  name: 'coverage',
+  description: 'Import a Go coverage profile (go test -coverprofile) into the index',
+  usage: 'cindex coverage <profile> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' } },
+    });
+
+    const [file] = positionals;
+    if (!file) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing coverage profile',
+        hint: `Usage: ${coverageCommand.usage}`,
+      });
+    }
+
+    let profile: CoverProfile;
+    try {
+      profile = parseCoverProfile(fs.readFileSync(file, 'utf-8'));
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      return reportError(ExitCode.Failure, {
+        code: 'INVALID_PROFILE',
+        message: `Cannot read ${file}: ${message}`,
+        file,
+        hint: 'Write a profile with: go test -coverprofile=cover.out ./...',
+      });
+    }
+
+    const repoId = resolveRepoId(values['repo-id']);
+    const { db } = await openSession();
+    // Writes to one index take its lock; readers see the import as a new generation
+    const lock = repoId ? await acquireIndexLock(repoId) : null;
+    try {
+      if (repoId) beginGeneration(repoId);
+      const spans = await listGoFunctionSpans(db.getPool(), repoId);
+      const indexedFiles = new Set(spans.map((span) => span.file_path));
+
+      const resolved = new Map<string, string | null>();
+      for (const block of profile.blocks) {
+        if (!resolved.has(block.file)) resolved.set(block.file, matchStoredPath(block.file, indexedFiles));
+      }
+      const blocks = profile.blocks.flatMap((block) => {
+        const indexed = resolved.get(block.file);
+        return indexed ? [{ ...block, file: indexed }] : [];
+      });
+      const matchedFiles = new Set([...resolved.values()].filter((indexed) => indexed !== null));
+      const unmatched = [...resolved].filter(([, indexed]) => indexed === null).map(([profileFile]) => profileFile);
+
+      if (matchedFiles.size === 0) {
+        return reportError(ExitCode.Failure, {
+          code: 'NO_MATCHING_FILES',
+          message: `None of the ${String(resolved.size)} files in ${file} are indexed Go files`,
+          file,
+          hint: 'Index the module the profile was taken from, or pass --repo-id for its index',
+        });
+      }
+
+      const coverage = computeFunctionCoverage(blocks, spans);
+      await createDatabaseWriter(db.getPool()).updateSymbolCoverage(
+        coverage.map(({ symbol, percent }) => ({ id: symbol.id, percent }))
+      );
+      const measured = coverage.filter((entry) => entry.percent !== null);
+
+      // Porcelain: coverage<TAB>path<TAB>function<TAB>line<TAB>percent
+      if (isPorcelain()) {
+        for (const { symbol, percent } of measured) {
+          printRecord('coverage', [symbol.file_path, symbol.symbol_name, symbol.line_number, percent?.toFixed(1)]);
+        }
+        return unmatched.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+      }
+
+      const theme = getTheme();
+      const statements = measured.reduce((sum, entry) => sum + entry.statements, 0);
+      const covered = measured.reduce((sum, entry) => sum + entry.covered, 0);
+      const total = statements > 0 ? ((covered / statements) * 100).toFixed(1) : '0.0';
+      print(
+        `Imported coverage of ${String(measured.length)} functions in ${String(matchedFiles.size)} files ` +
+          `(${total}% of statements, mode ${profile.mode})`
+      );
+      if (unmatched.length > 0) {
+        print(theme.dim(`${String(unmatched.length)} files in the profile are not indexed:`));
+        for (const profileFile of unmatched.slice(0, UNMATCHED_SHOWN)) {
+          print(`  ${theme.path(profileFile)}`);
+        }
+        if (unmatched.length > UNMATCHED_SHOWN) {
+          print(theme.dim(`  ... and ${String(unmatched.length - UNMATCHED_SHOWN)} more`));
+        }
+      }
+      print(theme.dim('Find risky untested code with: cindex search coverage:<50 complexity:>10'));
+
+      // Profile files missing from the index leave part of the profile unused
+      return unmatched.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      if (repoId) publishGeneration(repoId);
+      lock?.release();
+      await db.close();
+    }
+  },
+};
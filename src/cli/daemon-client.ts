@@ -0,0 +1,117 @@
+/**
+ * Run a CLI command in cindex daemon, when one is listening
+ *
+ * The command's output is copied to this process's stdout and stderr as it
+ * arrives, and its exit code returned. With no daemon, or one that refuses
+ * the request (another protocol version), the caller runs the command
+ * itself; so does everything with CINDEX_DAEMON=off.
+ */
+import * as fs from 'node:fs';
+import * as net from 'node:net';
+
+import chalk from 'chalk';
+
+import {
+  DAEMON_PROTOCOL_VERSION,
+  DaemonChannel,
+  daemonSocketPath,
+  encodeFrame,
+  FrameDecoder,
+  parseControl,
+  type DaemonReply,
+  type DaemonRequest,
+} from '@cli/daemon-protocol';
+import { reportError } from '@cli/output';
+import { logger } from '@utils/logger';
+import { ExitCode } from '@/types/cli';
+
+/**
+ * Run a command in the daemon
+ *
+ * @param argv - Arguments after the script path, as given to the CLI
+ * @returns Exit code of the command, or null if no daemon ran it
+ */
+export const runInDaemon = async (argv: string[]): Promise<ExitCode | null> => {
+  const socketPath = daemonSocketPath();
+  if (!socketPath || !fs.existsSync(socketPath)) return null;
+
+  const env: Record<string, string> = {};
+  for (const [name, value] of Object.entries(process.env)) {
+    if (value !== undefined) env[name] = value;
+  }
+  const request: DaemonRequest = {
+    type: 'run',
+    version: DAEMON_PROTOCOL_VERSION,
+    argv,
+    cwd: process.cwd(),
+    env,
+    tty: process.stdout.isTTY === true,
+    color_level: chalk.level,
+  };
+
+  return new Promise((resolve) => {
+    const socket = net.createConnection(socketPath);
+    const decoder = new FrameDecoder();
+    let connected = false;
+    let settled = false;
+
+    const finish = (code: ExitCode | null): void => {
+      if (settled) return;
+      settled = true;
+      socket.destroy();
+      resolve(code);
+    };
+    // Once the command may have started, running it again here could repeat its writes
+    const lost = (reason: string): void => {
+      if (settled) return;
+      finish(
+        reportError(ExitCode.Failure, {
+          code: 'DAEMON_ERROR',
+          message: `cindex daemon stopped before the command finished: ${reason}`,
+          hint: 'Run the command again, or set CINDEX_DAEMON=off to bypass the daemon',
+        })
+      );
+    };
+
+    socket.on('connect', () => {
+      connected = true;
+      socket.write(encodeFrame(DaemonChannel.Control, request));
+    });
+    socket.on('data', (chunk) => {
+      try {
+        for (const frame of decoder.push(chunk)) {
+          if (frame.channel === DaemonChannel.Stdout) {
+            process.stdout.write(frame.data);
+          } else if (frame.channel === DaemonChannel.Stderr) {
+            process.stderr.write(frame.data);
+          } else {
+            const reply = parseControl<DaemonReply>(frame.data);
+            if (reply.type === 'exit') {
+              finish(reply.code as ExitCode);
+            } else {
+              logger.debug('cindex daemon refused the command; running it here', { reason: reply.reason });
+              finish(null);
+            }
+          }
+        }
+      } catch (error) {
+        lost(error instanceof Error ? error.message : String(error));
+      }
+    });
+    socket.on('error', (error: NodeJS.ErrnoException) => {
+      // A socket left behind by a daemon that exited refuses connections
+      if (!connected) {
+        logger.debug('No cindex daemon on the socket; running the command here', {
+          socket: socketPath,
+          error: error.code,
+        });
+        finish(null);
+      } else {
+        lost(error.message);
+      }
+    });
+    socket.on('close', () => {
+      lost('connection closed');
+    });
+  });
+};
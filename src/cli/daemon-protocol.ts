@@ -0,0 +1,144 @@
+/**
+ * Wire protocol of cindex daemon
+ *
+ * The CLI and the daemon talk over a Unix domain socket in frames: a 4-byte
+ * big-endian payload length, then the payload. The first payload byte is its
+ * channel:
+ *
+ *   0  control  UTF-8 JSON: the request (client), the exit code or a refusal (daemon)
+ *   1  stdout   bytes the command wrote to stdout
+ *   2  stderr   bytes the command wrote to stderr
+ *
+ * A client sends one request and reads frames until the control frame that
+ * ends the exchange; one connection runs one command.
+ */
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+/** Environment variable naming the daemon socket, or "off" to never use a daemon */
+export const DAEMON_ENV = 'CINDEX_DAEMON';
+
+/** Protocol version; a daemon speaking another refuses the request */
+export const DAEMON_PROTOCOL_VERSION = 1;
+
+/** Largest payload accepted (a frame of stdout is at most one write) */
+export const MAX_FRAME_BYTES = 64 * 1024 * 1024;
+
+/** Channel of a frame, its first payload byte */
+export enum DaemonChannel {
+  Control = 0,
+  Stdout = 1,
+  Stderr = 2,
+}
+
+/**
+ * Command the CLI asks the daemon to run
+ */
+export interface DaemonRequest {
+  type: 'run';
+  version: number;
+  /** Arguments after the script path, as given to the CLI */
+  argv: string[];
+  /** Working directory of the CLI */
+  cwd: string;
+  /** Environment of the CLI; the command sees it in place of the daemon's */
+  env: Record<string, string>;
+  /** Whether the CLI's stdout is a terminal, and chalk's color level there */
+  tty: boolean;
+  color_level: number;
+}
+
+/**
+ * Control frame ending an exchange
+ */
+export type DaemonReply = { type: 'exit'; code: number } | { type: 'refused'; reason: string };
+
+/**
+ * One decoded frame
+ */
+export interface DaemonFrame {
+  channel: DaemonChannel;
+  data: Buffer;
+}
+
+/**
+ * Socket the CLI and daemon use
+ *
+ * @param env - Environment (default: process.env)
+ * @returns Socket path from CINDEX_DAEMON (default ~/.cindex/daemon.sock), or null when it is "off"
+ */
+export const daemonSocketPath = (env: NodeJS.ProcessEnv = process.env): string | null => {
+  const value = env[DAEMON_ENV]?.trim();
+  if (value === 'off') return null;
+  return value ? path.resolve(value) : path.join(os.homedir(), '.cindex', 'daemon.sock');
+};
+
+/**
+ * Encode one frame
+ *
+ * @param channel - Channel of the payload
+ * @param data - Bytes, or a value sent as JSON (control)
+ */
+export const encodeFrame = (channel: DaemonChannel, data: Buffer | DaemonRequest | DaemonReply): Buffer => {
+  const body = Buffer.isBuffer(data) ? data : Buffer.from(JSON.stringify(data), 'utf8');
+  const header = Buffer.alloc(5);
+  header.writeUInt32BE(body.length + 1, 0);
+  header.writeUInt8(channel, 4);
+  return Buffer.concat([header, body]);
+};
+
+/**
+ * Decoder of a stream of frames, fed chunks as they arrive
+ */
+export class FrameDecoder {
+  private buffered = Buffer.alloc(0);
+
+  /**
+   * Add received bytes
+   *
+   * @param chunk - Bytes from the socket
+   * @returns Frames completed by the chunk, in order
+   * @throws {Error} If a frame is empty, too large, or on an unknown channel
+   */
+  public push = (chunk: Buffer): DaemonFrame[] => {
+    this.buffered = this.buffered.length > 0 ? Buffer.concat([this.buffered, chunk]) : chunk;
+    const frames: DaemonFrame[] = [];
+    while (this.buffered.length >= 4) {
+      const length = this.buffered.readUInt32BE(0);
+      if (length === 0 || length > MAX_FRAME_BYTES) throw new Error(`Invalid frame length ${String(length)}`);
+      if (this.buffered.length < 4 + length) break;
+
+      const channel = this.buffered.readUInt8(4);
+      if (!(channel in DaemonChannel)) throw new Error(`Unknown frame channel ${String(channel)}`);
+      frames.push({ channel, data: this.buffered.subarray(5, 4 + length) });
+      this.buffered = this.buffered.subarray(4 + length);
+    }
+    return frames;
+  };
+}
+
+/**
+ * Read a control payload
+ *
+ * @throws {SyntaxError} If the payload is not JSON
+ */
+export const parseControl = <T extends DaemonRequest | DaemonReply>(data: Buffer): T =>
+  JSON.parse(data.toString('utf8')) as T;
+
+/**
+ * Check a request read from a client
+ *
+ * @param value - Parsed control payload
+ * @returns Why the request is refused, or null to run it
+ */
+export const checkRequest = (value: unknown): string | null => {
+  const request = value as Partial<DaemonRequest> | null;
+  if (request?.type !== 'run') return 'expected a run request';
+  if (request.version !== DAEMON_PROTOCOL_VERSION) {
+    return `protocol version ${String(request.version)} (the daemon speaks ${String(DAEMON_PROTOCOL_VERSION)})`;
+  }
+  if (!Array.isArray(request.argv) || typeof request.cwd !== 'string' || typeof request.env !== 'object') {
+    return 'malformed request';
+  }
+  return null;
+};
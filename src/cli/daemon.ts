@@ -0,0 +1,247 @@
+/**
+ * CLI command: daemon
+ * Keep the index open and run other cindex processes' commands over a Unix socket
+ *
+ *   cindex daemon                          listen on $CINDEX_DAEMON or ~/.cindex/daemon.sock
+ *   cindex daemon --socket /tmp/ci.sock    another socket (clients set CINDEX_DAEMON to it)
+ *
+ * While a daemon listens, the CLI sends it each command (see @cli/daemon-client
+ * and @cli/daemon-protocol) instead of connecting to the database itself, so
+ * a query skips connecting and health-checking. The daemon runs the command
+ * as the CLI would, in the CLI's working directory and environment, and
+ * streams its output back. Commands run one at a time. Commands that read
+ * the terminal or stdin, or run until stopped (index, watch, serve, repl),
+ * always run in the CLI's own process.
+ *
+ * The socket is only accessible to the user who started the daemon.
+ */
+import * as fs from 'node:fs';
+import * as net from 'node:net';
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import chalk from 'chalk';
+
+import {
+  checkRequest,
+  DAEMON_ENV,
+  DaemonChannel,
+  daemonSocketPath,
+  encodeFrame,
+  FrameDecoder,
+  parseControl,
+  type DaemonReply,
+  type DaemonRequest,
+} from '@cli/daemon-protocol';
+import { isPorcelain, print, printRecord, reportError, reportUncaughtError } from '@cli/output';
+import { keepSessionsOpen } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { logger } from '@utils/logger';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Whether a daemon is listening on a socket
+ */
+const isListening = (socketPath: string): Promise<boolean> =>
+  new Promise((resolve) => {
+    const probe = net.createConnection(socketPath);
+    probe.once('connect', () => {
+      probe.destroy();
+      resolve(true);
+    });
+    probe.once('error', () => {
+      resolve(false);
+    });
+  });
+
+/**
+ * Replace the variables of process.env in place
+ */
+const replaceEnv = (env: Record<string, string | undefined>): void => {
+  for (const name of Object.keys(process.env)) {
+    if (!(name in env)) delete process.env[name];
+  }
+  Object.assign(process.env, env);
+};
+
+/**
+ * Stream writer sending what is written to the client as frames
+ */
+const captureWrites = (send: (data: Uint8Array) => void): typeof process.stdout.write =>
+  ((chunk: string | Uint8Array, encoding?: BufferEncoding | ((error?: Error) => void), done?: () => void) => {
+    send(typeof chunk === 'string' ? Buffer.from(chunk, typeof encoding === 'string' ? encoding : 'utf8') : chunk);
+    (typeof encoding === 'function' ? encoding : done)?.();
+    return true;
+  }) as typeof process.stdout.write;
+
+/**
+ * Run one client's command in this process, as the client would have
+ *
+ * The working directory, environment, terminal settings, and stdout and
+ * stderr of the process are the client's until the command finishes.
+ *
+ * @param request - Request of the client
+ * @param socket - Connection replies are written to
+ * @param run - CLI entry point
+ */
+const runRequest = async (
+  request: DaemonRequest,
+  socket: net.Socket,
+  run: (argv: string[]) => Promise<ExitCode>
+): Promise<void> => {
+  const send = (channel: DaemonChannel, data: Buffer | DaemonReply): void => {
+    if (!socket.destroyed) socket.write(encodeFrame(channel, data));
+  };
+  const stdout = process.stdout as { isTTY?: boolean };
+  const saved = {
+    cwd: process.cwd(),
+    env: { ...process.env },
+    tty: stdout.isTTY,
+    colorLevel: chalk.level,
+    stdout: process.stdout.write,
+    stderr: process.stderr.write,
+  };
+
+  let code: ExitCode;
+  try {
+    process.stdout.write = captureWrites((data) => send(DaemonChannel.Stdout, Buffer.from(data)));
+    process.stderr.write = captureWrites((data) => send(DaemonChannel.Stderr, Buffer.from(data)));
+    // The command must not hand itself back to the daemon
+    replaceEnv({ ...request.env, [DAEMON_ENV]: 'off' });
+    stdout.isTTY = request.tty;
+    chalk.level = request.color_level as typeof chalk.level;
+    process.chdir(request.cwd);
+    code = await run(request.argv);
+  } catch (error) {
+    code = reportUncaughtError(error);
+  } finally {
+    process.stdout.write = saved.stdout;
+    process.stderr.write = saved.stderr;
+    replaceEnv(saved.env);
+    stdout.isTTY = saved.tty;
+    chalk.level = saved.colorLevel;
+    process.chdir(saved.cwd);
+  }
+
+  send(DaemonChannel.Control, { type: 'exit', code });
+  socket.end();
+};
+
+/**
+ * Read a client's request and queue it
+ *
+ * @param socket - Client connection
+ * @param enqueue - Runs the request after those queued before it
+ */
+const acceptConnection = (socket: net.Socket, enqueue: (request: DaemonRequest, socket: net.Socket) => void): void => {
+  const decoder = new FrameDecoder();
+  let received = false;
+
+  socket.on('data', (chunk) => {
+    if (received) return;
+    try {
+      const [frame] = decoder.push(chunk);
+      if (!frame) return;
+      received = true;
+
+      const request = frame.channel === DaemonChannel.Control ? parseControl<DaemonRequest>(frame.data) : null;
+      const refusal = checkRequest(request);
+      if (refusal !== null || !request) {
+        socket.end(encodeFrame(DaemonChannel.Control, { type: 'refused', reason: refusal ?? 'expected a request' }));
+        return;
+      }
+      enqueue(request, socket);
+    } catch (error) {
+      const reason = error instanceof Error ? error.message : String(error);
+      logger.warn('Dropping a malformed daemon request', { error: reason });
+      socket.destroy();
+    }
+  });
+  // A client that exits early only loses its output
+  socket.on('error', (error) => {
+    logger.debug('Daemon client disconnected', { error: error.message });
+  });
+};
+
+/**
+ * Build the daemon command
+ *
+ * @param run - CLI entry point commands are run with
+ */
+export const createDaemonCommand = (run: (argv: string[]) => Promise<ExitCode>): CliCommand => ({
+  name: 'daemon',
+  description: 'Keep the index open and answer CLI commands over a Unix socket',
+  usage: 'cindex daemon [--socket <path>]',
+  local: true,
+  options: [
+    {
+      name: 'socket',
+      description: 'Socket to listen on (default: $CINDEX_DAEMON, or ~/.cindex/daemon.sock)',
+      takesValue: true,
+      complete: 'path',
+    },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { socket: { type: 'string' } } });
+    const socketPath = values.socket !== undefined ? path.resolve(values.socket) : daemonSocketPath();
+    if (!socketPath) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `${DAEMON_ENV}=off leaves the daemon no socket`,
+        hint: 'Pass --socket <path>, and set CINDEX_DAEMON to it for the CLI',
+      });
+    }
+
+    // A socket nobody listens on was left by a daemon that exited
+    if (await isListening(socketPath)) {
+      return reportError(ExitCode.Failure, {
+        code: 'DAEMON_RUNNING',
+        message: `A cindex daemon is already listening on ${socketPath}`,
+      });
+    }
+    fs.rmSync(socketPath, { force: true });
+    fs.mkdirSync(path.dirname(socketPath), { recursive: true, mode: 0o700 });
+
+    // Commands run one at a time: each takes over the process's directory, environment, and output
+    let queue = Promise.resolve();
+    const server = net.createServer((socket) => {
+      acceptConnection(socket, (request, client) => {
+        queue = queue.then(() => runRequest(request, client, run));
+      });
+    });
+
+    const closeSessions = keepSessionsOpen();
+    const controller = new AbortController();
+    const removeSignalHandlers = handleShutdownSignals(() => {
+      controller.abort();
+    });
+    try {
+      await new Promise<void>((resolve, reject) => {
+        server.once('error', reject);
+        server.listen(socketPath, resolve);
+      });
+      fs.chmodSync(socketPath, 0o600);
+
+      // Porcelain: listening<TAB>socket
+      if (isPorcelain()) printRecord('listening', [socketPath]);
+      else print(`cindex daemon listening on ${getTheme().path(socketPath)} (Ctrl+C to stop)`);
+
+      await new Promise<void>((resolve) => {
+        controller.signal.addEventListener('abort', () => resolve(), { once: true });
+      });
+      await new Promise<void>((resolve) => server.close(() => resolve()));
+      await queue;
+      return ExitCode.Success;
+    } catch (error) {
+      return reportError(ExitCode.Failure, {
+        code: 'LISTEN_ERROR',
+        message: `Cannot listen on ${socketPath}: ${error instanceof Error ? error.message : String(error)}`,
+      });
+    } finally {
+      removeSignalHandlers();
+      fs.rmSync(socketPath, { force: true });
+      await closeSessions();
+    }
+  },
+});
@@ -0,0 +1,255 @@
+/**
+ * CLI command: deadcode
+ * List Go declarations nothing uses
+ *
+ *   cindex deadcode                     declarations with no uses at all
+ *   cindex deadcode --scope package     also those only their own package uses
+ *   cindex deadcode --allow 'Config.*'  never report the fields and methods of Config
+ *
+ * Uses come from `cindex index --typed`, so a declaration called only
+ * through reflection, cgo, or linkname looks unused. Entry points (main,
+ * init, TestMain), declarations in _test.go files (test helpers), methods
+ * of types that satisfy an interface, and struct fields with tags (read by
+ * encoding/json and friends) are allowed by default. Other names are
+ * allowed with --allow or in .cindex.yaml:
+ *
+ *   deadcode:
+ *     allow: [plugin.Register*, '*.MarshalText']
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { findProjectConfigs, readMapping } from '@cli/project-config';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { hasGoReferences, listGoSymbolUsage } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoSymbolUsageRecord } from '@/types/database';
+
+/**
+ * Uses that keep a declaration alive
+ *
+ * - any: every use
+ * - file: uses in other files
+ * - package: uses in other packages (unexported declarations: every use)
+ */
+export type DeadCodeScope = 'any' | 'file' | 'package';
+
+/** Scopes --scope takes */
+export const DEADCODE_SCOPES: DeadCodeScope[] = ['any', 'file', 'package'];
+
+/**
+ * Why an unused declaration is not reported
+ */
+export type DeadCodeAllowance = 'entrypoint' | 'test' | 'interface' | 'reflection' | 'allowlist';
+
+/** Allowances in summary order, with their labels */
+const ALLOWANCE_LABELS: [DeadCodeAllowance, string][] = [
+  ['entrypoint', 'entry points'],
+  ['test', 'test helpers'],
+  ['interface', 'interface methods'],
+  ['reflection', 'tagged fields'],
+  ['allowlist', 'allowlisted'],
+];
+
+/** Functions the Go runtime and test runner call */
+const ENTRYPOINTS = new Set(['main', 'init', 'TestMain']);
+
+/**
+ * Result of checking an index for unused declarations
+ */
+export interface DeadCodeReport {
+  /** Declarations reported, ordered as given */
+  unused: GoSymbolUsageRecord[];
+  /** Unused declarations not reported, by reason */
+  allowed: Map<DeadCodeAllowance, number>;
+}
+
+/**
+ * Count the uses of a declaration that count for a scope
+ */
+export const usesInScope = (symbol: GoSymbolUsageRecord, scope: DeadCodeScope): number => {
+  if (scope === 'file') return symbol.outside_file;
+  // Only the package can use an unexported name
+  if (scope === 'package' && symbol.scope === 'exported') return symbol.outside_package;
+  return symbol.reference_count;
+};
+
+/**
+ * Check whether a declaration matches an allowlist pattern
+ *
+ * Patterns match the symbol name (Config.Timeout) or the name qualified by
+ * its package directory (client.Config.Timeout); * matches any run of
+ * characters, dots included.
+ *
+ * @param symbol - Declaration
+ * @param pattern - Allowlist entry, e.g. Config.*, handlers.Handle*, *.MarshalJSON
+ */
+export const matchesAllowPattern = (symbol: GoSymbolUsageRecord, pattern: string): boolean => {
+  const source = pattern
+    .split('*')
+    .map((part) => part.replace(/[.+?^${}()|[\]\\]/g, '\\$&'))
+    .join('.*');
+  const regex = new RegExp(`^${source}$`, 'u');
+  const dir = symbol.file_path.includes('/') ? symbol.file_path.slice(0, symbol.file_path.lastIndexOf('/')) : '';
+  const packageName = dir.slice(dir.lastIndexOf('/') + 1);
+  return regex.test(symbol.symbol_name) || (packageName !== '' && regex.test(`${packageName}.${symbol.symbol_name}`));
+};
+
+/**
+ * Find why an unused declaration is expected to be unused
+ *
+ * @param symbol - Declaration
+ * @param allow - Allowlist patterns (see matchesAllowPattern)
+ * @returns The first reason that applies, or null if it should be reported
+ */
+export const findAllowance = (symbol: GoSymbolUsageRecord, allow: string[]): DeadCodeAllowance | null => {
+  if (symbol.symbol_type === 'function' && ENTRYPOINTS.has(symbol.symbol_name)) return 'entrypoint';
+  if (symbol.file_path.endsWith('_test.go')) return 'test';
+  if (symbol.implements_interface) return 'interface';
+  if (symbol.symbol_type === 'field' && symbol.definition?.includes('`')) return 'reflection';
+  if (allow.some((pattern) => matchesAllowPattern(symbol, pattern))) return 'allowlist';
+  return null;
+};
+
+/**
+ * Select the declarations with no uses in a scope
+ *
+ * @param symbols - Declarations with their use counts
+ * @param options.scope - Uses that keep a declaration alive
+ * @param options.allow - Allowlist patterns
+ * @returns Unused declarations, and the counts of those allowed
+ */
+export const findDeadCode = (
+  symbols: GoSymbolUsageRecord[],
+  options: { scope: DeadCodeScope; allow: string[] }
+): DeadCodeReport => {
+  const report: DeadCodeReport = { unused: [], allowed: new Map() };
+  for (const symbol of symbols) {
+    if (usesInScope(symbol, options.scope) > 0) continue;
+    const allowance = findAllowance(symbol, options.allow);
+    if (allowance) {
+      report.allowed.set(allowance, (report.allowed.get(allowance) ?? 0) + 1);
+    } else {
+      report.unused.push(symbol);
+    }
+  }
+  return report;
+};
+
+/**
+ * Load the deadcode allowlist of the project config files (every file's entries)
+ *
+ * @param cwd - Directory to start from
+ * @returns Allowlist patterns
+ */
+export const loadDeadCodeAllowlist = (cwd: string = process.cwd()): string[] => {
+  return findProjectConfigs(cwd).flatMap((config) => {
+    const allow = readMapping(config, 'deadcode').allow;
+    return allow ? allow.split(',').map((pattern) => pattern.trim()) : [];
+  });
+};
+
+/**
+ * Deadcode command - declarations without type-checked uses
+ */
+export const deadcodeCommand: CliCommand = {
+  name: 'deadcode',
+  description: 'List Go declarations with no type-checked uses (needs cindex index --typed)',
+  usage: 'cindex deadcode [--scope any|file|package] [--allow <pattern>]... [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'scope',
+      description: 'Ignore uses inside the defining file or package (default: any)',
+      takesValue: true,
+      complete: [...DEADCODE_SCOPES],
+    },
+    { name: 'allow', description: 'Never report names matching a pattern (repeatable; * wildcard)', takesValue: true },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        'repo-id': { type: 'string' },
+        scope: { type: 'string', default: 'any' },
+        allow: { type: 'string', multiple: true, default: [] },
+      },
+    });
+
+    const scope = values.scope as DeadCodeScope;
+    if (!DEADCODE_SCOPES.includes(scope)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --scope value '${values.scope}'`,
+        hint: `Expected one of ${DEADCODE_SCOPES.join(', ')}`,
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+    const allow = [...loadDeadCodeAllowlist(), ...values.allow];
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const { symbols, typed } = await readIndex(repoId, async () => ({
+        symbols: await listGoSymbolUsage(pool, repoId),
+        typed: await hasGoReferences(pool, repoId),
+      }));
+      if (!typed) {
+        return reportError(ExitCode.Failure, {
+          code: 'NO_TYPE_FACTS',
+          message: `No type facts recorded for '${repoId}'`,
+          hint: 'Uses are resolved by typed indexing: cindex index <path> --typed',
+        });
+      }
+
+      const { unused, allowed } = findDeadCode(symbols, { scope, allow });
+
+      // Porcelain: deadcode<TAB>repo_id<TAB>path<TAB>line<TAB>kind<TAB>name<TAB>scope<TAB>references
+      if (isPorcelain()) {
+        for (const symbol of unused) {
+          printRecord('deadcode', [
+            symbol.repo_id,
+            symbol.file_path,
+            symbol.line_number,
+            symbol.symbol_type,
+            symbol.symbol_name,
+            symbol.scope,
+            symbol.reference_count,
+          ]);
+        }
+      } else {
+        const theme = getTheme();
+        for (const symbol of unused) {
+          const location = `${symbol.file_path}:${String(symbol.line_number)}`;
+          const uses = symbol.reference_count;
+          const local = uses > 0 ? `  ${theme.dim(`(${String(uses)} local uses)`)}` : '';
+          print(`${theme.path(location)}  ${symbol.symbol_type}  ${theme.kind(symbol.symbol_name)}${local}`);
+        }
+        if (unused.length > 0) print();
+        const files = new Set(unused.map((symbol) => symbol.file_path)).size;
+        const reasons = ALLOWANCE_LABELS.filter(([allowance]) => allowed.has(allowance)).map(
+          ([allowance, label]) => `${String(allowed.get(allowance))} ${label}`
+        );
+        const skipped = reasons.length > 0 ? theme.dim(` (not reported: ${reasons.join(', ')})`) : '';
+        print(
+          unused.length > 0
+            ? `${String(unused.length)} unused declarations in ${String(files)} files${skipped}`
+            : `No unused declarations${skipped}`
+        );
+      }
+      return unused.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,198 @@
+/**
+ * CLI command: def
+ * Resolve the Go declaration an identifier refers to
+ *
+ *   cindex def internal/auth/login.go:42:17
+ *   cindex def /work/shop/internal/auth/login.go:42:17 --porcelain
+ *
+ * The position is a 1-based line and byte column (what go vet and gopls
+ * print), in a path relative to the repository root or absolute. Files
+ * unchanged since `cindex index --typed` are answered from the index; edited
+ * and new files are resolved by gopls (see @retrieval/gopls).
+ */
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { GOPLS_OPTIONS } from '@cli/refs';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import {
+  findGoReferenceAt,
+  hasGoReferences,
+  listIndexedFileVersions,
+  listIndexedRepositories,
+} from '@database/queries';
+import {
+  findEditedFiles,
+  GOPLS_DEFAULT_REMOTE,
+  goplsDefinition,
+  identifierAt,
+  type ResolutionSource,
+} from '@retrieval/gopls';
+import { readSourceFile } from '@utils/edge-cases';
+import { toPosixPath, toStoredPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Resolved declaration
+ */
+interface Definition {
+  file_path: string;
+  line: number;
+  /** Byte column (gopls only: the index records declaration lines) */
+  column: number | null;
+  name: string | null;
+  /** Import path (index only) */
+  package: string | null;
+  /** Declaration as gopls describes it (gopls only) */
+  description: string | null;
+  source: ResolutionSource;
+}
+
+/**
+ * Split a path:line:column argument
+ *
+ * @returns Path, 1-based line, and 1-based column, or null if the argument has no line and column
+ */
+export const parsePosition = (value: string): { file: string; line: number; column: number } | null => {
+  const match = /^(.+):(\d+):(\d+)$/.exec(value);
+  if (!match || Number(match[2]) < 1 || Number(match[3]) < 1) return null;
+  return { file: match[1], line: Number(match[2]), column: Number(match[3]) };
+};
+
+/**
+ * Def command - declaration of the identifier at a position
+ */
+export const defCommand: CliCommand = {
+  name: 'def',
+  description: 'Resolve the Go declaration an identifier at path:line:column refers to',
+  usage: 'cindex def <path>:<line>:<column> [--gopls <remote> | --no-gopls] [--repo-id <name>]',
+  options: [REPO_ID_OPTION, ...GOPLS_OPTIONS],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        gopls: { type: 'string', default: GOPLS_DEFAULT_REMOTE },
+        'no-gopls': { type: 'boolean', default: false },
+      },
+    });
+
+    const position = parsePosition(positionals[0] ?? '');
+    if (!position) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: positionals[0] ? `Invalid position: ${positionals[0]}` : 'Missing position',
+        hint: 'Usage: cindex def <path>:<line>:<column>, e.g. cindex def internal/auth/login.go:42:17',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repo = (await listIndexedRepositories(pool)).find((candidate) => candidate.repo_id === repoId);
+      if (!repo?.repo_path) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+      const repoPath = repo.repo_path;
+      const filePath = path.isAbsolute(position.file)
+        ? toPosixPath(path.relative(repoPath, position.file))
+        : toStoredPath(position.file);
+      const location = { file_path: filePath, line: position.line, column: position.column };
+
+      const { reference, typed, versions } = await readIndex(repoId, async () => ({
+        reference: await findGoReferenceAt(pool, repoId, filePath, position.line, position.column),
+        typed: await hasGoReferences(pool, repoId),
+        versions: (await listIndexedFileVersions(pool, repoId, '.go')).filter(
+          (version) => version.file_path === filePath
+        ),
+      }));
+
+      // Edited and new files have moved on from the index: ask gopls
+      const current = versions.length > 0 && (await findEditedFiles(repoPath, versions)).size === 0;
+      let definition: Definition | null = null;
+      if (!current && !values['no-gopls']) {
+        let resolved: Awaited<ReturnType<typeof goplsDefinition>>;
+        try {
+          resolved = await goplsDefinition(repoPath, location, values.gopls);
+        } catch (error) {
+          return reportError(ExitCode.Failure, {
+            code: 'GOPLS_FAILED',
+            message: error instanceof Error ? error.message : String(error),
+            file: filePath,
+            hint: `${filePath} changed since indexing; pass --no-gopls to answer from the index as of the last run`,
+          });
+        }
+        if (resolved) {
+          const lines = (await readSourceFile(path.join(repoPath, filePath))).content.split(/\r?\n/);
+          definition = {
+            file_path: resolved.file_path,
+            line: resolved.line,
+            column: resolved.column,
+            name: identifierAt(lines.at(position.line - 1) ?? '', position.column),
+            package: null,
+            description: resolved.description,
+            source: 'gopls',
+          };
+        }
+      } else if (reference) {
+        definition = {
+          file_path: reference.target_file,
+          line: reference.target_line,
+          column: null,
+          name: reference.target_name,
+          package: reference.target_package,
+          description: null,
+          source: 'index',
+        };
+      }
+
+      // Porcelain: definition<TAB>path<TAB>line<TAB>column<TAB>name<TAB>package<TAB>source
+      if (isPorcelain()) {
+        if (definition) {
+          printRecord('definition', [
+            definition.file_path,
+            definition.line,
+            definition.column,
+            definition.name,
+            definition.package,
+            definition.source,
+          ]);
+        }
+      } else if (!definition) {
+        const at = `${filePath}:${String(position.line)}:${String(position.column)}`;
+        print(
+          typed || !current
+            ? `No declaration in the repository for the identifier at ${at}`
+            : 'No type facts recorded (index a Go repository with: cindex index <path> --typed)'
+        );
+      } else {
+        const theme = getTheme();
+        const column = definition.column !== null ? `:${String(definition.column)}` : '';
+        const qualified = [definition.package, definition.name].filter(Boolean).join('.');
+        print(`${theme.path(`${definition.file_path}:${String(definition.line)}${column}`)}  ${theme.kind(qualified)}`);
+        if (definition.description) print(theme.dim(definition.description));
+        if (definition.source === 'gopls') print(theme.dim(`(${filePath} changed since indexing: resolved by gopls)`));
+      }
+      return definition ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
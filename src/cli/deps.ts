@@ -0,0 +1,228 @@
+/**
+ * CLI command: deps
+ * Index Go module dependencies from the module cache
+ *
+ *   cindex deps                        modules of the selected index and their state
+ *   cindex deps golang.org/x/...       index matching modules and link them
+ *   cindex deps all --direct           every module the go.mod requires directly
+ *
+ * Each module version is indexed once, as the index module@version, and
+ * linked to every index that requires it (see @indexing/go-modules).
+ * Module indexes are left out of searches unless asked for:
+ * `cindex search <terms> --deps` adds the modules linked to the selected
+ * index, and `--repo-id module@version` searches one.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedRepositories, listLinkedModules } from '@database/queries';
+import { DatabaseWriter } from '@database/writer';
+import {
+  goModuleCache,
+  listGoModules,
+  moduleCacheSubdirectory,
+  moduleIndexId,
+  moduleUnavailable,
+  selectModules,
+  type GoModule,
+} from '@indexing/go-modules';
+import { createPipeline } from '@indexing/pipeline';
+import { ollamaEmbeddingModel } from '@utils/embedders';
+import { createOllamaClient } from '@utils/ollama';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { IndexingStage, type IndexingOptions } from '@/types/indexing';
+
+/**
+ * Outcome of one module
+ */
+type DependencyResult = 'indexed' | 'shared' | 'failed' | 'local' | 'not-downloaded';
+
+/**
+ * Deps command - list, index, and link the Go modules of an index
+ */
+export const depsCommand: CliCommand = {
+  name: 'deps',
+  description: 'Index Go module dependencies from the module cache, shared across indexes',
+  usage: 'cindex deps [<module-pattern> ...] [--direct] [--repo-id <name>]',
+  options: [REPO_ID_OPTION, { name: 'direct', description: 'Only modules the go.mod requires directly' }],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        direct: { type: 'boolean', default: false },
+      },
+    });
+
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+
+    const { config, db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repositories = await listIndexedRepositories(pool);
+      const repo = repositories.find((candidate) => candidate.repo_id === repoId);
+      if (!repo?.repo_path) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+
+      let modules: GoModule[];
+      let moduleCache: string;
+      try {
+        modules = await listGoModules(repo.repo_path);
+        moduleCache = await goModuleCache();
+      } catch (error) {
+        return reportError(ExitCode.Failure, {
+          code: 'GO_LIST_FAILED',
+          message: error instanceof Error ? error.message : String(error),
+          hint: 'Dependencies are read from the go.mod at the root of the indexed repository',
+        });
+      }
+      const indexed = new Set(
+        repositories.filter((candidate) => Number(candidate.file_count) > 0).map((candidate) => candidate.repo_id)
+      );
+      const linked = new Set(await listLinkedModules(pool, repoId));
+      const theme = getTheme();
+
+      // Without patterns, list the dependencies and what is indexed
+      // Porcelain: module<TAB>path<TAB>version<TAB>state<TAB>index
+      //            (state: linked, indexed, available, local, not-downloaded)
+      if (positionals.length === 0) {
+        const { selected } = selectModules(modules, ['all'], values.direct);
+        for (const module of selected) {
+          const id = moduleIndexId(module);
+          const unavailable = moduleUnavailable(module);
+          let state: string = unavailable ?? 'available';
+          if (linked.has(id)) state = 'linked';
+          else if (indexed.has(id)) state = 'indexed';
+
+          if (isPorcelain()) {
+            printRecord('module', [module.path, module.version, state, unavailable ? null : id]);
+          } else {
+            const indirect = module.indirect ? theme.dim(' // indirect') : '';
+            const shown = state === 'linked' || state === 'indexed' ? theme.kind(state) : theme.dim(state);
+            print(`${theme.path(module.path)} ${module.version || '-'}  ${shown}${indirect}`);
+          }
+        }
+        if (!isPorcelain()) {
+          print();
+          print(`${String(selected.length)} modules, ${String(linked.size)} linked to ${repoId}`);
+        }
+        return selected.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      const { selected, unmatched } = selectModules(modules, positionals, values.direct);
+      if (selected.length === 0) {
+        return reportError(ExitCode.NoResults, {
+          code: 'NO_MATCHING_MODULES',
+          message: `No dependency of ${repoId} matches ${unmatched.join(', ')}`,
+          hint: "Run 'cindex deps' to list the modules of the index",
+        });
+      }
+
+      const defaults = config.indexing;
+      const ollama = createOllamaClient(config.ollama);
+      const writer = new DatabaseWriter(pool);
+      let healthChecked = false;
+
+      // Porcelain: dep<TAB>path<TAB>version<TAB>result<TAB>index<TAB>files<TAB>error
+      //            unmatched<TAB>pattern
+      const results: DependencyResult[] = [];
+      for (const module of selected) {
+        const id = moduleIndexId(module);
+        const subdirectory = moduleCacheSubdirectory(module, moduleCache);
+        const unavailable = moduleUnavailable(module) ?? (subdirectory ? null : 'not-downloaded');
+        let result: DependencyResult = unavailable ?? 'shared';
+        let files: number | null = null;
+        let failure: string | null = null;
+
+        if (!unavailable && subdirectory && !indexed.has(id)) {
+          if (!healthChecked) {
+            await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
+            healthChecked = true;
+          }
+          const options: IndexingOptions = {
+            repoId: id,
+            repoName: module.source_path,
+            repoType: 'reference',
+            version: module.version,
+            metadata: { go_module: module.source_path, version: module.version, exclude_from_default_search: true },
+            subdirectory,
+            symlinkPolicy: defaults.symlink_policy,
+            generatedFiles: defaults.generated_files,
+            excludeDirectories: defaults.exclude_directories,
+            maxDirectoryDepth: defaults.max_directory_depth,
+            maxPathLength: defaults.max_path_length,
+          };
+          const pipeline = createPipeline(config, db, ollama, moduleCache, options);
+          const stats = await pipeline.indexRepository(moduleCache, options);
+          files = stats.files_processed;
+          if (stats.stage === IndexingStage.Failed) {
+            result = 'failed';
+            failure = stats.errors.at(-1)?.error ?? 'indexing failed';
+          } else {
+            result = 'indexed';
+            indexed.add(id);
+          }
+        }
+
+        if (result === 'indexed' || result === 'shared') {
+          await writer.insertCrossRepoDependencies([
+            {
+              source_repo_id: repoId,
+              target_repo_id: id,
+              dependency_type: 'library',
+              source_service_id: null,
+              target_service_id: null,
+              api_contracts: null,
+              metadata: { go_module: module.source_path, version: module.version },
+            },
+          ]);
+        }
+        results.push(result);
+
+        if (isPorcelain()) {
+          printRecord('dep', [module.path, module.version, result, unavailable ? null : id, files, failure]);
+        } else {
+          const detail: Record<DependencyResult, string> = {
+            indexed: `indexed ${String(files ?? 0)} files`,
+            shared: 'already indexed, linked',
+            failed: `failed: ${failure ?? ''}`,
+            local: 'replaced by a local directory (index it with cindex index)',
+            'not-downloaded': 'not in the module cache (run go mod download)',
+          };
+          const shown = result === 'indexed' || result === 'shared' ? detail[result] : theme.match(detail[result]);
+          print(`${theme.path(module.path)} ${module.version || '-'}  ${shown}`);
+        }
+      }
+
+      for (const pattern of unmatched) {
+        if (isPorcelain()) printRecord('unmatched', [pattern]);
+        else print(theme.dim(`No dependency matches ${pattern}`));
+      }
+      const ok = results.filter((result) => result === 'indexed' || result === 'shared').length;
+      if (!isPorcelain()) {
+        print();
+        print(`${String(ok)} of ${String(results.length)} modules linked to ${repoId}; search them with --deps`);
+      }
+      return ok === results.length && unmatched.length === 0 ? ExitCode.Success : ExitCode.PartialFailure;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,200 @@
+/**
+ * CLI command: diff-symbols
+ * Symbols added, removed, and changed between two revisions
+ *
+ *   cindex index . --rev v1.4.0 && cindex index . --rev v1.5.0
+ *   cindex diff-symbols v1.4.0 v1.5.0
+ *
+ * Both revisions must be indexed with `cindex index --rev` (see
+ * @indexing/revisions). Symbols are matched by file, kind, and name; a
+ * symbol whose source lines differ is changed, so edits to a body count and
+ * a symbol that only moved within its file does not. A file that was renamed
+ * shows as its symbols removed and added.
+ */
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedRepositories, listSymbolFingerprints } from '@database/queries';
+import { resolveRevision, revisionIndexId, type ResolvedRevision } from '@indexing/revisions';
+import { compareStrings } from '@utils/ordering';
+import { normalizeRootPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type SymbolFingerprintRecord } from '@/types/database';
+
+/**
+ * Difference of one symbol between two revisions
+ */
+export interface SymbolChange {
+  status: 'removed' | 'changed' | 'added';
+  /** Symbol in the newer revision (in the older one, for removed symbols) */
+  symbol: SymbolFingerprintRecord;
+}
+
+/** Marker per change status in the text listing */
+const CHANGE_MARKERS: Record<SymbolChange['status'], string> = { removed: '-', changed: '~', added: '+' };
+
+/**
+ * Diff the symbols of two revisions
+ *
+ * Symbols are matched by file, kind, and name. Symbols sharing all three
+ * (overloads, repeated init functions) are paired by source first; when one
+ * is left on each side it is a change, otherwise the rest are removed and added.
+ *
+ * @param previous - Symbols of the older revision, paths relative to the repository
+ * @param current - Symbols of the newer revision, paths relative to the repository
+ * @returns Changes ordered by file and line
+ */
+export const diffSymbols = (
+  previous: SymbolFingerprintRecord[],
+  current: SymbolFingerprintRecord[]
+): SymbolChange[] => {
+  const key = (symbol: SymbolFingerprintRecord): string =>
+    `${symbol.file_path}\0${symbol.symbol_type}\0${symbol.symbol_name}`;
+  const group = (symbols: SymbolFingerprintRecord[]): Map<string, SymbolFingerprintRecord[]> => {
+    const groups = new Map<string, SymbolFingerprintRecord[]>();
+    for (const symbol of symbols) groups.set(key(symbol), [...(groups.get(key(symbol)) ?? []), symbol]);
+    return groups;
+  };
+  const before = group(previous);
+  const after = group(current);
+
+  const changes: SymbolChange[] = [];
+  for (const name of new Set([...before.keys(), ...after.keys()])) {
+    const removed = [...(before.get(name) ?? [])];
+    const added: SymbolFingerprintRecord[] = [];
+    for (const symbol of after.get(name) ?? []) {
+      const same = removed.findIndex((candidate) => candidate.source_hash === symbol.source_hash);
+      if (same === -1) added.push(symbol);
+      else removed.splice(same, 1);
+    }
+
+    if (removed.length === 1 && added.length === 1) {
+      changes.push({ status: 'changed', symbol: added[0] });
+      continue;
+    }
+    changes.push(...removed.map((symbol) => ({ status: 'removed' as const, symbol })));
+    changes.push(...added.map((symbol) => ({ status: 'added' as const, symbol })));
+  }
+
+  return changes.sort(
+    (a, b) =>
+      compareStrings(a.symbol.file_path, b.symbol.file_path) ||
+      a.symbol.line_number - b.symbol.line_number ||
+      compareStrings(a.status, b.status)
+  );
+};
+
+/**
+ * Drop the <repo>@<commit>/ prefix of a revision index from symbol paths
+ */
+const relativeToRevision = (symbols: SymbolFingerprintRecord[], repoId: string): SymbolFingerprintRecord[] => {
+  const prefix = `${repoId}/`;
+  return symbols.map((symbol) => ({
+    ...symbol,
+    file_path: symbol.file_path.startsWith(prefix) ? symbol.file_path.slice(prefix.length) : symbol.file_path,
+  }));
+};
+
+/**
+ * Print symbol changes
+ *
+ * Porcelain: symbol_change<TAB>status<TAB>kind<TAB>name<TAB>file<TAB>line
+ *            (line in the newer revision; in the older one for removed symbols)
+ */
+const printChanges = (changes: SymbolChange[], from: ResolvedRevision, to: ResolvedRevision): void => {
+  if (isPorcelain()) {
+    for (const { status, symbol } of changes) {
+      const { symbol_type, symbol_name, file_path, line_number } = symbol;
+      printRecord('symbol_change', [status, symbol_type, symbol_name, file_path, line_number]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const { status, symbol } of changes) {
+    const location = `${theme.path(symbol.file_path)}:${theme.line(String(symbol.line_number))}`;
+    print(`${CHANGE_MARKERS[status]} ${theme.kind(symbol.symbol_type.padEnd(9))} ${symbol.symbol_name}  ${location}`);
+  }
+
+  const count = (status: SymbolChange['status']): string =>
+    String(changes.filter((change) => change.status === status).length);
+  if (changes.length > 0) print();
+  print(
+    `${count('added')} added, ${count('removed')} removed, ${count('changed')} changed ` +
+      `from ${from.ref} to ${to.ref}`
+  );
+};
+
+/**
+ * Diff-symbols command - symbol changes between two indexed revisions
+ */
+export const diffSymbolsCommand: CliCommand = {
+  name: 'diff-symbols',
+  description: 'List symbols added, removed, and changed between two indexed revisions',
+  usage: 'cindex diff-symbols <rev1> <rev2> [--path <repo>] [--repo-id <id>]',
+  options: [
+    { name: 'path', description: 'Repository the revisions belong to (default: current directory)', takesValue: true },
+    {
+      name: 'repo-id',
+      description: 'Index ID the revisions were indexed under (default: directory name)',
+      takesValue: true,
+      complete: 'repo',
+    },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { path: { type: 'string' }, 'repo-id': { type: 'string' } },
+    });
+
+    if (positionals.length !== 2) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Expected two revisions',
+        hint: 'e.g. cindex diff-symbols v1.4.0 v1.5.0',
+      });
+    }
+
+    const repoPath = normalizeRootPath(values.path ?? '.');
+    const sourceId = values['repo-id'] ?? path.basename(repoPath);
+    let revisions: ResolvedRevision[];
+    try {
+      revisions = await Promise.all(positionals.map((ref) => resolveRevision(repoPath, ref)));
+    } catch (error) {
+      return reportError(ExitCode.Failure, {
+        code: 'INVALID_REVISION',
+        message: error instanceof Error ? error.message : String(error),
+        hint: 'Pass tags, branches, or commits of the repository (--path)',
+      });
+    }
+    const [from, to] = revisions;
+    const ids = revisions.map((revision) => revisionIndexId(sourceId, revision.commit));
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const indexed = new Set((await listIndexedRepositories(pool)).map((repo) => repo.repo_id));
+      const missing = revisions.filter((_, index) => !indexed.has(ids[index]));
+      if (missing.length > 0) {
+        const commands = missing.map((revision) => `cindex index ${repoPath} --rev ${revision.ref}`);
+        return reportError(ExitCode.Failure, {
+          code: 'REVISION_NOT_INDEXED',
+          message: `Revision not indexed: ${missing.map((revision) => revision.ref).join(', ')}`,
+          hint: `Index it first: ${commands.join(' && ')}`,
+        });
+      }
+
+      const [previous, current] = await Promise.all(
+        ids.map(async (id) => relativeToRevision(await readIndex(id, () => listSymbolFingerprints(pool, id)), id))
+      );
+      printChanges(diffSymbols(previous, current), from, to);
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,179 @@
+/**
+ * CLI command: doc
+ * Print a symbol's signature and documentation, or search documentation
+ *
+ *   cindex doc AuthService.Login
+ *   cindex doc --search "session expiration"
+ *
+ * Doc comments are recorded at index time with their comment markers
+ * stripped (see @indexing/doc-comments), and searched together with the
+ * words of each symbol's name, stemmed: "session expiration" finds
+ * SessionTimeout when its comment says sessions expire. Searches read the
+ * configured store (STORE_BACKEND, see @database/store).
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { resolvePreviews, splitQualifiedName, type SymbolPreview } from '@cli/show';
+import { highlightCode } from '@cli/syntax';
+import { getTheme } from '@cli/theme';
+import { openStore } from '@database/store';
+import { cleanDocComment, docSummary } from '@indexing/doc-comments';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type DocMatchRecord } from '@/types/database';
+
+/** Maximum symbols documented by one lookup */
+const DOC_LIMIT = 5;
+
+/** Lines a wrapped signature may span */
+const SIGNATURE_LINES = 8;
+
+/**
+ * Declaration line(s) of a symbol's source, without its body
+ *
+ * The signature ends at the first line that closes every parenthesis and
+ * bracket opened before it (wrapped parameter lists); a trailing { is dropped.
+ *
+ * @param source - Source starting at the declaration
+ * @returns Signature text
+ */
+export const declarationSignature = (source: string): string => {
+  const lines = source.split('\n').slice(0, SIGNATURE_LINES);
+  let depth = 0;
+  for (let i = 0; i < lines.length; i++) {
+    for (const char of lines[i]) {
+      if (char === '(' || char === '[') depth++;
+      else if (char === ')' || char === ']') depth--;
+    }
+    if (depth <= 0) {
+      return [...lines.slice(0, i), lines[i].replace(/\s*\{\s*$/, '')].join('\n').trimEnd();
+    }
+  }
+  return lines.join('\n').trimEnd();
+};
+
+/**
+ * Print one symbol's signature and doc comment
+ *
+ * Porcelain: doc_symbol<TAB>repo_id<TAB>kind<TAB>name<TAB>file<TAB>line, then signature<TAB>text and doc<TAB>text
+ * per line
+ */
+const printDoc = (preview: SymbolPreview): void => {
+  const signature = declarationSignature(preview.source).split('\n');
+  const doc = preview.doc ? cleanDocComment(preview.doc) : '';
+
+  if (isPorcelain()) {
+    const { repo_id, kind, name, file_path, start_line } = preview;
+    printRecord('doc_symbol', [repo_id, kind, name, file_path, start_line]);
+    for (const text of signature) printRecord('signature', [text]);
+    for (const text of doc ? doc.split('\n') : []) printRecord('doc', [text]);
+    return;
+  }
+
+  const theme = getTheme();
+  const location = `${theme.path(preview.file_path)}:${theme.line(String(preview.start_line))}`;
+  print(`${location}  ${theme.dim(`${preview.kind} ${preview.name}`)}`);
+  for (const text of highlightCode(signature.join('\n'), preview.language)) print(`  ${text}`);
+  print();
+  if (doc) {
+    for (const text of doc.split('\n')) print(`  ${text}`);
+  } else {
+    print(theme.dim('  (no documentation)'));
+  }
+};
+
+/**
+ * Print documentation search results
+ *
+ * Porcelain: doc_match<TAB>repo_id<TAB>kind<TAB>name<TAB>file<TAB>line<TAB>complete (1 if every word matched)
+ *            <TAB>summary (first sentence of the doc comment)
+ */
+const printMatches = (matches: DocMatchRecord[]): void => {
+  if (isPorcelain()) {
+    for (const match of matches) {
+      const summary = match.doc_comment ? docSummary(match.doc_comment) : '';
+      const symbol = [match.symbol_type, match.symbol_name, match.file_path, match.line_number];
+      printRecord('doc_match', [match.repo_id, ...symbol, Number(match.complete), summary]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const match of matches) {
+    const location = `${theme.path(match.file_path)}:${theme.line(String(match.line_number))}`;
+    print(`${theme.kind(match.symbol_type.padEnd(9))} ${match.symbol_name}  ${location}`);
+    if (match.doc_comment) print(`          ${theme.dim(docSummary(match.doc_comment))}`);
+  }
+  print(theme.dim(`(${String(matches.length)} results)`));
+};
+
+/**
+ * Doc command - a symbol's signature and documentation, or symbols whose documentation matches
+ */
+export const docCommand: CliCommand = {
+  name: 'doc',
+  description: "Print a symbol's signature and documentation, or search documentation",
+  usage: 'cindex doc <[qualifier.]symbol> | --search <words> [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'search', description: 'Find symbols whose doc comments match words', takesValue: true },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' }, search: { type: 'string' } },
+    });
+
+    const searching = values.search !== undefined;
+    const words = values.search?.trim() ?? '';
+    const segments = splitQualifiedName(positionals[0] ?? '');
+    if (searching ? words === '' || positionals.length > 0 : segments.length === 0 || positionals.length > 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: searching ? 'Pass either a symbol or --search <words>' : 'Missing symbol name',
+        hint: `Usage: ${docCommand.usage}`,
+      });
+    }
+
+    const { config, db } = await openSession();
+    try {
+      const repoId = resolveRepoId(values['repo-id']);
+      const pool = db.getPool();
+
+      if (searching) {
+        const store = await openStore(config.store, pool);
+        const matches = await readIndex(repoId, () => store.searchDocs(words, { repoId })).finally(store.close);
+        if (matches.length === 0) {
+          if (!isPorcelain()) print(`No documentation mentions ${words}`);
+          return ExitCode.NoResults;
+        }
+        printMatches(matches);
+        return ExitCode.Success;
+      }
+
+      const previews = await readIndex(repoId, () => resolvePreviews(pool, segments, repoId));
+      if (previews.length === 0) {
+        return reportError(ExitCode.NoResults, {
+          code: 'SYMBOL_NOT_FOUND',
+          message: `No symbol '${positionals[0]}' in the index`,
+          hint: 'Try `cindex doc --search <words>` to find it by what it does',
+        });
+      }
+      previews.slice(0, DOC_LIMIT).forEach((preview, index) => {
+        if (index > 0 && !isPorcelain()) print();
+        printDoc(preview);
+      });
+      if (previews.length > DOC_LIMIT && !isPorcelain()) {
+        print();
+        print(getTheme().dim(`(${String(previews.length - DOC_LIMIT)} more matches; qualify the name to narrow)`));
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,299 @@
+/**
+ * CLI command: doctor
+ * Diagnose configuration, database, Ollama, git, and file watching support
+ *
+ * Each check reports OK/WARN/FAIL with an actionable fix. Checks that depend on
+ * a failed prerequisite (e.g. schema checks without a database connection) are
+ * reported as SKIP instead of cascading failures.
+ */
+import { execFile } from 'node:child_process';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import { promisify } from 'node:util';
+
+import { isPorcelain, print, printCheck } from '@cli/output';
+import { loadConfig, validateConfig } from '@config/env';
+import { createDatabaseClient, type DatabaseClient } from '@database/client';
+import { findCorruptFiles } from '@indexing/integrity';
+import { ollamaEmbeddingModel } from '@utils/embedders';
+import { CindexError } from '@utils/errors';
+import { initLogger } from '@utils/logger';
+import { createOllamaClient } from '@utils/ollama';
+import { ExitCode, type CliCommand, type DiagnosticCheck } from '@/types/cli';
+import { type CindexConfig } from '@/types/config';
+
+const execFileAsync = promisify(execFile);
+
+/**
+ * Tables created by database.sql - a missing table means the schema file was
+ * applied from an older release and needs to be re-applied
+ */
+const REQUIRED_TABLES = [
+  'code_chunks',
+  'code_files',
+  'code_symbols',
+  'workspaces',
+  'services',
+  'repositories',
+  'workspace_aliases',
+  'cross_repo_dependencies',
+  'workspace_dependencies',
+  'api_endpoints',
+  'documentation_chunks',
+  'documentation_files',
+];
+
+/** Repositories not re-indexed within this many days are reported as stale */
+const STALE_INDEX_DAYS = 7;
+
+/**
+ * Convert an unknown error into a failed check, preferring CindexError suggestions as the fix
+ *
+ * @param name - Check name
+ * @param error - Caught error
+ * @param fallbackFix - Fix to show when the error carries no suggestion
+ * @returns Failed diagnostic check
+ */
+const failedCheck = (name: string, error: unknown, fallbackFix: string): DiagnosticCheck => {
+  if (error instanceof CindexError) {
+    return { name, status: 'fail', detail: error.message, fix: error.suggestion ?? fallbackFix };
+  }
+  const message = error instanceof Error ? error.message : String(error);
+  return { name, status: 'fail', detail: message, fix: fallbackFix };
+};
+
+/**
+ * Check environment configuration loads and passes semantic validation
+ */
+const checkConfig = (): { check: DiagnosticCheck; config: CindexConfig | null } => {
+  try {
+    const config = loadConfig();
+    validateConfig(config);
+    return {
+      check: { name: 'Configuration', status: 'ok', detail: 'environment variables are valid' },
+      config,
+    };
+  } catch (error) {
+    return {
+      check: failedCheck('Configuration', error, 'Review the Environment Variables section of the README'),
+      config: null,
+    };
+  }
+};
+
+/**
+ * Check all tables from database.sql exist in the connected database
+ */
+const checkSchemaTables = async (db: DatabaseClient): Promise<DiagnosticCheck> => {
+  const result = await db.query<{ table_name: string }>(
+    `SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_name = ANY($1)`,
+    [REQUIRED_TABLES]
+  );
+  const present = new Set(result.rows.map((row) => row.table_name));
+  const missing = REQUIRED_TABLES.filter((table) => !present.has(table));
+
+  if (missing.length > 0) {
+    return {
+      name: 'Schema version',
+      status: 'fail',
+      detail: `missing tables: ${missing.join(', ')}`,
+      fix: 'Re-apply the schema: psql <database> < database.sql',
+    };
+  }
+  return { name: 'Schema version', status: 'ok', detail: `${String(REQUIRED_TABLES.length)} tables present` };
+};
+
+/**
+ * Check indexed repositories are recent and still exist on disk
+ */
+const checkStaleIndexes = async (db: DatabaseClient): Promise<DiagnosticCheck> => {
+  // Revision indexes (cindex index --rev) never go stale, and their repo_path is nominal
+  const result = await db.query<{ repo_id: string; repo_path: string; last_indexed: Date | null }>(
+    `SELECT repo_id, repo_path, COALESCE(last_updated, indexed_at) AS last_indexed FROM repositories
+     WHERE metadata IS NULL OR NOT metadata ? 'revision'`
+  );
+
+  const cutoff = Date.now() - STALE_INDEX_DAYS * 24 * 60 * 60 * 1000;
+  const missingPaths: string[] = [];
+  const outdated: string[] = [];
+
+  for (const row of result.rows) {
+    if (!fs.existsSync(row.repo_path)) {
+      missingPaths.push(row.repo_id);
+    } else if (row.last_indexed && row.last_indexed.getTime() < cutoff) {
+      outdated.push(row.repo_id);
+    }
+  }
+
+  if (missingPaths.length > 0) {
+    return {
+      name: 'Stale indexes',
+      status: 'warn',
+      detail: `repository path no longer exists: ${missingPaths.join(', ')}`,
+      fix: 'Remove stale entries with the delete_repository tool',
+    };
+  }
+  if (outdated.length > 0) {
+    return {
+      name: 'Stale indexes',
+      status: 'warn',
+      detail: `not re-indexed in ${String(STALE_INDEX_DAYS)}+ days: ${outdated.join(', ')}`,
+      fix: 'Re-index with the index_repository tool (incremental: true)',
+    };
+  }
+  return { name: 'Stale indexes', status: 'ok', detail: `${String(result.rows.length)} repositories up to date` };
+};
+
+/**
+ * Check stored chunks match their file records (report only; nothing is quarantined)
+ */
+const checkIndexIntegrity = async (db: DatabaseClient): Promise<DiagnosticCheck> => {
+  const corrupt = await findCorruptFiles(db);
+  if (corrupt.length > 0) {
+    const examples = corrupt.slice(0, 3).map((file) => file.file_path);
+    return {
+      name: 'Index integrity',
+      status: 'warn',
+      detail: `${String(corrupt.length)} files with truncated or mismatched chunks (${examples.join(', ')})`,
+      fix: 'Restart the MCP server to rebuild them, or run `cindex index <path> --incremental`',
+    };
+  }
+  return { name: 'Index integrity', status: 'ok', detail: 'stored chunks match their files' };
+};
+
+/**
+ * Check database connection, pgvector, schema, index freshness, and integrity
+ */
+const checkDatabase = async (config: CindexConfig | null): Promise<DiagnosticCheck[]> => {
+  const dependentChecks = ['Schema version', 'Stale indexes', 'Index integrity'];
+  if (!config) {
+    return ['Database connection', ...dependentChecks].map((name): DiagnosticCheck => ({
+      name,
+      status: 'skip',
+      detail: 'configuration invalid',
+    }));
+  }
+
+  const db = createDatabaseClient(config.database);
+  try {
+    await db.connect();
+    await db.healthCheck(config.embedding.dimensions);
+  } catch (error) {
+    await db.close().catch(() => undefined);
+    return [
+      failedCheck('Database connection', error, 'Check POSTGRES_* variables and that PostgreSQL is running'),
+      ...dependentChecks.map((name): DiagnosticCheck => ({ name, status: 'skip', detail: 'database unavailable' })),
+    ];
+  }
+
+  const { user, host, port, database } = config.database;
+  const checks: DiagnosticCheck[] = [
+    { name: 'Database connection', status: 'ok', detail: `${user}@${host}:${String(port)}/${database}` },
+  ];
+
+  try {
+    checks.push(await checkSchemaTables(db));
+    checks.push(await checkStaleIndexes(db));
+    checks.push(await checkIndexIntegrity(db));
+  } catch (error) {
+    checks.push(failedCheck('Schema version', error, 'Re-apply the schema: psql <database> < database.sql'));
+  } finally {
+    await db.close();
+  }
+
+  return checks;
+};
+
+/**
+ * Check Ollama is reachable and the configured models it serves are pulled
+ */
+const checkOllama = async (config: CindexConfig | null): Promise<DiagnosticCheck> => {
+  if (!config) {
+    return { name: 'Ollama models', status: 'skip', detail: 'configuration invalid' };
+  }
+
+  try {
+    const ollama = createOllamaClient(config.ollama);
+    const embeddingModel = ollamaEmbeddingModel(config.embedding);
+    await ollama.healthCheck(embeddingModel, config.summary.model);
+    const models = [embeddingModel, config.summary.model].filter(Boolean).join(', ');
+    return { name: 'Ollama models', status: 'ok', detail: models };
+  } catch (error) {
+    const model = ollamaEmbeddingModel(config.embedding) ?? config.summary.model;
+    return failedCheck('Ollama models', error, `Start Ollama and run: ollama pull ${model}`);
+  }
+};
+
+/**
+ * Check git is installed (used for .gitignore handling and repository metadata)
+ */
+const checkGit = async (): Promise<DiagnosticCheck> => {
+  try {
+    const { stdout } = await execFileAsync('git', ['--version']);
+    return { name: 'Git integration', status: 'ok', detail: stdout.trim() };
+  } catch {
+    return {
+      name: 'Git integration',
+      status: 'warn',
+      detail: 'git executable not found on PATH',
+      fix: 'Install git to enable repository metadata detection',
+    };
+  }
+};
+
+/**
+ * Check recursive fs.watch is supported on this platform
+ */
+const checkWatchBackend = (): DiagnosticCheck => {
+  try {
+    const watcher = fs.watch(os.tmpdir(), { recursive: true });
+    watcher.close();
+    return { name: 'Watch backend', status: 'ok', detail: `recursive fs.watch available (${process.platform})` };
+  } catch (error) {
+    return {
+      name: 'Watch backend',
+      status: 'warn',
+      detail: error instanceof Error ? error.message : String(error),
+      fix: 'Upgrade Node.js to 22+ or re-index manually after changes',
+    };
+  }
+};
+
+/**
+ * Run all diagnostic checks
+ *
+ * @returns Diagnostic checks in display order
+ */
+export const runDiagnostics = async (): Promise<DiagnosticCheck[]> => {
+  const { check: configCheck, config } = checkConfig();
+  const databaseChecks = await checkDatabase(config);
+  const ollamaCheck = await checkOllama(config);
+  const gitCheck = await checkGit();
+
+  return [configCheck, ...databaseChecks, ollamaCheck, gitCheck, checkWatchBackend()];
+};
+
+/**
+ * Doctor command - prints each check and exits non-zero if any check failed
+ */
+export const doctorCommand: CliCommand = {
+  name: 'doctor',
+  description: 'Check configuration, database, Ollama, and environment health',
+  usage: 'cindex doctor [--porcelain]',
+  run: async () => {
+    // Health check helpers log to stderr; keep doctor output to the check list
+    initLogger('ERROR');
+
+    const checks = await runDiagnostics();
+    checks.forEach(printCheck);
+
+    const failed = checks.filter((check) => check.status === 'fail').length;
+    const warned = checks.filter((check) => check.status === 'warn').length;
+    if (!isPorcelain()) {
+      print();
+      print(`${String(checks.length)} checks: ${String(failed)} failed, ${String(warned)} warnings`);
+    }
+
+    return failed > 0 ? ExitCode.Failure : ExitCode.Success;
+  },
+};
@@ -0,0 +1,145 @@
+/**
+ * CLI command: dupes
+ * Report groups of duplicated and near-duplicated functions (see @indexing/clones)
+ *
+ *   cindex dupes                      functions at least 90% alike
+ *   cindex dupes --threshold 0.75     near-duplicates too
+ *   cindex dupes --min-tokens 100     only longer functions
+ *
+ * Function bodies are fingerprinted at index time with identifiers and
+ * literals normalized, so a copy with renamed variables or other constants
+ * is an exact duplicate. Similarity is estimated from the fingerprints and
+ * can be a few points off for near-duplicates.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listCloneCandidates } from '@database/queries';
+import { CLONE_MIN_TOKENS, findCloneGroups, type CloneGroup } from '@indexing/clones';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Default lowest similarity reported */
+const DEFAULT_THRESHOLD = 0.9;
+
+/** Default shortest function compared, in normalized tokens */
+const DEFAULT_MIN_TOKENS = 50;
+
+/**
+ * Similarity as a whole percentage
+ */
+const percent = (similarity: number): string => `${String(Math.round(similarity * 100))}%`;
+
+/**
+ * Print clone groups
+ *
+ * Porcelain:
+ *   clone_group<TAB>group<TAB>similarity<TAB>functions
+ *   clone<TAB>group<TAB>repo_id<TAB>path<TAB>line<TAB>end_line<TAB>kind<TAB>name<TAB>tokens
+ *
+ * @param groups - Groups, the most duplicated first
+ */
+const printGroups = (groups: CloneGroup[]): void => {
+  if (isPorcelain()) {
+    groups.forEach((group, index) => {
+      const id = index + 1;
+      printRecord('clone_group', [id, group.similarity.toFixed(3), group.members.length]);
+      for (const member of group.members) {
+        const { repo_id, file_path, line_number, end_line, symbol_type, symbol_name, clone_tokens } = member;
+        printRecord('clone', [id, repo_id, file_path, line_number, end_line, symbol_type, symbol_name, clone_tokens]);
+      }
+    });
+    return;
+  }
+
+  const theme = getTheme();
+  groups.forEach((group, index) => {
+    if (index > 0) print();
+    const alike = group.similarity === 1 ? 'identical' : `${percent(group.similarity)} alike`;
+    print(theme.dim(`${String(group.members.length)} functions, ${alike}`));
+    const locations = group.members.map((member) => {
+      const end = member.end_line !== null ? `-${String(member.end_line)}` : '';
+      return `${member.file_path}:${String(member.line_number)}${end}`;
+    });
+    const width = Math.max(...locations.map((location) => location.length));
+    group.members.forEach((member, position) => {
+      const tokens = theme.dim(`${String(member.clone_tokens)} tokens`);
+      print(`  ${theme.path(locations[position].padEnd(width))}  ${theme.kind(member.symbol_name)}  ${tokens}`);
+    });
+  });
+};
+
+/**
+ * Dupes command - groups of functions that duplicate each other
+ */
+export const dupesCommand: CliCommand = {
+  name: 'dupes',
+  description: 'Report groups of duplicated and near-duplicated functions',
+  usage: 'cindex dupes [--threshold <0-1>] [--min-tokens <n>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'threshold',
+      description: `Lowest similarity reported, 0 to 1 (default ${String(DEFAULT_THRESHOLD)})`,
+      takesValue: true,
+    },
+    {
+      name: 'min-tokens',
+      description: `Leave out shorter functions, in normalized tokens (default ${String(DEFAULT_MIN_TOKENS)})`,
+      takesValue: true,
+    },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        'repo-id': { type: 'string' },
+        threshold: { type: 'string' },
+        'min-tokens': { type: 'string' },
+      },
+    });
+
+    const threshold = values.threshold !== undefined ? Number(values.threshold) : DEFAULT_THRESHOLD;
+    if (!Number.isFinite(threshold) || threshold <= 0 || threshold > 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --threshold value: ${values.threshold ?? ''}`,
+        hint: 'Expected a similarity above 0 and up to 1, such as 0.8',
+      });
+    }
+    const minTokens = values['min-tokens'] !== undefined ? Number(values['min-tokens']) : DEFAULT_MIN_TOKENS;
+    if (!Number.isInteger(minTokens) || minTokens < 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --min-tokens value: ${values['min-tokens'] ?? ''}`,
+        hint: `Expected a number of tokens; functions under ${String(CLONE_MIN_TOKENS)} are never fingerprinted`,
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const candidates = await readIndex(repoId, () => listCloneCandidates(db.getPool(), { repoId, minTokens }));
+      const groups = findCloneGroups(candidates, threshold);
+      if (groups.length === 0) {
+        if (!isPorcelain()) {
+          print(`No functions at least ${percent(threshold)} alike among ${String(candidates.length)} compared`);
+        }
+        return ExitCode.NoResults;
+      }
+
+      printGroups(groups);
+      if (!isPorcelain()) {
+        const functions = groups.reduce((sum, group) => sum + group.members.length, 0);
+        print();
+        print(`${String(groups.length)} groups, ${String(functions)} of ${String(candidates.length)} functions`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,123 @@
+/**
+ * CLI command: enums
+ * List the values of a Go enum, recorded at index time (see @indexing/go-constants)
+ *
+ *   cindex enums UserRole         constants of a type, with values and docs
+ *   cindex enums auth.UserRole    a type qualified by its package
+ *   cindex enums SessionTimeout   the const declaration a constant is in
+ *
+ * Values are evaluated from the declaring file alone; a constant whose value
+ * uses a name declared elsewhere is shown with its expression instead.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoConstants } from '@database/queries';
+import { docSummary } from '@indexing/doc-comments';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoConstantRecord } from '@/types/database';
+
+/**
+ * Print constants, one block per const declaration
+ *
+ * Porcelain:
+ *   constant<TAB>repo_id<TAB>path<TAB>line<TAB>type<TAB>name<TAB>value<TAB>expression<TAB>iota<TAB>doc
+ *
+ * @param constants - Constants ordered by index, file, and line
+ */
+const printConstants = (constants: GoConstantRecord[]): void => {
+  if (isPorcelain()) {
+    for (const constant of constants) {
+      printRecord('constant', [
+        constant.repo_id,
+        constant.file_path,
+        constant.line_number,
+        constant.type_name,
+        constant.constant_name,
+        constant.value,
+        constant.expression,
+        constant.iota,
+        constant.doc ? docSummary(constant.doc) : null,
+      ]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const groups = new Map<string, GoConstantRecord[]>();
+  for (const constant of constants) {
+    const key = `${constant.repo_id ?? ''}\0${constant.file_path}\0${String(constant.group_line)}`;
+    groups.set(key, [...(groups.get(key) ?? []), constant]);
+  }
+
+  let first = true;
+  for (const group of groups.values()) {
+    if (!first) print();
+    first = false;
+    const types = [...new Set(group.map((constant) => constant.type_name ?? 'untyped'))].join(', ');
+    print(`${theme.kind(types)}  ${theme.path(`${group[0].file_path}:${String(group[0].group_line)}`)}`);
+
+    const nameWidth = Math.max(...group.map((constant) => constant.constant_name.length));
+    const valueOf = (constant: GoConstantRecord): string => constant.value ?? constant.expression;
+    const valueWidth = Math.max(...group.map((constant) => valueOf(constant).length));
+    for (const constant of group) {
+      const value = constant.value ? theme.number(valueOf(constant)) : theme.dim(valueOf(constant));
+      let line = `  ${constant.constant_name.padEnd(nameWidth)}  ${value}`;
+      if (constant.doc) line += `${' '.repeat(valueWidth - valueOf(constant).length)}  ${docSummary(constant.doc)}`;
+      print(line.trimEnd());
+    }
+  }
+};
+
+/**
+ * Enums command - values of a Go enum type or const group
+ */
+export const enumsCommand: CliCommand = {
+  name: 'enums',
+  description: 'List the constants of a Go type or const group with their values',
+  usage: 'cindex enums <type|constant> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const [name] = positionals;
+    if (!name) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing type or constant name',
+        hint: 'Usage: cindex enums <type|constant>, e.g. cindex enums UserRole',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const constants = await readIndex(repoId, () => listGoConstants(db.getPool(), name, repoId));
+      if (constants.length === 0) {
+        if (!isPorcelain()) print(`No constants of ${name}`);
+        return ExitCode.NoResults;
+      }
+
+      printConstants(constants);
+      if (!isPorcelain()) {
+        const unresolved = constants.filter((constant) => constant.value === null).length;
+        const of = unresolved > 0 ? ` (${String(unresolved)} not resolved in their file)` : '';
+        print();
+        print(`${String(constants.length)} constants${of}`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
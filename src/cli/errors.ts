@@ -0,0 +1,84 @@
+/**
+ * CLI command: errors
+ * List files that failed or partially failed to parse in their last build
+ *
+ * A file with syntax errors is indexed partially (the declarations tree-sitter
+ * could still parse) or, if nothing parsed, with the regex fallback parser.
+ * The recorded reason and position help decide whether to fix the file or
+ * exclude it (.cindex.yaml `exclude`). Columns are UTF-8 bytes by default;
+ * pass --position-encoding utf-16 for LSP-style code-unit columns.
+ */
+import { parseArgs } from 'node:util';
+
+import { getPositionEncoding, isPorcelain, print, printRecord } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listParseErrors } from '@database/queries';
+import { selectColumn } from '@utils/positions';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Errors command - report parse failures recorded in the index
+ */
+export const errorsCommand: CliCommand = {
+  name: 'errors',
+  description: 'List files that failed to parse cleanly in the last build',
+  usage: 'cindex errors [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const records = await readIndex(repoId, () => listParseErrors(db.getPool(), repoId));
+      const encoding = getPositionEncoding();
+      const columnOf = (record: (typeof records)[number]): number | null =>
+        record.parse_error_column === null
+          ? null
+          : selectColumn({ column: record.parse_error_column, byte_column: record.parse_error_byte_column }, encoding);
+
+      // Porcelain: parse_error<TAB>repo_id<TAB>path<TAB>language<TAB>line<TAB>column<TAB>message<TAB>status
+      if (isPorcelain()) {
+        for (const record of records) {
+          const { repo_id, file_path, language, parse_error_line, parse_error } = record;
+          const status = record.parse_partial ? 'partial' : 'fallback';
+          printRecord('parse_error', [
+            repo_id,
+            file_path,
+            language,
+            parse_error_line,
+            columnOf(record),
+            parse_error,
+            status,
+          ]);
+        }
+      } else if (records.length === 0) {
+        print('No parse errors in the last build');
+      } else {
+        const theme = getTheme();
+        for (const record of records) {
+          const position = record.parse_error_line
+            ? `:${theme.line(`${String(record.parse_error_line)}:${String(columnOf(record) ?? 1)}`)}`
+            : '';
+          const label = theme.dim(`(${record.language}, ${record.parse_partial ? 'partial' : 'fallback'})`);
+          print(`${theme.path(record.file_path)}${position}  ${label}  ${record.parse_error}`);
+        }
+        const partial = records.filter((record) => record.parse_partial).length;
+        print();
+        print(
+          `${String(records.length)} files with syntax errors: ${String(partial)} partially parsed, ` +
+            `${String(records.length - partial)} indexed with the fallback parser`
+        );
+        print(theme.dim('Fix the syntax errors, or exclude the files in .cindex.yaml, then re-index'));
+      }
+
+      // Partially parsed files are a partial failure of the build
+      return records.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
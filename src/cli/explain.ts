@@ -0,0 +1,264 @@
+/**
+ * CLI command: explain
+ * Show how a search runs: the database plan, the local filters, and time per stage
+ *
+ *   cindex explain auth kind:func path:internal/
+ *
+ * A search sends its longest term to the database (symbol_name ILIKE), takes
+ * up to SEARCH_LIMIT candidates, and applies the other terms and filters
+ * locally. The report shows which tables and indexes the database touched,
+ * how many rows it read and discarded, how many candidates each filter kept,
+ * and where the time went, with hints for the usual causes of slow or
+ * incomplete results. The plan comes from EXPLAIN ANALYZE, which runs the
+ * query once more with instrumentation, so its timings run slightly high.
+ */
+import { performance } from 'node:perf_hooks';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import {
+  applyQuery,
+  genericCondition,
+  idConditions,
+  implementsConditions,
+  kindConditions,
+  languageConditions,
+  metricConditions,
+  moduleConditions,
+  parseQuery,
+  traceQuery,
+  wordConditions,
+} from '@cli/query-filter';
+import { findFilesChangedSince, REPO_ID_OPTION, SEARCH_LIMIT, seedTerm, SINCE_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { buildSymbolSearchQuery, explainQuery, searchSymbols } from '@database/queries';
+import { parseSince } from '@indexing/changed-files';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type QueryPlanNode } from '@/types/database';
+
+/**
+ * Plan node flattened for display (totals across loops)
+ */
+interface PlanStep {
+  depth: number;
+  node: string;
+  relation: string | null;
+  index: string | null;
+  rows: number;
+  removed: number;
+  time_ms: number;
+  shared_hit: number;
+  shared_read: number;
+}
+
+/**
+ * Flatten a plan tree depth-first
+ */
+const flattenPlan = (node: QueryPlanNode, depth = 0): PlanStep[] => {
+  const loops = node['Actual Loops'] ?? 1;
+  const step: PlanStep = {
+    depth,
+    node: node['Node Type'],
+    relation: node['Relation Name'] ?? null,
+    index: node['Index Name'] ?? null,
+    rows: (node['Actual Rows'] ?? 0) * loops,
+    removed: (node['Rows Removed by Filter'] ?? 0) * loops,
+    time_ms: (node['Actual Total Time'] ?? 0) * loops,
+    shared_hit: node['Shared Hit Blocks'] ?? 0,
+    shared_read: node['Shared Read Blocks'] ?? 0,
+  };
+  return [step, ...(node.Plans ?? []).flatMap((child) => flattenPlan(child, depth + 1))];
+};
+
+/**
+ * Milliseconds with one decimal
+ */
+const formatMs = (ms: number): string => `${ms.toFixed(1)} ms`;
+
+/**
+ * Explain command - report the plan and cost of a search
+ */
+export const explainCommand: CliCommand = {
+  name: 'explain',
+  description: 'Show the plan, candidates, and time per stage of a search',
+  usage: 'cindex explain <terms> [kind:|path:|scope:|name:value ...] [--repo-id <name>] [--since <window>]',
+  options: [REPO_ID_OPTION, SINCE_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' }, since: { type: 'string' } },
+    });
+
+    if (positionals.length === 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing search terms',
+        hint: `Usage: ${explainCommand.usage}`,
+      });
+    }
+
+    const since = values.since !== undefined ? parseSince(values.since) : undefined;
+    if (since === null) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --since value: ${values.since ?? ''}`,
+        hint: 'Expected a window such as 2w, 3d, 12h, or a date such as 2025-01-31',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repoId = resolveRepoId(values['repo-id']);
+      const stages: { stage: string; ms: number }[] = [];
+      const timed = async <T>(stage: string, run: () => T | Promise<T>): Promise<T> => {
+        const started = performance.now();
+        const result = await run();
+        stages.push({ stage, ms: performance.now() - started });
+        return result;
+      };
+
+      const query = await timed('parse', () => parseQuery(positionals.join(' ')));
+      const seed = seedTerm(query);
+      const options = {
+        limit: SEARCH_LIMIT,
+        repoId,
+        metrics: metricConditions(query),
+        symbolTypes: kindConditions(query),
+        implements: implementsConditions(query),
+        languages: languageConditions(query),
+        generic: genericCondition(query),
+        modules: moduleConditions(query),
+        words: wordConditions(query),
+        ids: idConditions(query),
+      };
+
+      const { plan, candidates, filters, results } = await readIndex(repoId, async () => {
+        // A retried read replaces the timings of the first attempt
+        stages.splice(1);
+        const found = await timed('database', () => searchSymbols(pool, seed, options));
+        const { sql, params } = buildSymbolSearchQuery(seed, options);
+        const executed = await explainQuery(pool, sql, params);
+        let matched = await timed('filter', () => applyQuery(found, query));
+        const trace = traceQuery(found, query);
+        if (since) {
+          const changed = await timed('since', () => findFilesChangedSince(pool, since, repoId));
+          matched = matched.filter((symbol) => changed.has(symbol.file_path));
+          trace.push({ step: `--since ${values.since ?? ''}`, remaining: matched.length });
+        }
+        return { plan: executed, candidates: found.length, filters: trace, results: matched.length };
+      });
+
+      const steps = flattenPlan(plan.Plan);
+      const scans = steps.filter((step) => step.node.includes('Scan'));
+      const rowsRead = scans.reduce((sum, step) => sum + step.rows + step.removed, 0);
+
+      const hints: string[] = [];
+      const pushedDown = options.metrics.length + options.symbolTypes.length + options.implements.length;
+      if (seed === '' && pushedDown === 0) {
+        hints.push(
+          `No name term: the database returns the first ${String(SEARCH_LIMIT)} symbols and the filters narrow ` +
+            'only those; add a term'
+        );
+      } else if (scans.some((step) => step.node === 'Seq Scan' && step.relation === 'code_symbols')) {
+        hints.push(
+          "code_symbols is read in full: ILIKE '%term%' cannot use idx_symbols_name. On large indexes, add a " +
+            'trigram index: CREATE EXTENSION IF NOT EXISTS pg_trgm; ' +
+            'CREATE INDEX ON code_symbols USING gin (symbol_name gin_trgm_ops);'
+        );
+      }
+      if (candidates >= SEARCH_LIMIT) {
+        hints.push(
+          `The database returned the candidate limit (${String(SEARCH_LIMIT)}), so matches past it are never ` +
+            'filtered; use a longer or more specific name term'
+        );
+      }
+
+      // Porcelain records: explain_stage, explain_plan, explain_filter, explain_hint
+      if (isPorcelain()) {
+        for (const { stage, ms } of stages) {
+          printRecord('explain_stage', [stage, ms.toFixed(3)]);
+        }
+        for (const step of steps) {
+          printRecord('explain_plan', [
+            step.depth,
+            step.node,
+            step.relation,
+            step.index,
+            step.rows,
+            step.removed,
+            step.time_ms.toFixed(3),
+            step.shared_hit,
+            step.shared_read,
+          ]);
+        }
+        printRecord('explain_filter', ['candidates', candidates]);
+        for (const { step, remaining } of filters) {
+          printRecord('explain_filter', [step, remaining]);
+        }
+        for (const hint of hints) {
+          printRecord('explain_hint', [hint]);
+        }
+        return results > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      const theme = getTheme();
+      print(`Query: ${positionals.join(' ')}`);
+      print(
+        seed
+          ? `Database term: ${theme.match(seed)} (longest term, symbol_name ILIKE '%${seed}%')`
+          : 'Database term: (none)'
+      );
+
+      print();
+      print('Stages');
+      for (const { stage, ms } of stages) {
+        print(`  ${stage.padEnd(10)} ${formatMs(ms).padStart(10)}`);
+      }
+      const total = stages.reduce((sum, { ms }) => sum + ms, 0);
+      print(`  ${'total'.padEnd(10)} ${formatMs(total).padStart(10)}`);
+
+      print();
+      print(
+        `Database plan ${theme.dim(
+          `(planning ${formatMs(plan['Planning Time'])}, execution ${formatMs(plan['Execution Time'])})`
+        )}`
+      );
+      for (const step of steps) {
+        const target = [step.relation ? ` on ${step.relation}` : '', step.index ? ` using ${step.index}` : ''].join('');
+        const removed = step.removed > 0 ? `, ${String(step.removed)} removed by filter` : '';
+        const buffers =
+          step.shared_hit + step.shared_read > 0
+            ? `, buffers ${String(step.shared_hit)} hit ${String(step.shared_read)} read`
+            : '';
+        print(
+          `  ${'  '.repeat(step.depth)}${theme.kind(step.node)}${target}  ` +
+            theme.dim(`rows ${String(step.rows)}${removed}, ${formatMs(step.time_ms)}${buffers}`)
+        );
+      }
+      const tables = [...new Set(scans.flatMap((step) => (step.relation ? [step.relation] : [])))];
+      print(`  Tables touched: ${tables.join(', ') || '(none)'}; rows read: ${String(rowsRead)}`);
+
+      print();
+      print('Filters');
+      print(`  ${'candidates'.padEnd(24)} ${String(candidates).padStart(6)}`);
+      for (const { step, remaining } of filters) {
+        print(`  ${step.padEnd(24)} ${String(remaining).padStart(6)}`);
+      }
+      print(`Results: ${String(results)}`);
+
+      if (hints.length > 0) {
+        print();
+        for (const hint of hints) {
+          print(theme.dim(`Hint: ${hint}`));
+        }
+      }
+      return results > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
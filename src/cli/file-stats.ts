@@ -0,0 +1,183 @@
+/**
+ * Per-file, per-package, and per-directory metrics (cindex stats --by)
+ *
+ * Rows add up the line counts recorded at index time (see
+ * @indexing/file-metrics) and the symbols of each file. Two ratios are derived
+ * from the sums, so they weigh every line the same whatever the file:
+ *
+ *   comment_density  comment lines / (code + comment lines)
+ *   test_ratio       code lines of test files / code lines of other files
+ *
+ * A Go package is its directory; other files belong to the workspace package
+ * detected at index time, or their directory outside one.
+ */
+import { csvField, directoryOf } from '@cli/ownership';
+import { compareStrings } from '@utils/ordering';
+import { type FileStatsRecord } from '@/types/database';
+import { Language } from '@/types/indexing';
+
+/** Groupings accepted by --by */
+export const STATS_GROUPINGS = ['file', 'package', 'dir'] as const;
+export type StatsGrouping = (typeof STATS_GROUPINGS)[number];
+
+/**
+ * Metrics of one file, package, or directory
+ */
+export interface FileStatsRow {
+  repo_id: string | null;
+  /** File path, package name, or directory ('.' for the root) */
+  group: string;
+  files: number;
+  test_files: number;
+  lines: number;
+  /** Null when no file of the group has line kinds recorded (indexed by an older cindex) */
+  code_lines: number | null;
+  comment_lines: number | null;
+  blank_lines: number | null;
+  comment_density: number | null;
+  /** Null when the group has no code outside tests */
+  test_ratio: number | null;
+  symbols: number;
+  symbols_by_kind: Record<string, number>;
+  functions: number;
+  /** Mean cyclomatic complexity of the functions, null without any */
+  avg_complexity: number | null;
+}
+
+/**
+ * Check that a value is a grouping
+ */
+export const isStatsGrouping = (value: string): value is StatsGrouping =>
+  (STATS_GROUPINGS as readonly string[]).includes(value);
+
+/**
+ * Group a file is reported under
+ */
+const groupOf = (file: FileStatsRecord, by: StatsGrouping): string => {
+  if (by === 'file') return file.file_path;
+  if (by === 'package' && file.language !== Language.Go && file.package_name) return file.package_name;
+  return directoryOf(file.file_path);
+};
+
+/**
+ * Files of one group
+ */
+interface GroupTotals {
+  repo_id: string | null;
+  group: string;
+  files: FileStatsRecord[];
+}
+
+/**
+ * Compute the metrics of a group from its files
+ */
+const summarize = ({ repo_id, group, files }: GroupTotals): FileStatsRow => {
+  const counted = files.filter((file) => file.code_lines !== null);
+  const sum = (values: number[]): number => values.reduce((total, value) => total + value, 0);
+  const lineSum = (key: 'code_lines' | 'comment_lines' | 'blank_lines', test?: boolean): number =>
+    sum(counted.filter((file) => test === undefined || file.is_test === test).map((file) => file[key] ?? 0));
+
+  const symbolsByKind: Record<string, number> = {};
+  for (const file of files) {
+    for (const [kind, count] of Object.entries(file.symbol_kinds)) {
+      symbolsByKind[kind] = (symbolsByKind[kind] ?? 0) + count;
+    }
+  }
+  const functions = sum(files.map((file) => file.functions));
+  const code = counted.length > 0 ? lineSum('code_lines') : null;
+  const comment = counted.length > 0 ? lineSum('comment_lines') : null;
+  const productionCode = lineSum('code_lines', false);
+
+  return {
+    repo_id,
+    group,
+    files: files.length,
+    test_files: files.filter((file) => file.is_test).length,
+    lines: sum(files.map((file) => file.total_lines)),
+    code_lines: code,
+    comment_lines: comment,
+    blank_lines: counted.length > 0 ? lineSum('blank_lines') : null,
+    comment_density: code !== null && comment !== null && code + comment > 0 ? comment / (code + comment) : null,
+    test_ratio: counted.length > 0 && productionCode > 0 ? lineSum('code_lines', true) / productionCode : null,
+    symbols: sum(Object.values(symbolsByKind)),
+    symbols_by_kind: Object.fromEntries(Object.entries(symbolsByKind).sort(([a], [b]) => compareStrings(a, b))),
+    functions,
+    avg_complexity: functions > 0 ? sum(files.map((file) => file.complexity_total)) / functions : null,
+  };
+};
+
+/**
+ * Aggregate file metrics per file, package, or directory
+ *
+ * @param files - Files of the indexes reported
+ * @param by - Grouping
+ * @returns One row per index and group, ordered by index and group
+ */
+export const aggregateFileStats = (files: FileStatsRecord[], by: StatsGrouping): FileStatsRow[] => {
+  const groups = new Map<string, GroupTotals>();
+  for (const file of files) {
+    const group = groupOf(file, by);
+    const key = `${file.repo_id ?? ''}\0${group}`;
+    const totals = groups.get(key) ?? { repo_id: file.repo_id, group, files: [] };
+    totals.files.push(file);
+    groups.set(key, totals);
+  }
+  return [...groups.values()]
+    .map(summarize)
+    .sort((a, b) => compareStrings(a.repo_id ?? '', b.repo_id ?? '') || compareStrings(a.group, b.group));
+};
+
+/** Column of the group in CSV per grouping */
+const GROUP_COLUMN: Record<StatsGrouping, string> = { file: 'path', package: 'package', dir: 'directory' };
+
+/**
+ * Render metrics rows as CSV
+ *
+ * Symbol counts get one `symbols_<kind>` column per kind found in any row,
+ * after the fixed columns and in name order.
+ *
+ * @param rows - Rows from aggregateFileStats
+ * @param by - Grouping the rows were built with
+ * @returns CSV text with a header line
+ */
+export const toFileStatsCsv = (rows: FileStatsRow[], by: StatsGrouping): string => {
+  const kinds = [...new Set(rows.flatMap((row) => Object.keys(row.symbols_by_kind)))].sort(compareStrings);
+  const ratio = (value: number | null): string | null => (value === null ? null : value.toFixed(4));
+  const header = [
+    'repo_id',
+    GROUP_COLUMN[by],
+    'files',
+    'test_files',
+    'lines',
+    'code_lines',
+    'comment_lines',
+    'blank_lines',
+    'comment_density',
+    'test_ratio',
+    'symbols',
+    'functions',
+    'avg_complexity',
+    ...kinds.map((kind) => `symbols_${kind}`),
+  ];
+  const lines = rows.map((row) =>
+    [
+      row.repo_id,
+      row.group,
+      row.files,
+      row.test_files,
+      row.lines,
+      row.code_lines,
+      row.comment_lines,
+      row.blank_lines,
+      ratio(row.comment_density),
+      ratio(row.test_ratio),
+      row.symbols,
+      row.functions,
+      row.avg_complexity === null ? null : row.avg_complexity.toFixed(2),
+      ...kinds.map((kind) => row.symbols_by_kind[kind] ?? 0),
+    ]
+      .map(csvField)
+      .join(',')
+  );
+  return [header.join(','), ...lines].join('\n') + '\n';
+};
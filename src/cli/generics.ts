@@ -0,0 +1,165 @@
+/**
+ * CLI command: generics
+ * List Go generics and where they are instantiated, recorded at index time (see @indexing/go-generics)
+ *
+ *   cindex generics                  every generic function and type, with its type parameters
+ *   cindex generics Map              type parameters of Map and its instantiations
+ *   cindex generics collections.Map  a generic qualified by its package
+ *
+ * Instantiations with written-out type arguments (Map[int, string]) are
+ * listed with them; calls that leave the arguments to inference
+ * (Map(xs, f)) are listed as inferred.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoInstantiations, listGoTypeParameters } from '@database/queries';
+import { defaultImportName } from '@indexing/go-calls';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoInstantiationRecord, type GoTypeParameterRecord } from '@/types/database';
+
+/**
+ * Generic declaration with its type parameters
+ */
+interface GenericDeclaration {
+  first: GoTypeParameterRecord;
+  /** Type parameter list, one constraint per parameter: K comparable, V any */
+  parameters: string;
+}
+
+/**
+ * Group type parameters by the declaration they belong to
+ *
+ * @param parameters - Type parameters ordered by file, line, and position
+ */
+const declarationsOf = (parameters: GoTypeParameterRecord[]): GenericDeclaration[] => {
+  const declarations = new Map<string, GoTypeParameterRecord[]>();
+  for (const parameter of parameters) {
+    const key = `${parameter.repo_id ?? ''}\0${parameter.file_path}\0${parameter.symbol_name}`;
+    declarations.set(key, [...(declarations.get(key) ?? []), parameter]);
+  }
+  return [...declarations.values()].map((group) => ({
+    first: group[0],
+    parameters: group.map((parameter) => `${parameter.parameter_name} ${parameter.constraint_text}`).join(', '),
+  }));
+};
+
+/**
+ * Generic as written at an instantiation: Map[int, string], slices.Collect (inferred)
+ */
+const instantiationLabel = (instantiation: GoInstantiationRecord): string => {
+  const qualifier = instantiation.target_package ? `${defaultImportName(instantiation.target_package)}.` : '';
+  const name = `${qualifier}${instantiation.target_name}`;
+  return instantiation.inferred ? name : `${name}[${instantiation.type_arguments.join(', ')}]`;
+};
+
+/**
+ * Print generic declarations, and the instantiations of a named one
+ *
+ * Porcelain:
+ *   generic<TAB>repo_id<TAB>path<TAB>line<TAB>kind<TAB>name<TAB>type_parameters
+ *   instantiation<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>target<TAB>package<TAB>type_arguments<TAB>context
+ *     <TAB>inferred (1 when the type arguments are inferred from a call)
+ *
+ * @param declarations - Generic declarations
+ * @param instantiations - Instantiations of the named generic (none when listing every generic)
+ */
+const printGenerics = (declarations: GenericDeclaration[], instantiations: GoInstantiationRecord[]): void => {
+  if (isPorcelain()) {
+    for (const { first, parameters } of declarations) {
+      printRecord('generic', [
+        first.repo_id,
+        first.file_path,
+        first.line_number,
+        first.symbol_kind,
+        first.symbol_name,
+        parameters,
+      ]);
+    }
+    for (const instantiation of instantiations) {
+      printRecord('instantiation', [
+        instantiation.repo_id,
+        instantiation.file_path,
+        instantiation.line_number,
+        instantiation.column_number,
+        instantiation.target_name,
+        instantiation.target_package,
+        instantiation.type_arguments.join(', '),
+        instantiation.context_name,
+        instantiation.inferred ? 1 : 0,
+      ]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const { first, parameters } of declarations) {
+    const kind = theme.kind(first.symbol_kind.padEnd(8));
+    const location = theme.path(`${first.file_path}:${String(first.line_number)}`);
+    print(`${kind} ${first.symbol_name}${theme.dim(`[${parameters}]`)}  ${location}`);
+  }
+  if (instantiations.length === 0) return;
+
+  print();
+  for (const instantiation of instantiations) {
+    const { file_path, line_number, column_number } = instantiation;
+    const location = `${file_path}:${String(line_number)}:${String(column_number)}`;
+    let line = `${theme.path(location)}  ${theme.kind(instantiationLabel(instantiation))}`;
+    if (instantiation.inferred) line += `  ${theme.dim('(inferred)')}`;
+    if (instantiation.context_name) line += `  ${theme.dim(`in ${instantiation.context_name}`)}`;
+    print(line);
+  }
+};
+
+/**
+ * Generics command - type parameters of Go generics and their instantiation sites
+ */
+export const genericsCommand: CliCommand = {
+  name: 'generics',
+  description: 'List Go generics with their type parameters, or where one is instantiated',
+  usage: 'cindex generics [<function|type>] [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const [name] = positionals;
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const { parameters, instantiations } = await readIndex(repoId, async () => ({
+        parameters: await listGoTypeParameters(db.getPool(), name, repoId),
+        instantiations: name ? await listGoInstantiations(db.getPool(), name, repoId) : [],
+      }));
+      if (parameters.length === 0) {
+        if (!isPorcelain()) print(name ? `No generic function or type named ${name}` : 'No generics indexed');
+        return ExitCode.NoResults;
+      }
+
+      const declarations = declarationsOf(parameters);
+      printGenerics(declarations, instantiations);
+      if (!isPorcelain()) {
+        const inferred = instantiations.filter((instantiation) => instantiation.inferred).length;
+        print();
+        print(
+          name
+            ? `${String(instantiations.length)} instantiations (${String(inferred)} inferred)`
+            : `${String(declarations.length)} generics`
+        );
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,201 @@
+/**
+ * CLI command: graph
+ * Package dependency graph of the index (see @cli/package-graph)
+ *
+ *   cindex graph                         packages and the packages they import
+ *   cindex graph --dot | dot -Tsvg > packages.svg
+ *   cindex graph --dependents auth       what imports package auth (--transitive: and what imports those)
+ *   cindex graph --cycles                import cycles; exits 5 when there are any
+ *
+ * Packages are directories. Without a selected index or --repo-id, the graph
+ * spans every index, and --deps adds the Go modules indexed with cindex deps,
+ * so cycles across module boundaries show up too.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { buildDependencyGraph, type DependencyGraph } from '@cli/package-graph';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listFileImports } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Print every package with its imports
+ *
+ * Porcelain: package<TAB>id<TAB>repo_id<TAB>path<TAB>files<TAB>external (1 or 0), then import<TAB>from<TAB>to
+ */
+const printGraph = (graph: DependencyGraph): void => {
+  const packages = graph.packages();
+  if (isPorcelain()) {
+    for (const node of packages) {
+      printRecord('package', [node.id, node.repo_id, node.path, node.files, node.external ? 1 : 0]);
+    }
+    for (const node of packages) {
+      for (const target of graph.dependencies(node.id)) printRecord('import', [node.id, target]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  let imports = 0;
+  for (const node of packages.filter((candidate) => !candidate.external)) {
+    const files = `${String(node.files)} ${node.files === 1 ? 'file' : 'files'}`;
+    print(`${theme.path(node.id)}  ${theme.dim(`(${files})`)}`);
+    for (const target of graph.dependencies(node.id)) {
+      print(`  -> ${target}`);
+      imports++;
+    }
+  }
+  print();
+  print(`${String(packages.filter((node) => !node.external).length)} packages, ${String(imports)} imports`);
+};
+
+/**
+ * Print import cycles
+ *
+ * Porcelain: cycle<TAB>packages<TAB>cross_module (1 or 0)<TAB>path (comma-separated, in import order)
+ *
+ * @returns Number of cycles
+ */
+const printCycles = (graph: DependencyGraph): number => {
+  const cycles = graph.cycles();
+  if (isPorcelain()) {
+    for (const cycle of cycles) {
+      printRecord('cycle', [cycle.packages.length, cycle.cross_module ? 1 : 0, cycle.path.join(',')]);
+    }
+    return cycles.length;
+  }
+
+  const theme = getTheme();
+  if (cycles.length === 0) {
+    print('No import cycles');
+    return 0;
+  }
+  for (const cycle of cycles) {
+    const across = cycle.cross_module ? ', across indexes' : '';
+    print(theme.kind(`Cycle of ${String(cycle.packages.length)} packages${across}`));
+    print(`  ${[...cycle.path, cycle.path[0]].join(' -> ')}`);
+  }
+  print();
+  print(`${String(cycles.length)} import ${cycles.length === 1 ? 'cycle' : 'cycles'}`);
+  return cycles.length;
+};
+
+/**
+ * Print the packages importing each matched package
+ *
+ * Porcelain: dependent<TAB>package<TAB>importer
+ *
+ * @returns Number of importers listed
+ */
+const printDependents = (graph: DependencyGraph, matches: string[], transitive: boolean): number => {
+  const theme = getTheme();
+  let listed = 0;
+  for (const id of matches) {
+    const dependents = graph.dependents(id, transitive);
+    listed += dependents.length;
+    if (isPorcelain()) {
+      for (const importer of dependents) printRecord('dependent', [id, importer]);
+      continue;
+    }
+    if (matches.length > 1) print(theme.path(id));
+    if (dependents.length === 0) print(`${matches.length > 1 ? '  ' : ''}Nothing imports ${id}`);
+    for (const importer of dependents) print(`${matches.length > 1 ? '  ' : ''}${importer}`);
+  }
+  return listed;
+};
+
+/**
+ * Graph command - package imports, reverse dependencies, and cycles
+ */
+export const graphCommand: CliCommand = {
+  name: 'graph',
+  description: 'Show the package dependency graph, what imports a package, or import cycles',
+  usage:
+    'cindex graph [--dot | --json] [--dependents <package> [--transitive]] [--cycles] [--external] [--deps] ' +
+    '[--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'dot', description: 'Write the graph in Graphviz DOT' },
+    { name: 'json', description: 'Write packages, imports, and cycles as JSON' },
+    {
+      name: 'dependents',
+      description: 'List the packages importing a package (auth, internal/auth)',
+      takesValue: true,
+    },
+    { name: 'transitive', description: 'With --dependents, also list indirect importers' },
+    { name: 'cycles', description: 'List import cycles (exit 5 when there are any)' },
+    { name: 'external', description: 'Include imported packages outside the index' },
+    { name: 'deps', description: 'Include the Go modules indexed with cindex deps' },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        'repo-id': { type: 'string' },
+        dot: { type: 'boolean', default: false },
+        json: { type: 'boolean', default: false },
+        dependents: { type: 'string' },
+        transitive: { type: 'boolean', default: false },
+        cycles: { type: 'boolean', default: false },
+        external: { type: 'boolean', default: false },
+        deps: { type: 'boolean', default: false },
+      },
+    });
+
+    if (values.dot && values.json) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Pass either --dot or --json' });
+    }
+    if (values.dependents !== undefined && values.cycles) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Pass either --dependents or --cycles' });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const files = await readIndex(repoId, () => listFileImports(db.getPool(), { repoId, dependencies: values.deps }));
+      if (files.length === 0) {
+        if (!isPorcelain()) print(repoId ? `No files indexed in '${repoId}'` : 'No files indexed');
+        return ExitCode.NoResults;
+      }
+      const graph = buildDependencyGraph(files, { external: values.external });
+
+      if (values.dependents !== undefined) {
+        const matches = graph.find(values.dependents);
+        if (matches.length === 0) {
+          if (!isPorcelain()) print(`No package matches ${values.dependents}`);
+          return ExitCode.NoResults;
+        }
+        if (values.json) {
+          const report = matches.map((id) => ({ package: id, dependents: graph.dependents(id, values.transitive) }));
+          process.stdout.write(JSON.stringify(report, null, 2) + '\n');
+          return report.some((entry) => entry.dependents.length > 0) ? ExitCode.Success : ExitCode.NoResults;
+        }
+        return printDependents(graph, matches, values.transitive) > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      if (values.cycles) {
+        if (values.json) {
+          const cycles = graph.cycles();
+          process.stdout.write(JSON.stringify(cycles, null, 2) + '\n');
+          return cycles.length > 0 ? ExitCode.PolicyViolation : ExitCode.Success;
+        }
+        return printCycles(graph) > 0 ? ExitCode.PolicyViolation : ExitCode.Success;
+      }
+
+      if (values.dot) {
+        process.stdout.write(graph.toDot());
+      } else if (values.json) {
+        process.stdout.write(JSON.stringify(graph.toJSON(), null, 2) + '\n');
+      } else {
+        printGraph(graph);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,209 @@
+/**
+ * CLI command: grep
+ * Regular expression search over the contents of indexed files
+ *
+ *   cindex grep 'func \(s \*AuthService\)'     lines matching a pattern
+ *   cindex grep -i todo --path src/auth         case-insensitive, under a directory
+ *   cindex grep -F 'user.Role ==' -l            literal text, matching files only
+ *   cindex grep TODO --packed monorepo.cidx      a packed index, without a database
+ *
+ * Contents are searched as of the last index, through a trigram index (see
+ * @retrieval/content-search), so results cover every indexed file, not only
+ * chunks. Patterns are JavaScript regular expressions, matched per line.
+ * --packed searches a file written by cindex export --format packed in place
+ * (see @indexing/packed-index).
+ */
+import { parseArgs } from 'node:util';
+
+import { getPositionEncoding, isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { openPackedIndex, type PackedIndex } from '@indexing/packed-index';
+import {
+  compileContentPattern,
+  searchContent,
+  searchPackedContent,
+  type ContentMatch,
+} from '@retrieval/content-search';
+import { CindexError } from '@utils/errors';
+import { selectColumn } from '@utils/positions';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Matching lines listed when --limit is not given */
+const DEFAULT_LIMIT = 100;
+
+/**
+ * Print matching lines
+ *
+ * Porcelain: match<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>text
+ */
+const printMatches = (matches: ContentMatch[]): void => {
+  const encoding = getPositionEncoding();
+  const columnOf = (match: ContentMatch): number =>
+    selectColumn({ column: match.column, byte_column: match.byte_column }, encoding);
+
+  if (isPorcelain()) {
+    for (const match of matches) {
+      printRecord('match', [match.repo_id, match.file_path, match.line, columnOf(match), match.text]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const match of matches) {
+    const start = match.column - 1;
+    const text =
+      match.text.slice(0, start) +
+      theme.match(match.text.slice(start, start + match.length)) +
+      match.text.slice(start + match.length);
+    const position = theme.line(`${String(match.line)}:${String(columnOf(match))}`);
+    print(`${theme.path(match.file_path)}:${position}: ${text.trim()}`);
+  }
+};
+
+/**
+ * Print the files holding matches
+ *
+ * Porcelain: file<TAB>repo_id<TAB>path<TAB>matches
+ */
+const printFiles = (matches: ContentMatch[]): void => {
+  const files = new Map<string, { repo_id: string | null; count: number }>();
+  for (const match of matches) {
+    const file = files.get(match.file_path) ?? { repo_id: match.repo_id, count: 0 };
+    file.count++;
+    files.set(match.file_path, file);
+  }
+
+  const theme = getTheme();
+  for (const [filePath, { repo_id, count }] of files) {
+    if (isPorcelain()) printRecord('file', [repo_id, filePath, count]);
+    else print(`${theme.path(filePath)}  ${theme.dim(`(${String(count)})`)}`);
+  }
+};
+
+/**
+ * Grep command - search indexed file contents
+ */
+export const grepCommand: CliCommand = {
+  name: 'grep',
+  description: 'Search the contents of indexed files with a regular expression',
+  usage: 'cindex grep <pattern> [-i] [-F] [-l] [--path <prefix>] [--limit <n>] [--repo-id <name> | --packed <file>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'ignore-case', description: 'Match letters in either case (-i)' },
+    { name: 'fixed-strings', description: 'Match the pattern as literal text (-F)' },
+    { name: 'files-with-matches', description: 'List matching files instead of lines (-l)' },
+    { name: 'path', description: 'Only files under a path of the repository', takesValue: true },
+    { name: 'limit', description: `Most matching lines (default: ${String(DEFAULT_LIMIT)})`, takesValue: true },
+    {
+      name: 'packed',
+      description: 'Search a packed index file (cindex export --format packed) instead of the database',
+      takesValue: true,
+      complete: 'path',
+    },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        'ignore-case': { type: 'boolean', short: 'i', default: false },
+        'fixed-strings': { type: 'boolean', short: 'F', default: false },
+        'files-with-matches': { type: 'boolean', short: 'l', default: false },
+        path: { type: 'string' },
+        limit: { type: 'string' },
+        packed: { type: 'string' },
+      },
+    });
+
+    const [pattern] = positionals;
+    if (!pattern) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing pattern',
+        hint: "Usage: cindex grep <pattern>, e.g. cindex grep 'TODO|FIXME'",
+      });
+    }
+    const limit = values.limit !== undefined ? Number(values.limit) : DEFAULT_LIMIT;
+    if (!Number.isInteger(limit) || limit < 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --limit value: ${values.limit ?? ''}`,
+        hint: 'Expected a number of lines, e.g. --limit 500',
+      });
+    }
+    const options = {
+      ignoreCase: values['ignore-case'],
+      fixedStrings: values['fixed-strings'],
+      pathPrefix: values.path?.replace(/^\.\//, ''),
+      limit,
+    };
+    try {
+      compileContentPattern(pattern, options);
+    } catch (error) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: error instanceof Error ? error.message : String(error),
+        hint: 'Patterns are regular expressions; pass -F to match literal text',
+      });
+    }
+
+    const report = (matches: ContentMatch[], truncated: boolean): ExitCode => {
+      if (matches.length === 0) {
+        if (!isPorcelain()) print(`No lines match ${pattern}`);
+        return ExitCode.NoResults;
+      }
+
+      if (values['files-with-matches']) printFiles(matches);
+      else printMatches(matches);
+      if (!isPorcelain()) {
+        const files = new Set(matches.map((match) => match.file_path)).size;
+        const more = truncated ? ` (first ${String(limit)}; raise --limit for more)` : '';
+        print();
+        print(`${String(matches.length)} matching lines in ${String(files)} files${more}`);
+      }
+      return ExitCode.Success;
+    };
+
+    if (values.packed !== undefined) {
+      if (values['repo-id'] !== undefined) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: '--packed searches the index in the file: it takes no --repo-id',
+        });
+      }
+      let index: PackedIndex;
+      try {
+        index = await openPackedIndex(values.packed);
+      } catch (error) {
+        const message = error instanceof Error ? error.message : String(error);
+        return reportError(ExitCode.Failure, {
+          code: error instanceof CindexError ? error.code : 'READ_ERROR',
+          message: `Cannot open ${values.packed}: ${message}`,
+          file: values.packed,
+          hint: error instanceof CindexError ? error.suggestion : undefined,
+        });
+      }
+      try {
+        const { matches, truncated } = await searchPackedContent(index, pattern, options);
+        return report(matches, truncated);
+      } finally {
+        await index.close();
+      }
+    }
+
+    const repoId = resolveRepoId(values['repo-id']);
+    const { db } = await openSession();
+    try {
+      const { matches, truncated } = await readIndex(repoId, () =>
+        searchContent(db.getPool(), pattern, { ...options, repoId })
+      );
+      return report(matches, truncated);
+    } finally {
+      await db.close();
+    }
+  },
+};
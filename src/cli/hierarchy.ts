@@ -0,0 +1,193 @@
+/**
+ * CLI command: hierarchy
+ * Tree of the Go types a struct or interface embeds, and of those embedding it (see @indexing/go-embeddings)
+ *
+ *   cindex hierarchy AdminService        Embeds: *AuthService, sync.Mutex; Embedded by: SuperAdmin
+ *   cindex hierarchy auth.Store --down   only the types embedding auth.Store
+ *   cindex hierarchy Reader --depth 1    direct embeddings only
+ *
+ * Struct embedding promotes fields and methods; interface embedding adds the
+ * embedded method set. Both are followed through every level, each type
+ * expanded once.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoSubtypes, listGoSupertypes } from '@database/queries';
+import { defaultImportName } from '@indexing/go-calls';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoEmbeddingRecord } from '@/types/database';
+
+/**
+ * Embedding in a tree, under the one it was reached through
+ */
+export interface HierarchyNode {
+  embedding: GoEmbeddingRecord;
+  /** 1 under the root */
+  level: number;
+  /** Expanded earlier in the tree, so its subtree is not repeated */
+  repeated: boolean;
+}
+
+/**
+ * Type an embedding leads to, as package.Name within its index
+ */
+const keyOf = (repoId: string | null, packageName: string, name: string): string =>
+  `${repoId ?? ''}\0${packageName}.${name}`;
+
+/**
+ * Lay out embeddings as a tree, depth first
+ *
+ * @param embeddings - Embeddings of one direction (see listGoSupertypes, listGoSubtypes)
+ * @param direction - up: from a type to those it embeds; down: to those embedding it
+ * @returns Nodes in display order
+ */
+export const buildHierarchy = (embeddings: GoEmbeddingRecord[], direction: 'up' | 'down'): HierarchyNode[] => {
+  const from = (embedding: GoEmbeddingRecord): string =>
+    direction === 'up'
+      ? keyOf(embedding.repo_id, embedding.type_package, embedding.type_name)
+      : keyOf(embedding.repo_id, embedding.embedded_package_name, embedding.embedded_name);
+  const to = (embedding: GoEmbeddingRecord): string =>
+    direction === 'up'
+      ? keyOf(embedding.repo_id, embedding.embedded_package_name, embedding.embedded_name)
+      : keyOf(embedding.repo_id, embedding.type_package, embedding.type_name);
+
+  const children = new Map<string, GoEmbeddingRecord[]>();
+  for (const embedding of embeddings) {
+    if (embedding.depth > 1) children.set(from(embedding), [...(children.get(from(embedding)) ?? []), embedding]);
+  }
+
+  const nodes: HierarchyNode[] = [];
+  const expanded = new Set<string>();
+  const visit = (embedding: GoEmbeddingRecord, level: number): void => {
+    const key = to(embedding);
+    const repeated = expanded.has(key) && (children.get(key) ?? []).length > 0;
+    nodes.push({ embedding, level, repeated });
+    if (expanded.has(key)) return;
+    expanded.add(key);
+    for (const child of children.get(key) ?? []) visit(child, level + 1);
+  };
+  for (const embedding of embeddings.filter((candidate) => candidate.depth === 1)) visit(embedding, 1);
+  return nodes;
+};
+
+/**
+ * Embedded type as written: *AuthService, sync.Mutex
+ */
+const embeddedLabel = (embedding: GoEmbeddingRecord): string => {
+  const qualifier = embedding.embedded_package ? `${defaultImportName(embedding.embedded_package)}.` : '';
+  return `${embedding.pointer ? '*' : ''}${qualifier}${embedding.embedded_name}`;
+};
+
+/**
+ * Print one direction of the hierarchy
+ *
+ * Porcelain:
+ *   embedding<TAB>direction<TAB>depth<TAB>repo_id<TAB>path<TAB>line<TAB>type<TAB>kind<TAB>embedded<TAB>package
+ *     <TAB>pointer (1 when embedded as *T)
+ */
+const printHierarchy = (title: string, embeddings: GoEmbeddingRecord[], direction: 'up' | 'down'): void => {
+  if (isPorcelain()) {
+    for (const embedding of embeddings) {
+      printRecord('embedding', [
+        direction,
+        embedding.depth,
+        embedding.repo_id,
+        embedding.file_path,
+        embedding.line_number,
+        embedding.type_name,
+        embedding.type_kind,
+        embedding.embedded_name,
+        embedding.embedded_package,
+        embedding.pointer ? 1 : 0,
+      ]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  print(title);
+  if (embeddings.length === 0) {
+    print(theme.dim('  (none)'));
+    return;
+  }
+  for (const { embedding, level, repeated } of buildHierarchy(embeddings, direction)) {
+    const label = direction === 'up' ? embeddedLabel(embedding) : embedding.type_name;
+    const kind = direction === 'down' ? `  ${theme.kind(embedding.type_kind)}` : '';
+    const location = theme.path(`${embedding.file_path}:${String(embedding.line_number)}`);
+    print(`${'  '.repeat(level)}${label}${kind}  ${location}${repeated ? `  ${theme.dim('(see above)')}` : ''}`);
+  }
+};
+
+/**
+ * Hierarchy command - Go struct and interface embedding, up and down
+ */
+export const hierarchyCommand: CliCommand = {
+  name: 'hierarchy',
+  description: 'Show the Go types a struct or interface embeds and the types embedding it',
+  usage: 'cindex hierarchy <type> [--up | --down] [--depth <n>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'up', description: 'Only the types it embeds (supertypes)' },
+    { name: 'down', description: 'Only the types embedding it (subtypes)' },
+    { name: 'depth', description: 'Most levels of embedding shown (default: all)', takesValue: true },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        up: { type: 'boolean', default: false },
+        down: { type: 'boolean', default: false },
+        depth: { type: 'string' },
+      },
+    });
+
+    const [type] = positionals;
+    if (!type) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing type',
+        hint: 'Usage: cindex hierarchy <type>, e.g. cindex hierarchy AdminService',
+      });
+    }
+    const depth = values.depth !== undefined ? Number(values.depth) : Infinity;
+    if (values.depth !== undefined && (!Number.isInteger(depth) || depth < 1)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --depth value: ${values.depth}`,
+        hint: 'Expected a number of levels, e.g. --depth 1',
+      });
+    }
+    // Neither flag shows both directions
+    const up = values.up || !values.down;
+    const down = values.down || !values.up;
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const { supertypes, subtypes } = await readIndex(repoId, async () => ({
+        supertypes: up ? await listGoSupertypes(pool, type, repoId) : [],
+        subtypes: down ? await listGoSubtypes(pool, type, repoId) : [],
+      }));
+      const shown = (embedding: GoEmbeddingRecord): boolean => embedding.depth <= depth;
+      if (supertypes.length === 0 && subtypes.length === 0) {
+        if (!isPorcelain()) print(`No embeddings of or in a Go type named ${type}`);
+        return ExitCode.NoResults;
+      }
+
+      if (up) printHierarchy(`${getTheme().kind(type)} embeds:`, supertypes.filter(shown), 'up');
+      if (up && down && !isPorcelain()) print();
+      if (down) printHierarchy(`${getTheme().kind(type)} is embedded by:`, subtypes.filter(shown), 'down');
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,102 @@
+/**
+ * Histograms of index composition (cindex stats --index)
+ *
+ * Distributions are bucketed on powers of two (0, 1, 2-3, 4-7, ...), which
+ * keeps long-tailed metrics such as file length readable in a dozen rows and
+ * makes the same buckets comparable across repositories of any size.
+ */
+import { type IndexComposition, type ValueFrequency } from '@/types/database';
+
+/**
+ * One histogram bucket (inclusive bounds)
+ */
+export interface HistogramBucket {
+  label: string;
+  min: number;
+  max: number;
+  count: number;
+}
+
+/**
+ * Histograms of one index, as printed and exported with --json
+ */
+export interface IndexHistograms {
+  files: number;
+  symbols: number;
+  symbols_per_file: HistogramBucket[];
+  function_complexity: HistogramBucket[];
+  file_lines: HistogramBucket[];
+  languages: { language: string; files: number; lines: number; share: number }[];
+}
+
+/** Width of the longest bar in a chart */
+export const CHART_WIDTH = 40;
+
+/**
+ * Lower bound of the power-of-two bucket holding a value (0 and 1 are buckets of their own)
+ */
+const bucketStart = (value: number): number => (value < 2 ? Math.max(value, 0) : 2 ** Math.floor(Math.log2(value)));
+
+/**
+ * Group value frequencies into power-of-two buckets
+ *
+ * Buckets between the smallest and largest value are all present, empty ones
+ * included, so gaps in the distribution show up in the chart.
+ *
+ * @param frequencies - Items per value
+ * @returns Buckets in ascending order
+ */
+export const bucketize = (frequencies: ValueFrequency[]): HistogramBucket[] => {
+  const counts = new Map<number, number>();
+  for (const { value, count } of frequencies) {
+    const start = bucketStart(value);
+    counts.set(start, (counts.get(start) ?? 0) + count);
+  }
+  if (counts.size === 0) return [];
+
+  const buckets: HistogramBucket[] = [];
+  const last = Math.max(...counts.keys());
+  for (let min = Math.min(...counts.keys()); min <= last; min = min < 2 ? min + 1 : min * 2) {
+    const max = min < 2 ? min : min * 2 - 1;
+    const label = min === max ? String(min) : `${String(min)}-${String(max)}`;
+    buckets.push({ label, min, max, count: counts.get(min) ?? 0 });
+  }
+  return buckets;
+};
+
+/**
+ * Build the histograms of an index
+ *
+ * @param composition - Raw distributions from the index
+ * @returns Bucketed distributions and language share (fraction of files)
+ */
+export const buildHistograms = (composition: IndexComposition): IndexHistograms => {
+  return {
+    files: composition.files,
+    symbols: composition.symbols,
+    symbols_per_file: bucketize(composition.symbols_per_file),
+    function_complexity: bucketize(composition.function_complexity),
+    file_lines: bucketize(composition.file_lines),
+    languages: composition.languages.map((row) => ({
+      ...row,
+      share: composition.files > 0 ? row.files / composition.files : 0,
+    })),
+  };
+};
+
+/**
+ * Render labeled counts as an ASCII bar chart
+ *
+ * @param rows - Label and count per bar
+ * @param width - Length of the longest bar
+ * @returns One line per row: label, bar, count
+ */
+export const renderBarChart = (rows: { label: string; count: number }[], width = CHART_WIDTH): string[] => {
+  const peak = Math.max(0, ...rows.map((row) => row.count));
+  const labelWidth = Math.max(0, ...rows.map((row) => row.label.length));
+  return rows.map((row) => {
+    // Non-empty buckets always get at least one mark
+    const length = peak > 0 ? Math.max(Math.round((row.count / peak) * width), row.count > 0 ? 1 : 0) : 0;
+    return `${row.label.padStart(labelWidth)} | ${'#'.repeat(length).padEnd(width)} ${String(row.count)}`;
+  });
+};
@@ -0,0 +1,246 @@
+/**
+ * Rename impact: the places a rename has to touch (cindex rename-impact)
+ *
+ * Uses of the declaration come from typed indexing, which resolves them
+ * exactly. What the type checker cannot see is found by text: the name in
+ * string literals (struct tags, SQL, JSON keys), comments, documents, and
+ * files of other languages. Literals are matched in the spellings
+ * serializers and databases use (role, user_id, user-id, USER_ID), so the
+ * list errs towards too much: it is a checklist, not a refactoring. Given
+ * the new name, each place carries its line as the rename would leave it.
+ */
+import { compareStrings } from '@utils/ordering';
+import { byteToUtf16Column, utf16ToByteColumn } from '@utils/positions';
+import { IDENTIFIER_CHAR_PATTERN, identifierWords } from '@utils/unicode';
+
+/**
+ * Why a place is listed
+ */
+export type ImpactCategory = 'declaration' | 'reference' | 'test' | 'tag' | 'string' | 'comment' | 'doc' | 'text';
+
+/** Categories in report order, with their headings */
+export const IMPACT_CATEGORIES: [ImpactCategory, string][] = [
+  ['declaration', 'Declarations'],
+  ['reference', 'References'],
+  ['test', 'Tests'],
+  ['tag', 'Struct tags'],
+  ['string', 'String literals'],
+  ['comment', 'Comments'],
+  ['doc', 'Documents'],
+  ['text', 'Other files'],
+];
+
+/**
+ * One place to touch
+ */
+export interface ImpactEntry {
+  category: ImpactCategory;
+  file_path: string;
+  line: number;
+  /** 1-based byte column of the match (null when only the line is known) */
+  column: number | null;
+  /** Source line, trimmed */
+  text: string;
+  /** Enclosing symbol of a reference */
+  symbol?: string | null;
+  /** Line after the rename, trimmed (see previewRename) */
+  replacement?: string;
+}
+
+/** Categories of places the type checker resolved */
+const CODE_CATEGORIES = new Set<ImpactCategory>(['declaration', 'reference', 'test']);
+
+/**
+ * Check whether a place was resolved by the type checker (not found by text)
+ */
+export const isCodeImpact = (entry: ImpactEntry): boolean => CODE_CATEGORIES.has(entry.category);
+
+/** Extensions of files whose mentions are listed as documents */
+const DOC_EXTENSIONS = ['.md', '.markdown', '.mdx', '.rst', '.adoc', '.txt'];
+
+/** Raw string holding only key:"value" pairs */
+const STRUCT_TAG = /^`\s*[\w.-]+:"[^"]*"(?:\s+[\w.-]+:"[^"]*")*\s*`$/;
+
+/**
+ * Check whether a file's mentions are listed as documents
+ */
+export const isDocumentFile = (filePath: string): boolean => {
+  const lower = filePath.toLowerCase();
+  return DOC_EXTENSIONS.some((extension) => lower.endsWith(extension));
+};
+
+/**
+ * Spellings of a name in the order of nameSpellings, duplicates kept (so two names' lists line up)
+ */
+const spellingsOf = (name: string): string[] => {
+  const found = identifierWords(name);
+  const words = found.length > 0 ? found : [name];
+  const lower = words.map((word) => word.toLowerCase());
+  // lowerCamel keeps initialisms after the first word: userID
+  const camel = lower[0] + words.slice(1).join('');
+  return [name, camel, lower.join('_'), lower.join('-'), lower.join('_').toUpperCase()];
+};
+
+/**
+ * Spellings of a Go name in literals and other languages
+ *
+ * @param name - Identifier (the last element of a qualified name)
+ * @returns The name, lowerCamel, snake_case, kebab-case, and SCREAMING_SNAKE spellings
+ */
+export const nameSpellings = (name: string): string[] => [...new Set(spellingsOf(name))];
+
+/**
+ * String literal or comment of Go source
+ */
+interface GoSegment {
+  kind: 'string' | 'comment';
+  start: number;
+  end: number;
+}
+
+/**
+ * Find the string literals and comments of Go source
+ *
+ * @param code - Go source
+ * @returns Segments in source order, with their text offsets (end exclusive)
+ */
+export const segmentGoSource = (code: string): GoSegment[] => {
+  const segments: GoSegment[] = [];
+  const after = (needle: string, from: number): number => {
+    const index = code.indexOf(needle, from);
+    return index === -1 ? code.length : index + needle.length;
+  };
+
+  for (let i = 0; i < code.length; ) {
+    const char = code[i];
+    let end = i + 1;
+    if (char === '/' && code[i + 1] === '/') {
+      end = after('\n', i);
+      segments.push({ kind: 'comment', start: i, end });
+    } else if (char === '/' && code[i + 1] === '*') {
+      end = after('*/', i + 2);
+      segments.push({ kind: 'comment', start: i, end });
+    } else if (char === '`') {
+      end = after('`', i + 1);
+      segments.push({ kind: 'string', start: i, end });
+    } else if (char === '"' || char === "'") {
+      // Interpreted strings and runes end at their quote or the line; runes are skipped
+      while (end < code.length && code[end] !== char && code[end] !== '\n') end += code[end] === '\\' ? 2 : 1;
+      end = Math.min(end + 1, code.length);
+      if (char === '"') segments.push({ kind: 'string', start: i, end });
+    }
+    i = end;
+  }
+  return segments;
+};
+
+/**
+ * Whole-word pattern matching any of the spellings
+ */
+const wordPattern = (spellings: string[]): RegExp => {
+  const alternatives = spellings.map((spelling) => spelling.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')).join('|');
+  return new RegExp(`(?<!${IDENTIFIER_CHAR_PATTERN})(?:${alternatives})(?!${IDENTIFIER_CHAR_PATTERN})`, 'gu');
+};
+
+/**
+ * Find the mentions of a name a type checker cannot resolve
+ *
+ * Go files are searched in their string literals (any spelling) and comments
+ * (the name as written); documents for the name as written; other files for
+ * any spelling.
+ *
+ * @param filePath - File path relative to the repository root
+ * @param content - File content
+ * @param name - Identifier being renamed
+ * @returns Mentions, one per line and category, in line order
+ */
+export const scanMentions = (filePath: string, content: string, name: string): ImpactEntry[] => {
+  const lineStarts = [0];
+  for (let i = content.indexOf('\n'); i !== -1; i = content.indexOf('\n', i + 1)) lineStarts.push(i + 1);
+  const lines = content.split('\n');
+
+  const entries: ImpactEntry[] = [];
+  const seen = new Set<string>();
+  const add = (category: ImpactCategory, offset: number): void => {
+    let line = lineStarts.length - 1;
+    while (lineStarts[line] > offset) line--;
+    if (seen.has(`${category}:${String(line)}`)) return;
+    seen.add(`${category}:${String(line)}`);
+    const lineText = lines[line].replace(/\r$/, '');
+    entries.push({
+      category,
+      file_path: filePath,
+      line: line + 1,
+      column: utf16ToByteColumn(lineText, offset - lineStarts[line] + 1),
+      text: lineText.trim(),
+    });
+  };
+  const matchAll = (pattern: RegExp, from: number, to: number, category: ImpactCategory): void => {
+    for (const match of content.slice(from, to).matchAll(pattern)) add(category, from + match.index);
+  };
+
+  const spelled = wordPattern(nameSpellings(name));
+  const written = wordPattern([name]);
+  if (filePath.endsWith('.go')) {
+    for (const segment of segmentGoSource(content)) {
+      if (segment.kind === 'comment') {
+        matchAll(written, segment.start, segment.end, 'comment');
+      } else {
+        const literal = content.slice(segment.start, segment.end);
+        matchAll(spelled, segment.start, segment.end, STRUCT_TAG.test(literal) ? 'tag' : 'string');
+      }
+    }
+  } else if (isDocumentFile(filePath)) {
+    matchAll(written, 0, content.length, 'doc');
+  } else {
+    matchAll(spelled, 0, content.length, 'text');
+  }
+  return entries.sort((a, b) => a.line - b.line);
+};
+
+/**
+ * Rewrite a line of a place as renaming a name would leave it
+ *
+ * Code places replace the identifier at their column (the first whole-word
+ * occurrence for declarations); comments and documents replace the name as
+ * written, literals and other files each spelling by the new name's
+ * spelling (user_id to account_id).
+ *
+ * @param entry - Place to rewrite
+ * @param lineText - Untrimmed source line of the place
+ * @param name - Identifier being renamed
+ * @param newName - Identifier it is renamed to
+ * @returns The rewritten line, trimmed
+ */
+export const previewRename = (entry: ImpactEntry, lineText: string, name: string, newName: string): string => {
+  if (CODE_CATEGORIES.has(entry.category)) {
+    const start =
+      entry.column !== null
+        ? byteToUtf16Column(lineText, entry.column) - 1
+        : (wordPattern([name]).exec(lineText)?.index ?? -1);
+    if (start < 0 || !lineText.startsWith(name, start)) return lineText.trim();
+    return (lineText.slice(0, start) + newName + lineText.slice(start + name.length)).trim();
+  }
+
+  const asWritten = entry.category === 'comment' || entry.category === 'doc';
+  const from = asWritten ? [name] : spellingsOf(name);
+  const to = asWritten ? [newName] : spellingsOf(newName);
+  const renamed = new Map<string, string>();
+  from.forEach((spelling, index) => {
+    if (!renamed.has(spelling)) renamed.set(spelling, to[index]);
+  });
+  return lineText.replace(wordPattern([...renamed.keys()]), (match) => renamed.get(match) ?? match).trim();
+};
+
+/**
+ * Order entries by category, then file and line
+ */
+export const sortImpact = (entries: ImpactEntry[]): ImpactEntry[] => {
+  const rank = new Map(IMPACT_CATEGORIES.map(([category], index) => [category, index]));
+  return [...entries].sort(
+    (a, b) =>
+      (rank.get(a.category) ?? 0) - (rank.get(b.category) ?? 0) ||
+      compareStrings(a.file_path, b.file_path) ||
+      a.line - b.line
+  );
+};
@@ -0,0 +1,175 @@
+/**
+ * CLI commands: implementations, satisfies
+ * Jump between Go interfaces and the types that satisfy them
+ *
+ *   cindex implementations PermissionChecker   types satisfying an interface
+ *   cindex implementations io.Reader           an interface of another package
+ *   cindex satisfies store.Memory              interfaces a type satisfies
+ *
+ * Facts come from `cindex index --typed`: a named type of the repository
+ * satisfies the interfaces of the repository's packages, their imports, and
+ * error whose method sets it has, with a value or pointer receiver.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { parseInterfaceFilter } from '@cli/query-filter';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { hasGoReferences, listGoImplementations, listGoSatisfiedInterfaces } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoImplementationRecord } from '@/types/database';
+
+/** Message when no index was built with --typed */
+const NOT_TYPED = 'No type facts recorded (index a Go repository with: cindex index <path> --typed)';
+
+/**
+ * Interface as its package names it: store.Store, io.Reader, error
+ */
+const interfaceLabel = (row: GoImplementationRecord): string => {
+  const pkg = row.interface_package.split('/').at(-1);
+  return pkg ? `${pkg}.${row.interface_name}` : row.interface_name;
+};
+
+/**
+ * Print implementations
+ *
+ * Porcelain:
+ *   implementation<TAB>repo_id<TAB>path<TAB>line<TAB>type<TAB>type_package
+ *                 <TAB>interface<TAB>interface_package<TAB>pointer
+ *
+ * @param rows - Implementations to print
+ * @param show - Side of each implementation to name after the type's location
+ */
+const printImplementations = (rows: GoImplementationRecord[], show: 'type' | 'interface'): void => {
+  if (isPorcelain()) {
+    for (const row of rows) {
+      printRecord('implementation', [
+        row.repo_id,
+        row.file_path,
+        row.line_number,
+        row.type_name,
+        row.type_package,
+        row.interface_name,
+        row.interface_package,
+        row.pointer_receiver,
+      ]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const row of rows) {
+    const location = `${row.file_path}:${String(row.line_number)}`;
+    const type = `${row.pointer_receiver ? '*' : ''}${row.type_name}`;
+    const name = show === 'type' ? type : `${type} → ${interfaceLabel(row)}`;
+    const via = show === 'type' ? `  ${theme.dim(`(${interfaceLabel(row)})`)}` : '';
+    print(`${theme.path(location)}  ${theme.kind(name)}${via}`);
+  }
+};
+
+/**
+ * Implementations command - types satisfying a Go interface
+ */
+export const implementationsCommand: CliCommand = {
+  name: 'implementations',
+  description: 'List Go types that satisfy an interface (needs cindex index --typed)',
+  usage: 'cindex implementations <interface> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const [target] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing interface name',
+        hint: 'Usage: cindex implementations <interface>, e.g. cindex implementations io.Reader',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const { rows, typed } = await readIndex(repoId, async () => ({
+        rows: await listGoImplementations(pool, parseInterfaceFilter(target), repoId),
+        typed: await hasGoReferences(pool, repoId),
+      }));
+      if (rows.length === 0) {
+        if (!isPorcelain()) print(typed ? `No types satisfy ${target}` : NOT_TYPED);
+        return ExitCode.NoResults;
+      }
+
+      printImplementations(rows, 'type');
+      if (!isPorcelain()) {
+        const interfaces = new Set(rows.map((row) => `${row.interface_package}.${row.interface_name}`)).size;
+        const of = interfaces > 1 ? ` of ${String(interfaces)} interfaces` : '';
+        print();
+        print(`${String(rows.length)} implementations${of}`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
+
+/**
+ * Satisfies command - interfaces a Go type satisfies
+ */
+export const satisfiesCommand: CliCommand = {
+  name: 'satisfies',
+  description: 'List the interfaces a Go type satisfies (needs cindex index --typed)',
+  usage: 'cindex satisfies <type> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const [target] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing type name',
+        hint: 'Usage: cindex satisfies <type>, e.g. cindex satisfies store.Memory',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const { rows, typed } = await readIndex(repoId, async () => ({
+        rows: await listGoSatisfiedInterfaces(pool, parseInterfaceFilter(target), repoId),
+        typed: await hasGoReferences(pool, repoId),
+      }));
+      if (rows.length === 0) {
+        if (!isPorcelain()) print(typed ? `${target} satisfies no recorded interface` : NOT_TYPED);
+        return ExitCode.NoResults;
+      }
+
+      printImplementations(rows, 'interface');
+      if (!isPorcelain()) {
+        print();
+        print(`${String(rows.length)} interfaces`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,556 @@
+/**
+ * CLI command: index
+ * Index a repository from the command line, or preview with --dry-run
+ *
+ * --dry-run walks and parses files without contacting Ollama or writing to
+ * the database, and prints which files would be indexed or skipped and why.
+ *
+ * --stdin indexes piped content into an ephemeral index instead
+ * (see @indexing/ephemeral):
+ *
+ *   cat buffer.go | cindex index --stdin --name scratch
+ *   curl -s <gist> | cindex index --stdin --name gist --path handler.py
+ *
+ * --rev indexes the repository as of a commit, read from git into memory
+ * without a checkout, into its own index <repo>@<commit> (see @indexing/revisions):
+ *
+ *   cindex index . --rev v1.4.0
+ *
+ * A git URL or an archive is fetched and indexed without a local checkout,
+ * into its own index named after it (see @indexing/remote-sources):
+ *
+ *   cindex index https://github.com/org/repo@v1.2.3 --repo-id repo-v1
+ *   cindex index ./lib-2.0.tar.gz
+ *
+ * Run in a member module of a Go workspace, it indexes the directory of the
+ * go.work instead, every member with it (see @indexing/go-workspace);
+ * --module-only indexes the given directory alone.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { openSession } from '@cli/session';
+import { SINCE_OPTION } from '@cli/search';
+import { getTheme } from '@cli/theme';
+import { recordUsage } from '@cli/usage-stats';
+import { loadConfig } from '@config/env';
+import { parsePlatformList } from '@indexing/build-constraints';
+import { parseSince } from '@indexing/changed-files';
+import { dryRunIndexing } from '@indexing/dry-run';
+import { addDocument, documentPath, isValidEphemeralName, type AddDocumentResult } from '@indexing/ephemeral';
+import { summarizeUnreadablePaths, UNREADABLE_EXAMPLES } from '@indexing/file-walker';
+import { findGoWorkspaceRoot } from '@indexing/go-workspace';
+import { createPipeline } from '@indexing/pipeline';
+import { indexSource, parseSourceSpec, redactLocation, type SourceSpec } from '@indexing/remote-sources';
+import { indexRevision, resolveRevision, type ResolvedRevision } from '@indexing/revisions';
+import { ollamaEmbeddingModel } from '@utils/embedders';
+import { initLogger } from '@utils/logger';
+import { createOllamaClient } from '@utils/ollama';
+import { normalizeRootPath } from '@utils/paths';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import {
+  IndexingStage,
+  SYMLINK_POLICIES,
+  type DryRunFile,
+  type DryRunReport,
+  type IndexingOptions,
+  type IndexingStats,
+  type SkipReason,
+  type SymlinkPolicy,
+} from '@/types/indexing';
+
+/**
+ * Parser label for a dry-run file
+ */
+const parserLabel = (file: DryRunFile): string => {
+  if (file.strategy === 'structure-only') return 'structure-only';
+  if (file.partial) return 'partial';
+  return file.used_fallback ? 'fallback' : 'tree-sitter';
+};
+
+/**
+ * Print a dry-run report: one line per file, then totals by skip reason
+ *
+ * Porcelain:
+ *   index<TAB>path<TAB>language<TAB>lines<TAB>parser<TAB>encoding<TAB>generated (`generated` or empty)
+ *   skip<TAB>path<TAB>reason<TAB>detail
+ */
+const printDryRun = (report: DryRunReport): void => {
+  if (isPorcelain()) {
+    for (const file of report.included) {
+      const { relative_path, language, line_count, encoding } = file;
+      const generated = file.generated ? 'generated' : '';
+      printRecord('index', [relative_path, language, line_count, parserLabel(file), encoding, generated]);
+    }
+    for (const file of report.skipped) {
+      printRecord('skip', [file.relative_path, file.reason, file.detail]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const file of report.included) {
+    const encoding = file.encoding === 'utf-8' ? '' : `, ${file.encoding}`;
+    const generated = file.generated ? ', generated' : '';
+    const detail = `(${file.language}, ${String(file.line_count)} lines, ${parserLabel(file)}${encoding}${generated})`;
+    print(`index  ${theme.path(file.relative_path)}  ${theme.dim(detail)}`);
+  }
+  for (const file of report.skipped) {
+    print(theme.dim(`skip   ${file.relative_path}  ${file.reason}${file.detail ? ` (${file.detail})` : ''}`));
+  }
+
+  const byReason = new Map<SkipReason, number>();
+  for (const file of report.skipped) {
+    byReason.set(file.reason, (byReason.get(file.reason) ?? 0) + 1);
+  }
+
+  print();
+  print(`${String(report.included.length)} files would be indexed, ${String(report.skipped.length)} skipped`);
+  for (const [reason, count] of byReason) {
+    print(`  ${reason}: ${String(count)}`);
+  }
+};
+
+/**
+ * Read standard input to the end
+ */
+const readStdin = async (): Promise<string> => {
+  const chunks: Buffer[] = [];
+  for await (const chunk of process.stdin) chunks.push(chunk as Buffer);
+  return Buffer.concat(chunks).toString('utf-8');
+};
+
+/**
+ * Index piped content into an ephemeral index
+ *
+ * Porcelain:
+ *   document<TAB>repo_id<TAB>path<TAB>chunks<TAB>symbols
+ *   pruned<TAB>repo_id (expired ephemeral indexes removed)
+ */
+const indexStdin = async (name: string, language?: string, documentName?: string): Promise<ExitCode> => {
+  if (process.stdin.isTTY) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: '--stdin needs content piped in',
+      hint: 'e.g. cat buffer.go | cindex index --stdin --name scratch',
+    });
+  }
+  if (!isValidEphemeralName(name)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Invalid --name value: ${name}`,
+      hint: "Use letters, digits, '.', '_', and '-', e.g. scratch",
+    });
+  }
+  const document = { name, language, path: documentName, content: '' };
+  try {
+    documentPath(document);
+  } catch (error) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: error instanceof Error ? error.message : String(error),
+      hint: 'Pass --language <name> or --path <file> with its extension, e.g. --language go',
+    });
+  }
+  document.content = await readStdin();
+
+  const { config, db } = await openSession();
+  try {
+    const ollama = createOllamaClient(config.ollama);
+    await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
+    let result: AddDocumentResult;
+    try {
+      result = await addDocument(config, db, ollama, document);
+    } catch (error) {
+      return reportError(ExitCode.Failure, {
+        code: 'ADD_DOCUMENT_FAILED',
+        message: error instanceof Error ? error.message : String(error),
+      });
+    }
+    const { stats } = result;
+
+    if (isPorcelain()) {
+      printRecord('document', [result.repo_id, result.file_path, stats.chunks_total, stats.symbols_extracted]);
+      for (const pruned of result.pruned) printRecord('pruned', [pruned]);
+    } else {
+      const counts = `${String(stats.chunks_total)} chunks, ${String(stats.symbols_extracted)} symbols`;
+      print(`Indexed ${result.file_path} into '${result.repo_id}' (${counts})`);
+      print(getTheme().dim(`Query it with --repo-id ${result.repo_id}; it expires a day after the last addition`));
+      if (result.pruned.length > 0) print(`Removed expired ephemeral indexes: ${result.pruned.join(', ')}`);
+      for (const error of stats.errors) print(`error  ${error.file_path ?? '-'}  ${error.stage}: ${error.error}`);
+    }
+    if (stats.stage === IndexingStage.Failed) return ExitCode.Failure;
+    return stats.files_failed > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+  } finally {
+    await db.close();
+  }
+};
+
+/**
+ * Index command - full indexing pipeline or dry run
+ */
+export const indexCommand: CliCommand = {
+  name: 'index',
+  description: 'Index a repository (use --dry-run to preview without writing)',
+  usage:
+    'cindex index <path | git-url[@ref] | archive> [--dry-run] [--incremental] [--since <window>] [--wait] ' +
+    '[--repo-id <id>] [--rev <rev>] [--max-file-size <lines>] [--symlinks <policy>] ' +
+    '[--scan-secrets] [--jobs <n>] [--typed [--platforms <list>]] [--history] [--module-only] | ' +
+    'cindex index --stdin --name <name> [--language <name>] [--path <file>]',
+  local: true,
+  options: [
+    { name: 'dry-run', description: 'List files that would be indexed or skipped, without writing' },
+    { name: 'incremental', description: 'Only re-index changed files' },
+    SINCE_OPTION,
+    { name: 'wait', description: 'Wait if another process is indexing the same repository' },
+    { name: 'rev', description: 'Index a tag, branch, or commit from git into <repo>@<commit>', takesValue: true },
+    {
+      name: 'repo-id',
+      description: 'Repository ID (for a git URL or archive: the index name, default from the source)',
+      takesValue: true,
+      complete: 'repo',
+    },
+    { name: 'max-file-size', description: 'Skip files longer than this many lines', takesValue: true },
+    {
+      name: 'symlinks',
+      description: 'Symlink policy (default: SYMLINK_POLICY or follow-within-root)',
+      takesValue: true,
+      complete: [...SYMLINK_POLICIES],
+    },
+    { name: 'scan-secrets', description: 'Record likely credentials for cindex secrets (default: SCAN_SECRETS)' },
+    { name: 'jobs', description: 'Files indexed in parallel (default: number of CPUs)', takesValue: true },
+    { name: 'typed', description: 'Type-check Go packages for signatures, implementations, and references (slower)' },
+    {
+      name: 'platforms',
+      description: 'GOOS/GOARCH pairs --typed loads the packages for, e.g. linux/amd64,windows/amd64',
+      takesValue: true,
+    },
+    { name: 'history', description: "Record each symbol's last commit, author, and date from git blame" },
+    { name: 'module-only', description: 'Index only this Go module, not the go.work workspace it is a member of' },
+    { name: 'stdin', description: 'Index content piped to standard input into an ephemeral index (--name)' },
+    { name: 'name', description: 'Ephemeral index the --stdin content is added to', takesValue: true },
+    {
+      name: 'language',
+      description: 'Language of the --stdin content (default: from --path)',
+      takesValue: true,
+      complete: 'language',
+    },
+    { name: 'path', description: 'Path of the --stdin content in the index (default: the name)', takesValue: true },
+  ],
+  positional: 'dir',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'dry-run': { type: 'boolean', default: false },
+        incremental: { type: 'boolean', default: false },
+        since: { type: 'string' },
+        wait: { type: 'boolean', default: false },
+        rev: { type: 'string' },
+        'repo-id': { type: 'string' },
+        'max-file-size': { type: 'string' },
+        symlinks: { type: 'string' },
+        'scan-secrets': { type: 'boolean' },
+        jobs: { type: 'string' },
+        typed: { type: 'boolean', default: false },
+        platforms: { type: 'string' },
+        history: { type: 'boolean', default: false },
+        'module-only': { type: 'boolean', default: false },
+        stdin: { type: 'boolean', default: false },
+        name: { type: 'string' },
+        language: { type: 'string' },
+        path: { type: 'string' },
+      },
+    });
+
+    if (!values.stdin && (values.name ?? values.language ?? values.path) !== undefined) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--name, --language, and --path apply to --stdin',
+        hint: 'e.g. cat buffer.go | cindex index --stdin --name scratch',
+      });
+    }
+    if (values.stdin) {
+      if (!values.name) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: '--stdin needs --name <name>',
+          hint: 'e.g. cat buffer.go | cindex index --stdin --name scratch [--language go]',
+        });
+      }
+      const pathMode =
+        values['dry-run'] || values.incremental || values.since !== undefined || values.typed || values.history;
+      if (positionals.length > 0 || pathMode || values.rev !== undefined) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message:
+            '--stdin indexes one document: it takes no path, --dry-run, --incremental, --since, --typed, ' +
+            '--history, --rev',
+          hint: 'e.g. cat buffer.go | cindex index --stdin --name scratch [--language go]',
+        });
+      }
+      return indexStdin(values.name, values.language, values.path);
+    }
+
+    if (values.symlinks !== undefined && !(SYMLINK_POLICIES as readonly string[]).includes(values.symlinks)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --symlinks value: ${values.symlinks}`,
+        hint: `Expected one of: ${SYMLINK_POLICIES.join(', ')}`,
+      });
+    }
+
+    const since = values.since !== undefined ? parseSince(values.since) : undefined;
+    if (since === null) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --since value: ${values.since ?? ''}`,
+        hint: 'Expected a window such as 2w, 3d, 12h, or a date such as 2025-01-31',
+      });
+    }
+
+    const platforms = values.platforms !== undefined ? parsePlatformList(values.platforms) : undefined;
+    if (platforms && !values.typed) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--platforms applies to typed indexing',
+        hint: 'Add --typed, e.g. cindex index . --typed --platforms linux/amd64,windows/amd64',
+      });
+    }
+    if (platforms && (platforms.invalid.length > 0 || platforms.platforms.length === 0)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --platforms value: ${platforms.invalid.join(', ') || (values.platforms ?? '')}`,
+        hint: 'Expected GOOS/GOARCH pairs such as linux/amd64,windows/amd64 (see: go tool dist list)',
+      });
+    }
+
+    const jobs = values.jobs !== undefined ? Number(values.jobs) : undefined;
+    if (jobs !== undefined && (!Number.isInteger(jobs) || jobs < 1)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --jobs value: ${values.jobs ?? ''}`,
+        hint: 'Expected a number of parallel files, e.g. --jobs 4 (--jobs 1 indexes one file at a time)',
+      });
+    }
+
+    const wholeCommit = values['dry-run'] || values.incremental || values.since !== undefined || values.history;
+    if (values.rev !== undefined && (wholeCommit || values.typed)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message:
+          '--rev indexes a whole commit from memory: it takes no --dry-run, --incremental, --since, --history, ' +
+          'or --typed',
+        hint: 'e.g. cindex index . --rev v1.4.0',
+      });
+    }
+
+    const source: SourceSpec | null = positionals[0] !== undefined ? parseSourceSpec(positionals[0]) : null;
+    if (source && (values.rev !== undefined || wholeCommit)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message:
+          'A git URL or archive is indexed whole: it takes no --rev, --dry-run, --incremental, --since, or --history',
+        hint: 'Name the ref after @, e.g. cindex index https://github.com/org/repo@v1.2.3',
+      });
+    }
+
+    let repoPath = source ? source.location : normalizeRootPath(positionals[0] ?? '.');
+    // A member module of a Go workspace is indexed with the rest of the workspace
+    const moduleOnly = values['module-only'] || values.rev !== undefined || source !== null;
+    const workspaceRoot = moduleOnly ? null : await findGoWorkspaceRoot(repoPath);
+    if (workspaceRoot !== null) {
+      const note = `Indexing the Go workspace at ${workspaceRoot} (go.work); --module-only indexes ${repoPath} alone`;
+      if (!isPorcelain()) print(getTheme().dim(note));
+      repoPath = normalizeRootPath(workspaceRoot);
+    }
+    let revision: ResolvedRevision | undefined;
+    if (values.rev !== undefined) {
+      try {
+        revision = await resolveRevision(repoPath, values.rev);
+      } catch (error) {
+        return reportError(ExitCode.Failure, {
+          code: 'INVALID_REVISION',
+          message: error instanceof Error ? error.message : String(error),
+          hint: 'Pass a tag, branch, or commit of the repository',
+        });
+      }
+    }
+
+    const defaults = loadConfig().indexing;
+    const options: IndexingOptions = {
+      incremental: values.incremental,
+      since,
+      waitForLock: values.wait,
+      repoId: values['repo-id'],
+      maxFileSize: values['max-file-size'] ? parseInt(values['max-file-size'], 10) : undefined,
+      symlinkPolicy: (values.symlinks as SymlinkPolicy | undefined) ?? defaults.symlink_policy,
+      generatedFiles: defaults.generated_files,
+      excludeDirectories: defaults.exclude_directories,
+      maxDirectoryDepth: defaults.max_directory_depth,
+      maxPathLength: defaults.max_path_length,
+      scanSecrets: values['scan-secrets'] ?? defaults.scan_secrets,
+      jobs,
+      typed: values.typed,
+      typedPlatforms: platforms?.platforms,
+      history: values.history,
+    };
+
+    if (values['dry-run']) {
+      initLogger('ERROR');
+      const report = await dryRunIndexing(repoPath, options);
+      printDryRun(report);
+      const failed = report.included.some((file) => file.used_fallback || file.partial);
+      return failed ? ExitCode.PartialFailure : ExitCode.Success;
+    }
+
+    const { config, db } = await openSession();
+
+    // First Ctrl+C finishes the file in progress and records a checkpoint; a second one exits at once
+    const controller = new AbortController();
+    const removeSignalHandlers = handleShutdownSignals((signal) => {
+      process.stderr.write(`${signal}: finishing the files in progress and saving progress (repeat to force exit)\n`);
+      controller.abort();
+    });
+    options.signal = controller.signal;
+
+    try {
+      const ollama = createOllamaClient(config.ollama);
+      await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
+      let stats: IndexingStats;
+      let commit: string | null = null;
+      if (source) {
+        try {
+          const result = await indexSource(config, db, ollama, source, options);
+          options.repoId = result.repo_id;
+          commit = result.commit;
+          stats = result.stats;
+        } catch (error) {
+          return reportError(ExitCode.Failure, {
+            code: 'SOURCE_UNAVAILABLE',
+            message: error instanceof Error ? error.message : String(error),
+            hint: 'Check that the URL or archive exists and that git, tar, or unzip is installed',
+          });
+        }
+      } else if (revision) {
+        const result = await indexRevision(config, db, ollama, repoPath, revision, options);
+        options.repoId = result.repo_id;
+        stats = result.stats;
+      } else {
+        stats = await createPipeline(config, db, ollama, repoPath, options).indexRepository(repoPath, options);
+      }
+      recordUsage(config, {
+        kind: 'index',
+        source: 'cli',
+        operation: options.incremental ? 'index --incremental' : 'index',
+        duration_ms: stats.total_time_ms,
+        repo_id: options.repoId,
+        files: stats.files_processed,
+      });
+
+      // Porcelain: stats<TAB>stage<TAB>processed<TAB>total<TAB>failed<TAB>chunks<TAB>symbols<TAB>time_ms
+      //            error<TAB>path<TAB>stage<TAB>message
+      //            unreadable<TAB>path<TAB>code
+      //            secrets<TAB>findings<TAB>files (only when scanning)
+      //            typed<TAB>methods<TAB>implementations<TAB>references<TAB>error (only with --typed)
+      //            history<TAB>files<TAB>symbols (only with --history)
+      //            revision<TAB>repo_id<TAB>commit<TAB>ref (only with --rev)
+      //            source<TAB>repo_id<TAB>kind<TAB>location<TAB>ref<TAB>commit (only for a git URL or archive)
+      const unreadable = stats.unreadable_paths ?? [];
+      if (isPorcelain()) {
+        printRecord('stats', [
+          stats.stage,
+          stats.files_processed,
+          stats.files_total,
+          stats.files_failed,
+          stats.chunks_total,
+          stats.symbols_extracted,
+          stats.total_time_ms,
+        ]);
+        for (const error of stats.errors) {
+          printRecord('error', [error.file_path, error.stage, error.error]);
+        }
+        for (const entry of unreadable) {
+          printRecord('unreadable', [entry.relative_path, entry.code]);
+        }
+        if (stats.secret_findings !== undefined) {
+          printRecord('secrets', [stats.secret_findings, stats.secret_files]);
+        }
+        if (options.typed) {
+          const { typed } = stats;
+          printRecord('typed', [typed?.methods, typed?.implementations, typed?.references, stats.typed_error]);
+        }
+        if (stats.history) {
+          printRecord('history', [stats.history.files, stats.history.symbols]);
+        }
+        if (revision) {
+          printRecord('revision', [options.repoId, revision.commit, revision.ref]);
+        }
+        if (source) {
+          printRecord('source', [options.repoId, source.kind, redactLocation(source.location), source.ref, commit]);
+        }
+      } else {
+        if (source) {
+          const fetched = `${redactLocation(source.location)}${source.ref ? `@${source.ref}` : ''}`;
+          print(`Source ${fetched}${commit ? ` (${commit.slice(0, 12)})` : ''} indexed as ${options.repoId ?? ''}`);
+        }
+        if (revision) {
+          print(`Revision ${revision.ref} (${revision.commit.slice(0, 12)}) indexed as ${options.repoId ?? ''}`);
+        }
+        print(`Indexed ${String(stats.files_processed)}/${String(stats.files_total)} files`);
+        print(`Chunks: ${String(stats.chunks_total)}, symbols: ${String(stats.symbols_extracted)}`);
+        for (const error of stats.errors) {
+          print(`error  ${error.file_path ?? '-'}  ${error.stage}: ${error.error}`);
+        }
+        if (unreadable.length > 0) {
+          print(`Skipped ${summarizeUnreadablePaths(unreadable)} that could not be read:`);
+          for (const entry of unreadable.slice(0, UNREADABLE_EXAMPLES)) {
+            print(`  ${entry.relative_path}  ${entry.code}`);
+          }
+          if (unreadable.length > UNREADABLE_EXAMPLES) {
+            print(`  ... and ${String(unreadable.length - UNREADABLE_EXAMPLES)} more (--porcelain lists all)`);
+          }
+        }
+        if (stats.secret_findings) {
+          const found = `${String(stats.secret_findings)} likely secrets in ${String(stats.secret_files ?? 0)} files`;
+          print(`Found ${found}; run 'cindex secrets' to review`);
+        }
+        if (stats.typed) {
+          const { methods, implementations, references } = stats.typed;
+          print(
+            `Typed: ${String(methods)} methods, ${String(implementations)} implementations, ` +
+              `${String(references)} references`
+          );
+        } else if (stats.typed_error) {
+          print(`Typed indexing skipped: ${stats.typed_error}`);
+        }
+        if (stats.history) {
+          const { files, symbols } = stats.history;
+          print(`History: ${String(symbols)} symbols in ${String(files)} files blamed`);
+        }
+      }
+
+      if (stats.stage === IndexingStage.Interrupted) {
+        return reportError(ExitCode.Interrupted, {
+          code: 'INTERRUPTED',
+          message: `Interrupted after ${String(stats.files_processed)}/${String(stats.files_total)} files`,
+          hint: revision || source ? 'Run the same command again' : 'Run again with --incremental to resume',
+        });
+      }
+      if (stats.stage === IndexingStage.Failed) {
+        const [last] = stats.errors.slice(-1);
+        return reportError(ExitCode.Failure, {
+          code: 'INDEXING_FAILED',
+          message: last ? `Indexing failed (${last.stage}): ${last.error}` : 'Indexing failed',
+          file: last?.file_path,
+        });
+      }
+      return stats.files_failed > 0 || stats.typed_error ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      removeSignalHandlers();
+      await db.close();
+    }
+  },
+};
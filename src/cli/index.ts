@@ -0,0 +1,377 @@
+/**
+ * CLI dispatcher for cindex subcommands
+ *
+ * `cindex` with no arguments starts the MCP server (stdio). When the first
+ * argument matches a registered subcommand, it runs that command instead and
+ * exits with the command's exit code. User-defined aliases (see aliases.ts)
+ * expand to a registered command before dispatch.
+ */
+import { expandAlias, loadAliases } from '@cli/aliases';
+import { apiCommand, apiDiffCommand } from '@cli/api';
+import { annotationsCommand, deprecatedCommand, todosCommand } from '@cli/annotations';
+import { auditCommand } from '@cli/audit';
+import { calleesCommand, callersCommand } from '@cli/calls';
+import { createCompletionCommand } from '@cli/completion';
+import { createDaemonCommand } from '@cli/daemon';
+import { runInDaemon } from '@cli/daemon-client';
+import { configCommand } from '@cli/config';
+import { contextCommand } from '@cli/context';
+import { coverageCommand } from '@cli/coverage';
+import { deadcodeCommand } from '@cli/deadcode';
+import { defCommand } from '@cli/def';
+import { depsCommand } from '@cli/deps';
+import { diffSymbolsCommand } from '@cli/diff-symbols';
+import { docCommand } from '@cli/doc';
+import { doctorCommand } from '@cli/doctor';
+import { dupesCommand } from '@cli/dupes';
+import { enumsCommand } from '@cli/enums';
+import { genericsCommand } from '@cli/generics';
+import { errorsCommand } from '@cli/errors';
+import { explainCommand } from '@cli/explain';
+import { graphCommand } from '@cli/graph';
+import { grepCommand } from '@cli/grep';
+import { hierarchyCommand } from '@cli/hierarchy';
+import { implementationsCommand, satisfiesCommand } from '@cli/implementations';
+import { indexCommand } from '@cli/index-repository';
+import { listIndexesCommand, rmCommand, useCommand } from '@cli/indexes';
+import { initCommand } from '@cli/init';
+import { licensesCommand } from '@cli/licenses';
+import { lintCommand } from '@cli/lint';
+import { modulesCommand } from '@cli/modules';
+import {
+  isOutputFormat,
+  isOutputMode,
+  OUTPUT_FORMATS,
+  OUTPUT_MODES,
+  print,
+  reportError,
+  setOutputFormat,
+  setOutputMode,
+  setPositionEncoding,
+} from '@cli/output';
+import { ownersCommand } from '@cli/owners';
+import { platformsCommand } from '@cli/platforms';
+import { pluginsCommand } from '@cli/plugins';
+import { applyProjectSettings } from '@cli/project-config';
+import { queryCommand } from '@cli/query';
+import { refsCommand } from '@cli/refs';
+import { relatedCommand } from '@cli/related';
+import { renameImpactCommand } from '@cli/rename-impact';
+import { replCommand } from '@cli/repl';
+import { searchCommand } from '@cli/search';
+import { secretsCommand } from '@cli/secrets';
+import { serveCommand } from '@cli/serve';
+import { showCommand } from '@cli/show';
+import { exportCommand, importCommand } from '@cli/snapshot';
+import { statsCommand } from '@cli/stats';
+import { testsCommand } from '@cli/tests';
+import { COLOR_MODES, configureColor, isColorMode, THEMES, type ColorMode } from '@cli/theme';
+import { repairCommand, verifyCommand } from '@cli/verify';
+import { watchCommand } from '@cli/watch';
+import { isPositionEncoding, POSITION_ENCODINGS } from '@utils/positions';
+import { ExitCode, type CliCommand, type CliOption } from '@/types/cli';
+
+/**
+ * Options accepted by every command (handled by the dispatcher)
+ */
+const GLOBAL_OPTIONS: CliOption[] = [
+  { name: 'porcelain', description: 'Stable tab-separated output for scripts' },
+  {
+    name: 'output',
+    description: 'Result output (text, porcelain, ndjson)',
+    takesValue: true,
+    complete: [...OUTPUT_MODES],
+  },
+  { name: 'color', description: 'Colorize output (auto, always, never)', takesValue: true, complete: [...COLOR_MODES] },
+  { name: 'theme', description: 'Color theme', takesValue: true, complete: Object.keys(THEMES) },
+  { name: 'format', description: 'Error format (text, json)', takesValue: true, complete: [...OUTPUT_FORMATS] },
+  {
+    name: 'position-encoding',
+    description: 'Column unit (utf-8 bytes, utf-16 code units)',
+    takesValue: true,
+    complete: [...POSITION_ENCODINGS],
+  },
+  { name: 'help', description: 'Show command usage' },
+];
+
+/**
+ * Registered subcommands (in help display order)
+ */
+const COMMAND_LIST: CliCommand[] = [
+  initCommand,
+  indexCommand,
+  watchCommand,
+  searchCommand,
+  grepCommand,
+  explainCommand,
+  serveCommand,
+  replCommand,
+  queryCommand,
+  showCommand,
+  docCommand,
+  contextCommand,
+  refsCommand,
+  relatedCommand,
+  callersCommand,
+  calleesCommand,
+  enumsCommand,
+  genericsCommand,
+  hierarchyCommand,
+  testsCommand,
+  graphCommand,
+  implementationsCommand,
+  satisfiesCommand,
+  defCommand,
+  renameImpactCommand,
+  deadcodeCommand,
+  dupesCommand,
+  diffSymbolsCommand,
+  platformsCommand,
+  depsCommand,
+  modulesCommand,
+  listIndexesCommand,
+  useCommand,
+  rmCommand,
+  exportCommand,
+  importCommand,
+  configCommand,
+  errorsCommand,
+  secretsCommand,
+  auditCommand,
+  todosCommand,
+  deprecatedCommand,
+  annotationsCommand,
+  licensesCommand,
+  apiCommand,
+  apiDiffCommand,
+  coverageCommand,
+  lintCommand,
+  ownersCommand,
+  statsCommand,
+  pluginsCommand,
+  verifyCommand,
+  repairCommand,
+  doctorCommand,
+];
+COMMAND_LIST.push(
+  createDaemonCommand((argv) => runCli(argv)),
+  createCompletionCommand(() => COMMAND_LIST, GLOBAL_OPTIONS)
+);
+
+/**
+ * Registered subcommands keyed by name
+ */
+const COMMANDS = new Map<string, CliCommand>(COMMAND_LIST.map((command) => [command.name, command]));
+
+/**
+ * Print usage for all registered subcommands
+ */
+const printHelp = (): void => {
+  print('Usage: cindex [command] [options]');
+  print();
+  print('Without a command, or with mcp, starts the cindex MCP server on stdio.');
+  print();
+  print('Global options:');
+  const flags = GLOBAL_OPTIONS.map((option) => (option.takesValue ? `${option.name}=<value>` : option.name));
+  const flagWidth = Math.max(...flags.map((flag) => flag.length));
+  GLOBAL_OPTIONS.forEach((option, index) => {
+    print(`  --${flags[index].padEnd(flagWidth)}  ${option.description}`);
+  });
+  print();
+  print('Commands:');
+  const width = Math.max(...[...COMMANDS.keys(), 'help'].map((name) => name.length));
+  for (const command of COMMANDS.values()) {
+    print(`  ${command.name.padEnd(width)}  ${command.description}`);
+  }
+  print(`  ${'help'.padEnd(width)}  Show this help`);
+
+  const aliases = Object.entries(loadAliases());
+  if (aliases.length > 0) {
+    print();
+    print('Aliases (.cindex.yaml):');
+    for (const [alias, expansion] of aliases) {
+      print(`  ${alias.padEnd(width)}  ${expansion}`);
+    }
+  }
+  print();
+  print('Exit codes:');
+  print('  0 success, 1 failure, 2 usage error, 3 no results, 4 partial parse/index failures,');
+  print('  5 policy violations, 70 internal error, 130 interrupted');
+};
+
+/**
+ * Global options extracted from a command's arguments
+ */
+interface GlobalArgs {
+  porcelain: boolean;
+  output: string;
+  color: string;
+  theme: string;
+  format: string;
+  positionEncoding: string;
+  args: string[];
+}
+
+/**
+ * Remove global options from command arguments
+ *
+ * Accepts both `--color=never` and `--color never` forms. An option the
+ * command declares itself (e.g. export --format) is left to the command.
+ */
+const extractGlobalArgs = (argv: string[], command?: CliCommand): GlobalArgs => {
+  const result: GlobalArgs = {
+    porcelain: false,
+    output: 'text',
+    color: 'auto',
+    theme: 'default',
+    format: 'text',
+    positionEncoding: 'utf-8',
+    args: [],
+  };
+  const own = new Set((command?.options ?? []).map((option) => `--${option.name}`));
+  const valueFlags = ['--output', '--color', '--theme', '--format', '--position-encoding'].filter(
+    (flag) => !own.has(flag)
+  );
+
+  for (let i = 0; i < argv.length; i++) {
+    const arg = argv[i];
+    const [flag, inline] = arg.split(/=(.*)/s, 2) as [string, string | undefined];
+
+    if (arg === '--porcelain') {
+      result.porcelain = true;
+    } else if (valueFlags.includes(flag)) {
+      const value = inline ?? argv[++i] ?? '';
+      if (flag === '--output') result.output = value;
+      else if (flag === '--color') result.color = value;
+      else if (flag === '--theme') result.theme = value;
+      else if (flag === '--format') result.format = value;
+      else result.positionEncoding = value;
+    } else {
+      result.args.push(arg);
+    }
+  }
+
+  return result;
+};
+
+/**
+ * Check whether argv should be handled by the CLI instead of the MCP server
+ *
+ * @param argv - Arguments after the script path (process.argv.slice(2))
+ * @returns True if the first argument is a subcommand, alias, or help flag
+ */
+export const isCliInvocation = (argv: string[]): boolean => {
+  const [name] = argv;
+  if (name === undefined) return false;
+  if (COMMANDS.has(name) || name === 'help' || name === '--help' || name === '-h') return true;
+  return Object.hasOwn(loadAliases(), name);
+};
+
+/**
+ * Run a CLI subcommand
+ *
+ * @param argv - Arguments after the script path (process.argv.slice(2))
+ * @returns Process exit code
+ */
+export const runCli = async (argv: string[]): Promise<ExitCode> => {
+  // Aliases never shadow built-in commands and expand exactly once
+  const [first = '', ...original] = argv;
+  const aliases: Record<string, string> = COMMANDS.has(first) ? {} : loadAliases();
+  const aliased = Object.hasOwn(aliases, first);
+  const expanded = aliased ? expandAlias(aliases[first], original) : argv;
+
+  // Global flags are accepted anywhere after the command name
+  const [name, ...rest] = expanded;
+
+  // A running daemon answers from its open index (see @cli/daemon)
+  const target = name ? COMMANDS.get(name) : undefined;
+  if (target && !target.local) {
+    const code = await runInDaemon(argv);
+    if (code !== null) return code;
+  }
+  const globals = extractGlobalArgs(rest, name ? COMMANDS.get(name) : undefined);
+  const args = globals.args;
+
+  // Select the format first so every later error is reported in it
+  if (!isOutputFormat(globals.format)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Invalid --format value '${globals.format}' (expected ${OUTPUT_FORMATS.join(', ')})`,
+    });
+  }
+  setOutputFormat(globals.format);
+
+  if (!isPositionEncoding(globals.positionEncoding)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message:
+        `Invalid --position-encoding value '${globals.positionEncoding}' ` +
+        `(expected ${POSITION_ENCODINGS.join(', ')})`,
+    });
+  }
+  setPositionEncoding(globals.positionEncoding);
+
+  if (aliased && !COMMANDS.has(name ?? '')) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Alias '${first}' must expand to a built-in command (got '${name ?? ''}')`,
+    });
+  }
+  if (!isColorMode(globals.color)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Invalid --color value '${globals.color}' (expected ${COLOR_MODES.join(', ')})`,
+    });
+  }
+  if (!(globals.theme in THEMES)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Unknown theme '${globals.theme}' (available: ${Object.keys(THEMES).join(', ')})`,
+    });
+  }
+
+  // --porcelain is short for --output porcelain
+  const output = globals.porcelain && globals.output === 'text' ? 'porcelain' : globals.output;
+  if (!isOutputMode(output)) {
+    return reportError(ExitCode.Usage, {
+      code: 'USAGE_ERROR',
+      message: `Invalid --output value '${output}' (expected ${OUTPUT_MODES.join(', ')})`,
+    });
+  }
+
+  // Team settings from .cindex.yaml fill in anything the environment leaves unset
+  applyProjectSettings();
+
+  const color: ColorMode = output === 'text' ? globals.color : 'never';
+  configureColor(color, globals.theme);
+  setOutputMode(output);
+
+  const command = name ? COMMANDS.get(name) : undefined;
+
+  if (!command) {
+    printHelp();
+    return ExitCode.Success;
+  }
+
+  if (args.includes('--help') || args.includes('-h')) {
+    print(`Usage: ${command.usage}`);
+    print();
+    print(command.description);
+    return ExitCode.Success;
+  }
+
+  try {
+    return await command.run(args);
+  } catch (error) {
+    // node:util parseArgs rejects unknown or malformed options
+    const code = (error as NodeJS.ErrnoException).code;
+    if (code?.startsWith('ERR_PARSE_ARGS')) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: (error as Error).message,
+        hint: `Usage: ${command.usage}`,
+      });
+    }
+    throw error;
+  }
+};
@@ -0,0 +1,197 @@
+/**
+ * CLI commands: list, use, rm
+ * Manage named indexes (one per indexed repository ID)
+ *
+ * Each `cindex index --repo-id <name>` run creates or refreshes a named index
+ * in the configured database. `use` picks the default index for the current
+ * directory tree, which `search` and `repl` then query unless --repo-id is given.
+ */
+import * as readline from 'node:readline/promises';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { clearSelectionsFor, getSelectedIndex, setSelectedIndex } from '@cli/selection';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedRepositories } from '@database/queries';
+import { acquireIndexLock } from '@indexing/index-lock';
+import { deleteRepository } from '@indexing/version-tracker';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * List command - show indexed repositories, marking the one selected for this directory
+ *
+ * Porcelain: index<TAB>repo_id<TAB>type<TAB>files<TAB>indexed_at<TAB>path<TAB>selected
+ */
+export const listIndexesCommand: CliCommand = {
+  name: 'list',
+  description: 'List named indexes (indexed repositories)',
+  usage: 'cindex list',
+  run: async () => {
+    const { db } = await openSession();
+    try {
+      const repos = await listIndexedRepositories(db.getPool());
+      const selected = getSelectedIndex()?.repoId;
+
+      if (isPorcelain()) {
+        for (const repo of repos) {
+          const { repo_id, repo_type, file_count, indexed_at, repo_path } = repo;
+          printRecord('index', [repo_id, repo_type, file_count, indexed_at, repo_path, repo_id === selected ? 1 : 0]);
+        }
+        return repos.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      if (repos.length === 0) {
+        print('No indexes yet. Create one with: cindex index <dir> --repo-id <name>');
+        return ExitCode.NoResults;
+      }
+
+      const theme = getTheme();
+      const width = Math.max(...repos.map((repo) => repo.repo_id.length));
+      for (const repo of repos) {
+        const marker = repo.repo_id === selected ? '*' : ' ';
+        const detail = `${repo.repo_type}, ${String(repo.file_count)} files, indexed ${repo.indexed_at}`;
+        print(`${marker} ${repo.repo_id.padEnd(width)}  ${theme.dim(detail)}  ${theme.path(repo.repo_path ?? '')}`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
+
+/**
+ * Use command - select the default index for the current directory tree
+ */
+export const useCommand: CliCommand = {
+  name: 'use',
+  description: 'Select the default index for the current directory',
+  usage: 'cindex use <name> | cindex use --clear',
+  options: [{ name: 'clear', description: 'Remove the selection for this directory' }],
+  positional: 'repo',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { clear: { type: 'boolean' } },
+    });
+    const cwd = process.cwd();
+
+    if (values.clear) {
+      setSelectedIndex(cwd, null);
+      const inherited = getSelectedIndex(cwd);
+      print(inherited ? `Cleared; now using '${inherited.repoId}' from ${inherited.directory}` : 'Selection cleared');
+      return ExitCode.Success;
+    }
+
+    const [name] = positionals;
+    if (!name) {
+      const current = getSelectedIndex(cwd);
+      print(current ? `${current.repoId} (selected for ${current.directory})` : 'No index selected');
+      return current ? ExitCode.Success : ExitCode.NoResults;
+    }
+
+    const { db } = await openSession();
+    try {
+      const repos = await listIndexedRepositories(db.getPool());
+      if (!repos.some((repo) => repo.repo_id === name)) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${name}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+    } finally {
+      await db.close();
+    }
+
+    setSelectedIndex(cwd, name);
+    print(`Using '${name}' for ${cwd}`);
+    return ExitCode.Success;
+  },
+};
+
+/**
+ * Ask for confirmation on the terminal
+ */
+const confirm = async (question: string): Promise<boolean> => {
+  const rl = readline.createInterface({ input: process.stdin, output: process.stdout });
+  try {
+    const answer = await rl.question(`${question} [y/N] `);
+    return /^y(es)?$/i.test(answer.trim());
+  } finally {
+    rl.close();
+  }
+};
+
+/**
+ * Rm command - delete a named index and every selection pointing at it
+ */
+export const rmCommand: CliCommand = {
+  name: 'rm',
+  description: 'Delete a named index and all of its data',
+  usage: 'cindex rm <name> [--yes]',
+  local: true,
+  options: [{ name: 'yes', description: 'Do not ask for confirmation' }],
+  positional: 'repo',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { yes: { type: 'boolean', short: 'y' } },
+    });
+
+    const [name] = positionals;
+    if (!name) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing index name',
+        hint: `Usage: ${rmCommand.usage}`,
+      });
+    }
+
+    if (!values.yes) {
+      if (!process.stdin.isTTY) {
+        return reportError(ExitCode.Usage, {
+          code: 'CONFIRMATION_REQUIRED',
+          message: 'Refusing to delete without confirmation',
+          hint: 'Pass --yes to delete without prompting',
+        });
+      }
+      if (!(await confirm(`Delete index '${name}' and all of its files, chunks, and symbols?`))) {
+        return reportError(ExitCode.Failure, { code: 'ABORTED', message: 'Aborted' });
+      }
+    }
+
+    const { config, db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repos = await listIndexedRepositories(pool);
+      if (!repos.some((repo) => repo.repo_id === name)) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${name}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+
+      // Never delete underneath a running indexer, and let active readers finish first
+      const lock = await acquireIndexLock(name, false, true);
+      const stats = await deleteRepository(pool, name, config.store).finally(lock.release);
+      const cleared = clearSelectionsFor(name);
+
+      if (isPorcelain()) {
+        printRecord('deleted', [stats.repo_id, stats.file_count, stats.chunk_count, stats.symbol_count, cleared]);
+      } else {
+        const counts = `${String(stats.file_count)} files, ${String(stats.chunk_count)} chunks`;
+        print(`Deleted '${name}' (${counts}, ${String(stats.symbol_count)} symbols)`);
+        if (cleared > 0) {
+          print(`Cleared ${String(cleared)} directory selection(s)`);
+        }
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,253 @@
+/**
+ * CLI command: init
+ * Inspect a repository and write a recommended .cindex.yaml
+ *
+ * Looks at languages, size, and version control, then proposes settings one
+ * at a time (interactive) or all at once (--yes). Without a terminal and
+ * without --yes, the recommendation is printed and nothing is written.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+import * as readline from 'node:readline/promises';
+import { parseArgs } from 'node:util';
+
+import * as yaml from 'js-yaml';
+
+import { PROJECT_CONFIG_FILE } from '@cli/config';
+import { print, reportError } from '@cli/output';
+import { readProjectConfig } from '@cli/project-config';
+import { getTheme } from '@cli/theme';
+import { FileWalker } from '@indexing/file-walker';
+import { initLogger } from '@utils/logger';
+import { normalizeRootPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { DEFAULT_CONFIG, ENV_VARS } from '@/types/config';
+import { type DiscoveredFile, type Language } from '@/types/indexing';
+
+/** Upper bound for inspection so oversized files are seen rather than skipped */
+const INSPECT_MAX_FILE_SIZE = 100000;
+
+/** File count above which larger database batches are recommended */
+const LARGE_REPO_FILES = 5000;
+
+/** Directory names that usually hold generated code */
+const GENERATED_DIRECTORIES = new Set(['generated', '__generated__', 'gen', 'autogen']);
+
+/**
+ * Repository facts gathered by init
+ */
+interface RepoInspection {
+  vcs: 'git' | 'mercurial' | 'svn' | 'none';
+  files: number;
+  lines: number;
+  languages: [Language, number][];
+  largest: DiscoveredFile | undefined;
+  oversized: number;
+  generatedDirs: string[];
+}
+
+/**
+ * Single recommended change to .cindex.yaml
+ */
+interface Recommendation {
+  /** Why this is recommended */
+  reason: string;
+  /** Top-level key ('settings' or a directory config key) */
+  key: string;
+  /** Value (mappings merge into settings) */
+  value: unknown;
+}
+
+/**
+ * Detect the version control system in use
+ */
+const detectVcs = (repoPath: string): RepoInspection['vcs'] => {
+  if (fs.existsSync(path.join(repoPath, '.git'))) return 'git';
+  if (fs.existsSync(path.join(repoPath, '.hg'))) return 'mercurial';
+  if (fs.existsSync(path.join(repoPath, '.svn'))) return 'svn';
+  return 'none';
+};
+
+/**
+ * Walk the repository and summarize what would be indexed
+ */
+const inspect = async (repoPath: string): Promise<RepoInspection> => {
+  const walker = new FileWalker(repoPath, { maxFileSize: INSPECT_MAX_FILE_SIZE });
+  const files = await walker.discoverFiles();
+  const stats = walker.getStats();
+  const defaultLimit = DEFAULT_CONFIG.indexing.max_file_size;
+
+  const generatedDirs = new Set<string>();
+  for (const file of files) {
+    const segments = file.relative_path.split('/');
+    const index = segments.findIndex((segment) => GENERATED_DIRECTORIES.has(segment));
+    if (index >= 0) generatedDirs.add(segments.slice(0, index + 1).join('/') + '/');
+  }
+
+  return {
+    vcs: detectVcs(repoPath),
+    files: files.length,
+    lines: stats.total_lines,
+    languages: (Object.entries(stats.files_by_language) as [Language, number][]).sort((a, b) => b[1] - a[1]),
+    largest: files.reduce<DiscoveredFile | undefined>(
+      (max, file) => (!max || file.line_count > max.line_count ? file : max),
+      undefined
+    ),
+    oversized: files.filter((file) => file.line_count > defaultLimit).length,
+    generatedDirs: [...generatedDirs].sort(),
+  };
+};
+
+/**
+ * Derive recommendations from an inspection
+ */
+const recommend = (facts: RepoInspection): Recommendation[] => {
+  const recommendations: Recommendation[] = [];
+
+  if (facts.languages.length > 0) {
+    recommendations.push({
+      reason: `Detected ${facts.languages.map(([language, count]) => `${language} (${String(count)})`).join(', ')}`,
+      key: 'languages',
+      value: facts.languages.map(([language]) => language),
+    });
+  }
+
+  if (facts.oversized > 0 && facts.largest) {
+    const limit = Math.min(INSPECT_MAX_FILE_SIZE, Math.ceil(facts.largest.line_count / 1000) * 1000);
+    const defaultLimit = String(DEFAULT_CONFIG.indexing.max_file_size);
+    const largest = `${facts.largest.relative_path}, ${String(facts.largest.line_count)} lines`;
+    recommendations.push({
+      reason: `${String(facts.oversized)} files exceed the default ${defaultLimit}-line limit (largest: ${largest})`,
+      key: 'max_file_size',
+      value: limit,
+    });
+  }
+
+  if (facts.generatedDirs.length > 0) {
+    recommendations.push({
+      reason: `Found generated code directories: ${facts.generatedDirs.join(', ')}`,
+      key: 'exclude',
+      value: facts.generatedDirs,
+    });
+  }
+
+  if (facts.files > LARGE_REPO_FILES) {
+    recommendations.push({
+      reason: `Large repository (${String(facts.files)} files) - use bigger database batches`,
+      key: 'settings',
+      value: { [ENV_VARS.INDEXING_BATCH_SIZE]: 500 },
+    });
+  }
+
+  if (facts.vcs !== 'git') {
+    recommendations.push({
+      reason: `No git repository (${facts.vcs}) - .gitignore rules may not reflect what is tracked`,
+      key: 'settings',
+      value: { [ENV_VARS.RESPECT_GITIGNORE]: false },
+    });
+  }
+
+  return recommendations;
+};
+
+/**
+ * Combine accepted recommendations into a YAML document
+ */
+const buildDocument = (accepted: Recommendation[]): Record<string, unknown> => {
+  const document: Record<string, unknown> = {};
+  for (const { key, value } of accepted) {
+    document[key] = key === 'settings' ? { ...(document.settings as object | undefined), ...(value as object) } : value;
+  }
+  return document;
+};
+
+/**
+ * Init command - inspect the repository and write .cindex.yaml
+ */
+export const initCommand: CliCommand = {
+  name: 'init',
+  description: 'Inspect the repository and write a recommended .cindex.yaml',
+  usage: 'cindex init [dir] [--yes] [--force]',
+  local: true,
+  options: [
+    { name: 'yes', description: 'Accept all recommendations without prompting' },
+    { name: 'force', description: 'Merge into an existing .cindex.yaml' },
+  ],
+  positional: 'dir',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { yes: { type: 'boolean', short: 'y' }, force: { type: 'boolean' } },
+    });
+
+    initLogger('ERROR');
+    const repoPath = normalizeRootPath(positionals[0] ?? '.');
+    const target = path.join(repoPath, PROJECT_CONFIG_FILE);
+
+    if (fs.existsSync(target) && !values.force) {
+      return reportError(ExitCode.Failure, {
+        code: 'FILE_EXISTS',
+        message: `${target} already exists`,
+        file: target,
+        hint: 'Use --force to merge recommendations into it',
+      });
+    }
+
+    const theme = getTheme();
+    const facts = await inspect(repoPath);
+    print(`Repository: ${theme.path(repoPath)}`);
+    print(`  VCS: ${facts.vcs}, ${String(facts.files)} files, ${String(facts.lines)} lines`);
+
+    const recommendations = recommend(facts);
+    if (recommendations.length === 0) {
+      print('Defaults look right for this repository; nothing to write.');
+      return ExitCode.Success;
+    }
+
+    const interactive = !values.yes && process.stdin.isTTY;
+    if (!values.yes && !interactive) {
+      print();
+      print(theme.dim('# Recommended .cindex.yaml (run with --yes to write it)'));
+      process.stdout.write(yaml.dump(buildDocument(recommendations), { lineWidth: 120 }));
+      return ExitCode.Success;
+    }
+
+    const accepted: Recommendation[] = [];
+    const rl = interactive ? readline.createInterface({ input: process.stdin, output: process.stdout }) : null;
+    try {
+      for (const recommendation of recommendations) {
+        print();
+        print(recommendation.reason);
+        const preview = yaml.dump({ [recommendation.key]: recommendation.value }, { flowLevel: 1 }).trimEnd();
+        if (rl) {
+          const answer = await rl.question(`  ${preview}\n  Apply? [Y/n] `);
+          if (/^n(o)?$/i.test(answer.trim())) continue;
+        } else {
+          print(`  ${preview}`);
+        }
+        accepted.push(recommendation);
+      }
+    } finally {
+      rl?.close();
+    }
+
+    if (accepted.length === 0) {
+      print('No recommendations accepted; nothing written.');
+      return ExitCode.Success;
+    }
+
+    // Settings merge into an existing file; directory config keys are replaced
+    const existing = readProjectConfig(target)?.doc ?? {};
+    const { settings, ...overrides } = buildDocument(accepted);
+    const document: Record<string, unknown> = { ...existing, ...overrides };
+    if (settings) {
+      document.settings = { ...(existing.settings as Record<string, unknown> | undefined), ...(settings as object) };
+    }
+    fs.writeFileSync(target, yaml.dump(document, { lineWidth: 120 }));
+
+    print();
+    print(`Wrote ${target}`);
+    return ExitCode.Success;
+  },
+};
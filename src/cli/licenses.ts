@@ -0,0 +1,100 @@
+/**
+ * CLI command: licenses
+ * License compliance report: licenses in use and files missing a license header
+ *
+ * Each indexed file records its license: declared in its own header (an
+ * SPDX-License-Identifier tag or a license notice) or inherited from the
+ * nearest license file. Source files (a supported language) are expected to
+ * declare it themselves; documentation and config files are counted but not
+ * required to. Generated files are left out.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listFileLicenses } from '@database/queries';
+import { compareStrings } from '@utils/ordering';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type FileLicenseRecord } from '@/types/database';
+import { Language } from '@/types/indexing';
+
+/** Label for files with neither a header nor a license file (also the license: facet value) */
+const NO_LICENSE = 'none';
+
+/**
+ * Check whether a file is expected to carry its own license header
+ */
+const requiresHeader = (record: FileLicenseRecord): boolean => record.language !== Language.Unknown;
+
+/**
+ * Check whether a file declares its license in its own header
+ */
+const hasHeader = (record: FileLicenseRecord): boolean => {
+  return record.license_source === 'spdx' || record.license_source === 'header';
+};
+
+/**
+ * Licenses command - summarize file licenses and list files missing a header
+ */
+export const licensesCommand: CliCommand = {
+  name: 'licenses',
+  description: 'Report file licenses and source files missing a license header',
+  usage: 'cindex licenses [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const records = await readIndex(repoId, () => listFileLicenses(db.getPool(), repoId));
+      const missing = records.filter((record) => requiresHeader(record) && !hasHeader(record));
+
+      // Porcelain: license<TAB>repo_id<TAB>path<TAB>license<TAB>source<TAB>header_required
+      if (isPorcelain()) {
+        for (const record of records) {
+          const { repo_id, file_path, license, license_source } = record;
+          printRecord('license', [repo_id, file_path, license, license_source, requiresHeader(record) ? 1 : 0]);
+        }
+      } else if (records.length === 0) {
+        print('No indexed files');
+      } else {
+        const theme = getTheme();
+        const totals = new Map<string, { files: number; headers: number }>();
+        for (const record of records) {
+          const total = totals.get(record.license ?? NO_LICENSE) ?? { files: 0, headers: 0 };
+          total.files++;
+          if (hasHeader(record)) total.headers++;
+          totals.set(record.license ?? NO_LICENSE, total);
+        }
+
+        const width = Math.max(...[...totals.keys()].map((license) => license.length), 'License'.length);
+        print(theme.dim(`${'License'.padEnd(width)}  ${'Files'.padStart(6)}  ${'In header'.padStart(9)}`));
+        for (const [license, total] of [...totals].sort(([a], [b]) => compareStrings(a, b))) {
+          print(`${license.padEnd(width)}  ${String(total.files).padStart(6)}  ${String(total.headers).padStart(9)}`);
+        }
+
+        if (missing.length > 0) {
+          print();
+          print('Source files without a license header:');
+          for (const record of missing) {
+            const label = record.license ? `inherits ${record.license} from a license file` : 'no license';
+            print(`${theme.path(record.file_path)}  ${theme.dim(`(${label})`)}`);
+          }
+        }
+
+        const required = records.filter(requiresHeader).length;
+        print();
+        print(`${String(missing.length)} of ${String(required)} source files have no license header`);
+      }
+
+      // Files missing a header fail the check, like parse errors do
+      return missing.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
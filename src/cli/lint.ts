@@ -0,0 +1,179 @@
+/**
+ * CLI command: lint
+ * Import staticcheck or golangci-lint JSON output, or list the imported findings
+ *
+ *   staticcheck -f json ./... > lint.json          (or golangci-lint run --out-format json)
+ *   cindex lint lint.json                          import; replaces the tool's previous findings
+ *   cindex lint                                    findings with their enclosing symbol
+ *
+ * Findings are stored by file and line and attached to the innermost symbol
+ * whose span holds them, so `cindex search` counts them per symbol and
+ * `cindex show` lists them under the source. Re-indexing a file clears its
+ * findings, since its lines may have moved; import the report again after
+ * indexing.
+ */
+import * as fs from 'node:fs';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedFiles, listLintFindings } from '@database/queries';
+import { createDatabaseWriter } from '@database/writer';
+import { acquireIndexLock, beginGeneration, publishGeneration } from '@indexing/index-lock';
+import { parseLintReport, type LintReport } from '@indexing/lint-report';
+import { matchStoredPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Unmatched report files listed before the rest are summarized */
+const UNMATCHED_SHOWN = 5;
+
+/**
+ * Store a report's findings on the indexed files they name
+ */
+const importReport = async (file: string, report: LintReport, repoId: string | undefined): Promise<ExitCode> => {
+  const { db } = await openSession();
+  // Writes to one index take its lock; readers see the import as a new generation
+  const lock = repoId ? await acquireIndexLock(repoId) : null;
+  try {
+    if (repoId) beginGeneration(repoId);
+    const indexed = await listIndexedFiles(db.getPool(), repoId);
+    const byPath = new Map(indexed.map((record) => [record.file_path, record]));
+
+    const resolved = new Map<string, string | null>();
+    const findings = report.issues.flatMap((issue) => {
+      if (!resolved.has(issue.file)) resolved.set(issue.file, matchStoredPath(issue.file, byPath.keys()));
+      const record = byPath.get(resolved.get(issue.file) ?? '');
+      if (!record) return [];
+      return [
+        {
+          repo_id: record.repo_id,
+          repo_path: record.repo_path,
+          file_path: record.file_path,
+          line_number: issue.line,
+          column_number: issue.column,
+          end_line: issue.end_line,
+          tool: report.tool,
+          linter: issue.linter,
+          rule: issue.rule,
+          severity: issue.severity,
+          message: issue.message,
+        },
+      ];
+    });
+    const unmatched = [...resolved].filter(([, stored]) => stored === null).map(([reported]) => reported);
+
+    if (report.issues.length > 0 && findings.length === 0) {
+      return reportError(ExitCode.Failure, {
+        code: 'NO_MATCHING_FILES',
+        message: `None of the ${String(resolved.size)} files in ${file} are indexed`,
+        file,
+        hint: 'Index the module the report was taken from, or pass --repo-id for its index',
+      });
+    }
+
+    await createDatabaseWriter(db.getPool()).replaceLintFindings(report.tool, repoId ?? null, findings);
+
+    // Porcelain: lint_import<TAB>tool<TAB>findings<TAB>files<TAB>unmatched_files
+    const files = new Set(findings.map((finding) => finding.file_path)).size;
+    if (isPorcelain()) {
+      printRecord('lint_import', [report.tool, findings.length, files, unmatched.length]);
+    } else {
+      const theme = getTheme();
+      print(`Imported ${String(findings.length)} ${report.tool} findings in ${String(files)} files`);
+      if (unmatched.length > 0) {
+        print(theme.dim(`${String(unmatched.length)} files in the report are not indexed:`));
+        for (const reported of unmatched.slice(0, UNMATCHED_SHOWN)) {
+          print(`  ${theme.path(reported)}`);
+        }
+        if (unmatched.length > UNMATCHED_SHOWN) {
+          print(theme.dim(`  ... and ${String(unmatched.length - UNMATCHED_SHOWN)} more`));
+        }
+      }
+    }
+    // Findings on files missing from the index were dropped
+    return unmatched.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+  } finally {
+    if (repoId) publishGeneration(repoId);
+    lock?.release();
+    await db.close();
+  }
+};
+
+/**
+ * Lint command - import linter findings or list them
+ */
+export const lintCommand: CliCommand = {
+  name: 'lint',
+  description: 'Import staticcheck or golangci-lint JSON findings, or list imported findings',
+  usage: 'cindex lint [<report.json>] [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' } },
+    });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const [file] = positionals;
+    if (file) {
+      let report: LintReport;
+      try {
+        report = parseLintReport(fs.readFileSync(file, 'utf-8'));
+      } catch (error) {
+        const message = error instanceof Error ? error.message : String(error);
+        return reportError(ExitCode.Failure, {
+          code: 'INVALID_REPORT',
+          message: `Cannot read ${file}: ${message}`,
+          file,
+          hint: 'Write a report with: staticcheck -f json ./... or golangci-lint run --out-format json',
+        });
+      }
+      return importReport(file, report, repoId);
+    }
+
+    const { db } = await openSession();
+    try {
+      const findings = await readIndex(repoId, () => listLintFindings(db.getPool(), repoId));
+
+      // Porcelain: lint<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>linter<TAB>rule<TAB>severity<TAB>symbol
+      //            <TAB>message
+      if (isPorcelain()) {
+        for (const finding of findings) {
+          const { repo_id, file_path, line_number, column_number, linter, rule, severity, symbol_name } = finding;
+          printRecord('lint', [
+            repo_id,
+            file_path,
+            line_number,
+            column_number,
+            linter,
+            rule,
+            severity,
+            symbol_name,
+            finding.message,
+          ]);
+        }
+      } else if (findings.length === 0) {
+        print('No lint findings (import a report with: cindex lint <report.json>)');
+      } else {
+        const theme = getTheme();
+        for (const finding of findings) {
+          const location = `${finding.file_path}:${String(finding.line_number)}:${String(finding.column_number)}`;
+          const symbol = finding.symbol_name ? `  ${theme.dim(`(in ${finding.symbol_name})`)}` : '';
+          print(`${theme.path(location)}  ${theme.kind(finding.rule)}  ${finding.message}${symbol}`);
+        }
+        print();
+        const files = new Set(findings.map((finding) => finding.file_path)).size;
+        print(`${String(findings.length)} findings in ${String(files)} files`);
+      }
+
+      // Outstanding findings fail the check, like secret findings do
+      return findings.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,103 @@
+/**
+ * CLI command: modules
+ * List the Go modules of an index and which of them require each other, recorded at index time
+ * (see @indexing/go-workspace)
+ *
+ *   cindex modules                 every module, with its files and the modules it requires
+ *   cindex modules --repo-id shop  the modules of one index
+ *
+ * Symbols are tagged with their module, so module:shop/api narrows a search
+ * to one of them. Modules required from outside the index are indexed with
+ * cindex deps.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoModules } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoModuleRecord } from '@/types/database';
+
+/**
+ * Print modules with the modules they require
+ *
+ * Porcelain:
+ *   module<TAB>repo_id<TAB>path<TAB>module_path<TAB>go_version<TAB>files<TAB>symbols
+ *   requires<TAB>repo_id<TAB>module_path<TAB>required_module_path
+ *
+ * @param modules - Modules ordered by index and directory
+ */
+const printModules = (modules: GoModuleRecord[]): void => {
+  if (isPorcelain()) {
+    for (const module of modules) {
+      printRecord('module', [
+        module.repo_id,
+        module.directory,
+        module.module_path,
+        module.go_version,
+        module.files,
+        module.symbols,
+      ]);
+    }
+    for (const module of modules) {
+      for (const required of module.requires) printRecord('requires', [module.repo_id, module.module_path, required]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const width = Math.max(...modules.map((module) => module.module_path.length));
+  let repoId: string | null = null;
+  for (const module of modules) {
+    if (module.repo_id !== repoId) {
+      if (repoId !== null) print();
+      repoId = module.repo_id;
+      print(theme.dim(module.repo_id));
+    }
+    const counts = `${String(module.files)} files, ${String(module.symbols)} symbols`;
+    print(`  ${theme.kind(module.module_path.padEnd(width))}  ${theme.path(module.directory)}  ${theme.dim(counts)}`);
+    for (const required of module.requires) print(`    requires ${required}`);
+  }
+};
+
+/**
+ * Modules command - Go modules of an index and the dependencies between them
+ */
+export const modulesCommand: CliCommand = {
+  name: 'modules',
+  description: 'List the Go modules of an index and which of them require each other',
+  usage: 'cindex modules [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const modules = await readIndex(repoId, () => listGoModules(db.getPool(), repoId));
+      if (modules.length === 0) {
+        if (!isPorcelain()) print('No Go modules indexed');
+        return ExitCode.NoResults;
+      }
+
+      printModules(modules);
+      if (!isPorcelain()) {
+        const dependencies = modules.reduce((total, module) => total + module.requires.length, 0);
+        print();
+        print(`${String(modules.length)} modules, ${String(dependencies)} dependencies between them`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
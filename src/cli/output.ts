@@ -0,0 +1,321 @@
+/**
+ * CLI output helpers
+ *
+ * Unlike the logger (stderr, reserved for MCP stdio conventions), CLI commands
+ * write their results to stdout so they can be piped and redirected.
+ *
+ * Two output modes:
+ * - human (default): readable, colored, free to change between versions
+ * - porcelain (--porcelain): stable tab-separated records for scripts
+ *
+ * With --output ndjson, porcelain records are written as one JSON object per
+ * line instead, as soon as they are produced, for pipelines such as jq.
+ *
+ * With --format json, failures are reported as a structured error object on
+ * stderr (see reportError) instead of plain text.
+ */
+import chalk from 'chalk';
+import * as yaml from 'js-yaml';
+
+import { CindexError } from '@utils/errors';
+import { logger } from '@utils/logger';
+import { utf16ToByteColumn, type PositionEncoding } from '@utils/positions';
+import {
+  ExitCode,
+  type CheckStatus,
+  type CliErrorReport,
+  type DiagnosticCheck,
+  type OutputFormat,
+  type OutputMode,
+} from '@/types/cli';
+
+/** Formats accepted by --format */
+export const OUTPUT_FORMATS: readonly OutputFormat[] = ['text', 'json'];
+
+/** Modes accepted by --output */
+export const OUTPUT_MODES: readonly OutputMode[] = ['text', 'porcelain', 'ndjson'];
+
+/** Active output mode (set once by the CLI dispatcher) */
+let porcelain = false;
+
+/** Records written as NDJSON instead of tab-separated (set once by the CLI dispatcher) */
+let ndjson = false;
+
+/** Active output format (set once by the CLI dispatcher) */
+let format: OutputFormat = 'text';
+
+/** Unit of reported columns (set once by the CLI dispatcher) */
+let positionEncoding: PositionEncoding = 'utf-8';
+
+/**
+ * Enable or disable porcelain output
+ *
+ * Porcelain mode also disables colors so records never contain escape codes.
+ *
+ * @param enabled - True for porcelain output
+ */
+export const setPorcelain = (enabled: boolean): void => {
+  porcelain = enabled;
+  if (enabled) {
+    chalk.level = 0;
+  }
+};
+
+/**
+ * Check whether porcelain output is active
+ */
+export const isPorcelain = (): boolean => porcelain;
+
+/**
+ * Check whether a string is a supported output mode
+ */
+export const isOutputMode = (value: string): value is OutputMode => {
+  return (OUTPUT_MODES as readonly string[]).includes(value);
+};
+
+/**
+ * Select the output mode
+ *
+ * 'ndjson' implies porcelain: commands produce the same records, written as
+ * JSON objects instead of tab-separated lines.
+ *
+ * @param value - 'text' (default), 'porcelain', or 'ndjson'
+ */
+export const setOutputMode = (value: OutputMode): void => {
+  ndjson = value === 'ndjson';
+  setPorcelain(value !== 'text');
+};
+
+/**
+ * Check whether records are written as NDJSON
+ */
+export const isNdjson = (): boolean => ndjson;
+
+/**
+ * Check whether a string is a supported output format
+ */
+export const isOutputFormat = (value: string): value is OutputFormat => {
+  return (OUTPUT_FORMATS as readonly string[]).includes(value);
+};
+
+/**
+ * Select the output format
+ *
+ * @param value - 'text' (default) or 'json'
+ */
+export const setOutputFormat = (value: OutputFormat): void => {
+  format = value;
+};
+
+/**
+ * Get the active output format
+ */
+export const getOutputFormat = (): OutputFormat => format;
+
+/**
+ * Select the unit of reported columns
+ *
+ * @param value - 'utf-8' (bytes, default) or 'utf-16' (code units, as used by LSP)
+ */
+export const setPositionEncoding = (value: PositionEncoding): void => {
+  positionEncoding = value;
+};
+
+/**
+ * Get the unit of reported columns
+ */
+export const getPositionEncoding = (): PositionEncoding => positionEncoding;
+
+/**
+ * Write a line to stdout
+ *
+ * @param line - Text to write (defaults to an empty line)
+ */
+export const print = (line = ''): void => {
+  process.stdout.write(line + '\n');
+};
+
+/**
+ * Escape a porcelain field so it cannot break record framing
+ *
+ * Backslash, tab, and newline are escaped as \\, \t, and \n.
+ */
+const escapeField = (field: string): string => {
+  return field.replace(/\\/g, '\\\\').replace(/\t/g, '\\t').replace(/\r?\n/g, '\\n');
+};
+
+/**
+ * Write one porcelain record: record type followed by tab-separated fields
+ *
+ * Fields are never reordered or removed within a format version; new fields
+ * are only appended. Missing values are written as empty fields.
+ *
+ * With --output ndjson the record is written as {"record": type, "fields": [...]},
+ * missing values as null.
+ *
+ * @param type - Record type (first column, e.g. 'index', 'skip', 'check')
+ * @param fields - Remaining columns
+ */
+export const printRecord = (type: string, fields: (string | number | undefined | null)[]): void => {
+  if (ndjson) {
+    print(JSON.stringify({ record: type, fields: fields.map((field) => field ?? null) }));
+    return;
+  }
+  const columns = [type, ...fields.map((field) => (field === undefined || field === null ? '' : String(field)))];
+  print(columns.map(escapeField).join('\t'));
+};
+
+/**
+ * Write one NDJSON record with named fields
+ *
+ * For commands whose records are worth addressing by name in jq; the record
+ * type is written first as "record".
+ *
+ * @param type - Record type
+ * @param fields - Named fields (serialized with JSON.stringify)
+ */
+export const printJsonRecord = (type: string, fields: Record<string, unknown>): void => {
+  print(JSON.stringify({ record: type, ...fields }));
+};
+
+/**
+ * Status label with color for diagnostic checks
+ *
+ * @param status - Check status
+ * @returns Fixed-width colored label
+ */
+const formatStatus = (status: CheckStatus): string => {
+  switch (status) {
+    case 'ok':
+      return chalk.green('[ OK ]');
+    case 'warn':
+      return chalk.yellow('[WARN]');
+    case 'fail':
+      return chalk.red('[FAIL]');
+    case 'skip':
+      return chalk.gray('[SKIP]');
+  }
+};
+
+/**
+ * Print a diagnostic check result with its fix hint (if any)
+ *
+ * Porcelain: check<TAB>status<TAB>name<TAB>detail<TAB>fix
+ *
+ * @param check - Diagnostic check result
+ */
+export const printCheck = (check: DiagnosticCheck): void => {
+  if (porcelain) {
+    printRecord('check', [check.status, check.name, check.detail, check.fix]);
+    return;
+  }
+
+  print(`${formatStatus(check.status)} ${check.name}: ${check.detail}`);
+  if (check.fix && (check.status === 'warn' || check.status === 'fail')) {
+    print(chalk.cyan(`       fix: ${check.fix}`));
+  }
+};
+
+/**
+ * Report a command failure and return its exit code
+ *
+ * Text format prints the message and hint as before; JSON format writes
+ * {"error": {code, message, file, position, hint, exit_code}} to stderr.
+ *
+ * @param exitCode - Exit code the command returns
+ * @param report - Error details
+ * @returns exitCode (so callers can `return reportError(...)`)
+ */
+export const reportError = (exitCode: ExitCode, report: CliErrorReport): ExitCode => {
+  if (format === 'json') {
+    process.stderr.write(JSON.stringify({ error: { ...report, exit_code: exitCode } }) + '\n');
+    return exitCode;
+  }
+
+  print(report.message);
+  if (report.hint) {
+    print(report.hint);
+  }
+  return exitCode;
+};
+
+/**
+ * Read a string field from error details
+ */
+const detailString = (details: Record<string, unknown>, keys: string[]): string | undefined => {
+  const key = keys.find((name) => typeof details[name] === 'string');
+  return key ? (details[key] as string) : undefined;
+};
+
+/**
+ * Build a structured report from a thrown error
+ *
+ * CindexError codes and suggestions are kept; file and position are taken
+ * from error details (file/file_path/path, line/column), YAML parse marks,
+ * or the path of a failed filesystem call. YAML columns follow the selected
+ * position encoding; detail columns are reported as given.
+ *
+ * @param error - Thrown value
+ * @returns Error report
+ */
+export const toErrorReport = (error: unknown): CliErrorReport => {
+  if (error instanceof yaml.YAMLException) {
+    const { name, buffer, position, line, column } = error.mark;
+    const lineText = buffer.slice(position - column, position);
+    return {
+      code: 'YAML_ERROR',
+      message: error.reason,
+      file: name || undefined,
+      position: {
+        line: line + 1,
+        column: positionEncoding === 'utf-8' ? utf16ToByteColumn(lineText, column + 1) : column + 1,
+      },
+    };
+  }
+
+  if (error instanceof CindexError) {
+    const report: CliErrorReport = { code: error.code, message: error.message, hint: error.suggestion };
+    if (typeof error.details === 'object' && error.details !== null) {
+      const details = error.details as Record<string, unknown>;
+      report.file = detailString(details, ['file', 'file_path', 'path']);
+      if (typeof details.line === 'number') {
+        report.position = { line: details.line, column: typeof details.column === 'number' ? details.column : 1 };
+      }
+    }
+    return report;
+  }
+
+  const errno = error as NodeJS.ErrnoException;
+  if (error instanceof Error && typeof errno.syscall === 'string') {
+    return { code: 'FILE_SYSTEM_ERROR', message: error.message, file: errno.path };
+  }
+
+  return { code: 'INTERNAL_ERROR', message: error instanceof Error ? error.message : String(error) };
+};
+
+/**
+ * Report an error that escaped a command and choose the exit code
+ *
+ * Known errors (configuration, connectivity) are failures; anything else is a bug.
+ *
+ * @param error - Thrown value
+ * @returns ExitCode.Failure for CindexError, otherwise ExitCode.InternalError
+ */
+export const reportUncaughtError = (error: unknown): ExitCode => {
+  const exitCode = error instanceof CindexError ? ExitCode.Failure : ExitCode.InternalError;
+
+  if (format === 'json') {
+    const report = toErrorReport(error);
+    if (exitCode === ExitCode.InternalError) {
+      report.hint = 'This is a bug; please report it with the output of the same command using --format text';
+    }
+    return reportError(exitCode, report);
+  }
+
+  if (error instanceof CindexError) {
+    console.error(error.getFormattedMessage());
+  } else {
+    logger.errorWithStack('Command failed', error instanceof Error ? error : new Error(String(error)));
+  }
+  return exitCode;
+};
@@ -0,0 +1,323 @@
+/**
+ * CLI command: owners
+ * Report primary contributors and bus factor per directory or symbol
+ *
+ *   cindex owners                          per directory
+ *   cindex owners internal/ --by symbol    per function, method, and class under internal/
+ *   cindex owners --depth 1 --csv > owners.csv
+ *   cindex owners CreateSession            one symbol, with the commit that last changed it
+ *
+ * Authorship comes from git blame of the indexed repository, so the index
+ * must be of a git worktree on this machine. Files are those in the index;
+ * symbol spans come from the last indexing run. A name that matches no
+ * indexed path is looked up as a symbol; its last change is the one
+ * recorded by `cindex index --history`, or read from blame when none was.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { buildDirectoryOwnership, buildSymbolOwnership, toOwnershipCsv, type OwnershipRow } from '@cli/ownership';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedFiles, listIndexedRepositories, listSymbolHistory, listSymbolSpans } from '@database/queries';
+import { BLAME_CONCURRENCY, blameFile, blameFileLines, lastChange, type BlameLine } from '@indexing/git-blame';
+import { toStoredPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type SymbolHistoryRecord } from '@/types/database';
+import { type LastChange } from '@/types/indexing';
+
+/** Symbol name: an identifier, optionally qualified (AuthService.CreateSession, auth.Login) */
+const SYMBOL_NAME = /^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$/;
+
+/** Authors listed per row in the text report */
+const AUTHORS_SHOWN = 3;
+
+/**
+ * Check whether a stored path is inside a path prefix ('' matches everything)
+ */
+const isUnder = (filePath: string, prefix: string): boolean => {
+  return prefix === '' || filePath === prefix || filePath.startsWith(`${prefix}/`);
+};
+
+/**
+ * Percentage with no decimals
+ */
+const percent = (share: number): string => `${(share * 100).toFixed(0)}%`;
+
+/**
+ * Print rows as a text table
+ */
+const printRows = (rows: OwnershipRow[]): void => {
+  const theme = getTheme();
+  const label = (row: OwnershipRow): string =>
+    row.symbol ? `${row.path}:${String(row.line)} ${row.symbol}` : row.path;
+  const width = Math.max(...rows.map((row) => label(row).length), 'Path'.length);
+
+  print(theme.dim(`${'Path'.padEnd(width)}  ${'Lines'.padStart(7)}  ${'Bus'.padStart(3)}  Authors`));
+  for (const row of rows) {
+    const authors = row.authors
+      .slice(0, AUTHORS_SHOWN)
+      .map((author) => `${author.author} ${percent(author.share)}`)
+      .join(', ');
+    const more = row.authors.length > AUTHORS_SHOWN ? theme.dim(` +${String(row.authors.length - AUTHORS_SHOWN)}`) : '';
+    const bus = String(row.bus_factor).padStart(3);
+    print(
+      `${theme.path(label(row).padEnd(width))}  ${String(row.lines).padStart(7)}  ` +
+        `${row.bus_factor === 1 ? theme.match(bus) : bus}  ${authors}${more}`
+    );
+  }
+
+  const concentrated = rows.filter((row) => row.bus_factor === 1).length;
+  print();
+  print(
+    `${String(concentrated)} of ${String(rows.length)} with a bus factor of 1 (one author owns over half the lines)`
+  );
+};
+
+/**
+ * Print ownership rows as porcelain records
+ *
+ * Porcelain: owner<TAB>scope<TAB>path<TAB>symbol<TAB>line<TAB>lines<TAB>primary<TAB>primary_share
+ *            <TAB>bus_factor<TAB>authors
+ */
+const printOwnerRecords = (rows: OwnershipRow[]): void => {
+  for (const row of rows) {
+    const share = row.authors.length > 0 ? row.authors[0].share.toFixed(3) : null;
+    printRecord('owner', [
+      row.scope,
+      row.path,
+      row.symbol,
+      row.line,
+      row.lines,
+      row.primary,
+      share,
+      row.bus_factor,
+      row.authors.length,
+    ]);
+  }
+};
+
+/**
+ * Report an index whose files git cannot blame
+ */
+const noGitHistory = (repoId: string, repoPath: string): ExitCode => {
+  return reportError(ExitCode.Failure, {
+    code: 'NO_GIT_HISTORY',
+    message: `No git history for the files of '${repoId}' in ${repoPath}`,
+    hint: 'cindex owners reads authorship with git blame; index a git worktree on this machine',
+  });
+};
+
+/**
+ * Last change to a symbol, as reported
+ */
+interface SymbolChange extends LastChange {
+  path: string;
+  symbol: string;
+  line: number;
+  /** Recorded by cindex index --history, rather than read from blame now */
+  recorded: boolean;
+}
+
+/**
+ * Report the owners and last change of the symbols a name matches
+ *
+ * Porcelain (after the owner records):
+ *   changed<TAB>path<TAB>symbol<TAB>line<TAB>commit<TAB>author<TAB>date<TAB>recorded
+ *
+ * @param symbols - Symbols matching the name, with any recorded history
+ * @param format - Report format flags
+ */
+const reportSymbolOwners = async (
+  repoId: string,
+  repoPath: string,
+  name: string,
+  symbols: SymbolHistoryRecord[],
+  format: { json: boolean; csv: boolean }
+): Promise<ExitCode> => {
+  const paths = [...new Set(symbols.map((symbol) => symbol.file_path))];
+  const blamed = await Promise.all(paths.map((filePath) => blameFileLines(repoPath, filePath)));
+  const lines = new Map<string, (BlameLine | null)[]>();
+  paths.forEach((filePath, index) => {
+    const blame = blamed[index];
+    if (blame) lines.set(filePath, blame);
+  });
+  if (lines.size === 0) return noGitHistory(repoId, repoPath);
+
+  const authors = new Map([...lines].map(([filePath, blame]) => [filePath, blame.map((line) => line?.author ?? null)]));
+  const spans = symbols.map((symbol) => ({ ...symbol, end_line: symbol.end_line ?? symbol.line_number }));
+  const rows = buildSymbolOwnership(authors, spans);
+  const changes: SymbolChange[] = spans.flatMap((symbol) => {
+    const where = { path: symbol.file_path, symbol: symbol.symbol_name, line: symbol.line_number };
+    if (symbol.last_commit && symbol.last_modified) {
+      const date = new Date(symbol.last_modified).toISOString();
+      return [{ ...where, commit: symbol.last_commit, author: symbol.last_author, date, recorded: true }];
+    }
+    const blame = lines.get(symbol.file_path);
+    const change = blame ? lastChange(blame, symbol.line_number, symbol.end_line) : null;
+    return change ? [{ ...where, ...change, recorded: false }] : [];
+  });
+
+  if (format.json) {
+    const report = { repo_id: repoId, symbol: name, by: 'symbol', files: lines.size, rows, changes };
+    process.stdout.write(JSON.stringify(report, null, 2) + '\n');
+  } else if (format.csv) {
+    process.stdout.write(toOwnershipCsv(rows));
+  } else if (isPorcelain()) {
+    printOwnerRecords(rows);
+    for (const change of changes) {
+      printRecord('changed', [
+        change.path,
+        change.symbol,
+        change.line,
+        change.commit,
+        change.author,
+        change.date,
+        change.recorded,
+      ]);
+    }
+  } else if (rows.length === 0) {
+    print(`No committed lines in ${name}`);
+  } else {
+    printRows(rows);
+    const theme = getTheme();
+    print();
+    for (const change of changes) {
+      const location = theme.path(`${change.path}:${String(change.line)} ${change.symbol}`);
+      const author = change.author ?? 'unknown author';
+      print(`Last changed ${location}  ${change.commit.slice(0, 12)}  ${author}  ${change.date.slice(0, 10)}`);
+    }
+  }
+  return rows.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+};
+
+/**
+ * Owners command - authorship concentration from git blame
+ */
+export const ownersCommand: CliCommand = {
+  name: 'owners',
+  description: 'Report primary contributors and bus factor per directory or symbol (git blame)',
+  usage: 'cindex owners [<path>|<symbol>] [--by directory|symbol] [--depth <n>] [--json | --csv] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'by', description: 'Group by directory (default) or symbol', takesValue: true },
+    { name: 'depth', description: 'Roll directories up to this many levels', takesValue: true },
+    { name: 'json', description: 'Write the report as JSON' },
+    { name: 'csv', description: 'Write the report as CSV' },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        by: { type: 'string', default: 'directory' },
+        depth: { type: 'string' },
+        json: { type: 'boolean', default: false },
+        csv: { type: 'boolean', default: false },
+      },
+    });
+
+    if (values.by !== 'directory' && values.by !== 'symbol') {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --by value: ${values.by}`,
+        hint: 'Expected directory or symbol',
+      });
+    }
+    const depth = values.depth !== undefined ? Number(values.depth) : undefined;
+    if (depth !== undefined && (!Number.isInteger(depth) || depth < 0)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --depth value: ${values.depth ?? ''}`,
+        hint: 'Expected a number of directory levels, such as 1 or 2',
+      });
+    }
+    if (values.json && values.csv) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Pass either --json or --csv' });
+    }
+
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repo = (await listIndexedRepositories(pool)).find((candidate) => candidate.repo_id === repoId);
+      if (!repo?.repo_path) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+
+      const repoPath = repo.repo_path;
+      const target = positionals[0] ?? '';
+      const prefix = toStoredPath(target).replace(/\/+$/, '');
+      const scope = prefix === '.' ? '' : prefix;
+      const { files, spans, symbols } = await readIndex(repoId, async () => {
+        const indexed = (await listIndexedFiles(pool, repoId)).filter((file) => isUnder(file.file_path, scope));
+        // A name no indexed path matches is a symbol: CreateSession, AuthService.CreateSession, auth.Login
+        const named = indexed.length === 0 && SYMBOL_NAME.test(target);
+        return {
+          files: indexed,
+          spans: values.by === 'symbol' ? await listSymbolSpans(pool, repoId) : [],
+          symbols: named ? await listSymbolHistory(pool, target, repoId) : [],
+        };
+      });
+      if (symbols.length > 0) {
+        return await reportSymbolOwners(repoId, repoPath, target, symbols, { json: values.json, csv: values.csv });
+      }
+      if (target !== '' && files.length === 0) {
+        if (!isPorcelain()) print(`No indexed path or symbol matches ${target}`);
+        return ExitCode.NoResults;
+      }
+
+      const blame = new Map<string, (string | null)[]>();
+      for (let i = 0; i < files.length; i += BLAME_CONCURRENCY) {
+        const batch = files.slice(i, i + BLAME_CONCURRENCY);
+        const results = await Promise.all(batch.map((file) => blameFile(repoPath, file.file_path)));
+        results.forEach((authors, index) => {
+          if (authors) blame.set(batch[index].file_path, authors);
+        });
+      }
+
+      if (files.length > 0 && blame.size === 0) {
+        return noGitHistory(repoId, repoPath);
+      }
+
+      const rows =
+        values.by === 'symbol'
+          ? buildSymbolOwnership(blame, spans.filter((span) => isUnder(span.file_path, scope)))
+          : buildDirectoryOwnership(blame, depth);
+
+      if (values.json) {
+        const report = { repo_id: repoId, path: scope || '.', by: values.by, files: blame.size, rows };
+        process.stdout.write(JSON.stringify(report, null, 2) + '\n');
+      } else if (values.csv) {
+        process.stdout.write(toOwnershipCsv(rows));
+      } else if (isPorcelain()) {
+        printOwnerRecords(rows);
+      } else if (rows.length === 0) {
+        print(scope ? `No committed lines under ${scope}` : 'No committed lines');
+      } else {
+        printRows(rows);
+        if (blame.size < files.length) {
+          print(getTheme().dim(`(${String(files.length - blame.size)} files not in git history were skipped)`));
+        }
+      }
+      return rows.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,182 @@
+/**
+ * Ownership report: primary contributors and bus factor (cindex owners)
+ *
+ * Ownership is measured in lines as they are today (from git blame), not in
+ * commits: an author who wrote a module and one who renamed a variable in
+ * every file should not weigh the same. The bus factor of a directory or
+ * symbol is the fewest authors who together own more than half of its lines;
+ * 1 means a single person holds most of the knowledge.
+ */
+import * as path from 'node:path';
+
+import { compareStrings } from '@utils/ordering';
+import { type FunctionSpanRecord } from '@/types/database';
+
+/** Share of lines the bus factor's authors must own together */
+export const BUS_FACTOR_SHARE = 0.5;
+
+/**
+ * Lines one author owns
+ */
+export interface AuthorShare {
+  author: string;
+  lines: number;
+  /** Fraction of the committed lines */
+  share: number;
+}
+
+/**
+ * Ownership of one directory or symbol
+ */
+export interface OwnershipRow {
+  scope: 'directory' | 'symbol';
+  /** Directory ('.' for the root), or the file of a symbol */
+  path: string;
+  symbol: string | null;
+  line: number | null;
+  /** Committed lines (uncommitted lines are not counted) */
+  lines: number;
+  authors: AuthorShare[];
+  primary: string | null;
+  bus_factor: number;
+}
+
+/**
+ * Rank authors by lines owned and compute the bus factor
+ *
+ * @param counts - Lines per author
+ * @returns Authors, most lines first, with the primary author and bus factor
+ */
+export const summarizeAuthors = (
+  counts: Map<string, number>
+): Pick<OwnershipRow, 'lines' | 'authors' | 'primary' | 'bus_factor'> => {
+  const lines = [...counts.values()].reduce((sum, count) => sum + count, 0);
+  const authors = [...counts]
+    .map(([author, count]) => ({ author, lines: count, share: lines > 0 ? count / lines : 0 }))
+    .sort((a, b) => b.lines - a.lines || compareStrings(a.author, b.author));
+
+  let busFactor = 0;
+  let owned = 0;
+  for (const { share } of authors) {
+    if (owned > BUS_FACTOR_SHARE) break;
+    owned += share;
+    busFactor++;
+  }
+  return { lines, authors, primary: authors.length > 0 ? authors[0].author : null, bus_factor: busFactor };
+};
+
+/**
+ * Directory a file is reported under
+ *
+ * @param filePath - Stored file path
+ * @param depth - Keep at most this many directory levels (default: the file's own directory)
+ */
+export const directoryOf = (filePath: string, depth?: number): string => {
+  const dir = path.posix.dirname(filePath);
+  if (dir === '.' || depth === undefined) return dir;
+  return depth <= 0 ? '.' : dir.split('/').slice(0, depth).join('/');
+};
+
+/**
+ * Count lines per author over a range of blamed lines
+ */
+const countAuthors = (authors: (string | null)[], counts = new Map<string, number>()): Map<string, number> => {
+  for (const author of authors) {
+    if (author !== null) counts.set(author, (counts.get(author) ?? 0) + 1);
+  }
+  return counts;
+};
+
+/**
+ * Build the ownership rows of directories
+ *
+ * @param blame - Author per line of each file
+ * @param depth - Directory levels to roll up to (default: each file's directory)
+ * @returns One row per directory, sorted by path
+ */
+export const buildDirectoryOwnership = (blame: Map<string, (string | null)[]>, depth?: number): OwnershipRow[] => {
+  const directories = new Map<string, Map<string, number>>();
+  for (const [filePath, authors] of blame) {
+    const dir = directoryOf(filePath, depth);
+    directories.set(dir, countAuthors(authors, directories.get(dir)));
+  }
+  return [...directories]
+    .map(([dir, counts]) => ({
+      scope: 'directory' as const,
+      path: dir,
+      symbol: null,
+      line: null,
+      ...summarizeAuthors(counts),
+    }))
+    .sort((a, b) => compareStrings(a.path, b.path));
+};
+
+/**
+ * Build the ownership rows of symbols
+ *
+ * @param blame - Author per line of each file
+ * @param symbols - Symbol spans (symbols without an end line are skipped)
+ * @returns One row per symbol with committed lines, in input order
+ */
+export const buildSymbolOwnership = (
+  blame: Map<string, (string | null)[]>,
+  symbols: FunctionSpanRecord[]
+): OwnershipRow[] => {
+  return symbols.flatMap((symbol) => {
+    const authors = blame.get(symbol.file_path);
+    if (!authors || symbol.end_line === null) return [];
+    const summary = summarizeAuthors(countAuthors(authors.slice(symbol.line_number - 1, symbol.end_line)));
+    if (summary.lines === 0) return [];
+    const { file_path, symbol_name, line_number } = symbol;
+    return [{ scope: 'symbol' as const, path: file_path, symbol: symbol_name, line: line_number, ...summary }];
+  });
+};
+
+/**
+ * Quote a CSV field when needed (RFC 4180)
+ */
+export const csvField = (value: string | number | null): string => {
+  const text = value === null ? '' : String(value);
+  return /[",\r\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+};
+
+/** CSV header of toOwnershipCsv */
+export const OWNERSHIP_CSV_COLUMNS = [
+  'scope',
+  'path',
+  'symbol',
+  'line',
+  'lines',
+  'primary',
+  'primary_share',
+  'bus_factor',
+  'authors',
+];
+
+/**
+ * Render ownership rows as CSV
+ *
+ * The authors column lists every author as `name:lines`, most lines first,
+ * separated by semicolons.
+ *
+ * @param rows - Ownership rows
+ * @returns CSV text with a header line
+ */
+export const toOwnershipCsv = (rows: OwnershipRow[]): string => {
+  const lines = rows.map((row) =>
+    [
+      row.scope,
+      row.path,
+      row.symbol,
+      row.line,
+      row.lines,
+      row.primary,
+      row.authors.length > 0 ? row.authors[0].share.toFixed(3) : null,
+      row.bus_factor,
+      row.authors.map((author) => `${author.author}:${String(author.lines)}`).join(';'),
+    ]
+      .map(csvField)
+      .join(',')
+  );
+  return [OWNERSHIP_CSV_COLUMNS.join(','), ...lines].join('\n') + '\n';
+};
@@ -0,0 +1,437 @@
+/**
+ * Package dependency graph: which packages import which (cindex graph)
+ *
+ * A package is a directory of an index, so Go packages map one to one and
+ * other languages group their modules by folder. Each indexed file's imports
+ * are resolved to a package: relative imports against the file's directory,
+ * Go imports against the module path of a cindex deps index or a module of
+ * the index (go.work members, nested go.mod files) or, failing that, the
+ * longest indexed directory the import path ends with, and
+ * dotted imports (Python, Java, Kotlin, C#) by the directories their
+ * segments name. Imports that resolve to no indexed package, such as the
+ * standard library and third-party packages, are external.
+ *
+ * Cycles are the strongly connected components of the graph, so a cycle
+ * through several indexes or Go modules (modules requiring each other) is
+ * reported like one inside a single module.
+ */
+import * as path from 'node:path';
+
+import { compareStrings } from '@utils/ordering';
+import { type FileImportsRecord } from '@/types/database';
+
+/**
+ * One package of the graph
+ */
+export interface PackageNode {
+  /** repo_id:directory ('.' for the root), or the import path of an external package */
+  id: string;
+  repo_id: string | null;
+  /** Directory within the index, or the import path of an external package */
+  path: string;
+  /** Indexed files in the package (0 for external packages) */
+  files: number;
+  external: boolean;
+  /** Go module path holding the package (null outside Go modules and for external packages) */
+  module: string | null;
+}
+
+/**
+ * Import cycle: packages that all import each other, directly or not
+ */
+export interface PackageCycle {
+  /** Members of the cycle, sorted */
+  packages: string[];
+  /** One shortest loop through the first member, in import order (the first member is not repeated) */
+  path: string[];
+  /** The cycle spans several indexes or Go modules */
+  cross_module: boolean;
+}
+
+/**
+ * Graph in JSON form (cindex graph --json)
+ */
+export interface DependencyGraphJson {
+  packages: PackageNode[];
+  imports: { from: string; to: string }[];
+  cycles: PackageCycle[];
+}
+
+/**
+ * Options for buildDependencyGraph
+ */
+export interface DependencyGraphOptions {
+  /** Keep packages outside the index as nodes (default: leave their imports out) */
+  external?: boolean;
+}
+
+/** Languages whose import paths separate segments with dots */
+const DOTTED_LANGUAGES = new Set(['python', 'java', 'kotlin', 'csharp']);
+
+/**
+ * Package id of a directory of an index
+ */
+export const packageId = (repoId: string, directory: string): string => `${repoId}:${directory}`;
+
+/**
+ * Module a package is in, for telling cycles across modules: its Go module, else its index
+ */
+const moduleOf = (node: PackageNode | undefined): string | null | undefined => node?.module ?? node?.repo_id;
+
+/**
+ * Directory-level graph of imports between packages
+ */
+export class DependencyGraph {
+  private readonly nodes = new Map<string, PackageNode>();
+  private readonly edges = new Map<string, Set<string>>();
+  private readonly reverse = new Map<string, Set<string>>();
+
+  /**
+   * Add a package, or count one more file of it
+   */
+  public addPackage = (node: Omit<PackageNode, 'files'>, files = 0): void => {
+    const existing = this.nodes.get(node.id);
+    if (existing) {
+      existing.files += files;
+      return;
+    }
+    this.nodes.set(node.id, { ...node, files });
+  };
+
+  /**
+   * Record that a package imports another (imports within a package are ignored)
+   */
+  public addImport = (from: string, to: string): void => {
+    if (from === to) return;
+    const targets = this.edges.get(from) ?? new Set<string>();
+    targets.add(to);
+    this.edges.set(from, targets);
+    const sources = this.reverse.get(to) ?? new Set<string>();
+    sources.add(from);
+    this.reverse.set(to, sources);
+  };
+
+  /**
+   * Packages ordered by id
+   */
+  public packages = (): PackageNode[] => [...this.nodes.values()].sort((a, b) => compareStrings(a.id, b.id));
+
+  /**
+   * Packages a package imports
+   */
+  public dependencies = (id: string): string[] => [...(this.edges.get(id) ?? [])].sort(compareStrings);
+
+  /**
+   * Packages importing a package (what imports auth?)
+   *
+   * @param id - Imported package
+   * @param transitive - Also the packages importing those, and so on
+   * @returns Importing packages ordered by id
+   */
+  public dependents = (id: string, transitive = false): string[] => {
+    const found = new Set<string>();
+    const queue = [id];
+    for (let current = queue.shift(); current !== undefined; current = queue.shift()) {
+      for (const source of this.reverse.get(current) ?? []) {
+        if (source === id || found.has(source)) continue;
+        found.add(source);
+        if (transitive) queue.push(source);
+      }
+    }
+    return [...found].sort(compareStrings);
+  };
+
+  /**
+   * Packages a name refers to: an id, a directory, the trailing directories of one (auth, internal/auth), or an
+   * external import path
+   */
+  public find = (name: string): string[] => {
+    const wanted = name.replace(/\/+$/, '');
+    const exact = this.nodes.get(wanted);
+    if (exact) return [exact.id];
+    return this.packages()
+      .filter((node) => node.path === wanted || node.path.endsWith(`/${wanted}`))
+      .map((node) => node.id);
+  };
+
+  /**
+   * Import cycles, largest first
+   *
+   * Each strongly connected component of more than one package is one cycle
+   * (Tarjan's algorithm), with one shortest loop through it as an example.
+   */
+  public cycles = (): PackageCycle[] => {
+    let counter = 0;
+    const index = new Map<string, number>();
+    const lowLink = new Map<string, number>();
+    const stack: string[] = [];
+    const onStack = new Set<string>();
+    const components: string[][] = [];
+
+    const connect = (id: string): void => {
+      index.set(id, counter);
+      lowLink.set(id, counter);
+      counter++;
+      stack.push(id);
+      onStack.add(id);
+
+      for (const target of this.dependencies(id)) {
+        if (!index.has(target)) {
+          connect(target);
+          lowLink.set(id, Math.min(lowLink.get(id) ?? 0, lowLink.get(target) ?? 0));
+        } else if (onStack.has(target)) {
+          lowLink.set(id, Math.min(lowLink.get(id) ?? 0, index.get(target) ?? 0));
+        }
+      }
+
+      if (lowLink.get(id) !== index.get(id)) return;
+      const component: string[] = [];
+      for (let member = stack.pop(); member !== undefined; member = stack.pop()) {
+        onStack.delete(member);
+        component.push(member);
+        if (member === id) break;
+      }
+      if (component.length > 1) components.push(component.sort(compareStrings));
+    };
+
+    for (const node of this.packages()) {
+      if (!index.has(node.id)) connect(node.id);
+    }
+
+    return components
+      .map((packages) => ({
+        packages,
+        path: this.shortestLoop(packages),
+        cross_module: new Set(packages.map((id) => moduleOf(this.nodes.get(id)))).size > 1,
+      }))
+      .sort((a, b) => b.packages.length - a.packages.length || compareStrings(a.packages[0], b.packages[0]));
+  };
+
+  /**
+   * Graph in JSON form
+   */
+  public toJSON = (): DependencyGraphJson => ({
+    packages: this.packages(),
+    imports: this.packages().flatMap((node) => this.dependencies(node.id).map((to) => ({ from: node.id, to }))),
+    cycles: this.cycles(),
+  });
+
+  /**
+   * Graph in Graphviz DOT (cindex graph --dot | dot -Tsvg)
+   *
+   * Imports inside a cycle are drawn red; external packages are dashed.
+   */
+  public toDot = (): string => {
+    const quote = (id: string): string => `"${id.replace(/["\\]/g, '\\$&')}"`;
+    const cyclic = new Map<string, number>();
+    this.cycles().forEach((cycle, number) => {
+      for (const id of cycle.packages) cyclic.set(id, number);
+    });
+
+    const lines = ['digraph packages {', '  rankdir=LR;', '  node [shape=box];'];
+    for (const node of this.packages()) {
+      if (node.external) lines.push(`  ${quote(node.id)} [style=dashed];`);
+      else if (!this.edges.has(node.id) && !this.reverse.has(node.id)) lines.push(`  ${quote(node.id)};`);
+    }
+    for (const node of this.packages()) {
+      for (const target of this.dependencies(node.id)) {
+        const inCycle = cyclic.has(node.id) && cyclic.get(node.id) === cyclic.get(target);
+        lines.push(`  ${quote(node.id)} -> ${quote(target)}${inCycle ? ' [color=red]' : ''};`);
+      }
+    }
+    lines.push('}');
+    return lines.join('\n') + '\n';
+  };
+
+  /**
+   * Shortest loop from the first member of a cycle back to it, through members only
+   */
+  private shortestLoop = (members: string[]): string[] => {
+    const [start] = members;
+    const inCycle = new Set(members);
+    const previous = new Map<string, string>();
+    const queue = [start];
+    for (let current = queue.shift(); current !== undefined; current = queue.shift()) {
+      for (const target of this.dependencies(current)) {
+        if (!inCycle.has(target)) continue;
+        if (target === start) {
+          const loop = [current];
+          for (let step = previous.get(current); step !== undefined; step = previous.get(step)) loop.push(step);
+          return loop.reverse();
+        }
+        if (previous.has(target)) continue;
+        previous.set(target, current);
+        queue.push(target);
+      }
+    }
+    return members;
+  };
+}
+
+/**
+ * Indexed directories, for resolving import paths
+ */
+class PackageIndex {
+  /** Directories of each index */
+  private readonly directories = new Map<string, Set<string>>();
+  /** Indexes by their directories */
+  private readonly byDirectory = new Map<string, string[]>();
+  /** Directories by each of their trailing paths (internal/auth: auth, internal/auth) */
+  private readonly byTrailingPath = new Map<string, { repoId: string; directory: string }[]>();
+  /** Indexes and directories by the Go module path they hold (cindex deps indexes, and modules of an index) */
+  private readonly modules = new Map<string, { repoId: string; directory: string }>();
+
+  public constructor(files: FileImportsRecord[]) {
+    for (const file of files) {
+      const directory = path.posix.dirname(file.file_path);
+      const known = this.directories.get(file.repo_id) ?? new Set<string>();
+      if (known.has(directory)) continue;
+      known.add(directory);
+      this.directories.set(file.repo_id, known);
+      this.byDirectory.set(directory, [...(this.byDirectory.get(directory) ?? []), file.repo_id]);
+      if (file.module_path) {
+        this.modules.set(file.module_path, { repoId: file.repo_id, directory: file.module_directory ?? '.' });
+      }
+
+      const segments = directory === '.' ? [] : directory.split('/');
+      for (let i = 0; i < segments.length; i++) {
+        const trailing = segments.slice(i).join('/');
+        this.byTrailingPath.set(trailing, [
+          ...(this.byTrailingPath.get(trailing) ?? []),
+          { repoId: file.repo_id, directory },
+        ]);
+      }
+    }
+  }
+
+  /**
+   * Check whether an index has a directory
+   */
+  public has = (repoId: string, directory: string): boolean => this.directories.get(repoId)?.has(directory) ?? false;
+
+  /**
+   * Package of a Go module holding an import path
+   */
+  public inModule = (importPath: string): string | null => {
+    const segments = importPath.split('/');
+    for (let i = segments.length; i > 0; i--) {
+      const module = this.modules.get(segments.slice(0, i).join('/'));
+      if (!module) continue;
+      const directory = path.posix.join(module.directory, ...segments.slice(i));
+      return this.has(module.repoId, directory) ? packageId(module.repoId, directory) : null;
+    }
+    return null;
+  };
+
+  /**
+   * Package of the longest indexed directory an import path ends with (Go: github.com/org/repo/internal/auth)
+   */
+  public endingOf = (importPath: string, repoId: string): string | null => {
+    const segments = importPath.split('/');
+    for (let i = 0; i < segments.length; i++) {
+      const directory = segments.slice(i).join('/');
+      const repos = this.byDirectory.get(directory);
+      if (repos) return packageId(repos.includes(repoId) ? repoId : repos[0], directory);
+    }
+    return null;
+  };
+
+  /**
+   * Package of the shortest indexed directory ending with an import path (Java: src/main/java/com/acme/auth)
+   */
+  public endingWith = (importPath: string, repoId: string): string | null => {
+    const candidates = this.byTrailingPath.get(importPath) ?? [];
+    const best = candidates
+      .slice()
+      .sort(
+        (a, b) =>
+          Number(b.repoId === repoId) - Number(a.repoId === repoId) ||
+          a.directory.length - b.directory.length ||
+          compareStrings(a.directory, b.directory)
+      )
+      .at(0);
+    return best ? packageId(best.repoId, best.directory) : null;
+  };
+}
+
+/**
+ * Resolve a relative import (./x, ../x, Python .x) against the file's directory
+ *
+ * @returns Imported path within the index, or null if the import is not relative
+ */
+const relativeTarget = (importPath: string, directory: string, language: string): string | null => {
+  if (importPath.startsWith('./') || importPath.startsWith('../')) {
+    return path.posix.normalize(path.posix.join(directory, importPath));
+  }
+  const dots = /^(\.+)(.*)$/.exec(importPath);
+  if (language === 'python' && dots) {
+    const up = Array.from({ length: dots[1].length - 1 }, () => '..');
+    return path.posix.normalize(path.posix.join(directory, ...up, dots[2].replace(/\./g, '/')));
+  }
+  return null;
+};
+
+/**
+ * Package an import refers to
+ *
+ * @returns Package id, or null if no indexed package holds the import
+ */
+const resolveImport = (index: PackageIndex, file: FileImportsRecord, importPath: string): string | null => {
+  const directory = path.posix.dirname(file.file_path);
+  const relative = relativeTarget(importPath, directory, file.language);
+  if (relative !== null) {
+    // A directory (./auth holding an index file) or a module file in one (./auth/session)
+    if (index.has(file.repo_id, relative)) return packageId(file.repo_id, relative);
+    const parent = path.posix.dirname(relative);
+    return index.has(file.repo_id, parent) ? packageId(file.repo_id, parent) : null;
+  }
+
+  if (file.language === 'go') {
+    const inModule = index.inModule(importPath);
+    if (inModule) return inModule;
+    // Standard library paths have no domain in their first element
+    if (!importPath.split('/')[0].includes('.')) return null;
+    return index.endingOf(importPath, file.repo_id);
+  }
+
+  // Root aliases (@/x, ~/x), Rust paths (crate::auth), and dotted module names
+  let normalized = importPath.replace(/^[@~]\//, '').replace(/::/g, '/').replace(/^crate\//, '');
+  if (DOTTED_LANGUAGES.has(file.language) && !normalized.includes('/')) normalized = normalized.replace(/\./g, '/');
+  return index.endingWith(normalized, file.repo_id) ?? index.endingWith(path.posix.dirname(normalized), file.repo_id);
+};
+
+/**
+ * Build the package graph of indexed files
+ *
+ * @param files - Indexed files with their import paths
+ * @param options - Whether to keep external packages
+ * @returns Graph of every indexed package, importing or not
+ */
+export const buildDependencyGraph = (
+  files: FileImportsRecord[],
+  options: DependencyGraphOptions = {}
+): DependencyGraph => {
+  const index = new PackageIndex(files);
+  const graph = new DependencyGraph();
+
+  for (const file of files) {
+    const directory = path.posix.dirname(file.file_path);
+    const from = packageId(file.repo_id, directory);
+    const node = { id: from, repo_id: file.repo_id, path: directory, external: false, module: file.module_path };
+    graph.addPackage(node, 1);
+
+    for (const raw of file.imports) {
+      // Python imports keep their alias (os as o)
+      const importPath = raw.trim().split(/\s+/)[0].replace(/^["'<]|["'>]$/g, '');
+      if (!importPath) continue;
+      const target = resolveImport(index, file, importPath);
+      if (target) {
+        graph.addImport(from, target);
+      } else if (options.external && !importPath.startsWith('.')) {
+        graph.addPackage({ id: importPath, repo_id: null, path: importPath, external: true, module: null });
+        graph.addImport(from, importPath);
+      }
+    }
+  }
+  return graph;
+};
@@ -0,0 +1,161 @@
+/**
+ * CLI command: platforms
+ * Platform matrix of a Go symbol defined per GOOS/GOARCH
+ *
+ *   cindex platforms Open
+ *   cindex platforms File.Fd --platforms linux/amd64,windows/amd64,plan9/amd64
+ *   cindex platforms newPoller --tags cgo
+ *
+ * Every build variant is indexed (file_linux.go and file_windows.go alike),
+ * and each file records its build constraint, so the matrix is evaluated
+ * from the index: which definition each platform compiles, the platforms
+ * with none (porting gaps), and those with several (redeclarations).
+ * Definitions are grouped by directory, one Go package each.
+ */
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listSymbolVariants } from '@database/queries';
+import {
+  DEFAULT_PLATFORMS,
+  parseConstraint,
+  parsePlatformList,
+  platformMatrix,
+  platformName,
+} from '@indexing/build-constraints';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type SymbolVariantRecord } from '@/types/database';
+
+/**
+ * How a platform is served: one definition, none, or several
+ */
+const platformStatus = (variants: number[]): 'defined' | 'missing' | 'duplicate' => {
+  if (variants.length === 0) return 'missing';
+  return variants.length === 1 ? 'defined' : 'duplicate';
+};
+
+/**
+ * Platforms command - which definition of a symbol each platform builds
+ */
+export const platformsCommand: CliCommand = {
+  name: 'platforms',
+  description: 'Show which GOOS/GOARCH builds each definition of a Go symbol',
+  usage: 'cindex platforms <name> [--platforms <list>] [--tags <list>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'platforms',
+      description: 'GOOS/GOARCH pairs to evaluate (default: first-class ports and wasm)',
+      takesValue: true,
+    },
+    { name: 'tags', description: 'Build tags to set, e.g. cgo,purego', takesValue: true },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        platforms: { type: 'string' },
+        tags: { type: 'string' },
+      },
+    });
+
+    const [target] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing symbol name',
+        hint: 'Usage: cindex platforms <name>, e.g. cindex platforms Open or cindex platforms File.Fd',
+      });
+    }
+    const requested = values.platforms !== undefined ? parsePlatformList(values.platforms) : undefined;
+    if (requested && (requested.invalid.length > 0 || requested.platforms.length === 0)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --platforms value: ${requested.invalid.join(', ') || (values.platforms ?? '')}`,
+        hint: 'Expected GOOS/GOARCH pairs such as linux/amd64,windows/amd64 (see: go tool dist list)',
+      });
+    }
+    const platforms = requested?.platforms ?? DEFAULT_PLATFORMS;
+    const tags = new Set((values.tags ?? '').split(',').map((tag) => tag.trim()).filter(Boolean));
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const variants = await readIndex(repoId, () => listSymbolVariants(db.getPool(), target, repoId));
+
+      // One Go package per directory of each index
+      const packages = new Map<string, SymbolVariantRecord[]>();
+      for (const variant of variants) {
+        const key = `${variant.repo_id ?? ''}\0${path.posix.dirname(variant.file_path)}`;
+        packages.set(key, [...(packages.get(key) ?? []), variant]);
+      }
+
+      const theme = getTheme();
+      const nameWidth = Math.max(...platforms.map((platform) => platformName(platform).length));
+      if (!isPorcelain() && variants.length === 0) print(`No Go definitions of ${target}`);
+
+      // Porcelain: variant<TAB>repo_id<TAB>path<TAB>line<TAB>type<TAB>constraint<TAB>valid
+      //            platform<TAB>repo_id<TAB>directory<TAB>goos/goarch<TAB>status<TAB>path<TAB>line (per definition)
+      for (const group of packages.values()) {
+        const directory = path.posix.dirname(group[0].file_path);
+        const matrix = platformMatrix(group.map((variant) => variant.build_constraint), platforms, tags);
+        const isValid = (variant: SymbolVariantRecord): boolean =>
+          variant.build_constraint === null || parseConstraint(variant.build_constraint) !== null;
+
+        if (isPorcelain()) {
+          for (const variant of group) {
+            const { repo_id, file_path, line_number, symbol_type, build_constraint } = variant;
+            const valid = isValid(variant) ? 1 : 0;
+            printRecord('variant', [repo_id, file_path, line_number, symbol_type, build_constraint, valid]);
+          }
+          matrix.forEach((indexes, row) => {
+            const fields = [group[0].repo_id, directory, platformName(platforms[row]), platformStatus(indexes)];
+            if (indexes.length === 0) printRecord('platform', [...fields, null, null]);
+            for (const index of indexes) {
+              printRecord('platform', [...fields, group[index].file_path, group[index].line_number]);
+            }
+          });
+          continue;
+        }
+
+        const plural = group.length === 1 ? 'definition' : 'definitions';
+        print(`${theme.kind(target)}  ${theme.path(directory)} ${theme.dim(`(${String(group.length)} ${plural})`)}`);
+        const locations = group.map((variant) => `${variant.file_path}:${String(variant.line_number)}`);
+        const locationWidth = Math.max(...locations.map((location) => location.length));
+        group.forEach((variant, index) => {
+          const constraint = variant.build_constraint ?? 'every platform';
+          const location = theme.path(locations[index].padEnd(locationWidth));
+          const malformed = isValid(variant) ? '' : theme.match('  (malformed constraint)');
+          print(`  [${String(index + 1)}] ${location}  ${constraint}${malformed}`);
+        });
+        print();
+
+        matrix.forEach((indexes, row) => {
+          const name = platformName(platforms[row]).padEnd(nameWidth);
+          const cells = indexes.map((index) => `[${String(index + 1)}]`).join(' ');
+          const status = platformStatus(indexes);
+          if (status === 'missing') print(`  ${name}  ${theme.match('missing')}`);
+          else if (status === 'duplicate') print(`  ${name}  ${cells}  ${theme.match('duplicate')}`);
+          else print(`  ${name}  ${cells}`);
+        });
+        const missing = matrix.filter((indexes) => indexes.length === 0).length;
+        const duplicates = matrix.filter((indexes) => indexes.length > 1).length;
+        const gaps = missing > 0 ? `, ${String(missing)} missing` : '';
+        const several = duplicates > 0 ? `, ${String(duplicates)} with several definitions` : '';
+        const defined = platforms.length - missing;
+        print(theme.dim(`  ${String(defined)} of ${String(platforms.length)} platforms defined${gaps}${several}`));
+        print();
+      }
+      return variants.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,136 @@
+/**
+ * CLI command: plugins
+ * List the extractor and analyzer plugins of PLUGINS, and the metrics analyzers stored
+ *
+ *   cindex plugins                              plugins loaded from PLUGINS
+ *   cindex plugins --metric sql_statements      stored values, highest first
+ *   cindex plugins --metric sql_statements --repo-id billing
+ *
+ * Listing loads every PLUGINS entry (plugin processes are started and shut
+ * down again), so it also checks that they load. Annotations reported by
+ * analyzers are listed by cindex annotations --kind plugin.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { loadConfig } from '@config/env';
+import { listPluginMetrics } from '@database/queries';
+import { closePlugins, listPlugins, loadPlugins } from '@indexing/plugins';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Load the PLUGINS entries and print what they registered
+ */
+const printPlugins = async (): Promise<ExitCode> => {
+  const specs = loadConfig().indexing.plugins;
+  try {
+    try {
+      await loadPlugins(specs);
+    } catch (error) {
+      return reportError(ExitCode.Failure, {
+        code: 'PLUGIN_ERROR',
+        message: error instanceof Error ? error.message : String(error),
+        hint: 'PLUGINS entries are module paths or exec:<command>, comma-separated',
+      });
+    }
+    const plugins = listPlugins();
+
+    // Porcelain: plugin<TAB>kind<TAB>name<TAB>languages<TAB>extensions<TAB>source
+    if (isPorcelain()) {
+      for (const { kind, name, languages, extensions, source } of plugins) {
+        printRecord('plugin', [kind, name, languages.join(','), extensions.join(','), source]);
+      }
+      return plugins.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    }
+    if (plugins.length === 0) {
+      print(specs.length > 0 ? 'The PLUGINS entries registered no plugins' : 'No plugins (set PLUGINS to load some)');
+      return ExitCode.NoResults;
+    }
+
+    const theme = getTheme();
+    const nameWidth = Math.max(...plugins.map((plugin) => plugin.name.length));
+    for (const plugin of plugins) {
+      const reads = plugin.kind === 'extractor' ? plugin.extensions.join(' ') : plugin.languages.join(' ') || 'all';
+      const kind = plugin.kind.padEnd('extractor'.length);
+      print(`${theme.kind(kind)}  ${plugin.name.padEnd(nameWidth)}  ${reads}  ${theme.dim(`(${plugin.source})`)}`);
+    }
+    return ExitCode.Success;
+  } finally {
+    await closePlugins();
+  }
+};
+
+/**
+ * Print the stored values of a plugin metric
+ *
+ * @param name - Metric name
+ * @param repoId - Restrict to one index (default: all indexes)
+ */
+const printMetric = async (name: string, repoId: string | undefined): Promise<ExitCode> => {
+  const { db } = await openSession();
+  try {
+    const metrics = await readIndex(repoId, () => listPluginMetrics(db.getPool(), name, repoId));
+
+    // Porcelain: metric<TAB>repo_id<TAB>path<TAB>line<TAB>symbol<TAB>analyzer<TAB>name<TAB>value
+    if (isPorcelain()) {
+      for (const { repo_id, file_path, line_number, symbol_name, analyzer, value } of metrics) {
+        printRecord('metric', [repo_id, file_path, line_number, symbol_name, analyzer, name, value]);
+      }
+      return metrics.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    }
+    if (metrics.length === 0) {
+      print(`No values of ${name} (is an analyzer reporting it in PLUGINS?)`);
+      return ExitCode.NoResults;
+    }
+
+    const theme = getTheme();
+    const valueWidth = Math.max(...metrics.map((metric) => String(metric.value).length));
+    for (const metric of metrics) {
+      const line = metric.line_number !== null ? `:${theme.line(String(metric.line_number))}` : '';
+      const symbol = metric.symbol_name ? theme.kind(metric.symbol_name) : theme.dim('(file)');
+      print(`${String(metric.value).padStart(valueWidth)}  ${theme.path(metric.file_path)}${line}  ${symbol}`);
+    }
+    print();
+    print(`${String(metrics.length)} values of ${name}`);
+    return ExitCode.Success;
+  } finally {
+    await db.close();
+  }
+};
+
+/**
+ * Plugins command - list plugins, or the values of a metric they reported
+ */
+export const pluginsCommand: CliCommand = {
+  name: 'plugins',
+  description: 'List extractor and analyzer plugins, or the values of a metric they reported',
+  usage: 'cindex plugins [--metric <name>] [--repo-id <name>]',
+  options: [
+    { name: 'metric', description: 'List the stored values of an analyzer metric', takesValue: true },
+    REPO_ID_OPTION,
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        metric: { type: 'string' },
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    if (values.metric === undefined) {
+      if (values['repo-id'] !== undefined) {
+        return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: '--repo-id requires --metric' });
+      }
+      return printPlugins();
+    }
+    if (!values.metric) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Missing metric name after --metric' });
+    }
+    return printMetric(values.metric, resolveRepoId(values['repo-id']));
+  },
+};
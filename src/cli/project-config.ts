@@ -0,0 +1,226 @@
+/**
+ * Project-level CLI configuration
+ *
+ * The .cindex.yaml files in the working directory and its ancestors hold
+ * team settings shared through version control (nearer files win):
+ *
+ *   settings:            # configuration variables (see README), applied
+ *     MAX_FILE_SIZE: 8000  # unless already set in the environment
+ *   aliases:
+ *     impl: search kind:type implements:$1
+ *
+ * The database password and the embedding API key are never read from
+ * project files, nor is PLUGINS: plugins run code, and a checkout being
+ * indexed may carry its own files. For the same reason the hosts cindex
+ * connects to are only taken from the environment: POSTGRES_HOST and
+ * POSTGRES_PORT receive the database password, and OLLAMA_HOST and
+ * EMBEDDING_API_BASE the code being summarized and embedded.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import * as yaml from 'js-yaml';
+
+import { loadConfig, validateConfig } from '@config/env';
+import { DIRECTORY_CONFIG_FILES } from '@indexing/directory-config';
+import { logger } from '@utils/logger';
+import { ENV_PREFIX, ENV_VARS } from '@/types/config';
+
+/** Settings that must never come from a shared file */
+const SECRET_SETTINGS = new Set<string>([ENV_VARS.POSTGRES_PASSWORD, ENV_VARS.EMBEDDING_API_KEY]);
+
+/** Settings naming code to run, only taken from the environment */
+const CODE_SETTINGS = new Set<string>([ENV_VARS.PLUGINS]);
+
+/** Settings naming a host credentials and code are sent to, only taken from the environment */
+const ENDPOINT_SETTINGS = new Set<string>([
+  ENV_VARS.POSTGRES_HOST,
+  ENV_VARS.POSTGRES_PORT,
+  ENV_VARS.OLLAMA_HOST,
+  ENV_VARS.EMBEDDING_API_BASE,
+]);
+
+/** Known configuration variable names */
+const SETTING_NAMES = new Set<string>(Object.values(ENV_VARS));
+
+/**
+ * Parsed project config file
+ */
+export interface ProjectConfigFile {
+  /** Absolute path of the .cindex.yaml file */
+  file: string;
+  /** Parsed YAML mapping */
+  doc: Record<string, unknown>;
+}
+
+/**
+ * Parse one config file, throwing on read or YAML errors
+ *
+ * @param file - Config file path
+ * @returns Parsed YAML document (unvalidated)
+ * @throws {YAMLException} If the file is not valid YAML (mark carries the position)
+ */
+export const parseProjectConfig = (file: string): unknown => {
+  return yaml.load(fs.readFileSync(file, 'utf-8'), { filename: file });
+};
+
+/**
+ * Read and parse one config file (null if missing or not a mapping)
+ */
+export const readProjectConfig = (file: string): ProjectConfigFile | null => {
+  let doc: unknown;
+  try {
+    doc = parseProjectConfig(file);
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+      logger.warn('Ignoring unreadable project config', {
+        file,
+        error: error instanceof Error ? error.message : String(error),
+      });
+    }
+    return null;
+  }
+
+  if (doc === null || doc === undefined) return { file, doc: {} };
+  if (typeof doc !== 'object' || Array.isArray(doc)) {
+    logger.warn('Ignoring project config (expected a mapping)', { file });
+    return null;
+  }
+  return { file, doc: doc as Record<string, unknown> };
+};
+
+/**
+ * Find .cindex.yaml files from a directory up to the filesystem root
+ *
+ * @param cwd - Directory to start from (default: process.cwd())
+ * @returns Parsed config files, nearest first
+ */
+export const findProjectConfigs = (cwd: string = process.cwd()): ProjectConfigFile[] => {
+  const configs: ProjectConfigFile[] = [];
+  let dir = path.resolve(cwd);
+
+  for (;;) {
+    const file = DIRECTORY_CONFIG_FILES.map((name) => path.join(dir, name)).find((candidate) =>
+      fs.existsSync(candidate)
+    );
+    const config = file ? readProjectConfig(file) : null;
+    if (config) configs.push(config);
+
+    const parent = path.dirname(dir);
+    if (parent === dir) break;
+    dir = parent;
+  }
+
+  return configs;
+};
+
+/**
+ * Extract a string-valued mapping under a top-level key
+ *
+ * Scalars (numbers, booleans) are converted to strings; other values are dropped.
+ */
+export const readMapping = (config: ProjectConfigFile, key: string): Record<string, string> => {
+  const raw = config.doc[key];
+  if (raw === undefined) return {};
+  if (typeof raw !== 'object' || raw === null || Array.isArray(raw)) {
+    logger.warn(`Ignoring ${key} (expected a mapping)`, { file: config.file });
+    return {};
+  }
+
+  const mapping: Record<string, string> = {};
+  for (const [name, value] of Object.entries(raw as Record<string, unknown>)) {
+    if (typeof value === 'string' || typeof value === 'number' || typeof value === 'boolean') {
+      mapping[name] = String(value);
+    } else if (Array.isArray(value) && value.every((item) => typeof item === 'string')) {
+      mapping[name] = value.join(',');
+    } else {
+      logger.warn(`Ignoring invalid ${key} entry`, { file: config.file, name });
+    }
+  }
+  return mapping;
+};
+
+/**
+ * Check whether a setting may come from a shared file (not a secret, code to run, or an endpoint)
+ *
+ * @param key - Configuration variable name, without the CINDEX_ prefix
+ */
+export const isSharedSetting = (key: string): boolean =>
+  !SECRET_SETTINGS.has(key) && !CODE_SETTINGS.has(key) && !ENDPOINT_SETTINGS.has(key);
+
+/**
+ * Filter settings to known configuration variables a shared file may set
+ *
+ * @param settings - Raw settings mapping
+ * @param source - File the settings came from (for log messages)
+ * @returns Accepted settings
+ */
+export const filterSettings = (settings: Record<string, string>, source: string): Record<string, string> => {
+  const accepted: Record<string, string> = {};
+  for (const [name, value] of Object.entries(settings)) {
+    const key = name.startsWith(ENV_PREFIX) ? name.slice(ENV_PREFIX.length) : name;
+    if (SECRET_SETTINGS.has(key)) {
+      logger.warn('Ignoring secret setting in project config (set it in the environment)', { file: source, key });
+    } else if (CODE_SETTINGS.has(key)) {
+      logger.warn('Ignoring plugins in project config (they run code; set them in the environment)', {
+        file: source,
+        key,
+      });
+    } else if (ENDPOINT_SETTINGS.has(key)) {
+      logger.warn('Ignoring endpoint in project config (set it in the environment)', { file: source, key });
+    } else if (!SETTING_NAMES.has(key)) {
+      logger.warn('Ignoring unknown setting in project config', { file: source, key });
+    } else {
+      accepted[key] = value;
+    }
+  }
+  return accepted;
+};
+
+/**
+ * Load merged project settings (nearest file wins)
+ *
+ * @param cwd - Directory to start from
+ * @returns Setting name (unprefixed) to value
+ */
+export const loadProjectSettings = (cwd: string = process.cwd()): Record<string, string> => {
+  const layers = findProjectConfigs(cwd).map((config) => filterSettings(readMapping(config, 'settings'), config.file));
+  return Object.assign({}, ...layers.reverse()) as Record<string, string>;
+};
+
+/**
+ * Apply project settings as environment defaults
+ *
+ * Variables already set in the environment (with or without CINDEX_ prefix) win.
+ *
+ * @param cwd - Directory to start from
+ * @returns Names of the settings that were applied
+ */
+export const applyProjectSettings = (cwd: string = process.cwd()): string[] => {
+  const applied: string[] = [];
+  for (const [key, value] of Object.entries(loadProjectSettings(cwd))) {
+    if (process.env[key] === undefined && process.env[ENV_PREFIX + key] === undefined) {
+      process.env[key] = value;
+      applied.push(key);
+    }
+  }
+  return applied;
+};
+
+/**
+ * Check that settings produce a valid configuration
+ *
+ * @param settings - Settings to validate on top of the current environment
+ * @throws {ConfigurationError} If any value is invalid
+ */
+export const validateSettings = (settings: Record<string, string>): void => {
+  const saved = process.env;
+  // Prefixed variables in the environment would shadow the values being checked
+  const overrides = Object.fromEntries(Object.entries(settings).map(([key, value]) => [ENV_PREFIX + key, value]));
+  process.env = { [ENV_VARS.POSTGRES_PASSWORD]: 'unused', ...saved, ...overrides };
+  try {
+    validateConfig(loadConfig());
+  } finally {
+    process.env = saved;
+  }
+};
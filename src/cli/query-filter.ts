@@ -0,0 +1,672 @@
+/**
+ * Query filter parsing for interactive symbol search
+ *
+ * Syntax: free-text terms plus field filters, e.g.
+ *   "auth kind:func path:internal/"
+ *   "-scope:internal"            (leading '-' negates a filter)
+ *   "license:none"               (files with no header or license file)
+ *   "coverage:<50 complexity:>10" (numeric comparisons: <, <=, >, >=, =)
+ *   "cognitive:>=15"             (cognitive complexity: nesting-weighted, see @indexing/complexity)
+ *   "implements:io.Reader"       (Go types satisfying an interface, from --typed indexing)
+ *   "lang:py"                    (language of the symbol's file)
+ *   "receiver:AuthService"       (Go methods by receiver type)
+ *   "generic:true"               (Go functions and types with type parameters; generic:false for the others)
+ *   "constraint:comparable"      (Go generics with a type parameter constrained by it, see constraintMatches)
+ *   "module:shop/api"            (Go module the symbol is in, by path or its trailing elements)
+ *   "word:service"               (a stemmed word of the name: NewAuthService, service_registry)
+ *   'id:"cindex gomod ..."'      (structured symbol ID, exactly as printed; see @indexing/symbol-ids)
+ *   "name:~^(get|set)User$"      (~: regular expression, for name, path, receiver, module, and word)
+ *   "path:internal/** -path:**_test.go" (globs: * within a directory, ** across them)
+ *   "kind:method (receiver:Session OR receiver:Token)" (OR, AND, and parentheses; -(...) negates a group)
+ *
+ * Terms and filters side by side are ANDed. A query parses into a tree of
+ * them (see QueryNode); the top-level terms and filters are kept apart so
+ * the database can apply the simple ones before its candidate limit, and
+ * the OR and parenthesized groups are evaluated on the candidates.
+ *
+ * Filters apply client-side so they can refine a previous result set
+ * without re-querying the database. Queries and names are compared in
+ * Unicode NFC, so accented identifiers match in either encoding form.
+ */
+import { type InterfaceCondition, type MetricCondition } from '@database/queries';
+import { identifierTokens, nameTokens } from '@indexing/tokenizer';
+import { toStoredPath } from '@utils/paths';
+import { normalizeUnicode } from '@utils/unicode';
+import { Language } from '@/types/indexing';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/**
+ * Filterable fields
+ */
+export const QUERY_FIELDS = [
+  'kind',
+  'path',
+  'scope',
+  'name',
+  'license',
+  'coverage',
+  'complexity',
+  'cognitive',
+  'implements',
+  'lang',
+  'receiver',
+  'generic',
+  'constraint',
+  'module',
+  'word',
+  'id',
+] as const;
+
+export type QueryField = (typeof QUERY_FIELDS)[number];
+
+/**
+ * Symbol kind aliases accepted by kind: (maps to code_symbols.symbol_type)
+ */
+export const KIND_ALIASES: Record<string, ResolvedSymbol['symbol_type']> = {
+  func: 'function',
+  function: 'function',
+  fn: 'function',
+  method: 'method',
+  class: 'class',
+  struct: 'class',
+  iface: 'interface',
+  interface: 'interface',
+  type: 'type',
+  var: 'variable',
+  variable: 'variable',
+  const: 'constant',
+  constant: 'constant',
+  field: 'field',
+  test: 'test',
+  bench: 'benchmark',
+  benchmark: 'benchmark',
+  fuzz: 'fuzz',
+  example: 'example',
+};
+
+/**
+ * Language aliases accepted by lang: (maps to code_files.language)
+ */
+export const LANGUAGE_ALIASES: Record<string, Language> = {
+  ts: Language.TypeScript,
+  tsx: Language.TypeScript,
+  js: Language.JavaScript,
+  jsx: Language.JavaScript,
+  py: Language.Python,
+  golang: Language.Go,
+  rs: Language.Rust,
+  'c++': Language.CPP,
+  cc: Language.CPP,
+  cs: Language.CSharp,
+  'c#': Language.CSharp,
+  rb: Language.Ruby,
+  kt: Language.Kotlin,
+};
+
+/**
+ * Language a lang: value names (an alias, or the stored name itself)
+ */
+const languageOf = (value: string): string => {
+  const lower = value.toLowerCase();
+  return LANGUAGE_ALIASES[lower] ?? lower;
+};
+
+/** Columns of the numeric filter fields */
+const METRIC_COLUMNS: Partial<Record<QueryField, MetricCondition['column']>> = {
+  coverage: 'coverage',
+  complexity: 'complexity',
+  cognitive: 'cognitive_complexity',
+};
+
+/**
+ * Single field filter
+ */
+export interface QueryFilter {
+  field: QueryField;
+  value: string;
+  negate: boolean;
+}
+
+/**
+ * Node of a parsed query tree
+ */
+export type QueryNode =
+  | { type: 'term'; term: string }
+  | { type: 'filter'; filter: QueryFilter }
+  | { type: 'and'; nodes: QueryNode[] }
+  | { type: 'or'; nodes: QueryNode[] }
+  | { type: 'not'; node: QueryNode };
+
+/**
+ * Parsed query: name terms and field filters, all of which must match
+ */
+export interface ParsedQuery {
+  terms: string[];
+  filters: QueryFilter[];
+  /** OR and parenthesized groups that must match too */
+  groups?: QueryNode[];
+}
+
+/**
+ * Check whether a string is a known filter field
+ */
+const isQueryField = (value: string): value is QueryField => {
+  return (QUERY_FIELDS as readonly string[]).includes(value);
+};
+
+/**
+ * Split query text into tokens: words, quoted phrases, and parentheses
+ *
+ * A parenthesis opens a group at the start of a word (after an optional -)
+ * and closes one at its end, unless the word opened it itself, so a value
+ * like name:~(get|set)User stays one token.
+ */
+const tokenizeQuery = (input: string): string[] => {
+  const tokens: string[] = [];
+  for (const match of input.matchAll(/(?:[^\s"]+|"[^"]*"?)+/g)) {
+    let word = match[0];
+    while (word.startsWith('(') || word.startsWith('-(')) {
+      tokens.push(word.startsWith('(') ? '(' : '-(');
+      word = word.slice(word.startsWith('(') ? 1 : 2);
+    }
+    let closing = 0;
+    const opened = (word.match(/\(/g) ?? []).length;
+    while (word.endsWith(')') && (word.match(/\)/g) ?? []).length > opened) {
+      closing++;
+      word = word.slice(0, -1);
+    }
+    if (word) tokens.push(word.replace(/"/g, ''));
+    for (let i = 0; i < closing; i++) tokens.push(')');
+  }
+  return tokens;
+};
+
+/**
+ * Parse one word: a field filter (optionally negated with '-') when field is known, else a term
+ */
+const parseWord = (token: string): QueryNode => {
+  const negate = token.startsWith('-');
+  const body = negate ? token.slice(1) : token;
+  const colon = body.indexOf(':');
+  if (colon > 0) {
+    const field = body.slice(0, colon).toLowerCase();
+    const value = body.slice(colon + 1);
+    if (isQueryField(field) && value.length > 0) return { type: 'filter', filter: { field, value, negate } };
+  }
+  return { type: 'term', term: token };
+};
+
+/**
+ * Parse a query string into terms, filters, and groups
+ *
+ * Grammar (OR binds looser than AND, which is implied between words):
+ *
+ *   query := and ('OR' and)*
+ *   and   := unary (['AND'] unary)*
+ *   unary := '(' query ')' | '-(' query ')' | word
+ *
+ * Words of the form field:value (optionally prefixed with '-') become
+ * filters when field is a known field; everything else is a name term. An
+ * unclosed group ends with the query, and a stray ')' is ignored.
+ *
+ * @param input - Raw query text
+ * @returns Parsed terms and filters, with the groups that are neither
+ */
+export const parseQuery = (input: string): ParsedQuery => {
+  const tokens = tokenizeQuery(normalizeUnicode(input).trim());
+  let position = 0;
+
+  const parseOr = (): QueryNode => {
+    const nodes = [parseAnd()];
+    while (tokens[position] === 'OR') {
+      position++;
+      nodes.push(parseAnd());
+    }
+    return nodes.length === 1 ? nodes[0] : { type: 'or', nodes };
+  };
+  const parseAnd = (): QueryNode => {
+    const nodes: QueryNode[] = [];
+    while (position < tokens.length && tokens[position] !== 'OR' && tokens[position] !== ')') {
+      const token = tokens[position++];
+      if (token === 'AND') continue;
+      if (token === '(' || token === '-(') {
+        const group = parseOr();
+        if (tokens[position] === ')') position++;
+        nodes.push(token === '(' ? group : { type: 'not', node: group });
+      } else {
+        nodes.push(parseWord(token));
+      }
+    }
+    return nodes.length === 1 ? nodes[0] : { type: 'and', nodes };
+  };
+
+  const query: ParsedQuery = { terms: [], filters: [] };
+  while (position < tokens.length) {
+    const node = parseOr();
+    // A stray ')' ends a group that was never opened
+    if (tokens[position] === ')') position++;
+
+    for (const part of node.type === 'and' ? node.nodes : [node]) {
+      if (part.type === 'term') query.terms.push(part.term);
+      else if (part.type === 'filter') query.filters.push(part.filter);
+      else query.groups = [...(query.groups ?? []), part];
+    }
+  }
+  return query;
+};
+
+/** Numeric comparison: optional operator, number, optional % (coverage:<50, complexity:>=10) */
+const NUMERIC_FILTER = /^(<=|>=|<|>|=)?(\d+(?:\.\d+)?)%?$/;
+
+/**
+ * Parse the value of a numeric filter
+ *
+ * @returns Operator and number, or null if the value is not a comparison
+ */
+const parseComparison = (expression: string): Omit<MetricCondition, 'column'> | null => {
+  const match = NUMERIC_FILTER.exec(expression);
+  if (!match) return null;
+  return { operator: (match[1] || '=') as MetricCondition['operator'], value: parseFloat(match[2]) };
+};
+
+/**
+ * Compare a symbol's metric with a numeric filter value
+ *
+ * Symbols without the metric (no coverage imported, not a function) never match.
+ */
+const compareMetric = (actual: number | null | undefined, expression: string): boolean => {
+  const comparison = parseComparison(expression);
+  if (!comparison || actual === null || actual === undefined) return false;
+  switch (comparison.operator) {
+    case '<':
+      return actual < comparison.value;
+    case '<=':
+      return actual <= comparison.value;
+    case '>':
+      return actual > comparison.value;
+    case '>=':
+      return actual >= comparison.value;
+    case '=':
+      return actual === comparison.value;
+  }
+};
+
+/**
+ * Metric filters the database can apply before its candidate limit
+ *
+ * Only positive filters are pushed down; negated ones are applied locally
+ * like the other fields.
+ *
+ * @param query - Parsed query
+ * @returns Conditions for SymbolSearchOptions.metrics
+ */
+export const metricConditions = (query: ParsedQuery): MetricCondition[] => {
+  return query.filters.flatMap((filter) => {
+    const column = METRIC_COLUMNS[filter.field];
+    if (filter.negate || !column) return [];
+    const comparison = parseComparison(filter.value);
+    return comparison ? [{ column, ...comparison }] : [];
+  });
+};
+
+/**
+ * Symbol types the database can filter on before its candidate limit
+ *
+ * @param query - Parsed query
+ * @returns Types of the positive kind filters, for SymbolSearchOptions.symbolTypes
+ */
+export const kindConditions = (query: ParsedQuery): string[] => {
+  return query.filters
+    .filter((filter) => filter.field === 'kind' && !filter.negate)
+    .map((filter) => {
+      const value = filter.value.toLowerCase();
+      return KIND_ALIASES[value] ?? value;
+    });
+};
+
+/**
+ * Languages the database can filter on before its candidate limit
+ *
+ * @param query - Parsed query
+ * @returns Languages of the positive lang filters, for SymbolSearchOptions.languages
+ */
+export const languageConditions = (query: ParsedQuery): string[] => {
+  return query.filters
+    .filter((filter) => filter.field === 'lang' && !filter.negate)
+    .map((filter) => languageOf(filter.value));
+};
+
+/**
+ * Parse the value of an implements: filter
+ *
+ * `Reader` names an interface in any package; `io.Reader` and
+ * `github.com/acme/shop/store.Store` also name its package, by import path or
+ * its last element.
+ *
+ * @param value - Filter value
+ * @returns Interface name and package, lowercased
+ */
+export const parseInterfaceFilter = (value: string): InterfaceCondition => {
+  const lower = value.toLowerCase();
+  const dot = lower.lastIndexOf('.');
+  return dot === -1 ? { name: lower, package: null } : { name: lower.slice(dot + 1), package: lower.slice(0, dot) };
+};
+
+/**
+ * Check whether a qualified interface (import/path.Name, or error) matches an implements: filter
+ */
+const interfaceMatches = (qualified: string, condition: InterfaceCondition): boolean => {
+  const { name, package: pkg } = parseInterfaceFilter(qualified);
+  if (name !== condition.name) return false;
+  const wanted = condition.package;
+  return wanted === null || (pkg !== null && (pkg === wanted || pkg.endsWith(`/${wanted}`)));
+};
+
+/**
+ * Interface filters the database can apply before its candidate limit
+ *
+ * @param query - Parsed query
+ * @returns Conditions of the positive implements filters, for SymbolSearchOptions.implements
+ */
+export const implementsConditions = (query: ParsedQuery): InterfaceCondition[] => {
+  return query.filters
+    .filter((filter) => filter.field === 'implements' && !filter.negate)
+    .map((filter) => parseInterfaceFilter(filter.value));
+};
+
+/**
+ * Read the value of a generic: filter
+ *
+ * @returns True or false, or null if the value is neither (such a filter matches nothing)
+ */
+const parseGenericFilter = (value: string): boolean | null => {
+  const lower = value.toLowerCase();
+  if (lower === 'true' || lower === 'yes') return true;
+  if (lower === 'false' || lower === 'no') return false;
+  return null;
+};
+
+/**
+ * Check whether a Go type parameter constraint matches a constraint: filter
+ *
+ * The filter names the whole constraint or one term of a union, with or
+ * without its tilde (int matches ~int | ~string), and a named constraint with
+ * or without its package (Ordered, constraints.Ordered, or
+ * golang.org/x/exp/constraints.Ordered all match constraints.Ordered).
+ *
+ * @param constraint - Constraint as written
+ * @param value - Filter value
+ */
+export const constraintMatches = (constraint: string, value: string): boolean => {
+  const wanted = value.toLowerCase().replace(/\s+/g, ' ').trim();
+  const whole = constraint.toLowerCase();
+  if (whole === wanted) return true;
+  return whole.split('|').some((part) => {
+    const term = part.trim();
+    if (term === wanted || term.replace(/^~/, '') === wanted.replace(/^~/, '')) return true;
+    const dot = term.lastIndexOf('.');
+    if (dot === -1) return false;
+    const [pkg, name] = [term.slice(0, dot), term.slice(dot + 1)];
+    const wantedDot = wanted.lastIndexOf('.');
+    if (wantedDot === -1) return name === wanted;
+    return name === wanted.slice(wantedDot + 1) && `/${wanted.slice(0, wantedDot)}`.endsWith(`/${pkg}`);
+  });
+};
+
+/**
+ * Generic filter the database can apply before its candidate limit
+ *
+ * A positive constraint: filter implies generic:true.
+ *
+ * @param query - Parsed query
+ * @returns Value for SymbolSearchOptions.generic, or undefined when the query does not filter on it
+ */
+export const genericCondition = (query: ParsedQuery): boolean | undefined => {
+  for (const filter of query.filters) {
+    if (filter.negate) continue;
+    if (filter.field === 'constraint') return true;
+    if (filter.field === 'generic') return parseGenericFilter(filter.value) ?? undefined;
+  }
+  return undefined;
+};
+
+/**
+ * Check whether a filter value is a regular expression or glob rather than plain text
+ */
+export const isPatternValue = (value: string): boolean => value.startsWith('~') || /[*?]/.test(value);
+
+/**
+ * Go module filters the database can apply before its candidate limit
+ *
+ * Patterns (~regex, globs) and negated filters are applied locally.
+ *
+ * @param query - Parsed query
+ * @returns Lowercase module paths of the positive module filters, for SymbolSearchOptions.modules
+ */
+export const moduleConditions = (query: ParsedQuery): string[] => {
+  return query.filters
+    .filter((filter) => filter.field === 'module' && !filter.negate && !isPatternValue(filter.value))
+    .map((filter) => filter.value.toLowerCase());
+};
+
+/**
+ * Name word filters the database can apply before its candidate limit
+ *
+ * Each word of a filter value must be in the name, as written or stemmed
+ * (word:authService asks for both auth and service). Patterns and negated
+ * filters are applied locally.
+ *
+ * @param query - Parsed query
+ * @returns Forms of each word (the word and its stem), for SymbolSearchOptions.words
+ */
+export const wordConditions = (query: ParsedQuery): string[][] => {
+  return query.filters
+    .filter((filter) => filter.field === 'word' && !filter.negate && !filter.value.startsWith('~'))
+    .flatMap((filter) => identifierTokens(filter.value).map(({ word, stem }) => [...new Set([word, stem])]));
+};
+
+/**
+ * Symbol ID filters the database can apply before its candidate limit
+ *
+ * IDs are matched exactly (they are case-sensitive); ~regex and negated
+ * filters are applied locally.
+ *
+ * @param query - Parsed query
+ * @returns IDs of the positive id filters, for SymbolSearchOptions.ids
+ */
+export const idConditions = (query: ParsedQuery): string[] => {
+  return query.filters
+    .filter((filter) => filter.field === 'id' && !filter.negate && !filter.value.startsWith('~'))
+    .map((filter) => filter.value);
+};
+
+/**
+ * Compile a ~regex filter value (case-insensitive); an invalid expression matches as plain text
+ */
+const valueRegex = (value: string): RegExp => {
+  try {
+    return new RegExp(value.slice(1), 'iu');
+  } catch {
+    return new RegExp(value.slice(1).replace(/[.*+?^${}()|[\]\\]/g, '\\$&'), 'iu');
+  }
+};
+
+/**
+ * Compile a path glob: * and ? within a directory, ** across directories, matched against the whole path
+ */
+const globRegex = (glob: string): RegExp => {
+  const source = glob
+    .split(/(\*\*\/?|\*|\?)/)
+    .map((part) => {
+      if (part === '**/') return '(?:.*/)?';
+      if (part === '**') return '.*';
+      if (part === '*') return '[^/]*';
+      if (part === '?') return '[^/]';
+      return part.replace(/[.+^${}()|[\]\\]/g, '\\$&');
+    })
+    .join('');
+  return new RegExp(`^${source}$`, 'iu');
+};
+
+/** Receiver type of a Go method declaration: func (s *Cache[K, V]) Get → Cache */
+const GO_RECEIVER = /^func\s*\(\s*(?:[\p{L}\p{N}_]+\s+)?\*?\s*([\p{L}_][\p{L}\p{N}_]*)/u;
+
+/**
+ * Receiver type of a Go method (AuthService for AuthService.Login or func (s *AuthService) Login)
+ */
+const receiverOf = (symbol: ResolvedSymbol): string | null => {
+  if (symbol.symbol_type !== 'method') return null;
+  const dot = symbol.symbol_name.lastIndexOf('.');
+  if (dot > 0) return symbol.symbol_name.slice(0, dot);
+  return GO_RECEIVER.exec(symbol.definition ?? '')?.[1] ?? null;
+};
+
+/**
+ * Check whether a symbol matches a single filter (ignoring negation)
+ */
+const matchesFilter = (symbol: ResolvedSymbol, filter: QueryFilter): boolean => {
+  const value = filter.value.toLowerCase();
+
+  switch (filter.field) {
+    case 'kind':
+      return symbol.symbol_type === (KIND_ALIASES[value] ?? value);
+    case 'path':
+      if (value.startsWith('~')) return valueRegex(filter.value).test(symbol.file_path);
+      if (isPatternValue(value)) return globRegex(toStoredPath(filter.value)).test(symbol.file_path);
+      // Stored paths use forward slashes; accept Windows-style filters too
+      return symbol.file_path.toLowerCase().includes(toStoredPath(value));
+    case 'scope':
+      return symbol.scope === value;
+    case 'name':
+      if (value.startsWith('~')) return valueRegex(filter.value).test(normalizeUnicode(symbol.symbol_name));
+      return normalizeUnicode(symbol.symbol_name).toLowerCase().includes(value);
+    case 'license':
+      // SPDX identifiers compare case-insensitively; `none` matches files without a license
+      return (symbol.license?.toLowerCase() ?? 'none') === value;
+    case 'coverage':
+      return compareMetric(symbol.coverage, value);
+    case 'complexity':
+      return compareMetric(symbol.complexity, value);
+    case 'cognitive':
+      return compareMetric(symbol.cognitive_complexity, value);
+    case 'implements':
+      return (symbol.implements ?? []).some((qualified) => interfaceMatches(qualified, parseInterfaceFilter(value)));
+    case 'lang':
+      return symbol.language === languageOf(value);
+    case 'receiver': {
+      const receiver = receiverOf(symbol);
+      if (receiver === null) return false;
+      if (value.startsWith('~')) return valueRegex(filter.value).test(receiver);
+      return receiver.toLowerCase() === value.replace(/^\*/, '');
+    }
+    case 'generic':
+      return parseGenericFilter(value) === ((symbol.type_parameters ?? []).length > 0);
+    case 'constraint':
+      // Type parameters are stored as "name constraint"
+      return (symbol.type_parameters ?? []).some((parameter) =>
+        constraintMatches(parameter.slice(parameter.indexOf(' ') + 1), filter.value)
+      );
+    case 'module': {
+      const module = symbol.package_name?.toLowerCase();
+      if (!module) return false;
+      if (value.startsWith('~')) return valueRegex(filter.value).test(module);
+      if (isPatternValue(value)) return globRegex(value).test(module);
+      // A module path or its trailing elements (shop/api matches github.com/acme/shop/api)
+      return module === value || module.endsWith(`/${value}`);
+    }
+    case 'word': {
+      const tokens = nameTokens(normalizeUnicode(symbol.symbol_name), symbol.language);
+      if (value.startsWith('~')) return tokens.some((token) => valueRegex(filter.value).test(token));
+      const wanted = identifierTokens(filter.value, symbol.language);
+      return wanted.length > 0 && wanted.every(({ word, stem }) => tokens.includes(word) || tokens.includes(stem));
+    }
+    case 'id':
+      if (!symbol.symbol_id) return false;
+      if (value.startsWith('~')) return valueRegex(filter.value).test(symbol.symbol_id);
+      return symbol.symbol_id === filter.value;
+  }
+};
+
+/**
+ * Check whether a symbol matches a query tree node
+ */
+const matchesNode = (symbol: ResolvedSymbol, node: QueryNode): boolean => {
+  switch (node.type) {
+    case 'term':
+      return normalizeUnicode(symbol.symbol_name).toLowerCase().includes(node.term.toLowerCase());
+    case 'filter':
+      return matchesFilter(symbol, node.filter) !== node.filter.negate;
+    case 'and':
+      return node.nodes.every((child) => matchesNode(symbol, child));
+    case 'or':
+      return node.nodes.some((child) => matchesNode(symbol, child));
+    case 'not':
+      return !matchesNode(symbol, node.node);
+  }
+};
+
+/**
+ * Write a query tree node back as query text (cindex explain)
+ */
+export const formatQueryNode = (node: QueryNode): string => {
+  switch (node.type) {
+    case 'term':
+      return node.term;
+    case 'filter':
+      return `${node.filter.negate ? '-' : ''}${node.filter.field}:${node.filter.value}`;
+    case 'and':
+      return node.nodes
+        .map((child) => (child.type === 'or' ? `(${formatQueryNode(child)})` : formatQueryNode(child)))
+        .join(' ');
+    case 'or':
+      return node.nodes.map(formatQueryNode).join(' OR ');
+    case 'not':
+      return `-(${formatQueryNode(node.node)})`;
+  }
+};
+
+/**
+ * Apply terms and filters to a symbol list
+ *
+ * Terms must all appear in the symbol name (case-insensitive); filters and groups are ANDed.
+ *
+ * @param symbols - Symbols to filter
+ * @param query - Parsed query
+ * @returns Matching symbols, original order preserved
+ */
+export const applyQuery = (symbols: ResolvedSymbol[], query: ParsedQuery): ResolvedSymbol[] => {
+  const terms = query.terms.map((term) => term.toLowerCase());
+
+  return symbols.filter(
+    (symbol) =>
+      terms.every((term) => normalizeUnicode(symbol.symbol_name).toLowerCase().includes(term)) &&
+      query.filters.every((filter) => matchesFilter(symbol, filter) !== filter.negate) &&
+      (query.groups ?? []).every((group) => matchesNode(symbol, group))
+  );
+};
+
+/**
+ * Candidates left after each term and filter, in the order applyQuery checks them (cindex explain)
+ *
+ * @param symbols - Symbols to filter
+ * @param query - Parsed query
+ * @returns One step per term, filter, and group with the symbols still matching
+ */
+export const traceQuery = (symbols: ResolvedSymbol[], query: ParsedQuery): { step: string; remaining: number }[] => {
+  const steps: { step: string; remaining: number }[] = [];
+  let remaining = symbols;
+  for (const term of query.terms) {
+    remaining = applyQuery(remaining, { terms: [term], filters: [] });
+    steps.push({ step: term, remaining: remaining.length });
+  }
+  for (const filter of query.filters) {
+    remaining = applyQuery(remaining, { terms: [], filters: [filter] });
+    steps.push({ step: formatQueryNode({ type: 'filter', filter }), remaining: remaining.length });
+  }
+  for (const group of query.groups ?? []) {
+    remaining = applyQuery(remaining, { terms: [], filters: [], groups: [group] });
+    const step = formatQueryNode(group);
+    steps.push({ step: group.type === 'or' ? `(${step})` : step, remaining: remaining.length });
+  }
+  return steps;
+};
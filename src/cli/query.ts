@@ -0,0 +1,303 @@
+/**
+ * CLI command: query
+ * Run many symbol searches in one process, results keyed by query ID
+ *
+ *   cindex query --stdin < queries.txt          one query per line: <id><TAB><query>, or a query alone
+ *   cindex query --stdin --output ndjson        one result record per query, for scripts
+ *   cindex query 'kind:method receiver:Store'   a single query, with the ID 1
+ *   cindex query --min-complexity 15            refactor candidates (complexity:>=15 added to each query)
+ *
+ * A line may also be a JSON object, {"id": "a", "query": "Login", "repo_id": "api"}.
+ * Queries use the syntax of cindex search and run a few at a time over one
+ * database connection pool, so scripts issuing thousands of lookups pay
+ * process startup once. Results come out in input order; a query that fails,
+ * or a line that is not one (under its line number), is reported under its ID
+ * and the rest continue. With --min-complexity a query returns every matching
+ * function, most complex first, instead of the first results in name order.
+ */
+import * as readline from 'node:readline';
+import { parseArgs } from 'node:util';
+
+import { type Pool } from 'pg';
+
+import { isNdjson, isPorcelain, print, printJsonRecord, printRecord, reportError } from '@cli/output';
+import { parseQuery, type ParsedQuery } from '@cli/query-filter';
+import { printSymbolRows, REPO_ID_OPTION, runSymbolSearch, streamSymbolSearch, symbolFields } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { recordUsage } from '@cli/usage-stats';
+import { compareStrings } from '@utils/ordering';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/** Queries run at once; more would queue on the connection pool */
+export const BATCH_QUERY_CONCURRENCY = 8;
+
+/**
+ * One query of a batch
+ */
+export interface BatchQuery {
+  /** Caller's key for the results (default: the line number) */
+  id: string;
+  /** Query in cindex search syntax */
+  query: string;
+  /** Index searched (default: that of the batch) */
+  repo_id?: string;
+  /** Why the line is not a query; it is reported as failed without running */
+  invalid?: string;
+}
+
+/**
+ * Results of one query of a batch
+ */
+export interface BatchResult {
+  id: string;
+  query: string;
+  symbols: ResolvedSymbol[];
+  /** Why the query failed (null when it ran) */
+  error: string | null;
+  duration_ms: number;
+}
+
+/**
+ * Read one input line as a query
+ *
+ * @param line - Line of input
+ * @param lineNumber - 1-based line number, the ID of a query given alone
+ * @returns Query (invalid for a JSON line that is not an object with a query), or null for blank lines and # comments
+ */
+export const parseBatchLine = (line: string, lineNumber: number): BatchQuery | null => {
+  const text = line.trim();
+  if (text === '' || text.startsWith('#')) return null;
+  if (text.startsWith('{')) {
+    let value: Partial<Record<keyof BatchQuery, unknown>> | null;
+    try {
+      value = JSON.parse(text) as typeof value;
+    } catch (error) {
+      const invalid = `Line ${String(lineNumber)}: ${error instanceof Error ? error.message : String(error)}`;
+      return { id: String(lineNumber), query: text, invalid };
+    }
+    if (typeof value?.query !== 'string') {
+      return { id: String(lineNumber), query: text, invalid: `Line ${String(lineNumber)}: "query" must be a string` };
+    }
+    return {
+      id: typeof value.id === 'string' || typeof value.id === 'number' ? String(value.id) : String(lineNumber),
+      query: value.query,
+      ...(typeof value.repo_id === 'string' && { repo_id: value.repo_id }),
+    };
+  }
+  const tab = line.indexOf('\t');
+  if (tab === -1) return { id: String(lineNumber), query: text };
+  return { id: line.slice(0, tab).trim() || String(lineNumber), query: line.slice(tab + 1).trim() };
+};
+
+/**
+ * Every symbol matching a query, most complex first (refactor candidates)
+ *
+ * Pages through the whole index rather than stopping at SEARCH_LIMIT, so no
+ * function above the threshold is left out.
+ */
+const searchByComplexity = async (
+  db: Pool,
+  query: ParsedQuery,
+  repoId?: string,
+  dependencies?: boolean
+): Promise<ResolvedSymbol[]> => {
+  const symbols: ResolvedSymbol[] = [];
+  for await (const page of streamSymbolSearch(db, query, { repoId, dependencies })) symbols.push(...page.symbols);
+  return symbols.sort(
+    (a, b) =>
+      (b.complexity ?? 0) - (a.complexity ?? 0) ||
+      compareStrings(a.file_path, b.file_path) ||
+      a.line_number - b.line_number
+  );
+};
+
+/**
+ * Run a batch of symbol searches, a few at a time
+ *
+ * @param db - Database connection pool
+ * @param queries - Queries, read as they are needed
+ * @param options.repoId - Index of queries that name none (default: all indexes)
+ * @param options.dependencies - Also search the Go modules indexed by cindex deps
+ * @param options.minComplexity - Only functions of at least this cyclomatic complexity (complexity:>=N), all of them,
+ *   most complex first
+ * @param options.concurrency - Queries run at once (default: BATCH_QUERY_CONCURRENCY)
+ * @returns Results in the order of the queries
+ */
+export const runBatchQuery = async function* (
+  db: Pool,
+  queries: Iterable<BatchQuery> | AsyncIterable<BatchQuery>,
+  options: { repoId?: string; dependencies?: boolean; minComplexity?: number; concurrency?: number } = {}
+): AsyncGenerator<BatchResult> {
+  const { concurrency = BATCH_QUERY_CONCURRENCY, minComplexity } = options;
+  const filter = minComplexity !== undefined ? ` complexity:>=${String(minComplexity)}` : '';
+  const run = async (query: BatchQuery): Promise<BatchResult> => {
+    if (query.invalid !== undefined) {
+      return { id: query.id, query: query.query, symbols: [], error: query.invalid, duration_ms: 0 };
+    }
+    const started = Date.now();
+    const repoId = query.repo_id ?? options.repoId;
+    try {
+      const parsed = parseQuery(`${query.query}${filter}`);
+      const search = minComplexity !== undefined ? searchByComplexity : runSymbolSearch;
+      const symbols = await readIndex(repoId, () => search(db, parsed, repoId, options.dependencies));
+      return { ...query, symbols, error: null, duration_ms: Date.now() - started };
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      return { ...query, symbols: [], error: message, duration_ms: Date.now() - started };
+    }
+  };
+
+  // A window of running queries, drained from the front so results keep input order
+  const running: Promise<BatchResult>[] = [];
+  for await (const query of queries) {
+    running.push(run(query));
+    if (running.length >= concurrency) yield await (running.shift() as Promise<BatchResult>);
+  }
+  while (running.length > 0) yield await (running.shift() as Promise<BatchResult>);
+};
+
+/**
+ * Print the results of one query
+ *
+ * Porcelain: query<TAB>id<TAB>count<TAB>error (empty when it ran)
+ *            result<TAB>id<TAB>kind<TAB>name<TAB>repo_id<TAB>file<TAB>line<TAB>symbol_id per symbol
+ * NDJSON:    {"record": "query", "id", "query", "count", "error", "symbols": [fields of cindex search symbol records]}
+ */
+const printBatchResult = (result: BatchResult): void => {
+  if (isNdjson()) {
+    const { id, query, symbols, error } = result;
+    printJsonRecord('query', { id, query, count: symbols.length, error, symbols: symbols.map(symbolFields) });
+    return;
+  }
+  if (isPorcelain()) {
+    printRecord('query', [result.id, result.symbols.length, result.error]);
+    for (const symbol of result.symbols) {
+      const { symbol_type, symbol_name, repo_id, file_path, line_number, symbol_id } = symbol;
+      printRecord('result', [result.id, symbol_type, symbol_name, repo_id, file_path, line_number, symbol_id]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const count = result.error ?? `${String(result.symbols.length)} results`;
+  print(`${theme.keyword(result.id)}  ${result.query}  ${theme.dim(`(${count})`)}`);
+  if (result.error === null) printSymbolRows(result.symbols, parseQuery(result.query));
+  print();
+};
+
+/**
+ * Read queries from standard input as lines arrive
+ */
+async function* readBatchInput(): AsyncGenerator<BatchQuery> {
+  const lines = readline.createInterface({ input: process.stdin, crlfDelay: Infinity });
+  let lineNumber = 0;
+  for await (const line of lines) {
+    const query = parseBatchLine(line, ++lineNumber);
+    if (query) yield query;
+  }
+}
+
+/**
+ * Query command - batch symbol search
+ */
+export const queryCommand: CliCommand = {
+  name: 'query',
+  description: 'Run many symbol searches in one process, one per line of stdin, results keyed by query ID',
+  usage: 'cindex query (--stdin | <query>) [--min-complexity <n>] [--repo-id <name>] [--deps] [--jobs <n>]',
+  local: true,
+  options: [
+    { name: 'stdin', description: 'Read one query per line: <id><TAB><query>, a query alone, or a JSON object' },
+    {
+      name: 'min-complexity',
+      description: 'Only functions of at least this cyclomatic complexity, as complexity:>=<n>',
+      takesValue: true,
+    },
+    REPO_ID_OPTION,
+    { name: 'deps', description: 'Also search the Go modules indexed with cindex deps' },
+    {
+      name: 'jobs',
+      description: `Queries run at once (default: ${String(BATCH_QUERY_CONCURRENCY)})`,
+      takesValue: true,
+    },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        stdin: { type: 'boolean', default: false },
+        'min-complexity': { type: 'string' },
+        'repo-id': { type: 'string' },
+        deps: { type: 'boolean', default: false },
+        jobs: { type: 'string' },
+      },
+    });
+
+    const minComplexity = values['min-complexity'] !== undefined ? Number(values['min-complexity']) : undefined;
+    if (minComplexity !== undefined && (!Number.isInteger(minComplexity) || minComplexity < 1)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --min-complexity value: ${values['min-complexity'] ?? ''}`,
+        hint: 'Expected a cyclomatic complexity such as 15 (see cindex search complexity:>=15)',
+      });
+    }
+    // --min-complexity alone lists every function above it
+    const single = positionals.join(' ').trim();
+    if (values.stdin === (single !== '') && (values.stdin || minComplexity === undefined)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: values.stdin ? '--stdin reads the queries: it takes no query argument' : 'Missing query',
+        hint: "e.g. printf 'a\\tLogin\\nb\\tkind:method receiver:Store\\n' | cindex query --stdin",
+      });
+    }
+    if (values.stdin && process.stdin.isTTY) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--stdin needs queries piped in',
+        hint: 'e.g. cindex query --stdin < queries.txt',
+      });
+    }
+    const concurrency = values.jobs !== undefined ? Number(values.jobs) : BATCH_QUERY_CONCURRENCY;
+    if (!Number.isInteger(concurrency) || concurrency < 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --jobs value: ${values.jobs ?? ''}`,
+        hint: 'Expected a number of queries run at once, e.g. --jobs 4',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { config, db } = await openSession();
+    try {
+      const started = Date.now();
+      const queries = values.stdin ? readBatchInput() : [{ id: '1', query: single }];
+      let total = 0;
+      let found = 0;
+      let failed = 0;
+      const options = { repoId, dependencies: values.deps, minComplexity, concurrency };
+      const results = runBatchQuery(db.getPool(), queries, options);
+      for await (const result of results) {
+        total++;
+        if (result.error !== null) failed++;
+        else if (result.symbols.length > 0) found++;
+        printBatchResult(result);
+      }
+      recordUsage(config, {
+        kind: 'query',
+        source: 'cli',
+        operation: 'query',
+        duration_ms: Date.now() - started,
+        repo_id: repoId,
+        results: total,
+      });
+
+      if (failed > 0) return failed === total ? ExitCode.Failure : ExitCode.PartialFailure;
+      return found > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,172 @@
+/**
+ * CLI command: refs
+ * List type-checked uses of a Go declaration
+ *
+ *   cindex refs Login                 every Login
+ *   cindex refs store.Memory.Get      one method, qualified by its package
+ *   cindex refs Handle                the Handle method of any receiver
+ *   cindex refs User.Role             a struct field
+ *   cindex refs Count --access write  assignments only
+ *
+ * References come from `cindex index --typed`: the Go type checker resolves
+ * each identifier to its declaration, so uses through renamed imports and
+ * method calls on variables are found, and a Handle of another type is not.
+ * Calls through an interface resolve to the interface's method. Each use
+ * reads, writes (assigned, incremented, or its address taken), or calls.
+ *
+ * Files edited since that run are resolved by gopls instead (see
+ * @retrieval/gopls); --no-gopls serves them from the index as of the run.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import {
+  hasGoReferences,
+  listGoReferences,
+  listIndexedFileVersions,
+  listIndexedRepositories,
+} from '@database/queries';
+import { GOPLS_DEFAULT_REMOTE, resolveReferences, type SourcedReference } from '@retrieval/gopls';
+import { ExitCode, type CliCommand, type CliOption } from '@/types/cli';
+import { type GoReferenceAccess } from '@/types/database';
+
+/** Access kinds --access takes */
+const ACCESS_KINDS: GoReferenceAccess[] = ['read', 'write', 'call'];
+
+/** Options selecting how edited Go files are resolved (cindex refs, cindex def) */
+export const GOPLS_OPTIONS: CliOption[] = [
+  {
+    name: 'gopls',
+    description: `gopls -remote address for edited files (default: ${GOPLS_DEFAULT_REMOTE}, the shared daemon)`,
+    takesValue: true,
+  },
+  { name: 'no-gopls', description: 'Serve edited files from the index too' },
+];
+
+/**
+ * Refs command - uses of a declaration from typed indexing
+ */
+export const refsCommand: CliCommand = {
+  name: 'refs',
+  description: 'List type-checked uses of a Go declaration (needs cindex index --typed)',
+  usage: 'cindex refs <name> [--access read,write,call] [--gopls <remote> | --no-gopls] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'access', description: 'Only uses that read, write, or call (comma-separated)', takesValue: true },
+    ...GOPLS_OPTIONS,
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        access: { type: 'string' },
+        gopls: { type: 'string', default: GOPLS_DEFAULT_REMOTE },
+        'no-gopls': { type: 'boolean', default: false },
+      },
+    });
+
+    const [target] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing declaration name',
+        hint: 'Usage: cindex refs <name>, e.g. cindex refs auth.Login or cindex refs Server.Handle',
+      });
+    }
+    const access = values.access?.split(',').map((kind) => kind.trim()) ?? [];
+    const unknown = access.find((kind) => !ACCESS_KINDS.includes(kind as GoReferenceAccess));
+    if (unknown !== undefined) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Unknown access kind: ${unknown}`,
+        hint: `Expected one or more of ${ACCESS_KINDS.join(', ')}, e.g. --access write,call`,
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const useGopls = !values['no-gopls'];
+      const indexed = await readIndex(repoId, async () => ({
+        references: await listGoReferences(pool, target, repoId),
+        typed: await hasGoReferences(pool, repoId),
+        versions: repoId && useGopls ? await listIndexedFileVersions(pool, repoId, '.go') : [],
+      }));
+
+      // gopls needs the repository on disk, so it only serves a selected index
+      const repoPath =
+        repoId && useGopls
+          ? (await listIndexedRepositories(pool)).find((repo) => repo.repo_id === repoId)?.repo_path
+          : undefined;
+      const resolved = repoPath
+        ? await resolveReferences(repoPath, indexed.references, indexed.versions, values.gopls)
+        : {
+            references: indexed.references.map((ref): SourcedReference => ({ ...ref, source: 'index' })),
+            edited: 0,
+            error: null,
+          };
+      const { edited, error: goplsError } = resolved;
+      // Uses gopls resolved carry no access kind, so a filter leaves them out
+      const references =
+        access.length > 0
+          ? resolved.references.filter((ref) => ref.access !== null && access.includes(ref.access))
+          : resolved.references;
+
+      // Porcelain: ref<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>target<TAB>package<TAB>symbol<TAB>source<TAB>access
+      //            gopls<TAB>edited<TAB>error (when files were edited since indexing)
+      if (isPorcelain()) {
+        if (edited > 0) printRecord('gopls', [edited, goplsError]);
+        for (const ref of references) {
+          printRecord('ref', [
+            ref.repo_id,
+            ref.file_path,
+            ref.line_number,
+            ref.column_number,
+            ref.target_name,
+            ref.target_package,
+            ref.symbol_name,
+            ref.source,
+            ref.access,
+          ]);
+        }
+      } else if (references.length === 0) {
+        print(
+          indexed.typed
+            ? `No references to ${target}`
+            : 'No type facts recorded (index a Go repository with: cindex index <path> --typed)'
+        );
+      } else {
+        const theme = getTheme();
+        for (const ref of references) {
+          const location = `${ref.file_path}:${String(ref.line_number)}:${String(ref.column_number)}`;
+          const symbol = ref.symbol_name ? `  ${theme.dim(`(in ${ref.symbol_name})`)}` : '';
+          const kind = ref.access && ref.access !== 'read' ? `  ${ref.access}` : '';
+          print(`${theme.path(location)}  ${theme.kind(ref.target_name)}${kind}${symbol}`);
+        }
+        print();
+        const files = new Set(references.map((ref) => ref.file_path)).size;
+        const targets = new Set(references.map((ref) => `${ref.target_package}.${ref.target_name}`)).size;
+        const of = targets > 1 ? ` of ${String(targets)} declarations` : '';
+        const fromGopls = references.filter((ref) => ref.source === 'gopls').length;
+        const via = edited > 0 && !goplsError ? ` (${String(fromGopls)} from gopls)` : '';
+        const writes = references.filter((ref) => ref.access === 'write').length;
+        const calls = references.filter((ref) => ref.access === 'call').length;
+        const accesses = `${String(writes)} writes, ${String(calls)} calls`;
+        print(`${String(references.length)} references${of} in ${String(files)} files: ${accesses}${via}`);
+      }
+      if (!isPorcelain() && goplsError) {
+        print(getTheme().dim(`gopls not used (${goplsError}); ${String(edited)} edited files may be out of date`));
+      }
+      return references.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,135 @@
+/**
+ * CLI command: related
+ * Suggest symbols related to one (see @retrieval/related-symbols)
+ *
+ *   cindex related CreateSession            SessionTimeout, generateSessionID, Login, ...
+ *   cindex related Service.Login -k 20
+ *   cindex related 'cindex gomod example.com/shop . `example.com/shop/auth`/Login().'
+ *
+ * The symbol is named like in search, or by its structured ID (the last
+ * field of `cindex search --porcelain`). Suggestions weigh files mentioning
+ * both, the Go call graph around it, and words their names share.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listRelatedCandidates } from '@database/queries';
+import { parseSymbolId } from '@indexing/symbol-ids';
+import { DEFAULT_RELATED, findRelatedSymbols } from '@retrieval/related-symbols';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Related command - symbols to read next to one
+ */
+export const relatedCommand: CliCommand = {
+  name: 'related',
+  description: 'Suggest symbols related to one, by shared files, call graph, and name',
+  usage: 'cindex related <name|symbol-id> [-k <n>] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'limit',
+      description: `Most symbols suggested (-k, default: ${String(DEFAULT_RELATED)})`,
+      takesValue: true,
+    },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        limit: { type: 'string', short: 'k' },
+      },
+    });
+
+    const [symbol] = positionals;
+    if (!symbol) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing symbol',
+        hint: 'Usage: cindex related <name|symbol-id>, e.g. cindex related CreateSession',
+      });
+    }
+    const limit = values.limit !== undefined ? Number(values.limit) : DEFAULT_RELATED;
+    if (!Number.isInteger(limit) || limit < 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --limit value: ${values.limit ?? ''}`,
+        hint: 'Expected a number of symbols, e.g. -k 20',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const filter = parseSymbolId(symbol) ? { ids: [symbol] } : { names: [symbol] };
+      const { matches, related } = await readIndex(repoId, async () => {
+        // Full names before last elements: Service.Login before Login
+        const found = await listRelatedCandidates(pool, { ...filter, repoId, limit: 50 });
+        const ordered = [...found].sort((a, b) => Number(b.symbol_name === symbol) - Number(a.symbol_name === symbol));
+        return { matches: ordered, related: ordered[0] ? await findRelatedSymbols(pool, ordered[0], limit) : [] };
+      });
+      const [target] = matches;
+      if (!target) {
+        return reportError(ExitCode.NoResults, {
+          code: 'SYMBOL_NOT_FOUND',
+          message: `No symbol '${symbol}' in the index`,
+          hint: "Find its name with 'cindex search <name>'",
+        });
+      }
+
+      // Porcelain: related<TAB>repo_id<TAB>path<TAB>line<TAB>kind<TAB>name<TAB>score<TAB>cooccurrence<TAB>call_graph
+      //            <TAB>name_similarity<TAB>symbol_id
+      if (isPorcelain()) {
+        for (const entry of related) {
+          printRecord('related', [
+            entry.repo_id,
+            entry.file_path,
+            entry.line_number,
+            entry.symbol_type,
+            entry.symbol_name,
+            entry.score.toFixed(3),
+            entry.cooccurrence.toFixed(3),
+            entry.call_graph.toFixed(3),
+            entry.name_similarity.toFixed(3),
+            entry.symbol_id,
+          ]);
+        }
+        return related.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      const theme = getTheme();
+      const location = `${target.file_path}:${String(target.line_number)}`;
+      print(`Related to ${target.symbol_type} ${theme.kind(target.symbol_name)} ${theme.dim(`(${location})`)}`);
+      const others = matches.filter((match) => match.symbol_name === target.symbol_name).length - 1;
+      if (others > 0) {
+        print(theme.dim(`${String(others)} more named ${target.symbol_name}; pass a symbol ID to choose`));
+      }
+      print();
+      if (related.length === 0) {
+        print('No related symbols found');
+        return ExitCode.NoResults;
+      }
+
+      const width = Math.max(...related.map((entry) => entry.symbol_name.length));
+      for (const entry of related) {
+        const signals = theme.dim(
+          `files ${entry.cooccurrence.toFixed(2)}  calls ${entry.call_graph.toFixed(2)}  ` +
+            `name ${entry.name_similarity.toFixed(2)}`
+        );
+        const where = theme.path(`${entry.file_path}:${String(entry.line_number)}`);
+        const name = `${entry.symbol_type.padEnd(9)} ${entry.symbol_name.padEnd(width)}`;
+        print(`  ${theme.number(entry.score.toFixed(2))}  ${name}  ${where}  ${signals}`);
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
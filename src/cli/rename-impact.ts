@@ -0,0 +1,305 @@
+/**
+ * CLI command: rename-impact
+ * List everything a rename would have to touch, before making it
+ *
+ *   cindex rename-impact auth.User.Role
+ *   cindex rename-impact auth.User.Role Kind     each line as the rename leaves it
+ *   cindex rename-impact store.Memory.Get --porcelain
+ *
+ * Declarations and uses come from `cindex index --typed`; struct tags,
+ * string literals, comments, and documents come from scanning the indexed
+ * files and the repository's tracked documents on disk (see @cli/impact).
+ * With a new name, declarations already using it in the same package and
+ * uses an unexported name would break are reported as conflicts.
+ */
+import { execFile } from 'node:child_process';
+import * as fs from 'node:fs/promises';
+import * as path from 'node:path';
+import { parseArgs, promisify } from 'node:util';
+
+import { type Pool } from 'pg';
+
+import {
+  IMPACT_CATEGORIES,
+  isCodeImpact,
+  isDocumentFile,
+  previewRename,
+  scanMentions,
+  sortImpact,
+  type ImpactEntry,
+} from '@cli/impact';
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import {
+  hasGoReferences,
+  listGoReferences,
+  listIndexedFiles,
+  listIndexedRepositories,
+  listSymbolVariants,
+} from '@database/queries';
+import { readSourceFile } from '@utils/edge-cases';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type GoReferenceRecord } from '@/types/database';
+
+const execFileAsync = promisify(execFile);
+
+/** Entries shown per category in the text report */
+const ENTRIES_SHOWN = 20;
+
+/**
+ * Documents tracked by git (documents are rarely indexed as code)
+ *
+ * @returns Paths relative to the root, or none if the repository is not a git worktree
+ */
+const listTrackedDocuments = async (repoPath: string): Promise<string[]> => {
+  try {
+    const { stdout } = await execFileAsync('git', ['-C', repoPath, 'ls-files', '-z'], { maxBuffer: 256 * 1024 * 1024 });
+    return stdout.split('\0').filter((file) => file !== '' && isDocumentFile(file));
+  } catch {
+    return [];
+  }
+};
+
+/**
+ * Lines of a repository file as it is on disk (null if it cannot be read)
+ */
+const readLines = async (repoPath: string, filePath: string): Promise<string[] | null> => {
+  try {
+    const absolute = path.join(repoPath, filePath);
+    if (!(await fs.stat(absolute)).isFile()) return null;
+    return (await readSourceFile(absolute)).content.split(/\r?\n/);
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * Problem a rename to a new name would cause
+ */
+interface RenameConflict {
+  file_path: string;
+  line: number;
+  reason: string;
+}
+
+/**
+ * Find what breaks when the declarations are renamed to a new name
+ *
+ * A Go package (directory) cannot declare a name twice, and a method or field
+ * cannot share its name with another of the same type. An unexported name
+ * cannot be used from other packages.
+ *
+ * @param pool - Database connection pool
+ * @param repoId - Index of the declarations
+ * @param references - Uses of the declarations being renamed
+ * @param newName - Identifier the declarations are renamed to
+ * @returns Conflicts ordered by declaration
+ */
+const findConflicts = async (
+  pool: Pool,
+  repoId: string,
+  references: GoReferenceRecord[],
+  newName: string
+): Promise<RenameConflict[]> => {
+  const conflicts: RenameConflict[] = [];
+  const directory = (filePath: string): string => path.posix.dirname(filePath);
+  const declarations = new Map(references.map((ref) => [`${ref.target_file}:${String(ref.target_line)}`, ref]));
+  const unexported = !/^\p{Lu}/u.test(newName);
+
+  for (const declaration of declarations.values()) {
+    const owner = declaration.target_name.includes('.')
+      ? declaration.target_name.slice(0, declaration.target_name.lastIndexOf('.') + 1)
+      : '';
+    if (owner + newName === declaration.target_name) continue;
+    const existing = (await listSymbolVariants(pool, owner + newName, repoId)).filter(
+      (variant) => directory(variant.file_path) === directory(declaration.target_file)
+    );
+    for (const variant of existing) {
+      conflicts.push({
+        file_path: declaration.target_file,
+        line: declaration.target_line,
+        reason: `${owner + newName} is already declared at ${variant.file_path}:${String(variant.line_number)}`,
+      });
+    }
+    const outside = references.filter(
+      (ref) =>
+        ref.target_file === declaration.target_file &&
+        ref.target_line === declaration.target_line &&
+        directory(ref.file_path) !== directory(declaration.target_file)
+    ).length;
+    if (unexported && outside > 0) {
+      conflicts.push({
+        file_path: declaration.target_file,
+        line: declaration.target_line,
+        reason: `${String(outside)} uses in other packages cannot reach unexported ${newName}`,
+      });
+    }
+  }
+  return conflicts;
+};
+
+/**
+ * Rename-impact command - declarations, uses, and textual mentions of a name
+ */
+export const renameImpactCommand: CliCommand = {
+  name: 'rename-impact',
+  description: 'List the uses, struct tags, literals, and doc mentions a rename would touch (Go, --typed)',
+  usage: 'cindex rename-impact <name> [<new-name>] [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' } },
+    });
+
+    const [target, renamedTo] = positionals;
+    if (!target) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing declaration name',
+        hint: 'Usage: cindex rename-impact <name> [<new-name>], e.g. cindex rename-impact auth.User.Role Kind',
+      });
+    }
+    // The new name replaces the last element (Kind for auth.User.Role, auth.User.Kind alike)
+    const newName = renamedTo?.slice(renamedTo.lastIndexOf('.') + 1);
+    if (newName !== undefined && !new RegExp(`^${IDENTIFIER_PATTERN}$`, 'u').test(newName)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid new name '${renamedTo ?? ''}'`,
+        hint: 'The new name must be an identifier, e.g. Kind',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const repo = (await listIndexedRepositories(pool)).find((candidate) => candidate.repo_id === repoId);
+      if (!repo?.repo_path) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+      const repoPath = repo.repo_path;
+
+      const { references, typed, files } = await readIndex(repoId, async () => ({
+        references: await listGoReferences(pool, target, repoId),
+        typed: await hasGoReferences(pool, repoId),
+        files: await listIndexedFiles(pool, repoId),
+      }));
+      if (!typed) {
+        return reportError(ExitCode.Failure, {
+          code: 'NO_TYPE_FACTS',
+          message: `No type facts recorded for '${repoId}'`,
+          hint: 'Uses are resolved by typed indexing: cindex index <path> --typed',
+        });
+      }
+
+      const scanned = new Set(files.map((file) => file.file_path));
+      for (const document of await listTrackedDocuments(repoPath)) scanned.add(document);
+      const contents = new Map<string, string[]>();
+      for (const filePath of scanned) {
+        const lines = await readLines(repoPath, filePath);
+        if (lines) contents.set(filePath, lines);
+      }
+      const rawLine = (filePath: string, line: number): string => contents.get(filePath)?.at(line - 1) ?? '';
+      const lineText = (filePath: string, line: number): string => rawLine(filePath, line).trim();
+
+      const entries: ImpactEntry[] = [];
+      const declarations = new Map(references.map((ref) => [`${ref.target_file}:${String(ref.target_line)}`, ref]));
+      for (const ref of declarations.values()) {
+        entries.push({
+          category: 'declaration',
+          file_path: ref.target_file,
+          line: ref.target_line,
+          column: null,
+          text: lineText(ref.target_file, ref.target_line),
+        });
+      }
+      for (const ref of references) {
+        entries.push({
+          category: ref.file_path.endsWith('_test.go') ? 'test' : 'reference',
+          file_path: ref.file_path,
+          line: ref.line_number,
+          column: ref.column_number,
+          text: lineText(ref.file_path, ref.line_number),
+          symbol: ref.symbol_name,
+        });
+      }
+
+      // Literals and prose name the declaration by its last element (Role of auth.User.Role)
+      const name = target.slice(target.lastIndexOf('.') + 1);
+      for (const [filePath, lines] of contents) entries.push(...scanMentions(filePath, lines.join('\n'), name));
+      const impact = sortImpact(entries);
+      if (newName !== undefined) {
+        for (const entry of impact) {
+          entry.replacement = previewRename(entry, rawLine(entry.file_path, entry.line), name, newName);
+        }
+      }
+      const conflicts = newName !== undefined ? await findConflicts(pool, repoId, references, newName) : [];
+
+      // Porcelain: impact<TAB>category<TAB>path<TAB>line<TAB>column<TAB>symbol<TAB>text<TAB>replacement
+      //            conflict<TAB>path<TAB>line<TAB>reason (with a new name)
+      if (isPorcelain()) {
+        for (const entry of impact) {
+          printRecord('impact', [
+            entry.category,
+            entry.file_path,
+            entry.line,
+            entry.column,
+            entry.symbol,
+            entry.text,
+            entry.replacement,
+          ]);
+        }
+        for (const conflict of conflicts) printRecord('conflict', [conflict.file_path, conflict.line, conflict.reason]);
+      } else if (impact.length === 0) {
+        print(`Nothing mentions ${target}`);
+      } else {
+        const theme = getTheme();
+        if (declarations.size === 0) print(theme.dim(`No type-checked uses of ${target}; textual mentions only`));
+        for (const [category, heading] of IMPACT_CATEGORIES) {
+          const group = impact.filter((entry) => entry.category === category);
+          if (group.length === 0) continue;
+          print(theme.kind(`${heading} (${String(group.length)})`));
+          for (const entry of group.slice(0, ENTRIES_SHOWN)) {
+            const column = entry.column !== null ? `:${String(entry.column)}` : '';
+            const symbol = entry.symbol ? `  ${theme.dim(`(in ${entry.symbol})`)}` : '';
+            const location = `${entry.file_path}:${String(entry.line)}${column}`;
+            print(`  ${theme.path(location)}  ${entry.text}${symbol}`);
+            if (entry.replacement !== undefined && entry.replacement !== entry.text) {
+              print(`  ${' '.repeat(location.length)}  ${theme.kind(entry.replacement)}`);
+            }
+          }
+          if (group.length > ENTRIES_SHOWN) print(theme.dim(`  ... ${String(group.length - ENTRIES_SHOWN)} more`));
+          print();
+        }
+        for (const conflict of conflicts) {
+          print(`${theme.path(`${conflict.file_path}:${String(conflict.line)}`)}  conflict: ${conflict.reason}`);
+        }
+        if (conflicts.length > 0) print();
+        const touched = new Set(impact.map((entry) => entry.file_path)).size;
+        const code = impact.filter(isCodeImpact).length;
+        const mentions = `${String(code)} in code, ${String(impact.length - code)} found by text`;
+        print(`${String(impact.length)} places in ${String(touched)} files: ${mentions}`);
+      }
+      return impact.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
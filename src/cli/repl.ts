@@ -0,0 +1,203 @@
+/**
+ * CLI command: repl
+ * Interactive symbol search with history, tab completion, and result refinement
+ *
+ * Input forms:
+ *   <terms> [field:value ...]   new search (e.g. "auth kind:func path:internal/")
+ *   | field:value ...           refine the previous result set without re-querying
+ *   .help / .exit               REPL commands
+ */
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import * as readline from 'node:readline';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print } from '@cli/output';
+import { applyQuery, KIND_ALIASES, parseQuery, QUERY_FIELDS, type ParsedQuery } from '@cli/query-filter';
+import { printSymbols, REPO_ID_OPTION, runSymbolSearch } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { recordUsage } from '@cli/usage-stats';
+import { listSymbolNames } from '@database/queries';
+import { readGeneration } from '@indexing/index-lock';
+import { normalizeUnicode } from '@utils/unicode';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { Language } from '@/types/indexing';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/** History file (one entry per line, oldest first) */
+const HISTORY_FILE = path.join(os.homedir(), '.cindex_history');
+
+/** Maximum history entries kept in memory and on disk */
+const HISTORY_SIZE = 500;
+
+/** Completion values for fields with a fixed vocabulary */
+const FIELD_VALUES: Partial<Record<(typeof QUERY_FIELDS)[number], string[]>> = {
+  kind: Object.keys(KIND_ALIASES),
+  scope: ['exported', 'internal'],
+  license: ['none', 'MIT', 'Apache-2.0', 'BSD-3-Clause', 'GPL-3.0', 'MPL-2.0'],
+  lang: Object.values(Language).filter((language) => language !== Language.Unknown),
+  generic: ['true', 'false'],
+  constraint: ['any', 'comparable'],
+};
+
+/**
+ * Load history from disk (readline expects newest first)
+ */
+const loadHistory = (): string[] => {
+  try {
+    return fs.readFileSync(HISTORY_FILE, 'utf-8').split('\n').filter(Boolean).slice(-HISTORY_SIZE).reverse();
+  } catch {
+    return [];
+  }
+};
+
+/**
+ * Append one entry to the history file (best effort)
+ */
+const appendHistory = (line: string): void => {
+  try {
+    fs.appendFileSync(HISTORY_FILE, line + '\n');
+  } catch {
+    // History is a convenience; ignore unwritable home directories
+  }
+};
+
+/**
+ * Print REPL help
+ */
+const printReplHelp = (): void => {
+  print('Search:  <terms> [field:value ...]     e.g. auth kind:func path:internal/');
+  print('Refine:  | field:value ...             filters the previous results');
+  print(`Fields:  ${QUERY_FIELDS.join(', ')} (prefix with - to negate, e.g. -scope:internal)`);
+  print('Combine: a OR b, (a b), -(a OR b)        e.g. kind:method (receiver:Session OR name:~^Token)');
+  print('Other:   .help  .exit');
+};
+
+/**
+ * REPL command - interactive symbol search over the index
+ */
+export const replCommand: CliCommand = {
+  name: 'repl',
+  description: 'Interactive symbol search with history and tab completion',
+  usage: 'cindex repl [--repo-id <name>]',
+  local: true,
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { config, db } = await openSession();
+    const pool = db.getPool();
+    let previous: ResolvedSymbol[] = [];
+    let previousQuery: ParsedQuery = { terms: [], filters: [] };
+    let previousGeneration = 0;
+
+    /**
+     * Complete field names, fixed field values, and symbol names
+     */
+    const completer = (line: string, callback: (err: Error | null, result: [string[], string]) => void): void => {
+      const token = line.split(/\s+/).pop() ?? '';
+      const body = token.startsWith('-') ? token.slice(1) : token;
+      const colon = body.indexOf(':');
+
+      if (colon > 0) {
+        const field = body.slice(0, colon) as (typeof QUERY_FIELDS)[number];
+        const values = FIELD_VALUES[field] ?? [];
+        const prefix = token.slice(0, token.length - body.length + colon + 1);
+        callback(null, [values.map((value) => prefix + value).filter((value) => value.startsWith(token)), token]);
+        return;
+      }
+
+      const fieldCompletions = QUERY_FIELDS.map((field) => `${field}:`).filter((field) => field.startsWith(body));
+      if (body.length === 0) {
+        callback(null, [fieldCompletions, token]);
+        return;
+      }
+
+      listSymbolNames(pool, normalizeUnicode(body))
+        .then((names) => {
+          callback(null, [[...fieldCompletions, ...names], token]);
+        })
+        .catch(() => {
+          callback(null, [fieldCompletions, token]);
+        });
+    };
+
+    const rl = readline.createInterface({
+      input: process.stdin,
+      output: process.stdout,
+      prompt: 'cindex> ',
+      completer,
+      history: loadHistory(),
+      historySize: HISTORY_SIZE,
+    });
+
+    /**
+     * Handle one input line
+     */
+    const handleLine = async (input: string): Promise<boolean> => {
+      const line = input.trim();
+      if (line.length === 0) return true;
+      appendHistory(line);
+
+      if (line === '.exit' || line === '.quit') return false;
+      if (line === '.help') {
+        printReplHelp();
+        return true;
+      }
+
+      if (line.startsWith('|')) {
+        const refinement = parseQuery(line.slice(1));
+        previous = applyQuery(previous, refinement);
+        previousQuery = {
+          terms: [...previousQuery.terms, ...refinement.terms],
+          filters: [...previousQuery.filters, ...refinement.filters],
+          groups: [...(previousQuery.groups ?? []), ...(refinement.groups ?? [])],
+        };
+        printSymbols(previous, previousQuery);
+        if (repoId && (readGeneration(repoId)?.generation ?? 0) !== previousGeneration && !isPorcelain()) {
+          print('(the index was updated since this search; run it again for fresh results)');
+        }
+        return true;
+      }
+
+      const started = Date.now();
+      previousQuery = parseQuery(line);
+      previous = await readIndex(repoId, () => runSymbolSearch(pool, previousQuery, repoId));
+      previousGeneration = repoId ? (readGeneration(repoId)?.generation ?? 0) : 0;
+      recordUsage(config, {
+        kind: 'query',
+        source: 'cli',
+        operation: 'repl',
+        duration_ms: Date.now() - started,
+        repo_id: repoId,
+        results: previous.length,
+      });
+      printSymbols(previous, previousQuery);
+      return true;
+    };
+
+    if (!isPorcelain()) {
+      print('cindex interactive search - type .help for syntax, .exit to quit');
+    }
+    rl.prompt();
+
+    try {
+      for await (const input of rl) {
+        try {
+          if (!(await handleLine(input))) break;
+        } catch (error) {
+          print(`error: ${error instanceof Error ? error.message : String(error)}`);
+        }
+        rl.prompt();
+      }
+    } finally {
+      rl.close();
+      await db.close();
+    }
+
+    return ExitCode.Success;
+  },
+};
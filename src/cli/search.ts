@@ -0,0 +1,738 @@
+/**
+ * CLI command: search
+ * One-shot symbol search using the same query syntax as the REPL
+ *
+ *   cindex search auth kind:func path:internal/
+ *   cindex search handler --since=2w
+ *   cindex search NAS --fuzzy              NewAuthService, ranked (see @retrieval/fuzzy-symbols)
+ *   cindex search "how do we validate passwords" --semantic
+ *   cindex search Login kind:method --show-source -C 5
+ *
+ * --semantic embeds the terms with the configured provider (see
+ * @utils/embedders) and ranks symbols by cosine similarity to their
+ * definitions and doc comments, so verifyPassword is found without sharing a
+ * word with the query.
+ *
+ * Name searches page through the index with --limit and --cursor, and with
+ * --output ndjson print each page as it is fetched instead of collecting
+ * every match first:
+ *
+ *   cindex search handler --output ndjson | jq -r .file
+ *   cindex search handler --limit 100 --cursor <cursor from the last run>
+ *
+ * --show-source prints each symbol's span from the indexed content with
+ * context lines around it (see @retrieval/snippets).
+ *
+ * --scope strings and --scope comments search the literal index instead of
+ * symbols (see @indexing/literals), for where an error message or a SQL
+ * fragment comes from. The terms are matched as one piece of text:
+ *
+ *   cindex search "user not found" --scope strings
+ *   cindex search "INSERT INTO sessions" --scope strings --repo-id api
+ */
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { type Pool } from 'pg';
+
+import { isNdjson, isPorcelain, print, printJsonRecord, printRecord, reportError } from '@cli/output';
+import {
+  applyQuery,
+  genericCondition,
+  idConditions,
+  implementsConditions,
+  isPatternValue,
+  kindConditions,
+  languageConditions,
+  metricConditions,
+  moduleConditions,
+  parseQuery,
+  wordConditions,
+  type ParsedQuery,
+} from '@cli/query-filter';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex, streamIndex } from '@cli/session';
+import { highlightCode } from '@cli/syntax';
+import { getTheme, highlight } from '@cli/theme';
+import { recordUsage } from '@cli/usage-stats';
+import {
+  decodeSymbolCursor,
+  encodeSymbolCursor,
+  listFilesModifiedSince,
+  listIndexedRepositories,
+  searchLiterals,
+  searchSymbols,
+  searchSymbolsPage,
+  type SymbolPage,
+} from '@database/queries';
+import { findGitChanges, parseSince } from '@indexing/changed-files';
+import { searchSymbolsFuzzy } from '@retrieval/fuzzy-symbols';
+import { attachSnippets, DEFAULT_SNIPPET_CONTEXT, MAX_SNIPPET_CONTEXT } from '@retrieval/snippets';
+import { createEmbedder, type Embedder } from '@utils/embedders';
+import { createOllamaClient } from '@utils/ollama';
+import { toPosixPath } from '@utils/paths';
+import { ExitCode, type CliCommand, type CliOption } from '@/types/cli';
+import { type CindexConfig } from '@/types/config';
+import { type LiteralKind, type LiteralRecord } from '@/types/database';
+import { type ResolvedSymbol, type SourceSnippet } from '@/types/retrieval';
+
+/** Maximum symbols fetched per search before client-side filtering */
+export const SEARCH_LIMIT = 200;
+
+/** Symbols fetched per page by a streamed search */
+const STREAM_PAGE_SIZE = 500;
+
+/** Symbols printed by a semantic search (the closest ones) */
+const SEMANTIC_RESULTS = 20;
+
+/** Corpora searched by --scope: symbols, or the literal kinds */
+const SEARCH_SCOPES: Record<string, LiteralKind | null> = { code: null, strings: 'string', comments: 'comment' };
+
+/** --repo-id option shared by commands that query an index */
+export const REPO_ID_OPTION: CliOption = {
+  name: 'repo-id',
+  description: 'Index to query (default: selected with cindex use)',
+  takesValue: true,
+  complete: 'repo',
+};
+
+/**
+ * Term sent to the database: the longest term or name: filter, as the most selective ('' when the query has none)
+ */
+export const seedTerm = (query: ParsedQuery): string => {
+  const names = query.filters.filter((f) => f.field === 'name' && !f.negate && !isPatternValue(f.value));
+  return [...query.terms, ...names.map((filter) => filter.value)].sort((a, b) => b.length - a.length)[0] ?? '';
+};
+
+/**
+ * Search symbols: query the database with the longest term, kinds, interfaces, and metric comparisons, then
+ * apply all terms and filters locally
+ *
+ * @param db - Database connection pool
+ * @param query - Parsed query
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @param dependencies - Also search the Go modules indexed by cindex deps
+ * @returns Matching symbols
+ */
+export const runSymbolSearch = async (
+  db: Pool,
+  query: ParsedQuery,
+  repoId?: string,
+  dependencies = false
+): Promise<ResolvedSymbol[]> => {
+  const symbols = await searchSymbols(db, seedTerm(query), {
+    limit: SEARCH_LIMIT,
+    repoId,
+    dependencies,
+    metrics: metricConditions(query),
+    symbolTypes: kindConditions(query),
+    implements: implementsConditions(query),
+    languages: languageConditions(query),
+    generic: genericCondition(query),
+    modules: moduleConditions(query),
+    words: wordConditions(query),
+    ids: idConditions(query),
+  });
+  return applyQuery(symbols, query);
+};
+
+/**
+ * Stream a symbol search page by page, in name order, applying the query to each page
+ *
+ * Unlike runSymbolSearch there is no overall limit; pages continue until the
+ * index has no more symbols matching the database conditions.
+ *
+ * @param db - Database connection pool
+ * @param query - Parsed query
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.dependencies - Also search the Go modules indexed by cindex deps
+ * @param options.cursor - Start after this position (from an earlier page)
+ * @returns Pages of matching symbols (possibly empty), each with the cursor after it
+ */
+export const streamSymbolSearch = async function* (
+  db: Pool,
+  query: ParsedQuery,
+  options: { repoId?: string; dependencies?: boolean; cursor?: string } = {}
+): AsyncGenerator<SymbolPage> {
+  let cursor = options.cursor;
+  do {
+    const page = await searchSymbolsPage(db, seedTerm(query), {
+      limit: STREAM_PAGE_SIZE,
+      cursor,
+      repoId: options.repoId,
+      dependencies: options.dependencies,
+      metrics: metricConditions(query),
+      symbolTypes: kindConditions(query),
+      implements: implementsConditions(query),
+      languages: languageConditions(query),
+      generic: genericCondition(query),
+      modules: moduleConditions(query),
+      words: wordConditions(query),
+      ids: idConditions(query),
+    });
+    yield { symbols: applyQuery(page.symbols, query), next_cursor: page.next_cursor };
+    cursor = page.next_cursor ?? undefined;
+  } while (cursor !== undefined);
+};
+
+/**
+ * Fuzzy symbol search: rank names holding the terms as a subsequence, then apply the filters locally
+ *
+ * @param db - Database connection pool
+ * @param query - Parsed query (its terms are joined into one fuzzy query)
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.dependencies - Also search the Go modules indexed by cindex deps
+ * @param options.near - Path whose neighborhood ranks first
+ * @returns Matching symbols, best first
+ */
+export const runFuzzySymbolSearch = async (
+  db: Pool,
+  query: ParsedQuery,
+  options: { repoId?: string; dependencies?: boolean; near?: string } = {}
+): Promise<ResolvedSymbol[]> => {
+  const symbols = await searchSymbolsFuzzy(db, query.terms.join(''), {
+    ...options,
+    limit: SEARCH_LIMIT,
+    metrics: metricConditions(query),
+    symbolTypes: kindConditions(query),
+    implements: implementsConditions(query),
+    languages: languageConditions(query),
+    generic: genericCondition(query),
+    modules: moduleConditions(query),
+    words: wordConditions(query),
+    ids: idConditions(query),
+  });
+  return applyQuery(symbols, { terms: [], filters: query.filters, groups: query.groups });
+};
+
+/**
+ * Semantic symbol search: rank symbols by cosine similarity of their embedding to the terms', then apply the filters
+ * locally
+ *
+ * @param db - Database connection pool
+ * @param embedder - Embedder of the provider the index was built with
+ * @param config - Loaded configuration (embedding model and similarity threshold)
+ * @param query - Parsed query (its terms are embedded as one sentence)
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.dependencies - Also search the Go modules indexed by cindex deps
+ * @returns Up to SEMANTIC_RESULTS symbols at or above the similarity threshold, closest first
+ */
+export const runSemanticSymbolSearch = async (
+  db: Pool,
+  embedder: Embedder,
+  config: CindexConfig,
+  query: ParsedQuery,
+  options: { repoId?: string; dependencies?: boolean } = {}
+): Promise<ResolvedSymbol[]> => {
+  const { model, dimensions, context_window } = config.embedding;
+  const embedding = await embedder.generateEmbedding(model, query.terms.join(' '), dimensions, context_window);
+  const symbols = await searchSymbols(db, '', {
+    ...options,
+    embedding,
+    limit: SEARCH_LIMIT,
+    metrics: metricConditions(query),
+    symbolTypes: kindConditions(query),
+    implements: implementsConditions(query),
+    languages: languageConditions(query),
+    generic: genericCondition(query),
+    modules: moduleConditions(query),
+    words: wordConditions(query),
+    ids: idConditions(query),
+  });
+  const close = symbols.filter((symbol) => (symbol.similarity ?? 0) >= config.performance.similarity_threshold);
+  return applyQuery(close, { terms: [], filters: query.filters, groups: query.groups }).slice(0, SEMANTIC_RESULTS);
+};
+
+/**
+ * Working directory relative to an index's repository root, for ranking nearby symbols first
+ *
+ * @returns Stored-form path, or undefined outside the repository (or with no single index)
+ */
+const workingPath = async (db: Pool, repoId?: string): Promise<string | undefined> => {
+  if (!repoId) return undefined;
+  const repo = (await listIndexedRepositories(db)).find((candidate) => candidate.repo_id === repoId);
+  if (!repo?.repo_path) return undefined;
+  const relative = path.relative(repo.repo_path, process.cwd());
+  return relative.startsWith('..') || path.isAbsolute(relative) ? undefined : toPosixPath(relative);
+};
+
+/**
+ * --since option shared by commands that narrow results to recently changed files
+ */
+export const SINCE_OPTION: CliOption = {
+  name: 'since',
+  description: 'Only files changed within a window (e.g. 2w, 3d, 2025-01-31)',
+  takesValue: true,
+};
+
+/**
+ * Files changed since a cutoff in an index
+ *
+ * Uses git history when the index's repository is a git worktree on this
+ * machine, otherwise the file mtimes recorded at index time.
+ *
+ * @param db - Database connection pool
+ * @param since - Cutoff date
+ * @param repoId - Index to check (default: all indexes, mtime only)
+ * @returns Relative file paths changed since the cutoff
+ */
+export const findFilesChangedSince = async (db: Pool, since: Date, repoId?: string): Promise<Set<string>> => {
+  if (repoId) {
+    const repo = (await listIndexedRepositories(db)).find((candidate) => candidate.repo_id === repoId);
+    const changed = repo?.repo_path ? await findGitChanges(repo.repo_path, since) : null;
+    if (changed) return changed;
+  }
+  return new Set(await listFilesModifiedSince(db, since, repoId));
+};
+
+/**
+ * Print a snippet under its symbol: context lines dimmed, the lines a long span skips counted
+ */
+const printSnippet = (snippet: SourceSnippet, language: string): void => {
+  const theme = getTheme();
+  const span = snippet.lines.filter((line) => !line.context);
+  const highlighted = highlightCode(span.map((line) => line.text).join('\n'), language);
+  const width = String(snippet.lines[snippet.lines.length - 1].line).length;
+  let previous: number | null = null;
+  for (const line of snippet.lines) {
+    if (previous !== null && line.line > previous + 1) {
+      print(theme.dim(`${' '.repeat(width + 4)}  ... ${String(line.line - previous - 1)} lines`));
+    }
+    const text = line.context ? theme.dim(line.text) : highlighted[span.indexOf(line)];
+    print(`${theme.dim(String(line.line).padStart(width + 4))}  ${text}`);
+    previous = line.line;
+  }
+  print();
+};
+
+/**
+ * Named fields of a symbol in NDJSON records (see printSymbolRows)
+ */
+export const symbolFields = (symbol: ResolvedSymbol): Record<string, unknown> => ({
+  kind: symbol.symbol_type,
+  name: symbol.symbol_name,
+  repo_id: symbol.repo_id ?? null,
+  file: symbol.file_path,
+  line: symbol.line_number,
+  scope: symbol.scope,
+  complexity: symbol.complexity ?? null,
+  coverage: symbol.coverage ?? null,
+  lint_count: symbol.lint_count ?? null,
+  implements: symbol.implements ?? [],
+  language: symbol.language ?? null,
+  cognitive_complexity: symbol.cognitive_complexity ?? null,
+  similarity: symbol.similarity ?? null,
+  type_parameters: symbol.type_parameters ?? [],
+  id: symbol.symbol_id ?? null,
+  ...(symbol.snippet !== undefined && { snippet: symbol.snippet }),
+});
+
+/**
+ * Print symbols without the result count (one page of a streamed search)
+ *
+ * Porcelain: symbol<TAB>kind<TAB>name<TAB>file<TAB>line<TAB>scope<TAB>complexity<TAB>coverage<TAB>lint_count
+ *            <TAB>implements (comma-separated interfaces, from typed indexing)<TAB>language<TAB>cognitive_complexity
+ *            <TAB>similarity (semantic search; empty otherwise)<TAB>type_parameters (comma-separated, Go generics)
+ *            <TAB>symbol_id (structured ID, see @indexing/symbol-ids)
+ *            With --show-source each is followed by
+ *            snippet<TAB>repo_id<TAB>path<TAB>start_line<TAB>end_line<TAB>start_byte<TAB>end_byte<TAB>omitted_lines
+ *            and source<TAB>line<TAB>text<TAB>context (1 for context lines) per line
+ * NDJSON:    {"record": "symbol", "kind", "name", "repo_id", "file", "line", "scope", "complexity", "coverage",
+ *             "lint_count", "implements", "language", "cognitive_complexity", "similarity", "type_parameters", "id"},
+ *            with "snippet" (see SourceSnippet) when asked for
+ *
+ * @param symbols - Symbols to print
+ * @param query - Query whose terms and name:/path: values are highlighted
+ */
+export const printSymbolRows = (symbols: ResolvedSymbol[], query: ParsedQuery): void => {
+  if (isNdjson()) {
+    for (const symbol of symbols) printJsonRecord('symbol', symbolFields(symbol));
+    return;
+  }
+  if (isPorcelain()) {
+    for (const symbol of symbols) {
+      const { symbol_type, symbol_name, file_path, line_number, scope, complexity, coverage, lint_count } = symbol;
+      const metrics = [complexity, coverage, lint_count];
+      const implemented = symbol.implements?.join(',');
+      const location = [file_path, line_number, scope];
+      const tail = [
+        implemented,
+        symbol.language,
+        symbol.cognitive_complexity,
+        symbol.similarity?.toFixed(3),
+        symbol.type_parameters?.join(', '),
+        symbol.symbol_id,
+      ];
+      printRecord('symbol', [symbol_type, symbol_name, ...location, ...metrics, ...tail]);
+      if (symbol.snippet) {
+        const { start_line, end_line, start_byte, end_byte, omitted_lines } = symbol.snippet;
+        printRecord('snippet', [symbol.repo_id, file_path, start_line, end_line, start_byte, end_byte, omitted_lines]);
+        for (const line of symbol.snippet.lines) printRecord('source', [line.line, line.text, line.context ? 1 : 0]);
+      }
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const positive = query.filters.filter((filter) => !filter.negate && !isPatternValue(filter.value));
+  const nameTerms = [...query.terms, ...positive.filter((f) => f.field === 'name').map((f) => f.value)];
+  const pathTerms = positive.filter((f) => f.field === 'path').map((f) => f.value);
+  // Metrics are shown when the query filters on them; outstanding lint findings always are
+  const showComplexity = query.filters.some((f) => f.field === 'complexity');
+  const showCognitive = query.filters.some((f) => f.field === 'cognitive');
+  const showCoverage = query.filters.some((f) => f.field === 'coverage');
+
+  for (const symbol of symbols) {
+    const kind = theme.kind(symbol.symbol_type.padEnd(9));
+    const typeParameters = symbol.type_parameters?.length ? theme.dim(`[${symbol.type_parameters.join(', ')}]`) : '';
+    const name = highlight(symbol.symbol_name, nameTerms) + typeParameters;
+    const location = `${theme.path(highlight(symbol.file_path, pathTerms))}:${theme.line(String(symbol.line_number))}`;
+    const metrics = [
+      typeof symbol.similarity === 'number' ? `similarity ${symbol.similarity.toFixed(2)}` : '',
+      showComplexity && typeof symbol.complexity === 'number' ? `complexity ${String(symbol.complexity)}` : '',
+      showCognitive && typeof symbol.cognitive_complexity === 'number'
+        ? `cognitive ${String(symbol.cognitive_complexity)}`
+        : '',
+      showCoverage && typeof symbol.coverage === 'number' ? `${symbol.coverage.toFixed(1)}% covered` : '',
+      symbol.lint_count ? `${String(symbol.lint_count)} lint` : '',
+    ].filter(Boolean);
+    const suffix = metrics.length > 0 ? `  ${theme.dim(`(${metrics.join(', ')})`)}` : '';
+    print(`${kind} ${name}  ${location}${suffix}`);
+    if (symbol.snippet) printSnippet(symbol.snippet, symbol.language ?? '');
+  }
+};
+
+/**
+ * Print a symbol result set, highlighting the query's matches, followed by its count
+ *
+ * Records are those of printSymbolRows.
+ *
+ * @param symbols - Symbols to print
+ * @param query - Query whose terms and name:/path: values are highlighted
+ */
+export const printSymbols = (symbols: ResolvedSymbol[], query: ParsedQuery): void => {
+  printSymbolRows(symbols, query);
+  if (!isPorcelain()) print(getTheme().dim(`(${String(symbols.length)} results)`));
+};
+
+/**
+ * Print where a paged search stopped, so the next run can continue with --cursor
+ *
+ * Porcelain: cursor<TAB>next
+ * NDJSON:    {"record": "cursor", "next"}
+ *
+ * @param count - Symbols printed
+ * @param next - Cursor after the last symbol printed (null when the search is complete)
+ */
+const printCursor = (count: number, next: string | null): void => {
+  if (isNdjson()) {
+    if (next) printJsonRecord('cursor', { next });
+  } else if (isPorcelain()) {
+    if (next) printRecord('cursor', [next]);
+  } else {
+    print(getTheme().dim(`(${String(count)} results)`));
+    if (next) print(getTheme().dim(`More results: --cursor ${next}`));
+  }
+};
+
+/**
+ * Stream a name search to stdout, page by page
+ *
+ * @param db - Database connection pool
+ * @param query - Parsed query
+ * @param options.limit - Stop after this many symbols (default: all)
+ * @param options.changed - Only symbols in these files (--since)
+ * @param options.snippetContext - Print each symbol's source with this many context lines (--show-source)
+ * @returns Symbols printed
+ */
+const streamSymbols = async (
+  db: Pool,
+  query: ParsedQuery,
+  options: {
+    repoId?: string;
+    dependencies?: boolean;
+    cursor?: string;
+    limit?: number;
+    changed?: Set<string>;
+    snippetContext?: number;
+  }
+): Promise<number> => {
+  const { limit = Infinity, changed, snippetContext } = options;
+  let count = 0;
+  let next: string | null = null;
+  for await (const page of streamSymbolSearch(db, query, options)) {
+    let symbols = changed ? page.symbols.filter((symbol) => changed.has(symbol.file_path)) : page.symbols;
+    next = page.next_cursor;
+    if (count + symbols.length >= limit) {
+      // The next run continues after the last symbol printed, not after the page
+      const truncated = count + symbols.length > limit || next !== null;
+      symbols = symbols.slice(0, limit - count);
+      next = truncated ? encodeSymbolCursor(symbols[symbols.length - 1]) : null;
+    }
+    if (snippetContext !== undefined) symbols = await attachSnippets(db, symbols, { context: snippetContext });
+    printSymbolRows(symbols, query);
+    count += symbols.length;
+    if (count >= limit) break;
+  }
+  printCursor(count, next);
+  return count;
+};
+
+/**
+ * Print string literals and comments matching a search, followed by their count
+ *
+ * Porcelain: literal<TAB>kind<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>text
+ * NDJSON:    {"record": "literal", "kind", "repo_id", "file", "line", "column", "byte_column", "text"}
+ *
+ * @param literals - Literals to print
+ * @param text - Text searched for, highlighted
+ */
+const printLiterals = (literals: LiteralRecord[], text: string): void => {
+  if (isNdjson()) {
+    for (const literal of literals) {
+      const { kind, repo_id, file_path, line_number, column_number, byte_column } = literal;
+      printJsonRecord('literal', {
+        kind,
+        repo_id,
+        file: file_path,
+        line: line_number,
+        column: column_number,
+        byte_column,
+        text: literal.text,
+      });
+    }
+    return;
+  }
+  if (isPorcelain()) {
+    for (const literal of literals) {
+      const { kind, repo_id, file_path, line_number, column_number } = literal;
+      printRecord('literal', [kind, repo_id, file_path, line_number, column_number, literal.text]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  for (const literal of literals) {
+    const position = `${theme.line(String(literal.line_number))}:${theme.line(String(literal.column_number))}`;
+    const body = highlight(literal.text, [text]);
+    const styled = literal.kind === 'string' ? theme.string(body) : theme.comment(body);
+    print(`${theme.path(literal.file_path)}:${position}  ${styled}`);
+  }
+  print(theme.dim(`(${String(literals.length)} results)`));
+};
+
+/**
+ * Search command - print symbols matching a query
+ */
+export const searchCommand: CliCommand = {
+  name: 'search',
+  description: 'Search indexed symbols (same syntax as the REPL)',
+  usage:
+    'cindex search <terms> [field:value ...] [--repo-id <name>] [--since <window>] [--deps] [--fuzzy | --semantic]' +
+    ' [--limit <n>] [--cursor <cursor>] [--show-source [-C <n>]]' +
+    ' [--scope strings|comments|code]',
+  options: [
+    REPO_ID_OPTION,
+    SINCE_OPTION,
+    { name: 'deps', description: 'Also search the Go modules indexed with cindex deps' },
+    { name: 'fuzzy', description: 'Match terms as abbreviations (NAS finds NewAuthService), best first' },
+    { name: 'semantic', description: 'Rank symbols by meaning: definitions and doc comments closest to the terms' },
+    { name: 'limit', description: 'Print at most this many symbols, then the cursor to continue', takesValue: true },
+    { name: 'cursor', description: 'Continue a search where an earlier --limit run stopped', takesValue: true },
+    { name: 'show-source', description: "Print each symbol's source from the index, long spans truncated" },
+    {
+      name: 'context',
+      description: `Context lines around the source (-C, default: ${String(DEFAULT_SNIPPET_CONTEXT)})`,
+      takesValue: true,
+    },
+    {
+      name: 'scope',
+      description: 'Search symbols (code, the default), string literals (strings), or comments (comments)',
+      takesValue: true,
+      complete: Object.keys(SEARCH_SCOPES),
+    },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        since: { type: 'string' },
+        deps: { type: 'boolean', default: false },
+        fuzzy: { type: 'boolean', default: false },
+        semantic: { type: 'boolean', default: false },
+        limit: { type: 'string' },
+        cursor: { type: 'string' },
+        'show-source': { type: 'boolean', default: false },
+        context: { type: 'string', short: 'C' },
+        scope: { type: 'string', default: 'code' },
+      },
+    });
+
+    if (positionals.length === 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing search terms',
+        hint: `Usage: ${searchCommand.usage}`,
+      });
+    }
+
+    const since = values.since !== undefined ? parseSince(values.since) : undefined;
+    if (since === null) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --since value: ${values.since ?? ''}`,
+        hint: 'Expected a window such as 2w, 3d, 12h, or a date such as 2025-01-31',
+      });
+    }
+
+    if (values.fuzzy && values.semantic) {
+      return reportError(ExitCode.Usage, { code: 'USAGE_ERROR', message: 'Pass either --fuzzy or --semantic' });
+    }
+
+    const limit = values.limit !== undefined ? Number(values.limit) : undefined;
+    if (limit !== undefined && (!Number.isInteger(limit) || limit < 1)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --limit value: ${values.limit ?? ''}`,
+        hint: 'Expected a positive number of symbols',
+      });
+    }
+    const context = values.context !== undefined ? Number(values.context) : DEFAULT_SNIPPET_CONTEXT;
+    if (!Number.isInteger(context) || context < 0 || context > MAX_SNIPPET_CONTEXT) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --context value: ${values.context ?? ''}`,
+        hint: `Expected a number of lines from 0 to ${String(MAX_SNIPPET_CONTEXT)}`,
+      });
+    }
+    const snippetContext = values['show-source'] ? context : undefined;
+    if (values.cursor !== undefined && !decodeSymbolCursor(values.cursor)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Invalid --cursor value',
+        hint: 'Pass the cursor printed by an earlier cindex search --limit run',
+      });
+    }
+    if (!(values.scope in SEARCH_SCOPES)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --scope value: ${values.scope}`,
+        hint: `Expected one of: ${Object.keys(SEARCH_SCOPES).join(', ')}`,
+      });
+    }
+    const literalKind = SEARCH_SCOPES[values.scope];
+    const paged = limit !== undefined || values.cursor !== undefined;
+    const symbolOnly = values.fuzzy || values.semantic || values.deps || values['show-source'];
+    if (literalKind && (symbolOnly || values.cursor !== undefined)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `--scope ${values.scope} takes --repo-id, --since, and --limit only`,
+        hint: 'Literals are matched as text: cindex search "user not found" --scope strings',
+      });
+    }
+    if (paged && (values.fuzzy || values.semantic)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--limit and --cursor page name searches (not --fuzzy or --semantic)',
+      });
+    }
+
+    const { config, db } = await openSession();
+    try {
+      const started = Date.now();
+      if (literalKind) {
+        const text = positionals.join(' ');
+        const repoId = resolveRepoId(values['repo-id']);
+        const literals = await readIndex(repoId, async () => {
+          const pool = db.getPool();
+          const options = { kinds: [literalKind], repoId, limit: limit ?? SEARCH_LIMIT };
+          const found = await searchLiterals(pool, text, options);
+          if (!since) return found;
+          const changed = await findFilesChangedSince(pool, since, repoId);
+          return found.filter((literal) => changed.has(literal.file_path));
+        });
+        recordUsage(config, {
+          kind: 'query',
+          source: 'cli',
+          operation: 'search',
+          duration_ms: Date.now() - started,
+          repo_id: repoId,
+          results: literals.length,
+        });
+        printLiterals(literals, text);
+        return literals.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      const query = parseQuery(positionals.join(' '));
+      if (values.fuzzy && query.terms.length === 0) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: '--fuzzy needs a search term',
+          hint: 'e.g. cindex search NAS --fuzzy',
+        });
+      }
+      if (values.semantic && query.terms.length === 0) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: '--semantic needs a description to search for',
+          hint: 'e.g. cindex search "how do we validate passwords" --semantic',
+        });
+      }
+      const repoId = resolveRepoId(values['repo-id']);
+
+      // Name searches print page by page when paged or streamed as NDJSON
+      if (paged || (isNdjson() && !values.fuzzy && !values.semantic)) {
+        const count = await streamIndex(repoId, async () => {
+          const changed = since ? await findFilesChangedSince(db.getPool(), since, repoId) : undefined;
+          const options = { repoId, dependencies: values.deps, cursor: values.cursor, limit, changed, snippetContext };
+          return streamSymbols(db.getPool(), query, options);
+        });
+        recordUsage(config, {
+          kind: 'query',
+          source: 'cli',
+          operation: 'search',
+          duration_ms: Date.now() - started,
+          repo_id: repoId,
+          results: count,
+        });
+        return count > 0 ? ExitCode.Success : ExitCode.NoResults;
+      }
+
+      const symbols = await readIndex(repoId, async () => {
+        const pool = db.getPool();
+        let found: ResolvedSymbol[];
+        if (values.semantic) {
+          const embedder = createEmbedder(config, createOllamaClient(config.ollama));
+          found = await runSemanticSymbolSearch(pool, embedder, config, query, { repoId, dependencies: values.deps });
+        } else if (values.fuzzy) {
+          found = await runFuzzySymbolSearch(pool, query, {
+            repoId,
+            dependencies: values.deps,
+            near: await workingPath(pool, repoId),
+          });
+        } else {
+          found = await runSymbolSearch(pool, query, repoId, values.deps);
+        }
+        if (since) {
+          const changed = await findFilesChangedSince(db.getPool(), since, repoId);
+          found = found.filter((symbol) => changed.has(symbol.file_path));
+        }
+        return snippetContext !== undefined ? attachSnippets(pool, found, { context: snippetContext }) : found;
+      });
+      recordUsage(config, {
+        kind: 'query',
+        source: 'cli',
+        operation: 'search',
+        duration_ms: Date.now() - started,
+        repo_id: repoId,
+        results: symbols.length,
+      });
+      printSymbols(symbols, query);
+      return symbols.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
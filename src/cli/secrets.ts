@@ -0,0 +1,71 @@
+/**
+ * CLI command: secrets
+ * List likely credentials the secret scanner found while indexing
+ *
+ * Scanning is opt-in (SCAN_SECRETS=true or `cindex index --scan-secrets`).
+ * Findings show a redacted preview and a fingerprint, never the secret, so
+ * the report itself is safe to paste into a ticket. Rotate the credential,
+ * remove it from the file (and its history), then re-index.
+ */
+import { parseArgs } from 'node:util';
+
+import { getPositionEncoding, isPorcelain, print, printRecord } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listSecretFindings } from '@database/queries';
+import { selectColumn } from '@utils/positions';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Secrets command - report secret scanner findings recorded in the index
+ */
+export const secretsCommand: CliCommand = {
+  name: 'secrets',
+  description: 'List likely credentials found while indexing (needs SCAN_SECRETS)',
+  usage: 'cindex secrets [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { config, db } = await openSession();
+    try {
+      const findings = await readIndex(repoId, () => listSecretFindings(db.getPool(), repoId));
+      const encoding = getPositionEncoding();
+      const columnOf = (finding: (typeof findings)[number]): number =>
+        selectColumn({ column: finding.column_number, byte_column: finding.byte_column }, encoding);
+
+      // Porcelain: secret<TAB>repo_id<TAB>path<TAB>line<TAB>column<TAB>rule<TAB>redacted<TAB>fingerprint
+      if (isPorcelain()) {
+        for (const finding of findings) {
+          const { repo_id, file_path, line_number, rule, redacted, fingerprint } = finding;
+          printRecord('secret', [repo_id, file_path, line_number, columnOf(finding), rule, redacted, fingerprint]);
+        }
+      } else if (findings.length === 0) {
+        print(
+          config.indexing.scan_secrets
+            ? 'No likely secrets found in the last build'
+            : 'No findings recorded; set SCAN_SECRETS=true (or pass --scan-secrets to cindex index) and re-index'
+        );
+      } else {
+        const theme = getTheme();
+        for (const finding of findings) {
+          const position = theme.line(`${String(finding.line_number)}:${String(columnOf(finding))}`);
+          const label = theme.dim(`(${finding.rule}, ${finding.fingerprint})`);
+          print(`${theme.path(finding.file_path)}:${position}  ${finding.redacted}  ${label}`);
+        }
+        const files = new Set(findings.map((finding) => finding.file_path)).size;
+        print();
+        print(`${String(findings.length)} likely secrets in ${String(files)} files`);
+        print(theme.dim('Rotate each credential and remove it from the file, then re-index'));
+      }
+
+      // Findings fail the check, like parse errors do
+      return findings.length > 0 ? ExitCode.PartialFailure : ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
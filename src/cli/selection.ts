@@ -0,0 +1,115 @@
+/**
+ * Per-directory default index selection
+ *
+ * `cindex use <name>` records which indexed repository CLI commands target
+ * when run from a directory (or any of its subdirectories). Selections are
+ * stored per user in ~/.cindex/selections.json:
+ *
+ *   { "/home/me/src/api": "api", "/home/me/src/web": "web-v2" }
+ */
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { logger } from '@utils/logger';
+
+/** Per-user CLI state directory */
+export const CINDEX_HOME = path.join(os.homedir(), '.cindex');
+
+/** Directory to index selections */
+const SELECTIONS_FILE = path.join(CINDEX_HOME, 'selections.json');
+
+/**
+ * Read all selections (absolute directory -> repo_id)
+ */
+export const readSelections = (): Record<string, string> => {
+  try {
+    const parsed = JSON.parse(fs.readFileSync(SELECTIONS_FILE, 'utf-8')) as unknown;
+    if (typeof parsed === 'object' && parsed !== null && !Array.isArray(parsed)) {
+      return parsed as Record<string, string>;
+    }
+    logger.warn('Ignoring malformed selections file', { file: SELECTIONS_FILE });
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+      logger.warn('Error reading selections file', { file: SELECTIONS_FILE, error });
+    }
+  }
+  return {};
+};
+
+/**
+ * Write all selections (atomic rename so concurrent readers never see a partial file)
+ */
+const writeSelections = (selections: Record<string, string>): void => {
+  fs.mkdirSync(CINDEX_HOME, { recursive: true });
+  const sorted = Object.fromEntries(Object.entries(selections).sort(([a], [b]) => a.localeCompare(b)));
+  const tmp = `${SELECTIONS_FILE}.${String(process.pid)}.tmp`;
+  fs.writeFileSync(tmp, JSON.stringify(sorted, null, 2) + '\n');
+  fs.renameSync(tmp, SELECTIONS_FILE);
+};
+
+/**
+ * Find the index selected for a directory (nearest ancestor selection wins)
+ *
+ * @param cwd - Directory to resolve from (default: process.cwd())
+ * @returns Selected repo_id and the directory it was selected for, or null
+ */
+export const getSelectedIndex = (cwd: string = process.cwd()): { repoId: string; directory: string } | null => {
+  const selections = readSelections();
+  let dir = path.resolve(cwd);
+
+  for (;;) {
+    if (Object.hasOwn(selections, dir)) {
+      return { repoId: selections[dir], directory: dir };
+    }
+    const parent = path.dirname(dir);
+    if (parent === dir) return null;
+    dir = parent;
+  }
+};
+
+/**
+ * Select an index for a directory, or clear the selection when repoId is null
+ *
+ * @param directory - Directory the selection applies to
+ * @param repoId - Repository ID to select (null to clear)
+ */
+export const setSelectedIndex = (directory: string, repoId: string | null): void => {
+  const selections = readSelections();
+  const dir = path.resolve(directory);
+
+  if (repoId === null) {
+    delete selections[dir];
+  } else {
+    selections[dir] = repoId;
+  }
+
+  writeSelections(selections);
+};
+
+/**
+ * Remove every selection pointing at a repository (after it is deleted)
+ *
+ * @param repoId - Deleted repository ID
+ * @returns Number of selections removed
+ */
+export const clearSelectionsFor = (repoId: string): number => {
+  const selections = readSelections();
+  const remaining = Object.fromEntries(Object.entries(selections).filter(([, selected]) => selected !== repoId));
+  const removed = Object.keys(selections).length - Object.keys(remaining).length;
+
+  if (removed > 0) {
+    writeSelections(remaining);
+  }
+  return removed;
+};
+
+/**
+ * Resolve the index a command should query
+ *
+ * @param explicit - Value of --repo-id, if given
+ * @returns Repository ID, or undefined to search all indexes
+ */
+export const resolveRepoId = (explicit: string | undefined): string | undefined => {
+  return explicit ?? getSelectedIndex()?.repoId;
+};
@@ -0,0 +1,191 @@
+/**
+ * CLI command: serve
+ * Answer lookups from the index over the Language Server Protocol or HTTP
+ *
+ *   cindex serve --lsp                       every indexed repository
+ *   cindex serve --lsp --repo-id api         one index
+ *   cindex serve --http 127.0.0.1:8080       REST API until Ctrl+C
+ *   cindex serve --lsp --metrics :9464       Prometheus metrics on the side
+ *
+ * With --lsp the editor starts the command and speaks JSON-RPC over stdin
+ * and stdout (see @lsp/server): workspace/symbol, textDocument/definition,
+ * and textDocument/references. Nothing else is written to stdout; logs go to
+ * stderr. With --http the REST endpoints of @http/server are served on the
+ * address given; they have no authentication. The REST API serves
+ * /metrics itself; --metrics adds the endpoint to the language server.
+ */
+import { type AddressInfo } from 'node:net';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { parseListenAddress, serveHttp, serveMetrics, type ListenAddress } from '@http/server';
+import { serveLsp } from '@lsp/server';
+import { logger } from '@utils/logger';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/** Hosts only this machine can reach */
+const LOOPBACK_HOSTS = new Set(['localhost', '127.0.0.1', '::1']);
+
+/**
+ * URL of a bound address
+ */
+const boundUrl = (bound: AddressInfo): string => {
+  const host = bound.family === 'IPv6' ? `[${bound.address}]` : bound.address;
+  return `http://${host}:${String(bound.port)}`;
+};
+
+/**
+ * Serve /metrics (see @utils/metrics) in the background until the signal aborts
+ *
+ * @param address - Host and port to listen on
+ * @param signal - Stops the endpoint
+ * @returns URL of /metrics once listening, and a promise that resolves once the endpoint is closed
+ * @throws {Error} If the address cannot be listened on
+ */
+export const startMetricsEndpoint = (
+  address: ListenAddress,
+  signal: AbortSignal
+): Promise<{ url: string; closed: Promise<void> }> =>
+  new Promise((resolve, reject) => {
+    const closed = serveMetrics(address, signal, (bound) => {
+      resolve({ url: `${boundUrl(bound)}/metrics`, closed });
+    });
+    closed.catch(reject);
+  });
+
+/**
+ * Report an address that cannot be listened on
+ */
+export const reportListenError = (address: ListenAddress, error: unknown): ExitCode => {
+  const reason = error instanceof Error ? error.message : String(error);
+  return reportError(ExitCode.Failure, {
+    code: 'LISTEN_ERROR',
+    message: `Cannot listen on ${address.host ?? ''}:${String(address.port)}: ${reason}`,
+  });
+};
+
+/**
+ * Serve the REST API until Ctrl+C
+ *
+ * Porcelain: listening<TAB>url
+ */
+const serveRest = async (address: ListenAddress, repoId: string | undefined): Promise<ExitCode> => {
+  if (!address.host || !LOOPBACK_HOSTS.has(address.host)) {
+    logger.warn('The HTTP API has no authentication and is reachable from other machines', {
+      host: address.host ?? '*',
+      hint: 'Listen on 127.0.0.1, or put it behind an authenticating proxy',
+    });
+  }
+
+  const { db } = await openSession();
+  const controller = new AbortController();
+  const removeSignalHandlers = handleShutdownSignals(() => {
+    controller.abort();
+  });
+  try {
+    await serveHttp(db.getPool(), address, repoId, controller.signal, (bound) => {
+      const url = boundUrl(bound);
+      if (isPorcelain()) printRecord('listening', [url]);
+      else print(`Serving the index API on ${getTheme().path(url)} (Ctrl+C to stop)`);
+    });
+    return ExitCode.Success;
+  } catch (error) {
+    return reportListenError(address, error);
+  } finally {
+    removeSignalHandlers();
+    await db.close();
+  }
+};
+
+/**
+ * Serve command - run a language server backed by the index
+ */
+export const serveCommand: CliCommand = {
+  name: 'serve',
+  description: 'Serve symbol, definition, and reference lookups (LSP over stdio, or a REST API)',
+  usage: 'cindex serve --lsp [--metrics [host]:port] | --http [host]:port [--repo-id <name>]',
+  local: true,
+  options: [
+    { name: 'lsp', description: 'Speak the Language Server Protocol over stdin and stdout' },
+    { name: 'http', description: 'Serve the REST API on an address (:8080, 127.0.0.1:8080)', takesValue: true },
+    { name: 'metrics', description: 'With --lsp, serve Prometheus metrics on an address (:9464)', takesValue: true },
+    { ...REPO_ID_OPTION, description: 'Serve one index (default: every indexed repository)' },
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        lsp: { type: 'boolean', default: false },
+        http: { type: 'string' },
+        metrics: { type: 'string' },
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    if (values.lsp === (values.http !== undefined)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: values.lsp ? 'Choose one of --lsp and --http' : 'Missing protocol',
+        hint: 'e.g. cindex serve --lsp or cindex serve --http :8080 (MCP clients run cindex with no command)',
+      });
+    }
+
+    if (values.http !== undefined && values.metrics !== undefined) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--metrics applies to --lsp',
+        hint: 'The REST API serves /metrics on its own address',
+      });
+    }
+
+    if (values.http !== undefined) {
+      let address: ListenAddress;
+      try {
+        address = parseListenAddress(values.http);
+      } catch (error) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: error instanceof Error ? error.message : String(error),
+        });
+      }
+      return serveRest(address, values['repo-id']);
+    }
+
+    let metricsAddress: ListenAddress | undefined;
+    try {
+      metricsAddress = values.metrics !== undefined ? parseListenAddress(values.metrics) : undefined;
+    } catch (error) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `--metrics: ${error instanceof Error ? error.message : String(error)}`,
+      });
+    }
+
+    // Nothing but JSON-RPC goes to stdout: the metrics endpoint is only logged
+    const metricsController = new AbortController();
+    let metrics: { closed: Promise<void> } | undefined;
+    if (metricsAddress) {
+      try {
+        metrics = await startMetricsEndpoint(metricsAddress, metricsController.signal);
+      } catch (error) {
+        return reportListenError(metricsAddress, error);
+      }
+    }
+
+    // No fallback to the selected index: one editor session spans every repository it opens
+    const { db } = await openSession();
+    try {
+      const clean = await serveLsp(db.getPool(), { input: process.stdin, output: process.stdout }, values['repo-id']);
+      return clean ? ExitCode.Success : ExitCode.Failure;
+    } finally {
+      metricsController.abort();
+      await metrics?.closed;
+      await db.close();
+    }
+  },
+};
+
@@ -0,0 +1,123 @@
+/**
+ * Shared setup for CLI commands that need configuration and a database
+ */
+import { isEnvSet, loadConfig, validateConfig } from '@config/env';
+import { createDatabaseClient, type DatabaseClient } from '@database/client';
+import { acquireReadLock, hasChangedSince, readConsistently } from '@indexing/index-lock';
+import { initLogger, logger } from '@utils/logger';
+import { ENV_VARS, type CindexConfig } from '@/types/config';
+
+/**
+ * Loaded configuration with a connected, health-checked database client
+ */
+export interface CliSession {
+  config: CindexConfig;
+  db: DatabaseClient;
+}
+
+/** Connected clients kept for later sessions (cindex daemon), by database settings */
+let keptClients: Map<string, DatabaseClient> | null = null;
+
+/**
+ * Keep database clients connected across sessions
+ *
+ * Each database gets one client, connected and health-checked by the first
+ * session that opens it; later sessions borrow it, and closing a borrowed
+ * client leaves it connected.
+ *
+ * @returns Function that closes the kept clients and stops keeping them
+ */
+export const keepSessionsOpen = (): (() => Promise<void>) => {
+  const clients = new Map<string, DatabaseClient>();
+  keptClients = clients;
+  return async () => {
+    keptClients = null;
+    await Promise.all([...clients.values()].map((db) => db.close()));
+  };
+};
+
+/**
+ * Client whose close() leaves the kept connection open
+ */
+const borrow = (db: DatabaseClient): DatabaseClient =>
+  new Proxy(db, {
+    get: (target, property) => (property === 'close' ? () => Promise.resolve() : Reflect.get(target, property, target)),
+  });
+
+/**
+ * Load configuration and connect to the database
+ *
+ * Logging is reduced to warnings so command output on stdout stays readable,
+ * unless LOG_LEVEL (or CINDEX_LOG_LEVEL) is set explicitly.
+ *
+ * @returns Session with config and connected database (caller must close db)
+ * @throws {ConfigurationError} If configuration is invalid
+ * @throws {DatabaseConnectionError} If the database is unreachable
+ */
+export const openSession = async (): Promise<CliSession> => {
+  const config = loadConfig();
+  validateConfig(config);
+  initLogger(isEnvSet(ENV_VARS.LOG_LEVEL) ? config.logging.level : 'WARN');
+
+  const key = JSON.stringify(config.database);
+  const kept = keptClients?.get(key);
+  if (kept) return { config, db: borrow(kept) };
+
+  const db = createDatabaseClient(config.database);
+  await db.connect();
+  try {
+    await db.healthCheck(config.embedding.dimensions);
+  } catch (error) {
+    await db.close();
+    throw error;
+  }
+
+  if (!keptClients) return { config, db };
+  keptClients.set(key, db);
+  return { config, db: borrow(db) };
+};
+
+/**
+ * Run a read against one index under a shared read lock
+ *
+ * Indexing runs never block the read. A read that spans several queries is
+ * retried once if a run finished in between, so its results come from one
+ * generation of the index. Reads across all indexes (no repoId) run unlocked.
+ *
+ * @param repoId - Index being read (undefined for all indexes)
+ * @param read - Queries to run
+ * @param scope - Files the read is limited to, so runs that rewrite other packages do not retry it
+ * @returns Result of the read
+ */
+export const readIndex = async <T>(
+  repoId: string | undefined,
+  read: () => Promise<T>,
+  scope?: string[]
+): Promise<T> => {
+  if (!repoId) return read();
+  return readConsistently(repoId, read, scope);
+};
+
+/**
+ * Run a read whose results are printed while it runs, under a shared read lock
+ *
+ * Printed results cannot be taken back, so unlike readIndex the read is never
+ * retried; a run that finished in between is reported as a warning instead.
+ *
+ * @param repoId - Index being read (undefined for all indexes)
+ * @param read - Queries to run
+ * @returns Result of the read
+ */
+export const streamIndex = async <T>(repoId: string | undefined, read: () => Promise<T>): Promise<T> => {
+  if (!repoId) return read();
+  const lock = await acquireReadLock(repoId);
+  try {
+    const result = await read();
+    if (hasChangedSince(repoId, lock)) {
+      logger.warn('Index was rewritten while results were streamed; they may span two runs', { repo_id: repoId });
+    }
+    return result;
+  } finally {
+    lock.release();
+  }
+};
@@ -0,0 +1,337 @@
+/**
+ * CLI command: show
+ * Print a symbol's source with syntax highlighting and its doc comment
+ *
+ *   cindex show AuthService
+ *   cindex show auth.AuthService.Login
+ *
+ * Leading name segments qualify the symbol: each must match a directory or
+ * file name in its path, an enclosing class, or (for Go receivers and similar)
+ * a word in its definition. Source comes from the chunks stored at index
+ * time; the filesystem is never read, so the preview reflects the last build.
+ * Imported lint findings (cindex lint) on the symbol's lines follow the source.
+ */
+import { parseArgs } from 'node:util';
+
+import { type Pool } from 'pg';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { highlightCode } from '@cli/syntax';
+import { getTheme } from '@cli/theme';
+import {
+  findClassChunksWithMethod,
+  findSymbolsByName,
+  listFileChunks,
+  listLintFindingsInLines,
+} from '@database/queries';
+import { containsIdentifier, IDENTIFIER_CHAR_PATTERN, normalizeUnicode } from '@utils/unicode';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type CodeChunk, type LintFindingRecord } from '@/types/database';
+
+/** Maximum matches previewed by one show */
+const SHOW_LIMIT = 5;
+
+/** Chunk as returned by the preview queries */
+type StoredChunk = Omit<CodeChunk, 'embedding'>;
+
+/**
+ * Source preview of one symbol
+ */
+export interface SymbolPreview {
+  repo_id?: string;
+  file_path: string;
+  language: string;
+  name: string;
+  kind: string;
+  start_line: number;
+  end_line: number;
+  source: string;
+  doc: string | null;
+  lint?: LintFindingRecord[];
+}
+
+/**
+ * Split a qualified name into segments (., ::, and # separators), normalized to NFC
+ */
+export const splitQualifiedName = (input: string): string[] => {
+  return normalizeUnicode(input)
+    .split(/::|[.#]/)
+    .filter((segment) => segment.length > 0);
+};
+
+/**
+ * Number of leading whitespace characters
+ */
+const indentOf = (line: string): number => line.length - line.trimStart().length;
+
+/**
+ * Find the last line of the block that starts at a declaration line
+ *
+ * The block continues while lines are indented deeper than the declaration.
+ * A closing line at the declaration's indentation (}, ), end) is included;
+ * one that reopens a block (`) {` after a wrapped signature) continues it.
+ *
+ * @param lines - Source lines
+ * @param start - Index of the declaration line
+ * @returns Index of the last line of the block
+ */
+export const findBlockEnd = (lines: string[], start: number): number => {
+  const indent = indentOf(lines[start]);
+  let end = start;
+  for (let i = start + 1; i < lines.length; i++) {
+    const trimmed = lines[i].trim();
+    if (trimmed === '') continue;
+    if (indentOf(lines[i]) > indent) {
+      end = i;
+      continue;
+    }
+    if (/^([}\])]|end\b)/.test(trimmed)) {
+      end = i;
+      if (/[{([:]\s*$/.test(trimmed)) continue;
+    }
+    break;
+  }
+  return end;
+};
+
+/**
+ * Comment lines directly above a line (doc comments, decorators excluded)
+ *
+ * @param lines - Source lines
+ * @param index - Index of the declaration line
+ * @returns Comment text, or null if the declaration has none
+ */
+const leadingComment = (lines: string[], index: number): string | null => {
+  let start = index;
+  while (start > 0 && /^(\/\/|\/\*|\*|#(?!\[)|--)/.test(lines[start - 1].trim())) {
+    start--;
+  }
+  return start < index ? lines.slice(start, index).join('\n') : null;
+};
+
+/**
+ * Cut a symbol's block out of a chunk that contains its declaration
+ *
+ * @param chunk - Stored chunk
+ * @param line - Declaration line (1-based, file coordinates)
+ * @returns Block boundaries and text, plus the comment above it
+ */
+const sliceBlock = (
+  chunk: StoredChunk,
+  line: number
+): Pick<SymbolPreview, 'start_line' | 'end_line' | 'source' | 'doc'> => {
+  const lines = chunk.chunk_content.split('\n');
+  const index = line - chunk.start_line;
+  const end = findBlockEnd(lines, index);
+  return {
+    start_line: line,
+    end_line: chunk.start_line + end,
+    source: lines.slice(index, end + 1).join('\n'),
+    doc: leadingComment(lines, index),
+  };
+};
+
+/**
+ * Docstring stored on a function or class chunk
+ */
+const chunkDocstring = (chunk: StoredChunk): string | null => {
+  const docstring = chunk.metadata?.docstring;
+  return typeof docstring === 'string' && docstring.trim() !== '' ? docstring : null;
+};
+
+/**
+ * Check whether a qualifier names a path segment, enclosing class, or definition word
+ */
+const matchesQualifier = (qualifier: string, filePath: string, containers: string[], definition: string): boolean => {
+  const wanted = qualifier.toLowerCase();
+  const segments = filePath.split('/').map((segment) => segment.replace(/\.[^.]*$/, '').toLowerCase());
+  if (segments.includes(wanted)) return true;
+  if (containers.some((name) => name.toLowerCase() === wanted)) return true;
+  return containsIdentifier(definition, qualifier);
+};
+
+/**
+ * Resolve a qualified name to source previews
+ *
+ * Symbols are matched by their last segment first. Methods are not stored as
+ * symbols, so `Class.method` also looks for class chunks that declare the method.
+ *
+ * @param db - Database connection pool
+ * @param segments - Qualified name segments (symbol name last)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Previews, exported symbols first
+ */
+export const resolvePreviews = async (db: Pool, segments: string[], repoId?: string): Promise<SymbolPreview[]> => {
+  const name = segments[segments.length - 1];
+  const qualifiers = segments.slice(0, -1);
+  const chunkCache = new Map<string, StoredChunk[]>();
+  const chunksOf = async (filePath: string, fileRepoId?: string): Promise<StoredChunk[]> => {
+    const key = `${fileRepoId ?? ''}\0${filePath}`;
+    let chunks = chunkCache.get(key);
+    if (!chunks) {
+      chunks = await listFileChunks(db, filePath, fileRepoId);
+      chunkCache.set(key, chunks);
+    }
+    return chunks;
+  };
+
+  const previews: SymbolPreview[] = [];
+  for (const symbol of await findSymbolsByName(db, name, repoId)) {
+    const line = symbol.line_number;
+    const chunks = (await chunksOf(symbol.file_path, symbol.repo_id)).filter(
+      (chunk) => chunk.chunk_type !== 'structure-only' && chunk.start_line <= line && chunk.end_line >= line
+    );
+    const containers = chunks
+      .filter((chunk) => chunk.chunk_type === 'class' && chunk.start_line < line)
+      .map((chunk) => chunk.metadata?.class_name)
+      .filter((className): className is string => typeof className === 'string');
+    if (!qualifiers.every((q) => matchesQualifier(q, symbol.file_path, containers, symbol.definition))) {
+      continue;
+    }
+
+    // Function/class chunk of the symbol itself, else the smallest chunk around it
+    const own = chunks.find(
+      (chunk) => (chunk.chunk_type === 'function' || chunk.chunk_type === 'class') && chunk.start_line === line
+    );
+    const base = { repo_id: symbol.repo_id, file_path: symbol.file_path, name, kind: symbol.symbol_type };
+    if (own) {
+      const { language, start_line, end_line, chunk_content: source } = own;
+      previews.push({ ...base, language, start_line, end_line, source, doc: chunkDocstring(own) });
+    } else if (chunks.length > 0) {
+      const enclosing = [...chunks].sort((a, b) => a.end_line - a.start_line - (b.end_line - b.start_line))[0];
+      previews.push({ ...base, language: enclosing.language, ...sliceBlock(enclosing, line) });
+    } else {
+      // Nothing stored around the declaration: fall back to the indexed definition
+      previews.push({ ...base, language: '', start_line: line, end_line: line, source: symbol.definition, doc: null });
+    }
+  }
+
+  if (previews.length > 0 || qualifiers.length === 0) {
+    return previews;
+  }
+
+  // Class.method: find the method inside its class chunk
+  const className = qualifiers[qualifiers.length - 1];
+  const escaped = name.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+  const declaration = new RegExp(`(?<!${IDENTIFIER_CHAR_PATTERN})${escaped}\\s*[(<=:]`, 'u');
+  for (const chunk of await findClassChunksWithMethod(db, className, name, repoId)) {
+    if (!qualifiers.slice(0, -1).every((q) => matchesQualifier(q, chunk.file_path, [], ''))) continue;
+    const lines = chunk.chunk_content.split('\n');
+    const index = lines.findIndex((text, i) => i > 0 && declaration.test(text));
+    if (index === -1) continue;
+    previews.push({
+      repo_id: chunk.repo_id,
+      file_path: chunk.file_path,
+      language: chunk.language,
+      name: `${className}.${name}`,
+      kind: 'method',
+      ...sliceBlock(chunk, chunk.start_line + index),
+    });
+  }
+  return previews;
+};
+
+/**
+ * Print one preview with a line-number gutter
+ *
+ * Porcelain: show<TAB>repo_id<TAB>kind<TAB>name<TAB>file<TAB>start_line<TAB>end_line,
+ * then doc<TAB>text and source<TAB>line<TAB>text per line, then
+ * lint<TAB>line<TAB>column<TAB>linter<TAB>rule<TAB>severity<TAB>message per finding
+ */
+const printPreview = (preview: SymbolPreview): void => {
+  const sourceLines = preview.source.split('\n');
+
+  if (isPorcelain()) {
+    const { repo_id, kind, name, file_path, start_line, end_line } = preview;
+    printRecord('show', [repo_id, kind, name, file_path, start_line, end_line]);
+    for (const text of preview.doc?.split('\n') ?? []) {
+      printRecord('doc', [text]);
+    }
+    sourceLines.forEach((text, index) => {
+      printRecord('source', [preview.start_line + index, text]);
+    });
+    for (const { line_number, column_number, linter, rule, severity, message } of preview.lint ?? []) {
+      printRecord('lint', [line_number, column_number, linter, rule, severity, message]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const range = `${String(preview.start_line)}-${String(preview.end_line)}`;
+  print(`${theme.path(preview.file_path)}:${theme.line(range)}  ${theme.dim(`${preview.kind} ${preview.name}`)}`);
+  if (preview.doc) {
+    for (const text of highlightCode(preview.doc, preview.language)) {
+      print(`      ${text}`);
+    }
+  }
+  const width = String(preview.start_line + sourceLines.length - 1).length;
+  highlightCode(preview.source, preview.language).forEach((text, index) => {
+    print(`${theme.dim(String(preview.start_line + index).padStart(width + 4))}  ${text}`);
+  });
+  for (const finding of preview.lint ?? []) {
+    const location = `${String(finding.line_number)}:${String(finding.column_number)}`;
+    print(`${theme.dim(`${location.padStart(width + 4)}  lint`)}  ${theme.kind(finding.rule)}  ${finding.message}`);
+  }
+};
+
+/**
+ * Show command - preview a symbol's stored source
+ */
+export const showCommand: CliCommand = {
+  name: 'show',
+  description: "Print a symbol's source and doc comment from the index",
+  usage: 'cindex show <[qualifier.]symbol> [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { 'repo-id': { type: 'string' } },
+    });
+
+    const segments = splitQualifiedName(positionals[0] ?? '');
+    if (segments.length === 0 || positionals.length > 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: positionals.length > 1 ? 'Expected a single symbol name' : 'Missing symbol name',
+        hint: `Usage: ${showCommand.usage}`,
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const repoId = resolveRepoId(values['repo-id']);
+      const pool = db.getPool();
+      const previews = await readIndex(repoId, async () => {
+        const resolved = await resolvePreviews(pool, segments, repoId);
+        for (const preview of resolved.slice(0, SHOW_LIMIT)) {
+          const { file_path, start_line, end_line } = preview;
+          preview.lint = await listLintFindingsInLines(pool, file_path, start_line, end_line, preview.repo_id);
+        }
+        return resolved;
+      });
+      if (previews.length === 0) {
+        return reportError(ExitCode.NoResults, {
+          code: 'SYMBOL_NOT_FOUND',
+          message: `No symbol '${positionals[0]}' in the index`,
+          hint: 'Try `cindex search` to find the right name',
+        });
+      }
+
+      previews.slice(0, SHOW_LIMIT).forEach((preview, index) => {
+        if (index > 0 && !isPorcelain()) print();
+        printPreview(preview);
+      });
+      if (previews.length > SHOW_LIMIT && !isPorcelain()) {
+        print();
+        print(getTheme().dim(`(${String(previews.length - SHOW_LIMIT)} more matches; qualify the name to narrow)`));
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
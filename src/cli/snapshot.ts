@@ -0,0 +1,267 @@
+/**
+ * CLI commands: export, import
+ * Move an index between databases as a snapshot file
+ *
+ *   cindex index . && cindex export --format=protobuf -o api.snapshot   # CI artifact
+ *   cindex export --format compact -o api.snapshot                      # zstd blocks, smallest
+ *   cindex import api.snapshot --path .                                 # no re-parsing
+ *   cindex export --format scip -o index.scip                          # for Sourcegraph (src code-intel upload)
+ *   cindex export --format packed -o monorepo.cidx                     # searched in place by cindex grep --packed
+ *
+ * A snapshot carries one index, embeddings included, so the importing side
+ * must use the same embedding model. See @indexing/snapshot-format for the
+ * format and @indexing/snapshot for what is (and is not) carried over.
+ * --format scip writes a SCIP index instead (see @indexing/scip), and
+ * --format packed a packed content index (see @indexing/packed-index);
+ * cindex import reads neither.
+ */
+import { once } from 'node:events';
+import * as fs from 'node:fs';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { resolveRepoId } from '@cli/selection';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listIndexedRepositories } from '@database/queries';
+import { syncStore } from '@database/store';
+import { acquireIndexLock, beginGeneration, publishGeneration } from '@indexing/index-lock';
+import { exportPackedIndex } from '@indexing/packed-index';
+import { exportScip } from '@indexing/scip';
+import { exportSnapshot, importSnapshot, type SnapshotImportResult } from '@indexing/snapshot';
+import {
+  compactBlockWriter,
+  encodeSnapshotHeader,
+  encodeSnapshotRow,
+  isSnapshotEncoding,
+  readSnapshot,
+  SNAPSHOT_ENCODINGS,
+  type SnapshotReader,
+} from '@indexing/snapshot-format';
+import { CindexError } from '@utils/errors';
+import { normalizeRootPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Total rows of per-table counts
+ */
+const totalRows = (rows: Record<string, number>): number => Object.values(rows).reduce((sum, count) => sum + count, 0);
+
+/** Values of cindex export --format */
+const EXPORT_FORMATS = [...SNAPSHOT_ENCODINGS, 'scip', 'packed'];
+
+/**
+ * Export command - write an index to a snapshot file
+ */
+export const exportCommand: CliCommand = {
+  name: 'export',
+  description: 'Write an index, embeddings included, to a snapshot file, or as a SCIP or packed content index',
+  usage: 'cindex export [<repo-id>] [--format jsonl|protobuf|compact|scip|packed] [-o <file>]',
+  options: [
+    {
+      name: 'format',
+      description: 'Snapshot encoding, scip for a SCIP index, or packed for a packed content index (default: jsonl)',
+      takesValue: true,
+      complete: EXPORT_FORMATS,
+    },
+    { name: 'output', description: 'Snapshot file (default: stdout)', takesValue: true, complete: 'path' },
+  ],
+  positional: 'repo',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { format: { type: 'string', default: 'jsonl' }, output: { type: 'string', short: 'o' } },
+    });
+
+    const repoId = positionals.at(0) ?? resolveRepoId(undefined);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing index to export',
+        hint: `Usage: ${exportCommand.usage} (or select one with cindex use)`,
+      });
+    }
+    const encoding = values.format;
+    if (encoding !== 'scip' && encoding !== 'packed' && !isSnapshotEncoding(encoding)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --format value '${encoding}' (expected ${EXPORT_FORMATS.join(', ')})`,
+      });
+    }
+    // A packed index is read in place, so it needs a file to seek in
+    if (encoding === 'packed' && !values.output) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--format packed writes to a file: it needs -o <file>',
+        hint: 'e.g. cindex export --format packed -o monorepo.cidx',
+      });
+    }
+
+    // Written next to the destination and renamed at the end, so a failed export leaves no partial file
+    const target = values.output ? { file: values.output, temp: `${values.output}.${String(process.pid)}.tmp` } : null;
+    const { config, db } = await openSession();
+    try {
+      const repos = await listIndexedRepositories(db.getPool());
+      const repo = repos.find((candidate) => candidate.repo_id === repoId);
+      if (!repo) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+
+      const out = target ? fs.createWriteStream(target.temp) : process.stdout;
+      const write = async (bytes: Buffer): Promise<void> => {
+        if (!out.write(bytes)) await once(out, 'drain');
+      };
+      // SCIP counts documents and packed indexes files in place of rows
+      let rows: number;
+      let detail: string;
+      if (encoding === 'packed') {
+        const result = await exportPackedIndex(db.getPool(), repoId, write);
+        rows = result.files;
+        detail = `${String(result.files)} files, ${String(result.trigrams)} trigrams`;
+      } else if (encoding === 'scip') {
+        const result = await exportScip(db.getPool(), repoId, repo.repo_path ?? '.', write, ['export', ...args]);
+        rows = result.documents;
+        detail = `${String(result.documents)} documents, ${String(result.occurrences)} occurrences`;
+      } else {
+        // Compact snapshots gather the records into compressed blocks
+        const blocks = encoding === 'compact' ? compactBlockWriter(write) : null;
+        const result = await exportSnapshot(db, repoId, config.embedding, blocks?.write ?? write, {
+          header: (header) => encodeSnapshotHeader(header, encoding),
+          row: (row) => encodeSnapshotRow(row, encoding),
+        });
+        await blocks?.end();
+        rows = totalRows(result.rows);
+        detail = `${String(rows)} rows`;
+      }
+
+      // With the snapshot on stdout, nothing else may be printed there
+      if (!target) return ExitCode.Success;
+      out.end();
+      await once(out, 'finish');
+      fs.renameSync(target.temp, target.file);
+
+      // Porcelain: exported<TAB>repo_id<TAB>format<TAB>rows (documents for scip, files for packed)<TAB>file
+      if (isPorcelain()) {
+        printRecord('exported', [repoId, encoding, rows, target.file]);
+      } else {
+        const size = (fs.statSync(target.file).size / (1024 * 1024)).toFixed(1);
+        print(`Exported '${repoId}' to ${getTheme().path(target.file)} (${detail}, ${size} MB, ${encoding})`);
+      }
+      return ExitCode.Success;
+    } finally {
+      if (target) fs.rmSync(target.temp, { force: true });
+      await db.close();
+    }
+  },
+};
+
+/**
+ * Import command - load a snapshot file, replacing the index it holds
+ */
+export const importCommand: CliCommand = {
+  name: 'import',
+  description: 'Load an index from a snapshot file (cindex export), replacing it',
+  usage: 'cindex import <file|-> [--path <repo>] [--force] [--wait]',
+  local: true,
+  options: [
+    {
+      name: 'path',
+      description: 'Repository the index now belongs to (default: path it was built at)',
+      takesValue: true,
+      complete: 'dir',
+    },
+    { name: 'force', description: 'Import vectors of a different embedding model' },
+    { name: 'wait', description: 'Wait for a running indexer of the same index' },
+  ],
+  positional: 'path',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: { path: { type: 'string' }, force: { type: 'boolean' }, wait: { type: 'boolean' } },
+    });
+
+    const [file] = positionals;
+    if (!file) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing snapshot file',
+        hint: `Usage: ${importCommand.usage}`,
+      });
+    }
+
+    let snapshot: SnapshotReader;
+    try {
+      const input = file === '-' ? process.stdin : fs.createReadStream(file);
+      snapshot = await readSnapshot(input as AsyncIterable<Buffer>);
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      return reportError(ExitCode.Failure, {
+        code: error instanceof CindexError ? error.code : 'READ_ERROR',
+        message: `Cannot import ${file}: ${message}`,
+        file,
+        hint: error instanceof CindexError ? error.suggestion : undefined,
+      });
+    }
+
+    const { header } = snapshot;
+    const { config, db } = await openSession();
+    try {
+      // Vectors of another size cannot be stored; another model's vectors would be compared with wrong queries
+      const { embedding } = config;
+      if (header.embedding.dimensions !== embedding.dimensions) {
+        return reportError(ExitCode.Failure, {
+          code: 'EMBEDDING_MISMATCH',
+          message:
+            `Snapshot vectors have ${String(header.embedding.dimensions)} dimensions, ` +
+            `the database ${String(embedding.dimensions)}`,
+          file,
+          hint: `Import into a database set up for ${header.embedding.model}`,
+        });
+      }
+      if (!values.force && header.embedding.model !== embedding.model) {
+        return reportError(ExitCode.Failure, {
+          code: 'EMBEDDING_MISMATCH',
+          message: `Snapshot was embedded with ${header.embedding.model}, this configuration uses ${embedding.model}`,
+          file,
+          hint: `Set EMBEDDING_MODEL=${header.embedding.model}, or pass --force to import anyway`,
+        });
+      }
+
+      const repoPath = values.path ? normalizeRootPath(values.path) : undefined;
+      const lock = await acquireIndexLock(header.repo_id, values.wait);
+      beginGeneration(header.repo_id);
+      let result: SnapshotImportResult;
+      try {
+        result = await importSnapshot(db, snapshot, { repoPath });
+        // The store copies the imported symbols as it does those of an indexing run
+        await syncStore(config.store, db.getPool(), header.repo_id);
+      } finally {
+        publishGeneration(header.repo_id);
+        lock.release();
+      }
+
+      // Porcelain: imported<TAB>repo_id<TAB>rows<TAB>version, skipped<TAB>table[.column]
+      const rows = totalRows(result.rows);
+      const skipped = [...result.skipped_tables, ...result.skipped_columns];
+      if (isPorcelain()) {
+        printRecord('imported', [result.repo_id, rows, header.version]);
+        for (const name of skipped) printRecord('skipped', [name]);
+      } else {
+        const theme = getTheme();
+        print(`Imported '${result.repo_id}' (${String(rows)} rows, exported ${header.exported_at})`);
+        if (skipped.length > 0) {
+          print(theme.dim(`Skipped data this version of cindex does not store: ${skipped.join(', ')}`));
+        }
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
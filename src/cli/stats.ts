@@ -0,0 +1,296 @@
+/**
+ * CLI command: stats
+ * Show locally recorded query latency and index build times, or the composition of an index
+ *
+ *   cindex stats             totals per operation
+ *   cindex stats --history   one row per day and operation
+ *   cindex stats --index     histograms of the selected index (--json for dashboards)
+ *   cindex stats --by dir    lines, comments, symbols, complexity, and tests per directory
+ *   cindex stats --by package --format csv > packages.csv
+ *
+ * Recording is opt-in (ENABLE_USAGE_STATS=true); see usage-stats.ts. Index
+ * composition and file metrics are read from the index itself and need no
+ * recording.
+ */
+import { parseArgs } from 'node:util';
+
+import {
+  aggregateFileStats,
+  isStatsGrouping,
+  STATS_GROUPINGS,
+  toFileStatsCsv,
+  type StatsGrouping,
+} from '@cli/file-stats';
+import { buildHistograms, renderBarChart, type HistogramBucket } from '@cli/histogram';
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { readUsageEvents, STATS_FILE, summarizeUsage } from '@cli/usage-stats';
+import { getIndexComposition, listFileStats } from '@database/queries';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { ENV_VARS } from '@/types/config';
+
+/**
+ * Format milliseconds for humans (ms below one second, otherwise seconds)
+ */
+const formatDuration = (ms: number): string => (ms < 1000 ? `${String(ms)}ms` : `${(ms / 1000).toFixed(1)}s`);
+
+/** Histograms in display order, with their titles */
+const HISTOGRAM_TITLES = {
+  symbols_per_file: 'Symbols per file',
+  function_complexity: 'Function complexity (cyclomatic)',
+  file_lines: 'File size (lines)',
+} as const;
+
+/**
+ * stats --index - histograms of an index's composition
+ */
+const printIndexComposition = async (repoId: string | undefined, json: boolean): Promise<ExitCode> => {
+  const { db } = await openSession();
+  try {
+    const histograms = buildHistograms(await readIndex(repoId, () => getIndexComposition(db.getPool(), repoId)));
+    if (histograms.files === 0) {
+      if (!isPorcelain()) print('No indexed files');
+      return ExitCode.NoResults;
+    }
+
+    if (json) {
+      process.stdout.write(JSON.stringify({ repo_id: repoId ?? null, ...histograms }, null, 2) + '\n');
+      return ExitCode.Success;
+    }
+
+    // Porcelain: histogram<TAB>name<TAB>bucket<TAB>min<TAB>max<TAB>count
+    //            language<TAB>language<TAB>files<TAB>lines<TAB>share
+    if (isPorcelain()) {
+      for (const name of Object.keys(HISTOGRAM_TITLES) as (keyof typeof HISTOGRAM_TITLES)[]) {
+        for (const { label, min, max, count } of histograms[name]) {
+          printRecord('histogram', [name, label, min, max, count]);
+        }
+      }
+      for (const { language, files, lines, share } of histograms.languages) {
+        printRecord('language', [language, files, lines, share.toFixed(4)]);
+      }
+      return ExitCode.Success;
+    }
+
+    const theme = getTheme();
+    print(`${String(histograms.files)} files, ${String(histograms.symbols)} symbols`);
+    for (const [name, title] of Object.entries(HISTOGRAM_TITLES) as [keyof typeof HISTOGRAM_TITLES, string][]) {
+      const buckets: HistogramBucket[] = histograms[name];
+      print();
+      print(theme.path(title));
+      if (buckets.length === 0) {
+        print(theme.dim('  (no data; re-index to record it)'));
+        continue;
+      }
+      for (const line of renderBarChart(buckets)) print(`  ${line}`);
+    }
+
+    print();
+    print(theme.path('Languages (share of files)'));
+    const languages = histograms.languages.map(({ language, files, share }) => ({
+      label: `${language} ${(share * 100).toFixed(1).padStart(5)}%`,
+      count: files,
+    }));
+    for (const line of renderBarChart(languages)) print(`  ${line}`);
+    return ExitCode.Success;
+  } finally {
+    await db.close();
+  }
+};
+
+/** Encodings accepted by --format (default: a table, or porcelain records) */
+const FILE_STATS_FORMATS = ['json', 'csv'] as const;
+
+/** Columns of the --by table after the group */
+const FILE_STATS_COLUMNS = ['files', 'lines', 'code', 'comments', 'symbols', 'avg cc', 'tests'];
+
+/**
+ * Format a fraction as a percentage for humans ('-' when unknown)
+ */
+const formatShare = (value: number | null): string => (value === null ? '-' : `${(value * 100).toFixed(1)}%`);
+
+/**
+ * stats --by - metrics per file, package, or directory
+ */
+const printFileStats = async (
+  repoId: string | undefined,
+  by: StatsGrouping,
+  format: string | undefined
+): Promise<ExitCode> => {
+  const { db } = await openSession();
+  try {
+    const rows = aggregateFileStats(await readIndex(repoId, () => listFileStats(db.getPool(), repoId)), by);
+    if (rows.length === 0) {
+      if (!isPorcelain() && format === undefined) print('No indexed files');
+      return ExitCode.NoResults;
+    }
+
+    if (format === 'json') {
+      process.stdout.write(JSON.stringify({ repo_id: repoId ?? null, by, rows }, null, 2) + '\n');
+      return ExitCode.Success;
+    }
+    if (format === 'csv') {
+      process.stdout.write(toFileStatsCsv(rows, by));
+      return ExitCode.Success;
+    }
+
+    // Porcelain: <by><TAB>repo_id<TAB>group<TAB>files<TAB>test_files<TAB>lines<TAB>code_lines<TAB>comment_lines
+    //            <TAB>blank_lines<TAB>symbols<TAB>functions<TAB>avg_complexity<TAB>comment_density<TAB>test_ratio
+    if (isPorcelain()) {
+      for (const row of rows) {
+        printRecord(by, [
+          row.repo_id,
+          row.group,
+          row.files,
+          row.test_files,
+          row.lines,
+          row.code_lines,
+          row.comment_lines,
+          row.blank_lines,
+          row.symbols,
+          row.functions,
+          row.avg_complexity?.toFixed(2),
+          row.comment_density?.toFixed(4),
+          row.test_ratio?.toFixed(4),
+        ]);
+      }
+      return ExitCode.Success;
+    }
+
+    const theme = getTheme();
+    const label = by === 'file' ? 'path' : by === 'dir' ? 'directory' : by;
+    const width = Math.max(label.length, ...rows.map((row) => row.group.length));
+    const cells = (values: string[]): string => values.map((value) => value.padStart(10)).join('');
+    let repo: string | null | undefined;
+    for (const row of rows) {
+      if (row.repo_id !== repo) {
+        repo = row.repo_id;
+        if (repo !== null && !repoId) print(theme.path(repo));
+        print(theme.dim(`${label.padEnd(width)}${cells(FILE_STATS_COLUMNS)}`));
+      }
+      const values = [
+        String(row.files),
+        String(row.lines),
+        row.code_lines === null ? '-' : String(row.code_lines),
+        formatShare(row.comment_density),
+        String(row.symbols),
+        row.avg_complexity === null ? '-' : row.avg_complexity.toFixed(1),
+        row.test_ratio === null ? '-' : row.test_ratio.toFixed(2),
+      ];
+      print(`${row.group.padEnd(width)}${cells(values)}`);
+    }
+    if (rows.some((row) => row.code_lines === null)) {
+      print(theme.dim('(code -: indexed before line counts were recorded; re-index to count them)'));
+    }
+    return ExitCode.Success;
+  } finally {
+    await db.close();
+  }
+};
+
+/**
+ * Stats command - summarize the local usage statistics file, or the composition of an index
+ */
+export const statsCommand: CliCommand = {
+  name: 'stats',
+  description: 'Show recorded query and indexing times (opt-in), index composition, or per-file metrics',
+  usage:
+    'cindex stats [--history] | cindex stats --index [--json] [--repo-id <name>] | ' +
+    'cindex stats --by file|package|dir [--format json|csv] [--repo-id <name>]',
+  options: [
+    { name: 'history', description: 'Break timings down by day' },
+    { name: 'index', description: 'Histograms of the index: symbols, complexity, file size, languages' },
+    { name: 'json', description: 'With --index, write the histograms as JSON' },
+    {
+      name: 'by',
+      description: 'Lines, comment density, symbols, complexity, and test ratio per file, package, or directory',
+      takesValue: true,
+      complete: [...STATS_GROUPINGS],
+    },
+    {
+      name: 'format',
+      description: 'With --by, write the rows as JSON or CSV (default: a table)',
+      takesValue: true,
+      complete: [...FILE_STATS_FORMATS],
+    },
+    REPO_ID_OPTION,
+  ],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        history: { type: 'boolean', default: false },
+        index: { type: 'boolean', default: false },
+        json: { type: 'boolean', default: false },
+        by: { type: 'string' },
+        format: { type: 'string' },
+        'repo-id': { type: 'string' },
+      },
+    });
+
+    if (values.by !== undefined || values.format !== undefined) {
+      const by = values.by ?? 'file';
+      if (values.index || values.history) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: '--by and --format cannot be combined with --index or --history',
+        });
+      }
+      if (!isStatsGrouping(by)) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: `Invalid --by value '${by}' (expected ${STATS_GROUPINGS.join(', ')})`,
+        });
+      }
+      if (values.format !== undefined && !(FILE_STATS_FORMATS as readonly string[]).includes(values.format)) {
+        return reportError(ExitCode.Usage, {
+          code: 'USAGE_ERROR',
+          message: `Invalid --format value '${values.format}' (expected ${FILE_STATS_FORMATS.join(', ')})`,
+        });
+      }
+      return printFileStats(resolveRepoId(values['repo-id']), by, values.format);
+    }
+
+    if (values.index) return printIndexComposition(resolveRepoId(values['repo-id']), values.json);
+
+    const events = readUsageEvents();
+    if (events.length === 0) {
+      if (!isPorcelain()) {
+        print(`No usage statistics recorded in ${STATS_FILE}`);
+        print(`Set ${ENV_VARS.ENABLE_USAGE_STATS}=true to record query and indexing times locally.`);
+      }
+      return ExitCode.NoResults;
+    }
+
+    const summaries = summarizeUsage(events, values.history);
+
+    // Porcelain: usage<TAB>day<TAB>kind<TAB>operation<TAB>count<TAB>p50_ms<TAB>p95_ms<TAB>max_ms
+    if (isPorcelain()) {
+      for (const row of summaries) {
+        printRecord('usage', [row.day, row.kind, row.operation, row.count, row.p50_ms, row.p95_ms, row.max_ms]);
+      }
+      return ExitCode.Success;
+    }
+
+    const theme = getTheme();
+    const width = Math.max(...summaries.map((row) => row.operation.length));
+    let day: string | undefined;
+    for (const row of summaries) {
+      if (row.day && row.day !== day) {
+        day = row.day;
+        print(theme.path(day));
+      }
+      const timings = [row.p50_ms, row.p95_ms, row.max_ms].map(formatDuration);
+      const detail = `p50 ${timings[0]}, p95 ${timings[1]}, max ${timings[2]}`;
+      print(`  ${row.kind.padEnd(5)} ${row.operation.padEnd(width)}  ${String(row.count).padStart(5)}x  ${detail}`);
+    }
+
+    const first = events[0].timestamp.slice(0, 10);
+    const last = events[events.length - 1].timestamp.slice(0, 10);
+    print(theme.dim(`(${String(events.length)} operations, ${first} to ${last})`));
+    return ExitCode.Success;
+  },
+};
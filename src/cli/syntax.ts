@@ -0,0 +1,273 @@
+/**
+ * Lightweight syntax highlighting for source previews
+ *
+ * A single-pass scanner that recognizes comments, string literals, numbers,
+ * and keywords. It does not try to be a full lexer: the goal is readable
+ * terminal output for `cindex show`, and unknown languages fall back to a
+ * C-style rule set.
+ */
+import chalk from 'chalk';
+
+import { getTheme, type Theme } from '@cli/theme';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
+
+/**
+ * Kinds of highlighted source tokens
+ */
+export type SyntaxTokenKind = 'keyword' | 'string' | 'comment' | 'number' | 'text';
+
+/**
+ * Highlighted source token
+ */
+export interface SyntaxToken {
+  kind: SyntaxTokenKind;
+  text: string;
+}
+
+/**
+ * Per-language scanning rules
+ */
+interface SyntaxRules {
+  keywords: Set<string>;
+  lineComments: string[];
+  blockComments: [string, string][];
+  /** String delimiters, longest first (''' before ') */
+  quotes: string[];
+}
+
+const C_COMMENTS = { lineComments: ['//'], blockComments: [['/*', '*/']] as [string, string][] };
+
+/**
+ * Build a keyword set from a space-separated list
+ */
+const keywordSet = (...lists: string[]): Set<string> => new Set(lists.join(' ').split(/\s+/).filter(Boolean));
+
+const JS_KEYWORDS = `
+  async await break case catch class const continue default delete do else export extends false finally for from
+  function if import in instanceof let new null of return static super switch this throw true try typeof undefined var
+  void while yield
+`;
+
+const TS_KEYWORDS = `
+  abstract as declare enum implements interface keyof namespace private protected public readonly satisfies type
+`;
+
+const C_KEYWORDS = `
+  break case char const continue default do double else enum extern float for goto if int long return short signed
+  sizeof static struct switch typedef union unsigned void volatile while NULL
+`;
+
+/**
+ * Keywords per language (Language enum values)
+ */
+const KEYWORDS: Record<string, Set<string>> = {
+  typescript: keywordSet(JS_KEYWORDS, TS_KEYWORDS),
+  javascript: keywordSet(JS_KEYWORDS),
+  python: keywordSet(`
+    and as assert async await break class continue def del elif else except False finally for from global if import in
+    is lambda None nonlocal not or pass raise return self True try while with yield
+  `),
+  go: keywordSet(`
+    break case chan const continue default defer else fallthrough false for func go goto if import interface map nil
+    package range return select struct switch true type var
+  `),
+  rust: keywordSet(`
+    as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut
+    pub ref return self Self static struct super trait true type unsafe use where while
+  `),
+  java: keywordSet(`
+    abstract boolean break case catch class continue default do else enum extends false final finally for if
+    implements import instanceof int interface new null package private protected public return static super switch
+    synchronized this throw throws true try void while
+  `),
+  c: keywordSet(C_KEYWORDS),
+  cpp: keywordSet(C_KEYWORDS, `
+    auto bool catch class constexpr delete false namespace new nullptr override private protected public template this
+    throw true try typename using virtual
+  `),
+  csharp: keywordSet(`
+    abstract as async await base bool break case catch class const continue default else enum false finally for
+    foreach if in int interface internal is namespace new null override private protected public readonly return
+    static string struct switch this throw true try using var virtual void while
+  `),
+  php: keywordSet(`
+    abstract as break case catch class const continue echo else elseif extends false finally fn for foreach function
+    if implements interface namespace new null private protected public return static switch throw trait true try use
+    while
+  `),
+  ruby: keywordSet(`
+    begin break case class def do else elsif end ensure false for if in module next nil not or and raise rescue return
+    self super then true unless until when while yield
+  `),
+  swift: keywordSet(`
+    as break case catch class continue default defer do else enum extension false for func guard if import in init let
+    nil private protocol public return self static struct switch throw throws true try var where while
+  `),
+  kotlin: keywordSet(`
+    as break class companion continue data else false for fun if import in interface is object override package
+    private public return sealed super this throw true try val var when while null
+  `),
+};
+
+/**
+ * Build rules for a language
+ */
+const rulesFor = (language: string): SyntaxRules => {
+  const keywords = KEYWORDS[language] ?? new Set<string>();
+  switch (language) {
+    case 'python':
+      return { keywords, lineComments: ['#'], blockComments: [], quotes: ['"""', "'''", '"', "'"] };
+    case 'ruby':
+      return { keywords, lineComments: ['#'], blockComments: [], quotes: ['"', "'"] };
+    case 'php':
+      return { keywords, ...C_COMMENTS, lineComments: ['//', '#'], quotes: ['"', "'"] };
+    case 'typescript':
+    case 'javascript':
+      return { keywords, ...C_COMMENTS, quotes: ['`', '"', "'"] };
+    case 'go':
+      return { keywords, ...C_COMMENTS, quotes: ['`', '"', "'"] };
+    case 'rust':
+      // Single quotes are also lifetimes ('a), so only double-quoted strings are highlighted
+      return { keywords, ...C_COMMENTS, quotes: ['"'] };
+    default:
+      return { keywords, ...C_COMMENTS, quotes: ['"', "'"] };
+  }
+};
+
+/** Identifier at the scan position (any Unicode letters, not just ASCII) */
+const WORD_PATTERN = new RegExp(IDENTIFIER_PATTERN, 'uy');
+
+/** Numeric literal at the scan position (decimal, hex, floats, suffixes) */
+const NUMBER_PATTERN = /\d[\w.]*/y;
+
+/**
+ * Find the end of a string literal
+ *
+ * Backslash escapes are skipped. Only triple quotes and backticks span lines;
+ * an unterminated single-line string ends at the newline.
+ *
+ * @returns Index just past the closing quote
+ */
+const findStringEnd = (code: string, start: number, quote: string): number => {
+  const multiline = quote.length === 3 || quote === '`';
+  for (let i = start; i < code.length; i++) {
+    if (code[i] === '\\') {
+      i++;
+      continue;
+    }
+    if (code[i] === '\n' && !multiline) return i;
+    if (code.startsWith(quote, i)) return i + quote.length;
+  }
+  return code.length;
+};
+
+/**
+ * Split source code into highlighted tokens
+ *
+ * Concatenating the token texts always yields the input unchanged.
+ *
+ * @param code - Source text
+ * @param language - Language (Language enum value); unknown languages use C-style rules
+ * @returns Tokens in source order (adjacent plain text is merged)
+ */
+export const tokenize = (code: string, language: string): SyntaxToken[] => {
+  const rules = rulesFor(language);
+  const tokens: SyntaxToken[] = [];
+  let text = '';
+
+  const push = (kind: SyntaxTokenKind, value: string): void => {
+    if (kind === 'text') {
+      text += value;
+      return;
+    }
+    if (text) {
+      tokens.push({ kind: 'text', text });
+      text = '';
+    }
+    tokens.push({ kind, text: value });
+  };
+
+  let i = 0;
+  while (i < code.length) {
+    const block = rules.blockComments.find(([open]) => code.startsWith(open, i));
+    if (block) {
+      const close = code.indexOf(block[1], i + block[0].length);
+      const end = close === -1 ? code.length : close + block[1].length;
+      push('comment', code.slice(i, end));
+      i = end;
+      continue;
+    }
+
+    if (rules.lineComments.some((marker) => code.startsWith(marker, i))) {
+      const newline = code.indexOf('\n', i);
+      const end = newline === -1 ? code.length : newline;
+      push('comment', code.slice(i, end));
+      i = end;
+      continue;
+    }
+
+    const quote = rules.quotes.find((candidate) => code.startsWith(candidate, i));
+    if (quote) {
+      const end = findStringEnd(code, i + quote.length, quote);
+      push('string', code.slice(i, end));
+      i = end;
+      continue;
+    }
+
+    WORD_PATTERN.lastIndex = i;
+    const word = WORD_PATTERN.exec(code);
+    if (word) {
+      push(rules.keywords.has(word[0]) ? 'keyword' : 'text', word[0]);
+      i += word[0].length;
+      continue;
+    }
+
+    NUMBER_PATTERN.lastIndex = i;
+    const number = NUMBER_PATTERN.exec(code);
+    if (number) {
+      push('number', number[0]);
+      i += number[0].length;
+      continue;
+    }
+
+    push('text', code[i]);
+    i++;
+  }
+
+  if (text) tokens.push({ kind: 'text', text });
+  return tokens;
+};
+
+/**
+ * Highlight source code with the active theme
+ *
+ * Styles are applied per line so each output line can be prefixed (e.g. with
+ * a line-number gutter) without breaking escape sequences.
+ *
+ * @param code - Source text
+ * @param language - Language (Language enum value)
+ * @returns Highlighted lines (plain lines when colors are disabled)
+ */
+export const highlightCode = (code: string, language: string): string[] => {
+  if (chalk.level === 0) {
+    return code.split('\n');
+  }
+
+  const theme = getTheme();
+  const styles: Record<Exclude<SyntaxTokenKind, 'text'>, Theme['keyword']> = {
+    keyword: theme.keyword,
+    string: theme.string,
+    comment: theme.comment,
+    number: theme.number,
+  };
+
+  const lines: string[] = [''];
+  for (const token of tokenize(code, language)) {
+    token.text.split('\n').forEach((piece, index) => {
+      if (index > 0) lines.push('');
+      const styled = token.kind === 'text' || piece === '' ? piece : styles[token.kind](piece);
+      lines[lines.length - 1] += styled;
+    });
+  }
+  return lines;
+};
@@ -0,0 +1,156 @@
+/**
+ * CLI command: tests
+ * List the Go tests that exercise changed declarations or files
+ *
+ *   cindex tests AuthService.Login                     tests reaching one method
+ *   cindex tests auth.Login hashPassword --exact       several names, resolved calls only
+ *   cindex tests --file internal/auth/session.go       tests reaching any declaration of a file
+ *   cindex tests $(git diff --name-only) --go-test     go test commands for CI
+ *
+ * Tests are linked to production code through the call graph recorded at
+ * index time (see @retrieval/test-mapping), so no --typed run is needed.
+ * Positional .go paths are taken as --file.
+ */
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { listGoCalls, listGoFunctions } from '@database/queries';
+import { goTestCommands, TestMap, type TestLink } from '@retrieval/test-mapping';
+import { compareStrings } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
+import { ExitCode, type CliCommand } from '@/types/cli';
+
+/**
+ * Keep one link per test: the exact one, then the nearest
+ */
+const mergeLinks = (links: TestLink[]): TestLink[] => {
+  const best = new Map<string, TestLink>();
+  for (const link of links) {
+    const key = `${link.test.file_path}\0${link.test.symbol_name}`;
+    const current = best.get(key);
+    const better =
+      !current ||
+      (current.possible && !link.possible) ||
+      (current.possible === link.possible && link.depth < current.depth);
+    if (better) best.set(key, link);
+  }
+  return [...best.values()];
+};
+
+/**
+ * Tests command - tests reaching declarations, from the call graph
+ */
+export const testsCommand: CliCommand = {
+  name: 'tests',
+  description: 'List the Go tests that call changed functions, methods, or files',
+  usage: 'cindex tests [<name|file.go>...] [--file <path>]... [--exact] [--depth <n>] [--go-test] [--repo-id <name>]',
+  options: [
+    REPO_ID_OPTION,
+    { name: 'file', description: 'Tests reaching any declaration of a file (repeatable)', takesValue: true },
+    { name: 'exact', description: 'Leave out links through method calls on values of unknown type' },
+    { name: 'depth', description: 'Most calls between a test and the declaration (default: any)', takesValue: true },
+    { name: 'go-test', description: 'Print go test commands running the tests, one per package' },
+  ],
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        file: { type: 'string', multiple: true, default: [] },
+        exact: { type: 'boolean', default: false },
+        depth: { type: 'string' },
+        'go-test': { type: 'boolean', default: false },
+      },
+    });
+
+    const files = [...values.file, ...positionals.filter((arg) => arg.endsWith('.go'))].map(toPosixPath);
+    const names = positionals.filter((arg) => !arg.endsWith('.go'));
+    if (files.length === 0 && names.length === 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'Missing function name or file',
+        hint: 'Usage: cindex tests <name|file.go>..., e.g. cindex tests AuthService.Login',
+      });
+    }
+    const depth = values.depth !== undefined ? Number(values.depth) : Infinity;
+    if (values.depth !== undefined && (!Number.isInteger(depth) || depth < 1)) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --depth value: ${values.depth}`,
+        hint: 'Expected a number of calls, such as 1 for tests that call the declaration directly',
+      });
+    }
+    const repoId = resolveRepoId(values['repo-id']);
+    if (!repoId) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: 'No index selected',
+        hint: 'Pass --repo-id <name> or select an index with: cindex use <name>',
+      });
+    }
+
+    const { db } = await openSession();
+    try {
+      const pool = db.getPool();
+      const { functions, calls } = await readIndex(repoId, async () => ({
+        functions: await listGoFunctions(pool, repoId),
+        calls: await listGoCalls(pool, repoId),
+      }));
+      const map = new TestMap(functions, calls, { exact: values.exact });
+
+      const unknown = names.filter((name) => map.resolve(name).length === 0);
+      const targets = names.flatMap((name) => map.resolve(name));
+      const links = mergeLinks([
+        ...map.testsFor(targets, depth),
+        ...files.flatMap((file) => map.coveredBy(file, depth)),
+      ]).sort((a, b) => compareStrings(a.test.file_path, b.test.file_path) || a.test.line_number - b.test.line_number);
+
+      // Porcelain:
+      //   test<TAB>repo_id<TAB>path<TAB>line<TAB>name<TAB>kind<TAB>target<TAB>target_path<TAB>depth<TAB>possible
+      //   unmatched<TAB>name (no production function or method has the name)
+      if (isPorcelain()) {
+        for (const name of unknown) printRecord('unmatched', [name]);
+        for (const link of links) {
+          printRecord('test', [
+            link.test.repo_id,
+            link.test.file_path,
+            link.test.line_number,
+            link.test.symbol_name,
+            link.test.symbol_type,
+            link.target.symbol_name,
+            link.target.file_path,
+            link.depth,
+            link.possible,
+          ]);
+        }
+      } else if (values['go-test']) {
+        for (const command of goTestCommands(links)) print(command);
+      } else {
+        const theme = getTheme();
+        for (const name of unknown) print(theme.dim(`No function or method named ${name}`));
+        for (const link of links) {
+          const location = `${link.test.file_path}:${String(link.test.line_number)}`;
+          const via = link.depth > 1 ? `, ${String(link.depth)} calls deep` : '';
+          const possible = link.possible ? ', possible' : '';
+          const reason = theme.dim(`(${link.target.symbol_name}${via}${possible})`);
+          print(`${theme.path(location)}  ${theme.kind(link.test.symbol_name)}  ${reason}`);
+        }
+        if (links.length > 0) {
+          print();
+          const packages = new Set(links.map((link) => link.test.file_path.replace(/\/?[^/]*$/, ''))).size;
+          print(`${String(links.length)} tests in ${String(packages)} packages`);
+        } else {
+          print('No tests reach these declarations');
+        }
+      }
+      return links.length > 0 ? ExitCode.Success : ExitCode.NoResults;
+    } finally {
+      await db.close();
+    }
+  },
+};
@@ -0,0 +1,140 @@
+/**
+ * CLI color modes and themes
+ *
+ * --color=auto (default) colors output only when stdout is a TTY and
+ * NO_COLOR is unset; --color=always forces colors (e.g. for `less -R`);
+ * --color=never disables them. Porcelain output never contains colors.
+ */
+import chalk, { type ChalkInstance } from 'chalk';
+
+/** Accepted --color values */
+export const COLOR_MODES = ['auto', 'always', 'never'] as const;
+
+export type ColorMode = (typeof COLOR_MODES)[number];
+
+/**
+ * Styles for each part of search output
+ */
+export interface Theme {
+  /** File paths */
+  path: ChalkInstance;
+  /** Line numbers */
+  line: ChalkInstance;
+  /** Matched query text */
+  match: ChalkInstance;
+  /** Symbol kinds */
+  kind: ChalkInstance;
+  /** Secondary text (counts, separators) */
+  dim: ChalkInstance;
+  /** Source preview: keywords */
+  keyword: ChalkInstance;
+  /** Source preview: string literals */
+  string: ChalkInstance;
+  /** Source preview: comments */
+  comment: ChalkInstance;
+  /** Source preview: numeric literals */
+  number: ChalkInstance;
+}
+
+/**
+ * Built-in themes (16-color palette so they work in any color terminal)
+ */
+export const THEMES: Record<string, Theme> = {
+  default: {
+    path: chalk.magenta,
+    line: chalk.green,
+    match: chalk.bold.red,
+    kind: chalk.cyan,
+    dim: chalk.gray,
+    keyword: chalk.blue,
+    string: chalk.yellow,
+    comment: chalk.gray,
+    number: chalk.cyan,
+  },
+  light: {
+    path: chalk.blue,
+    line: chalk.green,
+    match: chalk.bold.magenta,
+    kind: chalk.cyan,
+    dim: chalk.gray,
+    keyword: chalk.magenta,
+    string: chalk.green,
+    comment: chalk.gray,
+    number: chalk.blue,
+  },
+  subtle: {
+    path: chalk.bold,
+    line: chalk.dim,
+    match: chalk.underline,
+    kind: chalk.italic,
+    dim: chalk.dim,
+    keyword: chalk.bold,
+    string: chalk.italic,
+    comment: chalk.dim,
+    number: chalk.reset,
+  },
+};
+
+/** Active theme */
+let theme: Theme = THEMES.default;
+
+/**
+ * Check whether a --color value is valid
+ */
+export const isColorMode = (value: string): value is ColorMode => {
+  return (COLOR_MODES as readonly string[]).includes(value);
+};
+
+/**
+ * Apply color mode and theme for this process
+ *
+ * @param mode - Color mode
+ * @param themeName - Theme name (must be a key of THEMES)
+ */
+export const configureColor = (mode: ColorMode, themeName = 'default'): void => {
+  theme = THEMES[themeName] ?? THEMES.default;
+
+  if (mode === 'never') {
+    chalk.level = 0;
+    return;
+  }
+
+  if (mode === 'always') {
+    chalk.level = Math.max(chalk.level, 1) as typeof chalk.level;
+    return;
+  }
+
+  // auto: respect NO_COLOR (https://no-color.org) and non-TTY stdout
+  const noColor = process.env.NO_COLOR !== undefined && process.env.NO_COLOR !== '';
+  if (noColor || !process.stdout.isTTY) {
+    chalk.level = 0;
+  }
+};
+
+/**
+ * Get the active theme
+ */
+export const getTheme = (): Theme => theme;
+
+/**
+ * Escape a string for use in a regular expression
+ */
+const escapeRegExp = (value: string): string => value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+/**
+ * Highlight case-insensitive occurrences of terms in text
+ *
+ * @param text - Text to highlight
+ * @param terms - Terms to highlight (empty strings are ignored)
+ * @returns Text with matches styled by the active theme
+ */
+export const highlight = (text: string, terms: string[]): string => {
+  const patterns = terms.filter((term) => term.length > 0).map(escapeRegExp);
+  if (patterns.length === 0 || chalk.level === 0) {
+    return text;
+  }
+
+  // Longest first so overlapping terms highlight the widest match
+  patterns.sort((a, b) => b.length - a.length);
+  return text.replace(new RegExp(patterns.join('|'), 'giu'), (match) => theme.match(match));
+};
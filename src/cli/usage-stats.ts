@@ -0,0 +1,144 @@
+/**
+ * Opt-in local usage statistics
+ *
+ * When ENABLE_USAGE_STATS is true, query latency and index build times are
+ * appended to ~/.cindex/stats.jsonl (one JSON object per line). Nothing is
+ * ever sent over the network; the file exists so users can see how
+ * performance changes over time (`cindex stats --history`) when tuning
+ * settings or sizing hardware.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { CINDEX_HOME } from '@cli/selection';
+import { logger } from '@utils/logger';
+import { type CindexConfig } from '@/types/config';
+
+/** Usage statistics file */
+export const STATS_FILE = path.join(CINDEX_HOME, 'stats.jsonl');
+
+/**
+ * One recorded operation
+ */
+export interface UsageEvent {
+  /** ISO timestamp when the operation finished */
+  timestamp: string;
+  /** Operation category */
+  kind: 'query' | 'index';
+  /** Where the operation ran */
+  source: 'cli' | 'mcp';
+  /** Command or tool name (e.g. 'search', 'search_codebase', 'index') */
+  operation: string;
+  /** Wall-clock duration in milliseconds */
+  duration_ms: number;
+  /** Repository the operation targeted */
+  repo_id?: string;
+  /** Results returned (queries) */
+  results?: number;
+  /** Files processed (index builds) */
+  files?: number;
+}
+
+/**
+ * Aggregated timings for one operation (and optionally one day)
+ */
+export interface UsageSummary {
+  /** Day (YYYY-MM-DD) for history rows, undefined for overall totals */
+  day?: string;
+  kind: UsageEvent['kind'];
+  operation: string;
+  count: number;
+  p50_ms: number;
+  p95_ms: number;
+  max_ms: number;
+}
+
+/**
+ * Append one event to the stats file when usage statistics are enabled
+ *
+ * Never throws: statistics must not break the command being measured.
+ *
+ * @param config - Loaded configuration (features.enable_usage_stats gates recording)
+ * @param event - Event without timestamp
+ */
+export const recordUsage = (config: CindexConfig, event: Omit<UsageEvent, 'timestamp'>): void => {
+  if (!config.features.enable_usage_stats) return;
+
+  try {
+    fs.mkdirSync(CINDEX_HOME, { recursive: true });
+    fs.appendFileSync(STATS_FILE, JSON.stringify({ timestamp: new Date().toISOString(), ...event }) + '\n');
+  } catch (error) {
+    logger.debug('Could not record usage statistics', {
+      file: STATS_FILE,
+      error: error instanceof Error ? error.message : String(error),
+    });
+  }
+};
+
+/**
+ * Read all recorded events (malformed lines are skipped)
+ *
+ * @returns Events, oldest first
+ */
+export const readUsageEvents = (): UsageEvent[] => {
+  let text: string;
+  try {
+    text = fs.readFileSync(STATS_FILE, 'utf-8');
+  } catch {
+    return [];
+  }
+
+  const events: UsageEvent[] = [];
+  for (const line of text.split('\n')) {
+    if (line.trim().length === 0) continue;
+    try {
+      const event = JSON.parse(line) as UsageEvent;
+      if (typeof event.duration_ms === 'number' && typeof event.operation === 'string') events.push(event);
+    } catch {
+      // Partially written line (e.g. interrupted process)
+    }
+  }
+  return events;
+};
+
+/**
+ * Nearest-rank percentile of sorted values
+ */
+const percentile = (sorted: number[], p: number): number => {
+  return sorted[Math.max(0, Math.ceil((p / 100) * sorted.length) - 1)] ?? 0;
+};
+
+/**
+ * Summarize events per operation, optionally per day
+ *
+ * @param events - Recorded events
+ * @param byDay - Group by day as well (for --history)
+ * @returns Summaries sorted by day, then kind and operation
+ */
+export const summarizeUsage = (events: UsageEvent[], byDay = false): UsageSummary[] => {
+  const groups = new Map<string, { day?: string; kind: UsageEvent['kind']; operation: string; durations: number[] }>();
+
+  for (const event of events) {
+    const day = byDay ? event.timestamp.slice(0, 10) : undefined;
+    const key = `${day ?? ''}\t${event.kind}\t${event.operation}`;
+    let group = groups.get(key);
+    if (!group) {
+      group = { day, kind: event.kind, operation: event.operation, durations: [] };
+      groups.set(key, group);
+    }
+    group.durations.push(event.duration_ms);
+  }
+
+  return [...groups.entries()]
+    .sort(([a], [b]) => a.localeCompare(b))
+    .map(([, { durations, ...group }]) => {
+      const sorted = [...durations].sort((a, b) => a - b);
+      return {
+        ...group,
+        count: sorted.length,
+        p50_ms: percentile(sorted, 50),
+        p95_ms: percentile(sorted, 95),
+        max_ms: sorted[sorted.length - 1] ?? 0,
+      };
+    });
+};
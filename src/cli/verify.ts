@@ -0,0 +1,332 @@
+/**
+ * CLI commands: verify, repair
+ * Check indexes for damage left by crashes, and rebuild only what is damaged
+ *
+ *   cindex verify                 every index (or the one selected with cindex use)
+ *   cindex verify --repo-id api   one index
+ *   cindex repair --dry-run       list the files a repair would rebuild
+ *   cindex repair                 quarantine them and re-index them incrementally
+ *
+ * Two checks run per index. Each file's stored chunks are compared with the
+ * chunk count and checksum of its file record (see @indexing/integrity), and
+ * the file records are compared with the manifest the last completed run
+ * recorded (see @indexing/manifest), one shard per directory. An index whose
+ * last run did not complete has no manifest, so only its chunks are checked.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { resolveRepoId } from '@cli/selection';
+import { openSession, readIndex } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { recordUsage } from '@cli/usage-stats';
+import { type DatabaseClient } from '@database/client';
+import { listIndexedRepositories, type RepositoryInfo } from '@database/queries';
+import { findCorruptFiles, quarantineFiles, type CorruptFile } from '@indexing/integrity';
+import {
+  buildManifest,
+  diffManifests,
+  readManifestLeaves,
+  recordedManifest,
+  type DamagedShard,
+  type IndexManifest,
+  type ManifestLeaf,
+} from '@indexing/manifest';
+import { createPipeline } from '@indexing/pipeline';
+import { ollamaEmbeddingModel } from '@utils/embedders';
+import { createOllamaClient } from '@utils/ollama';
+import { compareStrings } from '@utils/ordering';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type RepositoryType } from '@/types/database';
+import { IndexingStage, type IndexingOptions } from '@/types/indexing';
+
+/**
+ * Result of checking one index
+ */
+interface IndexCheck {
+  repo: RepositoryInfo;
+  /** Manifest the last completed run recorded (null: none) */
+  manifest: IndexManifest | null;
+  /** Manifest of the file records stored now */
+  current: IndexManifest;
+  leaves: ManifestLeaf[];
+  corrupt: CorruptFile[];
+  damaged: DamagedShard[];
+}
+
+/**
+ * Check whether an index check found damage
+ */
+const isDamaged = (check: IndexCheck): boolean => check.corrupt.length > 0 || check.damaged.length > 0;
+
+/**
+ * Check the chunks and file records of one index
+ */
+const checkIndex = async (db: DatabaseClient, repo: RepositoryInfo): Promise<IndexCheck> => {
+  return readIndex(repo.repo_id, async () => {
+    const corrupt = (await findCorruptFiles(db, repo.repo_path)).filter((file) => file.repo_id === repo.repo_id);
+    const leaves = await readManifestLeaves(db, repo.repo_id);
+    const manifest = recordedManifest(repo.metadata);
+    const current = buildManifest(leaves);
+    const damaged = manifest ? diffManifests(manifest, current) : [];
+    return { repo, manifest, current, leaves, corrupt, damaged };
+  });
+};
+
+/**
+ * Check the selected index, or every index
+ *
+ * @returns Checks ordered by index ID, or null if the selected index does not exist
+ */
+const checkIndexes = async (db: DatabaseClient, repoId: string | undefined): Promise<IndexCheck[] | null> => {
+  const repos = (await listIndexedRepositories(db.getPool(), { includeMetadata: true }))
+    .filter((repo) => !repoId || repo.repo_id === repoId)
+    .sort((a, b) => compareStrings(a.repo_id, b.repo_id));
+  if (repoId && repos.length === 0) return null;
+
+  const checks: IndexCheck[] = [];
+  for (const repo of repos) checks.push(await checkIndex(db, repo));
+  return checks;
+};
+
+/**
+ * Files a repair rebuilds: the corrupt ones, and every file of a changed shard
+ *
+ * Files of missing shards have no records left; the incremental run finds
+ * them on disk as new files.
+ */
+const filesToRebuild = (check: IndexCheck): string[] => {
+  const directories = new Set(check.damaged.filter((shard) => shard.reason !== 'missing').map((s) => s.directory));
+  const files = new Set(check.corrupt.map((file) => file.file_path));
+  for (const leaf of check.leaves) {
+    if (directories.has(path.posix.dirname(leaf.file_path))) files.add(leaf.file_path);
+  }
+  return [...files].sort(compareStrings);
+};
+
+/**
+ * Print the findings of one index check
+ *
+ * Porcelain:
+ *   verify<TAB>repo_id<TAB>status<TAB>root<TAB>files (status: ok, damaged, or unrecorded)
+ *   corrupt<TAB>repo_id<TAB>path<TAB>reason<TAB>expected_chunks<TAB>stored_chunks
+ *   shard<TAB>repo_id<TAB>directory<TAB>reason<TAB>expected_files<TAB>stored_files
+ */
+const printCheck = (check: IndexCheck): void => {
+  const { repo, manifest, current, corrupt, damaged } = check;
+  const status = isDamaged(check) ? 'damaged' : manifest ? 'ok' : 'unrecorded';
+  if (isPorcelain()) {
+    printRecord('verify', [repo.repo_id, status, current.root, current.files]);
+    for (const file of corrupt) {
+      printRecord('corrupt', [repo.repo_id, file.file_path, file.reason, file.expected_chunks, file.stored_chunks]);
+    }
+    for (const shard of damaged) {
+      printRecord('shard', [repo.repo_id, shard.directory, shard.reason, shard.expected_files, shard.stored_files]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const summary =
+    status === 'ok'
+      ? theme.dim(`ok (${String(current.files)} files, root ${current.root.slice(0, 12)})`)
+      : status === 'unrecorded'
+        ? theme.dim('chunks ok, no manifest (the last run did not complete; re-index to record one)')
+        : 'damaged';
+  print(`${theme.path(repo.repo_id)}  ${summary}`);
+  for (const file of corrupt) {
+    const chunks = `${String(file.stored_chunks)} of ${String(file.expected_chunks)} chunks`;
+    print(`  ${theme.path(file.file_path)}  ${file.reason} ${theme.dim(`(${chunks})`)}`);
+  }
+  for (const shard of damaged) {
+    const files = `${String(shard.expected_files)} files recorded, ${String(shard.stored_files)} stored`;
+    print(`  ${theme.path(`${shard.directory}/`)}  ${shard.reason} ${theme.dim(`(${files})`)}`);
+  }
+};
+
+/**
+ * Verify command - detect corrupt chunks and damaged shards
+ */
+export const verifyCommand: CliCommand = {
+  name: 'verify',
+  description: 'Check indexes for corrupt chunks and file records that differ from the last completed run',
+  usage: 'cindex verify [--repo-id <name>]',
+  options: [REPO_ID_OPTION],
+  run: async (args) => {
+    const { values } = parseArgs({ args, options: { 'repo-id': { type: 'string' } } });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { db } = await openSession();
+    try {
+      const checks = await checkIndexes(db, repoId);
+      if (!checks) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId ?? ''}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+      for (const check of checks) printCheck(check);
+
+      const damaged = checks.filter(isDamaged);
+      if (!isPorcelain() && checks.length > 1) {
+        print();
+        print(damaged.length > 0 ? `${String(damaged.length)} of ${String(checks.length)} indexes damaged` : 'All ok');
+      }
+      if (damaged.length > 0) {
+        return reportError(ExitCode.Failure, {
+          code: 'INDEX_DAMAGED',
+          message: `Damaged: ${damaged.map((check) => check.repo.repo_id).join(', ')}`,
+          hint: 'Rebuild the damaged files with: cindex repair',
+        });
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
+
+/**
+ * Repair command - rebuild the files of damaged indexes incrementally
+ */
+export const repairCommand: CliCommand = {
+  name: 'repair',
+  description: 'Re-index only the files cindex verify finds damaged',
+  usage: 'cindex repair [--repo-id <name>] [--dry-run]',
+  options: [REPO_ID_OPTION, { name: 'dry-run', description: 'List the files to rebuild without changing the index' }],
+  run: async (args) => {
+    const { values } = parseArgs({
+      args,
+      options: {
+        'repo-id': { type: 'string' },
+        'dry-run': { type: 'boolean', default: false },
+      },
+    });
+    const repoId = resolveRepoId(values['repo-id']);
+
+    const { config, db } = await openSession();
+    try {
+      const checks = await checkIndexes(db, repoId);
+      if (!checks) {
+        return reportError(ExitCode.Failure, {
+          code: 'UNKNOWN_INDEX',
+          message: `Unknown index '${repoId ?? ''}'`,
+          hint: "Run 'cindex list' to see available indexes",
+        });
+      }
+      const damaged = checks.filter(isDamaged);
+      if (damaged.length === 0) {
+        if (!isPorcelain()) print('Nothing to repair');
+        return ExitCode.Success;
+      }
+
+      // Ephemeral, Go module, and revision indexes have no working tree to rebuild from
+      const unrepairable = damaged.filter(
+        ({ repo }) =>
+          repo.metadata?.ephemeral === true ||
+          repo.metadata?.go_module !== undefined ||
+          repo.metadata?.revision !== undefined ||
+          !repo.repo_path ||
+          !fs.existsSync(repo.repo_path)
+      );
+      const repairable = damaged.filter((check) => !unrepairable.includes(check));
+
+      // Porcelain: rebuild<TAB>repo_id<TAB>path (one per file, --dry-run too)
+      const theme = getTheme();
+      for (const check of repairable) {
+        const files = filesToRebuild(check);
+        if (isPorcelain()) {
+          for (const file of files) printRecord('rebuild', [check.repo.repo_id, file]);
+        } else {
+          print(`${theme.path(check.repo.repo_id)}  ${String(files.length)} files to rebuild`);
+          if (values['dry-run']) for (const file of files) print(`  ${file}`);
+        }
+      }
+      if (values['dry-run']) return unrepairable.length > 0 ? ExitCode.Failure : ExitCode.Success;
+
+      const ollama = createOllamaClient(config.ollama);
+      if (repairable.length > 0) {
+        await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
+      }
+
+      // First Ctrl+C stops after the file in progress; the quarantined rest is rebuilt by the next run
+      const controller = new AbortController();
+      const removeSignalHandlers = handleShutdownSignals(() => {
+        controller.abort();
+      });
+      let failed = 0;
+      try {
+        const defaults = config.indexing;
+        for (const check of repairable) {
+          if (controller.signal.aborted) break;
+          const { repo } = check;
+          const repoPath = repo.repo_path ?? '';
+          await quarantineFiles(db, filesToRebuild(check).map((file_path) => ({ file_path })));
+
+          // Stored settings are kept; the run records a fresh manifest when it completes
+          const { checkpoint: _checkpoint, ...metadata } = repo.metadata ?? {};
+          const options: IndexingOptions = {
+            incremental: true,
+            waitForLock: true,
+            repoId: repo.repo_id,
+            repoName: repo.repo_name ?? undefined,
+            repoType: repo.repo_type as RepositoryType,
+            metadata,
+            symlinkPolicy: defaults.symlink_policy,
+            generatedFiles: defaults.generated_files,
+            excludeDirectories: defaults.exclude_directories,
+            maxDirectoryDepth: defaults.max_directory_depth,
+            maxPathLength: defaults.max_path_length,
+            scanSecrets: defaults.scan_secrets,
+            signal: controller.signal,
+          };
+          const stats = await createPipeline(config, db, ollama, repoPath, options).indexRepository(repoPath, options);
+          recordUsage(config, {
+            kind: 'index',
+            source: 'cli',
+            operation: 'repair',
+            duration_ms: stats.total_time_ms,
+            repo_id: repo.repo_id,
+            files: stats.files_processed,
+          });
+          if (stats.stage !== IndexingStage.Complete) failed++;
+
+          // Porcelain: repaired<TAB>repo_id<TAB>stage<TAB>indexed<TAB>failed<TAB>time_ms
+          if (isPorcelain()) {
+            const { stage, files_processed, files_failed, total_time_ms } = stats;
+            printRecord('repaired', [repo.repo_id, stage, files_processed, files_failed, total_time_ms]);
+          } else {
+            const outcome = stats.stage === IndexingStage.Complete ? 'repaired' : stats.stage;
+            print(`${theme.path(repo.repo_id)}  ${outcome}: ${String(stats.files_processed)} files re-indexed`);
+          }
+        }
+      } finally {
+        removeSignalHandlers();
+      }
+
+      if (controller.signal.aborted) {
+        return reportError(ExitCode.Interrupted, {
+          code: 'INTERRUPTED',
+          message: 'Repair interrupted',
+          hint: 'Quarantined files stay out of results until rebuilt; run cindex repair again',
+        });
+      }
+      if (unrepairable.length > 0 || failed > 0) {
+        const ids = unrepairable.map((check) => check.repo.repo_id).join(', ');
+        return reportError(ExitCode.Failure, {
+          code: 'REPAIR_FAILED',
+          message: ids ? `Cannot repair without a working tree: ${ids}` : 'Some rebuilds did not complete',
+          hint: ids ? 'Re-index them from their source with cindex index' : 'Run cindex verify for details',
+        });
+      }
+      return ExitCode.Success;
+    } finally {
+      await db.close();
+    }
+  },
+};
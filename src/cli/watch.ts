@@ -0,0 +1,246 @@
+/**
+ * CLI command: watch
+ * Keep indexes current while files change (see @indexing/watcher)
+ *
+ *   cindex watch                  every indexed repository
+ *   cindex watch . --repo-id api  one directory, indexed as api
+ *   cindex watch --metrics :9464  with Prometheus metrics at /metrics
+ *
+ * Each root is brought up to date with an incremental run at startup, then
+ * re-indexed incrementally whenever its changes settle, until Ctrl+C.
+ */
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+import { parseArgs } from 'node:util';
+
+import { isPorcelain, print, printRecord, reportError } from '@cli/output';
+import { REPO_ID_OPTION } from '@cli/search';
+import { reportListenError, startMetricsEndpoint } from '@cli/serve';
+import { openSession } from '@cli/session';
+import { getTheme } from '@cli/theme';
+import { recordUsage } from '@cli/usage-stats';
+import { listIndexedRepositories } from '@database/queries';
+import { parseListenAddress, type ListenAddress } from '@http/server';
+import { createPipeline } from '@indexing/pipeline';
+import { DEFAULT_DEBOUNCE_MS, IndexWatcher } from '@indexing/watcher';
+import { ollamaEmbeddingModel } from '@utils/embedders';
+import { createOllamaClient } from '@utils/ollama';
+import { normalizeRootPath } from '@utils/paths';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { ExitCode, type CliCommand } from '@/types/cli';
+import { type IndexingOptions, type IndexingStats } from '@/types/indexing';
+
+/**
+ * A watched directory and the index it updates
+ */
+interface WatchedRoot {
+  repoId: string;
+  repoPath: string;
+}
+
+/**
+ * Print the outcome of one run
+ *
+ * Porcelain:
+ *   update<TAB>repo_id<TAB>changed<TAB>indexed<TAB>removed<TAB>failed<TAB>time_ms
+ *   error<TAB>repo_id<TAB>path<TAB>stage<TAB>message
+ */
+const printUpdate = (root: WatchedRoot, changed: number, stats: IndexingStats): void => {
+  const removed = stats.files_removed ?? 0;
+  if (isPorcelain()) {
+    const { files_processed, files_failed, total_time_ms } = stats;
+    printRecord('update', [root.repoId, changed, files_processed, removed, files_failed, total_time_ms]);
+    for (const error of stats.errors) {
+      printRecord('error', [root.repoId, error.file_path, error.stage, error.error]);
+    }
+    return;
+  }
+
+  const theme = getTheme();
+  const changes = changed > 0 ? `${String(changed)} changed, ` : '';
+  const summary = `${changes}${String(stats.files_processed)} indexed, ${String(removed)} removed`;
+  print(`${theme.dim(new Date().toLocaleTimeString())}  ${theme.path(root.repoId)}  ${summary}`);
+  for (const error of stats.errors) {
+    print(`error  ${error.file_path ?? '-'}  ${error.stage}: ${error.error}`);
+  }
+};
+
+/**
+ * Watch command - re-index directories incrementally as they change
+ */
+export const watchCommand: CliCommand = {
+  name: 'watch',
+  description: 'Keep indexes current: re-index changed files as they are saved',
+  usage: 'cindex watch [<path> ...] [--repo-id <id>] [--debounce <ms>] [--metrics [host]:port]',
+  local: true,
+  options: [
+    REPO_ID_OPTION,
+    {
+      name: 'debounce',
+      description: `Quiet period before re-indexing, in milliseconds (default: ${String(DEFAULT_DEBOUNCE_MS)})`,
+      takesValue: true,
+    },
+    { name: 'metrics', description: 'Serve Prometheus metrics at /metrics on an address (:9464)', takesValue: true },
+  ],
+  positional: 'dir',
+  run: async (args) => {
+    const { values, positionals } = parseArgs({
+      args,
+      allowPositionals: true,
+      options: {
+        'repo-id': { type: 'string' },
+        debounce: { type: 'string' },
+        metrics: { type: 'string' },
+      },
+    });
+
+    const debounceMs = values.debounce !== undefined ? Number(values.debounce) : DEFAULT_DEBOUNCE_MS;
+    if (!Number.isInteger(debounceMs) || debounceMs < 0) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `Invalid --debounce value: ${values.debounce ?? ''}`,
+        hint: 'Expected milliseconds, e.g. --debounce 1000',
+      });
+    }
+    if (values['repo-id'] !== undefined && positionals.length !== 1) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: '--repo-id applies to a single directory',
+        hint: 'e.g. cindex watch . --repo-id api',
+      });
+    }
+    let metricsAddress: ListenAddress | undefined;
+    try {
+      metricsAddress = values.metrics !== undefined ? parseListenAddress(values.metrics) : undefined;
+    } catch (error) {
+      return reportError(ExitCode.Usage, {
+        code: 'USAGE_ERROR',
+        message: `--metrics: ${error instanceof Error ? error.message : String(error)}`,
+      });
+    }
+
+    const { config, db } = await openSession();
+    try {
+      // Ephemeral, Go module, and revision indexes have no working tree to watch
+      const repos = (await listIndexedRepositories(db.getPool(), { includeMetadata: true })).filter(
+        (repo) =>
+          repo.metadata?.ephemeral !== true &&
+          repo.metadata?.go_module === undefined &&
+          repo.metadata?.revision === undefined
+      );
+
+      let roots: WatchedRoot[];
+      if (positionals.length > 0) {
+        roots = positionals.map((dir) => {
+          const repoPath = normalizeRootPath(dir);
+          const indexed = repos.find((repo) => repo.repo_path === repoPath);
+          return { repoPath, repoId: values['repo-id'] ?? indexed?.repo_id ?? path.basename(repoPath) };
+        });
+      } else {
+        roots = repos
+          .filter((repo) => fs.existsSync(repo.repo_path))
+          .map((repo) => ({ repoPath: repo.repo_path, repoId: repo.repo_id }));
+        if (roots.length === 0) {
+          return reportError(ExitCode.Usage, {
+            code: 'USAGE_ERROR',
+            message: 'No indexed repositories to watch',
+            hint: 'Index one first with: cindex index <dir> --repo-id <name>, or run cindex watch <dir>',
+          });
+        }
+      }
+
+      const ollama = createOllamaClient(config.ollama);
+      await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
+
+      // First Ctrl+C stops after the file in progress; a second one exits at once
+      const controller = new AbortController();
+      const stopped = new Promise<void>((resolve) => {
+        controller.signal.addEventListener('abort', () => {
+          resolve();
+        });
+      });
+      const removeSignalHandlers = handleShutdownSignals(() => {
+        controller.abort();
+      });
+
+      const defaults = config.indexing;
+      const reindex = async (root: WatchedRoot, changed: string[]): Promise<void> => {
+        const options: IndexingOptions = {
+          incremental: true,
+          waitForLock: true,
+          repoId: root.repoId,
+          symlinkPolicy: defaults.symlink_policy,
+          generatedFiles: defaults.generated_files,
+          excludeDirectories: defaults.exclude_directories,
+          maxDirectoryDepth: defaults.max_directory_depth,
+          maxPathLength: defaults.max_path_length,
+          scanSecrets: defaults.scan_secrets,
+          signal: controller.signal,
+        };
+        const pipeline = createPipeline(config, db, ollama, root.repoPath, options);
+        const stats = await pipeline.indexRepository(root.repoPath, options);
+        recordUsage(config, {
+          kind: 'index',
+          source: 'cli',
+          operation: 'watch',
+          duration_ms: stats.total_time_ms,
+          repo_id: root.repoId,
+          files: stats.files_processed,
+        });
+        printUpdate(root, changed.length, stats);
+      };
+
+      const watcher = new IndexWatcher(
+        (repoPath, changed) => {
+          const root = roots.find((candidate) => candidate.repoPath === repoPath);
+          return root && !controller.signal.aborted ? reindex(root, changed) : Promise.resolve();
+        },
+        { debounceMs, excludeDirectories: defaults.exclude_directories }
+      );
+      const metricsController = new AbortController();
+      let metrics: { url: string; closed: Promise<void> } | undefined;
+      try {
+        if (metricsAddress) {
+          try {
+            metrics = await startMetricsEndpoint(metricsAddress, metricsController.signal);
+          } catch (error) {
+            return reportListenError(metricsAddress, error);
+          }
+          // Porcelain: metrics<TAB>url
+          if (isPorcelain()) printRecord('metrics', [metrics.url]);
+          else print(getTheme().dim(`Serving metrics on ${metrics.url}`));
+        }
+
+        try {
+          for (const root of roots) watcher.watch(root.repoPath);
+        } catch (error) {
+          return reportError(ExitCode.Failure, {
+            code: 'WATCH_FAILED',
+            message: `Cannot watch: ${error instanceof Error ? error.message : String(error)}`,
+            hint: 'Run cindex doctor to check the watch backend',
+          });
+        }
+
+        // Catch up with changes made while nothing was watching (watching started first, so none are missed)
+        for (const root of roots) {
+          if (controller.signal.aborted) break;
+          await reindex(root, []);
+        }
+
+        if (!isPorcelain() && !controller.signal.aborted) {
+          const names = roots.map((root) => root.repoId).join(', ');
+          print(getTheme().dim(`Watching ${names} (Ctrl+C to stop)`));
+        }
+        await stopped;
+        return ExitCode.Success;
+      } finally {
+        await watcher.close();
+        metricsController.abort();
+        await metrics?.closed;
+        removeSignalHandlers();
+      }
+    } finally {
+      await db.close();
+    }
+  },
+};
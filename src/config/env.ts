@@ -1,11 +1,42 @@
 /**
  * Environment configuration loader and validator
  * Loads configuration from environment variables with validation and defaults
+ *
+ * Every variable can also be set with a CINDEX_ prefix (e.g. CINDEX_POSTGRES_HOST),
+ * which takes precedence over the unprefixed name. This avoids collisions with
+ * other tools in containers and CI where POSTGRES_* or OLLAMA_* are already used.
  */
 
 import { ConfigurationError } from '@utils/errors';
 import { logger } from '@utils/logger';
-import { DEFAULT_CONFIG, ENV_VARS, type CindexConfig } from '@/types/config';
+import { type LogLevel } from '@utils/logger';
+import { DEFAULT_CONFIG, ENV_PREFIX, ENV_VARS, type CindexConfig } from '@/types/config';
+import { GENERATED_FILE_POLICIES, SYMLINK_POLICIES } from '@/types/indexing';
+import { STORE_BACKENDS } from '@/types/store';
+
+/**
+ * Accepted LOG_LEVEL values
+ */
+const LOG_LEVELS: readonly LogLevel[] = ['DEBUG', 'INFO', 'WARN', 'ERROR'];
+
+/**
+ * Resolve the environment variable name actually in effect for a key
+ * Prefers CINDEX_<key> when set, otherwise the unprefixed key
+ * @param key - Environment variable name (without prefix)
+ * @returns Variable name to read (used in error messages too)
+ */
+const envName = (key: string): string => {
+  return process.env[ENV_PREFIX + key] !== undefined ? ENV_PREFIX + key : key;
+};
+
+/**
+ * Check whether a configuration variable is explicitly set (with or without prefix)
+ * @param key - Environment variable name (without prefix)
+ * @returns True if CINDEX_<key> or <key> is set to a non-empty value
+ */
+export const isEnvSet = (key: string): boolean => {
+  return Boolean(process.env[envName(key)]);
+};
 
 /**
  * Get environment variable with optional default fallback
@@ -14,7 +45,7 @@ import { DEFAULT_CONFIG, ENV_VARS, type CindexConfig } from '@/types/config';
  * @returns Environment variable value or default, undefined if neither exists
  */
 const getEnv = (key: string, defaultValue?: string): string | undefined => {
-  return process.env[key] ?? defaultValue;
+  return process.env[envName(key)] ?? defaultValue;
 };
 
 /**
@@ -24,7 +55,7 @@ const getEnv = (key: string, defaultValue?: string): string | undefined => {
  * @throws {ConfigurationError} If environment variable is not set
  */
 const getEnvRequired = (key: string): string => {
-  const value = process.env[key];
+  const value = process.env[envName(key)];
   if (!value) {
     throw ConfigurationError.missingRequired(key);
   }
@@ -48,17 +79,17 @@ const parseEnvInt = (key: string, defaultValue: number, min?: number, max?: numb
 
   const parsed = parseInt(value, 10);
   if (isNaN(parsed)) {
-    throw ConfigurationError.invalidValue(key, value, 'valid integer');
+    throw ConfigurationError.invalidValue(envName(key), value, 'valid integer');
   }
 
   // Validate minimum bound
   if (min !== undefined && parsed < min) {
-    throw ConfigurationError.invalidValue(key, String(parsed), `>= ${String(min)}`);
+    throw ConfigurationError.invalidValue(envName(key), String(parsed), `>= ${String(min)}`);
   }
 
   // Validate maximum bound
   if (max !== undefined && parsed > max) {
-    throw ConfigurationError.invalidValue(key, String(parsed), `<= ${String(max)}`);
+    throw ConfigurationError.invalidValue(envName(key), String(parsed), `<= ${String(max)}`);
   }
 
   return parsed;
@@ -81,17 +112,17 @@ const parseEnvFloat = (key: string, defaultValue: number, min?: number, max?: nu
 
   const parsed = parseFloat(value);
   if (isNaN(parsed)) {
-    throw ConfigurationError.invalidValue(key, value, 'valid number');
+    throw ConfigurationError.invalidValue(envName(key), value, 'valid number');
   }
 
   // Validate minimum bound
   if (min !== undefined && parsed < min) {
-    throw ConfigurationError.invalidValue(key, String(parsed), `>= ${String(min)}`);
+    throw ConfigurationError.invalidValue(envName(key), String(parsed), `>= ${String(min)}`);
   }
 
   // Validate maximum bound
   if (max !== undefined && parsed > max) {
-    throw ConfigurationError.invalidValue(key, String(parsed), `<= ${String(max)}`);
+    throw ConfigurationError.invalidValue(envName(key), String(parsed), `<= ${String(max)}`);
   }
 
   return parsed;
@@ -121,7 +152,28 @@ const parseEnvBool = (key: string, defaultValue: boolean): boolean => {
     return false;
   }
 
-  throw ConfigurationError.invalidValue(key, value, 'true/false, 1/0, or yes/no');
+  throw ConfigurationError.invalidValue(envName(key), value, 'true/false, 1/0, or yes/no');
+};
+
+/**
+ * Parse enumerated string from environment variable
+ * @param key - Environment variable name
+ * @param defaultValue - Default value if environment variable is not set
+ * @param allowed - Allowed values (compared case-insensitively)
+ * @returns Matching allowed value
+ * @throws {ConfigurationError} If value is not one of the allowed values
+ */
+const parseEnvEnum = <T extends string>(key: string, defaultValue: T, allowed: readonly T[]): T => {
+  const value = getEnv(key);
+  if (!value) {
+    return defaultValue;
+  }
+
+  const match = allowed.find((option) => option.toLowerCase() === value.toLowerCase());
+  if (!match) {
+    throw ConfigurationError.invalidValue(envName(key), value, allowed.join(', '));
+  }
+  return match;
 };
 
 /**
@@ -132,6 +184,11 @@ const parseEnvBool = (key: string, defaultValue: boolean): boolean => {
  */
 export const loadConfig = (): CindexConfig => {
   // Load embedding configuration
+  const embeddingProvider = parseEnvEnum(ENV_VARS.EMBEDDING_PROVIDER, DEFAULT_CONFIG.embedding.provider, [
+    'ollama',
+    'openai',
+    'onnx',
+  ]);
   const embeddingModel =
     getEnv(ENV_VARS.EMBEDDING_MODEL, DEFAULT_CONFIG.embedding.model) ?? DEFAULT_CONFIG.embedding.model;
   const embeddingDimensions = parseEnvInt(ENV_VARS.EMBEDDING_DIMENSIONS, DEFAULT_CONFIG.embedding.dimensions, 1, 4096);
@@ -143,8 +200,14 @@ export const loadConfig = (): CindexConfig => {
     131072
   );
 
+  const embeddingBatchSize = parseEnvInt(ENV_VARS.EMBEDDING_BATCH_SIZE, DEFAULT_CONFIG.embedding.batch_size, 1, 1000);
+  const embeddingApiBase = getEnv(ENV_VARS.EMBEDDING_API_BASE, DEFAULT_CONFIG.embedding.api_base);
+  const embeddingApiKey = getEnv(ENV_VARS.EMBEDDING_API_KEY);
+
   // Load summary configuration
   const summaryModel = getEnv(ENV_VARS.SUMMARY_MODEL, DEFAULT_CONFIG.summary.model) ?? DEFAULT_CONFIG.summary.model;
+  const summaryMethod = parseEnvEnum(ENV_VARS.SUMMARY_METHOD, DEFAULT_CONFIG.summary.method, ['llm', 'rule-based']);
+  const summaryMaxLines = parseEnvInt(ENV_VARS.SUMMARY_MAX_LINES, DEFAULT_CONFIG.summary.max_lines, 10, 1000);
   // Context window range: 512-131072 tokens (qwen2.5-coder:7b supports up to 32K)
   const summaryContextWindow = parseEnvInt(
     ENV_VARS.SUMMARY_CONTEXT_WINDOW,
@@ -157,6 +220,7 @@ export const loadConfig = (): CindexConfig => {
   const ollamaHost = getEnv(ENV_VARS.OLLAMA_HOST, DEFAULT_CONFIG.ollama.host) ?? DEFAULT_CONFIG.ollama.host;
   // Timeout range: 1-300 seconds
   const ollamaTimeout = parseEnvInt(ENV_VARS.OLLAMA_TIMEOUT, DEFAULT_CONFIG.ollama.timeout, 1000, 300000);
+  const ollamaRetryAttempts = parseEnvInt(ENV_VARS.OLLAMA_RETRY_ATTEMPTS, DEFAULT_CONFIG.ollama.retry_attempts, 0, 10);
 
   // Load database configuration (password is required for security)
   const postgresHost = getEnv(ENV_VARS.POSTGRES_HOST, DEFAULT_CONFIG.database.host) ?? DEFAULT_CONFIG.database.host;
@@ -171,6 +235,11 @@ export const loadConfig = (): CindexConfig => {
     1,
     100
   );
+  const idleTimeout = parseEnvInt(ENV_VARS.POSTGRES_IDLE_TIMEOUT, DEFAULT_CONFIG.database.idle_timeout, 1000, 600000);
+
+  // Load store configuration
+  const storeBackend = parseEnvEnum(ENV_VARS.STORE_BACKEND, DEFAULT_CONFIG.store.backend, STORE_BACKENDS);
+  const sqlitePath = getEnv(ENV_VARS.SQLITE_PATH, DEFAULT_CONFIG.store.sqlite_path);
 
   // Load performance configuration
   // HNSW parameters: higher values = more accurate but slower
@@ -212,14 +281,68 @@ export const loadConfig = (): CindexConfig => {
   const importDepth = parseEnvInt(ENV_VARS.IMPORT_DEPTH, DEFAULT_CONFIG.performance.import_depth, 1, 10);
   const workspaceDepth = parseEnvInt(ENV_VARS.WORKSPACE_DEPTH, DEFAULT_CONFIG.performance.workspace_depth, 1, 10);
   const serviceDepth = parseEnvInt(ENV_VARS.SERVICE_DEPTH, DEFAULT_CONFIG.performance.service_depth, 1, 10);
+  // Context size limits (tokens)
+  const maxContextTokens = parseEnvInt(
+    ENV_VARS.MAX_CONTEXT_TOKENS,
+    DEFAULT_CONFIG.performance.max_context_tokens,
+    1000,
+    10000000
+  );
+  const warnContextTokens = parseEnvInt(
+    ENV_VARS.WARN_CONTEXT_TOKENS,
+    DEFAULT_CONFIG.performance.warn_context_tokens,
+    1000,
+    10000000
+  );
+  const indexingBatchSize = parseEnvInt(
+    ENV_VARS.INDEXING_BATCH_SIZE,
+    DEFAULT_CONFIG.performance.indexing_batch_size,
+    1,
+    10000
+  );
 
   // Load indexing configuration
   // Max file size in kilobytes (100KB - 100MB range)
   const maxFileSize = parseEnvInt(ENV_VARS.MAX_FILE_SIZE, DEFAULT_CONFIG.indexing.max_file_size, 100, 100000);
+  // Traversal guards against pathological trees (deeply nested or very long generated paths)
+  const maxDirectoryDepth = parseEnvInt(
+    ENV_VARS.MAX_DIRECTORY_DEPTH,
+    DEFAULT_CONFIG.indexing.max_directory_depth,
+    1,
+    1000
+  );
+  const maxPathLength = parseEnvInt(ENV_VARS.MAX_PATH_LENGTH, DEFAULT_CONFIG.indexing.max_path_length, 64, 32767);
   // Secret file protection enabled by default for security
   const protectSecrets = parseEnvBool(ENV_VARS.PROTECT_SECRETS, DEFAULT_CONFIG.indexing.protect_secrets);
   // Parse comma-separated secret patterns (e.g., "*.key,credentials.json")
   const secretPatterns = getEnv(ENV_VARS.SECRET_PATTERNS)?.split(',').map((p) => p.trim()).filter(Boolean) ?? DEFAULT_CONFIG.indexing.secret_patterns;
+  // Credential scanning of indexed content is opt-in
+  const scanSecrets = parseEnvBool(ENV_VARS.SCAN_SECRETS, DEFAULT_CONFIG.indexing.scan_secrets);
+  const respectGitignore = parseEnvBool(ENV_VARS.RESPECT_GITIGNORE, DEFAULT_CONFIG.indexing.respect_gitignore);
+  const symlinkPolicy = parseEnvEnum(ENV_VARS.SYMLINK_POLICY, DEFAULT_CONFIG.indexing.symlink_policy, SYMLINK_POLICIES);
+  const generatedFiles = parseEnvEnum(
+    ENV_VARS.GENERATED_FILES,
+    DEFAULT_CONFIG.indexing.generated_files,
+    GENERATED_FILE_POLICIES
+  );
+  // Parse comma-separated directory overrides (e.g., "fixtures,!vendor")
+  const excludeDirectories =
+    getEnv(ENV_VARS.EXCLUDE_DIRECTORIES)
+      ?.split(',')
+      .map((d) => d.trim())
+      .filter(Boolean) ?? DEFAULT_CONFIG.indexing.exclude_directories;
+  // Parse comma-separated language list (e.g., "go,typescript"), empty = all
+  const languages =
+    getEnv(ENV_VARS.LANGUAGES)
+      ?.split(',')
+      .map((l) => l.trim().toLowerCase())
+      .filter(Boolean) ?? DEFAULT_CONFIG.indexing.languages;
+  // Parse comma-separated plugins (e.g., "./tools/abap.mjs,exec:cindex-abap --stdio")
+  const plugins =
+    getEnv(ENV_VARS.PLUGINS)
+      ?.split(',')
+      .map((p) => p.trim())
+      .filter(Boolean) ?? DEFAULT_CONFIG.indexing.plugins;
 
   // Load feature flags
   const enableWorkspaceDetection = parseEnvBool(
@@ -236,25 +359,41 @@ export const loadConfig = (): CindexConfig => {
     DEFAULT_CONFIG.features.enable_api_endpoint_detection
   );
   const enableHybridSearch = parseEnvBool(ENV_VARS.ENABLE_HYBRID_SEARCH, DEFAULT_CONFIG.features.enable_hybrid_search);
+  const enableDeduplication = parseEnvBool(
+    ENV_VARS.ENABLE_DEDUPLICATION,
+    DEFAULT_CONFIG.features.enable_deduplication
+  );
+  const enableIncrementalIndexing = parseEnvBool(
+    ENV_VARS.ENABLE_INCREMENTAL_INDEXING,
+    DEFAULT_CONFIG.features.enable_incremental_indexing
+  );
+  const enableLlmSummaries = parseEnvBool(ENV_VARS.ENABLE_LLM_SUMMARIES, DEFAULT_CONFIG.features.enable_llm_summaries);
+  const enableUsageStats = parseEnvBool(ENV_VARS.ENABLE_USAGE_STATS, DEFAULT_CONFIG.features.enable_usage_stats);
+
+  // Load logging configuration
+  const logLevel = parseEnvEnum(ENV_VARS.LOG_LEVEL, DEFAULT_CONFIG.logging.level, LOG_LEVELS);
 
   // Build final configuration object from all parsed values
   const config: CindexConfig = {
     embedding: {
+      provider: embeddingProvider,
       model: embeddingModel,
       dimensions: embeddingDimensions,
-      batch_size: DEFAULT_CONFIG.embedding.batch_size,
+      batch_size: embeddingBatchSize,
       context_window: embeddingContextWindow,
+      api_base: embeddingApiBase,
+      api_key: embeddingApiKey,
     },
     summary: {
       model: summaryModel,
-      method: DEFAULT_CONFIG.summary.method,
-      max_lines: DEFAULT_CONFIG.summary.max_lines,
+      method: summaryMethod,
+      max_lines: summaryMaxLines,
       context_window: summaryContextWindow,
     },
     ollama: {
       host: ollamaHost,
       timeout: ollamaTimeout,
-      retry_attempts: DEFAULT_CONFIG.ollama.retry_attempts,
+      retry_attempts: ollamaRetryAttempts,
     },
     database: {
       host: postgresHost,
@@ -263,7 +402,11 @@ export const loadConfig = (): CindexConfig => {
       user: postgresUser,
       password: postgresPassword,
       max_connections: maxConnections,
-      idle_timeout: DEFAULT_CONFIG.database.idle_timeout,
+      idle_timeout: idleTimeout,
+    },
+    store: {
+      backend: storeBackend,
+      sqlite_path: sqlitePath,
     },
     performance: {
       hnsw_ef_search: hnswEfSearch,
@@ -274,9 +417,9 @@ export const loadConfig = (): CindexConfig => {
       import_depth: importDepth,
       workspace_depth: workspaceDepth,
       service_depth: serviceDepth,
-      max_context_tokens: DEFAULT_CONFIG.performance.max_context_tokens,
-      warn_context_tokens: DEFAULT_CONFIG.performance.warn_context_tokens,
-      indexing_batch_size: DEFAULT_CONFIG.performance.indexing_batch_size,
+      max_context_tokens: maxContextTokens,
+      warn_context_tokens: warnContextTokens,
+      indexing_batch_size: indexingBatchSize,
       embedding_batch_size: DEFAULT_CONFIG.performance.embedding_batch_size,
       hybrid_vector_weight: hybridVectorWeight,
       hybrid_keyword_weight: hybridKeywordWeight,
@@ -286,18 +429,26 @@ export const loadConfig = (): CindexConfig => {
       enable_service_detection: enableServiceDetection,
       enable_multi_repo: enableMultiRepo,
       enable_api_endpoint_detection: enableApiEndpointDetection,
-      enable_deduplication: DEFAULT_CONFIG.features.enable_deduplication,
-      enable_incremental_indexing: DEFAULT_CONFIG.features.enable_incremental_indexing,
-      enable_llm_summaries: DEFAULT_CONFIG.features.enable_llm_summaries,
+      enable_deduplication: enableDeduplication,
+      enable_incremental_indexing: enableIncrementalIndexing,
+      enable_llm_summaries: enableLlmSummaries,
       enable_tsconfig_paths: DEFAULT_CONFIG.features.enable_tsconfig_paths,
       enable_hybrid_search: enableHybridSearch,
+      enable_usage_stats: enableUsageStats,
     },
     indexing: {
-      respect_gitignore: DEFAULT_CONFIG.indexing.respect_gitignore,
+      respect_gitignore: respectGitignore,
+      symlink_policy: symlinkPolicy,
+      generated_files: generatedFiles,
+      exclude_directories: excludeDirectories,
       max_file_size: maxFileSize,
+      max_directory_depth: maxDirectoryDepth,
+      max_path_length: maxPathLength,
       protect_secrets: protectSecrets,
       secret_patterns: secretPatterns,
-      languages: DEFAULT_CONFIG.indexing.languages,
+      scan_secrets: scanSecrets,
+      languages,
+      plugins,
       detect_workspaces: DEFAULT_CONFIG.indexing.detect_workspaces,
       resolve_workspace_aliases: DEFAULT_CONFIG.indexing.resolve_workspace_aliases,
       parse_tsconfig_paths: DEFAULT_CONFIG.indexing.parse_tsconfig_paths,
@@ -305,6 +456,9 @@ export const loadConfig = (): CindexConfig => {
       detect_api_endpoints: DEFAULT_CONFIG.indexing.detect_api_endpoints,
       detect_from_docker_compose: DEFAULT_CONFIG.indexing.detect_from_docker_compose,
     },
+    logging: {
+      level: logLevel,
+    },
   };
 
   return config;
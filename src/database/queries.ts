@@ -7,8 +7,56 @@
 import { type Pool } from 'pg';
 
 import { DatabaseQueryError } from '@utils/errors';
-import { type CodeChunk, type CodeFile, getImportPaths, type Service, type Workspace } from '@/types/database';
+import { compareStrings } from '@utils/ordering';
+import {
+  type AnnotationKind,
+  type AnnotationRecord,
+  type CallNeighborRecord,
+  type CloneCandidateRecord,
+  type CodeChunk,
+  type CodeFile,
+  type DocMatchRecord,
+  type ExportedSymbolRecord,
+  type FileContentRecord,
+  type FileImportsRecord,
+  type FileLicenseRecord,
+  type FileStatsRecord,
+  type FunctionSpanRecord,
+  getImportPaths,
+  type GoCallRecord,
+  type GoConstantRecord,
+  type GoEmbeddingRecord,
+  type GoImplementationRecord,
+  type GoInstantiationRecord,
+  type GoModuleRecord,
+  type GoReferenceRecord,
+  type GoSymbolUsageRecord,
+  type GoTypeParameterRecord,
+  type IndexComposition,
+  type IndexedFileRecord,
+  type IndexedFileVersionRecord,
+  type LintFindingRecord,
+  type LiteralKind,
+  type LiteralRecord,
+  type ParseErrorRecord,
+  type PluginMetricRecord,
+  type QueryPlan,
+  type RelatedCandidateRecord,
+  type ScipImplementationRecord,
+  type ScipReferenceRecord,
+  type ScipSymbolRecord,
+  type SecretFindingRecord,
+  type SourceFileRecord,
+  type Service,
+  type SymbolEndLineRecord,
+  type SymbolFingerprintRecord,
+  type SymbolHistoryRecord,
+  type SymbolVariantRecord,
+  type ValueFrequency,
+  type Workspace,
+} from '@/types/database';
 import { type APIEndpointMatch, type ResolvedSymbol } from '@/types/retrieval';
+import { type StoredSymbol } from '@/types/store';
 
 // Re-export database types for MCP tool usage
 export type { Workspace, Service };
@@ -68,7 +116,7 @@ export const getFileContext = async (
     // If not found and looks like a relative path, try suffix match
     if (fileResult.rows.length === 0 && !filePath.startsWith('/')) {
       fileResult = await db.query<CodeFile>(
-        `SELECT * FROM code_files WHERE file_path LIKE $1 ORDER BY LENGTH(file_path) ASC LIMIT 1`,
+        `SELECT * FROM code_files WHERE file_path LIKE $1 ORDER BY LENGTH(file_path) ASC, file_path LIMIT 1`,
         [`%/${filePath}`]
       );
     }
@@ -131,81 +179,2520 @@ export const getFileContext = async (
   }
 };
 
+/**
+ * Comparison on a symbol metric (coverage:<50, complexity:>10, cognitive:>=15)
+ */
+export interface MetricCondition {
+  column: 'coverage' | 'complexity' | 'cognitive_complexity';
+  operator: '<' | '<=' | '>' | '>=' | '=';
+  value: number;
+}
+
+/**
+ * Interface a Go type must satisfy (implements:io.Reader); lowercase
+ */
+export interface InterfaceCondition {
+  name: string;
+  /** Import path, or its last elements (null: any package) */
+  package: string | null;
+}
+
+/**
+ * SQL condition matching a Go name and package column against an InterfaceCondition
+ *
+ * @param name - Name column
+ * @param pkg - Import path column
+ * @param nameParam - Placeholder of the lowercase name
+ * @param pkgParam - Placeholder of the lowercase package (text, NULL: any package)
+ */
+const goNameMatches = (name: string, pkg: string, nameParam: string, pkgParam: string): string =>
+  `lower(${name}) = ${nameParam}
+          AND (${pkgParam} IS NULL OR lower(${pkg}) = ${pkgParam}
+               OR right(lower(${pkg}), length(${pkgParam}) + 1) = '/' || ${pkgParam})`;
+
+/**
+ * Go module of a code_symbols row: its module within the index, or the module a cindex deps index holds
+ */
+const SYMBOL_MODULE = `COALESCE(code_symbols.package_name,
+        (SELECT r.metadata->>'go_module' FROM repositories r WHERE r.repo_id = code_symbols.repo_id))`;
+
+/**
+ * Options of a symbol search
+ */
+export interface SymbolSearchOptions {
+  scope?: 'all' | 'exported' | 'internal';
+  workspaceId?: string;
+  serviceId?: string;
+  repoId?: string;
+  /** Include the Go module indexes linked by cindex deps (otherwise only a selected module index is searched) */
+  dependencies?: boolean;
+  /** Metric comparisons applied before the limit (symbols without the metric never match) */
+  metrics?: MetricCondition[];
+  /** Symbol types every result must have (each entry is ANDed, like repeated kind: filters) */
+  symbolTypes?: string[];
+  /** Interfaces every result must satisfy (Go types, from typed indexing) */
+  implements?: InterfaceCondition[];
+  /** Languages every result's file must have (each entry is ANDed, like repeated lang: filters) */
+  languages?: string[];
+  /** Only generic Go functions and types (true), or only the others (false) */
+  generic?: boolean;
+  /** Go modules every result must be in, by module path or its trailing elements (lowercase) */
+  modules?: string[];
+  /** Words every result's name must have: each entry lists the forms that match (a word and its stem) */
+  words?: string[][];
+  /** Structured IDs every result must have (each entry is ANDed, like repeated id: filters) */
+  ids?: string[];
+  /** Query embedding: results are ranked by cosine similarity to it (symbols without an embedding never match) */
+  embedding?: number[];
+  /** Continue after this position (from a SymbolPage's next_cursor); name order only */
+  cursor?: string;
+  limit?: number;
+}
+
+/**
+ * Position in the name order of a symbol search: scope rank, name, and id of the last symbol returned
+ */
+type SymbolCursorKey = [rank: number, name: string, id: number];
+
+/**
+ * One page of a symbol search (see searchSymbolsPage)
+ */
+export interface SymbolPage {
+  symbols: ResolvedSymbol[];
+  /** Cursor of the next page, or null after the last page */
+  next_cursor: string | null;
+}
+
+/**
+ * Encode the position after a symbol as an opaque cursor
+ *
+ * @param symbol - Last symbol of a page (must carry its id)
+ * @returns Base64url cursor
+ */
+export const encodeSymbolCursor = (symbol: Pick<ResolvedSymbol, 'scope' | 'symbol_name' | 'id'>): string => {
+  const key: SymbolCursorKey = [symbol.scope === 'exported' ? 0 : 1, symbol.symbol_name, symbol.id ?? 0];
+  return Buffer.from(JSON.stringify(key)).toString('base64url');
+};
+
+/**
+ * Decode a cursor made by encodeSymbolCursor
+ *
+ * @param cursor - Cursor
+ * @returns Position, or null if the cursor is malformed
+ */
+export const decodeSymbolCursor = (cursor: string): SymbolCursorKey | null => {
+  try {
+    const key = JSON.parse(Buffer.from(cursor, 'base64url').toString('utf-8')) as unknown;
+    if (!Array.isArray(key) || key.length !== 3) return null;
+    const [rank, name, id] = key as unknown[];
+    if ((rank !== 0 && rank !== 1) || typeof name !== 'string' || !Number.isInteger(id)) return null;
+    return [rank, name, id as number];
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * Build the SQL of a symbol search (shared by searchSymbols and cindex explain)
+ * @param symbolName - Symbol name (supports partial ILIKE match with %)
+ * @param options - Search options including scope, workspace, service, and repo filters
+ * @returns Parameterized SQL and its parameters
+ */
+export const buildSymbolSearchQuery = (
+  symbolName: string,
+  options: SymbolSearchOptions = {}
+): { sql: string; params: unknown[] } => {
+  const conditions: string[] = ['symbol_name ILIKE $1'];
+  const params: unknown[] = [`%${symbolName}%`];
+  let paramIndex = 2;
+
+  // Scope filter (exported symbols are public API, internal are private)
+  if (options.scope === 'exported') {
+    conditions.push(`scope = 'exported'`);
+  } else if (options.scope === 'internal') {
+    conditions.push(`scope = 'internal'`);
+  }
+
+  // Multi-project filters
+  if (options.workspaceId) {
+    conditions.push(`workspace_id = $${String(paramIndex++)}`);
+    params.push(options.workspaceId);
+  }
+
+  if (options.serviceId) {
+    conditions.push(`service_id = $${String(paramIndex++)}`);
+    params.push(options.serviceId);
+  }
+
+  // Go module indexes (cindex deps) are searched on request: with the index linking them, or all of them
+  if (options.repoId && options.dependencies) {
+    const repo = `$${String(paramIndex++)}`;
+    conditions.push(
+      `(repo_id = ${repo} OR repo_id IN (SELECT d.target_repo_id FROM cross_repo_dependencies d
+        WHERE d.source_repo_id = ${repo} AND d.metadata->>'go_module' IS NOT NULL))`
+    );
+    params.push(options.repoId);
+  } else if (options.repoId) {
+    conditions.push(`repo_id = $${String(paramIndex++)}`);
+    params.push(options.repoId);
+  } else if (!options.dependencies) {
+    conditions.push(
+      `NOT EXISTS (SELECT 1 FROM repositories r
+        WHERE r.repo_id = code_symbols.repo_id AND (r.metadata->>'go_module' IS NOT NULL OR r.metadata ? 'revision'))`
+    );
+  } else {
+    // Revision indexes (cindex index --rev) are searched by naming them
+    conditions.push(
+      `NOT EXISTS (SELECT 1 FROM repositories r WHERE r.repo_id = code_symbols.repo_id AND r.metadata ? 'revision')`
+    );
+  }
+
+  // Column and operator come from closed sets, only the value is a parameter
+  for (const metric of options.metrics ?? []) {
+    conditions.push(`${metric.column} ${metric.operator} $${String(paramIndex++)}`);
+    params.push(metric.value);
+  }
+
+  for (const symbolType of options.symbolTypes ?? []) {
+    conditions.push(`symbol_type = $${String(paramIndex++)}`);
+    params.push(symbolType);
+  }
+
+  for (const iface of options.implements ?? []) {
+    const name = `$${String(paramIndex++)}`;
+    const pkg = `$${String(paramIndex++)}::text`;
+    conditions.push(
+      `EXISTS (SELECT 1 FROM go_implementations g
+        WHERE g.file_path = code_symbols.file_path AND g.type_name = code_symbols.symbol_name
+          AND ${goNameMatches('g.interface_name', 'g.interface_package', name, pkg)})`
+    );
+    params.push(iface.name, iface.package);
+  }
+
+  for (const language of options.languages ?? []) {
+    conditions.push(
+      `EXISTS (SELECT 1 FROM code_files f
+        WHERE f.file_path = code_symbols.file_path AND f.language = $${String(paramIndex++)})`
+    );
+    params.push(language);
+  }
+
+  if (options.generic !== undefined) {
+    conditions.push(
+      `${options.generic ? '' : 'NOT '}EXISTS (SELECT 1 FROM go_type_parameters t
+        WHERE t.file_path = code_symbols.file_path AND t.symbol_name = code_symbols.symbol_name)`
+    );
+  }
+
+  for (const module of options.modules ?? []) {
+    const param = `$${String(paramIndex++)}`;
+    conditions.push(
+      `(lower(${SYMBOL_MODULE}) = ${param} OR right(lower(${SYMBOL_MODULE}), length(${param}) + 1) = '/' || ${param})`
+    );
+    params.push(module);
+  }
+
+  // Name tokens are indexed (GIN), so word: filters narrow before the limit
+  for (const forms of options.words ?? []) {
+    conditions.push(`name_tokens && $${String(paramIndex++)}::text[]`);
+    params.push(forms);
+  }
+
+  for (const id of options.ids ?? []) {
+    conditions.push(`symbol_id = $${String(paramIndex++)}`);
+    params.push(id);
+  }
+
+  // Keyset pagination: rows after the cursor in (scope rank, name, id) order
+  if (options.cursor !== undefined) {
+    const key = decodeSymbolCursor(options.cursor);
+    if (!key) throw new Error(`Invalid symbol search cursor: ${options.cursor}`);
+    if (options.embedding) throw new Error('Symbol search cursors only apply to name order');
+    const rank = `$${String(paramIndex++)}::int`;
+    const name = `$${String(paramIndex++)}::text`;
+    const id = `$${String(paramIndex++)}::int`;
+    conditions.push(`(CASE WHEN scope = 'exported' THEN 0 ELSE 1 END, symbol_name, id) > (${rank}, ${name}, ${id})`);
+    params.push(...key);
+  }
+
+  // Semantic search ranks by similarity instead of scope and name
+  let similarity = '';
+  let order = `CASE WHEN scope = 'exported' THEN 0 ELSE 1 END,
+      symbol_name,
+      id`;
+  if (options.embedding) {
+    const vector = `$${String(paramIndex++)}::vector`;
+    conditions.push('embedding IS NOT NULL');
+    params.push(`[${options.embedding.join(',')}]`);
+    similarity = `,
+      1 - (embedding <=> ${vector}) AS similarity`;
+    order = `embedding <=> ${vector}`;
+  }
+
+  const limit = options.limit ?? 50;
+
+  const sql = `
+    SELECT
+      id,
+      symbol_name,
+      symbol_type,
+      repo_id,
+      file_path,
+      line_number,
+      definition,
+      scope,
+      workspace_id,
+      ${SYMBOL_MODULE} AS package_name,
+      service_id,
+      complexity,
+      cognitive_complexity,
+      coverage,
+      (SELECT COUNT(*)::int FROM lint_findings l
+       WHERE l.file_path = code_symbols.file_path
+         AND l.line_number BETWEEN code_symbols.line_number
+                               AND COALESCE(code_symbols.end_line, code_symbols.line_number)) AS lint_count,
+      ARRAY(SELECT CASE WHEN g.interface_package = '' THEN g.interface_name
+                        ELSE g.interface_package || '.' || g.interface_name END
+            FROM go_implementations g
+            WHERE g.file_path = code_symbols.file_path AND g.type_name = code_symbols.symbol_name
+            ORDER BY 1) AS implements,
+      ARRAY(SELECT t.parameter_name || ' ' || t.constraint_text
+            FROM go_type_parameters t
+            WHERE t.file_path = code_symbols.file_path AND t.symbol_name = code_symbols.symbol_name
+            ORDER BY t.position) AS type_parameters,
+      symbol_id,
+      (SELECT license FROM code_files WHERE code_files.file_path = code_symbols.file_path) AS license,
+      (SELECT language FROM code_files WHERE code_files.file_path = code_symbols.file_path) AS language${similarity}
+    FROM code_symbols
+    WHERE ${conditions.join(' AND ')}
+    ORDER BY
+      ${order}
+    LIMIT $${String(paramIndex)}
+  `;
+
+  params.push(limit);
+
+  return { sql, params };
+};
+
 /**
  * Search symbols by name with optional scope and project filters
  * @param db - Database connection pool
- * @param symbolName - Symbol name (supports partial ILIKE match with %)
- * @param options - Search options including scope, workspace, service, and repo filters
- * @returns Array of resolved symbols sorted by scope (exported first) and name
+ * @param symbolName - Symbol name (supports partial ILIKE match with %)
+ * @param options - Search options including scope, workspace, service, and repo filters
+ * @returns Array of resolved symbols sorted by scope (exported first) and name, or by similarity to options.embedding
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const searchSymbols = async (
+  db: Pool,
+  symbolName: string,
+  options: SymbolSearchOptions = {}
+): Promise<ResolvedSymbol[]> => {
+  try {
+    const { sql, params } = buildSymbolSearchQuery(symbolName, options);
+    const result = await db.query<ResolvedSymbol>(sql, params);
+
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('searchSymbols', [symbolName], err);
+  }
+};
+
+/**
+ * Fetch one page of a symbol search, in name order
+ *
+ * Pages are keyed on the last symbol returned, so symbols indexed or removed
+ * between pages neither shift nor repeat the rest of the results.
+ *
+ * @param db - Database connection pool
+ * @param symbolName - Symbol name (supports partial ILIKE match with %)
+ * @param options - Search options; limit is the page size (default 50), cursor the page to fetch (default: first)
+ * @returns Symbols of the page and the cursor of the next one
+ * @throws {DatabaseQueryError} If the cursor is malformed or query execution fails
+ */
+export const searchSymbolsPage = async (
+  db: Pool,
+  symbolName: string,
+  options: Omit<SymbolSearchOptions, 'embedding'> = {}
+): Promise<SymbolPage> => {
+  const limit = options.limit ?? 50;
+  try {
+    // One row past the page tells whether another page follows
+    const { sql, params } = buildSymbolSearchQuery(symbolName, { ...options, limit: limit + 1 });
+    const result = await db.query<ResolvedSymbol>(sql, params);
+    const symbols = result.rows.slice(0, limit);
+    const more = result.rows.length > limit && symbols.length > 0;
+    return { symbols, next_cursor: more ? encodeSymbolCursor(symbols[symbols.length - 1]) : null };
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('searchSymbolsPage', [symbolName], err);
+  }
+};
+
+/**
+ * Run a query under EXPLAIN ANALYZE and return its plan (cindex explain)
+ *
+ * The query is executed, so only pass read-only statements.
+ *
+ * @param db - Database connection pool
+ * @param sql - Query to explain
+ * @param params - Query parameters
+ * @returns Executed plan with actual row counts, timings, and buffer usage
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const explainQuery = async (db: Pool, sql: string, params: unknown[]): Promise<QueryPlan> => {
+  try {
+    const result = await db.query<{ 'QUERY PLAN': QueryPlan[] }>(
+      `EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) ${sql}`,
+      params
+    );
+    const [plan] = result.rows[0]['QUERY PLAN'];
+    return plan;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('explainQuery', [sql], err);
+  }
+};
+
+/**
+ * List distinct symbol names starting with a prefix (for CLI tab completion)
+ * @param db - Database connection pool
+ * @param prefix - Case-sensitive name prefix
+ * @param limit - Maximum names to return (default: 20)
+ * @returns Sorted distinct symbol names
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolNames = async (db: Pool, prefix: string, limit = 20): Promise<string[]> => {
+  try {
+    const result = await db.query<{ symbol_name: string }>(
+      `SELECT DISTINCT symbol_name FROM code_symbols WHERE symbol_name LIKE $1 ORDER BY symbol_name LIMIT $2`,
+      [`${prefix.replace(/[\\%_]/g, '\\$&')}%`, limit]
+    );
+    return result.rows.map((row) => row.symbol_name);
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolNames', [prefix], err);
+  }
+};
+
+/**
+ * Find symbols with an exact name
+ * @param db - Database connection pool
+ * @param symbolName - Exact (case-sensitive) symbol name
+ * @param repoId - Repository ID (optional, searches all repositories if not specified)
+ * @returns Matching symbols, exported first, then by repository, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const findSymbolsByName = async (db: Pool, symbolName: string, repoId?: string): Promise<ResolvedSymbol[]> => {
+  try {
+    const params = repoId ? [symbolName, repoId] : [symbolName];
+    const result = await db.query<ResolvedSymbol>(
+      `SELECT symbol_name, symbol_type, file_path, line_number, definition, scope, repo_id, workspace_id, service_id
+       FROM code_symbols
+       WHERE symbol_name = $1${repoId ? ' AND repo_id = $2' : ''}
+       ORDER BY CASE WHEN scope = 'exported' THEN 0 ELSE 1 END, repo_id, file_path, line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('findSymbolsByName', [symbolName, repoId], err);
+  }
+};
+
+/**
+ * Find which of some names are declared as types (classes, interfaces, type declarations)
+ *
+ * @param db - Database connection pool
+ * @param names - Names to check
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Names declared as types, in the order given
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const findTypeSymbolNames = async (db: Pool, names: string[], repoId?: string): Promise<string[]> => {
+  if (names.length === 0) return [];
+  try {
+    const params = repoId ? [names, repoId] : [names];
+    const result = await db.query<{ symbol_name: string }>(
+      `SELECT DISTINCT symbol_name
+       FROM code_symbols
+       WHERE symbol_name = ANY($1) AND symbol_type IN ('class', 'interface', 'type')
+         ${repoId ? 'AND repo_id = $2' : ''}`,
+      params
+    );
+    const declared = new Set(result.rows.map((row) => row.symbol_name));
+    return names.filter((name) => declared.has(name));
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('findTypeSymbolNames', [names.length, repoId], err);
+  }
+};
+
+/**
+ * List the functions of an index that have a clone fingerprint (cindex dupes)
+ *
+ * @param db - Database connection pool
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.minTokens - Leave out functions with fewer normalized tokens
+ * @returns Functions ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listCloneCandidates = async (
+  db: Pool,
+  options: { repoId?: string; minTokens?: number } = {}
+): Promise<CloneCandidateRecord[]> => {
+  const { repoId, minTokens = 0 } = options;
+  try {
+    const params: unknown[] = [minTokens];
+    if (repoId) params.push(repoId);
+    const result = await db.query<CloneCandidateRecord>(
+      `SELECT repo_id, file_path, symbol_name, symbol_type, line_number, end_line, clone_tokens, clone_signature
+       FROM code_symbols
+       WHERE clone_signature IS NOT NULL AND clone_tokens >= $1${repoId ? ' AND repo_id = $2' : ''}
+       ORDER BY repo_id, file_path, line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listCloneCandidates', [repoId, minTokens], err);
+  }
+};
+
+/**
+ * Find the indexed file a path names
+ *
+ * The path may be as stored (relative to the repository root), absolute, or
+ * a trailing part of a stored path (auth/login.go); exact matches win, then
+ * the shortest path.
+ *
+ * @param db - Database connection pool
+ * @param filePath - Path to look up
+ * @param repoId - Repository ID (optional, matches any repository if not specified)
+ * @returns Index and stored path of the file, or null if no indexed file matches
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const resolveIndexedFile = async (
+  db: Pool,
+  filePath: string,
+  repoId?: string
+): Promise<{ repo_id: string | null; file_path: string } | null> => {
+  try {
+    const params = repoId ? [filePath, repoId] : [filePath];
+    const result = await db.query<{ repo_id: string | null; file_path: string }>(
+      `SELECT repo_id, file_path
+       FROM code_files
+       WHERE (file_path = $1 OR repo_path || '/' || file_path = $1 OR right(file_path, length($1) + 1) = '/' || $1)
+         ${repoId ? 'AND repo_id = $2' : ''}
+       ORDER BY (file_path = $1 OR repo_path || '/' || file_path = $1) DESC, length(file_path), file_path
+       LIMIT 1`,
+      params
+    );
+    return result.rows.length > 0 ? result.rows[0] : null;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('resolveIndexedFile', [filePath, repoId], err);
+  }
+};
+
+/**
+ * List the symbols declared in one file (get_file_outline)
+ * @param db - Database connection pool
+ * @param filePath - File path as stored in the index
+ * @param repoId - Repository ID (optional, matches any repository if not specified)
+ * @returns Symbols in declaration order
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileSymbols = async (db: Pool, filePath: string, repoId?: string): Promise<ResolvedSymbol[]> => {
+  try {
+    const params = repoId ? [filePath, repoId] : [filePath];
+    const result = await db.query<ResolvedSymbol>(
+      `SELECT symbol_name, symbol_type, file_path, line_number, definition, scope, repo_id, workspace_id, service_id
+       FROM code_symbols
+       WHERE file_path = $1${repoId ? ' AND repo_id = $2' : ''}
+       ORDER BY line_number, symbol_name`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileSymbols', [filePath, repoId], err);
+  }
+};
+
+/**
+ * Get the indexed content of one file and its language (get_file_outline)
+ * @param db - Database connection pool
+ * @param filePath - File path as stored in the index
+ * @param repoId - Repository ID (optional, matches any repository if not specified)
+ * @returns Content as indexed and language, or null if no content is stored
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const getIndexedFileContent = async (
+  db: Pool,
+  filePath: string,
+  repoId?: string
+): Promise<{ content: string; language: string } | null> => {
+  try {
+    const params = repoId ? [filePath, repoId] : [filePath];
+    const result = await db.query<{ content: string; language: string }>(
+      `SELECT c.content, f.language
+       FROM code_contents c
+       JOIN code_files f ON f.file_path = c.file_path AND f.repo_id IS NOT DISTINCT FROM c.repo_id
+       WHERE c.file_path = $1${repoId ? ' AND c.repo_id = $2' : ''}
+       LIMIT 1`,
+      params
+    );
+    return result.rows.length > 0 ? result.rows[0] : null;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('getIndexedFileContent', [filePath, repoId], err);
+  }
+};
+
+/**
+ * List the indexed content of files, each once (source snippets)
+ *
+ * @param db - Database connection pool
+ * @param files - Files by index and path as stored
+ * @returns Contents of the files indexed with content, ordered by index and path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileContents = async (
+  db: Pool,
+  files: { repo_id: string | null; file_path: string }[]
+): Promise<FileContentRecord[]> => {
+  try {
+    const result = await db.query<FileContentRecord>(
+      `SELECT c.repo_id, c.file_path, c.content
+       FROM code_contents c
+       WHERE EXISTS (SELECT 1 FROM unnest($1::text[], $2::text[]) AS w(repo_id, file_path)
+                     WHERE w.file_path = c.file_path AND w.repo_id IS NOT DISTINCT FROM c.repo_id)
+       ORDER BY c.repo_id, c.file_path`,
+      [files.map((file) => file.repo_id), files.map((file) => file.file_path)]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileContents', [files.length], err);
+  }
+};
+
+/**
+ * List the last lines of symbols declared at locations (source snippets)
+ *
+ * @param db - Database connection pool
+ * @param locations - Declarations by index, path, and line
+ * @returns Symbols declared there, ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolEndLines = async (
+  db: Pool,
+  locations: { repo_id: string | null; file_path: string; line_number: number }[]
+): Promise<SymbolEndLineRecord[]> => {
+  try {
+    const result = await db.query<SymbolEndLineRecord>(
+      `SELECT s.repo_id, s.file_path, s.line_number, MAX(s.end_line) AS end_line
+       FROM code_symbols s
+       JOIN unnest($1::text[], $2::text[], $3::int[]) AS w(repo_id, file_path, line_number)
+         ON w.file_path = s.file_path AND w.line_number = s.line_number AND w.repo_id IS NOT DISTINCT FROM s.repo_id
+       GROUP BY s.repo_id, s.file_path, s.line_number
+       ORDER BY s.repo_id, s.file_path, s.line_number`,
+      [
+        locations.map((location) => location.repo_id),
+        locations.map((location) => location.file_path),
+        locations.map((location) => location.line_number),
+      ]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolEndLines', [locations.length], err);
+  }
+};
+
+/**
+ * List the stored chunks of one file (without embeddings)
+ * @param db - Database connection pool
+ * @param filePath - File path as stored in the index
+ * @param repoId - Repository ID (optional, matches any repository if not specified)
+ * @returns Chunks sorted by start line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileChunks = async (
+  db: Pool,
+  filePath: string,
+  repoId?: string
+): Promise<Omit<CodeChunk, 'embedding'>[]> => {
+  try {
+    const params = repoId ? [filePath, repoId] : [filePath];
+    const result = await db.query<Omit<CodeChunk, 'embedding'>>(
+      `SELECT id, repo_id, repo_path, file_path, chunk_type, chunk_content, start_line, end_line, language,
+              token_count, metadata, indexed_at
+       FROM code_chunks
+       WHERE file_path = $1${repoId ? ' AND repo_id = $2' : ''}
+       ORDER BY start_line, end_line DESC`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileChunks', [filePath, repoId], err);
+  }
+};
+
+/**
+ * Find class chunks that declare a method (methods are stored on their class chunk, not as symbols)
+ * @param db - Database connection pool
+ * @param className - Exact class name
+ * @param methodName - Exact method name
+ * @param repoId - Repository ID (optional, searches all repositories if not specified)
+ * @returns Class chunks sorted by repository and file path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const findClassChunksWithMethod = async (
+  db: Pool,
+  className: string,
+  methodName: string,
+  repoId?: string
+): Promise<Omit<CodeChunk, 'embedding'>[]> => {
+  try {
+    const params = repoId ? [className, methodName, repoId] : [className, methodName];
+    const result = await db.query<Omit<CodeChunk, 'embedding'>>(
+      `SELECT id, repo_id, repo_path, file_path, chunk_type, chunk_content, start_line, end_line, language,
+              token_count, metadata, indexed_at
+       FROM code_chunks
+       WHERE chunk_type = 'class'
+         AND metadata->>'class_name' = $1
+         AND metadata->'method_names' ? $2${repoId ? ' AND repo_id = $3' : ''}
+       ORDER BY repo_id, file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('findClassChunksWithMethod', [className, methodName, repoId], err);
+  }
+};
+
+/**
+ * List indexed files modified since a cutoff (uses the mtime recorded at index time)
+ * @param db - Database connection pool
+ * @param since - Cutoff date
+ * @param repoId - Repository ID (optional, searches all repositories if not specified)
+ * @returns File paths relative to their repository root
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFilesModifiedSince = async (db: Pool, since: Date, repoId?: string): Promise<string[]> => {
+  try {
+    const params: unknown[] = [since];
+    let sql = 'SELECT file_path FROM code_files WHERE last_modified >= $1';
+    if (repoId) {
+      params.push(repoId);
+      sql += ' AND repo_id = $2';
+    }
+
+    const result = await db.query<{ file_path: string }>(sql, params);
+    return result.rows.map((row) => row.file_path);
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFilesModifiedSince', [since.toISOString()], err);
+  }
+};
+
+/**
+ * List files whose last build hit syntax errors (partially parsed or fell back to regex parsing)
+ * @param db - Database connection pool
+ * @param repoId - Repository ID (optional, lists all repositories if not specified)
+ * @returns Parse error records sorted by repository and file path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listParseErrors = async (db: Pool, repoId?: string): Promise<ParseErrorRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<ParseErrorRecord>(
+      `SELECT repo_id, file_path, language, parse_error, parse_error_line, parse_error_column, parse_error_byte_column,
+              parse_partial, indexed_at
+       FROM code_files
+       WHERE parse_error IS NOT NULL${repoId ? ' AND repo_id = $1' : ''}
+       ORDER BY repo_id, file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listParseErrors', [repoId], err);
+  }
+};
+
+/**
+ * List secret scanner findings recorded during indexing (cindex secrets)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Findings ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSecretFindings = async (db: Pool, repoId?: string): Promise<SecretFindingRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<SecretFindingRecord>(
+      `SELECT repo_id, file_path, rule, line_number, column_number, byte_column, redacted, fingerprint, detected_at
+       FROM secret_findings${repoId ? ' WHERE repo_id = $1' : ''}
+       ORDER BY repo_id, file_path, line_number, column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSecretFindings', [repoId], err);
+  }
+};
+
+/**
+ * Innermost symbol whose span holds the line of a row with file_path and line_number
+ *
+ * @param alias - Alias of the row's table
+ */
+const enclosingSymbol = (alias: string): string => `(SELECT s.symbol_name FROM code_symbols s
+     WHERE s.file_path = ${alias}.file_path
+       AND ${alias}.line_number BETWEEN s.line_number AND COALESCE(s.end_line, s.line_number)
+     ORDER BY s.line_number DESC LIMIT 1) AS symbol_name`;
+
+/** Innermost symbol whose span holds a lint finding (alias l) */
+const LINT_SYMBOL = enclosingSymbol('l');
+
+/**
+ * List imported linter findings (cindex lint)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Findings with their enclosing symbol, ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listLintFindings = async (db: Pool, repoId?: string): Promise<LintFindingRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<LintFindingRecord>(
+      `SELECT l.repo_id, l.file_path, l.line_number, l.column_number, l.end_line, l.tool, l.linter, l.rule,
+              l.severity, l.message, ${LINT_SYMBOL}, l.imported_at
+       FROM lint_findings l${repoId ? ' WHERE l.repo_id = $1' : ''}
+       ORDER BY l.repo_id, l.file_path, l.line_number, l.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listLintFindings', [repoId], err);
+  }
+};
+
+/**
+ * List linter findings on a range of lines of one file (cindex show)
+ *
+ * @param db - Database connection pool
+ * @param filePath - Stored file path
+ * @param startLine - First line (1-based, inclusive)
+ * @param endLine - Last line (inclusive)
+ * @param repoId - Index of the file, when known
+ * @returns Findings ordered by position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listLintFindingsInLines = async (
+  db: Pool,
+  filePath: string,
+  startLine: number,
+  endLine: number,
+  repoId?: string
+): Promise<LintFindingRecord[]> => {
+  try {
+    const params: unknown[] = [filePath, startLine, endLine];
+    if (repoId) params.push(repoId);
+    const result = await db.query<LintFindingRecord>(
+      `SELECT l.repo_id, l.file_path, l.line_number, l.column_number, l.end_line, l.tool, l.linter, l.rule,
+              l.severity, l.message, ${LINT_SYMBOL}, l.imported_at
+       FROM lint_findings l
+       WHERE l.file_path = $1 AND l.line_number BETWEEN $2 AND $3${repoId ? ' AND l.repo_id = $4' : ''}
+       ORDER BY l.line_number, l.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listLintFindingsInLines', [filePath, startLine, endLine], err);
+  }
+};
+
+/**
+ * List type-checked uses of a Go declaration (cindex refs)
+ *
+ * The target is a name as recorded (Login, Server.Handle), a method name
+ * alone (Handle: every receiver's), or either qualified by the last element
+ * of its package path (auth.Login, store.Memory.Get).
+ *
+ * @param db - Database connection pool
+ * @param target - Declaration to find uses of
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns References with their enclosing symbol, ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoReferences = async (db: Pool, target: string, repoId?: string): Promise<GoReferenceRecord[]> => {
+  try {
+    const params = repoId ? [target, repoId] : [target];
+    const result = await db.query<GoReferenceRecord>(
+      `SELECT r.repo_id, r.file_path, r.line_number, r.column_number, r.target_name, r.target_package,
+              r.target_file, r.target_line, r.access, ${enclosingSymbol('r')}
+       FROM go_references r
+       WHERE (r.target_name = $1
+              OR right(r.target_name, length($1) + 1) = '.' || $1
+              OR regexp_replace(r.target_package, '^.*/', '') || '.' || r.target_name = $1)
+         ${repoId ? 'AND r.repo_id = $2' : ''}
+       ORDER BY r.repo_id, r.file_path, r.line_number, r.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoReferences', [target, repoId], err);
+  }
+};
+
+/**
+ * Check whether typed indexing has recorded references
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to check (default: any index)
+ * @returns True if an index was indexed with --typed
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const hasGoReferences = async (db: Pool, repoId?: string): Promise<boolean> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<{ found: boolean }>(
+      `SELECT EXISTS (SELECT 1 FROM go_references${repoId ? ' WHERE repo_id = $1' : ''}) AS found`,
+      params
+    );
+    return result.rows[0].found;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('hasGoReferences', [repoId], err);
+  }
+};
+
+/** Directory (Go package) of a file path column ('' at the root) */
+const goPackageDir = (column: string): string => `regexp_replace(${column}, '/?[^/]*$', '')`;
+
+/**
+ * List an index's Go declarations with counts of their type-checked uses (cindex deadcode)
+ *
+ * Tests, benchmarks, fuzz targets, examples, and generated files are left out.
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Declarations ordered by file and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoSymbolUsage = async (db: Pool, repoId: string): Promise<GoSymbolUsageRecord[]> => {
+  try {
+    const result = await db.query<GoSymbolUsageRecord>(
+      `SELECT s.repo_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, s.scope, s.definition,
+              COUNT(r.id)::int AS reference_count,
+              (COUNT(r.id) FILTER (WHERE r.file_path <> s.file_path))::int AS outside_file,
+              (COUNT(r.id) FILTER (WHERE ${goPackageDir('r.file_path')} <> ${goPackageDir('s.file_path')}))::int
+                AS outside_package,
+              (s.symbol_type = 'method' AND EXISTS (SELECT 1 FROM go_implementations g
+                WHERE g.repo_id = s.repo_id AND g.type_name = split_part(s.symbol_name, '.', 1)
+                  AND ${goPackageDir('g.file_path')} = ${goPackageDir('s.file_path')})) AS implements_interface
+       FROM code_symbols s
+       JOIN code_files f ON f.file_path = s.file_path AND f.repo_id = s.repo_id
+       LEFT JOIN go_references r
+         ON r.repo_id = s.repo_id AND r.target_file = s.file_path AND r.target_name = s.symbol_name
+       WHERE s.repo_id = $1 AND f.language = 'go' AND NOT f.generated
+         AND s.symbol_type NOT IN ('test', 'benchmark', 'fuzz', 'example')
+       GROUP BY s.id
+       ORDER BY s.file_path, s.line_number`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoSymbolUsage', [repoId], err);
+  }
+};
+
+/** Columns of a go_calls row (alias c) */
+const GO_CALL_COLUMNS = `c.repo_id, c.file_path, c.caller_name, c.caller_line, c.callee_name, c.callee_package,
+              c.callee_qualifier, c.call_kind, c.line_number, c.column_number`;
+
+/** Package of a go_calls row: its directory's name (NULL at the root) */
+const GO_CALL_PACKAGE = "substring(c.file_path from '([^/]+)/[^/]+$')";
+
+/**
+ * List the calls of a Go function or method (cindex callers)
+ *
+ * The target takes the forms of listGoReferences: Login, AuthService.Login,
+ * Login alone for the method on any receiver, or a name qualified by its
+ * package (auth.Login). Calls on values of unknown type (kind dynamic) are
+ * matched by method name only; exact leaves them out.
+ *
+ * @param db - Database connection pool
+ * @param target - Declaration to find calls of
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.exact - Only calls resolved by receiver, package, or import
+ * @returns Calls ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoCallers = async (
+  db: Pool,
+  target: string,
+  options: { repoId?: string; exact?: boolean } = {}
+): Promise<GoCallRecord[]> => {
+  const { repoId, exact = false } = options;
+  try {
+    const params = repoId ? [target, repoId] : [target];
+    const result = await db.query<GoCallRecord>(
+      `SELECT ${GO_CALL_COLUMNS}
+       FROM go_calls c
+       WHERE ((c.call_kind IN ('function', 'method')
+               AND (c.callee_name = $1
+                    OR right(c.callee_name, length($1) + 1) = '.' || $1
+                    OR ${GO_CALL_PACKAGE} || '.' || c.callee_name = $1))
+              OR (c.call_kind = 'import'
+                  AND (c.callee_name = $1 OR regexp_replace(c.callee_package, '^.*/', '') || '.' || c.callee_name = $1))
+              ${exact ? '' : "OR (c.call_kind = 'dynamic' AND c.callee_name = regexp_replace($1, '^.*[.]', ''))"})
+         ${repoId ? 'AND c.repo_id = $2' : ''}
+       ORDER BY c.repo_id, c.file_path, c.line_number, c.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoCallers', [target, repoId], err);
+  }
+};
+
+/**
+ * List the calls made by a Go function or method (cindex callees)
+ *
+ * @param db - Database connection pool
+ * @param caller - Calling declaration, in the target forms of listGoCallers
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Calls ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoCallees = async (db: Pool, caller: string, repoId?: string): Promise<GoCallRecord[]> => {
+  try {
+    const params = repoId ? [caller, repoId] : [caller];
+    const result = await db.query<GoCallRecord>(
+      `SELECT ${GO_CALL_COLUMNS}
+       FROM go_calls c
+       WHERE (c.caller_name = $1
+              OR right(c.caller_name, length($1) + 1) = '.' || $1
+              OR ${GO_CALL_PACKAGE} || '.' || c.caller_name = $1)
+         ${repoId ? 'AND c.repo_id = $2' : ''}
+       ORDER BY c.repo_id, c.file_path, c.line_number, c.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoCallees', [caller, repoId], err);
+  }
+};
+
+/**
+ * List every Go call recorded for an index (cindex tests, cindex audit)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list (default: all indexes)
+ * @returns Calls ordered by file and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoCalls = async (db: Pool, repoId?: string): Promise<GoCallRecord[]> => {
+  try {
+    const result = await db.query<GoCallRecord>(
+      `SELECT ${GO_CALL_COLUMNS}
+       FROM go_calls c
+       ${repoId ? 'WHERE c.repo_id = $1' : ''}
+       ORDER BY c.file_path, c.line_number, c.column_number`,
+      repoId ? [repoId] : []
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoCalls', [repoId], err);
+  }
+};
+
+/**
+ * List a Go enum: the constants of a type, or the const group of a constant (cindex enums)
+ *
+ * The name is a type (UserRole) or a constant (SessionTimeout, RoleAdmin),
+ * optionally qualified by its package (auth.UserRole). A constant stands for
+ * every constant declared with it in one const declaration.
+ *
+ * @param db - Database connection pool
+ * @param name - Type or constant name
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Constants ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoConstants = async (db: Pool, name: string, repoId?: string): Promise<GoConstantRecord[]> => {
+  try {
+    const params = repoId ? [name, repoId] : [name];
+    const result = await db.query<GoConstantRecord>(
+      `SELECT c.repo_id, c.file_path, c.constant_name, c.type_name, c.value, c.expression,
+              c.iota, c.group_line, c.line_number, c.doc
+       FROM go_constants c
+       WHERE (c.type_name = $1
+              OR substring(c.file_path from '([^/]+)/[^/]+$') || '.' || c.type_name = $1
+              OR EXISTS (
+                SELECT 1 FROM go_constants g
+                WHERE g.file_path = c.file_path AND g.group_line = c.group_line
+                  AND (g.constant_name = $1
+                       OR substring(g.file_path from '([^/]+)/[^/]+$') || '.' || g.constant_name = $1)))
+         ${repoId ? 'AND c.repo_id = $2' : ''}
+       ORDER BY c.repo_id, c.file_path, c.line_number, c.id`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoConstants', [name, repoId], err);
+  }
+};
+
+/** Package of a go_type_parameters row (alias t): its directory's name (NULL at the root) */
+const GO_GENERIC_PACKAGE = "substring(t.file_path from '([^/]+)/[^/]+$')";
+
+/**
+ * List the type parameters of generic Go functions and types (cindex generics)
+ *
+ * @param db - Database connection pool
+ * @param name - Generic (Map, or qualified by its package: collections.Map); all generics when omitted
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Type parameters ordered by index, file, declaration, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoTypeParameters = async (
+  db: Pool,
+  name?: string,
+  repoId?: string
+): Promise<GoTypeParameterRecord[]> => {
+  try {
+    const conditions: string[] = [];
+    const params: unknown[] = [];
+    if (name !== undefined) {
+      params.push(name);
+      conditions.push(`(t.symbol_name = $1 OR ${GO_GENERIC_PACKAGE} || '.' || t.symbol_name = $1)`);
+    }
+    if (repoId) {
+      params.push(repoId);
+      conditions.push(`t.repo_id = $${String(params.length)}`);
+    }
+    const result = await db.query<GoTypeParameterRecord>(
+      `SELECT t.repo_id, t.file_path, t.symbol_name, t.symbol_kind, t.parameter_name, t.constraint_text,
+              t.position, t.line_number
+       FROM go_type_parameters t
+       ${conditions.length > 0 ? `WHERE ${conditions.join(' AND ')}` : ''}
+       ORDER BY t.repo_id, t.file_path, t.line_number, t.position`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoTypeParameters', [name, repoId], err);
+  }
+};
+
+/**
+ * List the instantiations of a generic Go function or type (cindex generics)
+ *
+ * Explicit instantiations are the recorded names followed by type arguments
+ * that resolve to a generic: one declared in the same index, or any name
+ * qualified by an import. Calls of a generic function without type arguments
+ * are returned as inferred instantiations.
+ *
+ * @param db - Database connection pool
+ * @param target - Generic, in the forms of listGoTypeParameters
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Instantiations ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoInstantiations = async (
+  db: Pool,
+  target: string,
+  repoId?: string
+): Promise<GoInstantiationRecord[]> => {
+  try {
+    const params = repoId ? [target, repoId] : [target];
+    const result = await db.query<GoInstantiationRecord>(
+      `SELECT i.repo_id, i.file_path, i.target_name, i.target_package, i.type_arguments, i.context_name,
+              i.line_number, i.column_number, false AS inferred
+       FROM go_instantiations i
+       WHERE (i.target_name = $1
+              OR COALESCE(regexp_replace(i.target_package, '^.*/', ''),
+                          substring(i.file_path from '([^/]+)/[^/]+$')) || '.' || i.target_name = $1)
+         AND (i.target_package IS NOT NULL
+              OR EXISTS (SELECT 1 FROM go_type_parameters t
+                         WHERE t.repo_id IS NOT DISTINCT FROM i.repo_id AND t.symbol_name = i.target_name))
+         ${repoId ? 'AND i.repo_id = $2' : ''}
+       UNION ALL
+       SELECT c.repo_id, c.file_path, c.callee_name, c.callee_package, ARRAY[]::text[], c.caller_name,
+              c.line_number, c.column_number, true
+       FROM go_calls c
+       WHERE c.call_kind IN ('function', 'import')
+         AND (c.callee_name = $1
+              OR COALESCE(regexp_replace(c.callee_package, '^.*/', ''),
+                          ${GO_CALL_PACKAGE}) || '.' || c.callee_name = $1)
+         AND EXISTS (SELECT 1 FROM go_type_parameters t
+                     WHERE t.symbol_kind = 'function' AND t.symbol_name = c.callee_name
+                       AND (c.call_kind = 'import' OR t.repo_id IS NOT DISTINCT FROM c.repo_id))
+         ${repoId ? 'AND c.repo_id = $2' : ''}
+       ORDER BY 1, 2, 7, 8`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoInstantiations', [target, repoId], err);
+  }
+};
+
+/** Deepest embedding chain followed by listGoSupertypes and listGoSubtypes */
+const MAX_EMBEDDING_DEPTH = 20;
+
+/**
+ * Follow Go embeddings from a type, up to the types it embeds or down to those embedding it
+ *
+ * Embeddings resolve by package name and type name within an index: the
+ * embedding type's package is its directory, an embedded type's the last
+ * element of its import path (or the embedding type's without one).
+ */
+const listGoEmbeddingChain = async (
+  db: Pool,
+  name: string,
+  direction: 'up' | 'down',
+  repoId?: string
+): Promise<GoEmbeddingRecord[]> => {
+  // Up: the type embeds; down: the type is embedded
+  const [from, to] =
+    direction === 'up' ? ['type_package', 'embedded_package_name'] : ['embedded_package_name', 'type_package'];
+  const [fromName, toName] = direction === 'up' ? ['type_name', 'embedded_name'] : ['embedded_name', 'type_name'];
+  const params = repoId ? [name, repoId] : [name];
+  const result = await db.query<GoEmbeddingRecord>(
+    `WITH RECURSIVE edges AS (
+       SELECT e.id, e.repo_id, e.file_path, e.type_name, e.type_kind, e.embedded_name, e.embedded_package,
+              e.pointer, e.line_number, e.column_number,
+              COALESCE(substring(e.file_path from '([^/]+)/[^/]+$'), '') AS type_package,
+              COALESCE(regexp_replace(e.embedded_package, '^.*/', ''),
+                       substring(e.file_path from '([^/]+)/[^/]+$'), '') AS embedded_package_name
+       FROM go_embeddings e
+       ${repoId ? 'WHERE e.repo_id = $2' : ''}
+     ),
+     chain AS (
+       SELECT edges.*, 1 AS depth, ARRAY[edges.${from} || '.' || edges.${fromName}] AS visited
+       FROM edges
+       WHERE edges.${fromName} = $1 OR edges.${from} || '.' || edges.${fromName} = $1
+       UNION ALL
+       SELECT next.*, chain.depth + 1, chain.visited || (next.${from} || '.' || next.${fromName})
+       FROM chain
+       JOIN edges next ON next.repo_id IS NOT DISTINCT FROM chain.repo_id
+                      AND next.${fromName} = chain.${toName} AND next.${from} = chain.${to}
+       WHERE chain.depth < ${String(MAX_EMBEDDING_DEPTH)}
+         AND NOT (next.${from} || '.' || next.${fromName}) = ANY(chain.visited)
+     )
+     SELECT repo_id, file_path, type_name, type_package, type_kind, embedded_name, embedded_package,
+            embedded_package_name, pointer, line_number, column_number, depth
+     FROM (SELECT DISTINCT ON (id) * FROM chain ORDER BY id, depth) shallowest
+     ORDER BY repo_id, depth, file_path, line_number`,
+    params
+  );
+  return result.rows;
+};
+
+/**
+ * List the types a Go struct or interface embeds, and those they embed (cindex hierarchy)
+ *
+ * @param db - Database connection pool
+ * @param type - Type name (Name) or package-qualified name (pkg.Name)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Embeddings, each once at its shallowest depth, ordered by index, depth, and location
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoSupertypes = async (db: Pool, type: string, repoId?: string): Promise<GoEmbeddingRecord[]> => {
+  try {
+    return await listGoEmbeddingChain(db, type, 'up', repoId);
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoSupertypes', [type, repoId], err);
+  }
+};
+
+/**
+ * List the Go structs and interfaces embedding a type, and those embedding them (cindex hierarchy)
+ *
+ * @param db - Database connection pool
+ * @param type - Type name (Name) or package-qualified name (pkg.Name)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Embeddings, each once at its shallowest depth, ordered by index, depth, and location
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoSubtypes = async (db: Pool, type: string, repoId?: string): Promise<GoEmbeddingRecord[]> => {
+  try {
+    return await listGoEmbeddingChain(db, type, 'down', repoId);
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoSubtypes', [type, repoId], err);
+  }
+};
+
+/**
+ * List the Go modules of indexes, with the dependencies between them (cindex modules)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Modules ordered by index and directory
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoModules = async (db: Pool, repoId?: string): Promise<GoModuleRecord[]> => {
+  try {
+    const result = await db.query<GoModuleRecord>(
+      `SELECT w.repo_id, w.package_name AS module_path, w.workspace_path AS directory, w.version AS go_version,
+              (SELECT COUNT(*)::int FROM code_files f WHERE f.workspace_id = w.workspace_id) AS files,
+              (SELECT COUNT(*)::int FROM code_symbols s WHERE s.workspace_id = w.workspace_id) AS symbols,
+              ARRAY(SELECT t.package_name FROM workspace_dependencies d
+                    JOIN workspaces t ON t.workspace_id = d.target_workspace_id
+                    WHERE d.source_workspace_id = w.workspace_id AND d.dependency_type = 'go_module'
+                    ORDER BY 1) AS requires,
+              ARRAY(SELECT t.package_name FROM workspace_dependencies d
+                    JOIN workspaces t ON t.workspace_id = d.source_workspace_id
+                    WHERE d.target_workspace_id = w.workspace_id AND d.dependency_type = 'go_module'
+                    ORDER BY 1) AS required_by
+       FROM workspaces w
+       WHERE w.metadata->>'language' = 'go'${repoId ? ' AND w.repo_id = $1' : ''}
+       ORDER BY w.repo_id, w.workspace_path`,
+      repoId ? [repoId] : []
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoModules', [repoId], err);
+  }
+};
+
+/**
+ * Find the indexed files whose content matches a regular expression (cindex grep)
+ *
+ * The trigram index narrows the scan to files holding every trigram the
+ * expression requires; an expression requiring none reads every file.
+ *
+ * @param db - Database connection pool
+ * @param regex - PostgreSQL regular expression (see @retrieval/content-search)
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.pathPrefix - Only files whose path starts with this
+ * @param options.after - Only files after this path (the last of the previous page)
+ * @param options.limit - Most files returned
+ * @returns Files ordered by path
+ * @throws {DatabaseQueryError} If the expression is invalid or query execution fails
+ */
+export const searchFileContents = async (
+  db: Pool,
+  regex: string,
+  options: { repoId?: string; pathPrefix?: string; after?: string | null; limit: number }
+): Promise<FileContentRecord[]> => {
+  const { repoId, pathPrefix, after, limit } = options;
+  const params: unknown[] = [regex, limit];
+  const conditions = ['content ~ $1'];
+  if (repoId) {
+    params.push(repoId);
+    conditions.push(`repo_id = $${String(params.length)}`);
+  }
+  if (pathPrefix) {
+    params.push(pathPrefix);
+    conditions.push(`starts_with(file_path, $${String(params.length)})`);
+  }
+  if (after) {
+    params.push(after);
+    conditions.push(`file_path > $${String(params.length)}`);
+  }
+
+  try {
+    const result = await db.query<FileContentRecord>(
+      `SELECT repo_id, file_path, content
+       FROM code_contents
+       WHERE ${conditions.join(' AND ')}
+       ORDER BY file_path
+       LIMIT $2`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('searchFileContents', [regex, repoId, pathPrefix, after], err);
+  }
+};
+
+/**
+ * List the stored contents of an index's files, a page at a time (cindex export --format=packed)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to read
+ * @param after - Path the previous page ended with (null for the first page)
+ * @param limit - Most files returned
+ * @returns Contents ordered by path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listIndexContents = async (
+  db: Pool,
+  repoId: string,
+  after: string | null,
+  limit: number
+): Promise<FileContentRecord[]> => {
+  try {
+    const result = await db.query<FileContentRecord>(
+      `SELECT repo_id, file_path, content
+       FROM code_contents
+       WHERE repo_id = $1 AND ($2::text IS NULL OR file_path > $2)
+       ORDER BY file_path
+       LIMIT $3`,
+      [repoId, after, limit]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listIndexContents', [repoId, after], err);
+  }
+};
+
+/**
+ * List the types that satisfy a Go interface (cindex implementations)
+ *
+ * @param db - Database connection pool
+ * @param iface - Interface, lowercase, optionally with its package (see parseInterfaceFilter)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Implementations ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoImplementations = async (
+  db: Pool,
+  iface: InterfaceCondition,
+  repoId?: string
+): Promise<GoImplementationRecord[]> => {
+  try {
+    const params = repoId ? [iface.name, iface.package, repoId] : [iface.name, iface.package];
+    const result = await db.query<GoImplementationRecord>(
+      `SELECT g.repo_id, g.file_path, g.line_number, g.type_name, g.type_package, g.interface_name,
+              g.interface_package, g.pointer_receiver
+       FROM go_implementations g
+       WHERE ${goNameMatches('g.interface_name', 'g.interface_package', '$1', '$2::text')}
+         ${repoId ? 'AND g.repo_id = $3' : ''}
+       ORDER BY g.repo_id, g.file_path, g.line_number, g.interface_package, g.interface_name`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoImplementations', [iface.name, iface.package, repoId], err);
+  }
+};
+
+/**
+ * List the interfaces a Go type satisfies (cindex satisfies)
+ *
+ * @param db - Database connection pool
+ * @param type - Type, lowercase, optionally with its package (see parseInterfaceFilter)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Interfaces satisfied, ordered by index, type, and interface
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoSatisfiedInterfaces = async (
+  db: Pool,
+  type: InterfaceCondition,
+  repoId?: string
+): Promise<GoImplementationRecord[]> => {
+  try {
+    const params = repoId ? [type.name, type.package, repoId] : [type.name, type.package];
+    const result = await db.query<GoImplementationRecord>(
+      `SELECT g.repo_id, g.file_path, g.line_number, g.type_name, g.type_package, g.interface_name,
+              g.interface_package, g.pointer_receiver
+       FROM go_implementations g
+       WHERE ${goNameMatches('g.type_name', 'g.type_package', '$1', '$2::text')}
+         ${repoId ? 'AND g.repo_id = $3' : ''}
+       ORDER BY g.repo_id, g.file_path, g.line_number, g.interface_package, g.interface_name`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoSatisfiedInterfaces', [type.name, type.package, repoId], err);
+  }
+};
+
+/**
+ * Find the type-checked reference at a position (cindex def)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to read
+ * @param filePath - File path relative to the repository root
+ * @param line - 1-based line
+ * @param column - 1-based byte column anywhere in the referring identifier
+ * @returns Reference whose identifier covers the position, or null
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const findGoReferenceAt = async (
+  db: Pool,
+  repoId: string,
+  filePath: string,
+  line: number,
+  column: number
+): Promise<GoReferenceRecord | null> => {
+  try {
+    // The identifier is the last element of the target name (Get of Store.Get)
+    const result = await db.query<GoReferenceRecord>(
+      `SELECT r.repo_id, r.file_path, r.line_number, r.column_number, r.target_name, r.target_package,
+              r.target_file, r.target_line, r.access, ${enclosingSymbol('r')}
+       FROM go_references r
+       WHERE r.repo_id = $1 AND r.file_path = $2 AND r.line_number = $3 AND r.column_number <= $4
+         AND $4 < r.column_number + octet_length(regexp_replace(r.target_name, '^.*[.]', ''))
+       ORDER BY r.column_number DESC
+       LIMIT 1`,
+      [repoId, filePath, line, column]
+    );
+    return result.rows.length > 0 ? result.rows[0] : null;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('findGoReferenceAt', [repoId, filePath, line, column], err);
+  }
+};
+
+/**
+ * List the type-checked uses of the declaration at a line (cindex serve --lsp)
+ *
+ * @param db - Database connection pool
+ * @param target.file_path - File the declaration is in, relative to the repository root
+ * @param target.line - 1-based line of the declaration
+ * @param target.name - Target name as recorded, or its last element (Get of Store.Get)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns References ordered by index, file, and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoReferencesTo = async (
+  db: Pool,
+  target: { file_path: string; line: number; name: string },
+  repoId?: string
+): Promise<GoReferenceRecord[]> => {
+  try {
+    const params = [target.file_path, target.line, target.name];
+    if (repoId) params.push(repoId);
+    const result = await db.query<GoReferenceRecord>(
+      `SELECT r.repo_id, r.file_path, r.line_number, r.column_number, r.target_name, r.target_package,
+              r.target_file, r.target_line, r.access, ${enclosingSymbol('r')}
+       FROM go_references r
+       WHERE r.target_file = $1 AND r.target_line = $2
+         AND (r.target_name = $3 OR right(r.target_name, length($3) + 1) = '.' || $3)
+         ${repoId ? 'AND r.repo_id = $4' : ''}
+       ORDER BY r.repo_id, r.file_path, r.line_number, r.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoReferencesTo', [target.file_path, target.line, target.name, repoId], err);
+  }
+};
+
+/**
+ * List the type-checked references on one line of a Go file (get_definition)
+ *
+ * @param db - Database connection pool
+ * @param filePath - File path relative to the repository root
+ * @param line - 1-based line
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns References ordered by column
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoReferencesOnLine = async (
+  db: Pool,
+  filePath: string,
+  line: number,
+  repoId?: string
+): Promise<GoReferenceRecord[]> => {
+  try {
+    const params: unknown[] = repoId ? [filePath, line, repoId] : [filePath, line];
+    const result = await db.query<GoReferenceRecord>(
+      `SELECT r.repo_id, r.file_path, r.line_number, r.column_number, r.target_name, r.target_package,
+              r.target_file, r.target_line, r.access, ${enclosingSymbol('r')}
+       FROM go_references r
+       WHERE r.file_path = $1 AND r.line_number = $2${repoId ? ' AND r.repo_id = $3' : ''}
+       ORDER BY r.column_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoReferencesOnLine', [filePath, line, repoId], err);
+  }
+};
+
+/**
+ * List the content hashes of an index's files
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to read
+ * @param extension - Only files with this extension (e.g. '.go')
+ * @returns File versions ordered by path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listIndexedFileVersions = async (
+  db: Pool,
+  repoId: string,
+  extension?: string
+): Promise<IndexedFileVersionRecord[]> => {
+  try {
+    const params = extension ? [repoId, `%${extension}`] : [repoId];
+    const result = await db.query<IndexedFileVersionRecord>(
+      `SELECT file_path, file_hash, last_modified FROM code_files
+       WHERE repo_id = $1${extension ? ' AND file_path LIKE $2' : ''}
+       ORDER BY file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listIndexedFileVersions', [repoId, extension], err);
+  }
+};
+
+/**
+ * List indexed files with their repository (matching external reports to the index)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Indexed files ordered by path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listIndexedFiles = async (db: Pool, repoId?: string): Promise<IndexedFileRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<IndexedFileRecord>(
+      `SELECT repo_id, repo_path, file_path FROM code_files${repoId ? ' WHERE repo_id = $1' : ''} ORDER BY file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listIndexedFiles', [repoId], err);
+  }
+};
+
+/**
+ * Search symbols by the words of their doc comments and names (cindex doc --search)
+ *
+ * Words are stemmed, so "session expiration" finds a SessionTimeout documented
+ * as expiring. Symbols matching every word rank before those matching some.
+ *
+ * @param db - Database connection pool
+ * @param text - Words to look for
+ * @param options.repoId - Restrict to one index (default: all indexes but Go module and revision indexes)
+ * @param options.limit - Maximum results (default 20)
+ * @returns Matches, best first
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const searchSymbolDocs = async (
+  db: Pool,
+  text: string,
+  options: { repoId?: string; limit?: number } = {}
+): Promise<DocMatchRecord[]> => {
+  const { repoId, limit = 20 } = options;
+  try {
+    const scope = repoId
+      ? 's.repo_id = $3'
+      : `NOT EXISTS (SELECT 1 FROM repositories r
+         WHERE r.repo_id = s.repo_id AND (r.metadata->>'go_module' IS NOT NULL OR r.metadata ? 'revision'))`;
+    const result = await db.query<DocMatchRecord>(
+      `SELECT s.repo_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, s.doc_comment,
+              s.doc_tsv @@ q.every_word AS complete, ts_rank_cd(s.doc_tsv, q.any_word) AS rank
+       FROM code_symbols s,
+            (SELECT plainto_tsquery('english', $1) AS every_word,
+                    (SELECT string_agg(quote_literal(lexeme), ' | ')
+                     FROM unnest(tsvector_to_array(to_tsvector('english', $1))) AS lexeme)::tsquery AS any_word) q
+       WHERE s.doc_tsv @@ q.any_word AND ${scope}
+       ORDER BY complete DESC, rank DESC, CASE WHEN s.scope = 'exported' THEN 0 ELSE 1 END, s.symbol_name
+       LIMIT $2`,
+      repoId ? [text, limit, repoId] : [text, limit]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('searchSymbolDocs', [text, repoId], err);
+  }
+};
+
+/**
+ * List the symbols of an index with their doc comments, a page at a time (see @database/store)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to read
+ * @param after - ID of the last symbol of the previous page (0 for the first page)
+ * @param limit - Most symbols returned
+ * @returns Symbols ordered by ID
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listStoreSymbols = async (
+  db: Pool,
+  repoId: string,
+  after: number,
+  limit: number
+): Promise<(StoredSymbol & { id: number })[]> => {
+  try {
+    const result = await db.query<StoredSymbol & { id: number }>(
+      `SELECT id, symbol_name, symbol_type, file_path, line_number, doc_comment, scope = 'exported' AS exported
+       FROM code_symbols
+       WHERE repo_id = $1 AND id > $2
+       ORDER BY id
+       LIMIT $3`,
+      [repoId, after, limit]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listStoreSymbols', [repoId, after], err);
+  }
+};
+
+/**
+ * Check whether an index is searched when no index is named
+ *
+ * Go module indexes (cindex deps) and revision indexes (cindex index --rev) are not.
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to check
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const isSearchedByDefault = async (db: Pool, repoId: string): Promise<boolean> => {
+  try {
+    const result = await db.query<{ hidden: boolean }>(
+      `SELECT EXISTS (SELECT 1 FROM repositories
+         WHERE repo_id = $1 AND (metadata->>'go_module' IS NOT NULL OR metadata ? 'revision')) AS hidden`,
+      [repoId]
+    );
+    return !result.rows[0].hidden;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('isSearchedByDefault', [repoId], err);
+  }
+};
+
+/**
+ * List the import paths of indexed files (cindex graph)
+ *
+ * Go module indexes (cindex deps) are left out unless asked for: with an
+ * index, the modules linked to it; without one, all of them. Revision
+ * indexes (cindex index --rev) are listed only when selected.
+ *
+ * @param db - Database connection pool
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.dependencies - Also list the files of Go module indexes
+ * @returns Files ordered by index and path
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileImports = async (
+  db: Pool,
+  options: { repoId?: string; dependencies?: boolean } = {}
+): Promise<FileImportsRecord[]> => {
+  const { repoId, dependencies = false } = options;
+  try {
+    let condition = dependencies
+      ? "r.metadata->>'revision' IS NULL"
+      : "r.metadata->>'go_module' IS NULL AND r.metadata->>'revision' IS NULL";
+    if (repoId && dependencies) {
+      condition = `(f.repo_id = $1 OR f.repo_id IN (SELECT d.target_repo_id FROM cross_repo_dependencies d
+        WHERE d.source_repo_id = $1 AND d.metadata->>'go_module' IS NOT NULL))`;
+    } else if (repoId) {
+      condition = 'f.repo_id = $1';
+    }
+    const result = await db.query<FileImportsRecord>(
+      `SELECT COALESCE(f.repo_id, f.repo_path) AS repo_id,
+              COALESCE(r.metadata->>'go_module', w.package_name) AS module_path,
+              CASE WHEN r.metadata->>'go_module' IS NOT NULL THEN '.' ELSE w.workspace_path END AS module_directory,
+              f.file_path, f.language,
+              ARRAY(SELECT imp->>'path' FROM jsonb_array_elements(f.imports->'imports') AS imp
+                    WHERE imp->>'path' <> '') AS imports
+       FROM code_files f
+       LEFT JOIN repositories r ON r.repo_id = f.repo_id
+       LEFT JOIN workspaces w ON w.workspace_id = f.workspace_id AND w.metadata->>'language' = 'go'
+       WHERE ${condition}
+       ORDER BY 1, f.file_path`,
+      repoId ? [repoId] : []
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileImports', [repoId, dependencies], err);
+  }
+};
+
+/**
+ * List exported symbols with the language of their file (cindex api)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Exported symbols ordered by file and line; generated files are left out
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listExportedSymbols = async (db: Pool, repoId?: string): Promise<ExportedSymbolRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<ExportedSymbolRecord>(
+      `SELECT s.symbol_name, s.symbol_type, s.file_path, s.definition, f.language
+       FROM code_symbols s
+       JOIN code_files f ON f.file_path = s.file_path AND f.repo_id IS NOT DISTINCT FROM s.repo_id
+       WHERE s.scope = 'exported' AND NOT f.generated${repoId ? ' AND s.repo_id = $1' : ''}
+       ORDER BY s.file_path, s.line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listExportedSymbols', [repoId], err);
+  }
+};
+
+/**
+ * List the line spans of Go functions and methods (cindex coverage)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Functions ordered by file and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoFunctionSpans = async (db: Pool, repoId?: string): Promise<FunctionSpanRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<FunctionSpanRecord>(
+      `SELECT s.id, s.symbol_name, s.file_path, s.line_number, s.end_line
+       FROM code_symbols s
+       JOIN code_files f ON f.file_path = s.file_path
+       WHERE f.language = 'go' AND s.symbol_type IN ('function', 'method')${repoId ? ' AND s.repo_id = $1' : ''}
+       ORDER BY s.file_path, s.line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoFunctionSpans', [repoId], err);
+  }
+};
+
+/**
+ * List the definitions of a Go symbol with the build constraints of their files (cindex platforms)
+ * @param db - Database connection pool
+ * @param symbolName - Exact (case-sensitive) symbol name (Receiver.Method for methods)
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Definitions ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolVariants = async (
+  db: Pool,
+  symbolName: string,
+  repoId?: string
+): Promise<SymbolVariantRecord[]> => {
+  try {
+    const params = repoId ? [symbolName, repoId] : [symbolName];
+    const result = await db.query<SymbolVariantRecord>(
+      `SELECT s.repo_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, f.build_constraint
+       FROM code_symbols s
+       JOIN code_files f ON f.file_path = s.file_path
+       WHERE s.symbol_name = $1 AND f.language = 'go'${repoId ? ' AND s.repo_id = $2' : ''}
+       ORDER BY s.repo_id, s.file_path, s.line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolVariants', [symbolName, repoId], err);
+  }
+};
+
+/**
+ * List the Go functions, methods, and test functions of an index (cindex tests)
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Declarations ordered by file and line; subtests are left out
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listGoFunctions = async (db: Pool, repoId: string): Promise<SymbolVariantRecord[]> => {
+  try {
+    const result = await db.query<SymbolVariantRecord>(
+      `SELECT s.repo_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, f.build_constraint
+       FROM code_symbols s
+       JOIN code_files f ON f.file_path = s.file_path
+       WHERE s.repo_id = $1 AND f.language = 'go'
+         AND s.symbol_type IN ('function', 'method', 'test', 'benchmark', 'fuzz', 'example')
+         AND strpos(s.symbol_name, '/') = 0
+       ORDER BY s.file_path, s.line_number`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listGoFunctions', [repoId], err);
+  }
+};
+
+/**
+ * List the Go module indexes linked to an index (cindex deps)
+ * @param db - Database connection pool
+ * @param repoId - Index that requires the modules
+ * @returns Module index IDs (module@version), sorted
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listLinkedModules = async (db: Pool, repoId: string): Promise<string[]> => {
+  try {
+    const result = await db.query<{ target_repo_id: string }>(
+      `SELECT target_repo_id FROM cross_repo_dependencies
+       WHERE source_repo_id = $1 AND metadata->>'go_module' IS NOT NULL
+       ORDER BY target_repo_id`,
+      [repoId]
+    );
+    return result.rows.map((row) => row.target_repo_id);
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listLinkedModules', [repoId], err);
+  }
+};
+
+/**
+ * List the stored content of every indexed file with its language (cindex audit)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Files ordered by path
  * @throws {DatabaseQueryError} If query execution fails
  */
-export const searchSymbols = async (
-  db: Pool,
-  symbolName: string,
-  options: {
-    scope?: 'all' | 'exported' | 'internal';
-    workspaceId?: string;
-    serviceId?: string;
-    repoId?: string;
-    limit?: number;
-  } = {}
-): Promise<ResolvedSymbol[]> => {
+export const listSourceFiles = async (db: Pool, repoId?: string): Promise<SourceFileRecord[]> => {
   try {
-    const conditions: string[] = ['symbol_name ILIKE $1'];
-    const params: unknown[] = [`%${symbolName}%`];
-    let paramIndex = 2;
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<SourceFileRecord>(
+      `SELECT c.repo_id, c.file_path, c.content, f.language
+       FROM code_contents c
+       JOIN code_files f ON f.file_path = c.file_path AND f.repo_id IS NOT DISTINCT FROM c.repo_id
+       ${repoId ? 'WHERE c.repo_id = $1' : ''}
+       ORDER BY c.file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSourceFiles', [repoId], err);
+  }
+};
 
-    // Scope filter (exported symbols are public API, internal are private)
-    if (options.scope === 'exported') {
-      conditions.push(`scope = 'exported'`);
-    } else if (options.scope === 'internal') {
-      conditions.push(`scope = 'internal'`);
-    }
+/**
+ * List the line spans of functions, methods, classes, and tests (cindex owners)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Symbols ordered by file and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolSpans = async (db: Pool, repoId?: string): Promise<FunctionSpanRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<FunctionSpanRecord>(
+      `SELECT id, symbol_name, file_path, line_number, end_line
+       FROM code_symbols
+       WHERE symbol_type IN ('function', 'method', 'class', 'test', 'benchmark', 'fuzz', 'example')
+         ${repoId ? 'AND repo_id = $1' : ''}
+       ORDER BY file_path, line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolSpans', [repoId], err);
+  }
+};
 
-    // Multi-project filters
-    if (options.workspaceId) {
-      conditions.push(`workspace_id = $${String(paramIndex++)}`);
-      params.push(options.workspaceId);
-    }
+/**
+ * List the symbols of an index with no last change recorded (cindex index --history)
+ *
+ * Symbols of files re-indexed since the last history pass, and of files with
+ * no committed lines, have none.
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Symbol spans ordered by file and line (end_line null for single-line symbols)
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolsWithoutHistory = async (db: Pool, repoId: string): Promise<FunctionSpanRecord[]> => {
+  try {
+    const result = await db.query<FunctionSpanRecord>(
+      `SELECT id, symbol_name, file_path, line_number, end_line
+       FROM code_symbols
+       WHERE repo_id = $1 AND last_commit IS NULL
+       ORDER BY file_path, line_number`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolsWithoutHistory', [repoId], err);
+  }
+};
 
-    if (options.serviceId) {
-      conditions.push(`service_id = $${String(paramIndex++)}`);
-      params.push(options.serviceId);
-    }
+/**
+ * Find symbols by name with their recorded last change (cindex owners <symbol>)
+ *
+ * The name takes the forms of listGoReferences: Login, AuthService.Login,
+ * Login alone for the method on any receiver, or a name qualified by its
+ * package directory (auth.Login).
+ *
+ * @param db - Database connection pool
+ * @param name - Symbol name
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Symbols ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolHistory = async (db: Pool, name: string, repoId?: string): Promise<SymbolHistoryRecord[]> => {
+  try {
+    const params = repoId ? [name, repoId] : [name];
+    const result = await db.query<SymbolHistoryRecord>(
+      `SELECT id, repo_id, symbol_name, symbol_type, file_path, line_number, end_line,
+              last_commit, last_author, last_modified
+       FROM code_symbols
+       WHERE (symbol_name = $1
+              OR right(symbol_name, length($1) + 1) = '.' || $1
+              OR substring(file_path from '([^/]+)/[^/]+$') || '.' || symbol_name = $1)
+         ${repoId ? 'AND repo_id = $2' : ''}
+       ORDER BY repo_id, file_path, line_number`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolHistory', [name, repoId], err);
+  }
+};
 
-    if (options.repoId) {
-      conditions.push(`repo_id = $${String(paramIndex++)}`);
-      params.push(options.repoId);
-    }
+/**
+ * List the symbols of an index with a hash of their source lines (cindex diff-symbols)
+ *
+ * The hash covers the symbol's lines as stored (declaration line only when
+ * its end is unknown); it is null for files whose content is not stored.
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Symbols ordered by file and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listSymbolFingerprints = async (db: Pool, repoId: string): Promise<SymbolFingerprintRecord[]> => {
+  try {
+    const result = await db.query<SymbolFingerprintRecord>(
+      `WITH contents AS (
+         SELECT file_path, string_to_array(content, E'\n') AS lines FROM code_contents WHERE repo_id = $1
+       )
+       SELECT s.symbol_name, s.symbol_type, s.file_path, s.line_number,
+              md5(array_to_string(c.lines[s.line_number:COALESCE(s.end_line, s.line_number)], E'\n')) AS source_hash
+       FROM code_symbols s
+       LEFT JOIN contents c ON c.file_path = s.file_path
+       WHERE s.repo_id = $1
+       ORDER BY s.file_path, s.line_number`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listSymbolFingerprints', [repoId], err);
+  }
+};
 
-    const limit = options.limit ?? 50;
+/**
+ * List an index's symbols with their IDs and declaration lines (cindex export --format scip)
+ *
+ * Symbols indexed before IDs were stored are left out.
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Symbols ordered by file and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listScipSymbols = async (db: Pool, repoId: string): Promise<ScipSymbolRecord[]> => {
+  try {
+    const result = await db.query<ScipSymbolRecord>(
+      `WITH contents AS (
+         SELECT file_path, string_to_array(content, E'\n') AS lines FROM code_contents WHERE repo_id = $1
+       )
+       SELECT s.symbol_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, s.end_line, s.definition,
+              s.doc_comment, f.language, c.lines[s.line_number] AS line_text
+       FROM code_symbols s
+       LEFT JOIN code_files f ON f.file_path = s.file_path AND f.repo_id IS NOT DISTINCT FROM s.repo_id
+       LEFT JOIN contents c ON c.file_path = s.file_path
+       WHERE s.repo_id = $1 AND s.symbol_id IS NOT NULL
+       ORDER BY s.file_path, s.line_number, s.symbol_name`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listScipSymbols', [repoId], err);
+  }
+};
 
-    const sql = `
-      SELECT
-        symbol_name,
-        symbol_type,
-        file_path,
-        line_number,
-        definition,
-        scope,
-        workspace_id,
-        service_id
-      FROM code_symbols
-      WHERE ${conditions.join(' AND ')}
-      ORDER BY
-        CASE WHEN scope = 'exported' THEN 0 ELSE 1 END,
-        symbol_name
-      LIMIT $${String(paramIndex)}
-    `;
+/**
+ * List an index's type-checked Go references with the IDs of their targets (cindex export --format scip)
+ *
+ * A target is found by its declaring file and name, in any index (a
+ * dependency module indexed with cindex deps holds the targets in it).
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns References ordered by file and position
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listScipReferences = async (db: Pool, repoId: string): Promise<ScipReferenceRecord[]> => {
+  try {
+    const result = await db.query<ScipReferenceRecord>(
+      `SELECT r.file_path, r.line_number, r.column_number, r.target_name, r.access,
+              (SELECT s.symbol_id FROM code_symbols s
+               WHERE s.file_path = r.target_file AND s.symbol_name = r.target_name AND s.symbol_id IS NOT NULL
+               ORDER BY s.repo_id IS NOT DISTINCT FROM r.repo_id DESC
+               LIMIT 1) AS target_symbol_id
+       FROM go_references r
+       WHERE r.repo_id = $1
+       ORDER BY r.file_path, r.line_number, r.column_number`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listScipReferences', [repoId], err);
+  }
+};
 
-    params.push(limit);
+/**
+ * List the interfaces an index's Go types satisfy, with the IDs of the types (cindex export --format scip)
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to list
+ * @returns Implementations ordered by file, line, and interface
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listScipImplementations = async (db: Pool, repoId: string): Promise<ScipImplementationRecord[]> => {
+  try {
+    const result = await db.query<ScipImplementationRecord>(
+      `SELECT g.repo_id, g.file_path, g.line_number, g.type_name, g.type_package, g.interface_name,
+              g.interface_package, g.pointer_receiver,
+              (SELECT s.symbol_id FROM code_symbols s
+               WHERE s.file_path = g.file_path AND s.symbol_name = g.type_name AND s.repo_id = g.repo_id
+               LIMIT 1) AS type_symbol_id
+       FROM go_implementations g
+       WHERE g.repo_id = $1
+       ORDER BY g.file_path, g.line_number, g.interface_package, g.interface_name`,
+      [repoId]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listScipImplementations', [repoId], err);
+  }
+};
 
-    const result = await db.query<ResolvedSymbol>(sql, params);
+/**
+ * Measure the composition of an index (cindex stats --index)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns File and symbol totals, value frequencies, and language share
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const getIndexComposition = async (db: Pool, repoId?: string): Promise<IndexComposition> => {
+  const params = repoId ? [repoId] : [];
+  const where = (alias: string): string => (repoId ? `WHERE ${alias}.repo_id = $1` : '');
+  const toFrequencies = (rows: { value: string; count: string }[]): ValueFrequency[] =>
+    rows.map((row) => ({ value: parseInt(row.value, 10), count: parseInt(row.count, 10) }));
+
+  try {
+    const [symbolsPerFile, complexity, lines, languages] = await Promise.all([
+      db.query<{ value: string; count: string }>(
+        `SELECT symbols AS value, COUNT(*) AS count
+         FROM (
+           SELECT f.file_path, COUNT(s.id) AS symbols
+           FROM code_files f
+           LEFT JOIN code_symbols s ON s.file_path = f.file_path
+           ${where('f')}
+           GROUP BY f.file_path
+         ) per_file
+         GROUP BY symbols
+         ORDER BY symbols`,
+        params
+      ),
+      db.query<{ value: string; count: string }>(
+        `SELECT (c.metadata->>'complexity')::int AS value, COUNT(*) AS count
+         FROM code_chunks c
+         WHERE c.chunk_type = 'function' AND c.metadata ? 'complexity'${repoId ? ' AND c.repo_id = $1' : ''}
+         GROUP BY 1
+         ORDER BY 1`,
+        params
+      ),
+      db.query<{ value: string; count: string }>(
+        `SELECT COALESCE(f.total_lines, 0) AS value, COUNT(*) AS count
+         FROM code_files f
+         ${where('f')}
+         GROUP BY 1
+         ORDER BY 1`,
+        params
+      ),
+      db.query<{ language: string; files: string; lines: string }>(
+        `SELECT f.language, COUNT(*) AS files, COALESCE(SUM(f.total_lines), 0) AS lines
+         FROM code_files f
+         ${where('f')}
+         GROUP BY f.language
+         ORDER BY COUNT(*) DESC, f.language`,
+        params
+      ),
+    ]);
+
+    const symbolsPerFileRows = toFrequencies(symbolsPerFile.rows);
+    return {
+      files: symbolsPerFileRows.reduce((sum, row) => sum + row.count, 0),
+      symbols: symbolsPerFileRows.reduce((sum, row) => sum + row.value * row.count, 0),
+      symbols_per_file: symbolsPerFileRows,
+      function_complexity: toFrequencies(complexity.rows),
+      file_lines: toFrequencies(lines.rows),
+      languages: languages.rows.map((row) => ({
+        language: row.language,
+        files: parseInt(row.files, 10),
+        lines: parseInt(row.lines, 10),
+      })),
+    };
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('getIndexComposition', [repoId], err);
+  }
+};
+
+/**
+ * Filters of listAnnotations
+ */
+export interface AnnotationFilter {
+  kinds: AnnotationKind[];
+  /** Markers as written, compared case-insensitively (TODO, FIXME, go:generate, ...) */
+  tags?: string[];
+  /** Assignee, compared case-insensitively; null for annotations without one */
+  assignee?: string | null;
+  /** Restrict to one index (default: all indexes) */
+  repoId?: string;
+}
+
+/**
+ * List annotations read from comments (cindex todos, deprecated, annotations)
+ * @param db - Database connection pool
+ * @param filter - Kinds and optional tag, assignee, and index filters
+ * @returns Annotations ordered by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listAnnotations = async (db: Pool, filter: AnnotationFilter): Promise<AnnotationRecord[]> => {
+  const conditions = ['kind = ANY($1::text[])'];
+  const params: unknown[] = [filter.kinds];
+  if (filter.tags && filter.tags.length > 0) {
+    params.push(filter.tags.map((tag) => tag.toLowerCase()));
+    conditions.push(`LOWER(tag) = ANY($${String(params.length)}::text[])`);
+  }
+  if (filter.assignee === null) {
+    conditions.push('assignee IS NULL');
+  } else if (filter.assignee !== undefined) {
+    params.push(filter.assignee);
+    conditions.push(`LOWER(assignee) = LOWER($${String(params.length)})`);
+  }
+  if (filter.repoId) {
+    params.push(filter.repoId);
+    conditions.push(`repo_id = $${String(params.length)}`);
+  }
 
+  try {
+    const result = await db.query<AnnotationRecord>(
+      `SELECT repo_id, file_path, kind, tag, assignee, text, line_number, column_number, byte_column,
+              symbol_name, symbol_type
+       FROM code_annotations
+       WHERE ${conditions.join(' AND ')}
+       ORDER BY repo_id, file_path, line_number, id`,
+      params
+    );
     return result.rows;
   } catch (error) {
     const err = error instanceof Error ? error : new Error(String(error));
-    throw new DatabaseQueryError('searchSymbols', [symbolName], err);
+    throw new DatabaseQueryError('listAnnotations', [filter], err);
+  }
+};
+
+/**
+ * Search string literals and comments for a piece of text (cindex search --scope strings|comments)
+ * @param db - Database connection pool
+ * @param text - Text to find, matched anywhere in a literal, case-insensitively
+ * @param options.kinds - Kinds of literal searched
+ * @param options.repoId - Restrict to one index (default: all indexes)
+ * @param options.limit - Most literals returned
+ * @returns Literals ordered by index, file, line, and column
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const searchLiterals = async (
+  db: Pool,
+  text: string,
+  options: { kinds: LiteralKind[]; repoId?: string; limit: number }
+): Promise<LiteralRecord[]> => {
+  // The text is matched literally: LIKE wildcards in it are escaped
+  const pattern = `%${text.replace(/[\\%_]/g, '\\$&')}%`;
+  const params: unknown[] = [pattern, options.kinds, options.limit];
+  if (options.repoId) params.push(options.repoId);
+
+  try {
+    const result = await db.query<LiteralRecord>(
+      `SELECT repo_id, file_path, kind, text, line_number, column_number, byte_column
+       FROM code_literals
+       WHERE text ILIKE $1 AND kind = ANY($2::text[])${options.repoId ? ' AND repo_id = $4' : ''}
+       ORDER BY repo_id, file_path, line_number, column_number
+       LIMIT $3`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('searchLiterals', [text, options], err);
+  }
+};
+
+/**
+ * List the values stored for a plugin metric (cindex plugins --metric)
+ * @param db - Database connection pool
+ * @param name - Metric name, as reported by its analyzer
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Values ordered highest first, then by index, file, and line
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listPluginMetrics = async (db: Pool, name: string, repoId?: string): Promise<PluginMetricRecord[]> => {
+  try {
+    const result = await db.query<PluginMetricRecord>(
+      `SELECT repo_id, file_path, analyzer, name, value, symbol_name, line_number
+       FROM code_plugin_metrics
+       WHERE name = $1${repoId ? ' AND repo_id = $2' : ''}
+       ORDER BY value DESC, repo_id, file_path, line_number NULLS FIRST, id`,
+      repoId ? [name, repoId] : [name]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listPluginMetrics', [name, repoId], err);
+  }
+};
+
+/**
+ * List indexed files with their line counts and symbols by kind (cindex stats --by)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Files ordered by index and path; generated files are left out
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileStats = async (db: Pool, repoId?: string): Promise<FileStatsRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<
+      Omit<FileStatsRecord, 'functions' | 'complexity_total'> & { functions: string; complexity_total: string }
+    >(
+      `SELECT f.repo_id, f.file_path, f.language, f.package_name, COALESCE(f.total_lines, 0) AS total_lines,
+              f.code_lines, f.comment_lines, f.blank_lines, f.is_test,
+              COALESCE(s.symbol_kinds, '{}'::jsonb) AS symbol_kinds,
+              COALESCE(s.functions, 0) AS functions, COALESCE(s.complexity_total, 0) AS complexity_total
+       FROM code_files f
+       LEFT JOIN LATERAL (
+         SELECT jsonb_object_agg(k.symbol_type, k.symbols) AS symbol_kinds,
+                SUM(k.functions) AS functions, SUM(k.complexity_total) AS complexity_total
+         FROM (
+           SELECT symbol_type, COUNT(*) AS symbols, COUNT(complexity) AS functions,
+                  COALESCE(SUM(complexity), 0) AS complexity_total
+           FROM code_symbols
+           WHERE file_path = f.file_path
+           GROUP BY symbol_type
+         ) k
+       ) s ON TRUE
+       WHERE NOT f.generated${repoId ? ' AND f.repo_id = $1' : ''}
+       ORDER BY f.repo_id, f.file_path`,
+      params
+    );
+    return result.rows.map((row) => ({
+      ...row,
+      functions: parseInt(row.functions, 10),
+      complexity_total: parseInt(row.complexity_total, 10),
+    }));
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileStats', [repoId], err);
+  }
+};
+
+/**
+ * Filters of listRelatedCandidates (one of names, ids, or tokens)
+ */
+export interface RelatedCandidateFilter {
+  /** Full names (Service.Login) or their last element (Login) */
+  names?: string[];
+  /** Structured symbol IDs (see @indexing/symbol-ids) */
+  ids?: string[];
+  /** Name tokens, any of which a symbol's name must have (see @indexing/tokenizer) */
+  tokens?: string[];
+  /** Restrict to one index (default: all indexes) */
+  repoId?: string;
+  limit?: number;
+}
+
+/**
+ * List symbols considered as related to another (cindex related)
+ * @param db - Database connection pool
+ * @param filter - Names, IDs, or tokens, and optional index and limit
+ * @returns Symbols with the most tokens in common first (tokens), else exported first, by index, file, and line;
+ *   subtests are left out
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listRelatedCandidates = async (
+  db: Pool,
+  filter: RelatedCandidateFilter
+): Promise<RelatedCandidateRecord[]> => {
+  const conditions = ["strpos(symbol_name, '/') = 0"];
+  const params: unknown[] = [];
+  let order = "CASE WHEN scope = 'exported' THEN 0 ELSE 1 END, repo_id, file_path, line_number";
+  if (filter.names) {
+    params.push(filter.names);
+    const names = `$${String(params.length)}::text[]`;
+    conditions.push(`(symbol_name = ANY(${names}) OR regexp_replace(symbol_name, '^.*\\.', '') = ANY(${names}))`);
+  }
+  if (filter.ids) {
+    params.push(filter.ids);
+    conditions.push(`symbol_id = ANY($${String(params.length)}::text[])`);
+  }
+  if (filter.tokens) {
+    params.push(filter.tokens);
+    const tokens = `$${String(params.length)}::text[]`;
+    conditions.push(`name_tokens && ${tokens}`);
+    order = `cardinality(ARRAY(SELECT unnest(name_tokens) INTERSECT SELECT unnest(${tokens}))) DESC,
+              cardinality(name_tokens), ${order}`;
+  }
+  if (filter.repoId) {
+    params.push(filter.repoId);
+    conditions.push(`repo_id = $${String(params.length)}`);
+  }
+  params.push(filter.limit ?? 1000);
+
+  try {
+    const result = await db.query<RelatedCandidateRecord>(
+      `SELECT repo_id, symbol_name, symbol_type, file_path, line_number, symbol_id, name_tokens
+       FROM code_symbols
+       WHERE ${conditions.join(' AND ')}
+       ORDER BY ${order}
+       LIMIT $${String(params.length)}`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listRelatedCandidates', [filter], err);
+  }
+};
+
+/**
+ * Count the indexed files mentioning each of some names as a whole word (cindex related)
+ * @param db - Database connection pool
+ * @param names - Identifiers
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Files per name
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const countFilesMentioning = async (
+  db: Pool,
+  names: string[],
+  repoId?: string
+): Promise<Map<string, number>> => {
+  if (names.length === 0) return new Map();
+  try {
+    // \y is a word boundary; $ is the only regex character identifiers hold
+    const patterns = names.map((name) => `\\y${name.replaceAll('$', '\\$')}\\y`);
+    const params = repoId ? [names, patterns, repoId] : [names, patterns];
+    const result = await db.query<{ name: string; files: number }>(
+      `SELECT n.name,
+              (SELECT COUNT(*)::int FROM code_contents c
+               WHERE c.content ~ n.pattern${repoId ? ' AND c.repo_id = $3' : ''}) AS files
+       FROM unnest($1::text[], $2::text[]) AS n(name, pattern)`,
+      params
+    );
+    return new Map(result.rows.map((row) => [row.name, row.files]));
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('countFilesMentioning', [names.length, repoId], err);
+  }
+};
+
+/**
+ * List the functions of the Go call graph around a function (cindex related)
+ *
+ * The graph is undirected: a function's neighbors are its callers and
+ * callees. Listed are the neighbors and the functions sharing one with it;
+ * calls to imported packages and dynamic calls are left out.
+ *
+ * @param db - Database connection pool
+ * @param name - Function, or Receiver.Method
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Functions ordered by name
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listCallNeighbors = async (db: Pool, name: string, repoId?: string): Promise<CallNeighborRecord[]> => {
+  const repo = repoId ? ' AND repo_id = $2' : '';
+  try {
+    const result = await db.query<CallNeighborRecord>(
+      `WITH edges AS (
+         SELECT caller_name AS name, callee_name AS neighbor FROM go_calls
+         WHERE call_kind IN ('function', 'method')${repo}
+         UNION
+         SELECT callee_name, caller_name FROM go_calls
+         WHERE call_kind IN ('function', 'method')${repo}
+       ),
+       around AS (SELECT neighbor FROM edges WHERE name = $1 AND neighbor <> $1)
+       SELECT e.name,
+              bool_or(e.neighbor = $1) AS adjacent,
+              (COUNT(*) FILTER (WHERE e.neighbor IN (SELECT neighbor FROM around)))::int AS shared,
+              COUNT(*)::int AS degree
+       FROM edges e
+       WHERE e.name <> $1
+         AND e.name IN (SELECT neighbor FROM around
+                        UNION SELECT n.name FROM edges n JOIN around a ON a.neighbor = n.neighbor)
+       GROUP BY e.name
+       ORDER BY e.name`,
+      repoId ? [name, repoId] : [name]
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listCallNeighbors', [name, repoId], err);
+  }
+};
+
+/**
+ * List indexed files with their license (cindex licenses)
+ * @param db - Database connection pool
+ * @param repoId - Restrict to one index (default: all indexes)
+ * @returns Files ordered by index and path; generated files are left out
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const listFileLicenses = async (db: Pool, repoId?: string): Promise<FileLicenseRecord[]> => {
+  try {
+    const params = repoId ? [repoId] : [];
+    const result = await db.query<FileLicenseRecord>(
+      `SELECT repo_id, file_path, language, license, license_source
+       FROM code_files
+       WHERE NOT generated${repoId ? ' AND repo_id = $1' : ''}
+       ORDER BY repo_id, file_path`,
+      params
+    );
+    return result.rows;
+  } catch (error) {
+    const err = error instanceof Error ? error : new Error(String(error));
+    throw new DatabaseQueryError('listFileLicenses', [repoId], err);
   }
 };
 
@@ -677,7 +3164,7 @@ export const searchAPIContracts = async (
       WHERE embedding IS NOT NULL
         AND 1 - (embedding <=> $1::vector) >= ${String(similarityThreshold)}
         ${whereClause}
-      ORDER BY similarity DESC
+      ORDER BY similarity DESC, endpoint_path, http_method
       LIMIT ${String(limit)}
     `;
 
@@ -835,8 +3322,10 @@ export const findCrossWorkspaceUsages = async (
         usage.file_count++;
       }
 
-      // Sort by usage frequency (most used packages first)
-      return Array.from(workspaceMap.values()).sort((a, b) => b.total_imports - a.total_imports);
+      // Sort by usage frequency (most used packages first, ties by workspace)
+      return Array.from(workspaceMap.values()).sort(
+        (a, b) => b.total_imports - a.total_imports || compareStrings(a.workspace_id, b.workspace_id)
+      );
     }
 
     // If only workspaceId, return empty (not yet implemented)
@@ -907,7 +3396,7 @@ export const findCrossServiceCalls = async (
       FROM cross_repo_dependencies
       ${whereClause}
       GROUP BY source_service_id, target_service_id, endpoint_path
-      ORDER BY call_count DESC
+      ORDER BY call_count DESC, source_service_id, target_service_id, endpoint_path
     `;
 
     const result = await db.query<{
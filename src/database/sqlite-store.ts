@@ -0,0 +1,223 @@
+/**
+ * SQLite store: symbols and doc comments in one file, searched with FTS5
+ *
+ * Each symbol is a row of `symbols` and a row of the FTS5 table `symbol_docs`
+ * holding the words of its name and its doc comment, stemmed by the porter
+ * tokenizer like PostgreSQL's english configuration stems doc_tsv. The file
+ * is opened in WAL mode, so any number of CLI invocations and servers read it
+ * while an indexing run rewrites an index, and writers wait for each other.
+ *
+ * node:sqlite is loaded only when the store is opened, so Node versions
+ * without it run cindex unless STORE_BACKEND=sqlite is set.
+ */
+import * as fs from 'node:fs/promises';
+import * as path from 'node:path';
+import type { DatabaseSync, SQLInputValue } from 'node:sqlite';
+
+import { StoreError } from '@utils/errors';
+import { identifierWords } from '@utils/unicode';
+import { type DocMatchRecord } from '@/types/database';
+import { type DocSearchOptions, type Store, type StoredIndex, type StoredSymbol } from '@/types/store';
+
+/** How long a writer waits for another process's write to finish */
+const BUSY_TIMEOUT_MS = 30000;
+
+/** Version of the schema below (PRAGMA user_version) */
+const SCHEMA_VERSION = 1;
+
+const SCHEMA = `
+  CREATE TABLE IF NOT EXISTS indexes (
+    repo_id TEXT PRIMARY KEY,
+    searched_by_default INTEGER NOT NULL,
+    synced_at TEXT NOT NULL
+  );
+  CREATE TABLE IF NOT EXISTS symbols (
+    id INTEGER PRIMARY KEY,
+    repo_id TEXT NOT NULL,
+    symbol_name TEXT NOT NULL,
+    symbol_type TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    line_number INTEGER NOT NULL,
+    doc_comment TEXT,
+    exported INTEGER NOT NULL
+  );
+  CREATE INDEX IF NOT EXISTS symbols_repo ON symbols (repo_id);
+  CREATE VIRTUAL TABLE IF NOT EXISTS symbol_docs USING fts5(words, tokenize = 'porter unicode61');
+`;
+
+/**
+ * Words left out of searches, as PostgreSQL's english configuration does
+ */
+const STOP_WORDS = new Set([
+  'a',
+  'an',
+  'and',
+  'are',
+  'as',
+  'at',
+  'be',
+  'by',
+  'for',
+  'from',
+  'if',
+  'in',
+  'is',
+  'it',
+  'of',
+  'on',
+  'or',
+  'that',
+  'the',
+  'this',
+  'to',
+  'was',
+  'when',
+  'with',
+]);
+
+/**
+ * FTS5 queries matching any and every word of a search
+ *
+ * Words are quoted, so FTS5 operators and column filters in the text are read as words.
+ *
+ * @param text - Words to look for
+ * @returns Queries, or null if the text has no words to look for
+ */
+export const docQueries = (text: string): { any: string; every: string } | null => {
+  const words = (text.toLowerCase().match(/[\p{L}\p{N}]+/gu) ?? []).filter((word) => !STOP_WORDS.has(word));
+  if (words.length === 0) return null;
+  const quoted = [...new Set(words)].map((word) => `"${word}"`);
+  return { any: quoted.join(' OR '), every: quoted.join(' AND ') };
+};
+
+/**
+ * Store in a SQLite file
+ */
+export class SqliteStore implements Store {
+  public readonly backend = 'sqlite';
+
+  constructor(private readonly database: DatabaseSync) {}
+
+  public replaceIndex = async (index: StoredIndex, pages: AsyncIterable<StoredSymbol[]>): Promise<number> => {
+    const insertSymbol = this.database.prepare(
+      `INSERT INTO symbols (repo_id, symbol_name, symbol_type, file_path, line_number, doc_comment, exported)
+       VALUES (?, ?, ?, ?, ?, ?, ?)`
+    );
+    const insertWords = this.database.prepare('INSERT INTO symbol_docs (rowid, words) VALUES (?, ?)');
+    let count = 0;
+
+    // Readers see the previous symbols of the index until the new ones are committed
+    this.database.exec('BEGIN IMMEDIATE');
+    try {
+      this.removeIndex(index.repo_id);
+      for await (const page of pages) {
+        for (const symbol of page) {
+          const { lastInsertRowid } = insertSymbol.run(
+            index.repo_id,
+            symbol.symbol_name,
+            symbol.symbol_type,
+            symbol.file_path,
+            symbol.line_number,
+            symbol.doc_comment,
+            Number(symbol.exported)
+          );
+          const words = [...identifierWords(symbol.symbol_name), symbol.doc_comment ?? ''].join(' ');
+          insertWords.run(lastInsertRowid, words);
+        }
+        count += page.length;
+      }
+      this.database
+        .prepare('INSERT INTO indexes (repo_id, searched_by_default, synced_at) VALUES (?, ?, ?)')
+        .run(index.repo_id, Number(index.searched_by_default), new Date().toISOString());
+      this.database.exec('COMMIT');
+    } catch (error) {
+      this.database.exec('ROLLBACK');
+      throw error;
+    }
+    return count;
+  };
+
+  public deleteIndex = (repoId: string): Promise<void> => {
+    this.database.exec('BEGIN IMMEDIATE');
+    try {
+      this.removeIndex(repoId);
+      this.database.exec('COMMIT');
+    } catch (error) {
+      this.database.exec('ROLLBACK');
+      throw error;
+    }
+    return Promise.resolve();
+  };
+
+  public searchDocs = (text: string, options: DocSearchOptions = {}): Promise<DocMatchRecord[]> => {
+    const { repoId, limit = 20 } = options;
+    const queries = docQueries(text);
+    if (!queries) return Promise.resolve([]);
+
+    const scope = repoId ? 's.repo_id = ?' : 's.repo_id IN (SELECT repo_id FROM indexes WHERE searched_by_default = 1)';
+    const params: SQLInputValue[] = [queries.every, queries.any, ...(repoId ? [repoId] : []), limit];
+    const rows = this.database
+      .prepare(
+        `SELECT s.repo_id, s.symbol_name, s.symbol_type, s.file_path, s.line_number, s.doc_comment,
+                s.id IN (SELECT rowid FROM symbol_docs WHERE symbol_docs MATCH ?) AS complete,
+                -bm25(symbol_docs) AS rank
+         FROM symbol_docs JOIN symbols s ON s.id = symbol_docs.rowid
+         WHERE symbol_docs MATCH ? AND ${scope}
+         ORDER BY complete DESC, rank DESC, s.exported DESC, s.symbol_name
+         LIMIT ?`
+      )
+      .all(...params) as unknown as (Omit<DocMatchRecord, 'complete'> & { complete: number })[];
+    return Promise.resolve(rows.map((row) => ({ ...row, complete: row.complete === 1 })));
+  };
+
+  public close = (): Promise<void> => {
+    this.database.close();
+    return Promise.resolve();
+  };
+
+  /**
+   * Delete the rows of an index, inside the caller's transaction
+   */
+  private removeIndex = (repoId: string): void => {
+    this.database
+      .prepare('DELETE FROM symbol_docs WHERE rowid IN (SELECT id FROM symbols WHERE repo_id = ?)')
+      .run(repoId);
+    this.database.prepare('DELETE FROM symbols WHERE repo_id = ?').run(repoId);
+    this.database.prepare('DELETE FROM indexes WHERE repo_id = ?').run(repoId);
+  };
+}
+
+/**
+ * Open a SQLite store, creating the file and its tables on first use
+ *
+ * @param file - SQLite file
+ * @returns Store (caller must close it)
+ * @throws {StoreError} If node:sqlite is unavailable or the file is not a store
+ */
+export const openSqliteStore = async (file: string): Promise<SqliteStore> => {
+  let Database: typeof DatabaseSync;
+  try {
+    ({ DatabaseSync: Database } = await import('node:sqlite'));
+  } catch (error) {
+    throw new StoreError(`The sqlite store needs Node.js 22.13 or later (running ${process.version})`, error);
+  }
+
+  await fs.mkdir(path.dirname(file), { recursive: true });
+  let database: DatabaseSync | undefined;
+  try {
+    database = new Database(file);
+    database.exec(`PRAGMA busy_timeout = ${String(BUSY_TIMEOUT_MS)}`);
+    database.exec('PRAGMA journal_mode = WAL');
+    const { user_version: version } = database.prepare('PRAGMA user_version').get() as { user_version: number };
+    if (version > SCHEMA_VERSION) {
+      throw new Error(`schema version ${String(version)} is newer than this cindex reads (${String(SCHEMA_VERSION)})`);
+    }
+    database.exec(SCHEMA);
+    database.exec(`PRAGMA user_version = ${String(SCHEMA_VERSION)}`);
+    return new SqliteStore(database);
+  } catch (error) {
+    database?.close();
+    const reason = error instanceof Error ? error.message : String(error);
+    throw new StoreError(`Cannot open the sqlite store ${file}: ${reason}`, error);
+  }
+};
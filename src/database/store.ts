@@ -0,0 +1,109 @@
+/**
+ * Stores symbol and doc-comment search reads from (STORE_BACKEND)
+ *
+ * The postgres store searches code_symbols directly. The sqlite store keeps
+ * a copy of each index's symbols in one file (see @database/sqlite-store),
+ * rewritten from PostgreSQL at the end of every indexing run and removed
+ * with the index, so it always holds the generation the run published.
+ */
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { type Pool } from 'pg';
+
+import { isSearchedByDefault, listStoreSymbols, searchSymbolDocs } from '@database/queries';
+import { openSqliteStore } from '@database/sqlite-store';
+import { logger } from '@utils/logger';
+import { type StoreConfig } from '@/types/config';
+import { type DocMatchRecord } from '@/types/database';
+import { type DocSearchOptions, type Store, type StoredSymbol } from '@/types/store';
+
+/** SQLite file of the sqlite store when SQLITE_PATH is not set */
+export const DEFAULT_SQLITE_PATH = path.join(os.homedir(), '.cindex', 'store.db');
+
+/** Symbols read from PostgreSQL per page when a store is synced */
+const SYNC_PAGE_SIZE = 5000;
+
+/**
+ * Store searching PostgreSQL, where indexing writes every index
+ */
+export class PostgresStore implements Store {
+  public readonly backend = 'postgres';
+
+  constructor(private readonly db: Pool) {}
+
+  /** Indexing already wrote the symbols */
+  public replaceIndex = (): Promise<number> => Promise.resolve(0);
+
+  /** Deleting the repository already removed its symbols */
+  public deleteIndex = (): Promise<void> => Promise.resolve();
+
+  public searchDocs = (text: string, options: DocSearchOptions = {}): Promise<DocMatchRecord[]> =>
+    searchSymbolDocs(this.db, text, options);
+
+  public close = (): Promise<void> => Promise.resolve();
+}
+
+/**
+ * Open the configured store
+ *
+ * @param config - Store settings
+ * @param db - Database connection pool (searched by the postgres store)
+ * @returns Store (caller must close it)
+ * @throws {StoreError} If the sqlite store cannot be opened
+ */
+export const openStore = async (config: StoreConfig, db: Pool): Promise<Store> => {
+  if (config.backend === 'postgres') return new PostgresStore(db);
+  return openSqliteStore(path.resolve(config.sqlite_path ?? DEFAULT_SQLITE_PATH));
+};
+
+/**
+ * Symbols of an index, read from PostgreSQL a page at a time
+ */
+const symbolPages = async function* (db: Pool, repoId: string): AsyncGenerator<StoredSymbol[]> {
+  let after = 0;
+  for (;;) {
+    const rows = await listStoreSymbols(db, repoId, after, SYNC_PAGE_SIZE);
+    if (rows.length === 0) return;
+    after = rows[rows.length - 1].id;
+    yield rows;
+  }
+};
+
+/**
+ * Copy the symbols of an index to the configured store (end of an indexing run)
+ *
+ * @param config - Store settings
+ * @param db - Database connection pool
+ * @param repoId - Index the run wrote
+ * @returns Symbols written (0 for the postgres store)
+ */
+export const syncStore = async (config: StoreConfig, db: Pool, repoId: string): Promise<number> => {
+  if (config.backend === 'postgres') return 0;
+  const store = await openStore(config, db);
+  try {
+    const index = { repo_id: repoId, searched_by_default: await isSearchedByDefault(db, repoId) };
+    const count = await store.replaceIndex(index, symbolPages(db, repoId));
+    logger.debug('Store synced', { backend: store.backend, repo_id: repoId, symbols: count });
+    return count;
+  } finally {
+    await store.close();
+  }
+};
+
+/**
+ * Remove an index from the configured store (cindex delete, delete_repository)
+ *
+ * @param config - Store settings
+ * @param db - Database connection pool
+ * @param repoId - Index deleted
+ */
+export const removeFromStore = async (config: StoreConfig, db: Pool, repoId: string): Promise<void> => {
+  if (config.backend === 'postgres') return;
+  const store = await openStore(config, db);
+  try {
+    await store.deleteIndex(repoId);
+  } finally {
+    await store.close();
+  }
+};
@@ -9,19 +9,34 @@ import type pg from 'pg';
 
 import { CindexError } from '@utils/errors';
 import { logger } from '@utils/logger';
+import { identifierWords } from '@utils/unicode';
 import { type ParsedAPIEndpoint } from '@/types/api-parsing';
 import {
   type CodeChunk,
   type CodeFile,
   type CodeSymbol,
   type CrossRepoDependency,
+  type LintFindingRecord,
   type Repository,
   type Service,
   type Workspace,
   type WorkspaceAlias,
   type WorkspaceDependency,
 } from '@/types/database';
-import { type BatchInsertResult } from '@/types/indexing';
+import {
+  type Annotation,
+  type BatchInsertResult,
+  type GoCall,
+  type GoConstant,
+  type GoEmbedding,
+  type GoInstantiation,
+  type GoTypeFacts,
+  type GoTypeParameter,
+  type LastChange,
+  type SecretFinding,
+  type SourceLiteral,
+} from '@/types/indexing';
+import { type AnalyzerMetric } from '@/types/plugins';
 
 /**
  * Error thrown during database write operations with context information
@@ -80,8 +95,15 @@ export class DatabaseWriter {
       INSERT INTO code_files (
         repo_path, file_path, file_summary, summary_embedding, summary_tsv,
         language, total_lines, imports, exports, file_hash,
-        last_modified, repo_id, workspace_id, package_name, service_id
-      ) VALUES ($1, $2, $3, $4, to_tsvector('english', COALESCE($3, '')), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        last_modified, repo_id, workspace_id, package_name, service_id,
+        parse_error, parse_error_line, parse_error_column, parse_partial, encoding, generated,
+        parse_error_byte_column, chunk_count, chunk_checksum, license, license_source, build_constraint,
+        file_size, file_mtime_ms, code_lines, comment_lines, blank_lines, is_test
+      ) VALUES (
+        $1, $2, $3, $4, to_tsvector('english', COALESCE($3, '')), $5, $6, $7, $8, $9,
+        $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28,
+        $29, $30, $31, $32
+      )
       ON CONFLICT (file_path) DO UPDATE SET
         file_summary = EXCLUDED.file_summary,
         summary_embedding = EXCLUDED.summary_embedding,
@@ -95,6 +117,25 @@ export class DatabaseWriter {
         workspace_id = EXCLUDED.workspace_id,
         package_name = EXCLUDED.package_name,
         service_id = EXCLUDED.service_id,
+        parse_error = EXCLUDED.parse_error,
+        parse_error_line = EXCLUDED.parse_error_line,
+        parse_error_column = EXCLUDED.parse_error_column,
+        parse_partial = EXCLUDED.parse_partial,
+        encoding = EXCLUDED.encoding,
+        generated = EXCLUDED.generated,
+        parse_error_byte_column = EXCLUDED.parse_error_byte_column,
+        chunk_count = EXCLUDED.chunk_count,
+        chunk_checksum = EXCLUDED.chunk_checksum,
+        license = EXCLUDED.license,
+        license_source = EXCLUDED.license_source,
+        build_constraint = EXCLUDED.build_constraint,
+        file_size = EXCLUDED.file_size,
+        file_mtime_ms = EXCLUDED.file_mtime_ms,
+        code_lines = EXCLUDED.code_lines,
+        comment_lines = EXCLUDED.comment_lines,
+        blank_lines = EXCLUDED.blank_lines,
+        is_test = EXCLUDED.is_test,
+        quarantined_at = NULL,
         indexed_at = NOW()
     `;
 
@@ -114,6 +155,24 @@ export class DatabaseWriter {
         file.workspace_id ?? null,
         file.package_name ?? null,
         file.service_id ?? null,
+        file.parse_error ?? null,
+        file.parse_error_line ?? null,
+        file.parse_error_column ?? null,
+        file.parse_partial ?? false,
+        file.encoding ?? null,
+        file.generated ?? false,
+        file.parse_error_byte_column ?? null,
+        file.chunk_count ?? null,
+        file.chunk_checksum ?? null,
+        file.license ?? null,
+        file.license_source ?? null,
+        file.build_constraint ?? null,
+        file.file_size ?? null,
+        file.file_mtime_ms ?? null,
+        file.code_lines ?? null,
+        file.comment_lines ?? null,
+        file.blank_lines ?? null,
+        file.is_test ?? false,
       ]);
 
       logger.debug('File inserted', { file: file.file_path });
@@ -320,9 +379,9 @@ export class DatabaseWriter {
     let paramIndex = 1;
 
     for (const symbol of symbols) {
-      placeholders.push(
-        `($${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)})`
-      );
+      const columns = Array.from({ length: 18 }, () => `$${String(paramIndex++)}`);
+      // doc_tsv: the words of the name and the doc comment, so prose finds symbols whose names it does not spell
+      placeholders.push(`(${columns.join(', ')}, to_tsvector('english', $${String(paramIndex++)}))`);
 
       values.push(
         symbol.repo_path,
@@ -334,7 +393,16 @@ export class DatabaseWriter {
         symbol.embedding ? `[${symbol.embedding.join(',')}]` : null,
         symbol.repo_id ?? null,
         symbol.workspace_id ?? null,
-        symbol.package_name ?? null
+        symbol.package_name ?? null,
+        symbol.end_line ?? null,
+        symbol.complexity ?? null,
+        symbol.cognitive_complexity ?? null,
+        symbol.clone_signature ?? null,
+        symbol.clone_tokens ?? null,
+        symbol.doc_comment ?? null,
+        symbol.name_tokens ?? null,
+        symbol.symbol_id ?? null,
+        [...identifierWords(symbol.symbol_name), symbol.doc_comment ?? ''].join(' ')
       );
     }
 
@@ -342,7 +410,10 @@ export class DatabaseWriter {
       INSERT INTO code_symbols (
         repo_path, symbol_name, symbol_type, file_path,
         line_number, definition, embedding,
-        repo_id, workspace_id, package_name
+        repo_id, workspace_id, package_name,
+        end_line, complexity, cognitive_complexity,
+        clone_signature, clone_tokens,
+        doc_comment, name_tokens, symbol_id, doc_tsv
       ) VALUES ${placeholders.join(', ')}
       ON CONFLICT DO NOTHING
     `;
@@ -723,6 +794,35 @@ export class DatabaseWriter {
     await this.pool.query(sql, values);
   };
 
+  /**
+   * Replace the Go modules recorded for a repository and the dependencies between them
+   *
+   * Modules are workspaces with metadata.language 'go'; other workspaces of
+   * the repository are kept.
+   *
+   * @param repoId - Repository the modules belong to
+   * @param modules - Modules from the latest index (none clears them)
+   * @param dependencies - Dependencies between the modules
+   */
+  public replaceGoModules = async (
+    repoId: string,
+    modules: Omit<Workspace, 'id' | 'indexed_at'>[],
+    dependencies: Omit<WorkspaceDependency, 'id' | 'indexed_at'>[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query(
+        "DELETE FROM workspace_dependencies WHERE repo_id = $1 AND dependency_type = 'go_module'",
+        [repoId]
+      );
+      await this.pool.query("DELETE FROM workspaces WHERE repo_id = $1 AND metadata->>'language' = 'go'", [repoId]);
+      await this.insertWorkspaceBatch(modules);
+      await this.insertWorkspaceDependencyBatch(dependencies);
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('workspaces', `replace Go modules of ${repoId}`, err);
+    }
+  };
+
   /**
    * Batch insert services for microservice architecture
    *
@@ -940,6 +1040,619 @@ export class DatabaseWriter {
     await this.pool.query(sql, values);
   };
 
+  /**
+   * Record an interrupted-run checkpoint in repository metadata
+   *
+   * Stored as metadata.checkpoint so the index is visibly incomplete. The next
+   * run replaces repository metadata (insertRepository), which clears it.
+   *
+   * @param repoId - Repository ID
+   * @param checkpoint - Checkpoint details
+   */
+  public updateRepositoryCheckpoint = async (repoId: string, checkpoint: Record<string, unknown>): Promise<void> => {
+    const sql = `
+      UPDATE repositories
+      SET metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('checkpoint', $2::jsonb),
+          last_updated = NOW()
+      WHERE repo_id = $1
+    `;
+
+    try {
+      await this.pool.query(sql, [repoId, JSON.stringify(checkpoint)]);
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('repositories', `update checkpoint for ${repoId}`, err);
+    }
+  };
+
+  /**
+   * Replace the secret scanner findings of one file
+   *
+   * @param file - File the findings belong to
+   * @param findings - Findings from the latest scan (empty clears the file)
+   */
+  public replaceSecretFindings = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    findings: SecretFinding[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM secret_findings WHERE file_path = $1', [file.file_path]);
+      if (findings.length === 0) return;
+
+      // Build multi-row INSERT statement
+      const placeholders: string[] = [];
+      const values: unknown[] = [];
+
+      let paramIndex = 1;
+
+      for (const finding of findings) {
+        placeholders.push(
+          `($${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)})`
+        );
+
+        values.push(
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          finding.rule,
+          finding.line,
+          finding.column,
+          finding.byte_column,
+          finding.redacted,
+          finding.fingerprint
+        );
+      }
+
+      const sql = `
+        INSERT INTO secret_findings (
+          repo_id, repo_path, file_path, rule, line_number,
+          column_number, byte_column, redacted, fingerprint
+        ) VALUES ${placeholders.join(', ')}
+      `;
+
+      await this.pool.query(sql, values);
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('secret_findings', `replace findings for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Store the content of one file for regex search
+   *
+   * @param file - File the content was read from
+   * @param content - Content as read for indexing (line endings are stored as LF)
+   */
+  public replaceFileContent = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    content: string
+  ): Promise<void> => {
+    try {
+      await this.pool.query(
+        `INSERT INTO code_contents (file_path, repo_id, repo_path, content)
+         VALUES ($1, $2, $3, $4)
+         ON CONFLICT (file_path) DO UPDATE
+         SET repo_id = EXCLUDED.repo_id, repo_path = EXCLUDED.repo_path, content = EXCLUDED.content`,
+        [file.file_path, file.repo_id, file.repo_path, content.replace(/\r\n?/g, '\n')]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_contents', `store content of ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the Go calls made in one file
+   *
+   * @param file - File the calls are made in
+   * @param calls - Calls from the latest parse (empty clears the file)
+   */
+  public replaceGoCalls = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    calls: GoCall[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM go_calls WHERE file_path = $1', [file.file_path]);
+      if (calls.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO go_calls (
+           repo_id, repo_path, file_path, caller_name, caller_line, callee_name,
+           callee_package, callee_qualifier, call_kind, line_number, column_number
+         )
+         SELECT $1, $2, $3, *
+         FROM unnest($4::text[], $5::int[], $6::text[], $7::text[], $8::text[], $9::text[], $10::int[], $11::int[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          calls.map((call) => call.caller),
+          calls.map((call) => call.caller_line),
+          calls.map((call) => call.callee),
+          calls.map((call) => call.callee_package),
+          calls.map((call) => call.qualifier),
+          calls.map((call) => call.kind),
+          calls.map((call) => call.line),
+          calls.map((call) => call.column),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('go_calls', `replace calls for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the Go constants declared in one file
+   *
+   * @param file - File the constants are declared in
+   * @param constants - Constants from the latest parse (empty clears the file)
+   */
+  public replaceGoConstants = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    constants: GoConstant[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM go_constants WHERE file_path = $1', [file.file_path]);
+      if (constants.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO go_constants (
+           repo_id, repo_path, file_path, constant_name, type_name, value,
+           expression, iota, group_line, line_number, doc
+         )
+         SELECT $1, $2, $3, *
+         FROM unnest($4::text[], $5::text[], $6::text[], $7::text[], $8::int[], $9::int[], $10::int[], $11::text[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          constants.map((constant) => constant.name),
+          constants.map((constant) => constant.type),
+          constants.map((constant) => constant.value),
+          constants.map((constant) => constant.expression),
+          constants.map((constant) => constant.iota),
+          constants.map((constant) => constant.group_line),
+          constants.map((constant) => constant.line),
+          constants.map((constant) => constant.doc),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('go_constants', `replace constants for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the type parameters and instantiations recorded for a Go file
+   *
+   * @param file - File the generics are declared and instantiated in
+   * @param parameters - Type parameters from the latest parse
+   * @param instantiations - Explicit instantiations from the latest parse (both empty clear the file)
+   */
+  public replaceGoGenerics = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    parameters: GoTypeParameter[],
+    instantiations: GoInstantiation[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM go_type_parameters WHERE file_path = $1', [file.file_path]);
+      await this.pool.query('DELETE FROM go_instantiations WHERE file_path = $1', [file.file_path]);
+
+      if (parameters.length > 0) {
+        await this.pool.query(
+          `INSERT INTO go_type_parameters (
+             repo_id, repo_path, file_path, symbol_name, symbol_kind, parameter_name,
+             constraint_text, position, line_number
+           )
+           SELECT $1, $2, $3, *
+           FROM unnest($4::text[], $5::text[], $6::text[], $7::text[], $8::int[], $9::int[])`,
+          [
+            file.repo_id,
+            file.repo_path,
+            file.file_path,
+            parameters.map((parameter) => parameter.symbol),
+            parameters.map((parameter) => parameter.symbol_kind),
+            parameters.map((parameter) => parameter.name),
+            parameters.map((parameter) => parameter.constraint),
+            parameters.map((parameter) => parameter.position),
+            parameters.map((parameter) => parameter.line),
+          ]
+        );
+      }
+
+      // Type argument lists vary in length, so they travel as JSON
+      if (instantiations.length > 0) {
+        await this.pool.query(
+          `INSERT INTO go_instantiations (
+             repo_id, repo_path, file_path, target_name, target_package, type_arguments,
+             context_name, line_number, column_number
+           )
+           SELECT $1, $2, $3, target, package, ARRAY(SELECT jsonb_array_elements_text(arguments)), context, line, col
+           FROM unnest($4::text[], $5::text[], $6::jsonb[], $7::text[], $8::int[], $9::int[])
+             AS i(target, package, arguments, context, line, col)`,
+          [
+            file.repo_id,
+            file.repo_path,
+            file.file_path,
+            instantiations.map((instantiation) => instantiation.target),
+            instantiations.map((instantiation) => instantiation.target_package),
+            instantiations.map((instantiation) => JSON.stringify(instantiation.type_arguments)),
+            instantiations.map((instantiation) => instantiation.context),
+            instantiations.map((instantiation) => instantiation.line),
+            instantiations.map((instantiation) => instantiation.column),
+          ]
+        );
+      }
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('go_type_parameters', `replace generics for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the embedded types recorded for a Go file
+   *
+   * @param file - File the structs and interfaces are declared in
+   * @param embeddings - Embedded types from the latest parse (empty clears the file)
+   */
+  public replaceGoEmbeddings = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    embeddings: GoEmbedding[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM go_embeddings WHERE file_path = $1', [file.file_path]);
+      if (embeddings.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO go_embeddings (
+           repo_id, repo_path, file_path, type_name, type_kind, embedded_name,
+           embedded_package, pointer, line_number, column_number
+         )
+         SELECT $1, $2, $3, *
+         FROM unnest($4::text[], $5::text[], $6::text[], $7::text[], $8::boolean[], $9::int[], $10::int[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          embeddings.map((embedding) => embedding.type_name),
+          embeddings.map((embedding) => embedding.type_kind),
+          embeddings.map((embedding) => embedding.embedded_name),
+          embeddings.map((embedding) => embedding.embedded_package),
+          embeddings.map((embedding) => embedding.pointer),
+          embeddings.map((embedding) => embedding.line),
+          embeddings.map((embedding) => embedding.column),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('go_embeddings', `replace embeddings for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the annotations read from one file's comments
+   *
+   * @param file - File the annotations are in
+   * @param annotations - Annotations from the latest read (empty clears the file)
+   */
+  public replaceAnnotations = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    annotations: Annotation[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM code_annotations WHERE file_path = $1', [file.file_path]);
+      if (annotations.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO code_annotations (
+           repo_id, repo_path, file_path, kind, tag, assignee, text,
+           line_number, column_number, byte_column, symbol_name, symbol_type
+         )
+         SELECT $1, $2, $3, *
+         FROM unnest($4::text[], $5::text[], $6::text[], $7::text[], $8::int[], $9::int[], $10::int[],
+                     $11::text[], $12::text[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          annotations.map((annotation) => annotation.kind),
+          annotations.map((annotation) => annotation.tag),
+          annotations.map((annotation) => annotation.assignee),
+          annotations.map((annotation) => annotation.text),
+          annotations.map((annotation) => annotation.line),
+          annotations.map((annotation) => annotation.column),
+          annotations.map((annotation) => annotation.byte_column),
+          annotations.map((annotation) => annotation.symbol_name),
+          annotations.map((annotation) => annotation.symbol_type),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_annotations', `replace annotations for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the string literals and comments of one file
+   *
+   * @param file - File the literals are in
+   * @param literals - Literals from the latest read (empty clears the file)
+   */
+  public replaceLiterals = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    literals: SourceLiteral[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM code_literals WHERE file_path = $1', [file.file_path]);
+      if (literals.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO code_literals (repo_id, repo_path, file_path, kind, text, line_number, column_number, byte_column)
+         SELECT $1, $2, $3, * FROM unnest($4::text[], $5::text[], $6::int[], $7::int[], $8::int[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          literals.map((literal) => literal.kind),
+          literals.map((literal) => literal.text),
+          literals.map((literal) => literal.line),
+          literals.map((literal) => literal.column),
+          literals.map((literal) => literal.byte_column),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_literals', `replace literals for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the metrics analyzer plugins reported for one file
+   *
+   * @param file - File measured
+   * @param metrics - Metrics from the latest run (empty clears the file)
+   */
+  public replacePluginMetrics = async (
+    file: { repo_id: string | null; repo_path: string; file_path: string },
+    metrics: AnalyzerMetric[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM code_plugin_metrics WHERE file_path = $1', [file.file_path]);
+      if (metrics.length === 0) return;
+
+      await this.pool.query(
+        `INSERT INTO code_plugin_metrics
+           (repo_id, repo_path, file_path, analyzer, name, value, symbol_name, line_number)
+         SELECT $1, $2, $3, * FROM unnest($4::text[], $5::text[], $6::float8[], $7::text[], $8::int[])`,
+        [
+          file.repo_id,
+          file.repo_path,
+          file.file_path,
+          metrics.map((metric) => metric.analyzer),
+          metrics.map((metric) => metric.name),
+          metrics.map((metric) => metric.value),
+          metrics.map((metric) => metric.symbol_name ?? null),
+          metrics.map((metric) => metric.line ?? null),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_plugin_metrics', `replace plugin metrics for ${file.file_path}`, err);
+    }
+  };
+
+  /**
+   * Replace the imported findings of one linting tool
+   *
+   * @param tool - Tool that wrote the report (its previous findings are removed)
+   * @param repoId - Index the report covers (null: all indexes)
+   * @param findings - Findings matched to indexed files
+   */
+  public replaceLintFindings = async (
+    tool: string,
+    repoId: string | null,
+    findings: (Omit<LintFindingRecord, 'symbol_name' | 'imported_at'> & { repo_path: string })[]
+  ): Promise<void> => {
+    try {
+      await this.pool.query('DELETE FROM lint_findings WHERE tool = $1 AND ($2::text IS NULL OR repo_id = $2)', [
+        tool,
+        repoId,
+      ]);
+
+      for (let i = 0; i < findings.length; i += DatabaseWriter.DEFAULT_BATCH_SIZE) {
+        // Build multi-row INSERT statement
+        const placeholders: string[] = [];
+        const values: unknown[] = [];
+
+        let paramIndex = 1;
+
+        for (const finding of findings.slice(i, i + DatabaseWriter.DEFAULT_BATCH_SIZE)) {
+          placeholders.push(
+            `($${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)}, $${String(paramIndex++)})`
+          );
+
+          values.push(
+            finding.repo_id,
+            finding.repo_path,
+            finding.file_path,
+            finding.line_number,
+            finding.column_number,
+            finding.end_line,
+            finding.tool,
+            finding.linter,
+            finding.rule,
+            finding.severity,
+            finding.message
+          );
+        }
+
+        const sql = `
+          INSERT INTO lint_findings (
+            repo_id, repo_path, file_path, line_number, column_number,
+            end_line, tool, linter, rule, severity, message
+          ) VALUES ${placeholders.join(', ')}
+        `;
+
+        await this.pool.query(sql, values);
+      }
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('lint_findings', `replace ${tool} findings`, err);
+    }
+  };
+
+  /**
+   * Replace the type facts of one index (cindex index --typed)
+   *
+   * Go methods are indexed from syntax in every run; here their definitions
+   * become the type checker's signatures, and methods of files without
+   * method symbols yet (indexed by an older version) are added.
+   *
+   * @param repoId - Index the facts belong to
+   * @param repoPath - Repository root
+   * @param facts - Facts from go/packages on indexed files
+   */
+  public replaceGoTypeFacts = async (repoId: string, repoPath: string, facts: GoTypeFacts): Promise<void> => {
+    const { implementations, references } = facts;
+    try {
+      await this.pool.query('DELETE FROM go_implementations WHERE repo_id = $1', [repoId]);
+      await this.pool.query('DELETE FROM go_references WHERE repo_id = $1', [repoId]);
+
+      // A receiver's method name is unique within a file, so file and name identify the syntax-indexed symbol
+      const typed = facts.methods.map((method) => ({ ...method, symbol_name: `${method.receiver}.${method.name}` }));
+      const updated = await this.pool.query<{ file_path: string; symbol_name: string }>(
+        `UPDATE code_symbols s SET definition = v.signature
+         FROM unnest($2::text[], $3::text[], $4::text[]) AS v(file_path, symbol_name, signature)
+         WHERE s.repo_id = $1 AND s.symbol_type = 'method'
+           AND s.file_path = v.file_path AND s.symbol_name = v.symbol_name
+         RETURNING s.file_path, s.symbol_name`,
+        [
+          repoId,
+          typed.map((method) => method.file_path),
+          typed.map((method) => method.symbol_name),
+          typed.map((method) => method.signature),
+        ]
+      );
+      const indexed = new Set(updated.rows.map((row) => `${row.file_path}\0${row.symbol_name}`));
+
+      const methods = typed
+        .filter((method) => !indexed.has(`${method.file_path}\0${method.symbol_name}`))
+        .map((method) => ({
+          repo_path: repoPath,
+          symbol_name: method.symbol_name,
+          symbol_type: 'method' as const,
+          file_path: method.file_path,
+          line_number: method.line_number,
+          end_line: method.end_line,
+          definition: method.signature,
+          embedding: null,
+          repo_id: repoId,
+          workspace_id: null,
+          package_name: null,
+          service_id: null,
+        }));
+      for (let i = 0; i < methods.length; i += DatabaseWriter.DEFAULT_BATCH_SIZE) {
+        await this.insertSymbolBatch(methods.slice(i, i + DatabaseWriter.DEFAULT_BATCH_SIZE));
+      }
+
+      await this.pool.query(
+        `INSERT INTO go_implementations (
+           repo_id, repo_path, file_path, line_number, type_name, type_package,
+           interface_name, interface_package, pointer_receiver
+         )
+         SELECT $1, $2, *
+         FROM unnest($3::text[], $4::int[], $5::text[], $6::text[], $7::text[], $8::text[], $9::bool[])`,
+        [
+          repoId,
+          repoPath,
+          implementations.map((row) => row.file_path),
+          implementations.map((row) => row.line_number),
+          implementations.map((row) => row.type_name),
+          implementations.map((row) => row.type_package),
+          implementations.map((row) => row.interface_name),
+          implementations.map((row) => row.interface_package),
+          implementations.map((row) => row.pointer_receiver),
+        ]
+      );
+
+      await this.pool.query(
+        `INSERT INTO go_references (
+           repo_id, repo_path, file_path, line_number, column_number,
+           target_name, target_package, target_file, target_line, access
+         )
+         SELECT $1, $2, *
+         FROM unnest($3::text[], $4::int[], $5::int[], $6::text[], $7::text[], $8::text[], $9::int[], $10::text[])`,
+        [
+          repoId,
+          repoPath,
+          references.map((row) => row.file_path),
+          references.map((row) => row.line_number),
+          references.map((row) => row.column_number),
+          references.map((row) => row.target_name),
+          references.map((row) => row.target_package),
+          references.map((row) => row.target_file),
+          references.map((row) => row.target_line),
+          references.map((row) => row.access),
+        ]
+      );
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('go_references', `replace type facts of ${repoId}`, err);
+    }
+  };
+
+  /**
+   * Set the statement coverage of indexed symbols
+   *
+   * @param coverage - Symbol ID and percent covered (null clears a previous import)
+   */
+  public updateSymbolCoverage = async (coverage: { id: number; percent: number | null }[]): Promise<void> => {
+    if (coverage.length === 0) return;
+
+    const sql = `
+      UPDATE code_symbols
+      SET coverage = v.coverage
+      FROM unnest($1::int[], $2::real[]) AS v(id, coverage)
+      WHERE code_symbols.id = v.id
+    `;
+
+    try {
+      await this.pool.query(sql, [coverage.map((row) => row.id), coverage.map((row) => row.percent)]);
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_symbols', `update coverage of ${String(coverage.length)} symbols`, err);
+    }
+  };
+
+  /**
+   * Record the last change to indexed symbols
+   *
+   * @param history - Symbol ID and the commit that last changed its lines
+   */
+  public updateSymbolHistory = async (history: { id: number; change: LastChange }[]): Promise<void> => {
+    if (history.length === 0) return;
+
+    const sql = `
+      UPDATE code_symbols
+      SET last_commit = v.last_commit, last_author = v.last_author, last_modified = v.last_modified
+      FROM unnest($1::int[], $2::text[], $3::text[], $4::timestamptz[]) AS v(id, last_commit, last_author, last_modified)
+      WHERE code_symbols.id = v.id
+    `;
+
+    try {
+      await this.pool.query(sql, [
+        history.map((row) => row.id),
+        history.map((row) => row.change.commit),
+        history.map((row) => row.change.author),
+        history.map((row) => row.change.date),
+      ]);
+    } catch (error) {
+      const err = error instanceof Error ? error : new Error(String(error));
+      throw new DatabaseWriteError('code_symbols', `update history of ${String(history.length)} symbols`, err);
+    }
+  };
+
   /**
    * Update service API endpoints from parsed API specification
    *
@@ -1043,6 +1756,20 @@ export class DatabaseWriter {
       // Delete in reverse dependency order to respect foreign key constraints
       const symbolsResult = await this.pool.query('DELETE FROM code_symbols WHERE repo_path = $1', [repoPath]);
 
+      await this.pool.query('DELETE FROM secret_findings WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM lint_findings WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_implementations WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_references WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_calls WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_constants WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_type_parameters WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_instantiations WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM go_embeddings WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM code_annotations WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM code_literals WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM code_plugin_metrics WHERE repo_path = $1', [repoPath]);
+      await this.pool.query('DELETE FROM code_contents WHERE repo_path = $1', [repoPath]);
+
       const chunksResult = await this.pool.query('DELETE FROM code_chunks WHERE repo_path = $1', [repoPath]);
 
       const filesResult = await this.pool.query('DELETE FROM code_files WHERE repo_path = $1', [repoPath]);
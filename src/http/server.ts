@@ -0,0 +1,611 @@
+/**
+ * REST API backed by the index (cindex serve --http)
+ *
+ * Read-only JSON endpoints for dashboards, bots, and scripts that cannot
+ * speak MCP or LSP:
+ *
+ *   GET /health                 database reachable, indexes served
+ *   GET /symbols?q=NAS          fuzzy symbol search (see @retrieval/fuzzy-symbols), with
+ *                               ?include_snippet=true their source (see @retrieval/snippets)
+ *   GET /files/{path}/outline   declarations of an indexed file, nested (see @retrieval/outline)
+ *   GET /refs/{id}              uses of a symbol returned by /symbols
+ *   GET /related/{id}?k=        symbols related to it (see @retrieval/related-symbols)
+ *   GET /metrics                Prometheus metrics of the process (see @utils/metrics)
+ *
+ * List endpoints page with ?limit= and the opaque ?cursor= of the previous
+ * page's next_cursor. Symbol IDs encode the declaration's index, file, line,
+ * and name, so they survive re-indexing as long as the declaration does not
+ * move. Results come from the last index run, like every other query.
+ * serveMetrics serves /metrics alone, for processes that have no API
+ * (cindex watch, cindex serve --lsp).
+ */
+
+import * as http from 'node:http';
+import { type AddressInfo } from 'node:net';
+
+import { type Pool } from 'pg';
+
+import {
+  getIndexedFileContent,
+  listFileSymbols,
+  listGoReferencesTo,
+  listIndexedRepositories,
+  listRelatedCandidates,
+  resolveIndexedFile,
+} from '@database/queries';
+import { readConsistently } from '@indexing/index-lock';
+import { escapeRegex, searchContent } from '@retrieval/content-search';
+import { searchSymbolsFuzzy } from '@retrieval/fuzzy-symbols';
+import { outlineFile } from '@retrieval/outline';
+import { DEFAULT_RELATED, findRelatedSymbols } from '@retrieval/related-symbols';
+import { attachSnippets, DEFAULT_SNIPPET_CONTEXT, MAX_SNIPPET_CONTEXT } from '@retrieval/snippets';
+import { CindexError } from '@utils/errors';
+import { logger } from '@utils/logger';
+import { METRICS_CONTENT_TYPE, queryDuration, renderMetrics, secondsSince } from '@utils/metrics';
+import {
+  type HttpErrorBody,
+  type HttpFileOutline,
+  type HttpHealth,
+  type HttpPage,
+  type HttpReference,
+  type HttpReferencePage,
+  type HttpRelated,
+  type HttpSymbol,
+} from '@/types/http';
+import { type Language } from '@/types/indexing';
+
+/** Items per page without ?limit= */
+const DEFAULT_PAGE_SIZE = 50;
+
+/** Largest ?limit= accepted */
+const MAX_PAGE_SIZE = 500;
+
+/** Symbols ranked per search (pages reach this deep) */
+const MAX_SYMBOL_RESULTS = 2000;
+
+/** Largest ?k= of /related */
+const MAX_RELATED = 100;
+
+/**
+ * Response of one request, before serialization
+ */
+export interface HttpApiResponse {
+  status: number;
+  body: unknown;
+  /** Set for a text body (body is then a string); bodies are JSON otherwise */
+  contentType?: string;
+}
+
+/**
+ * Declaration a symbol ID names
+ */
+export interface SymbolKey {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  symbol_name: string;
+}
+
+/**
+ * Host and port to listen on
+ */
+export interface ListenAddress {
+  /** Undefined listens on every interface */
+  host?: string;
+  port: number;
+}
+
+/**
+ * Error answered with a status instead of a result
+ */
+class HttpError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly code: string,
+    message: string,
+    public readonly hint?: string
+  ) {
+    super(message);
+  }
+}
+
+/**
+ * Parse a listen address: :8080, 8080, localhost:8080, 127.0.0.1:0, or [::1]:8080
+ *
+ * @param value - Address as given to --http
+ * @returns Host (undefined for every interface) and port
+ * @throws {Error} If the port is not a number from 0 to 65535
+ */
+export const parseListenAddress = (value: string): ListenAddress => {
+  const match = /^(?:\[([^\]]+)\]|([^:]*)):(\d+)$/.exec(value) ?? /^()()(\d+)$/.exec(value);
+  const port = match ? Number(match[3]) : Number.NaN;
+  if (!match || !Number.isInteger(port) || port > 65535) {
+    throw new Error(`Invalid listen address '${value}' (expected [host]:port, e.g. :8080 or 127.0.0.1:8080)`);
+  }
+  // An empty or omitted host (:8080) listens on every interface
+  const host = match[1] || match[2];
+  return { host: host || undefined, port };
+};
+
+/**
+ * Encode the ID of a declaration (URL-safe)
+ */
+export const encodeSymbolId = (key: SymbolKey): string =>
+  Buffer.from(JSON.stringify([key.repo_id, key.file_path, key.line_number, key.symbol_name])).toString('base64url');
+
+/**
+ * Decode a symbol ID
+ *
+ * @returns Declaration, or null if the ID was not made by encodeSymbolId
+ */
+export const decodeSymbolId = (id: string): SymbolKey | null => {
+  let fields: unknown;
+  try {
+    fields = JSON.parse(Buffer.from(id, 'base64url').toString('utf8'));
+  } catch {
+    return null;
+  }
+  if (!Array.isArray(fields) || fields.length !== 4) return null;
+  const [repoId, filePath, line, name] = fields as unknown[];
+  if (repoId !== null && typeof repoId !== 'string') return null;
+  if (typeof filePath !== 'string' || typeof name !== 'string') return null;
+  if (typeof line !== 'number' || !Number.isInteger(line) || line < 1) return null;
+  return { repo_id: repoId, file_path: filePath, line_number: line, symbol_name: name };
+};
+
+/**
+ * Cursor of the page starting at an offset
+ */
+export const encodeCursor = (offset: number): string => Buffer.from(`o${String(offset)}`).toString('base64url');
+
+/**
+ * Read ?limit= and ?cursor=
+ *
+ * @returns Offset of the page and its size
+ * @throws {HttpError} If either is malformed
+ */
+export const pageParams = (params: URLSearchParams): { offset: number; limit: number } => {
+  const limitText = params.get('limit');
+  const limit = limitText === null ? DEFAULT_PAGE_SIZE : Number(limitText);
+  if (!Number.isInteger(limit) || limit < 1 || limit > MAX_PAGE_SIZE) {
+    throw new HttpError(400, 'INVALID_PARAMETER', `limit must be an integer from 1 to ${String(MAX_PAGE_SIZE)}`);
+  }
+
+  const cursor = params.get('cursor');
+  if (cursor === null || cursor === '') return { offset: 0, limit };
+  const offset = /^o(\d+)$/.exec(Buffer.from(cursor, 'base64url').toString('utf8'));
+  if (!offset) throw new HttpError(400, 'INVALID_CURSOR', 'cursor is not a next_cursor of this API');
+  return { offset: Number(offset[1]), limit };
+};
+
+/**
+ * Read ?include_snippet= and ?context=
+ *
+ * @returns Context lines of the snippets asked for, or undefined without include_snippet=true
+ * @throws {HttpError} If either is malformed
+ */
+export const snippetParams = (params: URLSearchParams): number | undefined => {
+  const include = params.get('include_snippet');
+  if (include !== null && include !== 'true' && include !== 'false') {
+    throw new HttpError(400, 'INVALID_PARAMETER', 'include_snippet must be true or false');
+  }
+  const contextText = params.get('context');
+  const context = contextText === null ? DEFAULT_SNIPPET_CONTEXT : Number(contextText);
+  if (!Number.isInteger(context) || context < 0 || context > MAX_SNIPPET_CONTEXT) {
+    const range = `0 to ${String(MAX_SNIPPET_CONTEXT)}`;
+    throw new HttpError(400, 'INVALID_PARAMETER', `context must be an integer from ${range}`);
+  }
+  return include === 'true' ? context : undefined;
+};
+
+/**
+ * Cut a page from results fetched one past its end
+ *
+ * @param results - Results from the start, up to offset + limit + 1
+ */
+export const toPage = <T>(results: T[], offset: number, limit: number): HttpPage<T> => ({
+  items: results.slice(offset, offset + limit),
+  next_cursor: results.length > offset + limit ? encodeCursor(offset + limit) : null,
+});
+
+/**
+ * Endpoint a path is routed to, as labelled in cindex_query_duration_seconds
+ */
+export const endpointOf = (pathname: string): string => {
+  if (['/health', '/symbols', '/metrics'].includes(pathname)) return pathname;
+  if (/^\/files\/.+\/outline$/.test(pathname)) return '/files/{path}/outline';
+  if (/^\/refs\/[^/]+$/.test(pathname)) return '/refs/{id}';
+  if (/^\/related\/[^/]+$/.test(pathname)) return '/related/{id}';
+  return 'other';
+};
+
+/**
+ * Response of GET /metrics
+ */
+const metricsResponse = (): HttpApiResponse => ({
+  status: 200,
+  body: renderMetrics(),
+  contentType: METRICS_CONTENT_TYPE,
+});
+
+/**
+ * Last element of a dotted name (Get of store.Memory.Get)
+ */
+const lastName = (name: string): string => name.slice(name.lastIndexOf('.') + 1);
+
+/**
+ * Request handlers of the API, independent of the HTTP server
+ */
+export class HttpApi {
+  /**
+   * @param db - Database connection pool
+   * @param repoId - Serve one index (default: every indexed repository)
+   */
+  constructor(
+    private readonly db: Pool,
+    private readonly repoId?: string
+  ) {}
+
+  /**
+   * Answer one request
+   *
+   * @param method - HTTP method
+   * @param target - Request target (path and query)
+   * @returns Status and body (JSON, or the text of /metrics)
+   */
+  public handle = async (method: string, target: string): Promise<HttpApiResponse> => {
+    const start = performance.now();
+    let endpoint = 'other';
+    try {
+      if (method !== 'GET' && method !== 'HEAD') {
+        throw new HttpError(405, 'METHOD_NOT_ALLOWED', `${method} is not supported; the API is read-only`);
+      }
+      const url = parseTarget(target);
+      endpoint = endpointOf(url.pathname);
+      if (url.pathname === '/metrics') return metricsResponse();
+      return { status: 200, body: await this.route(url) };
+    } catch (error) {
+      return errorResponse(error);
+    } finally {
+      queryDuration.observe(secondsSince(start), { api: 'http', endpoint });
+    }
+  };
+
+  /**
+   * Run the endpoint of a path
+   *
+   * @throws {HttpError} If no endpoint matches or its parameters are invalid
+   */
+  private route = (url: URL): Promise<unknown> => {
+    const { pathname, searchParams } = url;
+    if (pathname === '/health') return this.health();
+    if (pathname === '/symbols') return this.symbols(searchParams);
+
+    const file = /^\/files\/(.+)\/outline$/.exec(pathname);
+    if (file) return this.outline(decodeSegment(file[1]), searchParams);
+    const refs = /^\/refs\/([^/]+)$/.exec(pathname);
+    if (refs) return this.references(decodeSegment(refs[1]), searchParams);
+    const related = /^\/related\/([^/]+)$/.exec(pathname);
+    if (related) return this.related(decodeSegment(related[1]), searchParams);
+
+    const endpoints = '/health, /symbols, /files/{path}/outline, /refs/{id}, /related/{id}, /metrics';
+    throw new HttpError(404, 'NOT_FOUND', `No endpoint at ${pathname}`, `Endpoints: ${endpoints}`);
+  };
+
+  /**
+   * GET /health
+   */
+  private health = async (): Promise<HttpHealth> => {
+    const repositories = await listIndexedRepositories(this.db).catch((error: unknown) => {
+      throw new HttpError(503, 'DATABASE_UNAVAILABLE', error instanceof Error ? error.message : String(error));
+    });
+    const served = repositories.filter((repo) => !this.repoId || repo.repo_id === this.repoId);
+    return { status: 'ok', indexes: served.length };
+  };
+
+  /**
+   * GET /symbols?q=&repo_id=&near=&include_snippet=&context=
+   */
+  private symbols = async (params: URLSearchParams): Promise<HttpPage<HttpSymbol>> => {
+    const query = params.get('q')?.trim() ?? '';
+    if (query === '') throw new HttpError(400, 'MISSING_PARAMETER', 'q is required', 'e.g. /symbols?q=NewAuthService');
+    const { offset, limit } = pageParams(params);
+    const snippetContext = snippetParams(params);
+    const symbols = await searchSymbolsFuzzy(this.db, query, {
+      repoId: this.selectRepo(params),
+      near: params.get('near') ?? undefined,
+      limit: Math.min(offset + limit + 1, MAX_SYMBOL_RESULTS),
+    });
+    const page = toPage(symbols, offset, limit);
+    const items =
+      snippetContext === undefined
+        ? page.items
+        : await attachSnippets(this.db, page.items, { context: snippetContext });
+    return {
+      items: items.map((symbol) => ({
+        ...symbol,
+        id: encodeSymbolId({ ...symbol, repo_id: symbol.repo_id ?? null }),
+      })),
+      next_cursor: page.next_cursor,
+    };
+  };
+
+  /**
+   * GET /files/{path}/outline?repo_id=
+   *
+   * The path may be as stored, absolute, or a trailing part of a stored path.
+   */
+  private outline = async (filePath: string, params: URLSearchParams): Promise<HttpFileOutline> => {
+    const file = await resolveIndexedFile(this.db, filePath, this.selectRepo(params));
+    if (!file) throw new HttpError(404, 'FILE_NOT_INDEXED', `File not indexed: ${filePath}`);
+    const repoId = file.repo_id ?? undefined;
+    const readFile = async () => ({
+      symbols: await listFileSymbols(this.db, file.file_path, repoId),
+      stored: await getIndexedFileContent(this.db, file.file_path, repoId),
+    });
+    // Symbols and content must come from the same run; runs re-indexing other packages do not matter
+    const { symbols, stored } = repoId ? await readConsistently(repoId, readFile, [file.file_path]) : await readFile();
+    const outline = stored ? outlineFile(stored.content, file.file_path, stored.language as Language) : null;
+    return { repo_id: file.repo_id, file_path: file.file_path, outline, symbols };
+  };
+
+  /**
+   * GET /refs/{id}: type-checked uses of Go declarations, else whole-word matches of the name
+   */
+  private references = async (id: string, params: URLSearchParams): Promise<HttpReferencePage> => {
+    const symbol = this.servedSymbol(id);
+    const { offset, limit } = pageParams(params);
+    const repoId = symbol.repo_id ?? undefined;
+
+    if (symbol.file_path.endsWith('.go')) {
+      const declaration = { file_path: symbol.file_path, line: symbol.line_number, name: symbol.symbol_name };
+      const uses = await listGoReferencesTo(this.db, declaration, repoId);
+      if (uses.length > 0) {
+        const references = uses.map(
+          (use): HttpReference => ({
+            repo_id: use.repo_id,
+            file_path: use.file_path,
+            line_number: use.line_number,
+            column_number: use.column_number,
+            symbol_name: use.symbol_name,
+            access: use.access,
+          })
+        );
+        return { ...toPage(references, offset, limit), source: 'typed' };
+      }
+    }
+
+    const { matches } = await searchContent(this.db, `\\b${escapeRegex(lastName(symbol.symbol_name))}\\b`, {
+      repoId,
+      limit: offset + limit + 1,
+    });
+    const references = matches.map(
+      (match): HttpReference => ({
+        repo_id: match.repo_id,
+        file_path: match.file_path,
+        line_number: match.line,
+        column_number: match.column,
+        text: match.text,
+      })
+    );
+    return { ...toPage(references, offset, limit), source: 'text' };
+  };
+
+  /**
+   * GET /related/{id}?k=: symbols related to a declaration, best first
+   */
+  private related = async (id: string, params: URLSearchParams): Promise<HttpRelated> => {
+    const symbol = this.servedSymbol(id);
+    const kText = params.get('k');
+    const k = kText === null ? DEFAULT_RELATED : Number(kText);
+    if (!Number.isInteger(k) || k < 1 || k > MAX_RELATED) {
+      throw new HttpError(400, 'INVALID_PARAMETER', `k must be an integer from 1 to ${String(MAX_RELATED)}`);
+    }
+
+    const repoId = symbol.repo_id ?? undefined;
+    const candidates = await listRelatedCandidates(this.db, { names: [symbol.symbol_name], repoId });
+    const target = candidates.find(
+      (candidate) =>
+        candidate.symbol_name === symbol.symbol_name &&
+        candidate.file_path === symbol.file_path &&
+        candidate.line_number === symbol.line_number
+    );
+    if (!target) {
+      throw new HttpError(404, 'SYMBOL_NOT_INDEXED', `${symbol.symbol_name} is no longer at its indexed location`);
+    }
+    const related = await findRelatedSymbols(this.db, target, k);
+    return {
+      items: related.map((entry) => ({ ...entry, id: encodeSymbolId(entry) })),
+    };
+  };
+
+  /**
+   * Declaration a symbol ID names, if its index is served
+   *
+   * @throws {HttpError} If the ID is malformed or names another index
+   */
+  private servedSymbol = (id: string): SymbolKey => {
+    const symbol = decodeSymbolId(id);
+    if (!symbol) throw new HttpError(400, 'INVALID_SYMBOL_ID', 'Not a symbol ID', 'Use the id of a /symbols result');
+    if (this.repoId && symbol.repo_id !== this.repoId) {
+      throw new HttpError(404, 'UNKNOWN_INDEX', `Index '${String(symbol.repo_id)}' is not served`);
+    }
+    return symbol;
+  };
+
+  /**
+   * Index a request is limited to: ?repo_id=, within the served index
+   *
+   * @throws {HttpError} If ?repo_id= names an index that is not served
+   */
+  private selectRepo = (params: URLSearchParams): string | undefined => {
+    const requested = params.get('repo_id') ?? undefined;
+    if (this.repoId && requested && requested !== this.repoId) {
+      const hint = `This server serves '${this.repoId}'`;
+      throw new HttpError(404, 'UNKNOWN_INDEX', `Index '${requested}' is not served`, hint);
+    }
+    return this.repoId ?? requested;
+  };
+}
+
+/**
+ * Parse a request target (path and query)
+ *
+ * @throws {HttpError} If the target is not a valid URL path, e.g. //[
+ */
+const parseTarget = (target: string): URL => {
+  try {
+    return new URL(target, 'http://localhost');
+  } catch {
+    throw new HttpError(400, 'INVALID_URL', `Malformed request target ${target}`);
+  }
+};
+
+/**
+ * Decode a percent-encoded path part
+ *
+ * @throws {HttpError} If the encoding is malformed
+ */
+const decodeSegment = (segment: string): string => {
+  try {
+    return decodeURIComponent(segment);
+  } catch {
+    throw new HttpError(400, 'INVALID_PATH', `Malformed percent-encoding in ${segment}`);
+  }
+};
+
+/**
+ * Status and body of a failed request
+ */
+const errorResponse = (error: unknown): HttpApiResponse => {
+  if (error instanceof HttpError) {
+    const { code, message, hint } = error;
+    const body: HttpErrorBody = { error: hint ? { code, message, hint } : { code, message } };
+    return { status: error.status, body };
+  }
+  // Anything else is the server's fault (database errors included)
+  logger.error('HTTP request failed', { error: String(error) });
+  const code = error instanceof CindexError ? error.code : 'INTERNAL_ERROR';
+  const body: HttpErrorBody = { error: { code, message: error instanceof Error ? error.message : String(error) } };
+  return { status: 500, body };
+};
+
+/**
+ * Write a response, JSON unless it has a content type of its own
+ */
+const writeResponse = (response: http.ServerResponse, method: string, answer: HttpApiResponse): void => {
+  const { status, body, contentType } = answer;
+  const text = contentType !== undefined ? String(body) : JSON.stringify(body);
+  response.writeHead(status, {
+    'Content-Type': contentType ?? 'application/json; charset=utf-8',
+    'Content-Length': Buffer.byteLength(text),
+    ...(status === 405 ? { Allow: 'GET, HEAD' } : {}),
+  });
+  response.end(method === 'HEAD' ? undefined : text);
+};
+
+/**
+ * Listen on an address and keep serving until the signal aborts
+ *
+ * @param server - Server to run
+ * @param address - Host and port to listen on
+ * @param signal - Stops the server; requests in progress are finished first
+ * @param onListening - Called with the bound address
+ * @returns Resolves once the server has closed
+ */
+const listenUntilAborted = async (
+  server: http.Server,
+  address: ListenAddress,
+  signal: AbortSignal,
+  onListening: (bound: AddressInfo) => void
+): Promise<void> => {
+  await new Promise<void>((resolve, reject) => {
+    server.once('error', reject);
+    server.listen(address.port, address.host, () => {
+      server.off('error', reject);
+      resolve();
+    });
+  });
+  onListening(server.address() as AddressInfo);
+
+  await new Promise<void>((resolve) => {
+    const close = (): void => {
+      server.close(() => {
+        resolve();
+      });
+      server.closeIdleConnections();
+    };
+    if (signal.aborted) close();
+    else signal.addEventListener('abort', close, { once: true });
+  });
+};
+
+/**
+ * Serve the API until the signal aborts
+ *
+ * @param db - Database connection pool
+ * @param address - Host and port to listen on
+ * @param repoId - Serve one index (default: every indexed repository)
+ * @param signal - Stops the server; requests in progress are finished first
+ * @param onListening - Called with the bound address (port 0 picks a free port)
+ * @returns Resolves once the server has closed
+ */
+export const serveHttp = async (
+  db: Pool,
+  address: ListenAddress,
+  repoId: string | undefined,
+  signal: AbortSignal,
+  onListening?: (bound: AddressInfo) => void
+): Promise<void> => {
+  const api = new HttpApi(db, repoId);
+  const server = http.createServer((request, response) => {
+    const method = request.method ?? 'GET';
+    void api.handle(method, request.url ?? '/').then((answer) => {
+      writeResponse(response, method, answer);
+      logger.debug('HTTP request', { method, url: request.url, status: answer.status });
+    });
+  });
+
+  await listenUntilAborted(server, address, signal, (bound) => {
+    logger.info('HTTP API listening', { address: bound.address, port: bound.port, repo_id: repoId });
+    onListening?.(bound);
+  });
+};
+
+/**
+ * Serve GET /metrics alone until the signal aborts
+ *
+ * @param address - Host and port to listen on
+ * @param signal - Stops the server
+ * @param onListening - Called with the bound address (port 0 picks a free port)
+ * @returns Resolves once the server has closed
+ */
+export const serveMetrics = async (
+  address: ListenAddress,
+  signal: AbortSignal,
+  onListening?: (bound: AddressInfo) => void
+): Promise<void> => {
+  const server = http.createServer((request, response) => {
+    const method = request.method ?? 'GET';
+    let pathname: string;
+    try {
+      pathname = parseTarget(request.url ?? '/').pathname;
+    } catch (error) {
+      writeResponse(response, method, errorResponse(error));
+      return;
+    }
+    if (pathname === '/metrics' && (method === 'GET' || method === 'HEAD')) {
+      writeResponse(response, method, metricsResponse());
+    } else {
+      const body: HttpErrorBody = {
+        error: { code: 'NOT_FOUND', message: `No endpoint at ${pathname}`, hint: 'Metrics are at /metrics' },
+      };
+      writeResponse(response, method, { status: 404, body });
+    }
+  });
+
+  await listenUntilAborted(server, address, signal, (bound) => {
+    logger.info('Metrics endpoint listening', { address: bound.address, port: bound.port });
+    onListening?.(bound);
+  });
+};
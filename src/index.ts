@@ -4,35 +4,40 @@
  * cindex - MCP Server for semantic code search and context retrieval.
  *
  * Features:
- * - 17 MCP tools for search, indexing, context retrieval, and documentation
+ * - 22 MCP tools for search, navigation, indexing, context retrieval, and documentation
  * - PostgreSQL + pgvector for vector similarity search
  * - Ollama for embeddings (bge-m3) and summaries (qwen2.5-coder)
  * - Supports 1M+ LoC with 9-stage retrieval pipeline
  *
  * @see https://github.com/gianged/cindex
  */
+import * as path from 'node:path';
+
 import { McpServer } from '@modelcontextprotocol/sdk/server/mcp.js';
 import { StdioServerTransport } from '@modelcontextprotocol/sdk/server/stdio.js';
 import { type z } from 'zod';
 
+import { isCliInvocation, runCli } from '@cli/index';
+import { reportUncaughtError } from '@cli/output';
+import { recordUsage } from '@cli/usage-stats';
 import { loadConfig, validateConfig } from '@config/env';
 import { createDatabaseClient } from '@database/client';
-import { DatabaseWriter } from '@database/writer';
-import { CodeChunker } from '@indexing/chunker';
-import { EmbeddingGenerator } from '@indexing/embeddings';
-import { FileWalker } from '@indexing/file-walker';
-import { IndexingOrchestrator } from '@indexing/orchestrator';
-import { CodeParser } from '@indexing/parser';
-import { FileSummaryGenerator } from '@indexing/summary';
-import { SymbolExtractor } from '@indexing/symbols';
+import { findIndexWriter } from '@indexing/index-lock';
+import { findCorruptFiles, findRebuildTargets } from '@indexing/integrity';
+import { type IndexingOrchestrator } from '@indexing/orchestrator';
+import { createPipeline } from '@indexing/pipeline';
 import { toMcpSchema } from '@mcp/schema-adapter';
 import {
+  AddDocumentSchema,
   DeleteDocumentationSchema,
   DeleteRepositorySchema,
   FindCrossServiceCallsSchema,
   FindCrossWorkspaceUsagesSchema,
   FindSymbolSchema,
+  GetDefinitionSchema,
   GetFileContextSchema,
+  GetFileOutlineSchema,
+  GetReferencesSchema,
   GetServiceContextSchema,
   GetWorkspaceContextSchema,
   IndexDocumentationSchema,
@@ -44,14 +49,19 @@ import {
   SearchAPIContractsSchema,
   SearchCodebaseSchema,
   SearchReferencesSchema,
+  SearchSymbolsSchema,
 } from '@mcp/schemas';
 import {
+  addDocumentMCP,
   deleteDocumentationMCP,
   deleteRepositoryMCP,
   findCrossServiceCallsMCP,
   findCrossWorkspaceUsagesMCP,
   findSymbolMCP,
+  getDefinitionMCP,
   getFileContextMCP,
+  getFileOutlineMCP,
+  getReferencesMCP,
   getServiceContextMCP,
   getWorkspaceContextMCP,
   indexDocumentationMCP,
@@ -63,14 +73,17 @@ import {
   searchAPIContractsMCP,
   searchCodebaseMCP,
   searchReferencesMCP,
+  searchSymbolsMCP,
 } from '@mcp/tools-mcp';
+import { createEmbedder, ollamaEmbeddingModel, type Embedder } from '@utils/embedders';
 import { CindexError } from '@utils/errors';
 import { initLogger, logger } from '@utils/logger';
 import { createOllamaClient } from '@utils/ollama';
-import { ProgressTracker } from '@utils/progress';
+import { handleShutdownSignals } from '@utils/shutdown';
+import { flushTraces } from '@utils/tracing';
 import { type IndexingOptions } from '@/types/indexing';
 
-// Tool input types (grouped: Search → Context → Index → List → Cross-Ref → Delete)
+// Tool input types (grouped: Search → Context → Index → List → Cross-Ref → Navigation → Delete)
 type SearchCodebaseInput = z.infer<typeof SearchCodebaseSchema>;
 type SearchReferencesInput = z.infer<typeof SearchReferencesSchema>;
 type SearchAPIContractsInput = z.infer<typeof SearchAPIContractsSchema>;
@@ -80,12 +93,17 @@ type GetWorkspaceContextInput = z.infer<typeof GetWorkspaceContextSchema>;
 type GetServiceContextInput = z.infer<typeof GetServiceContextSchema>;
 type IndexRepositoryInput = z.infer<typeof IndexRepositorySchema>;
 type IndexDocumentationInput = z.infer<typeof IndexDocumentationSchema>;
+type AddDocumentInput = z.infer<typeof AddDocumentSchema>;
 type ListIndexedReposInput = z.infer<typeof ListIndexedReposSchema>;
 type ListWorkspacesInput = z.infer<typeof ListWorkspacesSchema>;
 type ListServicesInput = z.infer<typeof ListServicesSchema>;
 type ListDocumentationInput = z.infer<typeof ListDocumentationSchema>;
 type FindCrossWorkspaceUsagesInput = z.infer<typeof FindCrossWorkspaceUsagesSchema>;
 type FindCrossServiceCallsInput = z.infer<typeof FindCrossServiceCallsSchema>;
+type SearchSymbolsInput = z.infer<typeof SearchSymbolsSchema>;
+type GetDefinitionInput = z.infer<typeof GetDefinitionSchema>;
+type GetReferencesInput = z.infer<typeof GetReferencesSchema>;
+type GetFileOutlineInput = z.infer<typeof GetFileOutlineSchema>;
 type DeleteRepositoryInput = z.infer<typeof DeleteRepositorySchema>;
 type DeleteDocumentationInput = z.infer<typeof DeleteDocumentationSchema>;
 
@@ -94,11 +112,21 @@ interface AppState {
   config: ReturnType<typeof loadConfig>; // Environment configuration
   db: ReturnType<typeof createDatabaseClient>; // PostgreSQL connection pool
   ollama: ReturnType<typeof createOllamaClient>; // Ollama API client
+  embedder: Embedder; // Embeddings of the configured provider (the Ollama client by default)
   server: McpServer; // MCP server instance
 }
 
 let appState: AppState | null = null;
 
+/** Aborted on SIGINT/SIGTERM so running index_repository calls stop after their current file */
+const shutdownController = new AbortController();
+
+/** index_repository calls in flight (awaited during shutdown) */
+const activeIndexing = new Set<Promise<unknown>>();
+
+/** Maximum time shutdown waits for in-flight indexing to reach a file boundary */
+const SHUTDOWN_TIMEOUT_MS = 30000;
+
 /**
  * Create IndexingOrchestrator with all pipeline components.
  * Called on-demand for each index_repository invocation.
@@ -110,17 +138,7 @@ const createOrchestrator = (repoPath: string, options: IndexingOptions): Indexin
 
   const { config, db, ollama } = appState;
 
-  return new IndexingOrchestrator(
-    db,
-    new FileWalker(repoPath, options),
-    new CodeParser(),
-    new CodeChunker(),
-    new FileSummaryGenerator(ollama, config.summary),
-    new EmbeddingGenerator(ollama, config.embedding),
-    new SymbolExtractor(new EmbeddingGenerator(ollama, config.embedding)),
-    new DatabaseWriter(db.getPool()),
-    new ProgressTracker()
-  );
+  return createPipeline(config, db, ollama, repoPath, options);
 };
 
 /**
@@ -130,22 +148,23 @@ const createOrchestrator = (repoPath: string, options: IndexingOptions): Indexin
  * 1. Load and validate environment configuration
  * 2. Initialize database and Ollama clients
  * 3. Health checks (database, pgvector, Ollama models)
- * 4. Register all 17 MCP tools
+ * 4. Register all 22 MCP tools
  */
 const initializeServer = async (): Promise<AppState> => {
   logger.info('Loading configuration...');
   const config = loadConfig();
   validateConfig(config);
-  initLogger('INFO');
+  initLogger(config.logging.level);
 
   logger.startup({ version: '0.1.0', models: [config.embedding.model, config.summary.model] });
 
   logger.info('Initializing clients...');
   const db = createDatabaseClient(config.database);
   const ollama = createOllamaClient(config.ollama);
+  const embedder = createEmbedder(config, ollama);
 
   logger.info('Checking Ollama connection...');
-  await ollama.healthCheck(config.embedding.model, config.summary.model);
+  await ollama.healthCheck(ollamaEmbeddingModel(config.embedding), config.summary.model);
 
   logger.info('Connecting to database...');
   await db.connect();
@@ -155,8 +174,8 @@ const initializeServer = async (): Promise<AppState> => {
 
   const server = new McpServer({ name: 'cindex', version: '0.1.0' }, { capabilities: { tools: {} } });
 
-  // Register all 17 MCP tools grouped by function:
-  // Search (4) → Context (3) → Index (2) → List (4) → Cross-Ref (2) → Delete (2)
+  // Register all 22 MCP tools grouped by function:
+  // Search (4) → Context (3) → Index (3) → List (4) → Cross-Ref (2) → Navigation (4) → Delete (2)
   logger.debug('Registering MCP tools...');
 
   // ===================
@@ -171,7 +190,17 @@ const initializeServer = async (): Promise<AppState> => {
         'MUST BE USED for all code search, discovery, and understanding tasks. Provides semantic search with multi-stage retrieval and dependency analysis. If results are empty, use list_indexed_repos to check if repository is indexed, then suggest index_repository if needed.',
       inputSchema: toMcpSchema(SearchCodebaseSchema),
     },
-    async (params: SearchCodebaseInput) => searchCodebaseMCP(db.getPool(), config, ollama, params)
+    async (params: SearchCodebaseInput) => {
+      const started = Date.now();
+      const result = await searchCodebaseMCP(db.getPool(), config, embedder, params);
+      recordUsage(config, {
+        kind: 'query',
+        source: 'mcp',
+        operation: 'search_codebase',
+        duration_ms: Date.now() - started,
+      });
+      return result;
+    }
   );
 
   // 2. search_references - Search markdown docs AND reference repository code
@@ -182,7 +211,7 @@ const initializeServer = async (): Promise<AppState> => {
         'Search reference materials including markdown documentation AND reference repository code. Combines documentation (syntax.md, Context7 docs) with actual code from indexed reference repos (frameworks, libraries). Use for learning patterns, checking syntax, and finding implementation examples.',
       inputSchema: toMcpSchema(SearchReferencesSchema),
     },
-    async (params: SearchReferencesInput) => searchReferencesMCP(db.getPool(), ollama, config, params)
+    async (params: SearchReferencesInput) => searchReferencesMCP(db.getPool(), embedder, config, params)
   );
 
   // 3. search_api_contracts - Search REST/GraphQL/gRPC endpoints
@@ -193,7 +222,7 @@ const initializeServer = async (): Promise<AppState> => {
         'Search API endpoints across services with semantic understanding. Use when implementing API integrations, finding endpoints to call, or understanding service communication patterns. Returns endpoint paths, HTTP methods, request/response schemas, and implementation file locations.',
       inputSchema: toMcpSchema(SearchAPIContractsSchema),
     },
-    async (params: SearchAPIContractsInput) => searchAPIContractsMCP(db.getPool(), ollama, config, params)
+    async (params: SearchAPIContractsInput) => searchAPIContractsMCP(db.getPool(), embedder, config, params)
   );
 
   // 4. find_symbol_definition - Locate functions/classes/variables
@@ -245,7 +274,7 @@ const initializeServer = async (): Promise<AppState> => {
   );
 
   // ===================
-  // Indexing Tools (2)
+  // Indexing Tools (3)
   // ===================
 
   // 8. index_repository - Index codebase with progress tracking
@@ -257,12 +286,22 @@ const initializeServer = async (): Promise<AppState> => {
       inputSchema: toMcpSchema(IndexRepositorySchema),
     },
     async (params: IndexRepositoryInput) => {
+      if (shutdownController.signal.aborted) {
+        throw new Error('cindex is shutting down; retry index_repository after it restarts');
+      }
+
       // Convert snake_case MCP params to camelCase IndexingOptions
       const indexingOptions: IndexingOptions = {
         incremental: params.incremental,
         languages: params.languages,
         respectGitignore: params.respect_gitignore,
+        symlinkPolicy: params.symlink_policy ?? config.indexing.symlink_policy,
+        generatedFiles: params.generated_files ?? config.indexing.generated_files,
+        excludeDirectories: config.indexing.exclude_directories,
         maxFileSize: params.max_file_size,
+        maxDirectoryDepth: config.indexing.max_directory_depth,
+        maxPathLength: config.indexing.max_path_length,
+        scanSecrets: config.indexing.scan_secrets,
         summaryMethod: params.summary_method,
         repoId: params.repo_id,
         repoName: params.repo_name,
@@ -308,7 +347,19 @@ const initializeServer = async (): Promise<AppState> => {
           });
       };
 
-      return indexRepositoryMCP(orchestrator, params, progressCallback);
+      const started = Date.now();
+      const input = { ...params, scan_secrets: params.scan_secrets ?? config.indexing.scan_secrets };
+      const run = indexRepositoryMCP(orchestrator, input, progressCallback, shutdownController.signal);
+      activeIndexing.add(run);
+      const result = await run.finally(() => activeIndexing.delete(run));
+      recordUsage(config, {
+        kind: 'index',
+        source: 'mcp',
+        operation: params.incremental ? 'index_repository --incremental' : 'index_repository',
+        duration_ms: Date.now() - started,
+        repo_id: params.repo_id,
+      });
+      return result;
     }
   );
 
@@ -320,14 +371,41 @@ const initializeServer = async (): Promise<AppState> => {
         'Index markdown files for documentation search. Standalone from code indexing. Use for syntax.md, Context7-fetched docs, or any reference documentation.',
       inputSchema: toMcpSchema(IndexDocumentationSchema),
     },
-    async (params: IndexDocumentationInput) => indexDocumentationMCP(db.getPool(), ollama, config, params)
+    async (params: IndexDocumentationInput) => indexDocumentationMCP(db.getPool(), embedder, config, params)
+  );
+
+  // 10. add_document - Index content that is not on disk (ephemeral)
+  server.registerTool(
+    'add_document',
+    {
+      description:
+        'Index content that is not on disk (editor buffers, generated code, fetched gists) into a temporary index named by name, queryable right away with that name as the repo filter. Documents added to the same name accumulate; re-adding a path replaces it. The index expires a day after the last addition.',
+      inputSchema: toMcpSchema(AddDocumentSchema),
+    },
+    async (params: AddDocumentInput) => {
+      if (shutdownController.signal.aborted) {
+        throw new Error('cindex is shutting down; retry add_document after it restarts');
+      }
+      const started = Date.now();
+      const run = addDocumentMCP(config, db, embedder, params);
+      activeIndexing.add(run);
+      const result = await run.finally(() => activeIndexing.delete(run));
+      recordUsage(config, {
+        kind: 'index',
+        source: 'mcp',
+        operation: 'add_document',
+        duration_ms: Date.now() - started,
+        repo_id: params.name,
+      });
+      return result;
+    }
   );
 
   // ===================
   // List/Discovery Tools (4)
   // ===================
 
-  // 10. list_indexed_repos - All indexed repositories
+  // 11. list_indexed_repos - All indexed repositories
   server.registerTool(
     'list_indexed_repos',
     {
@@ -338,7 +416,7 @@ const initializeServer = async (): Promise<AppState> => {
     async (params: ListIndexedReposInput) => listIndexedReposMCP(db.getPool(), params)
   );
 
-  // 11. list_workspaces - Monorepo workspaces/packages
+  // 12. list_workspaces - Monorepo workspaces/packages
   server.registerTool(
     'list_workspaces',
     {
@@ -349,7 +427,7 @@ const initializeServer = async (): Promise<AppState> => {
     async (params: ListWorkspacesInput) => listWorkspacesMCP(db.getPool(), params)
   );
 
-  // 12. list_services - Microservices across repos
+  // 13. list_services - Microservices across repos
   server.registerTool(
     'list_services',
     {
@@ -360,7 +438,7 @@ const initializeServer = async (): Promise<AppState> => {
     async (params: ListServicesInput) => listServicesMCP(db.getPool(), params)
   );
 
-  // 13. list_documentation - Indexed markdown docs
+  // 14. list_documentation - Indexed markdown docs
   server.registerTool(
     'list_documentation',
     {
@@ -374,7 +452,7 @@ const initializeServer = async (): Promise<AppState> => {
   // Cross-Reference Tools (2)
   // ===================
 
-  // 14. find_cross_workspace_usages - Track package imports across monorepo
+  // 15. find_cross_workspace_usages - Track package imports across monorepo
   server.registerTool(
     'find_cross_workspace_usages',
     {
@@ -385,7 +463,7 @@ const initializeServer = async (): Promise<AppState> => {
     async (params: FindCrossWorkspaceUsagesInput) => findCrossWorkspaceUsagesMCP(db.getPool(), params)
   );
 
-  // 15. find_cross_service_calls - Track inter-service API calls
+  // 16. find_cross_service_calls - Track inter-service API calls
   server.registerTool(
     'find_cross_service_calls',
     {
@@ -396,11 +474,59 @@ const initializeServer = async (): Promise<AppState> => {
     async (params: FindCrossServiceCallsInput) => findCrossServiceCallsMCP(db.getPool(), params)
   );
 
+  // ===================
+  // Navigation Tools (4)
+  // ===================
+
+  // 17. search_symbols - Fuzzy, ranked symbol names
+  server.registerTool(
+    'search_symbols',
+    {
+      description:
+        'Find symbols by a fuzzy name: the characters in order, ignoring case (NAS or newauthsvc finds NewAuthService). Use when you know roughly what a function, type, or method is called. Returns ranked symbols with kind, scope, and file:line; pass near (a directory) to rank symbols close to the code you are working on first.',
+      inputSchema: toMcpSchema(SearchSymbolsSchema),
+    },
+    async (params: SearchSymbolsInput) => searchSymbolsMCP(db.getPool(), params)
+  );
+
+  // 18. get_definition - Declaration of an identifier
+  server.registerTool(
+    'get_definition',
+    {
+      description:
+        'Jump to the declaration of an identifier you are reading. Pass the file_path and line where it is used: for Go repositories indexed with --typed the type checker resolves it exactly (methods, renamed imports); otherwise declarations with that name are returned, the same file first. Returns signatures with file:line.',
+      inputSchema: toMcpSchema(GetDefinitionSchema),
+    },
+    async (params: GetDefinitionInput) => getDefinitionMCP(db.getPool(), params)
+  );
+
+  // 19. get_references - Uses of a declaration
+  server.registerTool(
+    'get_references',
+    {
+      description:
+        'List every use of a function, type, method, or field - use BEFORE changing a signature or renaming. Type-checked for Go repositories indexed with --typed (qualify as Type.Method or pkg.Name to narrow); whole-word matches of the indexed files otherwise. Returns file:line:column per use, grouped by file.',
+      inputSchema: toMcpSchema(GetReferencesSchema),
+    },
+    async (params: GetReferencesInput) => getReferencesMCP(db.getPool(), params)
+  );
+
+  // 20. get_file_outline - Declarations of a file, nested
+  server.registerTool(
+    'get_file_outline',
+    {
+      description:
+        'Outline a file: its package, and the types, functions, methods, and constants it declares as a tree (methods under their type, Go methods under their receiver), with lines and signatures. Use to find your way around a large file before reading it. Accepts stored, absolute, or trailing paths (auth/login.go).',
+      inputSchema: toMcpSchema(GetFileOutlineSchema),
+    },
+    async (params: GetFileOutlineInput) => getFileOutlineMCP(db.getPool(), params)
+  );
+
   // ===================
   // Delete Tools (2)
   // ===================
 
-  // 16. delete_repository - Remove repo and all data (destructive)
+  // 21. delete_repository - Remove repo and all data (destructive)
   server.registerTool(
     'delete_repository',
     {
@@ -408,10 +534,10 @@ const initializeServer = async (): Promise<AppState> => {
         'Delete one or more indexed repositories and all associated data. IMPORTANT: ALWAYS ask user for explicit confirmation before executing - this is destructive and cannot be undone.',
       inputSchema: toMcpSchema(DeleteRepositorySchema),
     },
-    async (params: DeleteRepositoryInput) => deleteRepositoryMCP(db.getPool(), params)
+    async (params: DeleteRepositoryInput) => deleteRepositoryMCP(db.getPool(), params, config.store)
   );
 
-  // 17. delete_documentation - Remove indexed docs (destructive)
+  // 22. delete_documentation - Remove indexed docs (destructive)
   server.registerTool(
     'delete_documentation',
     {
@@ -422,12 +548,79 @@ const initializeServer = async (): Promise<AppState> => {
   );
 
   logger.info('MCP server initialized successfully');
-  return { config, db, ollama, server };
+  return { config, db, ollama, embedder, server };
+};
+
+/**
+ * Verify stored chunks on startup and rebuild corrupt files in the background
+ *
+ * Each affected repository is re-indexed incrementally with its stored name,
+ * type, and metadata; the run quarantines the corrupt files under the write
+ * lock, so only they (and any changed files) are rebuilt. Queries keep working
+ * meanwhile; they just miss those files. A repository another process is
+ * writing is left alone: its files recorded but not yet chunked look truncated.
+ */
+const rebuildCorruptFiles = async (state: AppState): Promise<void> => {
+  const corrupt = await findCorruptFiles(state.db);
+  for (const target of await findRebuildTargets(state.db, corrupt)) {
+    if (shutdownController.signal.aborted) return;
+
+    const writer = findIndexWriter(target.repo_id ?? path.basename(target.repo_path));
+    if (writer) {
+      logger.info('Skipping integrity rebuild of an index being written', { repo: target.repo_path, pid: writer.pid });
+      continue;
+    }
+    const files = corrupt.filter((file) => file.repo_path === target.repo_path).length;
+    logger.warn(`Rebuilding ${String(files)} corrupt files in ${target.repo_path}`, { repo_id: target.repo_id });
+
+    const { indexing } = state.config;
+    const options: IndexingOptions = {
+      incremental: true,
+      repoId: target.repo_id,
+      repoName: target.repo_name,
+      repoType: target.repo_type,
+      metadata: (target.metadata as Record<string, unknown> | null | undefined) ?? undefined,
+      symlinkPolicy: indexing.symlink_policy,
+      generatedFiles: indexing.generated_files,
+      excludeDirectories: indexing.exclude_directories,
+      maxDirectoryDepth: indexing.max_directory_depth,
+      maxPathLength: indexing.max_path_length,
+      scanSecrets: indexing.scan_secrets,
+      signal: shutdownController.signal,
+    };
+    const run = createOrchestrator(target.repo_path, options).indexRepository(target.repo_path, options);
+    activeIndexing.add(run);
+    try {
+      await run;
+    } catch (error) {
+      // Quarantined files stay out of results until the next successful incremental run
+      logger.warn('Rebuild of corrupt files failed; re-index the repository with incremental: true', {
+        repo: target.repo_path,
+        error: error instanceof Error ? error.message : String(error),
+      });
+    } finally {
+      activeIndexing.delete(run);
+    }
+  }
 };
 
-/** Graceful shutdown handler - closes database connections and flushes logs */
+/**
+ * Graceful shutdown handler
+ *
+ * Stops in-flight indexing at the next file boundary (each writes a checkpoint),
+ * then closes database connections and flushes logs.
+ */
 const shutdown = async (signal: string): Promise<void> => {
   logger.info(`Received ${signal}, shutting down...`);
+  shutdownController.abort();
+
+  if (activeIndexing.size > 0) {
+    logger.info('Waiting for indexing to finish the current file', { runs: activeIndexing.size });
+    const timeout = new Promise<void>((resolve) => {
+      setTimeout(resolve, SHUTDOWN_TIMEOUT_MS).unref();
+    });
+    await Promise.race([Promise.allSettled(activeIndexing), timeout]);
+  }
 
   if (appState) {
     try {
@@ -438,6 +631,7 @@ const shutdown = async (signal: string): Promise<void> => {
     }
   }
 
+  await flushTraces();
   logger.shutdown();
   process.exit(0);
 };
@@ -445,16 +639,21 @@ const shutdown = async (signal: string): Promise<void> => {
 /** Main entry point - initialize server and connect stdio transport */
 const main = async (): Promise<void> => {
   try {
-    appState = await initializeServer();
+    const state = await initializeServer();
+    appState = state;
 
-    process.on('SIGINT', () => void shutdown('SIGINT'));
-    process.on('SIGTERM', () => void shutdown('SIGTERM'));
+    handleShutdownSignals((signal) => void shutdown(signal));
 
     const transport = new StdioServerTransport();
-    await appState.server.connect(transport);
+    await state.server.connect(transport);
 
     logger.info('cindex MCP server is ready');
     logger.info('Waiting for requests...');
+
+    // Runs after the transport is up so a large check never delays the first request
+    rebuildCorruptFiles(state).catch((error: unknown) => {
+      logger.warn('Index integrity check failed', { error: error instanceof Error ? error.message : String(error) });
+    });
   } catch (error) {
     if (error instanceof CindexError) {
       console.error('\n' + error.getFormattedMessage());
@@ -468,4 +667,18 @@ const main = async (): Promise<void> => {
   }
 };
 
-void main();
+/** Entry point - start the MCP server without a command or with `mcp`, otherwise run a CLI subcommand */
+const argv = process.argv.slice(2);
+if (argv.length === 1 && argv[0] === 'mcp') {
+  void main();
+} else if (isCliInvocation(argv)) {
+  // Spans still queued are exported before exiting
+  runCli(argv)
+    .then(async (code) => {
+      await flushTraces();
+      process.exit(code);
+    })
+    .catch((error: unknown) => process.exit(reportUncaughtError(error)));
+} else {
+  void main();
+}
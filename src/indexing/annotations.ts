@@ -0,0 +1,152 @@
+/**
+ * Annotations read from comments: TODOs, deprecations, Go directives, and build tags
+ *
+ *   // TODO(ada): drop the v1 fallback       todo        TODO, assignee ada
+ *   # FIXME: retries never back off          todo        FIXME
+ *   // Deprecated: Use SignIn instead.       deprecated  Deprecated (Go convention)
+ *   /** @deprecated since 2.0 *\/             deprecated  @deprecated (JSDoc, Javadoc, PHPDoc)
+ *   //go:generate stringer -type=Role        directive   go:generate
+ *   //go:build linux && !race                build       go:build
+ *
+ * Markers count only at the start of a comment (after its markers), so prose
+ * mentioning a todo list is not one. An annotation in a symbol's doc comment
+ * belongs to that symbol, one inside a declaration to the innermost symbol
+ * around it; build constraints and go:generate lines belong to the file.
+ */
+import { extractComments, type SourceComment } from '@indexing/file-metrics';
+import { utf16ToByteColumn } from '@utils/positions';
+import { Language, type Annotation, type ExtractedSymbol } from '@/types/indexing';
+
+/** Symbol fields annotations are attached with */
+export type AnnotatedSymbol = Pick<ExtractedSymbol, 'symbol_name' | 'symbol_type' | 'line_number' | 'end_line'>;
+
+/** Work marker, optional assignee in parentheses, optional colon */
+const TODO = /^(TODO|FIXME|HACK|XXX|BUG)\b(?:\(\s*@?([^)]*?)\s*\))?:?\s*(.*)$/;
+
+/** Deprecation notice: Go's paragraph, or a doc tag */
+const DEPRECATED = /^(?:(Deprecated):|(@deprecated)\b)\s*(.*)$/;
+
+/** Go directive: //go:name with no space after the slashes */
+const GO_DIRECTIVE = /^go:([a-z]\w*)\s*(.*)$/;
+
+/** Go build constraint in the pre-1.17 form */
+const PLUS_BUILD = /^\+build\s+(.*)$/;
+
+/** Lines allowed between a doc comment and its declaration: decorators, annotations, Rust attributes */
+const DECORATOR = /^\s*(?:@|#\[)/;
+
+/**
+ * Comment text without its leading markers (JSDoc stars, Rust's extra slash) and whitespace
+ */
+const bodyOf = (comment: SourceComment): string => comment.text.replace(/^\s*[/*!#]*\s*/, '').trimEnd();
+
+/**
+ * Read the annotation a comment starts, if any
+ */
+const readAnnotation = (
+  comment: SourceComment,
+  language: string
+): Pick<Annotation, 'kind' | 'tag' | 'assignee' | 'text'> | null => {
+  if (language === Language.Go) {
+    const directive = GO_DIRECTIVE.exec(comment.text);
+    if (directive) {
+      const tag = `go:${directive[1]}`;
+      return { kind: tag === 'go:build' ? 'build' : 'directive', tag, assignee: null, text: directive[2].trim() };
+    }
+  }
+
+  const body = bodyOf(comment);
+  const todo = TODO.exec(body);
+  if (todo) return { kind: 'todo', tag: todo[1], assignee: todo[2] || null, text: todo[3] };
+  const deprecated = DEPRECATED.exec(body);
+  if (deprecated) {
+    return { kind: 'deprecated', tag: deprecated[1] || deprecated[2], assignee: null, text: deprecated[3] };
+  }
+  const plusBuild = language === Language.Go ? PLUS_BUILD.exec(body) : null;
+  if (plusBuild) return { kind: 'build', tag: '+build', assignee: null, text: plusBuild[1] };
+  return null;
+};
+
+/**
+ * Symbol an annotation on a line is attached to
+ *
+ * @param line - Line of the annotation
+ * @param blockEnd - Last line of the run of whole-line comments holding it (null for a trailing comment)
+ * @param lines - Lines of the file
+ * @param symbols - Symbols of the file
+ */
+const attachedSymbol = (
+  line: number,
+  blockEnd: number | null,
+  lines: string[],
+  symbols: AnnotatedSymbol[]
+): AnnotatedSymbol | null => {
+  if (blockEnd !== null) {
+    let declaration = blockEnd + 1;
+    while (declaration <= lines.length && DECORATOR.test(lines[declaration - 1])) declaration++;
+    const documented = symbols.find((symbol) => symbol.line_number > blockEnd && symbol.line_number <= declaration);
+    if (documented) return documented;
+  }
+
+  let innermost: AnnotatedSymbol | null = null;
+  for (const symbol of symbols) {
+    if (symbol.line_number > line || symbol.end_line < line) continue;
+    if (!innermost || symbol.line_number > innermost.line_number || symbol.end_line < innermost.end_line) {
+      innermost = symbol;
+    }
+  }
+  return innermost;
+};
+
+/**
+ * Extract the annotations of a file
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @param symbols - Symbols extracted from the file (empty attaches everything to the file)
+ * @returns Annotations in line order
+ */
+export const extractAnnotations = (content: string, language: string, symbols: AnnotatedSymbol[]): Annotation[] => {
+  const comments = extractComments(content, language);
+  if (comments.length === 0) return [];
+  const lines = content.split('\n');
+  const wholeLines = new Set(comments.filter((comment) => comment.own_line).map((comment) => comment.line));
+
+  const annotations: Annotation[] = [];
+  for (let i = 0; i < comments.length; i++) {
+    const comment = comments[i];
+    const annotation = readAnnotation(comment, language);
+    if (!annotation) continue;
+
+    // A Deprecated: paragraph runs to the first empty comment line
+    if (annotation.kind === 'deprecated') {
+      const parts = [annotation.text];
+      for (let next = i + 1; next < comments.length; next++) {
+        const following = comments[next];
+        const body = bodyOf(following);
+        if (following.line !== comments[next - 1].line + 1 || !following.own_line || body === '') break;
+        if (readAnnotation(following, language)) break;
+        parts.push(body);
+      }
+      annotation.text = parts.filter(Boolean).join(' ');
+    }
+
+    let blockEnd: number | null = null;
+    if (comment.own_line) {
+      blockEnd = comment.line;
+      while (wholeLines.has(blockEnd + 1)) blockEnd++;
+    }
+    const fileLevel = annotation.kind === 'build' || annotation.tag === 'go:generate';
+    const symbol = fileLevel ? null : attachedSymbol(comment.line, blockEnd, lines, symbols);
+
+    annotations.push({
+      ...annotation,
+      line: comment.line,
+      column: comment.column,
+      byte_column: utf16ToByteColumn(lines[comment.line - 1], comment.column),
+      symbol_name: symbol?.symbol_name ?? null,
+      symbol_type: symbol?.symbol_type ?? null,
+    });
+  }
+  return annotations;
+};
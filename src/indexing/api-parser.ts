@@ -4,7 +4,6 @@
  * Parses OpenAPI/Swagger, GraphQL schemas, and gRPC protobufs
  */
 
-import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
 // eslint-disable-next-line @typescript-eslint/naming-convention
@@ -12,6 +11,7 @@ import SwaggerParser from '@apidevtools/swagger-parser';
 import { buildSchema, type GraphQLField, type GraphQLObjectType, type GraphQLSchema } from 'graphql';
 import protobuf from 'protobufjs';
 
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
 import {
   type APIParser,
@@ -510,7 +510,7 @@ export class APISpecificationParser {
   public parseFile = async (filePath: string): Promise<APIParsingResult | null> => {
     try {
       // Read file content
-      const content = await fs.readFile(filePath, 'utf-8');
+      const content = await readTextFile(filePath);
 
       // Find appropriate parser
       const parser = this.parsers.find((p) => p.canParse(filePath));
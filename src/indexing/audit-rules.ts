@@ -0,0 +1,365 @@
+/**
+ * Audit rules: security-sensitive patterns in indexed code (cindex audit)
+ *
+ * A rule matches calls of the Go call graph, lines of source, or both, and
+ * can be narrowed to the functions it applies in:
+ *
+ *   audit:
+ *     disable: [predictable_session_id]
+ *     rules:
+ *       - id: shell_exec
+ *         severity: high                   # low, medium, high, or critical
+ *         message: Command run through a shell
+ *         calls: [os/exec.Command]         # package.Name, Type.Method, or Name; * matches anything
+ *         line: '"(?:ba)?sh"'              # the line of a call must match too
+ *         pattern: 'child_process\.exec\(' # JavaScript regex, per source line
+ *         function: 'handler|serve'        # enclosing function or method
+ *         languages: [go, typescript]
+ *         ignore_case: true                # for every regex of the rule
+ *
+ * Rules come from the audit mapping of .cindex.yaml files (nearer files
+ * win) or of a --rules file, and add to the built-in ones; a rule with a
+ * built-in id replaces it. Matching is textual: a rule finds the shape of a
+ * mistake, not proof of one, and comment lines are never matched.
+ */
+import { byteToUtf16Column, utf16ToByteColumn } from '@utils/positions';
+import { type FunctionSpanRecord, type GoCallRecord, type SourceFileRecord } from '@/types/database';
+
+/** Severity of a rule, lowest first */
+export type AuditSeverity = 'low' | 'medium' | 'high' | 'critical';
+
+/** Severities, lowest first */
+export const AUDIT_SEVERITIES: readonly AuditSeverity[] = ['low', 'medium', 'high', 'critical'];
+
+/**
+ * One audit rule (see the module comment for the YAML form)
+ */
+export interface AuditRule {
+  id: string;
+  severity: AuditSeverity;
+  message: string;
+  /** Callees of the Go call graph */
+  calls?: string[];
+  /** Regex the line of a call must match too */
+  line?: string;
+  /** Regex matched against each source line */
+  pattern?: string;
+  /** Regex the enclosing function or method name must match */
+  function?: string;
+  /** Languages the rule applies to (default: all) */
+  languages?: string[];
+  ignore_case?: boolean;
+}
+
+/**
+ * Rule settings of one config file
+ */
+export interface AuditConfig {
+  rules: AuditRule[];
+  /** Ids of rules turned off */
+  disable: string[];
+}
+
+/**
+ * What the rules are matched against
+ */
+export interface AuditSource {
+  files: SourceFileRecord[];
+  calls: GoCallRecord[];
+  /** Functions, methods, and classes, to find the one a match is in */
+  spans: FunctionSpanRecord[];
+}
+
+/**
+ * One match of a rule
+ */
+export interface AuditFinding {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  column_number: number; // UTF-16 code units
+  byte_column: number; // UTF-8 bytes
+  rule: string;
+  severity: AuditSeverity;
+  message: string;
+  /** Innermost function, method, or class around the match */
+  symbol_name: string | null;
+  /** Matched line, trimmed */
+  text: string;
+}
+
+/** Function names a session ID or other secret value is made in */
+const SECRET_VALUE_FUNCTION = /session|token|nonce|secret|salt|otp|csrf|api_?key/.source;
+
+/** Built-in rules */
+export const DEFAULT_AUDIT_RULES: readonly AuditRule[] = [
+  {
+    id: 'sql_string_concat',
+    severity: 'high',
+    message: 'SQL built by string concatenation; pass the values as query parameters',
+    pattern: [
+      /\b(?:SELECT|INSERT|UPDATE|DELETE)\b[^"`]*"\s*\+/.source,
+      /\b(?:SELECT|INSERT|UPDATE|DELETE)\b[^'`]*'\s*\+/.source,
+      /\+\s*["'`]\s*(?:WHERE|AND|OR|VALUES|SET|FROM|LIMIT|ORDER BY)\b/.source,
+      /Sprintf\(\s*["`](?:SELECT|INSERT|UPDATE|DELETE)\b[^"`]*%[sdv]/.source,
+      /\bf["'](?:SELECT|INSERT|UPDATE|DELETE)\b[^"']*\{/.source,
+    ].join('|'),
+  },
+  {
+    id: 'plaintext_password_compare',
+    severity: 'high',
+    message: 'Password compared as plain text; compare a salted hash in constant time',
+    pattern: [
+      /\b\w*passw(?:or)?d\w*\s*(?:===?|!==?)\s*(?!nil\b|null\b|undefined\b|none\b|""|''|\d)[\w.]/.source,
+      /[\w.)\]]\s*(?:===?|!==?)\s*\w*passw(?:or)?d\w*\b/.source,
+      /\b\w*passw(?:or)?d\w*\.equals\(/.source,
+    ].join('|'),
+    ignore_case: true,
+  },
+  {
+    id: 'weak_random_session',
+    severity: 'medium',
+    message: 'Secret value from a predictable random generator; use a cryptographic one',
+    calls: ['math/rand.*', 'math/rand/v2.*'],
+    pattern: [
+      /\bMath\.random\(/.source,
+      /\brandom\.(?:random|randint|randrange|choices?|getrandbits)\(/.source,
+      /\bnew Random\(|\brand\(\)/.source,
+    ].join('|'),
+    function: SECRET_VALUE_FUNCTION,
+    ignore_case: true,
+  },
+  {
+    id: 'predictable_session_id',
+    severity: 'medium',
+    message: 'Secret value derived from the clock; use a cryptographic random generator',
+    calls: ['time.Now'],
+    line: /\.Unix(?:Nano|Micro|Milli)?\(/.source,
+    pattern: [
+      /\bDate\.now\(\)/.source,
+      /\btime\.time(?:_ns)?\(\)/.source,
+      /\bSystem\.(?:currentTimeMillis|nanoTime)\(\)/.source,
+    ].join('|'),
+    function: SECRET_VALUE_FUNCTION,
+    ignore_case: true,
+  },
+];
+
+/** Lines that are comments in the indexed languages */
+const COMMENT_LINE = /^\s*(?:\/\/|\/\*|\*|#(?!include|define|if|pragma))/;
+
+/**
+ * Whether a value is a severity
+ */
+export const isAuditSeverity = (value: string): value is AuditSeverity =>
+  (AUDIT_SEVERITIES as readonly string[]).includes(value);
+
+/**
+ * Rank of a severity (low is 0)
+ */
+export const severityRank = (severity: AuditSeverity): number => AUDIT_SEVERITIES.indexOf(severity);
+
+/**
+ * Read a list of strings, or throw naming the field
+ */
+const readStrings = (value: unknown, field: string): string[] | undefined => {
+  if (value === undefined) return undefined;
+  const list = typeof value === 'string' ? [value] : value;
+  if (!Array.isArray(list) || !list.every((item) => typeof item === 'string')) {
+    throw new Error(`${field} must be a string or a list of strings`);
+  }
+  return list as string[];
+};
+
+/**
+ * Read an optional regex, or throw naming the field
+ */
+const readRegex = (value: unknown, field: string): string | undefined => {
+  if (value === undefined) return undefined;
+  if (typeof value !== 'string') throw new Error(`${field} must be a string`);
+  try {
+    new RegExp(value);
+  } catch (error) {
+    throw new Error(`${field} is not a valid regex: ${error instanceof Error ? error.message : String(error)}`);
+  }
+  return value;
+};
+
+/**
+ * Read one rule of a config
+ */
+const readRule = (raw: unknown, index: number): AuditRule => {
+  if (typeof raw !== 'object' || raw === null || Array.isArray(raw)) {
+    throw new Error(`rules[${String(index)}] must be a mapping`);
+  }
+  const entry = raw as Record<string, unknown>;
+  const id = entry.id;
+  if (typeof id !== 'string' || !/^[\w.-]+$/.test(id)) {
+    throw new Error(`rules[${String(index)}].id must be a name of letters, digits, _ . or -`);
+  }
+
+  const severity = entry.severity ?? 'medium';
+  if (typeof severity !== 'string' || !isAuditSeverity(severity)) {
+    throw new Error(`${id}: severity must be one of ${AUDIT_SEVERITIES.join(', ')}`);
+  }
+  if (entry.message !== undefined && typeof entry.message !== 'string') {
+    throw new Error(`${id}: message must be a string`);
+  }
+  if (entry.ignore_case !== undefined && typeof entry.ignore_case !== 'boolean') {
+    throw new Error(`${id}: ignore_case must be true or false`);
+  }
+
+  const rule: AuditRule = {
+    id,
+    severity,
+    message: typeof entry.message === 'string' ? entry.message : id,
+    calls: readStrings(entry.calls, `${id}: calls`),
+    line: readRegex(entry.line, `${id}: line`),
+    pattern: readRegex(entry.pattern, `${id}: pattern`),
+    function: readRegex(entry.function, `${id}: function`),
+    languages: readStrings(entry.languages, `${id}: languages`),
+    ignore_case: entry.ignore_case as boolean | undefined,
+  };
+  if (!rule.calls?.length && !rule.pattern) throw new Error(`${id}: needs calls, a pattern, or both`);
+  return rule;
+};
+
+/**
+ * Read the audit mapping of a config file
+ *
+ * @param doc - Parsed YAML mapping (the audit key of .cindex.yaml, or a whole --rules file)
+ * @returns Rules and disabled ids
+ * @throws {Error} If a field is missing or of the wrong type, or a regex does not compile
+ */
+export const parseAuditConfig = (doc: unknown): AuditConfig => {
+  if (doc === null || doc === undefined) return { rules: [], disable: [] };
+  if (typeof doc !== 'object' || Array.isArray(doc)) throw new Error('audit must be a mapping');
+  const { rules = [], disable } = doc as Record<string, unknown>;
+  if (!Array.isArray(rules)) throw new Error('rules must be a list');
+  return { rules: rules.map(readRule), disable: readStrings(disable, 'disable') ?? [] };
+};
+
+/**
+ * Rules in effect: the built-in ones with the configs applied
+ *
+ * @param configs - Configs, nearest first (a nearer config overrides a farther one)
+ * @returns Rules by id order of first appearance
+ */
+export const resolveAuditRules = (configs: AuditConfig[]): AuditRule[] => {
+  const rules = new Map(DEFAULT_AUDIT_RULES.map((rule) => [rule.id, rule]));
+  for (const config of [...configs].reverse()) {
+    for (const id of config.disable) rules.delete(id);
+    for (const rule of config.rules) rules.set(rule.id, rule);
+  }
+  return [...rules.values()];
+};
+
+/**
+ * Regex of a callee glob (* matches any run of characters)
+ */
+const globRegex = (glob: string, flags: string): RegExp =>
+  new RegExp(`^${glob.split('*').map((part) => part.replace(/[.+?^${}()|[\]\\/]/g, '\\$&')).join('.*')}$`, flags);
+
+/**
+ * Name a call is matched by: package.Name for imported functions, the callee otherwise
+ */
+const calleeName = (call: GoCallRecord): string =>
+  call.call_kind === 'import' && call.callee_package ? `${call.callee_package}.${call.callee_name}` : call.callee_name;
+
+/**
+ * Innermost function, method, or class holding a line
+ */
+const enclosingSymbol = (spans: FunctionSpanRecord[], line: number): string | null => {
+  let inner: FunctionSpanRecord | null = null;
+  for (const span of spans) {
+    if (span.line_number > line) break;
+    if ((span.end_line ?? span.line_number) >= line && (!inner || span.line_number >= inner.line_number)) {
+      inner = span;
+    }
+  }
+  return inner?.symbol_name ?? null;
+};
+
+/**
+ * Match rules against indexed files and calls
+ *
+ * @param source - Files, Go calls, and symbol spans of the indexes audited
+ * @param rules - Rules to match (see resolveAuditRules)
+ * @returns Findings ordered by file, line, and rule; one per rule and line
+ */
+export const runAudit = (source: AuditSource, rules: readonly AuditRule[]): AuditFinding[] => {
+  const lines = new Map(source.files.map((file) => [file, file.content.split('\n')]));
+  const files = new Map(source.files.map((file) => [`${file.repo_id ?? ''}\0${file.file_path}`, file]));
+  const spansByFile = new Map<string, FunctionSpanRecord[]>();
+  for (const span of source.spans) {
+    const spans = spansByFile.get(span.file_path) ?? [];
+    spans.push(span);
+    spansByFile.set(span.file_path, spans);
+  }
+  for (const spans of spansByFile.values()) spans.sort((a, b) => a.line_number - b.line_number);
+
+  const findings = new Map<string, AuditFinding>();
+  const add = (rule: AuditRule, file: SourceFileRecord, line: number, byteColumn: number, column: number): void => {
+    const key = `${file.repo_id ?? ''}\0${file.file_path}\0${String(line)}\0${rule.id}`;
+    if (findings.has(key)) return;
+    const text = lines.get(file)?.[line - 1] ?? '';
+    findings.set(key, {
+      repo_id: file.repo_id,
+      file_path: file.file_path,
+      line_number: line,
+      column_number: column,
+      byte_column: byteColumn,
+      rule: rule.id,
+      severity: rule.severity,
+      message: rule.message,
+      symbol_name: enclosingSymbol(spansByFile.get(file.file_path) ?? [], line),
+      text: text.trim(),
+    });
+  };
+
+  for (const rule of rules) {
+    const flags = rule.ignore_case ? 'i' : '';
+    const inFunction = rule.function ? new RegExp(rule.function, flags) : null;
+    const applies = (file: SourceFileRecord): boolean => !rule.languages || rule.languages.includes(file.language);
+
+    if (rule.calls?.length) {
+      const callees = rule.calls.map((glob) => globRegex(glob, flags));
+      const line = rule.line ? new RegExp(rule.line, flags) : null;
+      for (const call of source.calls) {
+        const file = files.get(`${call.repo_id ?? ''}\0${call.file_path}`);
+        if (!file || !applies(file)) continue;
+        if (inFunction && !inFunction.test(call.caller_name)) continue;
+        const name = calleeName(call);
+        if (!callees.some((callee) => callee.test(name))) continue;
+        const text = lines.get(file)?.[call.line_number - 1] ?? '';
+        if (line && !line.test(text)) continue;
+        add(rule, file, call.line_number, call.column_number, byteToUtf16Column(text, call.column_number));
+      }
+    }
+
+    if (rule.pattern) {
+      const pattern = new RegExp(rule.pattern, flags);
+      for (const file of source.files) {
+        if (!applies(file)) continue;
+        const spans = spansByFile.get(file.file_path) ?? [];
+        lines.get(file)?.forEach((text, index) => {
+          if (COMMENT_LINE.test(text)) return;
+          const match = pattern.exec(text);
+          if (!match) return;
+          const symbol = enclosingSymbol(spans, index + 1);
+          if (inFunction && (symbol === null || !inFunction.test(symbol))) return;
+          const column = match.index + 1;
+          add(rule, file, index + 1, utf16ToByteColumn(text, column), column);
+        });
+      }
+    }
+  }
+
+  return [...findings.values()].sort(
+    (a, b) =>
+      (a.repo_id ?? '').localeCompare(b.repo_id ?? '') ||
+      a.file_path.localeCompare(b.file_path) ||
+      a.line_number - b.line_number ||
+      a.rule.localeCompare(b.rule)
+  );
+};
@@ -0,0 +1,328 @@
+/**
+ * Go build constraints: which platforms a file is compiled for
+ *
+ * A Go file is limited to some GOOS/GOARCH pairs by its name
+ * (file_linux.go, file_windows_amd64.go) and by a //go:build line before
+ * the package clause (older files: // +build lines). Syntax indexing reads
+ * every variant regardless, and each file records its constraint as one
+ * expression (`linux && (amd64 || arm64)`), so the platform matrix of a
+ * symbol (`cindex platforms`) is evaluated from the index alone.
+ *
+ * Tags are matched the way go/build does for the default gc toolchain:
+ * GOOS and GOARCH, the GOOS implied by another (android is linux, ios is
+ * darwin, illumos is solaris), unix, gc, and go1.N release tags. cgo and
+ * custom tags only match when asked for.
+ */
+
+import { type Platform } from '@/types/indexing';
+
+/** GOOS values recognized in file names (go/build syslist) */
+const KNOWN_OS = new Set([
+  'aix',
+  'android',
+  'darwin',
+  'dragonfly',
+  'freebsd',
+  'hurd',
+  'illumos',
+  'ios',
+  'js',
+  'linux',
+  'nacl',
+  'netbsd',
+  'openbsd',
+  'plan9',
+  'solaris',
+  'wasip1',
+  'windows',
+  'zos',
+]);
+
+/** GOOS values matching the unix tag */
+const UNIX_OS = new Set([
+  'aix',
+  'android',
+  'darwin',
+  'dragonfly',
+  'freebsd',
+  'hurd',
+  'illumos',
+  'ios',
+  'linux',
+  'netbsd',
+  'openbsd',
+  'solaris',
+]);
+
+/** GOARCH values recognized in file names (go/build syslist) */
+const KNOWN_ARCH = new Set([
+  '386',
+  'amd64',
+  'amd64p32',
+  'arm',
+  'armbe',
+  'arm64',
+  'arm64be',
+  'loong64',
+  'mips',
+  'mipsle',
+  'mips64',
+  'mips64le',
+  'mips64p32',
+  'mips64p32le',
+  'ppc',
+  'ppc64',
+  'ppc64le',
+  'riscv',
+  'riscv64',
+  's390',
+  's390x',
+  'sparc',
+  'sparc64',
+  'wasm',
+]);
+
+/** GOOS matched by a tag naming another GOOS */
+const IMPLIED_OS: Record<string, string> = { android: 'linux', ios: 'darwin', illumos: 'solaris' };
+
+/** Platforms a matrix covers by default: the first-class ports and the common others */
+export const DEFAULT_PLATFORMS: Platform[] = [
+  { os: 'linux', arch: 'amd64' },
+  { os: 'linux', arch: 'arm64' },
+  { os: 'linux', arch: '386' },
+  { os: 'linux', arch: 'arm' },
+  { os: 'darwin', arch: 'amd64' },
+  { os: 'darwin', arch: 'arm64' },
+  { os: 'windows', arch: 'amd64' },
+  { os: 'windows', arch: 'arm64' },
+  { os: 'windows', arch: '386' },
+  { os: 'freebsd', arch: 'amd64' },
+  { os: 'js', arch: 'wasm' },
+  { os: 'wasip1', arch: 'wasm' },
+];
+
+/**
+ * Parsed constraint expression
+ */
+export type ConstraintNode =
+  | { op: 'tag'; name: string }
+  | { op: 'not'; operand: ConstraintNode }
+  | { op: 'and' | 'or'; left: ConstraintNode; right: ConstraintNode };
+
+/**
+ * Format a platform as GOOS/GOARCH
+ */
+export const platformName = (platform: Platform): string => `${platform.os}/${platform.arch}`;
+
+/**
+ * Parse a GOOS/GOARCH pair
+ *
+ * @param value - e.g. linux/amd64
+ * @returns Platform, or null if either part is not a known GOOS or GOARCH
+ */
+export const parsePlatform = (value: string): Platform | null => {
+  const [os, arch, ...rest] = value.trim().split('/');
+  if (rest.length > 0 || !KNOWN_OS.has(os) || !arch || !KNOWN_ARCH.has(arch)) return null;
+  return { os, arch };
+};
+
+/**
+ * Parse a comma-separated list of GOOS/GOARCH pairs (--platforms)
+ *
+ * @param value - e.g. linux/amd64,windows/amd64
+ * @returns Platforms, and the entries that are not known pairs
+ */
+export const parsePlatformList = (value: string): { platforms: Platform[]; invalid: string[] } => {
+  const platforms: Platform[] = [];
+  const invalid: string[] = [];
+  for (const entry of value.split(',').map((item) => item.trim())) {
+    if (entry === '') continue;
+    const platform = parsePlatform(entry);
+    if (platform) platforms.push(platform);
+    else invalid.push(entry);
+  }
+  return { platforms, invalid };
+};
+
+/**
+ * Read the constraint of a Go file name (the _GOOS, _GOARCH, or _GOOS_GOARCH suffix)
+ *
+ * @param filePath - File path (only the base name is read)
+ * @returns Constraint expression, or null if the name carries none
+ */
+export const fileNameConstraint = (filePath: string): string | null => {
+  const base = filePath.slice(filePath.lastIndexOf('/') + 1).replace(/\.go$/, '');
+  // A name without an underscore (linux.go) is not constrained
+  const parts = base.split('_').slice(1);
+  if (parts.at(-1) === 'test') parts.pop();
+
+  const last = parts.at(-1);
+  const previous = parts.at(-2);
+  if (last && previous && KNOWN_OS.has(previous) && KNOWN_ARCH.has(last)) return `${previous} && ${last}`;
+  if (last && (KNOWN_OS.has(last) || KNOWN_ARCH.has(last))) return last;
+  return null;
+};
+
+/**
+ * Convert // +build lines to a //go:build expression
+ *
+ * Options on a line are alternatives, comma-separated terms must all hold,
+ * and every line must hold.
+ */
+const plusBuildExpression = (lines: string[]): string => {
+  const clauses = lines.map((line) => {
+    const options = line.split(/\s+/).map((option) => option.split(',').join(' && '));
+    if (options.length === 1) return options[0];
+    return options.map((option) => (option.includes('&&') ? `(${option})` : option)).join(' || ');
+  });
+  if (clauses.length === 1) return clauses[0];
+  return clauses.map((clause) => (clause.includes('||') ? `(${clause})` : clause)).join(' && ');
+};
+
+/**
+ * Read the //go:build expression of a Go file
+ *
+ * Only the comments before the package clause are read. Files with only
+ * // +build lines have them converted, as gofmt does.
+ *
+ * @param content - Go source
+ * @returns Expression as written, or null if the file has no build line
+ */
+export const goBuildExpression = (content: string): string | null => {
+  const plusBuild: string[] = [];
+  let inBlock = false;
+  for (const raw of content.split('\n')) {
+    const line = raw.trim();
+    if (inBlock) {
+      inBlock = !line.includes('*/');
+      continue;
+    }
+    if (line === '') continue;
+    if (line.startsWith('/*')) {
+      inBlock = !line.includes('*/', 2);
+      continue;
+    }
+    if (!line.startsWith('//')) break;
+
+    const goBuild = /^\/\/go:build\s+(.+)$/.exec(line);
+    if (goBuild) return goBuild[1].trim();
+    const legacy = /^\/\/\s*\+build\s+(.+)$/.exec(line);
+    if (legacy) plusBuild.push(legacy[1].trim());
+  }
+  return plusBuild.length > 0 ? plusBuildExpression(plusBuild) : null;
+};
+
+/**
+ * Combine the name and build-line constraints of a Go file
+ *
+ * @param filePath - File path relative to the repository root
+ * @param content - Go source
+ * @returns One expression covering both, or null if the file builds everywhere
+ */
+export const goBuildConstraint = (filePath: string, content: string): string | null => {
+  const fromName = fileNameConstraint(filePath);
+  const fromLine = goBuildExpression(content);
+  if (!fromName || !fromLine) return fromName ?? fromLine;
+  return `${fromName} && ${fromLine.includes('||') ? `(${fromLine})` : fromLine}`;
+};
+
+/**
+ * Parse a constraint expression (||, &&, !, parentheses, tags)
+ *
+ * @param expression - Expression such as `linux && (amd64 || arm64)`
+ * @returns Expression tree, or null if the expression is malformed
+ */
+export const parseConstraint = (expression: string): ConstraintNode | null => {
+  const tokens = expression.match(/&&|\|\||[!()]|[\p{L}\p{Nd}_.]+|\S/gu) ?? [];
+  let position = 0;
+
+  const parseOr = (): ConstraintNode | null => {
+    let left = parseAnd();
+    while (left && tokens[position] === '||') {
+      position++;
+      const right = parseAnd();
+      left = right ? { op: 'or', left, right } : null;
+    }
+    return left;
+  };
+  const parseAnd = (): ConstraintNode | null => {
+    let left = parseNot();
+    while (left && tokens[position] === '&&') {
+      position++;
+      const right = parseNot();
+      left = right ? { op: 'and', left, right } : null;
+    }
+    return left;
+  };
+  const parseNot = (): ConstraintNode | null => {
+    const token = tokens.at(position);
+    position++;
+    if (token === '!') {
+      const operand = parseNot();
+      return operand ? { op: 'not', operand } : null;
+    }
+    if (token === '(') {
+      const inner = parseOr();
+      if (tokens[position] !== ')') return null;
+      position++;
+      return inner;
+    }
+    return token && /^[\p{L}\p{Nd}_.]+$/u.test(token) ? { op: 'tag', name: token } : null;
+  };
+
+  const tree = parseOr();
+  return tree && position === tokens.length ? tree : null;
+};
+
+/**
+ * Check whether a tag is satisfied on a platform
+ */
+const matchesTag = (name: string, platform: Platform, tags: ReadonlySet<string>): boolean => {
+  if (tags.has(name) || name === platform.os || name === platform.arch) return true;
+  if (IMPLIED_OS[platform.os] === name) return true;
+  if (name === 'unix') return UNIX_OS.has(platform.os);
+  return name === 'gc' || /^go1\.\d+$/.test(name);
+};
+
+/**
+ * Evaluate a parsed constraint on a platform
+ *
+ * @param node - Parsed constraint
+ * @param platform - Target platform
+ * @param tags - Extra build tags (-tags), e.g. cgo
+ * @returns Whether a file with the constraint is compiled for the platform
+ */
+export const matchesConstraint = (node: ConstraintNode, platform: Platform, tags: ReadonlySet<string>): boolean => {
+  switch (node.op) {
+    case 'tag':
+      return matchesTag(node.name, platform, tags);
+    case 'not':
+      return !matchesConstraint(node.operand, platform, tags);
+    case 'and':
+      return matchesConstraint(node.left, platform, tags) && matchesConstraint(node.right, platform, tags);
+    case 'or':
+      return matchesConstraint(node.left, platform, tags) || matchesConstraint(node.right, platform, tags);
+  }
+};
+
+/**
+ * Find the variants of a definition compiled for each platform
+ *
+ * @param constraints - Build constraint of each variant's file (null: every platform)
+ * @param platforms - Platforms to evaluate
+ * @param tags - Extra build tags (-tags)
+ * @returns For each platform, the indexes of the variants compiled for it (malformed constraints match none)
+ */
+export const platformMatrix = (
+  constraints: (string | null)[],
+  platforms: Platform[],
+  tags: ReadonlySet<string>
+): number[][] => {
+  const parsed = constraints.map((constraint) => (constraint === null ? null : parseConstraint(constraint)));
+  return platforms.map((platform) =>
+    parsed.flatMap((node, index) => {
+      if (constraints[index] === null) return [index];
+      return node && matchesConstraint(node, platform, tags) ? [index] : [];
+    })
+  );
+};
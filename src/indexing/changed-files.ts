@@ -0,0 +1,108 @@
+/**
+ * Changed Files: Time-Window Filtering (--since)
+ *
+ * Narrows indexing or search to files changed within a window. In a git
+ * worktree, "changed" means touched by a commit since the cutoff, modified in
+ * the working tree, or untracked; elsewhere the file mtime is used.
+ */
+
+import { execFile } from 'node:child_process';
+import { promisify } from 'node:util';
+
+import { logger } from '@utils/logger';
+import { toPosixPath } from '@utils/paths';
+import { type DiscoveredFile } from '@/types/indexing';
+
+const execFileAsync = promisify(execFile);
+
+/** Duration units accepted by parseSince */
+const UNIT_MS: Record<string, number> = {
+  m: 60 * 1000,
+  h: 60 * 60 * 1000,
+  d: 24 * 60 * 60 * 1000,
+  w: 7 * 24 * 60 * 60 * 1000,
+  mo: 30 * 24 * 60 * 60 * 1000,
+  y: 365 * 24 * 60 * 60 * 1000,
+};
+
+/** Maximum git output buffered (large histories list many paths) */
+const GIT_MAX_BUFFER = 64 * 1024 * 1024;
+
+/**
+ * Parse a --since value into a cutoff date
+ *
+ * Accepts relative durations (30m, 12h, 3d, 2w, 6mo, 1y) or an ISO date (2025-01-31).
+ *
+ * @param value - Raw option value
+ * @param now - Reference time (default: current time)
+ * @returns Cutoff date, or null if the value is not understood
+ */
+export const parseSince = (value: string, now: Date = new Date()): Date | null => {
+  const match = /^(\d+)\s*(mo|m|h|d|w|y)$/i.exec(value.trim());
+  if (match) {
+    return new Date(now.getTime() - Number(match[1]) * UNIT_MS[match[2].toLowerCase()]);
+  }
+
+  if (/^\d{4}-\d{2}-\d{2}/.test(value)) {
+    const date = new Date(value);
+    return isNaN(date.getTime()) ? null : date;
+  }
+
+  return null;
+};
+
+/**
+ * Run git in a directory, returning non-empty output lines
+ */
+const git = async (cwd: string, args: string[]): Promise<string[]> => {
+  const { stdout } = await execFileAsync('git', ['-C', cwd, ...args], { maxBuffer: GIT_MAX_BUFFER });
+  return stdout.split('\n').filter((line) => line.length > 0);
+};
+
+/**
+ * Find files changed since a cutoff using git
+ *
+ * Paths are relative to repoPath with forward slashes.
+ *
+ * @param repoPath - Repository (or subdirectory) path
+ * @param since - Cutoff date
+ * @returns Changed paths, or null if repoPath is not in a git worktree
+ */
+export const findGitChanges = async (repoPath: string, since: Date): Promise<Set<string> | null> => {
+  try {
+    const [committed, modified, untracked] = await Promise.all([
+      git(repoPath, ['log', `--since=${since.toISOString()}`, '--name-only', '--pretty=format:', '--relative']),
+      git(repoPath, ['diff', '--name-only', '--relative', 'HEAD']),
+      git(repoPath, ['ls-files', '--others', '--exclude-standard']),
+    ]);
+    return new Set([...committed, ...modified, ...untracked]);
+  } catch (error) {
+    logger.debug('git change detection unavailable, falling back to mtime', {
+      repo: repoPath,
+      error: error instanceof Error ? error.message : String(error),
+    });
+    return null;
+  }
+};
+
+/**
+ * Keep only discovered files changed since a cutoff
+ *
+ * @param repoPath - Repository root path
+ * @param files - Discovered files
+ * @param since - Cutoff date
+ * @returns Changed files and which signal was used
+ */
+export const filterChangedSince = async (
+  repoPath: string,
+  files: DiscoveredFile[],
+  since: Date
+): Promise<{ files: DiscoveredFile[]; source: 'git' | 'mtime' }> => {
+  const changed = await findGitChanges(repoPath, since);
+
+  if (changed) {
+    return { files: files.filter((file) => changed.has(toPosixPath(file.relative_path))), source: 'git' };
+  }
+
+  return { files: files.filter((file) => file.modified_time >= since), source: 'mtime' };
+};
@@ -282,8 +282,9 @@ export class CodeChunker {
         continue;
       }
 
-      // Extract method names from children
-      const methodNames = cls.children?.map((child) => child.name) ?? [];
+      // Extract method names from children (Go structs hold their fields instead)
+      const methodNames =
+        cls.children?.filter((child) => child.node_type !== NodeType.Field).map((child) => child.name) ?? [];
 
       chunks.push({
         chunk_id: uuidv4(),
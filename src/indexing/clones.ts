@@ -0,0 +1,223 @@
+/**
+ * Clone fingerprints: MinHash signatures of normalized function bodies (cindex dupes)
+ *
+ * A function body is tokenized with comments dropped, identifiers replaced
+ * by one placeholder (keywords kept), and string and number literals by
+ * another, so copies that rename variables or change constants normalize to
+ * the same tokens. Runs of SHINGLE_SIZE tokens are hashed, and the signature
+ * keeps the smallest hash of the set under each of SIGNATURE_SIZE hash
+ * functions. The share of slots two signatures agree on estimates the
+ * Jaccard similarity of their shingle sets: 1 for copies, 0.8 for a copy
+ * with a few statements changed.
+ *
+ * Groups are found by locality-sensitive hashing: signatures are cut into
+ * bands, functions sharing a band are compared, and pairs at or above the
+ * threshold are joined into groups. Bands of BAND_ROWS slots find pairs
+ * down to a similarity of about 0.3.
+ */
+import { compareStrings } from '@utils/ordering';
+import { type CloneCandidateRecord } from '@/types/database';
+
+/** Tokens per shingle */
+const SHINGLE_SIZE = 5;
+
+/** Slots of a signature */
+export const SIGNATURE_SIZE = 64;
+
+/** Slots per band */
+const BAND_ROWS = 2;
+
+/** Normalized tokens below which a function gets no signature (accessors and one-liners are all alike) */
+export const CLONE_MIN_TOKENS = 30;
+
+/** Placeholders for identifiers and literals */
+const IDENTIFIER = '$id';
+const LITERAL = '$lit';
+
+/** Keywords kept as written, across the indexed languages */
+const KEYWORDS = new Set(
+  `
+  and as async await break case catch chan class const continue def default defer del delete do elif else end except
+  false False finally fn for func function go goto if impl in instanceof is lambda let loop map match mut new nil None
+  not null of or pass raise range return select self static struct super switch this throw true True try typeof
+  undefined unless until var void while with yield
+`
+    .split(/\s+/)
+    .filter(Boolean)
+);
+
+/** Languages whose comments start with # */
+const HASH_COMMENTS = new Set(['python', 'ruby']);
+
+/**
+ * One token: a string or number literal, an identifier, a multi-character operator, or a character
+ */
+const TOKEN_PATTERN =
+  /"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'|`[^`]*`|\d[\w.]*|[\p{L}_$][\p{L}\p{N}_$]*|[-+*/%&|^<>=!:.?]{2,3}|\S/gu;
+
+/**
+ * Tokenize a function body for comparison
+ *
+ * @param code - Function source
+ * @param language - Source language (for # comments)
+ * @returns Tokens with identifiers and literals replaced by placeholders
+ */
+export const normalizeTokens = (code: string, language: string): string[] => {
+  const comments = HASH_COMMENTS.has(language) ? /#.*$|"""[\s\S]*?"""|'''[\s\S]*?'''/gm : /\/\/.*$|\/\*[\s\S]*?\*\//gm;
+  const tokens: string[] = [];
+  for (const [token] of code.replace(comments, ' ').matchAll(TOKEN_PATTERN)) {
+    if (/^["'`\d]/.test(token)) tokens.push(LITERAL);
+    else if (/^[\p{L}_$]/u.test(token)) tokens.push(KEYWORDS.has(token) ? token : IDENTIFIER);
+    else tokens.push(token);
+  }
+  return tokens;
+};
+
+/**
+ * 32-bit FNV-1a hash of a string
+ */
+const hashString = (text: string): number => {
+  let hash = 0x811c9dc5;
+  for (let i = 0; i < text.length; i++) {
+    hash = Math.imul(hash ^ text.charCodeAt(i), 0x01000193);
+  }
+  return hash >>> 0;
+};
+
+/**
+ * Murmur3 finalizer: mix the bits of a 32-bit value
+ */
+const mix = (value: number): number => {
+  let hash = value;
+  hash = Math.imul(hash ^ (hash >>> 16), 0x85ebca6b);
+  hash = Math.imul(hash ^ (hash >>> 13), 0xc2b2ae35);
+  return (hash ^ (hash >>> 16)) >>> 0;
+};
+
+/** Seed of each signature slot's hash function */
+const SEEDS = Array.from({ length: SIGNATURE_SIZE }, (_, slot) => mix(slot + 1));
+
+/**
+ * Clone fingerprint of a function
+ *
+ * @param code - Function source
+ * @param language - Source language
+ * @returns Signature (signed 32-bit slots, as stored in INT[]) and the number of normalized tokens,
+ *   or null if the function is too short to compare
+ */
+export const cloneFingerprint = (code: string, language: string): { signature: number[]; tokens: number } | null => {
+  const tokens = normalizeTokens(code, language);
+  if (tokens.length < CLONE_MIN_TOKENS) return null;
+
+  const shingles = new Set<number>();
+  for (let i = 0; i + SHINGLE_SIZE <= tokens.length; i++) {
+    shingles.add(hashString(tokens.slice(i, i + SHINGLE_SIZE).join(' ')));
+  }
+  const signature = SEEDS.map((seed) => {
+    let min = 0xffffffff;
+    for (const shingle of shingles) {
+      const hash = mix(shingle ^ seed);
+      if (hash < min) min = hash;
+    }
+    return min | 0;
+  });
+  return { signature, tokens: tokens.length };
+};
+
+/**
+ * Estimated similarity of two functions: the share of signature slots they agree on
+ */
+export const signatureSimilarity = (a: number[], b: number[]): number => {
+  let same = 0;
+  for (let i = 0; i < SIGNATURE_SIZE; i++) {
+    if (a[i] === b[i]) same++;
+  }
+  return same / SIGNATURE_SIZE;
+};
+
+/**
+ * Functions that duplicate each other
+ */
+export interface CloneGroup {
+  /** Lowest similarity between two members found alike */
+  similarity: number;
+  /** Members by path and line */
+  members: CloneCandidateRecord[];
+}
+
+/**
+ * Group functions whose similarity reaches a threshold
+ *
+ * Members of a group are joined by a chain of similar pairs, so two of them
+ * may be less alike than the threshold.
+ *
+ * @param candidates - Functions with signatures
+ * @param threshold - Lowest similarity reported (0-1)
+ * @returns Groups, the most duplicated tokens first
+ */
+export const findCloneGroups = (candidates: CloneCandidateRecord[], threshold: number): CloneGroup[] => {
+  const parent = candidates.map((_, index) => index);
+  const root = (index: number): number => {
+    let node = index;
+    while (parent[node] !== node) {
+      parent[node] = parent[parent[node]];
+      node = parent[node];
+    }
+    return node;
+  };
+
+  // Functions sharing a band are compared once
+  const compared = new Set<string>();
+  const lowest = new Map<number, number>();
+  const edges: [number, number, number][] = [];
+  for (let band = 0; band < SIGNATURE_SIZE; band += BAND_ROWS) {
+    const buckets = new Map<string, number[]>();
+    candidates.forEach((candidate, index) => {
+      const key = candidate.clone_signature.slice(band, band + BAND_ROWS).join(',');
+      const bucket = buckets.get(key);
+      if (bucket) bucket.push(index);
+      else buckets.set(key, [index]);
+    });
+    for (const bucket of buckets.values()) {
+      for (let i = 0; i < bucket.length; i++) {
+        for (let j = i + 1; j < bucket.length; j++) {
+          const pair = `${String(bucket[i])}:${String(bucket[j])}`;
+          if (compared.has(pair)) continue;
+          compared.add(pair);
+          const [a, b] = [candidates[bucket[i]], candidates[bucket[j]]];
+          const similarity = signatureSimilarity(a.clone_signature, b.clone_signature);
+          if (similarity >= threshold) edges.push([bucket[i], bucket[j], similarity]);
+        }
+      }
+    }
+  }
+
+  for (const [a, b] of edges) parent[root(a)] = root(b);
+  for (const [a, , similarity] of edges) {
+    const group = root(a);
+    lowest.set(group, Math.min(lowest.get(group) ?? 1, similarity));
+  }
+
+  const members = new Map<number, CloneCandidateRecord[]>();
+  candidates.forEach((candidate, index) => {
+    const group = root(index);
+    if (!lowest.has(group)) return;
+    const list = members.get(group);
+    if (list) list.push(candidate);
+    else members.set(group, [candidate]);
+  });
+
+  const byLocation = (a: CloneCandidateRecord, b: CloneCandidateRecord): number =>
+    compareStrings(a.repo_id ?? '', b.repo_id ?? '') ||
+    compareStrings(a.file_path, b.file_path) ||
+    a.line_number - b.line_number;
+  // Tokens a group repeats: all but one copy
+  const duplicated = (group: CloneGroup): number => {
+    const tokens = group.members.map((member) => member.clone_tokens);
+    return tokens.reduce((sum, count) => sum + count, 0) - Math.max(...tokens);
+  };
+
+  return [...members.entries()]
+    .map(([group, list]) => ({ similarity: lowest.get(group) ?? 1, members: list.sort(byLocation) }))
+    .sort((a, b) => duplicated(b) - duplicated(a) || byLocation(a.members[0], b.members[0]));
+};
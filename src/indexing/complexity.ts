@@ -0,0 +1,229 @@
+/**
+ * Function complexity metrics over tree-sitter syntax trees
+ *
+ * Cyclomatic complexity (McCabe) counts the paths through a function: one,
+ * plus one per branch, loop, case, catch, ternary, and && / || operator.
+ * Functions nested inside it have paths of their own and are not counted.
+ *
+ * Cognitive complexity (SonarSource) estimates how hard a function is to
+ * read: each break in the linear flow (if, else if, else, loop, switch,
+ * catch, ternary, labeled jump, and each run of mixed boolean operators)
+ * adds one, and structures nested inside others add one more per level of
+ * nesting. A switch counts once however many cases it has, and nested
+ * functions deepen the nesting of their contents.
+ *
+ * Node types are the union of the supported grammars' names, so one walk
+ * serves every language. Only named nodes are classified: keyword tokens
+ * share names with the nodes they start (if, for, case in Ruby).
+ */
+
+/**
+ * The part of a tree-sitter node the metrics read
+ */
+export interface ComplexityNode {
+  type: string;
+  isNamed: boolean;
+  startIndex: number;
+  children: ComplexityNode[];
+  childForFieldName(fieldName: string): ComplexityNode | null;
+}
+
+/** Conditionals */
+const IFS = new Set(['if_statement', 'if_expression', 'if', 'unless']);
+
+/** Else-if clauses holding their own condition (Python elif, Ruby elsif) */
+const ELSE_IFS = new Set(['elif_clause', 'elsif']);
+
+/** Loops */
+const LOOPS = new Set([
+  'for_statement',
+  'for_in_statement',
+  'for_range_loop',
+  'enhanced_for_statement',
+  'foreach_statement',
+  'while_statement',
+  'do_statement',
+  'do_while_statement',
+  'for_expression',
+  'while_expression',
+  'loop_expression',
+  'for',
+  'while',
+  'until',
+]);
+
+/** Multi-way branches (cognitive complexity counts the switch, cyclomatic its cases) */
+const SWITCHES = new Set([
+  'switch_statement',
+  'expression_switch_statement',
+  'type_switch_statement',
+  'select_statement',
+  'switch_expression',
+  'match_statement',
+  'match_expression',
+  'when_expression',
+  'case',
+]);
+
+/** Cases of a multi-way branch */
+const CASES = new Set([
+  'switch_case',
+  'expression_case',
+  'type_case',
+  'communication_case',
+  'case_clause',
+  'case_statement',
+  'switch_section',
+  'match_arm',
+  'when_entry',
+  'when',
+]);
+
+/** Exception handlers */
+const CATCHES = new Set(['catch_clause', 'except_clause', 'rescue']);
+
+/** Conditional expressions */
+const TERNARIES = new Set(['conditional_expression', 'ternary_expression']);
+
+/** Functions nested in a function (their bodies are one level deeper, and have their own paths) */
+const FUNCTIONS = new Set([
+  'arrow_function',
+  'function_expression',
+  'function_declaration',
+  'function_definition',
+  'func_literal',
+  'lambda',
+  'lambda_expression',
+  'closure_expression',
+  'anonymous_function',
+  'anonymous_function_creation_expression',
+]);
+
+/** Jumps that always count (labeled break and continue are checked separately) */
+const GOTOS = new Set(['goto_statement']);
+
+/** Children naming the label of a break or continue */
+const LABELS = new Set(['label_name', 'statement_identifier', 'identifier', 'label']);
+
+/**
+ * Boolean operator of a node, normalized to && or ||
+ *
+ * @returns The operator, or null if the node is not an && or || expression
+ */
+const booleanOperator = (node: ComplexityNode): string | null => {
+  if (!node.isNamed) return null;
+  if (node.type === 'conjunction_expression') return '&&';
+  if (node.type === 'disjunction_expression') return '||';
+  if (node.type !== 'binary_expression' && node.type !== 'boolean_operator' && node.type !== 'binary') return null;
+  const operator = node.childForFieldName('operator')?.type;
+  if (operator === '&&' || operator === 'and') return '&&';
+  if (operator === '||' || operator === 'or') return '||';
+  return null;
+};
+
+/**
+ * Check whether a break or continue names a label
+ */
+const isLabeledJump = (node: ComplexityNode): boolean =>
+  (node.type === 'break_statement' || node.type === 'continue_statement') &&
+  node.children.some((child) => LABELS.has(child.type));
+
+/**
+ * Cyclomatic complexity of a function
+ *
+ * @param node - Function or method declaration
+ * @returns Decision points plus one
+ */
+export const cyclomaticComplexity = (node: ComplexityNode): number => {
+  let complexity = 1;
+  const traverse = (current: ComplexityNode): void => {
+    const type = current.type;
+    if (!current.isNamed || (current !== node && FUNCTIONS.has(type))) return;
+    if (
+      IFS.has(type) ||
+      ELSE_IFS.has(type) ||
+      LOOPS.has(type) ||
+      CASES.has(type) ||
+      CATCHES.has(type) ||
+      TERNARIES.has(type) ||
+      booleanOperator(current) !== null
+    ) {
+      complexity++;
+    }
+    for (const child of current.children) traverse(child);
+  };
+  traverse(node);
+  return complexity;
+};
+
+/**
+ * Cognitive complexity of a function
+ *
+ * @param node - Function or method declaration
+ * @returns Structural increments plus their nesting increments (0 for straight-line code)
+ */
+export const cognitiveComplexity = (node: ComplexityNode): number => {
+  let complexity = 0;
+
+  /**
+   * Score a conditional and its else branches
+   *
+   * @param elseIf - The conditional continues an else (no nesting increment)
+   */
+  const visitIf = (current: ComplexityNode, nesting: number, elseIf: boolean): void => {
+    complexity += elseIf ? 1 : 1 + nesting;
+    const alternative = current.childForFieldName('alternative');
+    for (const child of current.children) {
+      if (child.type === 'else_clause') {
+        const body = child.children.filter((part) => part.isNamed);
+        const chained = body.length === 1 ? body[0] : undefined;
+        if (chained && IFS.has(chained.type)) {
+          visitIf(chained, nesting, true);
+        } else {
+          complexity += 1;
+          for (const part of body) visit(part, nesting + 1, null);
+        }
+      } else if (ELSE_IFS.has(child.type)) {
+        visitIf(child, nesting, true);
+      } else if (alternative && child.startIndex === alternative.startIndex && child.type === alternative.type) {
+        // Alternatives outside an else clause (Go, Java, Ruby): an else if, or the else body
+        if (IFS.has(child.type)) {
+          visitIf(child, nesting, true);
+        } else {
+          complexity += 1;
+          visit(child, nesting + 1, null);
+        }
+      } else {
+        visit(child, nesting + 1, null);
+      }
+    }
+  };
+
+  const visit = (current: ComplexityNode, nesting: number, parentOperator: string | null): void => {
+    const type = current.type;
+    if (!current.isNamed) return;
+    if (IFS.has(type)) {
+      visitIf(current, nesting, false);
+      return;
+    }
+
+    let childNesting = nesting;
+    if (LOOPS.has(type) || SWITCHES.has(type) || CATCHES.has(type) || TERNARIES.has(type)) {
+      complexity += 1 + nesting;
+      childNesting = nesting + 1;
+    } else if (FUNCTIONS.has(type)) {
+      childNesting = nesting + 1;
+    } else if (GOTOS.has(type) || isLabeledJump(current)) {
+      complexity += 1;
+    }
+
+    // A run of one operator (a && b && c) counts once; each change of operator counts again
+    const operator = booleanOperator(current);
+    if (operator !== null && operator !== parentOperator) complexity += 1;
+
+    for (const child of current.children) visit(child, childNesting, operator);
+  };
+
+  for (const child of node.children) visit(child, 0, null);
+  return complexity;
+};
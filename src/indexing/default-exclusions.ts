@@ -0,0 +1,180 @@
+/**
+ * Default exclusions for file discovery
+ *
+ * Dependency trees, VCS metadata, build output, and binary assets are skipped
+ * out of the box. The directory list can be changed with EXCLUDE_DIRECTORIES
+ * (`name` adds a directory, `!name` indexes a default one, e.g. `!vendor`);
+ * `cindex config defaults` prints every list and the effective directory set.
+ */
+
+/**
+ * Binary file extensions to exclude from indexing
+ * Media files are skipped - path saved but not parsed/embedded
+ */
+export const DEFAULT_BINARY_EXTENSIONS: ReadonlySet<string> = new Set([
+  // Images
+  '.png',
+  '.jpg',
+  '.jpeg',
+  '.gif',
+  '.bmp',
+  '.ico',
+  '.svg',
+  '.webp',
+  '.tiff',
+  '.tif',
+  '.raw',
+  '.heic',
+  '.heif',
+  '.avif',
+  '.psd',
+
+  // Video
+  '.mp4',
+  '.avi',
+  '.mov',
+  '.mkv',
+  '.webm',
+  '.wmv',
+  '.flv',
+  '.m4v',
+  '.3gp',
+  '.mpeg',
+  '.mpg',
+  '.ogv',
+
+  // Audio
+  '.mp3',
+  '.wav',
+  '.flac',
+  '.aac',
+  '.ogg',
+  '.wma',
+  '.m4a',
+  '.opus',
+  '.mid',
+  '.midi',
+
+  // Fonts
+  '.ttf',
+  '.otf',
+  '.woff',
+  '.woff2',
+  '.eot',
+
+  // Archives
+  '.zip',
+  '.tar',
+  '.gz',
+  '.bz2',
+  '.7z',
+  '.rar',
+  '.dmg',
+  '.iso',
+
+  // Executables & Libraries
+  '.exe',
+  '.dll',
+  '.so',
+  '.dylib',
+  '.wasm',
+  '.deb',
+  '.rpm',
+  '.apk',
+
+  // 3D Models & CAD
+  '.obj',
+  '.fbx',
+  '.blend',
+  '.max',
+  '.stl',
+  '.dae',
+
+  // Databases
+  '.db',
+  '.sqlite',
+  '.sqlite3',
+  '.mdb',
+  '.accdb',
+
+  // Office Documents
+  '.doc',
+  '.docx',
+  '.xls',
+  '.xlsx',
+  '.ppt',
+  '.pptx',
+  '.pdf',
+]);
+
+/**
+ * Generated file name patterns to exclude (lock files, bundles, source maps)
+ */
+export const DEFAULT_GENERATED_FILE_NAMES: readonly string[] = [
+  'package-lock.json',
+  'yarn.lock',
+  'pnpm-lock.yaml',
+  'bun.lockb',
+  'Cargo.lock',
+  'Gemfile.lock',
+  'poetry.lock',
+  'composer.lock',
+  '.min.js',
+  '.bundle.js',
+  '.min.css',
+  '.map',
+  '-min.js',
+  '-bundle.js',
+];
+
+/**
+ * Directory names excluded by default (dependencies, VCS metadata, build output, caches)
+ */
+export const DEFAULT_EXCLUDED_DIRECTORIES: ReadonlySet<string> = new Set([
+  'node_modules',
+  '.git',
+  '.svn',
+  '.hg',
+  'dist',
+  'build',
+  'out',
+  'coverage',
+  '.next',
+  '.nuxt',
+  '.cache',
+  '.parcel-cache',
+  '.turbo',
+  '__pycache__',
+  '.pytest_cache',
+  '.mypy_cache',
+  'venv',
+  'env',
+  '.venv',
+  '.env',
+  'target', // Rust
+  'bin',
+  'obj', // C#
+  'vendor', // PHP/Go
+  '.gradle',
+  '.mvn',
+  'bower_components',
+]);
+
+/**
+ * Apply EXCLUDE_DIRECTORIES entries to the default directory exclusions
+ *
+ * @param overrides - Directory names to add, or `!name` to remove a default
+ * @returns Directory names excluded during discovery
+ */
+export const resolveExcludedDirectories = (overrides: readonly string[] = []): Set<string> => {
+  const excluded = new Set(DEFAULT_EXCLUDED_DIRECTORIES);
+  for (const entry of overrides) {
+    const name = entry.trim().replace(/[\\/]+$/, '');
+    if (name.startsWith('!')) {
+      excluded.delete(name.slice(1));
+    } else if (name) {
+      excluded.add(name);
+    }
+  }
+  return excluded;
+};
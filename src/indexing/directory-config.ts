@@ -0,0 +1,231 @@
+/**
+ * Directory Config: Nested .cindex.yaml Overrides
+ *
+ * Any directory in a repository may contain a `.cindex.yaml` file that
+ * overrides parsing and metric settings for its subtree, e.g. relaxed
+ * thresholds for generated code:
+ *
+ *   # internal/gen/.cindex.yaml
+ *   exclude: ['*_mock.go']
+ *   structure_only_lines: 1000
+ *   metrics:
+ *     max_complexity: 50
+ *
+ * Configs are merged from the repository root down to the file's directory.
+ * A directory may also hold a `.cindexignore` with gitignore syntax: its
+ * patterns exclude paths of that subtree, whether or not .gitignore is
+ * respected.
+ */
+
+import * as path from 'node:path';
+
+import * as yaml from 'js-yaml';
+
+import { readTextFile } from '@utils/edge-cases';
+import { logger } from '@utils/logger';
+import { Language, type DirectoryConfig } from '@/types/indexing';
+
+/**
+ * Config file names checked in each directory (first match wins)
+ */
+export const DIRECTORY_CONFIG_FILES = ['.cindex.yaml', '.cindex.yml'];
+
+/**
+ * Ignore file checked in each directory (gitignore syntax)
+ */
+export const IGNORE_FILE = '.cindexignore';
+
+/**
+ * Read a positive integer key, warning on invalid values
+ */
+const readPositiveInt = (raw: Record<string, unknown>, key: string, source: string): number | undefined => {
+  const value = raw[key];
+  if (value === undefined) return undefined;
+  if (typeof value === 'number' && Number.isInteger(value) && value > 0) return value;
+
+  logger.warn('Ignoring invalid directory config value', { file: source, key, value });
+  return undefined;
+};
+
+/**
+ * Read a string list key, warning on invalid values
+ */
+const readStringList = (raw: Record<string, unknown>, key: string, source: string): string[] | undefined => {
+  const value = raw[key];
+  if (value === undefined) return undefined;
+  if (Array.isArray(value) && value.every((item) => typeof item === 'string')) return value as string[];
+
+  logger.warn('Ignoring invalid directory config value', { file: source, key, value });
+  return undefined;
+};
+
+/**
+ * Validate parsed YAML into a DirectoryConfig
+ *
+ * Invalid or unknown keys are logged and dropped so a typo in one subtree
+ * never aborts indexing of the whole repository.
+ *
+ * @param raw - Parsed YAML document
+ * @param source - Config file path (for log messages)
+ * @returns Validated config
+ */
+export const parseDirectoryConfig = (raw: unknown, source: string): DirectoryConfig => {
+  if (raw === null || raw === undefined) {
+    return {};
+  }
+
+  if (typeof raw !== 'object' || Array.isArray(raw)) {
+    logger.warn('Ignoring directory config (expected a mapping)', { file: source });
+    return {};
+  }
+
+  const doc = raw as Record<string, unknown>;
+  const config: DirectoryConfig = {};
+
+  const exclude = readStringList(doc, 'exclude', source);
+  if (exclude) config.exclude = exclude;
+
+  const include = readStringList(doc, 'include', source);
+  if (include) config.include = include;
+
+  const languages = readStringList(doc, 'languages', source);
+  if (languages) {
+    const known = new Set<string>(Object.values(Language));
+    const unknown = languages.filter((language) => !known.has(language));
+    if (unknown.length > 0) {
+      logger.warn('Ignoring unknown languages in directory config', { file: source, languages: unknown });
+    }
+    config.languages = languages.filter((language) => known.has(language));
+  }
+
+  const maxFileSize = readPositiveInt(doc, 'max_file_size', source);
+  if (maxFileSize !== undefined) config.max_file_size = maxFileSize;
+
+  const maxFileBytes = readPositiveInt(doc, 'max_file_bytes', source);
+  if (maxFileBytes !== undefined) config.max_file_bytes = maxFileBytes;
+
+  const binaryExtensions = readStringList(doc, 'binary_extensions', source);
+  if (binaryExtensions) {
+    // '.dat', 'dat', and '.DAT' all name the same extension
+    config.binary_extensions = binaryExtensions.map((ext) => (ext.startsWith('.') ? ext : `.${ext}`).toLowerCase());
+  }
+
+  const structureOnlyLines = readPositiveInt(doc, 'structure_only_lines', source);
+  if (structureOnlyLines !== undefined) config.structure_only_lines = structureOnlyLines;
+
+  if (doc.metrics !== undefined) {
+    if (typeof doc.metrics === 'object' && doc.metrics !== null && !Array.isArray(doc.metrics)) {
+      const metrics: Record<string, number> = {};
+      for (const [name, value] of Object.entries(doc.metrics as Record<string, unknown>)) {
+        if (typeof value === 'number' && Number.isFinite(value)) {
+          metrics[name] = value;
+        } else {
+          logger.warn('Ignoring invalid metric threshold', { file: source, metric: name, value });
+        }
+      }
+      config.metrics = metrics;
+    } else {
+      logger.warn('Ignoring directory config metrics (expected a mapping)', { file: source });
+    }
+  }
+
+  // 'settings' and 'aliases' are read by the CLI (see src/cli/project-config.ts)
+  const knownKeys = new Set([
+    'exclude',
+    'include',
+    'languages',
+    'max_file_size',
+    'max_file_bytes',
+    'binary_extensions',
+    'structure_only_lines',
+    'metrics',
+    'settings',
+    'aliases',
+  ]);
+  const unknownKeys = Object.keys(doc).filter((key) => !knownKeys.has(key));
+  if (unknownKeys.length > 0) {
+    logger.warn('Ignoring unknown directory config keys', { file: source, keys: unknownKeys });
+  }
+
+  return config;
+};
+
+/**
+ * Load the .cindex.yaml file in a directory
+ *
+ * @param dirPath - Absolute directory path
+ * @returns Parsed config with its file path, or null if the directory has none
+ */
+export const loadDirectoryConfig = async (
+  dirPath: string
+): Promise<{ config: DirectoryConfig; source: string } | null> => {
+  for (const fileName of DIRECTORY_CONFIG_FILES) {
+    const source = path.join(dirPath, fileName);
+    let content: string;
+
+    try {
+      content = await readTextFile(source);
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+        logger.warn('Error reading directory config', { file: source, error });
+      }
+      continue;
+    }
+
+    try {
+      return { config: parseDirectoryConfig(yaml.load(content), source), source };
+    } catch (error) {
+      logger.warn('Ignoring directory config with invalid YAML', {
+        file: source,
+        error: error instanceof Error ? error.message : String(error),
+      });
+      return null;
+    }
+  }
+
+  return null;
+};
+
+/**
+ * Merge a child directory config over its parent
+ *
+ * Scalars and `languages` are replaced, `metrics` merge key by key, and
+ * `binary_extensions` add to the inherited ones. `exclude` and `include`
+ * are not merged: patterns stay scoped to the declaring directory.
+ *
+ * @param parent - Effective config inherited from ancestors
+ * @param child - Config declared in the current directory
+ * @returns Effective config for the current directory
+ */
+export const mergeDirectoryConfig = (parent: DirectoryConfig, child: DirectoryConfig): DirectoryConfig => {
+  const merged: DirectoryConfig = { ...parent, ...child };
+  delete merged.exclude;
+  delete merged.include;
+
+  if (parent.metrics ?? child.metrics) {
+    merged.metrics = { ...parent.metrics, ...child.metrics };
+  }
+  if (parent.binary_extensions && child.binary_extensions) {
+    merged.binary_extensions = [...new Set([...parent.binary_extensions, ...child.binary_extensions])];
+  }
+
+  return merged;
+};
+
+/**
+ * Load the .cindexignore file in a directory
+ *
+ * @param dirPath - Absolute directory path
+ * @returns Pattern lines with the file path, or null if the directory has none
+ */
+export const loadIgnoreFile = async (dirPath: string): Promise<{ patterns: string; source: string } | null> => {
+  const source = path.join(dirPath, IGNORE_FILE);
+  try {
+    return { patterns: await readTextFile(source), source };
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+      logger.warn('Error reading ignore file', { file: source, error });
+    }
+    return null;
+  }
+};
@@ -14,6 +14,8 @@ import { createHash } from 'node:crypto';
 import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
+import { readTextFile } from '@utils/edge-cases';
+import { compareNames } from '@utils/ordering';
 import { type ParsedDocChunk, type ParsedDocFile, type TableOfContentsEntry } from '@/types/documentation';
 
 /**
@@ -150,7 +152,7 @@ const extractCodeBlockChunks = (content: string, sectionStartLine: number, headi
  * @returns Parsed document with chunks ready for indexing
  */
 export const parseMarkdownForDocumentation = async (filePath: string): Promise<ParsedDocFile> => {
-  const content = await fs.readFile(filePath, 'utf-8');
+  const content = await readTextFile(filePath);
   const fileHash = createHash('sha256').update(content).digest('hex');
 
   // Parse front matter
@@ -318,7 +320,7 @@ export const findMarkdownFiles = async (dirPath: string, recursive = true): Prom
 
   const searchDirectory = async (currentPath: string): Promise<void> => {
     try {
-      const entries = await fs.readdir(currentPath, { withFileTypes: true });
+      const entries = (await fs.readdir(currentPath, { withFileTypes: true })).sort(compareNames);
 
       for (const entry of entries) {
         const fullPath = path.join(currentPath, entry.name);
@@ -348,7 +350,7 @@ export const findMarkdownFiles = async (dirPath: string, recursive = true): Prom
  * @returns SHA256 hash of file content
  */
 export const getFileHash = async (filePath: string): Promise<string> => {
-  const content = await fs.readFile(filePath, 'utf-8');
+  const content = await readTextFile(filePath);
   return createHash('sha256').update(content).digest('hex');
 };
 
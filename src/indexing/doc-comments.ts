@@ -0,0 +1,42 @@
+/**
+ * Doc comments as plain text
+ *
+ * The parser keeps a declaration's doc comment as written: a run of // or #
+ * lines, a JSDoc or other block comment, Rust's /// and //! lines, or a
+ * Python docstring. For search and for cindex doc the markers are stripped,
+ * leaving the prose with its paragraph breaks.
+ */
+
+/** Comment markers at the start of a line, longest first */
+const LINE_MARKER = /^(\/\*\*|\/\*!|\/\*|\/\/\/|\/\/!|\/\/|\*(?!\/)|#+|--|'''|""")\s?/;
+
+/** Block and docstring closers at the end of a line */
+const LINE_END = /\s*(\*\/|'''|""")$/;
+
+/**
+ * Strip comment markers from a doc comment
+ *
+ * @param raw - Comment text as extracted (one or more lines)
+ * @returns Text without markers, blank lines at either end removed (empty if nothing but markers)
+ */
+export const cleanDocComment = (raw: string): string => {
+  const literal = /^[rRuUbBfF]{0,2}("""|''')/.exec(raw.trim());
+  const text = literal ? raw.trim().slice(literal[0].length - 3) : raw;
+
+  const lines = text.split('\n').map((line) => line.trim().replace(LINE_END, '').replace(LINE_MARKER, '').trimEnd());
+  while (lines.length > 0 && lines[0] === '') lines.shift();
+  while (lines.length > 0 && lines[lines.length - 1] === '') lines.pop();
+  return lines.join('\n');
+};
+
+/**
+ * First sentence of a doc comment, for one-line listings
+ *
+ * @param doc - Cleaned doc comment
+ * @returns Text up to the first sentence end or paragraph break
+ */
+export const docSummary = (doc: string): string => {
+  const paragraph = doc.split(/\n\s*\n/)[0].replace(/\s+/g, ' ').trim();
+  const end = /[.!?](\s|$)/.exec(paragraph);
+  return end ? paragraph.slice(0, end.index + 1) : paragraph;
+};
@@ -0,0 +1,95 @@
+/**
+ * Dry-run indexing
+ *
+ * Walks and parses a repository exactly like the indexing pipeline, but
+ * without generating summaries/embeddings or writing to the database.
+ * Used to debug ignore rules, size limits, and binary detection.
+ */
+
+import { filterChangedSince } from '@indexing/changed-files';
+import { FileWalker } from '@indexing/file-walker';
+import { determineLargeFileStrategy } from '@indexing/large-file-handler';
+import { parseCode } from '@indexing/parser';
+import { readSourceFile } from '@utils/edge-cases';
+import { logger } from '@utils/logger';
+import { normalizeUnicode } from '@utils/unicode';
+import { type DryRunFile, type DryRunReport, type IndexingOptions, type SkippedFile } from '@/types/indexing';
+
+/**
+ * Map a large-file strategy skip into a discovery skip reason
+ */
+const strategySkip = (relativePath: string, fileType: string, reason?: string): SkippedFile => ({
+  relative_path: relativePath,
+  reason: fileType === 'binary' ? 'binary' : 'generated',
+  detail: reason,
+});
+
+/**
+ * Plan an indexing run without writing anything
+ *
+ * @param repoPath - Absolute path to repository root
+ * @param options - Indexing options (same as index_repository)
+ * @returns Files that would be indexed and paths that would be skipped
+ */
+export const dryRunIndexing = async (repoPath: string, options: IndexingOptions = {}): Promise<DryRunReport> => {
+  const walker = new FileWalker(repoPath, options);
+  let discovered = await walker.discoverFiles();
+
+  const included: DryRunFile[] = [];
+  const skipped: SkippedFile[] = walker.getSkippedFiles();
+
+  if (options.since) {
+    const { files, source } = await filterChangedSince(repoPath, discovered, options.since);
+    const changed = new Set(files);
+    for (const file of discovered) {
+      if (!changed.has(file)) {
+        skipped.push({ relative_path: file.relative_path, reason: 'unchanged_since', detail: `by ${source}` });
+      }
+    }
+    discovered = files;
+  }
+
+  for (const file of discovered) {
+    const strategy = determineLargeFileStrategy(file);
+
+    if (!strategy.shouldIndex) {
+      skipped.push(strategySkip(file.relative_path, strategy.fileType, strategy.reason));
+      continue;
+    }
+
+    // Structure-only files are not fully parsed by the pipeline either
+    if (strategy.useStructureOnly) {
+      included.push({
+        relative_path: file.relative_path,
+        language: file.language,
+        line_count: file.line_count,
+        encoding: file.encoding,
+        strategy: 'structure-only',
+        used_fallback: false,
+        partial: false,
+        generated: file.generated !== undefined,
+        node_count: 0,
+      });
+      continue;
+    }
+
+    const content = normalizeUnicode((await readSourceFile(file.absolute_path)).content);
+    const parseResult = parseCode(content, file.language, file.relative_path);
+
+    included.push({
+      relative_path: file.relative_path,
+      language: file.language,
+      line_count: file.line_count,
+      encoding: file.encoding,
+      strategy: 'full',
+      used_fallback: parseResult.used_fallback,
+      partial: parseResult.partial ?? false,
+      generated: file.generated !== undefined,
+      node_count: parseResult.nodes.length,
+    });
+  }
+
+  logger.info('Dry run complete', { included: included.length, skipped: skipped.length });
+
+  return { repo_path: repoPath, included, skipped };
+};
@@ -1,14 +1,15 @@
 /**
  * Embedding generation with enhanced text construction
  *
- * Generates vector embeddings for code chunks using Ollama with a structured
- * text format that includes file context, code type, language, content, and symbols.
+ * Generates vector embeddings for code chunks using the configured embedder
+ * (Ollama by default) with a structured text format that includes file
+ * context, code type, language, content, and symbols.
  * Supports batch processing with concurrency control and dimension validation.
  */
 
+import { type Embedder } from '@utils/embedders';
 import { EmbeddingGenerationError, VectorDimensionError } from '@utils/errors';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import { type EmbeddingConfig } from '@/types/config';
 import { type ChunkEmbedding, type CodeChunkInput } from '@/types/indexing';
 
@@ -17,7 +18,7 @@ import { type ChunkEmbedding, type CodeChunkInput } from '@/types/indexing';
  */
 export class EmbeddingGenerator {
   constructor(
-    private readonly ollamaClient: OllamaClient,
+    private readonly embedder: Embedder,
     private readonly config: EmbeddingConfig
   ) {}
 
@@ -58,15 +59,15 @@ export class EmbeddingGenerator {
     const enhancedText = this.buildEnhancedText(chunk, fileSummary);
 
     try {
-      // Generate embedding via Ollama
-      const embedding = await this.ollamaClient.generateEmbedding(
+      // Generate embedding via the embedder
+      const embedding = await this.embedder.generateEmbedding(
         this.config.model,
         enhancedText,
         this.config.dimensions,
         this.config.context_window
       );
 
-      // Validate dimensions (OllamaClient also validates, but double-check)
+      // Validate dimensions (embedders also validate, but double-check)
       if (embedding.length !== this.config.dimensions) {
         throw new VectorDimensionError(
           this.config.dimensions,
@@ -109,7 +110,7 @@ export class EmbeddingGenerator {
   /**
    * Batch generate embeddings for multiple chunks
    *
-   * Processes chunks in batches with concurrency control using the Ollama
+   * Processes chunks in batches with concurrency control using the embedder's
    * batch embedding endpoint for efficiency.
    *
    * @param chunks - Array of code chunks to embed
@@ -134,8 +135,8 @@ export class EmbeddingGenerator {
     // Build all enhanced texts first
     const enhancedTexts = chunks.map((chunk) => this.buildEnhancedText(chunk, fileSummary));
 
-    // Generate embeddings via Ollama batch API
-    const embeddings = await this.ollamaClient.generateEmbeddingBatch(
+    // Generate embeddings via the embedder's batch API
+    const embeddings = await this.embedder.generateEmbeddingBatch(
       this.config.model,
       enhancedTexts,
       this.config.dimensions,
@@ -235,7 +236,7 @@ export class EmbeddingGenerator {
     const startTime = Date.now();
 
     try {
-      const embedding = await this.ollamaClient.generateEmbedding(
+      const embedding = await this.embedder.generateEmbedding(
         this.config.model,
         text,
         this.config.dimensions,
@@ -298,10 +299,10 @@ export class EmbeddingGenerator {
 /**
  * Create embedding generator instance
  *
- * @param ollamaClient - Ollama client for embedding operations
+ * @param embedder - Embedder of the configured provider (see @utils/embedders)
  * @param config - Embedding generation configuration
  * @returns Initialized EmbeddingGenerator
  */
-export const createEmbeddingGenerator = (ollamaClient: OllamaClient, config: EmbeddingConfig): EmbeddingGenerator => {
-  return new EmbeddingGenerator(ollamaClient, config);
+export const createEmbeddingGenerator = (embedder: Embedder, config: EmbeddingConfig): EmbeddingGenerator => {
+  return new EmbeddingGenerator(embedder, config);
 };
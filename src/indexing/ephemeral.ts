@@ -0,0 +1,196 @@
+/**
+ * Ephemeral indexes: content that is not on disk (cindex index --stdin, MCP add_document)
+ *
+ * Editor buffers, generated code, and fetched snippets are written under
+ * ~/.cindex/ephemeral/ and indexed by the regular pipeline, so they can be
+ * queried like any index right away. Each name is its own index; documents
+ * added to it accumulate, and a document added again under the same path
+ * replaces the previous version (the run is incremental). Files are stored as
+ * <name>/<path>, which keeps them apart from repository paths.
+ *
+ * An ephemeral index expires a day after its last document was added and is
+ * removed the next time a document is added to any ephemeral index;
+ * `cindex rm <name>` removes it at once.
+ */
+
+import * as fs from 'node:fs/promises';
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { type DatabaseClient } from '@database/client';
+import { listIndexedRepositories } from '@database/queries';
+import { acquireIndexLock } from '@indexing/index-lock';
+import { createPipeline } from '@indexing/pipeline';
+import { deleteRepository } from '@indexing/version-tracker';
+import { logger } from '@utils/logger';
+import { type OllamaClient } from '@utils/ollama';
+import { type CindexConfig, type StoreConfig } from '@/types/config';
+import { LANGUAGE_EXTENSIONS, type IndexingOptions, type IndexingStats, type Language } from '@/types/indexing';
+
+/** Content of ephemeral indexes, one directory per name */
+const EPHEMERAL_DIR = path.join(os.homedir(), '.cindex', 'ephemeral');
+
+/** How long an ephemeral index outlives its last added document */
+export const EPHEMERAL_TTL_MS = 24 * 60 * 60 * 1000;
+
+/** Names usable as index IDs and directory names alike */
+const NAME_PATTERN = /^[A-Za-z0-9][A-Za-z0-9._-]*$/;
+
+/**
+ * Content to index without a file on disk
+ */
+export interface EphemeralDocument {
+  /** Ephemeral index to add the document to */
+  name: string;
+  /** Document content */
+  content: string;
+  /** Language (default: from the path's extension) */
+  language?: string;
+  /** Path of the document in the index (default: the name with the language's extension) */
+  path?: string;
+}
+
+/**
+ * Result of adding a document
+ */
+export interface AddDocumentResult {
+  repo_id: string;
+  /** Stored path of the document (<name>/<path>) */
+  file_path: string;
+  stats: IndexingStats;
+  /** Expired ephemeral indexes removed before indexing */
+  pruned: string[];
+}
+
+/**
+ * Extension the parser reads as a language
+ *
+ * @param language - Language name (go, typescript, python, ...)
+ * @returns Extension with its dot, or null if the language is not supported
+ */
+export const languageExtension = (language: string): string | null => {
+  const wanted = language.trim().toLowerCase();
+  const entry = Object.entries(LANGUAGE_EXTENSIONS).find(([, candidate]) => candidate === (wanted as Language));
+  return entry ? entry[0] : null;
+};
+
+/**
+ * Check whether an ephemeral index name is usable
+ */
+export const isValidEphemeralName = (name: string): boolean => NAME_PATTERN.test(name) && !name.includes('..');
+
+/**
+ * Path of a document inside its ephemeral index
+ *
+ * A path without a supported extension gets the language's; without a
+ * path, the document is named after the index.
+ *
+ * @param document - Document to add
+ * @returns Relative POSIX path
+ * @throws {Error} If the path leaves the index, or the language is unknown or missing
+ */
+export const documentPath = (document: Pick<EphemeralDocument, 'name' | 'language' | 'path'>): string => {
+  const relative = path.posix.normalize((document.path ?? document.name).replaceAll('\\', '/'));
+  if (path.posix.isAbsolute(relative) || relative === '.' || relative === '..' || relative.startsWith('../')) {
+    throw new Error(`Invalid document path: ${document.path ?? document.name}`);
+  }
+  if (LANGUAGE_EXTENSIONS[path.posix.extname(relative).toLowerCase()]) return relative;
+
+  if (!document.language) {
+    throw new Error(`Cannot tell the language of ${relative}; pass a language or a path with its extension`);
+  }
+  const extension = languageExtension(document.language);
+  if (!extension) {
+    const supported = [...new Set(Object.values(LANGUAGE_EXTENSIONS))].join(', ');
+    throw new Error(`Unsupported language: ${document.language} (supported: ${supported})`);
+  }
+  return `${relative}${extension}`;
+};
+
+/**
+ * Check whether an index is ephemeral and past its expiry
+ *
+ * @param metadata - Repository metadata
+ * @param now - Current time
+ */
+export const isExpiredEphemeral = (metadata: Record<string, unknown> | null | undefined, now: Date): boolean => {
+  if (metadata?.ephemeral !== true || typeof metadata.expires_at !== 'string') return false;
+  const expires = Date.parse(metadata.expires_at);
+  return !Number.isNaN(expires) && expires <= now.getTime();
+};
+
+/**
+ * Remove expired ephemeral indexes and their content
+ *
+ * Indexes being written are left for the next run.
+ *
+ * @returns Removed index IDs
+ */
+const pruneExpired = async (db: DatabaseClient, store: StoreConfig, keep: string): Promise<string[]> => {
+  const pool = db.getPool();
+  const repos = await listIndexedRepositories(pool, { includeMetadata: true });
+  const pruned: string[] = [];
+  for (const repo of repos) {
+    if (repo.repo_id === keep || !isExpiredEphemeral(repo.metadata, new Date())) continue;
+    try {
+      const lock = await acquireIndexLock(repo.repo_id, false, true);
+      await deleteRepository(pool, repo.repo_id, store).finally(lock.release);
+      await fs.rm(path.join(EPHEMERAL_DIR, repo.repo_id), { recursive: true, force: true });
+      pruned.push(repo.repo_id);
+    } catch (error) {
+      logger.warn('Could not remove expired ephemeral index', { repo_id: repo.repo_id, error });
+    }
+  }
+  return pruned;
+};
+
+/**
+ * Add a document to an ephemeral index and index it
+ *
+ * @param config - Loaded configuration
+ * @param db - Connected database client
+ * @param ollama - Ollama client (healthy)
+ * @param document - Name, content, language, and path
+ * @returns Stored path, indexing statistics, and the expired indexes removed
+ * @throws {Error} If the name or path is invalid, or the name belongs to a repository index
+ */
+export const addDocument = async (
+  config: CindexConfig,
+  db: DatabaseClient,
+  ollama: OllamaClient,
+  document: EphemeralDocument
+): Promise<AddDocumentResult> => {
+  const { name } = document;
+  if (!isValidEphemeralName(name)) {
+    throw new Error(`Invalid name: ${name} (letters, digits, '.', '_', and '-')`);
+  }
+  const relative = documentPath(document);
+
+  const existing = (await listIndexedRepositories(db.getPool(), { includeMetadata: true })).find(
+    (repo) => repo.repo_id === name
+  );
+  if (existing && existing.metadata?.ephemeral !== true) {
+    throw new Error(`'${name}' is a repository index; add documents under another name`);
+  }
+  const pruned = await pruneExpired(db, config.store, name);
+
+  // The root holds one directory named after the index, so stored paths start with <name>/
+  const root = path.join(EPHEMERAL_DIR, name);
+  const file = path.join(root, name, ...relative.split('/'));
+  await fs.mkdir(path.dirname(file), { recursive: true });
+  await fs.writeFile(file, document.content, 'utf-8');
+
+  const options: IndexingOptions = {
+    repoId: name,
+    repoName: name,
+    incremental: true,
+    waitForLock: true,
+    subdirectory: name,
+    metadata: { ephemeral: true, expires_at: new Date(Date.now() + EPHEMERAL_TTL_MS).toISOString() },
+    symlinkPolicy: 'skip',
+    generatedFiles: 'tag',
+    summaryMethod: 'rule-based',
+  };
+  const stats = await createPipeline(config, db, ollama, root, options).indexRepository(root, options);
+  return { repo_id: name, file_path: `${name}/${relative}`, stats, pruned };
+};
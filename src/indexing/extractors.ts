@@ -0,0 +1,62 @@
+/**
+ * Language extractors: what turns the content of a file into its symbols
+ *
+ * Every file is read by one extractor, which returns its declarations,
+ * imports, and exports as a ParseResult, so chunks, symbols, metrics, and the
+ * language column are built the same way whichever extractor read it. The
+ * built-in extractor parses with tree-sitter (see @indexing/parser): Python,
+ * TypeScript, JavaScript, Java, Go, and the other languages with a grammar,
+ * and the regex fallback for the rest. Extractor plugins (see
+ * @indexing/plugins) are adapted to the same interface and read the files of
+ * the extensions they claim, ahead of the built-in one.
+ */
+
+import { extractorForFile, toParseResult } from '@indexing/plugins';
+import { Language, type LanguageExtractor, type ParseResult } from '@/types/indexing';
+import { type ExtractorPlugin } from '@/types/plugins';
+
+/** Languages read by the built-in extractor (Unknown files, such as Markdown, get the regex fallback) */
+export const TREE_SITTER_LANGUAGES: string[] = Object.values(Language);
+
+/**
+ * Built-in extractor, parsing with tree-sitter
+ *
+ * @param parse - Parse a file with the grammar of its language (a parse pool, or a CodeParser)
+ */
+export const treeSitterExtractor = (
+  parse: (content: string, filePath: string, language: Language) => Promise<ParseResult>
+): LanguageExtractor => ({
+  name: 'tree-sitter',
+  languages: TREE_SITTER_LANGUAGES,
+  extract: (content, filePath, language) => parse(content, filePath, language as Language),
+});
+
+/**
+ * Extractor plugin, as a language extractor
+ */
+export const pluginExtractor = (plugin: ExtractorPlugin): LanguageExtractor => ({
+  name: plugin.name,
+  languages: [plugin.language],
+  extract: async (content, filePath) => toParseResult(await plugin.extract(content, filePath), content),
+});
+
+/**
+ * Extractor of a file: the plugin claiming its extension, else the first built-in one reading its language
+ *
+ * @param filePath - Path of the file
+ * @param language - Language of the file
+ * @param builtins - Built-in extractors, in order of preference
+ * @throws {Error} If no extractor reads the language
+ */
+export const selectExtractor = (
+  filePath: string,
+  language: string,
+  builtins: LanguageExtractor[]
+): LanguageExtractor => {
+  const plugin = extractorForFile(filePath);
+  if (plugin) return pluginExtractor(plugin);
+
+  const builtin = builtins.find((extractor) => extractor.languages.includes(language));
+  if (!builtin) throw new Error(`No extractor reads ${language} files`);
+  return builtin;
+};
@@ -0,0 +1,226 @@
+/**
+ * Per-file metrics recorded at index time (cindex stats --by)
+ *
+ * Each line of a file is counted once: blank (whitespace only), comment
+ * (nothing but comment text), or code (anything else, so a statement with a
+ * trailing comment is code). Comment markers inside string literals on the
+ * same line are skipped; strings spanning lines (template literals, raw
+ * strings, Python docstrings) count as code. Languages without a known
+ * comment syntax count every non-blank line as code.
+ *
+ * The same scan lists the comments themselves (see extractComments), from
+ * which annotations such as TODOs are read, and the string literals (see
+ * extractStrings) of the literal index.
+ */
+import { Language, type LineCounts } from '@/types/indexing';
+
+/**
+ * Comment text on one line
+ */
+export interface SourceComment {
+  line: number;
+  /** 1-based UTF-16 column of the comment marker (of the text, on lines a block comment continues) */
+  column: number;
+  /** Text between the markers */
+  text: string;
+  /** Nothing but whitespace precedes the comment on its line */
+  own_line: boolean;
+}
+
+/**
+ * String literal on one line, as written (escapes kept)
+ */
+export interface SourceString {
+  line: number;
+  /** 1-based UTF-16 column of the opening quote */
+  column: number;
+  /** Text between the quotes; to the end of the line for strings that continue on the next */
+  text: string;
+}
+
+/**
+ * Comment and string delimiters of a language
+ */
+interface CommentSyntax {
+  line: string[];
+  /** Block comment openers and their terminators */
+  block: [string, string][];
+  quotes: string[];
+}
+
+const C_STYLE: CommentSyntax = { line: ['//'], block: [['/*', '*/']], quotes: ['"', "'", '`'] };
+
+const COMMENT_SYNTAX: Partial<Record<string, CommentSyntax>> = {
+  [Language.TypeScript]: C_STYLE,
+  [Language.JavaScript]: C_STYLE,
+  [Language.Java]: C_STYLE,
+  [Language.Go]: C_STYLE,
+  [Language.Rust]: { ...C_STYLE, quotes: ['"'] },
+  [Language.C]: C_STYLE,
+  [Language.CPP]: C_STYLE,
+  [Language.CSharp]: C_STYLE,
+  [Language.Swift]: C_STYLE,
+  [Language.Kotlin]: C_STYLE,
+  [Language.PHP]: { ...C_STYLE, line: ['//', '#'] },
+  [Language.Python]: { line: ['#'], block: [], quotes: ['"', "'"] },
+  [Language.Ruby]: { line: ['#'], block: [['=begin', '=end']], quotes: ['"', "'"] },
+};
+
+/** Test files by name: _test.go, .test.ts, .spec.js, _spec.rb, FooTest.java, ... */
+const TEST_FILE_NAME = new RegExp(
+  [
+    /_test\.(?:go|py|rb|exs?)$/.source,
+    /\.(?:test|spec)\.[cm]?[jt]sx?$/.source,
+    /_spec\.rb$/.source,
+    /(?:Tests?|Spec)\.(?:java|kt|cs|swift|php)$/.source,
+    /^test_.*\.py$/.source,
+  ].join('|')
+);
+
+/** Directories holding tests */
+const TEST_DIRECTORY = /(?:^|\/)(?:tests?|__tests__|spec)\//;
+
+/**
+ * Index of the quote closing the string literal starting at a quote (-1 if it does not close on the line)
+ */
+const stringEnd = (line: string, start: number): number => {
+  const quote = line[start];
+  for (let i = start + 1; i < line.length; i++) {
+    if (line[i] === '\\') i++;
+    else if (line[i] === quote) return i;
+  }
+  return -1;
+};
+
+/**
+ * Classify the lines of a file, collecting the comments and strings on them
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @param comments - Receives each comment, one per line it spans
+ * @param strings - Receives each non-empty string literal
+ */
+const scanLines = (
+  content: string,
+  language: string,
+  comments?: SourceComment[],
+  strings?: SourceString[]
+): LineCounts => {
+  const counts: LineCounts = { code: 0, comment: 0, blank: 0 };
+  if (content.length === 0) return counts;
+  const syntax = COMMENT_SYNTAX[language];
+
+  // Terminator of the block comment the current line continues
+  let open: string | null = null;
+  const lines = content.split('\n');
+  for (let index = 0; index < lines.length; index++) {
+    const line = lines[index];
+    if (line.trim() === '') {
+      counts.blank++;
+      continue;
+    }
+    if (!syntax) {
+      counts.code++;
+      continue;
+    }
+
+    let code = false;
+    let i = 0;
+    const comment = (from: number, to: number, column: number): void => {
+      comments?.push({ line: index + 1, column: column + 1, text: line.slice(from, to), own_line: !code });
+    };
+    while (i < line.length) {
+      if (open !== null) {
+        const end = line.indexOf(open, i);
+        const column = i + (line.slice(i).length - line.slice(i).trimStart().length);
+        comment(i, end === -1 ? line.length : end, column);
+        if (end === -1) break;
+        i = end + open.length;
+        open = null;
+        continue;
+      }
+      const marker = syntax.line.find((candidate) => line.startsWith(candidate, i));
+      if (marker) {
+        comment(i + marker.length, line.length, i);
+        break;
+      }
+      const block = syntax.block.find(([start]) => line.startsWith(start, i));
+      if (block) {
+        open = block[1];
+        const end = line.indexOf(open, i + block[0].length);
+        comment(i + block[0].length, end === -1 ? line.length : end, i);
+        if (end === -1) break;
+        i = end + open.length;
+        open = null;
+        continue;
+      }
+
+      const char = line[i];
+      if (char === ' ' || char === '\t' || char === '\r') {
+        i++;
+        continue;
+      }
+      code = true;
+      if (!syntax.quotes.includes(char)) {
+        i++;
+        continue;
+      }
+      const close = stringEnd(line, i);
+      const text = line.slice(i + 1, close === -1 ? line.length : close);
+      if (text !== '') strings?.push({ line: index + 1, column: i + 1, text });
+      i = close === -1 ? line.length : close + 1;
+    }
+
+    if (code) counts.code++;
+    else counts.comment++;
+  }
+  return counts;
+};
+
+/**
+ * Count the code, comment, and blank lines of a file
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @returns Line counts adding up to the file's line count (see countLines)
+ */
+export const countLineKinds = (content: string, language: string): LineCounts => scanLines(content, language);
+
+/**
+ * List the comments of a file, as counted by countLineKinds
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @returns One comment per line a comment spans, in order (none without a known comment syntax)
+ */
+export const extractComments = (content: string, language: string): SourceComment[] => {
+  const comments: SourceComment[] = [];
+  scanLines(content, language, comments);
+  return comments;
+};
+
+/**
+ * List the string literals of a file
+ *
+ * Strings spanning lines (template literals, raw strings, docstrings) are
+ * listed up to the end of their first line.
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @returns Non-empty strings in order (none without a known comment syntax)
+ */
+export const extractStrings = (content: string, language: string): SourceString[] => {
+  const strings: SourceString[] = [];
+  scanLines(content, language, undefined, strings);
+  return strings;
+};
+
+/**
+ * Check whether a file holds tests, by its name or directory
+ *
+ * @param filePath - Repository-relative path (forward slashes)
+ */
+export const isTestPath = (filePath: string): boolean => {
+  const name = filePath.slice(filePath.lastIndexOf('/') + 1);
+  return TEST_FILE_NAME.test(name) || TEST_DIRECTORY.test(filePath);
+};
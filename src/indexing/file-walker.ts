@@ -3,183 +3,55 @@
  *
  * Recursively discovers code files in a repository with:
  * - .gitignore pattern application
- * - Binary and generated file exclusion
- * - SHA256 hash computation for incremental indexing
+ * - Binary file exclusion; generated files excluded or tagged
+ * - SHA256 hash computation for incremental indexing (skipped for files whose size and mtime are unchanged)
  * - Language detection by file extension
  * - Line counting and file statistics
+ * - Stable reads for indexing: files changing while read are re-read or deferred, never indexed torn
  * - Multi-project context detection (repo_id, workspace_id, service_id)
+ * - Nested .cindex.yaml overrides merged per subtree, .cindexignore files, and include lists
+ * - Symlink policy (skip, follow-within-root, follow-all) with loop detection
+ * - Directory depth and path length limits for pathological trees
  */
 
 import * as crypto from 'node:crypto';
+import { type Dirent } from 'node:fs';
 import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
 import ignore, { type Ignore } from 'ignore';
 
+import {
+  DEFAULT_BINARY_EXTENSIONS,
+  DEFAULT_GENERATED_FILE_NAMES,
+  resolveExcludedDirectories,
+} from '@indexing/default-exclusions';
+import { loadDirectoryConfig, loadIgnoreFile, mergeDirectoryConfig } from '@indexing/directory-config';
+import { isUnchangedOnDisk } from '@indexing/incremental';
+import { detectGenerated } from '@indexing/large-file-handler';
+import { type MemoryTree } from '@indexing/memory-tree';
+import { extractorForExtension } from '@indexing/plugins';
 import { createSecretFileDetector, type SecretFileDetector } from '@indexing/secret-file-detector';
+import { readStableSourceFile, readTextFile, type DecodedText } from '@utils/edge-cases';
 import { FileSystemError } from '@utils/errors';
 import { logger } from '@utils/logger';
+import { compareNames, compareStrings } from '@utils/ordering';
+import { isCaseInsensitiveFs, normalizeRootPath, pathKey, toPosixPath } from '@utils/paths';
+import { normalizeUnicode } from '@utils/unicode';
 import {
   Language,
   LANGUAGE_EXTENSIONS,
+  type DirectoryConfig,
   type DiscoveredFile,
   type FileDiscoveryStats,
+  type IndexedFileStamp,
   type IndexingOptions,
+  type SkippedFile,
+  type SkipReason,
+  type SymlinkPolicy,
+  type UnreadablePath,
 } from '@/types/indexing';
 
-/**
- * Binary file extensions to exclude from indexing
- * Media files are skipped - path saved but not parsed/embedded
- */
-const BINARY_EXTENSIONS = new Set([
-  // Images
-  '.png',
-  '.jpg',
-  '.jpeg',
-  '.gif',
-  '.bmp',
-  '.ico',
-  '.svg',
-  '.webp',
-  '.tiff',
-  '.tif',
-  '.raw',
-  '.heic',
-  '.heif',
-  '.avif',
-  '.psd',
-
-  // Video
-  '.mp4',
-  '.avi',
-  '.mov',
-  '.mkv',
-  '.webm',
-  '.wmv',
-  '.flv',
-  '.m4v',
-  '.3gp',
-  '.mpeg',
-  '.mpg',
-  '.ogv',
-
-  // Audio
-  '.mp3',
-  '.wav',
-  '.flac',
-  '.aac',
-  '.ogg',
-  '.wma',
-  '.m4a',
-  '.opus',
-  '.mid',
-  '.midi',
-
-  // Fonts
-  '.ttf',
-  '.otf',
-  '.woff',
-  '.woff2',
-  '.eot',
-
-  // Archives
-  '.zip',
-  '.tar',
-  '.gz',
-  '.bz2',
-  '.7z',
-  '.rar',
-  '.dmg',
-  '.iso',
-
-  // Executables & Libraries
-  '.exe',
-  '.dll',
-  '.so',
-  '.dylib',
-  '.wasm',
-  '.deb',
-  '.rpm',
-  '.apk',
-
-  // 3D Models & CAD
-  '.obj',
-  '.fbx',
-  '.blend',
-  '.max',
-  '.stl',
-  '.dae',
-
-  // Databases
-  '.db',
-  '.sqlite',
-  '.sqlite3',
-  '.mdb',
-  '.accdb',
-
-  // Office Documents
-  '.doc',
-  '.docx',
-  '.xls',
-  '.xlsx',
-  '.ppt',
-  '.pptx',
-  '.pdf',
-]);
-
-/**
- * Generated file patterns to exclude
- */
-const GENERATED_FILE_PATTERNS = [
-  'package-lock.json',
-  'yarn.lock',
-  'pnpm-lock.yaml',
-  'bun.lockb',
-  'Cargo.lock',
-  'Gemfile.lock',
-  'poetry.lock',
-  'composer.lock',
-  '.min.js',
-  '.bundle.js',
-  '.min.css',
-  '.map',
-  '-min.js',
-  '-bundle.js',
-];
-
-/**
- * Hardcoded directory exclusions (always ignored)
- */
-const EXCLUDED_DIRECTORIES = new Set([
-  'node_modules',
-  '.git',
-  '.svn',
-  '.hg',
-  'dist',
-  'build',
-  'out',
-  'coverage',
-  '.next',
-  '.nuxt',
-  '.cache',
-  '.parcel-cache',
-  '.turbo',
-  '__pycache__',
-  '.pytest_cache',
-  '.mypy_cache',
-  'venv',
-  'env',
-  '.venv',
-  '.env',
-  'target', // Rust
-  'bin',
-  'obj', // C#
-  'vendor', // PHP/Go
-  '.gradle',
-  '.mvn',
-  'bower_components',
-]);
-
 /**
  * Default indexing options
  */
@@ -193,6 +65,77 @@ const DEFAULT_OPTIONS: IndexingOptions = {
   enable_api_endpoint_detection: false,
 };
 
+/** Default symlink policy: follow links, but never out of the repository */
+const DEFAULT_SYMLINK_POLICY: SymlinkPolicy = 'follow-within-root';
+
+/** Default maximum directory nesting below the root */
+const DEFAULT_MAX_DIRECTORY_DEPTH = 64;
+
+/** Default maximum relative path length (characters) */
+const DEFAULT_MAX_PATH_LENGTH = 1024;
+
+/**
+ * Check whether a path is inside (or equal to) a directory
+ */
+const isWithin = (directory: string, target: string): boolean => {
+  const relative = path.relative(directory, target);
+  return relative === '' || (!relative.startsWith('..') && !path.isAbsolute(relative));
+};
+
+/**
+ * Count lines in file content (newlines + 1; 0 for an empty file)
+ */
+export const countLines = (content: string): number => {
+  return content.length === 0 ? 0 : content.split('\n').length;
+};
+
+/**
+ * Compute the SHA256 hash of decoded file content
+ *
+ * Used for incremental indexing - only re-index files with changed hashes
+ */
+export const computeContentHash = (content: string): string => {
+  return crypto.createHash('sha256').update(content, 'utf-8').digest('hex');
+};
+
+/** Unreadable paths listed by name in summaries */
+export const UNREADABLE_EXAMPLES = 5;
+
+/**
+ * Summarize unreadable paths by errno (e.g. "12 paths (EACCES 10, EIO 2)")
+ */
+export const summarizeUnreadablePaths = (paths: UnreadablePath[]): string => {
+  const byCode = new Map<string, number>();
+  for (const { code } of paths) {
+    byCode.set(code, (byCode.get(code) ?? 0) + 1);
+  }
+  const codes = [...byCode].sort((a, b) => b[1] - a[1] || compareStrings(a[0], b[0]));
+  const noun = paths.length === 1 ? 'path' : 'paths';
+  return `${String(paths.length)} ${noun} (${codes.map(([code, count]) => `${code} ${String(count)}`).join(', ')})`;
+};
+
+/**
+ * Patterns declared by a .cindex.yaml or .cindexignore, relative to its directory
+ */
+interface ScopedPatterns {
+  base: string;
+  filter: Ignore;
+  /** Declaring file, relative to the repository root */
+  source: string;
+}
+
+/**
+ * Directory-scoped settings inherited while walking the tree
+ */
+interface DirectoryScope {
+  /** Effective merged .cindex.yaml config */
+  config: DirectoryConfig;
+  /** Exclude patterns of every ancestor's .cindex.yaml and .cindexignore */
+  excludes: (ScopedPatterns & { reason: SkipReason })[];
+  /** Include patterns of the nearest .cindex.yaml that declares them (files outside are skipped) */
+  include?: ScopedPatterns;
+}
+
 /**
  * File walker for code discovery
  */
@@ -208,12 +151,21 @@ export class FileWalker {
     files_by_language: {} as Record<Language, number>,
     total_lines: 0,
   };
-
-  constructor(
-    private readonly rootPath: string,
-    options?: Partial<IndexingOptions>
-  ) {
+  private skipped: SkippedFile[] = [];
+  /** Real paths already walked (directories) or discovered (files), mapped to the path they were seen under */
+  private visited = new Map<string, string>();
+  /** Whether the repository's filesystem ignores case (paths are compared case-insensitively) */
+  private caseInsensitive = false;
+  /** Directory names never walked (defaults adjusted by EXCLUDE_DIRECTORIES) */
+  private readonly excludedDirectories: Set<string>;
+  /** What the index recorded per file; files with unchanged size and mtime are not read */
+  private indexedFiles = new Map<string, IndexedFileStamp>();
+  private readonly rootPath: string;
+
+  constructor(rootPath: string, options?: Partial<IndexingOptions>) {
+    this.rootPath = normalizeRootPath(rootPath);
     this.options = { ...DEFAULT_OPTIONS, ...options };
+    this.excludedDirectories = resolveExcludedDirectories(this.options.excludeDirectories);
 
     // Initialize secret file detector
     this.secretDetector = createSecretFileDetector({
@@ -234,17 +186,108 @@ export class FileWalker {
       options: this.options,
     });
 
+    this.skipped = [];
+    this.visited = new Map();
+    this.caseInsensitive = await isCaseInsensitiveFs(this.rootPath);
+
     // Load .gitignore patterns
     await this.loadGitignore();
 
-    // Recursively walk directory tree
-    const files = await this.walkDirectory(this.rootPath);
+    // Recursively walk directory tree (real paths detect symlink loops and duplicates)
+    const walkRoot = this.options.subdirectory ? path.join(this.rootPath, this.options.subdirectory) : this.rootPath;
+    let realRoot: string;
+    try {
+      realRoot = await fs.realpath(walkRoot);
+    } catch (error) {
+      throw new FileSystemError(`Failed to read directory: ${walkRoot}`, error as Error);
+    }
+    this.visited.set(pathKey(realRoot, this.caseInsensitive), '.');
+    const files = await this.walkDirectory(walkRoot, realRoot, realRoot, { config: {}, excludes: [] }, 0);
+
+    // One summary instead of a warning per unreadable file
+    const unreadable = this.getUnreadablePaths();
+    if (unreadable.length > 0) {
+      logger.warn(`Could not read ${summarizeUnreadablePaths(unreadable)}; they were skipped`, {
+        examples: unreadable.slice(0, UNREADABLE_EXAMPLES).map((entry) => `${entry.relative_path} (${entry.code})`),
+      });
+    }
 
     logger.info('File discovery complete', { ...this.stats });
 
     return files;
   };
 
+  /**
+   * Discover the indexable files of a tree held in memory (a git commit's, see @indexing/memory-tree)
+   *
+   * Files are filtered as on disk, by excluded directories, limits, names,
+   * and content; .gitignore, .cindex.yaml, and .cindexignore files are not
+   * applied (a commit's files are those it tracks). Absolute paths are
+   * nominal, below the root: content is read from the tree.
+   *
+   * @param tree - Files to discover, with paths relative to the root
+   */
+  public discoverTree = (tree: MemoryTree): DiscoveredFile[] => {
+    logger.info('Starting file discovery', { root: this.rootPath, in_memory: true, options: this.options });
+
+    this.skipped = [];
+    const maxDepth = this.options.maxDirectoryDepth ?? DEFAULT_MAX_DIRECTORY_DEPTH;
+    const maxPathLength = this.options.maxPathLength ?? DEFAULT_MAX_PATH_LENGTH;
+    const skippedDirectories = new Set<string>();
+    const files: DiscoveredFile[] = [];
+
+    for (const file of [...tree.list()].sort((a, b) => compareStrings(a.path, b.path))) {
+      const relativePath = file.path;
+      if (relativePath.length > maxPathLength) {
+        const detail = `${String(relativePath.length)} characters > ${String(maxPathLength)}`;
+        this.recordSkip(relativePath, 'path_length', detail);
+        continue;
+      }
+
+      // Excluded directories and those past the depth limit are reported once, as a walk would
+      const directories = relativePath.split('/').slice(0, -1);
+      const excluded = directories.findIndex((name, depth) => this.excludedDirectories.has(name) || depth >= maxDepth);
+      if (excluded !== -1) {
+        const directory = `${directories.slice(0, excluded + 1).join('/')}/`;
+        if (!skippedDirectories.has(directory)) {
+          skippedDirectories.add(directory);
+          if (this.excludedDirectories.has(directories[excluded])) {
+            this.recordSkip(directory, 'excluded_directory');
+          } else {
+            this.recordSkip(directory, 'depth_limit', `depth ${String(excluded + 1)} > ${String(maxDepth)}`);
+          }
+        }
+        continue;
+      }
+
+      const language = this.classifyPath(relativePath, {});
+      const source = language !== null ? tree.read(relativePath) : null;
+      if (language === null || !source) continue;
+
+      const absolutePath = path.join(this.rootPath, ...relativePath.split('/'));
+      const discoveredFile = this.describeContent(absolutePath, relativePath, language, source, {});
+      if (discoveredFile) {
+        files.push(discoveredFile);
+        this.stats.total_files++;
+      }
+    }
+
+    logger.info('File discovery complete', { ...this.stats });
+    return files;
+  };
+
+  /**
+   * Reuse recorded hashes and line counts for files unchanged since they were indexed
+   *
+   * Files whose size and modification time match their stamp are discovered
+   * without reading or hashing them.
+   *
+   * @param stamps - Map of relative path → what the index recorded
+   */
+  public reuseIndexedFiles = (stamps: Map<string, IndexedFileStamp>): void => {
+    this.indexedFiles = stamps;
+  };
+
   /**
    * Get file discovery statistics
    */
@@ -252,79 +295,303 @@ export class FileWalker {
     return { ...this.stats };
   };
 
+  /**
+   * Get paths excluded during the last discovery, with reasons
+   */
+  public getSkippedFiles = (): SkippedFile[] => {
+    return [...this.skipped];
+  };
+
+  /**
+   * Get paths the last discovery could not read (permission or I/O errors)
+   */
+  public getUnreadablePaths = (): UnreadablePath[] => {
+    return this.skipped
+      .filter((entry) => entry.reason === 'unreadable')
+      .map((entry) => ({ relative_path: entry.relative_path, code: entry.detail ?? 'UNKNOWN' }));
+  };
+
+  /**
+   * Record an excluded path for dry-run reporting
+   */
+  private recordSkip = (relativePath: string, reason: SkipReason, detail?: string): void => {
+    this.skipped.push({ relative_path: relativePath, reason, detail });
+  };
+
+  /**
+   * Record a path that failed to read (summarized at the end of discovery)
+   */
+  private recordUnreadable = (relativePath: string, error: unknown): void => {
+    const code = (error as NodeJS.ErrnoException).code ?? 'UNKNOWN';
+    logger.debug('Skipping unreadable path', { path: relativePath, code });
+    this.recordSkip(relativePath, 'unreadable', code);
+  };
+
   /**
    * Load and parse .gitignore file
    */
   private loadGitignore = async (): Promise<void> => {
     const gitignorePath = path.join(this.rootPath, '.gitignore');
 
+    // Match case like git does (core.ignorecase follows the filesystem)
+    const ignorecase = this.caseInsensitive;
+
+    if (this.options.respectGitignore === false) {
+      logger.debug('Ignoring .gitignore (respectGitignore disabled)');
+      this.ignoreFilter = ignore({ ignorecase });
+      return;
+    }
+
     try {
-      const content = await fs.readFile(gitignorePath, 'utf-8');
-      this.ignoreFilter = ignore().add(content);
+      const content = await readTextFile(gitignorePath);
+      this.ignoreFilter = ignore({ ignorecase }).add(content);
       logger.debug('Loaded .gitignore', { path: gitignorePath });
     } catch (error) {
       if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
         logger.debug('No .gitignore found, using default exclusions only');
-        this.ignoreFilter = ignore();
+        this.ignoreFilter = ignore({ ignorecase });
       } else {
         logger.warn('Error loading .gitignore', { error });
-        this.ignoreFilter = ignore();
+        this.ignoreFilter = ignore({ ignorecase });
       }
     }
   };
 
+  /**
+   * Resolve a symlink according to the symlink policy
+   *
+   * @param linkPath - Absolute path of the link
+   * @param relativePath - Link path relative to the repository root
+   * @param realRoot - Real path of the repository root
+   * @returns Real target path and type, or null if the link is not followed
+   */
+  private resolveSymlink = async (
+    linkPath: string,
+    relativePath: string,
+    realRoot: string
+  ): Promise<{ realPath: string; isDirectory: boolean } | null> => {
+    const policy = this.options.symlinkPolicy ?? DEFAULT_SYMLINK_POLICY;
+    if (policy === 'skip') {
+      this.recordSkip(relativePath, 'symlink', 'symlink policy is skip');
+      return null;
+    }
+
+    let realPath: string;
+    let isDirectory: boolean;
+    try {
+      realPath = await fs.realpath(linkPath);
+      isDirectory = (await fs.stat(realPath)).isDirectory();
+    } catch (error) {
+      // ELOOP: the link chain itself is circular
+      const code = (error as NodeJS.ErrnoException).code;
+      logger.debug('Skipping unresolvable symlink', { path: relativePath, code });
+      this.recordSkip(relativePath, 'symlink', code === 'ELOOP' ? 'circular link chain' : 'broken link');
+      return null;
+    }
+
+    const within = isWithin(pathKey(realRoot, this.caseInsensitive), pathKey(realPath, this.caseInsensitive));
+    if (policy === 'follow-within-root' && !within) {
+      logger.debug('Skipping symlink outside repository', { path: relativePath, target: realPath });
+      this.recordSkip(relativePath, 'symlink', `target outside repository: ${realPath}`);
+      return null;
+    }
+
+    return { realPath, isDirectory };
+  };
+
   /**
    * Recursively walk directory tree
+   *
+   * @param dirPath - Directory path as walked (may pass through followed symlinks)
+   * @param realDirPath - Real path of the directory
+   * @param realRoot - Real path of the repository root
+   * @param parentScope - Settings inherited from the parent directory
+   * @param depth - Nesting below the repository root (0 for the root itself)
    */
-  private walkDirectory = async (dirPath: string): Promise<DiscoveredFile[]> => {
+  private walkDirectory = async (
+    dirPath: string,
+    realDirPath: string,
+    realRoot: string,
+    parentScope: DirectoryScope,
+    depth: number
+  ): Promise<DiscoveredFile[]> => {
     const files: DiscoveredFile[] = [];
+    const scope = await this.enterDirectory(dirPath, parentScope);
+    const maxDepth = this.options.maxDirectoryDepth ?? DEFAULT_MAX_DIRECTORY_DEPTH;
+    const maxPathLength = this.options.maxPathLength ?? DEFAULT_MAX_PATH_LENGTH;
 
+    let entries: Dirent[];
     try {
-      const entries = await fs.readdir(dirPath, { withFileTypes: true });
+      entries = (await fs.readdir(dirPath, { withFileTypes: true })).sort(compareNames);
+    } catch (error) {
+      // The root must be readable; anything below it is skipped and summarized
+      if (depth === 0) {
+        throw new FileSystemError(`Failed to read directory: ${dirPath}`, error as Error);
+      }
+      this.recordUnreadable(`${toPosixPath(path.relative(this.rootPath, dirPath))}/`, error);
+      return files;
+    }
 
-      for (const entry of entries) {
-        const fullPath = path.join(dirPath, entry.name);
-        const relativePath = path.relative(this.rootPath, fullPath);
+    for (const entry of entries) {
+      const fullPath = path.join(dirPath, entry.name);
+      const relativePath = toPosixPath(path.relative(this.rootPath, fullPath));
+
+      // Over-long paths (runaway generated or nested trees) are reported, never walked
+      if (relativePath.length > maxPathLength) {
+        logger.debug('Skipping path over length limit', { path: relativePath, length: relativePath.length });
+        const skipped = entry.isDirectory() ? `${relativePath}/` : relativePath;
+        const detail = `${String(relativePath.length)} characters > ${String(maxPathLength)}`;
+        this.recordSkip(skipped, 'path_length', detail);
+        continue;
+      }
 
-        // Check if path is ignored by .gitignore
-        if (this.isIgnored(relativePath)) {
-          if (entry.isDirectory()) {
-            logger.debug('Directory ignored by .gitignore', { path: relativePath });
-          }
-          this.stats.excluded_by_gitignore++;
+      let realPath = path.join(realDirPath, entry.name);
+      let isDirectory = entry.isDirectory();
+      let isFile = entry.isFile();
+
+      // Symlinks: apply the policy, then treat the link as its target
+      if (entry.isSymbolicLink() && !this.isIgnored(relativePath)) {
+        const target = await this.resolveSymlink(fullPath, relativePath, realRoot);
+        if (!target) continue;
+        realPath = target.realPath;
+        isDirectory = target.isDirectory;
+        isFile = !target.isDirectory;
+      }
+
+      // Loops (link to an ancestor) and duplicates (two paths to one target) are walked once
+      const seenAs = this.visited.get(pathKey(realPath, this.caseInsensitive));
+      if (seenAs !== undefined && (isDirectory || isFile)) {
+        logger.debug('Skipping path already discovered', { path: relativePath, first: seenAs });
+        this.recordSkip(isDirectory ? `${relativePath}/` : relativePath, 'symlink', `same target as ${seenAs}`);
+        continue;
+      }
+
+      // Check if path is ignored by .gitignore
+      if (this.isIgnored(relativePath)) {
+        if (isDirectory) {
+          logger.debug('Directory ignored by .gitignore', { path: relativePath });
+        }
+        this.stats.excluded_by_gitignore++;
+        this.recordSkip(isDirectory ? `${relativePath}/` : relativePath, 'gitignore');
+        continue;
+      }
+
+      // Handle directories
+      if (isDirectory) {
+        // Skip excluded directories
+        if (this.excludedDirectories.has(entry.name)) {
+          logger.debug('Skipping excluded directory', { name: entry.name });
+          this.recordSkip(`${relativePath}/`, 'excluded_directory');
           continue;
         }
 
-        // Handle directories
-        if (entry.isDirectory()) {
-          // Skip excluded directories
-          if (EXCLUDED_DIRECTORIES.has(entry.name)) {
-            logger.debug('Skipping excluded directory', { name: entry.name });
-            continue;
-          }
+        // Skip directories excluded by a .cindex.yaml or .cindexignore
+        const excludedBy = this.excludedBy(scope, fullPath, true);
+        if (excludedBy) {
+          logger.debug('Directory excluded by directory config', { path: relativePath, config: excludedBy.source });
+          this.recordSkip(`${relativePath}/`, excludedBy.reason, excludedBy.source);
+          continue;
+        }
 
-          // Recursively walk subdirectory
-          const subFiles = await this.walkDirectory(fullPath);
-          files.push(...subFiles);
+        // Stop descending past the depth limit (bounds recursion on deeply nested trees)
+        if (depth + 1 > maxDepth) {
+          logger.debug('Skipping directory over depth limit', { path: relativePath, depth: depth + 1 });
+          this.recordSkip(`${relativePath}/`, 'depth_limit', `depth ${String(depth + 1)} > ${String(maxDepth)}`);
           continue;
         }
 
-        // Handle files
-        if (entry.isFile()) {
-          const discoveredFile = await this.processFile(fullPath, relativePath);
-          if (discoveredFile) {
-            files.push(discoveredFile);
-            this.stats.total_files++;
-          }
+        // Recursively walk subdirectory
+        this.visited.set(pathKey(realPath, this.caseInsensitive), `${relativePath}/`);
+        const subFiles = await this.walkDirectory(fullPath, realPath, realRoot, scope, depth + 1);
+        files.push(...subFiles);
+        continue;
+      }
+
+      // Handle files
+      if (isFile) {
+        const excludedBy = this.excludedBy(scope, fullPath, false);
+        if (excludedBy) {
+          logger.debug('File excluded by directory config', { path: relativePath, config: excludedBy.source });
+          this.recordSkip(relativePath, excludedBy.reason, excludedBy.source);
+          continue;
+        }
+
+        // With an include list in scope, only matching files are indexed
+        const { include } = scope;
+        if (include && !include.filter.ignores(toPosixPath(path.relative(include.base, fullPath)))) {
+          logger.debug('File not matched by include patterns', { path: relativePath, config: include.source });
+          this.recordSkip(relativePath, 'not_included', include.source);
+          continue;
+        }
+
+        this.visited.set(pathKey(realPath, this.caseInsensitive), relativePath);
+        const discoveredFile = await this.processFile(fullPath, relativePath, scope.config);
+        if (discoveredFile) {
+          files.push(discoveredFile);
+          this.stats.total_files++;
         }
       }
-    } catch (error) {
-      throw new FileSystemError(`Failed to read directory: ${dirPath}`, error as Error);
     }
 
     return files;
   };
 
+  /**
+   * Load the directory's .cindex.yaml and .cindexignore (if any) and merge them into the inherited scope
+   */
+  private enterDirectory = async (dirPath: string, parentScope: DirectoryScope): Promise<DirectoryScope> => {
+    const loaded = await loadDirectoryConfig(dirPath);
+    const ignoreFile = await loadIgnoreFile(dirPath);
+    if (!loaded && !ignoreFile) {
+      return parentScope;
+    }
+
+    const patterns = (lines: string | string[], source: string): ScopedPatterns => ({
+      base: dirPath,
+      filter: ignore({ ignorecase: this.caseInsensitive }).add(lines),
+      source: toPosixPath(path.relative(this.rootPath, source)),
+    });
+    const excludes = [...parentScope.excludes];
+    let { config, include } = parentScope;
+
+    if (loaded) {
+      logger.debug('Loaded directory config', { path: loaded.source, config: loaded.config });
+      if (loaded.config.exclude && loaded.config.exclude.length > 0) {
+        excludes.push({ ...patterns(loaded.config.exclude, loaded.source), reason: 'directory_config' });
+      }
+      // An empty list lifts an inherited one: the subtree is indexed whole again
+      if (loaded.config.include) {
+        include = loaded.config.include.length > 0 ? patterns(loaded.config.include, loaded.source) : undefined;
+      }
+      config = mergeDirectoryConfig(config, loaded.config);
+    }
+    if (ignoreFile) {
+      logger.debug('Loaded ignore file', { path: ignoreFile.source });
+      excludes.push({ ...patterns(ignoreFile.patterns, ignoreFile.source), reason: 'cindexignore' });
+    }
+
+    return { config, excludes, include };
+  };
+
+  /**
+   * Find the .cindex.yaml or .cindexignore whose exclude patterns match a path
+   *
+   * @returns Relative path of the matching file and the skip reason it gives, or null
+   */
+  private excludedBy = (
+    scope: DirectoryScope,
+    absolutePath: string,
+    isDirectory: boolean
+  ): { source: string; reason: SkipReason } | null => {
+    for (const exclude of scope.excludes) {
+      const relative = toPosixPath(path.relative(exclude.base, absolutePath));
+      if (exclude.filter.ignores(isDirectory ? `${relative}/` : relative)) {
+        return { source: exclude.source, reason: exclude.reason };
+      }
+    }
+    return null;
+  };
+
   /**
    * Check if path is ignored by .gitignore patterns
    */
@@ -333,23 +600,88 @@ export class FileWalker {
       return false;
     }
 
-    // Normalize path separators for ignore library (always use forward slashes)
-    const normalizedPath = relativePath.split(path.sep).join('/');
-
-    return this.ignoreFilter.ignores(normalizedPath);
+    // Relative paths already use forward slashes, as the ignore library expects
+    return this.ignoreFilter.ignores(relativePath);
   };
 
   /**
    * Process individual file and extract metadata
    */
-  private processFile = async (absolutePath: string, relativePath: string): Promise<DiscoveredFile | null> => {
-    const ext = path.extname(absolutePath).toLowerCase();
-    const basename = path.basename(absolutePath);
+  private processFile = async (
+    absolutePath: string,
+    relativePath: string,
+    directoryConfig: DirectoryConfig
+  ): Promise<DiscoveredFile | null> => {
+    const language = this.classifyPath(relativePath, directoryConfig);
+    if (language === null) {
+      return null;
+    }
+
+    const maxFileSize = directoryConfig.max_file_size ?? this.options.maxFileSize ?? 5000;
+    const maxFileBytes = directoryConfig.max_file_bytes;
+
+    try {
+      // Byte limit: checked before reading, so oversized dumps and fixtures are never loaded
+      if (maxFileBytes !== undefined) {
+        const { size } = await fs.stat(absolutePath);
+        if (this.exceedsByteLimit(relativePath, size, maxFileBytes)) {
+          return null;
+        }
+      }
+
+      // Unchanged since it was indexed (same size and mtime): reuse the recorded hash instead of reading
+      const stamp = this.indexedFiles.get(relativePath);
+      if (stamp && stamp.line_count <= maxFileSize) {
+        const stats = await fs.stat(absolutePath);
+        if (isUnchangedOnDisk(stamp, stats.size, stats.mtime)) {
+          return this.withContext(
+            {
+              absolute_path: absolutePath,
+              relative_path: relativePath,
+              file_hash: stamp.file_hash,
+              language,
+              line_count: stamp.line_count,
+              file_size_bytes: stats.size,
+              modified_time: stats.mtime,
+              encoding: stamp.encoding,
+            },
+            directoryConfig
+          );
+        }
+      }
+
+      // Read file stats and content (transcoded to UTF-8 from its detected encoding)
+      const source = await readStableSourceFile(absolutePath);
+      if (!source) {
+        logger.warn('File kept changing while being read, deferring it to the next run', { path: relativePath });
+        this.recordSkip(relativePath, 'changing', 'modified while being read');
+        return null;
+      }
+      return this.describeContent(absolutePath, relativePath, language, source, directoryConfig);
+    } catch (error) {
+      // Permission and I/O errors skip the file; anything else is a bug worth surfacing
+      if (typeof (error as NodeJS.ErrnoException).code === 'string') {
+        this.recordUnreadable(relativePath, error);
+        return null;
+      }
+      throw new FileSystemError(`Failed to process file: ${relativePath}`, error as Error);
+    }
+  };
+
+  /**
+   * Apply the checks a file's path decides: binary, generated, and secret names, language, and the language filter
+   *
+   * @returns Language of the file, or null if it is skipped
+   */
+  private classifyPath = (relativePath: string, directoryConfig: DirectoryConfig): Language | null => {
+    const ext = path.extname(relativePath).toLowerCase();
+    const basename = path.basename(relativePath);
 
     // Exclude binary files
-    if (BINARY_EXTENSIONS.has(ext)) {
+    if (DEFAULT_BINARY_EXTENSIONS.has(ext) || directoryConfig.binary_extensions?.includes(ext)) {
       logger.debug('Skipping binary file', { path: relativePath });
       this.stats.excluded_binary++;
+      this.recordSkip(relativePath, 'binary', `extension ${ext}`);
       return null;
     }
 
@@ -357,6 +689,7 @@ export class FileWalker {
     if (this.isGeneratedFile(basename)) {
       logger.debug('Skipping generated file', { path: relativePath });
       this.stats.excluded_binary++;
+      this.recordSkip(relativePath, 'generated');
       return null;
     }
 
@@ -368,6 +701,7 @@ export class FileWalker {
         pattern: matchedPattern,
       });
       this.stats.excluded_by_secret_protection++;
+      this.recordSkip(relativePath, 'secret', matchedPattern ? `pattern ${matchedPattern}` : undefined);
       return null;
     }
 
@@ -381,6 +715,7 @@ export class FileWalker {
 
       if (!isRootReadme) {
         logger.debug('Skipping markdown file (use index_documentation for markdown)', { path: relativePath });
+        this.recordSkip(relativePath, 'markdown', 'use index_documentation for markdown');
         return null;
       }
     }
@@ -388,76 +723,137 @@ export class FileWalker {
     // Skip unknown file types
     if (language === Language.Unknown && ext !== '.md') {
       logger.debug('Skipping unknown file type', { path: relativePath, ext });
+      this.recordSkip(relativePath, 'unsupported_language', ext ? `extension ${ext}` : 'no extension');
       return null;
     }
 
-    try {
-      // Read file stats and content
-      const stats = await fs.stat(absolutePath);
-      const content = await fs.readFile(absolutePath, 'utf-8');
-
-      // Count lines
-      const lineCount = this.countLines(content);
-
-      // Check file size limit (default: 5000 lines)
-      const maxFileSize = this.options.maxFileSize ?? 5000;
-      if (lineCount > maxFileSize) {
-        logger.warn('Skipping large file', {
-          path: relativePath,
-          lines: lineCount,
-          max: maxFileSize,
-        });
-        this.stats.excluded_size++;
-        return null;
-      }
+    // Apply the .cindex.yaml language filter (none = all languages)
+    const languages = directoryConfig.languages ?? [];
+    if (languages.length > 0 && language !== Language.Unknown && !languages.includes(language)) {
+      logger.debug('Skipping file excluded by language filter', { path: relativePath, language });
+      this.recordSkip(relativePath, 'language_filter', language);
+      return null;
+    }
 
-      // Compute SHA256 hash for incremental indexing
-      const fileHash = this.computeHash(content);
-
-      // Update statistics
-      this.stats.files_by_language[language] = (this.stats.files_by_language[language] || 0) + 1;
-      this.stats.total_lines += lineCount;
-
-      // Build discovered file metadata
-      const discoveredFile: DiscoveredFile = {
-        absolute_path: absolutePath,
-        relative_path: relativePath,
-        file_hash: fileHash,
-        language,
-        line_count: lineCount,
-        file_size_bytes: stats.size,
-        modified_time: stats.mtime,
-        encoding: 'utf-8',
-      };
-
-      // Add repository context when repo_id is provided
-      // Note: repo_id should always be set when provided, regardless of enable_multi_repo flag
-      if (this.options.repoId) {
-        discoveredFile.repo_id = this.options.repoId;
-      }
+    return language;
+  };
+
+  /**
+   * Check the byte limit of a file, recording the skip when it is over
+   */
+  private exceedsByteLimit = (relativePath: string, size: number, maxFileBytes: number): boolean => {
+    if (size <= maxFileBytes) {
+      return false;
+    }
+    logger.debug('Skipping file over byte limit', { path: relativePath, bytes: size, max: maxFileBytes });
+    this.stats.excluded_size++;
+    this.recordSkip(relativePath, 'size_limit', `${String(size)} bytes > ${String(maxFileBytes)}`);
+    return true;
+  };
+
+  /**
+   * Apply the checks a file's content decides (encoding, generated markers, line limit) and describe the file
+   *
+   * @returns Discovered file, or null if it is skipped
+   */
+  private describeContent = (
+    absolutePath: string,
+    relativePath: string,
+    language: Language,
+    source: DecodedText & { stats: { size: number; mtime: Date } },
+    directoryConfig: DirectoryConfig
+  ): DiscoveredFile | null => {
+    const { content, encoding, bom, stats } = source;
+
+    // Skip files whose bytes are not text in any supported encoding
+    if (encoding === 'binary') {
+      logger.debug('Skipping file with undetectable encoding', { path: relativePath });
+      this.stats.excluded_binary++;
+      this.recordSkip(relativePath, 'encoding', 'not text in a supported encoding');
+      return null;
+    }
+
+    // Generated output (DO NOT EDIT headers, *.pb.go, minified JS): skip, or index tagged
+    const generated = detectGenerated(relativePath, content);
+    if (generated && this.options.generatedFiles !== 'tag') {
+      logger.debug('Skipping generated file', { path: relativePath, reason: generated });
+      this.stats.excluded_binary++;
+      this.recordSkip(relativePath, 'generated', generated);
+      return null;
+    }
 
-      logger.debug('File discovered', {
+    // Count lines
+    const lineCount = countLines(content);
+
+    // Check file size limit (default: 5000 lines)
+    const maxFileSize = directoryConfig.max_file_size ?? this.options.maxFileSize ?? 5000;
+    if (lineCount > maxFileSize) {
+      logger.warn('Skipping large file', {
         path: relativePath,
-        language,
         lines: lineCount,
-        hash: fileHash.substring(0, 8),
+        max: maxFileSize,
       });
+      this.stats.excluded_size++;
+      this.recordSkip(relativePath, 'size_limit', `${String(lineCount)} lines > ${String(maxFileSize)}`);
+      return null;
+    }
 
-      return discoveredFile;
-    } catch (error) {
-      // Handle encoding errors (binary files misdetected as text)
-      if ((error as Error).message.includes('invalid')) {
-        logger.debug('Skipping file with encoding issues', { path: relativePath });
-        this.stats.excluded_binary++;
-        return null;
-      }
+    // Compute SHA256 hash for incremental indexing
+    const fileHash = computeContentHash(content);
+
+    // Build discovered file metadata
+    const discoveredFile: DiscoveredFile = {
+      absolute_path: absolutePath,
+      relative_path: relativePath,
+      file_hash: fileHash,
+      language,
+      line_count: lineCount,
+      file_size_bytes: stats.size,
+      modified_time: stats.mtime,
+      encoding,
+    };
+
+    if (bom) {
+      discoveredFile.bom = true;
+    }
 
-      throw new FileSystemError(`Failed to process file: ${relativePath}`, error as Error);
+    if (generated) {
+      discoveredFile.generated = generated;
     }
+
+    return this.withContext(discoveredFile, directoryConfig);
   };
 
   /**
-   * Detect programming language from file extension
+   * Add repository context to a discovered file and count it in the statistics
+   */
+  private withContext = (discoveredFile: DiscoveredFile, directoryConfig: DirectoryConfig): DiscoveredFile => {
+    const { language } = discoveredFile;
+    this.stats.files_by_language[language] = (this.stats.files_by_language[language] || 0) + 1;
+    this.stats.total_lines += discoveredFile.line_count;
+
+    // Add repository context when repo_id is provided
+    // Note: repo_id should always be set when provided, regardless of enable_multi_repo flag
+    if (this.options.repoId) {
+      discoveredFile.repo_id = this.options.repoId;
+    }
+
+    if (Object.keys(directoryConfig).length > 0) {
+      discoveredFile.directory_config = directoryConfig;
+    }
+
+    logger.debug('File discovered', {
+      path: discoveredFile.relative_path,
+      language,
+      lines: discoveredFile.line_count,
+      hash: discoveredFile.file_hash.substring(0, 8),
+    });
+
+    return discoveredFile;
+  };
+
+  /**
+   * Detect programming language from file extension (an extractor plugin's, when one claims it)
    */
   private detectLanguage = (ext: string, _basename: string): Language => {
     // Special case for markdown
@@ -465,6 +861,9 @@ export class FileWalker {
       return Language.Unknown; // Markdown handled separately
     }
 
+    // Plugin languages are names outside the enum, e.g. abap
+    const extractor = extractorForExtension(ext);
+    if (extractor) return extractor.language as Language;
     return LANGUAGE_EXTENSIONS[ext] ?? Language.Unknown;
   };
 
@@ -472,31 +871,54 @@ export class FileWalker {
    * Check if file is a generated/build artifact
    */
   private isGeneratedFile = (basename: string): boolean => {
-    return GENERATED_FILE_PATTERNS.some((pattern) => basename.includes(pattern));
+    return DEFAULT_GENERATED_FILE_NAMES.some((pattern) => basename.includes(pattern));
   };
+}
 
-  /**
-   * Count lines in file content
-   */
-  private countLines = (content: string): number => {
-    if (content.length === 0) {
-      return 0;
-    }
+/**
+ * Read a discovered file for indexing and reconcile it with its discovery snapshot
+ *
+ * The read is retried until the file is not being modified, so a torn mix of
+ * old and new content is never indexed. If the file changed since discovery,
+ * hash and line count are taken from what was read, so the stored hash always
+ * describes the stored content.
+ *
+ * Files discovered in a memory tree are read from it.
+ *
+ * @param discovered - Discovered file metadata
+ * @param tree - Tree the file was discovered in (default: read from disk)
+ * @returns File metadata matching the content, and the content (UTF-8, NFC)
+ * @throws {Error} If the file kept changing (deferred: the next incremental run retries it), or is not in the tree
+ */
+export const readDiscoveredFile = async (
+  discovered: DiscoveredFile,
+  tree?: MemoryTree
+): Promise<{ file: DiscoveredFile; content: string }> => {
+  const source = tree ? tree.read(discovered.relative_path) : await readStableSourceFile(discovered.absolute_path);
+  if (!source) {
+    throw new Error(
+      tree ? 'File is not in the tree being indexed' : 'File kept changing while being read; deferred to the next run'
+    );
+  }
 
-    // Count newlines + 1 (last line may not have newline)
-    const lines = content.split('\n').length;
-    return lines;
-  };
+  const fileHash = computeContentHash(source.content);
+  if (fileHash === discovered.file_hash) {
+    // Files discovered by their stamp were not read, so the byte order mark is only known now
+    const file = source.bom && !discovered.bom ? { ...discovered, bom: true } : discovered;
+    return { file, content: normalizeUnicode(source.content) };
+  }
 
-  /**
-   * Compute SHA256 hash of file content
-   *
-   * Used for incremental indexing - only re-index files with changed hashes
-   */
-  private computeHash = (content: string): string => {
-    return crypto.createHash('sha256').update(content, 'utf-8').digest('hex');
+  logger.info('File changed since discovery, indexing its current content', { file: discovered.relative_path });
+  const file: DiscoveredFile = {
+    ...discovered,
+    file_hash: fileHash,
+    line_count: countLines(source.content),
+    file_size_bytes: source.stats.size,
+    modified_time: source.stats.mtime,
+    bom: source.bom || undefined,
   };
-}
+  return { file, content: normalizeUnicode(source.content) };
+};
 
 /**
  * Discover files in a repository (convenience function)
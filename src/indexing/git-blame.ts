@@ -0,0 +1,129 @@
+/**
+ * Git Blame: Line Authorship of Indexed Files (cindex owners)
+ *
+ * Authorship is read from the repository's git history when a report is
+ * made: `git blame --line-porcelain` attributes each line of the working-tree
+ * file to the author of the commit that last changed it, following .mailmap.
+ * Lines not committed yet have no author. `cindex index --history` also
+ * records each symbol's last change in the index.
+ */
+
+import { execFile } from 'node:child_process';
+import { promisify } from 'node:util';
+
+import { logger } from '@utils/logger';
+import { type LastChange } from '@/types/indexing';
+
+const execFileAsync = promisify(execFile);
+
+/** Maximum blame output buffered per file (porcelain repeats commit details on every line) */
+const BLAME_MAX_BUFFER = 256 * 1024 * 1024;
+
+/** Files blamed at once */
+export const BLAME_CONCURRENCY = 8;
+
+/** Object name git blame reports for lines not committed yet */
+const UNCOMMITTED = /^0{40}$/;
+
+/**
+ * Commit that last changed a line
+ */
+export interface BlameLine {
+  commit: string;
+  author: string | null;
+  /** Author time of the commit, in seconds since the epoch */
+  time: number;
+}
+
+/**
+ * Parse `git blame --line-porcelain` output into the commit of each line
+ *
+ * @param text - Blame output of one file
+ * @returns Commit per line (index 0 is line 1), null for uncommitted lines
+ */
+export const parseBlameLines = (text: string): (BlameLine | null)[] => {
+  const lines: (BlameLine | null)[] = [];
+  let commit = '';
+  let author: string | null = null;
+  let time = 0;
+
+  for (const line of text.split('\n')) {
+    if (line.startsWith('\t')) {
+      // Content line ends the entry
+      lines.push(UNCOMMITTED.test(commit) ? null : { commit, author, time });
+      author = null;
+      time = 0;
+    } else if (line.startsWith('author ')) {
+      author = line.slice('author '.length);
+    } else if (line.startsWith('author-time ')) {
+      time = Number(line.slice('author-time '.length));
+    } else if (/^[0-9a-f]{40} \d+ \d+/.test(line)) {
+      commit = line.slice(0, 40);
+    }
+  }
+  return lines;
+};
+
+/**
+ * Parse `git blame --line-porcelain` output
+ *
+ * @param text - Blame output of one file
+ * @returns Author per line (index 0 is line 1), null for uncommitted lines
+ */
+export const parseBlamePorcelain = (text: string): (string | null)[] => {
+  return parseBlameLines(text).map((line) => line?.author ?? null);
+};
+
+/**
+ * Find the most recent change to a span of blamed lines
+ *
+ * @param lines - Commit per line of a file
+ * @param start - First line (1-based)
+ * @param end - Last line (default: the first)
+ * @returns Latest commit by author time, or null if no line of the span is committed
+ */
+export const lastChange = (lines: (BlameLine | null)[], start: number, end: number = start): LastChange | null => {
+  let latest: BlameLine | null = null;
+  for (const line of lines.slice(start - 1, end)) {
+    if (line && (!latest || line.time > latest.time)) latest = line;
+  }
+  if (!latest) return null;
+  return { commit: latest.commit, author: latest.author, date: new Date(latest.time * 1000).toISOString() };
+};
+
+/**
+ * Blame one file of a repository, by commit
+ *
+ * @param repoPath - Repository root path
+ * @param filePath - File path relative to the root (forward slashes)
+ * @returns Commit per line, or null if git cannot blame the file (not a worktree, untracked file)
+ */
+export const blameFileLines = async (repoPath: string, filePath: string): Promise<(BlameLine | null)[] | null> => {
+  try {
+    const { stdout } = await execFileAsync(
+      'git',
+      ['-C', repoPath, 'blame', '--line-porcelain', '-w', '--', filePath],
+      { maxBuffer: BLAME_MAX_BUFFER }
+    );
+    return parseBlameLines(stdout);
+  } catch (error) {
+    logger.debug('git blame unavailable for file', {
+      repo: repoPath,
+      file: filePath,
+      error: error instanceof Error ? error.message : String(error),
+    });
+    return null;
+  }
+};
+
+/**
+ * Blame one file of a repository
+ *
+ * @param repoPath - Repository root path
+ * @param filePath - File path relative to the root (forward slashes)
+ * @returns Author per line, or null if git cannot blame the file (not a worktree, untracked file)
+ */
+export const blameFile = async (repoPath: string, filePath: string): Promise<(string | null)[] | null> => {
+  const lines = await blameFileLines(repoPath, filePath);
+  return lines ? lines.map((line) => line?.author ?? null) : null;
+};
@@ -0,0 +1,256 @@
+/**
+ * Go call graph: caller → callee edges found in function bodies
+ *
+ * Calls are read from the source, without type checking, so indexing stays
+ * fast and needs no Go toolchain. Each call is resolved as far as syntax
+ * allows:
+ *
+ *   queryUser(email)       function  same-package function queryUser
+ *   s.queryUser(email)     method    AuthService.queryUser, s being the receiver
+ *   fmt.Sprintf(...)       import    Sprintf of the package imported as fmt
+ *   svc.CreateSession(id)  dynamic   a CreateSession method of unknown receiver type
+ *
+ * Calls inside function literals belong to the enclosing declaration.
+ * Builtins (len, append, ...) and conversions to predeclared types are left
+ * out; conversions to other types look like calls and are kept.
+ */
+
+import { type GoCall } from '@/types/indexing';
+
+/** Keywords that can precede a parenthesis */
+const KEYWORDS = new Set([
+  'break',
+  'case',
+  'chan',
+  'const',
+  'continue',
+  'default',
+  'defer',
+  'else',
+  'fallthrough',
+  'for',
+  'func',
+  'go',
+  'goto',
+  'if',
+  'import',
+  'interface',
+  'map',
+  'package',
+  'range',
+  'return',
+  'select',
+  'struct',
+  'switch',
+  'type',
+  'var',
+]);
+
+/** Builtin functions and predeclared types (conversions) */
+const PREDECLARED = new Set([
+  'any',
+  'append',
+  'bool',
+  'byte',
+  'cap',
+  'clear',
+  'close',
+  'complex',
+  'complex64',
+  'complex128',
+  'copy',
+  'delete',
+  'error',
+  'float32',
+  'float64',
+  'imag',
+  'int',
+  'int8',
+  'int16',
+  'int32',
+  'int64',
+  'len',
+  'make',
+  'max',
+  'min',
+  'new',
+  'panic',
+  'print',
+  'println',
+  'real',
+  'recover',
+  'rune',
+  'string',
+  'uint',
+  'uint8',
+  'uint16',
+  'uint32',
+  'uint64',
+  'uintptr',
+]);
+
+/** Declaration header: optional receiver (name, type) and the declared name */
+const DECLARATION =
+  /^\s*func\s*(?:\(\s*(?:([A-Za-z_]\w*)\s+)?\*?\s*([A-Za-z_]\w*)\s*(?:\[[^\]]*\])?\s*\)\s*)?([A-Za-z_]\w*)/;
+
+/** Call: an optional selector chain, the callee identifier, and its opening parenthesis */
+const CALL = /((?:[A-Za-z_]\w*\s*\.\s*)*)([A-Za-z_]\w*)\s*\(/g;
+
+/** Import spec: optional name and the quoted path */
+const IMPORT_SPEC = /(?:^|\s)(?:([A-Za-z_]\w*|\.)\s+)?"([^"\n]+)"/g;
+
+/**
+ * Blank out comments and, optionally, string and rune literals
+ *
+ * Offsets and line breaks are kept, so positions in the result are positions
+ * in the source.
+ *
+ * @param code - Go source
+ * @param strings - Also blank literals (their quotes stay)
+ */
+export const maskGo = (code: string, strings: boolean): string => {
+  const out = code.split('');
+  const blank = (from: number, to: number): void => {
+    for (let i = from; i < to; i++) {
+      if (out[i] !== '\n') out[i] = ' ';
+    }
+  };
+
+  for (let i = 0; i < code.length; i++) {
+    const char = code[i];
+    if (char === '/' && code[i + 1] === '/') {
+      const end = code.indexOf('\n', i);
+      blank(i, end === -1 ? code.length : end);
+      i = end === -1 ? code.length : end;
+    } else if (char === '/' && code[i + 1] === '*') {
+      const end = code.indexOf('*/', i + 2);
+      blank(i, end === -1 ? code.length : end + 2);
+      i = end === -1 ? code.length : end + 1;
+    } else if (char === '"' || char === "'" || char === '`') {
+      let end = i + 1;
+      while (end < code.length && code[end] !== char && (char === '`' || code[end] !== '\n')) {
+        if (code[end] === '\\' && char !== '`') end++;
+        end++;
+      }
+      if (strings) blank(i + 1, end);
+      i = end;
+    }
+  }
+  return out.join('');
+};
+
+/**
+ * Name a package gets when imported without one
+ *
+ * The last path element, skipping a major version (example.com/mod/v2 → mod)
+ * and trimming gopkg.in versions (yaml.v3 → yaml) and a go- prefix or -go
+ * suffix (go-sqlite3 → sqlite3).
+ */
+export const defaultImportName = (importPath: string): string => {
+  const elements = importPath.split('/');
+  let name = elements[elements.length - 1];
+  if (/^v\d+$/.test(name) && elements.length > 1) name = elements[elements.length - 2];
+  return name.replace(/\.v\d+$/, '').replace(/^go-/, '').replace(/-go$/, '');
+};
+
+/**
+ * Map the names a Go file refers to its imports by to their paths
+ *
+ * Blank (_) and dot imports are left out: they add no name to call through.
+ *
+ * @param content - Go source
+ * @returns Map of name → import path
+ */
+export const parseGoImports = (content: string): Map<string, string> => {
+  const code = maskGo(content, false);
+  const imports = new Map<string, string>();
+  const declarations = code.matchAll(/\bimport\s*(?:\(([^)]*)\)|((?:[A-Za-z_]\w*\s+|\.\s+)?"[^"\n]+"))/g);
+  for (const declaration of declarations) {
+    for (const spec of (declaration[1] ?? declaration[2]).matchAll(IMPORT_SPEC)) {
+      const [, name, importPath] = spec;
+      if (name === '_' || name === '.') continue;
+      imports.set(name ?? defaultImportName(importPath), importPath);
+    }
+  }
+  return imports;
+};
+
+/**
+ * Offset of the body's opening brace after a declaration header
+ *
+ * Braces of struct and interface types in the signature are skipped.
+ *
+ * @returns Offset of the brace, or -1 if the declaration has no body
+ */
+export const bodyStart = (code: string, from: number): number => {
+  let depth = 0;
+  for (let i = from; i < code.length; i++) {
+    const char = code[i];
+    if (char === '(' || char === '[') depth++;
+    else if (char === ')' || char === ']') depth--;
+    else if (char === '{') {
+      if (depth === 0 && !/\b(?:struct|interface)\s*$/.test(code.slice(Math.max(0, i - 12), i))) return i;
+      // Type literal in the signature: skip to its closing brace
+      let braces = 1;
+      while (braces > 0 && ++i < code.length) {
+        if (code[i] === '{') braces++;
+        else if (code[i] === '}') braces--;
+      }
+    }
+  }
+  return -1;
+};
+
+/**
+ * Extract the calls made by a Go function or method declaration
+ *
+ * @param code - Source of the declaration, from `func` to its closing brace
+ * @param startLine - Line the declaration starts on
+ * @param imports - Import names of the file (see parseGoImports)
+ * @returns Calls in source order, or none if the code is not a declaration with a body
+ */
+export const extractGoCalls = (code: string, startLine: number, imports: ReadonlyMap<string, string>): GoCall[] => {
+  const masked = maskGo(code, true);
+  const header = DECLARATION.exec(masked);
+  if (!header) return [];
+  const [, receiverName, receiverType, name] = header;
+  const caller = receiverType ? `${receiverType}.${name}` : name;
+  const body = bodyStart(masked, header[0].length);
+  if (body === -1) return [];
+
+  const lines = code.split('\n');
+  const lineStarts: number[] = [0];
+  for (const line of lines) lineStarts.push(lineStarts[lineStarts.length - 1] + line.length + 1);
+  const position = (offset: number): { line: number; column: number } => {
+    let index = 0;
+    while (lineStarts[index + 1] <= offset) index++;
+    const prefix = lines[index].slice(0, offset - lineStarts[index]);
+    return { line: startLine + index, column: Buffer.byteLength(prefix, 'utf-8') + 1 };
+  };
+
+  const calls: GoCall[] = [];
+  for (const match of masked.matchAll(CALL)) {
+    if (match.index < body) continue;
+    const chain = match[1] ? match[1].split('.').map((part) => part.trim()).filter(Boolean) : [];
+    const callee = match[2];
+    // A call on a call result or index expression: foo().Bar(), items[i].Close()
+    const onExpression = /[)\]]\s*\.\s*$/.test(masked.slice(Math.max(0, match.index - 64), match.index));
+    const calleeOffset = match.index + match[0].lastIndexOf(callee, match[0].length - 1);
+
+    let call: Pick<GoCall, 'callee' | 'kind' | 'callee_package' | 'qualifier'>;
+    if (onExpression) {
+      call = { callee, kind: 'dynamic', callee_package: null, qualifier: ['()', ...chain].join('.') };
+    } else if (chain.length === 0) {
+      if (KEYWORDS.has(callee) || PREDECLARED.has(callee)) continue;
+      call = { callee, kind: 'function', callee_package: null, qualifier: null };
+    } else if (chain.length === 1 && receiverName && chain[0] === receiverName) {
+      call = { callee: `${receiverType}.${callee}`, kind: 'method', callee_package: null, qualifier: null };
+    } else if (chain.length === 1 && imports.has(chain[0])) {
+      call = { callee, kind: 'import', callee_package: imports.get(chain[0]) ?? null, qualifier: null };
+    } else {
+      call = { callee, kind: 'dynamic', callee_package: null, qualifier: chain.join('.') };
+    }
+    calls.push({ caller, caller_line: startLine, ...call, ...position(calleeOffset) });
+  }
+  return calls;
+};
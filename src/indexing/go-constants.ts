@@ -0,0 +1,455 @@
+/**
+ * Go constants: top-level const declarations with their values
+ *
+ * Values are evaluated from the source, without type checking, as far as the
+ * file determines them:
+ *
+ *   const SessionTimeout = 3600     3600
+ *   const (
+ *       RoleUser UserRole = iota    0, type UserRole
+ *       RoleModerator               1, repeating `UserRole = iota`
+ *       _                           2, skipped
+ *       KB = 1 << (10 * (iota - 2)) 1024
+ *   )
+ *
+ * Literals, iota, constants declared earlier or later in the same file, and
+ * the constant operators (arithmetic, shifts, bitwise, comparison, string
+ * concatenation) are evaluated; conversions keep their operand's value and
+ * name the constant's type. A value that uses a name declared elsewhere
+ * (time.Second, a constant of another file) is left unresolved.
+ */
+
+import { cleanDocComment } from '@indexing/doc-comments';
+import { maskGo } from '@indexing/go-calls';
+import { type GoConstant } from '@/types/indexing';
+
+/** Builtins allowed in constant expressions (a call to anything else is a conversion) */
+const BUILTINS = new Set(['len', 'cap', 'real', 'imag', 'complex', 'min', 'max']);
+
+/** Constant spec: names, an optional type, and optional values */
+const SPEC = /^([A-Za-z_]\w*(?:\s*,\s*[A-Za-z_]\w*)*)\s*([^=]*?)\s*(?:=\s*([\s\S]*))?$/;
+
+/** Conversion: a (possibly qualified) type name and its parenthesized operand */
+const CONVERSION = /^([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?)\s*\(/;
+
+/** Tokens of a constant expression */
+const TOKEN =
+  /\s*(?:(`[^`]*`|"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.[^']*)')|(\.?\d(?:[eEpP][+-]|[\w.])*)|([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?)|(<<|>>|&\^|&&|\|\||==|!=|<=|>=|[-+*/%&|^<>!(),]))/y;
+
+/** Binary operators by precedence (Go spec: 5 binds tightest) */
+const PRECEDENCE: Record<string, number> = {
+  '*': 5,
+  '/': 5,
+  '%': 5,
+  '<<': 5,
+  '>>': 5,
+  '&': 5,
+  '&^': 5,
+  '+': 4,
+  '-': 4,
+  '|': 4,
+  '^': 4,
+  '==': 3,
+  '!=': 3,
+  '<': 3,
+  '<=': 3,
+  '>': 3,
+  '>=': 3,
+  '&&': 2,
+  '||': 1,
+};
+
+/** Constant value: integers and runes as bigint, floats as number */
+type Value = bigint | number | string | boolean;
+
+/** Expression that cannot be evaluated with what the file declares */
+class Unresolved extends Error {}
+
+/**
+ * Constant spec before evaluation
+ */
+interface Spec {
+  names: string[];
+  type: string | null;
+  expressions: string[];
+  iota: number;
+  groupLine: number;
+  offset: number;
+}
+
+/**
+ * Split text at top-level separators, skipping string and rune literals
+ *
+ * @param code - Go source, comments masked
+ * @param from - Offset to start at
+ * @param options.end - Stop at this character at depth 0 (a group's closing parenthesis)
+ * @param options.statement - Also end a part at a newline where Go inserts a semicolon;
+ *   without end, stop after the first part
+ * @param options.separator - Part separator at depth 0
+ * @returns Parts with their offsets, and the offset scanning stopped at
+ */
+const splitTopLevel = (
+  code: string,
+  from: number,
+  options: { end?: string; statement?: boolean; separator: string }
+): { parts: { text: string; offset: number }[]; stop: number } => {
+  const parts: { text: string; offset: number }[] = [];
+  let depth = 0;
+  let start = from;
+  const push = (to: number): void => {
+    const text = code.slice(start, to);
+    const lead = text.length - text.trimStart().length;
+    if (text.trim() !== '') parts.push({ text: text.trim(), offset: start + lead });
+    start = to + 1;
+  };
+
+  let i = from;
+  for (; i < code.length; i++) {
+    const char = code[i];
+    if (char === '"' || char === "'" || char === '`') {
+      let close = i + 1;
+      while (close < code.length && code[close] !== char && (char === '`' || code[close] !== '\n')) {
+        if (code[close] === '\\' && char !== '`') close++;
+        close++;
+      }
+      i = close;
+    } else if (char === '(' || char === '[' || char === '{') {
+      depth++;
+    } else if (depth > 0 && (char === ')' || char === ']' || char === '}')) {
+      depth--;
+    } else if (depth === 0 && char === options.end) {
+      break;
+    } else if (depth === 0 && char === options.separator) {
+      push(i);
+    } else if (depth === 0 && options.statement && char === '\n') {
+      // A line ending in an operator or comma continues on the next
+      if (/[\w"'`)\]}]\s*$/.test(code.slice(start, i))) push(i);
+      if (!options.end && parts.length > 0) return { parts, stop: i };
+    }
+  }
+  push(i);
+  return { parts, stop: i };
+};
+
+/**
+ * Read the specs of the file's top-level const declarations
+ *
+ * @param code - Go source, comments masked
+ * @param lineOf - Line of an offset
+ */
+const readSpecs = (code: string, lineOf: (offset: number) => number): Spec[] => {
+  const specs: Spec[] = [];
+  for (const declaration of code.matchAll(/^const\b\s*(\(?)/gm)) {
+    const grouped = declaration[1] === '(';
+    const from = declaration.index + declaration[0].length;
+    const { parts } = grouped
+      ? splitTopLevel(code, from, { end: ')', statement: true, separator: ';' })
+      : splitTopLevel(code, from, { statement: true, separator: ';' });
+
+    let previous: Pick<Spec, 'type' | 'expressions'> = { type: null, expressions: [] };
+    for (const [iota, part] of (grouped ? parts : parts.slice(0, 1)).entries()) {
+      const match = SPEC.exec(part.text);
+      if (!match) continue;
+      const [, names, type, values] = match;
+      if (values !== undefined) {
+        const expressions = splitTopLevel(values, 0, { separator: ',' }).parts.map((value) => value.text);
+        previous = { type: type === '' ? null : type, expressions };
+      }
+      specs.push({
+        names: names.split(',').map((name) => name.trim()),
+        ...previous,
+        iota,
+        groupLine: lineOf(declaration.index),
+        offset: part.offset,
+      });
+    }
+  }
+  return specs;
+};
+
+/**
+ * Parse a Go integer or float literal
+ */
+const parseNumber = (literal: string): Value => {
+  const digits = literal.replace(/_/g, '');
+  if (/^0[xX][\da-fA-F]+$|^0[bB][01]+$|^0[oO][0-7]+$|^\d+$/.test(digits)) {
+    return BigInt(/^0\d/.test(digits) ? `0o${digits.slice(1)}` : digits);
+  }
+  if (/^(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?$/.test(digits)) return Number(digits);
+  // Hex floats and imaginary literals
+  throw new Unresolved(literal);
+};
+
+/**
+ * Value of a rune literal: its code point
+ */
+const parseRune = (literal: string): bigint => {
+  const inner = literal.slice(1, -1);
+  const escapes: Record<string, string> = { a: '\x07', b: '\b', f: '\f', n: '\n', r: '\r', t: '\t', v: '\v' };
+  if (!inner.startsWith('\\')) return BigInt(inner.codePointAt(0) ?? 0);
+  const escape = inner[1];
+  if (escape in escapes) return BigInt(escapes[escape].charCodeAt(0));
+  if (/^[xuU]$/.test(escape)) return BigInt(`0x${inner.slice(2)}`);
+  if (/^[0-7]$/.test(escape)) return BigInt(`0o${inner.slice(1)}`);
+  return BigInt(escape.charCodeAt(0));
+};
+
+/**
+ * Value of a string literal
+ */
+const parseString = (literal: string): string => {
+  if (literal.startsWith('`')) return literal.slice(1, -1).replace(/\r/g, '');
+  // Go's escapes beyond JSON's (\a, \v, \x.., octal) are rare in constants
+  try {
+    return JSON.parse(literal) as string;
+  } catch {
+    throw new Unresolved(literal);
+  }
+};
+
+/**
+ * Apply a binary operator to constant values
+ */
+const applyBinary = (op: string, left: Value, right: Value): Value => {
+  if (op === '&&' || op === '||') {
+    if (typeof left !== 'boolean' || typeof right !== 'boolean') throw new Unresolved(op);
+    return op === '&&' ? left && right : left || right;
+  }
+  if (op === '==' || op === '!=') {
+    const equal = typeof left === typeof right ? left === right : Number(left) === Number(right);
+    return op === '==' ? equal : !equal;
+  }
+  if (op === '<' || op === '<=' || op === '>' || op === '>=') {
+    let sign: number;
+    if (typeof left === 'string' && typeof right === 'string') sign = left < right ? -1 : left > right ? 1 : 0;
+    else if (typeof left === 'bigint' && typeof right === 'bigint') sign = left < right ? -1 : left > right ? 1 : 0;
+    else if (typeof left !== 'bigint' && typeof left !== 'number') throw new Unresolved(op);
+    else if (typeof right !== 'bigint' && typeof right !== 'number') throw new Unresolved(op);
+    else sign = Math.sign(Number(left) - Number(right));
+    if (op === '<') return sign < 0;
+    if (op === '<=') return sign <= 0;
+    return op === '>' ? sign > 0 : sign >= 0;
+  }
+  if (typeof left === 'string' && typeof right === 'string' && op === '+') return left + right;
+  if (typeof left === 'bigint' && typeof right === 'bigint') {
+    switch (op) {
+      case '+':
+        return left + right;
+      case '-':
+        return left - right;
+      case '*':
+        return left * right;
+      case '/':
+      case '%':
+        if (right === 0n) throw new Unresolved('division by zero');
+        return op === '/' ? left / right : left % right;
+      case '<<':
+        // Untyped constants are exact; a wider shift is a mistake or a refusal to compile
+        if (right > 512n) throw new Unresolved('shift too wide');
+        return left << right;
+      case '>>':
+        return left >> right;
+      case '&':
+        return left & right;
+      case '|':
+        return left | right;
+      case '^':
+        return left ^ right;
+      case '&^':
+        return left & ~right;
+    }
+  }
+  if (typeof left !== 'bigint' && typeof left !== 'number') throw new Unresolved(op);
+  if (typeof right !== 'bigint' && typeof right !== 'number') throw new Unresolved(op);
+  const [a, b] = [Number(left), Number(right)];
+  if (op === '+') return a + b;
+  if (op === '-') return a - b;
+  if (op === '*') return a * b;
+  if (op === '/' && b !== 0) return a / b;
+  throw new Unresolved(op);
+};
+
+/**
+ * Evaluate a constant expression
+ *
+ * @param expression - Expression as written
+ * @param iota - Value of iota
+ * @param lookup - Value of a constant of the file, or undefined if it has none (yet)
+ * @throws {Unresolved} If the expression uses a name or construct that cannot be evaluated
+ */
+const evaluate = (expression: string, iota: number, lookup: (name: string) => Value | undefined): Value => {
+  const tokens: string[] = [];
+  TOKEN.lastIndex = 0;
+  while (TOKEN.lastIndex < expression.length) {
+    const start = TOKEN.lastIndex;
+    const match = TOKEN.exec(expression);
+    if (!match) {
+      if (expression.slice(start).trim() === '') break;
+      throw new Unresolved(expression);
+    }
+    tokens.push(match[0].trim());
+  }
+
+  let position = 0;
+  const next = (): string | undefined => tokens[position++];
+  const expect = (token: string): void => {
+    if (next() !== token) throw new Unresolved(expression);
+  };
+
+  const unary = (): Value => {
+    const token = next();
+    if (token === undefined) throw new Unresolved(expression);
+    if (token === '(') {
+      const value = binary(1);
+      expect(')');
+      return value;
+    }
+    if (token === '-' || token === '+' || token === '^' || token === '!') {
+      const operand = unary();
+      if (token === '!' && typeof operand === 'boolean') return !operand;
+      if (token === '^' && typeof operand === 'bigint') return ~operand;
+      if (token === '-' && typeof operand === 'bigint') return -operand;
+      if (token === '-' && typeof operand === 'number') return -operand;
+      if (token === '+' && typeof operand !== 'boolean' && typeof operand !== 'string') return operand;
+      throw new Unresolved(expression);
+    }
+    if (/^["`]/.test(token)) return parseString(token);
+    if (token.startsWith("'")) return parseRune(token);
+    if (/^\.?\d/.test(token)) return parseNumber(token);
+    if (!/^[A-Za-z_]/.test(token)) throw new Unresolved(expression);
+
+    if (tokens[position] === '(') {
+      position++;
+      const args: Value[] = [];
+      while (tokens[position] !== ')') {
+        args.push(binary(1));
+        if (tokens[position] === ',') position++;
+        else if (tokens[position] !== ')') throw new Unresolved(expression);
+      }
+      position++;
+      if (token === 'len' && args.length === 1 && typeof args[0] === 'string') {
+        return BigInt(Buffer.byteLength(args[0]));
+      }
+      if (BUILTINS.has(token) || args.length !== 1) throw new Unresolved(token);
+      // Conversion: the operand's value, as an integer for integer types
+      return /^u?int\d*$|^byte$|^rune$|^uintptr$/.test(token) && typeof args[0] === 'number'
+        ? BigInt(Math.trunc(args[0]))
+        : args[0];
+    }
+    if (token === 'iota') return BigInt(iota);
+    if (token === 'true' || token === 'false') return token === 'true';
+    const value = lookup(token);
+    if (value === undefined) throw new Unresolved(token);
+    return value;
+  };
+
+  const binary = (minPrecedence: number): Value => {
+    let left = unary();
+    for (;;) {
+      const op = tokens[position];
+      const precedence = op === undefined ? 0 : (PRECEDENCE[op] ?? 0);
+      if (precedence < minPrecedence || precedence === 0) return left;
+      position++;
+      left = applyBinary(op, left, binary(precedence + 1));
+    }
+  };
+
+  const value = binary(1);
+  if (position !== tokens.length) throw new Unresolved(expression);
+  return value;
+};
+
+/**
+ * Format a constant value as a Go literal
+ */
+const formatValue = (value: Value): string => {
+  if (typeof value === 'string') return JSON.stringify(value);
+  return String(value);
+};
+
+/**
+ * Type a constant is converted to at the top of its expression (UserRole(iota))
+ */
+const conversionType = (expression: string): string | null => {
+  const match = CONVERSION.exec(expression);
+  if (!match || BUILTINS.has(match[1])) return null;
+  const { parts, stop } = splitTopLevel(expression, match[0].length, { end: ')', separator: ',' });
+  return parts.length === 1 && stop === expression.length - 1 ? match[1] : null;
+};
+
+/**
+ * Extract the top-level constants of a Go file
+ *
+ * @param content - Go source
+ * @returns Constants in source order (blank names left out), values resolved within the file where possible
+ */
+export const extractGoConstants = (content: string): GoConstant[] => {
+  const code = maskGo(content, false);
+  const lineStarts = [0];
+  for (let i = 0; i < code.length; i++) if (code[i] === '\n') lineStarts.push(i + 1);
+  const lineOf = (offset: number): number => {
+    let low = 0;
+    let high = lineStarts.length - 1;
+    while (low < high) {
+      const mid = Math.ceil((low + high) / 2);
+      if (lineStarts[mid] <= offset) low = mid;
+      else high = mid - 1;
+    }
+    return low + 1;
+  };
+
+  const sourceLines = content.split('\n');
+  const maskedLines = code.split('\n');
+  const docOf = (line: number): string | null => {
+    const above: string[] = [];
+    for (let i = line - 2; i >= 0 && sourceLines[i].trim().startsWith('//'); i--) above.unshift(sourceLines[i]);
+    if (above.length > 0) return cleanDocComment(above.join('\n')) || null;
+    // A comment after the spec on its line (masked away in the code)
+    const source = sourceLines[line - 1];
+    const masked = maskedLines[line - 1];
+    let i = 0;
+    while (i < source.length && source[i] === masked[i]) i++;
+    return source.startsWith('//', i) ? cleanDocComment(source.slice(i)) || null : null;
+  };
+
+  const specs = readSpecs(code, lineOf);
+  const values = new Map<string, Value>();
+  const lookup = (name: string): Value | undefined => values.get(name);
+
+  // Constants may use constants declared after them: evaluate until nothing more resolves
+  const pending = specs.flatMap((spec) =>
+    spec.names.map((name, i) => ({ name, expression: spec.expressions[i] as string | undefined, spec }))
+  );
+  let unresolved = pending.filter((constant) => constant.expression !== undefined);
+  for (let progress = true; progress; ) {
+    progress = false;
+    unresolved = unresolved.filter((constant) => {
+      try {
+        const value = evaluate(constant.expression ?? '', constant.spec.iota, lookup);
+        if (constant.name !== '_') values.set(constant.name, value);
+        progress = true;
+        return false;
+      } catch (error) {
+        if (error instanceof Unresolved) return true;
+        throw error;
+      }
+    });
+  }
+
+  return pending
+    .filter((constant) => constant.name !== '_')
+    .map(({ name, expression = '', spec }) => {
+      const line = lineOf(spec.offset);
+      const value = values.get(name);
+      return {
+        name,
+        type: spec.type ?? conversionType(expression),
+        value: value === undefined ? null : formatValue(value),
+        expression,
+        iota: spec.iota,
+        group_line: spec.groupLine,
+        line,
+        doc: docOf(line),
+      };
+    });
+};
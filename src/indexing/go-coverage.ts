@@ -0,0 +1,123 @@
+/**
+ * Go coverage profiles: parse `go test -coverprofile` output and attribute it to functions
+ *
+ * A profile lists basic blocks as `file:startLine.startCol,endLine.endCol
+ * statements count`, with files named by import path
+ * (github.com/org/repo/pkg/file.go). Profile files are matched to indexed
+ * files by the longest path suffix, and a function's coverage is the share of
+ * statements in the blocks inside its line span that ran at least once, the
+ * same figure `go tool cover -func` reports.
+ */
+import { type FunctionSpanRecord } from '@/types/database';
+
+/**
+ * One block of a coverage profile
+ */
+export interface CoverBlock {
+  file: string;
+  start_line: number;
+  start_column: number;
+  end_line: number;
+  end_column: number;
+  statements: number;
+  /** Times the block ran (0 or 1 in set mode) */
+  count: number;
+}
+
+/**
+ * Parsed coverage profile
+ */
+export interface CoverProfile {
+  /** Profile mode: set, count, or atomic */
+  mode: string;
+  blocks: CoverBlock[];
+}
+
+/**
+ * Statement coverage of one function
+ */
+export interface FunctionCoverage {
+  symbol: FunctionSpanRecord;
+  statements: number;
+  covered: number;
+  /** Percent of statements covered (null if the profile has no blocks in the function) */
+  percent: number | null;
+}
+
+/** Block line: file:startLine.startCol,endLine.endCol statements count */
+const BLOCK_LINE = /^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$/;
+
+/**
+ * Parse a coverage profile
+ *
+ * Profiles merged from several test runs repeat blocks; a repeated block
+ * keeps its highest count.
+ *
+ * @param text - Content of a file written by `go test -coverprofile`
+ * @returns Mode and blocks in profile order
+ * @throws {Error} If a line is neither the mode line nor a block
+ */
+export const parseCoverProfile = (text: string): CoverProfile => {
+  let mode = 'set';
+  const blocks = new Map<string, CoverBlock>();
+
+  text.split(/\r?\n/).forEach((raw, index) => {
+    const line = raw.trim();
+    if (line === '') return;
+    if (line.startsWith('mode:')) {
+      mode = line.slice('mode:'.length).trim();
+      return;
+    }
+
+    const match = BLOCK_LINE.exec(line);
+    if (!match) {
+      throw new Error(`line ${String(index + 1)}: not a coverage block: ${line}`);
+    }
+    const [, file, startLine, startColumn, endLine, endColumn, statements, count] = match;
+    const block: CoverBlock = {
+      file,
+      start_line: parseInt(startLine, 10),
+      start_column: parseInt(startColumn, 10),
+      end_line: parseInt(endLine, 10),
+      end_column: parseInt(endColumn, 10),
+      statements: parseInt(statements, 10),
+      count: parseInt(count, 10),
+    };
+
+    const key = `${file}:${startLine}.${startColumn},${endLine}.${endColumn}`;
+    const previous = blocks.get(key);
+    if (!previous || previous.count < block.count) blocks.set(key, block);
+  });
+
+  return { mode, blocks: [...blocks.values()] };
+};
+
+/**
+ * Compute the statement coverage of functions
+ *
+ * A block belongs to the function whose line span contains it. Functions
+ * without an end line (indexed before spans were recorded) get no value.
+ *
+ * @param blocks - Profile blocks, with file set to the indexed file path
+ * @param functions - Function spans of the indexed files
+ * @returns Coverage per function, in input order
+ */
+export const computeFunctionCoverage = (blocks: CoverBlock[], functions: FunctionSpanRecord[]): FunctionCoverage[] => {
+  const byFile = new Map<string, CoverBlock[]>();
+  for (const block of blocks) {
+    byFile.set(block.file, [...(byFile.get(block.file) ?? []), block]);
+  }
+
+  return functions.map((symbol) => {
+    const { end_line } = symbol;
+    const inside =
+      end_line === null
+        ? []
+        : (byFile.get(symbol.file_path) ?? []).filter(
+            (block) => block.start_line >= symbol.line_number && block.end_line <= end_line
+          );
+    const statements = inside.reduce((sum, block) => sum + block.statements, 0);
+    const covered = inside.reduce((sum, block) => sum + (block.count > 0 ? block.statements : 0), 0);
+    return { symbol, statements, covered, percent: statements > 0 ? (covered / statements) * 100 : null };
+  });
+};
@@ -0,0 +1,160 @@
+/**
+ * Go embedding: types embedded in structs and interfaces (cindex hierarchy)
+ *
+ * Read from the source, without type checking, like the call graph (see
+ * @indexing/go-calls):
+ *
+ *   type AdminService struct {          AdminService embeds AuthService (by pointer)
+ *       *AuthService                    and sync.Mutex
+ *       sync.Mutex
+ *       audit Logger                    a named field, not an embedding
+ *   }
+ *   type RoleChecker interface {        RoleChecker embeds PermissionChecker
+ *       PermissionChecker
+ *       HasRole(role string) bool
+ *   }
+ *
+ * An embedded type is named without its type arguments (Base of Base[T]) and
+ * with the import path of its qualifier; one of the same package has none.
+ * Type set terms of constraint interfaces (~int | ~string, int) are not
+ * embeddings; error is.
+ */
+
+import { maskGo } from '@indexing/go-calls';
+import { PREDECLARED_TYPES } from '@indexing/go-generics';
+import { type GoEmbedding } from '@/types/indexing';
+
+/** Struct entry without a field name: optional pointer, qualified name, type arguments, tag (masked) */
+const EMBEDDED_FIELD = new RegExp(
+  [
+    /^(\*)?[ \t]*/.source,
+    /(?:([A-Za-z_]\w*)[ \t]*\.[ \t]*)?([A-Za-z_]\w*)/.source,
+    /[ \t]*(?:\[.*\])?[ \t]*(?:([`"])[ \t]*\4)?$/.source,
+  ].join('')
+);
+
+/** Interface element naming a type: qualified name, type arguments */
+const EMBEDDED_INTERFACE = /^(?:([A-Za-z_]\w*)[ \t]*\.[ \t]*)?([A-Za-z_]\w*)[ \t]*(?:\[.*\])?$/;
+
+/** Struct or interface type of a spec, after its name and type parameters */
+const TYPE_LITERAL = /^[ \t]*(struct|interface)[ \t]*\{/;
+
+/**
+ * Offset of the bracket closing the one at an offset
+ *
+ * @returns Offset of the closing bracket, or -1 if it is never closed
+ */
+const closing = (code: string, open: number): number => {
+  let depth = 0;
+  for (let i = open; i < code.length; i++) {
+    const char = code[i];
+    if (char === '(' || char === '[' || char === '{') depth++;
+    else if (char === ')' || char === ']' || char === '}') {
+      if (--depth === 0) return i;
+    }
+  }
+  return -1;
+};
+
+/**
+ * Offsets of the type specs of a file: after `type`, or at each top-level line of a type group
+ */
+const typeSpecOffsets = (code: string): number[] => {
+  const offsets: number[] = [];
+  for (const declaration of code.matchAll(/^type\b[ \t]*(\(?)/gm)) {
+    const start = declaration.index + declaration[0].length;
+    if (declaration[1] === '') {
+      offsets.push(start);
+      continue;
+    }
+    const close = closing(code, start - 1);
+    let depth = 0;
+    for (let i = start; i < (close === -1 ? code.length : close); i++) {
+      const char = code[i];
+      if (char === '(' || char === '[' || char === '{') depth++;
+      else if (char === ')' || char === ']' || char === '}') depth--;
+      else if (depth === 0 && code[i - 1] === '\n') offsets.push(i);
+    }
+  }
+  return offsets;
+};
+
+/**
+ * Split the body of a struct or interface into its entries (lines or ;-separated)
+ *
+ * @returns Entries, trimmed, with the offset of their first character
+ */
+const splitEntries = (code: string, start: number, end: number): { text: string; offset: number }[] => {
+  const entries: { text: string; offset: number }[] = [];
+  let depth = 0;
+  let from = start;
+  for (let i = start; i <= end; i++) {
+    const char = code[i];
+    if (i < end && (char === '(' || char === '[' || char === '{')) depth++;
+    else if (i < end && (char === ')' || char === ']' || char === '}')) depth--;
+    else if (i === end || (depth === 0 && (char === '\n' || char === ';'))) {
+      const raw = code.slice(from, i);
+      const text = raw.trim();
+      if (text !== '') entries.push({ text, offset: from + raw.length - raw.trimStart().length });
+      from = i + 1;
+    }
+  }
+  return entries;
+};
+
+/**
+ * Extract the types embedded in a Go file's structs and interfaces
+ *
+ * @param content - Go source
+ * @param imports - Import names of the file (see parseGoImports)
+ * @returns Embeddings in source order
+ */
+export const extractGoEmbeddings = (content: string, imports: ReadonlyMap<string, string>): GoEmbedding[] => {
+  const code = maskGo(content, true);
+  const lines = content.split('\n');
+  const lineStarts: number[] = [0];
+  for (const line of lines) lineStarts.push(lineStarts[lineStarts.length - 1] + line.length + 1);
+  const position = (offset: number): { line: number; column: number } => {
+    let index = 0;
+    while (lineStarts[index + 1] <= offset) index++;
+    const prefix = lines[index].slice(0, offset - lineStarts[index]);
+    return { line: index + 1, column: Buffer.byteLength(prefix, 'utf-8') + 1 };
+  };
+
+  const embeddings: GoEmbedding[] = [];
+  for (const offset of typeSpecOffsets(code)) {
+    const name = /^[ \t]*([A-Za-z_]\w*)[ \t]*/.exec(code.slice(offset, offset + 256));
+    if (!name) continue;
+    let after = offset + name[0].length;
+    if (code[after] === '[') {
+      const close = closing(code, after);
+      if (close === -1) continue;
+      after = close + 1;
+    }
+    const literal = TYPE_LITERAL.exec(code.slice(after, after + 64));
+    if (!literal) continue;
+    const kind = literal[1] as GoEmbedding['type_kind'];
+    const open = after + literal[0].length - 1;
+    const close = closing(code, open);
+    if (close === -1) continue;
+
+    for (const entry of splitEntries(code, open + 1, close)) {
+      const match = (kind === 'struct' ? EMBEDDED_FIELD : EMBEDDED_INTERFACE).exec(entry.text);
+      if (!match) continue;
+      const [pointer, qualifier, embedded] = kind === 'struct' ? match.slice(1, 4) : [undefined, ...match.slice(1, 3)];
+      // Predeclared types other than error are type set terms (or an odd embedded int)
+      if (qualifier === undefined && PREDECLARED_TYPES.has(embedded) && embedded !== 'error') continue;
+
+      const qualifierEnd = qualifier === undefined ? 0 : entry.text.indexOf('.') + 1;
+      embeddings.push({
+        type_name: name[1],
+        type_kind: kind,
+        embedded_name: embedded,
+        embedded_package: qualifier === undefined ? null : (imports.get(qualifier) ?? qualifier),
+        pointer: pointer !== undefined,
+        ...position(entry.offset + entry.text.indexOf(embedded, qualifierEnd)),
+      });
+    }
+  }
+  return embeddings;
+};
@@ -0,0 +1,264 @@
+/**
+ * Go generics: type parameter lists and explicit instantiations
+ *
+ * Read from the source, without type checking, like the call graph (see
+ * @indexing/go-calls):
+ *
+ *   func Map[S ~[]E, E, R any](s S, f func(E) R) []R    S ~[]E, E any, R any
+ *   type Cache[K comparable, V any] struct{ ... }         K comparable, V any
+ *   Map[[]int, int, string](xs, strconv.Itoa)             instantiation of Map
+ *   cache := NewCache[string, *User]()                    instantiation of NewCache
+ *
+ * Instantiations are taken where the type arguments are written out. An
+ * index expression looks the same (users[ID]), so a bracket counts only if
+ * every argument reads as a type; lowercase names other than predeclared
+ * types are read as index operands, and names are resolved to generics when
+ * queried. Inferred instantiations (Map(xs, f)) are recorded as calls.
+ */
+
+import { bodyStart, maskGo } from '@indexing/go-calls';
+import { type GoInstantiation, type GoTypeParameter } from '@/types/indexing';
+
+/** Predeclared types and constraints (lowercase names that are types) */
+export const PREDECLARED_TYPES = new Set([
+  'any',
+  'bool',
+  'byte',
+  'comparable',
+  'complex64',
+  'complex128',
+  'error',
+  'float32',
+  'float64',
+  'int',
+  'int8',
+  'int16',
+  'int32',
+  'int64',
+  'rune',
+  'string',
+  'uint',
+  'uint8',
+  'uint16',
+  'uint32',
+  'uint64',
+  'uintptr',
+]);
+
+/** Keywords that can precede a bracket */
+const KEYWORDS = new Set(['map', 'chan', 'func', 'interface', 'struct', 'range', 'return', 'case', 'go', 'defer']);
+
+/** Function declaration header, optional receiver (type name captured), and the declared name */
+const FUNC_HEADER = /^func\s*(?:\(\s*(?:[A-Za-z_]\w*\s+)?\*?\s*([A-Za-z_]\w*)[^)]*\)\s*)?([A-Za-z_]\w*)/gm;
+
+/** Possibly qualified name followed by an opening bracket */
+const INDEXED = /(?:([A-Za-z_]\w*)\s*\.\s*)?([A-Za-z_]\w*)\s*\[/g;
+
+/**
+ * Offset of the bracket closing the one at an offset
+ *
+ * @returns Offset of the closing bracket, or -1 if it is never closed
+ */
+const closing = (code: string, open: number): number => {
+  let depth = 0;
+  for (let i = open; i < code.length; i++) {
+    const char = code[i];
+    if (char === '(' || char === '[' || char === '{') depth++;
+    else if (char === ')' || char === ']' || char === '}') {
+      if (--depth === 0) return i;
+    }
+  }
+  return -1;
+};
+
+/**
+ * Split a list at top-level commas
+ *
+ * @returns Parts, trimmed, whitespace collapsed
+ */
+const splitList = (text: string): string[] => {
+  const parts: string[] = [];
+  let depth = 0;
+  let start = 0;
+  for (let i = 0; i <= text.length; i++) {
+    const char = text[i];
+    if (char === '(' || char === '[' || char === '{') depth++;
+    else if (char === ')' || char === ']' || char === '}') depth--;
+    else if (i === text.length || (depth === 0 && char === ',')) {
+      parts.push(text.slice(start, i).replace(/\s+/g, ' ').trim());
+      start = i + 1;
+    }
+  }
+  return parts;
+};
+
+/**
+ * Parse a type parameter list (the text between its brackets)
+ *
+ * Names without a constraint share the next one (K, V any). A list whose last
+ * entry has no constraint is an array length instead (type Buffer [Size]byte).
+ *
+ * @returns Parameters in order, or null if the text is not a type parameter list
+ */
+const parseTypeParameters = (text: string): { name: string; constraint: string }[] | null => {
+  const entries = splitList(text).map((part) => /^([A-Za-z_]\w*)(?: (.+))?$/.exec(part));
+  if (entries.length === 0 || entries.some((entry) => entry === null)) return null;
+
+  const parameters: { name: string; constraint: string }[] = [];
+  let constraint: string | undefined;
+  for (const entry of [...entries].reverse()) {
+    const [, name, own] = entry as RegExpExecArray;
+    constraint = own ?? constraint;
+    // An array length of a product (type Grid [N * 2]int)
+    if (constraint === undefined || /^[-+*/%] ?\d/.test(constraint)) return null;
+    parameters.unshift({ name, constraint });
+  }
+  return parameters;
+};
+
+/**
+ * Check whether an argument in brackets reads as a type
+ *
+ * @param text - Argument, whitespace collapsed
+ * @param imports - Import names of the file (qualified names must use one)
+ */
+const isTypeExpression = (text: string, imports: ReadonlyMap<string, string>): boolean => {
+  const element = /^(?:\*|\[\]|\[\d+\]) ?/.exec(text);
+  if (element) return isTypeExpression(text.slice(element[0].length), imports);
+  if (/^(?:map ?\[|chan\b|<-chan\b|func ?\(|struct ?\{|interface ?\{)/.test(text)) return true;
+
+  const named = /^(?:([A-Za-z_]\w*)\.)?([A-Za-z_]\w*)(?: ?\[(.*)\])?$/.exec(text);
+  if (!named) return false;
+  const [, qualifier, name, args] = named;
+  if (qualifier !== undefined && !imports.has(qualifier)) return false;
+  if (qualifier === undefined && !/^[A-Z]/.test(name) && !PREDECLARED_TYPES.has(name)) return false;
+  return args === undefined || splitList(args).every((arg) => isTypeExpression(arg, imports));
+};
+
+/**
+ * Find the type parameter lists of a file's top-level declarations
+ *
+ * @param code - Go source, comments and literals masked
+ * @returns Lists with the offset of their opening bracket
+ */
+const readTypeParameterLists = (code: string): { open: number; parameters: GoTypeParameter[] }[] => {
+  const lineOf = (offset: number): number => code.slice(0, offset).split('\n').length;
+  const lists: { open: number; parameters: GoTypeParameter[] }[] = [];
+  const add = (symbol: string, kind: GoTypeParameter['symbol_kind'], open: number, line: number): void => {
+    const close = closing(code, open);
+    const parameters = close === -1 ? null : parseTypeParameters(code.slice(open + 1, close));
+    if (!parameters) return;
+    lists.push({
+      open,
+      parameters: parameters.map((parameter, position) => ({
+        symbol,
+        symbol_kind: kind,
+        ...parameter,
+        position,
+        line,
+      })),
+    });
+  };
+
+  for (const header of code.matchAll(/^func[ \t]+([A-Za-z_]\w*)[ \t]*\[/gm)) {
+    add(header[1], 'function', header.index + header[0].length - 1, lineOf(header.index));
+  }
+
+  for (const declaration of code.matchAll(/^type\b[ \t]*(\(?)/gm)) {
+    if (declaration[1] === '') {
+      const spec = /^type[ \t]+([A-Za-z_]\w*)[ \t]*\[/.exec(code.slice(declaration.index));
+      if (spec) add(spec[1], 'type', declaration.index + spec[0].length - 1, lineOf(declaration.index));
+      continue;
+    }
+    // Grouped specs: one per line at the group's top level
+    const open = declaration.index + declaration[0].length - 1;
+    const close = closing(code, open);
+    let depth = 0;
+    for (let i = open + 1; i < (close === -1 ? code.length : close); i++) {
+      const char = code[i];
+      if (char === '(' || char === '[' || char === '{') depth++;
+      else if (char === ')' || char === ']' || char === '}') depth--;
+      else if (depth === 0 && code[i - 1] === '\n') {
+        const spec = /^[ \t]*([A-Za-z_]\w*)[ \t]*\[/.exec(code.slice(i));
+        if (spec) add(spec[1], 'type', i + spec[0].length - 1, lineOf(i));
+      }
+    }
+  }
+
+  return lists.sort((a, b) => a.open - b.open);
+};
+
+/**
+ * Extract the type parameters of a Go file's generic functions and types
+ *
+ * Methods declare none of their own: a receiver such as (c *Cache[K, V])
+ * names the parameters of its type.
+ *
+ * @param content - Go source
+ * @returns Type parameters in source order
+ */
+export const extractGoTypeParameters = (content: string): GoTypeParameter[] => {
+  return readTypeParameterLists(maskGo(content, true)).flatMap((list) => list.parameters);
+};
+
+/**
+ * Extract the explicit instantiations of generic functions and types in a Go file
+ *
+ * @param content - Go source
+ * @param imports - Import names of the file (see parseGoImports)
+ * @returns Candidate instantiations in source order; names that are not generics are dropped when queried
+ */
+export const extractGoInstantiations = (
+  content: string,
+  imports: ReadonlyMap<string, string>
+): GoInstantiation[] => {
+  const code = maskGo(content, true);
+  const declared = new Set(readTypeParameterLists(code).map((list) => list.open));
+
+  // Function bodies (for context) and receivers (whose brackets name type parameters, not arguments)
+  const bodies: { name: string; start: number; end: number }[] = [];
+  const receivers: { start: number; end: number }[] = [];
+  for (const header of code.matchAll(FUNC_HEADER)) {
+    const [, receiverType, name] = header;
+    if (receiverType) receivers.push({ start: header.index, end: code.indexOf(')', header.index) });
+    const start = bodyStart(code, header.index + header[0].length);
+    const end = start === -1 ? -1 : closing(code, start);
+    if (end !== -1) bodies.push({ name: receiverType ? `${receiverType}.${name}` : name, start, end });
+  }
+
+  const lines = content.split('\n');
+  const lineStarts: number[] = [0];
+  for (const line of lines) lineStarts.push(lineStarts[lineStarts.length - 1] + line.length + 1);
+  const position = (offset: number): { line: number; column: number } => {
+    let index = 0;
+    while (lineStarts[index + 1] <= offset) index++;
+    const prefix = lines[index].slice(0, offset - lineStarts[index]);
+    return { line: index + 1, column: Buffer.byteLength(prefix, 'utf-8') + 1 };
+  };
+
+  const instantiations: GoInstantiation[] = [];
+  for (const match of code.matchAll(INDEXED)) {
+    const [text, qualifier, target] = match;
+    const open = match.index + text.length - 1;
+    if (declared.has(open) || KEYWORDS.has(target)) continue;
+    // A field of a value (s.items[i], rows[0].cells[j]) or an array type's name (type Buffer [Size]byte)
+    if (/(?:\.|\btype)\s*$/.test(code.slice(Math.max(0, match.index - 16), match.index))) continue;
+    if (qualifier !== undefined && !imports.has(qualifier)) continue;
+    if (receivers.some((receiver) => open > receiver.start && open < receiver.end)) continue;
+
+    const close = closing(code, open);
+    if (close === -1) continue;
+    const args = splitList(code.slice(open + 1, close));
+    if (args.some((arg) => arg === '' || !isTypeExpression(arg, imports))) continue;
+
+    const enclosing = bodies.find((body) => open > body.start && open < body.end);
+    instantiations.push({
+      target,
+      target_package: qualifier === undefined ? null : (imports.get(qualifier) ?? null),
+      type_arguments: args,
+      context: enclosing?.name ?? null,
+      ...position(match.index + text.lastIndexOf(target, text.length - 1)),
+    });
+  }
+  return instantiations;
+};
@@ -0,0 +1,348 @@
+/**
+ * Source of the Go helper behind `cindex index --typed`
+ *
+ * Kept here, rather than as a .go file, so the bundled CLI can write it out
+ * and build it wherever it runs. See go-typed.ts for the facts it writes.
+ */
+
+/** go.mod of the helper; golang.org/x/tools is added at build time */
+export const GO_LOADER_MODULE = 'module cindex.local/go-loader\n\ngo 1.22\n';
+
+/** main.go of the helper */
+export const GO_LOADER_SOURCE = `// Command cindex-go-loader writes type facts of Go packages for cindex index --typed.
+//
+// It loads packages with go/packages (full type information, tests included)
+// and writes one JSON object per line:
+//
+//	method      a method declaration with its receiver type and signature
+//	implements  a named type of the loaded packages that satisfies an interface
+//	            of the loaded packages, their imports, or error
+//	reference   a use of a package-level object, method, or struct field of the
+//	            loaded packages, and whether it reads, writes, or calls it
+//
+// Package errors are printed to stderr; facts from what type-checked are still
+// written.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type fact struct {
+	Kind          string \`json:"kind"\`
+	File          string \`json:"file"\`
+	Line          int    \`json:"line"\`
+	Column        int    \`json:"column,omitempty"\`
+	EndLine       int    \`json:"end_line,omitempty"\`
+	Name          string \`json:"name"\`
+	Package       string \`json:"package"\`
+	Receiver      string \`json:"receiver,omitempty"\`
+	Pointer       bool   \`json:"pointer,omitempty"\`
+	Signature     string \`json:"signature,omitempty"\`
+	Target        string \`json:"target,omitempty"\`
+	TargetPackage string \`json:"target_package,omitempty"\`
+	TargetFile    string \`json:"target_file,omitempty"\`
+	TargetLine    int    \`json:"target_line,omitempty"\`
+	Access        string \`json:"access,omitempty"\`
+}
+
+// writer writes each fact once (test variants of a package repeat its files)
+type writer struct {
+	out  *json.Encoder
+	seen map[fact]bool
+}
+
+func (w *writer) emit(f fact) {
+	if w.seen[f] {
+		return
+	}
+	w.seen[f] = true
+	if err := w.out.Encode(f); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// receiverName returns the receiver type name of a method and whether the receiver is a pointer
+func receiverName(sig *types.Signature) (string, bool) {
+	t := sig.Recv().Type()
+	ptr, pointer := t.(*types.Pointer)
+	if pointer {
+		t = ptr.Elem()
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		return named.Origin().Obj().Name(), pointer
+	}
+	return "", pointer
+}
+
+// referenceName names a used object of the loaded packages: package-level
+// objects by name, methods as Receiver.Method, and fields of named struct
+// types as Type.Field. Locals, other fields, labels, and package names are
+// not recorded.
+func referenceName(obj types.Object, local map[string]bool, fields map[*types.Var]string) (string, bool) {
+	if obj.Pkg() == nil || !local[obj.Pkg().Path()] {
+		return "", false
+	}
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+			recv, _ := receiverName(sig)
+			return recv + "." + obj.Name(), recv != ""
+		}
+	case *types.Var:
+		if obj.IsField() {
+			name, ok := fields[obj.Origin()]
+			return name, ok
+		}
+	case *types.PkgName, *types.Label:
+		return "", false
+	}
+	return obj.Name(), obj.Parent() == obj.Pkg().Scope()
+}
+
+// accesses classifies the identifiers of a file that write or call what they
+// use; every other use reads. Assignment targets, operands of ++, --, and &,
+// and field keys of composite literals write: a.b.c = 1 writes c and reads a
+// and b, and x[i] = v writes x. Conversions read the type.
+func accesses(file *ast.File, info *types.Info) map[*ast.Ident]string {
+	kinds := map[*ast.Ident]string{}
+	var mark func(e ast.Expr, kind string)
+	mark = func(e ast.Expr, kind string) {
+		switch e := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			if _, conversion := info.Uses[e].(*types.TypeName); !conversion || kind != "call" {
+				kinds[e] = kind
+			}
+		case *ast.SelectorExpr:
+			mark(e.Sel, kind)
+		case *ast.IndexExpr:
+			// F[int](x) calls F; m[k]() calls an element of m, which reads m
+			if tv, ok := info.Types[e.Index]; kind != "call" || (ok && tv.IsType()) {
+				mark(e.X, kind)
+			}
+		case *ast.IndexListExpr:
+			mark(e.X, kind)
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			mark(n.Fun, "call")
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				mark(lhs, "write")
+			}
+		case *ast.IncDecStmt:
+			mark(n.X, "write")
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if e != nil {
+						mark(e, "write")
+					}
+				}
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				mark(n.X, "write")
+			}
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					if field, ok := info.Uses[key].(*types.Var); ok && field.IsField() {
+						kinds[key] = "write"
+					}
+				}
+			}
+		}
+		return true
+	})
+	return kinds
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory to load packages from")
+	tests := flag.Bool("tests", true, "load test files")
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax |
+			packages.NeedTypesInfo,
+		Dir:   *dir,
+		Fset:  fset,
+		Tests: *tests,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	packages.PrintErrors(pkgs)
+
+	// The generated main packages of test binaries are not the module's code
+	loaded := pkgs[:0]
+	for _, pkg := range pkgs {
+		if pkg.Types != nil && !strings.HasSuffix(pkg.PkgPath, ".test") {
+			loaded = append(loaded, pkg)
+		}
+	}
+	pkgs = loaded
+
+	buffered := bufio.NewWriter(os.Stdout)
+	defer buffered.Flush()
+	w := &writer{out: json.NewEncoder(buffered), seen: map[fact]bool{}}
+
+	local := map[string]bool{}
+	for _, pkg := range pkgs {
+		local[pkg.Types.Path()] = true
+	}
+
+	// Named types of the loaded packages, and the interfaces they may satisfy
+	var named []*types.TypeName
+	errorType := types.Universe.Lookup("error").(*types.TypeName)
+	interfaces := map[*types.TypeName]*types.Interface{errorType: errorType.Type().Underlying().(*types.Interface)}
+	collect := func(scope *types.Scope, imported bool) {
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || (imported && !tn.Exported()) {
+				continue
+			}
+			if n, ok := tn.Type().(*types.Named); ok && n.TypeParams().Len() > 0 {
+				continue
+			}
+			if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+				// Empty interfaces hold every type; constraint interfaces are not method sets
+				if iface.IsMethodSet() && iface.NumMethods() > 0 {
+					interfaces[tn] = iface
+				}
+			} else if !imported {
+				named = append(named, tn)
+			}
+		}
+	}
+	for _, pkg := range pkgs {
+		collect(pkg.Types.Scope(), false)
+		for _, imp := range pkg.Types.Imports() {
+			collect(imp.Scope(), true)
+		}
+	}
+
+	// Fields of the named struct types, by the name references record them under
+	fields := map[*types.Var]string{}
+	for _, tn := range named {
+		if st, ok := tn.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < st.NumFields(); i++ {
+				fields[st.Field(i)] = tn.Name() + "." + st.Field(i).Name()
+			}
+		}
+	}
+
+	for _, tn := range named {
+		at := fset.Position(tn.Pos())
+		for iface, methods := range interfaces {
+			pointer := false
+			if !types.Implements(tn.Type(), methods) {
+				if !types.Implements(types.NewPointer(tn.Type()), methods) {
+					continue
+				}
+				pointer = true
+			}
+			ifacePackage := ""
+			if iface.Pkg() != nil {
+				ifacePackage = iface.Pkg().Path()
+			}
+			w.emit(fact{
+				Kind:          "implements",
+				File:          at.Filename,
+				Line:          at.Line,
+				Name:          tn.Name(),
+				Package:       tn.Pkg().Path(),
+				Pointer:       pointer,
+				Target:        iface.Name(),
+				TargetPackage: ifacePackage,
+			})
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		qualifier := types.RelativeTo(pkg.Types)
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil {
+					continue
+				}
+				obj, ok := pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				sig := obj.Type().(*types.Signature)
+				recv, pointer := receiverName(sig)
+				start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+				w.emit(fact{
+					Kind:      "method",
+					File:      start.Filename,
+					Line:      start.Line,
+					EndLine:   end.Line,
+					Name:      fn.Name.Name,
+					Package:   pkg.Types.Path(),
+					Receiver:  recv,
+					Pointer:   pointer,
+					Signature: types.ObjectString(obj, qualifier),
+				})
+			}
+		}
+		kinds := map[*ast.Ident]string{}
+		for _, file := range pkg.Syntax {
+			for ident, kind := range accesses(file, pkg.TypesInfo) {
+				kinds[ident] = kind
+			}
+		}
+		for ident, obj := range pkg.TypesInfo.Uses {
+			name, ok := referenceName(obj, local, fields)
+			if !ok {
+				continue
+			}
+			access := kinds[ident]
+			if access == "" {
+				access = "read"
+			}
+			at, target := fset.Position(ident.Pos()), fset.Position(obj.Pos())
+			w.emit(fact{
+				Kind:       "reference",
+				File:       at.Filename,
+				Line:       at.Line,
+				Column:     at.Column,
+				Name:       name,
+				Package:    obj.Pkg().Path(),
+				TargetFile: target.Filename,
+				TargetLine: target.Line,
+				Access:     access,
+			})
+		}
+	}
+}
+`;
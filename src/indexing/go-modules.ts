@@ -0,0 +1,219 @@
+/**
+ * Go module dependencies indexed from the module cache (cindex deps)
+ *
+ * `go list -m -json all` names the modules a repository builds with and the
+ * directory of each under GOMODCACHE. A module version is immutable there,
+ * so it is indexed once as its own index, named module@version, and shared
+ * by every repository that requires it; a repository is only linked to the
+ * indexes of its modules. The cache is read-only: files are walked from
+ * GOMODCACHE and keep their module@version/ prefix, so paths never collide
+ * between versions.
+ *
+ * Modules replaced by a local directory are not in the cache (index them
+ * with cindex index), and modules missing from it need `go mod download`.
+ */
+
+import { execFile } from 'node:child_process';
+import * as path from 'node:path';
+import { promisify } from 'node:util';
+
+import { toPosixPath } from '@utils/paths';
+
+const execFileAsync = promisify(execFile);
+
+/** Maximum `go list` output buffered */
+const GO_LIST_MAX_BUFFER = 256 * 1024 * 1024;
+
+/**
+ * Module of a repository's build list
+ */
+export interface GoModule {
+  /** Module path as required (golang.org/x/text) */
+  path: string;
+  /** Version built with, after replacements (empty for the main module and local replacements) */
+  version: string;
+  /** Module path of the code built with (differs when replaced by another module) */
+  source_path: string;
+  /** Directory of the code (null when not downloaded) */
+  dir: string | null;
+  /** The repository's own module */
+  main: boolean;
+  /** Required only by other modules */
+  indirect: boolean;
+  /** Replaced by a directory on disk */
+  local: boolean;
+}
+
+/**
+ * Object of `go list -m -json` output
+ */
+interface ListedModule {
+  Path?: string;
+  Version?: string;
+  Dir?: string;
+  Main?: boolean;
+  Indirect?: boolean;
+  Replace?: { Path?: string; Version?: string; Dir?: string };
+}
+
+/**
+ * Split concatenated JSON objects (`go list -json` prints one after another)
+ */
+const splitObjects = (text: string): string[] => {
+  const objects: string[] = [];
+  let depth = 0;
+  let start = 0;
+  let inString = false;
+  for (let i = 0; i < text.length; i++) {
+    const char = text[i];
+    if (inString) {
+      if (char === '\\') i++;
+      else if (char === '"') inString = false;
+    } else if (char === '"') {
+      inString = true;
+    } else if (char === '{') {
+      if (depth === 0) start = i;
+      depth++;
+    } else if (char === '}') {
+      depth--;
+      if (depth === 0) objects.push(text.slice(start, i + 1));
+    }
+  }
+  return objects;
+};
+
+/**
+ * Parse `go list -m -json all` output
+ *
+ * @param text - JSON objects, one per module
+ * @returns Modules in build-list order
+ * @throws {Error} If an object is not JSON
+ */
+export const parseModuleList = (text: string): GoModule[] =>
+  splitObjects(text).map((raw, index) => {
+    let listed: ListedModule;
+    try {
+      listed = JSON.parse(raw) as ListedModule;
+    } catch {
+      throw new Error(`module ${String(index + 1)}: not JSON`);
+    }
+    const { Replace: replace } = listed;
+    const local = replace !== undefined && !replace.Version;
+    return {
+      path: listed.Path ?? '',
+      version: (replace ? replace.Version : listed.Version) ?? '',
+      source_path: replace && !local ? (replace.Path ?? '') : (listed.Path ?? ''),
+      dir: (replace ? replace.Dir : listed.Dir) ?? null,
+      main: listed.Main ?? false,
+      indirect: listed.Indirect ?? false,
+      local,
+    };
+  });
+
+/**
+ * Check whether a module path matches a pattern
+ *
+ * Patterns follow the go command: `...` matches any string, and a trailing
+ * `/...` also matches the path before it (golang.org/x/... matches
+ * golang.org/x/text). `all` matches every module.
+ */
+export const matchesModulePattern = (pattern: string, modulePath: string): boolean => {
+  if (pattern === 'all') return true;
+  if (pattern.endsWith('/...') && modulePath === pattern.slice(0, -4)) return true;
+  const source = pattern
+    .split('...')
+    .map((part) => part.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'))
+    .join('.*');
+  return new RegExp(`^${source}$`).test(modulePath);
+};
+
+/**
+ * Name of the shared index of a module version
+ */
+export const moduleIndexId = (module: GoModule): string => `${module.source_path}@${module.version}`;
+
+/**
+ * Why a dependency cannot be indexed from the module cache (null: it can)
+ */
+export const moduleUnavailable = (module: GoModule): 'local' | 'not-downloaded' | null => {
+  if (module.local) return 'local';
+  return module.dir === null || module.version === '' ? 'not-downloaded' : null;
+};
+
+/**
+ * Select the dependencies matching any pattern
+ *
+ * @param modules - Build list (the main module is never selected)
+ * @param patterns - Module patterns (see matchesModulePattern)
+ * @param direct - Only modules the go.mod requires directly
+ * @returns Matching modules, and the patterns no dependency matched
+ */
+export const selectModules = (
+  modules: GoModule[],
+  patterns: string[],
+  direct = false
+): { selected: GoModule[]; unmatched: string[] } => {
+  const dependencies = modules.filter((module) => !module.main && !(direct && module.indirect));
+  const matches = (pattern: string): GoModule[] =>
+    dependencies.filter((module) => matchesModulePattern(pattern, module.path));
+  return {
+    selected: dependencies.filter((module) => patterns.some((pattern) => matchesModulePattern(pattern, module.path))),
+    unmatched: patterns.filter((pattern) => matches(pattern).length === 0),
+  };
+};
+
+/**
+ * Directory of a module below the module cache, as walked (null when the module is outside it)
+ */
+export const moduleCacheSubdirectory = (module: GoModule, moduleCache: string): string | null => {
+  if (!module.dir) return null;
+  const relative = path.relative(moduleCache, module.dir);
+  if (relative === '' || relative.startsWith('..') || path.isAbsolute(relative)) return null;
+  return toPosixPath(relative);
+};
+
+/**
+ * Standard error of a failed command, or its message
+ */
+const commandError = (error: unknown): string => {
+  const stderr = (error as { stderr?: unknown }).stderr;
+  if (typeof stderr === 'string' && stderr.trim() !== '') return stderr.trim();
+  return error instanceof Error ? error.message : String(error);
+};
+
+/**
+ * List the build list of a Go module
+ *
+ * Modules that cannot be resolved are listed without a directory (-e).
+ *
+ * @param repoPath - Directory of the go.mod
+ * @returns Modules, the main module first
+ * @throws {Error} If go is not installed or the directory is not a module
+ */
+export const listGoModules = async (repoPath: string): Promise<GoModule[]> => {
+  try {
+    const { stdout } = await execFileAsync('go', ['list', '-m', '-e', '-json', 'all'], {
+      cwd: repoPath,
+      maxBuffer: GO_LIST_MAX_BUFFER,
+    });
+    return parseModuleList(stdout);
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+      throw new Error('go is not on PATH; dependencies are listed with the Go toolchain');
+    }
+    throw new Error(`cannot list the modules of ${repoPath}: ${commandError(error)}`);
+  }
+};
+
+/**
+ * Locate the module cache (go env GOMODCACHE)
+ *
+ * @throws {Error} If go is not installed
+ */
+export const goModuleCache = async (): Promise<string> => {
+  try {
+    return (await execFileAsync('go', ['env', 'GOMODCACHE'])).stdout.trim();
+  } catch {
+    throw new Error('go is not on PATH; dependencies are listed with the Go toolchain');
+  }
+};
@@ -0,0 +1,139 @@
+/**
+ * Go test inventory: tests, benchmarks, fuzz targets, examples, and subtests
+ *
+ * `go test` runs the functions of _test.go files named Test*, Benchmark*,
+ * Fuzz*, and Example*; the prefix must be the whole name or be followed by a
+ * character that is not a lowercase letter (TestLogin, not Testify). Subtests
+ * are `t.Run("name", ...)` calls with a literal name, reported the way
+ * `go test -run` matches them: TestLogin/valid_password, with spaces replaced
+ * by underscores. Subtests named at run time (table tests with tc.name) are
+ * not listed.
+ */
+
+/**
+ * Kind of test function
+ */
+export type GoTestKind = 'test' | 'benchmark' | 'fuzz' | 'example';
+
+/**
+ * Subtest found in a test function body
+ */
+export interface GoSubtest {
+  /** Full name: parent name and subtest names joined with '/' */
+  name: string;
+  line: number;
+  end_line: number;
+  /** Source line of the Run call */
+  definition: string;
+}
+
+/** Function name prefixes go test runs, with their kind */
+const TEST_PREFIXES: [string, GoTestKind][] = [
+  ['Test', 'test'],
+  ['Benchmark', 'benchmark'],
+  ['Fuzz', 'fuzz'],
+  ['Example', 'example'],
+];
+
+/** Run call with a literal subtest name (interpreted or raw string) */
+const RUN_CALL = /\b\w+\.Run\(\s*(?:"((?:[^"\\\n]|\\.)*)"|`([^`]*)`)/g;
+
+/**
+ * Classify a Go function as a test function
+ *
+ * @param name - Function name (methods are never test functions)
+ * @param filePath - File the function is declared in
+ * @returns Test kind, or null if go test does not run the function
+ */
+export const classifyGoTest = (name: string, filePath: string): GoTestKind | null => {
+  if (!filePath.endsWith('_test.go')) return null;
+  for (const [prefix, kind] of TEST_PREFIXES) {
+    if (!name.startsWith(prefix)) continue;
+    return /^\p{Ll}/u.test(name.slice(prefix.length)) ? null : kind;
+  }
+  return null;
+};
+
+/**
+ * Name of a subtest as go test reports it
+ */
+const rewriteSubtestName = (literal: string, raw: boolean): string => {
+  let name = literal;
+  if (!raw) {
+    try {
+      name = JSON.parse(`"${literal}"`) as string;
+    } catch {
+      // Go escapes JSON lacks (\x41, \u{...}): keep the literal as written
+    }
+  }
+  return name.replace(/\s/g, '_');
+};
+
+/**
+ * Find the parenthesis closing the one at an offset, skipping strings and comments
+ *
+ * @returns Offset of the closing parenthesis, or the end of the code if unbalanced
+ */
+const closingParen = (code: string, open: number): number => {
+  let depth = 0;
+  for (let i = open; i < code.length; i++) {
+    const char = code[i];
+    if (char === '"' || char === "'") {
+      for (i++; i < code.length && code[i] !== char && code[i] !== '\n'; i++) {
+        if (code[i] === '\\') i++;
+      }
+    } else if (char === '`') {
+      i = code.indexOf('`', i + 1);
+      if (i === -1) return code.length;
+    } else if (char === '/' && code[i + 1] === '/') {
+      i = code.indexOf('\n', i);
+      if (i === -1) return code.length;
+    } else if (char === '/' && code[i + 1] === '*') {
+      i = code.indexOf('*/', i + 2);
+      if (i === -1) return code.length;
+      i++;
+    } else if (char === '(') {
+      depth++;
+    } else if (char === ')' && --depth === 0) {
+      return i;
+    }
+  }
+  return code.length;
+};
+
+/**
+ * Find the subtests of a test function
+ *
+ * Nested Run calls are named under the Run call that holds them.
+ *
+ * @param testName - Test function name
+ * @param code - Source of the test function
+ * @param startLine - Line the function starts on
+ * @returns Subtests in source order
+ */
+export const extractSubtests = (testName: string, code: string, startLine: number): GoSubtest[] => {
+  const lineAt = (offset: number): number => startLine + (code.slice(0, offset).match(/\n/g)?.length ?? 0);
+  const subtests: GoSubtest[] = [];
+  // Run calls still open at the current offset, innermost last
+  const open: { name: string; end: number }[] = [];
+
+  for (const match of code.matchAll(RUN_CALL)) {
+    const start = match.index;
+    while (open.length > 0 && open[open.length - 1].end < start) open.pop();
+
+    const raw = match[0].endsWith('`');
+    const parent = open.length > 0 ? open[open.length - 1].name : testName;
+    const name = `${parent}/${rewriteSubtestName(raw ? match[2] : match[1], raw)}`;
+    const end = closingParen(code, start + match[0].indexOf('('));
+    const lineEnd = code.indexOf('\n', start);
+
+    subtests.push({
+      name,
+      line: lineAt(start),
+      end_line: lineAt(end),
+      definition: code.slice(start, lineEnd === -1 ? undefined : lineEnd).trim(),
+    });
+    open.push({ name, end });
+  }
+  return subtests;
+};
@@ -0,0 +1,264 @@
+/**
+ * Type-checked Go indexing (cindex index --typed)
+ *
+ * Syntax alone cannot tell which type a method belongs to once it is called
+ * through a variable, which types satisfy an interface, or what an identifier
+ * from another package refers to. Typed mode builds a small helper on
+ * go/packages (the loader gopls uses), type-checks the repository's packages
+ * with their tests, and records:
+ * - method signatures as go/types prints them, for the Receiver.Method
+ *   symbols syntax indexing names
+ * - interface satisfaction between named types of the repository and the
+ *   interfaces of its packages, their imports, and error
+ * - uses of the repository's package-level declarations, methods, and
+ *   struct fields
+ *
+ * The helper is built once per Go version into ~/.cindex/go-loader/; the
+ * first build downloads golang.org/x/tools. Loading compiles dependencies
+ * like `go vet` does, so typed mode is chosen per run. Packages are loaded
+ * with `./...` from the repository root: a go.work there covers nested
+ * modules, other nested modules are not loaded.
+ *
+ * go/packages sees the files of one GOOS/GOARCH, by default the go
+ * command's. Methods and uses in files built for other platforms
+ * (file_windows.go on a Linux host) are recorded when the packages are also
+ * loaded for those platforms (--platforms); the facts of every load are
+ * merged.
+ */
+
+import { execFile } from 'node:child_process';
+import { createHash } from 'node:crypto';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import { promisify } from 'node:util';
+
+import { platformName } from '@indexing/build-constraints';
+import { GO_LOADER_MODULE, GO_LOADER_SOURCE } from '@indexing/go-loader-source';
+import { logger } from '@utils/logger';
+import { toPosixPath } from '@utils/paths';
+import { type GoReferenceAccess } from '@/types/database';
+import { type GoTypeFacts, type Platform } from '@/types/indexing';
+
+const execFileAsync = promisify(execFile);
+
+/** Built helpers, one directory per helper source and Go version */
+const LOADER_DIR = path.join(os.homedir(), '.cindex', 'go-loader');
+
+/** Maximum helper output buffered (one line per reference) */
+const LOADER_MAX_BUFFER = 1024 * 1024 * 1024;
+
+/** Package errors logged before the rest are counted */
+const LOAD_ERRORS_LOGGED = 10;
+
+/**
+ * Line of the helper's output
+ */
+interface LoaderFact {
+  kind?: string;
+  file?: string;
+  line?: number;
+  column?: number;
+  end_line?: number;
+  name?: string;
+  package?: string;
+  receiver?: string;
+  pointer?: boolean;
+  signature?: string;
+  target?: string;
+  target_package?: string;
+  target_file?: string;
+  target_line?: number;
+  access?: GoReferenceAccess;
+}
+
+/**
+ * Path of a loaded file relative to the repository root (null outside it, e.g. cgo output)
+ */
+const toRepoPath = (file: string | undefined, repoPath: string): string | null => {
+  if (!file) return null;
+  const relative = path.relative(repoPath, file);
+  if (relative === '' || relative.startsWith('..') || path.isAbsolute(relative)) return null;
+  return toPosixPath(relative);
+};
+
+/**
+ * Parse the helper's output
+ *
+ * @param text - One JSON fact per line
+ * @param repoPath - Repository root the packages were loaded from
+ * @returns Facts on files inside the repository, paths relative to it
+ * @throws {Error} If a line is not JSON
+ */
+export const parseLoaderOutput = (text: string, repoPath: string): GoTypeFacts => {
+  const facts: GoTypeFacts = { methods: [], implementations: [], references: [] };
+
+  text.split(/\r?\n/).forEach((raw, index) => {
+    if (raw.trim() === '') return;
+    let fact: LoaderFact;
+    try {
+      fact = JSON.parse(raw) as LoaderFact;
+    } catch {
+      throw new Error(`line ${String(index + 1)}: not JSON`);
+    }
+
+    const filePath = toRepoPath(fact.file, repoPath);
+    if (!filePath || !fact.line || !fact.name) return;
+    const pkg = fact.package ?? '';
+
+    if (fact.kind === 'method' && fact.receiver) {
+      facts.methods.push({
+        file_path: filePath,
+        line_number: fact.line,
+        end_line: fact.end_line ?? fact.line,
+        name: fact.name,
+        receiver: fact.receiver,
+        pointer_receiver: fact.pointer ?? false,
+        package: pkg,
+        signature: fact.signature ?? `func (${fact.receiver}).${fact.name}`,
+      });
+    } else if (fact.kind === 'implements' && fact.target) {
+      facts.implementations.push({
+        file_path: filePath,
+        line_number: fact.line,
+        type_name: fact.name,
+        type_package: pkg,
+        interface_name: fact.target,
+        interface_package: fact.target_package ?? '',
+        pointer_receiver: fact.pointer ?? false,
+      });
+    } else if (fact.kind === 'reference') {
+      const targetFile = toRepoPath(fact.target_file, repoPath);
+      if (!targetFile || !fact.target_line) return;
+      facts.references.push({
+        file_path: filePath,
+        line_number: fact.line,
+        column_number: fact.column ?? 0,
+        target_name: fact.name,
+        target_package: pkg,
+        target_file: targetFile,
+        target_line: fact.target_line,
+        access: fact.access ?? null,
+      });
+    }
+  });
+  return facts;
+};
+
+/**
+ * Merge the facts of several loads (one per platform)
+ *
+ * Files shared by the platforms yield the same fact in every load; each is
+ * kept once.
+ *
+ * @param loads - Facts of each load
+ * @returns Facts of all loads, first occurrence kept
+ */
+export const mergeGoTypeFacts = (loads: GoTypeFacts[]): GoTypeFacts => {
+  const unique = <T>(rows: T[], key: (row: T) => (string | number | boolean)[]): T[] => {
+    const seen = new Set<string>();
+    return rows.filter((row) => {
+      const id = key(row).join(':');
+      if (seen.has(id)) return false;
+      seen.add(id);
+      return true;
+    });
+  };
+  return {
+    methods: unique(
+      loads.flatMap((facts) => facts.methods),
+      (method) => [method.file_path, method.line_number, method.receiver, method.name]
+    ),
+    implementations: unique(
+      loads.flatMap((facts) => facts.implementations),
+      (row) => [row.file_path, row.line_number, row.interface_package, row.interface_name, row.pointer_receiver]
+    ),
+    references: unique(
+      loads.flatMap((facts) => facts.references),
+      (row) => [row.file_path, row.line_number, row.column_number, row.target_name]
+    ),
+  };
+};
+
+/**
+ * Standard error of a failed command, or its message
+ */
+const commandError = (error: unknown): string => {
+  const stderr = (error as { stderr?: unknown }).stderr;
+  if (typeof stderr === 'string' && stderr.trim() !== '') return stderr.trim();
+  return error instanceof Error ? error.message : String(error);
+};
+
+/**
+ * Build the helper, or reuse the one built for this source and Go version
+ *
+ * @returns Path of the helper binary
+ * @throws {Error} If go is not installed or the build fails
+ */
+export const buildGoLoader = async (): Promise<string> => {
+  let goVersion: string;
+  try {
+    goVersion = (await execFileAsync('go', ['env', 'GOVERSION'])).stdout.trim();
+  } catch {
+    throw new Error('go is not on PATH; typed mode loads packages with the Go toolchain');
+  }
+
+  const version = createHash('sha256').update(GO_LOADER_MODULE).update(GO_LOADER_SOURCE).update(goVersion);
+  const dir = path.join(LOADER_DIR, version.digest('hex').slice(0, 16));
+  const binary = path.join(dir, process.platform === 'win32' ? 'cindex-go-loader.exe' : 'cindex-go-loader');
+  if (fs.existsSync(binary)) return binary;
+
+  logger.info('Building the Go package loader', { dir, go: goVersion });
+  fs.mkdirSync(dir, { recursive: true });
+  fs.writeFileSync(path.join(dir, 'go.mod'), GO_LOADER_MODULE);
+  fs.writeFileSync(path.join(dir, 'main.go'), GO_LOADER_SOURCE);
+  try {
+    // The newest x/tools reads the export data of the newest toolchains
+    await execFileAsync('go', ['get', 'golang.org/x/tools@latest'], { cwd: dir });
+    await execFileAsync('go', ['build', '-o', binary, '.'], { cwd: dir });
+  } catch (error) {
+    throw new Error(`cannot build the Go package loader in ${dir}: ${commandError(error)}`);
+  }
+  return binary;
+};
+
+/**
+ * Type-check the Go packages of a repository
+ *
+ * Packages that fail to type-check are logged and contribute what could be
+ * checked, like gopls does.
+ *
+ * @param repoPath - Repository root
+ * @param platform - GOOS/GOARCH to load the packages for (default: the go command's)
+ * @returns Type facts, paths relative to the root
+ * @throws {Error} If the helper cannot be built or go/packages cannot load the packages at all
+ */
+export const loadGoTypes = async (repoPath: string, platform?: Platform): Promise<GoTypeFacts> => {
+  const loader = await buildGoLoader();
+  const env = platform ? { ...process.env, GOOS: platform.os, GOARCH: platform.arch } : process.env;
+  const target = platform ? ` for ${platformName(platform)}` : '';
+
+  let stdout: string;
+  let stderr: string;
+  try {
+    ({ stdout, stderr } = await execFileAsync(loader, ['-dir', repoPath, './...'], {
+      cwd: repoPath,
+      env,
+      maxBuffer: LOADER_MAX_BUFFER,
+    }));
+  } catch (error) {
+    throw new Error(`go/packages cannot load ${repoPath}${target}: ${commandError(error)}`);
+  }
+
+  const errors = stderr.split('\n').filter((line) => line.trim() !== '');
+  if (errors.length > 0) {
+    logger.warn('Go packages loaded with errors', {
+      repo: repoPath,
+      platform: platform ? platformName(platform) : undefined,
+      count: errors.length,
+      errors: errors.slice(0, LOAD_ERRORS_LOGGED),
+    });
+  }
+
+  return parseLoaderOutput(stdout, repoPath);
+};
@@ -0,0 +1,279 @@
+/**
+ * Go workspaces: the modules of a repository and how they require each other
+ *
+ * A repository can hold several Go modules, each a go.mod and the files
+ * under it up to the next go.mod. With a go.work at the root, its `use`
+ * directives name the member modules; without one, every go.mod next to
+ * indexed files counts (testdata and vendor directories are not modules).
+ * Files are tagged with the module they belong to, and a module depends on
+ * another member it requires or replaces with the member's directory.
+ *
+ *   go.work            use ( ./api ./shared )
+ *   api/go.mod         module github.com/acme/shop/api    requires shared
+ *   shared/go.mod      module github.com/acme/shop/shared
+ *
+ * Invoked in a member module, `cindex index` indexes the whole workspace
+ * (see findGoWorkspaceRoot). Members outside the go.work's directory
+ * (use ../tools) are left out.
+ */
+
+import * as path from 'node:path';
+
+import { type MemoryTree } from '@indexing/memory-tree';
+import { readTextFile } from '@utils/edge-cases';
+import { logger } from '@utils/logger';
+import { compareStrings } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
+import { type GoWorkspaceModule } from '@/types/indexing';
+
+/** Directory names whose go.mod files are not modules of the repository */
+const NOT_MODULES = new Set(['testdata', 'vendor']);
+
+/**
+ * Dependency of one member module on another
+ */
+export interface GoModuleDependency {
+  from: GoWorkspaceModule;
+  to: GoWorkspaceModule;
+  /** Version required, or the directory a replacement points at */
+  version: string;
+}
+
+/**
+ * Declarations of a go.mod
+ */
+export interface GoModFile {
+  /** Module path (null when the file declares none) */
+  module: string | null;
+  go_version: string | null;
+  requires: GoWorkspaceModule['requires'];
+  /** Local replacements, with directories as written */
+  replacements: GoWorkspaceModule['replacements'];
+}
+
+/**
+ * Split a directive line into tokens, unquoting "interpreted" and `raw` strings
+ */
+const tokensOf = (line: string): string[] =>
+  [...line.matchAll(/"[^"]*"|`[^`]*`|\S+/g)].map(([token]) => token.replace(/^(["`])(.*)\1$/, '$2'));
+
+/**
+ * Read the directives of a go.mod or go.work, expanding blocks (require ( ... ))
+ *
+ * @returns Verb and argument tokens of each directive, comments dropped and quotes removed
+ */
+const readDirectives = (content: string): { verb: string; args: string[] }[] => {
+  const directives: { verb: string; args: string[] }[] = [];
+  let block: string | null = null;
+  for (const raw of content.split('\n')) {
+    const line = raw.replace(/\/\/.*$/, '').trim();
+    if (line === '') continue;
+    if (block !== null) {
+      if (line === ')') block = null;
+      else directives.push({ verb: block, args: tokensOf(line) });
+      continue;
+    }
+    const [verb, ...args] = tokensOf(line);
+    if (args.length === 1 && args[0] === '(') block = verb;
+    else directives.push({ verb, args });
+  }
+  return directives;
+};
+
+/**
+ * Read the member directories of a go.work
+ *
+ * @param content - go.work content
+ * @returns Directories as written in its use directives
+ */
+export const parseGoWork = (content: string): string[] => {
+  return readDirectives(content)
+    .filter((directive) => directive.verb === 'use' && directive.args.length > 0)
+    .map((directive) => directive.args[0]);
+};
+
+/**
+ * Read the module path, Go version, requirements, and local replacements of a go.mod
+ *
+ * @param content - go.mod content
+ * @returns Declarations of the file
+ */
+export const parseGoMod = (content: string): GoModFile => {
+  const parsed: GoModFile = { module: null, go_version: null, requires: [], replacements: [] };
+  for (const { verb, args } of readDirectives(content)) {
+    if (verb === 'module' && args.length > 0) parsed.module = args[0];
+    else if (verb === 'go' && args.length > 0) parsed.go_version = args[0];
+    else if (verb === 'require' && args.length >= 2) parsed.requires.push({ path: args[0], version: args[1] });
+    else if (verb === 'replace') {
+      // old [version] => new [version]; only directories (./x, ../x, /x) are local
+      const arrow = args.indexOf('=>');
+      const target = arrow === -1 ? undefined : args[arrow + 1];
+      if (target && /^(?:\.{1,2}\/|\/)/.test(target)) parsed.replacements.push({ path: args[0], directory: target });
+    }
+  }
+  return parsed;
+};
+
+/**
+ * Read a file that may not exist
+ *
+ * @returns Content, or null if the file is missing or unreadable
+ */
+const readIfPresent = async (filePath: string): Promise<string | null> => {
+  try {
+    return await readTextFile(filePath);
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * Resolve a directory written in a go.mod or go.work against the repository root
+ *
+ * @param rootPath - Repository root
+ * @param base - Directory of the file it is written in, relative to the root
+ * @param written - Directory as written
+ * @returns Directory relative to the root ('.' for the root), or null if it is outside the root
+ */
+const resolveMember = (rootPath: string, base: string, written: string): string | null => {
+  const absolute = path.resolve(rootPath, base, written);
+  const relative = toPosixPath(path.relative(rootPath, absolute));
+  if (relative.startsWith('..') || path.isAbsolute(relative)) return null;
+  return relative === '' ? '.' : relative;
+};
+
+/**
+ * Find the Go modules of a repository
+ *
+ * @param rootPath - Repository root
+ * @param files - Paths of the discovered files, relative to the root (where go.mod files are looked for)
+ * @param tree - Files held in memory, read instead of the root (see @indexing/memory-tree)
+ * @returns Modules ordered by directory
+ */
+export const detectGoModules = async (
+  rootPath: string,
+  files: string[],
+  tree?: MemoryTree
+): Promise<GoWorkspaceModule[]> => {
+  const read = (relativePath: string): Promise<string | null> =>
+    tree ? Promise.resolve(tree.readText(relativePath)) : readIfPresent(path.join(rootPath, relativePath));
+
+  const work = await read('go.work');
+  const directories = new Set<string>();
+  if (work !== null) {
+    for (const written of parseGoWork(work)) {
+      const directory = resolveMember(rootPath, '.', written);
+      if (directory === null) logger.warn('go.work member outside the indexed directory is not indexed', { written });
+      else directories.add(directory);
+    }
+  } else {
+    // Directories of the discovered files and their parents, up to the root
+    directories.add('.');
+    for (const file of files) {
+      let directory = path.posix.dirname(file);
+      while (directory !== '.' && !directories.has(directory)) {
+        directories.add(directory);
+        directory = path.posix.dirname(directory);
+      }
+    }
+  }
+
+  const modules: GoWorkspaceModule[] = [];
+  for (const directory of [...directories].sort(compareStrings)) {
+    if (directory.split('/').some((segment) => NOT_MODULES.has(segment))) continue;
+    const content = await read(path.posix.join(directory, 'go.mod'));
+    if (content === null) continue;
+    const goMod = parseGoMod(content);
+    if (goMod.module === null) continue;
+    modules.push({
+      module_path: goMod.module,
+      directory,
+      go_version: goMod.go_version,
+      requires: goMod.requires,
+      replacements: goMod.replacements.flatMap((replacement) => {
+        const resolved = resolveMember(rootPath, directory, replacement.directory);
+        return resolved === null ? [] : [{ path: replacement.path, directory: resolved }];
+      }),
+    });
+  }
+  return modules;
+};
+
+/**
+ * Module a file belongs to: the one whose directory holds it most closely
+ *
+ * @param modules - Modules of the repository
+ * @param filePath - File path relative to the repository root
+ * @returns Module, or null if no go.mod is above the file
+ */
+export const goModuleOf = (modules: GoWorkspaceModule[], filePath: string): GoWorkspaceModule | null => {
+  let best: GoWorkspaceModule | null = null;
+  for (const module of modules) {
+    const holds = module.directory === '.' || filePath.startsWith(`${module.directory}/`);
+    if (holds && (best === null || module.directory.length > best.directory.length)) best = module;
+  }
+  return best;
+};
+
+/**
+ * Workspace id of a module (code_files.workspace_id): unique across indexes
+ */
+export const goModuleWorkspaceId = (repoId: string, module: GoWorkspaceModule): string =>
+  `${repoId}:${module.module_path}`;
+
+/**
+ * Dependencies between the modules of a repository
+ *
+ * A module depends on a member it requires, or replaces with the member's
+ * directory (replace github.com/acme/shared => ../shared).
+ *
+ * @param modules - Modules of the repository
+ * @returns Dependencies ordered by module and required module
+ */
+export const goModuleDependencies = (modules: GoWorkspaceModule[]): GoModuleDependency[] => {
+  const byPath = new Map(modules.map((module) => [module.module_path, module]));
+  const byDirectory = new Map(modules.map((module) => [module.directory, module]));
+
+  const dependencies: GoModuleDependency[] = [];
+  for (const from of modules) {
+    const targets = new Map<GoWorkspaceModule, string>();
+    for (const required of from.requires) {
+      const to = byPath.get(required.path);
+      if (to && to !== from) targets.set(to, required.version);
+    }
+    for (const replacement of from.replacements) {
+      const to = byDirectory.get(replacement.directory);
+      if (to && to !== from) targets.set(to, replacement.directory);
+    }
+    for (const [to, version] of targets) dependencies.push({ from, to, version });
+  }
+  return dependencies.sort(
+    (a, b) =>
+      compareStrings(a.from.module_path, b.from.module_path) || compareStrings(a.to.module_path, b.to.module_path)
+  );
+};
+
+/**
+ * Find the go.work a directory is a member module of
+ *
+ * Go uses the nearest go.work above the working directory; it applies when
+ * one of its use directives names the directory or one of its parents.
+ *
+ * @param directory - Absolute directory
+ * @returns Directory of the go.work, or null if the directory is not in a workspace (or holds its go.work)
+ */
+export const findGoWorkspaceRoot = async (directory: string): Promise<string | null> => {
+  const start = path.resolve(directory);
+  for (let dir = start; ; dir = path.dirname(dir)) {
+    const work = await readIfPresent(path.join(dir, 'go.work'));
+    if (work !== null) {
+      if (dir === start) return null;
+      const member = parseGoWork(work).some((written) => {
+        const relative = path.relative(path.resolve(dir, written), start);
+        return relative === '' || (!relative.startsWith('..') && !path.isAbsolute(relative));
+      });
+      return member ? dir : null;
+    }
+    if (path.dirname(dir) === dir) return null;
+  }
+};
@@ -7,7 +7,9 @@
 import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { compareNames } from '@utils/ordering';
 import {
   type EndpointImplementation,
   type ImplementationLinker,
@@ -349,7 +351,7 @@ export class APIImplementationLinker implements ImplementationLinker {
     const files = await this.getAllFiles(dirPath);
 
     for (const file of files) {
-      const content = await fs.readFile(file, 'utf-8');
+      const content = await readTextFile(file);
       const lines = content.split('\n');
 
       for (let i = 0; i < lines.length; i++) {
@@ -388,7 +390,7 @@ export class APIImplementationLinker implements ImplementationLinker {
     ];
 
     for (const file of files) {
-      const content = await fs.readFile(file, 'utf-8');
+      const content = await readTextFile(file);
       const lines = content.split('\n');
 
       for (let i = 0; i < lines.length; i++) {
@@ -434,7 +436,7 @@ export class APIImplementationLinker implements ImplementationLinker {
     method: string,
     _endpointPath: string
   ): Promise<{ line_start: number; line_end: number; function_name?: string } | null> => {
-    const content = await fs.readFile(filePath, 'utf-8');
+    const content = await readTextFile(filePath);
     const lines = content.split('\n');
 
     // Search for method handler (simple heuristic)
@@ -500,7 +502,7 @@ export class APIImplementationLinker implements ImplementationLinker {
 
     const traverse = async (currentPath: string) => {
       try {
-        const entries = await fs.readdir(currentPath, { withFileTypes: true });
+        const entries = (await fs.readdir(currentPath, { withFileTypes: true })).sort(compareNames);
 
         for (const entry of entries) {
           const fullPath = path.join(currentPath, entry.name);
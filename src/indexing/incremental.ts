@@ -5,17 +5,18 @@
  * Uses SHA256 file hashes to identify new, modified, unchanged, and deleted files.
  *
  * Key Features:
+ * - Stat fast path: Files with the size and mtime recorded at indexing are not read or hashed
  * - Hash comparison: Compare filesystem hashes with database hashes
  * - Change classification: Categorize files into new/modified/unchanged/deleted
  * - Selective processing: Only re-index files that have changed
- * - Stale data cleanup: Remove chunks/symbols for modified/deleted files
+ * - Stale data cleanup: Remove chunks/symbols for modified/deleted files, and file records for deleted files
  *
  * Performance Target: 100 files processed in <15s (vs 30-60s for full re-index)
  */
 
 import { type DatabaseClient } from '@database/client';
 import { logger } from '@utils/logger';
-import { type DiscoveredFile } from '@/types/indexing';
+import { type DiscoveredFile, type IndexedFileStamp } from '@/types/indexing';
 
 /**
  * File change types for incremental indexing
@@ -60,6 +61,73 @@ interface FileHashRow {
   file_hash: string;
 }
 
+/**
+ * Database row for file stamp query
+ */
+interface FileStampRow {
+  file_path: string;
+  file_hash: string;
+  total_lines: number | null;
+  file_size: number;
+  file_mtime_ms: number;
+  encoding: string | null;
+}
+
+/**
+ * Check whether a file is unchanged since it was indexed, judged by its stat
+ *
+ * Size and modification time must both match; the content is not compared.
+ *
+ * @param stamp - What the index recorded for the file
+ * @param size - Current size in bytes
+ * @param mtime - Current modification time
+ */
+export const isUnchangedOnDisk = (stamp: IndexedFileStamp, size: number, mtime: Date): boolean => {
+  return stamp.file_size === size && stamp.mtime_ms === mtime.getTime();
+};
+
+/**
+ * Fetch what the index recorded about each file, for the stat fast path
+ *
+ * Files indexed before sizes and mtimes were recorded, quarantined files, and
+ * generated files (their tag is decided from the content) are left out, so
+ * discovery reads them.
+ *
+ * @param db - Database client
+ * @param repoPath - Repository path to filter files
+ * @returns Map of file_path → stamp
+ */
+export const fetchIndexedFiles = async (
+  db: DatabaseClient,
+  repoPath: string
+): Promise<Map<string, IndexedFileStamp>> => {
+  const query = `
+    SELECT file_path, file_hash, total_lines, file_size::float8 AS file_size,
+           file_mtime_ms::float8 AS file_mtime_ms, encoding
+    FROM code_files
+    WHERE repo_path = $1
+      AND file_size IS NOT NULL AND file_mtime_ms IS NOT NULL
+      AND quarantined_at IS NULL AND NOT generated
+  `;
+
+  const result = await db.query<FileStampRow>(query, [repoPath]);
+
+  const stamps = new Map<string, IndexedFileStamp>();
+  for (const row of result.rows) {
+    stamps.set(row.file_path, {
+      file_hash: row.file_hash,
+      line_count: row.total_lines ?? 0,
+      file_size: row.file_size,
+      mtime_ms: row.file_mtime_ms,
+      encoding: row.encoding ?? 'utf-8',
+    });
+  }
+
+  logger.debug('Fetched indexed file stamps', { repo: repoPath, files: stamps.size });
+
+  return stamps;
+};
+
 /**
  * Fetch existing file hashes from database
  *
@@ -73,8 +141,9 @@ interface FileHashRow {
 const fetchExistingHashes = async (db: DatabaseClient, repoPath: string): Promise<Map<string, string>> => {
   logger.debug('Fetching existing file hashes from database', { repo: repoPath });
 
+  // Quarantined files (see integrity.ts) get an empty hash so they are re-indexed as modified
   const query = `
-    SELECT file_path, file_hash
+    SELECT file_path, CASE WHEN quarantined_at IS NULL THEN file_hash ELSE '' END AS file_hash
     FROM code_files
     WHERE repo_path = $1
   `;
@@ -210,6 +279,25 @@ export const deleteStaleData = async (
     const chunksResult = await db.query(chunksQuery, [filePaths]);
     const chunksDeleted = chunksResult.rowCount ?? 0;
 
+    // Findings are rewritten when the file is scanned again
+    await db.query('DELETE FROM secret_findings WHERE file_path = ANY($1::text[])', [filePaths]);
+    // Lint findings point at lines that may have moved; import the report again
+    await db.query('DELETE FROM lint_findings WHERE file_path = ANY($1::text[])', [filePaths]);
+    // Type facts too, including uses elsewhere of declarations in these files; run --typed again
+    await db.query('DELETE FROM go_implementations WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM go_references WHERE file_path = ANY($1::text[]) OR target_file = ANY($1::text[])', [
+      filePaths,
+    ]);
+    await db.query('DELETE FROM go_calls WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM go_constants WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM go_type_parameters WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM go_instantiations WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM go_embeddings WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM code_annotations WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM code_literals WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM code_plugin_metrics WHERE file_path = ANY($1::text[])', [filePaths]);
+    await db.query('DELETE FROM code_contents WHERE file_path = ANY($1::text[])', [filePaths]);
+
     logger.info('Deleted stale data', {
       fileCount: filePaths.length,
       chunks_deleted: chunksDeleted,
@@ -299,7 +387,7 @@ export const detectFileChanges = async (
  *
  * Orchestrates the incremental indexing workflow:
  * 1. Delete old chunks/symbols for modified files (required because chunks use ON CONFLICT DO NOTHING)
- * 2. Delete data for deleted files
+ * 2. Delete data and file records for deleted files
  * 3. Return files that need processing (new + modified)
  *
  * Why delete modified files?
@@ -336,9 +424,10 @@ export const processIncrementalChanges = async (
     logger.info('Deleted old chunks/symbols for modified files', { count: modifiedPaths.length });
   }
 
-  // Step 2: Delete data for deleted files
+  // Step 2: Delete data for deleted files, then their file records so they are not reported deleted again
   if (changes.deleted.length > 0) {
     await deleteStaleData(db, changes.deleted);
+    await db.query('DELETE FROM code_files WHERE file_path = ANY($1::text[])', [changes.deleted]);
     logger.info('Deleted data for removed files', { count: changes.deleted.length });
   }
 
@@ -0,0 +1,569 @@
+/**
+ * Index Lock: Cross-Process Advisory Locking for Index Writes
+ *
+ * Two writers on the same repository (two `cindex index` runs, or the CLI
+ * racing the MCP server) would interleave deletes and inserts and leave the
+ * index inconsistent. Each writer holds a lock file in ~/.cindex/locks named
+ * after the repository ID, created with O_EXCL so only one process wins.
+ * Locks left behind by a crashed process are detected by PID and removed by
+ * one waiter at a time (see removeStaleLock).
+ *
+ * Readers never wait for a writer: PostgreSQL serves queries while an index
+ * is being written. A reader registers a shared lock file in
+ * <repo>.readers/, and only writers that remove an index outright take the
+ * write lock as exclusive and wait for registered readers to finish. A
+ * generation file, bumped each time a writer finishes, tells readers whether
+ * the index changed underneath them.
+ *
+ * The index is split into SHARD_COUNT shards by package directory (a hash of
+ * it), each with its own generation. An incremental run publishes only the
+ * shards of the files it rewrote or removed, so a read of one file or
+ * package (see readConsistently) is only retried when its own shards change,
+ * not whenever the watcher re-indexes another part of the repository.
+ */
+
+import { createHash, randomBytes } from 'node:crypto';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { IndexLockedError } from '@utils/errors';
+import { logger } from '@utils/logger';
+import { lockWaitDuration, lockWaits, readRetries, secondsSince } from '@utils/metrics';
+
+/** Lock directory (inside the per-user cindex state directory) */
+const LOCK_DIR = path.join(os.homedir(), '.cindex', 'locks');
+
+/** Interval between attempts while waiting for a lock */
+const LOCK_POLL_MS = 500;
+
+/** Longest an exclusive writer waits for readers before proceeding anyway */
+const READER_DRAIN_MS = 10_000;
+
+/** Interval between reader checks (readers are short-lived queries) */
+const READER_POLL_MS = 50;
+
+/** Shards an index is split into for change tracking */
+export const SHARD_COUNT = 64;
+
+/**
+ * Resolve after a delay
+ */
+const sleep = (ms: number): Promise<void> => new Promise((resolve) => setTimeout(resolve, ms));
+
+/**
+ * Contents of a lock file
+ */
+export interface LockHolder {
+  pid: number;
+  hostname: string;
+  started_at: string;
+  command: string;
+  /** Set by writers that readers must not overlap with (e.g. deleting an index) */
+  exclusive?: boolean;
+}
+
+/**
+ * Held index lock
+ */
+export interface IndexLock {
+  /** Lock file path */
+  file: string;
+  /** Release the lock (idempotent) */
+  release: () => void;
+}
+
+/**
+ * Held shared read lock
+ */
+export interface ReadLock {
+  /** Reader lock file path */
+  file: string;
+  /** Generation of the index when the lock was taken (0 if never written) */
+  generation: number;
+  /** Files the read is limited to (undefined: the whole index) */
+  scope?: string[];
+  /** Generations of the scope's shards when the lock was taken */
+  shards: number[];
+  /** Release the lock (idempotent) */
+  release: () => void;
+}
+
+/**
+ * Contents of a generation file
+ */
+export interface IndexGeneration {
+  /** Incremented each time a writer finishes */
+  generation: number;
+  /** Generation of the last run that rewrote every shard (absent: the current generation) */
+  base?: number;
+  /** Generation of each shard rewritten since base, by shard number */
+  shards?: Record<string, number>;
+  /** A writer is currently running */
+  writing: boolean;
+  pid: number;
+  hostname: string;
+  updated_at: string;
+}
+
+/**
+ * Shard of a file: hashed from its directory, so a package stays in one shard
+ *
+ * @param filePath - File path relative to the repository root
+ * @returns Shard number, below SHARD_COUNT
+ */
+export const shardOf = (filePath: string): number => {
+  const directory = path.posix.dirname(filePath.replace(/\\/g, '/'));
+  return createHash('md5').update(directory).digest().readUInt32BE(0) % SHARD_COUNT;
+};
+
+/**
+ * Generation of one shard: the last one that rewrote it
+ */
+const shardGeneration = (current: IndexGeneration | null, shard: number): number => {
+  if (!current) return 0;
+  return current.shards?.[String(shard)] ?? current.base ?? current.generation;
+};
+
+/**
+ * Generations of the shards holding some files, ordered by shard number
+ */
+const scopeGenerations = (current: IndexGeneration | null, filePaths: string[]): number[] => {
+  const shards = [...new Set(filePaths.map(shardOf))].sort((a, b) => a - b);
+  return shards.map((shard) => shardGeneration(current, shard));
+};
+
+/**
+ * Lock directory file name for a repository ID (unsafe filename characters replaced)
+ */
+const safeName = (repoId: string): string => repoId.replace(/[^A-Za-z0-9._-]/g, '_');
+
+/**
+ * Lock file path for a repository ID
+ */
+const lockFileFor = (repoId: string): string => {
+  return path.join(LOCK_DIR, `${safeName(repoId)}.lock`);
+};
+
+/**
+ * Directory holding the shared reader locks of a repository ID
+ */
+const readersDirFor = (repoId: string): string => {
+  return path.join(LOCK_DIR, `${safeName(repoId)}.readers`);
+};
+
+/**
+ * Generation file path for a repository ID
+ */
+const generationFileFor = (repoId: string): string => {
+  return path.join(LOCK_DIR, `${safeName(repoId)}.generation`);
+};
+
+/**
+ * Read the current holder of a lock file (null if missing or unreadable)
+ */
+const readHolder = (file: string): LockHolder | null => {
+  try {
+    const holder = JSON.parse(fs.readFileSync(file, 'utf-8')) as LockHolder;
+    return typeof holder.pid === 'number' ? holder : null;
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * Milliseconds since a lock file was written (0 if it vanished meanwhile)
+ */
+const lockAge = (file: string): number => {
+  try {
+    return Date.now() - fs.statSync(file).mtimeMs;
+  } catch {
+    return 0;
+  }
+};
+
+/**
+ * Check whether a lock holder is still running
+ *
+ * Holders on another host (shared home directory) are assumed alive.
+ */
+const isHolderAlive = (holder: { pid: number; hostname: string }): boolean => {
+  if (holder.hostname !== os.hostname()) return true;
+  try {
+    process.kill(holder.pid, 0);
+    return true;
+  } catch (error) {
+    // EPERM: process exists but belongs to another user
+    return (error as NodeJS.ErrnoException).code === 'EPERM';
+  }
+};
+
+/**
+ * Check whether a lock holder is this process
+ */
+const isSelf = (holder: { pid: number; hostname: string }): boolean => {
+  return holder.pid === process.pid && holder.hostname === os.hostname();
+};
+
+/**
+ * Try to create the lock file exclusively
+ *
+ * @param exclusive - Record the holder as excluding readers
+ * @returns True if this process now holds the lock
+ */
+const tryCreate = (file: string, exclusive = false): boolean => {
+  const holder: LockHolder = {
+    pid: process.pid,
+    hostname: os.hostname(),
+    started_at: new Date().toISOString(),
+    command: process.argv.slice(1).join(' '),
+    ...(exclusive ? { exclusive } : {}),
+  };
+
+  try {
+    fs.writeFileSync(file, JSON.stringify(holder) + '\n', { flag: 'wx' });
+    return true;
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'EEXIST') return false;
+    throw error;
+  }
+};
+
+/**
+ * Check whether two lock files name the same holder
+ */
+const isSameHolder = (a: LockHolder, b: LockHolder): boolean =>
+  a.pid === b.pid && a.hostname === b.hostname && a.started_at === b.started_at;
+
+/**
+ * Remove a lock file left by a dead holder, unless it was taken over meanwhile
+ *
+ * Waiters that found the same dead holder go through a takeover file created
+ * with O_EXCL one at a time, and each reads the lock again inside it: the
+ * first removes the stale lock and re-creates it as its own, and the next
+ * finds that lock and leaves it alone, instead of removing it to create a
+ * second one.
+ *
+ * @param file - Lock file
+ * @param stale - Holder found dead (null: a lock file left empty)
+ * @returns False if another waiter is taking the lock over (retry shortly)
+ */
+export const removeStaleLock = (file: string, stale: LockHolder | null): boolean => {
+  const takeover = `${file}.takeover`;
+  if (!tryCreate(takeover)) {
+    // A waiter that died between these lines leaves its takeover file behind
+    const other = readHolder(takeover);
+    if (other ? !isHolderAlive(other) : lockAge(takeover) >= LOCK_POLL_MS) fs.rmSync(takeover, { force: true });
+    return false;
+  }
+  try {
+    const current = readHolder(file);
+    const unchanged = stale
+      ? current !== null && isSameHolder(current, stale)
+      : current === null && lockAge(file) >= LOCK_POLL_MS;
+    if (unchanged) fs.rmSync(file, { force: true });
+    return true;
+  } finally {
+    fs.rmSync(takeover, { force: true });
+  }
+};
+
+/**
+ * Live reader lock files of a repository (stale ones left by crashed readers are removed)
+ */
+const liveReaders = (repoId: string): string[] => {
+  const dir = readersDirFor(repoId);
+  let names: string[];
+  try {
+    names = fs.readdirSync(dir);
+  } catch {
+    return [];
+  }
+
+  const live: string[] = [];
+  for (const name of names) {
+    const file = path.join(dir, name);
+    const holder = readHolder(file);
+    if (holder && isHolderAlive(holder)) {
+      live.push(file);
+    } else if (holder || lockAge(file) >= LOCK_POLL_MS) {
+      fs.rmSync(file, { force: true });
+    }
+  }
+  return live;
+};
+
+/**
+ * Wait for registered readers to finish, up to READER_DRAIN_MS
+ *
+ * Readers that outlast the limit keep running; they only read, so the worst
+ * case is a result that still lists rows being deleted.
+ */
+const drainReaders = async (repoId: string): Promise<void> => {
+  const deadline = Date.now() + READER_DRAIN_MS;
+  let readers = liveReaders(repoId);
+  while (readers.length > 0 && Date.now() < deadline) {
+    await sleep(READER_POLL_MS);
+    readers = liveReaders(repoId);
+  }
+  if (readers.length > 0) {
+    logger.warn('Proceeding with readers still active', { repo_id: repoId, readers: readers.length });
+  }
+};
+
+/**
+ * Count a lock acquisition that had to wait for another process
+ *
+ * @param lock - Lock waited for
+ * @param start - performance.now() when the wait began
+ */
+const recordWait = (lock: 'write' | 'read', start: number): void => {
+  lockWaits.inc({ lock });
+  lockWaitDuration.observe(secondsSince(start), { lock });
+};
+
+/**
+ * Acquire the write lock for a repository
+ *
+ * An exclusive lock also keeps new readers out and waits for active ones to
+ * finish; use it for writes that remove data readers may be looking at all
+ * at once (deleting an index), not for indexing runs.
+ *
+ * @param repoId - Repository ID being written
+ * @param wait - Wait for the current holder to finish instead of failing
+ * @param exclusive - Also exclude readers while the lock is held
+ * @returns Held lock (release it when writing is done)
+ * @throws {IndexLockedError} If another live process holds the lock and wait is false
+ */
+export const acquireIndexLock = async (repoId: string, wait = false, exclusive = false): Promise<IndexLock> => {
+  fs.mkdirSync(LOCK_DIR, { recursive: true });
+  const file = lockFileFor(repoId);
+  const start = performance.now();
+  let announced = false;
+
+  for (;;) {
+    if (tryCreate(file, exclusive)) break;
+
+    const holder = readHolder(file);
+    if (!holder && lockAge(file) < LOCK_POLL_MS) {
+      // Another writer is between creating and filling the file
+      await sleep(LOCK_POLL_MS);
+      continue;
+    }
+    if (!holder || !isHolderAlive(holder)) {
+      // Crashed writer (or an abandoned empty lock file): take it over
+      logger.warn('Removing stale index lock', { repo_id: repoId, file, pid: holder?.pid });
+      if (!removeStaleLock(file, holder)) await sleep(READER_POLL_MS);
+      continue;
+    }
+
+    // Waiting on our own lock (concurrent run in the same server) would never finish
+    if (!wait || isSelf(holder)) {
+      throw new IndexLockedError(repoId, holder.pid, file, holder.started_at);
+    }
+
+    if (!announced) {
+      logger.info('Waiting for index lock', { repo_id: repoId, pid: holder.pid, since: holder.started_at });
+      announced = true;
+    }
+    await sleep(LOCK_POLL_MS);
+  }
+  if (announced) recordWait('write', start);
+
+  let released = false;
+  const release = (): void => {
+    if (released) return;
+    released = true;
+    process.removeListener('exit', release);
+    // Only remove the file if it is still ours (never delete another writer's lock)
+    if (readHolder(file)?.pid === process.pid) {
+      fs.rmSync(file, { force: true });
+    }
+  };
+  process.once('exit', release);
+
+  if (exclusive) await drainReaders(repoId);
+
+  logger.debug('Acquired index lock', { repo_id: repoId, file, exclusive });
+  return { file, release };
+};
+
+/**
+ * Find the live process writing a repository, if any
+ *
+ * @param repoId - Repository ID
+ * @returns Holder of its write lock (null when no writer is running)
+ */
+export const findIndexWriter = (repoId: string): LockHolder | null => {
+  const holder = readHolder(lockFileFor(repoId));
+  return holder && isHolderAlive(holder) ? holder : null;
+};
+
+/**
+ * Acquire a shared read lock for a repository
+ *
+ * Never fails and never waits for indexing runs. Only an exclusive writer
+ * (see acquireIndexLock) makes a reader wait, and only until it finishes.
+ * The reader registers before checking for an exclusive writer, and the
+ * writer announces itself before checking for readers, so neither can miss
+ * the other.
+ *
+ * @param repoId - Repository ID being read
+ * @param scope - Files the read is limited to (default: the whole index)
+ * @returns Held lock (release it when the read is done)
+ */
+export const acquireReadLock = async (repoId: string, scope?: string[]): Promise<ReadLock> => {
+  const dir = readersDirFor(repoId);
+  fs.mkdirSync(dir, { recursive: true });
+  let file = '';
+  const start = performance.now();
+  let announced = false;
+
+  for (;;) {
+    file = path.join(dir, `${String(process.pid)}-${randomBytes(4).toString('hex')}.lock`);
+    if (!tryCreate(file)) continue;
+
+    const writer = readHolder(lockFileFor(repoId));
+    if (!writer?.exclusive || isSelf(writer) || !isHolderAlive(writer)) break;
+
+    // Step aside so the writer can drain, then register again once it is done
+    fs.rmSync(file, { force: true });
+    if (!announced) {
+      logger.info('Waiting for exclusive index operation', { repo_id: repoId, pid: writer.pid });
+      announced = true;
+    }
+    await sleep(READER_POLL_MS);
+  }
+  if (announced) recordWait('read', start);
+
+  let released = false;
+  const release = (): void => {
+    if (released) return;
+    released = true;
+    process.removeListener('exit', release);
+    fs.rmSync(file, { force: true });
+  };
+  process.once('exit', release);
+
+  const current = readGeneration(repoId);
+  return {
+    file,
+    generation: current?.generation ?? 0,
+    scope,
+    shards: scope ? scopeGenerations(current, scope) : [],
+    release,
+  };
+};
+
+/**
+ * Check whether what a read lock covers was rewritten since it was taken
+ *
+ * A lock with a scope only looks at the shards of its files.
+ *
+ * @param repoId - Repository ID being read
+ * @param lock - Read lock held for the read
+ * @returns True if a writer published a change the read may have missed
+ */
+export const hasChangedSince = (repoId: string, lock: ReadLock): boolean => {
+  const current = readGeneration(repoId);
+  if (!lock.scope) return (current?.generation ?? 0) !== lock.generation;
+  return scopeGenerations(current, lock.scope).some((generation, i) => generation !== lock.shards[i]);
+};
+
+/**
+ * Run a read under a shared read lock, retrying once if a writer changed it meanwhile
+ *
+ * Indexing runs never block the read. A read that spans several queries is
+ * retried once if a run finished in between, so its results come from one
+ * generation of the index (of its scope's shards, when given).
+ *
+ * @param repoId - Repository ID being read
+ * @param read - Queries to run
+ * @param scope - Files the read is limited to (default: the whole index)
+ * @returns Result of the read
+ */
+export const readConsistently = async <T>(repoId: string, read: () => Promise<T>, scope?: string[]): Promise<T> => {
+  for (let attempt = 1; ; attempt++) {
+    const lock = await acquireReadLock(repoId, scope);
+    try {
+      const result = await read();
+      if (attempt === 2 || !hasChangedSince(repoId, lock)) return result;
+      readRetries.inc();
+    } finally {
+      lock.release();
+    }
+  }
+};
+
+/**
+ * Read the generation file of a repository
+ *
+ * A writer that died without finishing is reported as not writing.
+ *
+ * @param repoId - Repository ID
+ * @returns Current generation (null if the index was never written by this version)
+ */
+export const readGeneration = (repoId: string): IndexGeneration | null => {
+  try {
+    const current = JSON.parse(fs.readFileSync(generationFileFor(repoId), 'utf-8')) as IndexGeneration;
+    if (typeof current.generation !== 'number') return null;
+    return current.writing && !isHolderAlive(current) ? { ...current, writing: false } : current;
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * Replace the generation file atomically (readers see the old or the new file, never a partial one)
+ */
+const writeGeneration = (
+  repoId: string,
+  generation: number,
+  writing: boolean,
+  shards: Pick<IndexGeneration, 'base' | 'shards'> = {}
+): void => {
+  const file = generationFileFor(repoId);
+  const next: IndexGeneration = {
+    generation,
+    ...shards,
+    writing,
+    pid: process.pid,
+    hostname: os.hostname(),
+    updated_at: new Date().toISOString(),
+  };
+  const temp = `${file}.${String(process.pid)}.tmp`;
+  fs.mkdirSync(LOCK_DIR, { recursive: true });
+  fs.writeFileSync(temp, JSON.stringify(next) + '\n');
+  fs.renameSync(temp, file);
+};
+
+/**
+ * Mark a repository as being written (call while holding its write lock)
+ *
+ * @param repoId - Repository ID being written
+ */
+export const beginGeneration = (repoId: string): void => {
+  const current = readGeneration(repoId);
+  writeGeneration(repoId, current?.generation ?? 0, true, { base: current?.base, shards: current?.shards });
+};
+
+/**
+ * Publish a new generation once a writer is done (call before releasing the write lock)
+ *
+ * @param repoId - Repository ID that was written
+ * @param changed - Files rewritten or removed, to publish only their shards (default: every shard)
+ * @returns The new generation number
+ */
+export const publishGeneration = (repoId: string, changed?: string[]): number => {
+  const current = readGeneration(repoId);
+  const generation = (current?.generation ?? 0) + 1;
+  if (!changed) {
+    writeGeneration(repoId, generation, false, { base: generation });
+    return generation;
+  }
+
+  const shards = { ...current?.shards };
+  for (const filePath of changed) shards[String(shardOf(filePath))] = generation;
+  writeGeneration(repoId, generation, false, { base: current?.base ?? current?.generation ?? 0, shards });
+  return generation;
+};
@@ -0,0 +1,172 @@
+/**
+ * Index integrity checks
+ *
+ * Each file record stores how many chunks were written for it and a checksum
+ * of their content. A build that died between the file record and its chunks,
+ * a chunk batch that failed to insert, or rows damaged outside cindex leave a
+ * file whose stored chunks no longer match. Such a file is quarantined: its
+ * chunks and symbols are dropped (so queries never see half a file) and its
+ * record is flagged so the next incremental run rebuilds just that file.
+ *
+ * Records written before chunk counts were stored are not checked.
+ */
+import { createHash } from 'node:crypto';
+
+import { type DatabaseClient } from '@database/client';
+import { deleteStaleData } from '@indexing/incremental';
+import { logger } from '@utils/logger';
+import { type Repository } from '@/types/database';
+
+/**
+ * Why a file's stored chunks failed verification
+ * - truncated: fewer (or more) chunks than were written
+ * - checksum_mismatch: the right number of chunks, but different content
+ */
+export type CorruptionReason = 'truncated' | 'checksum_mismatch';
+
+/**
+ * File whose stored chunks do not match its file record
+ */
+export interface CorruptFile {
+  repo_id: string | null;
+  repo_path: string;
+  file_path: string;
+  expected_chunks: number;
+  stored_chunks: number;
+  reason: CorruptionReason;
+}
+
+/**
+ * Repository to re-index after files were quarantined, with its stored settings
+ */
+export type RebuildTarget = Pick<Repository, 'repo_path'> &
+  Partial<Pick<Repository, 'repo_id' | 'repo_name' | 'repo_type' | 'metadata'>>;
+
+/**
+ * Database row for the integrity query
+ */
+interface IntegrityRow {
+  repo_id: string | null;
+  repo_path: string;
+  file_path: string;
+  chunk_count: number;
+  stored_chunks: number;
+}
+
+/**
+ * Checksum of a file's chunks
+ *
+ * Order-independent (the MD5 of the sorted per-chunk MD5s) so it can be
+ * recomputed in SQL without relying on insertion order. MD5 is used because
+ * PostgreSQL has it built in; it guards against damage, not tampering.
+ *
+ * @param contents - Chunk contents of one file
+ * @returns Hex checksum (the MD5 of an empty string for a file without chunks)
+ */
+export const computeChunkChecksum = (contents: string[]): string => {
+  const digests = contents.map((content) => createHash('md5').update(content).digest('hex')).sort();
+  return createHash('md5').update(digests.join('')).digest('hex');
+};
+
+/**
+ * Find files whose stored chunks do not match their file record
+ *
+ * @param db - Database client
+ * @param repoPath - Restrict to one repository (default: all)
+ * @returns Corrupt files, ordered by repository and path
+ */
+export const findCorruptFiles = async (db: DatabaseClient, repoPath?: string): Promise<CorruptFile[]> => {
+  const query = `
+    SELECT f.repo_id, f.repo_path, f.file_path, f.chunk_count, COUNT(c.id)::int AS stored_chunks
+    FROM code_files f
+    LEFT JOIN code_chunks c ON c.file_path = f.file_path
+    WHERE f.chunk_count IS NOT NULL AND f.quarantined_at IS NULL${repoPath ? ' AND f.repo_path = $1' : ''}
+    GROUP BY f.id
+    HAVING COUNT(c.id) <> f.chunk_count
+        OR md5(COALESCE(string_agg(md5(c.chunk_content), '' ORDER BY md5(c.chunk_content) COLLATE "C"), ''))
+           IS DISTINCT FROM f.chunk_checksum
+    ORDER BY f.repo_path, f.file_path
+  `;
+
+  const result = await db.query<IntegrityRow>(query, repoPath ? [repoPath] : []);
+  return result.rows.map((row) => ({
+    repo_id: row.repo_id,
+    repo_path: row.repo_path,
+    file_path: row.file_path,
+    expected_chunks: row.chunk_count,
+    stored_chunks: row.stored_chunks,
+    reason: row.stored_chunks === row.chunk_count ? 'checksum_mismatch' : 'truncated',
+  }));
+};
+
+/**
+ * Quarantine corrupt files until they are rebuilt
+ *
+ * Drops their chunks and symbols and flags the file records. Incremental
+ * indexing treats a quarantined file as modified, and writing the file again
+ * clears the flag.
+ *
+ * @param db - Database client
+ * @param files - Files to quarantine
+ */
+export const quarantineFiles = async (db: DatabaseClient, files: Pick<CorruptFile, 'file_path'>[]): Promise<void> => {
+  if (files.length === 0) return;
+
+  const filePaths = files.map((file) => file.file_path);
+  await deleteStaleData(db, filePaths);
+  await db.query('UPDATE code_files SET quarantined_at = NOW() WHERE file_path = ANY($1::text[])', [filePaths]);
+};
+
+/**
+ * Verify stored chunks and quarantine the files that fail
+ *
+ * @param db - Database client
+ * @param repoPath - Restrict to one repository (default: all)
+ * @returns Files quarantined by this check
+ */
+export const verifyIndexIntegrity = async (db: DatabaseClient, repoPath?: string): Promise<CorruptFile[]> => {
+  const corrupt = await findCorruptFiles(db, repoPath);
+  if (corrupt.length === 0) return corrupt;
+
+  await quarantineFiles(db, corrupt);
+  logger.warn('Corrupt index data quarantined; the files will be rebuilt on the next incremental run', {
+    files: corrupt.length,
+    truncated: corrupt.filter((file) => file.reason === 'truncated').length,
+    checksum_mismatch: corrupt.filter((file) => file.reason === 'checksum_mismatch').length,
+    examples: corrupt.slice(0, 5).map((file) => file.file_path),
+  });
+  return corrupt;
+};
+
+/**
+ * Repositories to re-index for a set of quarantined files
+ *
+ * Stored name, type, and metadata are returned so the rebuild does not reset
+ * them to indexing defaults.
+ *
+ * @param db - Database client
+ * @param files - Quarantined files
+ * @returns One target per repository path
+ */
+export const findRebuildTargets = async (db: DatabaseClient, files: CorruptFile[]): Promise<RebuildTarget[]> => {
+  const targets = new Map<string, RebuildTarget>();
+  for (const file of files) {
+    if (targets.has(file.repo_path)) continue;
+
+    const result = file.repo_id
+      ? await db.query<Pick<Repository, 'repo_name' | 'repo_type' | 'metadata'>>(
+          'SELECT repo_name, repo_type, metadata FROM repositories WHERE repo_id = $1',
+          [file.repo_id]
+        )
+      : null;
+    const stored = result?.rows[0];
+    targets.set(file.repo_path, {
+      repo_path: file.repo_path,
+      repo_id: file.repo_id ?? undefined,
+      repo_name: stored?.repo_name,
+      repo_type: stored?.repo_type,
+      metadata: stored?.metadata ?? undefined,
+    });
+  }
+  return [...targets.values()];
+};
@@ -5,7 +5,7 @@
  * Implements intelligent strategies to avoid performance issues and embedding pollution.
  *
  * Key Features:
- * - Detect generated/minified files (skip or use structure-only indexing)
+ * - Detect generated/minified files by path, header marker, or content (skip or tag)
  * - Binary file detection and skipping
  * - Structure-only indexing for very large files (>5000 lines)
  * - Section-based chunking for large files (1000-5000 lines)
@@ -14,9 +14,9 @@
  * Performance Target: Handle 10k+ line files without memory issues
  */
 
-import { readFile } from 'node:fs/promises';
-
+import { readSourceFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
 import { type DiscoveredFile } from '@/types/indexing';
 
 /**
@@ -126,18 +126,31 @@ const GENERATED_FILE_PATTERNS = [
   /_generated\./,
   /\.proto\.js$/,
   /\.proto\.ts$/,
+  /\.pb\.(go|cc|h)$/,
+  /_pb2(_grpc)?\.pyi?$/,
+  /\.(g|freezed)\.dart$/,
+  /\.g\.cs$/,
+  /\.designer\.cs$/i,
   // Package lock files
   /package-lock\.json$/,
   /yarn\.lock$/,
   /pnpm-lock\.yaml$/,
-  // Build directories (should be filtered earlier, but double-check)
-  /\/dist\//,
-  /\/build\//,
-  /\/out\//,
-  /\/\.next\//,
-  /\/node_modules\//,
+  // Build directories are excluded by discovery (overridable with EXCLUDE_DIRECTORIES)
 ];
 
+/**
+ * Header markers that generators write at the top of their output
+ */
+const GENERATED_HEADER_PATTERNS = [
+  /^\/\/ Code generated .* DO NOT EDIT\.$/m, // Go convention (go generate, protoc-gen-go, stringer)
+  /@generated\b/, // Meta tooling (Relay, Thrift, Buck)
+  /<auto-generated\b/i, // .NET
+  /\b(?:auto-?generated|automatically generated)\b.*\bdo not (?:edit|modify)\b/i,
+];
+
+/** Lines searched for a generated header marker */
+const GENERATED_HEADER_LINES = 10;
+
 /**
  * Minified code detection patterns
  */
@@ -149,6 +162,9 @@ const MINIFIED_PATTERNS = {
   // Low average line length variance (minified code is uniform)
   MIN_VARIANCE_THRESHOLD: 10,
 
+  // Variance and space checks only apply to long lines (short uniform lines are barrel files, import lists)
+  MIN_AVERAGE_LINE_LENGTH: 120,
+
   // High character density (few spaces)
   MIN_SPACE_RATIO: 0.05, // Less than 5% spaces is suspicious
 };
@@ -191,7 +207,10 @@ export const isGeneratedFile = (filePath: string): boolean => {
  */
 export const isMinifiedCode = (content: string): boolean => {
   const lines = content.split('\n');
-  if (lines.length < 10) return false; // Too small to detect
+  if (lines.length < 10) {
+    // Too few lines for statistics; single-line bundles are one very long line
+    return lines.some((line) => line.length > MINIFIED_PATTERNS.LONG_LINE_THRESHOLD * 4);
+  }
 
   // Check 1: Count very long lines
   const longLines = lines.filter((line) => line.length > MINIFIED_PATTERNS.LONG_LINE_THRESHOLD).length;
@@ -202,6 +221,9 @@ export const isMinifiedCode = (content: string): boolean => {
   // Check 2: Calculate line length variance
   const lineLengths = lines.map((line) => line.length);
   const avgLength = lineLengths.reduce((sum, len) => sum + len, 0) / lineLengths.length;
+  if (avgLength < MINIFIED_PATTERNS.MIN_AVERAGE_LINE_LENGTH) {
+    return false;
+  }
   const variance = lineLengths.reduce((sum, len) => sum + Math.pow(len - avgLength, 2), 0) / lineLengths.length;
 
   if (variance < MINIFIED_PATTERNS.MIN_VARIANCE_THRESHOLD) {
@@ -220,6 +242,53 @@ export const isMinifiedCode = (content: string): boolean => {
   return false;
 };
 
+/**
+ * Find a generator's header marker in the first lines of a file
+ *
+ * @param content - File content
+ * @returns The marker line (trimmed), or null if the header has none
+ */
+export const findGeneratedHeader = (content: string): string | null => {
+  const header = content.split('\n', GENERATED_HEADER_LINES).join('\n');
+  for (const pattern of GENERATED_HEADER_PATTERNS) {
+    const match = pattern.exec(header);
+    if (match) {
+      const start = header.lastIndexOf('\n', match.index) + 1;
+      const end = header.indexOf('\n', match.index);
+      return header.slice(start, end === -1 ? undefined : end).trim();
+    }
+  }
+  return null;
+};
+
+/**
+ * Explain why a file looks generated
+ *
+ * Checks the path (build output, *.pb.go, *_pb2.py), then the header marker,
+ * then (for JavaScript and CSS) minified content.
+ *
+ * @param relativePath - Path relative to repository root
+ * @param content - File content
+ * @returns Reason for skip/tag reporting, or null for hand-written files
+ */
+export const detectGenerated = (relativePath: string, content: string): string | null => {
+  if (isGeneratedFile(relativePath)) {
+    return 'generated file name';
+  }
+
+  const marker = findGeneratedHeader(content);
+  if (marker) {
+    return `header: ${marker.length > 60 ? `${marker.slice(0, 57)}...` : marker}`;
+  }
+
+  // Other languages legitimately have long or uniform lines (data tables, tab indentation)
+  if (/\.(m?js|cjs|css)$/.test(relativePath) && isMinifiedCode(content)) {
+    return 'minified content';
+  }
+
+  return null;
+};
+
 /**
  * Determine file type (normal, generated, minified, binary)
  *
@@ -233,8 +302,8 @@ export const detectFileType = (file: DiscoveredFile, content?: string): FileType
     return 'binary';
   }
 
-  // Check 2: Generated file (by path patterns)
-  if (isGeneratedFile(file.relative_path)) {
+  // Check 2: Generated file (by path patterns, or as detected and tagged during discovery)
+  if (file.generated !== undefined || isGeneratedFile(file.relative_path)) {
     return 'generated';
   }
 
@@ -250,15 +319,19 @@ export const detectFileType = (file: DiscoveredFile, content?: string): FileType
  * Determine file size category
  *
  * @param lineCount - Number of lines in file
+ * @param structureOnlyLines - Line count at which files become very large (default: 5000)
  * @returns File size category
  */
-export const categorizeFileSize = (lineCount: number): FileSizeCategory => {
-  if (lineCount < SIZE_THRESHOLDS.SMALL) {
+export const categorizeFileSize = (
+  lineCount: number,
+  structureOnlyLines: number = SIZE_THRESHOLDS.VERY_LARGE
+): FileSizeCategory => {
+  if (lineCount >= structureOnlyLines) {
+    return 'very-large';
+  } else if (lineCount < SIZE_THRESHOLDS.SMALL) {
     return 'small';
-  } else if (lineCount < SIZE_THRESHOLDS.LARGE) {
-    return 'large';
   } else {
-    return 'very-large';
+    return 'large';
   }
 };
 
@@ -267,19 +340,22 @@ export const categorizeFileSize = (lineCount: number): FileSizeCategory => {
  *
  * Decision tree:
  * 1. Binary files → skip (shouldIndex = false)
- * 2. Generated files → skip or structure-only (configurable)
+ * 2. Generated files → skip, unless discovery tagged them (GENERATED_FILES=tag)
  * 3. Minified files → skip (low semantic value)
  * 4. Very large files (>5000 lines) → structure-only
  * 5. Large files (1000-5000 lines) → section-based chunking
  * 6. Small files (&lt;1000 lines) → normal chunking
  *
+ * The structure-only threshold can be overridden per subtree via
+ * `structure_only_lines` in a .cindex.yaml file.
+ *
  * @param file - Discovered file
  * @param content - File content (optional, for minification detection)
  * @returns Indexing strategy
  */
 export const determineLargeFileStrategy = (file: DiscoveredFile, content?: string): LargeFileStrategy => {
   const fileType = detectFileType(file, content);
-  const category = categorizeFileSize(file.line_count);
+  const category = categorizeFileSize(file.line_count, file.directory_config?.structure_only_lines);
 
   // Binary files: skip
   if (fileType === 'binary') {
@@ -292,8 +368,8 @@ export const determineLargeFileStrategy = (file: DiscoveredFile, content?: strin
     };
   }
 
-  // Generated files: skip (low semantic value for RAG)
-  if (fileType === 'generated') {
+  // Generated files: skip (low semantic value for RAG); tagged files are indexed but hidden from search
+  if (fileType === 'generated' && file.generated === undefined) {
     return {
       category,
       fileType,
@@ -374,15 +450,21 @@ export const extractStructureOnlyMetadata = (content: string): StructureOnlyMeta
   ];
 
   const exportPatterns = [
-    /^export\s+(default\s+)?(class|function|const|let|var|interface|type)\s+(\w+)/,
+    new RegExp(
+      `^export\\s+(default\\s+)?(class|function|const|let|var|interface|type)\\s+(${IDENTIFIER_PATTERN})`,
+      'u'
+    ),
     /^export\s+\{([^}]+)\}/,
     /^module\.exports\s*=/,
   ];
 
   const declarationPatterns = [
-    /^(export\s+)?(default\s+)?(class|function|const|let|var|interface|type|enum)\s+(\w+)/,
-    /^def\s+(\w+)/,
-    /^class\s+(\w+)/,
+    new RegExp(
+      `^(export\\s+)?(default\\s+)?(class|function|const|let|var|interface|type|enum)\\s+(${IDENTIFIER_PATTERN})`,
+      'u'
+    ),
+    new RegExp(`^def\\s+(${IDENTIFIER_PATTERN})`, 'u'),
+    new RegExp(`^class\\s+(${IDENTIFIER_PATTERN})`, 'u'),
   ];
 
   for (const line of lines) {
@@ -458,14 +540,14 @@ export const shouldIndexFile = (file: DiscoveredFile, content?: string): boolean
 /**
  * Read file content safely with error handling
  *
- * Handles encoding errors and returns undefined if file cannot be read.
+ * Transcodes non-UTF-8 files and returns undefined if the file cannot be read.
  *
  * @param absolutePath - Absolute file path
  * @returns File content or undefined if error
  */
 export const readFileContentSafely = async (absolutePath: string): Promise<string | undefined> => {
   try {
-    return await readFile(absolutePath, 'utf-8');
+    return (await readSourceFile(absolutePath)).content;
   } catch (error) {
     logger.warn('Failed to read file content', {
       file: absolutePath,
@@ -0,0 +1,166 @@
+/**
+ * License detection for files and directories
+ *
+ * A file's license comes from its own header when it has one: an SPDX
+ * identifier (`SPDX-License-Identifier: MIT`) or a recognizable license
+ * notice in the leading comment. Otherwise it inherits the license of the
+ * nearest directory (up to the repository root) holding a license file
+ * (LICENSE, COPYING, ...). Only headers count as the file carrying its
+ * license; the compliance report (`cindex licenses`) lists files without one.
+ */
+import * as fs from 'node:fs/promises';
+import * as path from 'node:path';
+
+import { type MemoryTree } from '@indexing/memory-tree';
+import { readTextFile } from '@utils/edge-cases';
+import { logger } from '@utils/logger';
+import { compareStrings } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
+import { type FileLicense, type LicenseFile } from '@/types/indexing';
+
+/** Leading lines searched for an SPDX identifier or license notice */
+export const LICENSE_HEADER_LINES = 30;
+
+/** License file names (LICENSE, LICENCE.md, COPYING.txt, LICENSE-MIT, ...) */
+const LICENSE_FILE_NAME = /^(?:LICEN[CS]E|COPYING|UNLICENSE)(?:[-_][A-Z0-9]+)*(?:\.(?:md|markdown|txt|rst))?$/i;
+
+/** SPDX identifier tag; the expression runs to the end of the line or a comment terminator */
+const SPDX_TAG = /SPDX-License-Identifier:\s*([^\s*][^*]*?)\s*(?:\*\/|-->|#\}|$)/m;
+
+/** Comment markers at the start of a line (stripped before matching notices) */
+const COMMENT_PREFIX = /^\s*(?:\/\/+|\/\*+|\*+\/?|#+|;+|--+|<!--|rem\b)?\s*/i;
+
+/**
+ * License notices, most specific first (matched against whitespace-collapsed text)
+ */
+const LICENSE_NOTICES: { license: string; pattern: RegExp }[] = [
+  { license: 'Apache-2.0', pattern: /Apache License,? Version 2\.0/i },
+  { license: 'AGPL-3.0', pattern: /GNU Affero General Public License[^.]*?version 3/i },
+  { license: 'LGPL-3.0', pattern: /GNU Lesser General Public License[^.]*?version 3/i },
+  { license: 'LGPL-2.1', pattern: /GNU Lesser General Public License[^.]*?version 2\.1/i },
+  { license: 'GPL-3.0', pattern: /GNU General Public License[^.]*?version 3/i },
+  { license: 'GPL-2.0', pattern: /GNU General Public License[^.]*?version 2/i },
+  { license: 'MPL-2.0', pattern: /Mozilla Public License,? (?:Version|v\.) ?2\.0/i },
+  { license: 'ISC', pattern: /Permission to use, copy, modify, and\/or distribute this software for any purpose/i },
+  { license: 'MIT', pattern: /Permission is hereby granted, free of charge/i },
+  { license: 'BSD-3-Clause', pattern: /Redistribution and use in source and binary forms.*Neither the name/i },
+  { license: 'BSD-2-Clause', pattern: /Redistribution and use in source and binary forms/i },
+  { license: 'Unlicense', pattern: /This is free and unencumbered software released into the public domain/i },
+];
+
+/**
+ * Identify a license from its text or notice
+ *
+ * @param text - License file content or a file's leading comment
+ * @returns SPDX identifier, or null if no known license matched
+ */
+export const identifyLicenseText = (text: string): string | null => {
+  const collapsed = text
+    .split('\n')
+    .map((line) => line.replace(COMMENT_PREFIX, ''))
+    .join(' ')
+    .replace(/\s+/g, ' ');
+  return LICENSE_NOTICES.find((notice) => notice.pattern.test(collapsed))?.license ?? null;
+};
+
+/**
+ * Detect a license declared in a file's header
+ *
+ * @param content - File content
+ * @returns License and how it was declared, or null if the file carries none
+ */
+export const detectFileLicense = (content: string): FileLicense | null => {
+  const header = content.split('\n', LICENSE_HEADER_LINES).join('\n');
+
+  const spdx = SPDX_TAG.exec(header);
+  if (spdx?.[1]) return { license: spdx[1], source: 'spdx' };
+
+  const license = identifyLicenseText(header);
+  return license ? { license, source: 'header' } : null;
+};
+
+/**
+ * Check whether a file name is a license file
+ */
+export const isLicenseFileName = (name: string): boolean => LICENSE_FILE_NAME.test(name);
+
+/**
+ * Find the license files governing a set of files
+ *
+ * Every directory holding one of the files, and each of its ancestors up to
+ * the root, is checked once. Several license files in one directory (e.g.
+ * LICENSE-MIT and LICENSE-APACHE) combine into `A OR B`.
+ *
+ * @param repoPath - Repository root
+ * @param relativePaths - Repository-relative paths of the files being indexed
+ * @param tree - Files held in memory, read instead of the root (see @indexing/memory-tree)
+ * @returns License per directory (forward-slash relative path, '' for the root)
+ */
+export const findDirectoryLicenses = async (
+  repoPath: string,
+  relativePaths: string[],
+  tree?: MemoryTree
+): Promise<Map<string, LicenseFile>> => {
+  const directories = new Set<string>();
+  for (const relativePath of relativePaths) {
+    for (let dir = path.posix.dirname(relativePath); !directories.has(dir); dir = path.posix.dirname(dir)) {
+      directories.add(dir);
+      if (dir === '.') break;
+    }
+  }
+
+  const listNames = (dir: string): Promise<string[]> =>
+    tree ? Promise.resolve(tree.fileNames(dir)) : fs.readdir(path.join(repoPath, dir));
+  const readLicense = (file: string): Promise<string> =>
+    tree ? Promise.resolve(tree.readText(file) ?? '') : readTextFile(path.join(repoPath, file));
+
+  const licenses = new Map<string, LicenseFile>();
+  for (const dir of [...directories].sort(compareStrings)) {
+    let names: string[];
+    try {
+      names = (await listNames(dir)).filter(isLicenseFileName).sort(compareStrings);
+    } catch {
+      continue;
+    }
+    if (names.length === 0) continue;
+
+    const identified: string[] = [];
+    for (const name of names) {
+      try {
+        const license = identifyLicenseText(await readLicense(path.posix.join(dir, name)));
+        if (license && !identified.includes(license)) identified.push(license);
+      } catch (error) {
+        logger.debug('Could not read license file', { file: path.join(dir, name), error });
+      }
+    }
+
+    const key = dir === '.' ? '' : dir;
+    licenses.set(key, {
+      path: toPosixPath(path.join(dir, names[0] ?? '')),
+      license: identified.length > 0 ? identified.join(' OR ') : null,
+    });
+  }
+  return licenses;
+};
+
+/**
+ * Resolve the license of a file: its own header, else the nearest directory license
+ *
+ * @param relativePath - Repository-relative path (forward slashes)
+ * @param header - License declared in the file's header (detectFileLicense)
+ * @param directoryLicenses - Licenses per directory (findDirectoryLicenses)
+ * @returns Effective license, or null if neither the file nor a directory declares one
+ */
+export const resolveFileLicense = (
+  relativePath: string,
+  header: FileLicense | null,
+  directoryLicenses: Map<string, LicenseFile>
+): FileLicense | null => {
+  if (header) return header;
+
+  for (let dir = path.posix.dirname(relativePath); ; dir = path.posix.dirname(dir)) {
+    const found = directoryLicenses.get(dir === '.' ? '' : dir);
+    if (found?.license) return { license: found.license, source: 'directory' };
+    if (dir === '.') return null;
+  }
+};
@@ -0,0 +1,148 @@
+/**
+ * Linter reports: parse staticcheck and golangci-lint JSON output (cindex lint)
+ *
+ * Two formats are read:
+ * - `staticcheck -f json ./...`: one JSON object per line, with code,
+ *   severity, location, end, and message
+ * - `golangci-lint run --out-format json` (v1) or `--output.json.path stdout`
+ *   (v2): one object whose Issues list FromLinter, Text, Severity, and Pos
+ *
+ * Rules are the check code (SA4006, ST1003) when the tool reports one, and
+ * the linter name (errcheck, govet) otherwise.
+ */
+
+/**
+ * Tool that wrote a report
+ */
+export type LintTool = 'staticcheck' | 'golangci-lint';
+
+/**
+ * One finding of a linter report
+ */
+export interface LintIssue {
+  /** File as the tool named it (absolute, or relative to where it ran) */
+  file: string;
+  line: number;
+  column: number;
+  end_line: number | null;
+  /** Linter that reported the issue (staticcheck, errcheck, ...) */
+  linter: string;
+  rule: string;
+  severity: string;
+  message: string;
+}
+
+/**
+ * Parsed linter report
+ */
+export interface LintReport {
+  tool: LintTool;
+  issues: LintIssue[];
+}
+
+/** Check code at the start of a golangci-lint message (staticcheck and gocritic style) */
+const CODE_PREFIX = /^([A-Z]{1,4}\d{1,4}):\s*/;
+
+/**
+ * Diagnostic line of `staticcheck -f json`
+ */
+interface StaticcheckDiagnostic {
+  code?: string;
+  severity?: string;
+  location?: { file?: string; line?: number; column?: number };
+  end?: { line?: number };
+  message?: string;
+}
+
+/**
+ * Issue of golangci-lint JSON output
+ */
+interface GolangciIssue {
+  FromLinter?: string;
+  Text?: string;
+  Severity?: string;
+  Pos?: { Filename?: string; Line?: number; Column?: number };
+  LineRange?: { From?: number; To?: number };
+}
+
+/**
+ * Read golangci-lint output (one object with an Issues list, null when clean)
+ */
+const parseGolangci = (report: { Issues?: GolangciIssue[] | null }): LintIssue[] => {
+  return (report.Issues ?? []).flatMap((issue) => {
+    const file = issue.Pos?.Filename;
+    const line = issue.Pos?.Line;
+    if (!file || !line) return [];
+
+    const linter = issue.FromLinter ?? 'golangci-lint';
+    const text = issue.Text ?? '';
+    const code = CODE_PREFIX.exec(text);
+    return [
+      {
+        file,
+        line,
+        column: issue.Pos?.Column ?? 0,
+        end_line: issue.LineRange?.To ?? null,
+        linter,
+        rule: code ? code[1] : linter,
+        // golangci-lint leaves Severity empty unless severity rules are configured
+        severity: issue.Severity || 'warning',
+        message: code ? text.slice(code[0].length) : text,
+      },
+    ];
+  });
+};
+
+/**
+ * Read `staticcheck -f json` output (one diagnostic per line)
+ */
+const parseStaticcheck = (text: string): LintIssue[] => {
+  const issues: LintIssue[] = [];
+  text.split(/\r?\n/).forEach((raw, index) => {
+    if (raw.trim() === '') return;
+    let diagnostic: StaticcheckDiagnostic;
+    try {
+      diagnostic = JSON.parse(raw) as StaticcheckDiagnostic;
+    } catch {
+      throw new Error(`line ${String(index + 1)}: not JSON`);
+    }
+    const file = diagnostic.location?.file;
+    const line = diagnostic.location?.line;
+    if (!file || !line || !diagnostic.code) {
+      throw new Error(`line ${String(index + 1)}: not a staticcheck diagnostic`);
+    }
+    // Suppressed with //lint:ignore, kept in the output for -show-ignored
+    if (diagnostic.severity === 'ignored') return;
+
+    issues.push({
+      file,
+      line,
+      column: diagnostic.location?.column ?? 0,
+      end_line: diagnostic.end?.line || null,
+      linter: 'staticcheck',
+      rule: diagnostic.code,
+      severity: diagnostic.severity ?? 'error',
+      message: diagnostic.message ?? '',
+    });
+  });
+  return issues;
+};
+
+/**
+ * Parse a linter report, detecting its format
+ *
+ * @param text - Output of staticcheck -f json or golangci-lint with JSON output
+ * @returns Tool and issues in report order
+ * @throws {Error} If the text is in neither format
+ */
+export const parseLintReport = (text: string): LintReport => {
+  try {
+    const value = JSON.parse(text) as unknown;
+    if (typeof value === 'object' && value !== null && 'Issues' in value) {
+      return { tool: 'golangci-lint', issues: parseGolangci(value as { Issues?: GolangciIssue[] | null }) };
+    }
+  } catch {
+    // Not a single JSON document: staticcheck writes one per line
+  }
+  return { tool: 'staticcheck', issues: parseStaticcheck(text) };
+};
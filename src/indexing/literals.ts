@@ -0,0 +1,74 @@
+/**
+ * Literal index: string literals and comments, searchable apart from code (cindex search --scope)
+ *
+ *   return nil, errors.New("user not found")   string   user not found
+ *   db.Exec(`INSERT INTO sessions (id) ...`)   string   INSERT INTO sessions (id) ...
+ *   // retry once: the lock may be stale       comment  retry once: the lock may be stale
+ *
+ * Strings are kept as written, escapes included, and comments without their
+ * markers, one literal per line with the position of its opening quote or
+ * comment marker. Doc comments are left out: they describe a declaration and
+ * are searched with it. A run of whole-line comments ending at a declaration,
+ * decorators aside, is its doc comment.
+ */
+import { type AnnotatedSymbol } from '@indexing/annotations';
+import { extractComments, extractStrings } from '@indexing/file-metrics';
+import { utf16ToByteColumn } from '@utils/positions';
+import { type SourceLiteral } from '@/types/indexing';
+
+/** Longest literal text stored; longer literals are cut */
+export const MAX_LITERAL_LENGTH = 1000;
+
+/** Lines allowed between a doc comment and its declaration: decorators, annotations, Rust attributes */
+const DECORATOR = /^\s*(?:@|#\[)/;
+
+/**
+ * Lines of the doc comments of a file's symbols
+ *
+ * @param ownLines - Lines holding nothing but a comment
+ * @param lines - Lines of the file
+ * @param symbols - Symbols of the file
+ */
+const docCommentLines = (ownLines: Set<number>, lines: string[], symbols: AnnotatedSymbol[]): Set<number> => {
+  const documented = new Set<number>();
+  for (const symbol of symbols) {
+    let end = symbol.line_number - 1;
+    while (end > 0 && !ownLines.has(end) && DECORATOR.test(lines[end - 1])) end--;
+    for (let line = end; ownLines.has(line); line--) documented.add(line);
+  }
+  return documented;
+};
+
+/**
+ * Extract the literals of a file
+ *
+ * @param content - File content
+ * @param language - Language of the file
+ * @param symbols - Symbols extracted from the file, whose doc comments are left out (empty keeps every comment)
+ * @returns Non-empty strings and comments in line order
+ */
+export const extractLiterals = (content: string, language: string, symbols: AnnotatedSymbol[]): SourceLiteral[] => {
+  const comments = extractComments(content, language);
+  const strings = extractStrings(content, language);
+  if (comments.length === 0 && strings.length === 0) return [];
+  const lines = content.split('\n');
+  const ownLines = new Set(comments.filter((comment) => comment.own_line).map((comment) => comment.line));
+  const documented = docCommentLines(ownLines, lines, symbols);
+
+  const literal = (kind: SourceLiteral['kind'], line: number, column: number, text: string): SourceLiteral => ({
+    kind,
+    text: text.slice(0, MAX_LITERAL_LENGTH),
+    line,
+    column,
+    byte_column: utf16ToByteColumn(lines[line - 1], column),
+  });
+  const literals = [
+    ...strings.map((string) => literal('string', string.line, string.column, string.text)),
+    ...comments
+      .filter((comment) => !(comment.own_line && documented.has(comment.line)))
+      .map((comment) => ({ ...comment, text: comment.text.replace(/^\s*[/*!#]*\s*/, '').trimEnd() }))
+      .filter((comment) => comment.text !== '')
+      .map((comment) => literal('comment', comment.line, comment.column, comment.text)),
+  ];
+  return literals.sort((a, b) => a.line - b.line || a.column - b.column);
+};
@@ -0,0 +1,218 @@
+/**
+ * Index manifest: a Merkle tree over the file records of one index
+ *
+ * Each directory of the repository is a shard. A shard's content hash covers
+ * the file records directly in it (content hash and chunk checksum of each
+ * file), and its tree hash covers its content hash and the tree hashes of its
+ * subdirectories, so the root hash changes when any file record does.
+ *
+ * The manifest is recorded in repository metadata when an indexing run
+ * completes. Comparing it with one rebuilt from the stored file records finds
+ * the shards a crash or outside damage left different from what the run
+ * wrote: records rewritten, lost, or left over. Chunk-level damage within a
+ * record is found by findCorruptFiles (see @indexing/integrity).
+ */
+import { createHash } from 'node:crypto';
+import * as path from 'node:path';
+
+import { type DatabaseClient } from '@database/client';
+import { compareStrings } from '@utils/ordering';
+
+/** Manifest format version */
+export const MANIFEST_VERSION = 1;
+
+/**
+ * File record covered by a manifest
+ */
+export interface ManifestLeaf {
+  file_path: string;
+  file_hash: string;
+  chunk_checksum: string | null;
+}
+
+/**
+ * One directory of the manifest
+ */
+export interface ManifestShard {
+  /** Hash of the shard's content hash and its subdirectories' tree hashes */
+  hash: string;
+  /** Hash of the file records directly in the directory */
+  content: string;
+  /** File records directly in the directory */
+  files: number;
+}
+
+/**
+ * Manifest of one index, stored as repository metadata.manifest
+ */
+export interface IndexManifest {
+  version: number;
+  /** Tree hash of the repository root */
+  root: string;
+  /** File records covered */
+  files: number;
+  created_at: string;
+  /** Shards by directory ('.' for the root) */
+  shards: Record<string, ManifestShard>;
+}
+
+/**
+ * Why a shard no longer matches the manifest
+ * - changed: its file records differ from those recorded
+ * - missing: its file records are gone
+ * - unexpected: it has file records the manifest does not know
+ */
+export type ShardDamage = 'changed' | 'missing' | 'unexpected';
+
+/**
+ * Shard that differs from the recorded manifest
+ */
+export interface DamagedShard {
+  directory: string;
+  reason: ShardDamage;
+  /** File records the manifest recorded */
+  expected_files: number;
+  /** File records stored now */
+  stored_files: number;
+}
+
+const sha256 = (text: string): string => createHash('sha256').update(text).digest('hex');
+
+/**
+ * Build the manifest of a set of file records
+ *
+ * @param leaves - File records of one index
+ * @param createdAt - Creation time to record (default: now)
+ * @returns Manifest; directories without files of their own are shards too
+ */
+export const buildManifest = (leaves: ManifestLeaf[], createdAt: Date = new Date()): IndexManifest => {
+  const entries = new Map<string, string[]>();
+  const children = new Map<string, Set<string>>();
+  const addDirectory = (directory: string): void => {
+    if (entries.has(directory)) return;
+    entries.set(directory, []);
+    if (directory === '.') return;
+    const parent = path.posix.dirname(directory);
+    addDirectory(parent);
+    children.set(parent, (children.get(parent) ?? new Set()).add(directory));
+  };
+
+  for (const leaf of leaves) {
+    const directory = path.posix.dirname(leaf.file_path);
+    addDirectory(directory);
+    const name = path.posix.basename(leaf.file_path);
+    entries.get(directory)?.push(`${name}\0${leaf.file_hash}\0${leaf.chunk_checksum ?? ''}`);
+  }
+  addDirectory('.');
+
+  // Deepest directories first, so every child's tree hash is known before its parent's
+  const depth = (directory: string): number => (directory === '.' ? 0 : directory.split('/').length);
+  const directories = [...entries.keys()].sort((a, b) => depth(b) - depth(a) || compareStrings(a, b));
+  const shards: Record<string, ManifestShard> = {};
+  for (const directory of directories) {
+    const files = (entries.get(directory) ?? []).sort(compareStrings);
+    const content = sha256(files.join('\n'));
+    const subdirectories = [...(children.get(directory) ?? [])]
+      .sort(compareStrings)
+      .map((child) => `${path.posix.basename(child)}\0${shards[child].hash}`);
+    shards[directory] = { hash: sha256([content, ...subdirectories].join('\n')), content, files: files.length };
+  }
+
+  return {
+    version: MANIFEST_VERSION,
+    root: shards['.'].hash,
+    files: leaves.length,
+    created_at: createdAt.toISOString(),
+    shards: Object.fromEntries(Object.entries(shards).sort(([a], [b]) => compareStrings(a, b))),
+  };
+};
+
+/**
+ * Find the shards whose file records differ from a recorded manifest
+ *
+ * Only shard contents are compared: a directory whose subdirectories differ
+ * is not itself damaged.
+ *
+ * @param recorded - Manifest recorded by the last completed run
+ * @param current - Manifest built from the stored file records
+ * @returns Damaged shards, ordered by directory (none when the root hashes match)
+ */
+export const diffManifests = (recorded: IndexManifest, current: IndexManifest): DamagedShard[] => {
+  if (recorded.root === current.root) return [];
+
+  const directories = new Set([...Object.keys(recorded.shards), ...Object.keys(current.shards)]);
+  const damaged: DamagedShard[] = [];
+  for (const directory of [...directories].sort(compareStrings)) {
+    const expected = recorded.shards[directory] as ManifestShard | undefined;
+    const stored = current.shards[directory] as ManifestShard | undefined;
+    const expectedFiles = expected?.files ?? 0;
+    const storedFiles = stored?.files ?? 0;
+    if (expectedFiles === 0 && storedFiles === 0) continue;
+    if (expected && stored && expected.content === stored.content) continue;
+
+    const reason: ShardDamage = storedFiles === 0 ? 'missing' : expectedFiles === 0 ? 'unexpected' : 'changed';
+    damaged.push({ directory, reason, expected_files: expectedFiles, stored_files: storedFiles });
+  }
+  return damaged;
+};
+
+/**
+ * Read the file records of one index
+ *
+ * @param db - Database client
+ * @param repoId - Index
+ * @returns Leaves ordered by path
+ */
+export const readManifestLeaves = async (db: DatabaseClient, repoId: string): Promise<ManifestLeaf[]> => {
+  const result = await db.query<ManifestLeaf>(
+    'SELECT file_path, file_hash, chunk_checksum FROM code_files WHERE repo_id = $1 ORDER BY file_path',
+    [repoId]
+  );
+  return result.rows;
+};
+
+/**
+ * Record the manifest of an index in its repository metadata
+ *
+ * Called when a run completes. The next run replaces repository metadata
+ * (insertRepository), so an index whose last run did not complete has none.
+ *
+ * @param db - Database client
+ * @param repoId - Index
+ * @returns Manifest recorded
+ */
+export const recordIndexManifest = async (db: DatabaseClient, repoId: string): Promise<IndexManifest> => {
+  const manifest = buildManifest(await readManifestLeaves(db, repoId));
+  await db.query(
+    `UPDATE repositories
+     SET metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('manifest', $2::jsonb)
+     WHERE repo_id = $1`,
+    [repoId, JSON.stringify(manifest)]
+  );
+  return manifest;
+};
+
+/**
+ * Read the manifest recorded for an index
+ *
+ * @param metadata - Repository metadata
+ * @returns Manifest, or null if none was recorded (or in a format this version does not read)
+ */
+export const recordedManifest = (metadata: Record<string, unknown> | null | undefined): IndexManifest | null => {
+  const manifest = metadata?.manifest as IndexManifest | undefined;
+  return manifest?.version === MANIFEST_VERSION && typeof manifest.root === 'string' ? manifest : null;
+};
+
+/**
+ * Drop the recorded manifest from metadata carried into a new run
+ *
+ * Rebuilds pass stored repository metadata back in; its manifest describes
+ * the previous run, and must not survive a run that does not complete.
+ *
+ * @param metadata - Repository metadata for the run
+ * @returns Metadata without the manifest
+ */
+export const withoutManifest = <T extends Record<string, unknown>>(metadata: T): Omit<T, 'manifest'> => {
+  const { manifest: _manifest, ...rest } = metadata;
+  return rest;
+};
@@ -19,7 +19,9 @@
 import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { compareNames } from '@utils/ordering';
 import { type ChunkType } from '@/types/indexing';
 
 /**
@@ -273,7 +275,7 @@ const extractSections = (content: string): MarkdownSection[] => {
  * @throws {Error} If file cannot be read or is invalid UTF-8
  */
 export const parseMarkdownFile = async (filePath: string): Promise<MarkdownDocument> => {
-  const content = await fs.readFile(filePath, 'utf-8');
+  const content = await readTextFile(filePath);
 
   // Parse front matter
   const { metadata, content: contentWithoutFrontMatter } = parseFrontMatter(content);
@@ -381,7 +383,7 @@ export const indexMarkdownFiles = async (dirPath: string, recursive = true): Pro
 
   const indexDirectory = async (currentPath: string): Promise<void> => {
     try {
-      const entries = await fs.readdir(currentPath, { withFileTypes: true });
+      const entries = (await fs.readdir(currentPath, { withFileTypes: true })).sort(compareNames);
 
       for (const entry of entries) {
         const fullPath = path.join(currentPath, entry.name);
@@ -502,7 +504,7 @@ export const findMarkdownFiles = async (rootPath: string): Promise<string[]> =>
 
   const searchDirectory = async (currentPath: string): Promise<void> => {
     try {
-      const entries = await fs.readdir(currentPath, { withFileTypes: true });
+      const entries = (await fs.readdir(currentPath, { withFileTypes: true })).sort(compareNames);
 
       for (const entry of entries) {
         const fullPath = path.join(currentPath, entry.name);
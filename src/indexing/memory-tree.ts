@@ -0,0 +1,68 @@
+/**
+ * Memory trees: the files of a repository held in memory instead of on disk
+ *
+ * A revision index (cindex index --rev) reads the blobs of a commit from git
+ * into a MemoryTree. File discovery, Go module detection, license lookup, and
+ * the read of each file for indexing take their content from the tree where
+ * they would read the repository directory, so nothing is written to disk.
+ */
+
+import * as path from 'node:path';
+
+import { decodeText, type DecodedText } from '@utils/edge-cases';
+import { type MemoryFile } from '@/types/indexing';
+
+/**
+ * Content of a file in a memory tree, with the size and mtime disk reads report
+ */
+export interface MemorySource extends DecodedText {
+  stats: { size: number; mtime: Date };
+}
+
+/**
+ * Files of a repository held in memory, by path
+ */
+export class MemoryTree {
+  private readonly files = new Map<string, MemoryFile>();
+  /** File names by directory ('.' for the root) */
+  private readonly directories = new Map<string, string[]>();
+
+  constructor(files: MemoryFile[]) {
+    for (const file of files) {
+      this.files.set(file.path, file);
+      const directory = path.posix.dirname(file.path);
+      const names = this.directories.get(directory) ?? [];
+      names.push(path.posix.basename(file.path));
+      this.directories.set(directory, names);
+    }
+  }
+
+  /**
+   * Files of the tree, in the order they were given
+   */
+  public list = (): MemoryFile[] => [...this.files.values()];
+
+  /**
+   * Names of the files directly in a directory
+   *
+   * @param directory - Directory relative to the root ('.' for the root)
+   */
+  public fileNames = (directory: string): string[] => [...(this.directories.get(directory) ?? [])];
+
+  /**
+   * Decoded content of a file
+   *
+   * @param relativePath - Path relative to the root
+   * @returns Content, encoding, and stats, or null if the tree has no such file
+   */
+  public read = (relativePath: string): MemorySource | null => {
+    const file = this.files.get(relativePath);
+    if (!file) return null;
+    return { ...decodeText(file.content), stats: { size: file.content.length, mtime: file.modified_time } };
+  };
+
+  /**
+   * Text of a file, or null if the tree has no such file
+   */
+  public readText = (relativePath: string): string | null => this.read(relativePath)?.content ?? null;
+}
@@ -7,31 +7,64 @@
  */
 
 import * as fs from 'node:fs/promises';
+import * as os from 'node:os';
 import * as path from 'node:path';
 
 import { type DatabaseClient } from '@database/client';
+import { listIndexedFiles, listSymbolsWithoutHistory } from '@database/queries';
+import { syncStore } from '@database/store';
 import { type DatabaseWriter } from '@database/writer';
+import { extractAnnotations, type AnnotatedSymbol } from '@indexing/annotations';
 import { type CrossServiceAPICallDetector } from '@indexing/api-call-detector';
 import { type APIEndpointEmbeddingGenerator } from '@indexing/api-embeddings';
 import { type APISpecificationParser } from '@indexing/api-parser';
+import { goBuildConstraint } from '@indexing/build-constraints';
+import { filterChangedSince } from '@indexing/changed-files';
 import { type CodeChunker } from '@indexing/chunker';
 import { type EmbeddingGenerator } from '@indexing/embeddings';
-import { type FileWalker } from '@indexing/file-walker';
+import { selectExtractor, treeSitterExtractor } from '@indexing/extractors';
+import { countLineKinds, isTestPath } from '@indexing/file-metrics';
+import { readDiscoveredFile, type FileWalker } from '@indexing/file-walker';
+import { BLAME_CONCURRENCY, blameFileLines, lastChange } from '@indexing/git-blame';
+import { extractGoCalls, parseGoImports } from '@indexing/go-calls';
+import { extractGoConstants } from '@indexing/go-constants';
+import { extractGoEmbeddings } from '@indexing/go-embeddings';
+import { extractGoInstantiations, extractGoTypeParameters } from '@indexing/go-generics';
+import { loadGoTypes, mergeGoTypeFacts } from '@indexing/go-typed';
+import { detectGoModules, goModuleDependencies, goModuleOf, goModuleWorkspaceId } from '@indexing/go-workspace';
 import { type APIImplementationLinker } from '@indexing/implementation-linker';
-import { detectFileChanges, processIncrementalChanges } from '@indexing/incremental';
+import { detectFileChanges, fetchIndexedFiles, processIncrementalChanges } from '@indexing/incremental';
+import { acquireIndexLock, beginGeneration, publishGeneration } from '@indexing/index-lock';
+import { computeChunkChecksum, verifyIndexIntegrity } from '@indexing/integrity';
 import { determineLargeFileStrategy, extractStructureOnlyMetadata } from '@indexing/large-file-handler';
+import { detectFileLicense, findDirectoryLicenses, resolveFileLicense } from '@indexing/license-detector';
+import { extractLiterals } from '@indexing/literals';
+import { recordIndexManifest, withoutManifest } from '@indexing/manifest';
+import { MemoryTree } from '@indexing/memory-tree';
 import { MetadataExtractor } from '@indexing/metadata';
+import { ParsePool } from '@indexing/parse-pool';
 import { type CodeParser } from '@indexing/parser';
+import { loadPlugins, runAnalyzers } from '@indexing/plugins';
+import { scanForSecrets } from '@indexing/secret-scanner';
 import { type FileSummaryGenerator } from '@indexing/summary';
 import { type SymbolExtractor } from '@indexing/symbols';
+import { nameTokens } from '@indexing/tokenizer';
+import { DEFAULT_IN_FLIGHT_BYTES, runWorkerPool } from '@indexing/worker-pool';
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { filesIndexed, parseErrors, phaseDuration, secondsSince } from '@utils/metrics';
+import { compareNames } from '@utils/ordering';
 import { PerformanceMonitor } from '@utils/performance';
+import { originalByteColumn } from '@utils/positions';
 import { type ProgressTracker } from '@utils/progress';
+import { withSpan, type SpanAttributes } from '@utils/tracing';
 import { type ImplementationSearchHints } from '@/types/api-parsing';
+import { type StoreConfig } from '@/types/config';
 import {
   type CodeChunk as CodeChunkDB,
   type CodeFile,
   type CrossRepoDependency,
+  type FunctionSpanRecord,
   type Repository,
   type RepositoryMetadata,
   type Service,
@@ -42,16 +75,25 @@ import {
 import {
   ChunkType,
   IndexingStage,
+  Language,
+  NodeType,
+  type Annotation,
   type ChunkEmbedding,
   type CodeChunkInput,
   type DiscoveredFile,
   type ExportInfo,
   type ExtractedSymbol,
   type FileSummary,
+  type GoTypeFacts,
+  type GoWorkspaceModule,
   type ImportInfo,
   type IndexingOptions,
   type IndexingStats,
+  type LanguageExtractor,
+  type LicenseFile,
+  type ParsedNode,
   type ParseResult,
+  type Platform,
 } from '@/types/indexing';
 import { type DetectedService } from '@/types/service';
 import { type DetectedWorkspace } from '@/types/workspace';
@@ -61,6 +103,22 @@ import { type DetectedWorkspace } from '@/types/workspace';
  */
 export class IndexingOrchestrator {
   private currentRepoPath = '';
+  private scanSecrets = false;
+  private secretCounts = { findings: 0, files: 0 };
+  private directoryLicenses = new Map<string, LicenseFile>();
+  private parsePool: ParsePool | null = null;
+  /** Files of the run held in memory (options.files), read instead of the repository directory */
+  private tree: MemoryTree | undefined;
+  private pluginSpecs: string[] = [];
+  private store: StoreConfig = { backend: 'postgres' };
+  /** Built-in extractors; plugins claiming an extension come first (see @indexing/extractors) */
+  private readonly extractors: LanguageExtractor[] = [
+    treeSitterExtractor((content, filePath, language) => {
+      if (this.parsePool) return this.parsePool.parse(content, filePath, language);
+      this.parser.setLanguage(language);
+      return Promise.resolve(this.parser.parse(content, filePath));
+    }),
+  ];
   private readonly metadataExtractor: MetadataExtractor;
   private readonly performanceMonitor: PerformanceMonitor;
 
@@ -93,6 +151,28 @@ export class IndexingOrchestrator {
     });
   }
 
+  /**
+   * Load these plugins before indexing (see @indexing/plugins)
+   *
+   * @param specs - PLUGINS entries
+   * @returns The orchestrator
+   */
+  public usePlugins = (specs: string[]): this => {
+    this.pluginSpecs = specs;
+    return this;
+  };
+
+  /**
+   * Copy each completed index to this store (see @database/store)
+   *
+   * @param store - STORE_BACKEND settings
+   * @returns The orchestrator
+   */
+  public useStore = (store: StoreConfig): this => {
+    this.store = store;
+    return this;
+  };
+
   /**
    * Run complete indexing pipeline for a repository
    *
@@ -106,11 +186,25 @@ export class IndexingOrchestrator {
   public indexRepository = async (repoPath: string, options: IndexingOptions): Promise<IndexingStats> => {
     // Store current repo path for use in persistence
     this.currentRepoPath = repoPath;
+    this.scanSecrets = options.scanSecrets ?? false;
+    this.secretCounts = { findings: 0, files: 0 };
+    this.tree = options.files ? new MemoryTree(options.files) : undefined;
 
     // Derive repoId from folder name if not provided
     // This ensures all files are properly linked to the repository for search filtering
     const repoId = options.repoId ?? path.basename(repoPath);
 
+    // Only one process may write a repository at a time
+    const lock = await acquireIndexLock(repoId, options.waitForLock);
+
+    // Files are indexed `jobs` at a time; their parses run on as many worker threads
+    const jobs = Math.max(1, options.jobs ?? os.availableParallelism());
+    const parsePool = new ParsePool(jobs, this.parser);
+    this.parsePool = parsePool;
+
+    // Files an incremental run rewrites or removes; only their shards get a new generation
+    let changedFiles: string[] | undefined;
+
     // Start performance monitoring
     this.performanceMonitor.start();
 
@@ -121,6 +215,12 @@ export class IndexingOrchestrator {
     });
 
     try {
+      // Readers compare generations to tell whether the index changed while they read
+      beginGeneration(repoId);
+
+      // Extractor plugins claim extensions before files are discovered
+      await loadPlugins(this.pluginSpecs);
+
       // Stage 0: Persist repository metadata
       // This must happen before file discovery so files can reference the repository
       const repository: Omit<Repository, 'id' | 'indexed_at' | 'last_updated'> = {
@@ -132,35 +232,61 @@ export class IndexingOrchestrator {
         workspace_patterns: null, // Populated during workspace detection
         root_package_json: null, // Populated during workspace detection
         git_remote_url: null, // Could extract from git, but not critical
-        metadata: options.metadata as RepositoryMetadata | null,
+        metadata: options.metadata ? (withoutManifest(options.metadata) as RepositoryMetadata) : null,
       };
 
       await this.persistRepositoryMetadata(repository);
 
       // Stage 1: File Discovery
+      // Incremental runs skip reading files whose size and mtime match what was indexed
       this.progressTracker.setStage(IndexingStage.Discovering);
-      const discoveredFiles = await this.fileWalker.discoverFiles();
+      this.fileWalker.reuseIndexedFiles(options.incremental ? await fetchIndexedFiles(this.db, repoPath) : new Map());
+      // Files held in memory (a commit's tree) are discovered from the tree instead of the directory
+      const discoveredFiles = this.tree
+        ? this.fileWalker.discoverTree(this.tree)
+        : await this.fileWalker.discoverFiles();
 
       logger.info('Files discovered', {
         count: discoveredFiles.length,
       });
 
+      // Go modules (go.work members, or every go.mod): files are tagged with the module they are in
+      const discoveredPaths = discoveredFiles.map((file) => file.relative_path);
+      const goModules = await detectGoModules(repoPath, discoveredPaths, this.tree);
+      await this.persistGoModules(goModules, repoId);
+
       // Enrich discovered files with repo_id for proper search filtering
       // This ensures all files/chunks are linked to the repository
-      const enrichedFiles = discoveredFiles.map((file) => ({
-        ...file,
-        repo_id: file.repo_id ?? repoId,
-      }));
+      const enrichedFiles = discoveredFiles.map((file) => {
+        const goModule = goModuleOf(goModules, file.relative_path);
+        return {
+          ...file,
+          repo_id: file.repo_id ?? repoId,
+          workspace_id: file.workspace_id ?? (goModule ? goModuleWorkspaceId(repoId, goModule) : undefined),
+          package_name: file.package_name ?? goModule?.module_path,
+          package_directory: file.package_name ? file.package_directory : goModule?.directory,
+        };
+      });
+
+      // License files are looked up for every discovered file, so a file's inherited license
+      // does not depend on which files an incremental run happens to re-index
+      this.directoryLicenses = await findDirectoryLicenses(repoPath, discoveredPaths, this.tree);
 
       // Stage 1.5: Incremental Indexing (if enabled)
       let filesToProcess = enrichedFiles;
+      let filesRemoved: number | undefined;
       if (options.incremental) {
         logger.info('Incremental indexing enabled, detecting changes');
 
+        // Corrupt files are quarantined first so change detection picks them up as modified
+        await verifyIndexIntegrity(this.db, repoPath);
+
         const { changes, stats } = await detectFileChanges(this.db, repoPath, enrichedFiles);
 
         // Process incremental changes (delete stale data)
         const incrementalFiles = await processIncrementalChanges(this.db, changes);
+        changedFiles = [...incrementalFiles.map((file) => file.relative_path), ...changes.deleted];
+        filesRemoved = stats.deleted_files;
 
         // Re-enrich files after incremental processing to ensure repo_id is set
         filesToProcess = incrementalFiles.map((file) => ({
@@ -179,6 +305,19 @@ export class IndexingOrchestrator {
         });
       }
 
+      // Stage 1.55: Time-window filter (--since)
+      // Applied after change detection so files outside the window are never treated as deleted
+      if (options.since) {
+        const { files, source } = await filterChangedSince(repoPath, filesToProcess, options.since);
+        logger.info('Applied --since filter', {
+          since: options.since.toISOString(),
+          source,
+          before: filesToProcess.length,
+          after: files.length,
+        });
+        filesToProcess = files;
+      }
+
       // Stage 1.6: File Validation & Filtering (large file, binary, generated, minified)
       const validatedFiles: typeof filesToProcess = [];
       const structureOnlyFiles: typeof filesToProcess = [];
@@ -230,10 +369,21 @@ export class IndexingOrchestrator {
       // Initialize progress tracker (including structure-only files)
       this.progressTracker.start(filesToProcess.length + structureOnlyFiles.length);
 
-      // Stage 2-7: Process each file through the pipeline
-      for (const file of filesToProcess) {
+      // Stage 2-7: Process files through the pipeline on a bounded worker pool
+      // Back-pressure on the source bytes in flight keeps memory bounded however many jobs run.
+      // On abort (SIGINT/SIGTERM) the files in progress are finished, so every persisted file is complete
+      const poolOptions = {
+        jobs,
+        weight: (file: DiscoveredFile) => file.file_size_bytes,
+        budget: DEFAULT_IN_FLIGHT_BYTES,
+        signal: options.signal,
+      };
+      logger.info('Indexing files', { jobs, workers: parsePool.usesWorkers() ? jobs : 0 });
+
+      await runWorkerPool(filesToProcess, poolOptions, async (file) => {
         try {
-          await this.processFile(file);
+          await withSpan('cindex.index_file', this.spanAttributes(file), () => this.processFile(file));
+          filesIndexed.inc({ repo_id: repoId });
           this.progressTracker.incrementFiles();
         } catch (error) {
           logger.error('File processing failed', {
@@ -250,12 +400,13 @@ export class IndexingOrchestrator {
 
           // Continue with next file
         }
-      }
+      });
 
       // Stage 2-7 (Structure-Only): Process very large files with structure-only indexing
-      for (const file of structureOnlyFiles) {
+      await runWorkerPool(structureOnlyFiles, poolOptions, async (file) => {
         try {
-          await this.processStructureOnlyFile(file);
+          await withSpan('cindex.index_file', this.spanAttributes(file), () => this.processStructureOnlyFile(file));
+          filesIndexed.inc({ repo_id: repoId });
           this.progressTracker.incrementFiles();
         } catch (error) {
           logger.error('Structure-only file processing failed', {
@@ -272,10 +423,54 @@ export class IndexingOrchestrator {
 
           // Continue with next file
         }
-      }
+      });
 
       // Get final statistics
       const stats = this.progressTracker.getStats();
+      stats.unreadable_paths = this.fileWalker.getUnreadablePaths();
+      if (filesRemoved !== undefined) {
+        stats.files_removed = filesRemoved;
+      }
+      if (this.scanSecrets) {
+        stats.secret_findings = this.secretCounts.findings;
+        stats.secret_files = this.secretCounts.files;
+      }
+
+      if (options.signal?.aborted) {
+        // Checkpoint: files persisted so far keep their hashes, so an incremental run resumes from here
+        const remaining = filesToProcess.length + structureOnlyFiles.length - stats.files_processed - stats.files_failed;
+        await this.dbWriter.updateRepositoryCheckpoint(repoId, {
+          interrupted_at: new Date().toISOString(),
+          files_done: stats.files_processed,
+          files_remaining: remaining,
+        });
+        logger.warn('Indexing interrupted; run again with incremental indexing to resume', {
+          repo_id: repoId,
+          files_done: stats.files_processed,
+          files_remaining: remaining,
+        });
+
+        stats.stage = IndexingStage.Interrupted;
+        return stats;
+      }
+
+      // Stage 8: Type facts (--typed); type-checking needs whole packages, so they are always reloaded
+      if (options.typed) {
+        await this.recordGoTypeFacts(repoPath, repoId, stats, options.typedPlatforms);
+        // Type facts of every package were replaced
+        changedFiles = undefined;
+      }
+
+      // Stage 9: Symbol history (--history); only symbols with no recorded change are blamed
+      if (options.history) {
+        const updated = await this.recordSymbolHistory(repoPath, repoId, stats);
+        changedFiles?.push(...updated);
+      }
+
+      // The manifest describes the index this run completed (cindex verify compares against it)
+      await recordIndexManifest(this.db, repoId);
+      await syncStore(this.store, this.db.getPool(), repoId);
+
       stats.stage = IndexingStage.Complete;
 
       // Log performance summary
@@ -294,24 +489,309 @@ export class IndexingOrchestrator {
       const stats = this.progressTracker.getStats();
       stats.stage = IndexingStage.Failed;
       return stats;
+    } finally {
+      this.parsePool = null;
+      this.tree = undefined;
+      await parsePool.close();
+      publishGeneration(repoId, changedFiles);
+      lock.release();
+    }
+  };
+
+  /**
+   * Type-check the repository's Go packages and replace the index's type facts
+   *
+   * A failure is reported in the stats instead of failing the run: the index
+   * is complete without type facts.
+   *
+   * @param repoPath - Repository root
+   * @param repoId - Index being written
+   * @param stats - Run statistics to record the outcome in
+   * @param platforms - Platforms to load the packages for, merged (default: the go command's)
+   */
+  private recordGoTypeFacts = async (
+    repoPath: string,
+    repoId: string,
+    stats: IndexingStats,
+    platforms?: Platform[]
+  ): Promise<void> => {
+    try {
+      const loads: GoTypeFacts[] = [];
+      for (const platform of platforms?.length ? platforms : [undefined]) {
+        loads.push(await loadGoTypes(repoPath, platform));
+      }
+      const loaded = mergeGoTypeFacts(loads);
+
+      // Facts on files the index skipped (excluded, generated, too large) have nothing to attach to
+      const indexed = new Set((await listIndexedFiles(this.db.getPool(), repoId)).map((file) => file.file_path));
+      const facts = {
+        methods: loaded.methods.filter((method) => indexed.has(method.file_path)),
+        implementations: loaded.implementations.filter((row) => indexed.has(row.file_path)),
+        references: loaded.references.filter((row) => indexed.has(row.file_path) && indexed.has(row.target_file)),
+      };
+      await this.dbWriter.replaceGoTypeFacts(repoId, repoPath, facts);
+
+      stats.typed = {
+        methods: facts.methods.length,
+        implementations: facts.implementations.length,
+        references: facts.references.length,
+      };
+      logger.info('Type facts recorded', { repo_id: repoId, ...stats.typed });
+    } catch (error) {
+      stats.typed_error = error instanceof Error ? error.message : String(error);
+      logger.warn('Typed indexing failed', { repo: repoPath, error: stats.typed_error });
+    }
+  };
+
+  /**
+   * Record the last change to each symbol that has none, from git blame
+   *
+   * Symbols of files re-indexed by this run come back without one, so a run
+   * blames the files it changed, and files whose symbols were never blamed.
+   *
+   * @returns Files whose symbols were updated
+   */
+  private recordSymbolHistory = async (repoPath: string, repoId: string, stats: IndexingStats): Promise<string[]> => {
+    const byFile = new Map<string, FunctionSpanRecord[]>();
+    for (const symbol of await listSymbolsWithoutHistory(this.db.getPool(), repoId)) {
+      byFile.set(symbol.file_path, [...(byFile.get(symbol.file_path) ?? []), symbol]);
+    }
+
+    const files = [...byFile.keys()];
+    const updated: string[] = [];
+    let symbols = 0;
+    for (let i = 0; i < files.length; i += BLAME_CONCURRENCY) {
+      const batch = files.slice(i, i + BLAME_CONCURRENCY);
+      const blamed = await Promise.all(batch.map((file) => blameFileLines(repoPath, file)));
+      const history = batch.flatMap((file, index) => {
+        const lines = blamed[index];
+        if (!lines) return [];
+        updated.push(file);
+        return (byFile.get(file) ?? []).flatMap((symbol) => {
+          const change = lastChange(lines, symbol.line_number, symbol.end_line ?? symbol.line_number);
+          return change ? [{ id: symbol.id, change }] : [];
+        });
+      });
+      await this.dbWriter.updateSymbolHistory(history);
+      symbols += history.length;
+    }
+
+    stats.history = { files: updated.length, symbols };
+    logger.info('Symbol history recorded', { repo_id: repoId, ...stats.history });
+    return updated;
+  };
+
+  /**
+   * Scan file content for likely credentials and replace the file's stored findings
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   */
+  private recordSecretFindings = async (file: DiscoveredFile, content: string): Promise<void> => {
+    if (!this.scanSecrets) return;
+
+    const hadUtf8Bom = file.encoding === 'utf-8' && file.bom === true;
+    const findings = scanForSecrets(content).map((finding) => ({
+      ...finding,
+      byte_column: originalByteColumn(finding, hadUtf8Bom),
+    }));
+    await this.dbWriter.replaceSecretFindings(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      findings
+    );
+    if (findings.length > 0) {
+      this.secretCounts.findings += findings.length;
+      this.secretCounts.files++;
+      logger.warn('Likely secrets found', {
+        file: file.relative_path,
+        rules: [...new Set(findings.map((finding) => finding.rule))],
+        count: findings.length,
+      });
+    }
+  };
+
+  /**
+   * Replace the stored calls of a Go file with those in its functions and methods
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   * @param nodes - Top-level nodes from the parser
+   */
+  private recordGoCalls = async (file: DiscoveredFile, content: string, nodes: ParsedNode[]): Promise<void> => {
+    if (file.language !== Language.Go) return;
+
+    const imports = parseGoImports(content);
+    const calls = nodes
+      .filter((node) => node.node_type === NodeType.Function || node.node_type === NodeType.Method)
+      .flatMap((node) => extractGoCalls(node.code_text, node.start_line, imports));
+    await this.dbWriter.replaceGoCalls(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      calls
+    );
+  };
+
+  /**
+   * Replace the stored constants of a Go file with those it declares
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   */
+  private recordGoConstants = async (file: DiscoveredFile, content: string): Promise<void> => {
+    if (file.language !== Language.Go) return;
+
+    await this.dbWriter.replaceGoConstants(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      extractGoConstants(content)
+    );
+  };
+
+  /**
+   * Replace the stored type parameters and explicit instantiations of a Go file
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   */
+  private recordGoGenerics = async (file: DiscoveredFile, content: string): Promise<void> => {
+    if (file.language !== Language.Go) return;
+
+    await this.dbWriter.replaceGoGenerics(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      extractGoTypeParameters(content),
+      extractGoInstantiations(content, parseGoImports(content))
+    );
+  };
+
+  /**
+   * Replace the stored embedded types of a Go file's structs and interfaces
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   */
+  private recordGoEmbeddings = async (file: DiscoveredFile, content: string): Promise<void> => {
+    if (file.language !== Language.Go) return;
+
+    await this.dbWriter.replaceGoEmbeddings(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      extractGoEmbeddings(content, parseGoImports(content))
+    );
+  };
+
+  /**
+   * Replace the stored annotations (TODOs, deprecations, directives, build tags) of a file
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   * @param symbols - Symbols extracted from the file, annotations are attached to
+   * @param reported - Annotations reported by analyzer plugins, stored with those of the comments
+   */
+  private recordAnnotations = async (
+    file: DiscoveredFile,
+    content: string,
+    symbols: AnnotatedSymbol[],
+    reported: Annotation[] = []
+  ): Promise<void> => {
+    await this.dbWriter.replaceAnnotations(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      [...extractAnnotations(content, file.language, symbols), ...reported]
+    );
+  };
+
+  /**
+   * Run the analyzer plugins of a file's language and replace the metrics they reported
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   * @param symbols - Symbols extracted from the file, handed to the analyzers
+   * @returns Annotations the analyzers reported, for recordAnnotations
+   */
+  private recordPluginAnalyses = async (
+    file: DiscoveredFile,
+    content: string,
+    symbols: AnnotatedSymbol[]
+  ): Promise<Annotation[]> => {
+    const analysis = await runAnalyzers({ path: file.relative_path, language: file.language, content }, symbols);
+    await this.dbWriter.replacePluginMetrics(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      analysis.metrics
+    );
+    return analysis.annotations;
+  };
+
+  /**
+   * Replace the stored string literals and comments of a file
+   *
+   * @param file - File being indexed
+   * @param content - Content as read for indexing
+   * @param symbols - Symbols extracted from the file, whose doc comments are not stored
+   */
+  private recordLiterals = async (file: DiscoveredFile, content: string, symbols: AnnotatedSymbol[]): Promise<void> => {
+    await this.dbWriter.replaceLiterals(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      extractLiterals(content, file.language, symbols)
+    );
+  };
+
+  /**
+   * Parse a file with its extractor: the plugin claiming its extension, or tree-sitter (on a
+   * parse worker while indexing a repository)
+   *
+   * @param content - File content
+   * @param file - File metadata
+   * @returns Parse result
+   */
+  private parse = async (content: string, file: DiscoveredFile): Promise<ParseResult> => {
+    const extractor = selectExtractor(file.relative_path, file.language, this.extractors);
+    return extractor.extract(content, file.relative_path, file.language);
+  };
+
+  /**
+   * Span attributes of a file
+   */
+  private spanAttributes = (file: DiscoveredFile): SpanAttributes => ({
+    'code.filepath': file.relative_path,
+    'cindex.language': file.language,
+    'cindex.repo_id': file.repo_id ?? '',
+  });
+
+  /**
+   * Run one phase of indexing a file as a span, timed into cindex_phase_duration_seconds
+   *
+   * @param phase - parse, extract (symbols), or store
+   * @param file - File being indexed
+   * @param work - The phase
+   * @returns Result of the phase
+   */
+  private phase = async <T>(phase: string, file: DiscoveredFile, work: () => Promise<T>): Promise<T> => {
+    const start = performance.now();
+    try {
+      return await withSpan(`cindex.${phase}`, this.spanAttributes(file), work);
+    } finally {
+      phaseDuration.observe(secondsSince(start), { phase });
     }
   };
 
   /**
    * Process a single file through all pipeline stages
    *
-   * @param file - Discovered file metadata
+   * @param discovered - Discovered file metadata
    */
-  private processFile = async (file: DiscoveredFile): Promise<void> => {
-    // Read file content
-    const content = await fs.readFile(file.absolute_path, 'utf-8');
+  private processFile = async (discovered: DiscoveredFile): Promise<void> => {
+    // Read file content (UTF-8 from its detected encoding, NFC so identifiers match queries in either form)
+    const { file, content } = await readDiscoveredFile(discovered, this.tree);
+    await this.recordSecretFindings(file, content);
+    file.license = resolveFileLicense(file.relative_path, detectFileLicense(content), this.directoryLicenses);
+    file.build_constraint = file.language === Language.Go ? goBuildConstraint(file.relative_path, content) : null;
+    file.line_counts = countLineKinds(content, file.language);
 
     // Stage 2: Parse
     this.progressTracker.setStage(IndexingStage.Parsing);
     const parseMetricId = this.performanceMonitor.startStage('parsing', file.relative_path);
-    const parseResult = this.parser.parse(content, file.relative_path);
+    const parseResult = await this.phase('parse', file, () => this.parse(content, file));
     this.performanceMonitor.endStage(parseMetricId);
 
+    if (parseResult.used_fallback || parseResult.partial || !parseResult.success) {
+      parseErrors.inc({ language: file.language });
+    }
     if (!parseResult.success && !parseResult.used_fallback) {
       throw new Error(`Parsing failed: ${parseResult.error ?? 'unknown error'}`);
     }
@@ -347,22 +827,34 @@ export class IndexingOrchestrator {
     // Stage 6: Extract symbols
     this.progressTracker.setStage(IndexingStage.Symbols);
     const symbolsMetricId = this.performanceMonitor.startStage('symbols', file.relative_path);
-    const symbols = await this.symbolExtractor.extractSymbols(parseResult, file);
+    const symbols = await this.phase('extract', file, () => this.symbolExtractor.extractSymbols(parseResult, file));
     this.performanceMonitor.endStage(symbolsMetricId, symbols.length);
     this.progressTracker.incrementSymbols(symbols.length);
 
     // Stage 7: Persist to database
     this.progressTracker.setStage(IndexingStage.Persisting);
     const persistMetricId = this.performanceMonitor.startStage('persistence', file.relative_path);
-    await this.persistFileData(
-      file,
-      parseResult,
-      summary,
-      summaryEmbedding,
-      chunkingResult.chunks,
-      chunkEmbeddings,
-      symbols
-    );
+    await this.phase('store', file, async () => {
+      await this.persistFileData(
+        file,
+        parseResult,
+        summary,
+        summaryEmbedding,
+        chunkingResult.chunks,
+        chunkEmbeddings,
+        symbols
+      );
+      await this.recordGoCalls(file, content, parseResult.nodes);
+      await this.recordGoConstants(file, content);
+      await this.recordGoGenerics(file, content);
+      await this.recordGoEmbeddings(file, content);
+      await this.recordAnnotations(file, content, symbols, await this.recordPluginAnalyses(file, content, symbols));
+      await this.recordLiterals(file, content, symbols);
+      await this.dbWriter.replaceFileContent(
+        { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+        content
+      );
+    });
     this.performanceMonitor.endStage(persistMetricId);
   };
 
@@ -374,11 +866,15 @@ export class IndexingOrchestrator {
    * the file structure. This avoids the overhead of detailed parsing, chunking,
    * and symbol extraction while still making the file discoverable via search.
    *
-   * @param file - Discovered file
+   * @param discovered - Discovered file metadata
    */
-  private processStructureOnlyFile = async (file: DiscoveredFile): Promise<void> => {
-    // Read file content
-    const content = await fs.readFile(file.absolute_path, 'utf-8');
+  private processStructureOnlyFile = async (discovered: DiscoveredFile): Promise<void> => {
+    // Read file content (UTF-8, NFC)
+    const { file, content } = await readDiscoveredFile(discovered, this.tree);
+    await this.recordSecretFindings(file, content);
+    file.license = resolveFileLicense(file.relative_path, detectFileLicense(content), this.directoryLicenses);
+    file.build_constraint = file.language === Language.Go ? goBuildConstraint(file.relative_path, content) : null;
+    file.line_counts = countLineKinds(content, file.language);
 
     // Extract structure metadata (imports, exports, declarations)
     this.progressTracker.setStage(IndexingStage.Parsing);
@@ -487,6 +983,17 @@ export class IndexingOrchestrator {
       [chunkEmbedding],
       []
     );
+    // Bodies are not parsed for structure-only files: clear calls from an earlier full index
+    await this.recordGoCalls(file, content, []);
+    await this.recordGoConstants(file, content);
+    await this.recordGoGenerics(file, content);
+    await this.recordGoEmbeddings(file, content);
+    await this.recordAnnotations(file, content, [], await this.recordPluginAnalyses(file, content, []));
+    await this.recordLiterals(file, content, []);
+    await this.dbWriter.replaceFileContent(
+      { repo_id: file.repo_id ?? null, repo_path: this.currentRepoPath, file_path: file.relative_path },
+      content
+    );
 
     this.performanceMonitor.endStage(persistMetricId);
 
@@ -539,6 +1046,27 @@ export class IndexingOrchestrator {
       workspace_id: file.workspace_id ?? null,
       package_name: file.package_name ?? null,
       service_id: file.service_id ?? null,
+      parse_error:
+        parseResult.used_fallback || parseResult.partial ? (parseResult.error ?? 'Fallback parser used') : null,
+      parse_error_line: parseResult.error_position?.line ?? null,
+      parse_error_column: parseResult.error_position?.column ?? null,
+      parse_error_byte_column: parseResult.error_position
+        ? originalByteColumn(parseResult.error_position, file.encoding === 'utf-8' && file.bom === true)
+        : null,
+      parse_partial: parseResult.partial ?? false,
+      encoding: file.encoding === 'utf-8' ? null : file.encoding,
+      generated: file.generated !== undefined,
+      license: file.license?.license ?? null,
+      license_source: file.license?.source ?? null,
+      build_constraint: file.build_constraint ?? null,
+      file_size: file.file_size_bytes,
+      file_mtime_ms: file.modified_time.getTime(),
+      code_lines: file.line_counts?.code ?? null,
+      comment_lines: file.line_counts?.comment ?? null,
+      blank_lines: file.line_counts?.blank ?? null,
+      is_test: isTestPath(file.relative_path),
+      chunk_count: chunks.length,
+      chunk_checksum: computeChunkChecksum(chunks.map((chunk) => chunk.chunk_content)),
     };
 
     await this.dbWriter.insertFile(codeFile);
@@ -565,6 +1093,14 @@ export class IndexingOrchestrator {
       symbol_type: symbol.symbol_type,
       file_path: symbol.file_path,
       line_number: symbol.line_number,
+      end_line: symbol.end_line,
+      complexity: symbol.complexity ?? null,
+      cognitive_complexity: symbol.cognitive_complexity ?? null,
+      clone_signature: symbol.clone_signature ?? null,
+      clone_tokens: symbol.clone_tokens ?? null,
+      doc_comment: symbol.doc_comment ?? null,
+      name_tokens: nameTokens(symbol.symbol_name, file.language),
+      symbol_id: symbol.symbol_id,
       definition: symbol.definition,
       embedding: symbol.embedding,
       repo_id: symbol.repo_id ?? null,
@@ -595,6 +1131,40 @@ export class IndexingOrchestrator {
     await this.dbWriter.insertRepository(repo);
   };
 
+  /**
+   * Persist the Go modules of a repository as workspaces, with the dependencies between them
+   *
+   * @param modules - Modules detected in the repository
+   * @param repoId - Repository ID
+   */
+  private persistGoModules = async (modules: GoWorkspaceModule[], repoId: string): Promise<void> => {
+    if (modules.length > 0) logger.info('Detected Go modules', { repo_id: repoId, modules: modules.length });
+
+    await this.dbWriter.replaceGoModules(
+      repoId,
+      modules.map((module) => ({
+        repo_id: repoId,
+        workspace_id: goModuleWorkspaceId(repoId, module),
+        package_name: module.module_path,
+        workspace_path: module.directory,
+        package_json_path: path.posix.join(module.directory, 'go.mod'),
+        version: module.go_version,
+        dependencies: Object.fromEntries(module.requires.map((required) => [required.path, required.version])),
+        dev_dependencies: null,
+        tsconfig_paths: null,
+        metadata: { language: 'go' },
+      })),
+      goModuleDependencies(modules).map((dependency) => ({
+        repo_id: repoId,
+        source_workspace_id: goModuleWorkspaceId(repoId, dependency.from),
+        target_workspace_id: goModuleWorkspaceId(repoId, dependency.to),
+        dependency_type: 'go_module',
+        version_specifier: dependency.version,
+        metadata: null,
+      }))
+    );
+  };
+
   /**
    * Persist workspace data for monorepo support
    *
@@ -923,7 +1493,7 @@ export class IndexingOrchestrator {
 
     const traverse = async (dir: string) => {
       try {
-        const entries = await fs.readdir(dir, { withFileTypes: true });
+        const entries = (await fs.readdir(dir, { withFileTypes: true })).sort(compareNames);
 
         for (const entry of entries) {
           const fullPath = `${dir}/${entry.name}`;
@@ -986,7 +1556,7 @@ export class IndexingOrchestrator {
 
       for (const filePath of serviceCodeFiles) {
         try {
-          const content = await fs.readFile(filePath, 'utf-8');
+          const content = await readTextFile(filePath);
           const language = this.detectLanguage(filePath);
 
           const detectedCalls = this.apiCallDetector.detectCalls(filePath, content, language);
@@ -1040,7 +1610,7 @@ export class IndexingOrchestrator {
 
     const traverse = async (dir: string) => {
       try {
-        const entries = await fs.readdir(dir, { withFileTypes: true });
+        const entries = (await fs.readdir(dir, { withFileTypes: true })).sort(compareNames);
 
         for (const entry of entries) {
           const fullPath = `${dir}/${entry.name}`;
@@ -0,0 +1,696 @@
+/**
+ * Packed content index (cindex export --format=packed, cindex grep --packed)
+ *
+ * A packed index holds the contents of one index's files and a trigram index
+ * over them in a single file laid out to be searched in place. Opening it
+ * reads only the trailer and the block directory, a few bytes per block; a
+ * search then reads, decompresses, and caches the blocks it touches, so
+ * resident memory follows the blocks in use rather than the size of the
+ * file. Node.js cannot mmap(2) a file, so blocks are paged in the way a
+ * mapping would page them, by positional reads served from the OS page
+ * cache, into a cache of decompressed blocks of at most PACKED_CACHE_BYTES.
+ *
+ * Layout, after PACKED_MAGIC:
+ *
+ * - blocks: zstd frames, section by section in this order
+ *   - contents: file contents (UTF-8) as one stream cut into blocks of PACKED_BLOCK_SIZE bytes
+ *   - strings: interned directory paths and file names, each a varint length
+ *     and its bytes, PACKED_RECORDS_PER_BLOCK per block
+ *   - files: in the order they were added, each the varint string IDs of its
+ *     directory and name and its content length, PACKED_RECORDS_PER_BLOCK per
+ *     block (content offsets are the running sum of lengths)
+ *   - postings: for each trigram, the IDs of the files holding it as varint
+ *     deltas, one stream cut into blocks of PACKED_BLOCK_SIZE bytes
+ *   - trigrams: the trigram table in trigram order, each a varint delta from
+ *     the previous trigram, its file count, and its postings length,
+ *     PACKED_RECORDS_PER_BLOCK per block
+ * - directory: one zstd frame of varints: the metadata JSON, the compressed
+ *   length of every block of each section, the content offset of the first
+ *   file of each files block, and the first trigram and postings offset of
+ *   each trigram block
+ * - trailer: directory offset (uint64 LE), directory length (uint32 LE), and PACKED_MAGIC
+ *
+ * Trigrams are the byte triples of content with ASCII letters lowercased,
+ * so one index serves case-sensitive and case-insensitive searches; triples
+ * across a line break are left out, since patterns match within a line.
+ * Candidate files are matched line by line by the search (see
+ * @retrieval/content-search). Needs Node.js 22.15 or later, the first with zstd.
+ */
+
+import * as fs from 'node:fs/promises';
+import * as zlib from 'node:zlib';
+
+import { type Pool } from 'pg';
+
+import { listIndexContents } from '@database/queries';
+import { PackedIndexError } from '@utils/errors';
+import { pushVarint, requireZstd } from '@utils/zstd';
+
+/** First and last bytes of a packed index */
+export const PACKED_MAGIC = Buffer.from('CIDXPK1\n');
+
+/** Uncompressed bytes of a contents or postings block */
+export const PACKED_BLOCK_SIZE = 64 * 1024;
+
+/** Records of a strings, files, or trigrams block */
+export const PACKED_RECORDS_PER_BLOCK = 1024;
+
+/** Decompressed bytes kept in the block cache of an open packed index (default) */
+export const PACKED_CACHE_BYTES = 64 * 1024 * 1024;
+
+/** zstd level of packed blocks, as for compact snapshots */
+const PACKED_LEVEL = 9;
+
+/** Directory offset, directory length, and magic */
+const TRAILER_BYTES = 8 + 4 + PACKED_MAGIC.length;
+
+/** Files read from the database per query while exporting */
+const EXPORT_PAGE_FILES = 200;
+
+/** Sections of a packed index, in the order their blocks are written */
+const SECTIONS = ['contents', 'strings', 'files', 'postings', 'trigrams'] as const;
+
+/**
+ * Section of a packed index
+ */
+type PackedSection = (typeof SECTIONS)[number];
+
+/**
+ * Metadata recorded in the directory of a packed index
+ */
+export interface PackedIndexMetadata {
+  /** Index the files were exported from */
+  repo_id: string;
+  /** ISO timestamp */
+  created_at: string;
+  files: number;
+  content_bytes: number;
+  trigrams: number;
+}
+
+/**
+ * File of a packed index
+ */
+export interface PackedFile {
+  /** Position in the index: files are searched in this order */
+  id: number;
+  file_path: string;
+  content_offset: number;
+  content_length: number;
+}
+
+/**
+ * Options of an open packed index
+ */
+export interface PackedIndexOptions {
+  /** Decompressed bytes kept in the block cache (default: PACKED_CACHE_BYTES) */
+  cacheBytes?: number;
+}
+
+/**
+ * Reader of the varints and byte strings of a decompressed block
+ */
+class VarintCursor {
+  private offset = 0;
+
+  constructor(private readonly buffer: Buffer) {}
+
+  /**
+   * Read a varint
+   *
+   * @throws {PackedIndexError} If the block ends inside it or it is over 8 bytes
+   */
+  public next = (): number => {
+    let value = 0;
+    for (let shift = 0; shift < 8 && this.offset < this.buffer.length; shift++) {
+      const byte = this.buffer[this.offset++];
+      value += (byte & 0x7f) * 2 ** (7 * shift);
+      if (byte < 0x80) return value;
+    }
+    throw new PackedIndexError('Invalid varint in packed index');
+  };
+
+  /**
+   * Read bytes
+   *
+   * @throws {PackedIndexError} If the block ends first
+   */
+  public bytes = (length: number): Buffer => {
+    if (this.offset + length > this.buffer.length) throw new PackedIndexError('Packed index record is truncated');
+    const bytes = this.buffer.subarray(this.offset, this.offset + length);
+    this.offset += length;
+    return bytes;
+  };
+}
+
+/**
+ * Check whether a byte can be part of an indexed trigram (no line break; ASCII only for case-insensitive lookups)
+ */
+const isTrigramByte = (byte: number, asciiOnly: boolean): boolean => byte !== 0x0a && (!asciiOnly || byte < 0x80);
+
+/**
+ * Trigrams of text: its byte triples with ASCII letters lowercased, as 24-bit numbers
+ *
+ * @param bytes - UTF-8 text
+ * @param asciiOnly - Leave out triples with non-ASCII bytes (their case folding is not ASCII's)
+ */
+const trigramsOf = (bytes: Buffer, asciiOnly = false): Set<number> => {
+  const trigrams = new Set<number>();
+  const fold = (byte: number): number => (byte >= 0x41 && byte <= 0x5a ? byte + 0x20 : byte);
+  for (let i = 0; i + 2 < bytes.length; i++) {
+    const [a, b, c] = [bytes[i], bytes[i + 1], bytes[i + 2]];
+    if (!isTrigramByte(a, asciiOnly) || !isTrigramByte(b, asciiOnly) || !isTrigramByte(c, asciiOnly)) continue;
+    trigrams.add(fold(a) * 0x10000 + fold(b) * 0x100 + fold(c));
+  }
+  return trigrams;
+};
+
+/**
+ * IDs of the files holding a trigram, delta-encoded as files are added
+ */
+class PostingList {
+  private bytes = Buffer.alloc(16);
+  private size = 0;
+  private last = 0;
+  public count = 0;
+
+  public add = (id: number): void => {
+    if (this.size + 8 > this.bytes.length) {
+      const grown = Buffer.alloc(this.bytes.length * 2);
+      this.bytes.copy(grown, 0, 0, this.size);
+      this.bytes = grown;
+    }
+    let rest = id - this.last;
+    while (rest >= 0x80) {
+      this.bytes[this.size++] = (rest % 0x80) | 0x80;
+      rest = Math.floor(rest / 0x80);
+    }
+    this.bytes[this.size++] = rest;
+    this.last = id;
+    this.count++;
+  };
+
+  public encoded = (): Buffer => this.bytes.subarray(0, this.size);
+}
+
+/**
+ * Writer of a packed index
+ *
+ * Contents are compressed as files are added; strings, file records, and
+ * postings are held until end() writes them, the directory, and the trailer.
+ */
+export class PackedIndexWriter {
+  private written = 0;
+  private contentBytes = 0;
+  private readonly blockLengths: Record<PackedSection, number[]> = {
+    contents: [],
+    strings: [],
+    files: [],
+    postings: [],
+    trigrams: [],
+  };
+  /** Uncompressed bytes of the contents and postings streams not yet in a block */
+  private readonly pending: Record<'contents' | 'postings', { parts: Buffer[]; size: number }> = {
+    contents: { parts: [], size: 0 },
+    postings: { parts: [], size: 0 },
+  };
+  private readonly strings = new Map<string, number>();
+  private readonly files: { directory: number; name: number; length: number }[] = [];
+  private readonly postings = new Map<number, PostingList>();
+
+  /**
+   * @param write - Write bytes to the file, in order
+   * @param repoId - Index the files come from
+   * @throws {PackedIndexError} If zstd is unavailable
+   */
+  constructor(
+    private readonly write: (bytes: Buffer) => Promise<void>,
+    private readonly repoId: string
+  ) {
+    requireZstd('Packed indexes', PackedIndexError);
+  }
+
+  /**
+   * Add a file
+   *
+   * @param filePath - Path as stored in the index
+   * @param content - Content as indexed
+   */
+  public add = async (filePath: string, content: string): Promise<void> => {
+    const bytes = Buffer.from(content);
+    const slash = filePath.lastIndexOf('/');
+    const id = this.files.length;
+    this.files.push({
+      directory: this.intern(slash === -1 ? '' : filePath.slice(0, slash)),
+      name: this.intern(filePath.slice(slash + 1)),
+      length: bytes.length,
+    });
+    for (const trigram of trigramsOf(bytes)) {
+      let list = this.postings.get(trigram);
+      if (!list) {
+        list = new PostingList();
+        this.postings.set(trigram, list);
+      }
+      list.add(id);
+    }
+    await this.append('contents', bytes);
+    this.contentBytes += bytes.length;
+  };
+
+  /**
+   * Write the held sections, the directory, and the trailer
+   *
+   * @returns Metadata of the index written
+   */
+  public end = async (): Promise<PackedIndexMetadata> => {
+    await this.flush('contents');
+
+    const strings = [...this.strings.keys()];
+    await this.putRecords('strings', strings.length, (index, out) => {
+      const bytes = Buffer.from(strings[index]);
+      pushVarint(out, bytes.length);
+      for (const byte of bytes) out.push(byte);
+    });
+
+    const fileOffsets: number[] = [];
+    let contentOffset = 0;
+    await this.putRecords('files', this.files.length, (index, out) => {
+      if (index % PACKED_RECORDS_PER_BLOCK === 0) fileOffsets.push(contentOffset);
+      const file = this.files[index];
+      pushVarint(out, file.directory);
+      pushVarint(out, file.name);
+      pushVarint(out, file.length);
+      contentOffset += file.length;
+    });
+
+    const trigrams = [...this.postings.keys()].sort((a, b) => a - b);
+    const blockStarts: { trigram: number; postings: number }[] = [];
+    let postingsOffset = 0;
+    const entries: { delta: number; count: number; length: number }[] = [];
+    for (const [index, trigram] of trigrams.entries()) {
+      const list = this.postings.get(trigram) as PostingList;
+      const encoded = list.encoded();
+      if (index % PACKED_RECORDS_PER_BLOCK === 0) blockStarts.push({ trigram, postings: postingsOffset });
+      const previous = index % PACKED_RECORDS_PER_BLOCK === 0 ? trigram : trigrams[index - 1];
+      entries.push({ delta: trigram - previous, count: list.count, length: encoded.length });
+      await this.append('postings', encoded);
+      postingsOffset += encoded.length;
+    }
+    await this.flush('postings');
+    await this.putRecords('trigrams', entries.length, (index, out) => {
+      pushVarint(out, entries[index].delta);
+      pushVarint(out, entries[index].count);
+      pushVarint(out, entries[index].length);
+    });
+
+    const metadata: PackedIndexMetadata = {
+      repo_id: this.repoId,
+      created_at: new Date().toISOString(),
+      files: this.files.length,
+      content_bytes: this.contentBytes,
+      trigrams: trigrams.length,
+    };
+    const directory: number[] = [];
+    const json = Buffer.from(JSON.stringify(metadata));
+    pushVarint(directory, json.length);
+    for (const byte of json) directory.push(byte);
+    for (const section of SECTIONS) {
+      pushVarint(directory, this.blockLengths[section].length);
+      for (const length of this.blockLengths[section]) pushVarint(directory, length);
+    }
+    for (const offset of fileOffsets) pushVarint(directory, offset);
+    for (const start of blockStarts) {
+      pushVarint(directory, start.trigram);
+      pushVarint(directory, start.postings);
+    }
+
+    const directoryOffset = this.written;
+    const compressed = this.compress(Buffer.from(directory));
+    await this.put(compressed);
+    const trailer = Buffer.alloc(TRAILER_BYTES);
+    trailer.writeBigUInt64LE(BigInt(directoryOffset), 0);
+    trailer.writeUInt32LE(compressed.length, 8);
+    PACKED_MAGIC.copy(trailer, 12);
+    await this.put(trailer);
+    return metadata;
+  };
+
+  /**
+   * ID of a string, interning it on first use
+   */
+  private intern = (text: string): number => {
+    let id = this.strings.get(text);
+    if (id === undefined) {
+      id = this.strings.size;
+      this.strings.set(text, id);
+    }
+    return id;
+  };
+
+  private compress = (bytes: Buffer): Buffer =>
+    zlib.zstdCompressSync(bytes, { params: { [zlib.constants.ZSTD_c_compressionLevel]: PACKED_LEVEL } });
+
+  /**
+   * Write bytes, after the magic at the start of the file
+   */
+  private put = async (bytes: Buffer): Promise<void> => {
+    if (this.written === 0) {
+      await this.write(PACKED_MAGIC);
+      this.written = PACKED_MAGIC.length;
+    }
+    await this.write(bytes);
+    this.written += bytes.length;
+  };
+
+  /**
+   * Compress and write one block of a section
+   */
+  private putBlock = async (section: PackedSection, bytes: Buffer): Promise<void> => {
+    const block = this.compress(bytes);
+    await this.put(block);
+    this.blockLengths[section].push(block.length);
+  };
+
+  /**
+   * Write the records of a section, PACKED_RECORDS_PER_BLOCK per block
+   */
+  private putRecords = async (
+    section: PackedSection,
+    count: number,
+    encode: (index: number, out: number[]) => void
+  ): Promise<void> => {
+    for (let start = 0; start < count; start += PACKED_RECORDS_PER_BLOCK) {
+      const out: number[] = [];
+      for (let index = start; index < Math.min(count, start + PACKED_RECORDS_PER_BLOCK); index++) encode(index, out);
+      await this.putBlock(section, Buffer.from(out));
+    }
+  };
+
+  /**
+   * Append bytes to a stream, writing each block as it fills
+   */
+  private append = async (stream: 'contents' | 'postings', bytes: Buffer): Promise<void> => {
+    const pending = this.pending[stream];
+    let rest = bytes;
+    while (rest.length > 0) {
+      const part = rest.subarray(0, PACKED_BLOCK_SIZE - pending.size);
+      pending.parts.push(part);
+      pending.size += part.length;
+      rest = rest.subarray(part.length);
+      if (pending.size === PACKED_BLOCK_SIZE) await this.flush(stream);
+    }
+  };
+
+  /**
+   * Write the bytes of a stream not yet in a block
+   */
+  private flush = async (stream: 'contents' | 'postings'): Promise<void> => {
+    const pending = this.pending[stream];
+    if (pending.size === 0) return;
+    await this.putBlock(stream, Buffer.concat(pending.parts, pending.size));
+    pending.parts = [];
+    pending.size = 0;
+  };
+}
+
+/**
+ * Positions of the blocks of a packed index, read from its directory
+ */
+interface PackedLayout {
+  blocks: Record<PackedSection, { offset: number; length: number }[]>;
+  /** Content offset of the first file of each files block */
+  fileOffsets: number[];
+  /** First trigram and postings offset of each trigram block */
+  trigramStarts: { trigram: number; postings: number }[];
+}
+
+/**
+ * Packed index opened for searching
+ *
+ * Blocks are read on demand and kept in a least recently used cache.
+ */
+export class PackedIndex {
+  private readonly cache = new Map<string, Buffer>();
+  private cachedBytes = 0;
+
+  constructor(
+    private readonly handle: fs.FileHandle,
+    public readonly metadata: PackedIndexMetadata,
+    private readonly layout: PackedLayout,
+    private readonly cacheBytes: number
+  ) {}
+
+  /**
+   * File by ID
+   *
+   * @throws {PackedIndexError} If the ID is out of range
+   */
+  public file = async (id: number): Promise<PackedFile> => {
+    if (!Number.isInteger(id) || id < 0 || id >= this.metadata.files) {
+      throw new PackedIndexError(`No file ${String(id)} in packed index`);
+    }
+    const blockIndex = Math.floor(id / PACKED_RECORDS_PER_BLOCK);
+    const cursor = new VarintCursor(await this.readBlock('files', blockIndex));
+    let offset = this.layout.fileOffsets[blockIndex];
+    for (let index = blockIndex * PACKED_RECORDS_PER_BLOCK; ; index++) {
+      const [directory, name, length] = [cursor.next(), cursor.next(), cursor.next()];
+      if (index === id) {
+        const [dir, base] = await Promise.all([this.string(directory), this.string(name)]);
+        return { id, file_path: dir ? `${dir}/${base}` : base, content_offset: offset, content_length: length };
+      }
+      offset += length;
+    }
+  };
+
+  /**
+   * Content of a file
+   */
+  public readContent = async (file: PackedFile): Promise<string> =>
+    (await this.readStream('contents', file.content_offset, file.content_length)).toString('utf-8');
+
+  /**
+   * IDs of the files holding a trigram, in file order
+   *
+   * @param trigram - Three bytes as a 24-bit number (ASCII letters lowercase)
+   */
+  public postings = async (trigram: number): Promise<number[]> => {
+    const starts = this.layout.trigramStarts;
+    let low = 0;
+    let high = starts.length - 1;
+    while (low <= high) {
+      const middle = (low + high) >> 1;
+      if (starts[middle].trigram <= trigram) low = middle + 1;
+      else high = middle - 1;
+    }
+    if (high < 0) return [];
+
+    const cursor = new VarintCursor(await this.readBlock('trigrams', high));
+    const entries = Math.min(PACKED_RECORDS_PER_BLOCK, this.metadata.trigrams - high * PACKED_RECORDS_PER_BLOCK);
+    let current = starts[high].trigram;
+    let offset = starts[high].postings;
+    for (let index = 0; index < entries; index++) {
+      current += cursor.next();
+      const [count, length] = [cursor.next(), cursor.next()];
+      if (current > trigram) return [];
+      if (current === trigram) {
+        const postings = new VarintCursor(await this.readStream('postings', offset, length));
+        const ids: number[] = [];
+        let id = 0;
+        for (let read = 0; read < count; read++) ids.push((id += postings.next()));
+        return ids;
+      }
+      offset += length;
+    }
+    return [];
+  };
+
+  /**
+   * Files that can hold every literal: those holding all of their trigrams
+   *
+   * @param literals - Text every match contains
+   * @param options - ignoreCase: look up only ASCII trigrams, whose case folding is known
+   * @returns File IDs in file order, or null when the literals have no trigram (every file is a candidate)
+   */
+  public candidates = async (literals: string[], options: { ignoreCase?: boolean } = {}): Promise<number[] | null> => {
+    const trigrams = new Set<number>();
+    for (const literal of literals) {
+      for (const trigram of trigramsOf(Buffer.from(literal), options.ignoreCase)) trigrams.add(trigram);
+    }
+    if (trigrams.size === 0) return null;
+
+    // Intersect from the rarest trigram, stopping once nothing is left
+    const lists = await Promise.all([...trigrams].map((trigram) => this.postings(trigram)));
+    lists.sort((a, b) => a.length - b.length);
+    let result = lists[0];
+    for (const list of lists.slice(1)) {
+      if (result.length === 0) break;
+      const holding = new Set(list);
+      result = result.filter((id) => holding.has(id));
+    }
+    return result;
+  };
+
+  /**
+   * Close the file
+   */
+  public close = async (): Promise<void> => {
+    this.cache.clear();
+    await this.handle.close();
+  };
+
+  /**
+   * Interned string by ID
+   */
+  private string = async (id: number): Promise<string> => {
+    const cursor = new VarintCursor(await this.readBlock('strings', Math.floor(id / PACKED_RECORDS_PER_BLOCK)));
+    for (let index = 0; index < id % PACKED_RECORDS_PER_BLOCK; index++) cursor.bytes(cursor.next());
+    return cursor.bytes(cursor.next()).toString('utf-8');
+  };
+
+  /**
+   * Bytes of a contents or postings stream, across as many blocks as they span
+   */
+  private readStream = async (stream: 'contents' | 'postings', offset: number, length: number): Promise<Buffer> => {
+    const parts: Buffer[] = [];
+    for (let position = offset; position < offset + length; ) {
+      const blockIndex = Math.floor(position / PACKED_BLOCK_SIZE);
+      const block = await this.readBlock(stream, blockIndex);
+      const start = position - blockIndex * PACKED_BLOCK_SIZE;
+      const part = block.subarray(start, Math.min(block.length, start + offset + length - position));
+      if (part.length === 0) throw new PackedIndexError(`Packed index ${stream} stream ends early`);
+      parts.push(part);
+      position += part.length;
+    }
+    return parts.length === 1 ? parts[0] : Buffer.concat(parts);
+  };
+
+  /**
+   * Decompressed block, read from the file unless cached
+   *
+   * @throws {PackedIndexError} If the block does not exist or does not decompress
+   */
+  private readBlock = async (section: PackedSection, index: number): Promise<Buffer> => {
+    const key = `${section}:${String(index)}`;
+    const cached = this.cache.get(key);
+    if (cached) {
+      // Most recently used last
+      this.cache.delete(key);
+      this.cache.set(key, cached);
+      return cached;
+    }
+
+    const location = this.layout.blocks[section].at(index);
+    if (!location) throw new PackedIndexError(`No ${section} block ${String(index)} in packed index`);
+    const compressed = Buffer.alloc(location.length);
+    const { bytesRead } = await this.handle.read(compressed, 0, location.length, location.offset);
+    if (bytesRead < location.length) throw new PackedIndexError('Packed index is truncated');
+    let block: Buffer;
+    try {
+      block = zlib.zstdDecompressSync(compressed);
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      throw new PackedIndexError(`Invalid ${section} block in packed index: ${message}`);
+    }
+
+    this.cache.set(key, block);
+    this.cachedBytes += block.length;
+    for (const [oldest, evicted] of this.cache) {
+      if (this.cachedBytes <= this.cacheBytes || oldest === key) break;
+      this.cache.delete(oldest);
+      this.cachedBytes -= evicted.length;
+    }
+    return block;
+  };
+}
+
+/**
+ * Open a packed index, reading its trailer and directory only
+ *
+ * @param file - Packed index file
+ * @param options - Block cache size
+ * @returns Index ready for searching; close() it when done
+ * @throws {PackedIndexError} If the file is not a packed index or zstd is unavailable
+ */
+export const openPackedIndex = async (file: string, options: PackedIndexOptions = {}): Promise<PackedIndex> => {
+  requireZstd('Packed indexes', PackedIndexError);
+  const handle = await fs.open(file, 'r');
+  try {
+    const { size } = await handle.stat();
+    const head = Buffer.alloc(PACKED_MAGIC.length);
+    const trailer = Buffer.alloc(TRAILER_BYTES);
+    await handle.read(head, 0, head.length, 0);
+    if (size < PACKED_MAGIC.length + TRAILER_BYTES || !head.equals(PACKED_MAGIC)) {
+      throw new PackedIndexError(`Not a packed index: ${file}`);
+    }
+    await handle.read(trailer, 0, TRAILER_BYTES, size - TRAILER_BYTES);
+    if (!trailer.subarray(12).equals(PACKED_MAGIC)) {
+      throw new PackedIndexError(`Packed index has no trailer (truncated file?): ${file}`);
+    }
+    const directoryOffset = Number(trailer.readBigUInt64LE(0));
+    const directoryLength = trailer.readUInt32LE(8);
+    if (directoryOffset + directoryLength > size - TRAILER_BYTES) {
+      throw new PackedIndexError(`Packed index directory is out of range: ${file}`);
+    }
+
+    const compressed = Buffer.alloc(directoryLength);
+    await handle.read(compressed, 0, directoryLength, directoryOffset);
+    let cursor: VarintCursor;
+    try {
+      cursor = new VarintCursor(zlib.zstdDecompressSync(compressed));
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      throw new PackedIndexError(`Invalid packed index directory: ${message}`);
+    }
+    const metadata = JSON.parse(cursor.bytes(cursor.next()).toString('utf-8')) as PackedIndexMetadata;
+
+    // Blocks follow the magic, section by section in write order
+    let offset = PACKED_MAGIC.length;
+    const blocks = {} as PackedLayout['blocks'];
+    for (const section of SECTIONS) {
+      blocks[section] = Array.from({ length: cursor.next() }, () => {
+        const length = cursor.next();
+        offset += length;
+        return { offset: offset - length, length };
+      });
+    }
+    const fileOffsets = blocks.files.map(() => cursor.next());
+    const trigramStarts = blocks.trigrams.map(() => ({ trigram: cursor.next(), postings: cursor.next() }));
+
+    return new PackedIndex(
+      handle,
+      metadata,
+      { blocks, fileOffsets, trigramStarts },
+      options.cacheBytes ?? PACKED_CACHE_BYTES
+    );
+  } catch (error) {
+    await handle.close();
+    throw error;
+  }
+};
+
+/**
+ * Write the stored contents of an index as a packed index
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to export
+ * @param write - Write bytes to the file, in order
+ * @returns Metadata of the index written
+ * @throws {PackedIndexError} If zstd is unavailable
+ * @throws {DatabaseQueryError} If reading the contents fails
+ */
+export const exportPackedIndex = async (
+  db: Pool,
+  repoId: string,
+  write: (bytes: Buffer) => Promise<void>
+): Promise<PackedIndexMetadata> => {
+  const writer = new PackedIndexWriter(write, repoId);
+  let after: string | null = null;
+  for (;;) {
+    const files = await listIndexContents(db, repoId, after, EXPORT_PAGE_FILES);
+    for (const file of files) await writer.add(file.file_path, file.content);
+    const last = files.at(-1);
+    if (!last || files.length < EXPORT_PAGE_FILES) break;
+    after = last.file_path;
+  }
+  return writer.end();
+};
@@ -0,0 +1,187 @@
+/**
+ * Pool of parse worker threads
+ *
+ * Tree-sitter parsing is synchronous and CPU-bound, so concurrent files only
+ * use more than one core when their parses run off the main thread. The pool
+ * starts up to `size` workers on demand (see @indexing/parse-worker), sends
+ * each one file at a time, and replaces a worker that dies (for instance on
+ * reaching its heap limit): the file it was parsing fails, later files go on.
+ *
+ * With one job, or when the worker script is not built (running from
+ * source), files are parsed on the calling thread instead.
+ */
+
+import * as fs from 'node:fs';
+import { fileURLToPath } from 'node:url';
+import { Worker } from 'node:worker_threads';
+
+import { type CodeParser } from '@indexing/parser';
+import { logger } from '@utils/logger';
+import { type Language, type ParseResult } from '@/types/indexing';
+
+/**
+ * Parse request posted to a worker
+ */
+export interface ParseRequest {
+  id: number;
+  code: string;
+  filePath: string;
+  language: Language;
+}
+
+/**
+ * Worker reply: the parse result, or why parsing threw
+ */
+export type ParseResponse = { id: number; result: ParseResult } | { id: number; error: string };
+
+/** Heap limit of one parse worker in MB (a file that exceeds it fails alone) */
+const PARSE_WORKER_HEAP_MB = 512;
+
+/** Worker script, next to the bundle (dist/parse-worker.js) */
+const PARSE_WORKER_URL = new URL('./parse-worker.js', import.meta.url);
+
+/**
+ * A parse waiting for, or running on, a worker
+ */
+interface PendingParse {
+  request: ParseRequest;
+  resolve: (result: ParseResult) => void;
+  reject: (error: Error) => void;
+}
+
+/**
+ * A worker thread and the parse it is running
+ */
+interface PoolWorker {
+  thread: Worker;
+  current: PendingParse | null;
+}
+
+/**
+ * Parse worker pool
+ */
+export class ParsePool {
+  private readonly workers: PoolWorker[] = [];
+  private readonly queue: PendingParse[] = [];
+  private readonly useWorkers: boolean;
+  private nextId = 0;
+
+  /**
+   * Create parse pool
+   *
+   * @param size - Maximum worker threads
+   * @param parser - Parser used on the calling thread when workers are not available
+   * @param script - Worker script (default: parse-worker.js next to the bundle)
+   */
+  constructor(
+    private readonly size: number,
+    private readonly parser: CodeParser,
+    private readonly script: URL = PARSE_WORKER_URL
+  ) {
+    this.useWorkers = size > 1 && fs.existsSync(fileURLToPath(script));
+    if (size > 1 && !this.useWorkers) {
+      logger.debug('Parse worker script not found, parsing on the main thread', { script: script.href });
+    }
+  }
+
+  /**
+   * Parse a file
+   *
+   * @param code - Source code
+   * @param filePath - File path (for messages)
+   * @param language - Language of the file
+   * @returns Parse result
+   * @throws {Error} If the worker parsing the file died
+   */
+  public parse = (code: string, filePath: string, language: Language): Promise<ParseResult> => {
+    if (!this.useWorkers) {
+      this.parser.setLanguage(language);
+      return Promise.resolve(this.parser.parse(code, filePath));
+    }
+
+    return new Promise<ParseResult>((resolve, reject) => {
+      this.queue.push({ request: { id: this.nextId++, code, filePath, language }, resolve, reject });
+      this.dispatch();
+    });
+  };
+
+  /**
+   * Whether parses run on worker threads
+   */
+  public usesWorkers = (): boolean => this.useWorkers;
+
+  /**
+   * Stop all workers; parses still queued are rejected
+   */
+  public close = async (): Promise<void> => {
+    for (const pending of this.queue.splice(0)) pending.reject(new Error('Parse pool closed'));
+    const workers = this.workers.splice(0);
+    await Promise.all(workers.map((worker) => worker.thread.terminate()));
+  };
+
+  /**
+   * Hand queued parses to idle workers, starting workers up to the pool size
+   */
+  private dispatch = (): void => {
+    while (this.queue.length > 0) {
+      const worker = this.workers.find((candidate) => candidate.current === null) ?? this.spawn();
+      if (!worker) return;
+      const pending = this.queue.shift();
+      if (!pending) return;
+      worker.current = pending;
+      worker.thread.postMessage(pending.request);
+    }
+  };
+
+  /**
+   * Start a worker if the pool has room
+   */
+  private spawn = (): PoolWorker | undefined => {
+    if (this.workers.length >= this.size) return undefined;
+
+    const worker: PoolWorker = {
+      thread: new Worker(this.script, {
+        workerData: { logLevel: logger.getLevel() },
+        resourceLimits: { maxOldGenerationSizeMb: PARSE_WORKER_HEAP_MB },
+      }),
+      current: null,
+    };
+
+    worker.thread.on('message', (response: ParseResponse) => {
+      const pending = worker.current;
+      worker.current = null;
+      if (pending?.request.id === response.id) {
+        if ('result' in response) pending.resolve(response.result);
+        else pending.reject(new Error(`Parsing failed: ${response.error}`));
+      }
+      this.dispatch();
+    });
+
+    worker.thread.on('error', (error) => {
+      this.retire(worker, error);
+    });
+    worker.thread.on('exit', (code) => {
+      this.retire(worker, new Error(`Parse worker exited with code ${String(code)}`));
+    });
+
+    this.workers.push(worker);
+    return worker;
+  };
+
+  /**
+   * Drop a dead worker, failing the parse it was running
+   */
+  private retire = (worker: PoolWorker, error: Error): void => {
+    const index = this.workers.indexOf(worker);
+    if (index === -1) return;
+    this.workers.splice(index, 1);
+
+    const pending = worker.current;
+    worker.current = null;
+    if (pending) {
+      logger.warn('Parse worker died', { file: pending.request.filePath, error: error.message });
+      pending.reject(error);
+    }
+    this.dispatch();
+  };
+}
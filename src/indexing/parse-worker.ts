@@ -0,0 +1,33 @@
+/**
+ * Parse worker thread (bundled as dist/parse-worker.js)
+ *
+ * Each worker owns a tree-sitter parser and the syntax trees it builds;
+ * trees never leave the thread. Only the extracted ParseResult, plain data,
+ * is posted back, so a worker's native and heap memory is released with
+ * the worker and is capped by its resource limits.
+ */
+
+import { parentPort, workerData } from 'node:worker_threads';
+
+import { type ParseRequest, type ParseResponse } from '@indexing/parse-pool';
+import { CodeParser } from '@indexing/parser';
+import { initLogger, type LogLevel } from '@utils/logger';
+
+if (!parentPort) {
+  throw new Error('parse-worker must run as a worker thread');
+}
+
+const port = parentPort;
+initLogger((workerData as { logLevel: LogLevel }).logLevel);
+const parser = new CodeParser();
+
+port.on('message', (request: ParseRequest) => {
+  let response: ParseResponse;
+  try {
+    parser.setLanguage(request.language);
+    response = { id: request.id, result: parser.parse(request.code, request.filePath) };
+  } catch (error) {
+    response = { id: request.id, error: error instanceof Error ? error.message : String(error) };
+  }
+  port.postMessage(response);
+});
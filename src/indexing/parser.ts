@@ -6,7 +6,7 @@
  * - Classes and interfaces
  * - Import and export statements
  * - Top-level variables and constants
- * - Cyclomatic complexity
+ * - Cyclomatic and cognitive complexity (see @indexing/complexity)
  *
  * Supports 12 programming languages with full tree-sitter parsing:
  * - TypeScript, JavaScript, Python, Java, Go, Rust
@@ -42,7 +42,10 @@ import Rust from 'tree-sitter-rust';
 // eslint-disable-next-line @typescript-eslint/naming-convention -- Tree-sitter library exports use PascalCase
 import TypeScript from 'tree-sitter-typescript';
 
+import { cognitiveComplexity, cyclomaticComplexity } from '@indexing/complexity';
 import { logger } from '@utils/logger';
+import { utf16ToByteColumn } from '@utils/positions';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
 import {
   Language,
   NodeType,
@@ -53,6 +56,21 @@ import {
   type ParseResult,
 } from '@/types/indexing';
 
+/**
+ * Nodes wrapping a declaration whose doc comment sits above the wrapper
+ * (export const, @decorator, var x = function, Go type groups)
+ */
+const DOC_HOLDERS = new Set([
+  'export_statement',
+  'decorated_definition',
+  'lexical_declaration',
+  'variable_declaration',
+  'variable_declarator',
+  'type_declaration',
+  'const_declaration',
+  'var_declaration',
+]);
+
 /**
  * Map languages to tree-sitter parsers
  * Note: Swift has build issues - using fallback parsing
@@ -74,6 +92,41 @@ const LANGUAGE_PARSERS: Record<Language, object | null> = {
   [Language.Unknown]: null,
 };
 
+/**
+ * Find the first ERROR or MISSING node in a tree with syntax errors
+ */
+const findSyntaxError = (node: Parser.SyntaxNode): Parser.SyntaxNode => {
+  for (const child of node.children) {
+    if (child.type === 'ERROR' || child.isMissing) return child;
+    if (child.hasError) return findSyntaxError(child);
+  }
+  return node;
+};
+
+/**
+ * Check whether a node can be extracted from a tree with syntax errors
+ *
+ * Error-free nodes always can. A declaration whose error lies in its body
+ * (the common work-in-progress case) is kept as long as its name parsed;
+ * ERROR, MISSING, and unnamed nodes with errors are skipped, though their
+ * children are still visited.
+ */
+const isIntact = (node: Parser.SyntaxNode): boolean => {
+  if (!node.hasError) return true;
+  if (node.type === 'ERROR' || node.isMissing) return false;
+  const nameNode = node.childForFieldName('name');
+  return nameNode !== null && !nameNode.hasError && !nameNode.isMissing;
+};
+
+/**
+ * Describe a syntax error node for reports (e.g. "Missing ';'", "Unexpected '=>'")
+ */
+const describeSyntaxError = (node: Parser.SyntaxNode): string => {
+  if (node.isMissing) return `Missing '${node.type}'`;
+  const text = node.text.split('\n')[0].trim();
+  return text.length > 0 ? `Unexpected '${text.length > 40 ? `${text.slice(0, 40)}...` : text}'` : 'Syntax error';
+};
+
 /**
  * Code parser with tree-sitter support
  */
@@ -117,29 +170,57 @@ export class CodeParser {
         language: this.language,
         file: filePath,
       });
-      return this.fallbackParse(code, filePath);
+      return { ...this.fallbackParse(code, filePath), error: `No tree-sitter grammar for ${this.language}` };
     }
 
     try {
       // Generate syntax tree
       const tree = this.parser.parse(code);
 
-      // Check for syntax errors
-      if (tree.rootNode.hasError) {
-        logger.warn('Syntax errors detected, using fallback', {
-          file: filePath,
-          language: this.language,
-        });
-        return this.fallbackParse(code, filePath);
-      }
-
-      // Extract nodes based on language
+      // Extract nodes based on language (with syntax errors, only intact declarations)
       const nodes: ParsedNode[] = [];
       const imports: ImportInfo[] = [];
       const exports: ExportInfo[] = [];
 
       this.traverseTree(tree.rootNode, code, nodes, imports, exports);
 
+      // Check for syntax errors: keep what parsed, fall back only if nothing did
+      if (tree.rootNode.hasError) {
+        const errorNode = findSyntaxError(tree.rootNode);
+        const error = describeSyntaxError(errorNode);
+        const { row, column } = errorNode.startPosition;
+        const lineText = code.split('\n')[row] ?? '';
+        const position = { line: row + 1, column: column + 1, byte_column: utf16ToByteColumn(lineText, column + 1) };
+
+        if (nodes.length === 0 && imports.length === 0 && exports.length === 0) {
+          logger.warn('Syntax errors detected, using fallback', {
+            file: filePath,
+            language: this.language,
+            error,
+            line: position.line,
+          });
+          return { ...this.fallbackParse(code, filePath), error, error_position: position };
+        }
+
+        logger.warn('Syntax errors detected, indexing declarations that parsed', {
+          file: filePath,
+          language: this.language,
+          error,
+          line: position.line,
+          nodes: nodes.length,
+        });
+        return {
+          success: true,
+          nodes,
+          imports,
+          exports,
+          error,
+          error_position: position,
+          used_fallback: false,
+          partial: true,
+        };
+      }
+
       return {
         success: true,
         nodes,
@@ -152,7 +233,8 @@ export class CodeParser {
         error,
         file: filePath,
       });
-      return this.fallbackParse(code, filePath);
+      const message = error instanceof Error ? error.message : String(error);
+      return { ...this.fallbackParse(code, filePath), error: `Tree-sitter failed: ${message}` };
     }
   };
 
@@ -166,7 +248,25 @@ export class CodeParser {
     imports: ImportInfo[],
     exports: ExportInfo[]
   ): void => {
-    // Language-specific node extraction
+    // Language-specific node extraction (children are visited either way)
+    if (isIntact(node)) this.extractNodes(node, code, nodes, imports, exports);
+
+    // Recursively traverse children
+    for (const child of node.children) {
+      this.traverseTree(child, code, nodes, imports, exports);
+    }
+  };
+
+  /**
+   * Extract declarations from a single syntax node for the current language
+   */
+  private extractNodes = (
+    node: Parser.SyntaxNode,
+    code: string,
+    nodes: ParsedNode[],
+    imports: ImportInfo[],
+    exports: ExportInfo[]
+  ): void => {
     switch (this.language) {
       case Language.TypeScript:
       case Language.JavaScript:
@@ -201,11 +301,6 @@ export class CodeParser {
         this.extractKotlinNodes(node, code, nodes, imports, exports);
         break;
     }
-
-    // Recursively traverse children
-    for (const child of node.children) {
-      this.traverseTree(child, code, nodes, imports, exports);
-    }
   };
 
   /**
@@ -287,7 +382,8 @@ export class CodeParser {
       const parameters = this.extractParameters(node, code);
       const returnType = this.extractReturnType(node, code);
       const docstring = this.extractDocstring(node, code);
-      const complexity = this.calculateComplexity(node, code);
+      const complexity = cyclomaticComplexity(node);
+      const cognitive = cognitiveComplexity(node);
 
       const isAsync = code.slice(node.startIndex, node.endIndex).includes('async');
 
@@ -301,6 +397,7 @@ export class CodeParser {
         return_type: returnType,
         docstring,
         complexity,
+        cognitive_complexity: cognitive,
         is_async: isAsync,
       };
     } catch (error) {
@@ -506,49 +603,35 @@ export class CodeParser {
 
   /**
    * Extract docstring/comment
-   */
-  private extractDocstring = (node: Parser.SyntaxNode, code: string): string | undefined => {
-    // Look for comment node preceding this node
-    const prevSibling = node.previousSibling;
-    if (prevSibling?.type === 'comment') {
-      return code.slice(prevSibling.startIndex, prevSibling.endIndex);
-    }
-    return undefined;
-  };
-
-  /**
-   * Calculate cyclomatic complexity
    *
-   * Complexity = decision points + 1
-   * Decision points: if, else, while, for, case, &&, ||, ?, catch
+   * The run of comments directly above the declaration (Go and Rust document
+   * with one comment per line), looked up on the enclosing export, decorator,
+   * or declaration when the node itself has none; else a Python docstring, a
+   * string as the first statement of the body.
    */
-  private calculateComplexity = (node: Parser.SyntaxNode, _code: string): number => {
-    let complexity = 1; // Base complexity
-
-    const decisionNodeTypes = new Set([
-      'if_statement',
-      'else_clause',
-      'while_statement',
-      'for_statement',
-      'switch_case',
-      'catch_clause',
-      'conditional_expression',
-      'logical_expression',
-    ]);
-
-    const traverse = (n: Parser.SyntaxNode) => {
-      if (decisionNodeTypes.has(n.type)) {
-        complexity++;
+  private extractDocstring = (node: Parser.SyntaxNode, code: string): string | undefined => {
+    for (let holder: Parser.SyntaxNode | null = node; holder; holder = holder.parent) {
+      const comments: Parser.SyntaxNode[] = [];
+      let next = holder;
+      for (let sibling = holder.previousSibling; sibling?.type.includes('comment'); sibling = sibling.previousSibling) {
+        if (sibling.endPosition.row < next.startPosition.row - 1) break;
+        comments.unshift(sibling);
+        next = sibling;
       }
-
-      for (const child of n.children) {
-        traverse(child);
+      if (comments.length > 0) {
+        return comments.map((comment) => code.slice(comment.startIndex, comment.endIndex)).join('\n');
       }
-    };
-
-    traverse(node);
+      if (!holder.parent || !DOC_HOLDERS.has(holder.parent.type)) break;
+    }
 
-    return complexity;
+    // Python bodies are blocks; a leading string in a JavaScript body is a directive ('use strict')
+    const body = node.childForFieldName('body');
+    const first = body?.type === 'block' ? body.namedChildren.at(0) : undefined;
+    const literal = first?.type === 'expression_statement' ? first.namedChildren.at(0) : undefined;
+    if (literal?.type === 'string') {
+      return code.slice(literal.startIndex, literal.endIndex);
+    }
+    return undefined;
   };
 
   /**
@@ -899,7 +982,10 @@ export class CodeParser {
     if (type === 'function_declaration' || type === 'method_declaration') {
       const func = this.extractFunction(node, code);
       if (func) {
-        func.node_type = type === 'method_declaration' ? NodeType.Method : NodeType.Function;
+        if (type === 'method_declaration') {
+          func.node_type = NodeType.Method;
+          func.receiver = this.extractGoReceiver(node, code);
+        }
         nodes.push(func);
       }
     }
@@ -923,6 +1009,17 @@ export class CodeParser {
       }
     }
 
+    // Extract package-level constants and variables (those inside functions are locals)
+    if ((type === 'const_declaration' || type === 'var_declaration') && node.parent?.type === 'source_file') {
+      const values = this.extractGoValueSpecs(node, code);
+      nodes.push(...values);
+      for (const value of values) {
+        if (/^[A-Z]/.test(value.name)) {
+          exports.push({ symbols: [value.name], is_default: false, is_reexport: false, line_number: value.start_line });
+        }
+      }
+    }
+
     // Extract import declarations
     if (type === 'import_declaration') {
       const imps = this.extractGoImports(node, code);
@@ -982,7 +1079,7 @@ export class CodeParser {
   };
 
   /**
-   * Extract Go type spec (struct or interface)
+   * Extract Go type spec (struct, interface, or named type such as type UserRole int)
    */
   private extractGoTypeSpec = (node: Parser.SyntaxNode, code: string, nodes: ParsedNode[]): void => {
     try {
@@ -992,6 +1089,7 @@ export class CodeParser {
       if (typeNode.type === 'struct_type') {
         const struct = this.extractClass(node, code);
         if (struct) {
+          struct.children = this.extractGoFields(typeNode, code);
           nodes.push(struct);
         }
       } else if (typeNode.type === 'interface_type') {
@@ -999,12 +1097,122 @@ export class CodeParser {
         if (iface) {
           nodes.push(iface);
         }
+      } else {
+        const nameNode = node.childForFieldName('name');
+        if (!nameNode) return;
+        nodes.push({
+          node_type: NodeType.Type,
+          name: code.slice(nameNode.startIndex, nameNode.endIndex),
+          start_line: node.startPosition.row + 1,
+          end_line: node.endPosition.row + 1,
+          code_text: code.slice(node.startIndex, node.endIndex),
+          return_type: code.slice(typeNode.startIndex, typeNode.endIndex),
+          docstring: this.extractDocstring(node, code),
+        });
       }
     } catch (error) {
       logger.debug('Failed to extract Go type spec', { error });
     }
   };
 
+  /**
+   * Extract the fields of a Go struct type, one node per name
+   *
+   * Embedded fields are named by their type, as Go names them (Mutex of
+   * sync.Mutex, Base of *Base[T]). Fields of nested anonymous structs are not
+   * listed.
+   */
+  private extractGoFields = (structNode: Parser.SyntaxNode, code: string): ParsedNode[] => {
+    const list = structNode.namedChildren.find((c) => c.type === 'field_declaration_list');
+    const fields: ParsedNode[] = [];
+
+    for (const declaration of list?.namedChildren ?? []) {
+      if (declaration.type !== 'field_declaration') continue;
+      const typeNode = declaration.childForFieldName('type');
+      if (!typeNode) continue;
+      const fieldType = code.slice(typeNode.startIndex, typeNode.endIndex);
+
+      let names = declaration.namedChildren
+        .filter((c) => c.type === 'field_identifier')
+        .map((c) => code.slice(c.startIndex, c.endIndex));
+      if (names.length === 0) {
+        const embedded = /([\p{L}_][\p{L}\p{N}_]*)(?:\[.*\])?$/u.exec(fieldType);
+        names = embedded ? [embedded[1]] : [];
+      }
+
+      const docstring = this.extractDocstring(declaration, code);
+      for (const name of names) {
+        if (name === '_') continue;
+        fields.push({
+          node_type: NodeType.Field,
+          name,
+          start_line: declaration.startPosition.row + 1,
+          end_line: declaration.endPosition.row + 1,
+          code_text: code.slice(declaration.startIndex, declaration.endIndex),
+          return_type: fieldType,
+          docstring,
+        });
+      }
+    }
+
+    return fields;
+  };
+
+  /**
+   * Extract the receiver type name of a Go method (AuthService of (s *AuthService))
+   */
+  private extractGoReceiver = (node: Parser.SyntaxNode, code: string): string | undefined => {
+    const receiver = node.childForFieldName('receiver');
+    const typeNode = receiver?.namedChildren.find((c) => c.type === 'parameter_declaration')?.childForFieldName('type');
+    if (!typeNode) return undefined;
+    // Pointer and generic receivers: (s *Store[K, V]) is a method of Store
+    const name = /^\*?\s*([\p{L}_][\p{L}\p{N}_]*)/u.exec(code.slice(typeNode.startIndex, typeNode.endIndex));
+    return name?.[1];
+  };
+
+  /**
+   * Extract the names of a Go const or var declaration, one node per name
+   *
+   * Names of a const group without type or value repeat the spec above
+   * (RoleModerator after RoleUser UserRole = iota), so they take its type.
+   */
+  private extractGoValueSpecs = (node: Parser.SyntaxNode, code: string): ParsedNode[] => {
+    const values: ParsedNode[] = [];
+    const isConst = node.type === 'const_declaration';
+
+    try {
+      const specs = node.namedChildren.flatMap((c) => (c.type === 'var_spec_list' ? c.namedChildren : [c]));
+      let previousType: string | undefined;
+      for (const spec of specs) {
+        if (spec.type !== 'const_spec' && spec.type !== 'var_spec') continue;
+        const typeNode = spec.childForFieldName('type');
+        const hasValue = spec.childForFieldName('value') !== null;
+        const valueType = typeNode ? code.slice(typeNode.startIndex, typeNode.endIndex) : undefined;
+        const declaredType = valueType ?? (isConst && !hasValue ? previousType : undefined);
+        previousType = declaredType;
+
+        const docstring = this.extractDocstring(spec, code);
+        for (const nameNode of spec.namedChildren.filter((c) => c.type === 'identifier')) {
+          const name = code.slice(nameNode.startIndex, nameNode.endIndex);
+          if (name === '_') continue;
+          values.push({
+            node_type: isConst ? NodeType.Constant : NodeType.Variable,
+            name,
+            start_line: spec.startPosition.row + 1,
+            end_line: spec.endPosition.row + 1,
+            code_text: code.slice(spec.startIndex, spec.endIndex),
+            return_type: declaredType,
+            docstring,
+          });
+        }
+      }
+    } catch (error) {
+      logger.debug('Failed to extract Go values', { error });
+    }
+
+    return values;
+  };
+
   /**
    * Extract Go import declarations
    */
@@ -1815,7 +2023,7 @@ export class CodeParser {
     const nodes: ParsedNode[] = [];
 
     // Extract functions using regex
-    const functionRegex = /^\s*(?:export\s+)?(?:async\s+)?function\s+(\w+)/gm;
+    const functionRegex = new RegExp(`^\\s*(?:export\\s+)?(?:async\\s+)?function\\s+(${IDENTIFIER_PATTERN})`, 'gmu');
     let match;
 
     while ((match = functionRegex.exec(code)) !== null) {
@@ -1835,7 +2043,7 @@ export class CodeParser {
     }
 
     // Extract classes using regex
-    const classRegex = /^\s*(?:export\s+)?class\s+(\w+)/gm;
+    const classRegex = new RegExp(`^\\s*(?:export\\s+)?class\\s+(${IDENTIFIER_PATTERN})`, 'gmu');
 
     while ((match = classRegex.exec(code)) !== null) {
       const name = match[1];
@@ -0,0 +1,56 @@
+/**
+ * Indexing pipeline assembly
+ *
+ * Wires the concrete pipeline components into an IndexingOrchestrator.
+ * Shared by the MCP index_repository tool and the `cindex index` command.
+ */
+
+import { type DatabaseClient } from '@database/client';
+import { DatabaseWriter } from '@database/writer';
+import { CodeChunker } from '@indexing/chunker';
+import { EmbeddingGenerator } from '@indexing/embeddings';
+import { FileWalker } from '@indexing/file-walker';
+import { IndexingOrchestrator } from '@indexing/orchestrator';
+import { CodeParser } from '@indexing/parser';
+import { FileSummaryGenerator } from '@indexing/summary';
+import { SymbolExtractor } from '@indexing/symbols';
+import { createEmbedder } from '@utils/embedders';
+import { type OllamaClient } from '@utils/ollama';
+import { ProgressTracker } from '@utils/progress';
+import { type CindexConfig } from '@/types/config';
+import { type IndexingOptions } from '@/types/indexing';
+
+/**
+ * Create IndexingOrchestrator with all pipeline components for one repository
+ *
+ * Pipeline: file discovery → parsing → chunking → summarization → embedding → persistence
+ *
+ * @param config - Loaded configuration
+ * @param db - Connected database client
+ * @param ollama - Ollama client (summaries, and embeddings unless EMBEDDING_PROVIDER selects another provider)
+ * @param repoPath - Repository root path
+ * @param options - Indexing options
+ * @returns Orchestrator ready to run indexRepository()
+ */
+export const createPipeline = (
+  config: CindexConfig,
+  db: DatabaseClient,
+  ollama: OllamaClient,
+  repoPath: string,
+  options: IndexingOptions
+): IndexingOrchestrator => {
+  const embedder = createEmbedder(config, ollama);
+  return new IndexingOrchestrator(
+    db,
+    new FileWalker(repoPath, options),
+    new CodeParser(),
+    new CodeChunker(),
+    new FileSummaryGenerator(ollama, config.summary),
+    new EmbeddingGenerator(embedder, config.embedding),
+    new SymbolExtractor(new EmbeddingGenerator(embedder, config.embedding)),
+    new DatabaseWriter(db.getPool()),
+    new ProgressTracker()
+  )
+    .usePlugins(config.indexing.plugins)
+    .useStore(config.store);
+};
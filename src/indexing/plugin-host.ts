@@ -0,0 +1,193 @@
+/**
+ * Plugin processes: extractors and analyzers served over JSON-RPC (PLUGINS=exec:<command>)
+ *
+ * The command is started once per cindex process and speaks JSON-RPC 2.0 on
+ * its stdin and stdout, framed as in the Language Server Protocol (a
+ * Content-Length header before each body, see @lsp/protocol), so any LSP or
+ * JSON-RPC library can serve it. stderr is passed through to cindex's.
+ *
+ *   → initialize {"protocol_version": 1}
+ *   ← {"name": "abap", "extractors": [{"name", "language", "extensions"}], "analyzers": [{"name", "languages"}]}
+ *   → extract {"extractor", "path", "language", "content"}   ← PluginExtraction
+ *   → analyze {"analyzer", "path", "language", "content", "symbols"}   ← PluginAnalysis
+ *   → shutdown, then the exit notification
+ *
+ * Requests may be answered in any order; each times out after
+ * PLUGIN_REQUEST_TIMEOUT_MS. A process that exits fails the requests still
+ * waiting, and every later one.
+ */
+
+import { spawn, type ChildProcessByStdio } from 'node:child_process';
+import { type Readable, type Writable } from 'node:stream';
+
+import { encodeMessage, MessageReader } from '@lsp/protocol';
+import { type JsonRpcResponse } from '@/types/lsp';
+import {
+  type AnalyzerPlugin,
+  type ExtractorPlugin,
+  type PluginAnalysis,
+  type PluginExtraction,
+  type PluginFile,
+} from '@/types/plugins';
+
+/** Version of the protocol, sent with initialize */
+export const PLUGIN_PROTOCOL_VERSION = 1;
+
+/** Longest a plugin may take to answer a request */
+export const PLUGIN_REQUEST_TIMEOUT_MS = 30_000;
+
+/** Time a plugin has to exit after shutdown before it is killed */
+const PLUGIN_EXIT_TIMEOUT_MS = 2_000;
+
+/**
+ * Plugins a process declares in its initialize result
+ */
+interface PluginManifest {
+  name?: string;
+  extractors?: { name: string; language: string; extensions: string[] }[];
+  analyzers?: { name: string; languages?: string[] }[];
+}
+
+/**
+ * Split a command line on whitespace, keeping "double" or 'single' quoted words together
+ */
+export const splitCommand = (command: string): string[] =>
+  [...command.matchAll(/"([^"]*)"|'([^']*)'|(\S+)/g)].map((match) => match[1] ?? match[2] ?? match[3]);
+
+/**
+ * Request waiting for its response
+ */
+interface PendingRequest {
+  resolve: (result: unknown) => void;
+  reject: (error: Error) => void;
+  timer: NodeJS.Timeout;
+}
+
+/**
+ * Connection to one plugin process
+ */
+export class PluginProcess {
+  private readonly child: ChildProcessByStdio<Writable, Readable, null>;
+  private readonly reader = new MessageReader();
+  private readonly pending = new Map<number, PendingRequest>();
+  private nextId = 1;
+  private exited: Error | null = null;
+
+  /**
+   * @param command - Command line of the plugin (see splitCommand)
+   */
+  constructor(private readonly command: string) {
+    const [program, ...args] = splitCommand(command);
+    this.child = spawn(program, args, { stdio: ['pipe', 'pipe', 'inherit'] });
+    this.child.stdout.on('data', (chunk: Buffer) => {
+      try {
+        for (const message of this.reader.push(chunk)) this.settle(message as JsonRpcResponse | undefined);
+      } catch (error) {
+        this.fail(error instanceof Error ? error : new Error(String(error)));
+      }
+    });
+    this.child.on('error', (error) => {
+      this.fail(new Error(`Cannot start plugin ${command}: ${error.message}`));
+    });
+    this.child.on('exit', (code, signal) => {
+      this.fail(new Error(`Plugin ${command} exited (${signal ?? `code ${String(code)}`})`));
+    });
+    // Writes after the process exited are reported by the requests that made them
+    this.child.stdin.on('error', () => undefined);
+  }
+
+  /**
+   * Send a request and wait for its result
+   *
+   * @throws {Error} If the plugin answers with an error, does not answer in time, or has exited
+   */
+  public request = (method: string, params: unknown): Promise<unknown> => {
+    if (this.exited) return Promise.reject(this.exited);
+    const id = this.nextId++;
+    return new Promise<unknown>((resolve, reject) => {
+      const timer = setTimeout(() => {
+        this.pending.delete(id);
+        reject(new Error(`Plugin ${this.command} did not answer ${method} in ${String(PLUGIN_REQUEST_TIMEOUT_MS)} ms`));
+      }, PLUGIN_REQUEST_TIMEOUT_MS);
+      this.pending.set(id, { resolve, reject, timer });
+      this.child.stdin.write(encodeMessage({ jsonrpc: '2.0', id, method, params }));
+    });
+  };
+
+  /**
+   * Ask for the plugins served, as extractors and analyzers forwarding to the process
+   *
+   * @throws {Error} If the process does not complete initialize
+   */
+  public initialize = async (): Promise<{ extractors: ExtractorPlugin[]; analyzers: AnalyzerPlugin[] }> => {
+    const result = await this.request('initialize', { protocol_version: PLUGIN_PROTOCOL_VERSION });
+    const manifest = (result ?? {}) as PluginManifest;
+    const extractors = (manifest.extractors ?? []).map(
+      (extractor): ExtractorPlugin => ({
+        ...extractor,
+        extract: async (content, filePath) =>
+          (await this.request('extract', {
+            extractor: extractor.name,
+            path: filePath,
+            language: extractor.language,
+            content,
+          })) as PluginExtraction,
+      })
+    );
+    const analyzers = (manifest.analyzers ?? []).map(
+      (analyzer): AnalyzerPlugin => ({
+        ...analyzer,
+        analyze: async (file: PluginFile) =>
+          ((await this.request('analyze', { analyzer: analyzer.name, ...file })) ?? {}) as PluginAnalysis,
+      })
+    );
+    return { extractors, analyzers };
+  };
+
+  /**
+   * Ask the process to shut down and exit, killing it if it does not
+   */
+  public close = async (): Promise<void> => {
+    if (this.exited) return;
+    const exit = new Promise<void>((resolve) => {
+      const timer = setTimeout(() => {
+        this.child.kill();
+        resolve();
+      }, PLUGIN_EXIT_TIMEOUT_MS);
+      this.child.once('exit', () => {
+        clearTimeout(timer);
+        resolve();
+      });
+    });
+    try {
+      await this.request('shutdown', null);
+      this.child.stdin.write(encodeMessage({ jsonrpc: '2.0', method: 'exit' }));
+    } catch {
+      // A plugin that cannot shut down is killed all the same
+    }
+    this.child.stdin.end();
+    await exit;
+  };
+
+  /**
+   * Resolve or reject the request a response answers
+   */
+  private settle = (response: JsonRpcResponse | undefined): void => {
+    if (typeof response?.id !== 'number') return;
+    const request = this.pending.get(response.id);
+    if (!request) return;
+    this.pending.delete(response.id);
+    clearTimeout(request.timer);
+    if (response.error) request.reject(new Error(`Plugin ${this.command}: ${response.error.message}`));
+    else request.resolve(response.result);
+  };
+
+  private fail = (error: Error): void => {
+    this.exited ??= error;
+    for (const request of this.pending.values()) {
+      clearTimeout(request.timer);
+      request.reject(error);
+    }
+    this.pending.clear();
+  };
+}
@@ -0,0 +1,286 @@
+/**
+ * Plugins: custom extractors and analyzers added without forking the indexer (PLUGINS)
+ *
+ *   PLUGINS=./tools/abap.mjs                      a module, loaded into cindex's process
+ *   PLUGINS=exec:cindex-abap --stdio              a plugin process (see @indexing/plugin-host)
+ *   PLUGINS=./tools/metrics.mjs,exec:./lint-rpc   several, comma-separated
+ *
+ * A module exports register(cindex), which calls cindex.registerExtractor and
+ * cindex.registerAnalyzer:
+ *
+ *   export const register = (cindex) => {
+ *     cindex.registerExtractor({ name: 'abap', language: 'abap', extensions: ['.abap'], extract });
+ *     cindex.registerAnalyzer({ name: 'sql-count', languages: ['go'], analyze });
+ *   };
+ *
+ * An extractor claims file extensions: their files are indexed under its
+ * language, and the declarations it lists become symbols and chunks as those
+ * of a parsed file do (see @indexing/extractors). An extension of a built-in
+ * language goes to the extractor. An analyzer reads every indexed file of
+ * its languages once its symbols are known; its annotations are stored with
+ * kind plugin (see cindex annotations) and its metrics per file or symbol
+ * (see cindex plugins --metric). A plugin that throws fails the file, as a
+ * parse error would.
+ *
+ * PLUGINS is only read from the environment: project files cannot set it (see
+ * @cli/project-config), so indexing a checkout never runs code it brings.
+ */
+
+import * as path from 'node:path';
+import { pathToFileURL } from 'node:url';
+
+import { type AnnotatedSymbol } from '@indexing/annotations';
+import { PluginProcess } from '@indexing/plugin-host';
+import { logger } from '@utils/logger';
+import { utf16ToByteColumn } from '@utils/positions';
+import { NodeType, type Annotation, type ParsedNode, type ParseResult } from '@/types/indexing';
+import {
+  type AnalyzerMetric,
+  type AnalyzerPlugin,
+  type ExtractorPlugin,
+  type PluginApi,
+  type PluginExtraction,
+  type PluginInfo,
+} from '@/types/plugins';
+
+/** Prefix of PLUGINS entries naming a plugin command */
+export const EXEC_PREFIX = 'exec:';
+
+/** Node types of extractor symbol kinds; others are variables */
+const NODE_TYPES: Record<string, NodeType> = {
+  function: NodeType.Function,
+  // Methods of languages other than Go are listed as functions (see @indexing/symbols)
+  method: NodeType.Function,
+  class: NodeType.Class,
+  interface: NodeType.Interface,
+  type: NodeType.Type,
+  constant: NodeType.Variable,
+  variable: NodeType.Variable,
+};
+
+/** Extractors by the extensions they claim (lower case) */
+const extractors = new Map<string, { plugin: ExtractorPlugin; source: string }>();
+
+/** Analyzers in registration order */
+const analyzers: { plugin: AnalyzerPlugin; source: string }[] = [];
+
+/** PLUGINS entries loaded in this process */
+const loaded = new Set<string>();
+
+/** Plugin processes started, closed by closePlugins */
+const processes: PluginProcess[] = [];
+
+/**
+ * Register an extractor for a language cindex has no grammar for
+ *
+ * @param extractor - Extractor with its language and extensions
+ * @param source - PLUGINS entry registering it (default: api, a direct call)
+ * @throws {Error} If the extractor is incomplete or an extension is claimed by another extractor
+ */
+export const registerExtractor = (extractor: ExtractorPlugin, source = 'api'): void => {
+  const { name, language, extensions } = extractor;
+  if (!name || !language || !Array.isArray(extensions) || typeof extractor.extract !== 'function') {
+    throw new Error(`Extractor from ${source} needs a name, a language, extensions, and an extract function`);
+  }
+  const invalid = extractor.extensions.find((extension) => !/^\.[\w.+-]+$/.test(extension));
+  if (extractor.extensions.length === 0 || invalid !== undefined) {
+    throw new Error(`Extractor ${extractor.name}: expected extensions such as .abap, got ${invalid ?? 'none'}`);
+  }
+  for (const extension of extractor.extensions) {
+    const claimed = extractors.get(extension.toLowerCase());
+    if (claimed && claimed.plugin.name !== extractor.name) {
+      throw new Error(`Extractor ${extractor.name}: ${extension} is already read by ${claimed.plugin.name}`);
+    }
+  }
+  for (const extension of extractor.extensions) extractors.set(extension.toLowerCase(), { plugin: extractor, source });
+};
+
+/**
+ * Register an analyzer of indexed files
+ *
+ * @param analyzer - Analyzer with the languages it reads
+ * @param source - PLUGINS entry registering it (default: api, a direct call)
+ * @throws {Error} If the analyzer is incomplete or its name is taken
+ */
+export const registerAnalyzer = (analyzer: AnalyzerPlugin, source = 'api'): void => {
+  if (!analyzer.name || typeof analyzer.analyze !== 'function') {
+    throw new Error(`Analyzer from ${source} needs a name and an analyze function`);
+  }
+  if (analyzers.some((registered) => registered.plugin.name === analyzer.name)) {
+    throw new Error(`Analyzer ${analyzer.name} is already registered`);
+  }
+  analyzers.push({ plugin: analyzer, source });
+};
+
+/**
+ * Extractor claiming a file extension
+ *
+ * @param extension - Extension with the dot, e.g. .abap
+ */
+export const extractorForExtension = (extension: string): ExtractorPlugin | undefined =>
+  extractors.get(extension.toLowerCase())?.plugin;
+
+/**
+ * Extractor of a file, by its extension
+ */
+export const extractorForFile = (filePath: string): ExtractorPlugin | undefined =>
+  extractorForExtension(path.extname(filePath));
+
+/**
+ * Analyzers reading files of a language
+ */
+export const analyzersFor = (language: string): AnalyzerPlugin[] =>
+  analyzers
+    .map(({ plugin }) => plugin)
+    .filter((analyzer) => !analyzer.languages?.length || analyzer.languages.includes(language));
+
+/**
+ * Registered extractors and analyzers, extractors first
+ */
+export const listPlugins = (): PluginInfo[] => {
+  const byExtractor = new Map<ExtractorPlugin, string>();
+  for (const { plugin, source } of extractors.values()) byExtractor.set(plugin, source);
+  return [
+    ...[...byExtractor].map(([plugin, source]): PluginInfo => ({
+      kind: 'extractor',
+      name: plugin.name,
+      languages: [plugin.language],
+      extensions: plugin.extensions,
+      source,
+    })),
+    ...analyzers.map(({ plugin, source }): PluginInfo => ({
+      kind: 'analyzer',
+      name: plugin.name,
+      languages: plugin.languages ?? [],
+      extensions: [],
+      source,
+    })),
+  ];
+};
+
+/**
+ * Load PLUGINS entries not loaded yet: modules are imported, commands started
+ *
+ * @param specs - Module paths (relative to the working directory) and exec:<command> entries
+ * @throws {Error} If a module has no register export, or a plugin fails to load or register
+ */
+export const loadPlugins = async (specs: string[]): Promise<void> => {
+  for (const spec of specs) {
+    if (loaded.has(spec)) continue;
+
+    if (spec.startsWith(EXEC_PREFIX)) {
+      const plugin = new PluginProcess(spec.slice(EXEC_PREFIX.length).trim());
+      processes.push(plugin);
+      const served = await plugin.initialize();
+      for (const extractor of served.extractors) registerExtractor(extractor, spec);
+      for (const analyzer of served.analyzers) registerAnalyzer(analyzer, spec);
+    } else {
+      const module = (await import(pathToFileURL(path.resolve(spec)).href)) as { register?: unknown };
+      if (typeof module.register !== 'function') throw new Error(`Plugin ${spec} does not export register(cindex)`);
+      const api: PluginApi = {
+        registerExtractor: (extractor) => {
+          registerExtractor(extractor, spec);
+        },
+        registerAnalyzer: (analyzer) => {
+          registerAnalyzer(analyzer, spec);
+        },
+      };
+      await (module.register as (cindex: PluginApi) => unknown)(api);
+    }
+    loaded.add(spec);
+    logger.info('Loaded plugin', { plugin: spec });
+  }
+};
+
+/**
+ * Stop the plugin processes and forget every registration
+ */
+export const closePlugins = async (): Promise<void> => {
+  await Promise.all(processes.splice(0).map((plugin) => plugin.close()));
+  extractors.clear();
+  analyzers.splice(0);
+  loaded.clear();
+};
+
+/**
+ * Parse result of a file read by an extractor
+ *
+ * @param extraction - What the extractor found
+ * @param content - File content, the symbols' code is cut from
+ */
+export const toParseResult = (extraction: PluginExtraction, content: string): ParseResult => {
+  const lines = content.split('\n');
+  const nodes = extraction.symbols.map(
+    (symbol): ParsedNode => ({
+      node_type: NODE_TYPES[symbol.kind] ?? NodeType.Variable,
+      name: symbol.name,
+      start_line: symbol.start_line,
+      end_line: Math.max(symbol.end_line, symbol.start_line),
+      code_text: lines.slice(symbol.start_line - 1, Math.max(symbol.end_line, symbol.start_line)).join('\n'),
+      ...(symbol.docstring !== undefined && { docstring: symbol.docstring }),
+    })
+  );
+  const exported = extraction.symbols.filter((symbol) => symbol.exported).map((symbol) => symbol.name);
+  return {
+    success: true,
+    nodes,
+    imports: (extraction.imports ?? []).map((imported) => ({
+      symbols: imported.symbols ?? [],
+      source: imported.source,
+      is_default: false,
+      is_namespace: false,
+      line_number: imported.line,
+    })),
+    exports: exported.length > 0 ? [{ symbols: exported, is_default: false, is_reexport: false }] : [],
+    used_fallback: false,
+    ...(extraction.error !== undefined && { error: extraction.error, partial: true }),
+  };
+};
+
+/**
+ * Run the analyzers of a file's language
+ *
+ * @param file - File path, language, and content
+ * @param symbols - Symbols indexed from the file
+ * @returns Annotations (kind plugin, tagged as reported) and metrics, by analyzer
+ * @throws {Error} If an analyzer fails
+ */
+export const runAnalyzers = async (
+  file: { path: string; language: string; content: string },
+  symbols: AnnotatedSymbol[]
+): Promise<{ annotations: Annotation[]; metrics: AnalyzerMetric[] }> => {
+  const annotations: Annotation[] = [];
+  const metrics: AnalyzerMetric[] = [];
+  const selected = analyzersFor(file.language);
+  if (selected.length === 0) return { annotations, metrics };
+
+  const lines = file.content.split('\n');
+  const fileSymbols = symbols.map((symbol) => ({
+    name: symbol.symbol_name,
+    kind: symbol.symbol_type,
+    start_line: symbol.line_number,
+    end_line: symbol.end_line,
+  }));
+  for (const analyzer of selected) {
+    const analysis = await analyzer.analyze({ ...file, symbols: fileSymbols });
+    for (const annotation of analysis.annotations ?? []) {
+      const column = annotation.column ?? 1;
+      const symbol = annotation.symbol_name
+        ? symbols.find((candidate) => candidate.symbol_name === annotation.symbol_name)
+        : undefined;
+      annotations.push({
+        kind: 'plugin',
+        tag: annotation.tag,
+        assignee: null,
+        text: annotation.text,
+        line: annotation.line,
+        column,
+        byte_column: utf16ToByteColumn(lines[annotation.line - 1] ?? '', column),
+        symbol_name: annotation.symbol_name ?? null,
+        symbol_type: symbol?.symbol_type ?? null,
+      });
+    }
+    for (const metric of analysis.metrics ?? []) metrics.push({ ...metric, analyzer: analyzer.name });
+  }
+  return { annotations, metrics };
+};
@@ -0,0 +1,299 @@
+/**
+ * Remote and archive sources: indexing code without a local checkout (cindex index <url|archive>)
+ *
+ *   cindex index https://github.com/org/repo@v1.2.3     shallow fetch of a tag, branch, or commit
+ *   cindex index git@github.com:org/repo.git            the default branch
+ *   cindex index ./vendor/lib-2.0.tar.gz                a local archive (.tar.gz, .tgz, .zip)
+ *   cindex index https://example.com/lib-2.0.zip        an archive downloaded first
+ *
+ * The source is fetched or extracted under ~/.cindex/sources/ and indexed by
+ * the regular pipeline into its own index (default: the repository or archive
+ * name, with the ref after @), whose metadata records where it came from and,
+ * for git, the commit fetched. Stored paths start with <index>/ (see
+ * sourceDirName), the layout of revision indexes (see @indexing/revisions), so
+ * a source never collides with a working copy's paths. The fetched tree is removed once indexed: file
+ * content is kept in the index. Archives holding a single top-level directory
+ * (as GitHub and most release tarballs do) are indexed from inside it.
+ */
+
+import { execFile } from 'node:child_process';
+import * as fs from 'node:fs/promises';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import { promisify } from 'node:util';
+
+import { type DatabaseClient } from '@database/client';
+import { createPipeline } from '@indexing/pipeline';
+import { logger } from '@utils/logger';
+import { type OllamaClient } from '@utils/ollama';
+import { type CindexConfig } from '@/types/config';
+import { type IndexingOptions, type IndexingStats } from '@/types/indexing';
+
+const execFileAsync = promisify(execFile);
+
+/** Fetched and extracted sources being indexed, one directory per index */
+const SOURCES_DIR = path.join(os.homedir(), '.cindex', 'sources');
+
+/** Archive formats, by file name suffix */
+const ARCHIVE_SUFFIXES: [string, SourceSpec['format']][] = [
+  ['.tar.gz', 'tar.gz'],
+  ['.tgz', 'tar.gz'],
+  ['.zip', 'zip'],
+];
+
+/** URL schemes of git remotes */
+const GIT_SCHEMES = /^(https?|ssh|git|git\+ssh|file):\/\//;
+
+/** scp-like git remotes: user@host:path */
+const SCP_REMOTE = /^[\w.-]+@[\w.-]+:(?!\/\/)/;
+
+/**
+ * Where the content of a remote or archive index comes from
+ */
+export interface SourceSpec {
+  kind: 'git' | 'archive';
+  /** Git remote, archive URL, or archive path, without the @ref */
+  location: string;
+  /** Tag, branch, or commit of a git remote (null: its default branch) */
+  ref: string | null;
+  /** Archive format (null for git) */
+  format: 'tar.gz' | 'zip' | null;
+}
+
+/**
+ * Result of indexing a source
+ */
+export interface SourceIndexResult {
+  repo_id: string;
+  source: SourceSpec;
+  /** Commit fetched from a git remote (null for archives) */
+  commit: string | null;
+  stats: IndexingStats;
+}
+
+/**
+ * Read an index argument as a remote or archive source
+ *
+ * @param input - Argument of cindex index
+ * @returns Source, or null for a local directory
+ */
+export const parseSourceSpec = (input: string): SourceSpec | null => {
+  // Download URLs may carry a query: lib-2.0.tar.gz?sig=...
+  const file = /^https?:\/\//.test(input) ? input.replace(/[?#].*$/, '') : input;
+  const archive = ARCHIVE_SUFFIXES.find(([suffix]) => file.toLowerCase().endsWith(suffix));
+  if (archive) return { kind: 'archive', location: input, ref: null, format: archive[1] };
+  if (!GIT_SCHEMES.test(input) && !SCP_REMOTE.test(input)) return null;
+
+  // The ref follows the first @ of the path, after any user@ of the host: user@host:org/repo@feature/login
+  const scheme = GIT_SCHEMES.exec(input);
+  const pathStart = scheme ? input.indexOf('/', scheme[0].length) : input.indexOf(':');
+  const at = pathStart === -1 ? -1 : input.indexOf('@', pathStart);
+  if (at === -1) return { kind: 'git', location: input, ref: null, format: null };
+  const ref = input.slice(at + 1);
+  return { kind: 'git', location: input.slice(0, at), ref: ref === '' ? null : ref, format: null };
+};
+
+/**
+ * Location without credentials, for printing and index metadata
+ */
+export const redactLocation = (location: string): string => {
+  if (!GIT_SCHEMES.test(location)) return location;
+  try {
+    const url = new URL(location);
+    if (url.password === '' && url.username === '') return location;
+    url.username = '';
+    url.password = '';
+    return url.toString();
+  } catch {
+    return location;
+  }
+};
+
+/**
+ * Default index ID of a source: repository or archive name, with the ref after @
+ *
+ * @returns e.g. repo@v1.2.3 for https://github.com/org/repo.git@v1.2.3, lib-2.0 for lib-2.0.tar.gz
+ */
+export const sourceIndexId = (source: SourceSpec): string => {
+  const trimmed = source.location.replace(/[/\\]+$/, '');
+  let name = trimmed.slice(Math.max(trimmed.lastIndexOf('/'), trimmed.lastIndexOf('\\'), trimmed.indexOf(':')) + 1);
+  name = name.replace(/[?#].*$/, '');
+  if (source.kind === 'archive') {
+    const suffix = ARCHIVE_SUFFIXES.find(([candidate]) => name.toLowerCase().endsWith(candidate));
+    name = suffix ? name.slice(0, -suffix[0].length) : name;
+  } else {
+    name = name.replace(/\.git$/, '');
+  }
+  const base = name.replace(/[^\w.-]/g, '_') || 'source';
+  return source.ref ? `${base}@${source.ref.replace(/[^\w.-]/g, '_')}` : base;
+};
+
+/**
+ * Run git, reporting its stderr on failure
+ */
+const git = async (args: string[]): Promise<string> => {
+  try {
+    const { stdout } = await execFileAsync('git', args);
+    return stdout.trim();
+  } catch (error) {
+    const stderr = (error as { stderr?: string }).stderr?.trim();
+    throw new Error(stderr || (error instanceof Error ? error.message : String(error)));
+  }
+};
+
+/**
+ * Fetch one commit of a git remote into a directory, without history
+ *
+ * @returns Commit fetched
+ * @throws {Error} If the remote or ref cannot be fetched
+ */
+const fetchGit = async (source: SourceSpec, destination: string): Promise<string> => {
+  // A ref or remote starting with - would be read as an option
+  if (source.ref?.startsWith('-') || source.location.startsWith('-')) {
+    throw new Error(`Cannot fetch ${source.location}: not a remote or revision name`);
+  }
+  await fs.mkdir(destination, { recursive: true });
+  await git(['-C', destination, 'init', '--quiet']);
+  try {
+    await git(['-C', destination, 'fetch', '--quiet', '--depth', '1', source.location, source.ref ?? 'HEAD']);
+  } catch (error) {
+    const reason = error instanceof Error ? error.message : String(error);
+    throw new Error(`Cannot fetch ${redactLocation(source.location)}@${source.ref ?? 'HEAD'}: ${reason}`);
+  }
+  await git(['-C', destination, 'checkout', '--quiet', '--detach', 'FETCH_HEAD']);
+  const commit = await git(['-C', destination, 'rev-parse', 'HEAD']);
+  await fs.rm(path.join(destination, '.git'), { recursive: true, force: true });
+  return commit;
+};
+
+/**
+ * Download an archive URL to a file
+ *
+ * @throws {Error} If the server does not answer with the archive
+ */
+const downloadArchive = async (url: string, file: string): Promise<void> => {
+  const response = await fetch(url, { redirect: 'follow' });
+  if (!response.ok) {
+    throw new Error(`Cannot download ${redactLocation(url)}: HTTP ${String(response.status)} ${response.statusText}`);
+  }
+  await fs.writeFile(file, Buffer.from(await response.arrayBuffer()));
+};
+
+/**
+ * Extract an archive into a directory with tar or unzip
+ *
+ * Both refuse entries that would land outside the directory (absolute paths, ..).
+ *
+ * @throws {Error} If the archive cannot be read
+ */
+const extractArchive = async (archive: string, format: 'tar.gz' | 'zip', destination: string): Promise<void> => {
+  await fs.mkdir(destination, { recursive: true });
+  const [command, args] =
+    format === 'zip' ? ['unzip', ['-q', archive, '-d', destination]] : ['tar', ['-xzf', archive, '-C', destination]];
+  try {
+    await execFileAsync(command, args);
+  } catch (error) {
+    const stderr = (error as { stderr?: string }).stderr?.trim();
+    const reason = stderr || (error instanceof Error ? error.message : String(error));
+    throw new Error(`Cannot extract ${path.basename(archive)} with ${command}: ${reason}`);
+  }
+};
+
+/**
+ * Directory to index of an extracted archive: its only top-level directory, if it has one and nothing else
+ */
+export const archiveRoot = async (directory: string): Promise<string> => {
+  const entries = await fs.readdir(directory, { withFileTypes: true });
+  const [only] = entries;
+  return entries.length === 1 && only.isDirectory() ? path.join(directory, only.name) : directory;
+};
+
+/**
+ * Directory name of an index's source tree: the index ID with characters unsafe in a file name
+ * replaced, and never . or .. (an index ID is chosen by the caller)
+ */
+export const sourceDirName = (repoId: string): string => {
+  const name = repoId.replace(/[^\w.@-]/g, '_');
+  return /^\.*$/.test(name) ? name.replace(/\./g, '_') || 'source' : name;
+};
+
+/**
+ * Remove a directory of SOURCES_DIR
+ *
+ * @throws {Error} If the directory does not resolve inside SOURCES_DIR
+ */
+const removeSourceDir = async (directory: string): Promise<void> => {
+  const relative = path.relative(SOURCES_DIR, path.resolve(directory));
+  if (!relative || relative.startsWith('..') || path.isAbsolute(relative)) {
+    throw new Error(`Refusing to remove ${directory}: not inside ${SOURCES_DIR}`);
+  }
+  await fs.rm(directory, { recursive: true, force: true });
+};
+
+/**
+ * Index a remote git repository or an archive, without a local checkout
+ *
+ * @param config - Loaded configuration
+ * @param db - Connected database client
+ * @param ollama - Ollama client (healthy)
+ * @param source - Parsed source (see parseSourceSpec)
+ * @param options - Indexing options; repoId names the index (default: sourceIndexId)
+ * @returns Index ID, commit fetched, and indexing statistics
+ * @throws {Error} If the source cannot be fetched or extracted
+ */
+export const indexSource = async (
+  config: CindexConfig,
+  db: DatabaseClient,
+  ollama: OllamaClient,
+  source: SourceSpec,
+  options: IndexingOptions
+): Promise<SourceIndexResult> => {
+  const repoId = options.repoId ?? sourceIndexId(source);
+
+  // The root holds one directory named after the index, so stored paths start with <index>/
+  const name = sourceDirName(repoId);
+  const root = path.join(SOURCES_DIR, name);
+  const tree = path.join(root, name);
+  await removeSourceDir(root);
+  try {
+    let commit: string | null = null;
+    if (source.kind === 'git') {
+      commit = await fetchGit(source, tree);
+    } else {
+      const format = source.format ?? 'tar.gz';
+      let archive = path.resolve(source.location);
+      if (/^https?:\/\//.test(source.location)) {
+        archive = path.join(root, `download.${format}`);
+        await fs.mkdir(root, { recursive: true });
+        await downloadArchive(source.location, archive);
+      }
+      const extracted = path.join(root, 'extracted');
+      await extractArchive(archive, format, extracted);
+      await fs.rename(await archiveRoot(extracted), tree);
+      // Only the tree is left under the root, so nothing else is walked
+      await fs.rm(extracted, { recursive: true, force: true });
+      if (archive.startsWith(root)) await fs.rm(archive, { force: true });
+    }
+    logger.info('Fetched source', { source: redactLocation(source.location), ref: source.ref, commit });
+
+    const sourceOptions: IndexingOptions = {
+      ...options,
+      since: undefined,
+      repoId,
+      repoName: options.repoName ?? repoId,
+      subdirectory: name,
+      metadata: {
+        source: redactLocation(source.location),
+        source_kind: source.kind,
+        ...(source.ref !== null && { source_ref: source.ref }),
+        ...(commit !== null && { source_commit: commit }),
+        fetched_at: new Date().toISOString(),
+      },
+    };
+    const pipeline = createPipeline(config, db, ollama, root, sourceOptions);
+    const stats = await pipeline.indexRepository(root, sourceOptions);
+    return { repo_id: repoId, source, commit, stats };
+  } finally {
+    await removeSourceDir(root);
+  }
+};
@@ -0,0 +1,277 @@
+/**
+ * Revision indexes: a repository as of a git commit (cindex index --rev)
+ *
+ * The tree of the commit is read from the object database (git ls-tree and
+ * git cat-file --batch), so the working tree, index, and checked-out branch
+ * are left untouched. Blobs are held in memory and handed to the regular
+ * pipeline as the files to index (see @indexing/memory-tree), nothing is
+ * written to disk; they go into their own index, <repo>@<commit> (the first
+ * 12 hex digits), whose metadata records the full commit and the name it was
+ * asked for. Stored paths start with <repo>@<commit>/, the same layout as
+ * ephemeral indexes, so revisions never collide with the working copy's
+ * paths. Type facts and blame history need a checkout and are not recorded.
+ *
+ * Revision indexes are searched only when named with --repo-id; cindex
+ * diff-symbols compares two of them.
+ */
+
+import { execFile, spawn, type ChildProcessByStdio } from 'node:child_process';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import { type Readable, type Writable } from 'node:stream';
+import { promisify } from 'node:util';
+
+import { type DatabaseClient } from '@database/client';
+import { createPipeline } from '@indexing/pipeline';
+import { logger } from '@utils/logger';
+import { type OllamaClient } from '@utils/ollama';
+import { type CindexConfig } from '@/types/config';
+import { type IndexingOptions, type IndexingStats, type MemoryFile } from '@/types/indexing';
+
+const execFileAsync = promisify(execFile);
+
+/** Root recorded for revision indexes, one per index (nominal: their files are read from memory) */
+const REVISIONS_DIR = path.join(os.homedir(), '.cindex', 'revisions');
+
+/** Hex digits of the commit in a revision index ID */
+const REVISION_ID_LENGTH = 12;
+
+/** Maximum git output buffered (ls-tree of large repositories) */
+const GIT_MAX_BUFFER = 64 * 1024 * 1024;
+
+/**
+ * A commit a revision name resolved to
+ */
+export interface ResolvedRevision {
+  /** Full commit hash */
+  commit: string;
+  /** Name as given (tag, branch, or hash) */
+  ref: string;
+  /** Committer date */
+  committed_at: Date;
+}
+
+/**
+ * File of a commit's tree
+ */
+export interface TreeEntry {
+  /** Blob hash */
+  blob: string;
+  /** Path relative to the repository root, forward slashes */
+  path: string;
+}
+
+/**
+ * Result of indexing a revision
+ */
+export interface RevisionIndexResult {
+  repo_id: string;
+  revision: ResolvedRevision;
+  /** Files read from the commit's tree */
+  files: number;
+  stats: IndexingStats;
+}
+
+/**
+ * Index ID of a repository at a commit
+ *
+ * @param repoId - Index ID of the working copy
+ * @param commit - Full or abbreviated commit hash
+ * @returns <repoId>@<first 12 hex digits>
+ */
+export const revisionIndexId = (repoId: string, commit: string): string =>
+  `${repoId}@${commit.slice(0, REVISION_ID_LENGTH)}`;
+
+/**
+ * Resolve a tag, branch, or hash to a commit
+ *
+ * @param repoPath - Repository (any directory of the worktree)
+ * @param ref - Revision name (anything git rev-parse accepts)
+ * @returns Commit and its date
+ * @throws {Error} If repoPath is not a git repository or ref names no commit
+ */
+export const resolveRevision = async (repoPath: string, ref: string): Promise<ResolvedRevision> => {
+  // A name starting with - would be read as an option
+  if (ref.startsWith('-')) throw new Error(`Cannot resolve revision '${ref}': not a revision name`);
+  try {
+    const args = ['-C', repoPath, 'log', '-1', '--format=%H %ct', `${ref}^{commit}`, '--'];
+    const { stdout } = await execFileAsync('git', args);
+    const [commit, seconds] = stdout.trim().split(' ');
+    return { commit, ref, committed_at: new Date(Number(seconds) * 1000) };
+  } catch (error) {
+    const stderr = (error as { stderr?: string }).stderr?.trim();
+    throw new Error(`Cannot resolve revision '${ref}': ${stderr || (error instanceof Error ? error.message : '')}`);
+  }
+};
+
+/**
+ * Parse `git ls-tree -r -z` output into the files to index
+ *
+ * Symbolic links (mode 120000) and submodules (commit entries) are left out:
+ * neither has content of its own at the commit.
+ *
+ * @param output - NUL-separated entries: <mode> <type> <hash><TAB><path>
+ * @returns Regular files of the tree
+ */
+export const parseTreeEntries = (output: string): TreeEntry[] => {
+  const entries: TreeEntry[] = [];
+  for (const record of output.split('\0')) {
+    const tab = record.indexOf('\t');
+    if (tab === -1) continue;
+    const [mode, type, blob] = record.slice(0, tab).split(' ');
+    if (type !== 'blob' || mode === '120000') continue;
+    entries.push({ blob, path: record.slice(tab + 1) });
+  }
+  return entries;
+};
+
+/**
+ * Reader of blobs through one `git cat-file --batch` process
+ *
+ * Requests are answered in order; each read resolves with the blob's bytes.
+ */
+class BlobReader {
+  private readonly git: ChildProcessByStdio<Writable, Readable, null>;
+  private buffer = Buffer.alloc(0);
+  private readonly pending: { resolve: (content: Buffer) => void; reject: (error: Error) => void }[] = [];
+
+  constructor(repoPath: string) {
+    this.git = spawn('git', ['-C', repoPath, 'cat-file', '--batch'], { stdio: ['pipe', 'pipe', 'ignore'] });
+    this.git.stdout.on('data', (chunk: Buffer) => {
+      this.buffer = Buffer.concat([this.buffer, chunk]);
+      this.drain();
+    });
+    this.git.on('error', (error) => {
+      this.fail(error);
+    });
+    this.git.on('exit', (code) => {
+      this.fail(new Error(`git cat-file exited with code ${String(code)}`));
+    });
+  }
+
+  /**
+   * Read one blob
+   */
+  public read = (blob: string): Promise<Buffer> =>
+    new Promise<Buffer>((resolve, reject) => {
+      this.pending.push({ resolve, reject });
+      this.git.stdin.write(`${blob}\n`);
+    });
+
+  /**
+   * End the git process
+   */
+  public close = (): void => {
+    this.git.stdin.end();
+  };
+
+  /**
+   * Resolve reads whose reply is complete: <hash> <type> <size>\n<content>\n
+   */
+  private drain = (): void => {
+    while (this.pending.length > 0) {
+      const newline = this.buffer.indexOf(10);
+      if (newline === -1) return;
+      const header = this.buffer.subarray(0, newline).toString('utf-8').split(' ');
+      if (header[1] === 'missing') {
+        this.buffer = this.buffer.subarray(newline + 1);
+        this.pending.shift()?.reject(new Error(`Object ${header[0]} is missing`));
+        continue;
+      }
+
+      const size = Number(header[2]);
+      const end = newline + 1 + size;
+      if (this.buffer.length < end + 1) return;
+      const content = Buffer.from(this.buffer.subarray(newline + 1, end));
+      this.buffer = this.buffer.subarray(end + 1);
+      this.pending.shift()?.resolve(content);
+    }
+  };
+
+  private fail = (error: Error): void => {
+    for (const read of this.pending.splice(0)) read.reject(error);
+  };
+}
+
+/**
+ * Read the files of a commit's tree into memory
+ *
+ * Files get the commit's date as their modification time.
+ *
+ * @param repoPath - Repository
+ * @param revision - Resolved commit
+ * @param prefix - Directory the paths are placed under (the revision index ID)
+ * @returns Files with paths <prefix>/<path in the tree>
+ * @throws {Error} If git fails or a blob cannot be read
+ */
+export const readRevisionFiles = async (
+  repoPath: string,
+  revision: ResolvedRevision,
+  prefix: string
+): Promise<MemoryFile[]> => {
+  const args = ['-C', repoPath, 'ls-tree', '-r', '-z', '--full-tree', revision.commit];
+  const { stdout } = await execFileAsync('git', args, { maxBuffer: GIT_MAX_BUFFER });
+  const entries = parseTreeEntries(stdout);
+
+  const reader = new BlobReader(repoPath);
+  try {
+    const files: MemoryFile[] = [];
+    for (const entry of entries) {
+      const content = await reader.read(entry.blob);
+      files.push({ path: `${prefix}/${entry.path}`, content, modified_time: revision.committed_at });
+    }
+    return files;
+  } finally {
+    reader.close();
+  }
+};
+
+/**
+ * Index a repository as of a commit, without checking it out
+ *
+ * @param config - Loaded configuration
+ * @param db - Connected database client
+ * @param ollama - Ollama client (healthy)
+ * @param repoPath - Repository (worktree or bare)
+ * @param revision - Resolved commit
+ * @param options - Indexing options; repoId names the working copy's index (default: directory name)
+ * @returns Revision index ID, files read, and indexing statistics
+ * @throws {Error} If the tree cannot be read
+ */
+export const indexRevision = async (
+  config: CindexConfig,
+  db: DatabaseClient,
+  ollama: OllamaClient,
+  repoPath: string,
+  revision: ResolvedRevision,
+  options: IndexingOptions
+): Promise<RevisionIndexResult> => {
+  const sourceId = options.repoId ?? path.basename(repoPath);
+  const repoId = revisionIndexId(sourceId, revision.commit);
+
+  // Paths start with <repo>@<commit>/ below a root nothing is written to
+  const root = path.join(REVISIONS_DIR, repoId);
+  const files = await readRevisionFiles(repoPath, revision, repoId);
+  logger.info('Read revision', { repo: repoPath, commit: revision.commit, files: files.length });
+
+  const revisionOptions: IndexingOptions = {
+    ...options,
+    incremental: false,
+    since: undefined,
+    typed: false,
+    history: false,
+    repoId,
+    repoName: `${options.repoName ?? sourceId}@${revision.ref}`,
+    files,
+    metadata: {
+      revision: revision.commit,
+      revision_ref: revision.ref,
+      revision_of: sourceId,
+      committed_at: revision.committed_at.toISOString(),
+      exclude_from_default_search: true,
+    },
+  };
+  const pipeline = createPipeline(config, db, ollama, root, revisionOptions);
+  const stats = await pipeline.indexRepository(root, revisionOptions);
+  return { repo_id: repoId, revision, files: files.length, stats };
+};
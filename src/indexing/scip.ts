@@ -0,0 +1,444 @@
+/**
+ * SCIP index export (cindex export --format scip)
+ *
+ * Converts an index into a SCIP index (https://github.com/sourcegraph/scip),
+ * the format Sourcegraph uploads and other code intelligence tools read:
+ *
+ * - definitions: every symbol with a structured ID (see @indexing/symbol-ids),
+ *   its name's range on the declaration line and the declaration's lines as
+ *   the enclosing range
+ * - hover docs: the symbol's definition as a code block, then its doc comment
+ * - references: type-checked Go references (cindex index --typed) to indexed
+ *   symbols, with read and write access
+ * - implementation edges: Go types to the interfaces they satisfy; interfaces
+ *   outside the index are listed as external symbols named by import path
+ * - type parameters of Go generics, as symbols of their function or type
+ *
+ * The index is written as a Metadata message followed by one Document at a
+ * time: concatenated Index messages decode as one, so the output is never
+ * encoded whole. Positions are UTF-8 byte offsets from the line start, 0-based.
+ */
+
+import { pathToFileURL } from 'node:url';
+
+import { type Pool } from 'pg';
+import protobuf from 'protobufjs';
+
+import {
+  listGoTypeParameters,
+  listScipImplementations,
+  listScipReferences,
+  listScipSymbols,
+} from '@database/queries';
+import {
+  formatSymbolId,
+  parseSymbolId,
+  SYMBOL_ID_SCHEME,
+  symbolKind,
+  typeParameterId,
+  type SymbolDescriptor,
+  type SymbolKind,
+} from '@indexing/symbol-ids';
+import { compareStrings } from '@utils/ordering';
+import { IDENTIFIER_CHAR_PATTERN } from '@utils/unicode';
+import {
+  type GoReferenceAccess,
+  type GoTypeParameterRecord,
+  type ScipImplementationRecord,
+  type ScipReferenceRecord,
+  type ScipSymbolRecord,
+} from '@/types/database';
+
+/**
+ * Wire schema: the part of scip.proto the export writes (field numbers as upstream)
+ */
+export const SCIP_PROTO = `
+syntax = "proto3";
+package scip;
+
+message Index {
+  Metadata metadata = 1;
+  repeated Document documents = 2;
+  repeated SymbolInformation external_symbols = 3;
+}
+
+message Metadata {
+  int32 version = 1;
+  ToolInfo tool_info = 2;
+  string project_root = 3;
+  int32 text_document_encoding = 4;
+}
+
+message ToolInfo {
+  string name = 1;
+  string version = 2;
+  repeated string arguments = 3;
+}
+
+message Document {
+  string relative_path = 1;
+  repeated Occurrence occurrences = 2;
+  repeated SymbolInformation symbols = 3;
+  string language = 4;
+  int32 position_encoding = 6;
+}
+
+message SymbolInformation {
+  string symbol = 1;
+  repeated string documentation = 3;
+  repeated Relationship relationships = 4;
+  int32 kind = 5;
+  string display_name = 6;
+  string enclosing_symbol = 8;
+}
+
+message Relationship {
+  string symbol = 1;
+  bool is_reference = 2;
+  bool is_implementation = 3;
+  bool is_type_definition = 4;
+  bool is_definition = 5;
+}
+
+message Occurrence {
+  repeated int32 range = 1;
+  string symbol = 2;
+  int32 symbol_roles = 3;
+  repeated int32 enclosing_range = 7;
+}
+`;
+
+/** SymbolRole bits of an occurrence */
+export const SCIP_ROLES = { definition: 0x1, writeAccess: 0x4, readAccess: 0x8 } as const;
+
+/** Roles of reference accesses */
+const ACCESS_ROLES: Partial<Record<GoReferenceAccess, number>> = {
+  read: SCIP_ROLES.readAccess,
+  write: SCIP_ROLES.writeAccess,
+};
+
+/** SymbolInformation.Kind of each kind of the taxonomy */
+const SCIP_KINDS: Record<SymbolKind, number> = {
+  func: 17,
+  method: 26,
+  struct: 49,
+  class: 7,
+  interface: 21,
+  type: 54,
+  const: 8,
+  var: 61,
+  field: 15,
+  typeparam: 58,
+};
+
+/** SCIP names of the indexed languages */
+const SCIP_LANGUAGES: Record<string, string> = {
+  typescript: 'TypeScript',
+  javascript: 'JavaScript',
+  python: 'Python',
+  java: 'Java',
+  go: 'Go',
+  rust: 'Rust',
+  c: 'C',
+  cpp: 'CPP',
+  ruby: 'Ruby',
+  php: 'PHP',
+  csharp: 'CSharp',
+  swift: 'Swift',
+  kotlin: 'Kotlin',
+};
+
+/** TextEncoding.UTF8 */
+const TEXT_ENCODING_UTF8 = 1;
+
+/** PositionEncoding.UTF8CodeUnitOffsetFromLineStart */
+const POSITION_ENCODING_UTF8 = 1;
+
+export interface ScipRelationship {
+  symbol: string;
+  is_implementation: boolean;
+}
+
+export interface ScipSymbolInformation {
+  symbol: string;
+  documentation: string[];
+  relationships: ScipRelationship[];
+  kind: number;
+  display_name: string;
+  enclosing_symbol?: string;
+}
+
+export interface ScipOccurrence {
+  /** [line, start, end] on one line, or [start line, start, end line, end] */
+  range: number[];
+  symbol: string;
+  symbol_roles: number;
+  enclosing_range?: number[];
+}
+
+export interface ScipDocument {
+  relative_path: string;
+  language: string;
+  occurrences: ScipOccurrence[];
+  symbols: ScipSymbolInformation[];
+  position_encoding: number;
+}
+
+/**
+ * What an index holds for the export, as read from the database
+ */
+export interface ScipSource {
+  symbols: ScipSymbolRecord[];
+  references: ScipReferenceRecord[];
+  implementations: ScipImplementationRecord[];
+  typeParameters: GoTypeParameterRecord[];
+}
+
+/**
+ * Documents and external symbols of a SCIP index
+ */
+export interface ScipIndexContent {
+  documents: ScipDocument[];
+  external_symbols: ScipSymbolInformation[];
+}
+
+/**
+ * Counts of an export
+ */
+export interface ScipExportResult {
+  documents: number;
+  symbols: number;
+  occurrences: number;
+}
+
+const IndexMessage = protobuf.parse(SCIP_PROTO, { keepCase: true }).root.lookupType('scip.Index');
+
+/**
+ * Length of text in UTF-8 bytes
+ */
+const byteLength = (text: string): number => Buffer.byteLength(text, 'utf8');
+
+/**
+ * Last part of a symbol name (Login of Service.Login, expired_token of TestLogin/expired_token)
+ */
+const displayName = (name: string): string => name.slice(Math.max(name.lastIndexOf('.'), name.lastIndexOf('/')) + 1);
+
+/**
+ * Range of a symbol's name on its declaration line
+ *
+ * Without the line's text, or with the name not on it, the range is the
+ * empty one at the start of the line.
+ */
+export const definitionRange = (line: number, lineText: string | null, name: string): number[] => {
+  const escaped = name.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+  const match = lineText
+    ? new RegExp(`(?<!${IDENTIFIER_CHAR_PATTERN})${escaped}(?!${IDENTIFIER_CHAR_PATTERN})`, 'u').exec(lineText)
+    : null;
+  if (!match || !lineText) return [line - 1, 0, 0];
+  const start = byteLength(lineText.slice(0, match.index));
+  return [line - 1, start, start + byteLength(name)];
+};
+
+/**
+ * Hover documentation: the definition as a code block, then the doc comment
+ */
+const documentation = (symbol: ScipSymbolRecord): string[] => {
+  const docs: string[] = [];
+  if (symbol.definition) docs.push(`\`\`\`${symbol.language ?? ''}\n${symbol.definition.trim()}\n\`\`\``);
+  if (symbol.doc_comment) docs.push(symbol.doc_comment);
+  return docs;
+};
+
+/**
+ * ID of a Go type outside the index, by import path ('' for the universe scope: error)
+ */
+const externalTypeId = (importPath: string, name: string): string => {
+  const descriptors: SymbolDescriptor[] = importPath ? [{ name: importPath, suffix: 'namespace' }] : [];
+  descriptors.push({ name, suffix: 'type' });
+  return formatSymbolId({ scheme: SYMBOL_ID_SCHEME, manager: 'gomod', package: '.', version: '.', descriptors });
+};
+
+/**
+ * Key of a Go type: its import path and name
+ */
+const goTypeKey = (importPath: string, name: string): string => `${importPath}\u0000${name}`;
+
+/**
+ * Convert what an index holds into SCIP documents
+ *
+ * @param source - Symbols, references, implementations, and type parameters of the index
+ * @returns Documents ordered by path, and the interfaces outside the index that types implement
+ */
+export const buildScipIndex = (source: ScipSource): ScipIndexContent => {
+  const documents = new Map<string, ScipDocument>();
+  const documentOf = (filePath: string, language: string | null): ScipDocument => {
+    let document = documents.get(filePath);
+    if (!document) {
+      document = {
+        relative_path: filePath,
+        language: SCIP_LANGUAGES[language ?? ''] ?? '',
+        occurrences: [],
+        symbols: [],
+        position_encoding: POSITION_ENCODING_UTF8,
+      };
+      documents.set(filePath, document);
+    }
+    return document;
+  };
+
+  // Go types by import path and name, for the interfaces implemented
+  const goTypes = new Map<string, string>();
+  const infos = new Map<string, ScipSymbolInformation>();
+  const idsByDeclaration = new Map<string, string>();
+  for (const symbol of source.symbols) {
+    const kind = symbolKind(symbol.symbol_type, symbol.language);
+    const document = documentOf(symbol.file_path, symbol.language);
+    const name = displayName(symbol.symbol_name);
+    idsByDeclaration.set(`${symbol.file_path}\u0000${symbol.symbol_name}`, symbol.symbol_id);
+
+    const endLine = symbol.end_line ?? symbol.line_number;
+    document.occurrences.push({
+      range: definitionRange(symbol.line_number, symbol.line_text, name),
+      symbol: symbol.symbol_id,
+      symbol_roles: SCIP_ROLES.definition,
+      enclosing_range: [symbol.line_number - 1, 0, endLine, 0],
+    });
+    // Declarations repeated for other platforms (build constraints) share one symbol
+    if (infos.has(symbol.symbol_id)) continue;
+    const info: ScipSymbolInformation = {
+      symbol: symbol.symbol_id,
+      documentation: documentation(symbol),
+      relationships: [],
+      kind: SCIP_KINDS[kind],
+      display_name: name,
+    };
+    infos.set(symbol.symbol_id, info);
+    document.symbols.push(info);
+
+    const namespace = parseSymbolId(symbol.symbol_id)?.descriptors[0];
+    if (symbol.language === 'go' && namespace?.suffix === 'namespace' && (kind === 'interface' || kind === 'type')) {
+      goTypes.set(goTypeKey(namespace.name, symbol.symbol_name), symbol.symbol_id);
+    }
+  }
+
+  for (const reference of source.references) {
+    if (!reference.target_symbol_id) continue;
+    const start = reference.column_number - 1;
+    documentOf(reference.file_path, 'go').occurrences.push({
+      range: [reference.line_number - 1, start, start + byteLength(displayName(reference.target_name))],
+      symbol: reference.target_symbol_id,
+      // Calls, and references resolved without their access, have no access role
+      symbol_roles: reference.access ? (ACCESS_ROLES[reference.access] ?? 0) : 0,
+    });
+  }
+
+  const external = new Map<string, ScipSymbolInformation>();
+  for (const implementation of source.implementations) {
+    const info = implementation.type_symbol_id ? infos.get(implementation.type_symbol_id) : undefined;
+    if (!info) continue;
+    const { interface_package: importPath, interface_name: name } = implementation;
+    let target = goTypes.get(goTypeKey(importPath, name));
+    if (!target) {
+      target = externalTypeId(importPath, name);
+      if (!external.has(target)) {
+        external.set(target, {
+          symbol: target,
+          documentation: [],
+          relationships: [],
+          kind: SCIP_KINDS.interface,
+          display_name: name,
+        });
+      }
+    }
+    if (!info.relationships.some((relationship) => relationship.symbol === target)) {
+      info.relationships.push({ symbol: target, is_implementation: true });
+    }
+  }
+
+  for (const parameter of source.typeParameters) {
+    const owner = idsByDeclaration.get(`${parameter.file_path}\u0000${parameter.symbol_name}`);
+    if (!owner) continue;
+    const symbol = typeParameterId(owner, parameter.parameter_name);
+    if (infos.has(symbol)) continue;
+    const info: ScipSymbolInformation = {
+      symbol,
+      documentation: [`\`\`\`go\n${parameter.parameter_name} ${parameter.constraint_text}\n\`\`\``],
+      relationships: [],
+      kind: SCIP_KINDS.typeparam,
+      display_name: parameter.parameter_name,
+      enclosing_symbol: owner,
+    };
+    infos.set(symbol, info);
+    documentOf(parameter.file_path, 'go').symbols.push(info);
+  }
+
+  const compareRanges = (a: ScipOccurrence, b: ScipOccurrence): number =>
+    a.range[0] - b.range[0] || a.range[1] - b.range[1];
+  const sorted = [...documents.values()].sort((a, b) => compareStrings(a.relative_path, b.relative_path));
+  for (const document of sorted) document.occurrences.sort(compareRanges);
+  return { documents: sorted, external_symbols: [...external.values()] };
+};
+
+/**
+ * Encode part of an Index message (parts concatenate into one index)
+ */
+export const encodeScipIndex = (message: {
+  metadata?: { project_root: string; arguments: string[] };
+  documents?: ScipDocument[];
+  external_symbols?: ScipSymbolInformation[];
+}): Buffer => {
+  const metadata = message.metadata
+    ? {
+        version: 0,
+        tool_info: { name: 'cindex', version: '', arguments: message.metadata.arguments },
+        project_root: message.metadata.project_root,
+        text_document_encoding: TEXT_ENCODING_UTF8,
+      }
+    : undefined;
+  const payload = IndexMessage.fromObject({ ...message, metadata });
+  return Buffer.from(IndexMessage.encode(payload).finish());
+};
+
+/**
+ * Decode a SCIP index (tests, and checking an export)
+ */
+export const decodeScipIndex = (bytes: Uint8Array): Record<string, unknown> =>
+  IndexMessage.toObject(IndexMessage.decode(bytes), { defaults: true, arrays: true });
+
+/**
+ * Export an index as SCIP
+ *
+ * @param db - Database connection pool
+ * @param repoId - Index to export
+ * @param projectRoot - Root directory of the indexed code (the metadata's project_root)
+ * @param write - Write encoded bytes (resolves once the bytes are accepted)
+ * @param args - Command line the export ran with (the metadata's tool arguments)
+ * @returns Counts of what was written
+ */
+export const exportScip = async (
+  db: Pool,
+  repoId: string,
+  projectRoot: string,
+  write: (bytes: Buffer) => Promise<void>,
+  args: string[] = []
+): Promise<ScipExportResult> => {
+  const content = buildScipIndex({
+    symbols: await listScipSymbols(db, repoId),
+    references: await listScipReferences(db, repoId),
+    implementations: await listScipImplementations(db, repoId),
+    typeParameters: await listGoTypeParameters(db, undefined, repoId),
+  });
+
+  await write(encodeScipIndex({ metadata: { project_root: pathToFileURL(projectRoot).href, arguments: args } }));
+  const result: ScipExportResult = { documents: 0, symbols: 0, occurrences: 0 };
+  for (const document of content.documents) {
+    await write(encodeScipIndex({ documents: [document] }));
+    result.documents++;
+    result.symbols += document.symbols.length;
+    result.occurrences += document.occurrences.length;
+  }
+  if (content.external_symbols.length > 0) {
+    await write(encodeScipIndex({ external_symbols: content.external_symbols }));
+  }
+  return result;
+};
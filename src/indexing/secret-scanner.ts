@@ -0,0 +1,125 @@
+/**
+ * Secret scanner - flags likely credentials in file content
+ *
+ * The secret file detector keeps files like .env out of the index by name;
+ * this scanner looks inside the files that are indexed. Indexing already
+ * reads every byte, so scanning is opt-in (SCAN_SECRETS) but nearly free.
+ *
+ * Findings never carry the secret itself: only its rule, position, a
+ * redacted preview, and a fingerprint (the same secret found twice has the
+ * same fingerprint). They are stored in their own table, which search and
+ * the MCP tools never read (see `cindex secrets`).
+ */
+import { createHash } from 'node:crypto';
+
+import { utf16ToByteColumn } from '@utils/positions';
+import { type SecretFinding, type SecretRule } from '@/types/indexing';
+
+/**
+ * Pattern for one rule; the value is capture group 1 if present, otherwise the whole match
+ */
+interface SecretPattern {
+  rule: SecretRule;
+  pattern: RegExp;
+  /** Require Shannon entropy of at least HIGH_ENTROPY_THRESHOLD (generic assignments) */
+  entropy?: boolean;
+}
+
+/** Minimum bits per character for a generic assignment to count as a secret */
+export const HIGH_ENTROPY_THRESHOLD = 4.0;
+
+/** Findings kept per file (a file full of test keys should not flood the report) */
+export const MAX_FINDINGS_PER_FILE = 50;
+
+/** Characters of a value left unmasked in the preview */
+const PREVIEW_CHARS = 4;
+
+const SECRET_PATTERNS: SecretPattern[] = [
+  { rule: 'aws_access_key_id', pattern: /\b(?:AKIA|ASIA)[0-9A-Z]{16}\b/g },
+  {
+    rule: 'aws_secret_access_key',
+    pattern: /aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})(?![A-Za-z0-9/+])/gi,
+  },
+  { rule: 'private_key', pattern: /-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----/g },
+  { rule: 'github_token', pattern: /\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b/g },
+  { rule: 'slack_token', pattern: /\bxox[abposr]-[A-Za-z0-9-]{10,}\b/g },
+  {
+    rule: 'high_entropy_string',
+    pattern:
+      /(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|auth)\w*["']?\s*[:=]\s*["'`]([A-Za-z0-9+/=_.-]{20,})["'`]/gi,
+    entropy: true,
+  },
+];
+
+/** Values that are documentation or templates, not credentials */
+const PLACEHOLDER = /example|sample|dummy|placeholder|changeme|your[_-]|x{8,}|\*{4,}|0{8,}/i;
+
+/**
+ * Shannon entropy of a string in bits per character
+ *
+ * @param value - String to measure
+ * @returns Entropy (0 for an empty or single-character-class string)
+ */
+export const shannonEntropy = (value: string): number => {
+  const counts = new Map<string, number>();
+  for (const char of value) {
+    counts.set(char, (counts.get(char) ?? 0) + 1);
+  }
+
+  let entropy = 0;
+  for (const count of counts.values()) {
+    const p = count / value.length;
+    entropy -= p * Math.log2(p);
+  }
+  return entropy;
+};
+
+/**
+ * Mask all but the first characters of a value
+ */
+const redact = (value: string): string => {
+  return value.slice(0, PREVIEW_CHARS) + '*'.repeat(Math.max(value.length - PREVIEW_CHARS, 0));
+};
+
+/**
+ * Scan file content for likely credentials
+ *
+ * @param content - File content (UTF-8, as indexed)
+ * @returns Findings in file order, at most MAX_FINDINGS_PER_FILE
+ */
+export const scanForSecrets = (content: string): SecretFinding[] => {
+  const findings: SecretFinding[] = [];
+  const seen = new Set<string>();
+  const lines = content.split('\n');
+
+  for (const [index, lineText] of lines.entries()) {
+    for (const { rule, pattern, entropy } of SECRET_PATTERNS) {
+      for (const match of lineText.matchAll(pattern)) {
+        const value = match[1] ?? match[0];
+        if (rule !== 'private_key' && PLACEHOLDER.test(value)) continue;
+        if (entropy && shannonEntropy(value) < HIGH_ENTROPY_THRESHOLD) continue;
+
+        // Specific rules come first; a generic match of the same value is not reported again
+        const column = (match.index ?? 0) + match[0].indexOf(value) + 1;
+        const key = `${String(index)}:${String(column)}`;
+        if (seen.has(key)) continue;
+        seen.add(key);
+
+        // A private key header is the same for every key; the key material follows it
+        const material = rule === 'private_key' ? (lines[index + 1] ?? value) : value;
+        findings.push({
+          rule,
+          line: index + 1,
+          column,
+          byte_column: utf16ToByteColumn(lineText, column),
+          redacted: rule === 'private_key' ? value : redact(value),
+          fingerprint: createHash('sha256').update(material).digest('hex').slice(0, 16),
+        });
+      }
+    }
+    if (findings.length >= MAX_FINDINGS_PER_FILE) break;
+  }
+
+  findings.sort((a, b) => a.line - b.line || a.column - b.column);
+  return findings.slice(0, MAX_FINDINGS_PER_FILE);
+};
@@ -30,7 +30,10 @@ import * as path from 'node:path';
 
 import * as yaml from 'js-yaml';
 
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { compareNames } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
 import { type PackageJsonInfo } from '@/types/workspace';
 
 /**
@@ -341,7 +344,7 @@ export class ServiceDetector {
       const filePath = path.join(this.rootPath, filename);
 
       try {
-        const content = await fs.readFile(filePath, 'utf-8');
+        const content = await readTextFile(filePath);
 
         // Parse YAML using js-yaml
         const parsed = yaml.load(content);
@@ -386,7 +389,7 @@ export class ServiceDetector {
       const dirPath = path.join(this.rootPath, dirName);
 
       try {
-        const entries = await fs.readdir(dirPath, { withFileTypes: true });
+        const entries = (await fs.readdir(dirPath, { withFileTypes: true })).sort(compareNames);
 
         for (const entry of entries) {
           if (entry.isDirectory()) {
@@ -415,10 +418,10 @@ export class ServiceDetector {
     const packageJsonPath = path.join(servicePath, 'package.json');
 
     try {
-      const content = await fs.readFile(packageJsonPath, 'utf-8');
+      const content = await readTextFile(packageJsonPath);
       const packageJson = parsePackageJson(content);
 
-      const relativePath = path.relative(this.rootPath, servicePath);
+      const relativePath = toPosixPath(path.relative(this.rootPath, servicePath));
 
       return {
         id: serviceId,
@@ -429,7 +432,7 @@ export class ServiceDetector {
       };
     } catch {
       // No package.json, might still be a service
-      const relativePath = path.relative(this.rootPath, servicePath);
+      const relativePath = toPosixPath(path.relative(this.rootPath, servicePath));
 
       return {
         id: serviceId,
@@ -557,7 +560,7 @@ export class ServiceDetector {
     const packageJsonPath = path.join(service.path, 'package.json');
 
     try {
-      const content = await fs.readFile(packageJsonPath, 'utf-8');
+      const content = await readTextFile(packageJsonPath);
       const packageJson = parsePackageJson(content);
       const dependencies = {
         ...(packageJson.dependencies ?? {}),
@@ -597,7 +600,7 @@ export class ServiceDetector {
     // Serverless Framework (serverless.yml)
     const serverlessYmlPath = path.join(servicePath, 'serverless.yml');
     try {
-      const content = await fs.readFile(serverlessYmlPath, 'utf-8');
+      const content = await readTextFile(serverlessYmlPath);
       const parsed = yaml.load(content) as Record<string, unknown>;
 
       const functions = this.extractServerlessFunctions(parsed);
@@ -616,7 +619,7 @@ export class ServiceDetector {
     // Vercel (vercel.json)
     const vercelJsonPath = path.join(servicePath, 'vercel.json');
     try {
-      const content = await fs.readFile(vercelJsonPath, 'utf-8');
+      const content = await readTextFile(vercelJsonPath);
       const parsed = parseJsonToRecord(content);
 
       const functions = this.extractVercelFunctions(parsed);
@@ -646,7 +649,7 @@ export class ServiceDetector {
     // AWS SAM (template.yaml)
     const samTemplatePath = path.join(servicePath, 'template.yaml');
     try {
-      const content = await fs.readFile(samTemplatePath, 'utf-8');
+      const content = await readTextFile(samTemplatePath);
       const parsed = yaml.load(content) as Record<string, unknown>;
 
       if (parsed.AWSTemplateFormatVersion) {
@@ -733,7 +736,7 @@ export class ServiceDetector {
     // React Native (app.json with displayName)
     const appJsonPath = path.join(servicePath, 'app.json');
     try {
-      const content = await fs.readFile(appJsonPath, 'utf-8');
+      const content = await readTextFile(appJsonPath);
       const parsed = parseJsonToRecord(content);
 
       if (parsed.expo) {
@@ -765,7 +768,7 @@ export class ServiceDetector {
     // Flutter (pubspec.yaml)
     const pubspecPath = path.join(servicePath, 'pubspec.yaml');
     try {
-      const content = await fs.readFile(pubspecPath, 'utf-8');
+      const content = await readTextFile(pubspecPath);
       const parsed = yaml.load(content) as Record<string, unknown>;
 
       if (parsed.flutter) {
@@ -821,7 +824,7 @@ export class ServiceDetector {
       const filePath = path.join(servicePath, filename);
 
       try {
-        const content = await fs.readFile(filePath, 'utf-8');
+        const content = await readTextFile(filePath);
         const parsed = filename.endsWith('.json') ? parseJsonToRecord(content) : this.parseYAML(content);
 
         contracts.push({
@@ -845,7 +848,7 @@ export class ServiceDetector {
       const filePath = path.join(servicePath, filename);
 
       try {
-        const content = await fs.readFile(filePath, 'utf-8');
+        const content = await readTextFile(filePath);
 
         contracts.push({
           format: APIContractFormat.GraphQL,
@@ -865,7 +868,7 @@ export class ServiceDetector {
 
     for (const filePath of protoFiles) {
       try {
-        const content = await fs.readFile(filePath, 'utf-8');
+        const content = await readTextFile(filePath);
 
         contracts.push({
           format: APIContractFormat.GRPC,
@@ -890,7 +893,7 @@ export class ServiceDetector {
     const protoFiles: string[] = [];
 
     try {
-      const entries = await fs.readdir(servicePath, { withFileTypes: true });
+      const entries = (await fs.readdir(servicePath, { withFileTypes: true })).sort(compareNames);
 
       for (const entry of entries) {
         const fullPath = path.join(servicePath, entry.name);
@@ -0,0 +1,487 @@
+/**
+ * Index snapshot format (cindex export / cindex import)
+ *
+ * A snapshot holds one index: a header, then the rows of every table that
+ * belongs to the index, without their serial IDs. Three encodings carry the
+ * same records:
+ *
+ * - jsonl: one JSON object per line, the header first
+ * - protobuf: length-delimited SnapshotRecord messages (SNAPSHOT_PROTO). The
+ *   row travels as JSON and its embeddings as packed floats, four bytes per
+ *   dimension instead of their decimal text.
+ * - compact: COMPACT_MAGIC, then the protobuf records in zstd-compressed
+ *   blocks of about COMPACT_BLOCK_SIZE bytes, each a varint length and one
+ *   zstd frame holding whole records. Paths, names, and JSON keys repeated
+ *   across rows are stored once per block window by zstd rather than in a
+ *   string table. Blocks are decompressed one at a time, so importing holds
+ *   one block in memory whatever the snapshot's size. Needs Node.js 22.15 or
+ *   later, the first with zstd.
+ *
+ * The header records the format version that wrote the snapshot and the
+ * oldest reader version able to import it. A writer that only adds tables or
+ * columns keeps min_reader_version, and older readers skip what they do not
+ * know; a change older readers would import wrongly raises it, and they
+ * refuse the snapshot instead.
+ */
+
+import * as zlib from 'node:zlib';
+
+import protobuf from 'protobufjs';
+
+import { SnapshotFormatError, SnapshotVersionError } from '@utils/errors';
+import { pushVarint, requireZstd } from '@utils/zstd';
+
+/** Header marker identifying a snapshot */
+export const SNAPSHOT_FORMAT = 'cindex-snapshot';
+
+/** Snapshot format version written and read by this cindex */
+export const SNAPSHOT_VERSION = 1;
+
+/** Oldest reader version able to import snapshots written here */
+export const SNAPSHOT_MIN_READER_VERSION = 1;
+
+/** Snapshot encodings (cindex export --format) */
+export const SNAPSHOT_ENCODINGS = ['jsonl', 'protobuf', 'compact'] as const;
+
+/**
+ * Snapshot encoding
+ */
+export type SnapshotEncoding = (typeof SNAPSHOT_ENCODINGS)[number];
+
+/**
+ * Check whether a string names a snapshot encoding
+ */
+export const isSnapshotEncoding = (value: string): value is SnapshotEncoding =>
+  (SNAPSHOT_ENCODINGS as readonly string[]).includes(value);
+
+/**
+ * Wire schema of the protobuf encoding
+ *
+ * The header is the first record, with an empty table. Vector columns of a
+ * row are moved out of its JSON into `vectors`; null vectors stay in the JSON.
+ */
+export const SNAPSHOT_PROTO = `
+syntax = "proto3";
+package cindex.snapshot;
+
+message SnapshotRecord {
+  string table = 1;
+  string json = 2;
+  repeated Vector vectors = 3;
+}
+
+message Vector {
+  string column = 1;
+  repeated float values = 2;
+}
+`;
+
+/**
+ * Table stored in a snapshot
+ */
+export interface SnapshotTable {
+  name: string;
+  /** Column naming the index a row belongs to */
+  repoColumn: string;
+  /** Content columns rows are exported in order of (serial IDs depend on which worker wrote a row first) */
+  order: string[];
+  /** pgvector columns */
+  vectors: string[];
+  /** Columns left out (references to serial IDs, which are not kept) */
+  omit: string[];
+}
+
+/** Table of per-file rows, ordered by path first */
+const codeTable = (name: string, order: string[], vectors: string[] = []): SnapshotTable => ({
+  name,
+  repoColumn: 'repo_id',
+  order: ['file_path', ...order],
+  vectors,
+  omit: [],
+});
+
+/**
+ * Tables of an index, in the order rows are written and imported
+ *
+ * secret_findings is not exported: a snapshot is shared, and detected
+ * secrets stay in the database they were found in.
+ */
+export const SNAPSHOT_TABLES: SnapshotTable[] = [
+  { name: 'repositories', repoColumn: 'repo_id', order: ['repo_id'], vectors: [], omit: [] },
+  codeTable('code_files', [], ['summary_embedding']),
+  codeTable('code_contents', []),
+  codeTable('code_chunks', ['start_line', 'end_line', 'chunk_type'], ['embedding']),
+  codeTable('code_symbols', ['line_number', 'symbol_name', 'symbol_type'], ['embedding']),
+  codeTable('lint_findings', ['line_number', 'column_number', 'tool', 'rule']),
+  codeTable('go_implementations', ['line_number', 'type_name', 'interface_name']),
+  codeTable('go_references', ['line_number', 'column_number', 'target_name']),
+  codeTable('go_calls', ['line_number', 'column_number', 'callee_name']),
+  codeTable('go_constants', ['line_number', 'constant_name']),
+  codeTable('go_type_parameters', ['line_number', 'symbol_name', 'position']),
+  codeTable('go_instantiations', ['line_number', 'column_number', 'target_name']),
+  codeTable('go_embeddings', ['line_number', 'column_number', 'embedded_name']),
+  codeTable('code_annotations', ['line_number', 'column_number', 'tag']),
+  codeTable('code_literals', ['line_number', 'column_number', 'text']),
+  codeTable('code_plugin_metrics', ['line_number', 'analyzer', 'name']),
+  { name: 'workspaces', repoColumn: 'repo_id', order: ['workspace_id'], vectors: [], omit: [] },
+  {
+    name: 'workspace_aliases',
+    repoColumn: 'repo_id',
+    order: ['workspace_id', 'alias_pattern'],
+    vectors: [],
+    omit: [],
+  },
+  {
+    name: 'workspace_dependencies',
+    repoColumn: 'repo_id',
+    order: ['source_workspace_id', 'target_workspace_id'],
+    vectors: [],
+    omit: [],
+  },
+  { name: 'services', repoColumn: 'repo_id', order: ['service_id'], vectors: ['api_embedding'], omit: [] },
+  {
+    name: 'api_endpoints',
+    repoColumn: 'repo_id',
+    order: ['service_id', 'endpoint_path', 'http_method'],
+    vectors: ['embedding'],
+    omit: ['implementation_chunk_id'],
+  },
+  {
+    name: 'cross_repo_dependencies',
+    repoColumn: 'source_repo_id',
+    order: ['target_repo_id', 'dependency_type'],
+    vectors: [],
+    omit: [],
+  },
+];
+
+/**
+ * Columns recording when a row was written, left out so exporting the same
+ * index twice gives the same bytes (imported rows take the column defaults)
+ */
+export const SNAPSHOT_VOLATILE_COLUMNS = ['indexed_at', 'imported_at', 'last_updated'];
+
+/** Keys of repositories.metadata recording when the index was built or fetched, left out for the same reason */
+export const SNAPSHOT_VOLATILE_METADATA = ['last_indexed', 'fetched_at', 'checkpoint'];
+
+/**
+ * First record of a snapshot
+ */
+export interface SnapshotHeader {
+  format: typeof SNAPSHOT_FORMAT;
+  /** Format version of the writer */
+  version: number;
+  /** Oldest reader version able to import the snapshot */
+  min_reader_version: number;
+  /** Index the snapshot was exported from */
+  repo_id: string;
+  /** ISO timestamp (SOURCE_DATE_EPOCH when set) */
+  exported_at: string;
+  /** Embedding model the vectors were computed with */
+  embedding: { provider: string; model: string; dimensions: number };
+  /** Tables written, in order */
+  tables: string[];
+}
+
+/**
+ * Row of a snapshot table (vector columns as number arrays)
+ */
+export interface SnapshotRow {
+  table: string;
+  row: Record<string, unknown>;
+}
+
+/**
+ * Snapshot opened for reading
+ */
+export interface SnapshotReader {
+  header: SnapshotHeader;
+  encoding: SnapshotEncoding;
+  /** Rows after the header (read once) */
+  rows: AsyncIterable<SnapshotRow>;
+}
+
+/**
+ * Decoded SnapshotRecord message
+ */
+interface ProtoRecord {
+  table: string;
+  json: string;
+  vectors: { column: string; values: number[] }[];
+}
+
+const SnapshotRecordMessage = protobuf.parse(SNAPSHOT_PROTO, { keepCase: true }).root.lookupType(
+  'cindex.snapshot.SnapshotRecord'
+);
+
+/** Vector columns by table name */
+const VECTOR_COLUMNS = new Map(SNAPSHOT_TABLES.map((table) => [table.name, table.vectors]));
+
+/** Bytes of a varint length prefix for 32-bit lengths */
+const MAX_VARINT_BYTES = 5;
+
+/** First bytes of a compact snapshot */
+export const COMPACT_MAGIC = Buffer.from('CIDXZ1\n');
+
+/** Uncompressed bytes gathered into one block of a compact snapshot */
+export const COMPACT_BLOCK_SIZE = 1024 * 1024;
+
+/** zstd level of compact blocks: most of the size gain of higher levels, at a fraction of their time */
+const COMPACT_LEVEL = 9;
+
+/**
+ * Encode a varint length prefix
+ */
+const encodeVarint = (value: number): Buffer => {
+  const bytes: number[] = [];
+  pushVarint(bytes, value);
+  return Buffer.from(bytes);
+};
+
+/**
+ * Gather encoded records into the compressed blocks of a compact snapshot
+ *
+ * @param write - Write bytes to the snapshot
+ * @returns Record writer; end() writes the last block
+ * @throws {SnapshotFormatError} If zstd is unavailable
+ */
+export const compactBlockWriter = (
+  write: (bytes: Buffer) => Promise<void>
+): { write: (record: Buffer) => Promise<void>; end: () => Promise<void> } => {
+  requireZstd('Compact snapshots', SnapshotFormatError);
+  let pending: Buffer[] = [];
+  let size = 0;
+  let started = false;
+
+  const flush = async (): Promise<void> => {
+    if (!started) {
+      started = true;
+      await write(COMPACT_MAGIC);
+    }
+    if (size === 0) return;
+    const block = zlib.zstdCompressSync(Buffer.concat(pending, size), {
+      params: { [zlib.constants.ZSTD_c_compressionLevel]: COMPACT_LEVEL },
+    });
+    pending = [];
+    size = 0;
+    await write(Buffer.concat([encodeVarint(block.length), block]));
+  };
+  return {
+    write: async (record) => {
+      pending.push(record);
+      size += record.length;
+      if (size >= COMPACT_BLOCK_SIZE) await flush();
+    },
+    end: flush,
+  };
+};
+
+/**
+ * Encode the header of a snapshot
+ *
+ * @param header - Snapshot header
+ * @param encoding - Snapshot encoding
+ * @returns Bytes to write first
+ */
+export const encodeSnapshotHeader = (header: SnapshotHeader, encoding: SnapshotEncoding): Buffer =>
+  encoding === 'jsonl'
+    ? Buffer.from(`${JSON.stringify(header)}\n`)
+    : Buffer.from(SnapshotRecordMessage.encodeDelimited({ table: '', json: JSON.stringify(header) }).finish());
+
+/**
+ * Encode one row of a snapshot
+ *
+ * @param record - Table and row; vector columns hold number arrays or null
+ * @param encoding - Snapshot encoding (compact records are protobuf ones, see compactBlockWriter)
+ * @returns Bytes of the record
+ */
+export const encodeSnapshotRow = ({ table, row }: SnapshotRow, encoding: SnapshotEncoding): Buffer => {
+  if (encoding === 'jsonl') return Buffer.from(`${JSON.stringify({ table, row })}\n`);
+
+  const vectors = (VECTOR_COLUMNS.get(table) ?? []).flatMap((column) => {
+    const values = row[column];
+    return Array.isArray(values) ? [{ column, values: values as number[] }] : [];
+  });
+  const moved = new Set(vectors.map((vector) => vector.column));
+  const fields = Object.fromEntries(Object.entries(row).filter(([column]) => !moved.has(column)));
+  return Buffer.from(SnapshotRecordMessage.encodeDelimited({ table, json: JSON.stringify(fields), vectors }).finish());
+};
+
+/**
+ * Validate the header of a snapshot
+ *
+ * @param value - Parsed header record
+ * @returns The header
+ * @throws {SnapshotFormatError} If the value is not a snapshot header
+ * @throws {SnapshotVersionError} If the snapshot needs a newer reader
+ */
+export const checkSnapshotHeader = (value: unknown): SnapshotHeader => {
+  const header = value as Partial<SnapshotHeader> | null | undefined;
+  if (header?.format !== SNAPSHOT_FORMAT) throw new SnapshotFormatError('Not a cindex snapshot (no snapshot header)');
+  if (typeof header.version !== 'number' || typeof header.min_reader_version !== 'number') {
+    throw new SnapshotFormatError('Snapshot header has no format version', header);
+  }
+  if (header.min_reader_version > SNAPSHOT_VERSION) {
+    throw new SnapshotVersionError(header.version, header.min_reader_version, SNAPSHOT_VERSION);
+  }
+  if (typeof header.repo_id !== 'string' || typeof header.embedding?.dimensions !== 'number') {
+    throw new SnapshotFormatError('Snapshot header is incomplete', header);
+  }
+  return header as SnapshotHeader;
+};
+
+/**
+ * Read a varint length prefix
+ *
+ * @returns Value and offset after it, or null if the buffer ends first
+ */
+const readVarint = (buffer: Buffer, offset: number): { value: number; next: number } | null => {
+  let value = 0;
+  for (let i = 0; i < MAX_VARINT_BYTES; i++) {
+    if (offset + i >= buffer.length) return null;
+    const byte = buffer[offset + i];
+    value += (byte & 0x7f) * 2 ** (7 * i);
+    if (byte < 0x80) return { value, next: offset + i + 1 };
+  }
+  throw new SnapshotFormatError('Invalid record length in snapshot');
+};
+
+/**
+ * Split a byte stream into the records of an encoding
+ *
+ * @param first - Chunk already read from the stream
+ * @param rest - Remaining chunks
+ * @returns Record bytes: lines (jsonl) or message bodies (protobuf)
+ */
+async function* splitRecords(
+  first: Buffer,
+  rest: AsyncIterator<Buffer>,
+  encoding: SnapshotEncoding
+): AsyncGenerator<Buffer> {
+  let buffer = first;
+  for (;;) {
+    let offset = 0;
+    for (;;) {
+      if (encoding === 'jsonl') {
+        const newline = buffer.indexOf(10, offset);
+        if (newline === -1) break;
+        yield buffer.subarray(offset, newline);
+        offset = newline + 1;
+        continue;
+      }
+      const prefix = readVarint(buffer, offset);
+      if (prefix === null || buffer.length < prefix.next + prefix.value) break;
+      yield buffer.subarray(prefix.next, prefix.next + prefix.value);
+      offset = prefix.next + prefix.value;
+    }
+    buffer = buffer.subarray(offset);
+
+    const next = await rest.next();
+    if (next.done === true) break;
+    buffer = buffer.length === 0 ? next.value : Buffer.concat([buffer, next.value]);
+  }
+
+  // A final line without a newline is complete; a partial message is not
+  if (encoding === 'jsonl' && buffer.length > 0) yield buffer;
+  else if (buffer.length > 0) throw new SnapshotFormatError('Snapshot ends inside a record (truncated file?)');
+}
+
+/**
+ * Decompress the blocks of a compact snapshot, one at a time
+ *
+ * @param first - Chunk already read from the stream, after COMPACT_MAGIC
+ * @param rest - Remaining chunks
+ * @returns Uncompressed block contents
+ */
+async function* decompressBlocks(first: Buffer, rest: AsyncIterator<Buffer>): AsyncGenerator<Buffer> {
+  requireZstd('Compact snapshots', SnapshotFormatError);
+  // Blocks are framed like protobuf records: a varint length, then the bytes
+  for await (const block of splitRecords(first, rest, 'protobuf')) {
+    try {
+      yield zlib.zstdDecompressSync(block);
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      throw new SnapshotFormatError(`Invalid compressed block in snapshot: ${message}`);
+    }
+  }
+}
+
+/**
+ * Decode one record
+ *
+ * @returns Parsed header (table '') or row
+ */
+const decodeRecord = (bytes: Buffer, encoding: SnapshotEncoding, index: number): SnapshotRow => {
+  try {
+    if (encoding === 'jsonl') {
+      const parsed = JSON.parse(bytes.toString('utf-8')) as Partial<SnapshotRow>;
+      if (index === 0) return { table: '', row: parsed as Record<string, unknown> };
+      if (typeof parsed.table !== 'string' || typeof parsed.row !== 'object') throw new Error('not a table row');
+      return { table: parsed.table, row: parsed.row };
+    }
+
+    const message = SnapshotRecordMessage.toObject(SnapshotRecordMessage.decode(bytes), {
+      defaults: true,
+      arrays: true,
+    }) as ProtoRecord;
+    const row = JSON.parse(message.json) as Record<string, unknown>;
+    for (const vector of message.vectors) row[vector.column] = vector.values;
+    return { table: message.table, row };
+  } catch (error) {
+    if (error instanceof SnapshotFormatError) throw error;
+    const message = error instanceof Error ? error.message : String(error);
+    throw new SnapshotFormatError(`Invalid snapshot record ${String(index + 1)}: ${message}`);
+  }
+};
+
+/**
+ * Open a snapshot
+ *
+ * The encoding is detected from the first bytes: a compact snapshot starts
+ * with COMPACT_MAGIC, a JSON line with `{`, while the header message is longer
+ * than 127 bytes, so its varint length prefix starts with a byte of 0x80 or
+ * more.
+ *
+ * @param input - Snapshot bytes (file or stdin stream)
+ * @returns Header and the rows that follow it
+ * @throws {SnapshotFormatError} If the input is empty or not a snapshot
+ * @throws {SnapshotVersionError} If the snapshot needs a newer reader
+ */
+export const readSnapshot = async (input: AsyncIterable<Buffer>): Promise<SnapshotReader> => {
+  const chunks = input[Symbol.asyncIterator]();
+  let first = await chunks.next();
+  while (first.done !== true && first.value.length === 0) first = await chunks.next();
+  if (first.done === true) throw new SnapshotFormatError('Snapshot is empty');
+
+  let start = first.value;
+  while (start.length < COMPACT_MAGIC.length && COMPACT_MAGIC.subarray(0, start.length).equals(start)) {
+    const next = await chunks.next();
+    if (next.done === true) break;
+    start = Buffer.concat([start, next.value]);
+  }
+  const compact = start.subarray(0, COMPACT_MAGIC.length).equals(COMPACT_MAGIC);
+  const encoding: SnapshotEncoding = compact ? 'compact' : start[0] === 0x7b ? 'jsonl' : 'protobuf';
+
+  let records: AsyncGenerator<Buffer>;
+  if (compact) {
+    const blocks = decompressBlocks(start.subarray(COMPACT_MAGIC.length), chunks);
+    const firstBlock = await blocks.next();
+    if (firstBlock.done === true) throw new SnapshotFormatError('Snapshot is empty');
+    records = splitRecords(firstBlock.value, blocks, 'protobuf');
+  } else {
+    records = splitRecords(start, chunks, encoding);
+  }
+  const headerBytes = await records.next();
+  if (headerBytes.done === true) throw new SnapshotFormatError('Snapshot is empty');
+  const header = checkSnapshotHeader(decodeRecord(headerBytes.value, encoding, 0).row);
+
+  async function* rows(): AsyncGenerator<SnapshotRow> {
+    let index = 1;
+    for await (const bytes of records) {
+      // Blank lines (e.g. a trailing newline added by an editor) carry nothing
+      if (encoding === 'jsonl' && bytes.toString('utf-8').trim() === '') continue;
+      yield decodeRecord(bytes, encoding, index++);
+    }
+  }
+  return { header, encoding, rows: rows() };
+};
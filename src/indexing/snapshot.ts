@@ -0,0 +1,292 @@
+/**
+ * Index snapshots: export an index to a file and import it elsewhere
+ *
+ *   cindex export api --format=protobuf -o api.snapshot   # in CI
+ *   cindex import api.snapshot --path .                    # on a laptop or server
+ *
+ * Importing a snapshot replaces the index of the same ID without parsing or
+ * embedding anything. Rows keep their stored paths and contents; serial IDs
+ * are assigned again, so columns that refer to another table's ID
+ * (SnapshotTable.omit) are not carried over. Secret findings are never
+ * exported, and importing clears those of the replaced index. See
+ * @indexing/snapshot-format for the file format and its version checks.
+ */
+
+import { type PoolClient } from 'pg';
+
+import { type DatabaseClient } from '@database/client';
+import {
+  SNAPSHOT_FORMAT,
+  SNAPSHOT_MIN_READER_VERSION,
+  SNAPSHOT_TABLES,
+  SNAPSHOT_VERSION,
+  SNAPSHOT_VOLATILE_COLUMNS,
+  SNAPSHOT_VOLATILE_METADATA,
+  type SnapshotHeader,
+  type SnapshotReader,
+  type SnapshotRow,
+  type SnapshotTable,
+} from '@indexing/snapshot-format';
+import { SnapshotFormatError } from '@utils/errors';
+import { logger } from '@utils/logger';
+import { type EmbeddingConfig } from '@/types/config';
+
+/** Rows read per export query */
+const EXPORT_PAGE_ROWS = 1000;
+
+/** Rows inserted per import statement */
+const IMPORT_BATCH_ROWS = 250;
+
+/**
+ * Result of exporting an index
+ */
+export interface SnapshotExportResult {
+  header: SnapshotHeader;
+  /** Rows written per table */
+  rows: Record<string, number>;
+}
+
+/**
+ * Options for importing a snapshot
+ */
+export interface SnapshotImportOptions {
+  /** Repository path recorded on imported rows (default: the path it was indexed at) */
+  repoPath?: string;
+}
+
+/**
+ * Result of importing a snapshot
+ */
+export interface SnapshotImportResult {
+  repo_id: string;
+  /** Rows inserted per table */
+  rows: Record<string, number>;
+  /** Tables in the snapshot this database does not have */
+  skipped_tables: string[];
+  /** Columns (table.column) in the snapshot this database does not have */
+  skipped_columns: string[];
+}
+
+/**
+ * Columns of the snapshot tables present in the connected database
+ */
+const loadTableColumns = async (client: PoolClient): Promise<Map<string, Set<string>>> => {
+  const result = await client.query<{ table_name: string; column_name: string }>(
+    `SELECT table_name, column_name FROM information_schema.columns
+     WHERE table_schema = current_schema() AND table_name = ANY($1)`,
+    [SNAPSHOT_TABLES.map((table) => table.name)]
+  );
+  const columns = new Map<string, Set<string>>();
+  for (const { table_name, column_name } of result.rows) {
+    columns.set(table_name, (columns.get(table_name) ?? new Set()).add(column_name));
+  }
+  return columns;
+};
+
+/**
+ * Time recorded in the header: SOURCE_DATE_EPOCH (seconds) when set, so a
+ * rebuilt snapshot is byte-identical, otherwise now
+ */
+const exportedAt = (): string => {
+  const epoch = Number(process.env.SOURCE_DATE_EPOCH);
+  if (!process.env.SOURCE_DATE_EPOCH || !Number.isInteger(epoch)) return new Date().toISOString();
+  return new Date(epoch * 1000).toISOString();
+};
+
+/**
+ * Columns of a table left out of snapshots
+ */
+const droppedColumns = (table: SnapshotTable): string[] => ['id', ...table.omit, ...SNAPSHOT_VOLATILE_COLUMNS];
+
+/**
+ * Fields of a row written to a snapshot: serial IDs, omitted references, and
+ * write timestamps are left out
+ */
+const exportedRow = (table: SnapshotTable, row: Record<string, unknown>): Record<string, unknown> => {
+  const dropped = droppedColumns(table);
+  const values = Object.entries(row)
+    .filter(([column]) => !dropped.includes(column))
+    .map(([column, value]): [string, unknown] => {
+      // row_to_json renders vectors as their text form, '[0.1,0.2,...]'
+      if (table.vectors.includes(column) && typeof value === 'string') return [column, JSON.parse(value) as unknown];
+      if (table.name === 'repositories' && column === 'metadata' && value && typeof value === 'object') {
+        const kept = Object.entries(value).filter(([key]) => !SNAPSHOT_VOLATILE_METADATA.includes(key));
+        return [column, Object.fromEntries(kept)];
+      }
+      return [column, value];
+    });
+  return Object.fromEntries(values);
+};
+
+/**
+ * Export an index
+ *
+ * Tables are read in one repeatable-read transaction, so the snapshot is
+ * consistent even while the index is being written. Rows are written in
+ * content order without serial IDs or write timestamps, so exporting the
+ * same index twice (with SOURCE_DATE_EPOCH set) gives the same bytes.
+ *
+ * @param db - Connected database client
+ * @param repoId - Index to export
+ * @param embedding - Embedding model the index was built with
+ * @param write - Write encoded records (resolves once the bytes are accepted)
+ * @param encode - Record encoder for the chosen encoding
+ * @returns Header written and row counts
+ */
+export const exportSnapshot = (
+  db: DatabaseClient,
+  repoId: string,
+  embedding: EmbeddingConfig,
+  write: (bytes: Buffer) => Promise<void>,
+  encode: { header: (header: SnapshotHeader) => Buffer; row: (row: SnapshotRow) => Buffer }
+): Promise<SnapshotExportResult> =>
+  db.transaction(async (client) => {
+    await client.query('SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY');
+    const columns = await loadTableColumns(client);
+    const tables = SNAPSHOT_TABLES.filter((table) => columns.has(table.name));
+
+    const header: SnapshotHeader = {
+      format: SNAPSHOT_FORMAT,
+      version: SNAPSHOT_VERSION,
+      min_reader_version: SNAPSHOT_MIN_READER_VERSION,
+      repo_id: repoId,
+      exported_at: exportedAt(),
+      embedding: { provider: embedding.provider, model: embedding.model, dimensions: embedding.dimensions },
+      tables: tables.map((table) => table.name),
+    };
+    await write(encode.header(header));
+
+    const rows: Record<string, number> = {};
+    for (const table of tables) {
+      rows[table.name] = 0;
+      // Rows equal in the order columns fall back to the rest of their content, never the serial ID
+      const dropped = droppedColumns(table).map((column) => `'${column}'`);
+      const order = [...table.order.map((column) => `t.${column}`), `to_jsonb(t) - ARRAY[${dropped.join(', ')}]`];
+      await client.query(
+        `DECLARE snapshot_rows NO SCROLL CURSOR FOR
+         SELECT row_to_json(t) AS row FROM ${table.name} t
+         WHERE t.${table.repoColumn} = ${client.escapeLiteral(repoId)} ORDER BY ${order.join(', ')}`
+      );
+
+      for (;;) {
+        const page = await client.query<{ row: Record<string, unknown> }>(
+          `FETCH ${String(EXPORT_PAGE_ROWS)} FROM snapshot_rows`
+        );
+        for (const { row } of page.rows) await write(encode.row({ table: table.name, row: exportedRow(table, row) }));
+        rows[table.name] += page.rows.length;
+        if (page.rows.length < EXPORT_PAGE_ROWS) break;
+      }
+      await client.query('CLOSE snapshot_rows');
+    }
+
+    logger.info('Exported index snapshot', { repo_id: repoId, rows });
+    return { header, rows };
+  });
+
+/**
+ * Insert rows of one table
+ *
+ * json_populate_recordset converts each value with the column's own input
+ * function, so timestamps, arrays, JSONB, tsvector, and vector text come
+ * back exactly as row_to_json wrote them.
+ */
+const insertRows = async (
+  client: PoolClient,
+  table: string,
+  columns: string[],
+  rows: Record<string, unknown>[]
+): Promise<void> => {
+  const list = columns.map((column) => `"${column}"`).join(', ');
+  await client.query(
+    `INSERT INTO ${table} (${list}) SELECT ${list} FROM json_populate_recordset(NULL::${table}, $1::json)`,
+    [JSON.stringify(rows)]
+  );
+};
+
+/**
+ * Import a snapshot, replacing the index it was exported from
+ *
+ * Runs in one transaction: on any error the existing index is left as it
+ * was. Tables and columns this database does not have are skipped and
+ * reported, which is how snapshots of a newer schema import into older ones.
+ * Call with the index's write lock held.
+ *
+ * @param db - Connected database client
+ * @param snapshot - Opened snapshot (header already checked)
+ * @param options - Import options
+ * @returns Rows inserted and what was skipped
+ * @throws {SnapshotFormatError} If a row belongs to another index or cannot be decoded
+ */
+export const importSnapshot = (
+  db: DatabaseClient,
+  snapshot: SnapshotReader,
+  options: SnapshotImportOptions = {}
+): Promise<SnapshotImportResult> => {
+  const repoId = snapshot.header.repo_id;
+  const tables = new Map(SNAPSHOT_TABLES.map((table) => [table.name, table]));
+
+  return db.transaction(async (client) => {
+    const columns = await loadTableColumns(client);
+    for (const table of [...SNAPSHOT_TABLES].reverse()) {
+      if (!columns.has(table.name)) continue;
+      await client.query(`DELETE FROM ${table.name} WHERE ${table.repoColumn} = $1`, [repoId]);
+    }
+    // Findings of the replaced index's files; snapshots do not carry them
+    const secrets = await client.query<{ found: boolean }>(
+      "SELECT to_regclass('secret_findings') IS NOT NULL AS found"
+    );
+    if (secrets.rows[0]?.found) await client.query('DELETE FROM secret_findings WHERE repo_id = $1', [repoId]);
+
+    const counts = new Map<string, number>();
+    const skippedTables = new Set<string>();
+    const skippedColumns = new Set<string>();
+    let batch: { table: string; rows: Record<string, unknown>[] } | null = null;
+
+    const flush = async (): Promise<void> => {
+      if (!batch || batch.rows.length === 0) return;
+      const known = columns.get(batch.table) ?? new Set<string>();
+      const present = new Set(batch.rows.flatMap((row) => Object.keys(row)));
+      for (const column of present) {
+        if (!known.has(column)) skippedColumns.add(`${batch.table}.${column}`);
+      }
+      await insertRows(
+        client,
+        batch.table,
+        [...present].filter((column) => known.has(column) && column !== 'id'),
+        batch.rows
+      );
+      counts.set(batch.table, (counts.get(batch.table) ?? 0) + batch.rows.length);
+      batch.rows = [];
+    };
+
+    for await (const { table: name, row } of snapshot.rows) {
+      const table = tables.get(name);
+      if (!table || !columns.has(name)) {
+        skippedTables.add(name);
+        continue;
+      }
+      const owner = row[table.repoColumn];
+      if (owner !== repoId) {
+        throw new SnapshotFormatError(`Row of ${name} belongs to index '${String(owner)}', not '${repoId}'`);
+      }
+
+      const values = Object.entries(row).map(([column, value]): [string, unknown] => {
+        if (table.vectors.includes(column) && Array.isArray(value)) return [column, `[${value.join(',')}]`];
+        if (column === 'repo_path' && options.repoPath) return [column, options.repoPath];
+        return [column, value];
+      });
+
+      if (batch?.table !== name) {
+        await flush();
+        batch = { table: name, rows: [] };
+      }
+      batch.rows.push(Object.fromEntries(values));
+      if (batch.rows.length >= IMPORT_BATCH_ROWS) await flush();
+    }
+    await flush();
+
+    const rows = Object.fromEntries(counts);
+    logger.info('Imported index snapshot', { repo_id: repoId, rows });
+    return { repo_id: repoId, rows, skipped_tables: [...skippedTables], skipped_columns: [...skippedColumns] };
+  });
+};
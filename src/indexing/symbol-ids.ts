@@ -0,0 +1,299 @@
+/**
+ * Symbol kinds and structured symbol IDs
+ *
+ * Every symbol gets an ID built like a SCIP symbol: scheme, package manager,
+ * package, and version, then the descriptors that lead to the symbol:
+ *
+ *   cindex gomod example.com/shop . `example.com/shop/auth`/Service#Login().
+ *   cindex npm @acme/web . src/`session.ts`/createSession().
+ *
+ * A Go symbol is named by its package import path, not its file, so its ID
+ * survives moving it to another file of the package; other languages name
+ * the file, their unit of import. IDs are stored in code_symbols.symbol_id
+ * at index time and found again with id: search.
+ *
+ * Kinds, with the descriptor each ends in:
+ *
+ *   func       Name().      functions, Go tests, benchmarks, fuzz tests, and examples
+ *   method     Type#Name(). Go methods, named by their receiver type
+ *   struct     Name#        Go structs
+ *   class      Name#        classes of other languages
+ *   interface  Name#
+ *   type       Name#        other type declarations (Go defined types and aliases)
+ *   const      Name.
+ *   var        Name.
+ *   field      Type#Name.   Go struct fields
+ *   typeparam  [Name]       type parameters, after the ID of their generic function or type
+ *
+ * Go subtests are terms of their test: TestLogin().expired_token.
+ */
+import { posix } from 'node:path';
+
+import { Language, type ExtractedSymbol } from '@/types/indexing';
+
+/** Kinds of the symbol taxonomy */
+export type SymbolKind =
+  | 'func'
+  | 'method'
+  | 'struct'
+  | 'class'
+  | 'interface'
+  | 'type'
+  | 'const'
+  | 'var'
+  | 'field'
+  | 'typeparam';
+
+/** Descriptor suffixes, as SCIP names them */
+export type DescriptorSuffix = 'namespace' | 'type' | 'term' | 'method' | 'type_parameter';
+
+/**
+ * One step of a symbol ID (a package, a type, a member)
+ */
+export interface SymbolDescriptor {
+  name: string;
+  suffix: DescriptorSuffix;
+}
+
+/**
+ * Parts of a symbol ID
+ */
+export interface SymbolIdParts {
+  scheme: string;
+  /** Package manager (gomod, npm), or '.' */
+  manager: string;
+  /** Package name (module path, package.json name), or '.' */
+  package: string;
+  /** Package version, or '.' */
+  version: string;
+  descriptors: SymbolDescriptor[];
+}
+
+/**
+ * What a symbol ID is built from
+ */
+export interface SymbolIdInput {
+  symbol_name: string;
+  symbol_type: ExtractedSymbol['symbol_type'];
+  /** Path relative to the repository root */
+  file_path: string;
+  language: Language | string;
+  /** Go module path or package.json name of the file */
+  package_name?: string | null;
+  /** Directory the package name applies to, relative to the repository root (default: the root) */
+  package_directory?: string | null;
+}
+
+/** Scheme of the IDs cindex assigns */
+export const SYMBOL_ID_SCHEME = 'cindex';
+
+/** Names written without backticks */
+const SIMPLE_NAME = /^[\w$+-]+$/;
+
+/** Closing character of each descriptor suffix */
+const SUFFIX_TEXT: Record<DescriptorSuffix, string> = {
+  namespace: '/',
+  type: '#',
+  term: '.',
+  method: '().',
+  type_parameter: ']',
+};
+
+/**
+ * Kind of an indexed symbol
+ *
+ * @param symbolType - code_symbols.symbol_type
+ * @param language - Language of the symbol's file
+ */
+export const symbolKind = (symbolType: ExtractedSymbol['symbol_type'], language?: string | null): SymbolKind => {
+  switch (symbolType) {
+    case 'function':
+    case 'test':
+    case 'benchmark':
+    case 'fuzz':
+    case 'example':
+      return 'func';
+    case 'class':
+      return language === Language.Go ? 'struct' : 'class';
+    case 'constant':
+      return 'const';
+    case 'variable':
+      return 'var';
+    default:
+      return symbolType;
+  }
+};
+
+/**
+ * Name as written in an ID (backticks around names with other characters, doubled inside)
+ */
+const escapeName = (name: string): string => (SIMPLE_NAME.test(name) ? name : `\`${name.replace(/`/g, '``')}\``);
+
+/**
+ * Header field as written in an ID (spaces doubled, '.' when empty)
+ */
+const escapeField = (value: string | null | undefined): string => (value ? value.replace(/ /g, '  ') : '.');
+
+/**
+ * Descriptors as written in an ID (Service#Login().)
+ */
+const formatDescriptors = (descriptors: SymbolDescriptor[]): string =>
+  descriptors
+    .map(({ name, suffix }) => `${suffix === 'type_parameter' ? '[' : ''}${escapeName(name)}${SUFFIX_TEXT[suffix]}`)
+    .join('');
+
+/**
+ * Write a symbol ID from its parts
+ */
+export const formatSymbolId = (parts: SymbolIdParts): string => {
+  const header = [parts.scheme, parts.manager, parts.package, parts.version].map(escapeField).join(' ');
+  return `${header} ${formatDescriptors(parts.descriptors)}`;
+};
+
+/**
+ * Read the parts of a symbol ID
+ *
+ * @param id - Symbol ID, as formatSymbolId writes it
+ * @returns Parts, or null if the ID is malformed
+ */
+export const parseSymbolId = (id: string): SymbolIdParts | null => {
+  // Header fields end at a single space; a doubled space is part of the field
+  const fields: string[] = [];
+  let position = 0;
+  while (fields.length < 4) {
+    let field = '';
+    for (;;) {
+      if (position >= id.length) return null;
+      if (id[position] === ' ') {
+        if (id[position + 1] !== ' ') break;
+        position++;
+      }
+      field += id[position++];
+    }
+    position++;
+    fields.push(field);
+  }
+
+  const descriptors: SymbolDescriptor[] = [];
+  while (position < id.length) {
+    const typeParameter = id[position] === '[';
+    if (typeParameter) position++;
+
+    let name = '';
+    if (id[position] === '`') {
+      position++;
+      for (;;) {
+        if (position >= id.length) return null;
+        if (id[position] === '`') {
+          if (id[position + 1] !== '`') break;
+          position++;
+        }
+        name += id[position++];
+      }
+      position++;
+    } else {
+      const simple = /^[\w$+-]+/.exec(id.slice(position));
+      if (!simple) return null;
+      name = simple[0];
+      position += name.length;
+    }
+
+    const rest = id.slice(position);
+    const suffixes: DescriptorSuffix[] = typeParameter ? ['type_parameter'] : ['namespace', 'type', 'method', 'term'];
+    const suffix = suffixes.find((candidate) => rest.startsWith(SUFFIX_TEXT[candidate]));
+    if (!suffix) return null;
+    position += SUFFIX_TEXT[suffix].length;
+    descriptors.push({ name, suffix });
+  }
+  if (descriptors.length === 0) return null;
+
+  const [scheme, manager, pkg, version] = fields;
+  return { scheme, manager, package: pkg, version, descriptors };
+};
+
+/**
+ * Namespaces a symbol is declared in: its Go package, or the directories and name of its file
+ */
+const namespaceDescriptors = (input: SymbolIdInput): SymbolDescriptor[] => {
+  const directory = posix.dirname(input.file_path);
+  if (input.language !== Language.Go) {
+    return input.file_path.split('/').map((name) => ({ name, suffix: 'namespace' }));
+  }
+
+  // Import path: the module path and the directory below the module's
+  const within = posix.relative(input.package_directory ?? '.', directory);
+  const importPath = input.package_name
+    ? posix.join(input.package_name, within.startsWith('..') ? directory : within)
+    : directory;
+  return importPath === '.' ? [] : [{ name: importPath, suffix: 'namespace' }];
+};
+
+/**
+ * Descriptors of a symbol within its namespace
+ *
+ * Go methods and fields are named Type.Name; Go subtests Test/subtest/...
+ */
+const memberDescriptors = (input: SymbolIdInput): SymbolDescriptor[] => {
+  const kind = symbolKind(input.symbol_type, input.language);
+  const { symbol_name: name } = input;
+  const dot = name.lastIndexOf('.');
+
+  if ((kind === 'method' || kind === 'field') && dot > 0) {
+    return [
+      { name: name.slice(0, dot), suffix: 'type' },
+      { name: name.slice(dot + 1), suffix: kind === 'method' ? 'method' : 'term' },
+    ];
+  }
+  switch (kind) {
+    case 'func':
+    case 'method': {
+      const [test, ...subtests] = input.language === Language.Go ? name.split('/') : [name];
+      return [
+        { name: test, suffix: 'method' },
+        ...subtests.map((subtest): SymbolDescriptor => ({ name: subtest, suffix: 'term' })),
+      ];
+    }
+    case 'struct':
+    case 'class':
+    case 'interface':
+    case 'type':
+      return [{ name, suffix: 'type' }];
+    default:
+      return [{ name, suffix: 'term' }];
+  }
+};
+
+/**
+ * Structured ID of an indexed symbol
+ *
+ * @param input - Symbol with its file's language and package
+ * @returns ID such as "cindex gomod example.com/shop . `example.com/shop/auth`/Service#Login()."
+ */
+export const symbolId = (input: SymbolIdInput): string => {
+  const go = input.language === Language.Go;
+  return formatSymbolId({
+    scheme: SYMBOL_ID_SCHEME,
+    manager: input.package_name ? (go ? 'gomod' : 'npm') : '.',
+    package: input.package_name ?? '.',
+    version: '.',
+    descriptors: [...namespaceDescriptors(input), ...memberDescriptors(input)],
+  });
+};
+
+/**
+ * ID of a symbol declared inside another (a struct's field, a test's subtest)
+ *
+ * @param ownerId - ID of the enclosing symbol
+ * @param descriptors - Descriptors below it
+ */
+export const memberId = (ownerId: string, descriptors: SymbolDescriptor[]): string =>
+  `${ownerId}${formatDescriptors(descriptors)}`;
+
+/**
+ * ID of a type parameter of a generic function or type
+ *
+ * @param ownerId - ID of the generic function or type
+ * @param name - Type parameter name (T, K)
+ */
+export const typeParameterId = (ownerId: string, name: string): string =>
+  memberId(ownerId, [{ name, suffix: 'type_parameter' }]);
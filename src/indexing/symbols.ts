@@ -3,14 +3,21 @@
  *
  * Extracts symbols (functions, classes, variables, types) from parsed code
  * and generates embeddings for each symbol definition. Detects symbol scope
- * (exported vs internal) for improved search relevance.
+ * (exported vs internal) for improved search relevance. Go test functions
+ * are typed by kind (test, benchmark, fuzz, example), and their literal
+ * subtests are extracted as symbols of the same kind. Go methods and struct
+ * fields are named by their type (AuthService.Login, User.PasswordHash), the
+ * names typed references use.
  */
 
-import { randomUUID } from 'node:crypto';
-
+import { cloneFingerprint } from '@indexing/clones';
+import { cleanDocComment } from '@indexing/doc-comments';
 import { type EmbeddingGenerator } from '@indexing/embeddings';
+import { classifyGoTest, extractSubtests } from '@indexing/go-tests';
+import { memberId, symbolId } from '@indexing/symbol-ids';
 import { logger } from '@utils/logger';
 import {
+  Language,
   NodeType,
   type DiscoveredFile,
   type ExtractedSymbol,
@@ -50,7 +57,7 @@ export class SymbolExtractor {
       try {
         const symbol = await this.extractSymbolFromNode(node, file, exportedSymbols);
         if (symbol) {
-          symbols.push(symbol);
+          symbols.push(symbol, ...this.extractSubtestSymbols(symbol, node), ...this.extractFieldSymbols(symbol, node));
         }
       } catch (error) {
         logger.warn('Symbol extraction failed for node', {
@@ -93,32 +100,57 @@ export class SymbolExtractor {
       NodeType.Interface,
     ];
 
-    if (!symbolTypes.includes(node.node_type)) {
+    // Go methods are declared outside their type; methods of other languages are listed as functions
+    const goMethod = node.node_type === NodeType.Method && file.language === Language.Go && node.receiver;
+    if (!symbolTypes.includes(node.node_type) && !goMethod) {
       return null;
     }
 
     // Build symbol definition text
     const definition = this.buildDefinitionText(node);
 
-    // Detect symbol scope
+    // Detect symbol scope (a Go method is exported by its own name, not its receiver's)
     const scope = this.detectScope(node.name, exportedSymbols);
 
-    // Generate embedding for symbol definition
+    // Generate embedding for symbol definition and doc comment (what the symbol does, for semantic search)
+    const docComment = node.docstring ? cleanDocComment(node.docstring) || undefined : undefined;
     const embedding = await this.embeddingGenerator.generateTextEmbedding(
-      definition,
+      this.buildEmbeddingText(node, definition, docComment),
       `symbol ${node.name} in ${file.relative_path}`
     );
 
-    // Map node type to symbol type
-    const symbolType = this.mapNodeTypeToSymbolType(node.node_type);
+    // Map node type to symbol type (Go test functions by their kind)
+    const testKind =
+      file.language === Language.Go && node.node_type === NodeType.Function
+        ? classifyGoTest(node.name, file.relative_path)
+        : null;
+    const symbolType = testKind ?? this.mapNodeTypeToSymbolType(node.node_type);
+
+    // Clone fingerprint of function and method bodies (see @indexing/clones)
+    const fingerprint =
+      node.node_type === NodeType.Function || goMethod ? cloneFingerprint(node.code_text, file.language) : null;
 
     // Create extracted symbol
+    const symbolName = goMethod ? `${goMethod}.${node.name}` : node.name;
     const symbol: ExtractedSymbol = {
-      symbol_id: randomUUID(),
-      symbol_name: node.name,
+      symbol_id: symbolId({
+        symbol_name: symbolName,
+        symbol_type: symbolType,
+        file_path: file.relative_path,
+        language: file.language,
+        package_name: file.package_name,
+        package_directory: file.package_directory,
+      }),
+      symbol_name: symbolName,
       symbol_type: symbolType,
       file_path: file.relative_path,
       line_number: node.start_line,
+      end_line: node.end_line,
+      complexity: node.complexity,
+      cognitive_complexity: node.cognitive_complexity,
+      clone_signature: fingerprint?.signature,
+      clone_tokens: fingerprint?.tokens,
+      doc_comment: docComment,
       definition,
       embedding,
       scope,
@@ -132,6 +164,95 @@ export class SymbolExtractor {
     return symbol;
   };
 
+  /**
+   * Extract the literal subtests of a Go test function
+   *
+   * Subtests share their test's embedding: their code is part of the test's.
+   *
+   * @param test - Symbol of the enclosing function
+   * @param node - Parsed function node
+   * @returns Subtest symbols, or none if the symbol is not a test function
+   */
+  private extractSubtestSymbols = (test: ExtractedSymbol, node: ParsedNode): ExtractedSymbol[] => {
+    // Examples have no *testing.T to run subtests with
+    if (!['test', 'benchmark', 'fuzz'].includes(test.symbol_type)) {
+      return [];
+    }
+
+    return extractSubtests(test.symbol_name, node.code_text, node.start_line).map((subtest) => ({
+      ...test,
+      symbol_id: memberId(
+        test.symbol_id,
+        subtest.name
+          .slice(test.symbol_name.length + 1)
+          .split('/')
+          .map((name) => ({ name, suffix: 'term' as const }))
+      ),
+      symbol_name: subtest.name,
+      line_number: subtest.line,
+      end_line: subtest.end_line,
+      complexity: undefined,
+      cognitive_complexity: undefined,
+      clone_signature: undefined,
+      clone_tokens: undefined,
+      doc_comment: undefined,
+      definition: subtest.definition,
+      scope: 'internal' as const,
+    }));
+  };
+
+  /**
+   * Extract the fields of a Go struct, named Struct.Field
+   *
+   * Fields share their struct's embedding: a field is searched for by the
+   * type it belongs to.
+   *
+   * @param struct - Symbol of the struct
+   * @param node - Parsed struct node
+   * @returns Field symbols, or none if the node has no fields
+   */
+  private extractFieldSymbols = (struct: ExtractedSymbol, node: ParsedNode): ExtractedSymbol[] => {
+    const fields = (node.children ?? []).filter((child) => child.node_type === NodeType.Field);
+
+    return fields.map((field) => {
+      const docComment = field.docstring ? cleanDocComment(field.docstring) || undefined : undefined;
+      return {
+        ...struct,
+        symbol_id: memberId(struct.symbol_id, [{ name: field.name, suffix: 'term' }]),
+        symbol_name: `${struct.symbol_name}.${field.name}`,
+        symbol_type: 'field' as const,
+        line_number: field.start_line,
+        end_line: field.end_line,
+        complexity: undefined,
+        cognitive_complexity: undefined,
+        clone_signature: undefined,
+        clone_tokens: undefined,
+        doc_comment: docComment,
+        definition: field.code_text.trim(),
+        // Exported fields are reachable through values of the type even when the type is not exported
+        scope: /^\p{Lu}/u.test(field.name) ? ('exported' as const) : ('internal' as const),
+      };
+    });
+  };
+
+  /**
+   * Build the text embedded for a symbol: its definition, then its whole doc comment
+   *
+   * Function and class definitions end with the first 200 characters of the
+   * raw docstring; that tail is replaced by the cleaned comment.
+   *
+   * @param node - Parsed node
+   * @param definition - Symbol definition text
+   * @param docComment - Cleaned doc comment
+   * @returns Text to embed
+   */
+  private buildEmbeddingText = (node: ParsedNode, definition: string, docComment?: string): string => {
+    if (!node.docstring || !docComment) return definition;
+    const docTail = ` - ${node.docstring.slice(0, 200)}`;
+    const signature = definition.endsWith(docTail) ? definition.slice(0, -docTail.length) : definition;
+    return `${signature}\n\n${docComment}`;
+  };
+
   /**
    * Build symbol definition text for embedding
    *
@@ -144,6 +265,7 @@ export class SymbolExtractor {
   private buildDefinitionText = (node: ParsedNode): string => {
     switch (node.node_type) {
       case NodeType.Function:
+      case NodeType.Method:
         return this.buildFunctionDefinition(node);
 
       case NodeType.Class:
@@ -165,9 +287,10 @@ export class SymbolExtractor {
   /**
    * Build function definition text
    *
-   * Format: "function name(params): returnType - docstring"
+   * Format: "function name(params): returnType - docstring", or
+   * "method Receiver.name(...)" for Go methods
    *
-   * @param node - Function node
+   * @param node - Function or method node
    * @returns Function definition
    */
   private buildFunctionDefinition = (node: ParsedNode): string => {
@@ -176,8 +299,9 @@ export class SymbolExtractor {
     // Function signature
     const params = node.parameters?.map((p) => `${p.name}${p.type ? `: ${p.type}` : ''}`).join(', ') ?? '';
     const returnType = node.return_type ?? 'void';
+    const name = node.receiver ? `method ${node.receiver}.${node.name}` : `function ${node.name}`;
 
-    parts.push(`function ${node.name}(${params}): ${returnType}`);
+    parts.push(`${name}(${params}): ${returnType}`);
 
     // Include docstring if available
     if (node.docstring) {
@@ -291,6 +415,8 @@ export class SymbolExtractor {
         return 'type';
       case NodeType.Interface:
         return 'interface';
+      case NodeType.Method:
+        return 'method';
       default:
         return 'variable';
     }
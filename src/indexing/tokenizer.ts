@@ -0,0 +1,127 @@
+/**
+ * Identifier tokenizer: the words of symbol names, for word: search
+ *
+ * A name is split into words (camelCase humps, initialisms, and the parts
+ * between _ - . or $), lowercased, and stemmed, so word:service
+ * finds NewAuthService, AuthServices, and service_registry alike. Each
+ * symbol's tokens, the words and their stems, are stored at index time in
+ * code_symbols.name_tokens and matched by the database before its
+ * candidate limit.
+ *
+ * The default tokenizer covers the identifiers of every indexed language.
+ * A language whose names split or stem differently registers its own with
+ * registerTokenizer; re-index for the stored tokens to follow.
+ */
+import { identifierWords } from '@utils/unicode';
+
+/**
+ * Splits and stems the identifiers of a language
+ */
+export interface IdentifierTokenizer {
+  /** Words of an identifier as written (NewAuthService: New, Auth, Service) */
+  split: (identifier: string) => string[];
+  /** Stem of a lowercase word; without one, words only match as written */
+  stem?: (word: string) => string;
+}
+
+/** Words at most this long are never stemmed (id, url, key) */
+const MIN_STEM_LENGTH = 3;
+
+/** Vowel in a stem (a stem needs one: "string" keeps its "ing") */
+const VOWEL = /[aeiouy]/;
+
+/**
+ * Drop a suffix if what remains is a long enough stem with a vowel
+ */
+const stripSuffix = (word: string, suffix: string): string | null => {
+  if (!word.endsWith(suffix)) return null;
+  const stem = word.slice(0, -suffix.length);
+  return stem.length >= MIN_STEM_LENGTH && VOWEL.test(stem) ? stem : null;
+};
+
+/**
+ * Light English stemmer for identifier words
+ *
+ * Strips plurals, -ing, -ed, -er, and a final e, and undoubles the consonant
+ * left by -ing and -ed, so parse, parsed, parser, and parsing all stem to
+ * pars, and services and service to servic. Stems are match keys, not words.
+ *
+ * @param word - Lowercase word
+ * @returns Stem (the word itself when short or not a plain ASCII word)
+ */
+export const stemWord = (word: string): string => {
+  if (word.length <= MIN_STEM_LENGTH || !/^[a-z]+$/.test(word)) return word;
+
+  let stem = word;
+  if (stem.endsWith('ies') && stem.length > 4) stem = `${stem.slice(0, -3)}y`;
+  else if (stem.endsWith('sses')) stem = stem.slice(0, -2);
+  else if (stem.endsWith('s') && !/(?:ss|us|is)$/.test(stem)) stem = stem.slice(0, -1);
+
+  // -eed is not a past tense (speed, feed)
+  const verb = stripSuffix(stem, 'ing') ?? (stem.endsWith('eed') ? null : stripSuffix(stem, 'ed'));
+  if (verb !== null) {
+    stem = /([^aeiouylsz])\1$/.test(verb) ? verb.slice(0, -1) : verb;
+  } else {
+    stem = stripSuffix(stem, 'er') ?? stem;
+  }
+
+  return stripSuffix(stem, 'e') ?? stem;
+};
+
+/**
+ * Tokenizer of every language without one of its own
+ */
+export const DEFAULT_TOKENIZER: IdentifierTokenizer = { split: identifierWords, stem: stemWord };
+
+/** Tokenizers registered by language */
+const tokenizers = new Map<string, IdentifierTokenizer>();
+
+/**
+ * Use a tokenizer for the identifiers of a language
+ *
+ * Names already indexed keep their tokens until re-indexed.
+ *
+ * @param language - Language as indexed (go, python, ...)
+ * @param tokenizer - Tokenizer, replacing any registered before
+ */
+export const registerTokenizer = (language: string, tokenizer: IdentifierTokenizer): void => {
+  tokenizers.set(language, tokenizer);
+};
+
+/**
+ * Tokenizer of a language (the default one if none is registered)
+ */
+export const tokenizerFor = (language?: string | null): IdentifierTokenizer =>
+  (language ? tokenizers.get(language) : undefined) ?? DEFAULT_TOKENIZER;
+
+/**
+ * Words of an identifier or query, each lowercase with its stem
+ *
+ * @param text - Identifier (NewAuthService) or words (auth service)
+ * @param language - Language the tokenizer is chosen by (default: the default tokenizer)
+ * @returns Words in order, repeats left out
+ */
+export const identifierTokens = (text: string, language?: string | null): { word: string; stem: string }[] => {
+  const tokenizer = tokenizerFor(language);
+  const seen = new Set<string>();
+  const tokens: { word: string; stem: string }[] = [];
+  for (const written of tokenizer.split(text)) {
+    const word = written.toLowerCase();
+    if (seen.has(word)) continue;
+    seen.add(word);
+    tokens.push({ word, stem: tokenizer.stem ? tokenizer.stem(word) : word });
+  }
+  return tokens;
+};
+
+/**
+ * Tokens stored for a symbol name: its words and their stems
+ *
+ * @param name - Symbol name
+ * @param language - Language of the symbol's file
+ * @returns Distinct tokens, words before stems
+ */
+export const nameTokens = (name: string, language?: string | null): string[] => {
+  const tokens = identifierTokens(name, language);
+  return [...new Set([...tokens.map((token) => token.word), ...tokens.map((token) => token.stem)])];
+};
@@ -19,7 +19,9 @@
  */
 import { type Pool, type QueryResult } from 'pg';
 
+import { removeFromStore } from '@database/store';
 import { logger } from '@utils/logger';
+import { type StoreConfig } from '@/types/config';
 import {
   type CountResult,
   type RepositoryMetadata,
@@ -250,6 +252,19 @@ export const clearRepositoryData = async (db: Pool, repoId: string): Promise<voi
   await db.query('DELETE FROM code_chunks WHERE repo_id = $1', [repoId]);
   await db.query('DELETE FROM code_symbols WHERE repo_id = $1', [repoId]);
   await db.query('DELETE FROM code_files WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM secret_findings WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM lint_findings WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_implementations WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_references WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_calls WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_constants WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_type_parameters WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_instantiations WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM go_embeddings WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM code_annotations WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM code_literals WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM code_plugin_metrics WHERE repo_id = $1', [repoId]);
+  await db.query('DELETE FROM code_contents WHERE repo_id = $1', [repoId]);
   await db.query('DELETE FROM workspace_dependencies WHERE repo_id = $1', [repoId]);
   await db.query('DELETE FROM workspace_aliases WHERE repo_id = $1', [repoId]);
   await db.query('DELETE FROM workspaces WHERE repo_id = $1', [repoId]);
@@ -495,10 +510,11 @@ export interface DeletionStats {
  *
  * @param db - Database connection pool
  * @param repoId - Repository identifier to delete
+ * @param store - Store to remove the repository's symbols from too (STORE_BACKEND)
  * @returns Deletion statistics with entity counts
  * @throws {Error} If repository not found
  */
-export const deleteRepository = async (db: Pool, repoId: string): Promise<DeletionStats> => {
+export const deleteRepository = async (db: Pool, repoId: string, store?: StoreConfig): Promise<DeletionStats> => {
   logger.info('Deleting repository', { repo_id: repoId });
 
   // Get repository info and stats before deletion
@@ -525,6 +541,7 @@ export const deleteRepository = async (db: Pool, repoId: string): Promise<Deleti
 
   // Delete repository entry
   await db.query('DELETE FROM repositories WHERE repo_id = $1', [repoId]);
+  if (store) await removeFromStore(store, db, repoId);
 
   logger.info('Repository deleted', {
     repo_id: repoId,
@@ -0,0 +1,157 @@
+/**
+ * Watcher: Keep Indexes Current While Files Change
+ *
+ * Watches indexed roots with recursive fs.watch and re-indexes a root
+ * incrementally once its changes settle. A save burst (format on save, a
+ * branch checkout) becomes one run: each change restarts a short debounce,
+ * and a root that never quiets down is still flushed after MAX_BATCH_WAIT_MS.
+ * Changes arriving during a run are batched for the next one, so runs on a
+ * root never overlap.
+ *
+ * Files are added, updated, and removed by the incremental run itself (see
+ * @indexing/incremental); files whose size and mtime are unchanged are not
+ * read, so a run after a few edits costs a directory walk.
+ */
+
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { resolveExcludedDirectories } from '@indexing/default-exclusions';
+import { logger } from '@utils/logger';
+import { toPosixPath } from '@utils/paths';
+
+/** Quiet period after the last change before a root is re-indexed */
+export const DEFAULT_DEBOUNCE_MS = 500;
+
+/** Longest a change waits while a root keeps changing */
+export const MAX_BATCH_WAIT_MS = 5000;
+
+/**
+ * Check whether a changed path can affect the index
+ *
+ * Paths inside excluded directories (.git, node_modules, ...) never do.
+ *
+ * @param relativePath - Path relative to the watched root (POSIX separators)
+ * @param excludedDirectories - Directory names never indexed
+ */
+export const isWatchedPath = (relativePath: string, excludedDirectories: ReadonlySet<string>): boolean => {
+  return relativePath !== '' && !relativePath.split('/').some((segment) => excludedDirectories.has(segment));
+};
+
+/**
+ * Collects changed paths and flushes them in batches
+ *
+ * The flush callback is never called while a previous flush is running.
+ */
+export class ChangeBatcher {
+  private pending = new Set<string>();
+  private timer: NodeJS.Timeout | null = null;
+  /** When the oldest pending change arrived (0: nothing pending) */
+  private firstChangeAt = 0;
+  private running: Promise<void> | null = null;
+  private closed = false;
+
+  constructor(
+    private readonly flush: (paths: string[]) => Promise<void>,
+    private readonly debounceMs = DEFAULT_DEBOUNCE_MS,
+    private readonly maxWaitMs = MAX_BATCH_WAIT_MS
+  ) {}
+
+  /**
+   * Record a changed path and (re)start the debounce
+   */
+  public add = (relativePath: string): void => {
+    if (this.closed) return;
+    if (this.pending.size === 0) this.firstChangeAt = Date.now();
+    this.pending.add(relativePath);
+    if (!this.running) this.schedule();
+  };
+
+  /**
+   * Stop flushing and wait for the running flush to finish
+   *
+   * @returns Paths changed but not flushed
+   */
+  public close = async (): Promise<string[]> => {
+    this.closed = true;
+    if (this.timer) clearTimeout(this.timer);
+    this.timer = null;
+    await this.running;
+    return [...this.pending].sort();
+  };
+
+  private schedule = (): void => {
+    if (this.timer) clearTimeout(this.timer);
+    if (this.pending.size === 0 || this.closed) return;
+    const deadline = this.firstChangeAt + this.maxWaitMs - Date.now();
+    this.timer = setTimeout(this.run, Math.max(0, Math.min(this.debounceMs, deadline)));
+  };
+
+  private run = (): void => {
+    this.timer = null;
+    const paths = [...this.pending].sort();
+    this.pending.clear();
+    this.firstChangeAt = 0;
+
+    this.running = this.flush(paths)
+      .catch((error: unknown) => {
+        logger.error('Watch batch failed', { error: error instanceof Error ? error.message : String(error) });
+      })
+      .finally(() => {
+        this.running = null;
+        this.schedule();
+      });
+  };
+}
+
+/**
+ * Watches roots and hands each root's settled changes to a callback
+ */
+export class IndexWatcher {
+  private readonly watchers: fs.FSWatcher[] = [];
+  private readonly batchers: ChangeBatcher[] = [];
+  private readonly excludedDirectories: Set<string>;
+
+  /**
+   * @param onChanges - Called with a root and its changed paths (relative, POSIX), one call per root at a time
+   * @param options.debounceMs - Quiet period before a root is re-indexed
+   * @param options.excludeDirectories - EXCLUDE_DIRECTORIES overrides (changes below these are ignored)
+   */
+  constructor(
+    private readonly onChanges: (root: string, paths: string[]) => Promise<void>,
+    private readonly options: { debounceMs?: number; excludeDirectories?: string[] } = {}
+  ) {
+    this.excludedDirectories = resolveExcludedDirectories(options.excludeDirectories);
+  }
+
+  /**
+   * Start watching a root
+   *
+   * @param root - Directory to watch (recursively)
+   * @throws {Error} If the directory cannot be watched (missing, or no recursive fs.watch on this platform)
+   */
+  public watch = (root: string): void => {
+    const batcher = new ChangeBatcher((paths) => this.onChanges(root, paths), this.options.debounceMs);
+    const watcher = fs.watch(root, { recursive: true }, (_event, filename) => {
+      // Some platforms omit the name when many files change at once: treat the root as changed
+      const relative = filename ? toPosixPath(filename.toString()) : '.';
+      if (relative === '.' || isWatchedPath(relative, this.excludedDirectories)) {
+        batcher.add(relative);
+      }
+    });
+    watcher.on('error', (error) => {
+      logger.error('Watching stopped for a root', { root, error: error.message });
+    });
+    this.watchers.push(watcher);
+    this.batchers.push(batcher);
+    logger.debug('Watching root', { root: path.resolve(root) });
+  };
+
+  /**
+   * Stop watching and wait for running re-index batches
+   */
+  public close = async (): Promise<void> => {
+    for (const watcher of this.watchers) watcher.close();
+    await Promise.all(this.batchers.map((batcher) => batcher.close()));
+  };
+}
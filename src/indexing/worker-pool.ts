@@ -0,0 +1,83 @@
+/**
+ * Bounded worker pool for the indexing pipeline
+ *
+ * Files are produced in discovery order and consumed by a fixed number of
+ * workers. Admission is bounded twice: by the number of workers and by the
+ * bytes of source in flight, so a run over many large files waits for
+ * earlier files to be persisted instead of holding all their parse trees,
+ * chunks, and embeddings at once. An item is always admitted when nothing
+ * else is in flight, so a file larger than the budget still gets indexed.
+ */
+
+/**
+ * Worker pool settings
+ */
+export interface WorkerPoolOptions<T> {
+  /** Items processed at a time */
+  jobs: number;
+
+  /** Memory weight of an item (e.g. file size in bytes) */
+  weight: (item: T) => number;
+
+  /** Total weight allowed in flight */
+  budget: number;
+
+  /** Stop admitting items when aborted; items in flight are finished */
+  signal?: AbortSignal;
+}
+
+/** Source bytes in flight by default (parse results and chunks take several times more) */
+export const DEFAULT_IN_FLIGHT_BYTES = 64 * 1024 * 1024;
+
+/**
+ * Process items with bounded concurrency and back-pressure
+ *
+ * @param items - Items in the order they are admitted
+ * @param options - Concurrency, weight, and budget
+ * @param work - Processes one item; it should handle its own errors
+ * @returns Number of items admitted (less than items.length after an abort)
+ */
+export const runWorkerPool = async <T>(
+  items: readonly T[],
+  options: WorkerPoolOptions<T>,
+  work: (item: T) => Promise<void>
+): Promise<number> => {
+  const jobs = Math.max(1, Math.floor(options.jobs));
+  let next = 0;
+  let inFlight = 0;
+  let inFlightWeight = 0;
+  let waiting: (() => void)[] = [];
+
+  const release = (weight: number): void => {
+    inFlight--;
+    inFlightWeight -= weight;
+    const woken = waiting;
+    waiting = [];
+    for (const wake of woken) wake();
+  };
+
+  const consume = async (): Promise<void> => {
+    while (next < items.length && !options.signal?.aborted) {
+      const item = items[next];
+      const weight = Math.max(0, options.weight(item));
+
+      // Back-pressure: wait for memory to be released, unless nothing else holds any
+      if (inFlight > 0 && inFlightWeight + weight > options.budget) {
+        await new Promise<void>((resolve) => waiting.push(resolve));
+        continue;
+      }
+
+      next++;
+      inFlight++;
+      inFlightWeight += weight;
+      try {
+        await work(item);
+      } finally {
+        release(weight);
+      }
+    }
+  };
+
+  await Promise.all(Array.from({ length: Math.min(jobs, items.length) }, consume));
+  return next;
+};
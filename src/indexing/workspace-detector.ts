@@ -15,7 +15,10 @@
 import * as fs from 'node:fs/promises';
 import * as path from 'node:path';
 
+import { readTextFile } from '@utils/edge-cases';
 import { logger } from '@utils/logger';
+import { compareNames } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
 import { type PackageJsonInfo, type TsConfigInfo } from '@/types/workspace';
 
 /**
@@ -200,7 +203,7 @@ export class WorkspaceDetector {
     const workspaceFile = path.join(this.rootPath, 'pnpm-workspace.yaml');
 
     try {
-      const content = await fs.readFile(workspaceFile, 'utf-8');
+      const content = await readTextFile(workspaceFile);
 
       // Simple YAML parsing for packages array
       const packagesMatch = /packages:\s*\n((?:\s+-\s+.+\n)+)/.exec(content);
@@ -240,7 +243,7 @@ export class WorkspaceDetector {
     const packageJsonPath = path.join(this.rootPath, 'package.json');
 
     try {
-      const content = await fs.readFile(packageJsonPath, 'utf-8');
+      const content = await readTextFile(packageJsonPath);
       const packageJson = parsePackageJson(content);
 
       if (!packageJson.workspaces) {
@@ -313,7 +316,7 @@ export class WorkspaceDetector {
     const lernaJsonPath = path.join(this.rootPath, 'lerna.json');
 
     try {
-      const content = await fs.readFile(lernaJsonPath, 'utf-8');
+      const content = await readTextFile(lernaJsonPath);
       const lernaJson = parseJsonToRecord(content);
 
       const packagesField = lernaJson.packages;
@@ -369,7 +372,7 @@ export class WorkspaceDetector {
     const rushJsonPath = path.join(this.rootPath, 'rush.json');
 
     try {
-      const content = await fs.readFile(rushJsonPath, 'utf-8');
+      const content = await readTextFile(rushJsonPath);
       const rushJson = parseJsonToRecord(content) as { projects?: RushProject[] };
 
       // Rush defines projects array
@@ -407,7 +410,7 @@ export class WorkspaceDetector {
         const baseDir = path.join(this.rootPath, basePath);
 
         try {
-          const entries = await fs.readdir(baseDir, { withFileTypes: true });
+          const entries = (await fs.readdir(baseDir, { withFileTypes: true })).sort(compareNames);
 
           for (const entry of entries) {
             if (entry.isDirectory()) {
@@ -445,7 +448,7 @@ export class WorkspaceDetector {
     const packageJsonPath = path.join(packagePath, 'package.json');
 
     try {
-      const content = await fs.readFile(packageJsonPath, 'utf-8');
+      const content = await readTextFile(packageJsonPath);
       const packageJson = parsePackageJson(content);
 
       if (!packageJson.name) {
@@ -455,7 +458,7 @@ export class WorkspaceDetector {
       return {
         name: packageJson.name,
         path: packagePath,
-        relativePath: path.relative(this.rootPath, packagePath),
+        relativePath: toPosixPath(path.relative(this.rootPath, packagePath)),
         version: packageJson.version,
         dependencies: packageJson.dependencies,
         devDependencies: packageJson.devDependencies,
@@ -473,7 +476,7 @@ export class WorkspaceDetector {
     const tsconfigPath = path.join(this.rootPath, 'tsconfig.json');
 
     try {
-      const content = await fs.readFile(tsconfigPath, 'utf-8');
+      const content = await readTextFile(tsconfigPath);
       // Remove comments (simple approach)
       const jsonContent = content.replace(/\/\/.*/g, '').replace(/\/\*[\s\S]*?\*\//g, '');
       const tsconfig = parseTsConfig(jsonContent);
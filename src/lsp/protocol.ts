@@ -0,0 +1,131 @@
+/**
+ * Language Server Protocol wire format and conversions
+ *
+ * Messages are JSON-RPC 2.0 bodies behind a Content-Length header, over
+ * stdin and stdout. Positions are 0-based; columns count UTF-16 code units
+ * unless the client accepts UTF-8 (positionEncoding, LSP 3.17).
+ */
+
+import * as path from 'node:path';
+import { fileURLToPath, pathToFileURL } from 'node:url';
+
+import { type OutlineKind } from '@retrieval/outline';
+import { type PositionEncoding } from '@utils/positions';
+import { type JsonRpcRequest, type JsonRpcResponse } from '@/types/lsp';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/**
+ * JSON-RPC and LSP error codes
+ */
+export enum LspErrorCode {
+  ParseError = -32700,
+  InvalidRequest = -32600,
+  MethodNotFound = -32601,
+  InvalidParams = -32602,
+  InternalError = -32603,
+  ServerNotInitialized = -32002,
+}
+
+/** SymbolKind numbers of the LSP specification, by code_symbols.symbol_type and outline kind */
+const SYMBOL_KINDS: Record<ResolvedSymbol['symbol_type'] | OutlineKind, number> = {
+  module: 2,
+  package: 4,
+  class: 5,
+  method: 6,
+  field: 8,
+  interface: 11,
+  function: 12,
+  variable: 13,
+  constant: 14,
+  type: 26,
+  test: 12,
+  benchmark: 12,
+  fuzz: 12,
+  example: 12,
+};
+
+const HEADER_END = '\r\n\r\n';
+
+/**
+ * Frame a message for the wire
+ */
+export const encodeMessage = (message: JsonRpcRequest | JsonRpcResponse): Buffer => {
+  const body = Buffer.from(JSON.stringify(message), 'utf8');
+  return Buffer.concat([Buffer.from(`Content-Length: ${String(body.length)}${HEADER_END}`, 'ascii'), body]);
+};
+
+/**
+ * Split a byte stream into messages
+ *
+ * Chunks may end anywhere, inside a header or a body; bytes of an incomplete
+ * message are kept for the next chunk.
+ */
+export class MessageReader {
+  private buffer = Buffer.alloc(0);
+
+  /**
+   * Add bytes read from the stream
+   *
+   * @param chunk - Bytes as received
+   * @returns Bodies of the messages completed by the chunk, parsed (undefined for a body that is not JSON)
+   * @throws {Error} If a header has no valid Content-Length
+   */
+  public push = (chunk: Buffer): unknown[] => {
+    this.buffer = Buffer.concat([this.buffer, chunk]);
+    const messages: unknown[] = [];
+    for (;;) {
+      const headerEnd = this.buffer.indexOf(HEADER_END);
+      if (headerEnd === -1) return messages;
+      const header = this.buffer.subarray(0, headerEnd).toString('ascii');
+      const length = /^content-length:\s*(\d+)\s*$/im.exec(header);
+      if (!length) throw new Error(`Message header without Content-Length: ${header}`);
+
+      const start = headerEnd + HEADER_END.length;
+      const end = start + Number(length[1]);
+      if (this.buffer.length < end) return messages;
+      const body = this.buffer.subarray(start, end).toString('utf8');
+      this.buffer = this.buffer.subarray(end);
+      try {
+        messages.push(JSON.parse(body));
+      } catch {
+        messages.push(undefined);
+      }
+    }
+  };
+}
+
+/**
+ * URI of a file
+ */
+export const toFileUri = (filePath: string): string => pathToFileURL(filePath).href;
+
+/**
+ * Path of a file: URI
+ *
+ * @returns Absolute path, or null for other schemes (untitled:, git:)
+ */
+export const fromFileUri = (uri: string): string | null => {
+  if (!uri.startsWith('file:')) return null;
+  try {
+    return path.normalize(fileURLToPath(uri));
+  } catch {
+    return null;
+  }
+};
+
+/**
+ * LSP SymbolKind of an indexed symbol or outline entry (tests and examples are functions)
+ */
+export const toSymbolKind = (symbolType: ResolvedSymbol['symbol_type'] | OutlineKind): number =>
+  SYMBOL_KINDS[symbolType];
+
+/**
+ * Pick the position encoding from the client's offer
+ *
+ * UTF-8 when offered, since stored Go positions are byte columns; the
+ * specification's default, UTF-16, otherwise.
+ *
+ * @param offered - general.positionEncodings of the client capabilities
+ */
+export const negotiateEncoding = (offered: unknown): PositionEncoding =>
+  Array.isArray(offered) && offered.includes('utf-8') ? 'utf-8' : 'utf-16';
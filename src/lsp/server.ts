@@ -0,0 +1,572 @@
+/**
+ * Language server backed by the index (cindex serve --lsp)
+ *
+ * Answers four requests for every indexed repository at once, so an editor
+ * can navigate across repositories without a language server per language:
+ *
+ *   workspace/symbol             fuzzy symbol search (see @retrieval/fuzzy-symbols)
+ *   textDocument/definition      type-checked Go references, else symbols named like the identifier
+ *   textDocument/references      type-checked Go references, else whole-word matches in indexed contents
+ *   textDocument/documentSymbol  outline of the document (see @retrieval/outline)
+ *
+ * Answers reflect the last index run, except outlines, which are parsed from
+ * the open document's text. Open documents are kept in full (sync kind Full)
+ * to read the identifier under the cursor and to outline them.
+ */
+
+import * as path from 'node:path';
+import { type Readable, type Writable } from 'node:stream';
+
+import { type Pool } from 'pg';
+
+import { findGoReferenceAt, listGoReferencesTo, listIndexedRepositories, searchSymbols } from '@database/queries';
+import {
+  encodeMessage,
+  fromFileUri,
+  LspErrorCode,
+  MessageReader,
+  negotiateEncoding,
+  toFileUri,
+  toSymbolKind,
+} from '@lsp/protocol';
+import { escapeRegex, searchContent } from '@retrieval/content-search';
+import { searchSymbolsFuzzy } from '@retrieval/fuzzy-symbols';
+import { identifierAt } from '@retrieval/gopls';
+import { outlineFile, type OutlineNode } from '@retrieval/outline';
+import { readSourceFile } from '@utils/edge-cases';
+import { logger } from '@utils/logger';
+import { queryDuration, secondsSince } from '@utils/metrics';
+import { toPosixPath } from '@utils/paths';
+import { byteToUtf16Column, utf16ToByteColumn, type PositionEncoding } from '@utils/positions';
+import { Language, LANGUAGE_EXTENSIONS } from '@/types/indexing';
+import {
+  type JsonRpcRequest,
+  type JsonRpcResponse,
+  type LspDocumentSymbol,
+  type LspLocation,
+  type LspSymbolInformation,
+  type LspTextDocumentPositionParams,
+} from '@/types/lsp';
+
+/** Symbols returned by workspace/symbol */
+const WORKSPACE_SYMBOL_LIMIT = 100;
+
+/** Symbols named like an identifier considered for textDocument/definition */
+const DEFINITION_CANDIDATES = 200;
+
+/** Locations returned by textDocument/references */
+const REFERENCE_LIMIT = 1000;
+
+/**
+ * Indexed repository served
+ */
+interface ServedRoot {
+  repoId: string;
+  repoPath: string;
+}
+
+/**
+ * Identifier under the cursor of a request
+ */
+interface CursorTarget {
+  root: ServedRoot;
+  /** Path relative to the repository root */
+  filePath: string;
+  /** 1-based line */
+  line: number;
+  /** 1-based byte column */
+  byteColumn: number;
+  name: string;
+}
+
+/**
+ * Params of the document synchronization notifications (didOpen, didChange, didClose)
+ */
+interface DocumentNotification {
+  textDocument?: { uri?: string; text?: string };
+  contentChanges?: { text?: string }[];
+}
+
+/**
+ * Error answered to a request instead of a result
+ */
+class RequestError extends Error {
+  constructor(
+    public readonly code: LspErrorCode,
+    message: string
+  ) {
+    super(message);
+  }
+}
+
+/**
+ * Language server over one message stream
+ */
+export class LspServer {
+  private encoding: PositionEncoding = 'utf-16';
+  private roots: ServedRoot[] = [];
+  private readonly documents = new Map<string, string>();
+  private initialized = false;
+  private shutdownRequested = false;
+
+  /**
+   * @param db - Database connection pool
+   * @param repoId - Serve one index (default: every indexed repository)
+   */
+  constructor(
+    private readonly db: Pool,
+    private readonly repoId?: string
+  ) {}
+
+  /**
+   * Whether a shutdown request preceded exit (the process exits 0 only then)
+   */
+  public get cleanShutdown(): boolean {
+    return this.shutdownRequested;
+  }
+
+  /**
+   * Handle one message
+   *
+   * @param message - Parsed message body
+   * @returns Response for a request, null for a notification
+   */
+  public handle = async (message: unknown): Promise<JsonRpcResponse | null> => {
+    if (message === undefined) {
+      return { jsonrpc: '2.0', id: null, error: { code: LspErrorCode.ParseError, message: 'Message is not JSON' } };
+    }
+    if (!isRequest(message)) {
+      return { jsonrpc: '2.0', id: null, error: { code: LspErrorCode.InvalidRequest, message: 'Invalid request' } };
+    }
+    const { id, method, params } = message;
+    const start = performance.now();
+    try {
+      const result = await this.dispatch(method, params);
+      return id === undefined ? null : { jsonrpc: '2.0', id, result };
+    } catch (error) {
+      if (id === undefined) {
+        // Unknown notifications are ignored, as the specification asks
+        if (!(error instanceof RequestError)) logger.warn('LSP notification failed', { method, error: String(error) });
+        return null;
+      }
+      const code = error instanceof RequestError ? error.code : LspErrorCode.InternalError;
+      return { jsonrpc: '2.0', id, error: { code, message: error instanceof Error ? error.message : String(error) } };
+    } finally {
+      if (id !== undefined) queryDuration.observe(secondsSince(start), { api: 'lsp', endpoint: method });
+    }
+  };
+
+  /**
+   * Run a method
+   *
+   * @returns Result of the method (null for notifications)
+   * @throws {RequestError} If the method is unknown or called before initialize
+   */
+  private dispatch = (method: string, params: unknown): Promise<unknown> => {
+    if (method === 'initialize') return this.initialize(params);
+    if (!this.initialized && method !== 'exit') {
+      throw new RequestError(LspErrorCode.ServerNotInitialized, 'Server not initialized');
+    }
+
+    switch (method) {
+      case 'workspace/symbol':
+        return this.workspaceSymbol(String((params as { query?: unknown } | undefined)?.query ?? ''));
+      case 'textDocument/definition':
+        return this.definition(positionParams(params));
+      case 'textDocument/references':
+        return this.references(positionParams(params));
+      case 'textDocument/documentSymbol':
+        return this.documentSymbol(documentUri(params));
+      case 'shutdown':
+        this.shutdownRequested = true;
+        return Promise.resolve(null);
+      default:
+        if (!this.notify(method, params)) {
+          throw new RequestError(LspErrorCode.MethodNotFound, `Unsupported method: ${method}`);
+        }
+        return Promise.resolve(null);
+    }
+  };
+
+  /**
+   * Apply a notification: document synchronization, or one with nothing to do
+   *
+   * @returns False if the method is not a known notification
+   */
+  private notify = (method: string, params: unknown): boolean => {
+    const { textDocument: document, contentChanges } = (params ?? {}) as DocumentNotification;
+    switch (method) {
+      case 'textDocument/didOpen':
+        if (document?.uri && typeof document.text === 'string') this.documents.set(document.uri, document.text);
+        return true;
+      case 'textDocument/didChange': {
+        const text = contentChanges?.at(-1)?.text;
+        if (document?.uri && typeof text === 'string') this.documents.set(document.uri, text);
+        return true;
+      }
+      case 'textDocument/didClose':
+        if (document?.uri) this.documents.delete(document.uri);
+        return true;
+      default:
+        return method === 'initialized' || method === 'exit' || method.startsWith('$/');
+    }
+  };
+
+  /**
+   * initialize: negotiate positions, load the served repositories, and report capabilities
+   */
+  private initialize = async (params: unknown): Promise<unknown> => {
+    const capabilities = (params as { capabilities?: { general?: { positionEncodings?: unknown } } } | undefined)
+      ?.capabilities;
+    this.encoding = negotiateEncoding(capabilities?.general?.positionEncodings);
+
+    const repositories = await listIndexedRepositories(this.db);
+    this.roots = repositories
+      .filter((repo) => repo.repo_path && (!this.repoId || repo.repo_id === this.repoId))
+      .map((repo) => ({ repoId: repo.repo_id, repoPath: path.resolve(repo.repo_path ?? '') }));
+    this.initialized = true;
+    logger.info('LSP server initialized', { roots: this.roots.length, encoding: this.encoding });
+
+    return {
+      capabilities: {
+        positionEncoding: this.encoding,
+        textDocumentSync: 1,
+        workspaceSymbolProvider: true,
+        definitionProvider: true,
+        referencesProvider: true,
+        documentSymbolProvider: true,
+      },
+      serverInfo: { name: 'cindex' },
+    };
+  };
+
+  /**
+   * workspace/symbol: symbols whose names match the query, best first
+   */
+  private workspaceSymbol = async (query: string): Promise<LspSymbolInformation[]> => {
+    if (query.trim() === '') return [];
+    const symbols = await searchSymbolsFuzzy(this.db, query, { repoId: this.repoId, limit: WORKSPACE_SYMBOL_LIMIT });
+    return symbols.flatMap((symbol) => {
+      const root = this.rootById(symbol.repo_id);
+      if (!root) return [];
+      const dot = symbol.symbol_name.lastIndexOf('.');
+      const at = { line: symbol.line_number - 1, character: 0 };
+      return [
+        {
+          name: dot === -1 ? symbol.symbol_name : symbol.symbol_name.slice(dot + 1),
+          kind: toSymbolKind(symbol.symbol_type),
+          location: { uri: toFileUri(path.join(root.repoPath, symbol.file_path)), range: { start: at, end: at } },
+          containerName: dot === -1 ? symbol.file_path : symbol.symbol_name.slice(0, dot),
+        },
+      ];
+    });
+  };
+
+  /**
+   * textDocument/definition: where the identifier under the cursor is declared
+   */
+  private definition = async (params: LspTextDocumentPositionParams): Promise<LspLocation[]> => {
+    const target = await this.cursorTarget(params);
+    if (!target) return [];
+
+    if (target.filePath.endsWith('.go')) {
+      const reference = await findGoReferenceAt(
+        this.db,
+        target.root.repoId,
+        target.filePath,
+        target.line,
+        target.byteColumn
+      );
+      if (reference) {
+        return [await this.declarationLocation(target.root, reference.target_file, reference.target_line, target.name)];
+      }
+    }
+
+    // Not type-checked: declarations of that name, nearest first
+    const symbols = (await searchSymbols(this.db, target.name, { repoId: this.repoId, limit: DEFINITION_CANDIDATES }))
+      .filter((symbol) => symbol.symbol_name === target.name || symbol.symbol_name.endsWith(`.${target.name}`))
+      .sort((a, b) => this.nearness(b, target) - this.nearness(a, target));
+    const locations: LspLocation[] = [];
+    for (const symbol of symbols) {
+      const root = this.rootById(symbol.repo_id);
+      if (root) locations.push(await this.declarationLocation(root, symbol.file_path, symbol.line_number, target.name));
+    }
+    return locations;
+  };
+
+  /**
+   * textDocument/references: uses of the identifier under the cursor
+   */
+  private references = async (params: LspTextDocumentPositionParams): Promise<LspLocation[]> => {
+    const target = await this.cursorTarget(params);
+    if (!target) return [];
+
+    if (target.filePath.endsWith('.go')) {
+      // On a use, its declaration; on the declaration itself, uses recorded for that line
+      const reference = await findGoReferenceAt(
+        this.db,
+        target.root.repoId,
+        target.filePath,
+        target.line,
+        target.byteColumn
+      );
+      const declaration = reference
+        ? { file_path: reference.target_file, line: reference.target_line, name: reference.target_name }
+        : { file_path: target.filePath, line: target.line, name: target.name };
+      const uses = await listGoReferencesTo(this.db, declaration, target.root.repoId);
+      if (uses.length > 0) {
+        const lines = new Map<string, string[]>();
+        const locations: LspLocation[] = [];
+        for (const use of uses.slice(0, REFERENCE_LIMIT)) {
+          const root = this.rootById(use.repo_id) ?? target.root;
+          const absolute = path.join(root.repoPath, use.file_path);
+          const lineText = (await this.fileLines(absolute, lines)).at(use.line_number - 1) ?? '';
+          locations.push(this.rangeAt(absolute, use.line_number, lineText, use.column_number, target.name));
+        }
+        if (params.context?.includeDeclaration) {
+          locations.unshift(
+            await this.declarationLocation(target.root, declaration.file_path, declaration.line, target.name)
+          );
+        }
+        return locations;
+      }
+    }
+
+    // Not type-checked: whole-word matches across the indexed contents
+    const { matches } = await searchContent(this.db, `\\b${escapeRegex(target.name)}\\b`, {
+      repoId: this.repoId,
+      limit: REFERENCE_LIMIT,
+    });
+    return matches.flatMap((match) => {
+      const root = this.rootById(match.repo_id);
+      if (!root) return [];
+      const start = { line: match.line - 1, character: this.character(match.column, match.byte_column) - 1 };
+      const end = { line: start.line, character: start.character + this.width(target.name) };
+      return [{ uri: toFileUri(path.join(root.repoPath, match.file_path)), range: { start, end } }];
+    });
+  };
+
+  /**
+   * textDocument/documentSymbol: declarations of the document, nested
+   *
+   * Any file: the outline is parsed from the open text (or the file on disk),
+   * not read from the index. The root is returned only for a declared package.
+   */
+  private documentSymbol = async (uri: string): Promise<LspDocumentSymbol[]> => {
+    const absolute = fromFileUri(uri);
+    if (!absolute) return [];
+    const language = LANGUAGE_EXTENSIONS[path.extname(absolute).toLowerCase()] ?? Language.Unknown;
+    if (language === Language.Unknown) return [];
+
+    const text = this.documents.get(uri) ?? (await this.fileLines(absolute)).join('\n');
+    const lines = text.split(/\r?\n/);
+    const root = outlineFile(text.replace(/\r\n/g, '\n'), toPosixPath(absolute), language);
+    const toSymbol = (node: OutlineNode): LspDocumentSymbol => {
+      const startText = lines.at(node.start_line - 1) ?? '';
+      const endText = lines.at(node.end_line - 1) ?? '';
+      const start = { line: node.start_line - 1, character: 0 };
+      const index = findName(startText, node.name);
+      const nameStart = { line: start.line, character: index === -1 ? 0 : this.width(startText.slice(0, index)) };
+      const nameEnd = { line: start.line, character: nameStart.character + (index === -1 ? 0 : this.width(node.name)) };
+      return {
+        name: node.name,
+        detail: node.detail,
+        kind: toSymbolKind(node.kind),
+        range: { start, end: { line: node.end_line - 1, character: this.width(endText) } },
+        selectionRange: { start: nameStart, end: nameEnd },
+        children: node.children.map(toSymbol),
+      };
+    };
+    return root.kind === 'package' ? [toSymbol(root)] : root.children.map(toSymbol);
+  };
+
+  /**
+   * Identifier under the cursor, in the repository holding the document
+   *
+   * @returns Target, or null outside the served repositories or off an identifier
+   */
+  private cursorTarget = async (params: LspTextDocumentPositionParams): Promise<CursorTarget | null> => {
+    const absolute = fromFileUri(params.textDocument.uri);
+    const root = absolute ? this.rootOf(absolute) : undefined;
+    if (!absolute || !root) return null;
+
+    const text = this.documents.get(params.textDocument.uri) ?? (await this.fileLines(absolute)).join('\n');
+    const lineText = text.split(/\r?\n/).at(params.position.line) ?? '';
+    const column = params.position.character + 1;
+    const byteColumn = this.encoding === 'utf-8' ? column : utf16ToByteColumn(lineText, column);
+    const name = identifierAt(lineText, byteColumn);
+    if (!name) return null;
+    const filePath = toPosixPath(path.relative(root.repoPath, absolute));
+    return { root, filePath, line: params.position.line + 1, byteColumn, name };
+  };
+
+  /**
+   * Location of a declaration, at its name when the line holds it
+   */
+  private declarationLocation = async (
+    root: ServedRoot,
+    filePath: string,
+    line: number,
+    name: string
+  ): Promise<LspLocation> => {
+    const absolute = path.join(root.repoPath, filePath);
+    const lineText = (await this.fileLines(absolute)).at(line - 1) ?? '';
+    const index = findName(lineText, name);
+    const byteColumn = index === -1 ? 1 : utf16ToByteColumn(lineText, index + 1);
+    return this.rangeAt(absolute, line, lineText, byteColumn, index === -1 ? '' : name);
+  };
+
+  /**
+   * Range of a name starting at a byte column
+   */
+  private rangeAt = (
+    absolute: string,
+    line: number,
+    lineText: string,
+    byteColumn: number,
+    name: string
+  ): LspLocation => {
+    const character = this.character(byteToUtf16Column(lineText, byteColumn), byteColumn) - 1;
+    const start = { line: line - 1, character };
+    const end = { line: start.line, character: start.character + this.width(name) };
+    return { uri: toFileUri(absolute), range: { start, end } };
+  };
+
+  /**
+   * 1-based column in the negotiated encoding
+   */
+  private character = (utf16Column: number, byteColumn: number): number =>
+    this.encoding === 'utf-8' ? byteColumn : utf16Column;
+
+  /**
+   * Length of a name in the negotiated encoding
+   */
+  private width = (name: string): number => (this.encoding === 'utf-8' ? Buffer.byteLength(name, 'utf8') : name.length);
+
+  /**
+   * Lines of a file as on disk (an open document's unsaved text is not used for results)
+   *
+   * @param cache - Lines already read during the request
+   * @returns Lines, or none if the file cannot be read
+   */
+  private fileLines = async (absolute: string, cache?: Map<string, string[]>): Promise<string[]> => {
+    const cached = cache?.get(absolute);
+    if (cached) return cached;
+    let lines: string[] = [];
+    try {
+      lines = (await readSourceFile(absolute)).content.split(/\r?\n/);
+    } catch {
+      // Deleted or moved since indexing: positions fall back to the line start
+    }
+    cache?.set(absolute, lines);
+    return lines;
+  };
+
+  /**
+   * Served repository holding a path (the innermost, for nested repositories)
+   */
+  private rootOf = (absolute: string): ServedRoot | undefined =>
+    this.roots
+      .filter((root) => absolute === root.repoPath || absolute.startsWith(root.repoPath + path.sep))
+      .sort((a, b) => b.repoPath.length - a.repoPath.length)
+      .at(0);
+
+  private rootById = (repoId: string | null | undefined): ServedRoot | undefined =>
+    this.roots.find((root) => root.repoId === repoId);
+
+  /**
+   * Closeness of a declaration to the cursor: same file, then same repository
+   */
+  private nearness = (symbol: { repo_id?: string; file_path: string }, target: CursorTarget): number => {
+    if (symbol.repo_id !== target.root.repoId) return 0;
+    return symbol.file_path === target.filePath ? 2 : 1;
+  };
+}
+
+/**
+ * Check whether a message body is a JSON-RPC request or notification
+ */
+const isRequest = (message: unknown): message is JsonRpcRequest =>
+  typeof message === 'object' && message !== null && typeof (message as { method?: unknown }).method === 'string';
+
+/**
+ * Validate the params of a position request
+ *
+ * @throws {RequestError} If the document or position is missing
+ */
+const positionParams = (params: unknown): LspTextDocumentPositionParams => {
+  const candidate = params as Partial<LspTextDocumentPositionParams> | undefined;
+  const { line, character } = candidate?.position ?? {};
+  if (typeof candidate?.textDocument?.uri !== 'string' || typeof line !== 'number' || typeof character !== 'number') {
+    throw new RequestError(LspErrorCode.InvalidParams, 'Expected textDocument.uri and position');
+  }
+  return candidate as LspTextDocumentPositionParams;
+};
+
+/**
+ * Index of a name as a whole word in a line
+ *
+ * @returns UTF-16 index, or -1 if the line does not hold the name
+ */
+const findName = (lineText: string, name: string): number =>
+  lineText.search(new RegExp(`(?<![\\p{L}\\p{N}_])${escapeRegex(name)}(?![\\p{L}\\p{N}_])`, 'u'));
+
+/**
+ * Validate the params of a document request
+ *
+ * @returns URI of the document
+ * @throws {RequestError} If the document is missing
+ */
+const documentUri = (params: unknown): string => {
+  const uri = (params as { textDocument?: { uri?: unknown } } | undefined)?.textDocument?.uri;
+  if (typeof uri !== 'string') throw new RequestError(LspErrorCode.InvalidParams, 'Expected textDocument.uri');
+  return uri;
+};
+
+/**
+ * Serve the protocol over a pair of streams until exit or end of input
+ *
+ * Requests are answered in the order received.
+ *
+ * @param db - Database connection pool
+ * @param streams.input - Client messages (stdin)
+ * @param streams.output - Server messages (stdout)
+ * @param repoId - Serve one index (default: every indexed repository)
+ * @returns Whether shutdown was requested before the session ended
+ */
+export const serveLsp = (
+  db: Pool,
+  streams: { input: Readable; output: Writable },
+  repoId?: string
+): Promise<boolean> => {
+  const server = new LspServer(db, repoId);
+  const reader = new MessageReader();
+  let queue = Promise.resolve();
+
+  return new Promise<boolean>((resolve, reject) => {
+    const finish = (): void => {
+      streams.input.off('data', onData);
+      resolve(server.cleanShutdown);
+    };
+    const onData = (chunk: Buffer): void => {
+      let messages: unknown[];
+      try {
+        messages = reader.push(chunk);
+      } catch (error) {
+        reject(error instanceof Error ? error : new Error(String(error)));
+        return;
+      }
+      for (const message of messages) {
+        queue = queue.then(async () => {
+          const response = await server.handle(message);
+          if (response) streams.output.write(encodeMessage(response));
+          if (isRequest(message) && message.method === 'exit') finish();
+        });
+      }
+    };
+    streams.input.on('data', onData);
+    streams.input.once('end', () => {
+      void queue.then(finish);
+    });
+  });
+};
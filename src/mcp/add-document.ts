@@ -0,0 +1,74 @@
+/**
+ * MCP Tool: add_document
+ * Index content that is not on disk (editor buffers, generated code, fetched snippets)
+ * into an ephemeral index for immediate querying
+ */
+import { type DatabaseClient } from '@database/client';
+import { addDocument, type AddDocumentResult } from '@indexing/ephemeral';
+import { type Embedder } from '@utils/embedders';
+import { logger } from '@utils/logger';
+import { type CindexConfig } from '@/types/config';
+
+/**
+ * Input schema for add_document tool
+ */
+export interface AddDocumentInput {
+  name: string; // Ephemeral index (repo_id) to add the document to
+  content: string; // Document content
+  language?: string; // Language of the content (default: from the path's extension)
+  path?: string; // Path of the document in the index (default: the name)
+}
+
+/**
+ * Add document MCP tool implementation
+ *
+ * @param config - cindex configuration
+ * @param db - Database client
+ * @param embedder - Embedder for chunk embeddings
+ * @param input - Name, content, language, and path
+ * @returns Stored path and indexing statistics
+ */
+export const addDocumentTool = async (
+  config: CindexConfig,
+  db: DatabaseClient,
+  embedder: Embedder,
+  input: AddDocumentInput
+): Promise<AddDocumentResult> => {
+  logger.info('Adding ephemeral document', { name: input.name, path: input.path, language: input.language });
+  return addDocument(config, db, embedder, input);
+};
+
+/**
+ * Format add_document output as Markdown
+ *
+ * @param result - Stored path and indexing statistics
+ * @returns Formatted Markdown string
+ */
+export const formatAddDocumentOutput = (result: AddDocumentResult): string => {
+  const { stats } = result;
+  const lines: string[] = [];
+
+  lines.push(`## Document Indexed: ${result.file_path}`);
+  lines.push('');
+  lines.push(`Ephemeral index \`${result.repo_id}\` (expires a day after the last document was added).`);
+  lines.push('Query it with search_codebase (repo_filter) or find_symbol_definition (repo_scope).');
+  lines.push('');
+  lines.push('### Statistics');
+  lines.push(`- Chunks: ${String(stats.chunks_total)}`);
+  lines.push(`- Symbols: ${String(stats.symbols_extracted)}`);
+  lines.push(`- Duration: ${String(stats.total_time_ms)}ms`);
+
+  if (result.pruned.length > 0) {
+    lines.push('');
+    lines.push(`Removed expired ephemeral indexes: ${result.pruned.join(', ')}`);
+  }
+  if (stats.errors.length > 0) {
+    lines.push('');
+    lines.push('### Errors');
+    for (const error of stats.errors) {
+      lines.push(`- ${error.file_path ?? '-'} (${error.stage}): ${error.error}`);
+    }
+  }
+
+  return lines.join('\n');
+};
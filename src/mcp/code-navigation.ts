@@ -0,0 +1,396 @@
+/**
+ * MCP Tools: search_symbols, get_definition, get_references, get_file_outline
+ * Navigate the index the way an editor does: by symbol, definition, use, and file
+ *
+ * Go repositories indexed with --typed are answered from type-checked
+ * references; everything else falls back to symbol names and whole-word
+ * matches of the indexed contents, and results say which was used.
+ */
+import { type Pool } from 'pg';
+
+import {
+  findSymbolsByName,
+  getIndexedFileContent,
+  listFileSymbols,
+  listGoReferences,
+  listGoReferencesOnLine,
+  resolveIndexedFile,
+  searchSymbols,
+} from '@database/queries';
+import { formatFilePath, formatResolvedSymbol } from '@mcp/formatter';
+import {
+  validateFilePath,
+  validateInteger,
+  validateMaxResults,
+  validateNonEmptyString,
+  validateQuery,
+  validateRepoId,
+  validateSymbolName,
+} from '@mcp/validator';
+import { escapeRegex, searchContent } from '@retrieval/content-search';
+import { searchSymbolsFuzzy, type RankedSymbol } from '@retrieval/fuzzy-symbols';
+import { countOutline, outlineFile, type OutlineNode } from '@retrieval/outline';
+import { logger } from '@utils/logger';
+import { type Language } from '@/types/indexing';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/**
+ * How a definition or reference was found
+ *
+ * typed: Go type checker (cindex index --typed); name: same-named declarations; text: whole-word content matches
+ */
+export type NavigationSource = 'typed' | 'name' | 'text';
+
+/**
+ * Input schema for search_symbols tool
+ */
+export interface SearchSymbolsInput {
+  query: string; // Characters of the name, in order (NAS finds NewAuthService)
+  repo_id?: string; // Limit to one repository
+  near?: string; // Path whose neighborhood ranks first
+  max_results?: number; // Default: 20, Range: 1-100
+}
+
+/**
+ * Output schema for search_symbols tool
+ */
+export interface SearchSymbolsOutput {
+  formatted_result: string; // Markdown-formatted ranked symbols
+  symbols: RankedSymbol[]; // Best first
+}
+
+/**
+ * Input schema for get_definition tool
+ */
+export interface GetDefinitionInput {
+  symbol_name: string; // Identifier as written at the use (Login, store.Get)
+  file_path?: string; // File of the use; with line, resolves Go identifiers exactly
+  line?: number; // 1-based line of the use
+  repo_id?: string; // Limit to one repository
+}
+
+/**
+ * Declaration a definition lookup found
+ */
+export interface DefinitionLocation {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  symbol_name: string;
+  /** Indexed declaration at the location (absent for locals and struct fields) */
+  symbol?: ResolvedSymbol;
+}
+
+/**
+ * Output schema for get_definition tool
+ */
+export interface GetDefinitionOutput {
+  formatted_result: string; // Markdown-formatted declarations
+  definitions: DefinitionLocation[];
+  source: NavigationSource;
+}
+
+/**
+ * Input schema for get_references tool
+ */
+export interface GetReferencesInput {
+  symbol_name: string; // Declaration name (Login, AuthService.Login, auth.Login)
+  repo_id?: string; // Limit to one repository
+  max_results?: number; // Default: 100, Range: 1-100
+}
+
+/**
+ * Use of a symbol
+ */
+export interface ReferenceLocation {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  column_number: number;
+  /** Enclosing symbol (typed references) */
+  symbol_name?: string | null;
+  /** read, write, or call (typed references, when recorded) */
+  access?: string | null;
+  /** Text of the line (text matches) */
+  text?: string;
+}
+
+/**
+ * Output schema for get_references tool
+ */
+export interface GetReferencesOutput {
+  formatted_result: string; // Markdown-formatted uses grouped by file
+  references: ReferenceLocation[];
+  source: NavigationSource;
+  truncated: boolean; // More uses exist than max_results
+}
+
+/**
+ * Input schema for get_file_outline tool
+ */
+export interface GetFileOutlineInput {
+  file_path: string; // Stored, absolute, or trailing path (auth/login.go)
+  repo_id?: string; // Limit to one repository
+}
+
+/**
+ * Output schema for get_file_outline tool
+ */
+export interface GetFileOutlineOutput {
+  formatted_result: string; // Markdown-formatted outline
+  file_path: string | null; // Path as stored, or null if the file is not indexed
+  repo_id: string | null;
+  outline: OutlineNode | null; // Package or module with declarations nested beneath; null without stored content
+  symbols: ResolvedSymbol[]; // In declaration order
+}
+
+/** Last element of a dotted name (Get of store.Memory.Get) */
+const lastName = (name: string): string => name.slice(name.lastIndexOf('.') + 1);
+
+/**
+ * Render outline entries as a nested Markdown list
+ */
+const formatOutline = (nodes: OutlineNode[], depth = 0): string[] =>
+  nodes.flatMap((node) => {
+    const callable = node.kind === 'function' || node.kind === 'method';
+    const label = callable && node.detail ? node.detail : [node.name, node.detail].filter(Boolean).join(' ');
+    const line = `${'  '.repeat(depth)}- ${String(node.start_line)}: \`${label}\` (${node.kind})`;
+    return [line, ...formatOutline(node.children, depth + 1)];
+  });
+
+/**
+ * search_symbols MCP tool implementation
+ *
+ * @param db - Database connection pool
+ * @param input - Search symbols parameters
+ * @returns Symbols ranked by fuzzy match, scope, kind, and nearness
+ */
+export const searchSymbolsTool = async (db: Pool, input: SearchSymbolsInput): Promise<SearchSymbolsOutput> => {
+  logger.info('search_symbols tool invoked', { query: input.query });
+
+  const query = validateQuery(input.query, true) as string;
+  const repoId = validateRepoId(input.repo_id, false);
+  const near = validateNonEmptyString('near', input.near, false);
+  const limit = validateMaxResults(input.max_results, false) ?? 20;
+
+  const symbols = await searchSymbolsFuzzy(db, query, { repoId, near, limit });
+  if (symbols.length === 0) {
+    return { formatted_result: `# Symbols: \`${query}\`\n\nNo symbol names match.`, symbols };
+  }
+
+  const lines = [`# Symbols: \`${query}\`\n`];
+  for (const symbol of symbols) {
+    const location = `${formatFilePath(symbol.file_path)}:${String(symbol.line_number)}`;
+    lines.push(`- \`${symbol.symbol_name}\` (${symbol.symbol_type}, ${symbol.scope}) ${location}`);
+  }
+
+  logger.info('search_symbols completed', { total: symbols.length });
+  return { formatted_result: lines.join('\n'), symbols };
+};
+
+/**
+ * get_definition MCP tool implementation
+ *
+ * With file_path and line of a Go use indexed with --typed, the identifier is
+ * resolved by the type checker; otherwise declarations with the name are
+ * returned, in the use's file first.
+ *
+ * @param db - Database connection pool
+ * @param input - Get definition parameters
+ * @returns Declarations and how they were found
+ */
+export const getDefinitionTool = async (db: Pool, input: GetDefinitionInput): Promise<GetDefinitionOutput> => {
+  logger.info('get_definition tool invoked', { symbol_name: input.symbol_name });
+
+  const symbolName = validateSymbolName(input.symbol_name, true) as string;
+  const filePath = validateFilePath(input.file_path, false);
+  const line = validateInteger('line', input.line, false);
+  const repoId = validateRepoId(input.repo_id, false);
+  const name = lastName(symbolName);
+
+  const file = filePath ? await resolveIndexedFile(db, filePath, repoId) : null;
+  let definitions: DefinitionLocation[] = [];
+  let source: NavigationSource = 'name';
+
+  if (file?.file_path.endsWith('.go') && line !== undefined) {
+    const uses = await listGoReferencesOnLine(db, file.file_path, line, file.repo_id ?? undefined);
+    const targets = uses.filter((use) => lastName(use.target_name) === name);
+    for (const target of targets) {
+      if (definitions.some((d) => d.file_path === target.target_file && d.line_number === target.target_line)) {
+        continue;
+      }
+      const declared = await findSymbolsByName(db, target.target_name, target.repo_id ?? undefined);
+      definitions.push({
+        repo_id: target.repo_id,
+        file_path: target.target_file,
+        line_number: target.target_line,
+        symbol_name: target.target_name,
+        symbol: declared.find((s) => s.file_path === target.target_file && s.line_number === target.target_line),
+      });
+    }
+    if (definitions.length > 0) source = 'typed';
+  }
+
+  if (definitions.length === 0) {
+    const candidates = await searchSymbols(db, name, { repoId, limit: 200 });
+    definitions = candidates
+      .filter((s) => s.symbol_name === symbolName || s.symbol_name === name || s.symbol_name.endsWith(`.${name}`))
+      .sort((a, b) => Number(b.file_path === file?.file_path) - Number(a.file_path === file?.file_path))
+      .map((symbol) => ({
+        repo_id: symbol.repo_id ?? null,
+        file_path: symbol.file_path,
+        line_number: symbol.line_number,
+        symbol_name: symbol.symbol_name,
+        symbol,
+      }));
+  }
+
+  if (definitions.length === 0) {
+    return {
+      formatted_result: `# Definition Not Found: \`${symbolName}\`\n\nNo declaration with this name is indexed.`,
+      definitions,
+      source,
+    };
+  }
+
+  const lines = [`# Definition: \`${symbolName}\`\n`];
+  if (source === 'name') lines.push('_Matched by name; not type-checked._\n');
+  for (const definition of definitions) {
+    if (definition.symbol) {
+      lines.push(formatResolvedSymbol(definition.symbol));
+    } else {
+      const location = `${formatFilePath(definition.file_path)}:${String(definition.line_number)}`;
+      lines.push(`#### \`${definition.symbol_name}\`\n**Location:** ${location}`);
+    }
+    lines.push('');
+  }
+
+  logger.info('get_definition completed', { total: definitions.length, source });
+  return { formatted_result: lines.join('\n'), definitions, source };
+};
+
+/**
+ * get_references MCP tool implementation
+ *
+ * Type-checked Go uses when the repository was indexed with --typed (the
+ * name takes the forms of cindex refs); whole-word matches of the indexed
+ * contents otherwise.
+ *
+ * @param db - Database connection pool
+ * @param input - Get references parameters
+ * @returns Uses ordered by file and position, and how they were found
+ */
+export const getReferencesTool = async (db: Pool, input: GetReferencesInput): Promise<GetReferencesOutput> => {
+  logger.info('get_references tool invoked', { symbol_name: input.symbol_name });
+
+  const symbolName = validateSymbolName(input.symbol_name, true) as string;
+  const repoId = validateRepoId(input.repo_id, false);
+  const limit = validateMaxResults(input.max_results, false) ?? 100;
+
+  let references: ReferenceLocation[];
+  let source: NavigationSource;
+  let truncated: boolean;
+
+  const typed = await listGoReferences(db, symbolName, repoId);
+  if (typed.length > 0) {
+    source = 'typed';
+    truncated = typed.length > limit;
+    references = typed.slice(0, limit).map((use) => ({
+      repo_id: use.repo_id,
+      file_path: use.file_path,
+      line_number: use.line_number,
+      column_number: use.column_number,
+      symbol_name: use.symbol_name,
+      access: use.access,
+    }));
+  } else {
+    source = 'text';
+    const result = await searchContent(db, `\\b${escapeRegex(lastName(symbolName))}\\b`, { repoId, limit });
+    truncated = result.truncated;
+    references = result.matches.map((match) => ({
+      repo_id: match.repo_id,
+      file_path: match.file_path,
+      line_number: match.line,
+      column_number: match.column,
+      text: match.text,
+    }));
+  }
+
+  if (references.length === 0) {
+    return {
+      formatted_result: `# References: \`${symbolName}\`\n\nNo uses found in the indexed code.`,
+      references,
+      source,
+      truncated,
+    };
+  }
+
+  const lines = [`# References: \`${symbolName}\`\n`];
+  lines.push(`**Total:** ${String(references.length)}${truncated ? ' (more not shown)' : ''}`);
+  if (source === 'text') lines.push('_Whole-word text matches; not type-checked._');
+  let currentFile: string | null = null;
+  for (const reference of references) {
+    if (reference.file_path !== currentFile) {
+      currentFile = reference.file_path;
+      lines.push(`\n## ${formatFilePath(reference.file_path)}\n`);
+    }
+    const position = `${String(reference.line_number)}:${String(reference.column_number)}`;
+    const detail = reference.text?.trim() ?? [reference.access, reference.symbol_name].filter(Boolean).join(' in ');
+    lines.push(`- ${position}${detail ? ` ${detail}` : ''}`);
+  }
+
+  logger.info('get_references completed', { total: references.length, source });
+  return { formatted_result: lines.join('\n'), references, source, truncated };
+};
+
+/**
+ * get_file_outline MCP tool implementation
+ *
+ * The outline is parsed from the indexed content, so it nests methods under
+ * their types (Go methods under their receiver) and includes constants the
+ * symbol table does not store. Files indexed without content are outlined
+ * from their symbols, flat.
+ *
+ * @param db - Database connection pool
+ * @param input - Get file outline parameters
+ * @returns Declarations of the file as a tree, and its indexed symbols in order
+ */
+export const getFileOutlineTool = async (db: Pool, input: GetFileOutlineInput): Promise<GetFileOutlineOutput> => {
+  logger.info('get_file_outline tool invoked', { file_path: input.file_path });
+
+  const filePath = validateFilePath(input.file_path, true) as string;
+  const repoId = validateRepoId(input.repo_id, false);
+
+  const file = await resolveIndexedFile(db, filePath, repoId);
+  if (!file) {
+    return {
+      formatted_result: `# File Not Found: ${formatFilePath(filePath)}\n\nThe file is not indexed.`,
+      file_path: null,
+      repo_id: null,
+      outline: null,
+      symbols: [],
+    };
+  }
+
+  const repo = file.repo_id ?? undefined;
+  const symbols = await listFileSymbols(db, file.file_path, repo);
+  const stored = await getIndexedFileContent(db, file.file_path, repo);
+  const outline = stored ? outlineFile(stored.content, file.file_path, stored.language as Language) : null;
+
+  const lines = [`# Outline: ${formatFilePath(file.file_path)}\n`];
+  if (outline) {
+    lines.push(`**${outline.kind === 'package' ? 'Package' : 'Module'}:** \`${outline.name}\`\n`);
+    if (outline.children.length === 0) lines.push('No symbols declared.');
+    lines.push(...formatOutline(outline.children));
+  } else {
+    if (symbols.length === 0) lines.push('No symbols declared.');
+    for (const symbol of symbols) {
+      const signature = symbol.definition.split('\n')[0].replace(/\s*\{$/, '').trim();
+      lines.push(`- ${String(symbol.line_number)}: \`${signature || symbol.symbol_name}\` (${symbol.symbol_type})`);
+    }
+  }
+
+  const total = outline ? countOutline(outline) : symbols.length;
+  logger.info('get_file_outline completed', { file_path: file.file_path, total });
+  return { formatted_result: lines.join('\n'), file_path: file.file_path, repo_id: file.repo_id, outline, symbols };
+};
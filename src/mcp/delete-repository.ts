@@ -4,8 +4,10 @@
  */
 import { type Pool } from 'pg';
 
+import { acquireIndexLock } from '@indexing/index-lock';
 import { deleteRepository, type DeletionStats } from '@indexing/version-tracker';
 import { logger } from '@utils/logger';
+import { type StoreConfig } from '@/types/config';
 
 /**
  * Input schema for delete_repository tool
@@ -50,9 +52,14 @@ const validateRepositoryIds = async (db: Pool, repoIds: string[]): Promise<strin
  *
  * @param db - Database connection pool
  * @param input - Delete repository input (repo_ids array)
+ * @param store - Store to remove the repositories from too (STORE_BACKEND)
  * @returns Deletion statistics for all repositories
  */
-export const deleteRepositoryTool = async (db: Pool, input: DeleteRepositoryInput): Promise<DeleteRepositoryOutput> => {
+export const deleteRepositoryTool = async (
+  db: Pool,
+  input: DeleteRepositoryInput,
+  store?: StoreConfig
+): Promise<DeleteRepositoryOutput> => {
   const { repo_ids: repoIds } = input;
 
   // Input validation
@@ -75,7 +82,9 @@ export const deleteRepositoryTool = async (db: Pool, input: DeleteRepositoryInpu
 
   for (const repoId of repoIds) {
     try {
-      const stats = await deleteRepository(db, repoId);
+      // Exclusive: CLI readers of this index finish before its rows go away
+      const lock = await acquireIndexLock(repoId, false, true);
+      const stats = await deleteRepository(db, repoId, store).finally(lock.release);
       deletionStats.push(stats);
     } catch (error) {
       logger.error('Failed to delete repository', {
@@ -9,10 +9,13 @@ import { formatCodeBlock, formatFilePath, formatResolvedSymbol } from '@mcp/form
 import {
   validateArray,
   validateBoolean,
+  validateInteger,
   validateMaxResults,
+  validateNumberInRange,
   validateScopeFilter,
   validateSymbolName,
 } from '@mcp/validator';
+import { attachSnippets, DEFAULT_SNIPPET_CONTEXT, MAX_SNIPPET_CONTEXT } from '@retrieval/snippets';
 import { logger } from '@utils/logger';
 import { type ResolvedSymbol } from '@/types/retrieval';
 
@@ -33,6 +36,10 @@ export interface FindSymbolInput {
   include_cross_workspace?: boolean; // Default: false - Include cross-workspace usages
   include_cross_service?: boolean; // Default: false - Include cross-service usages
   max_usages?: number; // Default: 50, Range: 1-100 - Limit number of usages returned
+
+  // Source options
+  include_snippet?: boolean; // Default: false - Include each definition's source span with context lines
+  snippet_context?: number; // Default: 2, Range: 0-50 - Context lines around the span
 }
 
 /**
@@ -247,6 +254,11 @@ export const findSymbolTool = async (db: Pool, input: FindSymbolInput): Promise<
     validateBoolean('include_cross_workspace', input.include_cross_workspace, false) ?? false;
   const includeCrossService = validateBoolean('include_cross_service', input.include_cross_service, false) ?? false;
   const maxUsages = validateMaxResults(input.max_usages, false) ?? 50;
+  const includeSnippet = validateBoolean('include_snippet', input.include_snippet, false) ?? false;
+  validateInteger('snippet_context', input.snippet_context, false);
+  const snippetContext =
+    validateNumberInRange('snippet_context', input.snippet_context, 0, MAX_SNIPPET_CONTEXT, false) ??
+    DEFAULT_SNIPPET_CONTEXT;
 
   logger.debug('Searching for symbol', {
     symbolName,
@@ -282,7 +294,8 @@ export const findSymbolTool = async (db: Pool, input: FindSymbolInput): Promise<
     searchOptions.repoId = repoScope[0];
   }
 
-  const symbols = await searchSymbols(db, symbolName, searchOptions);
+  const found = await searchSymbols(db, symbolName, searchOptions);
+  const symbols = includeSnippet ? await attachSnippets(db, found, { context: snippetContext }) : found;
 
   if (symbols.length === 0) {
     logger.info('No symbols found', { symbol_name: symbolName });
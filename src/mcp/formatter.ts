@@ -3,7 +3,9 @@
  * Provides Markdown formatters for all MCP tool outputs
  */
 import { type ServiceContext, type WorkspaceContext } from '@database/queries';
+import { summarizeUnreadablePaths, UNREADABLE_EXAMPLES } from '@indexing/file-walker';
 import { type RepositoryType } from '@/types/database';
+import { type UnreadablePath } from '@/types/indexing';
 import {
   type APIEndpointMatch,
   type CrossServiceCall,
@@ -333,7 +335,25 @@ export const formatResolvedSymbol = (symbol: ResolvedSymbol): string => {
 
   // Detect language from file extension
   const language = symbol.file_path.split('.').pop() ?? 'text';
-  lines.push(`\n${formatCodeBlock(symbol.definition, language)}`);
+  if (!symbol.snippet) {
+    lines.push(`\n${formatCodeBlock(symbol.definition, language)}`);
+    return lines.join('\n');
+  }
+
+  // Source span with context lines; a truncated span marks the lines it skips
+  const { start_line, end_line, start_byte, end_byte, omitted_lines } = symbol.snippet;
+  const omitted = omitted_lines > 0 ? `, ${String(omitted_lines)} lines omitted` : '';
+  const bytes = `bytes ${String(start_byte)}-${String(end_byte)}`;
+  lines.push(`**Span:** lines ${String(start_line)}-${String(end_line)}, ${bytes}${omitted}`);
+  const source: string[] = [];
+  let previous: number | null = null;
+  for (const line of symbol.snippet.lines) {
+    if (previous !== null && line.line > previous + 1) source.push('...');
+    source.push(line.text);
+    previous = line.line;
+  }
+  const firstLine = symbol.snippet.lines[0]?.line ?? start_line;
+  lines.push(`**Source** (from line ${String(firstLine)}):\n\n${formatCodeBlock(source.join('\n'), language)}`);
 
   return lines.join('\n');
 };
@@ -679,6 +699,9 @@ export interface IndexingStats {
   api_endpoints_found?: number;
   indexing_time_ms: number;
   errors?: string[];
+  unreadable_paths?: UnreadablePath[];
+  secret_findings?: number;
+  secret_files?: number;
 }
 
 /**
@@ -725,6 +748,26 @@ export const formatIndexingStats = (stats: IndexingStats): string => {
     }
   }
 
+  if (stats.unreadable_paths && stats.unreadable_paths.length > 0) {
+    lines.push('\n## Unreadable Paths\n');
+    lines.push(`Skipped ${summarizeUnreadablePaths(stats.unreadable_paths)}:`);
+    for (const entry of stats.unreadable_paths.slice(0, UNREADABLE_EXAMPLES)) {
+      lines.push(`- \`${entry.relative_path}\` (${entry.code})`);
+    }
+    if (stats.unreadable_paths.length > UNREADABLE_EXAMPLES) {
+      lines.push(`- ... and ${String(stats.unreadable_paths.length - UNREADABLE_EXAMPLES)} more`);
+    }
+  }
+
+  // Counts only: findings are reviewed locally with `cindex secrets`, never sent to the client
+  if (stats.secret_findings) {
+    lines.push('\n## Likely Secrets\n');
+    lines.push(
+      `${String(stats.secret_findings)} likely secrets in ${String(stats.secret_files ?? 0)} files; ` +
+        'run `cindex secrets` to review them'
+    );
+  }
+
   return lines.join('\n');
 };
 
@@ -16,8 +16,8 @@ import {
   getFileHash,
   parseMarkdownForDocumentation,
 } from '@indexing/doc-chunker';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import {
   type IndexDocumentationError,
   type IndexDocumentationInput,
@@ -31,7 +31,7 @@ const DEFAULT_CONTEXT_WINDOW = 4096;
 /**
  * Generate embedding for documentation chunk
  *
- * @param ollamaClient - Ollama client
+ * @param embedder - Embedder of the configured provider
  * @param model - Embedding model name
  * @param dimensions - Embedding dimensions
  * @param contextWindow - Context window size (optional, defaults to 4096)
@@ -40,7 +40,7 @@ const DEFAULT_CONTEXT_WINDOW = 4096;
  * @returns Embedding vector
  */
 const generateDocEmbedding = async (
-  ollamaClient: OllamaClient,
+  embedder: Embedder,
   model: string,
   dimensions: number,
   contextWindow: number | undefined,
@@ -57,7 +57,7 @@ const generateDocEmbedding = async (
     enhancedText = `Code example (${chunk.language ?? 'text'}) in ${headingContext}:\n\n${chunk.content}`;
   }
 
-  return ollamaClient.generateEmbedding(model, enhancedText, dimensions, contextWindow ?? DEFAULT_CONTEXT_WINDOW);
+  return embedder.generateEmbedding(model, enhancedText, dimensions, contextWindow ?? DEFAULT_CONTEXT_WINDOW);
 };
 
 /**
@@ -226,14 +226,14 @@ const resolvePaths = async (paths: string[]): Promise<string[]> => {
  * Index documentation MCP tool implementation
  *
  * @param pool - Database connection pool
- * @param ollamaClient - Ollama client for embeddings
+ * @param embedder - Embedder for chunk embeddings
  * @param embeddingConfig - Embedding configuration
  * @param input - Tool input parameters
  * @returns Indexing results
  */
 export const indexDocumentationTool = async (
   pool: pg.Pool,
-  ollamaClient: OllamaClient,
+  embedder: Embedder,
   embeddingConfig: { model: string; dimensions: number; context_window?: number },
   input: IndexDocumentationInput
 ): Promise<IndexDocumentationOutput> => {
@@ -328,7 +328,7 @@ export const indexDocumentationTool = async (
         let embedding: number[] | null = null;
         try {
           embedding = await generateDocEmbedding(
-            ollamaClient,
+            embedder,
             embeddingConfig.model,
             embeddingConfig.dimensions,
             embeddingConfig.context_window,
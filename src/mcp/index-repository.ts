@@ -9,6 +9,7 @@ import { formatIndexingStats, type IndexingStats } from '@mcp/formatter';
 import {
   validateArray,
   validateBoolean,
+  validateEnum,
   validateLanguages,
   validateMaxFileSize,
   validateObject,
@@ -21,7 +22,13 @@ import {
 import { clearAllCaches } from '@utils/cache';
 import { logger } from '@utils/logger';
 import { type RepositoryType } from '@/types/database';
-import { type IndexingOptions } from '@/types/indexing';
+import {
+  GENERATED_FILE_POLICIES,
+  SYMLINK_POLICIES,
+  type GeneratedFilePolicy,
+  type IndexingOptions,
+  type SymlinkPolicy,
+} from '@/types/indexing';
 
 /**
  * Input schema for index_repository tool
@@ -32,9 +39,12 @@ export interface IndexRepositoryInput {
   incremental?: boolean; // Default: true - Skip unchanged files
   languages?: string[]; // Filter by languages (empty = all)
   respect_gitignore?: boolean; // Default: true - Respect .gitignore
+  symlink_policy?: SymlinkPolicy; // Default: SYMLINK_POLICY env - skip, follow-within-root, or follow-all
+  generated_files?: GeneratedFilePolicy; // Default: GENERATED_FILES env - exclude, or tag generated: true
   max_file_size?: number; // Default: 5000 lines - Max file size in lines
   protect_secrets?: boolean; // Default: true - Detect and exclude secret files (.env, credentials, keys)
   secret_patterns?: string[]; // Custom patterns for secret detection (glob-style)
+  scan_secrets?: boolean; // Default: SCAN_SECRETS env (false) - Record likely credentials found in content
   summary_method?: 'llm' | 'rule-based'; // Default: llm - Summary generation method
 
   // Repository configuration
@@ -141,12 +151,14 @@ type ProgressCallback = (progress: {
  * @param orchestrator - Indexing orchestrator
  * @param input - Index repository parameters
  * @param onProgress - Progress callback for MCP notifications (optional)
+ * @param signal - Aborted on server shutdown; indexing stops after the current file (optional)
  * @returns Formatted indexing statistics
  */
 export const indexRepositoryTool = async (
   orchestrator: IndexingOrchestrator,
   input: IndexRepositoryInput,
-  onProgress?: ProgressCallback
+  onProgress?: ProgressCallback,
+  signal?: AbortSignal
 ): Promise<IndexRepositoryOutput> => {
   logger.info('index_repository tool invoked', { repo_path: input.repo_path });
 
@@ -158,9 +170,12 @@ export const indexRepositoryTool = async (
   const incremental = validateBoolean('incremental', input.incremental, false) ?? true;
   const languages = validateLanguages(input.languages, false);
   const respectGitignore = validateBoolean('respect_gitignore', input.respect_gitignore, false) ?? true;
+  const symlinkPolicy = validateEnum('symlink_policy', input.symlink_policy, SYMLINK_POLICIES, false);
+  const generatedFiles = validateEnum('generated_files', input.generated_files, GENERATED_FILE_POLICIES, false);
   const maxFileSize = validateMaxFileSize(input.max_file_size, false) ?? 5000;
   const protectSecrets = validateBoolean('protect_secrets', input.protect_secrets, false) ?? true;
   const secretPatterns = validateArray('secret_patterns', input.secret_patterns, false) as string[] | undefined;
+  const scanSecrets = validateBoolean('scan_secrets', input.scan_secrets, false) ?? false;
   const summaryMethod = validateSummaryMethod(input.summary_method, false) ?? 'llm';
 
   // Validate repository configuration
@@ -209,9 +224,12 @@ export const indexRepositoryTool = async (
     incremental,
     languages: languages ?? [],
     respectGitignore,
+    symlinkPolicy,
+    generatedFiles,
     maxFileSize,
     protectSecrets,
     secretPatterns: secretPatterns ?? [],
+    scanSecrets,
     summaryMethod,
 
     // Repository configuration
@@ -236,6 +254,8 @@ export const indexRepositoryTool = async (
     forceReindex,
     metadata,
 
+    signal,
+
     // Progress callback
     onProgress: onProgress
       ? (stage: string, current: number, total: number, message: string, etaSeconds?: number) => {
@@ -294,6 +314,9 @@ export const indexRepositoryTool = async (
     api_endpoints_found: stats.api_endpoints_found,
     indexing_time_ms: stats.indexing_time_ms,
     errors: stats.errors.length > 0 ? stats.errors.map((e) => e.error) : undefined,
+    unreadable_paths: stats.unreadable_paths,
+    secret_findings: stats.secret_findings,
+    secret_files: stats.secret_files,
   };
 
   // Format result
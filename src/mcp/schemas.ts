@@ -21,6 +21,7 @@ import { z } from 'zod';
  * @property import_depth - Maximum import chain depth (1-3, default: 2)
  * @property dedup_threshold - Similarity threshold for deduplication (0-1, default: 0.92)
  * @property similarity_threshold - Minimum similarity score (0-1, default: 0.75)
+ * @property include_generated - Include files tagged generated (default: false)
  * @property workspace_filter - Filter by workspace ID(s)
  * @property package_filter - Filter by package name(s)
  * @property exclude_workspaces - Exclude specific workspaces
@@ -44,6 +45,7 @@ export const SearchCodebaseSchema = z.object({
   dedup_threshold: z.number().min(0).max(1).optional(),
   similarity_threshold: z.number().min(0).max(1).optional(),
   chunk_similarity_threshold: z.number().min(0).max(1).optional(),
+  include_generated: z.boolean().optional(),
 
   // Multi-project filtering
   workspace_filter: z.union([z.string(), z.array(z.string())]).optional(),
@@ -126,6 +128,8 @@ export const GetFileContextSchema = z.object({
  * @property include_cross_workspace - Include usages across workspace boundaries
  * @property include_cross_service - Include usages across service boundaries
  * @property max_usages - Maximum number of usage locations to return (1-100, default: 50)
+ * @property include_snippet - Include each definition's source span, byte offsets, and context lines
+ * @property snippet_context - Context lines around the span (0-50, default: 2)
  */
 export const FindSymbolSchema = z.object({
   symbol_name: z.string().min(1, 'Symbol name is required'),
@@ -141,6 +145,10 @@ export const FindSymbolSchema = z.object({
   include_cross_workspace: z.boolean().optional(),
   include_cross_service: z.boolean().optional(),
   max_usages: z.number().int().min(1).max(100).optional(),
+
+  // Source options
+  include_snippet: z.boolean().optional(),
+  snippet_context: z.number().int().min(0).max(50).optional(),
 });
 
 /**
@@ -158,6 +166,9 @@ export const FindSymbolSchema = z.object({
  * @property incremental - Use incremental indexing (hash comparison, default: true)
  * @property languages - Specific languages to index (default: all supported)
  * @property respect_gitignore - Respect .gitignore exclusions (default: true)
+ * @property symlink_policy - Symlink handling (skip/follow-within-root/follow-all, default: SYMLINK_POLICY env)
+ * @property generated_files - Generated file handling (exclude/tag, default: GENERATED_FILES env)
+ * @property scan_secrets - Record likely credentials for `cindex secrets` (default: SCAN_SECRETS env)
  * @property max_file_size - Maximum file size in KB (100-10000, default: 1000)
  * @property summary_method - Summary generation method (llm/rule-based, default: llm)
  * @property repo_id - Unique repository identifier (auto-generated if not provided)
@@ -181,6 +192,9 @@ export const IndexRepositorySchema = z.object({
   incremental: z.boolean().optional(),
   languages: z.array(z.string()).optional(),
   respect_gitignore: z.boolean().optional(),
+  symlink_policy: z.enum(['skip', 'follow-within-root', 'follow-all']).optional(),
+  generated_files: z.enum(['exclude', 'tag']).optional(),
+  scan_secrets: z.boolean().optional(),
   max_file_size: z.number().int().min(100).max(10000).optional(),
   summary_method: z.enum(['llm', 'rule-based']).optional(),
 
@@ -239,6 +253,24 @@ export const IndexRepositorySchema = z.object({
     .optional(),
 });
 
+/**
+ * Zod schema for add_document MCP tool
+ *
+ * Indexes content that is not on disk (editor buffers, generated code, fetched snippets)
+ * into an ephemeral index, queryable right away with its name as the repo_id.
+ *
+ * @property name - Ephemeral index to add the document to (letters, digits, '.', '_', '-')
+ * @property content - Document content
+ * @property language - Language of the content (default: from the path's extension)
+ * @property path - Path of the document in the index (default: the name)
+ */
+export const AddDocumentSchema = z.object({
+  name: z.string().regex(/^[A-Za-z0-9][A-Za-z0-9._-]*$/, 'Name must use letters, digits, ".", "_", and "-"'),
+  content: z.string(),
+  language: z.string().min(1).optional(),
+  path: z.string().min(1).optional(),
+});
+
 /**
  * Zod schema for delete_repository MCP tool
  *
@@ -481,3 +513,67 @@ export const ListDocumentationSchema = z.object({
 export const DeleteDocumentationSchema = z.object({
   doc_ids: z.array(z.string().min(1)).min(1, 'At least one doc_id is required'),
 });
+
+/**
+ * Zod schema for search_symbols MCP tool
+ *
+ * Fuzzy symbol name search, ranked (cindex search --fuzzy).
+ *
+ * @property query - Characters of the name in order (NAS finds NewAuthService)
+ * @property repo_id - Limit to one repository
+ * @property near - Path relative to the repository root whose neighborhood ranks first
+ * @property max_results - Maximum symbols to return (1-100, default: 20)
+ */
+export const SearchSymbolsSchema = z.object({
+  query: z.string().min(2, 'Query must be at least 2 characters'),
+  repo_id: z.string().optional(),
+  near: z.string().optional(),
+  max_results: z.number().int().min(1).max(100).optional(),
+});
+
+/**
+ * Zod schema for get_definition MCP tool
+ *
+ * Resolve an identifier to its declaration; exact for Go indexed with --typed
+ * when the use's file and line are given.
+ *
+ * @property symbol_name - Identifier as written at the use
+ * @property file_path - File of the use
+ * @property line - 1-based line of the use
+ * @property repo_id - Limit to one repository
+ */
+export const GetDefinitionSchema = z.object({
+  symbol_name: z.string().min(1, 'Symbol name is required'),
+  file_path: z.string().optional(),
+  line: z.number().int().min(1).optional(),
+  repo_id: z.string().optional(),
+});
+
+/**
+ * Zod schema for get_references MCP tool
+ *
+ * Uses of a declaration: type-checked for Go indexed with --typed, whole-word
+ * text matches otherwise.
+ *
+ * @property symbol_name - Declaration name (Login, AuthService.Login, auth.Login)
+ * @property repo_id - Limit to one repository
+ * @property max_results - Maximum uses to return (1-100, default: 100)
+ */
+export const GetReferencesSchema = z.object({
+  symbol_name: z.string().min(1, 'Symbol name is required'),
+  repo_id: z.string().optional(),
+  max_results: z.number().int().min(1).max(100).optional(),
+});
+
+/**
+ * Zod schema for get_file_outline MCP tool
+ *
+ * Symbols a file declares, in order.
+ *
+ * @property file_path - Stored, absolute, or trailing path of the file
+ * @property repo_id - Limit to one repository
+ */
+export const GetFileOutlineSchema = z.object({
+  file_path: z.string().min(1, 'File path is required'),
+  repo_id: z.string().optional(),
+});
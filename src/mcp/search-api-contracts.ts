@@ -7,8 +7,8 @@ import { type Pool } from 'pg';
 import { searchAPIContracts } from '@database/queries';
 import { formatAPIContractResults } from '@mcp/formatter';
 import { validateArray, validateBoolean, validateMaxResults, validateQuery, validateThreshold } from '@mcp/validator';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import { type EmbeddingConfig } from '@/types/config';
 
 /**
@@ -50,7 +50,7 @@ export interface SearchAPIContractsOutput {
  * deprecation status.
  *
  * @param db - Database connection pool
- * @param ollama - Ollama client for embedding generation
+ * @param embedder - Embedder for the query embedding
  * @param embeddingConfig - Embedding configuration (model, dimensions, context window)
  * @param input - Search API contracts parameters with filters
  * @returns Formatted API contract results grouped by service with implementation links
@@ -58,7 +58,7 @@ export interface SearchAPIContractsOutput {
  */
 export const searchAPIContractsTool = async (
   db: Pool,
-  ollama: OllamaClient,
+  embedder: Embedder,
   embeddingConfig: EmbeddingConfig,
   input: SearchAPIContractsInput
 ): Promise<SearchAPIContractsOutput> => {
@@ -87,7 +87,7 @@ export const searchAPIContractsTool = async (
   });
 
   // Generate embedding for query
-  const queryEmbedding = await ollama.generateEmbedding(
+  const queryEmbedding = await embedder.generateEmbedding(
     embeddingConfig.model,
     query,
     embeddingConfig.dimensions,
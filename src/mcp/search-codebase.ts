@@ -21,8 +21,8 @@ import {
   validateThreshold,
   validateWorkspaceId,
 } from '@mcp/validator';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import { type CindexConfig } from '@/types/config';
 import { type RepositoryType } from '@/types/database';
 import { type SearchOptions, type SearchResult } from '@/types/retrieval';
@@ -40,6 +40,7 @@ export interface SearchCodebaseInput {
   dedup_threshold?: number; // Default: 0.92, Range: 0.0-1.0
   similarity_threshold?: number; // Default: 0.3, Range: 0.0-1.0 (file-level)
   chunk_similarity_threshold?: number; // Default: 0.2, Range: 0.0-1.0 (chunk-level)
+  include_generated?: boolean; // Default: false - Include files tagged generated
 
   // Multi-project filtering
   workspace_filter?: string | string[];
@@ -186,7 +187,7 @@ const normalizeRepoFilter = (value: unknown): string[] | undefined => {
  *
  * @param db - Database connection pool
  * @param config - cindex configuration with embedding and summary settings
- * @param ollama - Ollama client for embedding generation
+ * @param embedder - Embedder for the query embedding
  * @param input - Search parameters with filters, scope, and retrieval options
  * @returns Formatted search result with context, metadata, and warnings
  * @throws {Error} If query validation fails or database connection fails
@@ -194,7 +195,7 @@ const normalizeRepoFilter = (value: unknown): string[] | undefined => {
 export const searchCodebaseTool = async (
   db: Pool,
   config: CindexConfig,
-  ollama: OllamaClient,
+  embedder: Embedder,
   input: SearchCodebaseInput
 ): Promise<SearchCodebaseOutput> => {
   logger.info('search_codebase tool invoked', { query: input.query });
@@ -215,6 +216,7 @@ export const searchCodebaseTool = async (
     input.chunk_similarity_threshold,
     false
   );
+  const includeGenerated = validateBoolean('include_generated', input.include_generated, false);
 
   // Validate multi-project filters with normalization
   const workspaceFilter = normalizeWorkspaceFilter(input.workspace_filter);
@@ -271,6 +273,7 @@ export const searchCodebaseTool = async (
     dedup_threshold: dedupThreshold,
     similarity_threshold: similarityThreshold,
     chunk_similarity_threshold: chunkSimilarityThreshold,
+    include_generated: includeGenerated,
 
     // Multi-project filtering
     workspace_filter: workspaceFilter,
@@ -297,7 +300,7 @@ export const searchCodebaseTool = async (
   // Note: DatabaseClient expects a full class instance, but we only need the query method.
   // We create a minimal wrapper that provides the query interface for compatibility.
   const dbClient = { query: db.query.bind(db) } as unknown as DatabaseClient;
-  const result = await searchCodebaseFn(query, config, dbClient, ollama, searchOptions);
+  const result = await searchCodebaseFn(query, config, dbClient, embedder, searchOptions);
 
   logger.info('search_codebase completed', {
     query,
@@ -12,7 +12,7 @@ import {
   listDocumentation,
   searchReferences,
 } from '@retrieval/doc-search';
-import { type OllamaClient } from '@utils/ollama';
+import { type Embedder } from '@utils/embedders';
 import {
   type DeleteDocumentationInput,
   type DeleteDocumentationOutput,
@@ -27,18 +27,18 @@ import {
  * Searches both markdown documentation and reference repository code
  *
  * @param pool - Database connection pool
- * @param ollamaClient - Ollama client for embeddings
+ * @param embedder - Embedder for the query embedding
  * @param embeddingConfig - Embedding configuration
  * @param input - Search parameters
  * @returns Search results from both documentation and reference repos
  */
 export const searchReferencesTool = async (
   pool: pg.Pool,
-  ollamaClient: OllamaClient,
+  embedder: Embedder,
   embeddingConfig: { model: string; dimensions: number; context_window?: number },
   input: SearchReferencesInput
 ): Promise<{ formatted_result: string; output: SearchReferencesOutput }> => {
-  const output = await searchReferences(pool, ollamaClient, embeddingConfig, input);
+  const output = await searchReferences(pool, embedder, embeddingConfig, input);
   const formattedResult = formatSearchReferencesOutput(output);
 
   return { formatted_result: formattedResult, output };
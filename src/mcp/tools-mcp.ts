@@ -10,7 +10,19 @@
  */
 import { type Pool } from 'pg';
 
+import { type DatabaseClient } from '@database/client';
 import { type IndexingOrchestrator } from '@indexing/orchestrator';
+import { addDocumentTool, formatAddDocumentOutput, type AddDocumentInput } from '@mcp/add-document';
+import {
+  getDefinitionTool,
+  getFileOutlineTool,
+  getReferencesTool,
+  searchSymbolsTool,
+  type GetDefinitionInput,
+  type GetFileOutlineInput,
+  type GetReferencesInput,
+  type SearchSymbolsInput,
+} from '@mcp/code-navigation';
 import { deleteRepositoryTool, formatDeletionOutput, type DeleteRepositoryInput } from '@mcp/delete-repository';
 import { findCrossServiceCallsTool, type FindCrossServiceCallsInput } from '@mcp/find-cross-service-calls';
 import { findCrossWorkspaceUsagesTool, type FindCrossWorkspaceUsagesInput } from '@mcp/find-cross-workspace-usages';
@@ -26,9 +38,9 @@ import { listWorkspacesTool, type ListWorkspacesInput } from '@mcp/list-workspac
 import { searchAPIContractsTool, type SearchAPIContractsInput } from '@mcp/search-api-contracts';
 import { searchCodebaseTool, type SearchCodebaseInput } from '@mcp/search-codebase';
 import { deleteDocumentationTool, listDocumentationTool, searchReferencesTool } from '@mcp/search-documentation';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
-import { type CindexConfig } from '@/types/config';
+import { type CindexConfig, type StoreConfig } from '@/types/config';
 import {
   type DeleteDocumentationInput,
   type IndexDocumentationInput,
@@ -57,7 +69,7 @@ interface MCPToolResult {
  *
  * @param db - Database connection pool
  * @param config - cindex configuration
- * @param ollama - Ollama client for embeddings
+ * @param embedder - Embedder of the configured provider
  * @param input - Search parameters
  * @returns MCP-formatted result with content and structured metadata
  * @throws {Error} If search fails or validation errors occur
@@ -65,11 +77,11 @@ interface MCPToolResult {
 export const searchCodebaseMCP = async (
   db: Pool,
   config: CindexConfig,
-  ollama: OllamaClient,
+  embedder: Embedder,
   input: SearchCodebaseInput
 ): Promise<MCPToolResult> => {
   try {
-    const result = await searchCodebaseTool(db, config, ollama, input);
+    const result = await searchCodebaseTool(db, config, embedder, input);
 
     return {
       content: [
@@ -175,6 +187,7 @@ export const findSymbolMCP = async (db: Pool, input: FindSymbolInput): Promise<M
           file_path: s.file_path,
           line_number: s.line_number,
           scope: s.scope,
+          ...(s.snippet !== undefined && { snippet: s.snippet }),
         })),
       },
     };
@@ -193,6 +206,7 @@ export const findSymbolMCP = async (db: Pool, input: FindSymbolInput): Promise<M
  * @param orchestrator - Indexing orchestrator instance
  * @param input - Index repository parameters
  * @param onProgress - Optional progress callback for MCP notifications
+ * @param signal - Optional abort signal (server shutdown stops indexing after the current file)
  * @returns MCP-formatted result with indexing statistics
  * @throws {Error} If repository path invalid or indexing fails
  */
@@ -205,10 +219,11 @@ export const indexRepositoryMCP = async (
     total: number;
     message: string;
     eta_seconds?: number;
-  }) => void
+  }) => void,
+  signal?: AbortSignal
 ): Promise<MCPToolResult> => {
   try {
-    const result = await indexRepositoryTool(orchestrator, input, onProgress);
+    const result = await indexRepositoryTool(orchestrator, input, onProgress, signal);
 
     return {
       content: [
@@ -235,6 +250,46 @@ export const indexRepositoryMCP = async (
   }
 };
 
+/**
+ * add_document MCP wrapper
+ *
+ * Wraps the add_document tool for MCP SDK compatibility. Returns the stored path
+ * and indexing statistics of the ephemeral document.
+ *
+ * @param config - cindex configuration
+ * @param db - Database client (the indexing pipeline needs more than the pool)
+ * @param embedder - Embedder of the configured provider
+ * @param input - Name, content, language, and path
+ * @returns MCP-formatted result with indexing statistics
+ * @throws {Error} If the name, path, or language is invalid, or the name belongs to a repository index
+ */
+export const addDocumentMCP = async (
+  config: CindexConfig,
+  db: DatabaseClient,
+  embedder: Embedder,
+  input: AddDocumentInput
+): Promise<MCPToolResult> => {
+  try {
+    const result = await addDocumentTool(config, db, embedder, input);
+
+    return {
+      content: [{ type: 'text', text: formatAddDocumentOutput(result) }],
+      structuredContent: {
+        repo_id: result.repo_id,
+        file_path: result.file_path,
+        chunks_created: result.stats.chunks_total,
+        symbols_extracted: result.stats.symbols_extracted,
+        errors: result.stats.errors,
+        pruned: result.pruned,
+        indexing_time_ms: result.stats.total_time_ms,
+      },
+    };
+  } catch (error) {
+    logger.error('add_document tool failed', { error });
+    throw error;
+  }
+};
+
 /**
  * delete_repository MCP wrapper
  *
@@ -243,12 +298,17 @@ export const indexRepositoryMCP = async (
  *
  * @param db - Database connection pool
  * @param input - Delete repository parameters with repo_ids array
+ * @param store - Store to remove the repositories from too (STORE_BACKEND)
  * @returns MCP-formatted result with deletion statistics per repository
  * @throws {Error} If validation fails, repository not found, or deletion fails
  */
-export const deleteRepositoryMCP = async (db: Pool, input: DeleteRepositoryInput): Promise<MCPToolResult> => {
+export const deleteRepositoryMCP = async (
+  db: Pool,
+  input: DeleteRepositoryInput,
+  store?: StoreConfig
+): Promise<MCPToolResult> => {
   try {
-    const result = await deleteRepositoryTool(db, input);
+    const result = await deleteRepositoryTool(db, input, store);
     const formattedResult = formatDeletionOutput(result);
 
     return {
@@ -434,12 +494,12 @@ export const findCrossServiceCallsMCP = async (db: Pool, input: FindCrossService
  */
 export const searchAPIContractsMCP = async (
   db: Pool,
-  ollama: OllamaClient,
+  embedder: Embedder,
   config: CindexConfig,
   input: SearchAPIContractsInput
 ): Promise<MCPToolResult> => {
   try {
-    const result = await searchAPIContractsTool(db, ollama, config.embedding, input);
+    const result = await searchAPIContractsTool(db, embedder, config.embedding, input);
 
     return {
       content: [{ type: 'text', text: result.formatted_result }],
@@ -464,12 +524,12 @@ export const searchAPIContractsMCP = async (
  */
 export const indexDocumentationMCP = async (
   db: Pool,
-  ollama: OllamaClient,
+  embedder: Embedder,
   config: CindexConfig,
   input: IndexDocumentationInput
 ): Promise<MCPToolResult> => {
   try {
-    const result = await indexDocumentationTool(db, ollama, config.embedding, input);
+    const result = await indexDocumentationTool(db, embedder, config.embedding, input);
 
     return {
       content: [{ type: 'text', text: formatIndexDocumentationOutput(result) }],
@@ -498,12 +558,12 @@ export const indexDocumentationMCP = async (
  */
 export const searchReferencesMCP = async (
   db: Pool,
-  ollama: OllamaClient,
+  embedder: Embedder,
   config: CindexConfig,
   input: SearchReferencesInput
 ): Promise<MCPToolResult> => {
   try {
-    const result = await searchReferencesTool(db, ollama, config.embedding, input);
+    const result = await searchReferencesTool(db, embedder, config.embedding, input);
 
     return {
       content: [{ type: 'text', text: result.formatted_result }],
@@ -567,3 +627,116 @@ export const deleteDocumentationMCP = async (db: Pool, input: DeleteDocumentatio
     throw error;
   }
 };
+
+/**
+ * search_symbols MCP wrapper
+ *
+ * Fuzzy, ranked symbol name search.
+ */
+export const searchSymbolsMCP = async (db: Pool, input: SearchSymbolsInput): Promise<MCPToolResult> => {
+  try {
+    const result = await searchSymbolsTool(db, input);
+
+    return {
+      content: [{ type: 'text', text: result.formatted_result }],
+      structuredContent: {
+        query: input.query,
+        symbols: result.symbols.map((s) => ({
+          name: s.symbol_name,
+          type: s.symbol_type,
+          repo_id: s.repo_id,
+          file_path: s.file_path,
+          line_number: s.line_number,
+          scope: s.scope,
+          language: s.language,
+          score: s.score,
+        })),
+      },
+    };
+  } catch (error) {
+    logger.error('search_symbols tool failed', { error });
+    throw error;
+  }
+};
+
+/**
+ * get_definition MCP wrapper
+ *
+ * Declarations an identifier refers to.
+ */
+export const getDefinitionMCP = async (db: Pool, input: GetDefinitionInput): Promise<MCPToolResult> => {
+  try {
+    const result = await getDefinitionTool(db, input);
+
+    return {
+      content: [{ type: 'text', text: result.formatted_result }],
+      structuredContent: {
+        symbol_name: input.symbol_name,
+        source: result.source,
+        definitions: result.definitions.map((d) => ({
+          name: d.symbol_name,
+          type: d.symbol?.symbol_type,
+          repo_id: d.repo_id,
+          file_path: d.file_path,
+          line_number: d.line_number,
+        })),
+      },
+    };
+  } catch (error) {
+    logger.error('get_definition tool failed', { error });
+    throw error;
+  }
+};
+
+/**
+ * get_references MCP wrapper
+ *
+ * Uses of a declaration.
+ */
+export const getReferencesMCP = async (db: Pool, input: GetReferencesInput): Promise<MCPToolResult> => {
+  try {
+    const result = await getReferencesTool(db, input);
+
+    return {
+      content: [{ type: 'text', text: result.formatted_result }],
+      structuredContent: {
+        symbol_name: input.symbol_name,
+        source: result.source,
+        truncated: result.truncated,
+        references: result.references,
+      },
+    };
+  } catch (error) {
+    logger.error('get_references tool failed', { error });
+    throw error;
+  }
+};
+
+/**
+ * get_file_outline MCP wrapper
+ *
+ * Declarations of a file as a tree, and its indexed symbols.
+ */
+export const getFileOutlineMCP = async (db: Pool, input: GetFileOutlineInput): Promise<MCPToolResult> => {
+  try {
+    const result = await getFileOutlineTool(db, input);
+
+    return {
+      content: [{ type: 'text', text: result.formatted_result }],
+      structuredContent: {
+        file_path: result.file_path,
+        repo_id: result.repo_id,
+        outline: result.outline,
+        symbols: result.symbols.map((s) => ({
+          name: s.symbol_name,
+          type: s.symbol_type,
+          line_number: s.line_number,
+          scope: s.scope,
+        })),
+      },
+    };
+  } catch (error) {
+    logger.error('get_file_outline tool failed', { error });
+    throw error;
+  }
+};
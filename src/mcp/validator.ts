@@ -2,7 +2,11 @@
  * MCP Tool Input Validation
  * Provides comprehensive validation functions for all MCP tool parameters
  */
+import { isAbsolute } from 'node:path';
+
 import { CindexError } from '@utils/errors';
+import { normalizeRootPath } from '@utils/paths';
+import { normalizeUnicode } from '@utils/unicode';
 
 /**
  * Validation error - invalid tool input parameters
@@ -349,27 +353,29 @@ export const validateRepoId = (value: unknown, required = true): string | undefi
 export const validateRepoPath = (value: unknown, required = true): string | undefined => {
   const path = validateNonEmptyString('repo_path', value, required);
 
-  if (path !== undefined && !path.startsWith('/')) {
+  if (path !== undefined && !isAbsolute(path)) {
     throw new ValidationError(
       'repo_path',
       'Must be an absolute path',
       { path },
-      'Provide an absolute directory path starting with /'
+      'Provide an absolute directory path (e.g. /home/me/project or C:\\Users\\me\\project)'
     );
   }
 
-  return path;
+  // Drive letter case, long-path prefixes, and trailing separators do not change the repository
+  return path !== undefined ? normalizeRootPath(path) : undefined;
 };
 
 /**
- * Validate symbol_name parameter
+ * Validate symbol_name parameter (normalized to NFC)
  */
 export const validateSymbolName = (value: unknown, required = true): string | undefined => {
-  return validateNonEmptyString('symbol_name', value, required);
+  const symbolName = validateNonEmptyString('symbol_name', value, required);
+  return symbolName !== undefined ? normalizeUnicode(symbolName) : undefined;
 };
 
 /**
- * Validate query parameter
+ * Validate query parameter (normalized to NFC)
  */
 export const validateQuery = (value: unknown, required = true): string | undefined => {
   const query = validateNonEmptyString('query', value, required);
@@ -383,7 +389,7 @@ export const validateQuery = (value: unknown, required = true): string | undefin
     );
   }
 
-  return query;
+  return query !== undefined ? normalizeUnicode(query) : undefined;
 };
 
 /**
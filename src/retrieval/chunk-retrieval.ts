@@ -10,6 +10,7 @@ import { type DatabaseClient } from '@database/client';
 import { type ScopeFilter } from '@retrieval/scope-filter';
 import { buildChunkLevelHybridSql, getHybridConfig, sanitizeQueryForFts } from '@utils/hybrid-search';
 import { logger } from '@utils/logger';
+import { compareRanked } from '@utils/ordering';
 import { type CindexConfig } from '@/types/config';
 import { type QueryEmbedding, type RelevantChunk, type RelevantFile } from '@/types/retrieval';
 
@@ -168,6 +169,9 @@ export const retrieveChunks = async (
       };
     });
 
+    // Stable order for equal scores (vector-only ORDER BY has no tie-breaker)
+    chunks.sort(compareRanked((chunk) => chunk.similarity));
+
     const retrievalTime = Date.now() - startTime;
 
     // Calculate statistics
@@ -339,6 +343,9 @@ export const retrieveChunksFiltered = async (
       };
     });
 
+    // Stable order for equal scores (vector-only ORDER BY has no tie-breaker)
+    chunks.sort(compareRanked((chunk) => chunk.similarity));
+
     const retrievalTime = Date.now() - startTime;
 
     logger.info('Filtered chunk-level retrieval complete', {
@@ -0,0 +1,297 @@
+/**
+ * Regex search over indexed file contents (cindex grep)
+ *
+ * Each indexed file's content is stored with a pg_trgm GIN index. PostgreSQL
+ * extracts the trigrams a regular expression requires and reads only the
+ * files holding all of them, as Google Code Search does, then runs the
+ * expression there. Lines are matched again here for positions, so a file is
+ * reported the way grep would: one match per line, first occurrence.
+ *
+ * Patterns are JavaScript regular expressions, matched line by line. They are
+ * handed to PostgreSQL as advanced regular expressions in newline-sensitive
+ * mode, so ^, $, and . stay within a line; the word boundaries \b and \B are
+ * translated (\y, \Y there). Constructs only one side knows, such as named
+ * groups, are rejected by the database.
+ *
+ * A packed index (cindex grep --packed, see @indexing/packed-index) is
+ * searched the same way without a database: the literal text a pattern
+ * requires gives the trigrams a file must hold, and only the files holding
+ * all of them are read and matched.
+ */
+
+import { type Pool } from 'pg';
+
+import { searchFileContents } from '@database/queries';
+import { type PackedIndex } from '@indexing/packed-index';
+import { utf16ToByteColumn } from '@utils/positions';
+
+/** Files read from the database per query while collecting matches */
+const CONTENT_PAGE_SIZE = 200;
+
+/** Longest line text reported with a match */
+const MAX_MATCH_TEXT = 400;
+
+/**
+ * Line matching a content search
+ */
+export interface ContentMatch {
+  repo_id: string | null;
+  file_path: string;
+  /** 1-based line */
+  line: number;
+  /** 1-based column of the match, in UTF-16 code units */
+  column: number;
+  /** 1-based column of the match, in UTF-8 bytes */
+  byte_column: number;
+  /** Length of the match, in UTF-16 code units */
+  length: number;
+  /** Line text (truncated to MAX_MATCH_TEXT characters) */
+  text: string;
+}
+
+/**
+ * Options of a content search
+ */
+export interface ContentSearchOptions {
+  ignoreCase?: boolean;
+  /** Match the pattern as literal text */
+  fixedStrings?: boolean;
+  repoId?: string;
+  /** Only files under this path (relative to their repository root) */
+  pathPrefix?: string;
+  /** Most matching lines returned */
+  limit?: number;
+}
+
+/**
+ * Escape regular expression syntax so text matches literally
+ */
+export const escapeRegex = (text: string): string => text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+
+/**
+ * Translate a JavaScript regular expression to a PostgreSQL one
+ *
+ * \b and \B become \y and \Y (in PostgreSQL, \b is a backspace); escapes
+ * inside bracket expressions are left alone.
+ *
+ * @param pattern - JavaScript pattern
+ * @returns Pattern for the ~ operator
+ */
+export const toPostgresRegex = (pattern: string): string => {
+  let out = '';
+  let inBracket = false;
+  for (let i = 0; i < pattern.length; i++) {
+    const char = pattern[i];
+    if (char === '\\' && i + 1 < pattern.length) {
+      const next = pattern[++i];
+      out += !inBracket && (next === 'b' || next === 'B') ? `\\${next === 'b' ? 'y' : 'Y'}` : `\\${next}`;
+      continue;
+    }
+    if (char === '[') inBracket = true;
+    else if (char === ']') inBracket = false;
+    out += char;
+  }
+  return out;
+};
+
+/**
+ * Compile a search pattern
+ *
+ * @param pattern - Pattern as given
+ * @param options - ignoreCase and fixedStrings
+ * @returns Expression for line matching and its PostgreSQL form
+ * @throws {SyntaxError} If the pattern is not a valid regular expression
+ */
+export const compileContentPattern = (
+  pattern: string,
+  options: Pick<ContentSearchOptions, 'ignoreCase' | 'fixedStrings'> = {}
+): { regex: RegExp; postgres: string } => {
+  const source = options.fixedStrings ? escapeRegex(pattern) : pattern;
+  return {
+    regex: new RegExp(source, options.ignoreCase ? 'i' : ''),
+    postgres: `(?n${options.ignoreCase ? 'i' : ''})${toPostgresRegex(source)}`,
+  };
+};
+
+/**
+ * Find the lines of a file that match
+ *
+ * @param content - File content
+ * @param regex - Compiled pattern (not global)
+ * @param limit - Most lines returned
+ * @returns Matches without the file fields, in line order
+ */
+export const matchLines = (
+  content: string,
+  regex: RegExp,
+  limit = Number.POSITIVE_INFINITY
+): Omit<ContentMatch, 'repo_id' | 'file_path'>[] => {
+  const matches: Omit<ContentMatch, 'repo_id' | 'file_path'>[] = [];
+  const lines = content.split(/\r?\n/);
+  for (let index = 0; index < lines.length && matches.length < limit; index++) {
+    const text = lines[index];
+    const match = regex.exec(text);
+    if (!match) continue;
+    matches.push({
+      line: index + 1,
+      column: match.index + 1,
+      byte_column: utf16ToByteColumn(text, match.index + 1),
+      length: match[0].length,
+      text: text.length > MAX_MATCH_TEXT ? text.slice(0, MAX_MATCH_TEXT) : text,
+    });
+  }
+  return matches;
+};
+
+/**
+ * Search indexed file contents for lines matching a pattern
+ *
+ * @param db - Database connection pool
+ * @param pattern - JavaScript regular expression, or text with fixedStrings
+ * @param options - Search options
+ * @returns Matches ordered by file and line, and whether the limit cut them short
+ * @throws {SyntaxError} If the pattern is not a valid regular expression
+ * @throws {DatabaseQueryError} If PostgreSQL rejects the pattern or the query fails
+ */
+export const searchContent = async (
+  db: Pool,
+  pattern: string,
+  options: ContentSearchOptions = {}
+): Promise<{ matches: ContentMatch[]; truncated: boolean }> => {
+  const { regex, postgres } = compileContentPattern(pattern, options);
+  const limit = options.limit ?? 100;
+  const matches: ContentMatch[] = [];
+
+  let after: string | null = null;
+  for (;;) {
+    const files = await searchFileContents(db, postgres, {
+      repoId: options.repoId,
+      pathPrefix: options.pathPrefix,
+      after,
+      limit: CONTENT_PAGE_SIZE,
+    });
+    for (const file of files) {
+      const remaining = limit - matches.length;
+      const lines = matchLines(file.content, regex, remaining + 1);
+      for (const line of lines.slice(0, remaining)) {
+        matches.push({ repo_id: file.repo_id, file_path: file.file_path, ...line });
+      }
+      if (lines.length > remaining) return { matches, truncated: true };
+    }
+
+    const last = files.at(-1);
+    if (!last || files.length < CONTENT_PAGE_SIZE) return { matches, truncated: false };
+    after = last.file_path;
+  }
+};
+
+/** Regular expression characters an escape makes literal */
+const ESCAPED_LITERALS = new Set([...'.*+?^${}()|[]\\/-']);
+
+/**
+ * Literal text every match of a pattern contains
+ *
+ * Conservative: groups, classes, and patterns with alternatives give no
+ * literal, and a character made optional by a quantifier ends the text
+ * before it. Texts shorter than three characters hold no trigram and are
+ * left out.
+ *
+ * @param pattern - Pattern as given
+ * @param fixedStrings - The pattern is literal text
+ * @returns Literal texts, possibly none
+ */
+export const requiredLiterals = (pattern: string, fixedStrings = false): string[] => {
+  if (fixedStrings) return pattern.length >= 3 ? [pattern] : [];
+  if (pattern.includes('|')) return [];
+
+  const literals: string[] = [];
+  let run = '';
+  let depth = 0;
+  const end = (): void => {
+    if (run.length >= 3) literals.push(run);
+    run = '';
+  };
+  const skipTo = (from: number, close: string): number => {
+    const at = pattern.indexOf(close, from);
+    return at === -1 ? pattern.length : at;
+  };
+
+  for (let i = 0; i < pattern.length; i++) {
+    const char = pattern[i];
+    if (char === '\\') {
+      const next = pattern[++i] ?? '';
+      if (depth === 0 && ESCAPED_LITERALS.has(next)) {
+        run += next;
+        continue;
+      }
+      end();
+      // Skip the operands of escapes naming a character or group (\x41, \u0041, \cJ, \k<name>)
+      if (next === 'x') i += 2;
+      else if (next === 'u') i = pattern[i + 1] === '{' ? skipTo(i, '}') : i + 4;
+      else if (next === 'c') i += 1;
+      else if (next === 'k' && pattern[i + 1] === '<') i = skipTo(i, '>');
+      continue;
+    }
+    if (char === '[') {
+      end();
+      for (i++; i < pattern.length && pattern[i] !== ']'; i++) {
+        if (pattern[i] === '\\') i++;
+      }
+      continue;
+    }
+    if (char === '(' || char === ')') {
+      end();
+      depth = Math.max(0, depth + (char === '(' ? 1 : -1));
+      continue;
+    }
+    if (char === '*' || char === '?' || char === '{') {
+      // The quantified character may be absent
+      run = run.slice(0, /[\udc00-\udfff]$/.test(run) ? -2 : -1);
+      end();
+      if (char === '{') i = skipTo(i, '}');
+      continue;
+    }
+    if (char === '+' || char === '.' || char === '^' || char === '$') {
+      end();
+      continue;
+    }
+    if (depth === 0) run += char;
+  }
+  end();
+  return literals;
+};
+
+/**
+ * Search the files of a packed index for lines matching a pattern
+ *
+ * @param index - Open packed index
+ * @param pattern - JavaScript regular expression, or text with fixedStrings
+ * @param options - Search options (repoId does not apply: a packed index holds one index)
+ * @returns Matches ordered by file and line, and whether the limit cut them short
+ * @throws {SyntaxError} If the pattern is not a valid regular expression
+ * @throws {PackedIndexError} If the packed index cannot be read
+ */
+export const searchPackedContent = async (
+  index: PackedIndex,
+  pattern: string,
+  options: ContentSearchOptions = {}
+): Promise<{ matches: ContentMatch[]; truncated: boolean }> => {
+  const { regex } = compileContentPattern(pattern, options);
+  const limit = options.limit ?? 100;
+  const matches: ContentMatch[] = [];
+
+  const candidates = await index.candidates(requiredLiterals(pattern, options.fixedStrings), options);
+  const ids = candidates ?? Array.from({ length: index.metadata.files }, (_, id) => id);
+  for (const id of ids) {
+    const file = await index.file(id);
+    if (options.pathPrefix && !file.file_path.startsWith(options.pathPrefix)) continue;
+
+    const remaining = limit - matches.length;
+    const lines = matchLines(await index.readContent(file), regex, remaining + 1);
+    for (const line of lines.slice(0, remaining)) {
+      matches.push({ repo_id: index.metadata.repo_id, file_path: file.file_path, ...line });
+    }
+    if (lines.length > remaining) return { matches, truncated: true };
+  }
+  return { matches, truncated: false };
+};
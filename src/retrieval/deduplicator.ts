@@ -10,6 +10,7 @@ import { type Pool, type QueryResult } from 'pg';
 
 import { type SearchResult } from '@retrieval/vector-search';
 import { logger } from '@utils/logger';
+import { compareRanked, compareStrings } from '@utils/ordering';
 import { type CodeChunk, type RepositoryType, type RepoTypeQueryResult } from '@/types/database';
 import { type DeduplicationResult, type RelevantChunk } from '@/types/retrieval';
 
@@ -82,9 +83,9 @@ export const deduplicateChunksBase = (chunks: RelevantChunk[], dedupThreshold =
     threshold: dedupThreshold,
   });
 
-  // Step 1: Sort chunks by similarity score (descending)
-  // This ensures we always keep the highest-scoring chunk
-  const sortedChunks = [...chunks].sort((a, b) => b.similarity - a.similarity);
+  // Step 1: Sort chunks by similarity score (descending, ties by path and line)
+  // This ensures we always keep the highest-scoring chunk, and the same one on every run
+  const sortedChunks = [...chunks].sort(compareRanked((chunk) => chunk.similarity));
 
   // Step 2: Deduplicate by pairwise comparison
   const uniqueChunks: RelevantChunk[] = [];
@@ -246,11 +247,12 @@ export const prioritizeResults = async <T extends CodeChunk>(
     result.priority = await calculatePriority(db, result.item, repoTypeCache);
   }
 
-  // Sort by weighted score (similarity * priority)
+  // Sort by weighted score (similarity * priority), ties by path and line
   const prioritized = results.sort((a, b) => {
     const scoreA = a.similarity * a.priority;
     const scoreB = b.similarity * b.priority;
-    return scoreB - scoreA; // Descending order
+    if (scoreA !== scoreB) return scoreB - scoreA; // Descending order
+    return compareStrings(a.item.file_path, b.item.file_path) || a.item.start_line - b.item.start_line;
   });
 
   return prioritized;
@@ -6,8 +6,8 @@
  */
 import type pg from 'pg';
 
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import {
   type DocChunkType,
   type DocumentationSearchResult,
@@ -86,14 +86,14 @@ interface DocumentationFileRow {
  * Search documentation using vector similarity
  *
  * @param pool - Database connection pool
- * @param ollamaClient - Ollama client for query embedding
+ * @param embedder - Embedder for the query embedding
  * @param embeddingConfig - Embedding configuration
  * @param input - Search parameters
  * @returns Search results with relevance scores
  */
 export const searchDocumentation = async (
   pool: pg.Pool,
-  ollamaClient: OllamaClient,
+  embedder: Embedder,
   embeddingConfig: { model: string; dimensions: number; context_window?: number },
   input: SearchDocumentationInput
 ): Promise<SearchDocumentationOutput> => {
@@ -116,7 +116,7 @@ export const searchDocumentation = async (
   });
 
   // Generate query embedding
-  const queryEmbedding = await ollamaClient.generateEmbedding(
+  const queryEmbedding = await embedder.generateEmbedding(
     embeddingConfig.model,
     input.query,
     embeddingConfig.dimensions,
@@ -223,14 +223,14 @@ interface ReferenceCodeRow {
  * Search references (documentation + reference repo code)
  *
  * @param pool - Database connection pool
- * @param ollamaClient - Ollama client for query embedding
+ * @param embedder - Embedder for the query embedding
  * @param embeddingConfig - Embedding configuration
  * @param input - Search parameters
  * @returns Combined search results from docs and reference repos
  */
 export const searchReferences = async (
   pool: pg.Pool,
-  ollamaClient: OllamaClient,
+  embedder: Embedder,
   embeddingConfig: { model: string; dimensions: number; context_window?: number },
   input: SearchReferencesInput
 ): Promise<SearchReferencesOutput> => {
@@ -255,7 +255,7 @@ export const searchReferences = async (
   });
 
   // Generate query embedding
-  const queryEmbedding = await ollamaClient.generateEmbedding(
+  const queryEmbedding = await embedder.generateEmbedding(
     embeddingConfig.model,
     input.query,
     embeddingConfig.dimensions,
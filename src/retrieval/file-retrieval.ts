@@ -10,6 +10,7 @@ import { type DatabaseClient } from '@database/client';
 import { type ScopeFilter } from '@retrieval/scope-filter';
 import { buildFileLevelHybridSql, getHybridConfig, sanitizeQueryForFts } from '@utils/hybrid-search';
 import { logger } from '@utils/logger';
+import { compareRanked } from '@utils/ordering';
 import { type CindexConfig } from '@/types/config';
 import { type QueryEmbedding, type RelevantFile } from '@/types/retrieval';
 
@@ -53,6 +54,7 @@ interface FileRetrievalRow {
  * @param scopeFilter - Scope filter from Stage 0 (optional, null for single-repo mode)
  * @param maxFiles - Maximum files to return (default: 15)
  * @param similarityThreshold - Minimum similarity score (default: 0.70)
+ * @param includeGenerated - Include files tagged generated (default: false)
  * @returns Array of relevant files ranked by similarity (includes metadata like imports, exports, line count)
  * @throws Error if database query fails or embedding dimensions mismatch
  */
@@ -62,7 +64,8 @@ export const retrieveFiles = async (
   db: DatabaseClient,
   scopeFilter: ScopeFilter | null = null,
   maxFiles = 15,
-  similarityThreshold?: number
+  similarityThreshold?: number,
+  includeGenerated = false
 ): Promise<RelevantFile[]> => {
   const startTime = Date.now();
 
@@ -97,6 +100,11 @@ export const retrieveFiles = async (
   // Build WHERE clauses for scope filtering
   const whereClauses: string[] = [hybridSql.whereCondition];
 
+  // Generated files are indexed for explicit requests only (chunks follow the Stage 1 files)
+  if (!includeGenerated) {
+    whereClauses.push('NOT generated');
+  }
+
   // Apply scope filtering if provided (multi-project mode)
   // Filter by repository IDs (from Stage 0)
   if (scopeFilter && scopeFilter.repo_ids.length > 0) {
@@ -168,6 +176,9 @@ export const retrieveFiles = async (
       repo_id: row.repo_id ?? undefined,
     }));
 
+    // Stable order for equal scores (vector-only ORDER BY has no tie-breaker)
+    files.sort(compareRanked((file) => file.similarity));
+
     const retrievalTime = Date.now() - startTime;
 
     logger.info('File-level retrieval complete', {
@@ -248,6 +259,7 @@ export const retrieveFilesFiltered = async (
     FROM code_files
     WHERE (${hybridSql.whereCondition})
       AND repo_id = ANY($5::text[])
+      AND NOT generated
     ORDER BY ${hybridSql.orderBy}
     LIMIT $4
   `;
@@ -271,6 +283,9 @@ export const retrieveFilesFiltered = async (
       repo_id: row.repo_id ?? undefined,
     }));
 
+    // Stable order for equal scores (vector-only ORDER BY has no tie-breaker)
+    files.sort(compareRanked((file) => file.similarity));
+
     const retrievalTime = Date.now() - startTime;
 
     logger.info('Filtered file-level retrieval complete', {
@@ -0,0 +1,217 @@
+/**
+ * Fuzzy symbol search (cindex search --fuzzy)
+ *
+ * A query matches a symbol name when its characters appear in the name in
+ * order, ignoring case: NAS matches NewAuthService, nas matches
+ * newAuthSession. The best alignment is scored so the intended symbol ranks
+ * first: characters at word starts (camelCase humps, after _ or .) and runs
+ * of consecutive characters score high, skipped characters cost a little.
+ *
+ * The database narrows candidates with the query as a subsequence LIKE
+ * pattern; each candidate's match score is then weighted by its scope, its
+ * kind, and how close its file is to a path (the working directory).
+ */
+
+import { type Pool } from 'pg';
+
+import { searchSymbols, type SymbolSearchOptions } from '@database/queries';
+import { compareStrings } from '@utils/ordering';
+import { normalizeUnicode } from '@utils/unicode';
+import { type ResolvedSymbol } from '@/types/retrieval';
+
+/** Candidates fetched from the database before ranking */
+const FUZZY_CANDIDATES = 2000;
+
+/** Score of each matched character */
+const MATCH = 1;
+
+/** Bonus of a character starting a word: the name, a camelCase hump, after _ . - or a digit run */
+const BOUNDARY = 8;
+
+/** Bonus of a character matched right after the previous one */
+const CONSECUTIVE = 4;
+
+/** Bonus of a character matched in the same case as typed */
+const SAME_CASE = 1;
+
+/** Cost of each name character skipped between matches */
+const GAP = 1;
+
+/** Cost of each character before the first match (up to LEADING_GAP_MAX) */
+const LEADING_GAP = 1;
+const LEADING_GAP_MAX = 3;
+
+/** Bonus of a name equal to the query, or starting with it (ignoring case) */
+const EXACT = 20;
+const PREFIX = 10;
+
+/** Weight of exported symbols (public API is looked up more often) */
+const EXPORTED = 6;
+
+/** Weight of each kind: declarations first, tests and examples last */
+const KIND_WEIGHTS: Record<ResolvedSymbol['symbol_type'], number> = {
+  class: 4,
+  interface: 4,
+  type: 4,
+  function: 4,
+  method: 3,
+  constant: 1,
+  variable: 1,
+  field: 1,
+  test: 0,
+  benchmark: 0,
+  fuzz: 0,
+  example: 0,
+};
+
+/** Weight of each directory a file shares with the path it is ranked near, and the most it adds */
+const PROXIMITY = 2;
+const PROXIMITY_MAX = 8;
+
+/**
+ * Symbol with its fuzzy ranking score
+ */
+export interface RankedSymbol extends ResolvedSymbol {
+  score: number;
+}
+
+/**
+ * Options of a fuzzy symbol search
+ */
+export interface FuzzySearchOptions extends Omit<SymbolSearchOptions, 'limit'> {
+  /** Path (relative to the repository root) whose neighborhood ranks first */
+  near?: string;
+  /** Most symbols returned */
+  limit?: number;
+}
+
+/** Character classes of camelCase and digit-run boundaries (letters without case are neither upper nor lower) */
+const isUpper = (char: string): boolean => char !== char.toLowerCase() && char === char.toUpperCase();
+const isLower = (char: string): boolean => char !== char.toUpperCase() && char === char.toLowerCase();
+const isDigit = (char: string): boolean => char >= '0' && char <= '9';
+
+/**
+ * Check whether a name character starts a word
+ *
+ * newAuthService: n, A, S; HTTPServer: H, S; parse_v2_header: p, v, 2, h
+ */
+const isBoundary = (name: string, index: number): boolean => {
+  if (index === 0) return true;
+  const char = name[index];
+  const previous = name[index - 1];
+  if (previous === '_' || previous === '.' || previous === '-' || previous === '$') return true;
+  if (isUpper(char)) return !isUpper(previous) || (index + 1 < name.length && isLower(name[index + 1]));
+  return isDigit(char) !== isDigit(previous) && char !== '_';
+};
+
+/**
+ * Score how well a query matches a name as a subsequence
+ *
+ * @param query - Characters typed
+ * @param name - Symbol name
+ * @returns Score of the best alignment (higher is better), or null if the query is not a subsequence of the name
+ */
+export const fuzzyScore = (query: string, name: string): number | null => {
+  const typed = [...normalizeUnicode(query)].filter((char) => char.trim() !== '');
+  const chars = [...normalizeUnicode(name)];
+  if (typed.length === 0 || typed.length > chars.length) return null;
+
+  const folded = chars.map((char) => char.toLowerCase());
+  const joined = chars.join('');
+  const character = (i: number, j: number): number => {
+    if (folded[j] !== typed[i].toLowerCase()) return Number.NEGATIVE_INFINITY;
+    return MATCH + (isBoundary(joined, j) ? BOUNDARY : 0) + (chars[j] === typed[i] ? SAME_CASE : 0);
+  };
+
+  // best[j]: best score with the current query character matched at name character j
+  let best = chars.map((_, j) => character(0, j) - LEADING_GAP * Math.min(j, LEADING_GAP_MAX));
+  for (let i = 1; i < typed.length; i++) {
+    const next = chars.map(() => Number.NEGATIVE_INFINITY);
+    // Best previous match at least one character back, with the gap up to j charged
+    let gapped = Number.NEGATIVE_INFINITY;
+    for (let j = i; j < chars.length; j++) {
+      if (j >= 2) gapped = Math.max(gapped, best[j - 2] + GAP * (j - 1));
+      const score = character(i, j);
+      if (score === Number.NEGATIVE_INFINITY) continue;
+      next[j] = score + Math.max(best[j - 1] + CONSECUTIVE, gapped - GAP * j);
+    }
+    best = next;
+  }
+
+  const score = Math.max(...best);
+  if (score === Number.NEGATIVE_INFINITY) return null;
+  const lowerQuery = typed.join('').toLowerCase();
+  const lowerName = folded.join('');
+  if (lowerName === lowerQuery) return score + EXACT;
+  return lowerName.startsWith(lowerQuery) ? score + PREFIX : score;
+};
+
+/**
+ * LIKE pattern matching names that hold the query as a subsequence
+ *
+ * NAS → N%A%S; LIKE wildcards in the query are matched literally.
+ */
+export const subsequencePattern = (query: string): string =>
+  [...normalizeUnicode(query)]
+    .filter((char) => char.trim() !== '')
+    .map((char) => char.replace(/[\\%_]/g, '\\$&'))
+    .join('%');
+
+/**
+ * Weight of a file's closeness to a path: shared leading directories
+ *
+ * @param filePath - Path of the symbol's file
+ * @param near - Path ranked near (a directory or file)
+ */
+const proximity = (filePath: string, near: string): number => {
+  const fileDirs = filePath.split('/').slice(0, -1);
+  const nearDirs = near.split('/').filter((part) => part !== '' && part !== '.');
+  let shared = 0;
+  while (shared < fileDirs.length && shared < nearDirs.length && fileDirs[shared] === nearDirs[shared]) shared++;
+  return Math.min(shared * PROXIMITY, PROXIMITY_MAX);
+};
+
+/**
+ * Rank symbols by how well their names match a query
+ *
+ * @param symbols - Candidates
+ * @param query - Characters typed
+ * @param near - Path whose neighborhood ranks first
+ * @returns Matching symbols, best first (ties: shorter name, then path and line)
+ */
+export const rankSymbols = (symbols: ResolvedSymbol[], query: string, near?: string): RankedSymbol[] => {
+  const ranked: RankedSymbol[] = [];
+  for (const symbol of symbols) {
+    const match = fuzzyScore(query, symbol.symbol_name);
+    if (match === null) continue;
+    const scope = symbol.scope === 'exported' ? EXPORTED : 0;
+    const nearby = near ? proximity(symbol.file_path, near) : 0;
+    ranked.push({ ...symbol, score: match + scope + KIND_WEIGHTS[symbol.symbol_type] + nearby });
+  }
+  return ranked.sort(
+    (a, b) =>
+      b.score - a.score ||
+      a.symbol_name.length - b.symbol_name.length ||
+      compareStrings(a.file_path, b.file_path) ||
+      a.line_number - b.line_number
+  );
+};
+
+/**
+ * Search symbols by fuzzy name match, ranked
+ *
+ * @param db - Database connection pool
+ * @param query - Characters typed (NAS, newauthsvc)
+ * @param options - Symbol search filters, near, and limit (default 50)
+ * @returns Matching symbols, best first
+ * @throws {DatabaseQueryError} If query execution fails
+ */
+export const searchSymbolsFuzzy = async (
+  db: Pool,
+  query: string,
+  options: FuzzySearchOptions = {}
+): Promise<RankedSymbol[]> => {
+  const { near, limit = 50, ...filters } = options;
+  const candidates = await searchSymbols(db, subsequencePattern(query), { ...filters, limit: FUZZY_CANDIDATES });
+  return rankSymbols(candidates, query, near).slice(0, limit);
+};
@@ -0,0 +1,321 @@
+/**
+ * gopls-assisted Go resolution (cindex refs, cindex def)
+ *
+ * Typed indexing (cindex index --typed) records definitions and references as
+ * of its last run. Files edited since then have moved on: their positions are
+ * stale and their new uses are missing. Resolution in those files is
+ * delegated to gopls, while the rest of the repository is still served from
+ * the index, so a query stays as cheap as one gopls request.
+ *
+ * gopls is started with -remote=auto unless told otherwise: it connects to
+ * the shared gopls daemon (starting one if needed) that editors launched with
+ * the same flag use, reusing its warm package cache. gopls reads files from
+ * disk, so edits not yet saved in the editor are not seen.
+ */
+
+import { execFile } from 'node:child_process';
+import * as fs from 'node:fs/promises';
+import * as path from 'node:path';
+import { promisify } from 'node:util';
+
+import { computeContentHash } from '@indexing/file-walker';
+import { readSourceFile } from '@utils/edge-cases';
+import { compareStrings } from '@utils/ordering';
+import { toPosixPath } from '@utils/paths';
+import { utf16ToByteColumn } from '@utils/positions';
+import { IDENTIFIER_CHAR_PATTERN } from '@utils/unicode';
+import { type GoReferenceRecord, type IndexedFileVersionRecord } from '@/types/database';
+
+const execFileAsync = promisify(execFile);
+
+/** gopls -remote value used by default: the shared daemon */
+export const GOPLS_DEFAULT_REMOTE = 'auto';
+
+/** Longest a gopls request may take (a cold daemon loads the workspace first) */
+const GOPLS_TIMEOUT_MS = 120_000;
+
+/** Location printed by gopls: path:line:column, optionally followed by -end and a description */
+const GOPLS_LOCATION = /^(.+\.go):(\d+):(\d+)(?:-\d+(?::\d+)?)?(?::\s*(.*))?$/;
+
+/**
+ * Position in a Go file (1-based line, 1-based byte column)
+ */
+export interface GoplsLocation {
+  /** Path relative to the repository root */
+  file_path: string;
+  line: number;
+  column: number;
+}
+
+/**
+ * Where a resolution came from
+ */
+export type ResolutionSource = 'index' | 'gopls';
+
+/**
+ * Reference from the index or from gopls
+ */
+export type SourcedReference = GoReferenceRecord & { source: ResolutionSource };
+
+/**
+ * Parse the locations gopls prints (references, definition)
+ *
+ * @param output - gopls standard output
+ * @param repoPath - Repository root the paths are made relative to
+ * @returns Locations inside the repository, with the text after each location
+ */
+export const parseGoplsLocations = (
+  output: string,
+  repoPath: string
+): (GoplsLocation & { description: string | null })[] => {
+  const locations: (GoplsLocation & { description: string | null })[] = [];
+  for (const line of output.split(/\r?\n/)) {
+    const match = GOPLS_LOCATION.exec(line.trim());
+    if (!match) continue;
+    const relative = path.relative(repoPath, match[1]);
+    if (relative.startsWith('..') || path.isAbsolute(relative)) continue;
+    locations.push({
+      file_path: toPosixPath(relative),
+      line: Number(match[2]),
+      column: Number(match[3]),
+      description: match[4] || null,
+    });
+  }
+  return locations;
+};
+
+/**
+ * Find the byte column of a declared name on its declaration line
+ *
+ * Methods are looked for after their receiver, so a receiver named like the
+ * method is not taken for it.
+ *
+ * @param lineText - Declaration line
+ * @param name - Declared name (Login, or Receiver.Method)
+ * @returns 1-based byte column, or null if the name is not on the line
+ */
+export const declarationColumn = (lineText: string, name: string): number | null => {
+  const identifier = name.slice(name.lastIndexOf('.') + 1);
+  const receiver = /^\s*func\s*\(/.exec(lineText);
+  const from = receiver ? lineText.indexOf(')', receiver[0].length) + 1 : 0;
+  const pattern = new RegExp(`(?<!${IDENTIFIER_CHAR_PATTERN})${identifier}(?!${IDENTIFIER_CHAR_PATTERN})`, 'gu');
+  pattern.lastIndex = from;
+  const match = pattern.exec(lineText);
+  return match ? utf16ToByteColumn(lineText, match.index + 1) : null;
+};
+
+/**
+ * Find the identifier covering a byte column
+ *
+ * @param lineText - Line text
+ * @param column - 1-based byte column
+ * @returns Identifier, or null if the column is not on one
+ */
+export const identifierAt = (lineText: string, column: number): string | null => {
+  for (const match of lineText.matchAll(new RegExp(`${IDENTIFIER_CHAR_PATTERN}+`, 'gu'))) {
+    const start = utf16ToByteColumn(lineText, match.index + 1);
+    if (column >= start && column < start + Buffer.byteLength(match[0], 'utf8')) return match[0];
+  }
+  return null;
+};
+
+/**
+ * Find indexed files whose content changed since they were indexed
+ *
+ * Files not modified since the index recorded them are not read.
+ *
+ * @param repoPath - Repository root
+ * @param versions - Indexed files with their content hashes
+ * @returns Paths (relative to the root) of edited and deleted files
+ */
+export const findEditedFiles = async (repoPath: string, versions: IndexedFileVersionRecord[]): Promise<Set<string>> => {
+  const edited = new Set<string>();
+  for (const version of versions) {
+    const absolute = path.join(repoPath, version.file_path);
+    try {
+      const stats = await fs.stat(absolute);
+      if (version.last_modified && stats.mtime <= version.last_modified) continue;
+      if (computeContentHash((await readSourceFile(absolute)).content) !== version.file_hash) {
+        edited.add(version.file_path);
+      }
+    } catch {
+      edited.add(version.file_path);
+    }
+  }
+  return edited;
+};
+
+/**
+ * Combine index and gopls references
+ *
+ * Each file is served by one source: gopls for files edited since indexing
+ * or not in the index, the index for the rest.
+ *
+ * @param indexed - References from the index
+ * @param fromGopls - References gopls resolved on the current files
+ * @param edited - Files edited since indexing
+ * @param indexedFiles - Files in the index
+ * @returns References ordered by file and position
+ */
+export const mergeReferences = (
+  indexed: GoReferenceRecord[],
+  fromGopls: GoReferenceRecord[],
+  edited: Set<string>,
+  indexedFiles: Set<string>
+): SourcedReference[] => {
+  const merged: SourcedReference[] = indexed
+    .filter((ref) => !edited.has(ref.file_path))
+    .map((ref): SourcedReference => ({ ...ref, source: 'index' }));
+
+  const seen = new Set<string>();
+  for (const ref of fromGopls) {
+    if (indexedFiles.has(ref.file_path) && !edited.has(ref.file_path)) continue;
+    const key = `${ref.file_path}:${String(ref.line_number)}:${String(ref.column_number)}`;
+    if (seen.has(key)) continue;
+    seen.add(key);
+    merged.push({ ...ref, source: 'gopls' });
+  }
+
+  return merged.sort(
+    (a, b) =>
+      compareStrings(a.repo_id ?? '', b.repo_id ?? '') ||
+      compareStrings(a.file_path, b.file_path) ||
+      a.line_number - b.line_number ||
+      a.column_number - b.column_number
+  );
+};
+
+/**
+ * Run a gopls subcommand against a repository
+ *
+ * @param repoPath - Repository root (the gopls workspace)
+ * @param args - Subcommand and its arguments
+ * @param remote - gopls -remote value ('' runs gopls in-process)
+ * @returns Standard output
+ * @throws {Error} If gopls is not installed or the request fails
+ */
+const runGopls = async (repoPath: string, args: string[], remote: string): Promise<string> => {
+  const flags = remote ? [`-remote=${remote}`] : [];
+  try {
+    const { stdout } = await execFileAsync('gopls', [...flags, ...args], {
+      cwd: repoPath,
+      timeout: GOPLS_TIMEOUT_MS,
+      maxBuffer: 64 * 1024 * 1024,
+    });
+    return stdout;
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+      throw new Error('gopls is not on PATH (go install golang.org/x/tools/gopls@latest)');
+    }
+    const stderr = (error as { stderr?: unknown }).stderr;
+    const detail = typeof stderr === 'string' && stderr.trim() !== '' ? stderr.trim() : String(error);
+    throw new Error(`gopls ${args[0]} failed: ${detail}`);
+  }
+};
+
+/**
+ * Position argument gopls takes (absolute path:line:column)
+ */
+const goplsPosition = (repoPath: string, location: GoplsLocation): string => {
+  return `${path.join(repoPath, location.file_path)}:${String(location.line)}:${String(location.column)}`;
+};
+
+/**
+ * Resolve the uses of the identifier at a position with gopls
+ *
+ * @param repoPath - Repository root
+ * @param location - Position of the declared name
+ * @param remote - gopls -remote value
+ * @returns Uses inside the repository, the declaration itself excluded
+ * @throws {Error} If gopls is not installed or the request fails
+ */
+export const goplsReferences = async (
+  repoPath: string,
+  location: GoplsLocation,
+  remote: string
+): Promise<GoplsLocation[]> => {
+  const output = await runGopls(repoPath, ['references', goplsPosition(repoPath, location)], remote);
+  return parseGoplsLocations(output, repoPath).map(({ file_path, line, column }) => ({ file_path, line, column }));
+};
+
+/**
+ * Resolve the declaration of the identifier at a position with gopls
+ *
+ * @param repoPath - Repository root
+ * @param location - Position of a use
+ * @param remote - gopls -remote value
+ * @returns Declaration and the description gopls gives it, or null if it is outside the repository
+ * @throws {Error} If gopls is not installed or the request fails (e.g. no identifier at the position)
+ */
+export const goplsDefinition = async (
+  repoPath: string,
+  location: GoplsLocation,
+  remote: string
+): Promise<(GoplsLocation & { description: string | null }) | null> => {
+  const output = await runGopls(repoPath, ['definition', goplsPosition(repoPath, location)], remote);
+  const locations = parseGoplsLocations(output, repoPath);
+  if (locations.length === 0) return null;
+  // gopls describes the declaration as "defined here as func Login(...) error"
+  const [definition] = locations;
+  return { ...definition, description: definition.description?.replace(/^defined here as /, '') ?? null };
+};
+
+/**
+ * Resolve references with gopls in the files edited since indexing
+ *
+ * gopls is asked for the uses of each declaration the index references point
+ * to, from the declaration's current line. If gopls fails, or a declaration
+ * is no longer on its indexed line, every file is served from the index and
+ * the error is returned with it.
+ *
+ * @param repoPath - Repository root
+ * @param references - References from the index
+ * @param versions - Indexed Go files with their content hashes
+ * @param remote - gopls -remote value
+ * @returns References, the number of edited files, and why gopls was not used
+ */
+export const resolveReferences = async (
+  repoPath: string,
+  references: GoReferenceRecord[],
+  versions: IndexedFileVersionRecord[],
+  remote: string
+): Promise<{ references: SourcedReference[]; edited: number; error: string | null }> => {
+  const fromIndex = references.map((ref): SourcedReference => ({ ...ref, source: 'index' }));
+  const edited = await findEditedFiles(repoPath, versions);
+  if (edited.size === 0 || references.length === 0) return { references: fromIndex, edited: edited.size, error: null };
+
+  const targets = new Map<string, GoReferenceRecord>();
+  for (const ref of references) targets.set(`${ref.target_file}:${String(ref.target_line)}:${ref.target_name}`, ref);
+
+  const fromGopls: GoReferenceRecord[] = [];
+  try {
+    for (const target of targets.values()) {
+      const lines = (await readSourceFile(path.join(repoPath, target.target_file))).content.split(/\r?\n/);
+      const column = declarationColumn(lines.at(target.target_line - 1) ?? '', target.target_name);
+      if (column === null) {
+        throw new Error(`${target.target_name} moved in ${target.target_file} since indexing; re-index with --typed`);
+      }
+      const uses = await goplsReferences(
+        repoPath,
+        { file_path: target.target_file, line: target.target_line, column },
+        remote
+      );
+      for (const use of uses) {
+        fromGopls.push({
+          ...target,
+          file_path: use.file_path,
+          line_number: use.line,
+          column_number: use.column,
+          access: null,
+          symbol_name: null,
+        });
+      }
+    }
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    return { references: fromIndex, edited: edited.size, error: message };
+  }
+
+  const indexedFiles = new Set(versions.map((version) => version.file_path));
+  return { references: mergeReferences(references, fromGopls, edited, indexedFiles), edited: edited.size, error: null };
+};
@@ -0,0 +1,218 @@
+/**
+ * Hierarchical file outlines (get_file_outline, textDocument/documentSymbol)
+ *
+ * The declarations of a file as a tree under its package or module:
+ *
+ *   package auth
+ *   ├── SessionTimeout              constant
+ *   ├── UserRole                    type int
+ *   │   ├── RoleUser                constant UserRole
+ *   │   └── RoleAdmin               constant UserRole
+ *   └── AuthService                 class
+ *       ├── db                      field *sql.DB
+ *       ├── Login                   method
+ *       └── queryUser               method
+ *
+ * Declarations nest by span (methods in a class body, functions declared in
+ * functions). Go declares methods outside their type, so a method is placed
+ * under its receiver type, and constants and variables of a type declared in
+ * the same file under that type, as go doc groups them.
+ */
+
+import * as path from 'node:path';
+
+import { parseCode } from '@indexing/parser';
+import { Language, NodeType, type ParsedNode } from '@/types/indexing';
+
+/**
+ * Kind of an outline entry
+ */
+export type OutlineKind =
+  | 'package'
+  | 'module'
+  | 'class'
+  | 'interface'
+  | 'type'
+  | 'function'
+  | 'method'
+  | 'field'
+  | 'constant'
+  | 'variable';
+
+/**
+ * Declaration in a file outline
+ */
+export interface OutlineNode {
+  name: string;
+  kind: OutlineKind;
+  /** 1-based, inclusive */
+  start_line: number;
+  end_line: number;
+  /** Signature of functions and methods, declared type of types, fields, constants, and variables */
+  detail?: string;
+  /** Nested declarations, in line order */
+  children: OutlineNode[];
+}
+
+/** Outline kinds of the parsed node types shown (imports, exports, and top-level blocks are not) */
+const NODE_KINDS: Partial<Record<NodeType, OutlineKind>> = {
+  [NodeType.Class]: 'class',
+  [NodeType.Interface]: 'interface',
+  [NodeType.Type]: 'type',
+  [NodeType.Function]: 'function',
+  [NodeType.Method]: 'method',
+  [NodeType.Field]: 'field',
+  [NodeType.Constant]: 'constant',
+  [NodeType.Variable]: 'variable',
+};
+
+/** Kinds declaring members */
+const CONTAINER_KINDS = new Set<OutlineKind>(['class', 'interface', 'type']);
+
+/** Package or namespace declaration, by language (other files are outlined as modules) */
+const PACKAGE_PATTERNS: Partial<Record<Language, RegExp>> = {
+  [Language.Go]: /^package\s+([\p{L}_][\p{L}\p{N}_]*)/mu,
+  [Language.Java]: /^\s*package\s+([\w.]+)/m,
+  [Language.Kotlin]: /^\s*package\s+([\w.]+)/m,
+  [Language.CSharp]: /^\s*namespace\s+([\w.]+)/m,
+  [Language.PHP]: /^\s*namespace\s+([\w\\]+)/m,
+};
+
+/**
+ * Entry of the outline, with the receiver or type it belongs to in Go
+ */
+interface Entry {
+  node: OutlineNode;
+  owner?: string;
+}
+
+/**
+ * First line of a declaration, without its opening brace (func (s *AuthService) Login(email string) error)
+ */
+const signature = (codeText: string): string => codeText.split('\n')[0].replace(/\s*[{:]\s*$/, '').trim();
+
+/**
+ * Check whether one entry's lines enclose another's
+ *
+ * Equal spans nest only a member in a container (class A { m() {} } on one line).
+ */
+const encloses = (outer: OutlineNode, inner: OutlineNode): boolean => {
+  if (outer.start_line > inner.start_line || outer.end_line < inner.end_line) return false;
+  if (outer.start_line < inner.start_line || outer.end_line > inner.end_line) return true;
+  return CONTAINER_KINDS.has(outer.kind) && !CONTAINER_KINDS.has(inner.kind);
+};
+
+/**
+ * Order entries by line, enclosing entries first
+ */
+const byPosition = (a: OutlineNode, b: OutlineNode): number =>
+  a.start_line - b.start_line ||
+  b.end_line - a.end_line ||
+  Number(CONTAINER_KINDS.has(b.kind)) - Number(CONTAINER_KINDS.has(a.kind));
+
+/**
+ * Outline entries of parsed nodes and their children, each declaration once
+ *
+ * Class methods are reported both flat, as functions, and as children of
+ * their class; the method wins. Anonymous functions are left out.
+ */
+const toEntries = (nodes: ParsedNode[], language: Language): Entry[] => {
+  const entries = new Map<string, Entry>();
+  const visit = (node: ParsedNode): void => {
+    const kind = NODE_KINDS[node.node_type];
+    const key = `${node.name}\0${String(node.start_line)}\0${String(node.end_line)}`;
+    const seen = entries.get(key);
+    if (seen?.node.kind === 'function' && kind === 'method') {
+      seen.node.kind = kind;
+    } else if (kind && !seen && node.name !== '<anonymous>') {
+      const callable = kind === 'function' || kind === 'method';
+      const detail = callable ? signature(node.code_text) : node.return_type;
+      // Go: methods belong to their receiver, typed constants and variables to their type
+      const value = kind === 'constant' || kind === 'variable';
+      let owner: string | undefined;
+      if (language === Language.Go) owner = kind === 'method' ? node.receiver : value ? detail : undefined;
+      const { name, start_line, end_line } = node;
+      entries.set(key, { node: { name, kind, start_line, end_line, detail, children: [] }, owner });
+    }
+    for (const child of node.children ?? []) visit(child);
+  };
+  for (const node of nodes) visit(node);
+  return [...entries.values()].sort((a, b) => byPosition(a.node, b.node));
+};
+
+/**
+ * Name and kind of the outline's root: the declared package or namespace, else the file as a module
+ */
+const outlineRoot = (code: string, filePath: string, language: Language): OutlineNode => {
+  const declared = PACKAGE_PATTERNS[language]?.exec(code)?.[1];
+  return {
+    name: declared ?? path.posix.basename(filePath),
+    kind: declared ? 'package' : 'module',
+    start_line: 1,
+    end_line: Math.max(1, code.split('\n').length),
+    children: [],
+  };
+};
+
+/**
+ * Build the outline of a parsed file
+ *
+ * @param nodes - Parsed declarations of the file (ParseResult.nodes)
+ * @param code - File content the nodes were parsed from
+ * @param filePath - Path of the file (names the root of files without a package)
+ * @param language - Language of the file
+ * @returns Root package or module, declarations nested beneath
+ */
+export const buildOutline = (
+  nodes: ParsedNode[],
+  code: string,
+  filePath: string,
+  language: Language
+): OutlineNode => {
+  const root = outlineRoot(code, filePath, language);
+  const entries = toEntries(nodes, language);
+
+  // Nest by span: each entry goes into the innermost open entry enclosing it
+  const open: OutlineNode[] = [];
+  const topLevel: Entry[] = [];
+  for (const entry of entries) {
+    let parent = open.at(-1);
+    while (parent && !encloses(parent, entry.node)) {
+      open.pop();
+      parent = open.at(-1);
+    }
+    if (parent) parent.children.push(entry.node);
+    else topLevel.push(entry);
+    open.push(entry.node);
+  }
+
+  // Go: top-level methods, constants, and variables move under a type of this file
+  const types = new Map(
+    topLevel.filter((entry) => CONTAINER_KINDS.has(entry.node.kind)).map((entry) => [entry.node.name, entry.node])
+  );
+  for (const entry of topLevel) {
+    const owner = entry.owner ? types.get(entry.owner) : undefined;
+    if (owner) owner.children.push(entry.node);
+    else root.children.push(entry.node);
+  }
+  for (const type of types.values()) type.children.sort(byPosition);
+
+  return root;
+};
+
+/**
+ * Parse a file and build its outline
+ *
+ * @param code - File content
+ * @param filePath - Path of the file
+ * @param language - Language of the file
+ * @returns Root package or module, declarations nested beneath
+ */
+export const outlineFile = (code: string, filePath: string, language: Language): OutlineNode =>
+  buildOutline(parseCode(code, language, filePath).nodes, code, filePath, language);
+
+/**
+ * Number of declarations in an outline, the root excluded
+ */
+export const countOutline = (node: OutlineNode): number =>
+  node.children.reduce((total, child) => total + 1 + countOutline(child), 0);
@@ -6,8 +6,8 @@
  */
 
 import { queryEmbeddingCache } from '@utils/cache';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import { type CindexConfig } from '@/types/config';
 import { type QueryEmbedding, type QueryType } from '@/types/retrieval';
 
@@ -288,7 +288,7 @@ const enhanceQueryForChunkSearch = (query: string): string => {
  * Generate query embedding with caching
  *
  * Converts user query into a 1024-dimensional vector for semantic search.
- * Caches embeddings for 30 minutes to avoid redundant API calls (saves ~80% of Ollama requests).
+ * Caches embeddings for 30 minutes to avoid redundant API calls (saves ~80% of embedding requests).
  *
  * For natural language queries, generates two embeddings:
  * 1. `embedding` - Raw query embedding (used for file-level search, matches natural language summaries)
@@ -299,14 +299,14 @@ const enhanceQueryForChunkSearch = (query: string): string => {
  *
  * @param query - User query text
  * @param config - cindex configuration
- * @param ollamaClient - Ollama API client
+ * @param embedder - Embedder of the configured provider
  * @returns Query embedding result with query type, embedding vector(s), and generation time
- * @throws Error if embedding generation fails (Ollama connection issues, model not found)
+ * @throws Error if embedding generation fails (embedding service unreachable, model not found)
  */
 export const processQuery = async (
   query: string,
   config: CindexConfig,
-  ollamaClient: OllamaClient
+  embedder: Embedder
 ): Promise<QueryEmbedding> => {
   const startTime = Date.now();
 
@@ -339,7 +339,7 @@ export const processQuery = async (
       dimensions: config.embedding.dimensions,
     });
 
-    embedding = await ollamaClient.generateEmbedding(
+    embedding = await embedder.generateEmbedding(
       config.embedding.model,
       processedQuery,
       config.embedding.dimensions,
@@ -381,7 +381,7 @@ export const processQuery = async (
         queryType,
       });
 
-      chunkEmbedding = await ollamaClient.generateEmbedding(
+      chunkEmbedding = await embedder.generateEmbedding(
         config.embedding.model,
         enhancedQuery,
         config.embedding.dimensions,
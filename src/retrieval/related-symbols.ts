@@ -0,0 +1,240 @@
+/**
+ * Related symbols (cindex related, GET /related/{id})
+ *
+ * Suggests the symbols worth reading next to a given one, for explore panes
+ * and for widening an AI context. Three signals are scored from 0 to 1:
+ *
+ *   cooccurrence     files mentioning both names, over files mentioning
+ *                    either (Jaccard), so names used everywhere score low
+ *   call_graph       half for calling or being called by the symbol, half
+ *                    for the callers and callees the two have in common
+ *                    (Go call graph, see @indexing/go-calls)
+ *   name_similarity  name words and their stems in common, over those of
+ *                    either name (CreateSession and SessionTimeout share one)
+ *
+ * The score is their weighted sum. Signals the symbol has no evidence for (a
+ * function outside the call graph, a name never mentioned elsewhere) are left
+ * out and the other weights scaled up, so scores compare across languages.
+ *
+ * Mentions are whole-word matches of the last element of a name in the
+ * indexed contents; shared mentions are counted in a sample of the symbol's
+ * files and scaled to all of them.
+ */
+
+import { type Pool } from 'pg';
+
+import { countFilesMentioning, listCallNeighbors, listRelatedCandidates, searchFileContents } from '@database/queries';
+import { compileContentPattern, escapeRegex } from '@retrieval/content-search';
+import { compareStrings } from '@utils/ordering';
+import { IDENTIFIER_PATTERN } from '@utils/unicode';
+import { type CallNeighborRecord, type RelatedCandidateRecord } from '@/types/database';
+import { type RelatedSymbol } from '@/types/retrieval';
+
+/** Symbols suggested without a k */
+export const DEFAULT_RELATED = 10;
+
+/** Weights of the signals */
+const COOCCURRENCE_WEIGHT = 0.4;
+const CALL_GRAPH_WEIGHT = 0.35;
+const NAME_WEIGHT = 0.25;
+
+/** Share of call_graph earned by a direct call */
+const ADJACENT = 0.5;
+
+/** Files mentioning the symbol read for shared mentions */
+const MENTION_SAMPLE = 200;
+
+/** Names, by shared mentions, looked up as symbols and counted across the index */
+const MENTIONED_NAMES = 300;
+const COUNTED_NAMES = 100;
+
+/** Call graph neighbors and name matches looked up */
+const NEIGHBOR_NAMES = 300;
+const NAME_MATCHES = 200;
+
+/** Shorter names are too common to count as mentions */
+const MIN_NAME_LENGTH = 3;
+
+/**
+ * What is known about the symbol and the names around it
+ */
+export interface RelatedEvidence {
+  /** Files mentioning the symbol's name */
+  target_files: number;
+  /** Files of those read for shared mentions */
+  sampled_files: number;
+  /** Name (last element) to sampled files mentioning it */
+  shared_files: Map<string, number>;
+  /** Name (last element) to files mentioning it in the index */
+  mention_files: Map<string, number>;
+  /** Distinct callers and callees of the symbol */
+  call_degree: number;
+  /** Full name to call graph neighborhood */
+  neighbors: Map<string, CallNeighborRecord>;
+}
+
+/**
+ * Last element of a dotted name (Login of Service.Login)
+ */
+const lastName = (name: string): string => name.slice(name.lastIndexOf('.') + 1);
+
+/**
+ * Jaccard similarity of two token sets
+ */
+const tokenSimilarity = (a: string[], b: string[]): number => {
+  const left = new Set(a);
+  const right = new Set(b);
+  const shared = [...left].filter((token) => right.has(token)).length;
+  const union = left.size + right.size - shared;
+  return union > 0 ? shared / union : 0;
+};
+
+/**
+ * Score the candidates and keep the best
+ *
+ * @param target - Symbol to suggest related symbols for
+ * @param candidates - Symbols found by any signal (duplicates allowed)
+ * @param evidence - Mentions and call graph around the target
+ * @param k - Most symbols returned
+ * @returns Symbols with a score above 0, best first, then by name and location
+ */
+export const rankRelated = (
+  target: RelatedCandidateRecord,
+  candidates: RelatedCandidateRecord[],
+  evidence: RelatedEvidence,
+  k: number
+): RelatedSymbol[] => {
+  const targetTokens = target.name_tokens ?? [];
+  const weights = [
+    evidence.target_files > 0 ? COOCCURRENCE_WEIGHT : 0,
+    evidence.call_degree > 0 ? CALL_GRAPH_WEIGHT : 0,
+    targetTokens.length > 0 ? NAME_WEIGHT : 0,
+  ];
+  const total = weights.reduce((sum, weight) => sum + weight, 0);
+  if (total === 0) return [];
+  const scale = evidence.sampled_files > 0 ? evidence.target_files / evidence.sampled_files : 0;
+
+  const seen = new Set<string>();
+  const related: RelatedSymbol[] = [];
+  for (const candidate of candidates) {
+    const key = `${candidate.repo_id ?? ''}\0${candidate.file_path}\0${String(candidate.line_number)}`;
+    if (seen.has(key) || candidate.symbol_name === target.symbol_name) continue;
+    seen.add(key);
+
+    const name = lastName(candidate.symbol_name);
+    const mentions = evidence.mention_files.get(name);
+    let cooccurrence = 0;
+    if (mentions !== undefined && name !== lastName(target.symbol_name)) {
+      const shared = Math.min((evidence.shared_files.get(name) ?? 0) * scale, mentions, evidence.target_files);
+      const union = evidence.target_files + mentions - shared;
+      cooccurrence = union > 0 ? shared / union : 0;
+    }
+
+    const neighbor = evidence.neighbors.get(candidate.symbol_name);
+    let callGraph = 0;
+    if (neighbor) {
+      // Neither counts the other as a neighbor in common
+      const others = evidence.call_degree + neighbor.degree - neighbor.shared - (neighbor.adjacent ? 2 : 0);
+      callGraph = (neighbor.adjacent ? ADJACENT : 0) + (1 - ADJACENT) * (others > 0 ? neighbor.shared / others : 0);
+    }
+
+    const nameSimilarity = tokenSimilarity(targetTokens, candidate.name_tokens ?? []);
+    const score = (weights[0] * cooccurrence + weights[1] * callGraph + weights[2] * nameSimilarity) / total;
+    if (score <= 0) continue;
+    related.push({
+      repo_id: candidate.repo_id,
+      symbol_name: candidate.symbol_name,
+      symbol_type: candidate.symbol_type,
+      file_path: candidate.file_path,
+      line_number: candidate.line_number,
+      symbol_id: candidate.symbol_id,
+      score,
+      cooccurrence,
+      call_graph: callGraph,
+      name_similarity: nameSimilarity,
+    });
+  }
+
+  return related
+    .sort(
+      (a, b) =>
+        b.score - a.score ||
+        compareStrings(a.symbol_name, b.symbol_name) ||
+        compareStrings(a.file_path, b.file_path) ||
+        a.line_number - b.line_number
+    )
+    .slice(0, k);
+};
+
+/**
+ * Count the sampled files mentioning each identifier
+ */
+const countMentions = (contents: string[]): Map<string, number> => {
+  const identifier = new RegExp(IDENTIFIER_PATTERN, 'gu');
+  const counts = new Map<string, number>();
+  for (const content of contents) {
+    for (const name of new Set(content.match(identifier))) {
+      if (name.length >= MIN_NAME_LENGTH) counts.set(name, (counts.get(name) ?? 0) + 1);
+    }
+  }
+  return counts;
+};
+
+/**
+ * Suggest symbols related to one
+ *
+ * @param db - Database connection pool
+ * @param target - Indexed symbol (see listRelatedCandidates)
+ * @param k - Most symbols returned
+ * @returns Related symbols of the target's index, best first
+ */
+export const findRelatedSymbols = async (
+  db: Pool,
+  target: RelatedCandidateRecord,
+  k = DEFAULT_RELATED
+): Promise<RelatedSymbol[]> => {
+  const repoId = target.repo_id ?? undefined;
+  const name = lastName(target.symbol_name);
+
+  // Names mentioned in the files mentioning the target
+  const { postgres } = compileContentPattern(`\\b${escapeRegex(name)}\\b`);
+  const sample = await searchFileContents(db, postgres, { repoId, limit: MENTION_SAMPLE });
+  const targetFiles =
+    sample.length < MENTION_SAMPLE
+      ? sample.length
+      : ((await countFilesMentioning(db, [name], repoId)).get(name) ?? sample.length);
+  const sharedFiles = countMentions(sample.map((file) => file.content));
+  sharedFiles.delete(name);
+  const mentioned = [...sharedFiles.keys()]
+    .sort((a, b) => (sharedFiles.get(b) ?? 0) - (sharedFiles.get(a) ?? 0) || compareStrings(a, b))
+    .slice(0, MENTIONED_NAMES);
+  const cooccurring = mentioned.length > 0 ? await listRelatedCandidates(db, { names: mentioned, repoId }) : [];
+  const symbolNames = new Set(cooccurring.map((candidate) => lastName(candidate.symbol_name)));
+  const counted = mentioned.filter((mention) => symbolNames.has(mention)).slice(0, COUNTED_NAMES);
+  const mentionFiles = await countFilesMentioning(db, counted, repoId);
+
+  // Callers, callees, and functions sharing them
+  const neighbors = target.file_path.endsWith('.go') ? await listCallNeighbors(db, target.symbol_name, repoId) : [];
+  const nearest = [...neighbors]
+    .sort((a, b) => Number(b.adjacent) - Number(a.adjacent) || b.shared - a.shared)
+    .slice(0, NEIGHBOR_NAMES)
+    .map((neighbor) => neighbor.name);
+  const connected = nearest.length > 0 ? await listRelatedCandidates(db, { names: nearest, repoId }) : [];
+
+  const tokens = target.name_tokens ?? [];
+  const named = tokens.length > 0 ? await listRelatedCandidates(db, { tokens, repoId, limit: NAME_MATCHES }) : [];
+
+  return rankRelated(
+    target,
+    [...cooccurring, ...connected, ...named],
+    {
+      target_files: targetFiles,
+      sampled_files: sample.length,
+      shared_files: sharedFiles,
+      mention_files: mentionFiles,
+      call_degree: neighbors.filter((neighbor) => neighbor.adjacent).length,
+      neighbors: new Map(neighbors.map((neighbor) => [neighbor.name, neighbor])),
+    },
+    k
+  );
+};
@@ -27,8 +27,8 @@ import { processQuery } from '@retrieval/query-processor';
 import { determineSearchScope, type ScopeFilterConfig, type ScopeMode } from '@retrieval/scope-filter';
 import { resolveSymbols } from '@retrieval/symbol-resolver';
 import { generateCacheKey, searchResultCache } from '@utils/cache';
+import { type Embedder } from '@utils/embedders';
 import { logger } from '@utils/logger';
-import { type OllamaClient } from '@utils/ollama';
 import { PerformanceMonitor } from '@utils/performance';
 import { type CindexConfig } from '@/types/config';
 import { type SearchOptions, type SearchResult } from '@/types/retrieval';
@@ -72,7 +72,7 @@ const retrievalPerformanceMonitor = new PerformanceMonitor({
  * @param query - User query (natural language or code snippet)
  * @param config - cindex configuration
  * @param db - Database client
- * @param ollama - Ollama client for embedding generation
+ * @param embedder - Embedder for the query embedding
  * @param options - Search options (optional, includes scope filtering params)
  * @returns Search result with relevant files, chunks, symbols, and imports
  */
@@ -80,7 +80,7 @@ export const searchCodebase = async (
   query: string,
   config: CindexConfig,
   db: DatabaseClient,
-  ollama: OllamaClient,
+  embedder: Embedder,
   options: SearchOptions = {}
 ): Promise<SearchResult> => {
   const startTime = Date.now();
@@ -94,6 +94,7 @@ export const searchCodebase = async (
   const dedupThreshold = options.dedup_threshold ?? config.performance.dedup_threshold;
   const similarityThreshold = options.similarity_threshold ?? config.performance.similarity_threshold;
   const chunkSimilarityThreshold = options.chunk_similarity_threshold ?? config.performance.chunk_similarity_threshold;
+  const includeGenerated = options.include_generated ?? false;
 
   // Check search result cache
   const cacheKey = generateCacheKey({ query, options });
@@ -170,7 +171,7 @@ export const searchCodebase = async (
   // STAGE 1: Query Processing
   // ============================================================================
   logger.debug('Stage 1: Query processing');
-  const queryEmbedding = await processQuery(query, config, ollama);
+  const queryEmbedding = await processQuery(query, config, embedder);
 
   logger.info('[3/9] Query processed', {
     stage: 'query_processing',
@@ -182,7 +183,15 @@ export const searchCodebase = async (
   // STAGE 2: File-Level Retrieval
   // ============================================================================
   logger.debug('Stage 2: File-level retrieval');
-  const relevantFiles = await retrieveFiles(queryEmbedding, config, db, scopeFilter, maxFiles, similarityThreshold);
+  const relevantFiles = await retrieveFiles(
+    queryEmbedding,
+    config,
+    db,
+    scopeFilter,
+    maxFiles,
+    similarityThreshold,
+    includeGenerated
+  );
 
   logger.info('[4/9] File retrieval complete', {
     stage: 'file_retrieval',
@@ -385,7 +394,7 @@ export const searchCodebase = async (
  * @param query - User query
  * @param config - cindex configuration
  * @param db - Database client
- * @param ollama - Ollama client
+ * @param embedder - Embedder of the configured provider
  * @param repoIds - Repository IDs to search within
  * @param options - Search options
  * @returns Search result filtered by repositories
@@ -394,7 +403,7 @@ export const searchCodebaseFiltered = async (
   query: string,
   config: CindexConfig,
   db: DatabaseClient,
-  ollama: OllamaClient,
+  embedder: Embedder,
   repoIds: string[],
   options: SearchOptions = {}
 ): Promise<SearchResult> => {
@@ -435,7 +444,7 @@ export const searchCodebaseFiltered = async (
   };
 
   // Perform base search
-  const result = await searchCodebase(query, config, db, ollama, filteredOptions);
+  const result = await searchCodebase(query, config, db, embedder, filteredOptions);
 
   // Post-filter results by repository (until full Stage 0 integration is complete)
   const repoIdSet = new Set(repoIds);
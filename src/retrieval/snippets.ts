@@ -0,0 +1,233 @@
+/**
+ * Source snippets of query results (cindex search --show-source, GET /symbols?include_snippet=true)
+ *
+ * A snippet is a symbol's span as indexed, from its declaration line to its
+ * end line, with context lines around it and the span's UTF-8 byte offsets in
+ * the file:
+ *
+ *        40  // Login checks a user's password       context
+ *        41  func (s *AuthService) Login(...) {      span: start_byte at `func`
+ *        ...
+ *        58  }                                       span: end_byte after `}`
+ *        59                                          context
+ *
+ * Spans longer than the line limit keep their head and closing line. The head
+ * is cut after a line that ends outside comments and multi-line strings and at
+ * the nesting depth of the body, so the lines shown read as complete
+ * statements; the lines left out are counted.
+ */
+
+import { type Pool } from 'pg';
+
+import { listFileContents, listSymbolEndLines } from '@database/queries';
+import { type ResolvedSymbol, type SourceSnippet } from '@/types/retrieval';
+
+/** Context lines above and below a span without a count */
+export const DEFAULT_SNIPPET_CONTEXT = 2;
+
+/** Most context lines asked for */
+export const MAX_SNIPPET_CONTEXT = 50;
+
+/** Longest span shown in full */
+export const MAX_SNIPPET_LINES = 60;
+
+/**
+ * Comment and string delimiters of a language, for finding safe cut points
+ */
+interface ScanRules {
+  lineComments: string[];
+  blockComment: [string, string] | null;
+  /** Delimiters of strings that may span lines */
+  multilineQuotes: string[];
+  /** Delimiters of strings that end at the line */
+  quotes: string[];
+}
+
+/**
+ * Scanning rules of a language (Language enum value); others are C-like
+ */
+const scanRules = (language: string): ScanRules => {
+  switch (language) {
+    case 'python':
+      return { lineComments: ['#'], blockComment: null, multilineQuotes: ['"""', "'''"], quotes: ['"', "'"] };
+    case 'ruby':
+      return { lineComments: ['#'], blockComment: null, multilineQuotes: [], quotes: ['"', "'"] };
+    case 'go':
+    case 'typescript':
+    case 'javascript':
+      return { lineComments: ['//'], blockComment: ['/*', '*/'], multilineQuotes: ['`'], quotes: ['"', "'"] };
+    case 'rust':
+      // Single quotes are also lifetimes ('a)
+      return { lineComments: ['//'], blockComment: ['/*', '*/'], multilineQuotes: [], quotes: ['"'] };
+    case 'java':
+    case 'kotlin':
+    case 'swift':
+    case 'csharp':
+      return { lineComments: ['//'], blockComment: ['/*', '*/'], multilineQuotes: ['"""'], quotes: ['"', "'"] };
+    default:
+      return { lineComments: ['//'], blockComment: ['/*', '*/'], multilineQuotes: [], quotes: ['"', "'"] };
+  }
+};
+
+/**
+ * State at the end of each line: bracket depth, and whether a comment or string is still open
+ *
+ * @param lines - Lines of a span
+ * @param language - Language of the file
+ */
+export const scanLineEnds = (lines: string[], language: string): { depth: number; open: boolean }[] => {
+  const rules = scanRules(language);
+  const states: { depth: number; open: boolean }[] = [];
+  let depth = 0;
+  // Closing delimiter of the comment or multi-line string spanning lines
+  let closer: string | null = null;
+
+  for (const line of lines) {
+    let i = 0;
+    while (i < line.length) {
+      if (closer !== null) {
+        const end = line.indexOf(closer, i);
+        if (end === -1) break;
+        i = end + closer.length;
+        closer = null;
+        continue;
+      }
+      if (rules.lineComments.some((marker) => line.startsWith(marker, i))) break;
+      if (rules.blockComment && line.startsWith(rules.blockComment[0], i)) {
+        closer = rules.blockComment[1];
+        i += rules.blockComment[0].length;
+        continue;
+      }
+      const multiline = rules.multilineQuotes.find((quote) => line.startsWith(quote, i));
+      if (multiline) {
+        closer = multiline;
+        i += multiline.length;
+        continue;
+      }
+      const quote = rules.quotes.find((candidate) => line.startsWith(candidate, i));
+      if (quote) {
+        i += quote.length;
+        while (i < line.length && !line.startsWith(quote, i)) i += line[i] === '\\' ? 2 : 1;
+        i += quote.length;
+        continue;
+      }
+      const char = line[i];
+      if (char === '(' || char === '[' || char === '{') depth++;
+      else if (char === ')' || char === ']' || char === '}') depth = Math.max(0, depth - 1);
+      i++;
+    }
+    states.push({ depth, open: closer !== null });
+  }
+  return states;
+};
+
+/**
+ * Number of head lines kept of a span too long to show in full
+ *
+ * @param lines - Lines of the span
+ * @param language - Language of the file
+ * @param maxLines - Most lines shown, the closing line included
+ * @returns Lines kept before the closing line
+ */
+export const truncationPoint = (lines: string[], language: string, maxLines: number): number => {
+  const budget = Math.max(1, maxLines - 1);
+  const states = scanLineEnds(lines.slice(0, budget), language);
+  const bodyDepth = states[0].depth;
+  for (let i = budget - 1; i > 0; i--) {
+    if (!states[i].open && states[i].depth <= bodyDepth) return i + 1;
+  }
+  // No statement boundary in reach: any line outside comments and strings
+  for (let i = budget - 1; i > 0; i--) {
+    if (!states[i].open) return i + 1;
+  }
+  // Inside one comment or string throughout: the declaration line alone
+  return 1;
+};
+
+/**
+ * Cut the snippet of a span out of a file's content
+ *
+ * @param content - File content as indexed
+ * @param span - Declaration line and last line (1-based; null end for single-line symbols)
+ * @param options.language - Language of the file, for safe truncation
+ * @param options.context - Lines above and below the span (default: DEFAULT_SNIPPET_CONTEXT)
+ * @param options.maxLines - Longest span shown in full (default: MAX_SNIPPET_LINES)
+ * @returns Snippet, or null if the span is outside the content
+ */
+export const extractSnippet = (
+  content: string,
+  span: { line_number: number; end_line: number | null },
+  options: { language?: string; context?: number; maxLines?: number } = {}
+): SourceSnippet | null => {
+  const { language = '', context = DEFAULT_SNIPPET_CONTEXT, maxLines = MAX_SNIPPET_LINES } = options;
+  const lines = content.split('\n');
+  const start = span.line_number;
+  const end = Math.min(Math.max(span.end_line ?? start, start), lines.length);
+  if (start < 1 || start > lines.length) return null;
+
+  // Byte offsets from the first character of the declaration to the last of the span
+  const prefix = lines.slice(0, start - 1).join('\n') + (start > 1 ? '\n' : '');
+  const first = lines[start - 1];
+  const startByte = Buffer.byteLength(prefix + first.slice(0, first.length - first.trimStart().length), 'utf-8');
+  const last = lines[end - 1];
+  const spanText = lines.slice(start - 1, end - 1).join('\n') + (end > start ? '\n' : '') + last.trimEnd();
+  const endByte = Buffer.byteLength(prefix + spanText, 'utf-8');
+
+  const spanLines = lines.slice(start - 1, end);
+  const kept = spanLines.length > maxLines ? truncationPoint(spanLines, language, maxLines) : spanLines.length;
+  const shown = [
+    ...spanLines.slice(0, kept).map((text, index) => ({ line: start + index, text, context: false })),
+    ...(kept < spanLines.length ? [{ line: end, text: last, context: false }] : []),
+  ];
+  const before = lines
+    .slice(Math.max(0, start - 1 - context), start - 1)
+    .map((text, index, all) => ({ line: start - all.length + index, text, context: true }));
+  const after = lines.slice(end, end + context).map((text, index) => ({ line: end + 1 + index, text, context: true }));
+
+  return {
+    start_line: start,
+    end_line: end,
+    start_byte: startByte,
+    end_byte: endByte,
+    lines: [...before, ...shown, ...after],
+    omitted_lines: kept < spanLines.length ? spanLines.length - kept - 1 : 0,
+  };
+};
+
+/**
+ * Attach source snippets to symbols, from the content stored at index time
+ *
+ * @param db - Database connection pool
+ * @param symbols - Query results
+ * @param options - Context lines and line limit (see extractSnippet)
+ * @returns The symbols with snippet set (null when their file was indexed without content)
+ */
+export const attachSnippets = async <T extends ResolvedSymbol>(
+  db: Pool,
+  symbols: T[],
+  options: { context?: number; maxLines?: number } = {}
+): Promise<T[]> => {
+  if (symbols.length === 0) return symbols;
+  const locations = symbols.map((symbol) => ({
+    repo_id: symbol.repo_id ?? null,
+    file_path: symbol.file_path,
+    line_number: symbol.line_number,
+  }));
+  const files = await listFileContents(db, locations);
+  const spans = await listSymbolEndLines(db, locations);
+
+  const key = (repoId: string | null | undefined, filePath: string): string => `${repoId ?? ''}\0${filePath}`;
+  const contents = new Map(files.map((file) => [key(file.repo_id, file.file_path), file.content]));
+  const endLines = new Map(
+    spans.map((span) => [`${key(span.repo_id, span.file_path)}\0${String(span.line_number)}`, span.end_line])
+  );
+  return symbols.map((symbol) => {
+    const content = contents.get(key(symbol.repo_id, symbol.file_path));
+    const span = {
+      line_number: symbol.line_number,
+      end_line: endLines.get(`${key(symbol.repo_id, symbol.file_path)}\0${String(symbol.line_number)}`) ?? null,
+    };
+    if (content === undefined) return { ...symbol, snippet: null };
+    return { ...symbol, snippet: extractSnippet(content, span, { ...options, language: symbol.language }) };
+  });
+};
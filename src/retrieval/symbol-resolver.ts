@@ -15,7 +15,19 @@ import { type RelevantChunk, type ResolvedSymbol } from '@/types/retrieval';
 interface SymbolResolutionRow {
   symbol_id: string;
   symbol_name: string;
-  symbol_type: 'function' | 'class' | 'variable' | 'interface' | 'type' | 'constant' | 'method';
+  symbol_type:
+    | 'function'
+    | 'class'
+    | 'variable'
+    | 'interface'
+    | 'type'
+    | 'constant'
+    | 'method'
+    | 'field'
+    | 'test'
+    | 'benchmark'
+    | 'fuzz'
+    | 'example';
   file_path: string;
   line_number: number;
   definition: string;
@@ -126,7 +138,7 @@ export const resolveSymbols = async (chunks: RelevantChunk[], db: DatabaseClient
     FROM code_symbols
     WHERE symbol_name = ANY($1::text[])
       AND scope = 'exported'
-    ORDER BY symbol_name, file_path
+    ORDER BY symbol_name, file_path, line_number
   `;
 
   const params = [symbolNamesArray];
@@ -256,7 +268,7 @@ export const resolveSymbolsFiltered = async (
       service_id
     FROM code_symbols
     WHERE ${whereClauses.join(' AND ')}
-    ORDER BY symbol_name, file_path
+    ORDER BY symbol_name, file_path, line_number
   `;
 
   try {
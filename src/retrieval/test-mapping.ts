@@ -0,0 +1,218 @@
+/**
+ * Test-to-code mapping for Go (cindex tests)
+ *
+ * Links the test functions of _test.go files to the production functions
+ * and methods they exercise, from the call graph recorded at index time
+ * (see @indexing/go-calls):
+ *
+ *   TestLogin ─calls→ newTestService (helper) ─calls→ AuthService.Login ─calls→ hashPassword
+ *
+ * testsFor walks the graph backwards from changed declarations to the tests
+ * that reach them, through helpers and other production code; coveredBy
+ * does the same for every declaration of a file. Calls are resolved as the
+ * call graph resolves them: a method called on a value of unknown type may
+ * be any method of that name, so links through such calls are marked
+ * possible and dropped with exact. A test reached both ways links exactly.
+ */
+
+import * as path from 'node:path';
+
+import { compareStrings } from '@utils/ordering';
+import { type GoCallRecord, type SymbolVariantRecord } from '@/types/database';
+
+/** Symbol kinds of the functions go test runs */
+export const GO_TEST_KINDS = new Set(['test', 'benchmark', 'fuzz', 'example']);
+
+/**
+ * Test reaching a production declaration
+ */
+export interface TestLink {
+  test: SymbolVariantRecord;
+  /** Nearest declaration the test reaches */
+  target: SymbolVariantRecord;
+  /** Calls from the test to the target (1: the test calls it) */
+  depth: number;
+  /** Reached only through calls on values of unknown type */
+  possible: boolean;
+}
+
+/**
+ * Call edge between declarations
+ */
+interface Edge {
+  from: string;
+  possible: boolean;
+}
+
+/**
+ * Declaration reached walking callers: calls from the start, and the start
+ */
+interface Reach {
+  depth: number;
+  start: string;
+}
+
+/** Key of a declaration: package directory and name (Login, AuthService.Login) */
+const declarationKey = (directory: string, name: string): string => `${directory}\0${name}`;
+
+/**
+ * Check whether a declaration is in a test file
+ */
+export const isTestFile = (filePath: string): boolean => filePath.endsWith('_test.go');
+
+/**
+ * Call graph of an index's Go functions, walked from callee to caller
+ */
+export class TestMap {
+  private readonly declarations = new Map<string, SymbolVariantRecord>();
+  private readonly callers = new Map<string, Edge[]>();
+
+  /**
+   * @param functions - Functions, methods, and tests of the index (see listGoFunctions)
+   * @param calls - Calls recorded for the index (see listGoCalls)
+   * @param options.exact - Leave out calls on values of unknown type
+   */
+  public constructor(functions: SymbolVariantRecord[], calls: GoCallRecord[], options: { exact?: boolean } = {}) {
+    // Build variants of a declaration share its key (the first one stands for it)
+    for (const fn of functions) {
+      const key = declarationKey(path.posix.dirname(fn.file_path), fn.symbol_name);
+      if (!this.declarations.has(key)) this.declarations.set(key, fn);
+    }
+
+    const directories = new Set([...this.declarations.values()].map((fn) => path.posix.dirname(fn.file_path)));
+    const methods = new Map<string, string[]>();
+    for (const [key, fn] of this.declarations) {
+      if (fn.symbol_type !== 'method') continue;
+      const name = fn.symbol_name.slice(fn.symbol_name.lastIndexOf('.') + 1);
+      methods.set(name, [...(methods.get(name) ?? []), key]);
+    }
+
+    for (const call of calls) {
+      const directory = path.posix.dirname(call.file_path);
+      const from = declarationKey(directory, call.caller_name);
+      const add = (to: string, possible: boolean): void => {
+        if (!this.declarations.has(to) || to === from) return;
+        this.callers.set(to, [...(this.callers.get(to) ?? []), { from, possible }]);
+      };
+
+      if (call.call_kind === 'function' || call.call_kind === 'method') {
+        add(declarationKey(directory, call.callee_name), false);
+      } else if (call.call_kind === 'import' && call.callee_package) {
+        // The longest indexed directory the import path ends with (github.com/acme/app/internal/auth)
+        const segments = call.callee_package.split('/');
+        const imported = segments.map((_, i) => segments.slice(i).join('/')).find((dir) => directories.has(dir));
+        if (imported) add(declarationKey(imported, call.callee_name), false);
+      } else if (call.call_kind === 'dynamic' && !options.exact) {
+        for (const key of methods.get(call.callee_name) ?? []) add(key, true);
+      }
+    }
+  }
+
+  /**
+   * Find the production declarations a name refers to
+   *
+   * @param target - Login, AuthService.Login, Login for the method on any
+   *   receiver, or a name qualified by its package directory (auth.Login)
+   * @returns Declarations outside test files, ordered by file and line
+   */
+  public resolve = (target: string): SymbolVariantRecord[] => {
+    return [...this.declarations.values()]
+      .filter((fn) => {
+        if (isTestFile(fn.file_path) || GO_TEST_KINDS.has(fn.symbol_type)) return false;
+        const packageName = path.posix.basename(path.posix.dirname(fn.file_path));
+        return (
+          fn.symbol_name === target ||
+          fn.symbol_name.endsWith(`.${target}`) ||
+          `${packageName}.${fn.symbol_name}` === target
+        );
+      })
+      .sort((a, b) => compareStrings(a.file_path, b.file_path) || a.line_number - b.line_number);
+  };
+
+  /**
+   * List the tests that reach any of the declarations
+   *
+   * @param targets - Declarations that changed
+   * @param maxDepth - Most calls between a test and a target (default: any)
+   * @returns One link per test (to its nearest target, exact links first), ordered by file and line
+   */
+  public testsFor = (targets: SymbolVariantRecord[], maxDepth = Infinity): TestLink[] => {
+    const starts = targets.map((fn) => declarationKey(path.posix.dirname(fn.file_path), fn.symbol_name));
+    const exact = this.walk(starts, maxDepth, false);
+    const possible = this.walk(starts, maxDepth, true);
+
+    const links: TestLink[] = [];
+    for (const [key, test] of this.declarations) {
+      if (!GO_TEST_KINDS.has(test.symbol_type)) continue;
+      const reached = exact.get(key) ?? possible.get(key);
+      if (!reached) continue;
+      const target = this.declarations.get(reached.start);
+      if (target) links.push({ test, target, depth: reached.depth, possible: !exact.has(key) });
+    }
+    return links.sort(
+      (a, b) => compareStrings(a.test.file_path, b.test.file_path) || a.test.line_number - b.test.line_number
+    );
+  };
+
+  /**
+   * List the tests that reach any declaration of a file
+   *
+   * The tests of a test file reach themselves (depth 0).
+   *
+   * @param filePath - File path relative to the repository root
+   * @param maxDepth - Most calls between a test and a declaration (default: any)
+   * @returns Links as testsFor returns them
+   */
+  public coveredBy = (filePath: string, maxDepth = Infinity): TestLink[] => {
+    return this.testsFor([...this.declarations.values()].filter((fn) => fn.file_path === filePath), maxDepth);
+  };
+
+  /**
+   * Walk callers breadth-first from the starting declarations
+   *
+   * @returns Each reached declaration with its distance and the start it was reached from
+   */
+  private walk = (starts: string[], maxDepth: number, possible: boolean): Map<string, Reach> => {
+    const reached = new Map<string, Reach>();
+    let frontier = starts.filter((key) => this.declarations.has(key));
+    for (const key of frontier) reached.set(key, { depth: 0, start: key });
+
+    for (let depth = 1; frontier.length > 0 && depth <= maxDepth; depth++) {
+      const next: string[] = [];
+      for (const key of frontier) {
+        const start = reached.get(key)?.start ?? key;
+        for (const edge of this.callers.get(key) ?? []) {
+          if ((edge.possible && !possible) || reached.has(edge.from)) continue;
+          reached.set(edge.from, { depth, start });
+          next.push(edge.from);
+        }
+      }
+      frontier = next;
+    }
+    return reached;
+  };
+}
+
+/**
+ * Build the go test commands that run the linked tests, one per package
+ *
+ * Benchmarks only run with -bench, so they are left out.
+ *
+ * @param links - Tests to run
+ * @returns Commands ordered by package directory
+ */
+export const goTestCommands = (links: TestLink[]): string[] => {
+  const byDirectory = new Map<string, Set<string>>();
+  for (const { test } of links) {
+    if (test.symbol_type === 'benchmark') continue;
+    const directory = path.posix.dirname(test.file_path);
+    byDirectory.set(directory, (byDirectory.get(directory) ?? new Set()).add(test.symbol_name));
+  }
+
+  return [...byDirectory.entries()]
+    .sort(([a], [b]) => compareStrings(a, b))
+    .map(([directory, names]) => {
+      const pattern = [...names].sort(compareStrings).join('|');
+      return `go test -run '^(${pattern})$' ${directory === '.' ? '.' : `./${directory}`}`;
+    });
+};
@@ -0,0 +1,123 @@
+/**
+ * CLI types for cindex command-line subcommands
+ *
+ * The MCP server remains the default entry point; these types describe the
+ * auxiliary commands (e.g. `cindex doctor`) dispatched from src/index.ts.
+ */
+
+/**
+ * Process exit codes returned by CLI commands
+ *
+ * Stable across releases so CI scripts can branch on outcomes without
+ * parsing stderr. New codes are only ever added, never renumbered.
+ */
+export enum ExitCode {
+  /** Command completed and found what it was asked for */
+  Success = 0,
+  /** Command failed (e.g. database unreachable, a doctor check failed, indexing aborted) */
+  Failure = 1,
+  /** Invalid arguments or usage */
+  Usage = 2,
+  /** Command succeeded but matched nothing (e.g. search with no results) */
+  NoResults = 3,
+  /** Command completed but some files failed or only partially parsed */
+  PartialFailure = 4,
+  /** Command completed and reported policy violations (e.g. rule checks) */
+  PolicyViolation = 5,
+  /** Unexpected internal error (bug); please report with the stack trace */
+  InternalError = 70,
+  /** Stopped by SIGINT/SIGTERM (128 + SIGINT); work done so far was saved */
+  Interrupted = 130,
+}
+
+/**
+ * Output format selected with --format
+ * - text: human-readable (or porcelain) output, errors as plain text
+ * - json: errors reported as a structured JSON object on stderr
+ */
+export type OutputFormat = 'text' | 'json';
+
+/**
+ * Result output selected with --output (--porcelain is short for 'porcelain')
+ */
+export type OutputMode = 'text' | 'porcelain' | 'ndjson';
+
+/**
+ * Structured error reported on failure with --format json
+ *
+ * Written to stderr as a single line: {"error": {...CliErrorReport, "exit_code": N}}
+ */
+export interface CliErrorReport {
+  /** Stable error code (e.g. 'USAGE_ERROR', 'CONFIG_ERROR', 'DB_CONNECTION_ERROR') */
+  code: string;
+  /** Human-readable message */
+  message: string;
+  /** File the error relates to, when known */
+  file?: string;
+  /** 1-based position within file, when known (column in the unit selected by --position-encoding) */
+  position?: { line: number; column: number };
+  /** Suggested fix or usage synopsis */
+  hint?: string;
+}
+
+/**
+ * Value completion source for a CLI option or positional argument
+ * - repo: indexed repository IDs (queried from the database)
+ * - language: supported language names
+ * - path / dir: filesystem paths
+ * - string[]: fixed set of choices
+ */
+export type CliCompletion = 'repo' | 'language' | 'path' | 'dir' | string[];
+
+/**
+ * CLI option metadata (used for shell completion)
+ */
+export interface CliOption {
+  /** Long option name without leading dashes (e.g. 'dry-run') */
+  name: string;
+  /** Short description */
+  description: string;
+  /** Whether the option takes a value (default: false) */
+  takesValue?: boolean;
+  /** Value completion source (only when takesValue is true) */
+  complete?: CliCompletion;
+}
+
+/**
+ * Registered CLI subcommand
+ */
+export interface CliCommand {
+  /** Subcommand name as typed on the command line (e.g. 'doctor') */
+  name: string;
+  /** One-line description shown in `cindex help` */
+  description: string;
+  /** Usage synopsis (e.g. 'cindex doctor [--verbose]') */
+  usage: string;
+  /** Options accepted by the command (for completion) */
+  options?: CliOption[];
+  /** Completion source for positional arguments */
+  positional?: CliCompletion;
+  /** Always run in the invoking process, never in cindex daemon (reads stdin or the terminal, or runs until stopped) */
+  local?: boolean;
+  /** Execute the command with remaining argv, resolves to process exit code */
+  run: (args: string[]) => Promise<ExitCode>;
+}
+
+/**
+ * Result status for a single diagnostic check
+ */
+export type CheckStatus = 'ok' | 'warn' | 'fail' | 'skip';
+
+/**
+ * Single diagnostic check result reported by `cindex doctor`
+ */
+export interface DiagnosticCheck {
+  /** Check name (e.g. 'Database connection') */
+  name: string;
+  /** Check outcome */
+  status: CheckStatus;
+  /** Short detail about what was found */
+  detail: string;
+  /** Actionable fix, shown when status is warn or fail */
+  fix?: string;
+}
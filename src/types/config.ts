@@ -3,6 +3,9 @@
  *
  * Defines environment variables, runtime configuration, and indexing options
  */
+import { type LogLevel } from '@utils/logger';
+import { type GeneratedFilePolicy, type SymlinkPolicy } from '@/types/indexing';
+import { type StoreBackend } from '@/types/store';
 
 /**
  * Main server configuration loaded from environment variables
@@ -16,19 +19,30 @@ export interface CindexConfig {
   ollama: OllamaConfig;
   /** PostgreSQL database settings */
   database: DatabaseConfig;
+  /** Symbol and doc-comment search store */
+  store: StoreConfig;
   /** Performance tuning parameters */
   performance: PerformanceConfig;
   /** Feature flags for optional features */
   features: FeatureFlags;
   /** Default indexing options */
   indexing: IndexingDefaults;
+  /** Logging settings */
+  logging: LoggingConfig;
 }
 
+/**
+ * Service that computes embeddings: Ollama, an OpenAI-compatible HTTP API, or a local ONNX model
+ */
+export type EmbeddingProvider = 'ollama' | 'openai' | 'onnx';
+
 /**
  * Embedding model configuration
  */
 export interface EmbeddingConfig {
-  /** Model name (default: 'bge-m3:567m') */
+  /** Embedding service (default: 'ollama') */
+  provider: EmbeddingProvider;
+  /** Model name (default: 'bge-m3:567m'; a Hugging Face model id or local directory for onnx) */
   model: string;
   /** Embedding vector dimensions (default: 1024) */
   dimensions: number;
@@ -36,6 +50,10 @@ export interface EmbeddingConfig {
   batch_size: number;
   /** Context window in tokens (default: 4096) */
   context_window?: number;
+  /** Base URL of the OpenAI-compatible API (default: 'https://api.openai.com/v1') */
+  api_base?: string;
+  /** API key sent as a bearer token (OpenAI-compatible provider) */
+  api_key?: string;
 }
 
 /**
@@ -84,6 +102,16 @@ export interface DatabaseConfig {
   idle_timeout: number;
 }
 
+/**
+ * Symbol and doc-comment search store (see @database/store)
+ */
+export interface StoreConfig {
+  /** Backend searched (default: 'postgres') */
+  backend: StoreBackend;
+  /** SQLite file of the sqlite backend (default: ~/.cindex/store.db) */
+  sqlite_path?: string;
+}
+
 /**
  * Performance tuning configuration
  */
@@ -140,6 +168,8 @@ export interface FeatureFlags {
   enable_tsconfig_paths: boolean;
   /** Enable hybrid search combining vector + full-text search (default: true) */
   enable_hybrid_search: boolean;
+  /** Record query and indexing timings to ~/.cindex/stats.jsonl (default: false) */
+  enable_usage_stats: boolean;
 }
 
 /**
@@ -148,14 +178,28 @@ export interface FeatureFlags {
 export interface IndexingDefaults {
   /** Respect .gitignore patterns (default: true) */
   respect_gitignore: boolean;
+  /** Symbolic link handling (default: follow-within-root) */
+  symlink_policy: SymlinkPolicy;
+  /** Generated file handling: exclude or tag (default: exclude) */
+  generated_files: GeneratedFilePolicy;
+  /** Directory exclusion overrides: names to add, `!name` to index a default (default: []) */
+  exclude_directories: string[];
   /** Maximum file size in lines (default: 5000) */
   max_file_size: number;
+  /** Maximum directory nesting below the repository root (default: 64) */
+  max_directory_depth: number;
+  /** Maximum relative path length in characters (default: 1024) */
+  max_path_length: number;
   /** Enable secret file protection (default: true) */
   protect_secrets: boolean;
   /** Custom secret file patterns (default: []) */
   secret_patterns: string[];
+  /** Scan indexed content for likely credentials (default: false) */
+  scan_secrets: boolean;
   /** Languages to index (default: [] = all) */
   languages: string[];
+  /** Extractor and analyzer plugins: module paths and exec:<command> entries (default: []) */
+  plugins: string[];
   /** Enable workspace detection (default: true) */
   detect_workspaces: boolean;
   /** Resolve workspace import aliases (default: true) */
@@ -170,6 +214,14 @@ export interface IndexingDefaults {
   detect_from_docker_compose: boolean;
 }
 
+/**
+ * Logging configuration
+ */
+export interface LoggingConfig {
+  /** Minimum log level (default: 'INFO') */
+  level: LogLevel;
+}
+
 /**
  * Runtime state managed internally (not from configuration)
  */
@@ -236,18 +288,31 @@ export interface ServiceCacheEntry {
   dependencies: string[];
 }
 
+/**
+ * Optional prefix accepted for every environment variable (e.g. CINDEX_POSTGRES_HOST)
+ * The prefixed form takes precedence over the unprefixed one.
+ */
+export const ENV_PREFIX = 'CINDEX_';
+
 /**
  * Environment variable keys
  */
 export const ENV_VARS = {
   // Models
+  EMBEDDING_PROVIDER: 'EMBEDDING_PROVIDER',
   EMBEDDING_MODEL: 'EMBEDDING_MODEL',
   EMBEDDING_DIMENSIONS: 'EMBEDDING_DIMENSIONS',
   EMBEDDING_CONTEXT_WINDOW: 'EMBEDDING_CONTEXT_WINDOW',
+  EMBEDDING_BATCH_SIZE: 'EMBEDDING_BATCH_SIZE',
+  EMBEDDING_API_BASE: 'EMBEDDING_API_BASE',
+  EMBEDDING_API_KEY: 'EMBEDDING_API_KEY',
   SUMMARY_MODEL: 'SUMMARY_MODEL',
   SUMMARY_CONTEXT_WINDOW: 'SUMMARY_CONTEXT_WINDOW',
+  SUMMARY_METHOD: 'SUMMARY_METHOD',
+  SUMMARY_MAX_LINES: 'SUMMARY_MAX_LINES',
   OLLAMA_HOST: 'OLLAMA_HOST',
   OLLAMA_TIMEOUT: 'OLLAMA_TIMEOUT',
+  OLLAMA_RETRY_ATTEMPTS: 'OLLAMA_RETRY_ATTEMPTS',
 
   // Database
   POSTGRES_HOST: 'POSTGRES_HOST',
@@ -256,6 +321,9 @@ export const ENV_VARS = {
   POSTGRES_USER: 'POSTGRES_USER',
   POSTGRES_PASSWORD: 'POSTGRES_PASSWORD',
   POSTGRES_MAX_CONNECTIONS: 'POSTGRES_MAX_CONNECTIONS',
+  POSTGRES_IDLE_TIMEOUT: 'POSTGRES_IDLE_TIMEOUT',
+  STORE_BACKEND: 'STORE_BACKEND',
+  SQLITE_PATH: 'SQLITE_PATH',
 
   // Performance
   HNSW_EF_SEARCH: 'HNSW_EF_SEARCH',
@@ -265,6 +333,9 @@ export const ENV_VARS = {
   DEDUP_THRESHOLD: 'DEDUP_THRESHOLD',
   HYBRID_VECTOR_WEIGHT: 'HYBRID_VECTOR_WEIGHT',
   HYBRID_KEYWORD_WEIGHT: 'HYBRID_KEYWORD_WEIGHT',
+  MAX_CONTEXT_TOKENS: 'MAX_CONTEXT_TOKENS',
+  WARN_CONTEXT_TOKENS: 'WARN_CONTEXT_TOKENS',
+  INDEXING_BATCH_SIZE: 'INDEXING_BATCH_SIZE',
 
   // Depths
   IMPORT_DEPTH: 'IMPORT_DEPTH',
@@ -273,8 +344,17 @@ export const ENV_VARS = {
 
   // Indexing
   MAX_FILE_SIZE: 'MAX_FILE_SIZE',
+  MAX_DIRECTORY_DEPTH: 'MAX_DIRECTORY_DEPTH',
+  MAX_PATH_LENGTH: 'MAX_PATH_LENGTH',
   PROTECT_SECRETS: 'PROTECT_SECRETS',
   SECRET_PATTERNS: 'SECRET_PATTERNS',
+  SCAN_SECRETS: 'SCAN_SECRETS',
+  RESPECT_GITIGNORE: 'RESPECT_GITIGNORE',
+  SYMLINK_POLICY: 'SYMLINK_POLICY',
+  GENERATED_FILES: 'GENERATED_FILES',
+  EXCLUDE_DIRECTORIES: 'EXCLUDE_DIRECTORIES',
+  LANGUAGES: 'LANGUAGES',
+  PLUGINS: 'PLUGINS',
 
   // Feature flags
   ENABLE_WORKSPACE_DETECTION: 'ENABLE_WORKSPACE_DETECTION',
@@ -282,6 +362,13 @@ export const ENV_VARS = {
   ENABLE_MULTI_REPO: 'ENABLE_MULTI_REPO',
   ENABLE_API_ENDPOINT_DETECTION: 'ENABLE_API_ENDPOINT_DETECTION',
   ENABLE_HYBRID_SEARCH: 'ENABLE_HYBRID_SEARCH',
+  ENABLE_DEDUPLICATION: 'ENABLE_DEDUPLICATION',
+  ENABLE_INCREMENTAL_INDEXING: 'ENABLE_INCREMENTAL_INDEXING',
+  ENABLE_LLM_SUMMARIES: 'ENABLE_LLM_SUMMARIES',
+  ENABLE_USAGE_STATS: 'ENABLE_USAGE_STATS',
+
+  // Logging
+  LOG_LEVEL: 'LOG_LEVEL',
 } as const;
 
 /**
@@ -289,10 +376,12 @@ export const ENV_VARS = {
  */
 export const DEFAULT_CONFIG: CindexConfig = {
   embedding: {
+    provider: 'ollama',
     model: 'bge-m3:567m',
     dimensions: 1024,
     batch_size: 100,
     context_window: 4096,
+    api_base: 'https://api.openai.com/v1',
   },
   summary: {
     model: 'qwen2.5-coder:7b',
@@ -314,6 +403,9 @@ export const DEFAULT_CONFIG: CindexConfig = {
     max_connections: 10,
     idle_timeout: 30000,
   },
+  store: {
+    backend: 'postgres',
+  },
   performance: {
     hnsw_ef_search: 300,
     hnsw_ef_construction: 200,
@@ -340,13 +432,21 @@ export const DEFAULT_CONFIG: CindexConfig = {
     enable_llm_summaries: true,
     enable_tsconfig_paths: true,
     enable_hybrid_search: true,
+    enable_usage_stats: false,
   },
   indexing: {
     respect_gitignore: true,
+    symlink_policy: 'follow-within-root',
+    generated_files: 'exclude',
+    exclude_directories: [],
     max_file_size: 5000,
+    max_directory_depth: 64,
+    max_path_length: 1024,
     protect_secrets: true,
     secret_patterns: [],
+    scan_secrets: false,
     languages: [],
+    plugins: [],
     detect_workspaces: true,
     resolve_workspace_aliases: true,
     parse_tsconfig_paths: true,
@@ -354,4 +454,7 @@ export const DEFAULT_CONFIG: CindexConfig = {
     detect_api_endpoints: true,
     detect_from_docker_compose: true,
   },
+  logging: {
+    level: 'INFO',
+  },
 };
@@ -95,9 +95,587 @@ export interface CodeFile extends RepositoryContext {
   exports: string[] | null;
   file_hash: string; // SHA256
   last_modified: Date | null;
+  parse_error?: string | null; // Set when tree-sitter failed and fallback parsing was used
+  parse_error_line?: number | null;
+  parse_error_column?: number | null; // UTF-16 code units (tree-sitter, LSP)
+  parse_error_byte_column?: number | null; // UTF-8 bytes
+  parse_partial?: boolean; // Syntax errors, but the declarations that parsed were indexed (no fallback)
+  encoding?: string | null; // Original encoding when not UTF-8 (content is stored transcoded)
+  generated?: boolean; // Generated file indexed with GENERATED_FILES=tag (hidden from default search)
+  license?: string | null; // SPDX identifier or expression (see license-detector)
+  license_source?: LicenseSource | null; // spdx/header: declared in the file; directory: inherited
+  build_constraint?: string | null; // Go: file name and //go:build constraint combined (see build-constraints)
+  file_size?: number | null; // Bytes on disk when indexed
+  file_mtime_ms?: number | null; // Modification time when indexed (epoch ms); unchanged size and mtime skip the read
+  code_lines?: number | null; // Lines with code (see file-metrics)
+  comment_lines?: number | null; // Lines holding only comments
+  blank_lines?: number | null;
+  is_test?: boolean; // Test file by name or directory
+  chunk_count?: number | null; // Chunks written for the file (integrity check)
+  chunk_checksum?: string | null; // Checksum of the chunks written (see computeChunkChecksum)
+  quarantined_at?: Date | null; // Stored chunks failed verification; rebuilt on the next incremental run
   indexed_at: Date;
 }
 
+/**
+ * How a file's license was determined
+ * - spdx: SPDX-License-Identifier tag in the file header
+ * - header: license notice in the file header
+ * - directory: inherited from the nearest license file (the file has no header)
+ */
+export type LicenseSource = 'spdx' | 'header' | 'directory';
+
+/**
+ * File that failed or partially failed to parse in its last build (cindex errors)
+ */
+export interface ParseErrorRecord {
+  repo_id: string | null;
+  file_path: string;
+  language: string;
+  parse_error: string;
+  parse_error_line: number | null;
+  parse_error_column: number | null;
+  parse_error_byte_column: number | null;
+  parse_partial: boolean;
+  indexed_at: Date;
+}
+
+/**
+ * Exported symbol and the language of its file (cindex api)
+ */
+export interface ExportedSymbolRecord {
+  symbol_name: string;
+  symbol_type: string;
+  file_path: string;
+  definition: string | null;
+  language: string;
+}
+
+/**
+ * Line span of an indexed symbol (cindex coverage, cindex owners)
+ */
+export interface FunctionSpanRecord {
+  id: number;
+  symbol_name: string;
+  file_path: string;
+  line_number: number;
+  end_line: number | null;
+}
+
+/**
+ * Symbol with the last change recorded for it (cindex owners <symbol>)
+ */
+export interface SymbolHistoryRecord extends FunctionSpanRecord {
+  repo_id: string | null;
+  symbol_type: string;
+  /** Commit, author, and date of the last change (null until cindex index --history records them) */
+  last_commit: string | null;
+  last_author: string | null;
+  last_modified: Date | null;
+}
+
+/**
+ * Symbol with a hash of its source lines (cindex diff-symbols)
+ */
+export interface SymbolFingerprintRecord {
+  symbol_name: string;
+  symbol_type: string;
+  file_path: string;
+  line_number: number;
+  /** md5 of the symbol's lines, null if the file's content is not stored */
+  source_hash: string | null;
+}
+
+/**
+ * Symbol with its ID, documentation, and declaration line (cindex export --format scip)
+ */
+export interface ScipSymbolRecord {
+  symbol_id: string;
+  symbol_name: string;
+  symbol_type: SymbolType;
+  file_path: string;
+  line_number: number;
+  end_line: number | null;
+  definition: string | null;
+  doc_comment: string | null;
+  language: string | null;
+  /** Text of the declaration's first line, null if the file's content is not stored */
+  line_text: string | null;
+}
+
+/**
+ * Type-checked Go reference with the ID of its target (cindex export --format scip)
+ */
+export interface ScipReferenceRecord {
+  file_path: string;
+  line_number: number;
+  /** 1-based, UTF-8 bytes */
+  column_number: number;
+  target_name: string;
+  access: GoReferenceAccess | null;
+  /** Null when the target is not an indexed symbol */
+  target_symbol_id: string | null;
+}
+
+/**
+ * Go interface satisfied by a type, with the ID of the type (cindex export --format scip)
+ */
+export interface ScipImplementationRecord extends GoImplementationRecord {
+  type_symbol_id: string | null;
+}
+
+/**
+ * How many items share a value (e.g. 12 files with 3 symbols)
+ */
+export interface ValueFrequency {
+  value: number;
+  count: number;
+}
+
+/**
+ * Composition of an index (cindex stats --index): raw distributions to bucket for display
+ */
+export interface IndexComposition {
+  files: number;
+  symbols: number;
+  /** Files per number of symbols they define */
+  symbols_per_file: ValueFrequency[];
+  /** Functions per cyclomatic complexity */
+  function_complexity: ValueFrequency[];
+  /** Files per line count */
+  file_lines: ValueFrequency[];
+  /** Files and lines per language */
+  languages: { language: string; files: number; lines: number }[];
+}
+
+/**
+ * Kind of annotation read from a comment
+ * - todo: TODO, FIXME, HACK, XXX, or BUG marker
+ * - deprecated: Deprecated: paragraph or @deprecated tag
+ * - directive: Go //go: directive other than go:build
+ * - build: Go build constraint line (//go:build, // +build)
+ * - plugin: reported by an analyzer plugin, tagged with its scheme's marker (see @indexing/plugins)
+ */
+export type AnnotationKind = 'todo' | 'deprecated' | 'directive' | 'build' | 'plugin';
+
+/** Kinds in display order */
+export const ANNOTATION_KINDS: readonly AnnotationKind[] = ['todo', 'deprecated', 'directive', 'build', 'plugin'];
+
+/**
+ * Annotation stored for an indexed file (cindex todos, deprecated, annotations)
+ */
+export interface AnnotationRecord {
+  repo_id: string | null;
+  file_path: string;
+  kind: AnnotationKind;
+  tag: string;
+  assignee: string | null;
+  text: string;
+  line_number: number;
+  column_number: number;
+  byte_column: number;
+  symbol_name: string | null;
+  symbol_type: string | null;
+}
+
+/**
+ * Kind of an indexed literal
+ * - string: string literal, as written
+ * - comment: comment other than a declaration's doc comment
+ */
+export type LiteralKind = 'string' | 'comment';
+
+/**
+ * String literal or comment stored for an indexed file (cindex search --scope strings|comments)
+ */
+export interface LiteralRecord {
+  repo_id: string | null;
+  file_path: string;
+  kind: LiteralKind;
+  text: string;
+  line_number: number;
+  column_number: number;
+  byte_column: number;
+}
+
+/**
+ * Metric an analyzer plugin reported for an indexed file or symbol (cindex plugins --metric)
+ */
+export interface PluginMetricRecord {
+  repo_id: string | null;
+  file_path: string;
+  analyzer: string;
+  name: string;
+  value: number;
+  symbol_name: string | null;
+  line_number: number | null;
+}
+
+/**
+ * Line and symbol metrics of one indexed file (cindex stats --by)
+ */
+export interface FileStatsRecord {
+  repo_id: string | null;
+  file_path: string;
+  language: string;
+  package_name: string | null;
+  total_lines: number;
+  /** NULL for files indexed before line kinds were recorded */
+  code_lines: number | null;
+  comment_lines: number | null;
+  blank_lines: number | null;
+  is_test: boolean;
+  /** Symbols defined in the file per kind (function, method, class, ...) */
+  symbol_kinds: Record<string, number>;
+  /** Functions with a recorded cyclomatic complexity, and the sum of it */
+  functions: number;
+  complexity_total: number;
+}
+
+/**
+ * Indexed file and its license (cindex licenses)
+ */
+export interface FileLicenseRecord {
+  repo_id: string | null;
+  file_path: string;
+  language: string;
+  license: string | null;
+  license_source: LicenseSource | null;
+}
+
+/**
+ * Stored secret scanner finding (cindex secrets)
+ */
+export interface SecretFindingRecord {
+  repo_id: string | null;
+  file_path: string;
+  rule: string;
+  line_number: number;
+  column_number: number; // UTF-16 code units
+  byte_column: number; // UTF-8 bytes
+  redacted: string;
+  fingerprint: string;
+  detected_at: Date;
+}
+
+/**
+ * Stored linter finding (cindex lint)
+ */
+export interface LintFindingRecord {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  column_number: number;
+  end_line: number | null;
+  tool: string;
+  linter: string;
+  rule: string;
+  severity: string;
+  message: string;
+  /** Innermost symbol whose span holds the finding */
+  symbol_name: string | null;
+  imported_at: Date;
+}
+
+/**
+ * Interface a Go type satisfies (cindex index --typed)
+ */
+export interface GoImplementationRecord {
+  repo_id: string | null;
+  /** File declaring the type */
+  file_path: string;
+  line_number: number;
+  type_name: string;
+  type_package: string;
+  interface_name: string;
+  /** Import path of the interface ('' for error) */
+  interface_package: string;
+  /** Only the pointer type *T satisfies the interface */
+  pointer_receiver: boolean;
+}
+
+/**
+ * How a Go reference uses its declaration (see the helper in @indexing/go-loader-source)
+ */
+export type GoReferenceAccess = 'read' | 'write' | 'call';
+
+/**
+ * Type-checked use of a Go declaration (cindex index --typed, cindex refs)
+ */
+export interface GoReferenceRecord {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  column_number: number;
+  /** Package-level name, Receiver.Method, or Type.Field */
+  target_name: string;
+  target_package: string;
+  target_file: string;
+  target_line: number;
+  /** Null when resolved by gopls, or indexed before accesses were recorded */
+  access: GoReferenceAccess | null;
+  /** Innermost symbol whose span holds the reference */
+  symbol_name: string | null;
+}
+
+/**
+ * Go declaration with counts of its type-checked uses (cindex deadcode)
+ */
+export interface GoSymbolUsageRecord {
+  repo_id: string | null;
+  symbol_name: string;
+  symbol_type: SymbolType;
+  file_path: string;
+  line_number: number;
+  scope: 'exported' | 'internal';
+  definition: string | null;
+  reference_count: number;
+  /** Uses in other files */
+  outside_file: number;
+  /** Uses in other packages (directories) */
+  outside_package: number;
+  /** Method of a type that satisfies an interface (may be called through it) */
+  implements_interface: boolean;
+}
+
+/**
+ * How the target of a Go call was resolved (see @indexing/go-calls)
+ */
+export type GoCallKind = 'function' | 'method' | 'import' | 'dynamic';
+
+/**
+ * Call edge between Go declarations (cindex callers, cindex callees)
+ */
+export interface GoCallRecord {
+  repo_id: string | null;
+  file_path: string;
+  caller_name: string;
+  caller_line: number;
+  callee_name: string;
+  callee_package: string | null;
+  callee_qualifier: string | null;
+  call_kind: GoCallKind;
+  line_number: number;
+  column_number: number;
+}
+
+/**
+ * Go constant recorded at index time (cindex enums)
+ */
+export interface GoConstantRecord {
+  repo_id: string | null;
+  file_path: string;
+  constant_name: string;
+  type_name: string | null;
+  /** Resolved value as a Go literal, or null */
+  value: string | null;
+  expression: string;
+  iota: number;
+  group_line: number;
+  line_number: number;
+  doc: string | null;
+}
+
+/**
+ * Type parameter of a generic Go function or type (cindex generics)
+ */
+export interface GoTypeParameterRecord {
+  repo_id: string | null;
+  file_path: string;
+  symbol_name: string;
+  symbol_kind: 'function' | 'type';
+  parameter_name: string;
+  constraint_text: string;
+  position: number;
+  line_number: number;
+}
+
+/**
+ * Instantiation of a generic Go function or type (cindex generics)
+ */
+export interface GoInstantiationRecord {
+  repo_id: string | null;
+  file_path: string;
+  target_name: string;
+  target_package: string | null;
+  /** Type arguments as written; empty when inferred */
+  type_arguments: string[];
+  /** Function or Receiver.Method containing the instantiation (null at package level) */
+  context_name: string | null;
+  line_number: number;
+  column_number: number;
+  /** Type arguments inferred from a call (Map(xs, f)) rather than written out */
+  inferred: boolean;
+}
+
+/**
+ * Type embedded in a Go struct or interface, at a depth of the hierarchy (cindex hierarchy)
+ */
+export interface GoEmbeddingRecord {
+  repo_id: string | null;
+  file_path: string;
+  type_name: string;
+  /** Package name of the embedding type (its directory) */
+  type_package: string;
+  type_kind: 'struct' | 'interface';
+  embedded_name: string;
+  /** Import path when qualified by an import */
+  embedded_package: string | null;
+  /** Package name of the embedded type: last element of its import path, else the embedding type's */
+  embedded_package_name: string;
+  pointer: boolean;
+  line_number: number;
+  column_number: number;
+  /** 1 for the queried type's own embeddings or embedders */
+  depth: number;
+}
+
+/**
+ * Go module of an index with the modules it requires (cindex modules)
+ */
+export interface GoModuleRecord {
+  repo_id: string;
+  module_path: string;
+  /** Directory of the go.mod within the index ('.' for the root) */
+  directory: string;
+  go_version: string | null;
+  files: number;
+  symbols: number;
+  /** Modules of the same index this one requires, by module path */
+  requires: string[];
+  /** Modules of the same index requiring this one */
+  required_by: string[];
+}
+
+/**
+ * Function with a clone fingerprint (cindex dupes)
+ */
+export interface CloneCandidateRecord {
+  repo_id: string | null;
+  file_path: string;
+  symbol_name: string;
+  symbol_type: string;
+  line_number: number;
+  end_line: number | null;
+  clone_tokens: number;
+  clone_signature: number[];
+}
+
+/**
+ * Stored content of an indexed file (cindex grep)
+ */
+export interface FileContentRecord {
+  repo_id: string | null;
+  file_path: string;
+  /** Content as indexed, with LF line endings */
+  content: string;
+}
+
+/**
+ * Last line of a symbol's span (source snippets)
+ */
+export interface SymbolEndLineRecord {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  /** Null for single-line symbols */
+  end_line: number | null;
+}
+
+/**
+ * Stored content of an indexed file with its language (cindex audit)
+ */
+export interface SourceFileRecord extends FileContentRecord {
+  language: string;
+}
+
+/**
+ * Indexed file and the repository it belongs to
+ */
+export interface IndexedFileRecord {
+  repo_id: string | null;
+  repo_path: string;
+  file_path: string;
+}
+
+/**
+ * Symbol whose doc comment or name matches a text search (cindex doc --search)
+ */
+export interface DocMatchRecord {
+  repo_id: string | null;
+  symbol_name: string;
+  symbol_type: SymbolType;
+  file_path: string;
+  line_number: number;
+  doc_comment: string | null;
+  /** Every word of the query matched, not only some */
+  complete: boolean;
+  rank: number;
+}
+
+/**
+ * Import paths of an indexed file (cindex graph)
+ */
+export interface FileImportsRecord {
+  /** Index of the file (its repository path for files indexed without an id) */
+  repo_id: string;
+  /** Go module path of a cindex deps index, or of the file's module within the index (null outside Go modules) */
+  module_path: string | null;
+  /** Directory of that module within the index ('.' for a cindex deps index) */
+  module_directory: string | null;
+  file_path: string;
+  language: string;
+  imports: string[];
+}
+
+/**
+ * Content version of an indexed file (finding files edited since indexing)
+ */
+export interface IndexedFileVersionRecord {
+  file_path: string;
+  file_hash: string;
+  last_modified: Date | null;
+}
+
+/**
+ * Definition of a Go symbol in one file, with the file's build constraint (cindex platforms)
+ */
+export interface SymbolVariantRecord {
+  repo_id: string | null;
+  symbol_name: string;
+  symbol_type: string;
+  file_path: string;
+  line_number: number;
+  build_constraint: string | null;
+}
+
+/**
+ * Symbol considered for cindex related, with its name tokens
+ */
+export interface RelatedCandidateRecord {
+  repo_id: string | null;
+  symbol_name: string;
+  symbol_type: string;
+  file_path: string;
+  line_number: number;
+  symbol_id: string | null;
+  name_tokens: string[] | null;
+}
+
+/**
+ * Function of the Go call graph next to, or sharing neighbors with, another (cindex related)
+ */
+export interface CallNeighborRecord {
+  /** Function, or Receiver.Method */
+  name: string;
+  /** Calls or is called by the function */
+  adjacent: boolean;
+  /** Callers and callees shared with the function */
+  shared: number;
+  /** Distinct callers and callees */
+  degree: number;
+}
+
 /**
  * Symbol registry (extended with workspace/service context)
  */
@@ -108,6 +686,15 @@ export interface CodeSymbol extends RepositoryContext {
   symbol_type: SymbolType;
   file_path: string;
   line_number: number;
+  end_line?: number | null;
+  complexity?: number | null; // Cyclomatic complexity (functions and methods)
+  cognitive_complexity?: number | null; // Cognitive complexity (functions and methods)
+  clone_signature?: number[] | null; // MinHash signature of the normalized body (cindex dupes)
+  clone_tokens?: number | null; // Normalized tokens of the body
+  doc_comment?: string | null; // Doc comment without comment markers
+  name_tokens?: string[] | null; // Words of the name and their stems (word: search)
+  symbol_id?: string | null; // Structured symbol ID (see @indexing/symbol-ids)
+  coverage?: number | null; // Percent of statements covered (cindex coverage)
   definition: string | null;
   embedding: number[] | null;
 }
@@ -115,7 +702,19 @@ export interface CodeSymbol extends RepositoryContext {
 /**
  * Symbol types
  */
-export type SymbolType = 'function' | 'class' | 'variable' | 'interface' | 'type' | 'constant' | 'method';
+export type SymbolType =
+  | 'function'
+  | 'class'
+  | 'variable'
+  | 'interface'
+  | 'type'
+  | 'constant'
+  | 'method'
+  | 'field'
+  | 'test'
+  | 'benchmark'
+  | 'fuzz'
+  | 'example';
 
 /**
  * Workspace/package registry for monorepo support
@@ -124,7 +723,7 @@ export interface Workspace {
   id: number;
   repo_id: string;
   workspace_id: string; // Unique identifier (e.g., 'auth-workspace')
-  package_name: string; // From package.json (e.g., '@workspace/auth')
+  package_name: string; // From package.json (e.g., '@workspace/auth'), or a Go module path
   workspace_path: string; // Relative path from repo root
   package_json_path: string | null;
   version: string | null;
@@ -240,6 +839,12 @@ export interface RepositoryMetadata {
   last_indexed?: string; // ISO timestamp of last indexing
   exclude_from_default_search?: boolean; // Don't include in default searches
 
+  // Revision index metadata (cindex index --rev)
+  revision?: string; // Commit the index was built from (full hash)
+  revision_ref?: string; // Name it was asked for (tag, branch, or hash)
+  revision_of?: string; // Index ID of the working copy
+  committed_at?: string; // ISO committer date
+
   // Documentation repository metadata (repo_type = 'documentation')
   indexed_for?: string; // Purpose: 'learning', 'reference', 'api-docs'
   documentation_type?: string; // 'markdown', 'jsdoc', 'api-reference'
@@ -327,9 +932,9 @@ export interface WorkspaceDependency {
 }
 
 /**
- * Workspace dependency types
+ * Workspace dependency types (go_module: a Go module requiring another module of the repository)
  */
-export type WorkspaceDependencyType = 'runtime' | 'dev' | 'peer';
+export type WorkspaceDependencyType = 'runtime' | 'dev' | 'peer' | 'go_module';
 
 /**
  * Workspace dependency metadata (JSONB)
@@ -394,3 +999,30 @@ export interface RepoIdQueryResult {
 export interface SimilarityResult {
   similarity: number;
 }
+
+/**
+ * Node of a PostgreSQL EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) plan (fields read by cindex explain)
+ */
+export interface QueryPlanNode {
+  'Node Type': string;
+  'Relation Name'?: string;
+  'Index Name'?: string;
+  /** Rows returned per loop */
+  'Actual Rows'?: number;
+  'Actual Loops'?: number;
+  /** Milliseconds per loop, including children */
+  'Actual Total Time'?: number;
+  'Rows Removed by Filter'?: number;
+  'Shared Hit Blocks'?: number;
+  'Shared Read Blocks'?: number;
+  Plans?: QueryPlanNode[];
+}
+
+/**
+ * Executed plan of one statement
+ */
+export interface QueryPlan {
+  Plan: QueryPlanNode;
+  'Planning Time': number;
+  'Execution Time': number;
+}
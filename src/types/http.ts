@@ -0,0 +1,94 @@
+/**
+ * HTTP API types for cindex serve --http
+ *
+ * Response bodies of the REST endpoints. Field names follow the database
+ * records and MCP tool outputs (snake_case), so a client can move between
+ * them without renaming.
+ */
+
+import { type OutlineNode } from '@retrieval/outline';
+import { type RelatedSymbol, type ResolvedSymbol } from '@/types/retrieval';
+
+/**
+ * One page of a list endpoint
+ */
+export interface HttpPage<T> {
+  items: T[];
+  /** Pass as ?cursor= for the next page; null on the last page */
+  next_cursor: string | null;
+}
+
+/**
+ * Error body of a failed request
+ */
+export interface HttpErrorBody {
+  error: {
+    code: string;
+    message: string;
+    hint?: string;
+  };
+}
+
+/**
+ * GET /health
+ */
+export interface HttpHealth {
+  /** unavailable when the database cannot be queried (status 503) */
+  status: 'ok' | 'unavailable';
+  /** Indexes served */
+  indexes: number;
+}
+
+/**
+ * Symbol of GET /symbols, with the ID GET /refs takes
+ */
+export interface HttpSymbol extends ResolvedSymbol {
+  id: string;
+  /** Fuzzy match score, best first */
+  score: number;
+}
+
+/**
+ * GET /files/{path}/outline
+ */
+export interface HttpFileOutline {
+  repo_id: string | null;
+  /** Path as stored */
+  file_path: string;
+  /** Package or module with declarations nested beneath; null if the file was indexed without content */
+  outline: OutlineNode | null;
+  /** Indexed symbols, in declaration order */
+  symbols: ResolvedSymbol[];
+}
+
+/**
+ * Use of a symbol (GET /refs/{id})
+ */
+export interface HttpReference {
+  repo_id: string | null;
+  file_path: string;
+  line_number: number;
+  /** 1-based, in UTF-16 code units for text matches, bytes for typed references */
+  column_number: number;
+  /** Enclosing symbol (typed references) */
+  symbol_name?: string | null;
+  /** read, write, or call (typed references, when recorded) */
+  access?: string | null;
+  /** Text of the line (text matches) */
+  text?: string;
+}
+
+/**
+ * GET /refs/{id}: a page of uses and how they were found
+ */
+export interface HttpReferencePage extends HttpPage<HttpReference> {
+  /** typed: Go type checker (cindex index --typed); text: whole-word matches of the indexed contents */
+  source: 'typed' | 'text';
+}
+
+/**
+ * GET /related/{id}: related symbols, best first, with the IDs /refs and /related take
+ */
+export interface HttpRelated {
+  items: (RelatedSymbol & { id: string })[];
+}
@@ -5,7 +5,15 @@
  * and metadata extraction across single-repo, monorepo, and microservice architectures.
  */
 
-import { type RepositoryType } from '@/types/database';
+import {
+  type AnnotationKind,
+  type GoCallKind,
+  type GoImplementationRecord,
+  type GoReferenceRecord,
+  type LicenseSource,
+  type LiteralKind,
+  type RepositoryType,
+} from '@/types/database';
 
 /**
  * Supported programming languages for code parsing and analysis
@@ -83,9 +91,21 @@ export interface DiscoveredFile {
   /** Last modified timestamp */
   modified_time: Date;
 
-  /** File encoding (default: utf-8) */
+  /** Original file encoding (utf-8, utf-16le, utf-16be, shift_jis, latin1); content is transcoded to UTF-8 */
   encoding: string;
 
+  /** File starts with a byte order mark (stripped before parsing; shifts line 1 byte columns) */
+  bom?: boolean;
+
+  /** License resolved while indexing (own header, else nearest license file) */
+  license?: FileLicense | null;
+
+  /** Go build constraint from the file name and //go:build line (null: built for every platform) */
+  build_constraint?: string | null;
+
+  /** Code, comment, and blank lines (cindex stats --by) */
+  line_counts?: LineCounts;
+
   // Multi-project context fields (nullable for single-repo mode)
 
   /** Repository ID for multi-project support */
@@ -97,8 +117,66 @@ export interface DiscoveredFile {
   /** Package name from package.json (monorepo) */
   package_name?: string;
 
+  /** Directory of the Go module the package name is the path of, relative to repository root */
+  package_directory?: string;
+
   /** Service ID for microservice architectures */
   service_id?: string;
+
+  /** Effective per-directory overrides from nested .cindex.yaml files (if any) */
+  directory_config?: DirectoryConfig;
+
+  /** Why the file looks generated (header marker, file name, minified), when tagged rather than excluded */
+  generated?: string;
+}
+
+/**
+ * What the index recorded about a file when it was last indexed
+ *
+ * Incremental runs reuse hash and line count for files whose size and
+ * modification time still match, without reading them.
+ */
+export interface IndexedFileStamp {
+  file_hash: string;
+  line_count: number;
+  file_size: number;
+  /** Modification time, epoch milliseconds */
+  mtime_ms: number;
+  /** Original encoding (utf-8 when stored as NULL) */
+  encoding: string;
+}
+
+/**
+ * Per-directory overrides loaded from `.cindex.yaml`
+ *
+ * Files deeper in the tree override their ancestors key by key; `metrics`
+ * thresholds merge individually and `exclude` patterns accumulate, each
+ * scoped to the directory that declared them.
+ */
+export interface DirectoryConfig {
+  /** Gitignore-style patterns relative to the declaring directory */
+  exclude?: string[];
+
+  /** Patterns a file must match to be indexed, relative to the declaring directory (replaces inherited ones) */
+  include?: string[];
+
+  /** Languages to index in this subtree (replaces the inherited list) */
+  languages?: string[];
+
+  /** Maximum file size in lines (skip larger files) */
+  max_file_size?: number;
+
+  /** Maximum file size in bytes (skip larger files without reading them) */
+  max_file_bytes?: number;
+
+  /** Extensions skipped as binary in addition to the defaults (e.g. '.dat') */
+  binary_extensions?: string[];
+
+  /** Line count at which files switch to structure-only indexing */
+  structure_only_lines?: number;
+
+  /** Metric thresholds for this subtree (e.g. max_complexity) */
+  metrics?: Record<string, number>;
 }
 
 /**
@@ -114,6 +192,8 @@ export enum NodeType {
   Constant = 'constant',
   Interface = 'interface',
   Type = 'type',
+  /** Go struct field, a child of its struct */
+  Field = 'field',
   TopLevelBlock = 'top_level_block',
 }
 
@@ -160,15 +240,21 @@ export interface ParsedNode {
   /** Function/method parameters */
   parameters?: ParameterInfo[];
 
-  /** Return type annotation (if available) */
+  /** Return type annotation (if available); the declared type of Go named types, constants, variables, and fields */
   return_type?: string;
 
+  /** Receiver type name of a Go method (AuthService of func (s *AuthService) Login) */
+  receiver?: string;
+
   /** Docstring or JSDoc comment */
   docstring?: string;
 
   /** Cyclomatic complexity (for functions/methods) */
   complexity?: number;
 
+  /** Cognitive complexity (for functions/methods) */
+  cognitive_complexity?: number;
+
   /** Child nodes (e.g., methods within a class) */
   children?: ParsedNode[];
 
@@ -370,6 +456,16 @@ export interface APIEndpointInfo {
   api_type: 'rest' | 'graphql' | 'grpc';
 }
 
+/**
+ * File indexed from memory instead of disk, such as a blob of a git commit
+ */
+export interface MemoryFile {
+  /** Path relative to the repository root, forward slashes */
+  path: string;
+  content: Buffer;
+  modified_time: Date;
+}
+
 /**
  * File processing options for indexing pipeline
  */
@@ -378,18 +474,63 @@ export interface IndexingOptions {
   /** Enable incremental indexing (skip unchanged files) */
   incremental?: boolean;
 
+  /** Only process files changed since this time (git history, else mtime) */
+  since?: Date;
+
+  /** Wait for another process's index lock instead of failing (default: false) */
+  waitForLock?: boolean;
+
+  /** Stop after the files in progress when aborted (SIGINT/SIGTERM); a checkpoint is recorded */
+  signal?: AbortSignal;
+
+  /** Files indexed at a time, each parsed on its own worker thread (default: available CPUs) */
+  jobs?: number;
+
   /** Languages to index (empty array = all languages) */
   languages?: string[];
 
   /** Respect .gitignore patterns during file discovery */
   respectGitignore?: boolean;
 
+  /** Symbolic link handling during file discovery (default: follow-within-root) */
+  symlinkPolicy?: SymlinkPolicy;
+
+  /** Directory names to exclude in addition to the defaults; `!name` indexes a default (e.g. `!vendor`) */
+  excludeDirectories?: string[];
+
+  /** Walk only this directory below the root; paths stay relative to the root (e.g. a module in GOMODCACHE) */
+  subdirectory?: string;
+
+  /** Index these files, held in memory, instead of walking the root (a git commit's tree, see @indexing/memory-tree) */
+  files?: MemoryFile[];
+
+  /** Exclude generated files or index them tagged generated (default: exclude) */
+  generatedFiles?: GeneratedFilePolicy;
+
   /** Maximum file size in lines (skip larger files) */
   maxFileSize?: number;
 
+  /** Maximum directory nesting below the root (deeper directories are skipped, default: 64) */
+  maxDirectoryDepth?: number;
+
+  /** Maximum relative path length in characters (longer paths are skipped, default: 1024) */
+  maxPathLength?: number;
+
   /** Enable secret file protection (detect .env, credentials, keys) */
   protectSecrets?: boolean;
 
+  /** Scan indexed content for likely credentials and record findings (default: false) */
+  scanSecrets?: boolean;
+
+  /** Type-check Go packages with go/packages and record methods, implementations, and references */
+  typed?: boolean;
+
+  /** Platforms typed mode loads the packages for (default: the GOOS/GOARCH of the go command) */
+  typedPlatforms?: Platform[];
+
+  /** Record each symbol's last commit, author, and date from git blame */
+  history?: boolean;
+
   /** Custom patterns for secret file detection (glob-style) */
   secretPatterns?: string[];
 
@@ -487,11 +628,28 @@ export interface ParseResult {
   /** Export statements */
   exports: ExportInfo[];
 
-  /** Error message if parsing failed */
+  /** Error message if parsing failed (or why fallback parsing was used) */
   error?: string;
 
+  /** 1-based position of the first syntax error, when known (column: UTF-16 code units, byte_column: UTF-8 bytes) */
+  error_position?: { line: number; column: number; byte_column: number };
+
   /** Whether fallback parsing was used */
   used_fallback: boolean;
+
+  /** Whether tree-sitter found syntax errors and only the declarations that parsed were kept */
+  partial?: boolean;
+}
+
+/**
+ * Source of the symbols of files in some languages (see @indexing/extractors)
+ */
+export interface LanguageExtractor {
+  /** Name reported when it fails, e.g. tree-sitter */
+  name: string;
+  /** Languages whose files it reads */
+  languages: string[];
+  extract: (content: string, filePath: string, language: string) => Promise<ParseResult>;
 }
 
 /**
@@ -537,6 +695,122 @@ export interface FileDiscoveryStats {
   total_lines: number;
 }
 
+/**
+ * Reason a path was excluded during file discovery
+ */
+export type SkipReason =
+  | 'gitignore'
+  | 'excluded_directory'
+  | 'binary'
+  | 'generated'
+  | 'secret'
+  | 'markdown'
+  | 'unsupported_language'
+  | 'language_filter'
+  | 'size_limit'
+  | 'encoding'
+  | 'directory_config'
+  | 'cindexignore'
+  | 'not_included'
+  | 'unchanged_since'
+  | 'symlink'
+  | 'depth_limit'
+  | 'path_length'
+  | 'changing'
+  | 'unreadable';
+
+/**
+ * Path that could not be read during file discovery (permission or I/O error)
+ */
+export interface UnreadablePath {
+  /** Relative path (directories end with /) */
+  relative_path: string;
+
+  /** errno code (EACCES, EPERM, EIO, ...), or UNKNOWN */
+  code: string;
+}
+
+/**
+ * How file discovery treats symbolic links
+ * - skip: ignore all symlinks
+ * - follow-within-root: follow links whose target resolves inside the repository root
+ * - follow-all: follow every link (targets outside the root are indexed under the link path)
+ */
+export type SymlinkPolicy = 'skip' | 'follow-within-root' | 'follow-all';
+
+/** Accepted symlink policies */
+export const SYMLINK_POLICIES: readonly SymlinkPolicy[] = ['skip', 'follow-within-root', 'follow-all'];
+
+/**
+ * What file discovery does with generated files (DO NOT EDIT headers, *.pb.go, minified JS)
+ * - exclude: skip them (reported as `generated`)
+ * - tag: index them with generated: true, hidden from search unless include_generated is set
+ */
+export type GeneratedFilePolicy = 'exclude' | 'tag';
+
+/** Accepted generated file policies */
+export const GENERATED_FILE_POLICIES: readonly GeneratedFilePolicy[] = ['exclude', 'tag'];
+
+/**
+ * Path excluded during file discovery (recorded for dry-run reporting)
+ */
+export interface SkippedFile {
+  /** Path relative to repository root (directories end with '/') */
+  relative_path: string;
+
+  /** Exclusion reason */
+  reason: SkipReason;
+
+  /** Additional detail (e.g., line count vs limit, matched secret pattern) */
+  detail?: string;
+}
+
+/**
+ * File that would be indexed in a dry run
+ */
+export interface DryRunFile {
+  /** Path relative to repository root */
+  relative_path: string;
+
+  /** Detected language */
+  language: Language;
+
+  /** Line count */
+  line_count: number;
+
+  /** Original file encoding (transcoded to UTF-8 for parsing) */
+  encoding: string;
+
+  /** Indexing strategy that would be applied */
+  strategy: 'full' | 'structure-only';
+
+  /** Whether parsing fell back to regex extraction */
+  used_fallback: boolean;
+
+  /** Whether syntax errors limited parsing to the declarations that parsed */
+  partial: boolean;
+
+  /** Whether the file would be tagged generated (hidden from default search) */
+  generated: boolean;
+
+  /** Number of top-level nodes extracted by the parser */
+  node_count: number;
+}
+
+/**
+ * Dry-run result: what would be indexed and what would be skipped, without writing
+ */
+export interface DryRunReport {
+  /** Repository root path */
+  repo_path: string;
+
+  /** Files that would be indexed */
+  included: DryRunFile[];
+
+  /** Paths that would be skipped, with reasons */
+  skipped: SkippedFile[];
+}
+
 /**
  * ============================================================================
  * Phase 3: Embedding & Summary Generation Types
@@ -590,14 +864,26 @@ export interface ChunkEmbedding {
  * Extracted symbol with embedding
  */
 export interface ExtractedSymbol {
-  /** Symbol ID (UUID) */
+  /** Structured symbol ID (see @indexing/symbol-ids) */
   symbol_id: string;
 
   /** Symbol name */
   symbol_name: string;
 
   /** Symbol type */
-  symbol_type: 'function' | 'class' | 'variable' | 'interface' | 'type' | 'constant' | 'method';
+  symbol_type:
+    | 'function'
+    | 'class'
+    | 'variable'
+    | 'interface'
+    | 'type'
+    | 'constant'
+    | 'method'
+    | 'field'
+    | 'test'
+    | 'benchmark'
+    | 'fuzz'
+    | 'example';
 
   /** File path where symbol is defined */
   file_path: string;
@@ -605,6 +891,22 @@ export interface ExtractedSymbol {
   /** Line number */
   line_number: number;
 
+  /** Last line of the declaration */
+  end_line: number;
+
+  /** Cyclomatic complexity (functions and methods) */
+  complexity?: number;
+
+  /** Cognitive complexity (functions and methods) */
+  cognitive_complexity?: number;
+
+  /** MinHash signature of the normalized body and its token count (functions and methods, see @indexing/clones) */
+  clone_signature?: number[];
+  clone_tokens?: number;
+
+  /** Doc comment without comment markers */
+  doc_comment?: string;
+
   /** Symbol definition text */
   definition: string;
 
@@ -634,6 +936,7 @@ export enum IndexingStage {
   Symbols = 'symbols',
   Persisting = 'persisting',
   Complete = 'complete',
+  Interrupted = 'interrupted',
   Failed = 'failed',
 }
 
@@ -711,6 +1014,27 @@ export interface IndexingStats {
     stage: IndexingStage;
     error: string;
   }[];
+
+  /** Paths discovery could not read (skipped, reported once at the end) */
+  unreadable_paths?: UnreadablePath[];
+
+  /** Deleted files removed from the index (only set by incremental runs) */
+  files_removed?: number;
+
+  /** Likely credentials found by the secret scanner (only set when scanning) */
+  secret_findings?: number;
+
+  /** Files with at least one secret finding (only set when scanning) */
+  secret_files?: number;
+
+  /** Type facts recorded by typed mode (only set with --typed) */
+  typed?: { methods: number; implementations: number; references: number };
+
+  /** Why typed mode recorded nothing (go missing, packages failed to load) */
+  typed_error?: string;
+
+  /** Files blamed and symbols given a last change (only set with --history) */
+  history?: { files: number; symbols: number };
 }
 
 /**
@@ -729,3 +1053,245 @@ export interface BatchInsertResult {
     error: string;
   }[];
 }
+
+/**
+ * Kind of credential a secret finding matched
+ */
+export type SecretRule =
+  | 'aws_access_key_id'
+  | 'aws_secret_access_key'
+  | 'private_key'
+  | 'github_token'
+  | 'slack_token'
+  | 'high_entropy_string';
+
+/**
+ * Likely credential found in an indexed file (the secret itself is never kept)
+ */
+export interface SecretFinding {
+  rule: SecretRule;
+  /** 1-based line */
+  line: number;
+  /** 1-based column in UTF-16 code units */
+  column: number;
+  /** 1-based column in UTF-8 bytes */
+  byte_column: number;
+  /** First characters of the match, the rest masked */
+  redacted: string;
+  /** Truncated SHA-256 of the matched value */
+  fingerprint: string;
+}
+
+/**
+ * Call made by a Go function or method
+ */
+export interface GoCall {
+  /** Calling declaration: Function, or Receiver.Method */
+  caller: string;
+  /** Line the calling declaration starts on */
+  caller_line: number;
+  /** Function, Receiver.Method (kind method), or method name (kind dynamic) */
+  callee: string;
+  kind: GoCallKind;
+  /** Import path the callee is declared in (kind import) */
+  callee_package: string | null;
+  /** Expression the method is called on (kind dynamic; `()` when it is a call result) */
+  qualifier: string | null;
+  /** 1-based line of the callee identifier */
+  line: number;
+  /** 1-based column of the callee identifier, in UTF-8 bytes */
+  column: number;
+}
+
+/**
+ * Go constant, with its value where the file alone determines it
+ */
+export interface GoConstant {
+  name: string;
+  /** Declared type, inherited within a group, or converted to (UserRole(iota)); null if untyped */
+  type: string | null;
+  /** Value as a Go literal (2, 3600, 0.5, "v1", true); null if it depends on names outside the file */
+  value: string | null;
+  /** Expression as written, or repeated from the spec above it */
+  expression: string;
+  /** Index of the spec in its declaration: the value of iota */
+  iota: number;
+  /** Line of the const keyword */
+  group_line: number;
+  line: number;
+  /** Doc comment, or the comment trailing the spec; markers stripped */
+  doc: string | null;
+}
+
+/**
+ * Type parameter of a generic Go function or type
+ */
+export interface GoTypeParameter {
+  /** Generic function or type declaring it */
+  symbol: string;
+  symbol_kind: 'function' | 'type';
+  name: string;
+  /** Constraint as written, whitespace collapsed: any, comparable, ~int | ~string, constraints.Ordered */
+  constraint: string;
+  /** 0-based index in the type parameter list */
+  position: number;
+  /** Line of the declaration */
+  line: number;
+}
+
+/**
+ * Generic Go function or type instantiated with explicit type arguments (Map[int, string], List[User]{})
+ */
+export interface GoInstantiation {
+  /** Generic named, without its package qualifier */
+  target: string;
+  /** Import path the generic is declared in, when qualified by an import (slices.Collect) */
+  target_package: string | null;
+  /** Type arguments as written, whitespace collapsed */
+  type_arguments: string[];
+  /** Function or Receiver.Method the instantiation is in (null outside function bodies) */
+  context: string | null;
+  /** 1-based line of the generic's name */
+  line: number;
+  /** 1-based column of the generic's name, in UTF-8 bytes */
+  column: number;
+}
+
+/**
+ * Type embedded in a Go struct or interface (type Admin struct{ *User }, type ReadCloser interface{ Reader })
+ */
+export interface GoEmbedding {
+  /** Struct or interface doing the embedding */
+  type_name: string;
+  type_kind: 'struct' | 'interface';
+  /** Type embedded, without its package qualifier or type arguments */
+  embedded_name: string;
+  /** Import path the embedded type is declared in, when qualified (sync.Mutex); null for the same package */
+  embedded_package: string | null;
+  /** Embedded by pointer (*User) */
+  pointer: boolean;
+  /** 1-based line of the embedded type */
+  line: number;
+  /** 1-based column of the embedded type, in UTF-8 bytes */
+  column: number;
+}
+
+/**
+ * Go module of an indexed repository: a go.mod, and the files under it up to the next one
+ */
+export interface GoWorkspaceModule {
+  /** Module path the go.mod declares */
+  module_path: string;
+  /** Directory of the go.mod within the repository ('.' for the root) */
+  directory: string;
+  /** Go version the go.mod declares */
+  go_version: string | null;
+  /** Modules the go.mod requires, with their versions */
+  requires: { path: string; version: string }[];
+  /** Modules the go.mod replaces with a directory, resolved against the repository root */
+  replacements: { path: string; directory: string }[];
+}
+
+/**
+ * Commit, author, and date of the last change to a symbol's lines (git blame)
+ */
+export interface LastChange {
+  commit: string;
+  author: string | null;
+  /** Author date, ISO 8601 */
+  date: string;
+}
+
+/**
+ * Go method with its resolved receiver (cindex index --typed)
+ */
+export interface GoMethod {
+  file_path: string;
+  line_number: number;
+  end_line: number;
+  name: string;
+  /** Receiver type name (generic receivers by their type name) */
+  receiver: string;
+  pointer_receiver: boolean;
+  package: string;
+  /** Signature as go/types prints it: func (*Server).Handle(w http.ResponseWriter) */
+  signature: string;
+}
+
+/**
+ * Type facts of a Go repository, paths relative to its root
+ */
+export interface GoTypeFacts {
+  methods: GoMethod[];
+  implementations: Omit<GoImplementationRecord, 'repo_id'>[];
+  references: Omit<GoReferenceRecord, 'repo_id' | 'symbol_name'>[];
+}
+
+/**
+ * Target platform of a Go build (GOOS/GOARCH pair)
+ */
+export interface Platform {
+  os: string;
+  arch: string;
+}
+
+/**
+ * License of an indexed file
+ */
+export interface FileLicense {
+  /** SPDX identifier or expression (e.g. MIT, Apache-2.0, MIT OR Apache-2.0) */
+  license: string;
+  source: LicenseSource;
+}
+
+/**
+ * License file governing a directory
+ */
+export interface LicenseFile {
+  /** Repository-relative path of the (first) license file */
+  path: string;
+  /** Identified license, or null if the text was not recognized */
+  license: string | null;
+}
+
+/**
+ * Lines of a file by what they hold (blank, comment only, or code)
+ */
+export interface LineCounts {
+  code: number;
+  comment: number;
+  blank: number;
+}
+
+/**
+ * Annotation read from a comment, attached to the symbol it documents or sits in
+ */
+export interface Annotation {
+  kind: AnnotationKind;
+  /** Marker as written: TODO, FIXME, Deprecated, @deprecated, go:generate, go:build, +build, ... */
+  tag: string;
+  /** Name in parentheses after a TODO marker (TODO(ada): ...), without a leading @ */
+  assignee: string | null;
+  /** Text after the marker; a Deprecated: paragraph is joined into one line */
+  text: string;
+  line: number;
+  /** 1-based UTF-16 column of the comment, and the same in UTF-8 bytes */
+  column: number;
+  byte_column: number;
+  /** Symbol the annotation is attached to; null for file-level annotations */
+  symbol_name: string | null;
+  symbol_type: string | null;
+}
+
+/**
+ * String literal or comment of a file, searchable with cindex search --scope strings|comments
+ */
+export interface SourceLiteral {
+  kind: LiteralKind;
+  /** Text between the quotes or after the comment markers, on one line */
+  text: string;
+  line: number;
+  /** 1-based UTF-16 column of the opening quote or comment marker, and the same in UTF-8 bytes */
+  column: number;
+  byte_column: number;
+}
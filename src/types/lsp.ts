@@ -0,0 +1,97 @@
+/**
+ * Language Server Protocol types for cindex serve --lsp
+ *
+ * Only the subset the server speaks: JSON-RPC messages, positions and
+ * locations, and the params and results of workspace/symbol,
+ * textDocument/definition, textDocument/references, and
+ * textDocument/documentSymbol. Names follow the
+ * specification (camelCase), unlike the database records.
+ */
+
+/**
+ * JSON-RPC request (has an id) or notification (has none)
+ */
+export interface JsonRpcRequest {
+  jsonrpc: '2.0';
+  id?: number | string | null;
+  method: string;
+  params?: unknown;
+}
+
+/**
+ * JSON-RPC error object
+ */
+export interface JsonRpcError {
+  code: number;
+  message: string;
+}
+
+/**
+ * JSON-RPC response
+ */
+export interface JsonRpcResponse {
+  jsonrpc: '2.0';
+  id: number | string | null;
+  result?: unknown;
+  error?: JsonRpcError;
+}
+
+/**
+ * Position in a document: 0-based line and character (in the negotiated encoding)
+ */
+export interface LspPosition {
+  line: number;
+  character: number;
+}
+
+/**
+ * Range in a document, end exclusive
+ */
+export interface LspRange {
+  start: LspPosition;
+  end: LspPosition;
+}
+
+/**
+ * Range in a document named by URI
+ */
+export interface LspLocation {
+  uri: string;
+  range: LspRange;
+}
+
+/**
+ * Symbol in the workspace (workspace/symbol result)
+ */
+export interface LspSymbolInformation {
+  name: string;
+  /** SymbolKind number (see toSymbolKind) */
+  kind: number;
+  location: LspLocation;
+  containerName?: string;
+}
+
+/**
+ * Symbol of a document with the symbols nested in it (textDocument/documentSymbol result)
+ */
+export interface LspDocumentSymbol {
+  name: string;
+  detail?: string;
+  /** SymbolKind number (see toSymbolKind) */
+  kind: number;
+  /** Whole declaration */
+  range: LspRange;
+  /** Name of the declaration */
+  selectionRange: LspRange;
+  children: LspDocumentSymbol[];
+}
+
+/**
+ * Params of textDocument/definition and textDocument/references
+ */
+export interface LspTextDocumentPositionParams {
+  textDocument: { uri: string };
+  position: LspPosition;
+  /** textDocument/references only */
+  context?: { includeDeclaration?: boolean };
+}
@@ -15,6 +15,7 @@ export interface SearchCodebaseParams {
   import_depth?: number; // Default: 3
   dedup_threshold?: number; // Default: 0.92
   similarity_threshold?: number; // Default: 0.75
+  include_generated?: boolean; // Default: false (files tagged generated are hidden)
 
   // NEW: Workspace filtering
   workspace_filter?: string | string[]; // Filter by workspace ID(s)
@@ -97,6 +98,10 @@ export interface FindSymbolDefinitionParams {
   include_cross_workspace?: boolean; // Include cross-workspace usages
   include_cross_service?: boolean; // Include cross-service usages
   max_usages?: number; // Limit number of usages returned
+
+  // Source options
+  include_snippet?: boolean; // Include each definition's source span with context lines
+  snippet_context?: number; // Context lines around the span (default: 2)
 }
 
 /**
@@ -108,6 +113,8 @@ export interface IndexRepositoryParams {
   incremental?: boolean; // Default: true
   languages?: string[]; // Filter by language
   respect_gitignore?: boolean; // Default: true
+  symlink_policy?: 'skip' | 'follow-within-root' | 'follow-all'; // Default: SYMLINK_POLICY env
+  generated_files?: 'exclude' | 'tag'; // Default: GENERATED_FILES env
   max_file_size?: number; // Default: 5000 lines
   summary_method?: 'llm' | 'rule-based'; // Default: 'llm'
 
@@ -0,0 +1,143 @@
+/**
+ * Plugin types: custom extractors and analyzers (see @indexing/plugins)
+ *
+ * An extractor lists the declarations of files in a language cindex has no
+ * grammar for; an analyzer reads every indexed file of its languages and
+ * reports annotations and metrics. Both are registered in process by a
+ * module, or served by a separate process over JSON-RPC (see
+ * @indexing/plugin-host). Field names are those of the wire format.
+ */
+
+/**
+ * Declaration found by an extractor
+ */
+export interface PluginSymbol {
+  name: string;
+  /** function, method, class, interface, type, constant, or variable (others are read as variables) */
+  kind: string;
+  /** 1-based first and last line */
+  start_line: number;
+  end_line: number;
+  /** Doc comment text */
+  docstring?: string;
+  /** Visible outside its file or module (default: false) */
+  exported?: boolean;
+}
+
+/**
+ * Import found by an extractor
+ */
+export interface PluginImport {
+  /** Module, package, or file imported */
+  source: string;
+  /** Names imported from it (default: none) */
+  symbols?: string[];
+  line: number;
+}
+
+/**
+ * What an extractor found in a file
+ */
+export interface PluginExtraction {
+  symbols: PluginSymbol[];
+  imports?: PluginImport[];
+  /** Why the file could not be read in full; symbols found are still indexed */
+  error?: string;
+}
+
+/**
+ * File handed to an analyzer
+ */
+export interface PluginFile {
+  /** Path relative to the repository root */
+  path: string;
+  language: string;
+  content: string;
+  /** Symbols indexed from the file */
+  symbols: { name: string; kind: string; start_line: number; end_line: number }[];
+}
+
+/**
+ * Annotation reported by an analyzer, listed by cindex annotations --kind plugin
+ */
+export interface PluginAnnotation {
+  /** Marker of the annotation scheme, e.g. SECURITY-REVIEW */
+  tag: string;
+  text: string;
+  line: number;
+  /** 1-based UTF-16 column (default: 1) */
+  column?: number;
+  /** Symbol the annotation belongs to (default: none, the file) */
+  symbol_name?: string;
+}
+
+/**
+ * Metric reported by an analyzer, for the file or one of its symbols
+ */
+export interface PluginMetric {
+  /** Metric name, e.g. sql_statements */
+  name: string;
+  value: number;
+  /** Symbol measured (default: none, the file) */
+  symbol_name?: string;
+  line?: number;
+}
+
+/**
+ * What an analyzer reported about a file
+ */
+export interface PluginAnalysis {
+  annotations?: PluginAnnotation[];
+  metrics?: PluginMetric[];
+}
+
+/**
+ * Extractor for a language cindex has no grammar for
+ */
+export interface ExtractorPlugin {
+  name: string;
+  /** Language name stored for its files, e.g. abap */
+  language: string;
+  /** File name suffixes it reads, with the dot, e.g. .abap */
+  extensions: string[];
+  extract: (content: string, filePath: string) => PluginExtraction | Promise<PluginExtraction>;
+}
+
+/**
+ * Analyzer of indexed files
+ */
+export interface AnalyzerPlugin {
+  name: string;
+  /** Languages of the files it reads (default: all) */
+  languages?: string[];
+  analyze: (file: PluginFile) => PluginAnalysis | Promise<PluginAnalysis>;
+}
+
+/**
+ * Registration functions handed to a plugin module's register export
+ */
+export interface PluginApi {
+  registerExtractor: (extractor: ExtractorPlugin) => void;
+  registerAnalyzer: (analyzer: AnalyzerPlugin) => void;
+}
+
+/**
+ * Registered plugin, as listed by cindex plugins
+ */
+export interface PluginInfo {
+  kind: 'extractor' | 'analyzer';
+  name: string;
+  /** Language of an extractor, or those of an analyzer (empty: all) */
+  languages: string[];
+  extensions: string[];
+  /** PLUGINS entry that registered it */
+  source: string;
+}
+
+/**
+ * Metric reported by an analyzer, as stored
+ */
+export interface AnalyzerMetric extends PluginMetric {
+  /** Analyzer that reported it */
+  analyzer: string;
+}
@@ -124,11 +124,26 @@ export interface RelevantChunk {
  * Resolved symbol definition (Stage 3 output)
  */
 export interface ResolvedSymbol {
+  /** Row id (selected by symbol searches, for pagination cursors) */
+  id?: number;
+
   /** Symbol name */
   symbol_name: string;
 
   /** Symbol type */
-  symbol_type: 'function' | 'class' | 'variable' | 'interface' | 'type' | 'constant' | 'method';
+  symbol_type:
+    | 'function'
+    | 'class'
+    | 'variable'
+    | 'interface'
+    | 'type'
+    | 'constant'
+    | 'method'
+    | 'field'
+    | 'test'
+    | 'benchmark'
+    | 'fuzz'
+    | 'example';
 
   /** File path where symbol is defined */
   file_path: string;
@@ -142,12 +157,101 @@ export interface ResolvedSymbol {
   /** Symbol scope */
   scope: 'exported' | 'internal';
 
+  /** Language of the defining file (code_files.language) */
+  language?: string;
+
+  /** License of the defining file (SPDX identifier or expression), when known */
+  license?: string | null;
+
+  /** Cyclomatic complexity (functions and methods) */
+  complexity?: number | null;
+
+  /** Cognitive complexity (functions and methods) */
+  cognitive_complexity?: number | null;
+
+  /** Percent of statements covered, from an imported coverage profile */
+  coverage?: number | null;
+
+  /** Imported linter findings inside the symbol's span */
+  lint_count?: number;
+
+  /** Interfaces a Go type satisfies (import/path.Name, or error), from typed indexing */
+  implements?: string[];
+
+  /** Type parameters of a generic Go function or type, with their constraints (K comparable, V any) */
+  type_parameters?: string[];
+
+  /** Structured symbol ID (see @indexing/symbol-ids; null for symbols indexed before IDs were stored) */
+  symbol_id?: string | null;
+
+  /** Cosine similarity to the query embedding (semantic search) */
+  similarity?: number;
+
+  /** Source of the symbol's span with context lines, when asked for (null without stored content) */
+  snippet?: SourceSnippet | null;
+
   // Multi-project context (optional)
+  repo_id?: string;
   workspace_id?: string;
+  package_name?: string | null; // Workspace package, or the Go module path
   service_id?: string;
   is_internal?: boolean; // Internal to workspace/service
 }
 
+/**
+ * Line of a source snippet
+ */
+export interface SnippetLine {
+  /** 1-based line number in the file */
+  line: number;
+  text: string;
+  /** Context around the span rather than part of it */
+  context: boolean;
+}
+
+/**
+ * Span of a symbol in its file's indexed content, with context lines (see @retrieval/snippets)
+ */
+export interface SourceSnippet {
+  /** Declaration line and last line of the span (1-based, inclusive) */
+  start_line: number;
+  end_line: number;
+
+  /** UTF-8 byte offsets of the span in the file: its first character, and just past its last */
+  start_byte: number;
+  end_byte: number;
+
+  /** Context lines, then the span, then context lines; a long span skips lines before its last */
+  lines: SnippetLine[];
+
+  /** Lines of the span left out by truncation */
+  omitted_lines: number;
+}
+
+/**
+ * Symbol suggested as related to another (cindex related)
+ */
+export interface RelatedSymbol {
+  repo_id: string | null;
+  symbol_name: string;
+  symbol_type: string;
+  file_path: string;
+  line_number: number;
+  symbol_id: string | null;
+
+  /** Weighted sum of the signals, 0 to 1, best first */
+  score: number;
+
+  /** Files mentioning both names, over files mentioning either (Jaccard) */
+  cooccurrence: number;
+
+  /** Direct call in either direction, plus callers and callees in common (Go) */
+  call_graph: number;
+
+  /** Name words and stems in common, over those of either name */
+  name_similarity: number;
+}
+
 /**
  * Import chain entry (Stage 4 output)
  */
@@ -505,6 +609,9 @@ export interface SearchOptions {
   /** Chunk similarity threshold (Stage 2: Chunk-level retrieval) */
   chunk_similarity_threshold?: number; // Default: uses similarity_threshold if not specified
 
+  /** Include files tagged generated (Stage 1) */
+  include_generated?: boolean; // Default: false
+
   // ============================================================================
   // Multi-project filtering options (Stage 0)
   // ============================================================================
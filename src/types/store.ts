@@ -0,0 +1,76 @@
+/**
+ * Store types: where symbol and doc-comment search reads from (see @database/store)
+ *
+ * PostgreSQL holds every index. With STORE_BACKEND=sqlite, the symbols and
+ * doc comments of each index are also written to one SQLite file after every
+ * indexing run, and searched there with FTS5, so CLI invocations and servers
+ * on the machine share that file without a database round trip per query.
+ */
+import { type DocMatchRecord, type SymbolType } from '@/types/database';
+
+/**
+ * Backend symbol and doc-comment search reads from
+ */
+export type StoreBackend = 'postgres' | 'sqlite';
+
+/**
+ * Accepted STORE_BACKEND values
+ */
+export const STORE_BACKENDS: readonly StoreBackend[] = ['postgres', 'sqlite'];
+
+/**
+ * Symbol as written to a store
+ */
+export interface StoredSymbol {
+  symbol_name: string;
+  symbol_type: SymbolType;
+  file_path: string;
+  line_number: number;
+  doc_comment: string | null;
+  exported: boolean;
+}
+
+/**
+ * Index whose symbols are written to a store
+ */
+export interface StoredIndex {
+  repo_id: string;
+  /** Searched when no index is named (false for Go module and revision indexes) */
+  searched_by_default: boolean;
+}
+
+/**
+ * Options of a doc-comment search
+ */
+export interface DocSearchOptions {
+  /** Restrict to one index (default: every index searched by default) */
+  repoId?: string;
+  /** Maximum results (default 20) */
+  limit?: number;
+}
+
+/**
+ * Symbol and doc-comment search over the indexes
+ */
+export interface Store {
+  readonly backend: StoreBackend;
+  /**
+   * Replace the symbols of an index with those of its latest run
+   *
+   * @param index - Index written
+   * @param pages - Its symbols, a page at a time
+   * @returns Symbols written
+   */
+  replaceIndex: (index: StoredIndex, pages: AsyncIterable<StoredSymbol[]>) => Promise<number>;
+  /**
+   * Remove an index (cindex delete)
+   */
+  deleteIndex: (repoId: string) => Promise<void>;
+  /**
+   * Find symbols whose doc comments or names match words, stemmed
+   *
+   * @returns Matches, those matching every word first, then by rank
+   */
+  searchDocs: (text: string, options?: DocSearchOptions) => Promise<DocMatchRecord[]>;
+  close: () => Promise<void>;
+}
@@ -15,6 +15,9 @@
  * Performance Target: Handle edge cases without crashing or hanging
  */
 
+import { type Stats } from 'node:fs';
+import * as fs from 'node:fs/promises';
+
 import { logger } from '@utils/logger';
 import { type DiscoveredFile } from '@/types/indexing';
 
@@ -122,6 +125,19 @@ export interface EncodingDetectionResult {
   hasInvalidChars: boolean;
 }
 
+/**
+ * Decode bytes, failing on any invalid sequence
+ *
+ * @returns Decoded text, or undefined if the bytes are invalid (or the runtime lacks the encoding)
+ */
+const decodeStrict = (buffer: Buffer, encoding: string): string | undefined => {
+  try {
+    return new TextDecoder(encoding, { fatal: true }).decode(buffer);
+  } catch {
+    return undefined;
+  }
+};
+
 /**
  * Detect file encoding using BOM and heuristics
  *
@@ -129,11 +145,13 @@ export interface EncodingDetectionResult {
  * 1. Check for BOM (Byte Order Mark) - most reliable
  * 2. Check for null bytes (indicates UTF-16 or binary)
  * 3. Try UTF-8 decoding (default for text files)
- * 4. Fall back to Latin1 if UTF-8 fails
+ * 4. Try Shift_JIS if the bytes decode strictly and contain kana
+ * 5. Fall back to Latin1 if UTF-8 fails
  *
  * Supported encodings:
  * - UTF-8 (with or without BOM)
  * - UTF-16 (LE/BE)
+ * - Shift_JIS
  * - ISO-8859-1 (Latin1)
  * - Binary (detected, not decoded)
  *
@@ -164,24 +182,24 @@ export const detectEncoding = (buffer: Buffer): EncodingDetectionResult => {
   // Check for null bytes (0x00) - indicates UTF-16 or binary
   const hasNullBytes = buffer.includes(0x00);
   if (hasNullBytes) {
-    // Check alternating null byte pattern (characteristic of UTF-16)
-    let utf16LELikely = true; // Null bytes at odd positions
-    let utf16BELikely = true; // Null bytes at even positions
+    // Count null bytes by position: ASCII-range UTF-16 has a null in most code
+    // units, always on the same side (odd offsets for LE, even offsets for BE)
+    let evenNulls = 0;
+    let oddNulls = 0;
 
     // Sample first 1000 bytes for performance
-    for (let i = 0; i < Math.min(buffer.length, 1000); i += 2) {
-      if (buffer[i] === 0x00 && buffer[i + 1] !== 0x00) {
-        utf16BELikely = false;
-      }
-      if (buffer[i] !== 0x00 && buffer[i + 1] === 0x00) {
-        utf16LELikely = false;
-      }
+    const sampleSize = Math.min(buffer.length, 1000);
+    for (let i = 0; i < sampleSize; i++) {
+      if (buffer[i] !== 0x00) continue;
+      if (i % 2 === 0) evenNulls++;
+      else oddNulls++;
     }
 
-    if (utf16LELikely) {
+    const units = sampleSize / 2;
+    if (oddNulls >= units / 2 && evenNulls <= oddNulls / 10) {
       return { encoding: 'utf-16le', confidence: 0.8, hasInvalidChars: false };
     }
-    if (utf16BELikely) {
+    if (evenNulls >= units / 2 && oddNulls <= evenNulls / 10) {
       return { encoding: 'utf-16be', confidence: 0.8, hasInvalidChars: false };
     }
 
@@ -197,7 +215,14 @@ export const detectEncoding = (buffer: Buffer): EncodingDetectionResult => {
     return { encoding: 'utf-8', confidence: 0.95, hasInvalidChars: false };
   }
 
-  // Step 4: UTF-8 failed, try Latin1 (ISO-8859-1)
+  // Step 4: Shift_JIS. Kana lead bytes (0x82/0x83) are C1 controls in Latin1,
+  // so valid SJIS with kana is very unlikely to be Latin1 text
+  const sjisContent = decodeStrict(buffer, 'shift_jis');
+  if (sjisContent !== undefined && /[\u3040-\u30ff]/.test(sjisContent)) {
+    return { encoding: 'shift_jis', confidence: 0.8, hasInvalidChars: false };
+  }
+
+  // Step 5: UTF-8 failed, try Latin1 (ISO-8859-1)
   const latin1Content = buffer.toString('latin1');
   const validLatin1 = !latin1Content.includes('\ufffd');
 
@@ -209,6 +234,119 @@ export const detectEncoding = (buffer: Buffer): EncodingDetectionResult => {
   return { encoding: 'utf-8', confidence: 0.5, hasInvalidChars: true };
 };
 
+/**
+ * Source text decoded to UTF-8
+ */
+export interface DecodedText {
+  /** Decoded content (BOM removed) */
+  content: string;
+
+  /** Original encoding ('binary' when the bytes are not text; content is then empty) */
+  encoding: string;
+
+  /** Whether the file started with a byte order mark (dropped from content) */
+  bom: boolean;
+}
+
+/** Byte order marks, as found at the start of a file */
+const BOMS = [Buffer.from([0xef, 0xbb, 0xbf]), Buffer.from([0xff, 0xfe]), Buffer.from([0xfe, 0xff])];
+
+/**
+ * Decode file bytes in their detected encoding
+ *
+ * UTF-16 and Shift_JIS are transcoded and a leading BOM is dropped, so
+ * parsers and symbol extraction always see plain UTF-8 text. Whether a BOM
+ * was dropped is reported so positions can be mapped back to the file's bytes.
+ *
+ * @param buffer - File bytes
+ * @returns Decoded content with the original encoding
+ */
+export const decodeText = (buffer: Buffer): DecodedText => {
+  const { encoding } = detectEncoding(buffer);
+  if (encoding === 'binary') {
+    return { content: '', encoding, bom: false };
+  }
+
+  // TextDecoder strips the BOM (ignoreBOM defaults to false) and replaces invalid bytes
+  const content = encoding === 'latin1' ? buffer.toString('latin1') : new TextDecoder(encoding).decode(buffer);
+  const bom = encoding.startsWith('utf-') && BOMS.some((mark) => buffer.subarray(0, mark.length).equals(mark));
+  return { content, encoding, bom };
+};
+
+/**
+ * Read a source file and decode it to UTF-8
+ *
+ * @param absolutePath - Absolute file path
+ * @returns Decoded content with the original encoding
+ */
+export const readSourceFile = async (absolutePath: string): Promise<DecodedText> => {
+  const decoded = decodeText(await fs.readFile(absolutePath));
+  if (decoded.encoding !== 'utf-8' && decoded.encoding !== 'binary') {
+    logger.debug('Transcoded file to UTF-8', { file: absolutePath, encoding: decoded.encoding });
+  }
+  return decoded;
+};
+
+/**
+ * Read a UTF-8 text file (manifest, config, spec, markdown) without its BOM
+ *
+ * JSON.parse and line-based parsers reject or misread a leading U+FEFF, which
+ * editors on Windows commonly write.
+ *
+ * @param filePath - File path
+ * @returns File text with a leading BOM removed
+ */
+export const readTextFile = async (filePath: string): Promise<string> => {
+  const text = await fs.readFile(filePath, 'utf-8');
+  return text.charCodeAt(0) === 0xfeff ? text.slice(1) : text;
+};
+
+/** Reads attempted before a file that keeps changing is given up on */
+const STABLE_READ_ATTEMPTS = 3;
+
+/** Pause between reads of a changing file, so a save in progress can finish */
+const STABLE_READ_RETRY_MS = 50;
+
+/**
+ * Source file read while it was not being modified
+ */
+export interface StableSourceFile extends DecodedText {
+  /** Stats taken after the read (size and mtime match the content) */
+  stats: Stats;
+}
+
+/**
+ * Read a source file, retrying while it is being modified
+ *
+ * The file is stat'ed before and after the read; if its size or mtime moved,
+ * or the bytes read do not match the size, it changed during the read (an
+ * editor saving, a formatter rewriting) and the content may mix old and new
+ * bytes. Such a read is retried a few times, briefly apart, before giving up.
+ *
+ * @param absolutePath - Absolute file path
+ * @param attempts - Reads to try (default: 3)
+ * @returns Decoded content with the stats it matches, or null if the file kept changing
+ */
+export const readStableSourceFile = async (
+  absolutePath: string,
+  attempts = STABLE_READ_ATTEMPTS
+): Promise<StableSourceFile | null> => {
+  for (let attempt = 1; attempt <= attempts; attempt++) {
+    const before = await fs.stat(absolutePath);
+    const buffer = await fs.readFile(absolutePath);
+    const after = await fs.stat(absolutePath);
+
+    if (before.size === after.size && before.mtimeMs === after.mtimeMs && buffer.length === after.size) {
+      return { ...decodeText(buffer), stats: after };
+    }
+    logger.debug('File changed while being read, retrying', { file: absolutePath, attempt });
+    if (attempt < attempts) {
+      await new Promise((resolve) => setTimeout(resolve, STABLE_READ_RETRY_MS));
+    }
+  }
+  return null;
+};
+
 /**
  * Parse error types
  */
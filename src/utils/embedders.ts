@@ -0,0 +1,315 @@
+/**
+ * Embedding providers
+ *
+ * Indexing and search embed text through an Embedder. Ollama is the default
+ * (OllamaClient implements the interface); EMBEDDING_PROVIDER selects an
+ * OpenAI-compatible HTTP API (OpenAI, Azure, vLLM, LM Studio, ...) or a local
+ * ONNX model run in-process by Transformers.js. An index must be searched
+ * with the provider and model it was built with: vectors from different
+ * models are not comparable.
+ */
+
+import { type CindexConfig, type EmbeddingConfig, type OllamaConfig } from '@/types/config';
+
+import {
+  ConfigurationError,
+  EmbeddingGenerationError,
+  RequestTimeoutError,
+  retryWithBackoff,
+  VectorDimensionError,
+} from './errors';
+import { logger } from './logger';
+import { type OllamaClient } from './ollama';
+
+/**
+ * Computes embedding vectors for text
+ */
+export interface Embedder {
+  /**
+   * Embed one text
+   *
+   * @param modelName - Embedding model
+   * @param text - Text to embed
+   * @param expectedDimensions - Vector dimensions the index stores
+   * @param contextWindow - Context window in tokens (providers that truncate themselves ignore it)
+   * @returns Embedding vector
+   * @throws {VectorDimensionError} If the model returns another number of dimensions
+   * @throws {EmbeddingGenerationError} If embedding fails
+   */
+  generateEmbedding(
+    modelName: string,
+    text: string,
+    expectedDimensions: number,
+    contextWindow?: number
+  ): Promise<number[]>;
+
+  /**
+   * Embed many texts; failures are logged and leave their entry undefined
+   *
+   * @param modelName - Embedding model
+   * @param texts - Texts to embed
+   * @param expectedDimensions - Vector dimensions the index stores
+   * @param concurrency - Texts embedded at a time
+   * @param contextWindow - Context window in tokens
+   * @returns Embedding vectors in input order
+   */
+  generateEmbeddingBatch(
+    modelName: string,
+    texts: string[],
+    expectedDimensions: number,
+    concurrency?: number,
+    contextWindow?: number
+  ): Promise<number[][]>;
+}
+
+/**
+ * Embed texts a slice at a time, logging the slices that fail
+ *
+ * @param texts - Texts to embed
+ * @param size - Texts per slice
+ * @param embed - Embeds one slice
+ * @returns Vectors in input order (undefined for failed slices)
+ */
+const embedInSlices = async (
+  texts: string[],
+  size: number,
+  embed: (slice: string[]) => Promise<number[][]>
+): Promise<number[][]> => {
+  const results: number[][] = new Array<number[]>(texts.length);
+  const failed: number[] = [];
+  let lastError = '';
+
+  for (let i = 0; i < texts.length; i += size) {
+    const slice = texts.slice(i, i + size);
+    try {
+      const vectors = await embed(slice);
+      vectors.forEach((vector, offset) => {
+        results[i + offset] = vector;
+      });
+    } catch (error) {
+      failed.push(...slice.map((_, offset) => i + offset));
+      lastError = error instanceof Error ? error.message : String(error);
+    }
+  }
+
+  if (failed.length > 0) {
+    logger.warn(`Failed to generate ${String(failed.length)} embeddings`, { failedIndices: failed, error: lastError });
+  }
+  return results;
+};
+
+/**
+ * Check every vector's dimensions
+ *
+ * @throws {VectorDimensionError} On the first vector of another size
+ */
+const checkDimensions = (vectors: number[][], expected: number, modelName: string): number[][] => {
+  const wrong = vectors.find((vector) => vector.length !== expected);
+  if (wrong) throw new VectorDimensionError(expected, wrong.length, `Embedding model ${modelName}`);
+  return vectors;
+};
+
+/**
+ * Response from an OpenAI-compatible /embeddings endpoint
+ */
+interface OpenAIEmbeddingResponse {
+  data: { index: number; embedding: number[] }[];
+}
+
+/** Inputs sent in one /embeddings request */
+const OPENAI_BATCH_SIZE = 64;
+
+/**
+ * Embedder for OpenAI-compatible HTTP APIs (POST {api_base}/embeddings)
+ *
+ * The index's dimensions are requested with the dimensions parameter, so
+ * models that can shorten their vectors (text-embedding-3-*) fit a 1024-wide
+ * index. Timeouts and retries follow OLLAMA_TIMEOUT and OLLAMA_RETRY_ATTEMPTS.
+ */
+export class OpenAIEmbedder implements Embedder {
+  /**
+   * Create OpenAI-compatible embedder
+   *
+   * @param apiBase - API base URL (e.g. https://api.openai.com/v1)
+   * @param apiKey - Bearer token (omit for local servers without authentication)
+   * @param requests - Timeout and retry settings
+   */
+  constructor(
+    private readonly apiBase: string,
+    private readonly apiKey: string | undefined,
+    private readonly requests: Pick<OllamaConfig, 'timeout' | 'retry_attempts'>
+  ) {}
+
+  async generateEmbedding(modelName: string, text: string, expectedDimensions: number): Promise<number[]> {
+    const [embedding] = await this.embed(modelName, [text], expectedDimensions);
+    return embedding;
+  }
+
+  generateEmbeddingBatch(modelName: string, texts: string[], expectedDimensions: number): Promise<number[][]> {
+    return embedInSlices(texts, OPENAI_BATCH_SIZE, (slice) => this.embed(modelName, slice, expectedDimensions));
+  }
+
+  /**
+   * Embed texts in one request
+   */
+  private embed = async (modelName: string, input: string[], dimensions: number): Promise<number[][]> => {
+    const request = async (): Promise<number[][]> => {
+      const controller = new AbortController();
+      const timeout = setTimeout(() => {
+        controller.abort();
+      }, this.requests.timeout);
+
+      try {
+        const response = await fetch(`${this.apiBase.replace(/\/+$/, '')}/embeddings`, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+            ...(this.apiKey && { Authorization: `Bearer ${this.apiKey}` }),
+          },
+          body: JSON.stringify({ model: modelName, input, dimensions }),
+          signal: controller.signal,
+        });
+
+        if (!response.ok) {
+          const body = await response.text();
+          throw new Error(`HTTP ${String(response.status)}: ${body.slice(0, 200) || response.statusText}`);
+        }
+
+        const data = (await response.json()) as OpenAIEmbeddingResponse;
+        const vectors = [...data.data].sort((a, b) => a.index - b.index).map((entry) => entry.embedding);
+        return checkDimensions(vectors, dimensions, modelName);
+      } catch (error) {
+        if (error instanceof Error && error.name === 'AbortError') {
+          throw new RequestTimeoutError(`Generate embedding with ${modelName}`, this.requests.timeout);
+        }
+        if (error instanceof VectorDimensionError) throw error;
+        const cause = error instanceof Error ? error : new Error(String(error));
+        throw new EmbeddingGenerationError(modelName, input.join('\n'), cause);
+      } finally {
+        clearTimeout(timeout);
+      }
+    };
+
+    return retryWithBackoff(request, this.requests.retry_attempts, 1000, `Generate embedding with ${modelName}`);
+  };
+}
+
+/**
+ * Feature-extraction pipeline of Transformers.js
+ */
+type FeatureExtractor = (
+  texts: string[],
+  options: { pooling: 'mean'; normalize: boolean }
+) => Promise<{ tolist: () => number[][] }>;
+
+/**
+ * The part of Transformers.js the ONNX embedder uses
+ */
+interface TransformersModule {
+  pipeline: (task: 'feature-extraction', model: string) => Promise<FeatureExtractor>;
+}
+
+/** Optional package that runs ONNX models (not a dependency: installed by those who use it) */
+const TRANSFORMERS_PACKAGE = '@huggingface/transformers';
+
+/** Texts run through the model at a time */
+const ONNX_BATCH_SIZE = 16;
+
+/**
+ * Embedder running an ONNX model in-process
+ *
+ * The model is a Hugging Face model id with ONNX weights (Xenova/bge-m3,
+ * Xenova/all-MiniLM-L6-v2) or a local directory holding one; it is downloaded
+ * on first use and cached. Vectors are mean-pooled and normalized. Needs the
+ * optional @huggingface/transformers package.
+ */
+export class OnnxEmbedder implements Embedder {
+  /** Pipelines by model, loaded on first use */
+  private readonly extractors = new Map<string, Promise<FeatureExtractor>>();
+
+  async generateEmbedding(modelName: string, text: string, expectedDimensions: number): Promise<number[]> {
+    const [embedding] = await this.embed(modelName, [text], expectedDimensions);
+    return embedding;
+  }
+
+  generateEmbeddingBatch(modelName: string, texts: string[], expectedDimensions: number): Promise<number[][]> {
+    return embedInSlices(texts, ONNX_BATCH_SIZE, (slice) => this.embed(modelName, slice, expectedDimensions));
+  }
+
+  /**
+   * Embed texts in one model run
+   */
+  private embed = async (modelName: string, texts: string[], dimensions: number): Promise<number[][]> => {
+    const extractor = await this.load(modelName);
+    try {
+      const output = await extractor(texts, { pooling: 'mean', normalize: true });
+      return checkDimensions(output.tolist(), dimensions, modelName);
+    } catch (error) {
+      if (error instanceof VectorDimensionError) throw error;
+      const cause = error instanceof Error ? error : new Error(String(error));
+      throw new EmbeddingGenerationError(modelName, texts.join('\n'), cause);
+    }
+  };
+
+  /**
+   * Load a model's pipeline once
+   *
+   * @throws {ConfigurationError} If @huggingface/transformers is not installed or the model cannot be loaded
+   */
+  private load = (modelName: string): Promise<FeatureExtractor> => {
+    let extractor = this.extractors.get(modelName);
+    if (!extractor) {
+      extractor = import(TRANSFORMERS_PACKAGE)
+        .catch((error: unknown) => {
+          throw new ConfigurationError(
+            'Local ONNX embeddings need the @huggingface/transformers package',
+            { cause: error instanceof Error ? error.message : String(error) },
+            'Install it next to cindex: npm install -g @huggingface/transformers'
+          );
+        })
+        .then((transformers) => (transformers as TransformersModule).pipeline('feature-extraction', modelName))
+        .catch((error: unknown) => {
+          this.extractors.delete(modelName);
+          if (error instanceof ConfigurationError) throw error;
+          throw new ConfigurationError(
+            `Cannot load ONNX embedding model '${modelName}'`,
+            { cause: error instanceof Error ? error.message : String(error) },
+            'Set EMBEDDING_MODEL to a Hugging Face model id with ONNX weights (e.g. Xenova/bge-m3) or a model directory'
+          );
+        });
+      this.extractors.set(modelName, extractor);
+    }
+    return extractor;
+  };
+}
+
+/**
+ * Embedder of the configured provider
+ *
+ * @param config - Loaded configuration
+ * @param ollama - Ollama client (the embedder for the ollama provider)
+ * @returns Embedder for indexing and query embeddings
+ */
+export const createEmbedder = (config: CindexConfig, ollama: OllamaClient): Embedder => {
+  switch (config.embedding.provider) {
+    case 'openai':
+      return new OpenAIEmbedder(
+        config.embedding.api_base ?? 'https://api.openai.com/v1',
+        config.embedding.api_key,
+        config.ollama
+      );
+    case 'onnx':
+      return new OnnxEmbedder();
+    case 'ollama':
+      return ollama;
+  }
+};
+
+/**
+ * Embedding model Ollama must serve
+ *
+ * @param config - Embedding configuration
+ * @returns Model name, or null when another provider embeds
+ */
+export const ollamaEmbeddingModel = (config: EmbeddingConfig): string | null =>
+  config.provider === 'ollama' ? config.model : null;
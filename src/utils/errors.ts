@@ -277,6 +277,66 @@ export class RequestTimeoutError extends CindexError {
   }
 }
 
+/**
+ * Index locked error - another process is writing the same index
+ */
+export class IndexLockedError extends CindexError {
+  constructor(repoId: string, pid: number, lockFile: string, startedAt?: string) {
+    super(
+      `Index '${repoId}' is locked by PID ${String(pid)}`,
+      'INDEX_LOCKED',
+      { repo_id: repoId, pid, lock_file: lockFile, started_at: startedAt },
+      `Wait for the other indexing run to finish, or pass --wait to queue behind it.\nIf PID ${String(pid)} is not a cindex process, delete ${lockFile}.`
+    );
+  }
+}
+
+/**
+ * Snapshot error - an index snapshot (cindex export) that cannot be read
+ */
+export class SnapshotFormatError extends CindexError {
+  constructor(message: string, details?: unknown) {
+    super(message, 'INVALID_SNAPSHOT', details, 'Export the index again with: cindex export <repo-id> -o <file>');
+  }
+}
+
+/**
+ * Packed index error - a packed content index (cindex export --format=packed) that cannot be read
+ */
+export class PackedIndexError extends CindexError {
+  constructor(message: string, details?: unknown) {
+    super(
+      message,
+      'INVALID_PACKED_INDEX',
+      details,
+      'Write it again with: cindex export <repo-id> --format=packed -o <file>'
+    );
+  }
+}
+
+/**
+ * Store error - the symbol and doc-comment search store (STORE_BACKEND) cannot be opened
+ */
+export class StoreError extends CindexError {
+  constructor(message: string, details?: unknown) {
+    super(message, 'STORE_ERROR', details, 'Set STORE_BACKEND=postgres to search PostgreSQL directly');
+  }
+}
+
+/**
+ * Snapshot version error - the snapshot needs a newer cindex to import
+ */
+export class SnapshotVersionError extends CindexError {
+  constructor(version: number, minReaderVersion: number, supported: number) {
+    super(
+      `Snapshot format version ${String(version)} requires a reader of version ${String(minReaderVersion)} or later (this cindex reads version ${String(supported)})`,
+      'SNAPSHOT_VERSION_UNSUPPORTED',
+      { version, min_reader_version: minReaderVersion, supported },
+      'Upgrade cindex to import this snapshot, or export it with the version of cindex used here.'
+    );
+  }
+}
+
 /**
  * Check if error is retriable (transient network/connection failure)
  *
@@ -34,7 +34,14 @@ export interface HybridSqlComponents {
   selectExpressions: string;
   /** WHERE clause conditions for hybrid filtering */
   whereCondition: string;
-  /** ORDER BY clause for ranking */
+  /**
+   * ORDER BY clause for ranking
+   *
+   * Hybrid ranking breaks score ties by path (and line) so equal scores come
+   * back in the same order on every run. Vector-only ranking orders by distance
+   * alone: a secondary key would stop PostgreSQL from using the HNSW index, so
+   * callers sort ties after the query instead.
+   */
   orderBy: string;
   /** Parameter placeholder for the query text (for ts_rank) */
   queryTextParam: string;
@@ -96,7 +103,8 @@ export const buildFileLevelHybridSql = (
       OR (summary_tsv IS NOT NULL AND ts_rank_cd(summary_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})) > 0.01)
     )`,
     orderBy: `(${vectorWeight.toString()} * (1 - (summary_embedding <=> $${embeddingParamIndex.toString()}::vector))) +
-      (${keywordWeight.toString()} * COALESCE(ts_rank_cd(summary_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})), 0)) DESC`,
+      (${keywordWeight.toString()} * COALESCE(ts_rank_cd(summary_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})), 0)) DESC,
+      file_path`,
     queryTextParam: `$${queryTextParamIndex.toString()}`,
   };
 };
@@ -143,7 +151,8 @@ export const buildChunkLevelHybridSql = (
       OR (content_tsv IS NOT NULL AND ts_rank_cd(content_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})) > 0.01)
     )`,
     orderBy: `(${vectorWeight.toString()} * (1 - (embedding <=> $${embeddingParamIndex.toString()}::vector))) +
-      (${keywordWeight.toString()} * COALESCE(ts_rank_cd(content_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})), 0)) DESC`,
+      (${keywordWeight.toString()} * COALESCE(ts_rank_cd(content_tsv, plainto_tsquery('english', $${queryTextParamIndex.toString()})), 0)) DESC,
+      file_path, start_line`,
     queryTextParam: `$${queryTextParamIndex.toString()}`,
   };
 };
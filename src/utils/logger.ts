@@ -57,6 +57,13 @@ class Logger {
     this.config.level = level;
   };
 
+  /**
+   * Current minimum log level
+   *
+   * @returns Level set by setLevel (INFO by default)
+   */
+  getLevel = (): LogLevel => this.config.level;
+
   /**
    * Enable or disable colored output
    *
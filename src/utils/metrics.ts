@@ -0,0 +1,200 @@
+/**
+ * Prometheus metrics of a long-running process (cindex serve, cindex watch)
+ *
+ * Counters and histograms are kept in memory for the life of the process
+ * and rendered in the Prometheus text exposition format (version 0.0.4) by
+ * the /metrics endpoint:
+ *
+ *   cindex_files_indexed_total{repo_id}          files written to the index
+ *   cindex_parse_errors_total{language}          files that failed to parse or needed the fallback parser
+ *   cindex_phase_duration_seconds{phase}         parse, extract, and store phases of one file
+ *   cindex_query_duration_seconds{api,endpoint}  HTTP and LSP requests answered
+ *   cindex_index_lock_waits_total{lock}          write or read locks that had to wait (see @indexing/index-lock)
+ *   cindex_index_lock_wait_seconds{lock}         time spent waiting for them
+ *   cindex_read_retries_total                    reads retried because a writer changed their shards
+ *
+ * A one-shot command records them too; nothing is kept across processes.
+ */
+
+/** Label names and values of one series */
+export type MetricLabels = Record<string, string>;
+
+/** Upper bounds of the duration buckets, in seconds */
+const DURATION_BUCKETS = [0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10];
+
+/**
+ * Key of a series: its labels in name order
+ */
+const seriesKey = (labels: MetricLabels): string =>
+  Object.keys(labels)
+    .sort()
+    .map((name) => `${name}=${labels[name]}`)
+    .join(',');
+
+/**
+ * Escape a label value (backslash, double quote, newline)
+ */
+const escapeLabel = (value: string): string => value.replace(/\\/g, '\\\\').replace(/"/g, '\\"').replace(/\n/g, '\\n');
+
+/**
+ * Labels as written after a metric name ({a="1",b="2"}, or nothing)
+ */
+const formatLabels = (labels: MetricLabels): string => {
+  const names = Object.keys(labels).sort();
+  if (names.length === 0) return '';
+  return `{${names.map((name) => `${name}="${escapeLabel(labels[name])}"`).join(',')}}`;
+};
+
+/**
+ * Sample value as Prometheus writes it
+ */
+const formatValue = (value: number): string => (value === Infinity ? '+Inf' : String(value));
+
+/**
+ * Counter: a total that only goes up
+ */
+export class Counter {
+  private readonly series = new Map<string, { labels: MetricLabels; value: number }>();
+
+  constructor(
+    public readonly name: string,
+    public readonly help: string
+  ) {}
+
+  /**
+   * Add to the series of a set of labels
+   *
+   * @param labels - Labels of the series (default: none)
+   * @param amount - Amount added (default 1)
+   */
+  public inc = (labels: MetricLabels = {}, amount = 1): void => {
+    const key = seriesKey(labels);
+    const series = this.series.get(key);
+    if (series) series.value += amount;
+    else this.series.set(key, { labels, value: amount });
+  };
+
+  /**
+   * Current value of a series (0 if never incremented)
+   */
+  public value = (labels: MetricLabels = {}): number => this.series.get(seriesKey(labels))?.value ?? 0;
+
+  public render = (): string[] => [
+    `# HELP ${this.name} ${this.help}`,
+    `# TYPE ${this.name} counter`,
+    ...[...this.series.values()].map(({ labels, value }) => `${this.name}${formatLabels(labels)} ${String(value)}`),
+  ];
+
+  public reset = (): void => {
+    this.series.clear();
+  };
+}
+
+/**
+ * Histogram: observations counted into cumulative buckets
+ */
+export class Histogram {
+  private readonly series = new Map<string, { labels: MetricLabels; counts: number[]; sum: number; count: number }>();
+
+  constructor(
+    public readonly name: string,
+    public readonly help: string,
+    private readonly buckets: number[] = DURATION_BUCKETS
+  ) {}
+
+  /**
+   * Record one observation
+   *
+   * @param value - Observed value (seconds for durations)
+   * @param labels - Labels of the series (default: none)
+   */
+  public observe = (value: number, labels: MetricLabels = {}): void => {
+    const key = seriesKey(labels);
+    let series = this.series.get(key);
+    if (!series) {
+      series = { labels, counts: this.buckets.map(() => 0), sum: 0, count: 0 };
+      this.series.set(key, series);
+    }
+    this.buckets.forEach((bound, index) => {
+      if (value <= bound && series) series.counts[index]++;
+    });
+    series.sum += value;
+    series.count++;
+  };
+
+  /**
+   * Observations of a series so far (0 if none)
+   */
+  public count = (labels: MetricLabels = {}): number => this.series.get(seriesKey(labels))?.count ?? 0;
+
+  public render = (): string[] => {
+    const lines = [`# HELP ${this.name} ${this.help}`, `# TYPE ${this.name} histogram`];
+    for (const { labels, counts, sum, count } of this.series.values()) {
+      [...this.buckets, Infinity].forEach((bound, index) => {
+        const bucketLabels = formatLabels({ ...labels, le: formatValue(bound) });
+        lines.push(`${this.name}_bucket${bucketLabels} ${String(index < counts.length ? counts[index] : count)}`);
+      });
+      lines.push(`${this.name}_sum${formatLabels(labels)} ${String(sum)}`);
+      lines.push(`${this.name}_count${formatLabels(labels)} ${String(count)}`);
+    }
+    return lines;
+  };
+
+  public reset = (): void => {
+    this.series.clear();
+  };
+}
+
+export const filesIndexed = new Counter('cindex_files_indexed_total', 'Files written to the index');
+
+export const parseErrors = new Counter(
+  'cindex_parse_errors_total',
+  'Files that failed to parse or were indexed with the fallback parser'
+);
+
+export const phaseDuration = new Histogram(
+  'cindex_phase_duration_seconds',
+  'Time to parse, extract symbols from, and store one file'
+);
+
+export const queryDuration = new Histogram('cindex_query_duration_seconds', 'Time to answer an HTTP or LSP request');
+
+export const lockWaits = new Counter('cindex_index_lock_waits_total', 'Index lock acquisitions that had to wait');
+
+export const lockWaitDuration = new Histogram('cindex_index_lock_wait_seconds', 'Time spent waiting for an index lock');
+
+export const readRetries = new Counter(
+  'cindex_read_retries_total',
+  'Reads retried because an indexing run changed their shards'
+);
+
+/** Every metric, in the order rendered */
+const METRICS: (Counter | Histogram)[] = [
+  filesIndexed,
+  parseErrors,
+  phaseDuration,
+  queryDuration,
+  lockWaits,
+  lockWaitDuration,
+  readRetries,
+];
+
+/** Content type of rendered metrics */
+export const METRICS_CONTENT_TYPE = 'text/plain; version=0.0.4; charset=utf-8';
+
+/**
+ * Seconds elapsed since a performance.now() reading
+ */
+export const secondsSince = (start: number): number => (performance.now() - start) / 1000;
+
+/**
+ * Render every metric in the Prometheus text format
+ */
+export const renderMetrics = (): string => METRICS.flatMap((metric) => metric.render()).join('\n') + '\n';
+
+/**
+ * Clear every series (tests)
+ */
+export const resetMetrics = (): void => {
+  for (const metric of METRICS) metric.reset();
+};
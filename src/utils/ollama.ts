@@ -5,6 +5,7 @@
 
 import { type OllamaConfig } from '@/types/config';
 
+import { type Embedder } from './embedders';
 import {
   EmbeddingGenerationError,
   ModelNotFoundError,
@@ -15,6 +16,9 @@ import {
 } from './errors';
 import { logger } from './logger';
 
+/** Fixed sampling seed for summaries (reproducible index builds) */
+const SUMMARY_SEED = 42;
+
 /**
  * Response from Ollama /api/tags endpoint
  */
@@ -53,7 +57,7 @@ interface OllamaGenerateResponse {
  * - LLM-based summary generation
  * - Batch operations with concurrency control
  */
-export class OllamaClient {
+export class OllamaClient implements Embedder {
   /**
    * Create Ollama client
    *
@@ -88,12 +92,12 @@ export class OllamaClient {
   /**
    * Health check - verify Ollama is running and models are available
    *
-   * @param embeddingModel - Name of embedding model to validate
+   * @param embeddingModel - Name of embedding model to validate (null: another provider embeds)
    * @param summaryModel - Name of summary model to validate
    * @throws {OllamaConnectionError} If Ollama is not accessible
    * @throws {ModelNotFoundError} If required models are not available
    */
-  async healthCheck(embeddingModel: string, summaryModel: string): Promise<void> {
+  async healthCheck(embeddingModel: string | null, summaryModel: string): Promise<void> {
     logger.debug('Performing Ollama health check', {
       host: this.config.host,
       embeddingModel,
@@ -109,7 +113,7 @@ export class OllamaClient {
     logger.healthCheck('Ollama', 'OK', { host: this.config.host });
 
     // Check if models are available
-    await this.checkModelAvailable(embeddingModel);
+    if (embeddingModel) await this.checkModelAvailable(embeddingModel);
     await this.checkModelAvailable(summaryModel);
 
     logger.info('All Ollama models available', {
@@ -243,7 +247,8 @@ export class OllamaClient {
   /**
    * Generate text summary using LLM
    *
-   * Uses retry logic with exponential backoff for transient failures.
+   * Uses retry logic with exponential backoff for transient failures. Sampling
+   * is deterministic so re-indexing an unchanged file reproduces its summary.
    *
    * @param modelName - Name of LLM model (e.g., "qwen2.5-coder:7b")
    * @param prompt - Prompt for summary generation
@@ -269,11 +274,12 @@ export class OllamaClient {
             model: modelName,
             prompt,
             stream: false,
-            ...(contextWindow && {
-              options: {
-                num_ctx: contextWindow,
-              },
-            }),
+            // Greedy decoding with a fixed seed: the same file always gets the same summary
+            options: {
+              temperature: 0,
+              seed: SUMMARY_SEED,
+              ...(contextWindow && { num_ctx: contextWindow }),
+            },
           }),
           signal: controller.signal,
         });
@@ -0,0 +1,38 @@
+/**
+ * Stable ordering helpers
+ *
+ * Indexing the same tree twice must produce the same files, chunks, and
+ * result order. Directory listings, hash maps, and equal-score results have
+ * no inherent order, so every sort that reaches output ends with a total,
+ * locale-independent tie-breaker (path, then line).
+ */
+
+/**
+ * Compare strings by UTF-16 code unit
+ *
+ * Unlike localeCompare, the result does not depend on the ICU build or the
+ * process locale, so the order is identical on every machine.
+ */
+export const compareStrings = (a: string, b: string): number => {
+  if (a === b) return 0;
+  return a < b ? -1 : 1;
+};
+
+/**
+ * Compare named entries (e.g. directory listings) by name
+ */
+export const compareNames = (a: { name: string }, b: { name: string }): number => compareStrings(a.name, b.name);
+
+/**
+ * Build a comparator for ranked results: higher score first, then file path, then start line
+ *
+ * @param score - Ranking score of an item (higher ranks first)
+ * @returns Comparator for Array.prototype.sort
+ */
+export const compareRanked =
+  <T extends { file_path: string; start_line?: number }>(score: (item: T) => number) =>
+  (a: T, b: T): number => {
+    const difference = score(b) - score(a);
+    if (difference !== 0) return difference;
+    return compareStrings(a.file_path, b.file_path) || (a.start_line ?? 0) - (b.start_line ?? 0);
+  };
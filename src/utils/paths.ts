@@ -0,0 +1,108 @@
+/**
+ * Cross-platform path helpers
+ *
+ * Stored paths are always relative to the repository root and use forward
+ * slashes, so an index built on Windows can be queried on Linux or macOS and
+ * vice versa. Native paths are only used to touch the filesystem.
+ */
+import * as fs from 'node:fs/promises';
+import * as path from 'node:path';
+
+/** Win32 namespace prefixes used for long paths (\\?\C:\... and \\?\UNC\server\share) */
+const NAMESPACE_PREFIX = /^\\\\\?\\(UNC\\)?/i;
+
+/**
+ * Convert a native relative path to the stored form (forward slashes)
+ *
+ * Only the native separator is converted: on POSIX a backslash is a valid
+ * file name character, on Windows both separators are accepted.
+ */
+export const toPosixPath = (nativePath: string): string => {
+  return path.sep === '\\' ? nativePath.replace(/\\/g, '/') : nativePath;
+};
+
+/**
+ * Convert a user-entered path filter to the stored form
+ *
+ * Unlike toPosixPath, backslashes are converted on every OS so that a path
+ * copied from Windows matches an index built anywhere.
+ */
+export const toStoredPath = (input: string): string => {
+  return input.replace(/\\/g, '/').replace(/^\.\//, '');
+};
+
+/**
+ * Match a path reported by an external tool to a stored path
+ *
+ * Tools name files by absolute path, import path
+ * (github.com/org/repo/pkg/file.go), or path relative to where they ran; the
+ * stored path with the longest matching suffix at a directory boundary wins.
+ *
+ * @param reported - Path as the tool reported it
+ * @param storedPaths - Stored file paths
+ * @returns The matching stored path, or null
+ */
+export const matchStoredPath = (reported: string, storedPaths: Iterable<string>): string | null => {
+  const normalized = toStoredPath(reported);
+  let best: string | null = null;
+  for (const file of storedPaths) {
+    if (normalized !== file && !normalized.endsWith(`/${file}`)) continue;
+    if (best === null || file.length > best.length) best = file;
+  }
+  return best;
+};
+
+/**
+ * Normalize a repository root for use as a key and in messages
+ *
+ * Resolves to an absolute path, strips the Win32 long-path prefix, upper-cases
+ * the drive letter, and drops a trailing separator.
+ *
+ * @param rootPath - Repository root as given by the user
+ * @returns Absolute, normalized root path
+ */
+export const normalizeRootPath = (rootPath: string): string => {
+  let resolved = path.resolve(rootPath);
+  const prefix = NAMESPACE_PREFIX.exec(resolved);
+  if (prefix) {
+    resolved = (prefix[1] ? '\\\\' : '') + resolved.slice(prefix[0].length);
+  }
+  resolved = resolved.replace(/^([a-z]):/, (_match, drive: string) => `${drive.toUpperCase()}:`);
+  return resolved.length > path.parse(resolved).root.length ? resolved.replace(/[\\/]+$/, '') : resolved;
+};
+
+/**
+ * Detect whether the filesystem holding a directory ignores case
+ *
+ * Looks up the directory under its case-swapped name; Windows and macOS
+ * volumes are usually case-insensitive, Linux volumes usually are not.
+ *
+ * @param directory - Existing directory whose name contains a letter
+ * @returns True if the case-swapped path names the same directory
+ */
+export const isCaseInsensitiveFs = async (directory: string): Promise<boolean> => {
+  const base = path.basename(directory);
+  const swapped = base.replace(/\p{L}/gu, (char) =>
+    char === char.toLowerCase() ? char.toUpperCase() : char.toLowerCase()
+  );
+  if (swapped === base) {
+    return process.platform === 'win32' || process.platform === 'darwin';
+  }
+
+  try {
+    const [original, other] = await Promise.all([
+      fs.stat(directory),
+      fs.stat(path.join(path.dirname(directory), swapped)),
+    ]);
+    return original.ino === other.ino && original.dev === other.dev;
+  } catch {
+    return false;
+  }
+};
+
+/**
+ * Comparison key for a path (lower-cased on case-insensitive filesystems)
+ */
+export const pathKey = (filePath: string, caseInsensitive: boolean): string => {
+  return caseInsensitive ? filePath.toLowerCase() : filePath;
+};
@@ -0,0 +1,89 @@
+/**
+ * Column encodings for reported positions
+ *
+ * tree-sitter (and JavaScript strings) count columns in UTF-16 code units,
+ * which is what LSP clients expect. Most CLI tools (grep, compilers, editors'
+ * `file:line:col` jump) count UTF-8 bytes. The two agree on ASCII lines and
+ * drift apart on every non-ASCII character before the column, so positions
+ * are stored in both units and the caller picks one.
+ */
+
+/**
+ * Unit of a reported column
+ */
+export type PositionEncoding = 'utf-8' | 'utf-16';
+
+/** Accepted position encodings */
+export const POSITION_ENCODINGS: readonly PositionEncoding[] = ['utf-8', 'utf-16'];
+
+/**
+ * Check whether a string is a supported position encoding
+ */
+export const isPositionEncoding = (value: string): value is PositionEncoding => {
+  return (POSITION_ENCODINGS as readonly string[]).includes(value);
+};
+
+/**
+ * Convert a 1-based UTF-16 column to a 1-based UTF-8 byte column
+ *
+ * @param lineText - Text of the line the column refers to
+ * @param column - 1-based column in UTF-16 code units
+ * @returns 1-based column in UTF-8 bytes
+ */
+export const utf16ToByteColumn = (lineText: string, column: number): number => {
+  return Buffer.byteLength(lineText.slice(0, Math.max(column - 1, 0)), 'utf8') + 1;
+};
+
+/**
+ * Convert a 1-based UTF-8 byte column to a 1-based UTF-16 column
+ *
+ * A column inside a multi-byte character maps to that character.
+ *
+ * @param lineText - Text of the line the column refers to
+ * @param byteColumn - 1-based column in UTF-8 bytes
+ * @returns 1-based column in UTF-16 code units
+ */
+export const byteToUtf16Column = (lineText: string, byteColumn: number): number => {
+  let bytes = 0;
+  let index = 0;
+  for (const char of lineText) {
+    bytes += Buffer.byteLength(char, 'utf8');
+    if (bytes >= byteColumn) break;
+    index += char.length;
+  }
+  return index + 1;
+};
+
+/**
+ * Pick the column to report from one stored in both encodings
+ *
+ * Positions recorded before byte columns were stored only have the UTF-16
+ * column, which is also the byte column on ASCII lines.
+ *
+ * @param position - Column in UTF-16 code units, and in bytes when known
+ * @param encoding - Requested unit
+ * @returns 1-based column in the requested unit
+ */
+export const selectColumn = (
+  position: { column: number; byte_column?: number | null },
+  encoding: PositionEncoding
+): number => {
+  return encoding === 'utf-8' ? (position.byte_column ?? position.column) : position.column;
+};
+
+/** Length of the UTF-8 byte order mark (EF BB BF) */
+const UTF8_BOM_BYTES = 3;
+
+/**
+ * Map a byte column in decoded text back to the file's bytes
+ *
+ * The BOM is stripped before parsing, so byte columns on the first line of a
+ * file that had one are short by its length.
+ *
+ * @param position - 1-based line and byte column in the decoded text
+ * @param hadUtf8Bom - Whether the file started with a UTF-8 BOM
+ * @returns 1-based byte column in the original file
+ */
+export const originalByteColumn = (position: { line: number; byte_column: number }, hadUtf8Bom: boolean): number => {
+  return position.byte_column + (hadUtf8Bom && position.line === 1 ? UTF8_BOM_BYTES : 0);
+};
@@ -0,0 +1,42 @@
+/**
+ * Signal handling for graceful shutdown
+ *
+ * The first SIGINT/SIGTERM asks the process to wind down (finish the file in
+ * progress, write a checkpoint, close connections). A second signal exits
+ * immediately for users who really want out.
+ */
+
+import { logger } from '@utils/logger';
+import { ExitCode } from '@/types/cli';
+
+/** Signals that request shutdown */
+const SHUTDOWN_SIGNALS: NodeJS.Signals[] = ['SIGINT', 'SIGTERM'];
+
+/**
+ * Install SIGINT/SIGTERM handlers
+ *
+ * @param onShutdown - Called once, on the first signal
+ * @returns Function that removes the handlers
+ */
+export const handleShutdownSignals = (onShutdown: (signal: NodeJS.Signals) => void): (() => void) => {
+  let requested = false;
+
+  const listener = (signal: NodeJS.Signals): void => {
+    if (requested) {
+      logger.warn(`Received ${signal} again, exiting immediately`);
+      process.exit(ExitCode.Interrupted);
+    }
+    requested = true;
+    onShutdown(signal);
+  };
+
+  for (const signal of SHUTDOWN_SIGNALS) {
+    process.on(signal, listener);
+  }
+
+  return () => {
+    for (const signal of SHUTDOWN_SIGNALS) {
+      process.removeListener(signal, listener);
+    }
+  };
+};
@@ -0,0 +1,226 @@
+/**
+ * Tracing spans of the indexer, exported to OpenTelemetry
+ *
+ * withSpan times a piece of work as a span; spans started inside it become
+ * its children (the current span follows async calls). With no collector
+ * configured spans are only logged at DEBUG. Setting the standard
+ * OpenTelemetry variables exports them with OTLP over HTTP (JSON):
+ *
+ *   OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   full URL of the traces endpoint
+ *   OTEL_EXPORTER_OTLP_ENDPOINT          base URL of the collector (/v1/traces is appended)
+ *   OTEL_EXPORTER_OTLP_HEADERS           extra headers, key=value pairs separated by commas
+ *   OTEL_SERVICE_NAME                    service.name of the spans (default: cindex)
+ *
+ * Spans are sent in batches, at most EXPORT_INTERVAL_MS after they end, and
+ * once more by flushTraces before the process exits. A collector that cannot
+ * be reached costs a warning, never an indexing run.
+ */
+
+import { AsyncLocalStorage } from 'node:async_hooks';
+import { randomBytes } from 'node:crypto';
+
+import { logger } from '@utils/logger';
+
+/** Attribute values a span records */
+export type SpanAttributes = Record<string, string | number | boolean>;
+
+/**
+ * A finished span, as kept until it is exported
+ */
+export interface FinishedSpan {
+  traceId: string;
+  spanId: string;
+  parentSpanId?: string;
+  name: string;
+  /** Epoch nanoseconds */
+  startTimeUnixNano: bigint;
+  endTimeUnixNano: bigint;
+  attributes: SpanAttributes;
+  error?: string;
+}
+
+/** Interval between batches sent to the collector */
+const EXPORT_INTERVAL_MS = 5000;
+
+/** Spans that trigger a batch before the interval is up */
+const EXPORT_BATCH_SIZE = 512;
+
+/** Spans kept at most while the collector is unreachable (the oldest are dropped) */
+const MAX_QUEUED_SPANS = 8192;
+
+/** Span of the code running now */
+const currentSpan = new AsyncLocalStorage<{ traceId: string; spanId: string }>();
+
+/** Spans waiting to be exported */
+const queue: FinishedSpan[] = [];
+
+let exportTimer: NodeJS.Timeout | null = null;
+let exportFailed = false;
+
+/**
+ * Wall-clock time in epoch nanoseconds
+ */
+const nowNanos = (): bigint => BigInt(Math.round((performance.timeOrigin + performance.now()) * 1e6));
+
+/**
+ * URL spans are exported to, or null if no collector is configured
+ */
+export const tracesEndpoint = (env: NodeJS.ProcessEnv = process.env): string | null => {
+  const traces = env.OTEL_EXPORTER_OTLP_TRACES_ENDPOINT?.trim();
+  if (traces) return traces;
+  const base = env.OTEL_EXPORTER_OTLP_ENDPOINT?.trim();
+  return base ? `${base.replace(/\/+$/, '')}/v1/traces` : null;
+};
+
+/**
+ * Parse OTEL_EXPORTER_OTLP_HEADERS (api-key=secret,x-tenant=a)
+ */
+export const parseOtlpHeaders = (value: string | undefined): Record<string, string> => {
+  const headers: Record<string, string> = {};
+  for (const pair of (value ?? '').split(',')) {
+    const separator = pair.indexOf('=');
+    if (separator <= 0) continue;
+    headers[decodeURIComponent(pair.slice(0, separator).trim())] = decodeURIComponent(pair.slice(separator + 1).trim());
+  }
+  return headers;
+};
+
+/**
+ * OTLP attribute list of span attributes
+ */
+const otlpAttributes = (attributes: SpanAttributes): { key: string; value: Record<string, unknown> }[] =>
+  Object.entries(attributes).map(([key, value]) => {
+    if (typeof value === 'boolean') return { key, value: { boolValue: value } };
+    if (typeof value === 'number') {
+      return { key, value: Number.isInteger(value) ? { intValue: String(value) } : { doubleValue: value } };
+    }
+    return { key, value: { stringValue: value } };
+  });
+
+/**
+ * OTLP/JSON export request of a batch of spans
+ *
+ * @param spans - Finished spans
+ * @param serviceName - service.name resource attribute
+ */
+export const toOtlpRequest = (spans: FinishedSpan[], serviceName: string): unknown => ({
+  resourceSpans: [
+    {
+      resource: { attributes: otlpAttributes({ 'service.name': serviceName }) },
+      scopeSpans: [
+        {
+          scope: { name: 'cindex' },
+          spans: spans.map((span) => ({
+            traceId: span.traceId,
+            spanId: span.spanId,
+            ...(span.parentSpanId ? { parentSpanId: span.parentSpanId } : {}),
+            name: span.name,
+            // SPAN_KIND_INTERNAL
+            kind: 1,
+            startTimeUnixNano: String(span.startTimeUnixNano),
+            endTimeUnixNano: String(span.endTimeUnixNano),
+            attributes: otlpAttributes(span.attributes),
+            // STATUS_CODE_ERROR or STATUS_CODE_UNSET
+            status: span.error !== undefined ? { code: 2, message: span.error } : { code: 0 },
+          })),
+        },
+      ],
+    },
+  ],
+});
+
+/**
+ * Send the queued spans to the collector
+ *
+ * Resolves once the batch was sent or given up on; safe to call with no collector configured.
+ */
+export const flushTraces = async (): Promise<void> => {
+  if (exportTimer) {
+    clearTimeout(exportTimer);
+    exportTimer = null;
+  }
+  const endpoint = tracesEndpoint();
+  if (!endpoint || queue.length === 0) return;
+
+  const batch = queue.splice(0, queue.length);
+  try {
+    const response = await fetch(endpoint, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', ...parseOtlpHeaders(process.env.OTEL_EXPORTER_OTLP_HEADERS) },
+      body: JSON.stringify(toOtlpRequest(batch, process.env.OTEL_SERVICE_NAME?.trim() || 'cindex')),
+      signal: AbortSignal.timeout(EXPORT_INTERVAL_MS),
+    });
+    if (!response.ok) throw new Error(`HTTP ${String(response.status)}`);
+    exportFailed = false;
+  } catch (error) {
+    // Keep the batch for the next attempt, within bounds
+    queue.unshift(...batch.slice(-(MAX_QUEUED_SPANS - queue.length)));
+    if (!exportFailed) {
+      logger.warn('Cannot export traces', { endpoint, error: error instanceof Error ? error.message : String(error) });
+      exportFailed = true;
+    }
+  }
+};
+
+/**
+ * Queue a finished span for export
+ */
+const record = (span: FinishedSpan): void => {
+  logger.debug('Span', {
+    name: span.name,
+    trace_id: span.traceId,
+    duration_ms: Number(span.endTimeUnixNano - span.startTimeUnixNano) / 1e6,
+    ...span.attributes,
+    ...(span.error !== undefined ? { error: span.error } : {}),
+  });
+  if (!tracesEndpoint()) return;
+
+  if (queue.length >= MAX_QUEUED_SPANS) queue.shift();
+  queue.push(span);
+  if (queue.length >= EXPORT_BATCH_SIZE) {
+    void flushTraces();
+  } else if (!exportTimer) {
+    exportTimer = setTimeout(() => void flushTraces(), EXPORT_INTERVAL_MS);
+    exportTimer.unref();
+  }
+};
+
+/**
+ * Run work as a span
+ *
+ * The span ends when the work settles; a thrown error marks it failed and is rethrown.
+ *
+ * @param name - Span name (cindex.parse)
+ * @param attributes - Span attributes (file, language)
+ * @param work - Work the span times
+ * @returns Result of the work
+ */
+export const withSpan = async <T>(name: string, attributes: SpanAttributes, work: () => Promise<T>): Promise<T> => {
+  const parent = currentSpan.getStore();
+  const context = {
+    traceId: parent?.traceId ?? randomBytes(16).toString('hex'),
+    spanId: randomBytes(8).toString('hex'),
+  };
+  const span: FinishedSpan = {
+    ...context,
+    parentSpanId: parent?.spanId,
+    name,
+    startTimeUnixNano: nowNanos(),
+    endTimeUnixNano: 0n,
+    attributes,
+  };
+  try {
+    return await currentSpan.run(context, work);
+  } catch (error) {
+    span.error = error instanceof Error ? error.message : String(error);
+    throw error;
+  } finally {
+    span.endTimeUnixNano = nowNanos();
+    record(span);
+  }
+};
+
+/**
+ * Spans waiting to be exported (tests)
+ */
+export const queuedSpans = (): readonly FinishedSpan[] => queue;
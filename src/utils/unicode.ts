@@ -0,0 +1,53 @@
+/**
+ * Unicode helpers for identifiers and query text
+ *
+ * The same accented identifier can be encoded precomposed (NFC, "é" as one
+ * code point) or decomposed (NFD, "e" + combining accent), depending on the
+ * editor, OS, or input method. Source text and queries are normalized to NFC
+ * so both forms match, and identifier patterns accept any Unicode letter
+ * instead of ASCII \w.
+ */
+
+/**
+ * Identifier pattern source (use with the `u` flag)
+ *
+ * Letters, letter-like numbers, `_`, and `$` start an identifier; combining
+ * marks, digits, and connector punctuation may follow (Unicode UAX #31 plus `$`).
+ */
+export const IDENTIFIER_PATTERN = '[\\p{L}\\p{Nl}_$][\\p{L}\\p{Nl}\\p{Mn}\\p{Mc}\\p{Nd}\\p{Pc}$]*';
+
+/** Character that can continue an identifier (for word boundaries; `\b` is ASCII-only) */
+export const IDENTIFIER_CHAR_PATTERN = '[\\p{L}\\p{Nl}\\p{Mn}\\p{Mc}\\p{Nd}\\p{Pc}$]';
+
+/**
+ * Normalize text to Unicode NFC
+ *
+ * @param text - Source text, identifier, or query
+ * @returns NFC-normalized text (unchanged for ASCII)
+ */
+export const normalizeUnicode = (text: string): string => {
+  return text.normalize('NFC');
+};
+
+/**
+ * Words of an identifier: camelCase humps, initialisms, and digit runs
+ *
+ * @param identifier - Name such as SessionTimeout, parseHTTPRequest, or max_retries
+ * @returns Words as written (Session, Timeout; parse, HTTP, Request; max, retries)
+ */
+export const identifierWords = (identifier: string): string[] => {
+  return identifier.match(/\p{Lu}+(?!\p{Ll})|\p{Lu}?[\p{Ll}\p{Nd}]+|\p{Nd}+/gu) ?? [];
+};
+
+/**
+ * Check whether text contains an identifier as a whole word
+ *
+ * @param text - Text to search (e.g. a definition line)
+ * @param identifier - Identifier to find (case-sensitive)
+ * @returns True if the identifier appears with no identifier characters on either side
+ */
+export const containsIdentifier = (text: string, identifier: string): boolean => {
+  const escaped = normalizeUnicode(identifier).replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+  const pattern = new RegExp(`(?<!${IDENTIFIER_CHAR_PATTERN})${escaped}(?!${IDENTIFIER_CHAR_PATTERN})`, 'u');
+  return pattern.test(normalizeUnicode(text));
+};
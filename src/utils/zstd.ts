@@ -0,0 +1,33 @@
+/**
+ * Helpers shared by the zstd block formats: compact snapshots and packed indexes
+ *
+ * Both write zstd frames located by varint lengths. zstd is in node:zlib
+ * from Node.js 22.15, so each format checks for it before reading or writing.
+ */
+
+import * as zlib from 'node:zlib';
+
+/**
+ * Fail early when this Node.js has no zstd (before 22.15)
+ *
+ * @param feature - What needs zstd, starting the message (e.g. 'Packed indexes')
+ * @param error - Error class of the format
+ * @throws {Error} An `error` if zstd is unavailable
+ */
+export const requireZstd = (feature: string, error: new (message: string) => Error): void => {
+  if (typeof zlib.zstdCompressSync !== 'function') {
+    throw new error(`${feature} need Node.js 22.15 or later for zstd (running ${process.version})`);
+  }
+};
+
+/**
+ * Append a varint to encoded bytes
+ */
+export const pushVarint = (bytes: number[], value: number): void => {
+  let rest = value;
+  while (rest >= 0x80) {
+    bytes.push((rest % 0x80) | 0x80);
+    rest = Math.floor(rest / 0x80);
+  }
+  bytes.push(rest);
+};
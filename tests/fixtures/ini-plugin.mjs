@@ -0,0 +1,58 @@
+// Plugin process for tests/unit/indexing/plugins.test.ts (see src/indexing/plugin-host.ts)
+// Serves an extractor listing the [sections] of .ini files and an analyzer counting their keys
+
+let buffer = Buffer.alloc(0);
+
+const send = (message) => {
+  const body = JSON.stringify({ jsonrpc: '2.0', ...message });
+  process.stdout.write(`Content-Length: ${String(Buffer.byteLength(body))}\r\n\r\n${body}`);
+};
+
+const handle = ({ id, method, params }) => {
+  switch (method) {
+    case 'initialize':
+      send({
+        id,
+        result: {
+          name: 'ini',
+          extractors: [{ name: 'ini', language: 'ini', extensions: ['.ini'] }],
+          analyzers: [{ name: 'ini-keys', languages: ['ini'] }],
+        },
+      });
+      break;
+    case 'extract': {
+      const lines = params.content.split('\n');
+      const symbols = lines.flatMap((line, index) => {
+        const section = /^\[(.+)\]$/.exec(line.trim());
+        return section ? [{ name: section[1], kind: 'type', start_line: index + 1, end_line: index + 1 }] : [];
+      });
+      send({ id, result: { symbols } });
+      break;
+    }
+    case 'analyze': {
+      const keys = params.content.split('\n').filter((line) => line.includes('=')).length;
+      send({ id, result: { metrics: [{ name: 'ini_keys', value: keys }] } });
+      break;
+    }
+    case 'shutdown':
+      send({ id, result: null });
+      break;
+    case 'exit':
+      process.exit(0);
+      break;
+    default:
+      send({ id, error: { code: -32601, message: `Unknown method ${method}` } });
+  }
+};
+
+process.stdin.on('data', (chunk) => {
+  buffer = Buffer.concat([buffer, chunk]);
+  for (;;) {
+    const end = buffer.indexOf('\r\n\r\n');
+    if (end < 0) return;
+    const length = Number(/Content-Length: (\d+)/i.exec(buffer.subarray(0, end).toString())[1]);
+    if (buffer.length < end + 4 + length) return;
+    handle(JSON.parse(buffer.subarray(end + 4, end + 4 + length).toString()));
+    buffer = buffer.subarray(end + 4 + length);
+  }
+});
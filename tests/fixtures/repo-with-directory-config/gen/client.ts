@@ -0,0 +1 @@
+export const fetchUser = async (id: string): Promise<Response> => fetch(`/users/${id}`);
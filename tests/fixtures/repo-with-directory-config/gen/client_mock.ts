@@ -0,0 +1 @@
+export const fetchUser = async (): Promise<Response> => new Response('{}');
@@ -0,0 +1,29 @@
+/**
+ * Unit tests for CLI alias expansion
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { expandAlias } from '../../../src/cli/aliases';
+
+describe('expandAlias', () => {
+  test('should substitute positional placeholders', () => {
+    expect(expandAlias('search kind:type name:$1', ['Reader'])).toEqual(['search', 'kind:type', 'name:Reader']);
+  });
+
+  test('should append arguments not consumed by a placeholder', () => {
+    expect(expandAlias('search name:$1', ['Login', '--porcelain'])).toEqual(['search', 'name:Login', '--porcelain']);
+  });
+
+  test('should splice all arguments for $@', () => {
+    expect(expandAlias('search $@ path:src/', ['auth', 'kind:func'])).toEqual([
+      'search',
+      'auth',
+      'kind:func',
+      'path:src/',
+    ]);
+  });
+
+  test('should drop placeholders without a matching argument', () => {
+    expect(expandAlias('search $1 kind:func', [])).toEqual(['search', 'kind:func']);
+  });
+});
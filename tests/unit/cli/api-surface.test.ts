@@ -0,0 +1,138 @@
+/**
+ * Unit tests for the exported API surface listing and diff
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  buildApiSurface,
+  diffApiSurface,
+  formatApiEntry,
+  matchesPackagePattern,
+  normalizeSignature,
+  relativeToIndex,
+} from '../../../src/cli/api-surface';
+import { type ExportedSymbolRecord } from '../../../src/types/database';
+
+const exported = (
+  symbol_name: string,
+  symbol_type: string,
+  file_path: string,
+  definition: string,
+  language = 'typescript'
+): ExportedSymbolRecord => ({ symbol_name, symbol_type, file_path, definition, language });
+
+describe('normalizeSignature', () => {
+  test('should drop doc comments, export keywords, and initializers', () => {
+    expect(
+      normalizeSignature({
+        symbol_name: 'login',
+        symbol_type: 'function',
+        definition: 'function login(user: string): Promise<Session> - Log a user in',
+      })
+    ).toBe('function login(user: string): Promise<Session>');
+    expect(
+      normalizeSignature({ symbol_name: 'LIMIT', symbol_type: 'constant', definition: 'export const LIMIT = 50;' })
+    ).toBe('const LIMIT');
+    expect(
+      normalizeSignature({
+        symbol_name: 'Options',
+        symbol_type: 'interface',
+        definition: 'export interface Options {\n  limit: number;\n}',
+      })
+    ).toBe('interface Options { limit: number; }');
+  });
+
+  test('should fall back to kind and name when the definition lacks the name', () => {
+    expect(normalizeSignature({ symbol_name: 'run', symbol_type: 'function', definition: null })).toBe('function run');
+  });
+});
+
+describe('matchesPackagePattern', () => {
+  test('should follow Go package patterns', () => {
+    expect(matchesPackagePattern('pkg/auth/login.go', './...')).toBe(true);
+    expect(matchesPackagePattern('pkg/auth/login.go', './pkg/...')).toBe(true);
+    expect(matchesPackagePattern('pkg/auth/login.go', './pkg')).toBe(false);
+    expect(matchesPackagePattern('pkg/auth/login.go', 'pkg/auth/')).toBe(true);
+    expect(matchesPackagePattern('pkg/auth/login.go', 'pkg/auth/login.go')).toBe(true);
+    expect(matchesPackagePattern('main.go', '.')).toBe(true);
+  });
+});
+
+describe('buildApiSurface', () => {
+  test('should group directory-package languages by directory and sort entries', () => {
+    const entries = buildApiSurface([
+      exported('Logout', 'function', 'pkg/auth/logout.go', 'function Logout(): void', 'go'),
+      exported('Login', 'function', 'pkg/auth/login.go', 'function Login(user: string): error', 'go'),
+      exported('search', 'function', 'src/search.ts', 'function search(query: string): Result[]'),
+    ]);
+
+    expect(entries.map(formatApiEntry)).toEqual([
+      'pkg pkg/auth, function Login(user: string): error',
+      'pkg pkg/auth, function Logout(): void',
+      'pkg src/search.ts, function search(query: string): Result[]',
+    ]);
+  });
+});
+
+describe('diffApiSurface', () => {
+  const before = buildApiSurface([
+    exported('search', 'function', 'src/search.ts', 'function search(query: string): Result[]'),
+    exported('LIMIT', 'constant', 'src/search.ts', 'export const LIMIT = 50;'),
+    exported('legacy', 'function', 'src/legacy.ts', 'function legacy(): void'),
+  ]);
+
+  test('should report removed, changed, and added entries', () => {
+    const after = buildApiSurface([
+      exported('search', 'function', 'src/search.ts', 'function search(query: string, limit: number): Result[]'),
+      exported('LIMIT', 'constant', 'src/search.ts', 'export const LIMIT = 100;'),
+      exported('suggest', 'function', 'src/search.ts', 'function suggest(prefix: string): string[]'),
+    ]);
+
+    const changes = diffApiSurface(before, after);
+
+    expect(changes.map((change) => [change.status, change.entry.name])).toEqual([
+      ['removed', 'legacy'],
+      ['changed', 'search'],
+      ['added', 'suggest'],
+    ]);
+    expect(changes[1]?.previous).toBe('function search(query: string): Result[]');
+  });
+
+  test('should report nothing for an unchanged surface', () => {
+    expect(diffApiSurface(before, before)).toEqual([]);
+  });
+});
+
+describe('relativeToIndex', () => {
+  test('should compare a revision index with the working copy by relative path', () => {
+    const release = relativeToIndex(
+      [exported('Login', 'function', 'api@3f2a9c1b7d4e/auth/login.go', 'func Login(user string) error', 'go')],
+      'api@3f2a9c1b7d4e'
+    );
+    const head = relativeToIndex(
+      [exported('Login', 'function', 'auth/login.go', 'func Login(ctx context.Context, user string) error', 'go')],
+      'api'
+    );
+    expect(release[0].file_path).toBe('auth/login.go');
+    expect(diffApiSurface(buildApiSurface(release), buildApiSurface(head))).toEqual([
+      {
+        status: 'changed',
+        entry: {
+          module: 'auth',
+          kind: 'function',
+          name: 'Login',
+          signature: 'func Login(ctx context.Context, user string) error',
+        },
+        previous: 'func Login(user string) error',
+      },
+    ]);
+  });
+
+  test('should keep paths when not every one is under the index name', () => {
+    const symbols = [
+      exported('A', 'function', 'api/a.ts', 'function A()'),
+      exported('B', 'function', 'b.ts', 'function B()'),
+    ];
+    expect(relativeToIndex(symbols, 'api')).toEqual(symbols);
+  });
+});
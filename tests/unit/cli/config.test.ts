@@ -0,0 +1,39 @@
+/**
+ * Unit tests for cindex config export
+ */
+
+import { afterEach, beforeEach, describe, test, expect } from '@jest/globals';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import * as yaml from 'js-yaml';
+import { configCommand } from '../../../src/cli/config';
+
+describe('config export', () => {
+  const originalEnv = process.env;
+  let dir: string;
+
+  beforeEach(() => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-config-'));
+    process.env = { ...originalEnv };
+  });
+
+  afterEach(() => {
+    process.env = originalEnv;
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  test('should leave out the embedding API key and endpoint', async () => {
+    const output = path.join(dir, 'team.yaml');
+    process.env.EMBEDDING_PROVIDER = 'openai';
+    process.env.EMBEDDING_API_KEY = 'sk-personal';
+    process.env.CINDEX_EMBEDDING_API_BASE = 'https://embeddings.example.com/v1';
+
+    await configCommand.run(['export', '--output', output]);
+
+    const { settings } = yaml.load(fs.readFileSync(output, 'utf-8')) as { settings: Record<string, string> };
+    expect(settings.EMBEDDING_PROVIDER).toBe('openai');
+    expect(settings).not.toHaveProperty('EMBEDDING_API_KEY');
+    expect(settings).not.toHaveProperty('EMBEDDING_API_BASE');
+  });
+});
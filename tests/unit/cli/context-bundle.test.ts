@@ -0,0 +1,173 @@
+/**
+ * Unit tests for token-budgeted context bundles
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  estimateTokens,
+  nearestPreview,
+  packBundle,
+  referencedNames,
+  renderBundle,
+  renderItem,
+} from '../../../src/cli/context-bundle';
+import { type SymbolPreview } from '../../../src/cli/show';
+
+const preview = (name: string, filePath: string, source: string, doc: string | null = null): SymbolPreview => ({
+  file_path: filePath,
+  language: 'go',
+  name,
+  kind: 'function',
+  start_line: 10,
+  end_line: 10 + source.split('\n').length - 1,
+  source,
+  doc,
+});
+
+const LOGIN = [
+  'func (s *AuthService) Login(ctx context.Context, creds Credentials) (*Session, error) {',
+  '\tuser, err := s.queryUser(ctx, creds.Email) // finds the User',
+  '\tif err != nil {',
+  '\t\treturn nil, fmt.Errorf("login %s: %w", creds.Email, err)',
+  '\t}',
+  '\treturn s.CreateSession(ctx, user)',
+  '}',
+].join('\n');
+
+describe('referencedNames', () => {
+  test('should list identifiers outside comments and strings, once each', () => {
+    const names = referencedNames(LOGIN, ['Login']);
+    expect(names.slice(0, 6)).toEqual(['func', 's', 'AuthService', 'ctx', 'context', 'Context']);
+    expect(names).toContain('Credentials');
+    expect(names).toContain('Session');
+    expect(names).not.toContain('Login');
+    expect(names).not.toContain('User');
+    expect(names).not.toContain('login');
+    expect(names.filter((name) => name === 'ctx')).toHaveLength(1);
+  });
+});
+
+describe('nearestPreview', () => {
+  test('should prefer the same file, then the same directory', () => {
+    const elsewhere = preview('Session', 'web/session.go', 'type Session struct{}');
+    const sibling = preview('Session', 'auth/session.go', 'type Session struct{}');
+    const same = preview('Session', 'auth/service.go', 'type Session struct{}');
+
+    expect(nearestPreview([elsewhere, sibling, same], 'auth/service.go')).toBe(same);
+    expect(nearestPreview([elsewhere, sibling], 'auth/service.go')).toBe(sibling);
+    expect(nearestPreview([elsewhere], 'auth/service.go')).toBe(elsewhere);
+    expect(nearestPreview([], 'auth/service.go')).toBeNull();
+  });
+});
+
+describe('packBundle', () => {
+  const definition = preview('Login', 'auth/service.go', LOGIN, '// Login checks credentials and opens a session.');
+  const queryUser = preview('queryUser', 'auth/store.go', 'func (s *AuthService) queryUser() {}');
+  const handlerSource = ['func HandleLogin() {', '\t// ...'.repeat(60), '}'].join('\n');
+  const handler = preview('HandleLogin', 'web/login.go', handlerSource);
+  const session = preview('Session', 'auth/session.go', 'type Session struct {\n\tToken string\n}');
+
+  test('should keep every part that fits, in section order', () => {
+    const bundle = packBundle(
+      'AuthService.Login',
+      definition,
+      [
+        { section: 'type', preview: session },
+        { section: 'caller', preview: handler },
+        { section: 'callee', preview: queryUser },
+      ],
+      8000
+    );
+
+    expect(bundle.items.map((item) => [item.section, item.name])).toEqual([
+      ['definition', 'Login'],
+      ['callee', 'queryUser'],
+      ['caller', 'HandleLogin'],
+      ['type', 'Session'],
+    ]);
+    expect(bundle.omitted).toEqual([]);
+    expect(bundle.tokens).toBe(bundle.items.reduce((sum, item) => sum + item.tokens, 0));
+  });
+
+  test('should leave out parts past the budget and still add smaller ones', () => {
+    const definitionTokens = estimateTokens(renderItem({ ...definition, section: 'definition' }));
+    const calleeTokens = estimateTokens(renderItem({ ...queryUser, section: 'callee' }));
+    const typeTokens = estimateTokens(renderItem({ ...session, section: 'type' }));
+    const budget = definitionTokens + calleeTokens + typeTokens;
+
+    const bundle = packBundle(
+      'Login',
+      definition,
+      [
+        { section: 'callee', preview: queryUser },
+        { section: 'caller', preview: handler },
+        { section: 'type', preview: session },
+      ],
+      budget
+    );
+
+    expect(bundle.items.map((item) => item.name)).toEqual(['Login', 'queryUser', 'Session']);
+    expect(bundle.omitted.map((item) => [item.section, item.name])).toEqual([['caller', 'HandleLogin']]);
+    expect(bundle.omitted[0]).not.toHaveProperty('source');
+    expect(bundle.tokens).toBeLessThanOrEqual(budget);
+  });
+
+  test('should cut a definition larger than the budget by lines', () => {
+    const bundle = packBundle('Login', definition, [{ section: 'callee', preview: queryUser }], 60);
+
+    const [item] = bundle.items;
+    expect(bundle.items).toHaveLength(1);
+    expect(item.truncated).toBeGreaterThan(0);
+    expect(item.source.split('\n')).toHaveLength(LOGIN.split('\n').length - (item.truncated ?? 0));
+    expect(item.tokens).toBeLessThanOrEqual(60);
+  });
+
+  test('should not repeat the definition or a declaration found twice', () => {
+    const bundle = packBundle(
+      'Login',
+      definition,
+      [
+        { section: 'caller', preview: definition },
+        { section: 'callee', preview: queryUser },
+        { section: 'type', preview: queryUser },
+      ],
+      8000
+    );
+    expect(bundle.items.map((item) => [item.section, item.name])).toEqual([
+      ['definition', 'Login'],
+      ['callee', 'queryUser'],
+    ]);
+  });
+});
+
+describe('renderBundle', () => {
+  test('should write a section per part with fenced source and the doc comment', () => {
+    const definition = preview('Login', 'auth/service.go', 'func Login() {}', '// Login opens a session.');
+    const tooLarge = preview('Big', 'auth/big.go', 'x'.repeat(4000));
+    const bundle = packBundle('auth.Login', definition, [{ section: 'callee', preview: tooLarge }], 100);
+    const markdown = renderBundle(bundle);
+
+    expect(markdown).toBe(
+      [
+        '# auth.Login',
+        '',
+        '## Definition',
+        '',
+        '### function Login',
+        '',
+        '`auth/service.go:10-10`',
+        '',
+        'Login opens a session.',
+        '',
+        '```go',
+        'func Login() {}',
+        '```',
+        '',
+        '## Left out for the budget',
+        '',
+        '- function Big (`auth/big.go:10`, 1013 tokens)',
+        '',
+      ].join('\n')
+    );
+  });
+});
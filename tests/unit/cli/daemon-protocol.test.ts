@@ -0,0 +1,84 @@
+/**
+ * Unit tests for the cindex daemon wire protocol
+ */
+
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  checkRequest,
+  DAEMON_PROTOCOL_VERSION,
+  DaemonChannel,
+  daemonSocketPath,
+  encodeFrame,
+  FrameDecoder,
+  parseControl,
+  type DaemonReply,
+  type DaemonRequest,
+} from '../../../src/cli/daemon-protocol';
+
+const REQUEST: DaemonRequest = {
+  type: 'run',
+  version: DAEMON_PROTOCOL_VERSION,
+  argv: ['search', 'Login'],
+  cwd: '/src/shop',
+  env: { HOME: '/home/dev' },
+  tty: false,
+  color_level: 0,
+};
+
+describe('FrameDecoder', () => {
+  test('should decode frames split and joined across chunks', () => {
+    const stream = Buffer.concat([
+      encodeFrame(DaemonChannel.Control, REQUEST),
+      encodeFrame(DaemonChannel.Stdout, Buffer.from('symbol\tLogin\n')),
+      encodeFrame(DaemonChannel.Control, { type: 'exit', code: 0 }),
+    ]);
+    const decoder = new FrameDecoder();
+
+    const frames = [stream.subarray(0, 3), stream.subarray(3, 40), stream.subarray(40)].flatMap((chunk) =>
+      decoder.push(chunk)
+    );
+
+    expect(frames.map((frame) => frame.channel)).toEqual([
+      DaemonChannel.Control,
+      DaemonChannel.Stdout,
+      DaemonChannel.Control,
+    ]);
+    expect(parseControl<DaemonRequest>(frames[0].data)).toEqual(REQUEST);
+    expect(frames[1].data.toString()).toBe('symbol\tLogin\n');
+    expect(parseControl<DaemonReply>(frames[2].data)).toEqual({ type: 'exit', code: 0 });
+  });
+
+  test('should keep bytes as written on the output channels', () => {
+    const bytes = Buffer.from([0x00, 0xff, 0x0a, 0xc3]);
+
+    const [frame] = new FrameDecoder().push(encodeFrame(DaemonChannel.Stderr, bytes));
+
+    expect(frame.channel).toBe(DaemonChannel.Stderr);
+    expect(Buffer.compare(frame.data, bytes)).toBe(0);
+  });
+
+  test('should reject empty frames and unknown channels', () => {
+    expect(() => new FrameDecoder().push(Buffer.from([0, 0, 0, 0]))).toThrow('Invalid frame length 0');
+    expect(() => new FrameDecoder().push(Buffer.from([0, 0, 0, 1, 7]))).toThrow('Unknown frame channel 7');
+  });
+});
+
+describe('checkRequest', () => {
+  test('should accept a request of this protocol version and refuse others', () => {
+    expect(checkRequest(REQUEST)).toBeNull();
+    expect(checkRequest({ ...REQUEST, version: DAEMON_PROTOCOL_VERSION + 1 })).toContain('protocol version');
+    expect(checkRequest({ type: 'exit', code: 0 })).toBe('expected a run request');
+    expect(checkRequest({ ...REQUEST, argv: 'search' })).toBe('malformed request');
+  });
+});
+
+describe('daemonSocketPath', () => {
+  test('should default to the cindex home and follow CINDEX_DAEMON', () => {
+    expect(daemonSocketPath({})).toBe(path.join(os.homedir(), '.cindex', 'daemon.sock'));
+    expect(daemonSocketPath({ CINDEX_DAEMON: '/tmp/ci.sock' })).toBe('/tmp/ci.sock');
+    expect(daemonSocketPath({ CINDEX_DAEMON: 'off' })).toBeNull();
+  });
+});
@@ -0,0 +1,85 @@
+/**
+ * Unit tests for unused declaration reports
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { findAllowance, findDeadCode, matchesAllowPattern, usesInScope } from '../../../src/cli/deadcode';
+import { type GoSymbolUsageRecord } from '../../../src/types/database';
+
+const symbol = (overrides: Partial<GoSymbolUsageRecord>): GoSymbolUsageRecord => ({
+  repo_id: 'app',
+  symbol_name: 'Login',
+  symbol_type: 'function',
+  file_path: 'internal/auth/login.go',
+  line_number: 10,
+  scope: 'exported',
+  definition: 'func Login(name string) error',
+  reference_count: 0,
+  outside_file: 0,
+  outside_package: 0,
+  implements_interface: false,
+  ...overrides,
+});
+
+describe('usesInScope', () => {
+  test('should count uses outside the file or package', () => {
+    const used = symbol({ reference_count: 5, outside_file: 3, outside_package: 1 });
+
+    expect(usesInScope(used, 'any')).toBe(5);
+    expect(usesInScope(used, 'file')).toBe(3);
+    expect(usesInScope(used, 'package')).toBe(1);
+  });
+
+  test('should count every use of an unexported name for the package scope', () => {
+    const internal = symbol({ symbol_name: 'hash', scope: 'internal', reference_count: 2, outside_file: 1 });
+
+    expect(usesInScope(internal, 'package')).toBe(2);
+  });
+});
+
+describe('matchesAllowPattern', () => {
+  test('should match names with wildcards, bare or qualified by package', () => {
+    const field = symbol({ symbol_name: 'Config.Timeout', symbol_type: 'field' });
+
+    expect(matchesAllowPattern(field, 'Config.*')).toBe(true);
+    expect(matchesAllowPattern(field, 'auth.Config.Timeout')).toBe(true);
+    expect(matchesAllowPattern(field, '*.Timeout')).toBe(true);
+    expect(matchesAllowPattern(field, 'Config')).toBe(false);
+    expect(matchesAllowPattern(field, 'login.Config.*')).toBe(false);
+  });
+});
+
+describe('findAllowance', () => {
+  test('should allow entry points, test helpers, interface methods, and tagged fields', () => {
+    const main = symbol({ symbol_name: 'main', file_path: 'cmd/app/main.go' });
+    const helper = symbol({ symbol_name: 'newFixture', file_path: 'internal/auth/login_test.go' });
+    const method = symbol({ symbol_name: 'Memory.Get', symbol_type: 'method', implements_interface: true });
+    expect(findAllowance(main, [])).toBe('entrypoint');
+    expect(findAllowance(helper, [])).toBe('test');
+    expect(findAllowance(method, [])).toBe('interface');
+    const tagged = symbol({ symbol_name: 'User.Role', symbol_type: 'field', definition: 'Role string `json:"role"`' });
+    expect(findAllowance(tagged, [])).toBe('reflection');
+    expect(findAllowance(symbol({}), ['auth.Log*'])).toBe('allowlist');
+    expect(findAllowance(symbol({}), [])).toBeNull();
+  });
+});
+
+describe('findDeadCode', () => {
+  test('should report unused declarations and count the allowed ones', () => {
+    const symbols = [
+      symbol({ symbol_name: 'Login', reference_count: 2, outside_file: 2, outside_package: 2 }),
+      symbol({ symbol_name: 'Logout' }),
+      symbol({ symbol_name: 'init' }),
+      symbol({ symbol_name: 'legacyHash', scope: 'internal', reference_count: 1 }),
+      symbol({ symbol_name: 'Session', symbol_type: 'class', reference_count: 4, outside_file: 1 }),
+    ];
+
+    const any = findDeadCode(symbols, { scope: 'any', allow: [] });
+    expect(any.unused.map((s) => s.symbol_name)).toEqual(['Logout']);
+    expect([...any.allowed]).toEqual([['entrypoint', 1]]);
+
+    const pkg = findDeadCode(symbols, { scope: 'package', allow: ['Logout'] });
+    expect(pkg.unused.map((s) => s.symbol_name)).toEqual(['Session']);
+    expect(Object.fromEntries(pkg.allowed)).toEqual({ allowlist: 1, entrypoint: 1 });
+  });
+});
@@ -0,0 +1,78 @@
+/**
+ * Unit tests for revision indexes and symbol diffs between revisions
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { diffSymbols } from '../../../src/cli/diff-symbols';
+import { parseTreeEntries, revisionIndexId } from '../../../src/indexing/revisions';
+import { type SymbolFingerprintRecord } from '../../../src/types/database';
+
+const symbol = (
+  name: string,
+  hash: string,
+  overrides: Partial<SymbolFingerprintRecord> = {}
+): SymbolFingerprintRecord => ({
+  symbol_name: name,
+  symbol_type: 'function',
+  file_path: 'auth/login.go',
+  line_number: 10,
+  source_hash: hash,
+  ...overrides,
+});
+
+describe('diffSymbols', () => {
+  test('reports added, removed, and changed symbols', () => {
+    const previous = [symbol('Login', 'a'), symbol('Logout', 'b', { line_number: 30 })];
+    const current = [symbol('Login', 'a2'), symbol('Refresh', 'c', { line_number: 50 })];
+
+    expect(diffSymbols(previous, current).map((change) => [change.status, change.symbol.symbol_name])).toEqual([
+      ['changed', 'Login'],
+      ['removed', 'Logout'],
+      ['added', 'Refresh'],
+    ]);
+  });
+
+  test('ignores symbols that only moved within their file', () => {
+    const previous = [symbol('Login', 'a', { line_number: 10 })];
+    const current = [symbol('Login', 'a', { line_number: 42 })];
+    expect(diffSymbols(previous, current)).toEqual([]);
+  });
+
+  test('matches same-named symbols by source before pairing the rest', () => {
+    const previous = [symbol('init', 'x'), symbol('init', 'y', { line_number: 20 })];
+    const current = [symbol('init', 'y', { line_number: 5 }), symbol('init', 'z', { line_number: 25 })];
+
+    const changes = diffSymbols(previous, current);
+    expect(changes).toHaveLength(1);
+    expect(changes[0].status).toBe('changed');
+    expect(changes[0].symbol.line_number).toBe(25);
+  });
+
+  test('treats a symbol in a renamed file as removed and added', () => {
+    const changes = diffSymbols([symbol('Login', 'a')], [symbol('Login', 'a', { file_path: 'auth/session.go' })]);
+    expect(changes.map((change) => change.status)).toEqual(['removed', 'added']);
+  });
+});
+
+describe('parseTreeEntries', () => {
+  test('keeps regular files and skips symlinks and submodules', () => {
+    const output = [
+      '100644 blob 1111111111111111111111111111111111111111\tgo.mod',
+      '100755 blob 2222222222222222222222222222222222222222\tscripts/build tool.sh',
+      '120000 blob 3333333333333333333333333333333333333333\tlatest',
+      '160000 commit 4444444444444444444444444444444444444444\tvendor/lib',
+      '',
+    ].join('\0');
+
+    expect(parseTreeEntries(output)).toEqual([
+      { blob: '1111111111111111111111111111111111111111', path: 'go.mod' },
+      { blob: '2222222222222222222222222222222222222222', path: 'scripts/build tool.sh' },
+    ]);
+  });
+});
+
+describe('revisionIndexId', () => {
+  test('appends the abbreviated commit', () => {
+    expect(revisionIndexId('api', '3f2a9c1b7d4e5f60718293a4b5c6d7e8f9012345')).toBe('api@3f2a9c1b7d4e');
+  });
+});
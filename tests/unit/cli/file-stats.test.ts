@@ -0,0 +1,131 @@
+/**
+ * Unit tests for file metrics and their per-package and per-directory rows
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { aggregateFileStats, toFileStatsCsv } from '../../../src/cli/file-stats';
+import { countLineKinds, isTestPath } from '../../../src/indexing/file-metrics';
+import { type FileStatsRecord } from '../../../src/types/database';
+
+/**
+ * File metrics with defaults for the fields a test does not set
+ */
+const fileStats = (file_path: string, fields: Partial<FileStatsRecord> = {}): FileStatsRecord => ({
+  repo_id: 'shop',
+  file_path,
+  language: 'go',
+  package_name: null,
+  total_lines: 10,
+  code_lines: 6,
+  comment_lines: 2,
+  blank_lines: 2,
+  is_test: isTestPath(file_path),
+  symbol_kinds: { function: 2 },
+  functions: 2,
+  complexity_total: 6,
+  ...fields,
+});
+
+describe('countLineKinds', () => {
+  test('should count comment-only lines as comments and lines with code as code', () => {
+    const content = [
+      '// Package auth signs sessions.',
+      'package auth',
+      '',
+      '/*',
+      '  Tokens are opaque.',
+      '*/',
+      'const url = "https://example.com" // trailing comment',
+      '/* inline */ var x = 1',
+      '\t',
+    ].join('\n');
+
+    expect(countLineKinds(content, 'go')).toEqual({ code: 3, comment: 4, blank: 2 });
+  });
+
+  test('should use the comment syntax of the language', () => {
+    const content = ['# config', 'port = 8080  # default', "url = 'http://x' # nope"].join('\n');
+
+    expect(countLineKinds(content, 'python')).toEqual({ code: 2, comment: 1, blank: 0 });
+    expect(countLineKinds(content, 'unknown')).toEqual({ code: 3, comment: 0, blank: 0 });
+    expect(countLineKinds('', 'go')).toEqual({ code: 0, comment: 0, blank: 0 });
+  });
+});
+
+describe('isTestPath', () => {
+  test('should recognize test files by name and directory', () => {
+    const paths = [
+      'internal/auth/session_test.go',
+      'src/auth.spec.ts',
+      'src/__tests__/auth.ts',
+      'tests/test_auth.py',
+      'app/src/AuthServiceTest.java',
+      'internal/auth/session.go',
+      'src/latest.ts',
+      'internal/testdata/session.go',
+    ];
+
+    expect(paths.filter(isTestPath)).toEqual(paths.slice(0, 5));
+  });
+});
+
+describe('aggregateFileStats', () => {
+  test('should add up files per directory and derive density, test ratio, and complexity', () => {
+    const files = [
+      fileStats('internal/auth/session.go', { symbol_kinds: { function: 2, struct: 1 } }),
+      fileStats('internal/auth/token.go', { code_lines: 4, comment_lines: 0, functions: 1, complexity_total: 5 }),
+      fileStats('internal/auth/session_test.go', { symbol_kinds: { test: 3 }, functions: 3, complexity_total: 3 }),
+      fileStats('main.go', { code_lines: null, comment_lines: null, blank_lines: null }),
+    ];
+
+    const [root, auth] = aggregateFileStats(files, 'dir');
+
+    expect(auth).toMatchObject({
+      group: 'internal/auth',
+      files: 3,
+      test_files: 1,
+      lines: 30,
+      code_lines: 16,
+      comment_lines: 4,
+      comment_density: 0.2,
+      test_ratio: 0.6,
+      symbols: 8,
+      symbols_by_kind: { function: 4, struct: 1, test: 3 },
+      functions: 6,
+      avg_complexity: 14 / 6,
+    });
+    expect(root).toMatchObject({ group: '.', code_lines: null, comment_density: null, test_ratio: null });
+  });
+
+  test('should group Go files by directory and others by workspace package', () => {
+    const files = [
+      fileStats('services/api/src/server.ts', { language: 'typescript', package_name: '@shop/api' }),
+      fileStats('services/api/src/routes/cart.ts', { language: 'typescript', package_name: '@shop/api' }),
+      fileStats('services/api/cmd/main.go', { package_name: '@shop/api' }),
+    ];
+
+    const rows = aggregateFileStats(files, 'package');
+
+    expect(rows.map((row) => [row.group, row.files])).toEqual([
+      ['@shop/api', 2],
+      ['services/api/cmd', 1],
+    ]);
+  });
+});
+
+describe('toFileStatsCsv', () => {
+  test('should write fixed columns, then one column per symbol kind', () => {
+    const rows = aggregateFileStats(
+      [fileStats('a,b/x.go', { symbol_kinds: { method: 1 } }), fileStats('c/y.go', { code_lines: null })],
+      'dir'
+    );
+
+    expect(toFileStatsCsv(rows, 'dir').split('\n')).toEqual([
+      'repo_id,directory,files,test_files,lines,code_lines,comment_lines,blank_lines,comment_density,test_ratio,' +
+        'symbols,functions,avg_complexity,symbols_function,symbols_method',
+      'shop,"a,b",1,0,10,6,2,2,0.2500,0.0000,1,2,3.00,0,1',
+      'shop,c,1,0,10,,,,,,2,2,3.00,2,0',
+      '',
+    ]);
+  });
+});
@@ -0,0 +1,74 @@
+/**
+ * Unit tests for laying out Go embedding hierarchies
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { buildHierarchy } from '../../../src/cli/hierarchy';
+import { type GoEmbeddingRecord } from '../../../src/types/database';
+
+const embedding = (
+  type_name: string,
+  embedded_name: string,
+  depth: number,
+  overrides: Partial<GoEmbeddingRecord> = {}
+): GoEmbeddingRecord => ({
+  repo_id: 'shop',
+  file_path: 'internal/auth/service.go',
+  type_name,
+  type_package: 'auth',
+  type_kind: 'struct',
+  embedded_name,
+  embedded_package: null,
+  embedded_package_name: 'auth',
+  pointer: false,
+  line_number: depth,
+  column_number: 2,
+  depth,
+  ...overrides,
+});
+
+const outline = (nodes: ReturnType<typeof buildHierarchy>, direction: 'up' | 'down'): string[] =>
+  nodes.map(
+    ({ embedding: { type_name, embedded_name }, level, repeated }) =>
+      `${'  '.repeat(level - 1)}${direction === 'up' ? embedded_name : type_name}${repeated ? ' (repeated)' : ''}`
+  );
+
+describe('buildHierarchy', () => {
+  test('should nest the types embedded by embedded types', () => {
+    const supertypes = [
+      embedding('AdminService', 'AuthService', 1),
+      embedding('AdminService', 'Mutex', 1, { embedded_package: 'sync', embedded_package_name: 'sync' }),
+      embedding('AuthService', 'Store', 2),
+      embedding('AuthService', 'Logger', 2),
+    ];
+
+    expect(outline(buildHierarchy(supertypes, 'up'), 'up')).toEqual(['AuthService', '  Store', '  Logger', 'Mutex']);
+  });
+
+  test('should not follow a same-named type of another package', () => {
+    const supertypes = [
+      embedding('Handler', 'Logger', 1, { embedded_package: 'example.com/shop/log', embedded_package_name: 'log' }),
+      embedding('Logger', 'Writer', 2),
+    ];
+
+    expect(outline(buildHierarchy(supertypes, 'up'), 'up')).toEqual(['Logger']);
+  });
+
+  test('should expand a type reached twice once', () => {
+    const subtypes = [
+      embedding('ReadCloser', 'Reader', 1, { type_kind: 'interface' }),
+      embedding('ReadWriter', 'Reader', 1, { type_kind: 'interface' }),
+      embedding('ReadWriteCloser', 'ReadCloser', 2, { type_kind: 'interface' }),
+      embedding('ReadWriteCloser', 'ReadWriter', 2, { type_kind: 'interface' }),
+      embedding('File', 'ReadWriteCloser', 3),
+    ];
+
+    expect(outline(buildHierarchy(subtypes, 'down'), 'down')).toEqual([
+      'ReadCloser',
+      '  ReadWriteCloser',
+      '    File',
+      'ReadWriter',
+      '  ReadWriteCloser (repeated)',
+    ]);
+  });
+});
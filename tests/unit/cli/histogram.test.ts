@@ -0,0 +1,44 @@
+/**
+ * Unit tests for index composition histograms
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { bucketize, renderBarChart } from '../../../src/cli/histogram';
+
+describe('bucketize', () => {
+  test('should group values into power-of-two buckets, keeping empty ones between', () => {
+    const buckets = bucketize([
+      { value: 0, count: 2 },
+      { value: 3, count: 1 },
+      { value: 2, count: 4 },
+      { value: 9, count: 1 },
+    ]);
+
+    expect(buckets.map((bucket) => [bucket.label, bucket.count])).toEqual([
+      ['0', 2],
+      ['1', 0],
+      ['2-3', 5],
+      ['4-7', 0],
+      ['8-15', 1],
+    ]);
+  });
+
+  test('should return no buckets without data', () => {
+    expect(bucketize([])).toEqual([]);
+  });
+});
+
+describe('renderBarChart', () => {
+  test('should scale bars to the largest count and mark small non-empty buckets', () => {
+    const lines = renderBarChart(
+      [
+        { label: '1', count: 100 },
+        { label: '2-3', count: 1 },
+        { label: '4-7', count: 0 },
+      ],
+      10
+    );
+
+    expect(lines).toEqual(['  1 | ########## 100', '2-3 | #          1', '4-7 |            0']);
+  });
+});
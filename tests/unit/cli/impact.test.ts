@@ -0,0 +1,122 @@
+/**
+ * Unit tests for rename impact scanning
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  nameSpellings,
+  previewRename,
+  scanMentions,
+  segmentGoSource,
+  sortImpact,
+  type ImpactEntry,
+} from '../../../src/cli/impact';
+
+const USER_GO = [
+  'package auth',
+  '',
+  '// User is an account. Role decides what it may do.',
+  'type User struct {',
+  '\tRole string `json:"role" db:"role"`',
+  '}',
+  '',
+  'const query = "SELECT id, role FROM users WHERE role = $1"',
+  '',
+  "func (u User) Admin() bool { return u.Role == \"admin\" && u.roles != 'r' } // not a Role check",
+].join('\n');
+
+describe('nameSpellings', () => {
+  test('should spell a name as serializers and databases do', () => {
+    expect(nameSpellings('Role')).toEqual(['Role', 'role', 'ROLE']);
+    expect(nameSpellings('UserID')).toEqual(['UserID', 'userID', 'user_id', 'user-id', 'USER_ID']);
+  });
+});
+
+describe('segmentGoSource', () => {
+  test('should find strings and comments, skipping runes', () => {
+    const code = 'x := "a\\"b" + `raw` // c\ny := \'"\' /* d */';
+    expect(segmentGoSource(code).map(({ kind, start, end }) => [kind, code.slice(start, end)])).toEqual([
+      ['string', '"a\\"b"'],
+      ['string', '`raw`'],
+      ['comment', '// c\n'],
+      ['comment', '/* d */'],
+    ]);
+  });
+});
+
+describe('scanMentions', () => {
+  test('should list struct tags, literals, and comments of Go files', () => {
+    const mentions = scanMentions('internal/auth/user.go', USER_GO, 'Role');
+
+    expect(mentions.map((m) => [m.category, m.line, m.column])).toEqual([
+      ['comment', 3, 24],
+      ['tag', 5, 21],
+      ['string', 8, 27],
+      ['comment', 10, 84],
+    ]);
+  });
+
+  test('should match documents as written and other files in any spelling', () => {
+    expect(scanMentions('docs/auth.md', 'Each User has a Role.\nThe role is checked.', 'Role')).toHaveLength(1);
+    expect(scanMentions('migrations/001.sql', 'ALTER TABLE users ADD COLUMN user_id int;', 'UserID')).toEqual([
+      {
+        category: 'text',
+        file_path: 'migrations/001.sql',
+        line: 1,
+        column: 30,
+        text: 'ALTER TABLE users ADD COLUMN user_id int;',
+      },
+    ]);
+  });
+});
+
+describe('previewRename', () => {
+  const place = (category: ImpactEntry['category'], column: number | null): ImpactEntry => ({
+    category,
+    file_path: 'internal/auth/user.go',
+    line: 1,
+    column,
+    text: '',
+  });
+
+  test('should rename the identifier at the column of a use', () => {
+    expect(previewRename(place('reference', 17), '\tif u.Role == x.Role {', 'Role', 'Kind')).toBe(
+      'if u.Role == x.Kind {'
+    );
+    expect(previewRename(place('declaration', null), '\tRole string `json:"role"`', 'Role', 'Kind')).toBe(
+      'Kind string `json:"role"`'
+    );
+  });
+
+  test('should rename each spelling in literals and the name as written in comments', () => {
+    expect(previewRename(place('tag', 14), '\tUserID int `json:"user_id" db:"USER_ID"`', 'UserID', 'AccountID')).toBe(
+      'AccountID int `json:"account_id" db:"ACCOUNT_ID"`'
+    );
+    expect(previewRename(place('comment', 4), '// Role is the role of the user', 'Role', 'Kind')).toBe(
+      '// Kind is the role of the user'
+    );
+  });
+});
+
+describe('sortImpact', () => {
+  test('should order by category, then file and line', () => {
+    const entry = (category: ImpactEntry['category'], file_path: string, line: number): ImpactEntry => ({
+      category,
+      file_path,
+      line,
+      column: null,
+      text: '',
+    });
+    const sorted = sortImpact([
+      entry('reference', 'b.go', 1),
+      entry('reference', 'a.go', 9),
+      entry('declaration', 'c.go', 4),
+    ]);
+
+    expect(sorted.map((e) => `${e.category} ${e.file_path}:${String(e.line)}`)).toEqual([
+      'declaration c.go:4',
+      'reference a.go:9',
+      'reference b.go:1',
+    ]);
+  });
+});
@@ -0,0 +1,114 @@
+/**
+ * Unit tests for the ownership report and git blame parsing
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  buildDirectoryOwnership,
+  buildSymbolOwnership,
+  summarizeAuthors,
+  toOwnershipCsv,
+} from '../../../src/cli/ownership';
+import { lastChange, parseBlameLines, parseBlamePorcelain } from '../../../src/indexing/git-blame';
+
+describe('parseBlamePorcelain', () => {
+  test('should read one author per line and leave uncommitted lines unattributed', () => {
+    const sha = 'a'.repeat(40);
+    const text = [
+      `${sha} 1 1 2`,
+      'author Ada Lovelace',
+      'author-mail <ada@example.com>',
+      'summary Add parser',
+      '\tpackage parser',
+      `${sha} 2 2`,
+      'author Ada Lovelace',
+      '\t',
+      `${'0'.repeat(40)} 3 3 1`,
+      'author Not Committed Yet',
+      '\tfunc wip() {}',
+    ].join('\n');
+
+    expect(parseBlamePorcelain(text)).toEqual(['Ada Lovelace', 'Ada Lovelace', null]);
+  });
+});
+
+describe('lastChange', () => {
+  test('should find the latest commit of a span by author time', () => {
+    const [older, newer] = ['a'.repeat(40), 'b'.repeat(40)];
+    const text = [
+      `${older} 1 1 1`,
+      'author Ada Lovelace',
+      'author-time 1700000000',
+      '\tfunc Login() {',
+      `${newer} 2 2 1`,
+      'author Grace Hopper',
+      'author-time 1760000000',
+      '\t\treturn nil',
+      `${older} 3 3`,
+      'author Ada Lovelace',
+      'author-time 1700000000',
+      '\t}',
+      `${'0'.repeat(40)} 4 4 1`,
+      'author Not Committed Yet',
+      'author-time 1770000000',
+      '\tfunc wip() {}',
+    ].join('\n');
+    const lines = parseBlameLines(text);
+
+    expect(lines.map((line) => line?.commit[0] ?? null)).toEqual(['a', 'b', 'a', null]);
+    expect(lastChange(lines, 1, 3)).toEqual({
+      commit: newer,
+      author: 'Grace Hopper',
+      date: '2025-10-09T08:53:20.000Z',
+    });
+    expect(lastChange(lines, 3)?.commit).toBe(older);
+    expect(lastChange(lines, 4)).toBeNull();
+  });
+});
+
+describe('summarizeAuthors', () => {
+  test('should rank authors and count the fewest owning more than half the lines', () => {
+    const summary = summarizeAuthors(new Map([['bob', 30], ['ada', 60], ['eve', 10]]));
+
+    expect(summary.primary).toBe('ada');
+    expect(summary.authors.map((author) => author.author)).toEqual(['ada', 'bob', 'eve']);
+    expect(summary.bus_factor).toBe(1);
+    expect(summarizeAuthors(new Map([['ada', 50], ['bob', 50]])).bus_factor).toBe(2);
+    expect(summarizeAuthors(new Map()).bus_factor).toBe(0);
+  });
+});
+
+describe('buildDirectoryOwnership', () => {
+  const blame = new Map<string, (string | null)[]>([
+    ['internal/auth/login.go', ['ada', 'ada', 'bob', null]],
+    ['internal/auth/token.go', ['ada']],
+    ['internal/billing/invoice.go', ['bob', 'eve']],
+    ['main.go', ['eve']],
+  ]);
+
+  test('should group files by directory and roll up to a depth', () => {
+    expect(buildDirectoryOwnership(blame).map((row) => [row.path, row.lines, row.primary])).toEqual([
+      ['.', 1, 'eve'],
+      ['internal/auth', 4, 'ada'],
+      ['internal/billing', 2, 'bob'],
+    ]);
+    expect(buildDirectoryOwnership(blame, 1).map((row) => [row.path, row.lines])).toEqual([
+      ['.', 1],
+      ['internal', 6],
+    ]);
+  });
+
+  test('should attribute symbol spans and write CSV', () => {
+    const rows = buildSymbolOwnership(blame, [
+      { id: 1, symbol_name: 'Login', file_path: 'internal/auth/login.go', line_number: 2, end_line: 4 },
+      { id: 2, symbol_name: 'Legacy', file_path: 'internal/auth/login.go', line_number: 1, end_line: null },
+    ]);
+
+    expect(rows.map((row) => [row.symbol, row.lines, row.bus_factor])).toEqual([['Login', 2, 2]]);
+    expect(toOwnershipCsv(rows).split('\n')).toEqual([
+      'scope,path,symbol,line,lines,primary,primary_share,bus_factor,authors',
+      'symbol,internal/auth/login.go,Login,2,2,ada,0.500,2,ada:1;bob:1',
+      '',
+    ]);
+  });
+});
@@ -0,0 +1,161 @@
+/**
+ * Unit tests for the package dependency graph
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { buildDependencyGraph } from '../../../src/cli/package-graph';
+import { type FileImportsRecord } from '../../../src/types/database';
+
+const file = (
+  filePath: string,
+  imports: string[],
+  language = 'go',
+  repoId = 'api',
+  modulePath: string | null = null,
+  moduleDirectory = '.'
+): FileImportsRecord => ({
+  repo_id: repoId,
+  module_path: modulePath,
+  module_directory: modulePath === null ? null : moduleDirectory,
+  file_path: filePath,
+  language,
+  imports,
+});
+
+const GO_FILES = [
+  file('cmd/server/main.go', ['fmt', 'github.com/acme/api/internal/auth', 'github.com/acme/api/internal/store']),
+  file('internal/auth/login.go', ['errors', 'github.com/acme/api/internal/store']),
+  file('internal/auth/session.go', ['github.com/acme/api/internal/auth/token']),
+  file('internal/auth/token/token.go', ['crypto/rand']),
+  file('internal/store/store.go', ['database/sql', 'github.com/lib/pq']),
+];
+
+describe('buildDependencyGraph', () => {
+  test('resolves Go imports by the directories they end with', () => {
+    const graph = buildDependencyGraph(GO_FILES);
+
+    expect(graph.packages().map((node) => [node.id, node.files])).toEqual([
+      ['api:cmd/server', 1],
+      ['api:internal/auth', 2],
+      ['api:internal/auth/token', 1],
+      ['api:internal/store', 1],
+    ]);
+    expect(graph.dependencies('api:cmd/server')).toEqual(['api:internal/auth', 'api:internal/store']);
+    expect(graph.dependencies('api:internal/auth')).toEqual(['api:internal/auth/token', 'api:internal/store']);
+    expect(graph.dependencies('api:internal/store')).toEqual([]);
+  });
+
+  test('keeps external packages on request', () => {
+    const graph = buildDependencyGraph(GO_FILES, { external: true });
+    expect(graph.dependencies('api:internal/store')).toEqual(['database/sql', 'github.com/lib/pq']);
+    expect(graph.packages().find((node) => node.id === 'fmt')).toMatchObject({ external: true, files: 0 });
+  });
+
+  test('resolves relative and root-alias imports against the file', () => {
+    const graph = buildDependencyGraph([
+      file('src/cli/search.ts', ['./output', '../database/queries', '@/types/cli', 'chalk'], 'typescript'),
+      file('src/cli/output.ts', [], 'typescript'),
+      file('src/database/queries.ts', ['pg'], 'typescript'),
+      file('src/types/cli.ts', [], 'typescript'),
+    ]);
+    expect(graph.dependencies('api:src/cli')).toEqual(['api:src/database', 'api:src/types']);
+  });
+
+  test('resolves dotted and relative Python imports', () => {
+    const graph = buildDependencyGraph([
+      file('app/api/routes.py', ['app.auth.service', '..models', 'os'], 'python'),
+      file('app/auth/service.py', ['.tokens'], 'python'),
+      file('app/auth/tokens.py', [], 'python'),
+      file('app/models/user.py', [], 'python'),
+    ]);
+    expect(graph.dependencies('api:app/api')).toEqual(['api:app/auth', 'api:app/models']);
+    expect(graph.dependencies('api:app/auth')).toEqual([]);
+  });
+
+  test('resolves Java imports to the source directory of their package', () => {
+    const graph = buildDependencyGraph([
+      file('src/main/java/com/acme/web/Controller.java', ['com.acme.auth.Login', 'java.util.List'], 'java'),
+      file('src/main/java/com/acme/auth/Login.java', [], 'java'),
+    ]);
+    expect(graph.dependencies('api:src/main/java/com/acme/web')).toEqual(['api:src/main/java/com/acme/auth']);
+  });
+});
+
+describe('DependencyGraph', () => {
+  test('lists direct and transitive dependents', () => {
+    const graph = buildDependencyGraph(GO_FILES);
+    expect(graph.find('store')).toEqual(['api:internal/store']);
+    expect(graph.dependents('api:internal/auth/token')).toEqual(['api:internal/auth']);
+    expect(graph.dependents('api:internal/auth/token', true)).toEqual(['api:cmd/server', 'api:internal/auth']);
+  });
+
+  test('finds packages by trailing directories', () => {
+    const graph = buildDependencyGraph(GO_FILES);
+    expect(graph.find('auth')).toEqual(['api:internal/auth']);
+    expect(graph.find('internal/auth/')).toEqual(['api:internal/auth']);
+    expect(graph.find('api:cmd/server')).toEqual(['api:cmd/server']);
+    expect(graph.find('billing')).toEqual([]);
+  });
+
+  test('reports no cycles for an acyclic graph', () => {
+    expect(buildDependencyGraph(GO_FILES).cycles()).toEqual([]);
+  });
+
+  test('reports a cycle with one loop through it', () => {
+    const graph = buildDependencyGraph([
+      file('a/a.go', ['example.com/m/b']),
+      file('b/b.go', ['example.com/m/c']),
+      file('c/c.go', ['example.com/m/a', 'example.com/m/d']),
+      file('d/d.go', []),
+    ]);
+    expect(graph.cycles()).toEqual([
+      { packages: ['api:a', 'api:b', 'api:c'], path: ['api:a', 'api:b', 'api:c'], cross_module: false },
+    ]);
+  });
+
+  test('reports cycles across module indexes', () => {
+    const graph = buildDependencyGraph([
+      file('client/client.go', ['example.com/shared/types'], 'go', 'api'),
+      file('types/types.go', ['example.com/api/client'], 'go', 'example.com/shared@v1.2.0', 'example.com/shared'),
+    ]);
+    const [cycle] = graph.cycles();
+    expect(cycle.packages).toEqual(['api:client', 'example.com/shared@v1.2.0:types']);
+    expect(cycle.cross_module).toBe(true);
+  });
+
+  test('resolves imports between the Go modules of one index', () => {
+    const [api, shared] = ['github.com/acme/shop/api', 'github.com/acme/shop/shared'];
+    const graph = buildDependencyGraph([
+      file('api/handler/orders.go', [`${shared}/money`], 'go', 'shop', api, 'api'),
+      file('shared/money/money.go', [`${api}/handler`], 'go', 'shop', shared, 'shared'),
+    ]);
+
+    expect(graph.dependencies('shop:api/handler')).toEqual(['shop:shared/money']);
+    const [cycle] = graph.cycles();
+    expect(cycle.packages).toEqual(['shop:api/handler', 'shop:shared/money']);
+    expect(cycle.cross_module).toBe(true);
+  });
+
+  test('writes DOT with cycle edges in red', () => {
+    const graph = buildDependencyGraph([
+      file('a/a.go', ['example.com/m/b']),
+      file('b/b.go', ['example.com/m/a', 'example.com/m/c']),
+      file('c/c.go', []),
+    ]);
+    const dot = graph.toDot();
+    expect(dot).toContain('"api:a" -> "api:b" [color=red];');
+    expect(dot).toContain('"api:b" -> "api:c";');
+    expect(dot.startsWith('digraph packages {')).toBe(true);
+  });
+
+  test('serializes packages, imports, and cycles', () => {
+    const json = JSON.parse(JSON.stringify(buildDependencyGraph(GO_FILES))) as {
+      packages: unknown[];
+      imports: { from: string; to: string }[];
+      cycles: unknown[];
+    };
+    expect(json.packages).toHaveLength(4);
+    expect(json.imports).toContainEqual({ from: 'api:cmd/server', to: 'api:internal/auth' });
+    expect(json.cycles).toEqual([]);
+  });
+});
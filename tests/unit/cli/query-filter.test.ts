@@ -0,0 +1,328 @@
+/**
+ * Unit tests for CLI query filter parsing
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  applyQuery,
+  genericCondition,
+  idConditions,
+  implementsConditions,
+  kindConditions,
+  languageConditions,
+  metricConditions,
+  moduleConditions,
+  parseQuery,
+  traceQuery,
+  wordConditions,
+} from '../../../src/cli/query-filter';
+import { type ResolvedSymbol } from '../../../src/types/retrieval';
+
+const symbol = (
+  symbol_name: string,
+  symbol_type: ResolvedSymbol['symbol_type'],
+  file_path: string,
+  scope: ResolvedSymbol['scope'] = 'exported'
+): ResolvedSymbol => ({
+  symbol_name,
+  symbol_type,
+  file_path,
+  line_number: 1,
+  definition: '',
+  scope,
+});
+
+const SYMBOLS: ResolvedSymbol[] = [
+  symbol('NewAuthService', 'function', 'internal/auth/service.go'),
+  symbol('Login', 'method', 'internal/auth/service.go'),
+  symbol('queryUser', 'method', 'internal/auth/service.go', 'internal'),
+  symbol('AuthConfig', 'class', 'pkg/config/auth.go'),
+];
+
+describe('parseQuery', () => {
+  test('should split terms and field filters', () => {
+    const query = parseQuery('auth kind:func path:internal/');
+
+    expect(query.terms).toEqual(['auth']);
+    expect(query.filters).toEqual([
+      { field: 'kind', value: 'func', negate: false },
+      { field: 'path', value: 'internal/', negate: false },
+    ]);
+  });
+
+  test('should parse negated filters', () => {
+    const query = parseQuery('-scope:internal');
+
+    expect(query.terms).toEqual([]);
+    expect(query.filters).toEqual([{ field: 'scope', value: 'internal', negate: true }]);
+  });
+
+  test('should treat unknown fields as terms', () => {
+    const query = parseQuery('foo:bar');
+
+    expect(query.terms).toEqual(['foo:bar']);
+    expect(query.filters).toEqual([]);
+  });
+});
+
+describe('applyQuery', () => {
+  test('should match kind aliases', () => {
+    const result = applyQuery(SYMBOLS, parseQuery('kind:func'));
+
+    expect(result.map((s) => s.symbol_name)).toEqual(['NewAuthService']);
+  });
+
+  test('should match terms case-insensitively against symbol names', () => {
+    const result = applyQuery(SYMBOLS, parseQuery('auth'));
+
+    expect(result.map((s) => s.symbol_name)).toEqual(['NewAuthService', 'AuthConfig']);
+  });
+
+  test('should match decomposed Unicode terms against precomposed names', () => {
+    const symbols = [symbol('caf\u00e9Service', 'class', 'src/cafe.ts')];
+    const result = applyQuery(symbols, parseQuery('cafe\u0301service'));
+
+    expect(result.map((s) => s.symbol_name)).toEqual(['caf\u00e9Service']);
+  });
+
+  test('should match Windows-style path filters against stored paths', () => {
+    const result = applyQuery(SYMBOLS, parseQuery('path:pkg\\config\\'));
+
+    expect(result.map((s) => s.symbol_name)).toEqual(['AuthConfig']);
+  });
+
+  test('should refine a previous result set', () => {
+    const first = applyQuery(SYMBOLS, parseQuery('path:internal/'));
+    const refined = applyQuery(first, parseQuery('-scope:internal kind:method'));
+
+    expect(first).toHaveLength(3);
+    expect(refined.map((s) => s.symbol_name)).toEqual(['Login']);
+  });
+});
+
+describe('traceQuery', () => {
+  test('should count the candidates left after each term and filter', () => {
+    const steps = traceQuery(SYMBOLS, parseQuery('auth path:internal/ -kind:method'));
+
+    expect(steps).toEqual([
+      { step: 'auth', remaining: 2 },
+      { step: 'path:internal/', remaining: 1 },
+      { step: '-kind:method', remaining: 1 },
+    ]);
+  });
+});
+
+describe('metric filters', () => {
+  const metrics: ResolvedSymbol[] = [
+    { ...symbol('Checkout', 'function', 'internal/cart/checkout.go'), complexity: 14, coverage: 35 },
+    { ...symbol('Total', 'function', 'internal/cart/total.go'), complexity: 3, coverage: 90 },
+    { ...symbol('Render', 'function', 'web/render.ts'), complexity: 12, coverage: null },
+  ];
+
+  test('should compare coverage and complexity, skipping symbols without a value', () => {
+    const query = parseQuery('coverage:<50 complexity:>10');
+
+    expect(applyQuery(metrics, query).map((s) => s.symbol_name)).toEqual(['Checkout']);
+    expect(applyQuery(metrics, parseQuery('complexity:3')).map((s) => s.symbol_name)).toEqual(['Total']);
+  });
+
+  test('should push positive comparisons down to the database', () => {
+    expect(metricConditions(parseQuery('cart coverage:<=50% complexity:>10 -coverage:0 complexity:high'))).toEqual([
+      { column: 'coverage', operator: '<=', value: 50 },
+      { column: 'complexity', operator: '>', value: 10 },
+    ]);
+  });
+});
+
+describe('kindConditions', () => {
+  test('should push positive kind filters down to the database as symbol types', () => {
+    expect(kindConditions(parseQuery('name:Login kind:test -kind:func'))).toEqual(['test']);
+    expect(kindConditions(parseQuery('kind:bench kind:Example'))).toEqual(['benchmark', 'example']);
+  });
+});
+
+describe('lang filters', () => {
+  const go = { ...symbol('Config', 'class', 'internal/config/config.go'), language: 'go' };
+  const py = { ...symbol('Config', 'class', 'tools/config.py'), language: 'python' };
+  const ts = { ...symbol('Config', 'interface', 'web/src/config.ts'), language: 'typescript' };
+
+  test('should match languages by name or alias', () => {
+    expect(applyQuery([go, py, ts], parseQuery('lang:python'))).toEqual([py]);
+    expect(applyQuery([go, py, ts], parseQuery('lang:TS'))).toEqual([ts]);
+    expect(applyQuery([go, py, ts], parseQuery('Config -lang:go'))).toEqual([py, ts]);
+  });
+
+  test('should push positive lang filters down as stored language names', () => {
+    expect(languageConditions(parseQuery('Config lang:py lang:golang -lang:js'))).toEqual(['python', 'go']);
+  });
+});
+
+describe('implements filters', () => {
+  const reader = { ...symbol('Buffer', 'class', 'internal/buf/buffer.go'), implements: ['io.Reader', 'error'] };
+  const store = { ...symbol('Memory', 'class', 'store/memory.go'), implements: ['github.com/acme/shop/store.Store'] };
+
+  test('should match interfaces by name, package, or import path', () => {
+    expect(applyQuery([reader, store], parseQuery('implements:Reader'))).toEqual([reader]);
+    expect(applyQuery([reader, store], parseQuery('implements:store.Store'))).toEqual([store]);
+    expect(applyQuery([reader, store], parseQuery('implements:github.com/acme/shop/store.Store'))).toEqual([store]);
+    expect(applyQuery([reader, store], parseQuery('implements:shop.Store'))).toEqual([]);
+    expect(applyQuery([reader, store], parseQuery('-implements:error'))).toEqual([store]);
+  });
+
+  test('should push positive implements filters down to the database', () => {
+    expect(implementsConditions(parseQuery('implements:io.Reader -implements:error'))).toEqual([
+      { name: 'reader', package: 'io' },
+    ]);
+  });
+});
+
+describe('generic filters', () => {
+  const mapFn = { ...symbol('Map', 'function', 'collections/map.go'), type_parameters: ['S ~[]E', 'E any', 'R any'] };
+  const tree = { ...symbol('Tree', 'class', 'collections/tree.go'), type_parameters: ['T constraints.Ordered'] };
+  const sum = { ...symbol('Sum', 'function', 'collections/sum.go'), type_parameters: ['N ~int | ~float64'] };
+  const plain = { ...symbol('Reverse', 'function', 'collections/reverse.go'), type_parameters: [] };
+  const all = [mapFn, tree, sum, plain];
+
+  test('should select generics and the others', () => {
+    expect(applyQuery(all, parseQuery('generic:true'))).toEqual([mapFn, tree, sum]);
+    expect(applyQuery(all, parseQuery('generic:false'))).toEqual([plain]);
+    expect(applyQuery(all, parseQuery('generic:maybe'))).toEqual([]);
+  });
+
+  test('should match constraints by union term and by package', () => {
+    expect(applyQuery(all, parseQuery('constraint:any'))).toEqual([mapFn]);
+    expect(applyQuery(all, parseQuery('constraint:int'))).toEqual([sum]);
+    expect(applyQuery(all, parseQuery('constraint:"~int | ~float64"'))).toEqual([sum]);
+    expect(applyQuery(all, parseQuery('constraint:Ordered'))).toEqual([tree]);
+    expect(applyQuery(all, parseQuery('constraint:golang.org/x/exp/constraints.Ordered'))).toEqual([tree]);
+    expect(applyQuery(all, parseQuery('constraint:cmp.Ordered'))).toEqual([]);
+  });
+
+  test('should push generic filters down to the database', () => {
+    expect(genericCondition(parseQuery('constraint:comparable'))).toBe(true);
+    expect(genericCondition(parseQuery('generic:false'))).toBe(false);
+    expect(genericCondition(parseQuery('-generic:true kind:func'))).toBeUndefined();
+  });
+});
+
+describe('module filters', () => {
+  const orders = { ...symbol('PlaceOrder', 'function', 'api/orders.go'), package_name: 'github.com/acme/shop/api' };
+  const money = { ...symbol('Money', 'class', 'shared/money/money.go'), package_name: 'github.com/acme/shop/shared' };
+  const script = symbol('main', 'function', 'scripts/gen.go', 'internal');
+  const all = [orders, money, script];
+
+  test('should match a module path or its trailing elements', () => {
+    expect(applyQuery(all, parseQuery('module:github.com/acme/shop/api'))).toEqual([orders]);
+    expect(applyQuery(all, parseQuery('module:shop/shared'))).toEqual([money]);
+    expect(applyQuery(all, parseQuery('module:hop/shared'))).toEqual([]);
+    expect(applyQuery(all, parseQuery('module:~/(api|shared)$'))).toEqual([orders, money]);
+    expect(applyQuery(all, parseQuery('-module:api'))).toEqual([money, script]);
+  });
+
+  test('should push plain module filters down to the database', () => {
+    expect(moduleConditions(parseQuery('module:Shop/API module:~api -module:shared'))).toEqual(['shop/api']);
+  });
+});
+
+describe('word filters', () => {
+  const service = symbol('NewAuthService', 'function', 'auth/service.go');
+  const registry = symbol('service_registry', 'variable', 'app/registry.py');
+  const parser = symbol('parseHTTPRequest', 'function', 'web/parse.ts');
+  const all = [service, registry, parser];
+
+  test('should match the words of names, stemmed, in any case style', () => {
+    expect(applyQuery(all, parseQuery('word:service'))).toEqual([service, registry]);
+    expect(applyQuery(all, parseQuery('word:services'))).toEqual([service, registry]);
+    expect(applyQuery(all, parseQuery('word:authService'))).toEqual([service]);
+    expect(applyQuery(all, parseQuery('word:parser word:http'))).toEqual([parser]);
+    expect(applyQuery(all, parseQuery('word:serv'))).toEqual([]);
+    expect(applyQuery(all, parseQuery('word:~^reg'))).toEqual([registry]);
+    expect(applyQuery(all, parseQuery('-word:auth'))).toEqual([registry, parser]);
+  });
+
+  test('should push plain word filters down to the database as word and stem', () => {
+    expect(wordConditions(parseQuery('word:AuthServices word:~^reg -word:new'))).toEqual([
+      ['auth'],
+      ['services', 'servic'],
+    ]);
+  });
+});
+
+describe('id filters', () => {
+  const LOGIN = 'cindex gomod example.com/shop . `example.com/shop/auth`/Service#Login().';
+  const login = { ...symbol('Service.Login', 'method', 'auth/service.go'), symbol_id: LOGIN };
+  const logout = { ...login, symbol_name: 'Service.Logout', symbol_id: LOGIN.replace('Login', 'Logout') };
+  const unnamed = symbol('Service.Reset', 'method', 'auth/service.go');
+  const all = [login, logout, unnamed];
+
+  test('should match structured IDs exactly, quoted, or by regular expression', () => {
+    expect(applyQuery(all, parseQuery(`id:"${LOGIN}"`))).toEqual([login]);
+    expect(applyQuery(all, parseQuery(`id:"${LOGIN.toLowerCase()}"`))).toEqual([]);
+    expect(applyQuery(all, parseQuery('id:~/Service#Log'))).toEqual([login, logout]);
+    expect(applyQuery(all, parseQuery(`-id:"${LOGIN}"`))).toEqual([logout, unnamed]);
+  });
+
+  test('should push exact IDs down to the database', () => {
+    expect(idConditions(parseQuery(`id:"${LOGIN}" id:~Logout -id:x`))).toEqual([LOGIN]);
+  });
+});
+
+describe('query trees', () => {
+  const methods: ResolvedSymbol[] = [
+    symbol('AuthService.Login', 'method', 'internal/auth/service.go'),
+    symbol('AuthService.refreshSession', 'method', 'internal/auth/session.go', 'internal'),
+    { ...symbol('Get', 'method', 'internal/cache/cache.go'), definition: 'func (c *Cache[K, V]) Get(key K) V' },
+    symbol('TestSessionExpiry', 'test', 'internal/auth/session_test.go'),
+    symbol('NewSession', 'function', 'pkg/session/session.go'),
+  ];
+  const names = (query: string): string[] => applyQuery(methods, parseQuery(query)).map((s) => s.symbol_name);
+
+  test('should keep top-level filters apart from OR groups', () => {
+    const query = parseQuery('kind:method (receiver:AuthService OR name:~^get$) -path:**_test.go');
+
+    expect(query.terms).toEqual([]);
+    expect(query.filters).toEqual([
+      { field: 'kind', value: 'method', negate: false },
+      { field: 'path', value: '**_test.go', negate: true },
+    ]);
+    expect(query.groups).toEqual([
+      {
+        type: 'or',
+        nodes: [
+          { type: 'filter', filter: { field: 'receiver', value: 'AuthService', negate: false } },
+          { type: 'filter', filter: { field: 'name', value: '~^get$', negate: false } },
+        ],
+      },
+    ]);
+    expect(kindConditions(query)).toEqual(['method']);
+  });
+
+  test('should match receivers by method name or declaration', () => {
+    expect(names('receiver:authservice')).toEqual(['AuthService.Login', 'AuthService.refreshSession']);
+    expect(names('receiver:*Cache')).toEqual(['Get']);
+    expect(names('receiver:~^auth name:~session')).toEqual(['AuthService.refreshSession']);
+  });
+
+  test('should match path globs against the whole path', () => {
+    expect(names('path:internal/** -path:**_test.go session')).toEqual(['AuthService.refreshSession']);
+    expect(names('path:internal/*/session*.go')).toEqual(['AuthService.refreshSession', 'TestSessionExpiry']);
+    expect(names('path:**/session.go')).toEqual(['AuthService.refreshSession', 'NewSession']);
+  });
+
+  test('should evaluate OR, AND, and negated groups', () => {
+    expect(names('kind:test OR kind:function')).toEqual(['TestSessionExpiry', 'NewSession']);
+    expect(names('session -(kind:test OR scope:internal)')).toEqual(['NewSession']);
+    expect(names('(kind:method AND scope:internal) OR path:pkg/')).toEqual([
+      'AuthService.refreshSession',
+      'NewSession',
+    ]);
+    expect(names('name:~(get|login)$')).toEqual(['AuthService.Login', 'Get']);
+  });
+
+  test('should trace groups after terms and filters', () => {
+    expect(traceQuery(methods, parseQuery('session (kind:test OR kind:function)'))).toEqual([
+      { step: 'session', remaining: 3 },
+      { step: '(kind:test OR kind:function)', remaining: 2 },
+    ]);
+  });
+});
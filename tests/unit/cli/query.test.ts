@@ -0,0 +1,119 @@
+/**
+ * Unit tests for batch symbol queries (cindex query --stdin)
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { type Pool } from 'pg';
+import { parseBatchLine, runBatchQuery, type BatchResult } from '../../../src/cli/query';
+import { type ResolvedSymbol } from '../../../src/types/retrieval';
+
+const symbol = (name: string): ResolvedSymbol => ({
+  id: 1,
+  symbol_name: name,
+  symbol_type: 'function',
+  file_path: 'auth/login.go',
+  line_number: 3,
+  definition: `func ${name}()`,
+  scope: 'exported',
+});
+
+/** Pool answering each search with the symbols named like its term, slower for earlier queries */
+const poolOf = (names: string[]): Pool => {
+  let calls = 0;
+  return {
+    query: async (_sql: string, params: unknown[]) => {
+      const term = String(params[0]).replace(/%/g, '').toLowerCase();
+      await new Promise((resolve) => setTimeout(resolve, Math.max(0, 20 - 5 * calls++)));
+      if (term === 'boom') throw new Error('connection reset');
+      return { rows: names.filter((name) => name.toLowerCase().includes(term)).map(symbol) };
+    },
+  } as unknown as Pool;
+};
+
+describe('parseBatchLine', () => {
+  test('reads tab-separated IDs, bare queries, and JSON objects', () => {
+    expect(parseBatchLine('a\tkind:func Login', 1)).toEqual({ id: 'a', query: 'kind:func Login' });
+    expect(parseBatchLine('  Login  ', 2)).toEqual({ id: '2', query: 'Login' });
+    expect(parseBatchLine('{"id": 7, "query": "Store", "repo_id": "api"}', 3)).toEqual({
+      id: '7',
+      query: 'Store',
+      repo_id: 'api',
+    });
+  });
+
+  test('skips blank lines and comments, and marks JSON without a query invalid under the line number', () => {
+    expect(parseBatchLine('', 1)).toBeNull();
+    expect(parseBatchLine('# lookups for the rename', 2)).toBeNull();
+    expect(parseBatchLine('{"id": "x"}', 3)).toEqual({
+      id: '3',
+      query: '{"id": "x"}',
+      invalid: 'Line 3: "query" must be a string',
+    });
+    expect(parseBatchLine('{"id": ', 4)).toMatchObject({ id: '4', invalid: expect.stringContaining('Line 4') });
+    expect(parseBatchLine('{}', 5)?.invalid).toBeDefined();
+  });
+});
+
+describe('runBatchQuery', () => {
+  test('keeps input order and reports failed queries under their ID', async () => {
+    const queries = [
+      { id: 'a', query: 'Login' },
+      { id: 'b', query: 'boom' },
+      { id: 'c', query: 'Logout' },
+    ];
+    const results: BatchResult[] = [];
+    for await (const result of runBatchQuery(poolOf(['Login', 'Logout']), queries, { concurrency: 2 })) {
+      results.push(result);
+    }
+    expect(results.map((result) => result.id)).toEqual(['a', 'b', 'c']);
+    expect(results[0].symbols.map((s) => s.symbol_name)).toEqual(['Login']);
+    expect(results[1]).toMatchObject({ symbols: [], error: expect.stringContaining('connection reset') });
+    expect(results[2].symbols.map((s) => s.symbol_name)).toEqual(['Logout']);
+  });
+
+  test('reports invalid lines as failed and runs the queries after them', async () => {
+    const lines = ['a\tLogin', '{"id": ', 'c\tLogout'];
+    const queries = lines.flatMap((line, index) => parseBatchLine(line, index + 1) ?? []);
+    const results: BatchResult[] = [];
+    for await (const result of runBatchQuery(poolOf(['Login', 'Logout']), queries)) results.push(result);
+
+    expect(results.map(({ id, error }) => ({ id, failed: error !== null }))).toEqual([
+      { id: 'a', failed: false },
+      { id: '2', failed: true },
+      { id: 'c', failed: false },
+    ]);
+    expect(results[2].symbols).toHaveLength(1);
+  });
+
+  test('lists every function above --min-complexity, most complex first, past the search limit', async () => {
+    const functions = Array.from({ length: 1200 }, (_, index) => ({
+      ...symbol(`fn${String(index).padStart(4, '0')}`),
+      id: index + 1,
+      line_number: index + 1,
+      complexity: 15 + (index % 40),
+    }));
+    // Pages of the name-ordered search, each asking for one row past the page
+    let served = 0;
+    const pool = {
+      query: (_sql: string, params: unknown[]) => {
+        const limit = Number(params[params.length - 1]);
+        const rows = functions.slice(served, served + limit);
+        served += limit - 1;
+        return Promise.resolve({ rows });
+      },
+    } as unknown as Pool;
+
+    const results: BatchResult[] = [];
+    for await (const result of runBatchQuery(pool, [{ id: '1', query: '' }], { minComplexity: 15 })) {
+      results.push(result);
+    }
+
+    const { symbols } = results[0];
+    expect(symbols).toHaveLength(1200);
+    expect(symbols.slice(0, 2).map((found) => [found.complexity, found.line_number])).toEqual([
+      [54, 40],
+      [54, 80],
+    ]);
+    expect(symbols[symbols.length - 1]).toMatchObject({ complexity: 15, line_number: 1161 });
+  });
+});
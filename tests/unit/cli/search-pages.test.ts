@@ -0,0 +1,73 @@
+/**
+ * Unit tests for paged symbol searches (--limit, --cursor, --output ndjson)
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { type Pool } from 'pg';
+import {
+  buildSymbolSearchQuery,
+  decodeSymbolCursor,
+  encodeSymbolCursor,
+  searchSymbolsPage,
+} from '../../../src/database/queries';
+import { type ResolvedSymbol } from '../../../src/types/retrieval';
+
+const symbol = (id: number, name: string, scope: 'exported' | 'internal' = 'exported'): ResolvedSymbol => ({
+  id,
+  symbol_name: name,
+  symbol_type: 'function',
+  file_path: 'auth/login.go',
+  line_number: id,
+  definition: `func ${name}()`,
+  scope,
+});
+
+/** Pool answering every query with the given rows, recording the parameters it was sent */
+const poolOf = (rows: ResolvedSymbol[], sent: unknown[][] = []): Pool =>
+  ({
+    query: (_sql: string, params: unknown[]) => {
+      sent.push(params);
+      return Promise.resolve({ rows: rows.slice(0, Number(params[params.length - 1])) });
+    },
+  }) as unknown as Pool;
+
+describe('symbol cursors', () => {
+  test('should round-trip the position after a symbol', () => {
+    expect(decodeSymbolCursor(encodeSymbolCursor(symbol(7, 'Login')))).toEqual([0, 'Login', 7]);
+    expect(decodeSymbolCursor(encodeSymbolCursor(symbol(9, 'hash', 'internal')))).toEqual([1, 'hash', 9]);
+  });
+
+  test('should reject malformed cursors', () => {
+    expect(decodeSymbolCursor('not a cursor')).toBeNull();
+    expect(decodeSymbolCursor(Buffer.from('[2,"x",1]').toString('base64url'))).toBeNull();
+    expect(decodeSymbolCursor(Buffer.from('{"id":1}').toString('base64url'))).toBeNull();
+  });
+
+  test('should continue after the cursor in name order', () => {
+    const cursor = encodeSymbolCursor(symbol(7, 'Login'));
+    const { sql, params } = buildSymbolSearchQuery('Log', { cursor });
+
+    expect(sql).toContain("(CASE WHEN scope = 'exported' THEN 0 ELSE 1 END, symbol_name, id) > ($2::int, $3::text");
+    expect(params).toEqual(['%Log%', 0, 'Login', 7, 50]);
+    expect(() => buildSymbolSearchQuery('', { cursor, embedding: [0.1] })).toThrow('name order');
+  });
+});
+
+describe('searchSymbolsPage', () => {
+  test('should return a cursor after the last symbol when more follow', async () => {
+    const sent: unknown[][] = [];
+    const page = await searchSymbolsPage(poolOf([symbol(1, 'A'), symbol(2, 'B'), symbol(3, 'C')], sent), '', {
+      limit: 2,
+    });
+
+    expect(page.symbols.map((found) => found.symbol_name)).toEqual(['A', 'B']);
+    expect(page.next_cursor).not.toBeNull();
+    expect(decodeSymbolCursor(page.next_cursor ?? '')).toEqual([0, 'B', 2]);
+    expect(sent[0][sent[0].length - 1]).toBe(3);
+  });
+
+  test('should end on the last page', async () => {
+    const page = await searchSymbolsPage(poolOf([symbol(1, 'A')]), '', { limit: 2 });
+    expect(page).toMatchObject({ next_cursor: null });
+  });
+});
@@ -0,0 +1,62 @@
+/**
+ * Unit tests for source preview syntax tokenization
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { tokenize, type SyntaxToken } from '../../../src/cli/syntax';
+
+/** Non-text tokens as kind:text pairs */
+const styled = (tokens: SyntaxToken[]): string[] => {
+  return tokens.filter((token) => token.kind !== 'text').map((token) => `${token.kind}:${token.text}`);
+};
+
+describe('tokenize', () => {
+  test('should preserve the input text exactly', () => {
+    const code = 'export const add = (a: number, b = 2): number => {\n  // sum\n  return a + b; /* done */\n};\n';
+
+    expect(
+      tokenize(code, 'typescript')
+        .map((token) => token.text)
+        .join('')
+    ).toBe(code);
+  });
+
+  test('should recognize keywords, strings, numbers, and comments', () => {
+    expect(styled(tokenize("const name = 'cindex'; // tool\nreturn 42;", 'typescript'))).toEqual([
+      'keyword:const',
+      "string:'cindex'",
+      'comment:// tool',
+      'keyword:return',
+      'number:42',
+    ]);
+  });
+
+  test('should not treat keywords inside identifiers as keywords', () => {
+    expect(styled(tokenize('const format = importer;', 'javascript'))).toEqual(['keyword:const']);
+  });
+
+  test('should keep escaped quotes inside strings', () => {
+    expect(styled(tokenize('"say \\"hi\\"" + x', 'go'))).toEqual(['string:"say \\"hi\\""']);
+  });
+
+  test('should span lines only for multi-line string delimiters', () => {
+    expect(styled(tokenize('x = "open\ny = 1', 'python'))).toEqual(['string:"open', 'number:1']);
+    expect(styled(tokenize('def f():\n    """Doc\n    more"""', 'python'))).toEqual([
+      'keyword:def',
+      'string:"""Doc\n    more"""',
+    ]);
+  });
+
+  test('should use language comment markers', () => {
+    expect(styled(tokenize('# note\nend', 'ruby'))).toEqual(['comment:# note', 'keyword:end']);
+    expect(styled(tokenize('# if', 'go'))).toEqual(['keyword:if']);
+  });
+
+  test('should not read Rust lifetimes as strings', () => {
+    expect(styled(tokenize("fn get<'a>(s: &'a str) {}", 'rust'))).toEqual(['keyword:fn']);
+  });
+
+  test('should fall back to C-style rules for unknown languages', () => {
+    expect(styled(tokenize('x /* a */ y // b', 'unknown'))).toEqual(['comment:/* a */', 'comment:// b']);
+  });
+});
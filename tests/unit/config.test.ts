@@ -72,6 +72,46 @@ describe('Configuration System', () => {
       expect(config.features.enable_workspace_detection).toBe(false);
       expect(config.features.enable_multi_repo).toBe(true);
     });
+
+    it('should read the store backend and its file', () => {
+      process.env.POSTGRES_PASSWORD = 'testpass';
+      expect(loadConfig().store).toEqual({ backend: 'postgres', sqlite_path: undefined });
+
+      process.env.STORE_BACKEND = 'SQLite';
+      process.env.SQLITE_PATH = '/var/lib/cindex/store.db';
+      expect(loadConfig().store).toEqual({ backend: 'sqlite', sqlite_path: '/var/lib/cindex/store.db' });
+
+      process.env.STORE_BACKEND = 'mysql';
+      expect(() => loadConfig()).toThrow('STORE_BACKEND');
+    });
+
+    it('should accept CINDEX_ prefixed variables', () => {
+      process.env.CINDEX_POSTGRES_PASSWORD = 'prefixed';
+      delete process.env.POSTGRES_PASSWORD;
+      process.env.CINDEX_LOG_LEVEL = 'debug';
+
+      const config = loadConfig();
+
+      expect(config.database.password).toBe('prefixed');
+      expect(config.logging.level).toBe('DEBUG');
+    });
+
+    it('should prefer CINDEX_ prefixed variables over unprefixed ones', () => {
+      process.env.POSTGRES_PASSWORD = 'testpass';
+      process.env.POSTGRES_HOST = 'plain-host';
+      process.env.CINDEX_POSTGRES_HOST = 'prefixed-host';
+
+      const config = loadConfig();
+
+      expect(config.database.host).toBe('prefixed-host');
+    });
+
+    it('should report the prefixed variable name in validation errors', () => {
+      process.env.POSTGRES_PASSWORD = 'testpass';
+      process.env.CINDEX_POSTGRES_PORT = 'not-a-port';
+
+      expect(() => loadConfig()).toThrow('CINDEX_POSTGRES_PORT');
+    });
   });
 
   describe('validateConfig', () => {
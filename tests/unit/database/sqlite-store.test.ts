@@ -0,0 +1,121 @@
+/**
+ * Unit tests for the SQLite store: FTS5 doc-comment search shared through one file
+ */
+
+import { afterEach, beforeEach, describe, test, expect } from '@jest/globals';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import { docQueries, openSqliteStore, type SqliteStore } from '../../../src/database/sqlite-store';
+import { type StoredSymbol } from '../../../src/types/store';
+
+const SYMBOLS: StoredSymbol[] = [
+  {
+    symbol_name: 'SessionTimeout',
+    symbol_type: 'constant',
+    file_path: 'auth/session.go',
+    line_number: 12,
+    doc_comment: 'SessionTimeout is how long until an idle session expires.',
+    exported: true,
+  },
+  {
+    symbol_name: 'refreshSession',
+    symbol_type: 'function',
+    file_path: 'auth/session.go',
+    line_number: 40,
+    doc_comment: 'refreshSession extends the current session.',
+    exported: false,
+  },
+  {
+    symbol_name: 'Retry',
+    symbol_type: 'function',
+    file_path: 'net/retry.go',
+    line_number: 5,
+    doc_comment: null,
+    exported: true,
+  },
+];
+
+/**
+ * Pages of symbols as syncStore reads them from PostgreSQL
+ */
+const pagesOf = async function* (...pages: StoredSymbol[][]): AsyncGenerator<StoredSymbol[]> {
+  for (const page of pages) yield await Promise.resolve(page);
+};
+
+describe('docQueries', () => {
+  test('should quote each word once and leave out stop words', () => {
+    expect(docQueries('When the session EXPIRES, session "ends" OR NOT')).toEqual({
+      any: '"session" OR "expires" OR "ends" OR "not"',
+      every: '"session" AND "expires" AND "ends" AND "not"',
+    });
+    expect(docQueries('the - of')).toBeNull();
+  });
+});
+
+describe('SqliteStore', () => {
+  let dir: string;
+  let file: string;
+  let store: SqliteStore;
+
+  beforeEach(async () => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-store-'));
+    file = path.join(dir, 'nested', 'store.db');
+    store = await openSqliteStore(file);
+  });
+
+  afterEach(async () => {
+    await store.close();
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  test('should find stemmed words, symbols matching every word first', async () => {
+    const pages = pagesOf(SYMBOLS.slice(0, 2), SYMBOLS.slice(2));
+    await store.replaceIndex({ repo_id: 'api', searched_by_default: true }, pages);
+
+    const matches = await store.searchDocs('session expiration');
+
+    expect(matches.map(({ symbol_name, complete }) => ({ symbol_name, complete }))).toEqual([
+      { symbol_name: 'SessionTimeout', complete: true },
+      { symbol_name: 'refreshSession', complete: false },
+    ]);
+    expect(matches[0]).toMatchObject({ repo_id: 'api', symbol_type: 'constant', file_path: 'auth/session.go' });
+    expect((await store.searchDocs('retry')).map((match) => match.symbol_name)).toEqual(['Retry']);
+  });
+
+  test('should replace an index and leave indexes not searched by default out of unscoped searches', async () => {
+    await store.replaceIndex({ repo_id: 'api', searched_by_default: true }, pagesOf(SYMBOLS));
+    await store.replaceIndex({ repo_id: 'api', searched_by_default: true }, pagesOf(SYMBOLS.slice(2)));
+    await store.replaceIndex({ repo_id: 'golang.org/x/net', searched_by_default: false }, pagesOf(SYMBOLS));
+
+    expect(await store.searchDocs('session')).toEqual([]);
+    expect((await store.searchDocs('session', { repoId: 'golang.org/x/net' })).length).toBe(2);
+
+    await store.deleteIndex('golang.org/x/net');
+    expect(await store.searchDocs('session', { repoId: 'golang.org/x/net' })).toEqual([]);
+  });
+
+  test('should keep the previous rows when a rewrite fails', async () => {
+    await store.replaceIndex({ repo_id: 'api', searched_by_default: true }, pagesOf(SYMBOLS));
+    const failing = async function* (): AsyncGenerator<StoredSymbol[]> {
+      yield await Promise.resolve([]);
+      throw new Error('connection lost');
+    };
+
+    await expect(store.replaceIndex({ repo_id: 'api', searched_by_default: true }, failing())).rejects.toThrow(
+      'connection lost'
+    );
+    expect((await store.searchDocs('session')).length).toBe(2);
+  });
+
+  test('should share one file between stores', async () => {
+    const reader = await openSqliteStore(file);
+    try {
+      await store.replaceIndex({ repo_id: 'api', searched_by_default: true }, pagesOf(SYMBOLS));
+
+      expect((await reader.searchDocs('idle')).map((match) => match.symbol_name)).toEqual(['SessionTimeout']);
+    } finally {
+      await reader.close();
+    }
+  });
+});
@@ -0,0 +1,88 @@
+/**
+ * Unit tests for embedding providers
+ */
+
+import { afterEach, beforeEach, describe, expect, it } from '@jest/globals';
+
+import { createEmbedder, ollamaEmbeddingModel, OnnxEmbedder, OpenAIEmbedder } from '@utils/embedders';
+import { VectorDimensionError } from '@utils/errors';
+import { createOllamaClient } from '@utils/ollama';
+import { DEFAULT_CONFIG, type CindexConfig } from '@/types/config';
+
+/** Requests seen by the fake fetch */
+interface SentRequest {
+  url: string;
+  headers: Record<string, string>;
+  body: { model: string; input: string[]; dimensions: number };
+}
+
+describe('OpenAIEmbedder', () => {
+  const originalFetch = globalThis.fetch;
+  let sent: SentRequest[];
+  let dimensions: number;
+
+  beforeEach(() => {
+    sent = [];
+    dimensions = 3;
+    globalThis.fetch = ((url: string, init: RequestInit) => {
+      const body = JSON.parse(String(init.body)) as SentRequest['body'];
+      sent.push({ url, headers: init.headers as Record<string, string>, body });
+      // Entries out of order, as the API allows
+      const data = body.input
+        .map((text, index) => ({ index, embedding: Array.from({ length: dimensions }, () => text.length) }))
+        .reverse();
+      return Promise.resolve(new Response(JSON.stringify({ data }), { status: 200 }));
+    }) as typeof fetch;
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  const embedder = new OpenAIEmbedder('https://llm.internal/v1/', 'sk-test', { timeout: 1000, retry_attempts: 0 });
+
+  it('posts to /embeddings with the model, dimensions, and bearer token', async () => {
+    const vector = await embedder.generateEmbedding('text-embedding-3-small', 'verify a password', 3);
+
+    expect(vector).toEqual([17, 17, 17]);
+    expect(sent[0].url).toBe('https://llm.internal/v1/embeddings');
+    expect(sent[0].headers.Authorization).toBe('Bearer sk-test');
+    expect(sent[0].body).toEqual({ model: 'text-embedding-3-small', input: ['verify a password'], dimensions: 3 });
+  });
+
+  it('returns batch vectors in input order', async () => {
+    const vectors = await embedder.generateEmbeddingBatch('m', ['a', 'bb', 'ccc'], 3);
+    expect(vectors.map((vector) => vector[0])).toEqual([1, 2, 3]);
+    expect(sent).toHaveLength(1);
+  });
+
+  it('rejects vectors of another size', async () => {
+    dimensions = 2;
+    let error: unknown;
+    try {
+      await embedder.generateEmbedding('m', 'text', 3);
+    } catch (caught) {
+      error = caught;
+    }
+    expect(error instanceof VectorDimensionError).toBe(true);
+  });
+});
+
+describe('createEmbedder', () => {
+  const config = (provider: CindexConfig['embedding']['provider']): CindexConfig => ({
+    ...DEFAULT_CONFIG,
+    embedding: { ...DEFAULT_CONFIG.embedding, provider },
+  });
+  const ollama = createOllamaClient(DEFAULT_CONFIG.ollama);
+
+  it('uses the Ollama client by default', () => {
+    expect(createEmbedder(config('ollama'), ollama)).toBe(ollama);
+    expect(ollamaEmbeddingModel(config('ollama').embedding)).toBe('bge-m3:567m');
+  });
+
+  it('builds the configured provider', () => {
+    expect(createEmbedder(config('openai'), ollama) instanceof OpenAIEmbedder).toBe(true);
+    expect(createEmbedder(config('onnx'), ollama) instanceof OnnxEmbedder).toBe(true);
+    expect(ollamaEmbeddingModel(config('onnx').embedding)).toBeNull();
+  });
+});
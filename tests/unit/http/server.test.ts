@@ -0,0 +1,172 @@
+/**
+ * Unit tests for the REST API (cindex serve --http)
+ */
+
+import * as net from 'node:net';
+
+import { describe, test, expect } from '@jest/globals';
+import { type Pool } from 'pg';
+import {
+  decodeSymbolId,
+  encodeCursor,
+  encodeSymbolId,
+  endpointOf,
+  HttpApi,
+  pageParams,
+  parseListenAddress,
+  serveMetrics,
+  snippetParams,
+  toPage,
+} from '../../../src/http/server';
+import { queryDuration, resetMetrics } from '../../../src/utils/metrics';
+
+/** Pool whose every query fails, as with the database down */
+const unreachable = {
+  query: () => Promise.reject(new Error('connect ECONNREFUSED 127.0.0.1:5432')),
+} as unknown as Pool;
+
+describe('parseListenAddress', () => {
+  test('should accept ports with and without hosts', () => {
+    expect(parseListenAddress(':8080')).toEqual({ host: undefined, port: 8080 });
+    expect(parseListenAddress('8080')).toEqual({ host: undefined, port: 8080 });
+    expect(parseListenAddress('127.0.0.1:0')).toEqual({ host: '127.0.0.1', port: 0 });
+    expect(parseListenAddress('[::1]:9000')).toEqual({ host: '::1', port: 9000 });
+  });
+
+  test('should reject addresses without a valid port', () => {
+    expect(() => parseListenAddress('localhost')).toThrow('Invalid listen address');
+    expect(() => parseListenAddress(':70000')).toThrow('Invalid listen address');
+  });
+});
+
+describe('symbol IDs', () => {
+  test('should round-trip a declaration through a URL-safe ID', () => {
+    const key = { repo_id: 'api', file_path: 'auth/login service.go', line_number: 42, symbol_name: 'Server.Login' };
+    const id = encodeSymbolId(key);
+    expect(id).toMatch(/^[\w-]+$/);
+    expect(decodeSymbolId(id)).toEqual(key);
+    expect(decodeSymbolId(encodeSymbolId({ ...key, repo_id: null }))).toEqual({ ...key, repo_id: null });
+  });
+
+  test('should reject IDs it did not make', () => {
+    expect(decodeSymbolId('not-an-id')).toBeNull();
+    expect(decodeSymbolId(Buffer.from('["api","a.go",0,"Run"]').toString('base64url'))).toBeNull();
+    expect(decodeSymbolId(Buffer.from('{"file_path":"a.go"}').toString('base64url'))).toBeNull();
+  });
+});
+
+describe('pagination', () => {
+  test('should page through results with next_cursor', () => {
+    const results = ['a', 'b', 'c', 'd', 'e'];
+    const first = toPage(results.slice(0, 3), 0, 2);
+    expect(first).toEqual({ items: ['a', 'b'], next_cursor: encodeCursor(2) });
+
+    const next = pageParams(new URLSearchParams({ limit: '2', cursor: String(first.next_cursor) }));
+    expect(next).toEqual({ offset: 2, limit: 2 });
+    expect(toPage(results, next.offset, next.limit).items).toEqual(['c', 'd']);
+    expect(toPage(results, 4, 2)).toEqual({ items: ['e'], next_cursor: null });
+  });
+
+  test('should reject malformed limits and cursors', () => {
+    expect(pageParams(new URLSearchParams())).toEqual({ offset: 0, limit: 50 });
+    expect(() => pageParams(new URLSearchParams({ limit: '0' }))).toThrow('limit must be');
+    expect(() => pageParams(new URLSearchParams({ limit: '2.5' }))).toThrow('limit must be');
+    expect(() => pageParams(new URLSearchParams({ cursor: 'abc' }))).toThrow('cursor is not');
+  });
+});
+
+describe('snippetParams', () => {
+  test('should read the context of snippets only when they are included', () => {
+    expect(snippetParams(new URLSearchParams())).toBeUndefined();
+    expect(snippetParams(new URLSearchParams({ include_snippet: 'false', context: '5' }))).toBeUndefined();
+    expect(snippetParams(new URLSearchParams({ include_snippet: 'true' }))).toBe(2);
+    expect(snippetParams(new URLSearchParams({ include_snippet: 'true', context: '0' }))).toBe(0);
+    expect(() => snippetParams(new URLSearchParams({ include_snippet: 'yes' }))).toThrow('include_snippet must');
+    expect(() => snippetParams(new URLSearchParams({ include_snippet: 'true', context: '-1' }))).toThrow('context');
+  });
+});
+
+describe('HttpApi', () => {
+  test('should answer unknown endpoints, methods, and parameters with errors', async () => {
+    const api = new HttpApi(unreachable);
+
+    expect(await api.handle('GET', '/nope')).toMatchObject({ status: 404, body: { error: { code: 'NOT_FOUND' } } });
+    expect(await api.handle('POST', '/symbols?q=a')).toMatchObject({ status: 405 });
+    expect(await api.handle('GET', '/symbols')).toMatchObject({
+      status: 400,
+      body: { error: { code: 'MISSING_PARAMETER' } },
+    });
+    expect(await api.handle('GET', '/refs/garbage')).toMatchObject({
+      status: 400,
+      body: { error: { code: 'INVALID_SYMBOL_ID' } },
+    });
+    expect(await api.handle('GET', '//[')).toMatchObject({ status: 400, body: { error: { code: 'INVALID_URL' } } });
+  });
+
+  test('should report the database as unavailable on /health', async () => {
+    const response = await new HttpApi(unreachable).handle('GET', '/health');
+    expect(response.status).toBe(503);
+    expect(response.body).toMatchObject({ error: { code: 'DATABASE_UNAVAILABLE' } });
+  });
+
+  test('should refuse indexes other than the one served', async () => {
+    const api = new HttpApi(unreachable, 'api');
+    const id = encodeSymbolId({ repo_id: 'web', file_path: 'main.go', line_number: 1, symbol_name: 'main' });
+    expect(await api.handle('GET', `/refs/${id}`)).toMatchObject({
+      status: 404,
+      body: { error: { code: 'UNKNOWN_INDEX' } },
+    });
+    expect(await api.handle('GET', '/files/main.go/outline?repo_id=web')).toMatchObject({ status: 404 });
+  });
+
+  test('should serve metrics as Prometheus text and time requests by endpoint', async () => {
+    resetMetrics();
+    const api = new HttpApi(unreachable);
+    await api.handle('GET', '/health');
+    await api.handle('GET', '/refs/garbage');
+
+    const response = await api.handle('GET', '/metrics');
+    expect(response.status).toBe(200);
+    expect(response.contentType).toMatch(/^text\/plain; version=0\.0\.4/);
+    expect(String(response.body)).toContain('cindex_query_duration_seconds_count{api="http",endpoint="/refs/{id}"} 1');
+    expect(queryDuration.count({ api: 'http', endpoint: '/health' })).toBe(1);
+    expect(endpointOf('/files/a/b.go/outline')).toBe('/files/{path}/outline');
+    expect(endpointOf('/related/abc')).toBe('/related/{id}');
+    expect(endpointOf('/nope')).toBe('other');
+  });
+});
+
+describe('serveMetrics', () => {
+  test('should answer malformed request targets with 400 and keep serving', async () => {
+    const controller = new AbortController();
+    let port = 0;
+    const listening = new Promise<void>((resolve) => {
+      void serveMetrics({ host: '127.0.0.1', port: 0 }, controller.signal, (bound) => {
+        port = bound.port;
+        resolve();
+      });
+    });
+    await listening;
+
+    // Sent raw: HTTP clients refuse to send such a target
+    const statusOf = (target: string): Promise<string> =>
+      new Promise((resolve, reject) => {
+        const socket = net.connect(port, '127.0.0.1', () => {
+          socket.write(`GET ${target} HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n`);
+        });
+        let reply = '';
+        socket.on('data', (chunk: Buffer) => (reply += chunk.toString()));
+        socket.on('end', () => {
+          resolve(reply.split('\r\n')[0]);
+        });
+        socket.on('error', reject);
+      });
+
+    try {
+      expect(await statusOf('//[')).toBe('HTTP/1.1 400 Bad Request');
+      expect(await statusOf('/metrics')).toBe('HTTP/1.1 200 OK');
+    } finally {
+      controller.abort();
+    }
+  });
+});
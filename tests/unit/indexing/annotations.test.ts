@@ -0,0 +1,113 @@
+/**
+ * Unit tests for annotations read from comments
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { extractAnnotations, type AnnotatedSymbol } from '../../../src/indexing/annotations';
+
+/**
+ * Symbol spanning lines of a test file
+ */
+const symbol = (symbol_name: string, symbol_type: string, line_number: number, end_line: number): AnnotatedSymbol =>
+  ({ symbol_name, symbol_type, line_number, end_line }) as AnnotatedSymbol;
+
+describe('extractAnnotations', () => {
+  test('should read todo markers with and without an assignee', () => {
+    const content = [
+      '// TODO(ada): drop the v1 fallback',
+      'func Login() {',
+      '\tretry() // FIXME: never backs off',
+      '\t// a todo list is not a TODO',
+      '}',
+    ].join('\n');
+    const symbols = [symbol('Login', 'function', 2, 5)];
+
+    const annotations = extractAnnotations(content, 'go', symbols);
+
+    expect(annotations).toEqual([
+      {
+        kind: 'todo',
+        tag: 'TODO',
+        assignee: 'ada',
+        text: 'drop the v1 fallback',
+        line: 1,
+        column: 1,
+        byte_column: 1,
+        symbol_name: 'Login',
+        symbol_type: 'function',
+      },
+      {
+        kind: 'todo',
+        tag: 'FIXME',
+        assignee: null,
+        text: 'never backs off',
+        line: 3,
+        column: 10,
+        byte_column: 10,
+        symbol_name: 'Login',
+        symbol_type: 'function',
+      },
+    ]);
+  });
+
+  test('should join a Deprecated paragraph and attach it to the documented symbol', () => {
+    const content = [
+      '// Login signs a user in.',
+      '//',
+      '// Deprecated: Use SignIn instead,',
+      '// which returns a session.',
+      '//',
+      '// More prose.',
+      'func Login() {}',
+    ].join('\n');
+
+    const [annotation] = extractAnnotations(content, 'go', [symbol('Login', 'function', 7, 7)]);
+
+    expect(annotation).toMatchObject({
+      kind: 'deprecated',
+      tag: 'Deprecated',
+      text: 'Use SignIn instead, which returns a session.',
+      line: 3,
+      symbol_name: 'Login',
+    });
+  });
+
+  test('should read JSDoc tags past decorators', () => {
+    const content = ['/**', ' * @deprecated since 2.0', ' */', '@Injectable()', 'class Auth {}'].join('\n');
+
+    const annotations = extractAnnotations(content, 'typescript', [symbol('Auth', 'class', 5, 5)]);
+
+    expect(annotations).toMatchObject([
+      { kind: 'deprecated', tag: '@deprecated', text: 'since 2.0', symbol_name: 'Auth' },
+    ]);
+  });
+
+  test('should keep Go directives and build constraints at file level', () => {
+    const content = [
+      '//go:build linux && !race',
+      '// +build linux,!race',
+      '',
+      'package auth',
+      '',
+      '//go:generate stringer -type=Role',
+      '//go:noinline',
+      'func hash() {}',
+      '// go:generate is not a directive',
+    ].join('\n');
+
+    const annotations = extractAnnotations(content, 'go', [symbol('hash', 'function', 8, 8)]);
+
+    expect(annotations.map(({ kind, tag, text, symbol_name }) => [kind, tag, text, symbol_name])).toEqual([
+      ['build', 'go:build', 'linux && !race', null],
+      ['build', '+build', 'linux,!race', null],
+      ['directive', 'go:generate', 'stringer -type=Role', null],
+      ['directive', 'go:noinline', '', 'hash'],
+    ]);
+  });
+
+  test('should count columns past multi-byte characters', () => {
+    const annotations = extractAnnotations("s = 'é' # TODO: ascii", 'python', []);
+
+    expect(annotations).toMatchObject([{ tag: 'TODO', column: 9, byte_column: 10, symbol_name: null }]);
+  });
+});
@@ -0,0 +1,147 @@
+/**
+ * Unit tests for audit rules
+ */
+
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  DEFAULT_AUDIT_RULES,
+  parseAuditConfig,
+  resolveAuditRules,
+  runAudit,
+  type AuditSource,
+} from '../../../src/indexing/audit-rules';
+import { extractGoCalls, parseGoImports } from '../../../src/indexing/go-calls';
+
+const fixture = (name: string): string => fs.readFileSync(path.join(__dirname, '../../fixtures', name), 'utf-8');
+
+const SAMPLE_GO = fixture('sample.go');
+const SAMPLE_TS = fixture('sample.ts');
+
+/**
+ * Line of the first fixture line starting with a header
+ */
+const lineOf = (content: string, header: string): number =>
+  content.split('\n').findIndex((line) => line.trimStart().startsWith(header)) + 1;
+
+/**
+ * Audit source of the Go and TypeScript fixtures, with spans and calls of their session and password functions
+ */
+const fixtureSource = (): AuditSource => {
+  const goLines = SAMPLE_GO.split('\n');
+  const generateStart = lineOf(SAMPLE_GO, 'func generateSessionID');
+  const generateCode = goLines.slice(generateStart - 1, generateStart + 2).join('\n');
+  const calls = extractGoCalls(generateCode, generateStart, parseGoImports(SAMPLE_GO)).map((call) => ({
+    repo_id: 'shop',
+    file_path: 'auth/sample.go',
+    caller_name: call.caller,
+    caller_line: call.caller_line,
+    callee_name: call.callee,
+    callee_package: call.callee_package,
+    callee_qualifier: call.qualifier,
+    call_kind: call.kind,
+    line_number: call.line,
+    column_number: call.column,
+  }));
+
+  const span = (file_path: string, symbol_name: string, line_number: number, length: number) => ({
+    id: line_number,
+    symbol_name,
+    file_path,
+    line_number,
+    end_line: line_number + length,
+  });
+  return {
+    files: [
+      { repo_id: 'shop', file_path: 'auth/sample.go', language: 'go', content: SAMPLE_GO },
+      { repo_id: 'shop', file_path: 'web/sample.ts', language: 'typescript', content: SAMPLE_TS },
+    ],
+    calls,
+    spans: [
+      span('auth/sample.go', 'AuthService.verifyPassword', lineOf(SAMPLE_GO, 'func (s *AuthService) verify'), 3),
+      span('auth/sample.go', 'generateSessionID', generateStart, 2),
+      span('web/sample.ts', 'verifyPassword', lineOf(SAMPLE_TS, 'private async verifyPassword'), 3),
+      span('web/sample.ts', 'generateSessionId', lineOf(SAMPLE_TS, 'private generateSessionId'), 2),
+    ],
+  };
+};
+
+describe('runAudit', () => {
+  test('should flag the plaintext password comparison of verifyPassword in each fixture', () => {
+    const findings = runAudit(fixtureSource(), DEFAULT_AUDIT_RULES).filter(
+      (finding) => finding.rule === 'plaintext_password_compare'
+    );
+
+    expect(findings.map((finding) => [finding.file_path, finding.symbol_name, finding.text])).toEqual([
+      ['auth/sample.go', 'AuthService.verifyPassword', 'return password == hash'],
+      ['web/sample.ts', 'verifyPassword', 'return password === hash; // Simplified for testing'],
+    ]);
+    expect(findings[0]).toMatchObject({ severity: 'high', column_number: 9, byte_column: 9 });
+  });
+
+  test('should flag session IDs from the clock (Go calls) and from Math.random', () => {
+    const findings = runAudit(fixtureSource(), DEFAULT_AUDIT_RULES).filter(
+      (finding) => finding.rule !== 'plaintext_password_compare'
+    );
+
+    expect(findings.map((finding) => [finding.rule, finding.symbol_name, finding.line_number])).toEqual([
+      ['predictable_session_id', 'generateSessionID', lineOf(SAMPLE_GO, 'func generateSessionID') + 1],
+      ['weak_random_session', 'generateSessionId', lineOf(SAMPLE_TS, 'private generateSessionId') + 1],
+    ]);
+  });
+
+  test('should flag SQL built by concatenation but not parameterized queries', () => {
+    const content = [
+      'func find(db *sql.DB, email string) {',
+      '\tdb.Query("SELECT id FROM users WHERE email = \'" + email + "\'")',
+      '\tdb.Query("SELECT id FROM users WHERE email = ?", email)',
+      '\tdb.Query(fmt.Sprintf("DELETE FROM users WHERE id = %s", id))',
+      '\t// db.Query("SELECT id FROM users WHERE email = \'" + email + "\'")',
+      '}',
+    ].join('\n');
+    const source: AuditSource = {
+      files: [{ repo_id: null, file_path: 'find.go', language: 'go', content }],
+      calls: [],
+      spans: [],
+    };
+
+    const findings = runAudit(source, DEFAULT_AUDIT_RULES);
+
+    expect(findings.map((finding) => [finding.rule, finding.line_number])).toEqual([
+      ['sql_string_concat', 2],
+      ['sql_string_concat', 4],
+    ]);
+  });
+});
+
+describe('parseAuditConfig', () => {
+  test('should read rules and apply them over the built-in ones, nearest config last', () => {
+    const project = parseAuditConfig({
+      disable: ['predictable_session_id'],
+      rules: [{ id: 'shell_exec', severity: 'critical', calls: 'os/exec.Command', function: 'handler' }],
+    });
+    const local = parseAuditConfig({
+      rules: [{ id: 'sql_string_concat', severity: 'low', message: 'Concatenated SQL', pattern: '\\+ *"WHERE' }],
+    });
+
+    const rules = resolveAuditRules([local, project]);
+
+    expect(rules.map((rule) => [rule.id, rule.severity])).toEqual([
+      ['sql_string_concat', 'low'],
+      ['plaintext_password_compare', 'high'],
+      ['weak_random_session', 'medium'],
+      ['shell_exec', 'critical'],
+    ]);
+    expect(rules.at(-1)).toMatchObject({ calls: ['os/exec.Command'], message: 'shell_exec' });
+  });
+
+  test('should reject rules it cannot match', () => {
+    expect(() => parseAuditConfig({ rules: [{ id: 'empty' }] })).toThrow('empty: needs calls, a pattern, or both');
+    expect(() => parseAuditConfig({ rules: [{ id: 'bad', pattern: '(' }] })).toThrow('bad: pattern is not a valid');
+    expect(() => parseAuditConfig({ rules: [{ id: 'loud', severity: 'urgent', pattern: 'x' }] })).toThrow(
+      'severity must be one of low, medium, high, critical'
+    );
+  });
+});
@@ -0,0 +1,132 @@
+/**
+ * Unit tests for Go build constraints and platform matrices
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  DEFAULT_PLATFORMS,
+  fileNameConstraint,
+  goBuildConstraint,
+  goBuildExpression,
+  matchesConstraint,
+  parseConstraint,
+  parsePlatformList,
+  platformMatrix,
+} from '../../../src/indexing/build-constraints';
+import { type Platform } from '../../../src/types/indexing';
+
+const LINUX: Platform = { os: 'linux', arch: 'amd64' };
+const ANDROID: Platform = { os: 'android', arch: 'arm64' };
+const WINDOWS: Platform = { os: 'windows', arch: 'amd64' };
+const WASM: Platform = { os: 'js', arch: 'wasm' };
+
+/**
+ * Evaluate a constraint expression (throws on malformed ones)
+ */
+const matches = (expression: string, platform: Platform, tags: string[] = []): boolean => {
+  const node = parseConstraint(expression);
+  if (!node) throw new Error(`malformed: ${expression}`);
+  return matchesConstraint(node, platform, new Set(tags));
+};
+
+describe('fileNameConstraint', () => {
+  test('should read GOOS, GOARCH, and GOOS_GOARCH suffixes', () => {
+    expect(fileNameConstraint('internal/fsx/file_linux.go')).toBe('linux');
+    expect(fileNameConstraint('asm_amd64.go')).toBe('amd64');
+    expect(fileNameConstraint('zsyscall_windows_arm64.go')).toBe('windows && arm64');
+    expect(fileNameConstraint('file_darwin_test.go')).toBe('darwin');
+  });
+
+  test('should ignore names that carry no constraint', () => {
+    expect(fileNameConstraint('linux.go')).toBeNull();
+    expect(fileNameConstraint('file_unix.go')).toBeNull();
+    expect(fileNameConstraint('file_test.go')).toBeNull();
+    expect(fileNameConstraint('poll_linux_extra.go')).toBeNull();
+  });
+});
+
+describe('goBuildExpression', () => {
+  test('should read the //go:build line before the package clause', () => {
+    const content = ['// Copyright 2024 The Shop Authors.', '', '//go:build linux || darwin', '', 'package fsx'].join(
+      '\n'
+    );
+
+    expect(goBuildExpression(content)).toBe('linux || darwin');
+  });
+
+  test('should ignore build lines after the package clause', () => {
+    expect(goBuildExpression('package fsx\n\n//go:build linux\n')).toBeNull();
+  });
+
+  test('should convert // +build lines', () => {
+    const content = ['/* generated */', '// +build linux,amd64 darwin', '// +build !cgo', '', 'package fsx'].join('\n');
+
+    expect(goBuildExpression(content)).toBe('((linux && amd64) || darwin) && !cgo');
+  });
+});
+
+describe('goBuildConstraint', () => {
+  test('should combine the file name and build line', () => {
+    expect(goBuildConstraint('poll_linux.go', '//go:build amd64 || arm64\n\npackage poll')).toBe(
+      'linux && (amd64 || arm64)'
+    );
+    expect(goBuildConstraint('poll.go', '//go:build unix\n\npackage poll')).toBe('unix');
+    expect(goBuildConstraint('poll.go', 'package poll')).toBeNull();
+  });
+});
+
+describe('matchesConstraint', () => {
+  test('should match GOOS and GOARCH tags with operators', () => {
+    expect(matches('linux && (amd64 || arm64)', LINUX)).toBe(true);
+    expect(matches('linux && !amd64', LINUX)).toBe(false);
+    expect(matches('windows || wasm', WASM)).toBe(true);
+  });
+
+  test('should match the tags implied by a platform', () => {
+    expect(matches('unix', LINUX)).toBe(true);
+    expect(matches('unix', WINDOWS)).toBe(false);
+    expect(matches('linux', ANDROID)).toBe(true);
+    expect(matches('gc && go1.21', WINDOWS)).toBe(true);
+  });
+
+  test('should match cgo and custom tags only when set', () => {
+    expect(matches('cgo', LINUX)).toBe(false);
+    expect(matches('cgo', LINUX, ['cgo'])).toBe(true);
+    expect(matches('!purego', LINUX, ['purego'])).toBe(false);
+  });
+
+  test('should reject malformed expressions', () => {
+    expect(parseConstraint('linux &&')).toBeNull();
+    expect(parseConstraint('(linux || darwin')).toBeNull();
+    expect(parseConstraint('linux darwin')).toBeNull();
+  });
+});
+
+describe('parsePlatformList', () => {
+  test('should split platforms and report unknown pairs', () => {
+    expect(parsePlatformList('linux/amd64, windows/arm64,')).toEqual({
+      platforms: [
+        { os: 'linux', arch: 'amd64' },
+        { os: 'windows', arch: 'arm64' },
+      ],
+      invalid: [],
+    });
+    expect(parsePlatformList('linux,plan9/amd64,beos/x86').invalid).toEqual(['linux', 'beos/x86']);
+  });
+});
+
+describe('platformMatrix', () => {
+  test('should list the variants each platform builds', () => {
+    const constraints = ['unix', 'windows', 'js && wasm', null];
+    const matrix = platformMatrix(constraints, [LINUX, WINDOWS, WASM, { os: 'plan9', arch: 'amd64' }], new Set());
+
+    expect(matrix).toEqual([[0, 3], [1, 3], [2, 3], [3]]);
+  });
+
+  test('should leave platforms without a variant empty', () => {
+    const matrix = platformMatrix(['linux', 'darwin', 'windows && ('], DEFAULT_PLATFORMS, new Set());
+    const missing = DEFAULT_PLATFORMS.filter((_, index) => matrix[index].length === 0).map((p) => p.os);
+
+    expect(missing).toEqual(['windows', 'windows', 'windows', 'freebsd', 'js', 'wasip1']);
+  });
+});
@@ -0,0 +1,135 @@
+/**
+ * Unit tests for clone fingerprints and grouping
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  CLONE_MIN_TOKENS,
+  cloneFingerprint,
+  findCloneGroups,
+  normalizeTokens,
+  signatureSimilarity,
+} from '../../../src/indexing/clones';
+import { type CloneCandidateRecord } from '../../../src/types/database';
+
+const TOTAL = `func orderTotal(items []Item, discount float64) (float64, error) {
+	// Sum the line items
+	total := 0.0
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return 0, fmt.Errorf("item %s: quantity %d", item.SKU, item.Quantity)
+		}
+		total += item.Price * float64(item.Quantity)
+	}
+	if discount > 0 {
+		total -= total * discount
+	}
+	return total, nil
+}`;
+
+// Same body, other names and constants
+const RENAMED = `func invoiceSum(lines []Line, rebate float64) (float64, error) {
+	sum := 1.0
+	for _, line := range lines {
+		if line.Count <= 1 {
+			return 1, fmt.Errorf("line %s: count %d", line.Code, line.Count)
+		}
+		sum += line.Cost * float64(line.Count)
+	}
+	if rebate > 0 {
+		sum -= sum * rebate
+	}
+	return sum, nil
+}`;
+
+// One statement added
+const EXTENDED = TOTAL.replace('\treturn total, nil', '\tlog.Printf("total %f", total)\n\treturn total, nil');
+
+const UNRELATED = `func parseHeader(raw string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.Split(raw, "\\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		headers[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}`;
+
+const candidate = (filePath: string, name: string, code: string): CloneCandidateRecord => {
+  const fingerprint = cloneFingerprint(code, 'go');
+  if (!fingerprint) throw new Error(`${name} is too short to fingerprint`);
+  return {
+    repo_id: 'shop',
+    file_path: filePath,
+    symbol_name: name,
+    symbol_type: 'function',
+    line_number: 1,
+    end_line: code.split('\n').length,
+    clone_tokens: fingerprint.tokens,
+    clone_signature: fingerprint.signature,
+  };
+};
+
+describe('normalizeTokens', () => {
+  test('should replace identifiers and literals, keep keywords, and drop comments', () => {
+    expect(normalizeTokens('if n := len(xs); n > 10 { return "big" } // done', 'go').join(' ')).toBe(
+      'if $id := $id ( $id ) ; $id > $lit { return $lit }'
+    );
+    expect(normalizeTokens('x = 1  # one\ny = "#"', 'python')).toEqual(['$id', '=', '$lit', '$id', '=', '$lit']);
+  });
+});
+
+describe('cloneFingerprint', () => {
+  test('should give renamed copies the same signature', () => {
+    const original = cloneFingerprint(TOTAL, 'go');
+    const renamed = cloneFingerprint(RENAMED, 'go');
+
+    expect(original?.signature).toHaveLength(64);
+    expect(renamed?.signature).toEqual(original?.signature);
+    expect(renamed?.tokens).toBe(original?.tokens);
+  });
+
+  test('should rate a changed copy near and other code far', () => {
+    const original = cloneFingerprint(TOTAL, 'go')?.signature ?? [];
+    const extended = cloneFingerprint(EXTENDED, 'go')?.signature ?? [];
+    const unrelated = cloneFingerprint(UNRELATED, 'go')?.signature ?? [];
+
+    expect(signatureSimilarity(original, extended)).toBeGreaterThan(0.7);
+    expect(signatureSimilarity(original, extended)).toBeLessThan(1);
+    expect(signatureSimilarity(original, unrelated)).toBeLessThan(0.3);
+  });
+
+  test('should leave out short functions', () => {
+    expect(cloneFingerprint('func (u User) Name() string { return u.name }', 'go')).toBeNull();
+    expect(normalizeTokens(TOTAL, 'go').length).toBeGreaterThan(CLONE_MIN_TOKENS);
+  });
+});
+
+describe('findCloneGroups', () => {
+  const functions = [
+    candidate('billing/invoice.go', 'invoiceSum', RENAMED),
+    candidate('orders/total.go', 'orderTotal', TOTAL),
+    candidate('orders/total_log.go', 'orderTotalLogged', EXTENDED),
+    candidate('web/headers.go', 'parseHeader', UNRELATED),
+  ];
+
+  test('should group copies at the threshold, ordered by location', () => {
+    const groups = findCloneGroups(functions, 0.95);
+
+    expect(groups).toHaveLength(1);
+    expect(groups[0].similarity).toBe(1);
+    expect(groups[0].members.map((member) => member.symbol_name)).toEqual(['invoiceSum', 'orderTotal']);
+  });
+
+  test('should add near-duplicates under a lower threshold', () => {
+    const groups = findCloneGroups(functions, 0.7);
+
+    expect(groups.map((group) => group.members.map((member) => member.file_path))).toEqual([
+      ['billing/invoice.go', 'orders/total.go', 'orders/total_log.go'],
+    ]);
+    expect(groups[0].similarity).toBeLessThan(1);
+    expect(groups[0].similarity).toBeGreaterThanOrEqual(0.7);
+  });
+});
@@ -0,0 +1,137 @@
+/**
+ * Unit tests for cyclomatic and cognitive complexity
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { cognitiveComplexity, cyclomaticComplexity, type ComplexityNode } from '../../../src/indexing/complexity';
+
+let offset = 0;
+
+/**
+ * Build a named node; fields name some of its children
+ */
+const node = (type: string, children: ComplexityNode[] = [], fields: Record<string, ComplexityNode> = {}) => {
+  const built: ComplexityNode = {
+    type,
+    isNamed: true,
+    startIndex: offset++,
+    children,
+    childForFieldName: (fieldName) => fields[fieldName] ?? null,
+  };
+  return built;
+};
+
+/**
+ * Build a keyword or punctuation token
+ */
+const token = (type: string): ComplexityNode => ({ ...node(type), isNamed: false });
+
+const block = (...statements: ComplexityNode[]) => node('statement_block', statements);
+const call = () => node('expression_statement', [node('call_expression')]);
+const fn = (...statements: ComplexityNode[]) =>
+  node('function_declaration', [node('identifier'), block(...statements)]);
+
+/**
+ * if (condition) body [else alternative], shaped like the TypeScript grammar
+ */
+const ifStatement = (body: ComplexityNode, alternative?: ComplexityNode): ComplexityNode => {
+  const children = [token('if'), node('parenthesized_expression'), body];
+  if (!alternative) return node('if_statement', children);
+  const elseClause = node('else_clause', [token('else'), alternative]);
+  return node('if_statement', [...children, elseClause], { alternative: elseClause });
+};
+
+const logical = (left: ComplexityNode, operator: string, right: ComplexityNode) => {
+  const op = token(operator);
+  return node('binary_expression', [left, op, right], { operator: op });
+};
+
+describe('cyclomaticComplexity', () => {
+  test('straight-line code has one path', () => {
+    expect(cyclomaticComplexity(fn(call(), call()))).toBe(1);
+  });
+
+  test('counts branches, loops, cases, and boolean operators', () => {
+    const condition = logical(node('identifier'), '&&', node('identifier'));
+    const cases = [node('switch_case'), node('switch_case'), node('switch_default')];
+    const body = fn(
+      ifStatement(block(call()), block(call())),
+      node('for_statement', [token('for'), block(node('expression_statement', [condition]))]),
+      node('switch_statement', [node('switch_body', cases)])
+    );
+
+    // if, for, &&, two cases (the else and default add no path)
+    expect(cyclomaticComplexity(body)).toBe(6);
+  });
+
+  test('ignores tokens named like statements', () => {
+    const ruby = node('method', [node('if', [token('if'), node('identifier'), node('then')])]);
+    expect(cyclomaticComplexity(ruby)).toBe(2);
+  });
+
+  test('leaves the paths of nested functions to them', () => {
+    const callback = node('func_literal', [node('parameter_list'), block(ifStatement(block(call())))]);
+    expect(cyclomaticComplexity(fn(ifStatement(block(node('expression_statement', [callback])))))).toBe(2);
+    expect(cyclomaticComplexity(callback)).toBe(2);
+  });
+});
+
+describe('cognitiveComplexity', () => {
+  test('straight-line code scores zero', () => {
+    expect(cognitiveComplexity(fn(call()))).toBe(0);
+  });
+
+  test('weights structures by nesting', () => {
+    const inner = ifStatement(block(call()));
+    const loop = node('for_statement', [token('for'), block(inner)]);
+    // if +1, for +2 (nested once), if +3 (nested twice)
+    expect(cognitiveComplexity(fn(ifStatement(block(loop))))).toBe(6);
+  });
+
+  test('scores an else-if chain flat', () => {
+    const chain = ifStatement(block(call()), ifStatement(block(call()), ifStatement(block(call()), block(call()))));
+    // if, else if, else if, else
+    expect(cognitiveComplexity(fn(chain))).toBe(4);
+  });
+
+  test('reads alternatives outside else clauses (Go)', () => {
+    const elseBlock = node('block');
+    const elseIf = node('if_statement', [token('if'), node('identifier'), node('block'), token('else'), elseBlock], {
+      alternative: elseBlock,
+    });
+    const first = node('if_statement', [token('if'), node('identifier'), node('block'), token('else'), elseIf], {
+      alternative: elseIf,
+    });
+    expect(cognitiveComplexity(fn(first))).toBe(3);
+  });
+
+  test('reads elif clauses (Python)', () => {
+    const elseClause = node('else_clause', [token(':'), node('block')]);
+    const branches = [node('block'), node('elif_clause', [node('block')]), elseClause];
+    expect(cognitiveComplexity(fn(node('if_statement', [token('if'), ...branches])))).toBe(3);
+  });
+
+  test('counts a switch once', () => {
+    const cases = [node('switch_case'), node('switch_case'), node('switch_case'), node('switch_default')];
+    expect(cognitiveComplexity(fn(node('switch_statement', [node('switch_body', cases)])))).toBe(1);
+  });
+
+  test('counts each run of one boolean operator once', () => {
+    const same = logical(logical(node('identifier'), '&&', node('identifier')), '&&', node('identifier'));
+    expect(cognitiveComplexity(fn(node('expression_statement', [same])))).toBe(1);
+
+    const mixed = logical(logical(node('identifier'), '&&', node('identifier')), '||', node('identifier'));
+    expect(cognitiveComplexity(fn(node('expression_statement', [mixed])))).toBe(2);
+  });
+
+  test('nests the bodies of nested functions', () => {
+    const callback = node('arrow_function', [node('formal_parameters'), block(ifStatement(block(call())))]);
+    expect(cognitiveComplexity(fn(node('expression_statement', [callback])))).toBe(2);
+  });
+
+  test('counts labeled jumps but not plain ones', () => {
+    const labeled = node('continue_statement', [token('continue'), node('statement_identifier')]);
+    const jumps = block(node('break_statement', [token('break')]), labeled);
+    expect(cognitiveComplexity(fn(node('while_statement', [token('while'), jumps])))).toBe(2);
+  });
+});
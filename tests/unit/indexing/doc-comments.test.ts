@@ -0,0 +1,66 @@
+/**
+ * Unit tests for doc comment cleanup and identifier words
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { cleanDocComment, docSummary } from '../../../src/indexing/doc-comments';
+import { identifierWords } from '../../../src/utils/unicode';
+
+describe('cleanDocComment', () => {
+  test('strips Go line comments', () => {
+    const raw = '// Login authenticates a user with credentials.\n//\n// It returns ErrLocked after five failures.';
+    expect(cleanDocComment(raw)).toBe(
+      'Login authenticates a user with credentials.\n\nIt returns ErrLocked after five failures.'
+    );
+  });
+
+  test('strips JSDoc blocks', () => {
+    const raw = '/**\n   * Create a session\n   *\n   * @param user - Signed-in user\n   */';
+    expect(cleanDocComment(raw)).toBe('Create a session\n\n@param user - Signed-in user');
+    expect(cleanDocComment('/** Session lifetime in minutes */')).toBe('Session lifetime in minutes');
+  });
+
+  test('strips Rust doc lines and Python comments', () => {
+    expect(cleanDocComment('/// Parse a token.\n/// Fails on expiry.')).toBe('Parse a token.\nFails on expiry.');
+    expect(cleanDocComment('# Retry policy for uploads')).toBe('Retry policy for uploads');
+  });
+
+  test('strips Python docstring quotes and prefixes', () => {
+    expect(cleanDocComment('"""Return the user.\n\n    Raises KeyError.\n    """')).toBe(
+      'Return the user.\n\nRaises KeyError.'
+    );
+    expect(cleanDocComment("r'''Match a path.'''")).toBe('Match a path.');
+  });
+
+  test('returns an empty string for bare markers', () => {
+    expect(cleanDocComment('//\n//')).toBe('');
+    expect(cleanDocComment('/** */')).toBe('');
+  });
+});
+
+describe('docSummary', () => {
+  test('keeps the first sentence', () => {
+    expect(docSummary('SessionTimeout is how long a session lasts. After it, sessions expire.')).toBe(
+      'SessionTimeout is how long a session lasts.'
+    );
+  });
+
+  test('joins the lines of the first paragraph', () => {
+    expect(docSummary('Create a session\nfor the user\n\n@param user - Signed-in user')).toBe(
+      'Create a session for the user'
+    );
+  });
+});
+
+describe('identifierWords', () => {
+  test('splits camelCase, initialisms, and snake_case', () => {
+    expect(identifierWords('SessionTimeout')).toEqual(['Session', 'Timeout']);
+    expect(identifierWords('parseHTTPRequest')).toEqual(['parse', 'HTTP', 'Request']);
+    expect(identifierWords('max_retries')).toEqual(['max', 'retries']);
+    expect(identifierWords('AuthService.Login')).toEqual(['Auth', 'Service', 'Login']);
+  });
+
+  test('keeps accented words whole', () => {
+    expect(identifierWords('créerSession')).toEqual(['créer', 'Session']);
+  });
+});
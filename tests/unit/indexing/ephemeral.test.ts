@@ -0,0 +1,85 @@
+/**
+ * Unit tests for ephemeral document names, paths, and expiry
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  documentPath,
+  EPHEMERAL_TTL_MS,
+  isExpiredEphemeral,
+  isValidEphemeralName,
+  languageExtension,
+} from '../../../src/indexing/ephemeral';
+
+describe('languageExtension', () => {
+  test('returns the first extension of a language', () => {
+    expect(languageExtension('go')).toBe('.go');
+    expect(languageExtension('TypeScript')).toBe('.ts');
+    expect(languageExtension('python')).toBe('.py');
+  });
+
+  test('returns null for unsupported languages', () => {
+    expect(languageExtension('cobol')).toBeNull();
+    expect(languageExtension('unknown')).toBeNull();
+  });
+});
+
+describe('isValidEphemeralName', () => {
+  test('accepts names usable as index IDs and directories', () => {
+    expect(isValidEphemeralName('scratch')).toBe(true);
+    expect(isValidEphemeralName('gist-42_v1.2')).toBe(true);
+  });
+
+  test('rejects paths and hidden names', () => {
+    expect(isValidEphemeralName('a/b')).toBe(false);
+    expect(isValidEphemeralName('.hidden')).toBe(false);
+    expect(isValidEphemeralName('a..b')).toBe(false);
+    expect(isValidEphemeralName('')).toBe(false);
+  });
+});
+
+describe('documentPath', () => {
+  test('names the document after the index with the language extension', () => {
+    expect(documentPath({ name: 'scratch', language: 'go' })).toBe('scratch.go');
+  });
+
+  test('keeps a path with a supported extension', () => {
+    expect(documentPath({ name: 'gist', path: 'cmd/handler.py' })).toBe('cmd/handler.py');
+    expect(documentPath({ name: 'gist', path: 'handler.py', language: 'go' })).toBe('handler.py');
+  });
+
+  test('adds the language extension to other paths', () => {
+    expect(documentPath({ name: 'gist', path: 'Dockerfile.tmpl', language: 'go' })).toBe('Dockerfile.tmpl.go');
+  });
+
+  test('normalizes separators and rejects paths leaving the index', () => {
+    expect(documentPath({ name: 'x', path: 'a\\b\\c.ts' })).toBe('a/b/c.ts');
+    expect(() => documentPath({ name: 'x', path: '../escape.go' })).toThrow('Invalid document path');
+    expect(() => documentPath({ name: 'x', path: '/etc/passwd.go' })).toThrow('Invalid document path');
+  });
+
+  test('requires a known language when the path has no extension', () => {
+    expect(() => documentPath({ name: 'scratch' })).toThrow('Cannot tell the language');
+    expect(() => documentPath({ name: 'scratch', language: 'cobol' })).toThrow('Unsupported language: cobol');
+  });
+});
+
+describe('isExpiredEphemeral', () => {
+  const now = new Date('2026-01-02T00:00:00Z');
+
+  test('expires ephemeral indexes past their expiry', () => {
+    expect(isExpiredEphemeral({ ephemeral: true, expires_at: '2026-01-01T23:59:59Z' }, now)).toBe(true);
+    expect(isExpiredEphemeral({ ephemeral: true, expires_at: '2026-01-02T00:00:01Z' }, now)).toBe(false);
+  });
+
+  test('never expires repository indexes or unreadable expiry times', () => {
+    expect(isExpiredEphemeral({ expires_at: '2020-01-01T00:00:00Z' }, now)).toBe(false);
+    expect(isExpiredEphemeral({ ephemeral: true, expires_at: 'soon' }, now)).toBe(false);
+    expect(isExpiredEphemeral(null, now)).toBe(false);
+  });
+
+  test('keeps a fresh index for a day', () => {
+    const expiresAt = new Date(now.getTime() + EPHEMERAL_TTL_MS).toISOString();
+    expect(isExpiredEphemeral({ ephemeral: true, expires_at: expiresAt }, new Date(now.getTime() + 1000))).toBe(false);
+  });
+});
@@ -0,0 +1,66 @@
+/**
+ * Unit tests for language extractors: the built-in tree-sitter one and plugins adapted to the interface
+ */
+
+import { afterEach, describe, test, expect } from '@jest/globals';
+import { selectExtractor, treeSitterExtractor } from '../../../src/indexing/extractors';
+import { closePlugins, registerExtractor } from '../../../src/indexing/plugins';
+import { Language, NodeType, type ParseResult } from '../../../src/types/indexing';
+
+/**
+ * Parse result naming the language and file it was parsed as
+ */
+const parsedAs = (filePath: string, language: Language): ParseResult => ({
+  success: true,
+  nodes: [{ node_type: NodeType.Function, name: `${language}:${filePath}`, start_line: 1, end_line: 1, code_text: '' }],
+  imports: [],
+  exports: [],
+  used_fallback: false,
+});
+
+const builtins = [treeSitterExtractor((_content, filePath, language) => Promise.resolve(parsedAs(filePath, language)))];
+
+afterEach(async () => {
+  await closePlugins();
+});
+
+describe('selectExtractor', () => {
+  test('should read Python, TypeScript, JavaScript, and Java with tree-sitter', async () => {
+    const files: [string, Language][] = [
+      ['app/main.py', Language.Python],
+      ['src/index.ts', Language.TypeScript],
+      ['src/legacy.js', Language.JavaScript],
+      ['src/Main.java', Language.Java],
+    ];
+
+    for (const [filePath, language] of files) {
+      const extractor = selectExtractor(filePath, language, builtins);
+      const result = await extractor.extract('', filePath, language);
+
+      expect(extractor.name).toBe('tree-sitter');
+      expect(result.nodes[0].name).toBe(`${language}:${filePath}`);
+    }
+  });
+
+  test('should hand the extensions a plugin claims to the plugin', async () => {
+    registerExtractor({
+      name: 'abap',
+      language: 'abap',
+      extensions: ['.abap'],
+      extract: () => ({ symbols: [{ name: 'main', kind: 'function', start_line: 1, end_line: 2 }] }),
+    });
+
+    const extractor = selectExtractor('src/zreport.abap', 'abap', builtins);
+    const result = await extractor.extract('FORM main.\nENDFORM.', 'src/zreport.abap', 'abap');
+
+    expect(extractor).toMatchObject({ name: 'abap', languages: ['abap'] });
+    expect(result.nodes.map(({ name, code_text }) => ({ name, code_text }))).toEqual([
+      { name: 'main', code_text: 'FORM main.\nENDFORM.' },
+    ]);
+    expect(selectExtractor('src/index.ts', Language.TypeScript, builtins).name).toBe('tree-sitter');
+  });
+
+  test('should fail for a language no extractor reads', () => {
+    expect(() => selectExtractor('notes.txt', 'plain', builtins)).toThrow('No extractor reads plain files');
+  });
+});
@@ -2,10 +2,21 @@
  * Unit tests for FileWalker
  */
 
-import { describe, test, expect } from '@jest/globals';
+import { describe, test, expect, beforeAll, afterAll } from '@jest/globals';
+import { spawn } from 'node:child_process';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
 import * as path from 'node:path';
-import { FileWalker, discoverFiles } from '../../../src/indexing/file-walker';
-import { Language } from '../../../src/types/indexing';
+import {
+  FileWalker,
+  computeContentHash,
+  discoverFiles,
+  readDiscoveredFile,
+  summarizeUnreadablePaths,
+} from '../../../src/indexing/file-walker';
+import { isUnchangedOnDisk } from '../../../src/indexing/incremental';
+import { MemoryTree } from '../../../src/indexing/memory-tree';
+import { Language, type IndexedFileStamp } from '../../../src/types/indexing';
 
 const FIXTURES_PATH = path.join(__dirname, '../../fixtures');
 
@@ -48,6 +59,14 @@ describe('FileWalker', () => {
         expect(tsFile.language).toBe(Language.TypeScript);
       }
     });
+
+    test('should discover files in the same order on every run', async () => {
+      const first = await new FileWalker(FIXTURES_PATH).discoverFiles();
+      const second = await new FileWalker(FIXTURES_PATH).discoverFiles();
+
+      expect(second.map((f) => f.relative_path)).toEqual(first.map((f) => f.relative_path));
+      expect(second.map((f) => f.file_hash)).toEqual(first.map((f) => f.file_hash));
+    });
   });
 
   describe('gitignore filtering', () => {
@@ -143,6 +162,498 @@ describe('FileWalker', () => {
     });
   });
 
+  describe('directory config overrides', () => {
+    const repoPath = path.join(FIXTURES_PATH, 'repo-with-directory-config');
+
+    test('should apply nested exclude patterns to their subtree', async () => {
+      const walker = new FileWalker(repoPath);
+      const files = await walker.discoverFiles();
+
+      expect(files.find((f) => f.relative_path.endsWith('client_mock.ts'))).toBeUndefined();
+      expect(files.find((f) => f.relative_path.endsWith('client.ts'))).toBeDefined();
+      expect(walker.getSkippedFiles()).toContainEqual({
+        relative_path: path.join('gen', 'client_mock.ts'),
+        reason: 'directory_config',
+        detail: 'gen/.cindex.yaml',
+      });
+    });
+
+    test('should merge configs hierarchically', async () => {
+      const files = await discoverFiles(repoPath);
+
+      const generated = files.find((f) => f.relative_path.endsWith('client.ts'));
+      expect(generated?.directory_config).toEqual({
+        structure_only_lines: 100,
+        metrics: { max_complexity: 50, max_nesting: 4 },
+      });
+
+      const source = files.find((f) => f.relative_path.endsWith('index.ts'));
+      expect(source?.directory_config).toEqual({
+        metrics: { max_complexity: 10, max_nesting: 4 },
+      });
+    });
+  });
+
+  describe('ignore files and include lists', () => {
+    let repoPath: string;
+    const write = (relative: string, content = 'package x\n'): void => {
+      fs.mkdirSync(path.dirname(path.join(repoPath, relative)), { recursive: true });
+      fs.writeFileSync(path.join(repoPath, relative), content);
+    };
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-include-'));
+      write('.cindexignore', '# fixtures pollute results\ntestdata/\n*_fixture.go\n');
+      write('.cindex.yaml', "include: ['internal/**', 'cmd/*.go']\n");
+      write('cmd/main.go');
+      write('cmd/tool/.cindex.yaml', 'include: []\n');
+      write('cmd/tool/tool.go');
+      write('internal/auth/login.go');
+      write('internal/auth/login_fixture.go');
+      write('internal/auth/.cindexignore', 'legacy.go\n');
+      write('internal/auth/legacy.go');
+      write('internal/testdata/sample.go');
+      write('scripts/gen.go');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should apply .cindexignore patterns to their subtree and index only included files', async () => {
+      const walker = new FileWalker(repoPath);
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['cmd/main.go', 'cmd/tool/tool.go', 'internal/auth/login.go']);
+      const skipped = walker.getSkippedFiles();
+      expect(skipped).toContainEqual({
+        relative_path: 'internal/auth/legacy.go',
+        reason: 'cindexignore',
+        detail: 'internal/auth/.cindexignore',
+      });
+      expect(skipped).toContainEqual({
+        relative_path: 'internal/auth/login_fixture.go',
+        reason: 'cindexignore',
+        detail: '.cindexignore',
+      });
+      expect(skipped).toContainEqual({
+        relative_path: 'internal/testdata/',
+        reason: 'cindexignore',
+        detail: '.cindexignore',
+      });
+      expect(skipped).toContainEqual({
+        relative_path: 'scripts/gen.go',
+        reason: 'not_included',
+        detail: '.cindex.yaml',
+      });
+    });
+
+    test('should apply .cindexignore with .gitignore disabled', async () => {
+      const files = await new FileWalker(repoPath, { respectGitignore: false }).discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).not.toContain('internal/auth/legacy.go');
+    });
+  });
+
+  describe('size and binary settings', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-limits-'));
+      fs.writeFileSync(path.join(repoPath, '.cindex.yaml'), "max_file_bytes: 64\nbinary_extensions: ['TS']\n");
+      fs.writeFileSync(path.join(repoPath, 'small.go'), 'package small\n');
+      fs.writeFileSync(path.join(repoPath, 'dump.go'), `package dump\n\nvar data = "${'x'.repeat(100)}"\n`);
+      fs.writeFileSync(path.join(repoPath, 'video.ts'), 'G@\u0000');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should skip files over max_file_bytes and extensions listed as binary', async () => {
+      const walker = new FileWalker(repoPath);
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['small.go']);
+      const skipped = walker.getSkippedFiles();
+      expect(skipped).toContainEqual({ relative_path: 'dump.go', reason: 'size_limit', detail: '128 bytes > 64' });
+      expect(skipped).toContainEqual({ relative_path: 'video.ts', reason: 'binary', detail: 'extension .ts' });
+    });
+  });
+
+  describe('symlink policy', () => {
+    // tmp/repo/src/a.ts, with links to an ancestor, to a sibling, and outside the repository
+    let tmpDir: string;
+    let repoPath: string;
+
+    beforeAll(() => {
+      tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-symlink-'));
+      repoPath = path.join(tmpDir, 'repo');
+      fs.mkdirSync(path.join(repoPath, 'src'), { recursive: true });
+      fs.mkdirSync(path.join(tmpDir, 'external'));
+      fs.writeFileSync(path.join(repoPath, 'src', 'a.ts'), 'export const a = 1;\n');
+      fs.writeFileSync(path.join(tmpDir, 'external', 'b.ts'), 'export const b = 2;\n');
+      fs.symlinkSync('..', path.join(repoPath, 'src', 'loop'));
+      fs.symlinkSync('src', path.join(repoPath, 'linked'));
+      fs.symlinkSync(path.join('..', 'external'), path.join(repoPath, 'outside'));
+      fs.symlinkSync('missing.ts', path.join(repoPath, 'broken.ts'));
+    });
+
+    afterAll(() => {
+      fs.rmSync(tmpDir, { recursive: true, force: true });
+    });
+
+    test('should follow links inside the root once and stop at loops', async () => {
+      const walker = new FileWalker(repoPath);
+      const files = await walker.discoverFiles();
+
+      expect(files.filter((f) => f.relative_path.endsWith('a.ts'))).toHaveLength(1);
+      expect(files.some((f) => f.relative_path.endsWith('b.ts'))).toBe(false);
+
+      const symlinkSkips = walker.getSkippedFiles().filter((skip) => skip.reason === 'symlink');
+      expect(symlinkSkips.find((skip) => skip.relative_path === 'outside')?.detail).toMatch(/outside repository/);
+      expect(symlinkSkips.find((skip) => skip.relative_path === 'broken.ts')?.detail).toBe('broken link');
+      expect(symlinkSkips.some((skip) => skip.detail === 'same target as .')).toBe(true);
+    });
+
+    test('should walk each directory in name order', async () => {
+      const files = await new FileWalker(repoPath, { symlinkPolicy: 'follow-all' }).discoverFiles();
+
+      // linked/ sorts before src/, so the shared target is discovered under the link
+      expect(files.map((f) => f.relative_path)).toEqual([path.join('linked', 'a.ts'), path.join('outside', 'b.ts')]);
+    });
+
+    test('should follow links outside the root with follow-all', async () => {
+      const walker = new FileWalker(repoPath, { symlinkPolicy: 'follow-all' });
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toContain(path.join('outside', 'b.ts'));
+    });
+
+    test('should ignore every link with skip', async () => {
+      const walker = new FileWalker(repoPath, { symlinkPolicy: 'skip' });
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual([path.join('src', 'a.ts')]);
+      expect(walker.getSkippedFiles().filter((skip) => skip.reason === 'symlink')).toHaveLength(4);
+    });
+  });
+
+  describe('traversal limits', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-limits-'));
+      fs.mkdirSync(path.join(repoPath, 'a', 'b', 'c'), { recursive: true });
+      fs.writeFileSync(path.join(repoPath, 'a', 'shallow.ts'), 'export const a = 1;\n');
+      fs.writeFileSync(path.join(repoPath, 'a', 'b', 'c', 'deep.ts'), 'export const b = 2;\n');
+      fs.writeFileSync(path.join(repoPath, `${'x'.repeat(40)}.ts`), 'export const c = 3;\n');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should skip directories nested past the depth limit', async () => {
+      const walker = new FileWalker(repoPath, { maxDirectoryDepth: 2 });
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).not.toContain('a/b/c/deep.ts');
+      expect(files.map((f) => f.relative_path)).toContain('a/shallow.ts');
+      expect(walker.getSkippedFiles()).toContainEqual({
+        relative_path: 'a/b/c/',
+        reason: 'depth_limit',
+        detail: 'depth 3 > 2',
+      });
+    });
+
+    test('should skip paths over the length limit', async () => {
+      const walker = new FileWalker(repoPath, { maxPathLength: 20 });
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['a/b/c/deep.ts', 'a/shallow.ts']);
+      expect(walker.getSkippedFiles()).toContainEqual({
+        relative_path: `${'x'.repeat(40)}.ts`,
+        reason: 'path_length',
+        detail: '43 characters > 20',
+      });
+    });
+  });
+
+  describe('default exclusions', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-defaults-'));
+      for (const dir of ['vendor', 'fixtures', 'src']) {
+        fs.mkdirSync(path.join(repoPath, dir));
+        fs.writeFileSync(path.join(repoPath, dir, 'lib.go'), 'package lib\n');
+      }
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should skip vendor/ by default', async () => {
+      const files = await new FileWalker(repoPath).discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['fixtures/lib.go', 'src/lib.go']);
+    });
+
+    test('should add and remove directories with overrides', async () => {
+      const walker = new FileWalker(repoPath, { excludeDirectories: ['!vendor', 'fixtures/'] });
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['src/lib.go', 'vendor/lib.go']);
+      expect(walker.getSkippedFiles()).toContainEqual({ relative_path: 'fixtures/', reason: 'excluded_directory' });
+    });
+  });
+
+  describe('generated files', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-generated-'));
+      const header = '// Code generated by "stringer"; DO NOT EDIT.\n';
+      fs.writeFileSync(path.join(repoPath, 'enum_string.go'), `${header}\npackage x\n`);
+      fs.writeFileSync(path.join(repoPath, 'api.pb.go'), 'package api\n');
+      fs.writeFileSync(path.join(repoPath, 'main.go'), '// Package main does not generate anything\npackage main\n');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should exclude generated files by default', async () => {
+      const walker = new FileWalker(repoPath);
+      const files = await walker.discoverFiles();
+
+      expect(files.map((f) => f.relative_path)).toEqual(['main.go']);
+      const skips = walker.getSkippedFiles().filter((skip) => skip.reason === 'generated');
+      expect(skips.map((skip) => skip.relative_path)).toEqual(['api.pb.go', 'enum_string.go']);
+      expect(skips[1].detail).toBe('header: // Code generated by "stringer"; DO NOT EDIT.');
+    });
+
+    test('should tag generated files with the tag policy', async () => {
+      const files = await new FileWalker(repoPath, { generatedFiles: 'tag' }).discoverFiles();
+      const tagged = Object.fromEntries(files.map((f) => [f.relative_path, f.generated !== undefined]));
+
+      expect(tagged).toEqual({ 'api.pb.go': true, 'enum_string.go': true, 'main.go': false });
+    });
+  });
+
+  describe('file encodings', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-encoding-'));
+      const utf16 = Buffer.concat([Buffer.from([0xff, 0xfe]), Buffer.from('export const a = 1;\n', 'utf16le')]);
+      fs.writeFileSync(path.join(repoPath, 'utf16.ts'), utf16);
+      // "// あい" in Shift_JIS
+      fs.writeFileSync(path.join(repoPath, 'sjis.ts'), Buffer.from([0x2f, 0x2f, 0x20, 0x82, 0xa0, 0x82, 0xa2, 0x0a]));
+      fs.writeFileSync(path.join(repoPath, 'latin1.ts'), Buffer.from('// caf\u00e9\nexport const b = 2;\n', 'latin1'));
+      fs.writeFileSync(path.join(repoPath, 'utf8.ts'), 'export const c = 3;\n');
+      fs.writeFileSync(path.join(repoPath, 'bom.ts'), '\ufeffexport const d = 4;\n');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should record the detected encoding of each file', async () => {
+      const files = await new FileWalker(repoPath).discoverFiles();
+      const encodings = Object.fromEntries(files.map((f) => [f.relative_path, f.encoding]));
+
+      expect(encodings).toEqual({
+        'bom.ts': 'utf-8',
+        'latin1.ts': 'latin1',
+        'sjis.ts': 'shift_jis',
+        'utf16.ts': 'utf-16le',
+        'utf8.ts': 'utf-8',
+      });
+    });
+
+    test('should count lines on the transcoded content', async () => {
+      const files = await new FileWalker(repoPath).discoverFiles();
+
+      expect(files.find((f) => f.relative_path === 'utf16.ts')?.line_count).toBe(2);
+    });
+
+    test('should flag files that start with a BOM', async () => {
+      const files = await new FileWalker(repoPath).discoverFiles();
+      const flagged = files.filter((f) => f.bom).map((f) => f.relative_path);
+
+      expect(flagged).toEqual(['bom.ts', 'utf16.ts']);
+    });
+  });
+
+  describe('indexed file stamps', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-stamps-'));
+      fs.writeFileSync(path.join(repoPath, 'same.ts'), 'export const a = 1;\n');
+      fs.writeFileSync(path.join(repoPath, 'edited.ts'), 'export const b = 2;\n');
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    /** Stamp as the index records it, with a hash the file was never read for */
+    const stampOf = (name: string, fileHash: string): [string, IndexedFileStamp] => {
+      const stats = fs.statSync(path.join(repoPath, name));
+      const stamp = { file_hash: fileHash, line_count: 7, file_size: stats.size, mtime_ms: stats.mtime.getTime() };
+      return [name, { ...stamp, encoding: 'utf-8' }];
+    };
+
+    test('should reuse the recorded hash for files with unchanged size and mtime', async () => {
+      const walker = new FileWalker(repoPath);
+      walker.reuseIndexedFiles(new Map([stampOf('same.ts', 'recorded')]));
+      const files = await walker.discoverFiles();
+      const same = files.find((f) => f.relative_path === 'same.ts');
+
+      expect(same).toMatchObject({ file_hash: 'recorded', line_count: 7 });
+      expect(walker.getStats().total_lines).toBe(9);
+    });
+
+    test('should read files whose size or mtime changed', async () => {
+      const [name, stamp] = stampOf('edited.ts', 'recorded');
+      const walker = new FileWalker(repoPath);
+      walker.reuseIndexedFiles(new Map([[name, { ...stamp, mtime_ms: stamp.mtime_ms - 1000 }]]));
+      const files = await walker.discoverFiles();
+
+      expect(files.find((f) => f.relative_path === 'edited.ts')?.file_hash).toHaveLength(64);
+    });
+
+    test('should compare size and modification time', () => {
+      const [, stamp] = stampOf('same.ts', 'recorded');
+
+      expect(isUnchangedOnDisk(stamp, stamp.file_size, new Date(stamp.mtime_ms))).toBe(true);
+      expect(isUnchangedOnDisk(stamp, stamp.file_size + 1, new Date(stamp.mtime_ms))).toBe(false);
+      expect(isUnchangedOnDisk(stamp, stamp.file_size, new Date(stamp.mtime_ms + 1))).toBe(false);
+    });
+  });
+
+  describe('files changing while read', () => {
+    let repoPath: string;
+
+    beforeAll(() => {
+      repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-changing-'));
+    });
+
+    afterAll(() => {
+      fs.rmSync(repoPath, { recursive: true, force: true });
+    });
+
+    test('should index what is read when a file changed after discovery', async () => {
+      fs.writeFileSync(path.join(repoPath, 'moved.ts'), 'export const a = 1;\n');
+      const [discovered] = await new FileWalker(repoPath).discoverFiles();
+      const current = 'export const a = 1;\nexport const b = 2;\n';
+      fs.writeFileSync(path.join(repoPath, 'moved.ts'), current);
+
+      const { file, content } = await readDiscoveredFile(discovered);
+
+      expect(content).toBe(current);
+      expect(file).toMatchObject({ file_hash: computeContentHash(current), line_count: 3, file_size_bytes: 40 });
+    });
+
+    test('should re-read or defer a file rewritten during reads, never returning it torn', async () => {
+      const versions = ['a'.repeat(1 << 20), 'b'.repeat(3 << 19)];
+      const target = path.join(repoPath, 'busy.ts');
+      fs.writeFileSync(target, versions[0]);
+      const files = await new FileWalker(repoPath).discoverFiles();
+      const [discovered] = files.filter((f) => f.relative_path === 'busy.ts');
+      // Each version is written aside and renamed into place, so reads of either are whole
+      const writer = spawn(process.execPath, [
+        '-e',
+        `const fs = require('fs');
+        for (let i = 0; ; i++) {
+          fs.writeFileSync('${target}.next', i % 2 ? 'a'.repeat(1 << 20) : 'b'.repeat(3 << 19));
+          fs.renameSync('${target}.next', '${target}');
+        }`,
+      ]);
+
+      try {
+        for (let read = 0; read < 30; read++) {
+          try {
+            const { file, content } = await readDiscoveredFile(discovered);
+            expect(versions).toContain(content);
+            expect(file).toMatchObject({ file_hash: computeContentHash(content), file_size_bytes: content.length });
+          } catch (error) {
+            expect((error as Error).message).toContain('deferred to the next run');
+          }
+        }
+      } finally {
+        writer.kill();
+      }
+    });
+  });
+
+  describe('memory trees', () => {
+    const modified = new Date('2024-05-01T12:00:00Z');
+    const tree = new MemoryTree(
+      [
+        ['app@3f2a9c1b7d4e/src/auth.ts', 'export const login = () => true;\n'],
+        ['app@3f2a9c1b7d4e/node_modules/left-pad/index.js', 'module.exports = 1;\n'],
+        ['app@3f2a9c1b7d4e/node_modules/left-pad/util.js', 'module.exports = 2;\n'],
+        ['app@3f2a9c1b7d4e/assets/logo.png', '\x89PNG'],
+        ['app@3f2a9c1b7d4e/.env', 'TOKEN=secret\n'],
+      ].map(([file, content]) => ({ path: file, content: Buffer.from(content), modified_time: modified }))
+    );
+
+    test('should discover the files of the tree without reading the root', () => {
+      const walker = new FileWalker('/nonexistent/revisions/app@3f2a9c1b7d4e');
+
+      const files = walker.discoverTree(tree);
+
+      expect(files).toEqual([
+        expect.objectContaining({
+          absolute_path: path.join('/nonexistent/revisions/app@3f2a9c1b7d4e', 'app@3f2a9c1b7d4e', 'src', 'auth.ts'),
+          relative_path: 'app@3f2a9c1b7d4e/src/auth.ts',
+          file_hash: computeContentHash('export const login = () => true;\n'),
+          language: Language.TypeScript,
+          file_size_bytes: 33,
+          modified_time: modified,
+        }),
+      ]);
+      expect(walker.getSkippedFiles().filter((skip) => skip.reason === 'excluded_directory')).toEqual([
+        { relative_path: 'app@3f2a9c1b7d4e/node_modules/', reason: 'excluded_directory', detail: undefined },
+      ]);
+    });
+
+    test('should read discovered files from the tree', async () => {
+      const [discovered] = new FileWalker('/nonexistent').discoverTree(tree);
+
+      const { content } = await readDiscoveredFile(discovered, tree);
+
+      expect(content).toBe('export const login = () => true;\n');
+      await expect(readDiscoveredFile({ ...discovered, relative_path: 'gone.ts' }, tree)).rejects.toThrow(
+        'File is not in the tree being indexed'
+      );
+    });
+  });
+
+  describe('unreadable paths', () => {
+    test('should summarize unreadable paths by errno, most common first', () => {
+      const summary = summarizeUnreadablePaths([
+        { relative_path: 'secret/', code: 'EACCES' },
+        { relative_path: 'disk/bad.ts', code: 'EIO' },
+        { relative_path: 'private.ts', code: 'EACCES' },
+      ]);
+
+      expect(summary).toBe('3 paths (EACCES 2, EIO 1)');
+    });
+
+    test('should report nothing unreadable in a readable tree', async () => {
+      const walker = new FileWalker(FIXTURES_PATH);
+      await walker.discoverFiles();
+
+      expect(walker.getUnreadablePaths()).toEqual([]);
+    });
+  });
+
   describe('file statistics', () => {
     test('should track discovery statistics', async () => {
       const walker = new FileWalker(FIXTURES_PATH);
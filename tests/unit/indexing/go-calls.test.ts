@@ -0,0 +1,137 @@
+/**
+ * Unit tests for Go call graph extraction
+ */
+
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { describe, test, expect } from '@jest/globals';
+import { defaultImportName, extractGoCalls, parseGoImports } from '../../../src/indexing/go-calls';
+
+const SAMPLE = fs.readFileSync(path.join(__dirname, '../../fixtures/sample.go'), 'utf-8');
+const IMPORTS = parseGoImports(SAMPLE);
+
+/**
+ * Source and start line of a top-level declaration in the sample fixture
+ */
+const declaration = (header: string): { code: string; line: number } => {
+  const lines = SAMPLE.split('\n');
+  const start = lines.findIndex((line) => line.startsWith(header));
+  const end = lines.indexOf('}', start);
+  return { code: lines.slice(start, end + 1).join('\n'), line: start + 1 };
+};
+
+const callsOf = (header: string): string[] => {
+  const { code, line } = declaration(header);
+  return extractGoCalls(code, line, IMPORTS).map((call) => `${call.kind} ${call.callee}`);
+};
+
+describe('parseGoImports', () => {
+  test('should map default names to import paths', () => {
+    expect([...IMPORTS]).toEqual([
+      ['sql', 'database/sql'],
+      ['errors', 'errors'],
+      ['fmt', 'fmt'],
+      ['time', 'time'],
+    ]);
+  });
+
+  test('should use aliases and skip blank and dot imports', () => {
+    const imports = parseGoImports(
+      [
+        'package main',
+        'import log "github.com/sirupsen/logrus"',
+        'import (',
+        '\t_ "github.com/lib/pq" // driver',
+        '\t. "strings"',
+        '\t"gopkg.in/yaml.v3"',
+        '\t// "unused"',
+        ')',
+      ].join('\n')
+    );
+    expect([...imports]).toEqual([
+      ['log', 'github.com/sirupsen/logrus'],
+      ['yaml', 'gopkg.in/yaml.v3'],
+    ]);
+  });
+
+  test('should derive default names from the path', () => {
+    expect(defaultImportName('github.com/go-chi/chi/v5')).toBe('chi');
+    expect(defaultImportName('github.com/mattn/go-sqlite3')).toBe('sqlite3');
+    expect(defaultImportName('net/http')).toBe('http');
+  });
+});
+
+describe('extractGoCalls', () => {
+  test('should resolve calls through the receiver to methods', () => {
+    expect(callsOf('func (s *AuthService) Login(')).toEqual([
+      'import New',
+      'method AuthService.queryUser',
+      'import New',
+      'method AuthService.verifyPassword',
+      'import New',
+    ]);
+  });
+
+  test('should record the caller with positions of each call', () => {
+    const { code, line } = declaration('func (s *AuthService) Login(');
+    const call = extractGoCalls(code, line, IMPORTS).find((c) => c.callee === 'AuthService.queryUser');
+    expect(call).toEqual({
+      caller: 'AuthService.Login',
+      caller_line: 50,
+      callee: 'AuthService.queryUser',
+      kind: 'method',
+      callee_package: null,
+      qualifier: null,
+      line: 55,
+      column: 17,
+    });
+  });
+
+  test('should resolve package functions, imports, and calls on other values', () => {
+    const { code, line } = declaration('func (s *AuthService) CreateSession(');
+    const calls = extractGoCalls(code, line, IMPORTS);
+    expect(calls.map((call) => [call.kind, call.callee, call.callee_package ?? call.qualifier])).toEqual([
+      ['function', 'generateSessionID', null],
+      ['import', 'Now', 'time'],
+      ['dynamic', 'Add', '()'],
+      ['import', 'Duration', 'time'],
+      ['dynamic', 'Exec', 's.dbClient'],
+    ]);
+    expect(callsOf('func generateSessionID(')).toEqual(['import Sprintf', 'import Now', 'dynamic UnixNano']);
+  });
+
+  test('should ignore builtins, keywords, strings, and comments', () => {
+    const code = [
+      'func walk[T any](items []T, visit func(T) error) (n int) {',
+      '\t// visit(items[0]) is skipped',
+      '\tfor i := range len(items) {',
+      '\t\tif err := visit(items[i]); err != nil {',
+      '\t\t\tpanic(fmt.Sprintf("visit(%d)", i))',
+      '\t\t}',
+      '\t\tn = int(uint8(i))',
+      '\t}',
+      '\tdefer func() { recover() }()',
+      '\treturn',
+      '}',
+    ].join('\n');
+    const calls = extractGoCalls(code, 1, new Map([['fmt', 'fmt']]));
+    expect(calls.map((call) => `${String(call.line)}:${call.caller} ${call.callee}`)).toEqual([
+      '4:walk visit',
+      '5:walk Sprintf',
+    ]);
+  });
+
+  test('should name methods of generic types without type parameters', () => {
+    const code = 'func (l *List[T]) Push(v T) {\n\tl.grow()\n\tl.items = append(l.items, v)\n}';
+    expect(extractGoCalls(code, 10, new Map()).map((call) => [call.caller, call.callee])).toEqual([
+      ['List.Push', 'List.grow'],
+    ]);
+  });
+
+  test('should skip signature types and declarations without a body', () => {
+    const code = 'func Decode(r io.Reader) (v interface{ Len() int }, err error) {\n\treturn parse(r)\n}';
+    expect(extractGoCalls(code, 1, new Map()).map((call) => call.callee)).toEqual(['parse']);
+    expect(extractGoCalls('func nanotime() int64', 1, new Map())).toEqual([]);
+  });
+});
@@ -0,0 +1,106 @@
+/**
+ * Unit tests for Go constant extraction
+ */
+
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { describe, test, expect } from '@jest/globals';
+import { extractGoConstants } from '../../../src/indexing/go-constants';
+
+const SAMPLE = fs.readFileSync(path.join(__dirname, '../../fixtures/sample.go'), 'utf-8');
+
+/** name=value pairs of a source's constants (the expression when unresolved) */
+const valuesOf = (content: string): string[] =>
+  extractGoConstants(content).map((constant) => `${constant.name}=${constant.value ?? `(${constant.expression})`}`);
+
+describe('extractGoConstants', () => {
+  test('should resolve the constants of the sample fixture', () => {
+    const constants = extractGoConstants(SAMPLE);
+
+    expect(valuesOf(SAMPLE)).toEqual([
+      'SessionTimeout=3600',
+      'APIVersion="v1"',
+      'RoleUser=0',
+      'RoleModerator=1',
+      'RoleAdmin=2',
+    ]);
+    expect(constants.filter((constant) => constant.type === 'UserRole').map((constant) => constant.iota)).toEqual([
+      0, 1, 2,
+    ]);
+    expect(constants[0]).toMatchObject({
+      type: null,
+      doc: 'SessionTimeout defines the session expiration time',
+      line: 12,
+      group_line: 12,
+    });
+    expect(constants[3]).toMatchObject({ expression: 'iota', group_line: 28, line: 30 });
+  });
+
+  test('should step iota per spec, skipping blank names', () => {
+    const source = `package size
+
+const (
+\t_  = iota // ignore first value
+\tKB = 1 << (10 * iota)
+\tMB
+\tGB
+)
+`;
+    expect(valuesOf(source)).toEqual(['KB=1024', 'MB=1048576', 'GB=1073741824']);
+    expect(extractGoConstants(source)[0].doc).toBeNull();
+  });
+
+  test('should evaluate constant expressions across specs', () => {
+    const source = `package limits
+
+const (
+\tA, B = iota, iota * 10
+\tC, D
+)
+
+const Name = "cindex" + "-" + Version
+const Version = "v2"
+const Ratio = 3 / 2.0
+const Mask = 0xFF &^ 0x0F
+const Ready = Ratio > 1 && Mask == 240
+const Letter = 'a' + 1
+const Timeout = 5 * time.Second
+`;
+    expect(valuesOf(source)).toEqual([
+      'A=0',
+      'B=0',
+      'C=1',
+      'D=10',
+      'Name="cindex-v2"',
+      'Version="v2"',
+      'Ratio=1.5',
+      'Mask=240',
+      'Ready=true',
+      'Letter=98',
+      'Timeout=(5 * time.Second)',
+    ]);
+  });
+
+  test('should take the type of a conversion and keep trailing comments as docs', () => {
+    const source = `package state
+
+type Status uint8
+
+const (
+\tStatusActive = Status(iota + 1) // Serving requests
+\tStatusDraining                   // Finishing in-flight work
+)
+
+func f() {
+\tconst local = 1
+}
+`;
+    const constants = extractGoConstants(source);
+
+    expect(constants.map((constant) => [constant.name, constant.type, constant.value, constant.doc])).toEqual([
+      ['StatusActive', 'Status', '1', 'Serving requests'],
+      ['StatusDraining', 'Status', '2', 'Finishing in-flight work'],
+    ]);
+  });
+});
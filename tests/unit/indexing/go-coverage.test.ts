@@ -0,0 +1,69 @@
+/**
+ * Unit tests for Go coverage profile parsing and per-function coverage
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { computeFunctionCoverage, parseCoverProfile } from '../../../src/indexing/go-coverage';
+import { type FunctionSpanRecord } from '../../../src/types/database';
+import { matchStoredPath } from '../../../src/utils/paths';
+
+const PROFILE = [
+  'mode: set',
+  'github.com/acme/shop/internal/auth/login.go:10.40,12.16 2 1',
+  'github.com/acme/shop/internal/auth/login.go:12.16,14.3 1 0',
+  'github.com/acme/shop/internal/auth/login.go:15.2,15.12 1 1',
+  'github.com/acme/shop/internal/auth/login.go:20.30,22.2 2 0',
+  // Merged profiles repeat blocks; the highest count wins
+  'github.com/acme/shop/internal/auth/login.go:20.30,22.2 2 1',
+].join('\n');
+
+const span = (id: number, symbol_name: string, line_number: number, end_line: number | null): FunctionSpanRecord => ({
+  id,
+  symbol_name,
+  file_path: 'internal/auth/login.go',
+  line_number,
+  end_line,
+});
+
+describe('parseCoverProfile', () => {
+  test('should read the mode and merge repeated blocks', () => {
+    const profile = parseCoverProfile(PROFILE);
+
+    expect(profile.mode).toBe('set');
+    expect(profile.blocks).toHaveLength(4);
+    expect(profile.blocks[3]).toMatchObject({ start_line: 20, end_line: 22, statements: 2, count: 1 });
+  });
+
+  test('should reject lines that are not blocks', () => {
+    expect(() => parseCoverProfile('mode: set\nok  github.com/acme/shop 0.2s')).toThrow('line 2');
+  });
+});
+
+describe('matchStoredPath', () => {
+  test('should match profile import paths by the longest suffix at a directory boundary', () => {
+    const indexed = ['login.go', 'internal/auth/login.go', 'auth/login.go'];
+
+    expect(matchStoredPath('github.com/acme/shop/internal/auth/login.go', indexed)).toBe('internal/auth/login.go');
+    expect(matchStoredPath('github.com/acme/shop/internal/auth/xlogin.go', indexed)).toBeNull();
+  });
+});
+
+describe('computeFunctionCoverage', () => {
+  test('should attribute blocks to the function spanning them', () => {
+    const blocks = parseCoverProfile(PROFILE).blocks.map((block) => ({ ...block, file: 'internal/auth/login.go' }));
+
+    const coverage = computeFunctionCoverage(blocks, [
+      span(1, 'Login', 10, 16),
+      span(2, 'Logout', 20, 22),
+      span(3, 'Refresh', 30, 40),
+      span(4, 'Legacy', 10, null),
+    ]);
+
+    expect(coverage.map((entry) => [entry.symbol.symbol_name, entry.percent])).toEqual([
+      ['Login', 75],
+      ['Logout', 100],
+      ['Refresh', null],
+      ['Legacy', null],
+    ]);
+  });
+});
@@ -0,0 +1,92 @@
+/**
+ * Unit tests for Go struct and interface embedding extraction
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { parseGoImports } from '../../../src/indexing/go-calls';
+import { extractGoEmbeddings } from '../../../src/indexing/go-embeddings';
+
+const SOURCE = `package auth
+
+import (
+\t"sync"
+
+\tlg "example.com/shop/log"
+)
+
+// AdminService manages users
+type AdminService struct {
+\t*AuthService
+\tsync.Mutex
+\tlg.Logger \`json:"-"\`
+\taudit Logger
+\tInner struct {
+\t\tHidden
+\t}
+\tBase[T]; name string
+}
+
+type (
+\tRoleChecker interface {
+\t\tPermissionChecker
+\t\terror
+\t\tHasRole(role string) bool
+\t}
+\tNumber interface { ~int | ~float64; int }
+\tPlain int
+)
+
+type Cache[K comparable, V any] struct { Store[K, V] }
+`;
+
+const summary = (source: string): string[] =>
+  extractGoEmbeddings(source, parseGoImports(source)).map(
+    (embedding) =>
+      `${embedding.type_name} ${embedding.type_kind} ${embedding.pointer ? '*' : ''}` +
+      `${embedding.embedded_package ?? ''}${embedding.embedded_package ? '.' : ''}${embedding.embedded_name}`
+  );
+
+describe('extractGoEmbeddings', () => {
+  test('should find embedded fields of structs, by pointer and through imports', () => {
+    expect(summary(SOURCE).filter((entry) => entry.startsWith('AdminService'))).toEqual([
+      'AdminService struct *AuthService',
+      'AdminService struct sync.Mutex',
+      'AdminService struct example.com/shop/log.Logger',
+      'AdminService struct Base',
+    ]);
+  });
+
+  test('should find interfaces embedded in interfaces, but not type set terms', () => {
+    expect(summary(SOURCE).filter((entry) => !entry.startsWith('AdminService'))).toEqual([
+      'RoleChecker interface PermissionChecker',
+      'RoleChecker interface error',
+      'Cache struct Store',
+    ]);
+  });
+
+  test('should position embedded names past pointers and qualifiers, in UTF-8 bytes', () => {
+    const source = 'package p\n\ntype Unit struct {\n\t/* Grundgerüst */ *Base\n\tio.Reader\n}\n';
+
+    const positions = extractGoEmbeddings(source, new Map()).map(({ line, column }) => [line, column]);
+
+    expect(positions).toEqual([
+      [4, 22],
+      [5, 5],
+    ]);
+  });
+
+  test('should leave out named fields, nested structs, comments, and strings', () => {
+    const source = [
+      'package p',
+      'type Config struct {',
+      '\t// Embedded is documented',
+      '\tName, Alias string',
+      '\tOptions struct{ Verbose bool }',
+      '\tHandler func(Request) Response',
+      '}',
+      'var s = "type Fake struct { Base }"',
+    ].join('\n');
+
+    expect(extractGoEmbeddings(source, new Map())).toEqual([]);
+  });
+});
@@ -0,0 +1,109 @@
+/**
+ * Unit tests for Go type parameter and instantiation extraction
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { parseGoImports } from '../../../src/indexing/go-calls';
+import { extractGoInstantiations, extractGoTypeParameters } from '../../../src/indexing/go-generics';
+
+const SOURCE = `package collections
+
+import (
+\t"strconv"
+
+\t"golang.org/x/exp/constraints"
+)
+
+// Map applies f to each element
+func Map[S ~[]E, E, R any](s S, f func(E) R) []R {
+\tout := make([]R, 0, len(s))
+\tfor _, v := range s {
+\t\tout = append(out, f(v))
+\t}
+\treturn out
+}
+
+type Cache[K comparable, V any] struct {
+\titems map[K]V
+}
+
+type Buffer [Size]byte
+
+type (
+\tPair[A, B any] struct{ First A; Second B }
+\tTree[T constraints.Ordered] struct{ left *Tree[T] }
+)
+
+func (c *Cache[K, V]) Get(key K) V {
+\treturn c.items[key]
+}
+
+func use(users []string, ID int) {
+\tc := NewCache[string, *User]()
+\t_ = users[ID]
+\tnames := Map[[]int, int, string]([]int{1}, strconv.Itoa)
+\tvar trees []Tree[int]
+\tx := c.items["a"]
+}
+`;
+
+describe('extractGoTypeParameters', () => {
+  test('should read type parameter lists of functions and types, sharing constraints', () => {
+    const parameters = extractGoTypeParameters(SOURCE);
+
+    expect(parameters.map((parameter) => `${parameter.symbol}.${parameter.name} ${parameter.constraint}`)).toEqual([
+      'Map.S ~[]E',
+      'Map.E any',
+      'Map.R any',
+      'Cache.K comparable',
+      'Cache.V any',
+      'Pair.A any',
+      'Pair.B any',
+      'Tree.T constraints.Ordered',
+    ]);
+    expect(parameters[0]).toMatchObject({ symbol_kind: 'function', position: 0, line: 10 });
+    expect(parameters[3]).toMatchObject({ symbol_kind: 'type', position: 0, line: 18 });
+  });
+});
+
+describe('extractGoInstantiations', () => {
+  test('should find written-out type arguments, skipping declarations, receivers, and fields', () => {
+    const instantiations = extractGoInstantiations(SOURCE, parseGoImports(SOURCE));
+
+    const labels = instantiations.map(
+      (site) => `${site.target}[${site.type_arguments.join(', ')}] ${site.context ?? '-'}`
+    );
+
+    // users[ID] reads as a type too; it is dropped when queried, as users names no generic
+    expect(labels).toEqual([
+      'Tree[T] -',
+      'NewCache[string, *User] use',
+      'users[ID] use',
+      'Map[[]int, int, string] use',
+      'Tree[int] use',
+    ]);
+    expect(instantiations[1]).toMatchObject({ line: 34, column: 7, target_package: null });
+  });
+
+  test('should qualify instantiations through imports only', () => {
+    const source = `package main
+
+import "example.com/lib/set"
+
+func main() {
+\ts := set.New[string]()
+\tcfg.items[Key] = 1
+}
+`;
+    expect(extractGoInstantiations(source, parseGoImports(source))).toEqual([
+      {
+        target: 'New',
+        target_package: 'example.com/lib/set',
+        type_arguments: ['string'],
+        context: 'main',
+        line: 6,
+        column: 11,
+      },
+    ]);
+  });
+});
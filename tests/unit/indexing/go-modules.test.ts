@@ -0,0 +1,135 @@
+/**
+ * Unit tests for Go module lists and dependency selection
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  matchesModulePattern,
+  moduleCacheSubdirectory,
+  moduleIndexId,
+  moduleUnavailable,
+  parseModuleList,
+  selectModules,
+} from '../../../src/indexing/go-modules';
+
+/** `go list -m -json all` output: main module, a replaced module, a local replacement, and a missing one */
+const LISTED = `{
+	"Path": "example.com/app",
+	"Main": true,
+	"Dir": "/src/app",
+	"GoMod": "/src/app/go.mod"
+}
+{
+	"Path": "golang.org/x/text",
+	"Version": "v0.14.0",
+	"Dir": "/home/me/go/pkg/mod/golang.org/x/text@v0.14.0"
+}
+{
+	"Path": "github.com/BurntSushi/toml",
+	"Version": "v1.2.0",
+	"Replace": {
+		"Path": "github.com/BurntSushi/toml",
+		"Version": "v1.3.2",
+		"Dir": "/home/me/go/pkg/mod/github.com/!burnt!sushi/toml@v1.3.2"
+	},
+	"Indirect": true,
+	"Dir": "/home/me/go/pkg/mod/github.com/!burnt!sushi/toml@v1.3.2"
+}
+{
+	"Path": "example.com/shared",
+	"Version": "v0.1.0",
+	"Replace": {
+		"Path": "../shared",
+		"Dir": "/src/shared"
+	},
+	"Dir": "/src/shared"
+}
+{
+	"Path": "golang.org/x/sys",
+	"Version": "v0.15.0",
+	"Indirect": true,
+	"Error": { "Err": "module lookup disabled by GOPROXY=off {}" }
+}
+`;
+
+const CACHE = '/home/me/go/pkg/mod';
+
+describe('parseModuleList', () => {
+  const modules = parseModuleList(LISTED);
+
+  test('reads every module in order', () => {
+    expect(modules.map((module) => module.path)).toEqual([
+      'example.com/app',
+      'golang.org/x/text',
+      'github.com/BurntSushi/toml',
+      'example.com/shared',
+      'golang.org/x/sys',
+    ]);
+    expect(modules[0].main).toBe(true);
+  });
+
+  test('uses the replacement version and directory', () => {
+    expect(modules[2]).toMatchObject({ version: 'v1.3.2', indirect: true, local: false });
+    expect(moduleIndexId(modules[2])).toBe('github.com/BurntSushi/toml@v1.3.2');
+  });
+
+  test('marks local replacements and modules missing from the cache', () => {
+    expect(moduleUnavailable(modules[3])).toBe('local');
+    expect(moduleUnavailable(modules[4])).toBe('not-downloaded');
+    expect(moduleUnavailable(modules[1])).toBeNull();
+  });
+
+  test('rejects output that is not JSON', () => {
+    expect(() => parseModuleList('{ "Path": }')).toThrow('module 1: not JSON');
+  });
+});
+
+describe('matchesModulePattern', () => {
+  test('matches exact paths and ... wildcards like the go command', () => {
+    expect(matchesModulePattern('golang.org/x/text', 'golang.org/x/text')).toBe(true);
+    expect(matchesModulePattern('golang.org/x/...', 'golang.org/x/text')).toBe(true);
+    expect(matchesModulePattern('golang.org/x/...', 'golang.org/x')).toBe(true);
+    expect(matchesModulePattern('golang.org/x/...', 'golang.org/xerrors')).toBe(false);
+    expect(matchesModulePattern('github.com/.../toml', 'github.com/BurntSushi/toml')).toBe(true);
+    expect(matchesModulePattern('all', 'anything.dev/module')).toBe(true);
+  });
+
+  test('treats dots as literal characters', () => {
+    expect(matchesModulePattern('golang.org/x/text', 'golangXorg/x/text')).toBe(false);
+  });
+});
+
+describe('selectModules', () => {
+  const modules = parseModuleList(LISTED);
+
+  test('never selects the main module', () => {
+    const { selected } = selectModules(modules, ['all']);
+    expect(selected.map((module) => module.path)).not.toContain('example.com/app');
+    expect(selected).toHaveLength(4);
+  });
+
+  test('keeps direct requirements only when asked', () => {
+    const { selected } = selectModules(modules, ['all'], true);
+    expect(selected.map((module) => module.path)).toEqual(['golang.org/x/text', 'example.com/shared']);
+  });
+
+  test('reports patterns no dependency matches', () => {
+    const { selected, unmatched } = selectModules(modules, ['golang.org/x/...', 'rsc.io/quote']);
+    expect(selected.map((module) => module.path)).toEqual(['golang.org/x/text', 'golang.org/x/sys']);
+    expect(unmatched).toEqual(['rsc.io/quote']);
+  });
+});
+
+describe('moduleCacheSubdirectory', () => {
+  const modules = parseModuleList(LISTED);
+
+  test('returns the module directory below the cache', () => {
+    expect(moduleCacheSubdirectory(modules[1], CACHE)).toBe('golang.org/x/text@v0.14.0');
+    expect(moduleCacheSubdirectory(modules[2], CACHE)).toBe('github.com/!burnt!sushi/toml@v1.3.2');
+  });
+
+  test('returns null outside the cache or without a directory', () => {
+    expect(moduleCacheSubdirectory(modules[3], CACHE)).toBeNull();
+    expect(moduleCacheSubdirectory(modules[4], CACHE)).toBeNull();
+  });
+});
@@ -0,0 +1,56 @@
+/**
+ * Unit tests for Go test function classification and subtest extraction
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { classifyGoTest, extractSubtests } from '../../../src/indexing/go-tests';
+
+const LOGIN_TEST = [
+  'func TestLogin(t *testing.T) {',
+  '\tt.Run("valid password", func(t *testing.T) {',
+  '\t\tif err := login("ok)"); err != nil { // ")" in a string',
+  '\t\t\tt.Fatal(err)',
+  '\t\t}',
+  '\t})',
+  '\tt.Run(`locked`, func(t *testing.T) {',
+  '\t\tt.Run("after\\tretry", func(t *testing.T) {})',
+  '\t})',
+  '\tfor _, tc := range cases {',
+  '\t\tt.Run(tc.name, func(t *testing.T) {})',
+  '\t}',
+  '}',
+].join('\n');
+
+describe('classifyGoTest', () => {
+  test('should classify functions go test runs by prefix', () => {
+    expect(classifyGoTest('TestLogin', 'auth/login_test.go')).toBe('test');
+    expect(classifyGoTest('Test', 'auth/login_test.go')).toBe('test');
+    expect(classifyGoTest('Test_login', 'auth/login_test.go')).toBe('test');
+    expect(classifyGoTest('BenchmarkHash', 'auth/hash_test.go')).toBe('benchmark');
+    expect(classifyGoTest('FuzzParseToken', 'auth/token_test.go')).toBe('fuzz');
+    expect(classifyGoTest('ExampleLogin_withMFA', 'auth/example_test.go')).toBe('example');
+  });
+
+  test('should skip lowercase continuations and non-test files', () => {
+    expect(classifyGoTest('Testify', 'auth/login_test.go')).toBeNull();
+    expect(classifyGoTest('TestLogin', 'auth/login.go')).toBeNull();
+    expect(classifyGoTest('helper', 'auth/login_test.go')).toBeNull();
+  });
+});
+
+describe('extractSubtests', () => {
+  test('should name literal subtests the way go test does, nesting under their parent', () => {
+    const subtests = extractSubtests('TestLogin', LOGIN_TEST, 20);
+
+    expect(subtests.map(({ name, line, end_line }) => ({ name, line, end_line }))).toEqual([
+      { name: 'TestLogin/valid_password', line: 21, end_line: 25 },
+      { name: 'TestLogin/locked', line: 26, end_line: 28 },
+      { name: 'TestLogin/locked/after_retry', line: 27, end_line: 27 },
+    ]);
+    expect(subtests[0].definition).toBe('t.Run("valid password", func(t *testing.T) {');
+  });
+
+  test('should return nothing for a test without literal subtests', () => {
+    expect(extractSubtests('TestTable', 'func TestTable(t *testing.T) {\n\tt.Run(tc.name, run)\n}', 1)).toEqual([]);
+  });
+});
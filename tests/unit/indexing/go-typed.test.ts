@@ -0,0 +1,88 @@
+/**
+ * Unit tests for the Go package loader output
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { mergeGoTypeFacts, parseLoaderOutput } from '../../../src/indexing/go-typed';
+
+const REPO = '/work/shop';
+
+const OUTPUT = [
+  '{"kind":"implements","file":"/work/shop/store/store.go","line":9,"name":"Memory",' +
+    '"package":"example.com/shop/store","pointer":true,"target":"Store","target_package":"example.com/shop/store"}',
+  '{"kind":"method","file":"/work/shop/store/store.go","line":11,"end_line":13,"name":"Get",' +
+    '"package":"example.com/shop/store","receiver":"Memory","pointer":true,' +
+    '"signature":"func (*Memory).Get(key string) (string, error)"}',
+  '',
+  '{"kind":"reference","file":"/work/shop/auth/login.go","line":6,"column":14,"name":"Store.Get",' +
+    '"package":"example.com/shop/store","target_file":"/work/shop/store/store.go","target_line":6,"access":"call"}',
+  // cgo output and uses of declarations in the module cache are outside the repository
+  '{"kind":"method","file":"/root/.cache/go-build/ab/cgo.go","line":3,"name":"Len","receiver":"buf"}',
+  '{"kind":"reference","file":"/work/shop/auth/login.go","line":8,"column":2,"name":"Errorf",' +
+    '"package":"example.com/errs","target_file":"/go/pkg/mod/example.com/errs/errs.go","target_line":4}',
+].join('\n');
+
+describe('parseLoaderOutput', () => {
+  test('should read facts with paths relative to the repository', () => {
+    const facts = parseLoaderOutput(OUTPUT, REPO);
+
+    expect(facts.methods).toEqual([
+      {
+        file_path: 'store/store.go',
+        line_number: 11,
+        end_line: 13,
+        name: 'Get',
+        receiver: 'Memory',
+        pointer_receiver: true,
+        package: 'example.com/shop/store',
+        signature: 'func (*Memory).Get(key string) (string, error)',
+      },
+    ]);
+    expect(facts.implementations).toEqual([
+      {
+        file_path: 'store/store.go',
+        line_number: 9,
+        type_name: 'Memory',
+        type_package: 'example.com/shop/store',
+        interface_name: 'Store',
+        interface_package: 'example.com/shop/store',
+        pointer_receiver: true,
+      },
+    ]);
+    expect(facts.references).toEqual([
+      {
+        file_path: 'auth/login.go',
+        line_number: 6,
+        column_number: 14,
+        target_name: 'Store.Get',
+        target_package: 'example.com/shop/store',
+        target_file: 'store/store.go',
+        target_line: 6,
+        access: 'call',
+      },
+    ]);
+  });
+
+  test('should report the line that is not JSON', () => {
+    expect(() => parseLoaderOutput('{"kind":"method"}\ngo: downloading', REPO)).toThrow('line 2: not JSON');
+  });
+});
+
+describe('mergeGoTypeFacts', () => {
+  test('should keep the facts every platform load shares once', () => {
+    const shared = parseLoaderOutput(OUTPUT, REPO);
+    const windows = parseLoaderOutput(
+      '{"kind":"method","file":"/work/shop/store/disk_windows.go","line":7,"end_line":9,"name":"Sync",' +
+        '"package":"example.com/shop/store","receiver":"Disk","pointer":true}',
+      REPO
+    );
+    const merged = mergeGoTypeFacts([shared, { ...windows, references: shared.references }]);
+
+    expect(merged.methods.map((method) => `${method.file_path}:${method.receiver}.${method.name}`)).toEqual([
+      'store/store.go:Memory.Get',
+      'store/disk_windows.go:Disk.Sync',
+    ]);
+    expect(merged.implementations).toHaveLength(1);
+    expect(merged.references).toHaveLength(1);
+  });
+});
@@ -0,0 +1,134 @@
+/**
+ * Unit tests for Go workspace and module detection
+ */
+
+import { describe, test, expect, beforeAll, afterAll } from '@jest/globals';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import {
+  detectGoModules,
+  findGoWorkspaceRoot,
+  goModuleDependencies,
+  goModuleOf,
+  parseGoMod,
+  parseGoWork,
+} from '../../../src/indexing/go-workspace';
+import { MemoryTree } from '../../../src/indexing/memory-tree';
+
+describe('parseGoWork', () => {
+  test('should read use directives, single and grouped', () => {
+    const work = `go 1.22
+
+use (
+\t./api // HTTP service
+\t"./shared"
+)
+use ./tools
+
+replace github.com/lib/pq => ./third_party/pq
+`;
+    expect(parseGoWork(work)).toEqual(['./api', './shared', './tools']);
+  });
+});
+
+describe('parseGoMod', () => {
+  test('should read the module, Go version, requirements, and local replacements', () => {
+    const mod = `module github.com/acme/shop/api
+
+go 1.22
+
+require github.com/acme/shop/shared v0.0.0
+
+require (
+\tgithub.com/lib/pq v1.10.9
+\tgolang.org/x/text v0.14.0 // indirect
+)
+
+replace github.com/acme/shop/shared => ../shared
+replace golang.org/x/text v0.14.0 => golang.org/x/text v0.13.0
+`;
+    expect(parseGoMod(mod)).toEqual({
+      module: 'github.com/acme/shop/api',
+      go_version: '1.22',
+      requires: [
+        { path: 'github.com/acme/shop/shared', version: 'v0.0.0' },
+        { path: 'github.com/lib/pq', version: 'v1.10.9' },
+        { path: 'golang.org/x/text', version: 'v0.14.0' },
+      ],
+      replacements: [{ path: 'github.com/acme/shop/shared', directory: '../shared' }],
+    });
+    expect(parseGoMod('go 1.22\n').module).toBeNull();
+  });
+});
+
+describe('detectGoModules', () => {
+  let repoPath: string;
+  const write = (relative: string, content: string): void => {
+    fs.mkdirSync(path.dirname(path.join(repoPath, relative)), { recursive: true });
+    fs.writeFileSync(path.join(repoPath, relative), content);
+  };
+
+  beforeAll(() => {
+    repoPath = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-gowork-'));
+    write('api/go.mod', 'module github.com/acme/shop/api\n\nrequire github.com/acme/shop/shared v0.0.0\n');
+    write('api/handler/orders.go', 'package handler\n');
+    write('shared/go.mod', 'module github.com/acme/shop/shared\n');
+    write('shared/money/money.go', 'package money\n');
+    write('shared/testdata/fixture/go.mod', 'module example.com/fixture\n');
+    write('tools/go.mod', 'module github.com/acme/shop/tools\n\nreplace github.com/acme/shop/shared => ../shared\n');
+    write('tools/gen.go', 'package main\n');
+  });
+
+  afterAll(() => {
+    fs.rmSync(repoPath, { recursive: true, force: true });
+  });
+
+  test('should find every go.mod above discovered files without a go.work', async () => {
+    const files = ['api/handler/orders.go', 'shared/money/money.go', 'shared/testdata/fixture/a.go', 'tools/gen.go'];
+    const modules = await detectGoModules(repoPath, files);
+
+    expect(modules.map((module) => [module.directory, module.module_path])).toEqual([
+      ['api', 'github.com/acme/shop/api'],
+      ['shared', 'github.com/acme/shop/shared'],
+      ['tools', 'github.com/acme/shop/tools'],
+    ]);
+    expect(goModuleOf(modules, 'shared/money/money.go')?.module_path).toBe('github.com/acme/shop/shared');
+    expect(goModuleOf(modules, 'README.md')).toBeNull();
+    const dependencies = goModuleDependencies(modules);
+    expect(dependencies.map(({ from, to, version }) => [from.directory, to.directory, version])).toEqual([
+      ['api', 'shared', 'v0.0.0'],
+      ['tools', 'shared', 'shared'],
+    ]);
+  });
+
+  test('should take the members of a go.work', async () => {
+    write('go.work', 'go 1.22\n\nuse (\n\t./api\n\t./shared\n\t../outside\n)\n');
+    try {
+      const modules = await detectGoModules(repoPath, ['tools/gen.go']);
+      expect(modules.map((module) => module.directory)).toEqual(['api', 'shared']);
+
+      expect(await findGoWorkspaceRoot(path.join(repoPath, 'api', 'handler'))).toBe(repoPath);
+      expect(await findGoWorkspaceRoot(path.join(repoPath, 'tools'))).toBeNull();
+      expect(await findGoWorkspaceRoot(repoPath)).toBeNull();
+    } finally {
+      fs.rmSync(path.join(repoPath, 'go.work'));
+    }
+  });
+
+  test('should read go.mod files from a memory tree instead of the root', async () => {
+    const tree = new MemoryTree(
+      [
+        ['go.work', 'go 1.22\n\nuse ./api\n'],
+        ['api/go.mod', 'module github.com/acme/shop/api\n'],
+        ['shared/go.mod', 'module github.com/acme/shop/shared\n'],
+      ].map(([file, content]) => ({ path: file, content: Buffer.from(content), modified_time: new Date(0) }))
+    );
+
+    const modules = await detectGoModules('/nonexistent', ['api/main.go', 'shared/money.go'], tree);
+
+    expect(modules.map((module) => [module.directory, module.module_path])).toEqual([
+      ['api', 'github.com/acme/shop/api'],
+    ]);
+  });
+});
@@ -0,0 +1,192 @@
+/**
+ * Unit tests for index locks and shard generations
+ */
+
+import { spawnSync } from 'node:child_process';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+
+import { afterEach, describe, test, expect } from '@jest/globals';
+import {
+  acquireIndexLock,
+  acquireReadLock,
+  beginGeneration,
+  hasChangedSince,
+  publishGeneration,
+  readGeneration,
+  removeStaleLock,
+  SHARD_COUNT,
+  shardOf,
+  type LockHolder,
+} from '../../../src/indexing/index-lock';
+
+const REPO_ID = `shard-test-${String(process.pid)}`;
+const LOCK_FILE = path.join(os.homedir(), '.cindex', 'locks', `${REPO_ID}.lock`);
+const READERS_DIR = path.join(os.homedir(), '.cindex', 'locks', `${REPO_ID}.readers`);
+const GENERATION_FILE = path.join(os.homedir(), '.cindex', 'locks', `${REPO_ID}.generation`);
+
+/**
+ * Holder of a lock file, by default a process that has exited
+ */
+const holderOf = (pid = spawnSync(process.execPath, ['-e', '']).pid ?? 0): LockHolder => ({
+  pid,
+  hostname: os.hostname(),
+  started_at: new Date().toISOString(),
+  command: 'cindex index',
+});
+
+/**
+ * Write a lock file as its holder would
+ */
+const writeLock = (holder: LockHolder): void => {
+  fs.mkdirSync(path.dirname(LOCK_FILE), { recursive: true });
+  fs.writeFileSync(LOCK_FILE, JSON.stringify(holder) + '\n');
+};
+
+afterEach(() => {
+  fs.rmSync(LOCK_FILE, { force: true });
+  fs.rmSync(GENERATION_FILE, { force: true });
+  fs.rmSync(READERS_DIR, { recursive: true, force: true });
+});
+
+describe('acquireIndexLock', () => {
+  test('should take over a lock left by a dead process', async () => {
+    writeLock(holderOf());
+
+    const lock = await acquireIndexLock(REPO_ID);
+
+    expect(JSON.parse(fs.readFileSync(LOCK_FILE, 'utf-8'))).toMatchObject({ pid: process.pid });
+    lock.release();
+    expect(fs.existsSync(LOCK_FILE)).toBe(false);
+  });
+});
+
+describe('acquireReadLock', () => {
+  test('should register a reader until it is released', async () => {
+    const lock = await acquireReadLock(REPO_ID);
+
+    expect(fs.readdirSync(READERS_DIR)).toEqual([path.basename(lock.file)]);
+    lock.release();
+    lock.release();
+    expect(fs.readdirSync(READERS_DIR)).toEqual([]);
+  });
+
+  test('should make an exclusive writer wait for active readers, and drop dead ones', async () => {
+    const reader = await acquireReadLock(REPO_ID);
+    fs.writeFileSync(path.join(READERS_DIR, 'crashed.lock'), JSON.stringify(holderOf()) + '\n');
+    let acquired = false;
+
+    const writer = acquireIndexLock(REPO_ID, false, true).then((lock) => {
+      acquired = true;
+      return lock;
+    });
+    await new Promise((resolve) => setTimeout(resolve, 200));
+    expect(acquired).toBe(false);
+    reader.release();
+    const lock = await writer;
+
+    expect(fs.readdirSync(READERS_DIR)).toEqual([]);
+    lock.release();
+  });
+});
+
+describe('generations', () => {
+  test('should count finished writes and report whether one is running', () => {
+    expect(readGeneration(REPO_ID)).toBeNull();
+
+    beginGeneration(REPO_ID);
+    expect(readGeneration(REPO_ID)).toMatchObject({ generation: 0, writing: true });
+    expect(publishGeneration(REPO_ID)).toBe(1);
+    expect(readGeneration(REPO_ID)).toMatchObject({ generation: 1, writing: false });
+    expect(publishGeneration(REPO_ID)).toBe(2);
+  });
+
+  test('should not report a writer that died as writing', () => {
+    const dead = holderOf();
+    fs.mkdirSync(path.dirname(GENERATION_FILE), { recursive: true });
+    fs.writeFileSync(GENERATION_FILE, JSON.stringify({ generation: 3, writing: true, ...dead, updated_at: '' }));
+
+    expect(readGeneration(REPO_ID)).toMatchObject({ generation: 3, writing: false });
+  });
+
+  test('should tell a whole-index read that a write finished since it began', async () => {
+    const lock = await acquireReadLock(REPO_ID);
+    expect(hasChangedSince(REPO_ID, lock)).toBe(false);
+
+    beginGeneration(REPO_ID);
+    expect(hasChangedSince(REPO_ID, lock)).toBe(false);
+    publishGeneration(REPO_ID);
+    expect(hasChangedSince(REPO_ID, lock)).toBe(true);
+    lock.release();
+  });
+});
+
+describe('removeStaleLock', () => {
+  test('should leave a lock another waiter took over after the dead holder was read', () => {
+    const dead = holderOf();
+    writeLock(dead);
+    // The first waiter of two that read the dead holder removes it and creates its own lock
+    expect(removeStaleLock(LOCK_FILE, dead)).toBe(true);
+    const first = holderOf(process.pid);
+    writeLock(first);
+
+    // The second finds the first's lock instead of the dead one
+    expect(removeStaleLock(LOCK_FILE, dead)).toBe(true);
+
+    expect(JSON.parse(fs.readFileSync(LOCK_FILE, 'utf-8'))).toEqual(first);
+  });
+
+  test('should wait while another waiter is taking over', () => {
+    const dead = holderOf();
+    writeLock(dead);
+    fs.writeFileSync(`${LOCK_FILE}.takeover`, JSON.stringify(holderOf(process.pid)) + '\n');
+
+    try {
+      expect(removeStaleLock(LOCK_FILE, dead)).toBe(false);
+      expect(fs.existsSync(LOCK_FILE)).toBe(true);
+    } finally {
+      fs.rmSync(`${LOCK_FILE}.takeover`, { force: true });
+    }
+  });
+});
+
+describe('shardOf', () => {
+  test('should keep a package in one shard', () => {
+    expect(shardOf('internal/auth/login.go')).toBe(shardOf('internal/auth/session.go'));
+    expect(shardOf('internal\\auth\\login.go')).toBe(shardOf('internal/auth/login.go'));
+    expect(shardOf('main.go')).toBeLessThan(SHARD_COUNT);
+  });
+});
+
+describe('hasChangedSince', () => {
+  // Two packages in different shards
+  const auth = 'internal/auth/login.go';
+  const other = ['internal/api/handler.go', 'internal/store/db.go', 'cmd/app/main.go'].find(
+    (file) => shardOf(file) !== shardOf(auth)
+  ) as string;
+
+  test('should only see changes to the shards a scoped read covers', async () => {
+    publishGeneration(REPO_ID);
+    const scoped = await acquireReadLock(REPO_ID, [auth]);
+    const whole = await acquireReadLock(REPO_ID);
+
+    publishGeneration(REPO_ID, [other]);
+    expect(hasChangedSince(REPO_ID, scoped)).toBe(false);
+    expect(hasChangedSince(REPO_ID, whole)).toBe(true);
+
+    publishGeneration(REPO_ID, [auth]);
+    expect(hasChangedSince(REPO_ID, scoped)).toBe(true);
+    scoped.release();
+    whole.release();
+  });
+
+  test('should see a full run as a change to every shard', async () => {
+    publishGeneration(REPO_ID, [other]);
+    const scoped = await acquireReadLock(REPO_ID, [auth]);
+
+    publishGeneration(REPO_ID);
+    expect(hasChangedSince(REPO_ID, scoped)).toBe(true);
+    scoped.release();
+  });
+});
@@ -0,0 +1,127 @@
+/**
+ * Unit tests for index integrity checks: corrupt files, quarantine, and rebuild targets
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { type DatabaseClient } from '../../../src/database/client';
+import {
+  computeChunkChecksum,
+  findCorruptFiles,
+  findRebuildTargets,
+  quarantineFiles,
+  verifyIndexIntegrity,
+} from '../../../src/indexing/integrity';
+
+/**
+ * Database client recording its queries, answering each with the rows of the first matching handler
+ */
+const clientOf = (
+  handlers: [RegExp, unknown[]][] = []
+): { db: DatabaseClient; queries: { sql: string; params: unknown[] }[] } => {
+  const queries: { sql: string; params: unknown[] }[] = [];
+  const db = {
+    query: (sql: string, params: unknown[] = []) => {
+      queries.push({ sql, params });
+      const rows = handlers.find(([pattern]) => pattern.test(sql))?.[1] ?? [];
+      return Promise.resolve({ rows, rowCount: rows.length });
+    },
+  } as unknown as DatabaseClient;
+  return { db, queries };
+};
+
+const INTEGRITY_ROWS = [
+  { repo_id: 'api', repo_path: '/src/api', file_path: 'auth/login.go', chunk_count: 4, stored_chunks: 1 },
+  { repo_id: 'api', repo_path: '/src/api', file_path: 'auth/token.go', chunk_count: 2, stored_chunks: 2 },
+];
+
+describe('computeChunkChecksum', () => {
+  test('should not depend on chunk order', () => {
+    expect(computeChunkChecksum(['a', 'b'])).toBe(computeChunkChecksum(['b', 'a']));
+    expect(computeChunkChecksum(['a', 'b'])).not.toBe(computeChunkChecksum(['a', 'c']));
+  });
+});
+
+describe('findCorruptFiles', () => {
+  test('should tell truncated files from checksum mismatches', async () => {
+    const { db, queries } = clientOf([[/FROM code_files f/, INTEGRITY_ROWS]]);
+
+    const corrupt = await findCorruptFiles(db, '/src/api');
+
+    expect(corrupt).toEqual([
+      {
+        repo_id: 'api',
+        repo_path: '/src/api',
+        file_path: 'auth/login.go',
+        expected_chunks: 4,
+        stored_chunks: 1,
+        reason: 'truncated',
+      },
+      {
+        repo_id: 'api',
+        repo_path: '/src/api',
+        file_path: 'auth/token.go',
+        expected_chunks: 2,
+        stored_chunks: 2,
+        reason: 'checksum_mismatch',
+      },
+    ]);
+    expect(queries[0].sql).toContain('f.quarantined_at IS NULL AND f.repo_path = $1');
+    expect(queries[0].params).toEqual(['/src/api']);
+  });
+});
+
+describe('quarantineFiles', () => {
+  test('should drop the chunks and symbols of the files and flag their records', async () => {
+    const { db, queries } = clientOf();
+
+    await quarantineFiles(db, [{ file_path: 'auth/login.go' }]);
+
+    const statements = queries.map((query) => query.sql);
+    expect(statements).toContain('DELETE FROM code_chunks WHERE file_path = ANY($1::text[])');
+    expect(statements).toContain('DELETE FROM code_symbols WHERE file_path = ANY($1::text[])');
+    expect(queries.at(-1)).toEqual({
+      sql: 'UPDATE code_files SET quarantined_at = NOW() WHERE file_path = ANY($1::text[])',
+      params: [['auth/login.go']],
+    });
+  });
+
+  test('should do nothing without files', async () => {
+    const { db, queries } = clientOf();
+
+    await quarantineFiles(db, []);
+    expect(await verifyIndexIntegrity(db)).toEqual([]);
+
+    expect(queries.map((query) => query.sql.trim().split(/\s+/)[0])).toEqual(['SELECT']);
+  });
+});
+
+describe('findRebuildTargets', () => {
+  test('should return each repository once, with its stored settings', async () => {
+    const stored = { repo_name: 'API', repo_type: 'microservice', metadata: { team: 'auth' } };
+    const { db, queries } = clientOf([[/FROM code_files f/, INTEGRITY_ROWS], [/FROM repositories/, [stored]]]);
+    const corrupt = [
+      ...(await findCorruptFiles(db)),
+      {
+        ...INTEGRITY_ROWS[0],
+        repo_id: null,
+        repo_path: '/src/legacy',
+        expected_chunks: 4,
+        reason: 'truncated' as const,
+      },
+    ];
+
+    const targets = await findRebuildTargets(db, corrupt);
+
+    expect(targets).toEqual([
+      { repo_path: '/src/api', repo_id: 'api', ...stored },
+      {
+        repo_path: '/src/legacy',
+        repo_id: undefined,
+        repo_name: undefined,
+        repo_type: undefined,
+        metadata: undefined,
+      },
+    ]);
+    expect(queries.filter((query) => query.sql.includes('FROM repositories'))).toHaveLength(1);
+  });
+});
@@ -0,0 +1,90 @@
+/**
+ * Unit tests for file and directory license detection
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  detectFileLicense,
+  identifyLicenseText,
+  isLicenseFileName,
+  resolveFileLicense,
+} from '../../../src/indexing/license-detector';
+import { type LicenseFile } from '../../../src/types/indexing';
+
+describe('detectFileLicense', () => {
+  test('should read SPDX identifiers in any comment style', () => {
+    expect(detectFileLicense('// SPDX-License-Identifier: MIT\nexport const a = 1;')).toEqual({
+      license: 'MIT',
+      source: 'spdx',
+    });
+    expect(detectFileLicense('/* SPDX-License-Identifier: Apache-2.0 OR MIT */\nint x;')?.license).toBe(
+      'Apache-2.0 OR MIT'
+    );
+    expect(detectFileLicense('#!/usr/bin/env python\n# SPDX-License-Identifier: GPL-2.0-only\n')?.license).toBe(
+      'GPL-2.0-only'
+    );
+  });
+
+  test('should recognize a license notice spread over comment lines', () => {
+    const content = [
+      '/*',
+      ' * Licensed under the Apache License, Version 2.0 (the "License");',
+      ' * you may not use this file except in compliance with the License.',
+      ' */',
+      'package main',
+    ].join('\n');
+
+    expect(detectFileLicense(content)).toEqual({ license: 'Apache-2.0', source: 'header' });
+  });
+
+  test('should ignore notices past the header', () => {
+    const content = 'x = 1\n'.repeat(40) + '# SPDX-License-Identifier: MIT\n';
+
+    expect(detectFileLicense(content)).toBeNull();
+  });
+});
+
+describe('identifyLicenseText', () => {
+  test('should tell the BSD variants apart', () => {
+    const bsd2 = 'Redistribution and use in source and binary forms, with or without modification, are permitted';
+    const bsd3 = `${bsd2}\n3. Neither the name of the copyright holder nor the names of its contributors`;
+
+    expect(identifyLicenseText(bsd2)).toBe('BSD-2-Clause');
+    expect(identifyLicenseText(bsd3)).toBe('BSD-3-Clause');
+    expect(identifyLicenseText('All rights reserved.')).toBeNull();
+  });
+});
+
+describe('isLicenseFileName', () => {
+  test('should match common license file names', () => {
+    expect(['LICENSE', 'LICENCE.md', 'COPYING.txt', 'LICENSE-MIT', 'license'].every(isLicenseFileName)).toBe(true);
+    expect(['LICENSES.json', 'license-checker.ts', 'README.md'].some(isLicenseFileName)).toBe(false);
+  });
+});
+
+describe('resolveFileLicense', () => {
+  const directories = new Map<string, LicenseFile>([
+    ['', { path: 'LICENSE', license: 'MIT' }],
+    ['vendor/lib', { path: 'vendor/lib/COPYING', license: 'GPL-3.0' }],
+    ['third_party', { path: 'third_party/LICENSE', license: null }],
+  ]);
+
+  test('should prefer the file header', () => {
+    const header = { license: 'Apache-2.0', source: 'spdx' as const };
+
+    expect(resolveFileLicense('vendor/lib/a.c', header, directories)).toBe(header);
+  });
+
+  test('should inherit the nearest recognized directory license', () => {
+    expect(resolveFileLicense('vendor/lib/src/a.c', null, directories)).toEqual({
+      license: 'GPL-3.0',
+      source: 'directory',
+    });
+    expect(resolveFileLicense('third_party/x.go', null, directories)?.license).toBe('MIT');
+    expect(resolveFileLicense('main.go', null, directories)?.license).toBe('MIT');
+  });
+
+  test('should return null without a header or license file', () => {
+    expect(resolveFileLicense('src/a.ts', null, new Map())).toBeNull();
+  });
+});
@@ -0,0 +1,75 @@
+/**
+ * Unit tests for staticcheck and golangci-lint report parsing
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { parseLintReport } from '../../../src/indexing/lint-report';
+
+describe('parseLintReport', () => {
+  test('should read staticcheck JSON lines and skip ignored diagnostics', () => {
+    const text = [
+      JSON.stringify({
+        code: 'SA4006',
+        severity: 'error',
+        location: { file: '/home/dev/shop/internal/auth/login.go', line: 12, column: 2 },
+        end: { file: '/home/dev/shop/internal/auth/login.go', line: 12, column: 9 },
+        message: 'this value of err is never used',
+      }),
+      JSON.stringify({
+        code: 'ST1003',
+        severity: 'ignored',
+        location: { file: '/home/dev/shop/internal/auth/login.go', line: 30, column: 6 },
+        message: 'should not use underscores in Go names',
+      }),
+    ].join('\n');
+
+    expect(parseLintReport(text)).toEqual({
+      tool: 'staticcheck',
+      issues: [
+        {
+          file: '/home/dev/shop/internal/auth/login.go',
+          line: 12,
+          column: 2,
+          end_line: 12,
+          linter: 'staticcheck',
+          rule: 'SA4006',
+          severity: 'error',
+          message: 'this value of err is never used',
+        },
+      ],
+    });
+  });
+
+  test('should read golangci-lint issues and split check codes from messages', () => {
+    const report = {
+      Issues: [
+        {
+          FromLinter: 'errcheck',
+          Text: 'Error return value of `conn.Close` is not checked',
+          Severity: '',
+          Pos: { Filename: 'internal/db/pool.go', Line: 40, Column: 13 },
+        },
+        {
+          FromLinter: 'staticcheck',
+          Text: 'SA1019: grpc.WithInsecure is deprecated',
+          Severity: 'error',
+          Pos: { Filename: 'cmd/server/main.go', Line: 7, Column: 2 },
+        },
+      ],
+      Report: {},
+    };
+
+    const { tool, issues } = parseLintReport(JSON.stringify(report));
+
+    expect(tool).toBe('golangci-lint');
+    expect(issues.map((issue) => [issue.linter, issue.rule, issue.severity, issue.message])).toEqual([
+      ['errcheck', 'errcheck', 'warning', 'Error return value of `conn.Close` is not checked'],
+      ['staticcheck', 'SA1019', 'error', 'grpc.WithInsecure is deprecated'],
+    ]);
+  });
+
+  test('should accept a clean golangci-lint run and reject other input', () => {
+    expect(parseLintReport('{"Issues": null}').issues).toEqual([]);
+    expect(() => parseLintReport('internal/auth/login.go:12:2: SA4006 never used')).toThrow('line 1');
+  });
+});
@@ -0,0 +1,82 @@
+/**
+ * Unit tests for the literal index: string literals and non-doc comments
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { type AnnotatedSymbol } from '../../../src/indexing/annotations';
+import { extractStrings } from '../../../src/indexing/file-metrics';
+import { extractLiterals, MAX_LITERAL_LENGTH } from '../../../src/indexing/literals';
+
+/**
+ * Symbol spanning lines of a test file
+ */
+const symbol = (symbol_name: string, symbol_type: string, line_number: number, end_line: number): AnnotatedSymbol =>
+  ({ symbol_name, symbol_type, line_number, end_line }) as AnnotatedSymbol;
+
+describe('extractStrings', () => {
+  test('should list strings as written, with the column of the opening quote', () => {
+    const content = [
+      'const msg = "user not found";',
+      "const sql = 'INSERT INTO \\'sessions\\''; // \"not a string\"",
+    ].join('\n');
+
+    expect(extractStrings(content, 'typescript')).toEqual([
+      { line: 1, column: 13, text: 'user not found' },
+      { line: 2, column: 13, text: "INSERT INTO \\'sessions\\'" },
+    ]);
+  });
+
+  test('should skip empty strings and keep the first line of strings that continue', () => {
+    const content = ['x := ""', 'q := `SELECT id', 'FROM users`'].join('\n');
+
+    expect(extractStrings(content, 'go')).toEqual([{ line: 2, column: 6, text: 'SELECT id' }]);
+  });
+});
+
+describe('extractLiterals', () => {
+  test('should index strings and comments but not doc comments', () => {
+    const content = [
+      '// Login checks a password',
+      'func Login() error {',
+      '\t// the lock may be stale',
+      '\treturn errors.New("user not found") // retry once',
+      '}',
+    ].join('\n');
+
+    const literals = extractLiterals(content, 'go', [symbol('Login', 'function', 2, 5)]);
+
+    expect(literals).toEqual([
+      { kind: 'comment', text: 'the lock may be stale', line: 3, column: 2, byte_column: 2 },
+      { kind: 'string', text: 'user not found', line: 4, column: 20, byte_column: 20 },
+      { kind: 'comment', text: 'retry once', line: 4, column: 38, byte_column: 38 },
+    ]);
+  });
+
+  test('should treat comments above decorators as doc comments', () => {
+    const content = ['/** Session store */', '@Injectable()', 'class Sessions {}', '', '/* license */'].join('\n');
+
+    const literals = extractLiterals(content, 'typescript', [symbol('Sessions', 'class', 3, 3)]);
+
+    expect(literals).toEqual([{ kind: 'comment', text: 'license', line: 5, column: 1, byte_column: 1 }]);
+  });
+
+  test('should keep every comment of a file without symbols', () => {
+    const content = '# setup the fixtures\nname = "élan"  # café';
+
+    const literals = extractLiterals(content, 'python', []);
+
+    expect(literals).toEqual([
+      { kind: 'comment', text: 'setup the fixtures', line: 1, column: 1, byte_column: 1 },
+      { kind: 'string', text: 'élan', line: 2, column: 8, byte_column: 8 },
+      { kind: 'comment', text: 'café', line: 2, column: 16, byte_column: 17 },
+    ]);
+  });
+
+  test('should cut long literals', () => {
+    const content = `const big = "${'x'.repeat(MAX_LITERAL_LENGTH + 10)}";`;
+
+    const [literal] = extractLiterals(content, 'typescript', []);
+
+    expect(literal.text).toHaveLength(MAX_LITERAL_LENGTH);
+  });
+});
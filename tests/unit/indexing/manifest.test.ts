@@ -0,0 +1,76 @@
+/**
+ * Unit tests for index manifests
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { buildManifest, diffManifests, recordedManifest, withoutManifest } from '../../../src/indexing/manifest';
+
+const CREATED = new Date('2026-01-01T00:00:00Z');
+
+const LEAVES = [
+  { file_path: 'main.go', file_hash: 'a1', chunk_checksum: 'c1' },
+  { file_path: 'internal/auth/login.go', file_hash: 'a2', chunk_checksum: 'c2' },
+  { file_path: 'internal/auth/session.go', file_hash: 'a3', chunk_checksum: 'c3' },
+  { file_path: 'internal/store/db.go', file_hash: 'a4', chunk_checksum: null },
+];
+
+describe('buildManifest', () => {
+  test('should record a shard per directory, parents included', () => {
+    const manifest = buildManifest(LEAVES, CREATED);
+
+    expect(Object.keys(manifest.shards)).toEqual(['.', 'internal', 'internal/auth', 'internal/store']);
+    expect(manifest.shards['internal/auth'].files).toBe(2);
+    expect(manifest.shards.internal.files).toBe(0);
+    expect(manifest.root).toBe(manifest.shards['.'].hash);
+    expect(manifest.files).toBe(4);
+  });
+
+  test('should not depend on record order', () => {
+    expect(buildManifest([...LEAVES].reverse(), CREATED)).toEqual(buildManifest(LEAVES, CREATED));
+  });
+
+  test('should change the hashes up to the root when a record changes', () => {
+    const before = buildManifest(LEAVES, CREATED);
+    const after = buildManifest(
+      LEAVES.map((leaf) => (leaf.file_path === 'internal/auth/login.go' ? { ...leaf, chunk_checksum: 'x' } : leaf)),
+      CREATED
+    );
+
+    expect(after.root).not.toBe(before.root);
+    expect(after.shards.internal.hash).not.toBe(before.shards.internal.hash);
+    expect(after.shards.internal.content).toBe(before.shards.internal.content);
+    expect(after.shards['internal/store']).toEqual(before.shards['internal/store']);
+  });
+});
+
+describe('diffManifests', () => {
+  test('should report no damage when the roots match', () => {
+    expect(diffManifests(buildManifest(LEAVES, CREATED), buildManifest(LEAVES))).toEqual([]);
+  });
+
+  test('should name changed, missing, and unexpected shards', () => {
+    const recorded = buildManifest(LEAVES, CREATED);
+    const current = buildManifest([
+      { file_path: 'main.go', file_hash: 'a1', chunk_checksum: 'c1' },
+      { file_path: 'internal/auth/login.go', file_hash: 'a2', chunk_checksum: 'c2' },
+      { file_path: 'tmp/left.go', file_hash: 'a5', chunk_checksum: 'c5' },
+    ]);
+
+    expect(diffManifests(recorded, current)).toEqual([
+      { directory: 'internal/auth', reason: 'changed', expected_files: 2, stored_files: 1 },
+      { directory: 'internal/store', reason: 'missing', expected_files: 1, stored_files: 0 },
+      { directory: 'tmp', reason: 'unexpected', expected_files: 0, stored_files: 1 },
+    ]);
+  });
+});
+
+describe('recordedManifest', () => {
+  test('should read a manifest of this version only', () => {
+    const manifest = buildManifest(LEAVES, CREATED);
+
+    expect(recordedManifest({ manifest })).toEqual(manifest);
+    expect(recordedManifest({ manifest: { ...manifest, version: 99 } })).toBeNull();
+    expect(recordedManifest(withoutManifest({ manifest, branch: 'main' }))).toBeNull();
+    expect(recordedManifest(null)).toBeNull();
+  });
+});
@@ -0,0 +1,109 @@
+/**
+ * Unit tests for packed content indexes (cindex export --format=packed, cindex grep --packed)
+ */
+
+import { afterAll, beforeAll, describe, test, expect } from '@jest/globals';
+import * as fs from 'node:fs';
+import * as os from 'node:os';
+import * as path from 'node:path';
+import {
+  openPackedIndex,
+  PACKED_BLOCK_SIZE,
+  PACKED_MAGIC,
+  PACKED_RECORDS_PER_BLOCK,
+  PackedIndexWriter,
+  type PackedIndex,
+} from '../../../src/indexing/packed-index';
+import { searchPackedContent } from '../../../src/retrieval/content-search';
+
+/**
+ * Files of a generated repository: more than one block of every section
+ */
+const FILES = Array.from({ length: PACKED_RECORDS_PER_BLOCK + 200 }, (_, index) => ({
+  file_path: `pkg/mod${String(index % 40)}/file${String(index)}.go`,
+  content: `package mod\n\n// Handler${String(index)} serves requests\nfunc Handler${String(index)}() {}\n`,
+}));
+
+/** A file larger than a contents block, and one with non-ASCII text */
+const LARGE = { file_path: 'assets/large.go', content: `${'x'.repeat(PACKED_BLOCK_SIZE * 2)}\nfunc Huge() {}\n` };
+const ACCENTED = { file_path: 'i18n/fr.go', content: 'const Greeting = "Élan vital"\n' };
+
+describe('packed index', () => {
+  let dir: string;
+  let file: string;
+  let index: PackedIndex;
+
+  beforeAll(async () => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), 'cindex-packed-'));
+    file = path.join(dir, 'api.cidx');
+    const parts: Buffer[] = [];
+    const writer = new PackedIndexWriter(async (bytes) => {
+      await Promise.resolve();
+      parts.push(bytes);
+    }, 'api');
+    for (const { file_path, content } of [...FILES, LARGE, ACCENTED]) await writer.add(file_path, content);
+    await writer.end();
+    fs.writeFileSync(file, Buffer.concat(parts));
+    index = await openPackedIndex(file, { cacheBytes: PACKED_BLOCK_SIZE * 4 });
+  });
+
+  afterAll(async () => {
+    await index.close();
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  test('should be much smaller than the contents, starting and ending with the magic', () => {
+    const bytes = fs.readFileSync(file);
+    const contents = [...FILES, LARGE, ACCENTED].reduce((sum, { content }) => sum + content.length, 0);
+
+    expect(bytes.subarray(0, PACKED_MAGIC.length)).toEqual(PACKED_MAGIC);
+    expect(bytes.subarray(-PACKED_MAGIC.length)).toEqual(PACKED_MAGIC);
+    expect(bytes.length).toBeLessThan(contents / 4);
+    expect(index.metadata).toMatchObject({ repo_id: 'api', files: FILES.length + 2 });
+  });
+
+  test('should read files and contents by ID, across blocks', async () => {
+    const last = await index.file(FILES.length - 1);
+    expect(last.file_path).toBe(FILES.at(-1)?.file_path);
+    expect(await index.readContent(last)).toBe(FILES.at(-1)?.content);
+
+    const large = await index.file(FILES.length);
+    expect(large.file_path).toBe('assets/large.go');
+    expect(await index.readContent(large)).toBe(LARGE.content);
+    await expect(index.file(FILES.length + 2)).rejects.toThrow('No file');
+  });
+
+  test('should narrow candidates to the files holding every trigram', async () => {
+    expect(await index.candidates(['Handler1037('])).toEqual([1037]);
+    expect(await index.candidates(['handler1037'], { ignoreCase: true })).toEqual([1037]);
+    expect(await index.candidates(['NoSuchSymbol'])).toEqual([]);
+    expect(await index.candidates(['ab'])).toBeNull();
+  });
+
+  test('should search like grep through the candidate files', async () => {
+    const { matches, truncated } = await searchPackedContent(index, 'func Handler10[0-9]\\(', { limit: 5 });
+
+    expect(truncated).toBe(true);
+    expect(matches.map((match) => [match.repo_id, match.file_path, match.line, match.column])).toEqual([
+      ['api', 'pkg/mod20/file100.go', 4, 1],
+      ['api', 'pkg/mod21/file101.go', 4, 1],
+      ['api', 'pkg/mod22/file102.go', 4, 1],
+      ['api', 'pkg/mod23/file103.go', 4, 1],
+      ['api', 'pkg/mod24/file104.go', 4, 1],
+    ]);
+    const huge = await searchPackedContent(index, 'HUGE', { ignoreCase: true, pathPrefix: 'assets/' });
+    expect(huge.matches.map((match) => match.text)).toEqual(['func Huge() {}']);
+    const accented = await searchPackedContent(index, 'élan', { ignoreCase: true });
+    expect(accented.matches.map((match) => match.file_path)).toEqual(['i18n/fr.go']);
+  });
+
+  test('should reject files that are not packed indexes', async () => {
+    const other = path.join(dir, 'other.snapshot');
+    fs.writeFileSync(other, '{"format":"cindex-snapshot"}\n');
+    await expect(openPackedIndex(other)).rejects.toThrow('Not a packed index');
+
+    const truncated = path.join(dir, 'truncated.cidx');
+    fs.writeFileSync(truncated, fs.readFileSync(file).subarray(0, 4096));
+    await expect(openPackedIndex(truncated)).rejects.toThrow('no trailer');
+  });
+});
@@ -379,6 +379,22 @@ describe('CodeParser', () => {
       expect(permissionChecker).toBeDefined();
     });
 
+    test('should extract Go struct fields and method receivers', async () => {
+      const samplePath = path.join(FIXTURES_PATH, 'sample.go');
+      const code = await fs.readFile(samplePath, 'utf-8');
+
+      const parser = new CodeParser(Language.Go);
+      const result = parser.parse(code, samplePath);
+
+      const user = result.nodes.find((n) => n.node_type === NodeType.Class && n.name === 'User');
+      const fields = (user?.children ?? []).filter((n) => n.node_type === NodeType.Field);
+      expect(fields.map((f) => f.name)).toEqual(['ID', 'Email', 'PasswordHash', 'Role']);
+      expect(fields[3]).toMatchObject({ return_type: 'UserRole', start_line: 22 });
+
+      const login = result.nodes.find((n) => n.node_type === NodeType.Method && n.name === 'Login');
+      expect(login?.receiver).toBe('AuthService');
+    });
+
     test('should extract Go imports', async () => {
       const samplePath = path.join(FIXTURES_PATH, 'sample.go');
       const code = await fs.readFile(samplePath, 'utf-8');
@@ -640,8 +656,47 @@ describe('CodeParser', () => {
     });
   });
 
+  describe('partial parsing', () => {
+    const code = [
+      'export function complete(a: number): number {',
+      '  return a + 1;',
+      '}',
+      '',
+      'export function draft(b: number) {',
+      '  const c = b +;',
+      '  return c;',
+      '}',
+      '',
+    ].join('\n');
+
+    test('should keep the declarations that parsed instead of falling back', () => {
+      const result = new CodeParser(Language.TypeScript).parse(code, 'draft.ts');
+
+      expect(result.success).toBe(true);
+      expect(result.used_fallback).toBe(false);
+      expect(result.partial).toBe(true);
+      expect(result.error_position?.line).toBe(6);
+    });
+
+    test('should keep a declaration whose error is inside its body', () => {
+      const result = new CodeParser(Language.TypeScript).parse(code, 'draft.ts');
+      const names = result.nodes.filter((n) => n.node_type === NodeType.Function).map((n) => n.name);
+
+      expect(names).toEqual(expect.arrayContaining(['complete', 'draft']));
+    });
+
+    test('should record the error column in UTF-16 code units and UTF-8 bytes', () => {
+      const accented = ['export function label() {', "  const text = 'ééé' +;", '  return text;', '}', ''].join('\n');
+      const position = new CodeParser(Language.TypeScript).parse(accented, 'label.ts').error_position;
+
+      expect(position?.line).toBe(2);
+      expect(position?.column).toBeGreaterThan(17);
+      expect(position?.byte_column).toBe((position?.column ?? 0) + 3);
+    });
+  });
+
   describe('fallback parsing', () => {
-    test('should use fallback for malformed code', async () => {
+    test('should not drop malformed code', async () => {
       const malformedPath = path.join(FIXTURES_PATH, 'malformed.js');
       const code = await fs.readFile(malformedPath, 'utf-8');
 
@@ -649,7 +704,8 @@ describe('CodeParser', () => {
       const result = parser.parse(code, malformedPath);
 
       expect(result.success).toBe(true);
-      expect(result.used_fallback).toBe(true);
+      expect(result.error).toBeDefined();
+      expect(result.nodes.length).toBeGreaterThan(0);
     });
 
     test('fallback should extract functions via regex', async () => {
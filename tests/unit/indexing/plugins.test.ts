@@ -0,0 +1,146 @@
+/**
+ * Unit tests for extractor and analyzer plugins, in process and over JSON-RPC
+ */
+
+import * as path from 'node:path';
+
+import { afterEach, describe, test, expect } from '@jest/globals';
+import { type AnnotatedSymbol } from '../../../src/indexing/annotations';
+import { splitCommand } from '../../../src/indexing/plugin-host';
+import {
+  closePlugins,
+  extractorForFile,
+  listPlugins,
+  loadPlugins,
+  registerAnalyzer,
+  registerExtractor,
+  runAnalyzers,
+  toParseResult,
+} from '../../../src/indexing/plugins';
+import { NodeType } from '../../../src/types/indexing';
+import { type ExtractorPlugin } from '../../../src/types/plugins';
+
+/**
+ * Extractor of .abap files finding nothing
+ */
+const abap = (name = 'abap'): ExtractorPlugin => ({
+  name,
+  language: 'abap',
+  extensions: ['.abap'],
+  extract: () => ({ symbols: [] }),
+});
+
+afterEach(async () => {
+  await closePlugins();
+});
+
+describe('registerExtractor', () => {
+  test('should claim its extensions case-insensitively', () => {
+    registerExtractor(abap());
+
+    expect(extractorForFile('src/ZREPORT.ABAP')?.name).toBe('abap');
+    expect(extractorForFile('src/report.go')).toBeUndefined();
+    expect(listPlugins()).toEqual([
+      { kind: 'extractor', name: 'abap', languages: ['abap'], extensions: ['.abap'], source: 'api' },
+    ]);
+  });
+
+  test('should reject extensions claimed by another extractor and malformed ones', () => {
+    registerExtractor(abap());
+
+    expect(() => registerExtractor(abap('abap-lite'))).toThrow('.abap is already read by abap');
+    expect(() => registerExtractor({ ...abap('cobol'), extensions: ['cbl'] })).toThrow('got cbl');
+    expect(() => registerAnalyzer({ name: 'noop', analyze: () => ({}) })).not.toThrow();
+    expect(() => registerAnalyzer({ name: 'noop', analyze: () => ({}) })).toThrow('already registered');
+  });
+});
+
+describe('toParseResult', () => {
+  test('should cut each symbol from the content and export the exported ones', () => {
+    const content = ['FORM main.', '  WRITE hello.', 'ENDFORM.', 'DATA count TYPE i.'].join('\n');
+
+    const result = toParseResult(
+      {
+        symbols: [
+          { name: 'main', kind: 'function', start_line: 1, end_line: 3, exported: true },
+          { name: 'count', kind: 'field', start_line: 4, end_line: 4 },
+        ],
+        imports: [{ source: 'zutils', line: 1 }],
+        error: 'unterminated block at line 9',
+      },
+      content
+    );
+
+    expect(result.nodes.map(({ node_type, name, code_text }) => ({ node_type, name, code_text }))).toEqual([
+      { node_type: NodeType.Function, name: 'main', code_text: 'FORM main.\n  WRITE hello.\nENDFORM.' },
+      { node_type: NodeType.Variable, name: 'count', code_text: 'DATA count TYPE i.' },
+    ]);
+    expect(result.imports).toEqual([
+      { symbols: [], source: 'zutils', is_default: false, is_namespace: false, line_number: 1 },
+    ]);
+    expect(result.exports).toEqual([{ symbols: ['main'], is_default: false, is_reexport: false }]);
+    expect(result).toMatchObject({ success: true, partial: true, error: 'unterminated block at line 9' });
+  });
+});
+
+describe('runAnalyzers', () => {
+  test('should store annotations as kind plugin and tag metrics with their analyzer', async () => {
+    registerAnalyzer({
+      name: 'sql-count',
+      languages: ['go'],
+      analyze: (file) => ({
+        annotations: [{ tag: 'SECURITY-REVIEW', text: 'raw SQL', line: 2, column: 3, symbol_name: 'Load' }],
+        metrics: [{ name: 'sql_statements', value: file.content.split('SELECT').length - 1, symbol_name: 'Load' }],
+      }),
+    });
+    const symbols = [{ symbol_name: 'Load', symbol_type: 'function', line_number: 1, end_line: 3 } as AnnotatedSymbol];
+    const file = { path: 'store.go', language: 'go', content: 'func Load() {\n\tq("SELECT 1")\n}' };
+
+    const analysis = await runAnalyzers(file, symbols);
+
+    expect(analysis.annotations).toEqual([
+      {
+        kind: 'plugin',
+        tag: 'SECURITY-REVIEW',
+        assignee: null,
+        text: 'raw SQL',
+        line: 2,
+        column: 3,
+        byte_column: 3,
+        symbol_name: 'Load',
+        symbol_type: 'function',
+      },
+    ]);
+    expect(analysis.metrics).toEqual([
+      { name: 'sql_statements', value: 1, symbol_name: 'Load', analyzer: 'sql-count' },
+    ]);
+    expect(await runAnalyzers({ ...file, language: 'python' }, symbols)).toEqual({ annotations: [], metrics: [] });
+  });
+});
+
+describe('plugin processes', () => {
+  test('should split command lines, keeping quoted words', () => {
+    expect(splitCommand(`node "/opt/my plugins/ini.mjs" --mode 'strict json'`)).toEqual([
+      'node',
+      '/opt/my plugins/ini.mjs',
+      '--mode',
+      'strict json',
+    ]);
+  });
+
+  test('should register the plugins a process serves and forward requests to it', async () => {
+    const spec = `exec:"${process.execPath}" "${path.join(__dirname, '../../fixtures/ini-plugin.mjs')}"`;
+
+    await loadPlugins([spec]);
+
+    expect(listPlugins().map(({ kind, name, source }) => ({ kind, name, source }))).toEqual([
+      { kind: 'extractor', name: 'ini', source: spec },
+      { kind: 'analyzer', name: 'ini-keys', source: spec },
+    ]);
+    const content = '[server]\nport = 80\nhost = a\n[client]\nretries = 3';
+    const extraction = await extractorForFile('app.ini')?.extract(content, 'app.ini');
+    expect(extraction?.symbols.map((symbol) => symbol.name)).toEqual(['server', 'client']);
+    const analysis = await runAnalyzers({ path: 'app.ini', language: 'ini', content }, []);
+    expect(analysis.metrics).toEqual([{ name: 'ini_keys', value: 3, analyzer: 'ini-keys' }]);
+  });
+});
@@ -0,0 +1,188 @@
+/**
+ * Unit tests for the SCIP index export
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  buildScipIndex,
+  decodeScipIndex,
+  definitionRange,
+  encodeScipIndex,
+  SCIP_ROLES,
+} from '../../../src/indexing/scip';
+import { typeParameterId } from '../../../src/indexing/symbol-ids';
+import { type ScipSymbolRecord } from '../../../src/types/database';
+
+const AUTH = 'cindex gomod example.com/shop . `example.com/shop/auth`';
+const SERVICE = `${AUTH}/Service#`;
+const LOGIN = `${AUTH}/Service#Login().`;
+const STORE = `${AUTH}/Store#`;
+
+const symbol = (overrides: Partial<ScipSymbolRecord> & Pick<ScipSymbolRecord, 'symbol_id'>): ScipSymbolRecord => ({
+  symbol_name: 'Service',
+  symbol_type: 'class',
+  file_path: 'auth/service.go',
+  line_number: 3,
+  end_line: 5,
+  definition: 'type Service struct',
+  doc_comment: null,
+  language: 'go',
+  line_text: 'type Service struct {',
+  ...overrides,
+});
+
+const SYMBOLS: ScipSymbolRecord[] = [
+  symbol({ symbol_id: SERVICE, doc_comment: 'Service signs users in.' }),
+  symbol({
+    symbol_id: LOGIN,
+    symbol_name: 'Service.Login',
+    symbol_type: 'method',
+    line_number: 8,
+    end_line: 12,
+    definition: 'func (s *Service) Login(name string) error',
+    line_text: 'func (s *Service) Login(name string) error {',
+  }),
+  symbol({
+    symbol_id: STORE,
+    symbol_name: 'Store',
+    symbol_type: 'interface',
+    file_path: 'auth/store.go',
+    line_number: 1,
+    line_text: null,
+  }),
+];
+
+describe('definitionRange', () => {
+  test('should find the name on its line as a whole word, in UTF-8 bytes', () => {
+    expect(definitionRange(8, 'func (s *Service) Login(name string) error {', 'Login')).toEqual([7, 18, 23]);
+    expect(definitionRange(2, 'var café, caféBar = 1, 2', 'caféBar')).toEqual([1, 11, 19]);
+    expect(definitionRange(4, null, 'Login')).toEqual([3, 0, 0]);
+    expect(definitionRange(4, 'LoginHandler()', 'Login')).toEqual([3, 0, 0]);
+  });
+});
+
+describe('buildScipIndex', () => {
+  const content = buildScipIndex({
+    symbols: SYMBOLS,
+    references: [
+      {
+        file_path: 'cmd/main.go',
+        line_number: 10,
+        column_number: 6,
+        target_name: 'Service.Login',
+        access: 'call',
+        target_symbol_id: LOGIN,
+      },
+      {
+        file_path: 'auth/service.go',
+        line_number: 9,
+        column_number: 2,
+        target_name: 'Service.name',
+        access: 'write',
+        target_symbol_id: `${AUTH}/Service#name.`,
+      },
+      {
+        file_path: 'cmd/main.go',
+        line_number: 4,
+        column_number: 2,
+        target_name: 'Println',
+        access: null,
+        target_symbol_id: null,
+      },
+    ],
+    implementations: [
+      {
+        repo_id: 'shop',
+        file_path: 'auth/service.go',
+        line_number: 3,
+        type_name: 'Service',
+        type_package: 'example.com/shop/auth',
+        interface_name: 'Store',
+        interface_package: 'example.com/shop/auth',
+        pointer_receiver: true,
+        type_symbol_id: SERVICE,
+      },
+      {
+        repo_id: 'shop',
+        file_path: 'auth/service.go',
+        line_number: 3,
+        type_name: 'Service',
+        type_package: 'example.com/shop/auth',
+        interface_name: 'Stringer',
+        interface_package: 'fmt',
+        pointer_receiver: false,
+        type_symbol_id: SERVICE,
+      },
+    ],
+    typeParameters: [
+      {
+        repo_id: 'shop',
+        file_path: 'auth/service.go',
+        symbol_name: 'Service',
+        symbol_kind: 'type',
+        parameter_name: 'K',
+        constraint_text: 'comparable',
+        position: 0,
+        line_number: 3,
+      },
+    ],
+  });
+
+  test('should write one document per file, with definitions and references in position order', () => {
+    expect(content.documents.map((document) => document.relative_path)).toEqual([
+      'auth/service.go',
+      'auth/store.go',
+      'cmd/main.go',
+    ]);
+    const [service, , main] = content.documents;
+
+    expect(service.language).toBe('Go');
+    expect(service.occurrences).toEqual([
+      { range: [2, 5, 12], symbol: SERVICE, symbol_roles: SCIP_ROLES.definition, enclosing_range: [2, 0, 5, 0] },
+      { range: [7, 18, 23], symbol: LOGIN, symbol_roles: SCIP_ROLES.definition, enclosing_range: [7, 0, 12, 0] },
+      { range: [8, 1, 5], symbol: `${AUTH}/Service#name.`, symbol_roles: SCIP_ROLES.writeAccess },
+    ]);
+    // Unresolved references are left out
+    expect(main.occurrences).toEqual([{ range: [9, 5, 10], symbol: LOGIN, symbol_roles: 0 }]);
+  });
+
+  test('should carry hover docs, kinds, implementations, and type parameters', () => {
+    const [service] = content.documents;
+    const info = service.symbols.find((candidate) => candidate.symbol === SERVICE);
+
+    expect(info?.documentation).toEqual(['```go\ntype Service struct\n```', 'Service signs users in.']);
+    expect(info?.kind).toBe(49);
+    expect(info?.relationships).toEqual([
+      { symbol: STORE, is_implementation: true },
+      { symbol: 'cindex gomod . . fmt/Stringer#', is_implementation: true },
+    ]);
+    expect(service.symbols.find((candidate) => candidate.symbol === LOGIN)?.display_name).toBe('Login');
+    expect(service.symbols.at(-1)).toMatchObject({
+      symbol: typeParameterId(SERVICE, 'K'),
+      kind: 58,
+      enclosing_symbol: SERVICE,
+    });
+    expect(content.external_symbols).toEqual([
+      expect.objectContaining({ symbol: 'cindex gomod . . fmt/Stringer#', display_name: 'Stringer', kind: 21 }),
+    ]);
+  });
+});
+
+describe('encodeScipIndex', () => {
+  test('should concatenate into one index', () => {
+    const { documents } = buildScipIndex({ symbols: SYMBOLS, references: [], implementations: [], typeParameters: [] });
+    const bytes = Buffer.concat([
+      encodeScipIndex({ metadata: { project_root: 'file:///src/shop', arguments: ['export'] } }),
+      ...documents.map((document) => encodeScipIndex({ documents: [document] })),
+    ]);
+    const index = decodeScipIndex(bytes) as {
+      metadata: { project_root: string; tool_info: { name: string } };
+      documents: { relative_path: string; occurrences: { range: number[] }[] }[];
+    };
+
+    expect(index.metadata.project_root).toBe('file:///src/shop');
+    expect(index.metadata.tool_info.name).toBe('cindex');
+    expect(index.documents.map((document) => document.relative_path)).toEqual(['auth/service.go', 'auth/store.go']);
+    expect(index.documents[0].occurrences[1].range).toEqual([7, 18, 23]);
+  });
+});
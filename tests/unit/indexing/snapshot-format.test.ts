@@ -0,0 +1,137 @@
+/**
+ * Unit tests for the index snapshot format (cindex export / cindex import)
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  checkSnapshotHeader,
+  compactBlockWriter,
+  COMPACT_BLOCK_SIZE,
+  encodeSnapshotHeader,
+  encodeSnapshotRow,
+  readSnapshot,
+  SNAPSHOT_TABLES,
+  SNAPSHOT_VERSION,
+  type SnapshotEncoding,
+  type SnapshotHeader,
+  type SnapshotRow,
+} from '../../../src/indexing/snapshot-format';
+
+const header: SnapshotHeader = {
+  format: 'cindex-snapshot',
+  version: SNAPSHOT_VERSION,
+  min_reader_version: 1,
+  repo_id: 'api',
+  exported_at: '2026-10-14T09:30:00.000Z',
+  embedding: { provider: 'ollama', model: 'bge-m3:567m', dimensions: 4 },
+  tables: SNAPSHOT_TABLES.map((table) => table.name),
+};
+
+const rows: SnapshotRow[] = [
+  { table: 'repositories', row: { repo_id: 'api', repo_path: '/ci/api', metadata: { branch: 'main' } } },
+  {
+    table: 'code_chunks',
+    row: { repo_id: 'api', file_path: 'auth/login.go', chunk_content: 'func Login() {\n}', embedding: [0.5, -1, 0.25, 2] },
+  },
+  { table: 'code_files', row: { repo_id: 'api', file_path: 'auth/login.go', summary_embedding: null } },
+];
+
+/**
+ * Encode a snapshot and split it into chunks of `size` bytes, as a file stream would
+ */
+async function* chunked(encoding: SnapshotEncoding, size: number, content = rows): AsyncGenerator<Buffer> {
+  const bytes = Buffer.concat([
+    encodeSnapshotHeader(header, encoding),
+    ...content.map((row) => encodeSnapshotRow(row, encoding)),
+  ]);
+  for (let offset = 0; offset < bytes.length; offset += size) {
+    await Promise.resolve();
+    yield bytes.subarray(offset, offset + size);
+  }
+}
+
+const readAll = async (input: AsyncIterable<Buffer>): Promise<{ header: SnapshotHeader; rows: SnapshotRow[] }> => {
+  const snapshot = await readSnapshot(input);
+  const read: SnapshotRow[] = [];
+  for await (const row of snapshot.rows) read.push(row);
+  return { header: snapshot.header, rows: read };
+};
+
+describe('snapshot encodings', () => {
+  test.each(['jsonl', 'protobuf'] as const)('%s round-trips header and rows across chunk boundaries', async (encoding) => {
+    for (const size of [7, 64 * 1024]) {
+      const snapshot = await readAll(chunked(encoding, size));
+      expect(snapshot.header).toEqual(header);
+      expect(snapshot.rows).toEqual(rows);
+    }
+  });
+
+  test('detects the encoding from the first byte', async () => {
+    expect((await readSnapshot(chunked('jsonl', 100))).encoding).toBe('jsonl');
+    expect((await readSnapshot(chunked('protobuf', 100))).encoding).toBe('protobuf');
+  });
+
+  test('compact round-trips rows across several compressed blocks', async () => {
+    const many: SnapshotRow[] = Array.from({ length: 4000 }, (_, index) => ({
+      table: 'code_chunks',
+      row: { repo_id: 'api', file_path: `pkg/file${String(index)}.go`, chunk_content: 'x'.repeat(400), embedding: [1] },
+    }));
+    const parts: Buffer[] = [];
+    const blocks = compactBlockWriter(async (bytes) => {
+      await Promise.resolve();
+      parts.push(bytes);
+    });
+    await blocks.write(encodeSnapshotHeader(header, 'compact'));
+    for (const row of many) await blocks.write(encodeSnapshotRow(row, 'compact'));
+    await blocks.end();
+    const bytes = Buffer.concat(parts);
+    // Magic, then more than one block, much smaller than the records
+    expect(parts.length).toBeGreaterThan(2);
+    expect(bytes.length).toBeLessThan(COMPACT_BLOCK_SIZE / 4);
+
+    async function* split(): AsyncGenerator<Buffer> {
+      for (let offset = 0; offset < bytes.length; offset += 3) {
+        await Promise.resolve();
+        yield bytes.subarray(offset, offset + 3);
+      }
+    }
+    const snapshot = await readSnapshot(split());
+    expect(snapshot.encoding).toBe('compact');
+    const read: SnapshotRow[] = [];
+    for await (const row of snapshot.rows) read.push(row);
+    expect(read).toEqual(many);
+  });
+
+  test('rejects a protobuf snapshot that ends inside a record', async () => {
+    async function* truncated(): AsyncGenerator<Buffer> {
+      const bytes = Buffer.concat([encodeSnapshotHeader(header, 'protobuf'), encodeSnapshotRow(rows[1], 'protobuf')]);
+      await Promise.resolve();
+      yield bytes.subarray(0, bytes.length - 3);
+    }
+    await expect(readAll(truncated())).rejects.toThrow('ends inside a record');
+  });
+
+  test('rejects input without a snapshot header', async () => {
+    async function* lines(): AsyncGenerator<Buffer> {
+      await Promise.resolve();
+      yield Buffer.from('{"table":"code_files","row":{}}\n');
+    }
+    await expect(readSnapshot(lines())).rejects.toThrow('Not a cindex snapshot');
+  });
+});
+
+describe('checkSnapshotHeader', () => {
+  test('accepts a newer snapshot that older readers can still import', () => {
+    const newer = { ...header, version: SNAPSHOT_VERSION + 1, tables: [...header.tables, 'go_generics'] };
+    expect(checkSnapshotHeader(newer)).toEqual(newer);
+  });
+
+  test('refuses a snapshot that needs a newer reader', () => {
+    const newer = { ...header, version: SNAPSHOT_VERSION + 1, min_reader_version: SNAPSHOT_VERSION + 1 };
+    expect(() => checkSnapshotHeader(newer)).toThrow('requires a reader of version');
+  });
+
+  test('refuses a header without format versions', () => {
+    expect(() => checkSnapshotHeader({ format: 'cindex-snapshot', repo_id: 'api' })).toThrow('no format version');
+  });
+});
@@ -0,0 +1,130 @@
+/**
+ * Unit tests for snapshot export: reproducible rows and what is left out
+ */
+
+import { afterEach, describe, test, expect } from '@jest/globals';
+import { type DatabaseClient } from '../../../src/database/client';
+import { exportSnapshot } from '../../../src/indexing/snapshot';
+import { type SnapshotHeader, type SnapshotRow } from '../../../src/indexing/snapshot-format';
+import { type EmbeddingConfig } from '../../../src/types/config';
+
+const COLUMNS: Record<string, string[]> = {
+  repositories: ['id', 'repo_id', 'repo_path', 'metadata', 'indexed_at', 'last_updated'],
+  code_chunks: ['id', 'repo_id', 'file_path', 'start_line', 'end_line', 'chunk_type', 'embedding', 'indexed_at'],
+  secret_findings: ['id', 'repo_id', 'file_path', 'rule', 'redacted', 'detected_at'],
+};
+
+const ROWS: Record<string, Record<string, unknown>[]> = {
+  repositories: [
+    {
+      id: 7,
+      repo_id: 'api',
+      repo_path: '/ci/api',
+      metadata: { version: 'v1.2.0', last_indexed: '2026-10-14T09:30:00.000Z', fetched_at: '2026-10-14T09:29:00.000Z' },
+      indexed_at: '2026-10-14T09:30:00',
+      last_updated: '2026-10-14T09:31:00',
+    },
+  ],
+  code_chunks: [
+    {
+      id: 31,
+      repo_id: 'api',
+      file_path: 'auth/login.go',
+      start_line: 1,
+      end_line: 9,
+      chunk_type: 'function',
+      embedding: '[0.5,-1]',
+      indexed_at: '2026-10-14T09:30:00',
+    },
+  ],
+  secret_findings: [{ id: 2, repo_id: 'api', file_path: '.env', rule: 'aws-key', redacted: 'AKIA****' }],
+};
+
+/**
+ * Database client serving COLUMNS and ROWS, recording the cursors it was asked to declare
+ */
+const clientOf = (): { db: DatabaseClient; cursors: string[] } => {
+  const cursors: string[] = [];
+  let pending: Record<string, unknown>[] = [];
+  const client = {
+    escapeLiteral: (value: string) => `'${value.replace(/'/g, "''")}'`,
+    query: (sql: string) => {
+      if (sql.includes('information_schema.columns')) {
+        const rows = Object.entries(COLUMNS).flatMap(([table_name, columns]) =>
+          columns.map((column_name) => ({ table_name, column_name }))
+        );
+        return Promise.resolve({ rows });
+      }
+      if (sql.startsWith('DECLARE')) {
+        cursors.push(sql);
+        pending = ROWS[/FROM (\w+) t/.exec(sql)?.[1] ?? ''] ?? [];
+      }
+      if (sql.startsWith('FETCH')) {
+        const rows = pending.map((row) => ({ row }));
+        pending = [];
+        return Promise.resolve({ rows });
+      }
+      return Promise.resolve({ rows: [] });
+    },
+  };
+  const db = {
+    transaction: <T>(callback: (tx: typeof client) => Promise<T>) => callback(client),
+  } as unknown as DatabaseClient;
+  return { db, cursors };
+};
+
+const EMBEDDING = { provider: 'ollama', model: 'bge-m3:567m', dimensions: 2 } as EmbeddingConfig;
+
+/**
+ * Export the fake index, returning the records written
+ */
+const exportRecords = async (db: DatabaseClient): Promise<{ header: SnapshotHeader; rows: SnapshotRow[] }> => {
+  const rows: SnapshotRow[] = [];
+  const { header } = await exportSnapshot(db, 'api', EMBEDDING, () => Promise.resolve(), {
+    header: () => Buffer.alloc(0),
+    row: (row) => {
+      rows.push(row);
+      return Buffer.alloc(0);
+    },
+  });
+  return { header, rows };
+};
+
+describe('exportSnapshot', () => {
+  const originalEnv = process.env;
+
+  afterEach(() => {
+    process.env = originalEnv;
+  });
+
+  test('should leave out serial IDs, write timestamps, and secret findings', async () => {
+    const { header, rows } = await exportRecords(clientOf().db);
+
+    expect(header.tables).toEqual(['repositories', 'code_chunks']);
+    expect(rows).toEqual([
+      { table: 'repositories', row: { repo_id: 'api', repo_path: '/ci/api', metadata: { version: 'v1.2.0' } } },
+      {
+        table: 'code_chunks',
+        row: {
+          repo_id: 'api',
+          file_path: 'auth/login.go',
+          start_line: 1,
+          end_line: 9,
+          chunk_type: 'function',
+          embedding: [0.5, -1],
+        },
+      },
+    ]);
+  });
+
+  test('should order rows by content and take the export time from SOURCE_DATE_EPOCH', async () => {
+    process.env = { ...originalEnv, SOURCE_DATE_EPOCH: '1791970200' };
+    const { db, cursors } = clientOf();
+
+    const { header } = await exportRecords(db);
+
+    expect(header.exported_at).toBe('2026-10-14T09:30:00.000Z');
+    expect(cursors[1]).toContain('ORDER BY t.file_path, t.start_line, t.end_line, t.chunk_type, to_jsonb(t) - ARRAY[');
+    expect(cursors.every((sql) => !/ORDER BY t\.id\b/.test(sql))).toBe(true);
+  });
+});
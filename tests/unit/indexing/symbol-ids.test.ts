@@ -0,0 +1,104 @@
+/**
+ * Unit tests for symbol kinds and structured symbol IDs
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  formatSymbolId,
+  memberId,
+  parseSymbolId,
+  symbolId,
+  symbolKind,
+  typeParameterId,
+  type SymbolIdInput,
+} from '../../../src/indexing/symbol-ids';
+import { Language } from '../../../src/types/indexing';
+
+const goSymbol = (symbol_name: string, symbol_type: SymbolIdInput['symbol_type'], file_path: string): string =>
+  symbolId({
+    symbol_name,
+    symbol_type,
+    file_path,
+    language: Language.Go,
+    package_name: 'example.com/shop/api',
+    package_directory: 'api',
+  });
+
+describe('symbolKind', () => {
+  test('should map symbol types to the kind taxonomy', () => {
+    expect(symbolKind('benchmark', 'go')).toBe('func');
+    expect(symbolKind('class', 'go')).toBe('struct');
+    expect(symbolKind('class', 'python')).toBe('class');
+    expect(symbolKind('constant')).toBe('const');
+    expect(symbolKind('variable')).toBe('var');
+    expect(symbolKind('field')).toBe('field');
+  });
+});
+
+describe('symbolId', () => {
+  test('should name Go symbols by package import path, not file', () => {
+    const login = goSymbol('Service.Login', 'method', 'api/auth/service.go');
+
+    expect(login).toBe('cindex gomod example.com/shop/api . `example.com/shop/api/auth`/Service#Login().');
+    expect(goSymbol('Service.Login', 'method', 'api/auth/login.go')).toBe(login);
+    expect(goSymbol('Service', 'class', 'api/router.go')).toBe(
+      'cindex gomod example.com/shop/api . `example.com/shop/api`/Service#'
+    );
+    expect(goSymbol('User.Email', 'field', 'api/auth/user.go')).toMatch(/\/User#Email\.$/);
+    expect(goSymbol('MaxRetries', 'constant', 'api/auth/user.go')).toMatch(/\/MaxRetries\.$/);
+    expect(goSymbol('TestLogin/expired_token', 'test', 'api/auth/service_test.go')).toMatch(
+      /\/TestLogin\(\)\.expired_token\.$/
+    );
+  });
+
+  test('should name other languages by file', () => {
+    expect(
+      symbolId({
+        symbol_name: 'createSession',
+        symbol_type: 'function',
+        file_path: 'src/session.ts',
+        language: Language.TypeScript,
+        package_name: '@acme/web',
+      })
+    ).toBe('cindex npm @acme/web . src/`session.ts`/createSession().');
+    expect(
+      symbolId({ symbol_name: 'Config', symbol_type: 'class', file_path: 'config.py', language: Language.Python })
+    ).toBe('cindex . . . `config.py`/Config#');
+  });
+
+  test('should extend the ID of an enclosing symbol', () => {
+    const map = goSymbol('Map', 'type', 'api/cache/map.go');
+
+    expect(memberId(map, [{ name: 'Get', suffix: 'method' }])).toBe(goSymbol('Map.Get', 'method', 'api/cache/map.go'));
+    expect(typeParameterId(map, 'K')).toBe(`${map}[K]`);
+  });
+});
+
+describe('parseSymbolId', () => {
+  test('should read back the parts of formatted IDs', () => {
+    const parts = {
+      scheme: 'cindex',
+      manager: 'gomod',
+      package: 'example.com/odd name',
+      version: '.',
+      descriptors: [
+        { name: 'example.com/odd name/pkg', suffix: 'namespace' as const },
+        { name: 'Cache', suffix: 'type' as const },
+        { name: 'K', suffix: 'type_parameter' as const },
+        { name: 'weird`name', suffix: 'term' as const },
+        { name: 'Get', suffix: 'method' as const },
+      ],
+    };
+    const id = formatSymbolId(parts);
+
+    expect(id).toBe('cindex gomod example.com/odd  name . `example.com/odd name/pkg`/Cache#[K]`weird``name`.Get().');
+    expect(parseSymbolId(id)).toEqual(parts);
+  });
+
+  test('should reject malformed IDs', () => {
+    expect(parseSymbolId('cindex gomod')).toBeNull();
+    expect(parseSymbolId('cindex . . . ')).toBeNull();
+    expect(parseSymbolId('cindex . . . Name')).toBeNull();
+    expect(parseSymbolId('cindex . . . `open#')).toBeNull();
+  });
+});
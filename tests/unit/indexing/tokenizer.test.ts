@@ -0,0 +1,67 @@
+/**
+ * Unit tests for the identifier tokenizer
+ */
+
+import { afterEach, describe, test, expect } from '@jest/globals';
+import {
+  DEFAULT_TOKENIZER,
+  identifierTokens,
+  nameTokens,
+  registerTokenizer,
+  stemWord,
+  tokenizerFor,
+} from '../../../src/indexing/tokenizer';
+
+describe('stemWord', () => {
+  test('should bring inflections of a word to one stem', () => {
+    expect(['parse', 'parsed', 'parser', 'parsing', 'parses'].map(stemWord)).toEqual(Array(5).fill('pars'));
+    expect(['service', 'services'].map(stemWord)).toEqual(['servic', 'servic']);
+    expect(['policy', 'policies'].map(stemWord)).toEqual(['policy', 'policy']);
+    expect(['run', 'running'].map(stemWord)).toEqual(['run', 'run']);
+    expect(stemWord('indexes')).toBe('index');
+  });
+
+  test('should leave short words, words without a stem, and non-ASCII words alone', () => {
+    expect(['id', 'url', 'string', 'status', 'address', 'analysis', 'speed', 'user'].map(stemWord)).toEqual([
+      'id',
+      'url',
+      'string',
+      'status',
+      'address',
+      'analysis',
+      'speed',
+      'user',
+    ]);
+    expect(stemWord('größes')).toBe('größes');
+  });
+});
+
+describe('nameTokens', () => {
+  test('should split camelCase, initialisms, and snake_case into lowercase words and stems', () => {
+    expect(nameTokens('NewAuthService')).toEqual(['new', 'auth', 'service', 'servic']);
+    expect(nameTokens('parseHTTPRequest')).toEqual(['parse', 'http', 'request', 'pars']);
+    expect(nameTokens('MAX_RETRIES')).toEqual(['max', 'retries', 'retry']);
+    expect(nameTokens('__init__')).toEqual(['init']);
+    expect(nameTokens('base64Encode')).toEqual(['base64', 'encode', 'encod']);
+  });
+
+  test('should list each word once', () => {
+    expect(identifierTokens('userUser_user')).toEqual([{ word: 'user', stem: 'user' }]);
+  });
+});
+
+describe('registerTokenizer', () => {
+  afterEach(() => {
+    registerTokenizer('sql', DEFAULT_TOKENIZER);
+  });
+
+  test('should use a language tokenizer for its identifiers only', () => {
+    // Whole identifiers, no stemming
+    registerTokenizer('sql', { split: (identifier) => identifier.split('$') });
+
+    expect(tokenizerFor('sql')).not.toBe(DEFAULT_TOKENIZER);
+    expect(nameTokens('Orders$Archived', 'sql')).toEqual(['orders', 'archived']);
+    expect(nameTokens('Orders$Archived', 'go')).toEqual(['orders', 'archived', 'ord', 'archiv']);
+    expect(tokenizerFor(null)).toBe(DEFAULT_TOKENIZER);
+  });
+});
@@ -0,0 +1,96 @@
+/**
+ * Unit tests for watch mode change filtering and batching
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { resolveExcludedDirectories } from '../../../src/indexing/default-exclusions';
+import { ChangeBatcher, isWatchedPath } from '../../../src/indexing/watcher';
+
+const sleep = (ms: number): Promise<void> => new Promise((resolve) => setTimeout(resolve, ms));
+
+describe('isWatchedPath', () => {
+  const excluded = resolveExcludedDirectories(['generated']);
+
+  test('watches source paths', () => {
+    expect(isWatchedPath('src/main.go', excluded)).toBe(true);
+    expect(isWatchedPath('README.md', excluded)).toBe(true);
+  });
+
+  test('ignores paths inside excluded directories', () => {
+    expect(isWatchedPath('.git/index.lock', excluded)).toBe(false);
+    expect(isWatchedPath('web/node_modules/react/index.js', excluded)).toBe(false);
+    expect(isWatchedPath('api/generated/client.ts', excluded)).toBe(false);
+    expect(isWatchedPath('', excluded)).toBe(false);
+  });
+});
+
+describe('ChangeBatcher', () => {
+  test('flushes a burst of changes once, after it settles', async () => {
+    const batches: string[][] = [];
+    const batcher = new ChangeBatcher((paths) => {
+      batches.push(paths);
+      return Promise.resolve();
+    }, 20);
+
+    batcher.add('b.go');
+    batcher.add('a.go');
+    await sleep(5);
+    batcher.add('a.go');
+    await sleep(60);
+
+    expect(batches).toEqual([['a.go', 'b.go']]);
+    await batcher.close();
+  });
+
+  test('flushes a root that keeps changing after the maximum wait', async () => {
+    const batches: string[][] = [];
+    const batcher = new ChangeBatcher(
+      (paths) => {
+        batches.push(paths);
+        return Promise.resolve();
+      },
+      30,
+      50
+    );
+
+    for (let i = 0; i < 8; i++) {
+      batcher.add(`f${String(i)}.go`);
+      await sleep(10);
+    }
+
+    expect(batches.length).toBeGreaterThan(0);
+    await batcher.close();
+  });
+
+  test('batches changes made during a flush for the next one', async () => {
+    const batches: string[][] = [];
+    let release = (): void => undefined;
+    const batcher = new ChangeBatcher(async (paths) => {
+      batches.push(paths);
+      if (batches.length === 1) {
+        await new Promise<void>((resolve) => {
+          release = resolve;
+        });
+      }
+    }, 5);
+
+    batcher.add('a.go');
+    await sleep(20);
+    batcher.add('b.go');
+    batcher.add('c.go');
+    await sleep(20);
+    expect(batches).toEqual([['a.go']]);
+
+    release();
+    await sleep(30);
+    expect(batches).toEqual([['a.go'], ['b.go', 'c.go']]);
+    await batcher.close();
+  });
+
+  test('returns unflushed changes on close', async () => {
+    const batcher = new ChangeBatcher(() => Promise.resolve(), 1000);
+    batcher.add('pending.go');
+
+    expect(await batcher.close()).toEqual(['pending.go']);
+  });
+});
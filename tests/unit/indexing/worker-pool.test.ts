@@ -0,0 +1,71 @@
+/**
+ * Unit tests for the bounded indexing worker pool
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { runWorkerPool } from '../../../src/indexing/worker-pool';
+
+/** Resolve on the next macrotask, letting other workers run */
+const tick = (): Promise<void> => new Promise((resolve) => setTimeout(resolve, 1));
+
+describe('runWorkerPool', () => {
+  test('processes every item with at most `jobs` at a time', async () => {
+    let running = 0;
+    let peak = 0;
+    const done: number[] = [];
+
+    const options = { jobs: 3, weight: () => 1, budget: 100 };
+    const admitted = await runWorkerPool([1, 2, 3, 4, 5, 6, 7], options, async (item) => {
+      running++;
+      peak = Math.max(peak, running);
+      await tick();
+      done.push(item);
+      running--;
+    });
+
+    expect(admitted).toBe(7);
+    expect(peak).toBe(3);
+    expect([...done].sort((a, b) => a - b)).toEqual([1, 2, 3, 4, 5, 6, 7]);
+  });
+
+  test('holds items back while their weight would exceed the budget', async () => {
+    let weight = 0;
+    let peak = 0;
+
+    await runWorkerPool([40, 40, 40, 10, 10], { jobs: 4, weight: (item) => item, budget: 90 }, async (item) => {
+      weight += item;
+      peak = Math.max(peak, weight);
+      await tick();
+      weight -= item;
+    });
+
+    expect(peak).toBeLessThanOrEqual(90);
+  });
+
+  test('admits an item heavier than the budget when nothing else is in flight', async () => {
+    const done: number[] = [];
+    await runWorkerPool([500, 1], { jobs: 2, weight: (item) => item, budget: 100 }, async (item) => {
+      await tick();
+      done.push(item);
+    });
+    expect(done).toEqual([500, 1]);
+  });
+
+  test('stops admitting items after an abort and finishes those in flight', async () => {
+    const controller = new AbortController();
+    const done: number[] = [];
+
+    const admitted = await runWorkerPool(
+      [1, 2, 3, 4, 5, 6],
+      { jobs: 2, weight: () => 1, budget: 100, signal: controller.signal },
+      async (item) => {
+        if (item === 2) controller.abort();
+        await tick();
+        done.push(item);
+      }
+    );
+
+    expect(admitted).toBe(2);
+    expect([...done].sort((a, b) => a - b)).toEqual([1, 2]);
+  });
+});
@@ -0,0 +1,83 @@
+/**
+ * Unit tests for LSP message framing and conversions
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  encodeMessage,
+  fromFileUri,
+  MessageReader,
+  negotiateEncoding,
+  toFileUri,
+  toSymbolKind,
+} from '../../../src/lsp/protocol';
+import { byteToUtf16Column } from '../../../src/utils/positions';
+
+const frame = (body: string): Buffer =>
+  Buffer.from(`Content-Length: ${String(Buffer.byteLength(body))}\r\n\r\n${body}`, 'utf8');
+
+describe('MessageReader', () => {
+  test('should read messages split across chunks', () => {
+    const reader = new MessageReader();
+    const wire = Buffer.concat([frame('{"id":1,"method":"a"}'), frame('{"id":2,"method":"é"}')]);
+    expect(reader.push(wire.subarray(0, 10))).toEqual([]);
+    expect(reader.push(wire.subarray(10, 50))).toEqual([{ id: 1, method: 'a' }]);
+    expect(reader.push(wire.subarray(50))).toEqual([{ id: 2, method: 'é' }]);
+  });
+
+  test('should count body length in bytes and accept other headers', () => {
+    const reader = new MessageReader();
+    const body = '{"text":"日本"}';
+    const length = String(Buffer.byteLength(body));
+    const wire = Buffer.from(`Content-Type: application/vscode-jsonrpc\r\ncontent-length: ${length}\r\n\r\n${body}`);
+    expect(reader.push(wire)).toEqual([{ text: '日本' }]);
+  });
+
+  test('should yield undefined for a body that is not JSON', () => {
+    expect(new MessageReader().push(frame('{oops'))).toEqual([undefined]);
+  });
+
+  test('should reject a header without Content-Length', () => {
+    expect(() => new MessageReader().push(Buffer.from('X-Other: 1\r\n\r\n{}'))).toThrow(/Content-Length/);
+  });
+
+  test('should read back what encodeMessage writes', () => {
+    const message = { jsonrpc: '2.0' as const, id: 7, result: { name: 'façade' } };
+    expect(new MessageReader().push(encodeMessage(message))).toEqual([message]);
+  });
+});
+
+describe('file URIs', () => {
+  test('should round-trip paths and reject other schemes', () => {
+    const uri = toFileUri('/repo/src/my file.go');
+    expect(uri).toBe('file:///repo/src/my%20file.go');
+    expect(fromFileUri(uri)).toBe('/repo/src/my file.go');
+    expect(fromFileUri('untitled:Untitled-1')).toBeNull();
+  });
+});
+
+describe('negotiateEncoding', () => {
+  test('should pick UTF-8 only when offered', () => {
+    expect(negotiateEncoding(['utf-16', 'utf-8'])).toBe('utf-8');
+    expect(negotiateEncoding(['utf-16'])).toBe('utf-16');
+    expect(negotiateEncoding(undefined)).toBe('utf-16');
+  });
+});
+
+describe('toSymbolKind', () => {
+  test('should map symbol types to SymbolKind numbers', () => {
+    expect(toSymbolKind('function')).toBe(12);
+    expect(toSymbolKind('test')).toBe(12);
+    expect(toSymbolKind('interface')).toBe(11);
+    expect(toSymbolKind('package')).toBe(4);
+  });
+});
+
+describe('byteToUtf16Column', () => {
+  test('should convert byte columns of multi-byte lines', () => {
+    expect(byteToUtf16Column('abc', 3)).toBe(3);
+    // é is 2 bytes, 1 code unit; 😀 is 4 bytes, 2 code units
+    expect(byteToUtf16Column('é = x', 4)).toBe(3);
+    expect(byteToUtf16Column('😀x', 5)).toBe(3);
+  });
+});
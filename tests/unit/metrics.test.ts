@@ -0,0 +1,143 @@
+/**
+ * Unit tests for Prometheus metrics and tracing spans
+ */
+
+import { afterEach, describe, expect, test } from '@jest/globals';
+
+import { Counter, filesIndexed, Histogram, renderMetrics, resetMetrics } from '@utils/metrics';
+import { flushTraces, parseOtlpHeaders, queuedSpans, toOtlpRequest, tracesEndpoint, withSpan } from '@utils/tracing';
+
+describe('metrics', () => {
+  afterEach(() => {
+    resetMetrics();
+  });
+
+  test('should count per label set and render counters', () => {
+    const counter = new Counter('test_total', 'Things counted');
+    counter.inc({ kind: 'a' });
+    counter.inc({ kind: 'a' }, 2);
+    counter.inc({ kind: 'say "hi"\n' });
+
+    expect(counter.value({ kind: 'a' })).toBe(3);
+    expect(counter.value({ kind: 'b' })).toBe(0);
+    expect(counter.render()).toEqual([
+      '# HELP test_total Things counted',
+      '# TYPE test_total counter',
+      'test_total{kind="a"} 3',
+      'test_total{kind="say \\"hi\\"\\n"} 1',
+    ]);
+  });
+
+  test('should render cumulative histogram buckets with sum and count', () => {
+    const histogram = new Histogram('test_seconds', 'Time taken', [0.1, 1]);
+    histogram.observe(0.05, { phase: 'parse' });
+    histogram.observe(0.5, { phase: 'parse' });
+    histogram.observe(3, { phase: 'parse' });
+
+    expect(histogram.render().slice(2)).toEqual([
+      'test_seconds_bucket{le="0.1",phase="parse"} 1',
+      'test_seconds_bucket{le="1",phase="parse"} 2',
+      'test_seconds_bucket{le="+Inf",phase="parse"} 3',
+      'test_seconds_sum{phase="parse"} 3.55',
+      'test_seconds_count{phase="parse"} 3',
+    ]);
+  });
+
+  test('should render every predeclared metric, series or not', () => {
+    filesIndexed.inc({ repo_id: 'api' }, 4);
+    const text = renderMetrics();
+
+    expect(text).toContain('# TYPE cindex_files_indexed_total counter\ncindex_files_indexed_total{repo_id="api"} 4\n');
+    expect(text).toContain('# TYPE cindex_index_lock_wait_seconds histogram');
+    expect(text).toContain('# TYPE cindex_read_retries_total counter');
+    expect(text.endsWith('\n')).toBe(true);
+  });
+});
+
+describe('tracing', () => {
+  test('should read the collector endpoint and headers from the OpenTelemetry variables', () => {
+    expect(tracesEndpoint({})).toBeNull();
+    expect(tracesEndpoint({ OTEL_EXPORTER_OTLP_ENDPOINT: 'http://otel:4318/' })).toBe('http://otel:4318/v1/traces');
+    expect(
+      tracesEndpoint({
+        OTEL_EXPORTER_OTLP_ENDPOINT: 'http://otel:4318',
+        OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: 'http://traces:4318/in',
+      })
+    ).toBe('http://traces:4318/in');
+    expect(parseOtlpHeaders('api-key=s%3Dcret, x-tenant=a,broken')).toEqual({ 'api-key': 's=cret', 'x-tenant': 'a' });
+  });
+
+  test('should nest spans started inside a span and mark failed ones', async () => {
+    process.env.OTEL_EXPORTER_OTLP_ENDPOINT = 'http://127.0.0.1:4318';
+    try {
+      const result = await withSpan('cindex.index_file', { 'code.filepath': 'main.go' }, async () => {
+        const inner = await withSpan('cindex.parse', {}, () => Promise.resolve(41));
+        await expect(withSpan('cindex.store', {}, () => Promise.reject(new Error('disk full')))).rejects.toThrow(
+          'disk full'
+        );
+        return inner + 1;
+      });
+      expect(result).toBe(42);
+    } finally {
+      delete process.env.OTEL_EXPORTER_OTLP_ENDPOINT;
+      // No collector left: the timer is cleared and nothing is sent
+      await flushTraces();
+    }
+
+    const [parse, store, file] = queuedSpans();
+    expect([parse.name, store.name, file.name]).toEqual(['cindex.parse', 'cindex.store', 'cindex.index_file']);
+    expect(file.parentSpanId).toBeUndefined();
+    expect(parse.parentSpanId).toBe(file.spanId);
+    expect(store.parentSpanId).toBe(file.spanId);
+    expect(new Set([parse.traceId, store.traceId, file.traceId]).size).toBe(1);
+    expect(store.error).toBe('disk full');
+    expect(file.endTimeUnixNano >= parse.endTimeUnixNano).toBe(true);
+  });
+
+  test('should build an OTLP/JSON export request', () => {
+    const request = toOtlpRequest(
+      [
+        {
+          traceId: 'a'.repeat(32),
+          spanId: 'b'.repeat(16),
+          name: 'cindex.parse',
+          startTimeUnixNano: 1_700_000_000_000_000_000n,
+          endTimeUnixNano: 1_700_000_000_250_000_000n,
+          attributes: { 'code.filepath': 'main.go', lines: 12, ratio: 0.5, generated: false },
+          error: 'syntax error',
+        },
+      ],
+      'cindex'
+    );
+
+    expect(request).toEqual({
+      resourceSpans: [
+        {
+          resource: { attributes: [{ key: 'service.name', value: { stringValue: 'cindex' } }] },
+          scopeSpans: [
+            {
+              scope: { name: 'cindex' },
+              spans: [
+                {
+                  traceId: 'a'.repeat(32),
+                  spanId: 'b'.repeat(16),
+                  name: 'cindex.parse',
+                  kind: 1,
+                  startTimeUnixNano: '1700000000000000000',
+                  endTimeUnixNano: '1700000000250000000',
+                  attributes: [
+                    { key: 'code.filepath', value: { stringValue: 'main.go' } },
+                    { key: 'lines', value: { intValue: '12' } },
+                    { key: 'ratio', value: { doubleValue: 0.5 } },
+                    { key: 'generated', value: { boolValue: false } },
+                  ],
+                  status: { code: 2, message: 'syntax error' },
+                },
+              ],
+            },
+          ],
+        },
+      ],
+    });
+  });
+});
@@ -0,0 +1,87 @@
+/**
+ * Unit tests for content search patterns and line matching
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import {
+  compileContentPattern,
+  escapeRegex,
+  matchLines,
+  requiredLiterals,
+  toPostgresRegex,
+} from '../../../src/retrieval/content-search';
+
+describe('toPostgresRegex', () => {
+  test('should translate word boundaries', () => {
+    expect(toPostgresRegex('\\bLogin\\b')).toBe('\\yLogin\\y');
+    expect(toPostgresRegex('\\BSession')).toBe('\\YSession');
+  });
+
+  test('should leave other escapes and bracket expressions alone', () => {
+    expect(toPostgresRegex('func \\(s \\*\\w+\\)')).toBe('func \\(s \\*\\w+\\)');
+    expect(toPostgresRegex('[\\b]x\\b')).toBe('[\\b]x\\y');
+    expect(toPostgresRegex('a\\\\b')).toBe('a\\\\b');
+  });
+});
+
+describe('compileContentPattern', () => {
+  test('should match newline-sensitively in PostgreSQL, ignoring case when asked', () => {
+    expect(compileContentPattern('^func').postgres).toBe('(?n)^func');
+    const { regex, postgres } = compileContentPattern('todo', { ignoreCase: true });
+    expect(postgres).toBe('(?ni)todo');
+    expect(regex.test('// TODO: retry')).toBe(true);
+  });
+
+  test('should escape fixed strings', () => {
+    expect(escapeRegex('user.Role == (a|b)')).toBe('user\\.Role == \\(a\\|b\\)');
+    const { regex } = compileContentPattern('s.db[0]', { fixedStrings: true });
+    expect(regex.test('return s.db[0]')).toBe(true);
+    expect(regex.test('return sxdb0')).toBe(false);
+  });
+
+  test('should reject invalid patterns', () => {
+    expect(() => compileContentPattern('func (')).toThrow(SyntaxError);
+    expect(() => compileContentPattern('func (', { fixedStrings: true })).not.toThrow();
+  });
+});
+
+describe('matchLines', () => {
+  test('should report the first match of each line with its position', () => {
+    const content = 'package auth\r\n\nfunc Login() {}\nfunc Logout() { Login() }\n';
+    expect(matchLines(content, /Login/)).toEqual([
+      { line: 3, column: 6, byte_column: 6, length: 5, text: 'func Login() {}' },
+      { line: 4, column: 17, byte_column: 17, length: 5, text: 'func Logout() { Login() }' },
+    ]);
+  });
+
+  test('should report UTF-8 byte columns beside UTF-16 columns', () => {
+    const [match] = matchLines('const café = "naïve"', /naïve/);
+    expect(match.column).toBe(15);
+    expect(match.byte_column).toBe(16);
+  });
+
+  test('should stop at the limit', () => {
+    expect(matchLines('a\na\na', /a/, 2).map((match) => match.line)).toEqual([1, 2]);
+  });
+});
+
+describe('requiredLiterals', () => {
+  test('should take the literal text outside groups and classes', () => {
+    expect(requiredLiterals('func \\(s \\*AuthService\\)')).toEqual(['func (s *AuthService)']);
+    expect(requiredLiterals('^import [a-z]+ from')).toEqual(['import ', ' from']);
+    expect(requiredLiterals('Handler(Func)?Name')).toEqual(['Handler', 'Name']);
+    expect(requiredLiterals('user.Role ==', true)).toEqual(['user.Role ==']);
+  });
+
+  test('should drop characters a quantifier makes optional, and escapes naming characters', () => {
+    expect(requiredLiterals('colou?r')).toEqual(['colo']);
+    expect(requiredLiterals('Retries{2,3}x')).toEqual(['Retrie']);
+    expect(requiredLiterals('\\x41BCD\\d+ms')).toEqual(['BCD']);
+  });
+
+  test('should give nothing for alternatives and short text', () => {
+    expect(requiredLiterals('TODO|FIXME')).toEqual([]);
+    expect(requiredLiterals('a.b')).toEqual([]);
+    expect(requiredLiterals('ab', true)).toEqual([]);
+  });
+});
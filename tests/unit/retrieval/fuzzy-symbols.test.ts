@@ -0,0 +1,84 @@
+/**
+ * Unit tests for fuzzy symbol matching and ranking
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { fuzzyScore, rankSymbols, subsequencePattern } from '../../../src/retrieval/fuzzy-symbols';
+import { type ResolvedSymbol } from '../../../src/types/retrieval';
+
+const symbol = (
+  symbol_name: string,
+  file_path = 'internal/auth/service.go',
+  overrides: Partial<ResolvedSymbol> = {}
+): ResolvedSymbol => ({
+  symbol_name,
+  symbol_type: 'function',
+  file_path,
+  line_number: 1,
+  definition: `func ${symbol_name}()`,
+  scope: 'exported',
+  ...overrides,
+});
+
+const names = (symbols: ResolvedSymbol[]): string[] => symbols.map((s) => s.symbol_name);
+
+describe('fuzzyScore', () => {
+  test('should match subsequences ignoring case', () => {
+    expect(fuzzyScore('NAS', 'NewAuthService')).not.toBeNull();
+    expect(fuzzyScore('nas', 'NewAuthService')).not.toBeNull();
+    expect(fuzzyScore('nsa', 'NewAuthService')).toBeNull();
+    expect(fuzzyScore('', 'NewAuthService')).toBeNull();
+  });
+
+  test('should prefer word starts to letters inside words', () => {
+    const humps = fuzzyScore('NAS', 'NewAuthService') ?? 0;
+    const inside = fuzzyScore('NAS', 'Nanoseconds') ?? 0;
+    expect(humps).toBeGreaterThan(inside);
+    expect(fuzzyScore('hs', 'HTTPServer') ?? 0).toBeGreaterThan(fuzzyScore('hs', 'Hashes') ?? 0);
+    expect(fuzzyScore('ph', 'parse_header') ?? 0).toBeGreaterThan(fuzzyScore('ph', 'alphabet') ?? 0);
+  });
+
+  test('should prefer consecutive runs, prefixes, and exact names', () => {
+    expect(fuzzyScore('auth', 'AuthService') ?? 0).toBeGreaterThan(fuzzyScore('auth', 'AddUserToHost') ?? 0);
+    expect(fuzzyScore('login', 'Login') ?? 0).toBeGreaterThan(fuzzyScore('login', 'LoginHandler') ?? 0);
+    expect(fuzzyScore('login', 'LoginHandler') ?? 0).toBeGreaterThan(fuzzyScore('login', 'UserLogin') ?? 0);
+  });
+});
+
+describe('subsequencePattern', () => {
+  test('should join characters with wildcards and escape LIKE syntax', () => {
+    expect(subsequencePattern('NAS')).toBe('N%A%S');
+    expect(subsequencePattern('a_b%')).toBe('a%\\_%b%\\%');
+  });
+});
+
+describe('rankSymbols', () => {
+  test('should rank by match, then drop non-matches', () => {
+    const ranked = rankSymbols(
+      [symbol('Nanoseconds'), symbol('NewAuthService'), symbol('Handler'), symbol('newAuthSession')],
+      'NAS'
+    );
+    expect(names(ranked)).toEqual(['NewAuthService', 'newAuthSession', 'Nanoseconds']);
+  });
+
+  test('should weight exported declarations above internal symbols and tests', () => {
+    const ranked = rankSymbols(
+      [
+        symbol('TestNewAuthService', 'internal/auth/service_test.go', { symbol_type: 'test' }),
+        symbol('newAuthService', 'internal/auth/service.go', { scope: 'internal' }),
+        symbol('NewAuthService', 'internal/auth/service.go'),
+      ],
+      'newauthservice'
+    );
+    expect(names(ranked)).toEqual(['NewAuthService', 'newAuthService', 'TestNewAuthService']);
+  });
+
+  test('should rank files near the given path first among equal matches', () => {
+    const ranked = rankSymbols(
+      [symbol('Config', 'cmd/server/config.go'), symbol('Config', 'internal/auth/config.go')],
+      'Config',
+      'internal/auth'
+    );
+    expect(ranked.map((s) => s.file_path)).toEqual(['internal/auth/config.go', 'cmd/server/config.go']);
+  });
+});
@@ -0,0 +1,79 @@
+/**
+ * Unit tests for gopls-assisted Go resolution
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { declarationColumn, identifierAt, mergeReferences, parseGoplsLocations } from '../../../src/retrieval/gopls';
+import { type GoReferenceRecord } from '../../../src/types/database';
+
+const ref = (file_path: string, line_number: number, symbol_name: string | null = 'Handler'): GoReferenceRecord => ({
+  repo_id: 'shop',
+  file_path,
+  line_number,
+  column_number: 9,
+  target_name: 'Login',
+  target_package: 'example.com/shop/auth',
+  target_file: 'auth/login.go',
+  target_line: 5,
+  access: 'call',
+  symbol_name,
+});
+
+describe('parseGoplsLocations', () => {
+  test('should read locations inside the repository', () => {
+    const output = [
+      '/work/shop/api/handler.go:12:9-14',
+      '/work/shop/auth/login.go:5:6-11: defined here as func Login(s store.Store) error',
+      '/usr/local/go/src/io/io.go:99:6-12',
+      'Login checks the credentials.',
+    ].join('\n');
+
+    expect(parseGoplsLocations(output, '/work/shop')).toEqual([
+      { file_path: 'api/handler.go', line: 12, column: 9, description: null },
+      {
+        file_path: 'auth/login.go',
+        line: 5,
+        column: 6,
+        description: 'defined here as func Login(s store.Store) error',
+      },
+    ]);
+  });
+});
+
+describe('declarationColumn', () => {
+  test('should find the declared name in bytes, after a method receiver', () => {
+    expect(declarationColumn('func Login(s store.Store) error {', 'Login')).toBe(6);
+    expect(declarationColumn('func (Get *getter) Get() {', 'getter.Get')).toBe(20);
+    expect(declarationColumn('var café, prix = 1, 2', 'prix')).toBe(12);
+    expect(declarationColumn('func Logout() {', 'Login')).toBeNull();
+  });
+});
+
+describe('identifierAt', () => {
+  test('should return the identifier covering a byte column', () => {
+    expect(identifierAt('\tif err := auth.Login(s); err != nil {', 17)).toBe('Login');
+    expect(identifierAt('\tif err := auth.Login(s); err != nil {', 16)).toBeNull();
+    expect(identifierAt('\tprix := café + taxe', 18)).toBe('taxe');
+  });
+});
+
+describe('mergeReferences', () => {
+  test('should serve edited and new files from gopls and the rest from the index', () => {
+    const indexed = [ref('api/handler.go', 12), ref('api/routes.go', 30)];
+    const fromGopls = [
+      ref('api/handler.go', 12, null),
+      ref('api/routes.go', 34, null),
+      ref('api/routes.go', 34, null),
+      ref('cmd/new.go', 3, null),
+    ];
+
+    const indexedFiles = new Set(['api/handler.go', 'api/routes.go']);
+    const merged = mergeReferences(indexed, fromGopls, new Set(['api/routes.go']), indexedFiles);
+
+    expect(merged.map((r) => [r.file_path, r.line_number, r.source, r.symbol_name])).toEqual([
+      ['api/handler.go', 12, 'index', 'Handler'],
+      ['api/routes.go', 34, 'gopls', null],
+      ['cmd/new.go', 3, 'gopls', null],
+    ]);
+  });
+});
@@ -0,0 +1,158 @@
+/**
+ * Unit tests for hierarchical file outlines
+ */
+
+import * as fs from 'node:fs';
+import * as path from 'node:path';
+
+import { describe, test, expect } from '@jest/globals';
+import { buildOutline, countOutline, type OutlineNode } from '../../../src/retrieval/outline';
+import { Language, NodeType, type ParsedNode } from '../../../src/types/indexing';
+
+const SAMPLE_GO = fs.readFileSync(path.join(__dirname, '../../fixtures/sample.go'), 'utf-8');
+
+/**
+ * Parsed node spanning lines of a file, as the parser reports it
+ */
+const node = (
+  code: string,
+  node_type: NodeType,
+  name: string,
+  start_line: number,
+  end_line = start_line,
+  overrides: Partial<ParsedNode> = {}
+): ParsedNode => ({
+  node_type,
+  name,
+  start_line,
+  end_line,
+  code_text: code
+    .split('\n')
+    .slice(start_line - 1, end_line)
+    .join('\n'),
+  ...overrides,
+});
+
+/** Declarations of tests/fixtures/sample.go, in the order the parser finds them */
+const goNodes = (): ParsedNode[] => {
+  const go = (type: NodeType, name: string, start: number, end?: number, overrides?: Partial<ParsedNode>): ParsedNode =>
+    node(SAMPLE_GO, type, name, start, end, overrides);
+  const method = (name: string, start: number, end: number): ParsedNode =>
+    go(NodeType.Method, name, start, end, { receiver: 'AuthService' });
+  return [
+    go(NodeType.Constant, 'SessionTimeout', 12),
+    go(NodeType.Constant, 'APIVersion', 15),
+    go(NodeType.Class, 'User', 18, 23, {
+      children: [
+        go(NodeType.Field, 'ID', 19, 19, { return_type: 'string' }),
+        go(NodeType.Field, 'Email', 20, 20, { return_type: 'string' }),
+        go(NodeType.Field, 'PasswordHash', 21, 21, { return_type: 'string' }),
+        go(NodeType.Field, 'Role', 22, 22, { return_type: 'UserRole' }),
+      ],
+    }),
+    go(NodeType.Type, 'UserRole', 26, 26, { return_type: 'int' }),
+    go(NodeType.Constant, 'RoleUser', 29, 29, { return_type: 'UserRole' }),
+    go(NodeType.Constant, 'RoleModerator', 30, 30, { return_type: 'UserRole' }),
+    go(NodeType.Constant, 'RoleAdmin', 31, 31, { return_type: 'UserRole' }),
+    go(NodeType.Class, 'AuthService', 35, 38),
+    go(NodeType.Function, 'NewAuthService', 41, 46),
+    method('Login', 50, 70),
+    method('queryUser', 73, 88),
+    method('verifyPassword', 91, 94),
+    method('CreateSession', 97, 111),
+    go(NodeType.Function, 'generateSessionID', 114, 116),
+    go(NodeType.Interface, 'PermissionChecker', 119, 121),
+    go(NodeType.Function, 'HasPermission', 124, 126),
+    go(NodeType.Function, 'CalculateComplexity', 129, 155),
+  ];
+};
+
+/** Names of an outline level */
+const names = (outline: OutlineNode): string[] => outline.children.map((child) => child.name);
+
+/** Entry of an outline level by name */
+const child = (outline: OutlineNode, name: string): OutlineNode => {
+  const found = outline.children.find((entry) => entry.name === name);
+  if (!found) throw new Error(`${name} not in outline of ${outline.name}`);
+  return found;
+};
+
+describe('buildOutline', () => {
+  test('should place Go methods under their receiver and typed constants under their type', () => {
+    const outline = buildOutline(goNodes(), SAMPLE_GO, 'auth/sample.go', Language.Go);
+
+    expect(outline).toMatchObject({ name: 'auth', kind: 'package', start_line: 1 });
+    expect(names(outline)).toEqual([
+      'SessionTimeout',
+      'APIVersion',
+      'User',
+      'UserRole',
+      'AuthService',
+      'NewAuthService',
+      'generateSessionID',
+      'PermissionChecker',
+      'HasPermission',
+      'CalculateComplexity',
+    ]);
+    expect(child(outline, 'UserRole')).toMatchObject({ kind: 'type', detail: 'int', start_line: 26 });
+    expect(names(child(outline, 'UserRole'))).toEqual(['RoleUser', 'RoleModerator', 'RoleAdmin']);
+    expect(names(child(outline, 'AuthService'))).toEqual(['Login', 'queryUser', 'verifyPassword', 'CreateSession']);
+    expect(child(child(outline, 'AuthService'), 'Login')).toMatchObject({
+      kind: 'method',
+      start_line: 50,
+      end_line: 70,
+      detail: 'func (s *AuthService) Login(email, password string) (*User, error)',
+    });
+    expect(names(child(outline, 'User'))).toEqual(['ID', 'Email', 'PasswordHash', 'Role']);
+    expect(child(child(outline, 'User'), 'Role')).toMatchObject({ kind: 'field', detail: 'UserRole' });
+    expect(countOutline(outline)).toBe(21);
+  });
+
+  test('should keep methods of types declared in other files at the top level', () => {
+    const nodes = goNodes().filter((parsed) => parsed.name !== 'AuthService');
+    const outline = buildOutline(nodes, SAMPLE_GO, 'auth/sample.go', Language.Go);
+
+    expect(names(outline)).toContain('Login');
+    expect(child(outline, 'Login').children).toEqual([]);
+  });
+
+  test('should nest by span and list class methods once', () => {
+    const code = [
+      'export class Store {',
+      '  get(key: string) {',
+      '    function normalize(k: string) {',
+      '      return k.trim();',
+      '    }',
+      '    return normalize(key);',
+      '  }',
+      '}',
+      'export const VERSION = 2;',
+    ].join('\n');
+    const get = node(code, NodeType.Method, 'get', 2, 7);
+    const nodes = [
+      node(code, NodeType.Class, 'Store', 1, 8, { children: [get] }),
+      node(code, NodeType.Function, 'get', 2, 7),
+      { ...get },
+      node(code, NodeType.Function, 'normalize', 3, 5),
+      node(code, NodeType.Function, '<anonymous>', 4),
+      node(code, NodeType.Constant, 'VERSION', 9),
+      node(code, NodeType.Import, 'store', 1),
+    ];
+    const outline = buildOutline(nodes, code, 'src/store.ts', Language.TypeScript);
+
+    expect(outline).toMatchObject({ name: 'store.ts', kind: 'module', end_line: 9 });
+    expect(names(outline)).toEqual(['Store', 'VERSION']);
+    const store = child(outline, 'Store');
+    expect(store.children.map((entry) => `${entry.kind} ${entry.name}`)).toEqual(['method get']);
+    expect(names(store.children[0])).toEqual(['normalize']);
+  });
+
+  test('should not group by declared type outside Go', () => {
+    const code = 'class Role {}\nconst ADMIN: Role = new Role();';
+    const nodes = [
+      node(code, NodeType.Class, 'Role', 1),
+      node(code, NodeType.Constant, 'ADMIN', 2, 2, { return_type: 'Role' }),
+    ];
+    expect(names(buildOutline(nodes, code, 'role.ts', Language.TypeScript))).toEqual(['Role', 'ADMIN']);
+  });
+});
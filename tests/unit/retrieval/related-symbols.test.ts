@@ -0,0 +1,99 @@
+/**
+ * Unit tests for ranking related symbols
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { rankRelated, type RelatedEvidence } from '../../../src/retrieval/related-symbols';
+import { type RelatedCandidateRecord } from '../../../src/types/database';
+
+const candidate = (
+  symbol_name: string,
+  name_tokens: string[],
+  overrides: Partial<RelatedCandidateRecord> = {}
+): RelatedCandidateRecord => ({
+  repo_id: 'shop',
+  symbol_name,
+  symbol_type: 'function',
+  file_path: 'internal/auth/session.go',
+  line_number: symbol_name.length,
+  symbol_id: null,
+  name_tokens,
+  ...overrides,
+});
+
+const TARGET = candidate('CreateSession', ['create', 'session'], { line_number: 1 });
+
+const evidence = (overrides: Partial<RelatedEvidence> = {}): RelatedEvidence => ({
+  target_files: 0,
+  sampled_files: 0,
+  shared_files: new Map(),
+  mention_files: new Map(),
+  call_degree: 0,
+  neighbors: new Map(),
+  ...overrides,
+});
+
+describe('rankRelated', () => {
+  test('should score files in common as a Jaccard similarity, scaled from the sample', () => {
+    const candidates = [candidate('SessionTimeout', ['session', 'timeout'], { symbol_type: 'constant' })];
+    const known = evidence({
+      target_files: 20,
+      sampled_files: 10,
+      shared_files: new Map([['SessionTimeout', 5]]),
+      mention_files: new Map([['SessionTimeout', 15]]),
+    });
+
+    const [related] = rankRelated(TARGET, candidates, known, 10);
+
+    // 10 of the 20 files shared, 25 files mention either
+    expect(related.cooccurrence).toBeCloseTo(0.4);
+    expect(related.name_similarity).toBeCloseTo(1 / 3);
+    expect(related.call_graph).toBe(0);
+    expect(related.score).toBeCloseTo((0.4 * 0.4 + 0.25 / 3) / 0.65);
+  });
+
+  test('should score direct calls and shared callers and callees', () => {
+    const candidates = [candidate('Login', ['login']), candidate('generateSessionID', ['generate', 'session', 'id'])];
+    const graph = evidence({
+      call_degree: 3,
+      neighbors: new Map([
+        ['Login', { name: 'Login', adjacent: true, shared: 1, degree: 4 }],
+        ['generateSessionID', { name: 'generateSessionID', adjacent: false, shared: 2, degree: 2 }],
+      ]),
+    });
+
+    const related = rankRelated(TARGET, candidates, graph, 10);
+
+    // Login calls or is called by it; generateSessionID shares two of its three neighbors
+    expect(related.map((entry) => [entry.symbol_name, entry.call_graph])).toEqual([
+      ['Login', 0.5 + 0.5 * (1 / 4)],
+      ['generateSessionID', 0.5 * (2 / 3)],
+    ]);
+  });
+
+  test('should leave out the symbol, its other declarations, and symbols scoring nothing', () => {
+    const candidates = [
+      TARGET,
+      candidate('CreateSession', ['create', 'session'], { file_path: 'internal/auth/session_windows.go' }),
+      candidate('Render', ['render']),
+      candidate('SessionStore', ['session', 'store']),
+      candidate('SessionStore', ['session', 'store']),
+    ];
+
+    const related = rankRelated(TARGET, candidates, evidence(), 10);
+
+    expect(related.map((entry) => entry.symbol_name)).toEqual(['SessionStore']);
+    expect(related[0].score).toBeCloseTo(1 / 3);
+    expect(rankRelated(candidate('x', []), candidates, evidence(), 10)).toEqual([]);
+  });
+
+  test('should keep the best k, ties by name', () => {
+    const candidates = ['SessionC', 'SessionA', 'SessionB'].map((name, index) =>
+      candidate(name, ['session', name.toLowerCase()], { line_number: index + 10 })
+    );
+
+    const related = rankRelated(TARGET, candidates, evidence(), 2);
+
+    expect(related.map((entry) => entry.symbol_name)).toEqual(['SessionA', 'SessionB']);
+  });
+});
@@ -0,0 +1,79 @@
+/**
+ * Unit tests for source snippets of query results
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { extractSnippet, scanLineEnds, truncationPoint } from '../../../src/retrieval/snippets';
+
+const SOURCE = [
+  'package auth', // 1
+  '', // 2
+  '// Login checks a user’s password', // 3
+  'func (s *AuthService) Login(name, password string) error {', // 4
+  '\tuser, err := s.find(name)', // 5
+  '\tif err != nil {', // 6
+  '\t\treturn err', // 7
+  '\t}', // 8
+  '\treturn verify(user, password)', // 9
+  '}  ', // 10
+  '', // 11
+  'func helper() {}', // 12
+].join('\n');
+
+describe('extractSnippet', () => {
+  test('should return the span with context lines and its UTF-8 byte offsets', () => {
+    const snippet = extractSnippet(SOURCE, { line_number: 4, end_line: 10 }, { context: 1 });
+
+    expect(snippet?.lines.map((line) => [line.line, line.context])).toEqual([
+      [3, true],
+      [4, false],
+      [5, false],
+      [6, false],
+      [7, false],
+      [8, false],
+      [9, false],
+      [10, false],
+      [11, true],
+    ]);
+    expect(snippet?.omitted_lines).toBe(0);
+    const bytes = Buffer.from(SOURCE, 'utf-8');
+    const span = bytes.subarray(snippet?.start_byte, snippet?.end_byte).toString('utf-8');
+    expect(span.startsWith('func (s *AuthService) Login(')).toBe(true);
+    expect(span.endsWith('verify(user, password)\n}')).toBe(true);
+  });
+
+  test('should treat a missing end line as a single-line symbol and clamp context to the file', () => {
+    const snippet = extractSnippet(SOURCE, { line_number: 12, end_line: null }, { context: 3 });
+
+    expect(snippet?.lines.map((line) => line.line)).toEqual([9, 10, 11, 12]);
+    expect(snippet?.end_line).toBe(12);
+    expect(Buffer.byteLength(SOURCE, 'utf-8')).toBe(snippet?.end_byte);
+    expect(extractSnippet(SOURCE, { line_number: 40, end_line: 41 })).toBeNull();
+  });
+
+  test('should keep the head and closing line of a long span, cut between statements', () => {
+    const body = Array.from({ length: 20 }, (_, index) => [
+      `\tif step${String(index)}() {`,
+      `\t\tlog("step ${String(index)} {")`,
+      '\t}',
+    ]).flat();
+    const source = ['func Run() {', ...body, '}'].join('\n');
+
+    const snippet = extractSnippet(source, { line_number: 1, end_line: 62 }, { language: 'go', maxLines: 10 });
+
+    // Nine lines fit before the closing brace; the last if block complete by then ends at line 7
+    const shown = snippet?.lines.map((line) => line.line);
+    expect(shown).toEqual([1, 2, 3, 4, 5, 6, 7, 62]);
+    expect(snippet?.omitted_lines).toBe(54);
+  });
+});
+
+describe('truncationPoint', () => {
+  test('should not cut inside block comments or multi-line strings', () => {
+    const lines = ['func Query() string {', '\treturn `', 'SELECT *', 'FROM users', '`', '}'];
+
+    expect(scanLineEnds(lines, 'go').map((state) => state.open)).toEqual([false, true, true, true, false, false]);
+    expect(truncationPoint(lines, 'go', 5)).toBe(1);
+    expect(truncationPoint(['def f():', '    """Doc', '    more', '    """', '    return 1'], 'python', 4)).toBe(1);
+  });
+});
@@ -0,0 +1,112 @@
+/**
+ * Unit tests for test-to-code mapping
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import { goTestCommands, TestMap } from '../../../src/retrieval/test-mapping';
+import { type GoCallRecord, type SymbolVariantRecord } from '../../../src/types/database';
+
+const fn = (file_path: string, symbol_name: string, symbol_type: string, line_number: number): SymbolVariantRecord => ({
+  repo_id: 'app',
+  symbol_name,
+  symbol_type,
+  file_path,
+  line_number,
+  build_constraint: null,
+});
+
+const call = (
+  file_path: string,
+  caller_name: string,
+  callee_name: string,
+  call_kind: GoCallRecord['call_kind'],
+  callee_package: string | null = null
+): GoCallRecord => ({
+  repo_id: 'app',
+  file_path,
+  caller_name,
+  caller_line: 1,
+  callee_name,
+  callee_package,
+  callee_qualifier: call_kind === 'dynamic' ? 'svc' : null,
+  call_kind,
+  line_number: 2,
+  column_number: 3,
+});
+
+const FUNCTIONS = [
+  fn('internal/auth/service.go', 'AuthService.Login', 'method', 10),
+  fn('internal/auth/service.go', 'hashPassword', 'function', 30),
+  fn('internal/auth/service_test.go', 'newTestService', 'function', 5),
+  fn('internal/auth/service_test.go', 'TestLogin', 'test', 12),
+  fn('internal/auth/service_test.go', 'BenchmarkHash', 'benchmark', 40),
+  fn('internal/api/handler.go', 'Handler.Login', 'method', 8),
+  fn('internal/api/handler_test.go', 'TestHandler', 'test', 9),
+];
+
+const CALLS = [
+  call('internal/auth/service.go', 'AuthService.Login', 'hashPassword', 'function'),
+  call('internal/auth/service_test.go', 'TestLogin', 'newTestService', 'function'),
+  call('internal/auth/service_test.go', 'newTestService', 'AuthService.Login', 'method'),
+  call('internal/auth/service_test.go', 'BenchmarkHash', 'hashPassword', 'function'),
+  call('internal/api/handler.go', 'Handler.Login', 'hashPassword', 'import', 'github.com/acme/app/internal/auth'),
+  call('internal/api/handler_test.go', 'TestHandler', 'Login', 'dynamic'),
+];
+
+describe('TestMap', () => {
+  test('should resolve names as the call graph takes them', () => {
+    const map = new TestMap(FUNCTIONS, CALLS);
+
+    expect(map.resolve('AuthService.Login').map((f) => f.file_path)).toEqual(['internal/auth/service.go']);
+    expect(map.resolve('Login')).toHaveLength(2);
+    expect(map.resolve('auth.hashPassword')).toHaveLength(1);
+    expect(map.resolve('TestLogin')).toEqual([]);
+  });
+
+  test('should link tests through helpers, imports, and production calls', () => {
+    const map = new TestMap(FUNCTIONS, CALLS);
+    const links = map.testsFor(map.resolve('hashPassword'));
+
+    expect(links.map((l) => [l.test.symbol_name, l.depth, l.possible])).toEqual([
+      ['TestHandler', 2, true],
+      ['TestLogin', 3, false],
+      ['BenchmarkHash', 1, false],
+    ]);
+  });
+
+  test('should drop possible links when exact and stop at the depth', () => {
+    const exact = new TestMap(FUNCTIONS, CALLS, { exact: true });
+    expect(exact.testsFor(exact.resolve('hashPassword')).map((l) => l.test.symbol_name)).toEqual([
+      'TestLogin',
+      'BenchmarkHash',
+    ]);
+
+    const map = new TestMap(FUNCTIONS, CALLS);
+    expect(map.testsFor(map.resolve('hashPassword'), 1).map((l) => l.test.symbol_name)).toEqual(['BenchmarkHash']);
+  });
+
+  test('should list the tests covering a file with their nearest declaration', () => {
+    const map = new TestMap(FUNCTIONS, CALLS);
+    const links = map.coveredBy('internal/auth/service.go');
+
+    expect(links.map((l) => [l.test.symbol_name, l.target.symbol_name, l.depth])).toEqual([
+      ['TestHandler', 'AuthService.Login', 1],
+      ['TestLogin', 'AuthService.Login', 2],
+      ['BenchmarkHash', 'hashPassword', 1],
+    ]);
+    expect(map.coveredBy('internal/api/handler_test.go').map((l) => [l.test.symbol_name, l.depth])).toEqual([
+      ['TestHandler', 0],
+    ]);
+  });
+});
+
+describe('goTestCommands', () => {
+  test('should run each package tests by name, leaving out benchmarks', () => {
+    const map = new TestMap(FUNCTIONS, CALLS);
+
+    expect(goTestCommands(map.testsFor(map.resolve('hashPassword')))).toEqual([
+      "go test -run '^(TestHandler)$' ./internal/api",
+      "go test -run '^(TestLogin)$' ./internal/auth",
+    ]);
+  });
+});
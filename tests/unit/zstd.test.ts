@@ -0,0 +1,28 @@
+/**
+ * Unit tests for the helpers shared by compact snapshots and packed indexes
+ */
+
+import { describe, test, expect } from '@jest/globals';
+import * as zlib from 'node:zlib';
+
+import { PackedIndexError } from '@utils/errors';
+import { pushVarint, requireZstd } from '@utils/zstd';
+
+describe('pushVarint', () => {
+  test('should write 7 bits per byte, low bits first', () => {
+    const bytes: number[] = [];
+    for (const value of [0, 127, 128, 300, 2 ** 32]) pushVarint(bytes, value);
+
+    expect(bytes).toEqual([0x00, 0x7f, 0x80, 0x01, 0xac, 0x02, 0x80, 0x80, 0x80, 0x80, 0x10]);
+  });
+});
+
+describe('requireZstd', () => {
+  test('should pass when this Node.js has zstd and name the feature otherwise', () => {
+    const available = typeof zlib.zstdCompressSync === 'function';
+    const check = (): void => requireZstd('Packed indexes', PackedIndexError);
+
+    if (available) expect(check).not.toThrow();
+    else expect(check).toThrow(/^Packed indexes need Node\.js 22\.15 or later/);
+  });
+});